@@ -57,6 +57,9 @@ var hostEntries = []HostEntry{
 	{Domain: "redis-sentinel-1.example.com", IP: "192.168.240.121"},
 	{Domain: "redis-sentinel-2.example.com", IP: "192.168.240.122"},
 
+	// Toxiproxy API of the Chaos suite.
+	{Domain: "toxiproxy.example.com", IP: "192.168.240.130"},
+
 	// Kubernetes dashboard.
 	{Domain: "kubernetes.example.com", IP: "192.168.240.110"},
 	// OIDC tester app
@@ -14,8 +14,18 @@ func buildAutheliaBinary(xflags []string) {
 	cmd := utils.CommandWithStdout("go", "build", "-o", "../../"+OutputDir+"/authelia", "-ldflags", strings.Join(xflags, " "))
 	cmd.Dir = "cmd/authelia"
 
+	arch := os.Getenv("GOARCH")
+	if arch == "" {
+		arch = defaultArch
+	}
+
 	cmd.Env = append(os.Environ(),
-		"GOOS=linux", "GOARCH=amd64", "CGO_ENABLED=0")
+		"GOOS=linux", "GOARCH="+arch, "CGO_ENABLED=0")
+
+	// ARMv7 is the oldest ARM architecture the official images are built for.
+	if arch == "arm" && os.Getenv("GOARM") == "" {
+		cmd.Env = append(cmd.Env, "GOARM=7")
+	}
 
 	err := cmd.Run()
 	if err != nil {
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/authelia/authelia/internal/loadtest"
+	"github.com/authelia/authelia/internal/suites"
+)
+
+var loadTestBudgetPath string
+var loadTestTargets []string
+
+func init() {
+	LoadTestCmd.Flags().StringVar(&loadTestBudgetPath, "budget", "internal/loadtest/budget.yml", "The performance budget to enforce")
+	LoadTestCmd.Flags().StringSliceVar(&loadTestTargets, "target", nil, "The targets to attack, all the targets of the budget by default")
+}
+
+// LoadTestCmd Command for running the load tests against the running suite.
+var LoadTestCmd = &cobra.Command{
+	Use:   "loadtest",
+	Short: "Run the load tests against the running suite and enforce the performance budget",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runLoadTest(); err != nil {
+			log.Fatal(err)
+		}
+	},
+	Args: cobra.NoArgs,
+}
+
+func runLoadTest() error {
+	budgets, err := loadtest.LoadBudgets(loadTestBudgetPath)
+	if err != nil {
+		return err
+	}
+
+	names := loadTestTargets
+
+	if len(names) == 0 {
+		for name := range budgets {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+	}
+
+	client := suites.NewHTTPClient()
+	client.Timeout = 10 * time.Second
+	client.Transport.(*http.Transport).MaxIdleConnsPerHost = 100
+
+	var failures []error
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "TARGET\tREQUESTS\tSUCCESS\tTHROUGHPUT\tMEAN\tP50\tP95\tP99\tMAX")
+
+	for _, name := range names {
+		budget, ok := budgets[name]
+		if !ok {
+			return fmt.Errorf("the target %s is not in the performance budget", name)
+		}
+
+		target, err := newLoadTestTarget(client, name)
+		if err != nil {
+			return err
+		}
+
+		log.Infof("Attacking target %s with %d requests per second for %s", name, budget.Rate, budget.Duration)
+
+		metrics := loadtest.Attack(client, target, budget.Rate, budget.Duration)
+
+		fmt.Fprintf(writer, "%s\t%d\t%.2f%%\t%.1f/s\t%s\t%s\t%s\t%s\t%s\n", name, metrics.Requests,
+			metrics.SuccessRatio()*100, metrics.Throughput(), metrics.Latencies.Mean, metrics.Latencies.P50,
+			metrics.Latencies.P95, metrics.Latencies.P99, metrics.Latencies.Max)
+
+		failures = append(failures, budget.Check(metrics)...)
+	}
+
+	_ = writer.Flush()
+
+	for _, failure := range failures {
+		log.Error(failure)
+	}
+
+	if len(failures) != 0 {
+		return errors.New("the performance budget is exceeded")
+	}
+
+	log.Info("The performance budget is met")
+
+	return nil
+}
+
+func newLoadTestTarget(client *http.Client, name string) (target loadtest.Target, err error) {
+	switch name {
+	case loadtest.TargetVerify:
+		cookie, err := loadtest.Login(client, suites.AutheliaBaseURL, "john", "password")
+		if err != nil {
+			return target, err
+		}
+
+		return loadtest.NewVerifyTarget(suites.AutheliaBaseURL, suites.SingleFactorBaseURL, cookie), nil
+	case loadtest.TargetFirstFactor:
+		return loadtest.NewFirstFactorTarget(suites.AutheliaBaseURL, "john", "password"), nil
+	default:
+		return target, fmt.Errorf("the target %s is unknown", name)
+	}
+}
@@ -1,5 +1,17 @@
 package main
 
+import "time"
+
+// startupCheckTimeout is the time the startup checks have to complete before they fail.
+const startupCheckTimeout = 30 * time.Second
+
+// serviceName is the name of the Windows service and of its event log source.
+const serviceName = "authelia"
+
+// defaultStandaloneDataDirectory is the data directory of the standalone mode, the configuration directory of the
+// container image.
+const defaultStandaloneDataDirectory = "/config"
+
 const fmtAutheliaLong = `authelia %s
 
 An open-source authentication and authorization server providing 
@@ -1,34 +1,58 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
 	"runtime"
+	"strings"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
+	"github.com/authelia/authelia/internal/audit"
 	"github.com/authelia/authelia/internal/authentication"
 	"github.com/authelia/authelia/internal/authorization"
 	"github.com/authelia/authelia/internal/commands"
 	"github.com/authelia/authelia/internal/configuration"
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/daemon"
+	"github.com/authelia/authelia/internal/events"
+	"github.com/authelia/authelia/internal/geoip"
 	"github.com/authelia/authelia/internal/logging"
+	"github.com/authelia/authelia/internal/logout"
 	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/models"
 	"github.com/authelia/authelia/internal/notification"
 	"github.com/authelia/authelia/internal/oidc"
 	"github.com/authelia/authelia/internal/regulation"
+	"github.com/authelia/authelia/internal/risk"
+	"github.com/authelia/authelia/internal/rpc"
 	"github.com/authelia/authelia/internal/server"
 	"github.com/authelia/authelia/internal/session"
+	"github.com/authelia/authelia/internal/standalone"
+	"github.com/authelia/authelia/internal/startup"
 	"github.com/authelia/authelia/internal/storage"
 	"github.com/authelia/authelia/internal/utils"
 )
 
-var configPathFlag string
+var (
+	configPathFlag    string
+	dryRunFlag        bool
+	strictStartupFlag bool
+
+	standaloneDataDirFlag string
+	standaloneDomainFlag  string
+)
 
 //nolint:gocyclo // TODO: Consider refactoring/simplifying, time permitting.
 func startServer() {
 	logger := logging.Logger()
-	config, errs := configuration.Read(configPathFlag)
+	sources := configuration.NewSources(configPathFlag, configuration.NewDefaultSources()...)
+	config, errs := sources.Load()
 
 	if len(errs) > 0 {
 		for _, err := range errs {
@@ -38,7 +62,7 @@ func startServer() {
 		os.Exit(1)
 	}
 
-	autheliaCertPool, errs, nonFatalErrs := utils.NewX509CertPool(config.CertificatesDirectory)
+	autheliaCertPool, errs, nonFatalErrs := utils.LoadX509CertPool(config.CertificatesDirectory)
 	if len(errs) > 0 {
 		for _, err := range errs {
 			logger.Error(err)
@@ -57,6 +81,13 @@ func startServer() {
 		logger.Fatalf("Cannot initialize logger: %v", err)
 	}
 
+	// The usernames, IP addresses and emails are minimized in all the entries logged from now on, including the audit
+	// log subscriber of the event bus.
+	minimizer := logging.NewMinimizer(config.Logging.Minimization)
+	if minimizer != nil {
+		logger.AddHook(minimizer)
+	}
+
 	logger.Infof("Authelia %s is starting", utils.Version())
 
 	switch config.Logging.Level {
@@ -81,77 +112,400 @@ func startServer() {
 		logger.Info("===> Authelia is running in development mode. <===")
 	}
 
-	var storageProvider storage.Provider
+	if config.Resolver != nil {
+		// The outbound connections resolve the names with the default resolver.
+		net.DefaultResolver = utils.NewResolver(*config.Resolver)
 
-	switch {
-	case config.Storage.PostgreSQL != nil:
-		storageProvider = storage.NewPostgreSQLProvider(*config.Storage.PostgreSQL)
-	case config.Storage.MySQL != nil:
-		storageProvider = storage.NewMySQLProvider(*config.Storage.MySQL)
-	case config.Storage.Local != nil:
-		storageProvider = storage.NewSQLiteProvider(config.Storage.Local.Path)
-	default:
-		logger.Fatalf("Unrecognized storage backend")
+		logger.Infof("Resolving the names with the DNS servers %s", strings.Join(config.Resolver.Addresses, ", "))
 	}
 
-	var (
-		userProvider authentication.UserProvider
-		err          error
-	)
+	if config.OutboundProxy != nil {
+		// The outbound HTTP requests are sent with the default transport, except the requests to the Duo API.
+		http.DefaultTransport.(*http.Transport).Proxy = utils.NewProxyFunc(config.OutboundProxy)
 
-	switch {
-	case config.AuthenticationBackend.File != nil:
-		userProvider = authentication.NewFileUserProvider(config.AuthenticationBackend.File)
-	case config.AuthenticationBackend.LDAP != nil:
-		userProvider, err = authentication.NewLDAPUserProvider(config.AuthenticationBackend, autheliaCertPool)
-		if err != nil {
-			logger.Fatalf("Failed to Check LDAP Authentication Backend: %v", err)
-		}
-	default:
-		logger.Fatalf("Unrecognized authentication backend")
+		// The URL has already been validated, its password must not be logged.
+		proxyURL, _ := url.Parse(config.OutboundProxy.URL)
+		logger.Infof("Sending the outbound HTTP requests through the proxy %s", proxyURL.Redacted())
 	}
 
-	var notifier notification.Notifier
+	// The policy wraps the proxy of the default transport, so it must be applied after the proxy.
+	utils.NewOutboundPolicy(config.OutboundRequests).Apply(http.DefaultTransport.(*http.Transport))
+
+	if dryRunFlag {
+		if _, err := storage.NewDryRunProvider(config.Storage, os.Stdout); err != nil {
+			logger.Fatalf("Failed to initialize storage provider: %v", err)
+		}
 
-	switch {
-	case config.Notifier.SMTP != nil:
-		notifier = notification.NewSMTPNotifier(*config.Notifier.SMTP, autheliaCertPool)
-	case config.Notifier.FileSystem != nil:
-		notifier = notification.NewFileNotifier(*config.Notifier.FileSystem)
-	default:
-		logger.Fatalf("Unrecognized notifier")
+		logger.Info("Dry-run completed, the server is not started")
+
+		return
 	}
 
-	if !config.Notifier.DisableStartupCheck {
-		_, err = notifier.StartupCheck()
+	storageProvider, err := storage.NewProvider(config.Storage)
+	if err != nil {
+		logger.Fatalf("Failed to initialize storage provider: %v", err)
+	}
+
+	// The upgrader is retrieved before the provider is wrapped by the cache which hides it.
+	storageUpgrader, _ := storageProvider.(storage.SchemaUpgrader)
+
+	clock := utils.RealClock{}
+
+	if !config.Storage.Cache.Disable {
+		storageProvider, err = newCachingStorageProvider(config, storageProvider, autheliaCertPool, clock)
 		if err != nil {
-			logger.Fatalf("Error during notifier startup check: %s", err)
+			logger.Fatalf("Failed to initialize the storage cache: %v", err)
 		}
 	}
 
-	clock := utils.RealClock{}
+	userProvider, err := authentication.NewUserProvider(config.AuthenticationBackend, autheliaCertPool)
+	if err != nil {
+		logger.Fatalf("Failed to Check Authentication Backend: %v", err)
+	}
+
+	notifier, err := notification.NewNotifier(*config.Notifier, autheliaCertPool)
+	if err != nil {
+		logger.Fatalf("Failed to initialize notifier: %v", err)
+	}
+
+	// The queue sends through the reloadable notifier so the notifications queued before a reload are sent by the new
+	// notifier.
+	reloadableNotifier := notification.NewReloadableNotifier(notifier)
+	notifier = notification.NewQueuedNotifier(*config.Notifier.Queue, reloadableNotifier, clock)
+
+	disableHTMLEmails := config.Notifier.SMTP != nil && config.Notifier.SMTP.DisableHTMLEmails
+
+	var operator *notification.OperatorNotifier
+
+	if config.Notifier.Operator != nil {
+		operator = notification.NewOperatorNotifier(*config.Notifier.Operator, notifier, clock, disableHTMLEmails)
+	}
+
 	authorizer := authorization.NewAuthorizer(config)
 	sessionProvider := session.NewProvider(config.Session, autheliaCertPool)
 	regulator := regulation.NewRegulator(config.Regulation, storageProvider, clock)
 
+	storage.NewPruner(config.Storage.Retention, config.Session, storageProvider, clock).Start()
+
 	oidcProvider, err := oidc.NewOpenIDConnectProvider(config.IdentityProviders.OIDC)
 	if err != nil {
 		logger.Fatalf("Error initializing OpenID Connect Provider: %+v", err)
 	}
 
+	if oidcProvider.KeyManager != nil {
+		oidcProvider.KeyManager.StartRotation(config.IdentityProviders.OIDC.KeyRotationInterval, clock)
+	}
+
+	startOperatorMonitor(config, operator, clock, storageUpgrader, userProvider, oidcProvider)
+
+	checks := []startup.Check{
+		startup.NewStorageCheck(storageProvider),
+		startup.NewCheck("session", sessionProvider),
+	}
+
+	if checker, ok := userProvider.(startup.Checker); ok {
+		checks = append(checks, startup.NewCheck("authentication backend", checker))
+	}
+
+	if !config.Notifier.DisableStartupCheck {
+		checks = append(checks, startup.NewNotifierCheck(notifier))
+	}
+
+	if oidcProvider.KeyManager != nil {
+		checks = append(checks, startup.NewCheck("openid connect keys", oidcProvider.KeyManager))
+	}
+
+	report := startup.Run(checks, startupCheckTimeout)
+	report.Log(logger, strictStartupFlag)
+
+	if report.Failed(strictStartupFlag) {
+		logger.Fatal("Authelia is not ready to start, see the failed startup checks above")
+	}
+
+	// The clients registered at runtime are loaded once the storage schema is up to date.
+	if oidcConfig := config.IdentityProviders.OIDC; oidcConfig != nil && oidcConfig.DynamicRegistration != nil {
+		clients, err := storageProvider.LoadOIDCClients()
+		if err != nil {
+			logger.Fatalf("Error loading the registered OpenID Connect clients: %+v", err)
+		}
+
+		oidcProvider.Store.SetRegisteredClients(clients)
+	}
+
+	var geoIPProvider geoip.Provider
+
+	if config.GeoIP != nil {
+		geoIPProvider, err = geoip.NewCSVProvider(config.GeoIP.Database)
+		if err != nil {
+			logger.Fatalf("Error initializing GeoIP Provider: %+v", err)
+		}
+	}
+
+	var riskEvaluator *risk.Evaluator
+
+	if config.Risk != nil {
+		riskEvaluator = risk.NewEvaluator(config.Risk, storageProvider, geoIPProvider)
+	}
+
+	var logoutProvider *logout.Provider
+
+	if config.Logout != nil {
+		logoutProvider, err = logout.NewProvider(config.Logout)
+		if err != nil {
+			logger.Fatalf("Error initializing the applications logout: %+v", err)
+		}
+	}
+
+	eventBus := events.NewBus()
+	eventBus.Subscribe(events.NewMetricsSubscriber())
+	eventBus.Subscribe(events.NewAuditSubscriber(logger))
+
+	if config.Audit != nil {
+		auditLogger, err := audit.NewLogger(config.Audit, minimizer)
+		if err != nil {
+			logger.Fatalf("Error initializing the audit log: %+v", err)
+		}
+
+		auditLogger.Start()
+		eventBus.Subscribe(auditLogger.Subscriber())
+	}
+
+	eventStream := events.NewBroker()
+	eventBus.Subscribe(eventStream.Subscriber(), events.PushRequested, events.SessionsRevoked)
+
+	// The break-glass accounts are only known by the handlers and the notifications, the monitoring, the checks and the
+	// watch of the authentication backend use the backend itself.
+	var accounts authentication.UserProvider = userProvider
+
+	if config.AuthenticationBackend.BreakGlass != nil {
+		accounts = authentication.NewBreakGlassUserProvider(userProvider, config.AuthenticationBackend.BreakGlass)
+
+		eventBus.Subscribe(notification.NewBreakGlassSubscriber(operator), events.BreakGlassUsed, events.BreakGlassDenied)
+	}
+
+	throttler := notification.NewThrottler(*config.Notifier.Throttling, storageProvider, notifier, accounts, clock, disableHTMLEmails)
+	throttler.Start()
+
+	eventBus.Subscribe(throttler.Throttle(models.NotificationKindDeviceRegistered,
+		notification.NewDeviceRegistrationSubscriber(notifier, disableHTMLEmails)), events.DeviceRegistered)
+	eventBus.Subscribe(throttler.Throttle(models.NotificationKindDeviceRemoved,
+		notification.NewDeviceRemovalSubscriber(notifier, accounts, disableHTMLEmails)), events.DeviceRevoked, events.SecondFactorResetApproved)
+	eventBus.Subscribe(throttler.Throttle(models.NotificationKindSessionImpossibleTravel,
+		notification.NewSessionImpossibleTravelSubscriber(notifier, disableHTMLEmails)), events.SessionImpossibleTravel)
+
+	if config.Notifier.Throttling.FailedAttempts > 0 {
+		eventBus.Subscribe(throttler.FailedAttemptsSubscriber(), events.LoginFailed)
+	}
+
 	providers := middlewares.Providers{
 		Authorizer:      authorizer,
-		UserProvider:    userProvider,
+		UserProvider:    accounts,
 		Regulator:       regulator,
 		OpenIDConnect:   oidcProvider,
 		StorageProvider: storageProvider,
 		Notifier:        notifier,
 		SessionProvider: sessionProvider,
+		GeoIP:           geoIPProvider,
+		Risk:            riskEvaluator,
+		Events:          eventBus,
+		EventStream:     eventStream,
+		Logout:          logoutProvider,
+	}
+
+	watchSplitFiles(config, authorizer, userProvider, oidcProvider)
+	watchConfiguration(sources, config, authorizer, oidcProvider, reloadableNotifier, autheliaCertPool)
+
+	// The providers are created first so the certificate authorities of their TLS configuration are watched too.
+	if err = autheliaCertPool.Watch(); err != nil {
+		logger.Errorf("Unable to watch the certificates, they won't be reloaded when they change: %v", err)
+	}
+
+	if config.GRPC != nil {
+		go rpc.StartServer(*config, providers)
+	}
+
+	if config.Metrics != nil {
+		go server.StartMetricsServer(*config)
 	}
 
 	server.StartServer(*config, providers)
 }
 
+// startOperatorMonitor monitors the expiry of the certificates in the background and exposes it as metrics. When the
+// operator alerts are configured, the operators are also alerted of the certificates nearing their expiry, of the
+// upgrade of the storage schema, of the rotations of the OpenID Connect issuer key and when the LDAP server becomes
+// unreachable.
+func startOperatorMonitor(config *schema.Configuration, operator *notification.OperatorNotifier, clock utils.Clock,
+	storageUpgrader storage.SchemaUpgrader, userProvider authentication.UserProvider, oidcProvider oidc.OpenIDConnectProvider) {
+	operatorConfig := schema.DefaultNotifierOperatorConfiguration
+
+	if operator != nil {
+		operatorConfig = *config.Notifier.Operator
+
+		if storageUpgrader != nil {
+			if from, to, upgraded := storageUpgrader.SchemaUpgrade(); upgraded {
+				operator.Alert(notification.AlertStorageMigrated, "Storage schema upgraded",
+					fmt.Sprintf("The storage schema has been upgraded from v%d to v%d.", from, to))
+			}
+		}
+
+		if oidcProvider.KeyManager != nil {
+			oidcProvider.KeyManager.OnActiveKeyChange(func(keyID string) {
+				operator.Alert(notification.AlertKeyRotated, "OpenID Connect issuer key rotated",
+					fmt.Sprintf("The OpenID Connect issuer key %s is now the active key.", keyID))
+			})
+		}
+	}
+
+	monitor := notification.NewOperatorMonitor(operatorConfig, operator, clock)
+
+	if config.TLSCert != "" {
+		monitor.WatchCertificate("server", config.TLSCert)
+	}
+
+	if config.GRPC != nil && config.GRPC.TLSCert != "" {
+		monitor.WatchCertificate("gRPC server", config.GRPC.TLSCert)
+	}
+
+	if config.CertificatesDirectory != "" {
+		monitor.WatchCertificatesDirectory("trusted", config.CertificatesDirectory)
+	}
+
+	if oidcConfig := config.IdentityProviders.OIDC; oidcConfig != nil {
+		monitor.WatchCertificatesPEM("OpenID Connect issuer key", "issuer_private_key", []byte(oidcConfig.IssuerPrivateKey))
+
+		for i, key := range oidcConfig.IssuerPrivateKeys {
+			monitor.WatchCertificatesPEM("OpenID Connect issuer key", fmt.Sprintf("issuer_private_keys[%d]", i), []byte(key.Key))
+		}
+	}
+
+	if ldapProvider, ok := userProvider.(*authentication.LDAPUserProvider); ok && operator != nil {
+		monitor.WatchDependency(notification.AlertLDAPUnreachable, "LDAP server", ldapProvider.StartupCheck)
+	}
+
+	monitor.Start()
+}
+
+// newCachingStorageProvider wraps the storage provider with a cache, the invalidations are propagated to the other
+// instances through redis when the sessions are stored in redis.
+func newCachingStorageProvider(config *schema.Configuration, provider storage.Provider,
+	certPool *utils.X509CertPool, clock utils.Clock) (storage.Provider, error) {
+	if invalidator := session.NewRedisCacheInvalidator(config.Session, certPool); invalidator != nil {
+		return storage.NewCachingProvider(provider, config.Storage.Cache, invalidator, clock)
+	}
+
+	return storage.NewCachingProvider(provider, config.Storage.Cache, nil, clock)
+}
+
+// watchSplitFiles reloads the users database, the access control rules and the OpenID Connect clients independently
+// when their own file changes.
+func watchSplitFiles(config *schema.Configuration, authorizer *authorization.Authorizer,
+	userProvider authentication.UserProvider, oidcProvider oidc.OpenIDConnectProvider) {
+	logger := logging.Logger()
+
+	if fileProvider, ok := userProvider.(*authentication.FileUserProvider); ok && config.AuthenticationBackend.File.Watch {
+		if err := utils.WatchFile(config.AuthenticationBackend.File.Path, "users database", fileProvider.Reload); err != nil {
+			logger.Errorf("Unable to watch the users database, it won't be reloaded when it changes: %v", err)
+		}
+	}
+
+	if config.AccessControl.RulesFile != "" {
+		err := utils.WatchFile(config.AccessControl.RulesFile, "access control rules", func() error {
+			rules, errs := configuration.ReloadAccessControlRules(config.AccessControl)
+			if len(errs) != 0 {
+				return joinErrors(errs)
+			}
+
+			accessControl := config.AccessControl
+			accessControl.Rules = rules
+			authorizer.SetRules(accessControl)
+
+			return nil
+		})
+		if err != nil {
+			logger.Errorf("Unable to watch the access control rules, they won't be reloaded when they change: %v", err)
+		}
+	}
+
+	if oidcConfig := config.IdentityProviders.OIDC; oidcConfig != nil && oidcConfig.ClientsFile != "" {
+		err := utils.WatchFile(oidcConfig.ClientsFile, "OpenID Connect clients", func() error {
+			clients, errs := configuration.ReloadOpenIDConnectClients(*oidcConfig)
+			if len(errs) != 0 {
+				return joinErrors(errs)
+			}
+
+			oidcProvider.Store.SetClients(clients)
+
+			return nil
+		})
+		if err != nil {
+			logger.Errorf("Unable to watch the OpenID Connect clients, they won't be reloaded when they change: %v", err)
+		}
+	}
+}
+
+// watchConfiguration reloads the configuration on SIGHUP and when the configuration file changes, the access control
+// rules, the OpenID Connect clients and the notifier are applied to the running providers.
+func watchConfiguration(sources *configuration.Sources, config *schema.Configuration, authorizer *authorization.Authorizer,
+	oidcProvider oidc.OpenIDConnectProvider, notifier *notification.ReloadableNotifier, certPool *utils.X509CertPool) {
+	reloader := configuration.NewReloader(sources, config)
+
+	reloader.Handle(configuration.SectionAccessControl, func(reloaded *schema.Configuration) error {
+		authorizer.SetRules(reloaded.AccessControl)
+
+		return nil
+	})
+
+	if oidcProvider.Store != nil {
+		reloader.Handle(configuration.SectionOpenIDConnectClients, func(reloaded *schema.Configuration) error {
+			if reloaded.IdentityProviders.OIDC == nil {
+				return errors.New("OpenID Connect can't be disabled without a restart")
+			}
+
+			oidcProvider.Store.SetClients(reloaded.IdentityProviders.OIDC.Clients)
+
+			return nil
+		})
+	}
+
+	reloader.Handle(configuration.SectionNotifier, func(reloaded *schema.Configuration) error {
+		reloadedNotifier, err := notification.NewNotifier(*reloaded.Notifier, certPool)
+		if err != nil {
+			return err
+		}
+
+		if !reloaded.Notifier.DisableStartupCheck {
+			if _, err = reloadedNotifier.StartupCheck(); err != nil {
+				return err
+			}
+		}
+
+		notifier.Swap(reloadedNotifier)
+
+		return nil
+	})
+
+	if err := reloader.Watch(); err != nil {
+		logging.Logger().Errorf("Unable to watch the configuration, it won't be reloaded when it changes: %v", err)
+	}
+}
+
+func joinErrors(errs []error) error {
+	messages := make([]string, len(errs))
+
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+
+	return errors.New(strings.Join(messages, ", "))
+}
+
+func runServer() {
+	if err := daemon.Run(serviceName, startServer); err != nil {
+		logging.Logger().Fatalf("Unable to run the service: %v", err)
+	}
+}
+
 func main() {
 	logger := logging.Logger()
 
@@ -160,7 +514,7 @@ func main() {
 	rootCmd := &cobra.Command{
 		Use: "authelia",
 		Run: func(cmd *cobra.Command, args []string) {
-			startServer()
+			runServer()
 		},
 		Version: version,
 		Short:   fmt.Sprintf("authelia %s", version),
@@ -168,6 +522,10 @@ func main() {
 	}
 
 	rootCmd.Flags().StringVar(&configPathFlag, "config", "", "Configuration file")
+	rootCmd.Flags().BoolVar(&strictStartupFlag, "strict-startup", false, "Fail to start when any startup check fails, not only the required ones")
+	rootCmd.PersistentFlags().BoolVar(&dryRunFlag, "dry-run", false, "Print the changes which would be made to the storage without applying them")
+
+	configuration.RegisterRemoteSourcesFlags(rootCmd.PersistentFlags())
 
 	buildCmd := &cobra.Command{
 		Use:   "build",
@@ -178,9 +536,33 @@ func main() {
 		},
 	}
 
+	standaloneCmd := &cobra.Command{
+		Use:   "standalone",
+		Short: "Start Authelia with a configuration generated in a data directory on the first start",
+		Long: `Start Authelia with a configuration generated in a data directory on the first start.
+
+The first start generates the secrets and a configuration storing the data in a SQLite database and reading the
+users from a users database in the data directory, it also creates an admin user whose password is logged once.
+The configuration is a regular configuration which can be edited and isn't changed by the next starts.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			path, err := standalone.Initialize(standaloneDataDirFlag, standaloneDomainFlag)
+			if err != nil {
+				logger.Fatalf("Unable to initialize the data directory: %v", err)
+			}
+
+			configPathFlag = path
+
+			runServer()
+		},
+	}
+
+	standaloneCmd.Flags().StringVar(&standaloneDataDirFlag, "data-dir", defaultStandaloneDataDirectory, "Directory of the configuration and the data")
+	standaloneCmd.Flags().StringVar(&standaloneDomainFlag, "domain", "", "Domain protected by Authelia, required on the first start")
+	standaloneCmd.Flags().BoolVar(&strictStartupFlag, "strict-startup", false, "Fail to start when any startup check fails, not only the required ones")
+
 	rootCmd.AddCommand(buildCmd, commands.HashPasswordCmd,
 		commands.ValidateConfigCmd, commands.CertificatesCmd,
-		commands.RSACmd)
+		commands.RSACmd, commands.OIDCCmd, commands.UserCmd, commands.AdminTokenCmd, commands.BreakGlassCmd, commands.BackupCmd, commands.NotifierCmd, commands.StorageCmd, commands.AccessReviewCmd, standaloneCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		logger.Fatal(err)
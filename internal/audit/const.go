@@ -0,0 +1,12 @@
+package audit
+
+const (
+	sinkFile    = "file"
+	sinkSyslog  = "syslog"
+	sinkWebhook = "webhook"
+)
+
+// headerSignature is the header of the webhook requests holding the HMAC-SHA256 of the body keyed with the secret.
+const headerSignature = "X-Authelia-Signature"
+
+const signaturePrefix = "sha256="
@@ -0,0 +1,171 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/events"
+	"github.com/authelia/authelia/internal/logging"
+)
+
+// NewLogger creates a Logger writing the records to the configured sinks, the personal data of the records is minimized
+// by the minimizer when it's not nil.
+func NewLogger(configuration *schema.AuditConfiguration, minimizer *logging.Minimizer) (*Logger, error) {
+	var sinks []Sink
+
+	if configuration.File != nil {
+		sink, err := NewFileSink(configuration.File.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		sinks = append(sinks, sink)
+	}
+
+	if configuration.Syslog != nil {
+		sink, err := NewSyslogSink(configuration.Syslog)
+		if err != nil {
+			closeSinks(sinks)
+			return nil, err
+		}
+
+		sinks = append(sinks, sink)
+	}
+
+	if configuration.Webhook != nil {
+		sink, err := NewWebhookSink(configuration.Webhook)
+		if err != nil {
+			closeSinks(sinks)
+			return nil, err
+		}
+
+		sinks = append(sinks, sink)
+	}
+
+	logger := NewLoggerWithSinks(configuration.BufferSize, sinks...)
+	logger.minimizer = minimizer
+
+	return logger, nil
+}
+
+// NewLoggerWithSinks creates a Logger writing the records to the given sinks, buffering up to size records.
+func NewLoggerWithSinks(size int, sinks ...Sink) *Logger {
+	return &Logger{
+		sinks:   sinks,
+		records: make(chan Record, size),
+	}
+}
+
+// Start starts writing the records to the sinks in the background.
+func (l *Logger) Start() {
+	l.wg.Add(1)
+
+	go func() {
+		defer l.wg.Done()
+
+		for record := range l.records {
+			l.write(record)
+		}
+	}()
+}
+
+// Close writes the buffered records and closes the sinks, the records of the events published afterwards are lost.
+func (l *Logger) Close() {
+	l.closeOnce.Do(func() {
+		close(l.records)
+		l.wg.Wait()
+		closeSinks(l.sinks)
+	})
+}
+
+// Subscriber returns the subscriber recording the events. The record of an event is dropped when the buffer is full
+// rather than blocking the request publishing it.
+func (l *Logger) Subscriber() events.Subscriber {
+	return func(event events.Event) {
+		select {
+		case l.records <- l.minimize(NewRecord(event)):
+		default:
+			logging.Logger().Errorf("Unable to record the %s event of user %s in the audit log: the buffer is full", event.Type, event.Username)
+		}
+	}
+}
+
+// minimize minimizes the usernames and IP address of a record. The actors which aren't users, e.g. the admin API
+// tokens, are described by several words and kept.
+func (l *Logger) minimize(record Record) Record {
+	if l.minimizer == nil {
+		return record
+	}
+
+	if !strings.ContainsRune(record.Actor, ' ') {
+		record.Actor = l.minimizer.Username(record.Actor)
+	}
+
+	record.Username = l.minimizer.Username(record.Username)
+	record.RemoteIP = l.minimizer.IP(record.RemoteIP)
+
+	if requestedBy, ok := record.Details[events.DetailRequestedBy]; ok && !strings.ContainsRune(requestedBy, ' ') {
+		record.Details[events.DetailRequestedBy] = l.minimizer.Username(requestedBy)
+	}
+
+	return record
+}
+
+func (l *Logger) write(record Record) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		logging.Logger().Errorf("Unable to encode the audit record of the %s event: %v", record.Event, err)
+		return
+	}
+
+	for _, sink := range l.sinks {
+		if err := sink.Write(data); err != nil {
+			logging.Logger().Errorf("Unable to write the audit record of the %s event to the %s sink: %v", record.Event, sink.Name(), err)
+		}
+	}
+}
+
+// NewRecord creates the audit record of the event. The details only useful to the notifications, the email address
+// and URLs, are left out.
+func NewRecord(event events.Event) Record {
+	record := Record{
+		Time:      event.Time.UTC(),
+		Event:     string(event.Type),
+		Actor:     event.Username,
+		Username:  event.Username,
+		RemoteIP:  event.RemoteIP,
+		UserAgent: event.UserAgent,
+		RequestID: event.RequestID,
+	}
+
+	for key, value := range event.Details {
+		switch key {
+		case events.DetailActor:
+			record.Actor = value
+		case events.DetailEmail, events.DetailResetPasswordURL:
+			continue
+		default:
+			if record.Details == nil {
+				record.Details = map[string]string{}
+			}
+
+			record.Details[key] = value
+		}
+	}
+
+	return record
+}
+
+func closeSinks(sinks []Sink) {
+	for _, sink := range sinks {
+		if err := sink.Close(); err != nil {
+			logging.Logger().Errorf("Unable to close the %s audit sink: %v", sink.Name(), err)
+		}
+	}
+}
+
+func sinkError(sink string, err error) error {
+	return fmt.Errorf("unable to create the %s audit sink: %w", sink, err)
+}
@@ -0,0 +1,183 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/events"
+	"github.com/authelia/authelia/internal/logging"
+)
+
+type memorySink struct {
+	mutex   sync.Mutex
+	records []string
+	err     error
+	closed  bool
+}
+
+func (s *memorySink) Name() string {
+	return "memory"
+}
+
+func (s *memorySink) Write(record []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.records = append(s.records, string(record))
+
+	return s.err
+}
+
+func (s *memorySink) Close() error {
+	s.closed = true
+
+	return nil
+}
+
+func TestShouldCreateRecordFromEvent(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	record := NewRecord(events.Event{
+		Type:      events.SessionsRevoked,
+		Time:      now,
+		Username:  "john",
+		RemoteIP:  "192.168.1.10",
+		UserAgent: "curl/7.68.0",
+		RequestID: "abc-123",
+		Details: map[string]string{
+			events.DetailActor: "harry",
+			events.DetailEmail: "john@example.com",
+		},
+	})
+
+	assert.Equal(t, Record{
+		Time:      now,
+		Event:     "sessions_revoked",
+		Actor:     "harry",
+		Username:  "john",
+		RemoteIP:  "192.168.1.10",
+		UserAgent: "curl/7.68.0",
+		RequestID: "abc-123",
+	}, record)
+
+	record = NewRecord(events.Event{
+		Type:     events.LoginFailed,
+		Time:     now,
+		Username: "john",
+		Details:  map[string]string{events.DetailAuthenticationType: "1FA"},
+	})
+
+	assert.Equal(t, "john", record.Actor)
+	assert.Equal(t, map[string]string{events.DetailAuthenticationType: "1FA"}, record.Details)
+}
+
+func TestShouldWriteRecordsToAllSinks(t *testing.T) {
+	failing := &memorySink{err: errors.New("unreachable")}
+	sink := &memorySink{}
+
+	logger := NewLoggerWithSinks(10, failing, sink)
+	logger.Start()
+
+	bus := events.NewBus()
+	bus.Subscribe(logger.Subscriber())
+
+	bus.Publish(events.Event{Type: events.LoginSucceeded, Time: time.Unix(1622548800, 0), Username: "john", RequestID: "abc-123"})
+	bus.Publish(events.Event{Type: events.PasswordReset, Time: time.Unix(1622548801, 0), Username: "john"})
+
+	logger.Close()
+
+	assert.Len(t, failing.records, 2)
+	require.Len(t, sink.records, 2)
+	assert.Equal(t, `{"time":"2021-06-01T12:00:00Z","event":"login_succeeded","actor":"john","username":"john","request_id":"abc-123"}`, sink.records[0])
+	assert.Equal(t, `{"time":"2021-06-01T12:00:01Z","event":"password_reset","actor":"john","username":"john"}`, sink.records[1])
+	assert.True(t, sink.closed)
+}
+
+func TestShouldMinimizeRecords(t *testing.T) {
+	sink := &memorySink{}
+
+	logger := NewLoggerWithSinks(10, sink)
+	logger.minimizer = logging.NewMinimizer(&schema.LogMinimizationConfiguration{
+		Usernames:   "truncate",
+		IPAddresses: "truncate",
+		Emails:      "none",
+	})
+	logger.Start()
+
+	subscriber := logger.Subscriber()
+	subscriber(events.Event{Type: events.LoginSucceeded, Time: time.Unix(1622548800, 0), Username: "john", RemoteIP: "192.168.1.20"})
+	subscriber(events.Event{Type: events.SecondFactorResetRequested, Time: time.Unix(1622548801, 0), Username: "john", Details: map[string]string{
+		events.DetailActor:       "admin API token 1a2b3c",
+		events.DetailRequestedBy: "harry",
+	}})
+
+	logger.Close()
+
+	require.Len(t, sink.records, 2)
+	assert.Equal(t, `{"time":"2021-06-01T12:00:00Z","event":"login_succeeded","actor":"jo***","username":"jo***","remote_ip":"192.168.1.0"}`, sink.records[0])
+	assert.Equal(t, `{"time":"2021-06-01T12:00:01Z","event":"second_factor_reset_requested","actor":"admin API token 1a2b3c","username":"jo***","details":{"requested_by":"ha***"}}`, sink.records[1])
+}
+
+func TestShouldDropRecordsWhenBufferIsFull(t *testing.T) {
+	sink := &memorySink{}
+	logger := NewLoggerWithSinks(1, sink)
+	subscriber := logger.Subscriber()
+
+	// The logger isn't started so the second record doesn't fit in the buffer.
+	subscriber(events.Event{Type: events.LoginSucceeded, Username: "john"})
+	subscriber(events.Event{Type: events.LoginFailed, Username: "john"})
+
+	logger.Start()
+	logger.Close()
+
+	require.Len(t, sink.records, 1)
+	assert.Contains(t, sink.records[0], `"event":"login_succeeded"`)
+}
+
+func TestShouldAppendRecordsToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	logger, err := NewLogger(&schema.AuditConfiguration{
+		BufferSize: 10,
+		File:       &schema.AuditFileConfiguration{Path: path},
+	}, nil)
+	require.NoError(t, err)
+
+	logger.Start()
+	logger.Subscriber()(events.Event{Type: events.ConsentGranted, Username: "john", Details: map[string]string{
+		events.DetailClientID: "grafana",
+		events.DetailScopes:   "openid profile",
+	}})
+	logger.Close()
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	require.Len(t, lines, 1)
+
+	var record Record
+
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	assert.Equal(t, "consent_granted", record.Event)
+	assert.Equal(t, "grafana", record.Details[events.DetailClientID])
+}
+
+func TestShouldFailToCreateFileSinkInMissingDirectory(t *testing.T) {
+	_, err := NewLogger(&schema.AuditConfiguration{
+		File: &schema.AuditFileConfiguration{Path: filepath.Join(t.TempDir(), "missing", "audit.log")},
+	}, nil)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to create the file audit sink: ")
+}
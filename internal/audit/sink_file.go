@@ -0,0 +1,45 @@
+package audit
+
+import (
+	"os"
+	"sync"
+)
+
+// FileSink appends the records to a file, one JSON object per line.
+type FileSink struct {
+	mutex sync.Mutex
+	file  *os.File
+}
+
+// NewFileSink creates a FileSink appending to the file at the given path, the file is created if it doesn't exist.
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, sinkError(sinkFile, err)
+	}
+
+	return &FileSink{file: file}, nil
+}
+
+// Name returns the name of the sink.
+func (s *FileSink) Name() string {
+	return sinkFile
+}
+
+// Write appends the record to the file followed by a line feed.
+func (s *FileSink) Write(record []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err := s.file.Write(append(record, '\n'))
+
+	return err
+}
+
+// Close closes the file.
+func (s *FileSink) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.file.Close()
+}
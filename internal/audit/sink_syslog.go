@@ -0,0 +1,40 @@
+//go:build !windows
+// +build !windows
+
+package audit
+
+import (
+	"log/syslog"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+// SyslogSink sends the records to a syslog server with the auth facility, the local one when no network is configured.
+type SyslogSink struct {
+	writer *syslog.Writer
+}
+
+// NewSyslogSink creates a SyslogSink connected to the configured syslog server.
+func NewSyslogSink(configuration *schema.AuditSyslogConfiguration) (*SyslogSink, error) {
+	writer, err := syslog.Dial(configuration.Network, configuration.Address, syslog.LOG_INFO|syslog.LOG_AUTH, configuration.Tag)
+	if err != nil {
+		return nil, sinkError(sinkSyslog, err)
+	}
+
+	return &SyslogSink{writer: writer}, nil
+}
+
+// Name returns the name of the sink.
+func (s *SyslogSink) Name() string {
+	return sinkSyslog
+}
+
+// Write sends the record as a message with the informational severity.
+func (s *SyslogSink) Write(record []byte) error {
+	return s.writer.Info(string(record))
+}
+
+// Close closes the connection to the syslog server.
+func (s *SyslogSink) Close() error {
+	return s.writer.Close()
+}
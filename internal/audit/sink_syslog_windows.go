@@ -0,0 +1,33 @@
+//go:build windows
+// +build windows
+
+package audit
+
+import (
+	"errors"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+// SyslogSink isn't supported on Windows.
+type SyslogSink struct{}
+
+// NewSyslogSink returns an error as syslog isn't supported on Windows.
+func NewSyslogSink(_ *schema.AuditSyslogConfiguration) (*SyslogSink, error) {
+	return nil, sinkError(sinkSyslog, errors.New("syslog isn't supported on windows"))
+}
+
+// Name returns the name of the sink.
+func (s *SyslogSink) Name() string {
+	return sinkSyslog
+}
+
+// Write does nothing.
+func (s *SyslogSink) Write(_ []byte) error {
+	return nil
+}
+
+// Close does nothing.
+func (s *SyslogSink) Close() error {
+	return nil
+}
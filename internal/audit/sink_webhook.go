@@ -0,0 +1,87 @@
+package audit
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// WebhookSink posts each record as a JSON body to a URL. When a secret is configured the body is signed with
+// HMAC-SHA256 in the X-Authelia-Signature header so the receiver can authenticate the records.
+type WebhookSink struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+// NewWebhookSink creates a WebhookSink posting to the configured URL.
+func NewWebhookSink(configuration *schema.AuditWebhookConfiguration) (*WebhookSink, error) {
+	timeout, err := utils.ParseDurationString(configuration.Timeout)
+	if err != nil {
+		return nil, sinkError(sinkWebhook, err)
+	}
+
+	return &WebhookSink{
+		url:    configuration.URL,
+		secret: []byte(configuration.Secret),
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// Name returns the name of the sink.
+func (s *WebhookSink) Name() string {
+	return sinkWebhook
+}
+
+// Write posts the record and expects a 2xx response.
+func (s *WebhookSink) Write(record []byte) error {
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(record))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if len(s.secret) != 0 {
+		req.Header.Set(headerSignature, signaturePrefix+Sign(s.secret, record))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	// Drain the body so the connection can be reused.
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("the webhook responded with the status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Close closes the idle connections to the webhook.
+func (s *WebhookSink) Close() error {
+	s.client.CloseIdleConnections()
+
+	return nil
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the body keyed with the secret.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,71 @@
+package audit
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+func TestShouldPostSignedRecordToWebhook(t *testing.T) {
+	var (
+		body      []byte
+		signature string
+		mediaType string
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = ioutil.ReadAll(r.Body)
+		signature = r.Header.Get("X-Authelia-Signature")
+		mediaType = r.Header.Get("Content-Type")
+	}))
+	defer server.Close()
+
+	sink, err := NewWebhookSink(&schema.AuditWebhookConfiguration{URL: server.URL, Secret: "secret", Timeout: "1s"})
+	require.NoError(t, err)
+
+	defer sink.Close()
+
+	record := []byte(`{"event":"login_succeeded"}`)
+
+	require.NoError(t, sink.Write(record))
+	assert.Equal(t, record, body)
+	assert.Equal(t, "application/json", mediaType)
+	assert.Equal(t, "sha256="+Sign([]byte("secret"), record), signature)
+}
+
+func TestShouldNotSignRecordWithoutSecret(t *testing.T) {
+	signed := true
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, signed = r.Header["X-Authelia-Signature"]
+	}))
+	defer server.Close()
+
+	sink, err := NewWebhookSink(&schema.AuditWebhookConfiguration{URL: server.URL, Timeout: "1s"})
+	require.NoError(t, err)
+
+	require.NoError(t, sink.Write([]byte(`{}`)))
+	assert.False(t, signed)
+}
+
+func TestShouldReturnErrorWhenWebhookFails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	sink, err := NewWebhookSink(&schema.AuditWebhookConfiguration{URL: server.URL, Timeout: "1s"})
+	require.NoError(t, err)
+
+	assert.EqualError(t, sink.Write([]byte(`{}`)), "the webhook responded with the status code 503")
+}
+
+func TestShouldSignBody(t *testing.T) {
+	assert.Equal(t, "f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8", Sign([]byte("key"), []byte("The quick brown fox jumps over the lazy dog")))
+}
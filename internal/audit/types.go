@@ -0,0 +1,49 @@
+package audit
+
+import (
+	"sync"
+	"time"
+
+	"github.com/authelia/authelia/internal/logging"
+)
+
+// Record is the structured record of a security relevant event, it's written to the sinks as a JSON object.
+type Record struct {
+	Time  time.Time `json:"time"`
+	Event string    `json:"event"`
+
+	// Actor is the user or admin API token which made the change, Username is the user concerned by the event. They
+	// only differ for the administrative operations, e.g. when a user is purged by an administrator.
+	Actor    string `json:"actor"`
+	Username string `json:"username"`
+
+	RemoteIP  string `json:"remote_ip,omitempty"`
+	UserAgent string `json:"user_agent,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// Sink writes the audit records encoded as JSON to a destination such as a file.
+type Sink interface {
+	// Name returns the name of the sink used in the logs.
+	Name() string
+
+	// Write writes the record encoded as a JSON object without a trailing line feed.
+	Write(record []byte) error
+
+	// Close releases the resources of the sink.
+	Close() error
+}
+
+// Logger records the events published on the event bus to the sinks. The records are written in the background so
+// the slow sinks, like the webhooks, don't slow down the requests publishing the events.
+type Logger struct {
+	sinks   []Sink
+	records chan Record
+
+	minimizer *logging.Minimizer
+
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
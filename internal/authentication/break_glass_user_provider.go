@@ -0,0 +1,77 @@
+package authentication
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+// ErrBreakGlassAccount indicates the operation isn't permitted to the break-glass accounts.
+var ErrBreakGlassAccount = errors.New("the operation isn't permitted to the break-glass accounts")
+
+// BreakGlassUserProvider is a UserProvider checking the break-glass accounts of the configuration before the wrapped
+// provider, so the operators can still log in while the authentication backend is unreachable.
+type BreakGlassUserProvider struct {
+	UserProvider
+
+	accounts map[string]schema.BreakGlassAccountConfiguration
+}
+
+// NewBreakGlassUserProvider creates a new instance of BreakGlassUserProvider wrapping the provider.
+func NewBreakGlassUserProvider(provider UserProvider, configuration *schema.BreakGlassConfiguration) *BreakGlassUserProvider {
+	accounts := make(map[string]schema.BreakGlassAccountConfiguration, len(configuration.Accounts))
+
+	for _, account := range configuration.Accounts {
+		accounts[strings.ToLower(account.Username)] = account
+	}
+
+	return &BreakGlassUserProvider{UserProvider: provider, accounts: accounts}
+}
+
+// IsBreakGlassAccount returns true if the username is the one of a break-glass account.
+func (p *BreakGlassUserProvider) IsBreakGlassAccount(username string) bool {
+	_, ok := p.accounts[strings.ToLower(username)]
+
+	return ok
+}
+
+// CheckUserPassword checks the password of the break-glass accounts locally and the password of the other users
+// against the wrapped provider.
+func (p *BreakGlassUserProvider) CheckUserPassword(username string, password string) (bool, error) {
+	if account, ok := p.accounts[strings.ToLower(username)]; ok {
+		return CheckPassword(password, account.Password)
+	}
+
+	return p.UserProvider.CheckUserPassword(username, password)
+}
+
+// GetDetails retrieve the details of the break-glass accounts from the configuration and the details of the other
+// users from the wrapped provider.
+func (p *BreakGlassUserProvider) GetDetails(username string) (*UserDetails, error) {
+	if account, ok := p.accounts[strings.ToLower(username)]; ok {
+		details := &UserDetails{
+			Username:    account.Username,
+			DisplayName: account.DisplayName,
+			Groups:      account.Groups,
+		}
+
+		if account.Email != "" {
+			details.Emails = []string{account.Email}
+		}
+
+		return details, nil
+	}
+
+	return p.UserProvider.GetDetails(username)
+}
+
+// UpdatePassword updates the password of the users of the wrapped provider, the password of the break-glass accounts
+// can only be changed in the configuration.
+func (p *BreakGlassUserProvider) UpdatePassword(username string, newPassword string) error {
+	if p.IsBreakGlassAccount(username) {
+		return ErrBreakGlassAccount
+	}
+
+	return p.UserProvider.UpdatePassword(username, newPassword)
+}
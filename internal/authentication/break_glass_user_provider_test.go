@@ -0,0 +1,75 @@
+package authentication
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+var testBreakGlassConfiguration = &schema.BreakGlassConfiguration{
+	Window: "4h",
+	Accounts: []schema.BreakGlassAccountConfiguration{
+		{
+			Username:    "emergency",
+			Password:    "$argon2id$v=19$m=65536,t=3,p=2$BpLnfgDsc2WD8F2q$o/vzA4myCqZZ36bUGsDY//8mKUYNZZaR0t4MFFSs+iM",
+			DisplayName: "Emergency Access",
+			Email:       "ops@authelia.com",
+			Groups:      []string{"admins"},
+		},
+	},
+}
+
+func TestShouldCheckBreakGlassAccountsLocally(t *testing.T) {
+	WithDatabase(UserDatabaseContent, func(path string) {
+		config := DefaultFileAuthenticationBackendConfiguration
+		config.Path = path
+		provider := NewBreakGlassUserProvider(NewFileUserProvider(&config), testBreakGlassConfiguration)
+
+		assert.True(t, provider.IsBreakGlassAccount("Emergency"))
+		assert.False(t, provider.IsBreakGlassAccount("john"))
+
+		ok, err := provider.CheckUserPassword("emergency", "password")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = provider.CheckUserPassword("emergency", "wrong_password")
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		ok, err = provider.CheckUserPassword("john", "password")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+}
+
+func TestShouldGetBreakGlassAccountDetails(t *testing.T) {
+	WithDatabase(UserDatabaseContent, func(path string) {
+		config := DefaultFileAuthenticationBackendConfiguration
+		config.Path = path
+		provider := NewBreakGlassUserProvider(NewFileUserProvider(&config), testBreakGlassConfiguration)
+
+		details, err := provider.GetDetails("EMERGENCY")
+		require.NoError(t, err)
+		assert.Equal(t, "emergency", details.Username)
+		assert.Equal(t, "Emergency Access", details.DisplayName)
+		assert.Equal(t, []string{"ops@authelia.com"}, details.Emails)
+		assert.Equal(t, []string{"admins"}, details.Groups)
+
+		details, err = provider.GetDetails("john")
+		require.NoError(t, err)
+		assert.Equal(t, "John Doe", details.DisplayName)
+	})
+}
+
+func TestShouldNotUpdateBreakGlassAccountPassword(t *testing.T) {
+	WithDatabase(UserDatabaseContent, func(path string) {
+		config := DefaultFileAuthenticationBackendConfiguration
+		config.Path = path
+		provider := NewBreakGlassUserProvider(NewFileUserProvider(&config), testBreakGlassConfiguration)
+
+		assert.Equal(t, ErrBreakGlassAccount, provider.UpdatePassword("emergency", "newpassword"))
+	})
+}
@@ -2,6 +2,8 @@ package authentication
 
 import (
 	"errors"
+	"math"
+	"time"
 )
 
 // Level is the type representing a level of authentication.
@@ -23,6 +25,8 @@ const (
 	U2F = "u2f"
 	// Push Method using Duo application to receive push notifications.
 	Push = "mobile_push"
+	// Webauthn Method using WebAuthn (FIDO2) authenticators like security keys or platform authenticators.
+	Webauthn = "webauthn"
 )
 
 const (
@@ -30,8 +34,40 @@ const (
 	ldapOIDPasswdModifyExtension    = "1.3.6.1.4.1.4203.1.11.1" // http://oidref.com/1.3.6.1.4.1.4203.1.11.1
 )
 
+// The attributes of the Active Directory accounts and of the policy of the domain.
+// https://docs.microsoft.com/en-us/windows/win32/adschema/attributes-all
+const (
+	adUserAccountControlAttribute = "userAccountControl"
+	adPasswordLastSetAttribute    = "pwdLastSet"
+	adLockoutTimeAttribute        = "lockoutTime"
+	adMaxPasswordAgeAttribute     = "maxPwdAge"
+	adLockoutDurationAttribute    = "lockoutDuration"
+)
+
+// The flags of the userAccountControl attribute of the Active Directory accounts.
+// https://docs.microsoft.com/en-us/troubleshoot/windows-server/identity/useraccountcontrol-manipulate-account-properties
+const (
+	adAccountDisable     = 0x2
+	adDontExpirePassword = 0x10000
+)
+
+// The times of Active Directory are 100-nanosecond intervals since January 1, 1601 and the durations of the policy of
+// the domain are negative intervals.
+const (
+	adFileTimeUnit      = 100 * time.Nanosecond
+	adFileTimeUnixEpoch = 116444736000000000
+	adIntervalNever     = math.MinInt64
+)
+
+// The data codes of the diagnostic messages of the binds failed because the password is wrong or the user doesn't
+// exist, the other codes detail the state of the account.
+const (
+	adBindInvalidCredentials = "52e"
+	adBindNoSuchUser         = "525"
+)
+
 // PossibleMethods is the set of all possible 2FA methods.
-var PossibleMethods = []string{TOTP, U2F, Push}
+var PossibleMethods = []string{TOTP, U2F, Push, Webauthn}
 
 // CryptAlgo the crypt representation of an algorithm used in the prefix of the hash.
 type CryptAlgo string
@@ -59,6 +95,15 @@ var HashingPossibleSaltCharacters = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJ
 // ErrUserNotFound indicates the user wasn't found in the authentication backend.
 var ErrUserNotFound = errors.New("user not found")
 
+// ErrPasswordExpired indicates the password of the user expired or must be changed before they can log in.
+var ErrPasswordExpired = errors.New("password expired")
+
+// ErrAccountDisabled indicates the account of the user was disabled in the authentication backend.
+var ErrAccountDisabled = errors.New("account disabled")
+
+// ErrAccountLocked indicates the account of the user is locked out after too many failed attempts.
+var ErrAccountLocked = errors.New("account locked")
+
 const argon2id = "argon2id"
 const sha512 = "sha512"
 
@@ -140,9 +140,36 @@ func readDatabase(path string) (*DatabaseModel, error) {
 	return &db, nil
 }
 
+// Reload reads the database file again. The current database is kept when the file can't be read.
+func (p *FileUserProvider) Reload() error {
+	database, err := readDatabase(p.configuration.Path)
+	if err != nil {
+		return err
+	}
+
+	if err = checkPasswordHashes(database); err != nil {
+		return err
+	}
+
+	p.lock.Lock()
+	p.database = database
+	p.lock.Unlock()
+
+	return nil
+}
+
+func (p *FileUserProvider) getUser(username string) (details UserDetailsModel, ok bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	details, ok = p.database.Users[username]
+
+	return details, ok
+}
+
 // CheckUserPassword checks if provided password matches for the given user.
 func (p *FileUserProvider) CheckUserPassword(username string, password string) (bool, error) {
-	if details, ok := p.database.Users[username]; ok {
+	if details, ok := p.getUser(username); ok {
 		ok, err := CheckPassword(password, details.HashedPassword)
 		if err != nil {
 			return false, err
@@ -156,7 +183,7 @@ func (p *FileUserProvider) CheckUserPassword(username string, password string) (
 
 // GetDetails retrieve the groups a user belongs to.
 func (p *FileUserProvider) GetDetails(username string) (*UserDetails, error) {
-	if details, ok := p.database.Users[username]; ok {
+	if details, ok := p.getUser(username); ok {
 		return &UserDetails{
 			Username:    username,
 			DisplayName: details.DisplayName,
@@ -170,7 +197,7 @@ func (p *FileUserProvider) GetDetails(username string) (*UserDetails, error) {
 
 // UpdatePassword update the password of the given user.
 func (p *FileUserProvider) UpdatePassword(username string, newPassword string) error {
-	details, ok := p.database.Users[username]
+	details, ok := p.getUser(username)
 	if !ok {
 		return ErrUserNotFound
 	}
@@ -149,6 +149,37 @@ func TestShouldUpdatePassword(t *testing.T) {
 	})
 }
 
+func TestShouldReloadDatabase(t *testing.T) {
+	WithDatabase(UserDatabaseContent, func(path string) {
+		config := DefaultFileAuthenticationBackendConfiguration
+		config.Path = path
+		provider := NewFileUserProvider(&config)
+
+		content := strings.Replace(string(UserDatabaseContent), "john.doe@authelia.com", "john.new@authelia.com", 1)
+		require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+		require.NoError(t, provider.Reload())
+
+		details, err := provider.GetDetails("john")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"john.new@authelia.com"}, details.Emails)
+	})
+}
+
+func TestShouldKeepDatabaseWhenReloadFails(t *testing.T) {
+	WithDatabase(UserDatabaseContent, func(path string) {
+		config := DefaultFileAuthenticationBackendConfiguration
+		config.Path = path
+		provider := NewFileUserProvider(&config)
+
+		require.NoError(t, ioutil.WriteFile(path, []byte("users: ["), 0600))
+		assert.Error(t, provider.Reload())
+
+		details, err := provider.GetDetails("john")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"john.doe@authelia.com"}, details.Emails)
+	})
+}
+
 // Checks both that the hashing algo changes and that it removes {CRYPT} from the start.
 func TestShouldUpdatePasswordHashingAlgorithmToArgon2id(t *testing.T) {
 	WithDatabase(UserDatabaseContent, func(path string) {
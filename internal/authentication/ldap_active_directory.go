@@ -0,0 +1,107 @@
+package authentication
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// adBindDataRegexp matches the data code of the diagnostic messages of the failed binds of Active Directory such as
+// `80090308: LdapErr: DSID-0C09044E, comment: AcceptSecurityContext error, data 532, v4563`.
+var adBindDataRegexp = regexp.MustCompile(`data ([0-9a-fA-F]+),`)
+
+// activeDirectoryPolicy is the password and lockout policy of the Active Directory domain, a duration is 0 when it's
+// unlimited or unknown.
+type activeDirectoryPolicy struct {
+	maxPasswordAge  time.Duration
+	lockoutDuration time.Duration
+}
+
+// activeDirectoryAccount is the state of an Active Directory account given by its attributes.
+type activeDirectoryAccount struct {
+	userAccountControl int64
+	passwordLastSet    int64
+	lockoutTime        int64
+}
+
+func newActiveDirectoryAccount(entry *ldap.Entry) *activeDirectoryAccount {
+	return &activeDirectoryAccount{
+		userAccountControl: parseActiveDirectoryInteger(entry.GetAttributeValue(adUserAccountControlAttribute)),
+		passwordLastSet:    parseActiveDirectoryInteger(entry.GetAttributeValue(adPasswordLastSetAttribute)),
+		lockoutTime:        parseActiveDirectoryInteger(entry.GetAttributeValue(adLockoutTimeAttribute)),
+	}
+}
+
+func newActiveDirectoryPolicy(entry *ldap.Entry) activeDirectoryPolicy {
+	return activeDirectoryPolicy{
+		maxPasswordAge:  parseActiveDirectoryInterval(entry.GetAttributeValue(adMaxPasswordAgeAttribute)),
+		lockoutDuration: parseActiveDirectoryInterval(entry.GetAttributeValue(adLockoutDurationAttribute)),
+	}
+}
+
+// disabled returns true when the account was disabled by an administrator.
+func (a *activeDirectoryAccount) disabled() bool {
+	return a.userAccountControl&adAccountDisable != 0
+}
+
+// err returns the error preventing the user from logging in: the account is disabled, locked out or the password
+// expired. A password last set at 0 must be changed at the next logon.
+func (a *activeDirectoryAccount) err(policy activeDirectoryPolicy, now time.Time) error {
+	switch {
+	case a.disabled():
+		return ErrAccountDisabled
+	case a.lockoutTime != 0 && (policy.lockoutDuration == 0 || now.Before(activeDirectoryTime(a.lockoutTime).Add(policy.lockoutDuration))):
+		return ErrAccountLocked
+	case a.passwordLastSet == 0:
+		return ErrPasswordExpired
+	case a.userAccountControl&adDontExpirePassword == 0 && policy.maxPasswordAge != 0 &&
+		!now.Before(activeDirectoryTime(a.passwordLastSet).Add(policy.maxPasswordAge)):
+		return ErrPasswordExpired
+	default:
+		return nil
+	}
+}
+
+// isActiveDirectoryAccountBindFailure returns true when Active Directory details that the bind failed because of the
+// state of the account rather than a wrong password. Active Directory only details it to the users knowing their
+// password, except for the locked out accounts, so the state isn't disclosed to the others.
+func isActiveDirectoryAccountBindFailure(err error) bool {
+	matches := adBindDataRegexp.FindStringSubmatch(err.Error())
+	if matches == nil {
+		return false
+	}
+
+	switch matches[1] {
+	case adBindInvalidCredentials, adBindNoSuchUser:
+		return false
+	default:
+		return true
+	}
+}
+
+// activeDirectoryTime returns the time of an Active Directory time, the 100-nanosecond intervals since January 1, 1601.
+func activeDirectoryTime(fileTime int64) time.Time {
+	return time.Unix(0, 0).Add(time.Duration(fileTime-adFileTimeUnixEpoch) * adFileTimeUnit)
+}
+
+// parseActiveDirectoryInterval returns the duration of an interval of the policy of the domain which is stored as a
+// negative number of 100-nanosecond intervals, it's 0 when the interval is unlimited or can't be parsed.
+func parseActiveDirectoryInterval(value string) time.Duration {
+	interval := parseActiveDirectoryInteger(value)
+	if interval == adIntervalNever || interval >= 0 {
+		return 0
+	}
+
+	return time.Duration(-interval) * adFileTimeUnit
+}
+
+func parseActiveDirectoryInteger(value string) int64 {
+	integer, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return integer
+}
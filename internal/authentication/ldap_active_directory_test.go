@@ -0,0 +1,276 @@
+package authentication
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/unicode"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+func activeDirectoryFileTime(t time.Time) int64 {
+	return t.UnixNano()/int64(adFileTimeUnit) + adFileTimeUnixEpoch
+}
+
+func TestShouldInterpretActiveDirectoryAccountState(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	policy := activeDirectoryPolicy{maxPasswordAge: 42 * 24 * time.Hour, lockoutDuration: 30 * time.Minute}
+
+	testCases := []struct {
+		name     string
+		account  activeDirectoryAccount
+		policy   activeDirectoryPolicy
+		expected error
+	}{
+		{"ShouldAllowValidAccount", activeDirectoryAccount{userAccountControl: 0x200, passwordLastSet: activeDirectoryFileTime(now.Add(-time.Hour))}, policy, nil},
+		{"ShouldDetectDisabledAccount", activeDirectoryAccount{userAccountControl: 0x202, passwordLastSet: activeDirectoryFileTime(now.Add(-time.Hour))}, policy, ErrAccountDisabled},
+		{"ShouldDetectLockedOutAccount", activeDirectoryAccount{userAccountControl: 0x200, passwordLastSet: activeDirectoryFileTime(now.Add(-time.Hour)), lockoutTime: activeDirectoryFileTime(now.Add(-10 * time.Minute))}, policy, ErrAccountLocked},
+		{"ShouldIgnoreExpiredLockout", activeDirectoryAccount{userAccountControl: 0x200, passwordLastSet: activeDirectoryFileTime(now.Add(-time.Hour)), lockoutTime: activeDirectoryFileTime(now.Add(-time.Hour))}, policy, nil},
+		{"ShouldDetectLockoutUntilUnlocked", activeDirectoryAccount{userAccountControl: 0x200, passwordLastSet: activeDirectoryFileTime(now.Add(-time.Hour)), lockoutTime: activeDirectoryFileTime(now.Add(-time.Hour))}, activeDirectoryPolicy{}, ErrAccountLocked},
+		{"ShouldDetectPasswordToChange", activeDirectoryAccount{userAccountControl: 0x200}, policy, ErrPasswordExpired},
+		{"ShouldDetectExpiredPassword", activeDirectoryAccount{userAccountControl: 0x200, passwordLastSet: activeDirectoryFileTime(now.Add(-43 * 24 * time.Hour))}, policy, ErrPasswordExpired},
+		{"ShouldIgnoreAgeOfPasswordWhichDoesNotExpire", activeDirectoryAccount{userAccountControl: 0x10200, passwordLastSet: activeDirectoryFileTime(now.Add(-43 * 24 * time.Hour))}, policy, nil},
+		{"ShouldIgnoreAgeOfPasswordWithoutPolicy", activeDirectoryAccount{userAccountControl: 0x200, passwordLastSet: activeDirectoryFileTime(now.Add(-43 * 24 * time.Hour))}, activeDirectoryPolicy{}, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.account.err(tc.policy, now))
+		})
+	}
+}
+
+func TestShouldDetectActiveDirectoryAccountBindFailures(t *testing.T) {
+	bindError := func(data string) error {
+		return ldap.NewError(ldap.LDAPResultInvalidCredentials,
+			errors.New("80090308: LdapErr: DSID-0C09044E, comment: AcceptSecurityContext error, data "+data+", v4563"))
+	}
+
+	assert.False(t, isActiveDirectoryAccountBindFailure(bindError("52e")))
+	assert.False(t, isActiveDirectoryAccountBindFailure(bindError("525")))
+	assert.False(t, isActiveDirectoryAccountBindFailure(errors.New("Invalid Credentials")))
+
+	assert.True(t, isActiveDirectoryAccountBindFailure(bindError("532")))
+	assert.True(t, isActiveDirectoryAccountBindFailure(bindError("533")))
+	assert.True(t, isActiveDirectoryAccountBindFailure(bindError("773")))
+	assert.True(t, isActiveDirectoryAccountBindFailure(bindError("775")))
+}
+
+func TestShouldParseActiveDirectoryIntervals(t *testing.T) {
+	assert.Equal(t, 42*24*time.Hour, parseActiveDirectoryInterval("-36288000000000"))
+	assert.Equal(t, 30*time.Minute, parseActiveDirectoryInterval("-18000000000"))
+	assert.Equal(t, time.Duration(0), parseActiveDirectoryInterval("-9223372036854775808"))
+	assert.Equal(t, time.Duration(0), parseActiveDirectoryInterval("0"))
+	assert.Equal(t, time.Duration(0), parseActiveDirectoryInterval(""))
+
+	assert.Equal(t, time.Unix(1600000000, 0).UTC(), activeDirectoryTime(activeDirectoryFileTime(time.Unix(1600000000, 0))).UTC())
+}
+
+func newActiveDirectoryTestProvider(factory LDAPConnectionFactory) *LDAPUserProvider {
+	return newLDAPUserProvider(
+		schema.LDAPAuthenticationBackendConfiguration{
+			Implementation:       schema.LDAPImplementationActiveDirectory,
+			URL:                  "ldaps://127.0.0.1:636",
+			User:                 "cn=admin,dc=example,dc=com",
+			Password:             "password",
+			UsernameAttribute:    "sAMAccountName",
+			MailAttribute:        "mail",
+			DisplayNameAttribute: "displayName",
+			UsersFilter:          "(sAMAccountName={input})",
+			BaseDN:               "dc=example,dc=com",
+		},
+		nil,
+		factory)
+}
+
+func newActiveDirectoryUserSearchResult(userAccountControl, passwordLastSet int64) *ldap.SearchResult {
+	return &ldap.SearchResult{
+		Entries: []*ldap.Entry{
+			ldap.NewEntry("cn=john,dc=example,dc=com", map[string][]string{
+				"displayName":                 {"John Doe"},
+				"mail":                        {"john@example.com"},
+				"sAMAccountName":              {"john"},
+				adUserAccountControlAttribute: {strconv.FormatInt(userAccountControl, 10)},
+				adPasswordLastSetAttribute:    {strconv.FormatInt(passwordLastSet, 10)},
+				adLockoutTimeAttribute:        {"0"},
+			}),
+		},
+	}
+}
+
+func TestShouldReportExpiredActiveDirectoryPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := NewMockLDAPConnectionFactory(ctrl)
+	mockConn := NewMockLDAPConnection(ctrl)
+
+	ldapClient := newActiveDirectoryTestProvider(mockFactory)
+
+	clock := &utils.FakeClock{}
+	clock.Set(time.Unix(1600000000, 0))
+	ldapClient.clock = clock
+
+	gomock.InOrder(
+		mockFactory.EXPECT().
+			DialURL(gomock.Eq("ldaps://127.0.0.1:636"), gomock.Any()).
+			Return(mockConn, nil),
+		mockConn.EXPECT().
+			Bind(gomock.Eq("cn=admin,dc=example,dc=com"), gomock.Eq("password")).
+			Return(nil),
+		mockConn.EXPECT().
+			Search(NewExtendedSearchRequestMatcher("(objectClass=*)", "dc=example,dc=com", ldap.ScopeBaseObject, ldap.NeverDerefAliases, false, []string{adMaxPasswordAgeAttribute, adLockoutDurationAttribute})).
+			Return(&ldap.SearchResult{
+				Entries: []*ldap.Entry{
+					ldap.NewEntry("dc=example,dc=com", map[string][]string{
+						adMaxPasswordAgeAttribute:  {"-36288000000000"},
+						adLockoutDurationAttribute: {"-18000000000"},
+					}),
+				},
+			}, nil),
+		mockConn.EXPECT().
+			Search(NewExtendedSearchRequestMatcher("(objectClass=*)", "", ldap.ScopeBaseObject, ldap.NeverDerefAliases, false, []string{ldapSupportedExtensionAttribute})).
+			Return(&ldap.SearchResult{}, nil),
+	)
+
+	require.NoError(t, ldapClient.checkServer())
+	assert.Equal(t, activeDirectoryPolicy{maxPasswordAge: 42 * 24 * time.Hour, lockoutDuration: 30 * time.Minute}, ldapClient.activeDirectoryPolicy)
+
+	gomock.InOrder(
+		mockFactory.EXPECT().
+			DialURL(gomock.Eq("ldaps://127.0.0.1:636"), gomock.Any()).
+			Return(mockConn, nil),
+		mockConn.EXPECT().
+			Bind(gomock.Eq("cn=admin,dc=example,dc=com"), gomock.Eq("password")).
+			Return(nil),
+		mockConn.EXPECT().
+			Search(gomock.Any()).
+			Return(newActiveDirectoryUserSearchResult(0x200, activeDirectoryFileTime(clock.Now().Add(-43*24*time.Hour))), nil),
+		mockFactory.EXPECT().
+			DialURL(gomock.Eq("ldaps://127.0.0.1:636"), gomock.Any()).
+			Return(mockConn, nil),
+		mockConn.EXPECT().
+			Bind(gomock.Eq("cn=john,dc=example,dc=com"), gomock.Eq("password")).
+			Return(ldap.NewError(ldap.LDAPResultInvalidCredentials, errors.New("80090308: LdapErr: DSID-0C09044E, comment: AcceptSecurityContext error, data 532, v4563"))),
+		mockConn.EXPECT().
+			Close(),
+	)
+
+	valid, err := ldapClient.CheckUserPassword("john", "password")
+
+	assert.False(t, valid)
+	assert.True(t, errors.Is(err, ErrPasswordExpired))
+	assert.EqualError(t, err, "Authentication of user john failed. Cause: password expired")
+}
+
+func TestShouldNotDiscloseActiveDirectoryAccountStateWhenPasswordIsWrong(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := NewMockLDAPConnectionFactory(ctrl)
+	mockConn := NewMockLDAPConnection(ctrl)
+
+	ldapClient := newActiveDirectoryTestProvider(mockFactory)
+
+	gomock.InOrder(
+		mockFactory.EXPECT().
+			DialURL(gomock.Eq("ldaps://127.0.0.1:636"), gomock.Any()).
+			Return(mockConn, nil),
+		mockConn.EXPECT().
+			Bind(gomock.Eq("cn=admin,dc=example,dc=com"), gomock.Eq("password")).
+			Return(nil),
+		mockConn.EXPECT().
+			Search(NewExtendedSearchRequestMatcher("(sAMAccountName=john)", "dc=example,dc=com", ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, false,
+				[]string{"dn", "displayName", "mail", "sAMAccountName", adUserAccountControlAttribute, adPasswordLastSetAttribute, adLockoutTimeAttribute})).
+			Return(newActiveDirectoryUserSearchResult(0x202, 0), nil),
+		mockFactory.EXPECT().
+			DialURL(gomock.Eq("ldaps://127.0.0.1:636"), gomock.Any()).
+			Return(mockConn, nil),
+		mockConn.EXPECT().
+			Bind(gomock.Eq("cn=john,dc=example,dc=com"), gomock.Eq("wrong")).
+			Return(ldap.NewError(ldap.LDAPResultInvalidCredentials, errors.New("80090308: LdapErr: DSID-0C09044E, comment: AcceptSecurityContext error, data 52e, v4563"))),
+		mockConn.EXPECT().
+			Close(),
+	)
+
+	valid, err := ldapClient.CheckUserPassword("john", "wrong")
+
+	assert.False(t, valid)
+	assert.False(t, errors.Is(err, ErrAccountDisabled))
+	assert.EqualError(t, err, "Authentication of user john failed. Cause: LDAP Result Code 49 \"Invalid Credentials\": 80090308: LdapErr: DSID-0C09044E, comment: AcceptSecurityContext error, data 52e, v4563")
+}
+
+func TestShouldNotReturnDetailsOfDisabledActiveDirectoryAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := NewMockLDAPConnectionFactory(ctrl)
+	mockConn := NewMockLDAPConnection(ctrl)
+
+	ldapClient := newActiveDirectoryTestProvider(mockFactory)
+
+	gomock.InOrder(
+		mockFactory.EXPECT().
+			DialURL(gomock.Eq("ldaps://127.0.0.1:636"), gomock.Any()).
+			Return(mockConn, nil),
+		mockConn.EXPECT().
+			Bind(gomock.Eq("cn=admin,dc=example,dc=com"), gomock.Eq("password")).
+			Return(nil),
+		mockConn.EXPECT().
+			Search(gomock.Any()).
+			Return(newActiveDirectoryUserSearchResult(0x202, 0), nil),
+		mockConn.EXPECT().
+			Close(),
+	)
+
+	details, err := ldapClient.GetDetails("john")
+
+	assert.Nil(t, details)
+	assert.Equal(t, ErrAccountDisabled, err)
+}
+
+func TestShouldUpdateActiveDirectoryPasswordWithUnicodePwd(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := NewMockLDAPConnectionFactory(ctrl)
+	mockConn := NewMockLDAPConnection(ctrl)
+
+	ldapClient := newActiveDirectoryTestProvider(mockFactory)
+
+	// The unicodePwd attribute is preferred even when the server advertises the password modify extension.
+	ldapClient.supportExtensionPasswdModify = true
+
+	pwdEncoded, err := unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewEncoder().String("\"password\"")
+	require.NoError(t, err)
+
+	modifyRequest := ldap.NewModifyRequest("cn=john,dc=example,dc=com", nil)
+	modifyRequest.Replace("unicodePwd", []string{pwdEncoded})
+
+	gomock.InOrder(
+		mockFactory.EXPECT().
+			DialURL(gomock.Eq("ldaps://127.0.0.1:636"), gomock.Any()).
+			Return(mockConn, nil),
+		mockConn.EXPECT().
+			Bind(gomock.Eq("cn=admin,dc=example,dc=com"), gomock.Eq("password")).
+			Return(nil),
+		mockConn.EXPECT().
+			Search(gomock.Any()).
+			Return(newActiveDirectoryUserSearchResult(0x200, 0), nil),
+		mockConn.EXPECT().
+			Modify(gomock.Eq(modifyRequest)).
+			Return(nil),
+		mockConn.EXPECT().
+			Close(),
+	)
+
+	assert.NoError(t, ldapClient.UpdatePassword("john", "password"))
+}
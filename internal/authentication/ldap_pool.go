@@ -0,0 +1,257 @@
+package authentication
+
+import (
+	"crypto/tls"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/metrics"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// ldapServer is an LDAP server the connections are made to, the servers whose connections fail are unhealthy until a
+// connection succeeds again.
+type ldapServer struct {
+	url       string
+	tlsConfig *tls.Config
+	dialOpts  ldap.DialOpt
+	healthy   bool
+}
+
+// ldapServers selects the LDAP servers the connections are made to: the healthy servers in order with the failover
+// load balancing, or in turn with the round-robin load balancing. The unhealthy servers are only tried when all the
+// healthy ones fail.
+type ldapServers struct {
+	mutex      sync.Mutex
+	servers    []*ldapServer
+	next       int
+	roundRobin bool
+}
+
+// newLDAPServers creates the servers of the URLs. The server name of the TLS configuration is deduced from the URL of
+// each server when there are several servers and none is configured.
+func newLDAPServers(urls []string, loadBalancing string, tlsConfig *tls.Config) *ldapServers {
+	servers := &ldapServers{roundRobin: loadBalancing == schema.LDAPLoadBalancingRoundRobin}
+
+	for _, ldapURL := range urls {
+		server := &ldapServer{url: ldapURL, tlsConfig: tlsConfig, healthy: true}
+
+		if len(urls) > 1 && tlsConfig != nil && tlsConfig.ServerName == "" {
+			if parsedURL, err := url.Parse(ldapURL); err == nil {
+				server.tlsConfig = tlsConfig.Clone()
+				server.tlsConfig.ServerName = parsedURL.Hostname()
+			}
+		}
+
+		if server.tlsConfig != nil {
+			server.dialOpts = ldap.DialWithTLSConfig(server.tlsConfig)
+		}
+
+		metrics.LDAPServerUp.Set(1, ldapURL)
+
+		servers.servers = append(servers.servers, server)
+	}
+
+	return servers
+}
+
+// order returns the servers in the order the connections are attempted.
+func (s *ldapServers) order() []*ldapServer {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	start := 0
+
+	if s.roundRobin {
+		start = s.next
+		s.next = (s.next + 1) % len(s.servers)
+	}
+
+	healthy := make([]*ldapServer, 0, len(s.servers))
+	unhealthy := make([]*ldapServer, 0)
+
+	for i := range s.servers {
+		server := s.servers[(start+i)%len(s.servers)]
+
+		if server.healthy {
+			healthy = append(healthy, server)
+		} else {
+			unhealthy = append(unhealthy, server)
+		}
+	}
+
+	return append(healthy, unhealthy...)
+}
+
+// unhealthy returns the servers whose last connection failed.
+func (s *ldapServers) unhealthy() (servers []*ldapServer) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, server := range s.servers {
+		if !server.healthy {
+			servers = append(servers, server)
+		}
+	}
+
+	return servers
+}
+
+// setHealthy records the result of a connection to the server, it returns true when the health of the server changed.
+func (s *ldapServers) setHealthy(server *ldapServer, healthy bool) (changed bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !healthy {
+		metrics.LDAPServerFailures.Inc(server.url)
+	}
+
+	if server.healthy == healthy {
+		return false
+	}
+
+	server.healthy = healthy
+
+	if healthy {
+		metrics.LDAPServerUp.Set(1, server.url)
+	} else {
+		metrics.LDAPServerUp.Set(0, server.url)
+	}
+
+	return true
+}
+
+// ldapIdleConnection is a connection of the pool waiting to be reused.
+type ldapIdleConnection struct {
+	conn  LDAPConnection
+	since time.Time
+}
+
+// ldapConnectionPool keeps the connections bound with the user of the configuration open to reuse them between the
+// operations. More connections than the size of the pool are opened during the peaks of load, they're closed once
+// released when the pool is full. The connections idle for longer than the idle timeout are closed.
+type ldapConnectionPool struct {
+	mutex       sync.Mutex
+	idle        []ldapIdleConnection
+	inUse       int
+	size        int
+	idleTimeout time.Duration
+	clock       utils.Clock
+}
+
+func newLDAPConnectionPool(configuration schema.LDAPPoolConfiguration, clock utils.Clock) *ldapConnectionPool {
+	idleTimeout, err := utils.ParseDurationString(configuration.IdleTimeout)
+	if err != nil {
+		panic(err)
+	}
+
+	return &ldapConnectionPool{
+		size:        configuration.Size,
+		idleTimeout: idleTimeout,
+		clock:       clock,
+	}
+}
+
+// get returns the most recently released idle connection, it's nil when there is none and a new connection must be
+// opened.
+func (p *ldapConnectionPool) get() (conn LDAPConnection) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.closeExpired()
+
+	p.inUse++
+
+	if n := len(p.idle); n != 0 {
+		conn, p.idle = p.idle[n-1].conn, p.idle[:n-1]
+	}
+
+	metrics.LDAPPoolAcquisitions.Inc(strconv.FormatBool(conn != nil))
+	p.updateMetrics()
+
+	return conn
+}
+
+// put releases a connection taken from the pool, it's closed when it's broken or the pool is full. The connection is
+// nil when opening a new connection failed.
+func (p *ldapConnectionPool) put(conn LDAPConnection, broken bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.inUse--
+
+	switch {
+	case conn == nil:
+		break
+	case broken || len(p.idle) >= p.size:
+		conn.Close()
+	default:
+		p.idle = append(p.idle, ldapIdleConnection{conn: conn, since: p.clock.Now()})
+	}
+
+	p.updateMetrics()
+}
+
+// check closes the expired idle connections and the ones failing the check.
+func (p *ldapConnectionPool) check(check func(conn LDAPConnection) error) {
+	p.mutex.Lock()
+	p.closeExpired()
+
+	idle := p.idle
+	p.idle = nil
+	p.updateMetrics()
+	p.mutex.Unlock()
+
+	healthy := make([]ldapIdleConnection, 0, len(idle))
+
+	for _, connection := range idle {
+		if err := check(connection.conn); err != nil {
+			connection.conn.Close()
+			continue
+		}
+
+		healthy = append(healthy, connection)
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	// The connections released during the check are the most recent ones.
+	p.idle = append(healthy, p.idle...)
+
+	for len(p.idle) > p.size {
+		p.idle[0].conn.Close()
+		p.idle = p.idle[1:]
+	}
+
+	p.updateMetrics()
+}
+
+// closeExpired closes the connections idle for longer than the idle timeout, there is no timeout when it's 0. The
+// p.mutex must be held.
+func (p *ldapConnectionPool) closeExpired() {
+	if p.idleTimeout <= 0 {
+		return
+	}
+
+	now := p.clock.Now()
+
+	i := 0
+
+	for ; i < len(p.idle) && now.Sub(p.idle[i].since) >= p.idleTimeout; i++ {
+		p.idle[i].conn.Close()
+	}
+
+	p.idle = p.idle[i:]
+}
+
+// updateMetrics updates the metrics of the connections of the pool, p.mutex must be held.
+func (p *ldapConnectionPool) updateMetrics() {
+	metrics.LDAPPoolConnections.Set(float64(len(p.idle)), "idle")
+	metrics.LDAPPoolConnections.Set(float64(p.inUse), "in_use")
+}
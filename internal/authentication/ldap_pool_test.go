@@ -0,0 +1,232 @@
+package authentication
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/metrics"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+func TestShouldFailOverToNextLDAPServer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := NewMockLDAPConnectionFactory(ctrl)
+	mockConn := NewMockLDAPConnection(ctrl)
+
+	ldapClient := newLDAPUserProvider(
+		schema.LDAPAuthenticationBackendConfiguration{
+			URLs: []string{"ldap://ldap1.example.com:389", "ldap://ldap2.example.com:389"},
+		},
+		nil,
+		mockFactory)
+
+	gomock.InOrder(
+		mockFactory.EXPECT().
+			DialURL(gomock.Eq("ldap://ldap1.example.com:389"), gomock.Any()).
+			Return(nil, errors.New("connection refused")),
+		mockFactory.EXPECT().
+			DialURL(gomock.Eq("ldap://ldap2.example.com:389"), gomock.Any()).
+			Return(mockConn, nil),
+		mockConn.EXPECT().
+			Bind(gomock.Eq("cn=admin,dc=example,dc=com"), gomock.Eq("password")).
+			Return(nil),
+
+		// The unhealthy server is only tried once the healthy ones failed.
+		mockFactory.EXPECT().
+			DialURL(gomock.Eq("ldap://ldap2.example.com:389"), gomock.Any()).
+			Return(nil, errors.New("connection refused")),
+		mockFactory.EXPECT().
+			DialURL(gomock.Eq("ldap://ldap1.example.com:389"), gomock.Any()).
+			Return(nil, errors.New("connection refused")),
+	)
+
+	_, err := ldapClient.connect("cn=admin,dc=example,dc=com", "password")
+	require.NoError(t, err)
+
+	assert.Equal(t, float64(0), metrics.LDAPServerUp.Value("ldap://ldap1.example.com:389"))
+	assert.Equal(t, float64(1), metrics.LDAPServerUp.Value("ldap://ldap2.example.com:389"))
+
+	_, err = ldapClient.connect("cn=admin,dc=example,dc=com", "password")
+	assert.EqualError(t, err, "connection refused")
+}
+
+func TestShouldSpreadConnectionsAcrossLDAPServers(t *testing.T) {
+	servers := newLDAPServers([]string{"ldap://ldap1.example.com", "ldap://ldap2.example.com", "ldap://ldap3.example.com"},
+		schema.LDAPLoadBalancingRoundRobin, nil)
+
+	first := func() string {
+		return servers.order()[0].url
+	}
+
+	assert.Equal(t, "ldap://ldap1.example.com", first())
+	assert.Equal(t, "ldap://ldap2.example.com", first())
+	assert.Equal(t, "ldap://ldap3.example.com", first())
+	assert.Equal(t, "ldap://ldap1.example.com", first())
+
+	servers.setHealthy(servers.servers[1], false)
+
+	order := servers.order()
+	require.Len(t, order, 3)
+	assert.Equal(t, "ldap://ldap3.example.com", order[0].url)
+	assert.Equal(t, "ldap://ldap1.example.com", order[1].url)
+	assert.Equal(t, "ldap://ldap2.example.com", order[2].url)
+}
+
+func TestShouldDeduceServerNameOfEachLDAPServer(t *testing.T) {
+	tlsConfig := utils.NewTLSConfig(&schema.TLSConfig{MinimumVersion: "TLS1.2"}, 0, nil)
+
+	servers := newLDAPServers([]string{"ldaps://ldap1.example.com", "ldaps://ldap2.example.com"}, schema.LDAPLoadBalancingFailover, tlsConfig)
+
+	assert.Equal(t, "ldap1.example.com", servers.servers[0].tlsConfig.ServerName)
+	assert.Equal(t, "ldap2.example.com", servers.servers[1].tlsConfig.ServerName)
+	assert.Equal(t, "", tlsConfig.ServerName)
+}
+
+func TestShouldReuseLDAPConnectionsOfPool(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := NewMockLDAPConnectionFactory(ctrl)
+	mockConn := NewMockLDAPConnection(ctrl)
+
+	ldapClient := newLDAPUserProvider(
+		schema.LDAPAuthenticationBackendConfiguration{
+			URL:                  "ldap://127.0.0.1:389",
+			User:                 "cn=admin,dc=example,dc=com",
+			Password:             "password",
+			UsernameAttribute:    "uid",
+			MailAttribute:        "mail",
+			DisplayNameAttribute: "displayname",
+			UsersFilter:          "uid={input}",
+			AdditionalUsersDN:    "ou=users",
+			BaseDN:               "dc=example,dc=com",
+			Pool:                 &schema.LDAPPoolConfiguration{Size: 1, IdleTimeout: "5m"},
+		},
+		nil,
+		mockFactory)
+
+	// The connection is only dialed and bound once.
+	mockFactory.EXPECT().
+		DialURL(gomock.Eq("ldap://127.0.0.1:389"), gomock.Any()).
+		Return(mockConn, nil)
+
+	mockConn.EXPECT().
+		Bind(gomock.Eq("cn=admin,dc=example,dc=com"), gomock.Eq("password")).
+		Return(nil)
+
+	mockConn.EXPECT().
+		Search(gomock.Any()).
+		Return(&ldap.SearchResult{}, nil).
+		Times(2)
+
+	_, err := ldapClient.GetDetails("john")
+	assert.Equal(t, ErrUserNotFound, err)
+
+	assert.Equal(t, float64(1), metrics.LDAPPoolConnections.Value("idle"))
+	assert.Equal(t, float64(0), metrics.LDAPPoolConnections.Value("in_use"))
+
+	_, err = ldapClient.GetDetails("john")
+	assert.Equal(t, ErrUserNotFound, err)
+
+	// The connection of an operation which failed is closed as it may be broken.
+	mockConn.EXPECT().
+		Search(gomock.Any()).
+		Return(nil, errors.New("connection reset"))
+
+	mockConn.EXPECT().
+		Close()
+
+	_, err = ldapClient.GetDetails("john")
+	assert.EqualError(t, err, "Cannot find user DN of user john. Cause: connection reset")
+
+	assert.Equal(t, float64(0), metrics.LDAPPoolConnections.Value("idle"))
+}
+
+func TestShouldCloseIdleLDAPConnectionsOfPool(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	clock := &utils.FakeClock{}
+	clock.Set(time.Unix(1600000000, 0))
+
+	pool := newLDAPConnectionPool(schema.LDAPPoolConfiguration{Size: 2, IdleTimeout: "5m"}, clock)
+
+	first, second, third := NewMockLDAPConnection(ctrl), NewMockLDAPConnection(ctrl), NewMockLDAPConnection(ctrl)
+
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, pool.get())
+	}
+
+	// The pool is full once the first two connections are released.
+	third.EXPECT().Close()
+
+	pool.put(first, false)
+
+	clock.Advance(time.Minute)
+
+	pool.put(second, false)
+	pool.put(third, false)
+
+	// The connection idle for longer than the idle timeout is closed.
+	clock.Advance(4 * time.Minute)
+
+	first.EXPECT().Close()
+
+	assert.Equal(t, second, pool.get())
+	assert.Nil(t, pool.get())
+}
+
+func TestShouldCheckHealthOfLDAPServersAndConnections(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := NewMockLDAPConnectionFactory(ctrl)
+	healthyConn, brokenConn, checkConn := NewMockLDAPConnection(ctrl), NewMockLDAPConnection(ctrl), NewMockLDAPConnection(ctrl)
+
+	ldapClient := newLDAPUserProvider(
+		schema.LDAPAuthenticationBackendConfiguration{
+			URLs: []string{"ldap://ldap1.example.com:389", "ldap://ldap2.example.com:389"},
+			Pool: &schema.LDAPPoolConfiguration{Size: 2, IdleTimeout: "0"},
+		},
+		nil,
+		mockFactory)
+
+	ldapClient.servers.setHealthy(ldapClient.servers.servers[0], false)
+
+	ldapClient.pool.get()
+	ldapClient.pool.get()
+	ldapClient.pool.put(healthyConn, false)
+	ldapClient.pool.put(brokenConn, false)
+
+	mockFactory.EXPECT().
+		DialURL(gomock.Eq("ldap://ldap1.example.com:389"), gomock.Any()).
+		Return(checkConn, nil)
+
+	checkConn.EXPECT().Close()
+
+	healthyConn.EXPECT().
+		Search(gomock.Any()).
+		Return(&ldap.SearchResult{}, nil)
+
+	brokenConn.EXPECT().
+		Search(gomock.Any()).
+		Return(nil, errors.New("connection reset"))
+
+	brokenConn.EXPECT().Close()
+
+	ldapClient.checkHealth()
+
+	assert.Len(t, ldapClient.servers.unhealthy(), 0)
+	assert.Equal(t, float64(1), metrics.LDAPServerUp.Value("ldap://ldap1.example.com:389"))
+	assert.Equal(t, healthyConn, ldapClient.pool.get())
+	assert.Nil(t, ldapClient.pool.get())
+}
@@ -2,9 +2,10 @@ package authentication
 
 import (
 	"crypto/tls"
-	"crypto/x509"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-ldap/ldap/v3"
 	"github.com/sirupsen/logrus"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/authelia/authelia/internal/configuration/schema"
 	"github.com/authelia/authelia/internal/logging"
+	"github.com/authelia/authelia/internal/metrics"
 	"github.com/authelia/authelia/internal/utils"
 )
 
@@ -19,17 +21,31 @@ import (
 type LDAPUserProvider struct {
 	configuration     schema.LDAPAuthenticationBackendConfiguration
 	tlsConfig         *tls.Config
-	dialOpts          ldap.DialOpt
+	servers           *ldapServers
 	logger            *logrus.Logger
 	connectionFactory LDAPConnectionFactory
 	usersBaseDN       string
 	groupsBaseDN      string
 
+	// pool holds the connections bound with the user of the configuration between the operations, it's nil when the
+	// pooling is disabled.
+	pool *ldapConnectionPool
+
+	// password is the password binding the user of the configuration, it's held outside of the configuration so it's
+	// locked in memory and never logged.
+	password *utils.SecureBuffer
+
+	// activeDirectoryPolicy is the password and lockout policy of the domain read at startup with the activedirectory
+	// implementation.
+	activeDirectoryPolicy activeDirectoryPolicy
+
+	clock utils.Clock
+
 	supportExtensionPasswdModify bool
 }
 
 // NewLDAPUserProvider creates a new instance of LDAPUserProvider.
-func NewLDAPUserProvider(configuration schema.AuthenticationBackendConfiguration, certPool *x509.CertPool) (provider *LDAPUserProvider, err error) {
+func NewLDAPUserProvider(configuration schema.AuthenticationBackendConfiguration, certPool *utils.X509CertPool) (provider *LDAPUserProvider, err error) {
 	provider = newLDAPUserProvider(*configuration.LDAP, certPool, nil)
 
 	err = provider.checkServer()
@@ -37,6 +53,8 @@ func NewLDAPUserProvider(configuration schema.AuthenticationBackendConfiguration
 		return provider, err
 	}
 
+	provider.startHealthChecks()
+
 	if !provider.supportExtensionPasswdModify && !configuration.DisableResetPassword &&
 		provider.configuration.Implementation != schema.LDAPImplementationActiveDirectory {
 		provider.logger.Warnf("Your LDAP server implementation may not support a method for password hashing " +
@@ -44,20 +62,31 @@ func NewLDAPUserProvider(configuration schema.AuthenticationBackendConfiguration
 			"attribute when users reset their password via Authelia.")
 	}
 
+	if provider.configuration.Implementation == schema.LDAPImplementationActiveDirectory &&
+		!configuration.DisableResetPassword && !provider.configuration.StartTLS {
+		for _, server := range provider.servers.servers {
+			if strings.HasPrefix(server.url, "ldap://") {
+				provider.logger.Warnf("Active Directory only accepts the password changes over an encrypted connection, " +
+					"the users won't be able to reset their password unless the LDAP servers are connected with ldaps or StartTLS.")
+
+				break
+			}
+		}
+	}
+
 	return provider, nil
 }
 
-func newLDAPUserProvider(configuration schema.LDAPAuthenticationBackendConfiguration, certPool *x509.CertPool, factory LDAPConnectionFactory) (provider *LDAPUserProvider) {
+func newLDAPUserProvider(configuration schema.LDAPAuthenticationBackendConfiguration, certPool *utils.X509CertPool, factory LDAPConnectionFactory) (provider *LDAPUserProvider) {
 	if configuration.TLS == nil {
 		configuration.TLS = schema.DefaultLDAPAuthenticationBackendConfiguration.TLS
 	}
 
 	tlsConfig := utils.NewTLSConfig(configuration.TLS, tls.VersionTLS12, certPool)
 
-	var dialOpts ldap.DialOpt
-
-	if tlsConfig != nil {
-		dialOpts = ldap.DialWithTLSConfig(tlsConfig)
+	urls := configuration.URLs
+	if len(urls) == 0 {
+		urls = []string{configuration.URL}
 	}
 
 	if factory == nil {
@@ -67,9 +96,17 @@ func newLDAPUserProvider(configuration schema.LDAPAuthenticationBackendConfigura
 	provider = &LDAPUserProvider{
 		configuration:     configuration,
 		tlsConfig:         tlsConfig,
-		dialOpts:          dialOpts,
+		servers:           newLDAPServers(urls, configuration.LoadBalancing, tlsConfig),
 		logger:            logging.Logger(),
 		connectionFactory: factory,
+		password:          utils.NewSecureBufferFromString(configuration.Password),
+		clock:             &utils.RealClock{},
+	}
+
+	provider.configuration.Password = ""
+
+	if configuration.Pool != nil {
+		provider.pool = newLDAPConnectionPool(*configuration.Pool, provider.clock)
 	}
 
 	provider.parseDynamicConfiguration()
@@ -102,11 +139,15 @@ func (p *LDAPUserProvider) parseDynamicConfiguration() {
 }
 
 func (p *LDAPUserProvider) checkServer() (err error) {
-	conn, err := p.connect(p.configuration.User, p.configuration.Password)
+	conn, err := p.connect(p.configuration.User, p.password.RevealString())
 	if err != nil {
 		return err
 	}
 
+	if p.configuration.Implementation == schema.LDAPImplementationActiveDirectory {
+		p.loadActiveDirectoryPolicy(conn)
+	}
+
 	searchRequest := ldap.NewSearchRequest("", ldap.ScopeBaseObject, ldap.NeverDerefAliases,
 		1, 0, false, "(objectClass=*)", []string{ldapSupportedExtensionAttribute}, nil)
 
@@ -138,40 +179,195 @@ func (p *LDAPUserProvider) checkServer() (err error) {
 	return nil
 }
 
-func (p *LDAPUserProvider) connect(userDN string, password string) (LDAPConnection, error) {
-	conn, err := p.connectionFactory.DialURL(p.configuration.URL, p.dialOpts)
+// loadActiveDirectoryPolicy reads the password and lockout policy from the domain object which is the base DN. The
+// passwords only expire when they must be changed at the next logon if it can't be read.
+func (p *LDAPUserProvider) loadActiveDirectoryPolicy(conn LDAPConnection) {
+	searchRequest := ldap.NewSearchRequest(p.configuration.BaseDN, ldap.ScopeBaseObject, ldap.NeverDerefAliases,
+		1, 0, false, "(objectClass=*)", []string{adMaxPasswordAgeAttribute, adLockoutDurationAttribute}, nil)
+
+	sr, err := conn.Search(searchRequest)
+	if err != nil || len(sr.Entries) != 1 {
+		p.logger.Warnf("Unable to read the password policy of the Active Directory domain %s, the expiration of "+
+			"the passwords is unknown", p.configuration.BaseDN)
+
+		return
+	}
+
+	p.activeDirectoryPolicy = newActiveDirectoryPolicy(sr.Entries[0])
+
+	p.logger.Debugf("Active Directory domain policy: the passwords expire after %s and the accounts are locked out for %s",
+		p.activeDirectoryPolicy.maxPasswordAge, p.activeDirectoryPolicy.lockoutDuration)
+}
+
+// connect dials the LDAP server and binds with the given user, the duration of the whole operation is measured.
+func (p *LDAPUserProvider) connect(userDN string, password string) (conn LDAPConnection, err error) {
+	start := time.Now()
+
+	defer func() {
+		metrics.LDAPBindDuration.Observe(time.Since(start).Seconds(), strconv.FormatBool(err == nil))
+	}()
+
+	if conn, err = p.dial(); err != nil {
+		return nil, err
+	}
+
+	if err = conn.Bind(userDN, password); err != nil {
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// dial connects to the first LDAP server accepting the connection in the order of the load balancing, the servers
+// which fail are failed over.
+func (p *LDAPUserProvider) dial() (conn LDAPConnection, err error) {
+	servers := p.servers.order()
+
+	for _, server := range servers {
+		if conn, err = p.dialServer(server); err == nil {
+			if p.servers.setHealthy(server, true) {
+				p.logger.Infof("LDAP server %s is reachable again", server.url)
+			}
+
+			return conn, nil
+		}
+
+		if p.servers.setHealthy(server, false) && len(servers) > 1 {
+			p.logger.Warnf("LDAP server %s is unreachable, failing over to the other servers: %s", server.url, err)
+		}
+	}
+
+	return nil, err
+}
+
+func (p *LDAPUserProvider) dialServer(server *ldapServer) (conn LDAPConnection, err error) {
+	conn, err = p.connectionFactory.DialURL(server.url, server.dialOpts)
 	if err != nil {
 		return nil, err
 	}
 
 	if p.configuration.StartTLS {
-		if err := conn.StartTLS(p.tlsConfig); err != nil {
+		if err = conn.StartTLS(server.tlsConfig); err != nil {
 			return nil, err
 		}
 	}
 
-	if err := conn.Bind(userDN, password); err != nil {
+	return conn, nil
+}
+
+// getConnection returns a connection bound with the user of the configuration, it's taken from the pool when the
+// pooling is enabled. It must be released with releaseConnection.
+func (p *LDAPUserProvider) getConnection() (conn LDAPConnection, err error) {
+	if p.pool == nil {
+		return p.connect(p.configuration.User, p.password.RevealString())
+	}
+
+	if conn = p.pool.get(); conn != nil {
+		return conn, nil
+	}
+
+	if conn, err = p.connect(p.configuration.User, p.password.RevealString()); err != nil {
+		p.pool.put(nil, true)
+
 		return nil, err
 	}
 
 	return conn, nil
 }
 
+// releaseConnection releases a connection returned by getConnection once the operation completed with the given
+// error. The connections of the operations which failed are closed instead of being reused as they may be broken,
+// except when the user isn't found or their account is disabled.
+func (p *LDAPUserProvider) releaseConnection(conn LDAPConnection, err error) {
+	if p.pool == nil {
+		conn.Close()
+		return
+	}
+
+	p.pool.put(conn, err != nil && err != ErrUserNotFound && err != ErrAccountDisabled)
+}
+
+// startHealthChecks checks the unhealthy LDAP servers and the idle connections of the pool periodically in the
+// background, so the traffic fails back to the servers once they're reachable again and the broken connections aren't
+// reused. The health checks are disabled when the interval is 0.
+func (p *LDAPUserProvider) startHealthChecks() {
+	interval, err := utils.ParseDurationString(p.configuration.HealthCheckInterval)
+	if err != nil || interval <= 0 {
+		return
+	}
+
+	go func() {
+		for range time.Tick(interval) {
+			p.checkHealth()
+		}
+	}()
+}
+
+// checkHealth dials the unhealthy servers and searches the root DSE with the idle connections of the pool.
+func (p *LDAPUserProvider) checkHealth() {
+	for _, server := range p.servers.unhealthy() {
+		conn, err := p.dialServer(server)
+		if err != nil {
+			p.servers.setHealthy(server, false)
+
+			p.logger.Debugf("LDAP server %s is still unreachable: %s", server.url, err)
+
+			continue
+		}
+
+		conn.Close()
+
+		if p.servers.setHealthy(server, true) {
+			p.logger.Infof("LDAP server %s is reachable again", server.url)
+		}
+	}
+
+	if p.pool == nil {
+		return
+	}
+
+	p.pool.check(func(conn LDAPConnection) error {
+		_, err := conn.Search(ldap.NewSearchRequest("", ldap.ScopeBaseObject, ldap.NeverDerefAliases,
+			1, 0, false, "(objectClass=*)", []string{"1.1"}, nil))
+
+		return err
+	})
+}
+
+// StartupCheck binds with the user of the configuration to check the server is reachable and the credentials are valid.
+func (p *LDAPUserProvider) StartupCheck() (err error) {
+	conn, err := p.connect(p.configuration.User, p.password.RevealString())
+	if err != nil {
+		return err
+	}
+
+	conn.Close()
+
+	return nil
+}
+
 // CheckUserPassword checks if provided password matches for the given user.
 func (p *LDAPUserProvider) CheckUserPassword(inputUsername string, password string) (bool, error) {
-	conn, err := p.connect(p.configuration.User, p.configuration.Password)
+	conn, err := p.getConnection()
 	if err != nil {
 		return false, err
 	}
-	defer conn.Close()
 
 	profile, err := p.getUserProfile(conn, inputUsername)
+
+	// The connection is released with the error of the lookup, failing to bind the user doesn't break it.
+	defer p.releaseConnection(conn, err)
+
 	if err != nil {
 		return false, err
 	}
 
 	userConn, err := p.connect(profile.DN, password)
 	if err != nil {
+		if accountErr := p.accountError(profile, err); accountErr != nil {
+			return false, fmt.Errorf("Authentication of user %s failed. Cause: %w", inputUsername, accountErr)
+		}
+
 		return false, fmt.Errorf("Authentication of user %s failed. Cause: %s", inputUsername, err)
 	}
 	defer userConn.Close()
@@ -179,6 +375,16 @@ func (p *LDAPUserProvider) CheckUserPassword(inputUsername string, password stri
 	return true, nil
 }
 
+// accountError returns the state of the Active Directory account preventing the user from logging in when their bind
+// failed because of it, it's nil when the password is wrong or the implementation isn't activedirectory.
+func (p *LDAPUserProvider) accountError(profile *ldapUserProfile, bindErr error) error {
+	if profile.account == nil || !isActiveDirectoryAccountBindFailure(bindErr) {
+		return nil
+	}
+
+	return profile.account.err(p.activeDirectoryPolicy, p.clock.Now())
+}
+
 func (p *LDAPUserProvider) ldapEscape(inputUsername string) string {
 	inputUsername = ldap.EscapeFilter(inputUsername)
 	for _, c := range specialLDAPRunes {
@@ -193,6 +399,9 @@ type ldapUserProfile struct {
 	Emails      []string
 	DisplayName string
 	Username    string
+
+	// account is the state of the account with the activedirectory implementation, it's nil otherwise.
+	account *activeDirectoryAccount
 }
 
 func (p *LDAPUserProvider) resolveUsersFilter(userFilter string, inputUsername string) string {
@@ -214,6 +423,10 @@ func (p *LDAPUserProvider) getUserProfile(conn LDAPConnection, inputUsername str
 		p.configuration.MailAttribute,
 		p.configuration.UsernameAttribute}
 
+	if p.configuration.Implementation == schema.LDAPImplementationActiveDirectory {
+		attributes = append(attributes, adUserAccountControlAttribute, adPasswordLastSetAttribute, adLockoutTimeAttribute)
+	}
+
 	// Search for the given username.
 	searchRequest := ldap.NewSearchRequest(
 		p.usersBaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
@@ -260,6 +473,10 @@ func (p *LDAPUserProvider) getUserProfile(conn LDAPConnection, inputUsername str
 		return nil, fmt.Errorf("No DN has been found for user %s", inputUsername)
 	}
 
+	if p.configuration.Implementation == schema.LDAPImplementationActiveDirectory {
+		userProfile.account = newActiveDirectoryAccount(sr.Entries[0])
+	}
+
 	return &userProfile, nil
 }
 
@@ -280,18 +497,26 @@ func (p *LDAPUserProvider) resolveGroupsFilter(inputUsername string, profile *ld
 }
 
 // GetDetails retrieve the groups a user belongs to.
-func (p *LDAPUserProvider) GetDetails(inputUsername string) (*UserDetails, error) {
-	conn, err := p.connect(p.configuration.User, p.configuration.Password)
+func (p *LDAPUserProvider) GetDetails(inputUsername string) (details *UserDetails, err error) {
+	conn, err := p.getConnection()
 	if err != nil {
 		return nil, err
 	}
-	defer conn.Close()
+
+	defer func() {
+		p.releaseConnection(conn, err)
+	}()
 
 	profile, err := p.getUserProfile(conn, inputUsername)
 	if err != nil {
 		return nil, err
 	}
 
+	// The disabled accounts are found to tell their users why they can't log in but their details aren't used.
+	if profile.account != nil && profile.account.disabled() {
+		return nil, ErrAccountDisabled
+	}
+
 	groupsFilter, err := p.resolveGroupsFilter(inputUsername, profile)
 	if err != nil {
 		return nil, fmt.Errorf("Unable to create group filter for user %s. Cause: %s", inputUsername, err)
@@ -329,12 +554,15 @@ func (p *LDAPUserProvider) GetDetails(inputUsername string) (*UserDetails, error
 }
 
 // UpdatePassword update the password of the given user.
-func (p *LDAPUserProvider) UpdatePassword(inputUsername string, newPassword string) error {
-	conn, err := p.connect(p.configuration.User, p.configuration.Password)
+func (p *LDAPUserProvider) UpdatePassword(inputUsername string, newPassword string) (err error) {
+	conn, err := p.getConnection()
 	if err != nil {
 		return fmt.Errorf("Unable to update password. Cause: %s", err)
 	}
-	defer conn.Close()
+
+	defer func() {
+		p.releaseConnection(conn, err)
+	}()
 
 	profile, err := p.getUserProfile(conn, inputUsername)
 
@@ -343,23 +571,29 @@ func (p *LDAPUserProvider) UpdatePassword(inputUsername string, newPassword stri
 	}
 
 	switch {
-	case p.supportExtensionPasswdModify:
-		modifyRequest := ldap.NewPasswordModifyRequest(
-			profile.DN,
-			"",
-			newPassword,
-		)
-
-		err = conn.PasswordModify(modifyRequest)
 	case p.configuration.Implementation == schema.LDAPImplementationActiveDirectory:
 		modifyRequest := ldap.NewModifyRequest(profile.DN, nil)
 		utf16 := unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
+
+		var pwdEncoded string
+
 		// The password needs to be enclosed in quotes
 		// https://docs.microsoft.com/en-us/openspecs/windows_protocols/ms-adts/6e803168-f140-4d23-b2d3-c3a8ab5917d2
-		pwdEncoded, _ := utf16.NewEncoder().String(fmt.Sprintf("\"%s\"", newPassword))
+		if pwdEncoded, err = utf16.NewEncoder().String(fmt.Sprintf("\"%s\"", newPassword)); err != nil {
+			return fmt.Errorf("Unable to update password. Cause: %s", err)
+		}
+
 		modifyRequest.Replace("unicodePwd", []string{pwdEncoded})
 
 		err = conn.Modify(modifyRequest)
+	case p.supportExtensionPasswdModify:
+		modifyRequest := ldap.NewPasswordModifyRequest(
+			profile.DN,
+			"",
+			newPassword,
+		)
+
+		err = conn.PasswordModify(modifyRequest)
 	default:
 		modifyRequest := ldap.NewModifyRequest(profile.DN, nil)
 		modifyRequest.Replace("userPassword", []string{newPassword})
@@ -291,6 +291,46 @@ func TestShouldReturnCheckServerConnectError(t *testing.T) {
 	assert.False(t, ldapClient.supportExtensionPasswdModify)
 }
 
+func TestShouldBindWithConfiguredUserOnStartupCheck(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFactory := NewMockLDAPConnectionFactory(ctrl)
+	mockConn := NewMockLDAPConnection(ctrl)
+
+	ldapClient := newLDAPUserProvider(
+		schema.LDAPAuthenticationBackendConfiguration{
+			URL:      "ldap://127.0.0.1:389",
+			User:     "cn=admin,dc=example,dc=com",
+			Password: "password",
+		},
+		nil,
+		mockFactory)
+
+	// The password is only held by the secure buffer of the provider.
+	assert.Equal(t, "", ldapClient.configuration.Password)
+
+	gomock.InOrder(
+		mockFactory.EXPECT().
+			DialURL(gomock.Eq("ldap://127.0.0.1:389"), gomock.Any()).
+			Return(mockConn, nil),
+		mockConn.EXPECT().
+			Bind(gomock.Eq("cn=admin,dc=example,dc=com"), gomock.Eq("password")).
+			Return(nil),
+		mockConn.EXPECT().
+			Close(),
+		mockFactory.EXPECT().
+			DialURL(gomock.Eq("ldap://127.0.0.1:389"), gomock.Any()).
+			Return(mockConn, nil),
+		mockConn.EXPECT().
+			Bind(gomock.Eq("cn=admin,dc=example,dc=com"), gomock.Eq("password")).
+			Return(errors.New("invalid credentials")),
+	)
+
+	assert.NoError(t, ldapClient.StartupCheck())
+	assert.EqualError(t, ldapClient.StartupCheck(), "invalid credentials")
+}
+
 func TestShouldReturnCheckServerSearchError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -0,0 +1,151 @@
+package authentication
+
+import (
+	"math"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+// The rules of the password policy, they're returned when a password doesn't follow them.
+const (
+	PasswordRuleMinLength = "min_length"
+	PasswordRuleMaxLength = "max_length"
+	PasswordRuleUppercase = "uppercase"
+	PasswordRuleLowercase = "lowercase"
+	PasswordRuleNumber    = "number"
+	PasswordRuleSpecial   = "special"
+	PasswordRuleMinScore  = "min_score"
+)
+
+// The size of the character classes used to estimate the entropy of a password.
+const (
+	passwordLowercasePool = 26
+	passwordUppercasePool = 26
+	passwordNumberPool    = 10
+	passwordSpecialPool   = 33
+)
+
+// passwordScoreThresholds are the entropies in bits a password must reach to get the scores 1 to 4.
+var passwordScoreThresholds = []float64{28, 36, 60, 128}
+
+// PasswordPolicy checks the new passwords of the users against the configured rules.
+type PasswordPolicy struct {
+	configuration schema.PasswordPolicyConfiguration
+}
+
+// NewPasswordPolicy creates a PasswordPolicy from the configuration.
+func NewPasswordPolicy(configuration schema.PasswordPolicyConfiguration) PasswordPolicy {
+	return PasswordPolicy{configuration: configuration}
+}
+
+// Check returns the rules the password doesn't follow, the password is valid when none is returned.
+func (p PasswordPolicy) Check(password string) (unmet []string) {
+	length := utf8.RuneCountInString(password)
+	classes := newPasswordClasses(password)
+
+	if length < p.configuration.MinLength {
+		unmet = append(unmet, PasswordRuleMinLength)
+	}
+
+	if p.configuration.MaxLength != 0 && length > p.configuration.MaxLength {
+		unmet = append(unmet, PasswordRuleMaxLength)
+	}
+
+	if p.configuration.RequireUppercase && !classes.uppercase {
+		unmet = append(unmet, PasswordRuleUppercase)
+	}
+
+	if p.configuration.RequireLowercase && !classes.lowercase {
+		unmet = append(unmet, PasswordRuleLowercase)
+	}
+
+	if p.configuration.RequireNumber && !classes.number {
+		unmet = append(unmet, PasswordRuleNumber)
+	}
+
+	if p.configuration.RequireSpecial && !classes.special {
+		unmet = append(unmet, PasswordRuleSpecial)
+	}
+
+	if PasswordStrength(password) < p.configuration.MinScore {
+		unmet = append(unmet, PasswordRuleMinScore)
+	}
+
+	return unmet
+}
+
+// PasswordStrength estimates the strength of a password with a score from 0 (very weak) to 4 (very strong) based on
+// its length and the character classes it uses. The characters repeating the previous one are not counted.
+func PasswordStrength(password string) (score int) {
+	classes := newPasswordClasses(password)
+
+	pool := 0
+
+	if classes.lowercase {
+		pool += passwordLowercasePool
+	}
+
+	if classes.uppercase {
+		pool += passwordUppercasePool
+	}
+
+	if classes.number {
+		pool += passwordNumberPool
+	}
+
+	if classes.special {
+		pool += passwordSpecialPool
+	}
+
+	if pool == 0 {
+		return 0
+	}
+
+	var (
+		length   int
+		previous rune = -1
+	)
+
+	for _, r := range password {
+		if r != previous {
+			length++
+		}
+
+		previous = r
+	}
+
+	entropy := float64(length) * math.Log2(float64(pool))
+
+	for _, threshold := range passwordScoreThresholds {
+		if entropy < threshold {
+			break
+		}
+
+		score++
+	}
+
+	return score
+}
+
+type passwordClasses struct {
+	uppercase, lowercase, number, special bool
+}
+
+func newPasswordClasses(password string) (classes passwordClasses) {
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			classes.uppercase = true
+		case unicode.IsLower(r):
+			classes.lowercase = true
+		case unicode.IsDigit(r):
+			classes.number = true
+		default:
+			classes.special = true
+		}
+	}
+
+	return classes
+}
@@ -0,0 +1,47 @@
+package authentication
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+func TestShouldCheckPasswordAgainstPolicy(t *testing.T) {
+	policy := NewPasswordPolicy(schema.PasswordPolicyConfiguration{
+		MinLength:        8,
+		MaxLength:        16,
+		RequireUppercase: true,
+		RequireLowercase: true,
+		RequireNumber:    true,
+		RequireSpecial:   true,
+	})
+
+	assert.Empty(t, policy.Check("Passw0rd!"))
+	assert.Equal(t, []string{PasswordRuleMinLength, PasswordRuleUppercase, PasswordRuleNumber, PasswordRuleSpecial}, policy.Check("pass"))
+	assert.Equal(t, []string{PasswordRuleMaxLength, PasswordRuleLowercase}, policy.Check("PASSWORD0123456789!"))
+}
+
+func TestShouldAcceptAnyPasswordWithEmptyPolicy(t *testing.T) {
+	policy := NewPasswordPolicy(schema.PasswordPolicyConfiguration{})
+
+	assert.Empty(t, policy.Check(""))
+	assert.Empty(t, policy.Check("a"))
+}
+
+func TestShouldCheckPasswordMinScore(t *testing.T) {
+	policy := NewPasswordPolicy(schema.PasswordPolicyConfiguration{MinScore: 3})
+
+	assert.Equal(t, []string{PasswordRuleMinScore}, policy.Check("password"))
+	assert.Empty(t, policy.Check("correct horse battery staple"))
+}
+
+func TestShouldEstimatePasswordStrength(t *testing.T) {
+	assert.Equal(t, 0, PasswordStrength(""))
+	assert.Equal(t, 0, PasswordStrength("aaaaaaaaaaaaaaaa"))
+	assert.Equal(t, 1, PasswordStrength("password"))
+	assert.Equal(t, 2, PasswordStrength("Passw0rd"))
+	assert.Equal(t, 3, PasswordStrength("Tr0ub4dor&3"))
+	assert.Equal(t, 4, PasswordStrength("correct horse battery staple"))
+}
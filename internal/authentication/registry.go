@@ -0,0 +1,75 @@
+package authentication
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// UserProviderFactory creates a UserProvider from the authentication backend configuration and the options of the
+// provider decoded from the authentication_backend.options configuration block.
+type UserProviderFactory func(configuration schema.AuthenticationBackendConfiguration, options map[string]interface{}, certPool *x509.CertPool) (UserProvider, error)
+
+var (
+	userProviderFactoriesMu sync.RWMutex
+	userProviderFactories   = map[string]UserProviderFactory{}
+)
+
+// RegisterUserProvider makes a UserProvider available under the provided name so it can be selected with the
+// authentication_backend.provider configuration key. It panics if the name is already registered or the factory is nil.
+func RegisterUserProvider(name string, factory UserProviderFactory) {
+	userProviderFactoriesMu.Lock()
+	defer userProviderFactoriesMu.Unlock()
+
+	if factory == nil {
+		panic("authentication: RegisterUserProvider factory is nil")
+	}
+
+	if _, dup := userProviderFactories[name]; dup {
+		panic("authentication: RegisterUserProvider called twice for provider " + name)
+	}
+
+	userProviderFactories[name] = factory
+}
+
+// UserProviders returns a sorted list of the names of the registered user providers.
+func UserProviders() (names []string) {
+	userProviderFactoriesMu.RLock()
+	defer userProviderFactoriesMu.RUnlock()
+
+	for name := range userProviderFactories {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// NewUserProvider creates the UserProvider described by the configuration, either one of the built-in providers or a
+// provider registered with RegisterUserProvider.
+func NewUserProvider(configuration schema.AuthenticationBackendConfiguration, certPool *utils.X509CertPool) (UserProvider, error) {
+	switch {
+	case configuration.Provider != "":
+		userProviderFactoriesMu.RLock()
+		factory, ok := userProviderFactories[configuration.Provider]
+		userProviderFactoriesMu.RUnlock()
+
+		if !ok {
+			return nil, fmt.Errorf("unknown authentication backend provider '%s'", configuration.Provider)
+		}
+
+		return factory(configuration, configuration.Options, certPool.CertPool())
+	case configuration.File != nil:
+		return NewFileUserProvider(configuration.File), nil
+	case configuration.LDAP != nil:
+		return NewLDAPUserProvider(configuration, certPool)
+	default:
+		return nil, errors.New("unrecognized authentication backend")
+	}
+}
@@ -0,0 +1,56 @@
+package authentication
+
+import (
+	"crypto/x509"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+func TestShouldCreateRegisteredUserProvider(t *testing.T) {
+	expected := &FileUserProvider{}
+
+	var options map[string]interface{}
+
+	RegisterUserProvider("test_registered", func(_ schema.AuthenticationBackendConfiguration, o map[string]interface{}, _ *x509.CertPool) (UserProvider, error) {
+		options = o
+		return expected, nil
+	})
+
+	assert.Contains(t, UserProviders(), "test_registered")
+
+	provider, err := NewUserProvider(schema.AuthenticationBackendConfiguration{
+		Provider: "test_registered",
+		Options:  map[string]interface{}{"url": "https://idp.example.com"},
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, expected, provider)
+	assert.Equal(t, map[string]interface{}{"url": "https://idp.example.com"}, options)
+}
+
+func TestShouldPanicWhenRegisteringUserProviderTwice(t *testing.T) {
+	factory := func(_ schema.AuthenticationBackendConfiguration, _ map[string]interface{}, _ *x509.CertPool) (UserProvider, error) {
+		return nil, nil
+	}
+
+	RegisterUserProvider("test_duplicate", factory)
+
+	assert.Panics(t, func() {
+		RegisterUserProvider("test_duplicate", factory)
+	})
+
+	assert.Panics(t, func() {
+		RegisterUserProvider("test_nil", nil)
+	})
+}
+
+func TestShouldErrorOnUnknownUserProvider(t *testing.T) {
+	_, err := NewUserProvider(schema.AuthenticationBackendConfiguration{Provider: "test_unknown"}, nil)
+	assert.EqualError(t, err, "unknown authentication backend provider 'test_unknown'")
+
+	_, err = NewUserProvider(schema.AuthenticationBackendConfiguration{}, nil)
+	assert.EqualError(t, err, "unrecognized authentication backend")
+}
@@ -28,6 +28,11 @@ func NewAccessControlRule(pos int, rule schema.ACLRule, networksMap map[string][
 		Networks:  schemaNetworksToACL(rule.Networks, networksMap, networksCacheMap),
 		Subjects:  schemaSubjectsToACL(rule.Subjects),
 		Policy:    PolicyToLevel(rule.Policy),
+
+		TrustedNetworks: schemaNetworksToACL(rule.TrustedNetworks, networksMap, networksCacheMap),
+		AccessGrants:    rule.AccessGrants,
+
+		Definition: rule,
 	}
 }
 
@@ -40,6 +45,12 @@ type AccessControlRule struct {
 	Networks  []*net.IPNet
 	Subjects  []AccessControlSubjects
 	Policy    Level
+
+	TrustedNetworks []*net.IPNet
+	AccessGrants    bool
+
+	// The rule as configured, it's reported by the access reviews.
+	Definition schema.ACLRule
 }
 
 // IsMatch returns true if all elements of an AccessControlRule match the object and subject.
@@ -67,6 +78,22 @@ func (acr *AccessControlRule) IsMatch(subject Subject, object Object) (match boo
 	return true
 }
 
+// GetPolicy returns the policy of the AccessControlRule for the subject. A two factor policy only requires one factor
+// when the subject originates from one of the trusted networks of the rule.
+func (acr *AccessControlRule) GetPolicy(subject Subject) (policy Level) {
+	if acr.Policy != TwoFactor {
+		return acr.Policy
+	}
+
+	for _, network := range acr.TrustedNetworks {
+		if network.Contains(subject.IP) {
+			return OneFactor
+		}
+	}
+
+	return acr.Policy
+}
+
 func isMatchForDomains(subject Subject, object Object, acl *AccessControlRule) (match bool) {
 	// If there are no domains in this rule then the domain condition is a match.
 	if len(acl.Domains) == 0 {
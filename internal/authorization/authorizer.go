@@ -1,6 +1,10 @@
 package authorization
 
 import (
+	"net"
+	"strings"
+	"sync"
+
 	"github.com/authelia/authelia/internal/configuration/schema"
 	"github.com/authelia/authelia/internal/logging"
 )
@@ -8,7 +12,11 @@ import (
 // Authorizer the component in charge of checking whether a user can access a given resource.
 type Authorizer struct {
 	defaultPolicy Level
+	mutex         *sync.RWMutex
 	rules         []*AccessControlRule
+	roleMappings  []*RoleMapping
+	proxyRules    []*ProxyAuthenticationRule
+	redirections  []*DefaultRedirectionRule
 	configuration *schema.Configuration
 }
 
@@ -16,18 +24,38 @@ type Authorizer struct {
 func NewAuthorizer(configuration *schema.Configuration) *Authorizer {
 	return &Authorizer{
 		defaultPolicy: PolicyToLevel(configuration.AccessControl.DefaultPolicy),
+		mutex:         &sync.RWMutex{},
 		rules:         NewAccessControlRules(configuration.AccessControl),
+		roleMappings:  NewRoleMappings(configuration.AccessControl),
+		proxyRules:    NewProxyAuthenticationRules(configuration),
+		redirections:  NewDefaultRedirectionRules(configuration.Redirection),
 		configuration: configuration,
 	}
 }
 
+// SetRules replaces the access control rules by the rules of the given access control configuration.
+func (p *Authorizer) SetRules(configuration schema.AccessControlConfiguration) {
+	rules := NewAccessControlRules(configuration)
+
+	p.mutex.Lock()
+	p.rules = rules
+	p.mutex.Unlock()
+}
+
+func (p *Authorizer) getRules() []*AccessControlRule {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.rules
+}
+
 // IsSecondFactorEnabled return true if at least one policy is set to second factor.
-func (p Authorizer) IsSecondFactorEnabled() bool {
+func (p *Authorizer) IsSecondFactorEnabled() bool {
 	if p.defaultPolicy == TwoFactor {
 		return true
 	}
 
-	for _, rule := range p.rules {
+	for _, rule := range p.getRules() {
 		if rule.Policy == TwoFactor {
 			return true
 		}
@@ -45,17 +73,24 @@ func (p Authorizer) IsSecondFactorEnabled() bool {
 }
 
 // GetRequiredLevel retrieve the required level of authorization to access the object.
-func (p Authorizer) GetRequiredLevel(subject Subject, object Object) Level {
+func (p *Authorizer) GetRequiredLevel(subject Subject, object Object) Level {
 	logger := logging.Logger()
 
 	logger.Debugf("Check authorization of subject %s and object %s (method %s).",
 		subject.String(), object.String(), object.Method)
 
-	for _, rule := range p.rules {
+	for _, rule := range p.getRules() {
 		if rule.IsMatch(subject, object) {
 			logger.Tracef(traceFmtACLHitMiss, "HIT", rule.Position, subject.String(), object.String(), object.Method)
 
-			return rule.Policy
+			policy := rule.GetPolicy(subject)
+
+			if policy != rule.Policy {
+				logger.Debugf("Subject %s originates from a trusted network of rule %d, the one factor policy applies.",
+					subject.String(), rule.Position)
+			}
+
+			return policy
 		}
 
 		logger.Tracef(traceFmtACLHitMiss, "MISS", rule.Position, subject.String(), object.String(), object.Method)
@@ -66,3 +101,85 @@ func (p Authorizer) GetRequiredLevel(subject Subject, object Object) Level {
 
 	return p.defaultPolicy
 }
+
+// IsAccessGrantPermitted returns true when the first rule matching the subject and the object permits the access
+// grants. The default policy never permits them.
+func (p *Authorizer) IsAccessGrantPermitted(subject Subject, object Object) bool {
+	for _, rule := range p.getRules() {
+		if rule.IsMatch(subject, object) {
+			return rule.AccessGrants
+		}
+	}
+
+	return false
+}
+
+// GetSubjectRules retrieve the rules naming the subject or one of their groups in their subjects. The rules without
+// subjects, which apply to every user, aren't returned.
+func (p *Authorizer) GetSubjectRules(subject Subject) (rules []*AccessControlRule) {
+	for _, rule := range p.getRules() {
+		if len(rule.Subjects) != 0 && isMatchForSubjects(subject, rule) {
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules
+}
+
+// GetRoleHeaders retrieve the role headers to forward to the application of the object. When several role mappings
+// matching the object set the same header, the first one applies. The mappings resulting in an empty role are ignored.
+func (p Authorizer) GetRoleHeaders(subject Subject, object Object) (headers []RoleHeader) {
+	for _, mapping := range p.roleMappings {
+		if !mapping.IsMatch(subject, object) {
+			continue
+		}
+
+		if isRoleHeaderSet(headers, mapping.Header) {
+			continue
+		}
+
+		if role := mapping.GetRole(subject); role != "" {
+			headers = append(headers, RoleHeader{Name: mapping.Header, Value: role})
+		}
+	}
+
+	return headers
+}
+
+func isRoleHeaderSet(headers []RoleHeader, name string) bool {
+	for _, header := range headers {
+		if strings.EqualFold(header.Name, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetProxySecrets retrieve the shared secrets one of which the reverse proxy with the given source IP must send. The
+// first rule matching the source IP applies, the secret isn't required when no rule matches.
+func (p Authorizer) GetProxySecrets(ip net.IP) (secrets []string, required bool) {
+	for _, rule := range p.proxyRules {
+		if rule.IsMatch(ip) {
+			return rule.Secrets, true
+		}
+	}
+
+	return nil, false
+}
+
+// GetDefaultRedirectionURL retrieve the default redirection URL of the subject, the URL of the first rule matching the
+// subject applies. It returns false when no rule matches, the global default redirection URL applies then.
+func (p Authorizer) GetDefaultRedirectionURL(subject Subject) (url string, ok bool) {
+	if subject.IsAnonymous() {
+		return "", false
+	}
+
+	for _, rule := range p.redirections {
+		if rule.IsMatch(subject) {
+			return rule.URL, true
+		}
+	}
+
+	return "", false
+}
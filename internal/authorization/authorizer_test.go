@@ -58,6 +58,11 @@ func (b *AuthorizerTesterBuilder) WithDefaultPolicy(policy string) *AuthorizerTe
 	return b
 }
 
+func (b *AuthorizerTesterBuilder) WithNetwork(network schema.ACLNetwork) *AuthorizerTesterBuilder {
+	b.config.Networks = append(b.config.Networks, network)
+	return b
+}
+
 func (b *AuthorizerTesterBuilder) WithRule(rule schema.ACLRule) *AuthorizerTesterBuilder {
 	b.config.Rules = append(b.config.Rules, rule)
 	return b
@@ -314,6 +319,16 @@ func (s *AuthorizerSuite) TestShouldCheckIPMatching() {
 			Policy:   twoFactor,
 			Networks: []string{"fec0::1"},
 		}).
+		WithRule(schema.ACLRule{
+			Domains:  []string{"ipv6-zone.example.com"},
+			Policy:   twoFactor,
+			Networks: []string{"fec0::%eth0/64"},
+		}).
+		WithRule(schema.ACLRule{
+			Domains:  []string{"mapped.example.com"},
+			Policy:   twoFactor,
+			Networks: []string{"::ffff:10.0.0.0/104"},
+		}).
 		Build()
 
 	tester.CheckAuthorizations(s.T(), John, "https://protected.example.com/", "GET", Bypass)
@@ -328,6 +343,72 @@ func (s *AuthorizerSuite) TestShouldCheckIPMatching() {
 	tester.CheckAuthorizations(s.T(), Sam, "https://ipv6-alt.example.com/", "GET", TwoFactor)
 	tester.CheckAuthorizations(s.T(), Sally, "https://ipv6.example.com/", "GET", TwoFactor)
 	tester.CheckAuthorizations(s.T(), Sam, "https://ipv6.example.com/", "GET", TwoFactor)
+
+	tester.CheckAuthorizations(s.T(), Sally, "https://ipv6-zone.example.com/", "GET", TwoFactor)
+	tester.CheckAuthorizations(s.T(), John, "https://ipv6-zone.example.com/", "GET", Denied)
+
+	tester.CheckAuthorizations(s.T(), John, "https://mapped.example.com/", "GET", TwoFactor)
+	tester.CheckAuthorizations(s.T(), Subject{Username: "john", IP: net.ParseIP("::ffff:10.0.0.8")}, "https://mapped.example.com/", "GET", TwoFactor)
+	tester.CheckAuthorizations(s.T(), Sally, "https://mapped.example.com/", "GET", Denied)
+}
+
+func (s *AuthorizerSuite) TestShouldCheckTrustedNetworks() {
+	tester := NewAuthorizerBuilder().
+		WithDefaultPolicy(deny).
+		WithNetwork(schema.ACLNetwork{
+			Name:     "internal",
+			Networks: []string{"10.0.0.0/8"},
+		}).
+		WithRule(schema.ACLRule{
+			Domains:         []string{"protected.example.com"},
+			Policy:          twoFactor,
+			TrustedNetworks: []string{"internal"},
+		}).
+		WithRule(schema.ACLRule{
+			Domains:         []string{"ipv6.example.com"},
+			Policy:          twoFactor,
+			TrustedNetworks: []string{"fec0::1"},
+		}).
+		WithRule(schema.ACLRule{
+			Domains:         []string{"bypass.example.com"},
+			Policy:          bypass,
+			TrustedNetworks: []string{"10.0.0.0/8"},
+		}).
+		Build()
+
+	tester.CheckAuthorizations(s.T(), John, "https://protected.example.com/", "GET", OneFactor)
+	tester.CheckAuthorizations(s.T(), Bob, "https://protected.example.com/", "GET", OneFactor)
+	tester.CheckAuthorizations(s.T(), AnonymousUser, "https://protected.example.com/", "GET", TwoFactor)
+	tester.CheckAuthorizations(s.T(), Sam, "https://protected.example.com/", "GET", TwoFactor)
+
+	tester.CheckAuthorizations(s.T(), Sam, "https://ipv6.example.com/", "GET", OneFactor)
+	tester.CheckAuthorizations(s.T(), Sally, "https://ipv6.example.com/", "GET", TwoFactor)
+
+	tester.CheckAuthorizations(s.T(), John, "https://bypass.example.com/", "GET", Bypass)
+}
+
+func (s *AuthorizerSuite) TestShouldCheckAccessGrantPermission() {
+	tester := NewAuthorizerBuilder().
+		WithDefaultPolicy(twoFactor).
+		WithRule(schema.ACLRule{
+			Domains:   []string{"protected.example.com"},
+			Resources: []string{"^/private/.*$"},
+			Policy:    twoFactor,
+		}).
+		WithRule(schema.ACLRule{
+			Domains:      []string{"protected.example.com"},
+			Policy:       oneFactor,
+			AccessGrants: true,
+		}).
+		Build()
+
+	object := func(path string) Object {
+		return Object{Scheme: "https", Domain: "protected.example.com", Path: path, Method: "GET"}
+	}
+
+	s.Assert().True(tester.IsAccessGrantPermitted(AnonymousUser, object("/documents/1")))
+	s.Assert().False(tester.IsAccessGrantPermitted(AnonymousUser, object("/private/1")))
+	s.Assert().False(tester.IsAccessGrantPermitted(AnonymousUser, Object{Scheme: "https", Domain: "other.example.com", Path: "/", Method: "GET"}))
 }
 
 func (s *AuthorizerSuite) TestShouldCheckMethodMatching() {
@@ -596,3 +677,76 @@ func TestAuthorizerIsSecondFactorEnabledRuleWithOIDC(t *testing.T) {
 
 	assert.True(t, authorizer.IsSecondFactorEnabled())
 }
+
+func TestAuthorizerShouldReplaceRules(t *testing.T) {
+	config := &schema.Configuration{
+		AccessControl: schema.AccessControlConfiguration{
+			DefaultPolicy: deny,
+			Rules: []schema.ACLRule{
+				{
+					Domains: []string{"example.com"},
+					Policy:  oneFactor,
+				},
+			},
+		},
+	}
+
+	authorizer := NewAuthorizer(config)
+	object := Object{Scheme: "https", Domain: "example.com", Path: "/"}
+
+	assert.Equal(t, OneFactor, authorizer.GetRequiredLevel(Subject{}, object))
+
+	accessControl := config.AccessControl
+	accessControl.Rules = []schema.ACLRule{
+		{
+			Domains: []string{"example.com"},
+			Policy:  twoFactor,
+		},
+	}
+
+	authorizer.SetRules(accessControl)
+
+	assert.Equal(t, TwoFactor, authorizer.GetRequiredLevel(Subject{}, object))
+	assert.True(t, authorizer.IsSecondFactorEnabled())
+}
+
+func TestAuthorizerShouldReturnTheRulesNamingTheSubject(t *testing.T) {
+	config := &schema.Configuration{
+		AccessControl: schema.AccessControlConfiguration{
+			DefaultPolicy: deny,
+			Rules: []schema.ACLRule{
+				{
+					Domains: []string{"public.example.com"},
+					Policy:  bypass,
+				},
+				{
+					Domains:  []string{"admin.example.com"},
+					Policy:   twoFactor,
+					Subjects: [][]string{{"group:admins"}},
+				},
+				{
+					Domains:  []string{"dev.example.com"},
+					Policy:   oneFactor,
+					Subjects: [][]string{{"user:john", "group:dev"}},
+				},
+				{
+					Domains:  []string{"secure.example.com"},
+					Policy:   twoFactor,
+					Subjects: [][]string{{"user:harry"}, {"user:john"}},
+				},
+			},
+		},
+	}
+
+	authorizer := NewAuthorizer(config)
+
+	rules := authorizer.GetSubjectRules(Subject{Username: "john", Groups: []string{"admins"}})
+
+	require.Len(t, rules, 2)
+	assert.Equal(t, 2, rules[0].Position)
+	assert.Equal(t, []string{"admin.example.com"}, rules[0].Definition.Domains)
+	assert.Equal(t, 4, rules[1].Position)
+	assert.Equal(t, twoFactor, rules[1].Definition.Policy)
+
+	assert.Len(t, authorizer.GetSubjectRules(Subject{Username: "bob"}), 0)
+}
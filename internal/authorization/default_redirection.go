@@ -0,0 +1,34 @@
+package authorization
+
+import (
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+// DefaultRedirectionRule represents the default redirection URL of the users matching the subjects.
+type DefaultRedirectionRule struct {
+	Subjects []AccessControlSubjects
+	URL      string
+}
+
+// NewDefaultRedirectionRules parses the schema default redirection URLs into default redirection rules.
+func NewDefaultRedirectionRules(config schema.RedirectionConfiguration) (rules []*DefaultRedirectionRule) {
+	for _, defaultURL := range config.DefaultURLs {
+		rules = append(rules, &DefaultRedirectionRule{
+			Subjects: schemaSubjectsToACL(defaultURL.Subjects),
+			URL:      defaultURL.URL,
+		})
+	}
+
+	return rules
+}
+
+// IsMatch returns true if the subject matches one of the subjects of the rule.
+func (r DefaultRedirectionRule) IsMatch(subject Subject) (match bool) {
+	for _, subjects := range r.Subjects {
+		if subjects.IsMatch(subject) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,40 @@
+package authorization
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+func TestShouldGetDefaultRedirectionURLOfFirstMatchingRule(t *testing.T) {
+	authorizer := NewAuthorizer(&schema.Configuration{
+		Redirection: schema.RedirectionConfiguration{
+			DefaultURLs: []schema.RedirectionDefaultURLConfiguration{
+				{URL: "https://john.example.com", Subjects: [][]string{{"user:john"}}},
+				{URL: "https://grafana.example.com", Subjects: [][]string{{"group:ops"}, {"group:sre"}}},
+				{URL: "https://admin.example.com", Subjects: [][]string{{"group:admins", "group:dev"}}},
+			},
+		},
+	})
+
+	url, ok := authorizer.GetDefaultRedirectionURL(Subject{Username: "john", Groups: []string{"ops"}})
+	assert.True(t, ok)
+	assert.Equal(t, "https://john.example.com", url)
+
+	url, ok = authorizer.GetDefaultRedirectionURL(Subject{Username: "harry", Groups: []string{"sre"}})
+	assert.True(t, ok)
+	assert.Equal(t, "https://grafana.example.com", url)
+
+	url, ok = authorizer.GetDefaultRedirectionURL(Subject{Username: "bob", Groups: []string{"admins", "dev"}})
+	assert.True(t, ok)
+	assert.Equal(t, "https://admin.example.com", url)
+
+	url, ok = authorizer.GetDefaultRedirectionURL(Subject{Username: "alice", Groups: []string{"admins"}})
+	assert.False(t, ok)
+	assert.Equal(t, "", url)
+
+	_, ok = authorizer.GetDefaultRedirectionURL(Subject{})
+	assert.False(t, ok)
+}
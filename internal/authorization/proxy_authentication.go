@@ -0,0 +1,47 @@
+package authorization
+
+import (
+	"net"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+// ProxyAuthenticationRule represents the shared secrets required from the reverse proxies of the source networks.
+type ProxyAuthenticationRule struct {
+	Networks []*net.IPNet
+	Secrets  []string
+}
+
+// NewProxyAuthenticationRules parses the schema proxy authentication rules into proxy authentication rules. The
+// networks can be network groups of the access control configuration.
+func NewProxyAuthenticationRules(config *schema.Configuration) (rules []*ProxyAuthenticationRule) {
+	if config.ProxyAuthentication == nil {
+		return nil
+	}
+
+	networksMap, networksCacheMap := parseSchemaNetworks(config.AccessControl.Networks)
+
+	for _, rule := range config.ProxyAuthentication.Rules {
+		rules = append(rules, &ProxyAuthenticationRule{
+			Networks: schemaNetworksToACL(rule.Networks, networksMap, networksCacheMap),
+			Secrets:  rule.Secrets,
+		})
+	}
+
+	return rules
+}
+
+// IsMatch returns true if the rule applies to the source IP, the rules without networks apply to all the sources.
+func (r ProxyAuthenticationRule) IsMatch(ip net.IP) (match bool) {
+	if len(r.Networks) == 0 {
+		return true
+	}
+
+	for _, network := range r.Networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,57 @@
+package authorization
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+func TestShouldGetProxySecretsOfFirstMatchingRule(t *testing.T) {
+	authorizer := NewAuthorizer(&schema.Configuration{
+		AccessControl: schema.AccessControlConfiguration{
+			Networks: []schema.ACLNetwork{{Name: "proxies", Networks: []string{"10.0.0.0/8", "192.168.1.10"}}},
+		},
+		ProxyAuthentication: &schema.ProxyAuthenticationConfiguration{
+			Rules: []schema.ProxyAuthenticationRule{
+				{Networks: []string{"proxies"}, Secrets: []string{"current", "previous"}},
+				{Networks: []string{"172.16.0.0/12"}, Secrets: []string{"other"}},
+			},
+		},
+	})
+
+	secrets, required := authorizer.GetProxySecrets(net.ParseIP("10.1.2.3"))
+	assert.True(t, required)
+	assert.Equal(t, []string{"current", "previous"}, secrets)
+
+	secrets, required = authorizer.GetProxySecrets(net.ParseIP("192.168.1.10"))
+	assert.True(t, required)
+	assert.Equal(t, []string{"current", "previous"}, secrets)
+
+	secrets, required = authorizer.GetProxySecrets(net.ParseIP("172.17.0.1"))
+	assert.True(t, required)
+	assert.Equal(t, []string{"other"}, secrets)
+
+	secrets, required = authorizer.GetProxySecrets(net.ParseIP("127.0.0.1"))
+	assert.False(t, required)
+	assert.Nil(t, secrets)
+}
+
+func TestShouldRequireProxySecretsFromAllSourcesWithoutNetworks(t *testing.T) {
+	authorizer := NewAuthorizer(&schema.Configuration{
+		ProxyAuthentication: &schema.ProxyAuthenticationConfiguration{
+			Rules: []schema.ProxyAuthenticationRule{
+				{Secrets: []string{"secret"}},
+			},
+		},
+	})
+
+	secrets, required := authorizer.GetProxySecrets(net.ParseIP("127.0.0.1"))
+	assert.True(t, required)
+	assert.Equal(t, []string{"secret"}, secrets)
+
+	_, required = NewAuthorizer(&schema.Configuration{}).GetProxySecrets(net.ParseIP("127.0.0.1"))
+	assert.False(t, required)
+}
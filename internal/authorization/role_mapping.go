@@ -0,0 +1,59 @@
+package authorization
+
+import (
+	"strings"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+// RoleMapping translates the groups of the users into the value of the role header of the applications.
+type RoleMapping struct {
+	Domains []AccessControlDomain
+	Header  string
+	Default string
+	Roles   []schema.ACLRole
+}
+
+// RoleHeader is a role header to forward to an application.
+type RoleHeader struct {
+	Name  string
+	Value string
+}
+
+// NewRoleMappings parses the schema role mappings into role mappings.
+func NewRoleMappings(config schema.AccessControlConfiguration) (mappings []*RoleMapping) {
+	for _, mapping := range config.RoleMappings {
+		mappings = append(mappings, &RoleMapping{
+			Domains: schemaDomainsToACL(mapping.Domains),
+			Header:  mapping.Header,
+			Default: mapping.Default,
+			Roles:   mapping.Roles,
+		})
+	}
+
+	return mappings
+}
+
+// IsMatch returns true if the role mapping applies to the object domain.
+func (m RoleMapping) IsMatch(subject Subject, object Object) (match bool) {
+	for _, domain := range m.Domains {
+		if domain.IsMatch(subject, object) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetRole returns the role of the first group of the mapping the subject is a member of, or the default role.
+func (m RoleMapping) GetRole(subject Subject) (role string) {
+	for _, r := range m.Roles {
+		for _, group := range subject.Groups {
+			if strings.EqualFold(r.Group, group) {
+				return r.Role
+			}
+		}
+	}
+
+	return m.Default
+}
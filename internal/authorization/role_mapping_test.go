@@ -0,0 +1,55 @@
+package authorization
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+func TestShouldGetRoleHeadersOfMatchingDomains(t *testing.T) {
+	authorizer := NewAuthorizerTester(schema.AccessControlConfiguration{
+		DefaultPolicy: "two_factor",
+		RoleMappings: []schema.ACLRoleMapping{
+			{
+				Domains: []string{"grafana.example.com"},
+				Header:  "Remote-Role",
+				Default: "Viewer",
+				Roles: []schema.ACLRole{
+					{Group: "admins", Role: "Admin"},
+					{Group: "dev", Role: "Editor"},
+				},
+			},
+			{
+				Domains: []string{"*.example.com"},
+				Header:  "Remote-Role",
+				Roles:   []schema.ACLRole{{Group: "dev", Role: "developer"}},
+			},
+			{
+				Domains: []string{"*.example.com"},
+				Header:  "X-Team",
+				Roles:   []schema.ACLRole{{Group: "dev", Role: "platform"}},
+			},
+		},
+	})
+
+	ip := net.ParseIP("127.0.0.1")
+	grafana := Object{Scheme: "https", Domain: "grafana.example.com", Path: "/"}
+	wiki := Object{Scheme: "https", Domain: "wiki.example.com", Path: "/"}
+
+	assert.Equal(t, []RoleHeader{{Name: "Remote-Role", Value: "Admin"}},
+		authorizer.GetRoleHeaders(Subject{Username: "john", Groups: []string{"users", "Admins"}, IP: ip}, grafana))
+
+	assert.Equal(t, []RoleHeader{{Name: "Remote-Role", Value: "Editor"}, {Name: "X-Team", Value: "platform"}},
+		authorizer.GetRoleHeaders(Subject{Username: "harry", Groups: []string{"dev"}, IP: ip}, grafana))
+
+	assert.Equal(t, []RoleHeader{{Name: "Remote-Role", Value: "Viewer"}},
+		authorizer.GetRoleHeaders(Subject{Username: "bob", Groups: []string{"users"}, IP: ip}, grafana))
+
+	assert.Equal(t, []RoleHeader{{Name: "Remote-Role", Value: "developer"}, {Name: "X-Team", Value: "platform"}},
+		authorizer.GetRoleHeaders(Subject{Username: "harry", Groups: []string{"dev"}, IP: ip}, wiki))
+
+	assert.Nil(t, authorizer.GetRoleHeaders(Subject{Username: "bob", Groups: []string{"users"}, IP: ip}, wiki))
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/authelia/authelia/internal/authentication"
 	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/utils"
 )
 
 // PolicyToLevel converts a string policy to int authorization level.
@@ -89,7 +90,7 @@ func schemaNetworksToACL(networkRules []string, networksMap map[string][]*net.IP
 			if _, ok := networksCacheMap[network]; ok {
 				networks = append(networks, networksCacheMap[network])
 			} else {
-				cidr, err := parseNetwork(network)
+				cidr, err := utils.ParseNetwork(network)
 				if err == nil {
 					networks = append(networks, cidr)
 					networksCacheMap[cidr.String()] = cidr
@@ -117,7 +118,7 @@ func parseSchemaNetworks(schemaNetworks []schema.ACLNetwork) (networksMap map[st
 		var networks []*net.IPNet
 
 		for _, networkRule := range aclNetwork.Networks {
-			cidr, err := parseNetwork(networkRule)
+			cidr, err := utils.ParseNetwork(networkRule)
 			if err == nil {
 				networks = append(networks, cidr)
 				networksCacheMap[cidr.String()] = cidr
@@ -136,21 +137,6 @@ func parseSchemaNetworks(schemaNetworks []schema.ACLNetwork) (networksMap map[st
 	return networksMap, networksCacheMap
 }
 
-func parseNetwork(networkRule string) (cidr *net.IPNet, err error) {
-	if !strings.Contains(networkRule, "/") {
-		ip := net.ParseIP(networkRule)
-		if ip.To4() != nil {
-			_, cidr, err = net.ParseCIDR(networkRule + "/32")
-		} else {
-			_, cidr, err = net.ParseCIDR(networkRule + "/128")
-		}
-	} else {
-		_, cidr, err = net.ParseCIDR(networkRule)
-	}
-
-	return cidr, err
-}
-
 func schemaSubjectsToACL(subjectRules [][]string) (subjects []AccessControlSubjects) {
 	for _, subjectRule := range subjectRules {
 		subject := AccessControlSubjects{}
@@ -0,0 +1,291 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+
+	"github.com/authelia/authelia/internal/storage"
+)
+
+const (
+	magic      = "AUTHELIA-BACKUP-1"
+	saltLength = 16
+
+	manifestFile               = "manifest.json"
+	storageFile                = "storage.json"
+	configurationFile          = "configuration.yml"
+	effectiveConfigurationFile = "configuration.effective.json"
+	issuerPrivateKeyFile       = "oidc_issuer_private_key.pem"
+
+	// The parameters of the argon2id key derivation.
+	kdfTime    = 3
+	kdfMemory  = 64 * 1024
+	kdfThreads = 4
+	keyLength  = 32
+)
+
+var (
+	// ErrNotABackup is returned when the file is not a backup.
+	ErrNotABackup = errors.New("file is not a backup")
+
+	// ErrIntegrity is returned when the backup can't be decrypted or when its content doesn't match the manifest,
+	// which means either the passphrase is wrong or the backup has been tampered with.
+	ErrIntegrity = errors.New("backup integrity verification failed, either the passphrase is wrong or the backup is corrupted")
+)
+
+// Archive is the content of a backup.
+type Archive struct {
+	Manifest Manifest
+
+	// Storage is the export of the database.
+	Storage *storage.Export
+
+	// Configuration is the configuration file as written by the administrator.
+	Configuration []byte
+
+	// EffectiveConfiguration is the configuration once the defaults and the environment variables have been applied.
+	EffectiveConfiguration []byte
+
+	// IssuerPrivateKey is the private key of the OpenID Connect issuer, it's empty when OpenID Connect is disabled.
+	IssuerPrivateKey []byte
+}
+
+// Manifest describes the content of a backup.
+type Manifest struct {
+	CreatedAt      time.Time         `json:"created_at"`
+	Version        string            `json:"version"`
+	SchemaVersion  int               `json:"schema_version"`
+	FilesChecksums map[string]string `json:"files_sha256"`
+}
+
+// Write writes the archive encrypted with a key derived from the passphrase.
+func Write(w io.Writer, passphrase []byte, archive *Archive) error {
+	storageData, err := json.Marshal(archive.Storage)
+	if err != nil {
+		return err
+	}
+
+	files := map[string][]byte{
+		storageFile:                storageData,
+		configurationFile:          archive.Configuration,
+		effectiveConfigurationFile: archive.EffectiveConfiguration,
+	}
+
+	if len(archive.IssuerPrivateKey) != 0 {
+		files[issuerPrivateKeyFile] = archive.IssuerPrivateKey
+	}
+
+	archive.Manifest.SchemaVersion = int(archive.Storage.SchemaVersion)
+	archive.Manifest.FilesChecksums = map[string]string{}
+
+	for name, data := range files {
+		archive.Manifest.FilesChecksums[name] = checksum(data)
+	}
+
+	manifestData, err := json.Marshal(archive.Manifest)
+	if err != nil {
+		return err
+	}
+
+	plaintext := &bytes.Buffer{}
+	gz := gzip.NewWriter(plaintext)
+	tw := tar.NewWriter(gz)
+
+	for _, name := range []string{manifestFile, storageFile, configurationFile, effectiveConfigurationFile, issuerPrivateKeyFile} {
+		data := manifestData
+
+		if name != manifestFile {
+			var ok bool
+
+			if data, ok = files[name]; !ok {
+				continue
+			}
+		}
+
+		if err = writeTarFile(tw, name, data, archive.Manifest.CreatedAt); err != nil {
+			return err
+		}
+	}
+
+	if err = tw.Close(); err != nil {
+		return err
+	}
+
+	if err = gz.Close(); err != nil {
+		return err
+	}
+
+	salt := make([]byte, saltLength)
+
+	if _, err = rand.Read(salt); err != nil {
+		return err
+	}
+
+	aead, err := newAEAD(passphrase, salt)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+
+	if _, err = rand.Read(nonce); err != nil {
+		return err
+	}
+
+	header := append([]byte(magic), salt...)
+
+	for _, chunk := range [][]byte{header, nonce, aead.Seal(nil, nonce, plaintext.Bytes(), header)} {
+		if _, err = w.Write(chunk); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Read decrypts the backup with a key derived from the passphrase and verifies its integrity.
+func Read(r io.Reader, passphrase []byte) (*Archive, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < len(magic)+saltLength || string(data[:len(magic)]) != magic {
+		return nil, ErrNotABackup
+	}
+
+	header, data := data[:len(magic)+saltLength], data[len(magic)+saltLength:]
+
+	aead, err := newAEAD(passphrase, header[len(magic):])
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < aead.NonceSize() {
+		return nil, ErrNotABackup
+	}
+
+	plaintext, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], header)
+	if err != nil {
+		return nil, ErrIntegrity
+	}
+
+	files, err := readTarFiles(plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	archive := &Archive{}
+
+	if err = json.Unmarshal(files[manifestFile], &archive.Manifest); err != nil {
+		return nil, fmt.Errorf("unable to parse the manifest: %w", err)
+	}
+
+	delete(files, manifestFile)
+
+	if len(files) != len(archive.Manifest.FilesChecksums) {
+		return nil, ErrIntegrity
+	}
+
+	for name, data := range files {
+		if expected, ok := archive.Manifest.FilesChecksums[name]; !ok || expected != checksum(data) {
+			return nil, ErrIntegrity
+		}
+	}
+
+	if err = json.Unmarshal(files[storageFile], &archive.Storage); err != nil {
+		return nil, fmt.Errorf("unable to parse the storage export: %w", err)
+	}
+
+	archive.Configuration = files[configurationFile]
+	archive.EffectiveConfiguration = files[effectiveConfigurationFile]
+	archive.IssuerPrivateKey = files[issuerPrivateKeyFile]
+
+	return archive, nil
+}
+
+// SnapshotFiles returns the files of the configuration snapshot by name.
+func (a *Archive) SnapshotFiles() map[string][]byte {
+	files := map[string][]byte{
+		configurationFile:          a.Configuration,
+		effectiveConfigurationFile: a.EffectiveConfiguration,
+	}
+
+	if len(a.IssuerPrivateKey) != 0 {
+		files[issuerPrivateKeyFile] = a.IssuerPrivateKey
+	}
+
+	return files
+}
+
+func newAEAD(passphrase, salt []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(argon2.IDKey(passphrase, salt, kdfTime, kdfMemory, kdfThreads, keyLength))
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func writeTarFile(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	header := &tar.Header{Name: name, Mode: 0600, Size: int64(len(data)), ModTime: modTime}
+
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+
+	_, err := tw.Write(data)
+
+	return err
+}
+
+func readTarFiles(data []byte) (files map[string][]byte, err error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrIntegrity
+	}
+
+	tr := tar.NewReader(gz)
+	files = map[string][]byte{}
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, ErrIntegrity
+		}
+
+		if files[header.Name], err = ioutil.ReadAll(tr); err != nil {
+			return nil, ErrIntegrity
+		}
+	}
+
+	if _, ok := files[manifestFile]; !ok {
+		return nil, ErrIntegrity
+	}
+
+	return files, nil
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/authorization"
+	"github.com/authelia/authelia/internal/review"
+	"github.com/authelia/authelia/internal/storage"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+var (
+	accessReviewConfigPath string
+	accessReviewFormat     string
+	accessReviewOutput     string
+)
+
+func init() {
+	AccessReviewCmd.Flags().StringVar(&accessReviewConfigPath, "config", "", "Configuration file")
+	AccessReviewCmd.Flags().StringVar(&accessReviewFormat, "format", review.FormatCSV, "Format of the report, either csv or json")
+	AccessReviewCmd.Flags().StringVarP(&accessReviewOutput, "output", "o", "", "File the report is written to, the standard output when not set")
+
+	if err := AccessReviewCmd.MarkFlagRequired("config"); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// AccessReviewCmd exports the access review report of the users.
+var AccessReviewCmd = &cobra.Command{
+	Use:   "access-review",
+	Short: "Export a report of the users, their groups, factors, last login and access control rules for access reviews",
+	Run:   exportAccessReview,
+	Args:  cobra.NoArgs,
+}
+
+func exportAccessReview(_ *cobra.Command, _ []string) {
+	config := readConfiguration(accessReviewConfigPath)
+
+	if accessReviewFormat != review.FormatCSV && accessReviewFormat != review.FormatJSON {
+		log.Fatalf("Unknown format %s, must be either %s or %s", accessReviewFormat, review.FormatCSV, review.FormatJSON)
+	}
+
+	certPool, errs, _ := utils.LoadX509CertPool(config.CertificatesDirectory)
+	if len(errs) > 0 {
+		log.Fatalf("Unable to load the certificates: %v", errs)
+	}
+
+	backend, err := authentication.NewUserProvider(config.AuthenticationBackend, certPool)
+	if err != nil {
+		log.Fatalf("Failed to initialize the authentication backend: %v", err)
+	}
+
+	// The break-glass accounts are reviewed like the other users.
+	var userProvider authentication.UserProvider = backend
+
+	if config.AuthenticationBackend.BreakGlass != nil {
+		userProvider = authentication.NewBreakGlassUserProvider(userProvider, config.AuthenticationBackend.BreakGlass)
+	}
+
+	storageProvider, err := storage.NewProvider(config.Storage)
+	if err != nil {
+		log.Fatalf("Failed to initialize storage provider: %v", err)
+	}
+
+	report, err := review.NewReport(storageProvider, userProvider, authorization.NewAuthorizer(config), time.Now())
+	if err != nil {
+		log.Fatalf("Unable to generate the access review report: %v", err)
+	}
+
+	var w io.Writer = os.Stdout
+
+	if accessReviewOutput != "" {
+		file, err := os.OpenFile(accessReviewOutput, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			log.Fatalf("Unable to create the access review report file: %v", err)
+		}
+
+		defer file.Close()
+
+		w = file
+	}
+
+	if err = report.Write(w, accessReviewFormat); err != nil {
+		log.Fatalf("Unable to write the access review report: %v", err)
+	}
+
+	if accessReviewOutput != "" {
+		log.Printf("Access review report of %d users written to %s.\n", len(report.Users), accessReviewOutput)
+	}
+}
@@ -0,0 +1,185 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/storage"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+const (
+	adminTokenIDLength     = 12
+	adminTokenSecretLength = 48
+)
+
+var (
+	adminTokenConfigPath  string
+	adminTokenDescription string
+	adminTokenScopes      []string
+	adminTokenExpiresIn   string
+)
+
+func init() {
+	for _, cmd := range []*cobra.Command{AdminTokenCreateCmd, AdminTokenListCmd, AdminTokenRevokeCmd} {
+		cmd.Flags().StringVar(&adminTokenConfigPath, "config", "", "Configuration file")
+
+		if err := cmd.MarkFlagRequired("config"); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	AdminTokenCreateCmd.Flags().StringVar(&adminTokenDescription, "description", "", "The description of the automation the token is created for")
+	AdminTokenCreateCmd.Flags().StringSliceVar(&adminTokenScopes, "scope", nil, fmt.Sprintf("A scope granted to the token, one of %s, can be specified multiple times", strings.Join(models.AdminAPIScopes, ", ")))
+	AdminTokenCreateCmd.Flags().StringVar(&adminTokenExpiresIn, "expires-in", "", "The duration the token is valid for such as 90d, the token never expires when empty")
+
+	for _, flag := range []string{"description", "scope"} {
+		if err := AdminTokenCreateCmd.MarkFlagRequired(flag); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	AdminTokenCmd.AddCommand(AdminTokenCreateCmd, AdminTokenListCmd, AdminTokenRevokeCmd)
+}
+
+// AdminTokenCmd admin API tokens management command.
+var AdminTokenCmd = &cobra.Command{
+	Use:   "admin-token",
+	Short: "Commands related to the management of the tokens of the admin API",
+}
+
+// AdminTokenCreateCmd creates an admin API token, only its hash is saved so it's only displayed once.
+var AdminTokenCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a token allowing automation to call the admin API",
+	Run:   createAdminToken,
+	Args:  cobra.NoArgs,
+}
+
+// AdminTokenListCmd lists the admin API tokens.
+var AdminTokenListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the tokens of the admin API",
+	Run:   listAdminTokens,
+	Args:  cobra.NoArgs,
+}
+
+// AdminTokenRevokeCmd revokes an admin API token.
+var AdminTokenRevokeCmd = &cobra.Command{
+	Use:   "revoke [id]",
+	Short: "Revoke a token of the admin API",
+	Run:   revokeAdminToken,
+	Args:  cobra.ExactArgs(1),
+}
+
+func createAdminToken(_ *cobra.Command, _ []string) {
+	for _, scope := range adminTokenScopes {
+		if !utils.IsStringInSlice(scope, models.AdminAPIScopes) {
+			log.Fatalf("Invalid scope '%s', should be one of %s", scope, strings.Join(models.AdminAPIScopes, ", "))
+		}
+	}
+
+	now := time.Now()
+
+	token := models.AdminAPIToken{
+		Description: adminTokenDescription,
+		Scopes:      adminTokenScopes,
+		CreatedAt:   now,
+	}
+
+	if adminTokenExpiresIn != "" {
+		duration, err := utils.ParseDurationString(adminTokenExpiresIn)
+		if err != nil || duration <= 0 {
+			log.Fatalf("Invalid expiration '%s', should be a positive duration such as 90d", adminTokenExpiresIn)
+		}
+
+		token.ExpiresAt = now.Add(duration)
+	}
+
+	id, err := utils.RandomSecureString(adminTokenIDLength, utils.AlphaNumericCharacters)
+	if err != nil {
+		log.Fatalf("Unable to generate token id: %v", err)
+	}
+
+	secret, err := utils.RandomSecureString(adminTokenSecretLength, utils.AlphaNumericCharacters)
+	if err != nil {
+		log.Fatalf("Unable to generate token: %v", err)
+	}
+
+	token.ID = strings.ToLower(id)
+	token.TokenHash = utils.HashSHA256FromString(secret)
+
+	config := readConfiguration(adminTokenConfigPath)
+
+	if err = newAdminTokenStorageProvider(config).SaveAdminAPIToken(token); err != nil {
+		log.Fatalf("Unable to save admin API token: %v", err)
+	}
+
+	fmt.Printf("Admin API token %s has been created, it's only displayed once:\n\n%s\n\n", token.ID, secret)
+	fmt.Printf("Provide it in the Authorization header of the requests to the admin API: Authorization: Bearer <token>\n")
+}
+
+func listAdminTokens(_ *cobra.Command, _ []string) {
+	config := readConfiguration(adminTokenConfigPath)
+
+	tokens, err := newAdminTokenStorageProvider(config).LoadAdminAPITokens()
+	if err != nil {
+		log.Fatalf("Unable to load admin API tokens: %v", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "ID\tDESCRIPTION\tSCOPES\tCREATED\tEXPIRES\tLAST USED")
+
+	for _, token := range tokens {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", token.ID, token.Description, strings.Join(token.Scopes, ","),
+			formatAdminTokenTime(token.CreatedAt, ""), formatAdminTokenTime(token.ExpiresAt, "never"), formatAdminTokenTime(token.LastUsedAt, "never"))
+	}
+
+	if err = w.Flush(); err != nil {
+		log.Fatalf("Unable to write admin API tokens: %v", err)
+	}
+}
+
+func revokeAdminToken(_ *cobra.Command, args []string) {
+	id := args[0]
+
+	config := readConfiguration(adminTokenConfigPath)
+
+	if err := newAdminTokenStorageProvider(config).DeleteAdminAPIToken(id); err != nil {
+		if err == storage.ErrNoAdminAPIToken {
+			log.Fatalf("Admin API token %s does not exist", id)
+		}
+
+		log.Fatalf("Unable to revoke admin API token %s: %v", id, err)
+	}
+
+	log.Printf("Admin API token %s has been revoked.\n", id)
+}
+
+// newAdminTokenStorageProvider creates the storage provider of the configuration, the dry-run mode isn't supported
+// since the tokens aren't written in a transaction.
+func newAdminTokenStorageProvider(config *schema.Configuration) storage.Provider {
+	provider, err := storage.NewProvider(config.Storage)
+	if err != nil {
+		log.Fatalf("Failed to initialize storage provider: %v", err)
+	}
+
+	return provider
+}
+
+func formatAdminTokenTime(t time.Time, zero string) string {
+	if t.IsZero() {
+		return zero
+	}
+
+	return t.Format(time.RFC3339)
+}
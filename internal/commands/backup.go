@@ -0,0 +1,195 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/authelia/authelia/internal/backup"
+	"github.com/authelia/authelia/internal/storage"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+const backupPassphraseEnv = "AUTHELIA_BACKUP_PASSPHRASE"
+
+var (
+	backupConfigPath     string
+	backupFile           string
+	backupPassphraseFile string
+	backupSnapshotDir    string
+)
+
+func init() {
+	for _, cmd := range []*cobra.Command{BackupCreateCmd, BackupRestoreCmd} {
+		cmd.Flags().StringVar(&backupConfigPath, "config", "", "Configuration file")
+		cmd.Flags().StringVar(&backupFile, "file", "", "The backup file")
+		cmd.Flags().StringVar(&backupPassphraseFile, "passphrase-file", "", fmt.Sprintf("File containing the passphrase of the backup, the %s environment variable is used otherwise", backupPassphraseEnv))
+
+		for _, flag := range []string{"config", "file"} {
+			if err := cmd.MarkFlagRequired(flag); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+
+	BackupRestoreCmd.Flags().StringVar(&backupSnapshotDir, "snapshot-dir", "", "Directory the configuration snapshot and the issuer private key are extracted to")
+
+	BackupCmd.AddCommand(BackupCreateCmd, BackupRestoreCmd)
+}
+
+// BackupCmd backup command.
+var BackupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Commands related to the backup of the storage and the configuration",
+}
+
+// BackupCreateCmd creates an encrypted backup of the storage, the configuration and the issuer private key.
+var BackupCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create an encrypted backup of the storage, the configuration and the OpenID Connect issuer private key",
+	Run:   createBackup,
+	Args:  cobra.NoArgs,
+}
+
+// BackupRestoreCmd restores the storage from an encrypted backup after verifying its integrity.
+var BackupRestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Verify an encrypted backup and restore the storage from it",
+	Run:   restoreBackup,
+	Args:  cobra.NoArgs,
+}
+
+func createBackup(cmd *cobra.Command, args []string) {
+	config := readConfiguration(backupConfigPath)
+	passphrase := readBackupPassphrase()
+
+	provider, err := storage.NewProvider(config.Storage)
+	if err != nil {
+		log.Fatalf("Failed to initialize storage provider: %v", err)
+	}
+
+	exporter, ok := provider.(storage.Exporter)
+	if !ok {
+		log.Fatalf("Storage provider doesn't support backups")
+	}
+
+	export, err := exporter.Export()
+	if err != nil {
+		log.Fatalf("Unable to export the storage: %v", err)
+	}
+
+	raw, err := ioutil.ReadFile(backupConfigPath)
+	if err != nil {
+		log.Fatalf("Unable to read the configuration: %v", err)
+	}
+
+	effective, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		log.Fatalf("Unable to snapshot the configuration: %v", err)
+	}
+
+	archive := &backup.Archive{
+		Manifest:               backup.Manifest{CreatedAt: time.Now().UTC(), Version: utils.Version()},
+		Storage:                export,
+		Configuration:          raw,
+		EffectiveConfiguration: effective,
+	}
+
+	if config.IdentityProviders.OIDC != nil {
+		archive.IssuerPrivateKey = []byte(config.IdentityProviders.OIDC.IssuerPrivateKey)
+	}
+
+	file, err := os.OpenFile(backupFile, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		log.Fatalf("Unable to create the backup: %v", err)
+	}
+
+	defer file.Close()
+
+	if err = backup.Write(file, passphrase, archive); err != nil {
+		log.Fatalf("Unable to write the backup: %v", err)
+	}
+
+	log.Printf("Backup of the storage schema v%d written to %s.\n", export.SchemaVersion, backupFile)
+}
+
+func restoreBackup(cmd *cobra.Command, args []string) {
+	config := readConfiguration(backupConfigPath)
+	passphrase := readBackupPassphrase()
+
+	file, err := os.Open(backupFile)
+	if err != nil {
+		log.Fatalf("Unable to open the backup: %v", err)
+	}
+
+	defer file.Close()
+
+	archive, err := backup.Read(file, passphrase)
+	if err != nil {
+		log.Fatalf("Unable to read the backup: %v", err)
+	}
+
+	log.Printf("Backup created at %s by Authelia %s has been verified.\n", archive.Manifest.CreatedAt.Format(time.RFC3339), archive.Manifest.Version)
+
+	if backupSnapshotDir != "" {
+		extractBackupSnapshot(archive)
+	}
+
+	provider, dryRun := newStorageProvider(cmd, config)
+
+	importer, ok := provider.(storage.Exporter)
+	if !ok {
+		log.Fatalf("Storage provider doesn't support backups")
+	}
+
+	if err = importer.Import(archive.Storage); err != nil {
+		log.Fatalf("Unable to restore the storage: %v", err)
+	}
+
+	if dryRun {
+		log.Println("Storage has not been restored (dry-run).")
+		return
+	}
+
+	log.Println("Storage has been restored.")
+}
+
+func extractBackupSnapshot(archive *backup.Archive) {
+	if err := os.MkdirAll(backupSnapshotDir, 0700); err != nil {
+		log.Fatalf("Unable to create the snapshot directory: %v", err)
+	}
+
+	for name, data := range archive.SnapshotFiles() {
+		if err := ioutil.WriteFile(filepath.Join(backupSnapshotDir, name), data, 0600); err != nil {
+			log.Fatalf("Unable to extract the configuration snapshot: %v", err)
+		}
+	}
+
+	log.Printf("Configuration snapshot extracted to %s.\n", backupSnapshotDir)
+}
+
+func readBackupPassphrase() []byte {
+	passphrase := os.Getenv(backupPassphraseEnv)
+
+	if backupPassphraseFile != "" {
+		data, err := ioutil.ReadFile(backupPassphraseFile)
+		if err != nil {
+			log.Fatalf("Unable to read the passphrase: %v", err)
+		}
+
+		passphrase = strings.TrimRight(string(data), "\r\n")
+	}
+
+	if passphrase == "" {
+		log.Fatalf("A passphrase is required, either with --passphrase-file or the %s environment variable", backupPassphraseEnv)
+	}
+
+	return []byte(passphrase)
+}
@@ -0,0 +1,138 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/storage"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+var breakGlassConfigPath string
+
+func init() {
+	for _, cmd := range []*cobra.Command{BreakGlassListCmd, BreakGlassResetCmd} {
+		cmd.Flags().StringVar(&breakGlassConfigPath, "config", "", "Configuration file")
+
+		if err := cmd.MarkFlagRequired("config"); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	BreakGlassCmd.AddCommand(BreakGlassListCmd, BreakGlassResetCmd)
+}
+
+// BreakGlassCmd break-glass accounts management command.
+var BreakGlassCmd = &cobra.Command{
+	Use:   "break-glass",
+	Short: "Commands related to the management of the break-glass accounts",
+}
+
+// BreakGlassListCmd lists the break-glass accounts and the state of their window.
+var BreakGlassListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the break-glass accounts and whether they have been used or disabled",
+	Run:   listBreakGlassAccounts,
+	Args:  cobra.NoArgs,
+}
+
+// BreakGlassResetCmd resets the window of a break-glass account so it can be used again.
+var BreakGlassResetCmd = &cobra.Command{
+	Use:   "reset [username]",
+	Short: "Reset a break-glass account so its window opens again on its next use",
+	Run:   resetBreakGlassAccount,
+	Args:  cobra.ExactArgs(1),
+}
+
+func listBreakGlassAccounts(_ *cobra.Command, _ []string) {
+	config, provider := readBreakGlassConfiguration()
+
+	window, err := utils.ParseDurationString(config.AuthenticationBackend.BreakGlass.Window)
+	if err != nil {
+		log.Fatalf("Unable to parse the break-glass window: %v", err)
+	}
+
+	activations, err := provider.LoadBreakGlassActivations()
+	if err != nil {
+		log.Fatalf("Unable to load break-glass activations: %v", err)
+	}
+
+	activatedAt := make(map[string]time.Time, len(activations))
+
+	for _, activation := range activations {
+		activatedAt[activation.Username] = activation.ActivatedAt
+	}
+
+	now := time.Now()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "USERNAME\tSTATUS\tFIRST USED\tDISABLED AT")
+
+	for _, account := range config.AuthenticationBackend.BreakGlass.Accounts {
+		at, ok := activatedAt[account.Username]
+
+		switch {
+		case !ok:
+			fmt.Fprintf(w, "%s\tunused\t-\t-\n", account.Username)
+		case now.Before(at.Add(window)):
+			fmt.Fprintf(w, "%s\tin use\t%s\t%s\n", account.Username, at.Format(time.RFC3339), at.Add(window).Format(time.RFC3339))
+		default:
+			fmt.Fprintf(w, "%s\tdisabled\t%s\t%s\n", account.Username, at.Format(time.RFC3339), at.Add(window).Format(time.RFC3339))
+		}
+	}
+
+	if err = w.Flush(); err != nil {
+		log.Fatalf("Unable to write break-glass accounts: %v", err)
+	}
+}
+
+func resetBreakGlassAccount(_ *cobra.Command, args []string) {
+	config, provider := readBreakGlassConfiguration()
+
+	username := ""
+
+	for _, account := range config.AuthenticationBackend.BreakGlass.Accounts {
+		if strings.EqualFold(account.Username, args[0]) {
+			username = account.Username
+		}
+	}
+
+	if username == "" {
+		log.Fatalf("Break-glass account %s does not exist", args[0])
+	}
+
+	if err := provider.DeleteBreakGlassActivation(username); err != nil {
+		if err == storage.ErrNoBreakGlassActivation {
+			log.Fatalf("Break-glass account %s has not been used since its last reset", username)
+		}
+
+		log.Fatalf("Unable to reset break-glass account %s: %v", username, err)
+	}
+
+	log.Printf("Break-glass account %s has been reset, its window opens again on its next use.\n", username)
+}
+
+// readBreakGlassConfiguration reads the configuration, which must have break-glass accounts, and creates its storage
+// provider.
+func readBreakGlassConfiguration() (*schema.Configuration, storage.Provider) {
+	config := readConfiguration(breakGlassConfigPath)
+
+	if config.AuthenticationBackend.BreakGlass == nil {
+		log.Fatalf("No break-glass account is configured in authentication_backend.break_glass")
+	}
+
+	provider, err := storage.NewProvider(config.Storage)
+	if err != nil {
+		log.Fatalf("Failed to initialize storage provider: %v", err)
+	}
+
+	return config, provider
+}
@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/authelia/authelia/internal/notification"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+var (
+	notifierConfigPath string
+	notifierRecipient  string
+)
+
+func init() {
+	NotifierTestCmd.Flags().StringVar(&notifierConfigPath, "config", "", "Configuration file")
+	NotifierTestCmd.Flags().StringVar(&notifierRecipient, "to", "", "The recipient of the test notification")
+
+	for _, flag := range []string{"config", "to"} {
+		if err := NotifierTestCmd.MarkFlagRequired(flag); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	NotifierCmd.AddCommand(NotifierTestCmd)
+}
+
+// NotifierCmd notifier command.
+var NotifierCmd = &cobra.Command{
+	Use:   "notifier",
+	Short: "Commands related to the notifier",
+}
+
+// NotifierTestCmd checks the notifier configuration and sends a test notification.
+var NotifierTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Check the notifier configuration and send a test notification",
+	Run:   testNotifier,
+	Args:  cobra.NoArgs,
+}
+
+func testNotifier(cmd *cobra.Command, args []string) {
+	config := readConfiguration(notifierConfigPath)
+
+	certPool, errs, _ := utils.LoadX509CertPool(config.CertificatesDirectory)
+	if len(errs) > 0 {
+		log.Fatalf("Unable to load the certificates: %v", errs)
+	}
+
+	// The notification is sent synchronously so the errors are reported, the queue is bypassed.
+	notifier, err := notification.NewNotifier(*config.Notifier, certPool)
+	if err != nil {
+		log.Fatalf("Failed to initialize notifier: %v", err)
+	}
+
+	if _, err = notifier.StartupCheck(); err != nil {
+		log.Fatalf("Notifier startup check failed: %v", err)
+	}
+
+	log.Println("Notifier startup check succeeded.")
+
+	body := fmt.Sprintf("This is a test notification sent by Authelia %s on %s.", utils.Version(), time.Now().Format(time.RFC1123))
+
+	if err = notifier.Send(notifierRecipient, "Test Notification", body, ""); err != nil {
+		log.Fatalf("Unable to send the test notification to %s: %v", notifierRecipient, err)
+	}
+
+	log.Printf("Test notification sent to %s.\n", notifierRecipient)
+}
@@ -0,0 +1,120 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+var (
+	oidcClientID           string
+	oidcClientDescription  string
+	oidcClientPolicy       string
+	oidcClientRedirectURIs []string
+	oidcClientScopes       []string
+	oidcClientSecretLength int
+	oidcClientSHA512       bool
+)
+
+func init() {
+	OIDCNewClientCmd.Flags().StringVar(&oidcClientID, "id", "", "The client id")
+	OIDCNewClientCmd.Flags().StringVar(&oidcClientDescription, "description", "", "The client description displayed to users")
+	OIDCNewClientCmd.Flags().StringVar(&oidcClientPolicy, "policy", schema.DefaultOpenIDConnectClientConfiguration.Policy, "The authorization policy of the client, either one_factor or two_factor")
+	OIDCNewClientCmd.Flags().StringSliceVar(&oidcClientRedirectURIs, "redirect-uri", nil, "A redirect URI of the client, can be specified multiple times")
+	OIDCNewClientCmd.Flags().StringSliceVar(&oidcClientScopes, "scope", schema.DefaultOpenIDConnectClientConfiguration.Scopes, "A scope the client is allowed to request, can be specified multiple times")
+	OIDCNewClientCmd.Flags().IntVar(&oidcClientSecretLength, "secret-length", 64, "The length of the generated client secret")
+	OIDCNewClientCmd.Flags().BoolVar(&oidcClientSHA512, "sha512", false, "Hash the client secret with sha512 instead of argon2id")
+
+	if err := OIDCNewClientCmd.MarkFlagRequired("id"); err != nil {
+		log.Fatal(err)
+	}
+
+	OIDCCmd.AddCommand(OIDCNewClientCmd)
+}
+
+// oidcClientTemplate is the YAML representation of a schema.OpenIDConnectClientConfiguration.
+type oidcClientTemplate struct {
+	ID                       string   `yaml:"id"`
+	Description              string   `yaml:"description,omitempty"`
+	Secret                   string   `yaml:"secret"`
+	Policy                   string   `yaml:"authorization_policy"`
+	RedirectURIs             []string `yaml:"redirect_uris"`
+	Scopes                   []string `yaml:"scopes"`
+	GrantTypes               []string `yaml:"grant_types"`
+	ResponseTypes            []string `yaml:"response_types"`
+	ResponseModes            []string `yaml:"response_modes"`
+	UserinfoSigningAlgorithm string   `yaml:"userinfo_signing_algorithm"`
+}
+
+func newOIDCClient(cmd *cobra.Command, args []string) {
+	if oidcClientPolicy != "one_factor" && oidcClientPolicy != "two_factor" {
+		log.Fatalf("Invalid policy '%s', should be either 'one_factor' or 'two_factor'", oidcClientPolicy)
+	}
+
+	if len(oidcClientRedirectURIs) == 0 {
+		log.Fatal("At least one redirect URI must be provided")
+	}
+
+	for _, redirectURI := range oidcClientRedirectURIs {
+		if err := utils.IsStringAbsURL(redirectURI); err != nil {
+			log.Fatalf("Invalid redirect URI: %v", err)
+		}
+	}
+
+	secret, err := utils.RandomSecureString(oidcClientSecretLength, utils.AlphaNumericCharacters)
+	if err != nil {
+		log.Fatalf("Unable to generate client secret: %v", err)
+	}
+
+	algorithm, iterations := authentication.HashingAlgorithmArgon2id, schema.DefaultPasswordConfiguration.Iterations
+	if oidcClientSHA512 {
+		algorithm, iterations = authentication.HashingAlgorithmSHA512, schema.DefaultPasswordSHA512Configuration.Iterations
+	}
+
+	hash, err := authentication.HashPassword(secret, "", algorithm, iterations,
+		schema.DefaultPasswordConfiguration.Memory*1024, schema.DefaultPasswordConfiguration.Parallelism,
+		schema.DefaultPasswordConfiguration.KeyLength, schema.DefaultPasswordConfiguration.SaltLength)
+	if err != nil {
+		log.Fatalf("Unable to hash client secret: %v", err)
+	}
+
+	client := oidcClientTemplate{
+		ID:                       oidcClientID,
+		Description:              oidcClientDescription,
+		Secret:                   hash,
+		Policy:                   oidcClientPolicy,
+		RedirectURIs:             oidcClientRedirectURIs,
+		Scopes:                   oidcClientScopes,
+		GrantTypes:               schema.DefaultOpenIDConnectClientConfiguration.GrantTypes,
+		ResponseTypes:            schema.DefaultOpenIDConnectClientConfiguration.ResponseTypes,
+		ResponseModes:            schema.DefaultOpenIDConnectClientConfiguration.ResponseModes,
+		UserinfoSigningAlgorithm: schema.DefaultOpenIDConnectClientConfiguration.UserinfoSigningAlgorithm,
+	}
+
+	out, err := yaml.Marshal(map[string][]oidcClientTemplate{"clients": {client}})
+	if err != nil {
+		log.Fatalf("Unable to marshal client configuration: %v", err)
+	}
+
+	fmt.Printf("Client Secret: %s\n\n", secret)
+	fmt.Printf("Configuration (add to identity_providers.oidc):\n\n%s", out)
+}
+
+// OIDCCmd OpenID Connect helper command.
+var OIDCCmd = &cobra.Command{
+	Use:   "oidc",
+	Short: "Commands related to OpenID Connect configuration",
+}
+
+// OIDCNewClientCmd OpenID Connect client template generation command.
+var OIDCNewClientCmd = &cobra.Command{
+	Use:   "new-client",
+	Short: "Generate a client secret and a configuration block for a new OpenID Connect client",
+	Run:   newOIDCClient,
+}
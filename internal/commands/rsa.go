@@ -10,16 +10,20 @@ import (
 	"github.com/authelia/authelia/internal/utils"
 )
 
-var rsaTargetDirectory string
+var (
+	rsaTargetDirectory string
+	rsaKeyBits         int
+)
 
 func init() {
 	RSAGenerateCmd.PersistentFlags().StringVar(&rsaTargetDirectory, "dir", "", "Target directory where the keypair will be stored")
+	RSAGenerateCmd.PersistentFlags().IntVarP(&rsaKeyBits, "bits", "b", 2048, "Size of the RSA key to generate")
 
 	RSACmd.AddCommand(RSAGenerateCmd)
 }
 
 func generateRSAKeypair(cmd *cobra.Command, args []string) {
-	privateKey, publicKey := utils.GenerateRsaKeyPair(2048)
+	privateKey, publicKey := utils.GenerateRsaKeyPair(rsaKeyBits)
 
 	keyPath := path.Join(rsaTargetDirectory, "key.pem")
 	keyOut, err := os.OpenFile(keyPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
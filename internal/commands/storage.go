@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/authelia/authelia/internal/configuration"
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/storage"
+)
+
+// readConfiguration reads the configuration and exits when it's invalid.
+func readConfiguration(path string) *schema.Configuration {
+	config, errs := configuration.Read(path)
+	if len(errs) != 0 {
+		errors := ""
+		for _, err := range errs {
+			errors += fmt.Sprintf("\t%s\n", err.Error())
+		}
+
+		log.Fatalf("Errors occurred parsing configuration:\n%s", errors)
+	}
+
+	return config
+}
+
+// newStorageProvider creates the storage provider of the configuration, it's a dry-run one when the global dry-run
+// flag is set.
+func newStorageProvider(cmd *cobra.Command, config *schema.Configuration) (provider storage.Provider, dryRun bool) {
+	var err error
+
+	dryRun, _ = cmd.Flags().GetBool("dry-run")
+
+	if dryRun {
+		provider, err = storage.NewDryRunProvider(config.Storage, os.Stdout)
+	} else {
+		provider, err = storage.NewProvider(config.Storage)
+	}
+
+	if err != nil {
+		log.Fatalf("Failed to initialize storage provider: %v", err)
+	}
+
+	return provider, dryRun
+}
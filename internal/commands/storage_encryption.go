@@ -0,0 +1,183 @@
+package commands
+
+import (
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/storage"
+)
+
+var (
+	storageEncryptionNewKey    string
+	storageEncryptionBatchSize int
+)
+
+func init() {
+	for _, cmd := range []*cobra.Command{StorageEncryptionEncryptCmd, StorageEncryptionChangeKeyCmd, StorageEncryptionDecryptCmd,
+		StorageEncryptionRotateKeyCmd, StorageEncryptionCheckCmd} {
+		cmd.Flags().StringVar(&storageConfigPath, "config", "", "Configuration file")
+
+		if err := cmd.MarkFlagRequired("config"); err != nil {
+			log.Fatal(err)
+		}
+
+		StorageEncryptionCmd.AddCommand(cmd)
+	}
+
+	StorageEncryptionChangeKeyCmd.Flags().StringVar(&storageEncryptionNewKey, "new-encryption-key", "", "Key the secrets are encrypted with from now on")
+
+	if err := StorageEncryptionChangeKeyCmd.MarkFlagRequired("new-encryption-key"); err != nil {
+		log.Fatal(err)
+	}
+
+	StorageEncryptionRotateKeyCmd.Flags().IntVar(&storageEncryptionBatchSize, "batch-size", 100, "Number of secrets rotated in each transaction")
+
+	StorageCmd.AddCommand(StorageEncryptionCmd)
+}
+
+// StorageEncryptionCmd storage encryption command.
+var StorageEncryptionCmd = &cobra.Command{
+	Use:   "encryption",
+	Short: "Commands related to the encryption of the secrets of the storage",
+}
+
+// StorageEncryptionEncryptCmd encrypts the secrets stored in plaintext with the configured encryption key.
+var StorageEncryptionEncryptCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "Encrypt the secrets stored before the encryption was enabled with the configured encryption key",
+	Run:   encryptStorageSecrets,
+	Args:  cobra.NoArgs,
+}
+
+// StorageEncryptionChangeKeyCmd encrypts the secrets with a new encryption key.
+var StorageEncryptionChangeKeyCmd = &cobra.Command{
+	Use:   "change-key",
+	Short: "Encrypt the secrets with a new encryption key, the configuration must be updated with the new key afterwards",
+	Run:   changeStorageEncryptionKey,
+	Args:  cobra.NoArgs,
+}
+
+// StorageEncryptionDecryptCmd decrypts the secrets, e.g. before downgrading the schema.
+var StorageEncryptionDecryptCmd = &cobra.Command{
+	Use:   "decrypt",
+	Short: "Decrypt the secrets, the encryption key must be removed from the configuration afterwards",
+	Run:   decryptStorageSecrets,
+	Args:  cobra.NoArgs,
+}
+
+// StorageEncryptionRotateKeyCmd encrypts the secrets encrypted with the previous encryption key with the encryption key
+// while Authelia is running.
+var StorageEncryptionRotateKeyCmd = &cobra.Command{
+	Use:   "rotate-key",
+	Short: "Encrypt the secrets encrypted with the configured previous_encryption_key with the encryption_key in batches while Authelia is running",
+	Run:   rotateStorageEncryptionKey,
+	Args:  cobra.NoArgs,
+}
+
+// StorageEncryptionCheckCmd checks the secrets can be decrypted with the configured encryption keys.
+var StorageEncryptionCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check all the secrets can be decrypted with the configured encryption keys",
+	Run:   checkStorageEncryption,
+	Args:  cobra.NoArgs,
+}
+
+func encryptStorageSecrets(cmd *cobra.Command, _ []string) {
+	config := readConfiguration(storageConfigPath)
+
+	if config.Storage.EncryptionKey == "" {
+		log.Fatal("No storage encryption_key is configured")
+	}
+
+	changeStorageSecretsEncryption(cmd, config, config.Storage.EncryptionKey, "encrypted")
+}
+
+func changeStorageEncryptionKey(cmd *cobra.Command, _ []string) {
+	if storageEncryptionNewKey == "" {
+		log.Fatal("The new encryption key must not be empty, use the decrypt command to decrypt the secrets")
+	}
+
+	changeStorageSecretsEncryption(cmd, readConfiguration(storageConfigPath), storageEncryptionNewKey, "encrypted with the new key")
+}
+
+func decryptStorageSecrets(cmd *cobra.Command, _ []string) {
+	changeStorageSecretsEncryption(cmd, readConfiguration(storageConfigPath), "", "decrypted")
+}
+
+func changeStorageSecretsEncryption(cmd *cobra.Command, config *schema.Configuration, key, action string) {
+	encrypter, dryRun := newStorageSecretsEncrypter(cmd, config)
+
+	count, err := encrypter.ChangeEncryptionKey(key)
+	if err != nil {
+		log.Fatalf("Unable to change the encryption of the storage secrets: %v", err)
+	}
+
+	if dryRun {
+		log.Printf("%d storage secrets have not been %s (dry-run).\n", count, action)
+		return
+	}
+
+	log.Printf("%d storage secrets have been %s.\n", count, action)
+}
+
+func rotateStorageEncryptionKey(cmd *cobra.Command, _ []string) {
+	config := readConfiguration(storageConfigPath)
+
+	if config.Storage.EncryptionKey == "" {
+		log.Fatal("No storage encryption_key is configured")
+	}
+
+	encrypter, dryRun := newStorageSecretsEncrypter(cmd, config)
+
+	count, err := encrypter.RotateEncryptionKey(storageEncryptionBatchSize)
+	if err != nil {
+		log.Fatalf("Unable to rotate the encryption key of the storage secrets after %d secrets: %v", count, err)
+	}
+
+	if dryRun {
+		log.Printf("%d storage secrets have not been rotated (dry-run).\n", count)
+		return
+	}
+
+	log.Printf("%d storage secrets have been rotated, the previous_encryption_key can be removed from the configuration.\n", count)
+}
+
+func checkStorageEncryption(cmd *cobra.Command, _ []string) {
+	encrypter, _ := newStorageSecretsEncrypter(cmd, readConfiguration(storageConfigPath))
+
+	check, err := encrypter.CheckEncryption()
+	if err != nil {
+		log.Fatalf("Unable to check the encryption of the storage secrets: %v", err)
+	}
+
+	for _, failure := range check.Failures {
+		log.Printf("The %s of user %s can't be decrypted: %v\n", failure.Kind, failure.Username, failure.Err)
+	}
+
+	if check.Plaintext != 0 {
+		log.Printf("%d storage secrets are stored in plaintext, use the encrypt command to encrypt them.\n", check.Plaintext)
+	}
+
+	if check.Previous != 0 {
+		log.Printf("%d storage secrets are encrypted with the previous_encryption_key, use the rotate-key command to rotate them.\n", check.Previous)
+	}
+
+	if len(check.Failures) != 0 {
+		log.Fatalf("%d of the %d storage secrets can't be decrypted", len(check.Failures), check.Total)
+	}
+
+	log.Printf("The %d storage secrets can be decrypted.\n", check.Total)
+}
+
+func newStorageSecretsEncrypter(cmd *cobra.Command, config *schema.Configuration) (encrypter storage.SecretsEncrypter, dryRun bool) {
+	provider, dryRun := newStorageProvider(cmd, config)
+
+	encrypter, ok := provider.(storage.SecretsEncrypter)
+	if !ok {
+		log.Fatal("The storage provider doesn't support the encryption of the secrets")
+	}
+
+	return encrypter, dryRun
+}
@@ -0,0 +1,147 @@
+package commands
+
+import (
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/storage"
+)
+
+var storageConfigPath string
+
+func init() {
+	for _, cmd := range []*cobra.Command{StorageMigrationCopyCmd, StorageMigrationVerifyCmd, StorageMigrationCutoverCmd} {
+		cmd.Flags().StringVar(&storageConfigPath, "config", "", "Configuration file")
+
+		if err := cmd.MarkFlagRequired("config"); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	StorageMigrationCmd.AddCommand(StorageMigrationCopyCmd, StorageMigrationVerifyCmd, StorageMigrationCutoverCmd)
+	StorageCmd.AddCommand(StorageMigrationCmd)
+}
+
+// StorageCmd storage command.
+var StorageCmd = &cobra.Command{
+	Use:   "storage",
+	Short: "Commands related to the storage",
+}
+
+// StorageMigrationCmd storage migration command.
+var StorageMigrationCmd = &cobra.Command{
+	Use:   "migration",
+	Short: "Commands related to the migration of the storage to the migration target",
+}
+
+// StorageMigrationCopyCmd copies the data of the current storage to the migration target.
+var StorageMigrationCopyCmd = &cobra.Command{
+	Use:   "copy",
+	Short: "Replace the data of the storage migration target by the data of the current storage",
+	Run:   copyStorageMigration,
+	Args:  cobra.NoArgs,
+}
+
+// StorageMigrationVerifyCmd verifies the migration target holds the same data as the current storage.
+var StorageMigrationVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify the storage migration target holds the same data as the current storage",
+	Run:   verifyStorageMigration,
+	Args:  cobra.NoArgs,
+}
+
+// StorageMigrationCutoverCmd verifies the migration is complete before switching to the migration target.
+var StorageMigrationCutoverCmd = &cobra.Command{
+	Use:   "cutover",
+	Short: "Verify the storage migration is complete before switching to the storage migration target",
+	Run:   cutoverStorageMigration,
+	Args:  cobra.NoArgs,
+}
+
+// newStorageMigrationProviders creates the providers of the current storage and of the migration target, the target
+// is a dry-run one when the global dry-run flag is set.
+func newStorageMigrationProviders(cmd *cobra.Command) (current, target storage.Provider) {
+	config := readConfiguration(storageConfigPath)
+
+	if config.Storage.MigrationTarget == nil {
+		log.Fatal("No storage migration target is configured")
+	}
+
+	currentConfig := config.Storage
+	currentConfig.MigrationTarget = nil
+
+	current, err := storage.NewProvider(currentConfig)
+	if err != nil {
+		log.Fatalf("Failed to initialize storage provider: %v", err)
+	}
+
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		target, err = storage.NewDryRunProvider(storageMigrationTargetConfiguration(config), os.Stdout)
+	} else {
+		target, err = storage.NewMigrationTargetProvider(config.Storage)
+	}
+
+	if err != nil {
+		log.Fatalf("Failed to initialize the storage migration target: %v", err)
+	}
+
+	return current, target
+}
+
+// storageMigrationTargetConfiguration returns the storage configuration using the migration target as the storage.
+func storageMigrationTargetConfiguration(config *schema.Configuration) schema.StorageConfiguration {
+	target := config.Storage.MigrationTarget
+
+	return schema.StorageConfiguration{
+		Local:      target.Local,
+		MySQL:      target.MySQL,
+		PostgreSQL: target.PostgreSQL,
+		Retention:  config.Storage.Retention,
+	}
+}
+
+func copyStorageMigration(cmd *cobra.Command, args []string) {
+	current, target := newStorageMigrationProviders(cmd)
+
+	export, err := storage.Migrate(current, target)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("Data of the storage schema v%d has been copied to the storage migration target.\n", export.SchemaVersion)
+}
+
+func verifyStorageMigration(cmd *cobra.Command, args []string) {
+	current, target := newStorageMigrationProviders(cmd)
+
+	verifyStorageMigrationTarget(current, target)
+
+	log.Println("The storage migration target holds the same data as the current storage.")
+}
+
+func cutoverStorageMigration(cmd *cobra.Command, args []string) {
+	current, target := newStorageMigrationProviders(cmd)
+
+	verifyStorageMigrationTarget(current, target)
+
+	log.Println("The storage migration target holds the same data as the current storage. Replace the storage backend by " +
+		"the one of storage.migration_target, remove storage.migration_target and restart Authelia to complete the cutover.")
+}
+
+func verifyStorageMigrationTarget(current, target storage.Provider) {
+	differences, err := storage.VerifyMigration(current, target)
+	if err != nil {
+		log.Fatalf("Unable to verify the storage migration: %v", err)
+	}
+
+	if len(differences) != 0 {
+		for _, difference := range differences {
+			log.Printf("\t%s\n", difference)
+		}
+
+		log.Fatal("The storage migration target doesn't hold the same data as the current storage, copy the data again before the cutover")
+	}
+}
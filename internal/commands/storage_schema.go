@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"github.com/authelia/authelia/internal/storage"
+)
+
+var storageSchemaTargetVersion int
+
+func init() {
+	StorageSchemaDowngradeCmd.Flags().StringVar(&storageConfigPath, "config", "", "Configuration file")
+	StorageSchemaDowngradeCmd.Flags().IntVar(&storageSchemaTargetVersion, "target", 0, "Version the schema is downgraded to")
+
+	for _, flag := range []string{"config", "target"} {
+		if err := StorageSchemaDowngradeCmd.MarkFlagRequired(flag); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	StorageSchemaCmd.AddCommand(StorageSchemaDowngradeCmd)
+	StorageCmd.AddCommand(StorageSchemaCmd)
+}
+
+// StorageSchemaCmd storage schema command.
+var StorageSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Commands related to the schema of the storage",
+}
+
+// StorageSchemaDowngradeCmd downgrades the schema of the storage to a previous version.
+var StorageSchemaDowngradeCmd = &cobra.Command{
+	Use:   "downgrade",
+	Short: "Downgrade the schema of the storage to a previous version before running an older version of Authelia",
+	Run:   downgradeStorageSchema,
+	Args:  cobra.NoArgs,
+}
+
+func downgradeStorageSchema(cmd *cobra.Command, _ []string) {
+	config := readConfiguration(storageConfigPath)
+
+	provider, dryRun := newStorageProvider(cmd, config)
+
+	downgrader, ok := provider.(storage.SchemaDowngrader)
+	if !ok {
+		log.Fatal("The storage provider doesn't support schema downgrades")
+	}
+
+	from, err := downgrader.SchemaDowngrade(storage.SchemaVersion(storageSchemaTargetVersion))
+	if err != nil {
+		log.Fatalf("Unable to downgrade the storage schema: %v", err)
+	}
+
+	if dryRun {
+		log.Printf("Storage schema has not been downgraded from v%d to v%d (dry-run).\n", from, storageSchemaTargetVersion)
+		return
+	}
+
+	log.Printf("Storage schema has been downgraded from v%d to v%d.\n", from, storageSchemaTargetVersion)
+}
@@ -0,0 +1,148 @@
+package commands
+
+import (
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/authelia/authelia/internal/audit"
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/events"
+	"github.com/authelia/authelia/internal/logging"
+	"github.com/authelia/authelia/internal/privacy"
+	"github.com/authelia/authelia/internal/storage"
+)
+
+var (
+	userConfigPath       string
+	userExportOutputPath string
+)
+
+// userCommandActor is the actor of the events recorded in the audit log by the user commands.
+const userCommandActor = "command line"
+
+func init() {
+	UserPurgeCmd.Flags().StringVar(&userConfigPath, "config", "", "Configuration file")
+
+	if err := UserPurgeCmd.MarkFlagRequired("config"); err != nil {
+		log.Fatal(err)
+	}
+
+	UserExportCmd.Flags().StringVar(&userConfigPath, "config", "", "Configuration file")
+	UserExportCmd.Flags().StringVarP(&userExportOutputPath, "output", "o", "", "File the export is written to, the standard output when not set")
+
+	if err := UserExportCmd.MarkFlagRequired("config"); err != nil {
+		log.Fatal(err)
+	}
+
+	UserCmd.AddCommand(UserPurgeCmd, UserExportCmd)
+}
+
+// UserCmd user management command.
+var UserCmd = &cobra.Command{
+	Use:   "user",
+	Short: "Commands related to the management of the users",
+}
+
+// UserPurgeCmd deletes all the data of a departing user and revokes the sessions of the user.
+var UserPurgeCmd = &cobra.Command{
+	Use:   "purge [username]",
+	Short: "Delete the 2FA devices, preferences, authentication logs and events of a user and revoke the sessions of the user",
+	Run:   purgeUser,
+	Args:  cobra.ExactArgs(1),
+}
+
+// UserExportCmd exports the personal data stored about a user as JSON.
+var UserExportCmd = &cobra.Command{
+	Use:   "export [username]",
+	Short: "Export the preferences, 2FA devices, events, consents and access grants stored about a user as JSON",
+	Run:   exportUser,
+	Args:  cobra.ExactArgs(1),
+}
+
+func purgeUser(cmd *cobra.Command, args []string) {
+	username := args[0]
+
+	config := readConfiguration(userConfigPath)
+
+	provider, dryRun := newStorageProvider(cmd, config)
+
+	if err := provider.PurgeUser(username, time.Now()); err != nil {
+		log.Fatalf("Unable to purge user %s: %v", username, err)
+	}
+
+	if dryRun {
+		log.Printf("User %s has not been purged (dry-run).\n", username)
+		return
+	}
+
+	recordUserEvent(config, events.UserDataErased, username)
+
+	log.Printf("User %s has been purged, the sessions of the user are destroyed when the profile is next refreshed.\n", username)
+}
+
+func exportUser(_ *cobra.Command, args []string) {
+	username := args[0]
+
+	config := readConfiguration(userConfigPath)
+
+	provider, err := storage.NewProvider(config.Storage)
+	if err != nil {
+		log.Fatalf("Failed to initialize storage provider: %v", err)
+	}
+
+	export, err := privacy.NewExport(provider, username, time.Now())
+	if err != nil {
+		log.Fatalf("Unable to export the personal data of user %s: %v", username, err)
+	}
+
+	var w io.Writer = os.Stdout
+
+	if userExportOutputPath != "" {
+		file, err := os.OpenFile(userExportOutputPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			log.Fatalf("Unable to create the export file: %v", err)
+		}
+
+		defer file.Close()
+
+		w = file
+	}
+
+	if err = export.Write(w); err != nil {
+		log.Fatalf("Unable to write the export of user %s: %v", username, err)
+	}
+
+	recordUserEvent(config, events.UserDataExported, username)
+
+	if userExportOutputPath != "" {
+		log.Printf("Personal data of user %s written to %s.\n", username, userExportOutputPath)
+	}
+}
+
+// recordUserEvent records an event of a user made by a command in the audit log when it's configured, the event bus
+// of the running instance isn't reachable from the commands.
+func recordUserEvent(config *schema.Configuration, eventType events.Type, username string) {
+	if config.Audit == nil {
+		return
+	}
+
+	logger, err := audit.NewLogger(config.Audit, logging.NewMinimizer(config.Logging.Minimization))
+	if err != nil {
+		log.Fatalf("Unable to record the %s event of user %s in the audit log: %v", eventType, username, err)
+	}
+
+	logger.Start()
+
+	logger.Subscriber()(events.Event{
+		Type:     eventType,
+		Time:     time.Now(),
+		Username: username,
+		Details:  map[string]string{events.DetailActor: userCommandActor},
+	})
+
+	logger.Close()
+}
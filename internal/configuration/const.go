@@ -1,3 +1,30 @@
 package configuration
 
+import (
+	"errors"
+	"time"
+)
+
 const windows = "windows"
+
+const (
+	dockerNamePrefix       = "authelia_"
+	dockerSecretsDirectory = "/run/secrets"
+	dockerConfigsDirectory = "/"
+	dockerConfigName       = "authelia_configuration"
+)
+
+const (
+	remoteSourceTimeout = 10 * time.Second
+
+	vaultTokenEnvName = "VAULT_TOKEN"
+	vaultTokenHeader  = "X-Vault-Token"
+
+	consulTokenEnvName = "CONSUL_HTTP_TOKEN"
+	consulTokenHeader  = "X-Consul-Token"
+)
+
+// errRemoteSourceNotFound is returned when the secret or the keys of a remote source don't exist.
+var errRemoteSourceNotFound = errors.New("server responded with status code 404")
+
+const errFmtSplitFileConflict = "%s and %s can't both be defined"
@@ -16,32 +16,74 @@ import (
 	"github.com/authelia/authelia/internal/logging"
 )
 
-// Read a YAML configuration and create a Configuration object out of it.
+// Read a YAML configuration and create a Configuration object out of it along with the default sources.
 func Read(configPath string) (*schema.Configuration, []error) {
-	logger := logging.Logger()
+	return ReadWithSources(configPath, NewDefaultSources()...)
+}
+
+// ReadWithSources reads a YAML configuration, loads the given sources and creates a Configuration object out of it.
+func ReadWithSources(configPath string, sources ...Source) (*schema.Configuration, []error) {
+	return NewSources(configPath, sources...).Load()
+}
+
+// Sources are the configuration file and the sources loaded along with it, they're all read again when the
+// configuration is reloaded.
+type Sources struct {
+	path    string
+	sources []Source
+}
 
-	if configPath == "" {
+// NewSources creates the Sources of the configuration file at the given path.
+func NewSources(configPath string, sources ...Source) *Sources {
+	return &Sources{path: configPath, sources: sources}
+}
+
+// Path returns the path of the configuration file.
+func (s *Sources) Path() string {
+	return s.path
+}
+
+// Load reads the configuration file and the sources into a new validated Configuration, the configuration file is
+// generated from the template when it doesn't exist.
+func (s *Sources) Load() (*schema.Configuration, []error) {
+	if s.path == "" {
 		return nil, []error{errors.New("No config file path provided")}
 	}
 
-	_, err := os.Stat(configPath)
+	_, err := os.Stat(s.path)
 	if err != nil {
 		errs := []error{
-			fmt.Errorf("Unable to find config file: %v", configPath),
-			fmt.Errorf("Generating config file: %v", configPath),
+			fmt.Errorf("Unable to find config file: %v", s.path),
+			fmt.Errorf("Generating config file: %v", s.path),
 		}
 
-		err = generateConfigFromTemplate(configPath)
+		err = generateConfigFromTemplate(s.path)
 		if err != nil {
 			errs = append(errs, err)
 		} else {
-			errs = append(errs, fmt.Errorf("Generated configuration at: %v", configPath))
+			errs = append(errs, fmt.Errorf("Generated configuration at: %v", s.path))
 		}
 
 		return nil, errs
 	}
 
-	file, err := ioutil.ReadFile(configPath)
+	return s.read()
+}
+
+// Reload reads the configuration file and the sources again into a new validated Configuration. Unlike Load the
+// configuration file isn't generated when it has been removed.
+func (s *Sources) Reload() (*schema.Configuration, []error) {
+	if _, err := os.Stat(s.path); err != nil {
+		return nil, []error{fmt.Errorf("Unable to find config file: %v", s.path)}
+	}
+
+	return s.read()
+}
+
+func (s *Sources) read() (*schema.Configuration, []error) {
+	logger := logging.Logger()
+
+	file, err := ioutil.ReadFile(s.path)
 	if err != nil {
 		return nil, []error{fmt.Errorf("Failed to %v", err)}
 	}
@@ -53,25 +95,35 @@ func Read(configPath string) (*schema.Configuration, []error) {
 		return nil, []error{fmt.Errorf("Error malformed %v", err)}
 	}
 
-	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	// Each read uses its own viper so the values removed from the configuration don't survive a reload.
+	v := viper.New()
+
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 
 	// Dynamically load the secret env names from the SecretNames map.
 	for _, secretName := range validator.SecretNames {
-		_ = viper.BindEnv(validator.SecretNameToEnvName(secretName))
+		_ = v.BindEnv(validator.SecretNameToEnvName(secretName))
 	}
 
-	viper.SetConfigFile(configPath)
+	v.SetConfigFile(s.path)
+
+	_ = v.ReadInConfig()
 
-	_ = viper.ReadInConfig()
+	for _, source := range s.sources {
+		if err = source.Load(v); err != nil {
+			return nil, []error{fmt.Errorf("Error loading the %s source: %v", source.Name(), err)}
+		}
+	}
 
 	var configuration schema.Configuration
 
-	viper.Unmarshal(&configuration) //nolint:errcheck // TODO: Legacy code, consider refactoring time permitting.
+	v.Unmarshal(&configuration) //nolint:errcheck // TODO: Legacy code, consider refactoring time permitting.
 
 	val := schema.NewStructValidator()
-	validator.ValidateSecrets(&configuration, val, viper.GetViper())
+	validator.ValidateSecrets(&configuration, val, v)
+	readSplitFiles(&configuration, val)
 	validator.ValidateConfiguration(&configuration, val)
-	validator.ValidateKeys(val, viper.AllKeys())
+	validator.ValidateKeys(val, v.AllKeys())
 
 	if val.HasErrors() {
 		return nil, val.Errors()
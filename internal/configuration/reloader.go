@@ -0,0 +1,234 @@
+package configuration
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/logging"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// Section is a section of the configuration which can be applied to the running providers without a restart.
+type Section string
+
+const (
+	// SectionAccessControl is the access_control section.
+	SectionAccessControl Section = "access control"
+
+	// SectionOpenIDConnectClients is the clients of the identity_providers.oidc section.
+	SectionOpenIDConnectClients Section = "OpenID Connect clients"
+
+	// SectionNotifier is the provider of the notifier section, i.e. its smtp or filesystem configuration.
+	SectionNotifier Section = "notifier"
+)
+
+// ReloadHandler applies a section of the reloaded configuration to the running providers.
+type ReloadHandler func(configuration *schema.Configuration) (err error)
+
+type reloadableSection struct {
+	section Section
+
+	// get returns the value compared to detect the changes of the section.
+	get func(configuration *schema.Configuration) interface{}
+
+	// set copies the section of src into dst.
+	set func(dst, src *schema.Configuration)
+}
+
+var reloadableSections = []reloadableSection{
+	{
+		section: SectionAccessControl,
+		get: func(configuration *schema.Configuration) interface{} {
+			return configuration.AccessControl
+		},
+		set: func(dst, src *schema.Configuration) {
+			dst.AccessControl = src.AccessControl
+		},
+	},
+	{
+		section: SectionOpenIDConnectClients,
+		get: func(configuration *schema.Configuration) interface{} {
+			if configuration.IdentityProviders.OIDC == nil {
+				return nil
+			}
+
+			return configuration.IdentityProviders.OIDC.Clients
+		},
+		set: func(dst, src *schema.Configuration) {
+			if dst.IdentityProviders.OIDC == nil {
+				return
+			}
+
+			if src.IdentityProviders.OIDC == nil {
+				dst.IdentityProviders.OIDC.Clients = nil
+				return
+			}
+
+			dst.IdentityProviders.OIDC.Clients = src.IdentityProviders.OIDC.Clients
+		},
+	},
+	{
+		section: SectionNotifier,
+		get: func(configuration *schema.Configuration) interface{} {
+			if configuration.Notifier == nil {
+				return nil
+			}
+
+			return []interface{}{configuration.Notifier.FileSystem, configuration.Notifier.SMTP}
+		},
+		set: func(dst, src *schema.Configuration) {
+			if dst.Notifier == nil {
+				return
+			}
+
+			if src.Notifier == nil {
+				dst.Notifier.FileSystem, dst.Notifier.SMTP = nil, nil
+				return
+			}
+
+			dst.Notifier.FileSystem, dst.Notifier.SMTP = src.Notifier.FileSystem, src.Notifier.SMTP
+		},
+	},
+}
+
+// Reloader reads the configuration again on SIGHUP or when the configuration file changes. The reloadable sections
+// which changed are applied to the running providers by their handlers, the changes of the other sections are only
+// applied on restart. The current configuration is kept when the new one is invalid.
+type Reloader struct {
+	sources *Sources
+
+	mutex    sync.Mutex
+	current  *schema.Configuration
+	handlers map[Section]ReloadHandler
+}
+
+// NewReloader creates a Reloader of the configuration read from the sources.
+func NewReloader(sources *Sources, current *schema.Configuration) *Reloader {
+	return &Reloader{
+		sources:  sources,
+		current:  copyConfiguration(current),
+		handlers: map[Section]ReloadHandler{},
+	}
+}
+
+// Handle registers the handler applying a section of the reloaded configuration, the changes of the sections without a
+// handler require a restart.
+func (r *Reloader) Handle(section Section, handler ReloadHandler) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.handlers[section] = handler
+}
+
+// Reload reads the configuration again and applies the reloadable sections which changed.
+func (r *Reloader) Reload() (errs []error) {
+	logger := logging.Logger()
+
+	configuration, errs := r.sources.Reload()
+	if len(errs) != 0 {
+		return errs
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, reloadable := range reloadableSections {
+		if reflect.DeepEqual(reloadable.get(r.current), reloadable.get(configuration)) {
+			continue
+		}
+
+		handler, ok := r.handlers[reloadable.section]
+		if !ok {
+			logger.Warnf("The %s changed but can't be reloaded, a restart is required to apply the changes", reloadable.section)
+			continue
+		}
+
+		if err := handler(configuration); err != nil {
+			errs = append(errs, fmt.Errorf("Unable to apply the %s: %w", reloadable.section, err))
+			continue
+		}
+
+		reloadable.set(r.current, configuration)
+
+		logger.Infof("Applied the reloaded %s", reloadable.section)
+	}
+
+	if !reflect.DeepEqual(withoutReloadableSections(r.current), withoutReloadableSections(configuration)) {
+		logger.Warn("Some of the changes of the configuration can't be reloaded, a restart is required to apply them")
+	}
+
+	return errs
+}
+
+// Watch reloads the configuration on SIGHUP and when the configuration file changes.
+func (r *Reloader) Watch() (err error) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	go func() {
+		logger := logging.Logger()
+
+		for range signals {
+			logger.Debugf("Reloading the configuration after receiving SIGHUP")
+
+			if err := r.reload(); err != nil {
+				logger.Errorf("Unable to reload the configuration, the current one is kept: %s", err)
+			} else {
+				logger.Infof("Reloaded the configuration from %s", r.sources.Path())
+			}
+		}
+	}()
+
+	return utils.WatchFile(r.sources.Path(), "configuration", r.reload)
+}
+
+func (r *Reloader) reload() error {
+	errs := r.Reload()
+	if len(errs) == 0 {
+		return nil
+	}
+
+	messages := make([]string, len(errs))
+
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+
+	return errors.New(strings.Join(messages, ", "))
+}
+
+// copyConfiguration copies the configuration along with the structs the reloadable sections are set into.
+func copyConfiguration(configuration *schema.Configuration) *schema.Configuration {
+	copied := *configuration
+
+	if copied.IdentityProviders.OIDC != nil {
+		oidc := *copied.IdentityProviders.OIDC
+		copied.IdentityProviders.OIDC = &oidc
+	}
+
+	if copied.Notifier != nil {
+		notifier := *copied.Notifier
+		copied.Notifier = &notifier
+	}
+
+	return &copied
+}
+
+// withoutReloadableSections returns a copy of the configuration without the reloadable sections to compare the
+// sections which require a restart.
+func withoutReloadableSections(configuration *schema.Configuration) *schema.Configuration {
+	copied := copyConfiguration(configuration)
+
+	for _, reloadable := range reloadableSections {
+		reloadable.set(copied, &schema.Configuration{})
+	}
+
+	return copied
+}
@@ -0,0 +1,124 @@
+package configuration
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+func setupReloaderTest(t *testing.T) (path string, content string) {
+	dir := setupEnv(t)
+
+	require.NoError(t, os.Setenv("AUTHELIA_JWT_SECRET_FILE", dir+"jwt"))
+	require.NoError(t, os.Setenv("AUTHELIA_DUO_API_SECRET_KEY_FILE", dir+"duo"))
+	require.NoError(t, os.Setenv("AUTHELIA_SESSION_SECRET_FILE", dir+"session"))
+	require.NoError(t, os.Setenv("AUTHELIA_AUTHENTICATION_BACKEND_LDAP_PASSWORD_FILE", dir+"authentication"))
+	require.NoError(t, os.Setenv("AUTHELIA_NOTIFIER_SMTP_PASSWORD_FILE", dir+"notifier"))
+	require.NoError(t, os.Setenv("AUTHELIA_SESSION_REDIS_PASSWORD_FILE", dir+"redis"))
+	require.NoError(t, os.Setenv("AUTHELIA_SESSION_REDIS_HIGH_AVAILABILITY_SENTINEL_PASSWORD_FILE", dir+"redis-sentinel"))
+	require.NoError(t, os.Setenv("AUTHELIA_STORAGE_MYSQL_PASSWORD_FILE", dir+"mysql"))
+	require.NoError(t, os.Setenv("AUTHELIA_STORAGE_POSTGRES_PASSWORD_FILE", dir+"postgres"))
+
+	data, err := ioutil.ReadFile("./test_resources/config.yml")
+	require.NoError(t, err)
+
+	path = filepath.Join(t.TempDir(), "config.yml")
+	content = string(data)
+
+	require.NoError(t, ioutil.WriteFile(path, data, 0600))
+
+	return path, content
+}
+
+func TestShouldApplyReloadedSectionsWhichChanged(t *testing.T) {
+	path, content := setupReloaderTest(t)
+	sources := NewSources(path)
+
+	config, errs := sources.Load()
+	require.Len(t, errs, 0)
+
+	reloader := NewReloader(sources, config)
+
+	var applied []string
+
+	reloader.Handle(SectionAccessControl, func(reloaded *schema.Configuration) error {
+		applied = append(applied, reloaded.AccessControl.DefaultPolicy)
+		return nil
+	})
+
+	// Nothing changed.
+	assert.Len(t, reloader.Reload(), 0)
+	assert.Len(t, applied, 0)
+
+	content = strings.Replace(content, "default_policy: deny", "default_policy: two_factor", 1)
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+
+	assert.Len(t, reloader.Reload(), 0)
+	assert.Equal(t, []string{"two_factor"}, applied)
+
+	// The sections which can't be reloaded aren't applied.
+	content = strings.Replace(content, "port: 9091", "port: 9092", 1)
+	content = strings.Replace(content, "port: 1025", "port: 1026", 1)
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+
+	assert.Len(t, reloader.Reload(), 0)
+	assert.Equal(t, []string{"two_factor"}, applied)
+
+	// The original configuration isn't modified.
+	assert.Equal(t, "deny", config.AccessControl.DefaultPolicy)
+}
+
+func TestShouldKeepCurrentConfigurationWhenReloadFails(t *testing.T) {
+	path, content := setupReloaderTest(t)
+	sources := NewSources(path)
+
+	config, errs := sources.Load()
+	require.Len(t, errs, 0)
+
+	reloader := NewReloader(sources, config)
+
+	var err error
+
+	applied := 0
+
+	reloader.Handle(SectionAccessControl, func(reloaded *schema.Configuration) error {
+		applied++
+		return err
+	})
+
+	require.NoError(t, ioutil.WriteFile(path, []byte(strings.Replace(content, "default_policy: deny", "default_policy: bad", 1)), 0600))
+
+	errs = reloader.Reload()
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "'default_policy' must either be 'deny', 'two_factor', 'one_factor' or 'bypass'")
+	assert.Equal(t, 0, applied)
+
+	require.NoError(t, ioutil.WriteFile(path, []byte(strings.Replace(content, "default_policy: deny", "default_policy: one_factor", 1)), 0600))
+
+	err = errors.New("unavailable")
+
+	errs = reloader.Reload()
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "Unable to apply the access control: unavailable")
+
+	// The section which failed to be applied is applied again at the next reload.
+	err = nil
+
+	assert.Len(t, reloader.Reload(), 0)
+	assert.Len(t, reloader.Reload(), 0)
+	assert.Equal(t, 2, applied)
+
+	require.NoError(t, os.Remove(path))
+
+	errs = reloader.Reload()
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "Unable to find config file: "+path)
+}
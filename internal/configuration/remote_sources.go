@@ -0,0 +1,238 @@
+package configuration
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
+
+	"github.com/authelia/authelia/internal/configuration/validator"
+)
+
+// remoteSources is the configuration of the remote sources loaded by NewDefaultSources, it's set by the command line
+// flags.
+var remoteSources struct {
+	vaultAddress  string
+	vaultPath     string
+	consulAddress string
+	consulPrefix  string
+}
+
+// RegisterRemoteSourcesFlags registers the command line flags configuring the remote sources loaded along with the
+// configuration file. The tokens are read from the conventional VAULT_TOKEN and CONSUL_HTTP_TOKEN environment
+// variables so they don't appear in the command line.
+func RegisterRemoteSourcesFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&remoteSources.vaultAddress, "vault-address", "", "Address of the Vault server the secrets are read from")
+	flags.StringVar(&remoteSources.vaultPath, "vault-path", "secret/data/authelia", "Path of the Vault KV secret holding the secrets")
+	flags.StringVar(&remoteSources.consulAddress, "consul-address", "", "Address of the Consul server the configuration keys are read from")
+	flags.StringVar(&remoteSources.consulPrefix, "consul-prefix", "authelia", "Prefix of the Consul KV keys holding the configuration keys")
+}
+
+func newRemoteSources() (sources []Source) {
+	if remoteSources.vaultAddress != "" {
+		sources = append(sources, NewVaultSource(remoteSources.vaultAddress, remoteSources.vaultPath, os.Getenv(vaultTokenEnvName)))
+	}
+
+	if remoteSources.consulAddress != "" {
+		sources = append(sources, NewConsulSource(remoteSources.consulAddress, remoteSources.consulPrefix, os.Getenv(consulTokenEnvName)))
+	}
+
+	return sources
+}
+
+// VaultSource reads the secrets from a KV secret of a Vault server, each key of the secret is the name of a secret of
+// the configuration such as jwt_secret or storage.mysql.password.
+type VaultSource struct {
+	address string
+	path    string
+	token   string
+	client  *http.Client
+}
+
+// NewVaultSource creates a source reading the secrets of the KV secret at the given path, both the versions 1 and 2 of
+// the KV secrets engine are supported.
+func NewVaultSource(address, path, token string) *VaultSource {
+	return &VaultSource{
+		address: address,
+		path:    path,
+		token:   token,
+		client:  &http.Client{Timeout: remoteSourceTimeout},
+	}
+}
+
+// Name returns the name of the source.
+func (s *VaultSource) Name() string {
+	return "vault"
+}
+
+// Load merges the secrets over the configuration. Like the secret files, a secret can't be defined both in Vault and
+// in the configuration.
+func (s *VaultSource) Load(v *viper.Viper) (err error) {
+	var secret struct {
+		Data map[string]interface{} `json:"data"`
+	}
+
+	if err = getRemoteSource(s.client, joinURL(s.address, "v1", s.path), vaultTokenHeader, s.token, &secret); err != nil {
+		return err
+	}
+
+	data := secret.Data
+
+	// The version 2 of the KV secrets engine nests the secret along with its metadata.
+	if nested, ok := data["data"].(map[string]interface{}); ok && data["metadata"] != nil {
+		data = nested
+	}
+
+	secrets := map[string]interface{}{}
+
+	for name, value := range data {
+		if !isSecretName(name) {
+			return fmt.Errorf("key %s of the secret isn't the name of a secret", name)
+		}
+
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("secret %s must be a string", name)
+		}
+
+		if v.GetString(name) != "" || v.GetString(validator.SecretNameToEnvName(name)) != "" {
+			return fmt.Errorf("secret %s is already defined in the configuration", name)
+		}
+
+		setNestedKey(secrets, name, str)
+	}
+
+	return v.MergeConfigMap(secrets)
+}
+
+// ConsulSource reads the configuration keys from the KV store of a Consul server. The keys under the prefix are the
+// configuration keys with their sections separated by slashes, such as session/domain, and their values are YAML.
+type ConsulSource struct {
+	address string
+	prefix  string
+	token   string
+	client  *http.Client
+}
+
+// NewConsulSource creates a source reading the configuration keys under the given prefix.
+func NewConsulSource(address, prefix, token string) *ConsulSource {
+	return &ConsulSource{
+		address: address,
+		prefix:  strings.Trim(prefix, "/"),
+		token:   token,
+		client:  &http.Client{Timeout: remoteSourceTimeout},
+	}
+}
+
+// Name returns the name of the source.
+func (s *ConsulSource) Name() string {
+	return "consul"
+}
+
+// Load merges the configuration keys over the configuration file. The keys are validated along with the keys of the
+// configuration file.
+func (s *ConsulSource) Load(v *viper.Viper) (err error) {
+	var entries []struct {
+		Key   string `json:"Key"`
+		Value []byte `json:"Value"`
+	}
+
+	err = getRemoteSource(s.client, joinURL(s.address, "v1", "kv", s.prefix)+"?recurse=true", consulTokenHeader, s.token, &entries)
+
+	switch {
+	case err == errRemoteSourceNotFound:
+		// Consul responds with a 404 when there isn't any key under the prefix.
+		return nil
+	case err != nil:
+		return err
+	}
+
+	keys := map[string]interface{}{}
+
+	for _, entry := range entries {
+		name := strings.Trim(strings.TrimPrefix(entry.Key, s.prefix), "/")
+
+		// Skip the folders.
+		if name == "" || strings.HasSuffix(entry.Key, "/") {
+			continue
+		}
+
+		var value interface{}
+
+		if err = yaml.Unmarshal(entry.Value, &value); err != nil {
+			return fmt.Errorf("value of the key %s is malformed: %v", entry.Key, err)
+		}
+
+		setNestedKey(keys, strings.ReplaceAll(name, "/", "."), value)
+	}
+
+	return v.MergeConfigMap(keys)
+}
+
+func getRemoteSource(client *http.Client, target, tokenHeader, token string, v interface{}) (err error) {
+	req, err := http.NewRequest(http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+
+	if token != "" {
+		req.Header.Set(tokenHeader, token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return errRemoteSourceNotFound
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("server responded with status code %d", resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func joinURL(address string, elements ...string) string {
+	for i, element := range elements {
+		elements[i] = (&url.URL{Path: strings.Trim(element, "/")}).EscapedPath()
+	}
+
+	return strings.TrimRight(address, "/") + "/" + strings.Join(elements, "/")
+}
+
+// setNestedKey sets the value of a dotted configuration key in the nested maps viper merges.
+func setNestedKey(m map[string]interface{}, key string, value interface{}) {
+	parts := strings.Split(key, ".")
+
+	for _, part := range parts[:len(parts)-1] {
+		nested, ok := m[part].(map[string]interface{})
+		if !ok {
+			nested = map[string]interface{}{}
+			m[part] = nested
+		}
+
+		m = nested
+	}
+
+	m[parts[len(parts)-1]] = value
+}
+
+func isSecretName(name string) bool {
+	for _, secretName := range validator.SecretNames {
+		if name == secretName {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,128 @@
+package configuration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/configuration/validator"
+)
+
+func newRemoteSourceTestServer(t *testing.T, path, tokenHeader string, body interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != path {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if req.Header.Get(tokenHeader) != "token" {
+			rw.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		assert.NoError(t, json.NewEncoder(rw).Encode(body))
+	}))
+}
+
+func TestShouldLoadVaultSecrets(t *testing.T) {
+	server := newRemoteSourceTestServer(t, "/v1/secret/data/authelia", vaultTokenHeader, map[string]interface{}{
+		"data": map[string]interface{}{
+			"data": map[string]interface{}{
+				"jwt_secret":             "secret_from_vault",
+				"storage.mysql.password": "mysql_secret_from_vault",
+			},
+			"metadata": map[string]interface{}{"version": 1},
+		},
+	})
+	defer server.Close()
+
+	v := viper.New()
+	v.Set("storage.mysql.host", "mysql")
+
+	require.NoError(t, NewVaultSource(server.URL+"/", "/secret/data/authelia", "token").Load(v))
+
+	assert.Equal(t, "secret_from_vault", v.GetString("jwt_secret"))
+	assert.Equal(t, "mysql_secret_from_vault", v.GetString("storage.mysql.password"))
+	assert.Equal(t, "mysql", v.GetString("storage.mysql.host"))
+
+	assert.EqualError(t, NewVaultSource(server.URL, "secret/data/authelia", "bad").Load(viper.New()),
+		"server responded with status code 403")
+	assert.EqualError(t, NewVaultSource(server.URL, "secret/data/missing", "token").Load(viper.New()),
+		"server responded with status code 404")
+}
+
+func TestShouldErrorVaultSecretsAlreadyDefinedOrUnknown(t *testing.T) {
+	server := newRemoteSourceTestServer(t, "/v1/secret/authelia", vaultTokenHeader, map[string]interface{}{
+		"data": map[string]interface{}{
+			"jwt_secret": "secret_from_vault",
+		},
+	})
+	defer server.Close()
+
+	v := viper.New()
+	v.Set("jwt_secret", "secret_from_config")
+
+	assert.EqualError(t, NewVaultSource(server.URL, "secret/authelia", "token").Load(v),
+		"secret jwt_secret is already defined in the configuration")
+
+	v = viper.New()
+	v.SetDefault("authelia.jwt_secret.file", "/run/secrets/authelia_jwt_secret")
+
+	assert.EqualError(t, NewVaultSource(server.URL, "secret/authelia", "token").Load(v),
+		"secret jwt_secret is already defined in the configuration")
+
+	unknown := newRemoteSourceTestServer(t, "/v1/secret/authelia", vaultTokenHeader, map[string]interface{}{
+		"data": map[string]interface{}{
+			"default_redirection_url": "https://home.example.com",
+		},
+	})
+	defer unknown.Close()
+
+	assert.EqualError(t, NewVaultSource(unknown.URL, "secret/authelia", "token").Load(viper.New()),
+		"key default_redirection_url of the secret isn't the name of a secret")
+}
+
+func TestShouldLoadConsulKeys(t *testing.T) {
+	server := newRemoteSourceTestServer(t, "/v1/kv/authelia", consulTokenHeader, []map[string]interface{}{
+		{"Key": "authelia/", "Value": nil},
+		{"Key": "authelia/session/domain", "Value": []byte("example.com")},
+		{"Key": "authelia/port", "Value": []byte("9092")},
+		{"Key": "authelia/access_control/rules", "Value": []byte("- domain: public.example.com\n  policy: bypass\n")},
+		{"Key": "authelia/session/unknown", "Value": []byte("true")},
+	})
+	defer server.Close()
+
+	v := viper.New()
+	v.SetConfigType("yaml")
+
+	require.NoError(t, v.ReadConfig(strings.NewReader("port: 9091\nsession:\n  name: authelia_session\n")))
+
+	require.NoError(t, NewConsulSource(server.URL, "/authelia/", "token").Load(v))
+
+	var configuration schema.Configuration
+
+	require.NoError(t, v.Unmarshal(&configuration))
+
+	assert.Equal(t, 9092, configuration.Port)
+	assert.Equal(t, "example.com", configuration.Session.Domain)
+	assert.Equal(t, "authelia_session", configuration.Session.Name)
+	require.Len(t, configuration.AccessControl.Rules, 1)
+	assert.Equal(t, "bypass", configuration.AccessControl.Rules[0].Policy)
+
+	// The keys are validated along with the keys of the configuration file.
+	val := schema.NewStructValidator()
+	validator.ValidateKeys(val, v.AllKeys())
+
+	require.Len(t, val.Errors(), 1)
+	assert.EqualError(t, val.Errors()[0], "config key not expected: session.unknown")
+
+	// A prefix without any key is empty.
+	require.NoError(t, NewConsulSource(server.URL, "missing", "token").Load(viper.New()))
+}
@@ -2,9 +2,13 @@ package schema
 
 // AccessControlConfiguration represents the configuration related to ACLs.
 type AccessControlConfiguration struct {
-	DefaultPolicy string       `mapstructure:"default_policy"`
-	Networks      []ACLNetwork `mapstructure:"networks"`
-	Rules         []ACLRule    `mapstructure:"rules"`
+	DefaultPolicy string           `mapstructure:"default_policy"`
+	Networks      []ACLNetwork     `mapstructure:"networks"`
+	Rules         []ACLRule        `mapstructure:"rules"`
+	RoleMappings  []ACLRoleMapping `mapstructure:"role_mappings"`
+
+	// RulesFile is a YAML file holding the rules under the rules key, it's reloaded when it changes.
+	RulesFile string `mapstructure:"rules_file"`
 }
 
 // ACLNetwork represents one ACL network group entry; "weak" coerces a single value into slice.
@@ -21,6 +25,32 @@ type ACLRule struct {
 	Networks  []string   `mapstructure:"networks"`
 	Resources []string   `mapstructure:"resources"`
 	Methods   []string   `mapstructure:"methods"`
+
+	// TrustedNetworks lowers a two_factor policy to one_factor for requests originating from these networks.
+	TrustedNetworks []string `mapstructure:"trusted_networks"`
+
+	// AccessGrants permits the users to give guests a time-boxed access to the resources matching the rule.
+	AccessGrants bool `mapstructure:"access_grants"`
+}
+
+// ACLRoleMapping represents the translation of the groups of the users into the role header of the applications of
+// the domains; "weak" coerces a single value into slice.
+type ACLRoleMapping struct {
+	Domains []string  `mapstructure:"domain,weak"`
+	Header  string    `mapstructure:"header"`
+	Default string    `mapstructure:"default"`
+	Roles   []ACLRole `mapstructure:"roles"`
+}
+
+// ACLRole represents the role given to the members of a group.
+type ACLRole struct {
+	Group string `mapstructure:"group"`
+	Role  string `mapstructure:"role"`
+}
+
+// DefaultACLRoleMapping represents the default configuration of the role mappings.
+var DefaultACLRoleMapping = ACLRoleMapping{
+	Header: "Remote-Role",
 }
 
 // DefaultACLNetwork represents the default configuration related to access control network group configuration.
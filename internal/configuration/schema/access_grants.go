@@ -0,0 +1,16 @@
+package schema
+
+// AccessGrantsConfiguration represents the configuration of the access grants, i.e. the time-boxed links the users
+// give to guests so they reach a protected resource without an account.
+type AccessGrantsConfiguration struct {
+	Parameter       string `mapstructure:"parameter"`
+	DefaultLifespan string `mapstructure:"default_lifespan"`
+	MaxLifespan     string `mapstructure:"max_lifespan"`
+}
+
+// DefaultAccessGrantsConfiguration represents the default configuration of the access grants.
+var DefaultAccessGrantsConfiguration = AccessGrantsConfiguration{
+	Parameter:       "authelia_grant",
+	DefaultLifespan: "1h",
+	MaxLifespan:     "1d",
+}
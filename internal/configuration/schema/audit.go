@@ -0,0 +1,43 @@
+package schema
+
+// AuditConfiguration represents the configuration of the audit log recording the security relevant events.
+type AuditConfiguration struct {
+	BufferSize int                        `mapstructure:"buffer_size"`
+	File       *AuditFileConfiguration    `mapstructure:"file"`
+	Syslog     *AuditSyslogConfiguration  `mapstructure:"syslog"`
+	Webhook    *AuditWebhookConfiguration `mapstructure:"webhook"`
+}
+
+// AuditFileConfiguration represents the configuration of the file the audit records are appended to.
+type AuditFileConfiguration struct {
+	Path string `mapstructure:"path"`
+}
+
+// AuditSyslogConfiguration represents the configuration of the syslog server the audit records are sent to.
+type AuditSyslogConfiguration struct {
+	Network string `mapstructure:"network"`
+	Address string `mapstructure:"address"`
+	Tag     string `mapstructure:"tag"`
+}
+
+// AuditWebhookConfiguration represents the configuration of the webhook the audit records are posted to.
+type AuditWebhookConfiguration struct {
+	URL     string `mapstructure:"url"`
+	Secret  string `mapstructure:"secret"`
+	Timeout string `mapstructure:"timeout"`
+}
+
+// DefaultAuditConfiguration represents the default configuration of the audit log.
+var DefaultAuditConfiguration = AuditConfiguration{
+	BufferSize: 1000,
+}
+
+// DefaultAuditSyslogConfiguration represents the default configuration of the syslog audit sink.
+var DefaultAuditSyslogConfiguration = AuditSyslogConfiguration{
+	Tag: "authelia",
+}
+
+// DefaultAuditWebhookConfiguration represents the default configuration of the webhook audit sink.
+var DefaultAuditWebhookConfiguration = AuditWebhookConfiguration{
+	Timeout: "5s",
+}
@@ -17,12 +17,28 @@ type LDAPAuthenticationBackendConfiguration struct {
 	Password             string     `mapstructure:"password"`
 	StartTLS             bool       `mapstructure:"start_tls"`
 	TLS                  *TLSConfig `mapstructure:"tls"`
+
+	// URLs are the LDAP servers the connections are spread across or failed over to, it's exclusive with URL.
+	URLs                []string               `mapstructure:"urls"`
+	LoadBalancing       string                 `mapstructure:"load_balancing"`
+	HealthCheckInterval string                 `mapstructure:"health_check_interval"`
+	Pool                *LDAPPoolConfiguration `mapstructure:"pool"`
+}
+
+// LDAPPoolConfiguration represents the configuration of the pool of the connections bound with the user of the LDAP
+// configuration, the connections binding the users to check their password are never pooled.
+type LDAPPoolConfiguration struct {
+	Size        int    `mapstructure:"size"`
+	IdleTimeout string `mapstructure:"idle_timeout"`
 }
 
 // FileAuthenticationBackendConfiguration represents the configuration related to file-based backend.
 type FileAuthenticationBackendConfiguration struct {
 	Path     string                 `mapstructure:"path"`
 	Password *PasswordConfiguration `mapstructure:"password"`
+
+	// Watch reloads the users database when the file changes.
+	Watch bool `mapstructure:"watch"`
 }
 
 // PasswordConfiguration represents the configuration related to password hashing.
@@ -39,8 +55,32 @@ type PasswordConfiguration struct {
 type AuthenticationBackendConfiguration struct {
 	DisableResetPassword bool                                    `mapstructure:"disable_reset_password"`
 	RefreshInterval      string                                  `mapstructure:"refresh_interval"`
+	Provider             string                                  `mapstructure:"provider"`
+	Options              map[string]interface{}                  `mapstructure:"options"`
 	LDAP                 *LDAPAuthenticationBackendConfiguration `mapstructure:"ldap"`
 	File                 *FileAuthenticationBackendConfiguration `mapstructure:"file"`
+	BreakGlass           *BreakGlassConfiguration                `mapstructure:"break_glass"`
+}
+
+// BreakGlassConfiguration represents the configuration of the emergency accounts checked locally so the operators
+// can still log in when the authentication backend is unreachable.
+type BreakGlassConfiguration struct {
+	Window   string                           `mapstructure:"window"`
+	Accounts []BreakGlassAccountConfiguration `mapstructure:"accounts"`
+}
+
+// BreakGlassAccountConfiguration represents the configuration of a break-glass account.
+type BreakGlassAccountConfiguration struct {
+	Username    string   `mapstructure:"username"`
+	Password    string   `mapstructure:"password"`
+	DisplayName string   `mapstructure:"displayname"`
+	Email       string   `mapstructure:"email"`
+	Groups      []string `mapstructure:"groups"`
+}
+
+// DefaultBreakGlassConfiguration represents the default configuration of the break-glass accounts.
+var DefaultBreakGlassConfiguration = BreakGlassConfiguration{
+	Window: "4h",
 }
 
 // DefaultPasswordConfiguration represents the default configuration related to Argon2id hashing.
@@ -80,11 +120,19 @@ var DefaultLDAPAuthenticationBackendConfiguration = LDAPAuthenticationBackendCon
 	TLS: &TLSConfig{
 		MinimumVersion: "TLS1.2",
 	},
+	LoadBalancing:       LDAPLoadBalancingFailover,
+	HealthCheckInterval: "30s",
+}
+
+// DefaultLDAPPoolConfiguration represents the default configuration of the pool of the LDAP connections.
+var DefaultLDAPPoolConfiguration = LDAPPoolConfiguration{
+	Size:        5,
+	IdleTimeout: "5m",
 }
 
 // DefaultLDAPAuthenticationBackendImplementationActiveDirectoryConfiguration represents the default LDAP config for the MSAD Implementation.
 var DefaultLDAPAuthenticationBackendImplementationActiveDirectoryConfiguration = LDAPAuthenticationBackendConfiguration{
-	UsersFilter:          "(&(|({username_attribute}={input})({mail_attribute}={input}))(objectCategory=person)(objectClass=user)(!userAccountControl:1.2.840.113556.1.4.803:=2)(!pwdLastSet=0))",
+	UsersFilter:          "(&(|({username_attribute}={input})({mail_attribute}={input}))(objectCategory=person)(objectClass=user))",
 	UsernameAttribute:    "sAMAccountName",
 	MailAttribute:        "mail",
 	DisplayNameAttribute: "displayName",
@@ -0,0 +1,14 @@
+package schema
+
+// BannerConfiguration represents the configuration of the messages displayed by the login portal.
+type BannerConfiguration struct {
+	Login        string                          `mapstructure:"login"`
+	Announcement BannerAnnouncementConfiguration `mapstructure:"announcement"`
+}
+
+// BannerAnnouncementConfiguration represents the configuration of the announcement displayed to the authenticated users
+// until they acknowledge it. The acknowledgments are tracked per ID so changing the ID displays it again.
+type BannerAnnouncementConfiguration struct {
+	ID      string `mapstructure:"id"`
+	Message string `mapstructure:"message"`
+}
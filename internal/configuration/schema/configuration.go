@@ -10,6 +10,7 @@ type Configuration struct {
 	CertificatesDirectory string `mapstructure:"certificates_directory"`
 	JWTSecret             string `mapstructure:"jwt_secret"`
 	DefaultRedirectionURL string `mapstructure:"default_redirection_url"`
+	CryptoProfile         string `mapstructure:"crypto_profile"`
 
 	// TODO: DEPRECATED START. Remove in 4.33.0.
 	LogLevel    string `mapstructure:"log_level"`
@@ -21,11 +22,27 @@ type Configuration struct {
 	IdentityProviders     IdentityProvidersConfiguration     `mapstructure:"identity_providers"`
 	AuthenticationBackend AuthenticationBackendConfiguration `mapstructure:"authentication_backend"`
 	Session               SessionConfiguration               `mapstructure:"session"`
+	Redirection           RedirectionConfiguration           `mapstructure:"redirection"`
+	Banner                BannerConfiguration                `mapstructure:"banner"`
+	Logout                *LogoutConfiguration               `mapstructure:"logout"`
 	TOTP                  *TOTPConfiguration                 `mapstructure:"totp"`
 	DuoAPI                *DuoAPIConfiguration               `mapstructure:"duo_api"`
+	Webauthn              WebauthnConfiguration              `mapstructure:"webauthn"`
 	AccessControl         AccessControlConfiguration         `mapstructure:"access_control"`
+	IdentityAssertion     *IdentityAssertionConfiguration    `mapstructure:"identity_assertion"`
+	AccessGrants          *AccessGrantsConfiguration         `mapstructure:"access_grants"`
+	ProxyAuthentication   *ProxyAuthenticationConfiguration  `mapstructure:"proxy_authentication"`
+	PasswordPolicy        PasswordPolicyConfiguration        `mapstructure:"password_policy"`
 	Regulation            *RegulationConfiguration           `mapstructure:"regulation"`
+	GeoIP                 *GeoIPConfiguration                `mapstructure:"geoip"`
+	Risk                  *RiskConfiguration                 `mapstructure:"risk"`
 	Storage               StorageConfiguration               `mapstructure:"storage"`
 	Notifier              *NotifierConfiguration             `mapstructure:"notifier"`
 	Server                ServerConfiguration                `mapstructure:"server"`
+	GRPC                  *GRPCConfiguration                 `mapstructure:"grpc"`
+	Metrics               *MetricsConfiguration              `mapstructure:"metrics"`
+	Audit                 *AuditConfiguration                `mapstructure:"audit"`
+	Resolver              *ResolverConfiguration             `mapstructure:"resolver"`
+	OutboundProxy         *OutboundProxyConfiguration        `mapstructure:"outbound_proxy"`
+	OutboundRequests      OutboundRequestsConfiguration      `mapstructure:"outbound_requests"`
 }
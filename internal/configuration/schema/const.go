@@ -23,3 +23,19 @@ const LDAPImplementationCustom = "custom"
 
 // LDAPImplementationActiveDirectory is the string for the Active Directory LDAP implementation.
 const LDAPImplementationActiveDirectory = "activedirectory"
+
+// LDAPLoadBalancingFailover is the load balancing of the LDAP servers connecting to the first healthy server in order.
+const LDAPLoadBalancingFailover = "failover"
+
+// LDAPLoadBalancingRoundRobin is the load balancing of the LDAP servers spreading the connections across the healthy
+// servers.
+const LDAPLoadBalancingRoundRobin = "round_robin"
+
+// CryptoProfileDefault is the crypto profile using the default algorithms of Authelia and Go.
+const CryptoProfileDefault = "default"
+
+// CryptoProfileFIPS is the crypto profile restricting the algorithms to the ones approved by FIPS 140-2.
+const CryptoProfileFIPS = "fips"
+
+// CryptoProfileStrict is the FIPS crypto profile only allowing TLS 1.3.
+const CryptoProfileStrict = "strict"
@@ -0,0 +1,7 @@
+//go:build !fips
+// +build !fips
+
+package schema
+
+// DefaultCryptoProfile is the crypto profile used when none is configured.
+var DefaultCryptoProfile = CryptoProfileDefault
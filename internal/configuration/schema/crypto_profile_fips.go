@@ -0,0 +1,8 @@
+//go:build fips
+// +build fips
+
+package schema
+
+// DefaultCryptoProfile is the crypto profile used when none is configured, the builds with the fips tag use the FIPS
+// profile.
+var DefaultCryptoProfile = CryptoProfileFIPS
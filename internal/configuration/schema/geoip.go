@@ -0,0 +1,6 @@
+package schema
+
+// GeoIPConfiguration represents the configuration related to the GeoIP lookups.
+type GeoIPConfiguration struct {
+	Database string `mapstructure:"database"`
+}
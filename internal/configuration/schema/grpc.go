@@ -0,0 +1,18 @@
+package schema
+
+// GRPCConfiguration represents the configuration of the gRPC server exposing the verify and admin APIs.
+type GRPCConfiguration struct {
+	Host          string `mapstructure:"host"`
+	Port          int    `mapstructure:"port"`
+	AddressFamily string `mapstructure:"address_family"`
+	TLSCert       string `mapstructure:"tls_cert"`
+	TLSKey        string `mapstructure:"tls_key"`
+	AdminToken    string `mapstructure:"admin_token"`
+}
+
+// DefaultGRPCConfiguration represents the default configuration of the gRPC server.
+var DefaultGRPCConfiguration = GRPCConfiguration{
+	Host:          "0.0.0.0",
+	Port:          9092,
+	AddressFamily: "dual",
+}
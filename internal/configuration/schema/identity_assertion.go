@@ -0,0 +1,16 @@
+package schema
+
+// IdentityAssertionConfiguration represents the configuration of the signed identity assertion forwarded to the
+// applications with the identity headers.
+type IdentityAssertionConfiguration struct {
+	Header   string `mapstructure:"header"`
+	Issuer   string `mapstructure:"issuer"`
+	Lifespan string `mapstructure:"lifespan"`
+}
+
+// DefaultIdentityAssertionConfiguration represents the default configuration of the identity assertion.
+var DefaultIdentityAssertionConfiguration = IdentityAssertionConfiguration{
+	Header:   "Remote-Assertion",
+	Issuer:   "authelia",
+	Lifespan: "1m",
+}
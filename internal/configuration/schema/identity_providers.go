@@ -13,6 +13,16 @@ type OpenIDConnectConfiguration struct {
 	HMACSecret       string `mapstructure:"hmac_secret"`
 	IssuerPrivateKey string `mapstructure:"issuer_private_key"`
 
+	// PreviousHMACSecret is the HMAC secret the tokens were signed with before the HMAC secret was rotated, the tokens
+	// it signed are accepted until its lifespan has elapsed since Authelia started.
+	PreviousHMACSecret         string        `mapstructure:"previous_hmac_secret"`
+	PreviousHMACSecretLifespan time.Duration `mapstructure:"previous_hmac_secret_lifespan"`
+
+	// IssuerPrivateKeys are issuer keys in addition to the issuer private key, they are all published by the JWKS
+	// endpoint so the tokens signed by any of them can be verified. The tokens are signed by the active key.
+	IssuerPrivateKeys   []OpenIDConnectIssuerKeyConfiguration `mapstructure:"issuer_private_keys"`
+	KeyRotationInterval time.Duration                         `mapstructure:"key_rotation_interval"`
+
 	AccessTokenLifespan       time.Duration `mapstructure:"access_token_lifespan"`
 	AuthorizeCodeLifespan     time.Duration `mapstructure:"authorize_code_lifespan"`
 	IDTokenLifespan           time.Duration `mapstructure:"id_token_lifespan"`
@@ -20,7 +30,31 @@ type OpenIDConnectConfiguration struct {
 	EnableClientDebugMessages bool          `mapstructure:"enable_client_debug_messages"`
 	MinimumParameterEntropy   int           `mapstructure:"minimum_parameter_entropy"`
 
+	// RememberConsentLifespan is how long the consent of a user who asked to remember it is given again without asking.
+	RememberConsentLifespan time.Duration `mapstructure:"remember_consent_lifespan"`
+
 	Clients []OpenIDConnectClientConfiguration `mapstructure:"clients"`
+
+	// ClientsFile is a YAML file holding the clients under the clients key, it's reloaded when it changes.
+	ClientsFile string `mapstructure:"clients_file"`
+
+	// DynamicRegistration enables the registration endpoint the trusted callers register clients with at runtime, the
+	// registered clients are saved in the storage.
+	DynamicRegistration *OpenIDConnectDynamicRegistrationConfiguration `mapstructure:"dynamic_registration"`
+}
+
+// OpenIDConnectDynamicRegistrationConfiguration configuration of the dynamic registration of the OpenID Connect clients.
+type OpenIDConnectDynamicRegistrationConfiguration struct {
+	// Policy is the authorization policy of the registered clients, they can't choose it.
+	Policy string `mapstructure:"authorization_policy"`
+	// Scopes are the scopes the registered clients may request.
+	Scopes []string `mapstructure:"scopes"`
+}
+
+// OpenIDConnectIssuerKeyConfiguration configuration for an issuer key of OpenID Connect.
+type OpenIDConnectIssuerKeyConfiguration struct {
+	Key    string `mapstructure:"key"`
+	Active bool   `mapstructure:"active"`
 }
 
 // OpenIDConnectClientConfiguration configuration for an OpenID Connect client.
@@ -35,7 +69,18 @@ type OpenIDConnectClientConfiguration struct {
 	ResponseTypes []string `mapstructure:"response_types"`
 	ResponseModes []string `mapstructure:"response_modes"`
 
+	// Audience are the audiences the client can request, e.g. the APIs the access tokens obtained with the
+	// client_credentials grant are meant for. The client ID is always granted as an audience.
+	Audience []string `mapstructure:"audience"`
+
 	UserinfoSigningAlgorithm string `mapstructure:"userinfo_signing_algorithm"`
+
+	// The logout URIs of the client, the back-channel one is sent a logout token and the front-channel one is loaded in
+	// a frame of the portal when the session the user authorized the client in ends. The post logout redirect URIs are
+	// the ones the client can redirect the user to once logged out by the end session endpoint.
+	BackChannelLogoutURI   string   `mapstructure:"backchannel_logout_uri"`
+	FrontChannelLogoutURI  string   `mapstructure:"frontchannel_logout_uri"`
+	PostLogoutRedirectURIs []string `mapstructure:"post_logout_redirect_uris"`
 }
 
 // DefaultOpenIDConnectConfiguration contains defaults for OIDC.
@@ -44,6 +89,8 @@ var DefaultOpenIDConnectConfiguration = OpenIDConnectConfiguration{
 	AuthorizeCodeLifespan: time.Minute,
 	IDTokenLifespan:       time.Hour,
 	RefreshTokenLifespan:  time.Minute * 90,
+
+	RememberConsentLifespan: time.Hour * 24 * 30,
 }
 
 // DefaultOpenIDConnectClientConfiguration contains defaults for OIDC Clients.
@@ -6,6 +6,20 @@ type LogConfiguration struct {
 	Format     string `mapstructure:"format"`
 	FilePath   string `mapstructure:"file_path"`
 	KeepStdout bool   `mapstructure:"keep_stdout"`
+
+	Minimization *LogMinimizationConfiguration `mapstructure:"minimization"`
+}
+
+// LogMinimizationConfiguration represents the configuration of the minimization of the personal data written to the
+// application and audit logs, each kind of data is either kept, hashed or truncated.
+type LogMinimizationConfiguration struct {
+	Usernames   string `mapstructure:"usernames"`
+	IPAddresses string `mapstructure:"ip_addresses"`
+	Emails      string `mapstructure:"emails"`
+
+	// HMACKey is the key of the HMAC the data is hashed with, the hashes of a value are the same as long as it doesn't
+	// change so the entries of a user can still be correlated.
+	HMACKey string `mapstructure:"hmac_key"`
 }
 
 // DefaultLoggingConfiguration is the default logging configuration.
@@ -13,3 +27,11 @@ var DefaultLoggingConfiguration = LogConfiguration{
 	Level:  "info",
 	Format: "text",
 }
+
+// DefaultLogMinimizationConfiguration is the default configuration of the minimization of the personal data in the
+// logs, the data is kept.
+var DefaultLogMinimizationConfiguration = LogMinimizationConfiguration{
+	Usernames:   "none",
+	IPAddresses: "none",
+	Emails:      "none",
+}
@@ -0,0 +1,28 @@
+package schema
+
+// LogoutConfiguration represents the configuration of the logout of the applications when the users log out.
+type LogoutConfiguration struct {
+	Timeout      string                           `mapstructure:"timeout"`
+	Applications []LogoutApplicationConfiguration `mapstructure:"applications"`
+}
+
+// LogoutApplicationConfiguration represents the configuration of the logout URL of an application.
+type LogoutApplicationConfiguration struct {
+	Name        string `mapstructure:"name"`
+	URL         string `mapstructure:"url"`
+	Method      string `mapstructure:"method"`
+	Body        string `mapstructure:"body"`
+	ContentType string `mapstructure:"content_type"`
+}
+
+// DefaultLogoutConfiguration represents the default configuration parameters of the logout of the applications.
+var DefaultLogoutConfiguration = LogoutConfiguration{
+	Timeout: "5s",
+}
+
+// DefaultLogoutApplicationConfiguration represents the default configuration parameters of the logout URL of an
+// application.
+var DefaultLogoutApplicationConfiguration = LogoutApplicationConfiguration{
+	Method:      "GET",
+	ContentType: "application/x-www-form-urlencoded",
+}
@@ -0,0 +1,15 @@
+package schema
+
+// MetricsConfiguration represents the configuration of the server exposing the metrics in the Prometheus format.
+type MetricsConfiguration struct {
+	Host          string `mapstructure:"host"`
+	Port          int    `mapstructure:"port"`
+	AddressFamily string `mapstructure:"address_family"`
+}
+
+// DefaultMetricsConfiguration represents the default configuration of the metrics server.
+var DefaultMetricsConfiguration = MetricsConfiguration{
+	Host:          "0.0.0.0",
+	Port:          9959,
+	AddressFamily: "dual",
+}
@@ -17,21 +17,89 @@ type SMTPNotifierConfiguration struct {
 	StartupCheckAddress string     `mapstructure:"startup_check_address"`
 	DisableRequireTLS   bool       `mapstructure:"disable_require_tls"`
 	DisableHTMLEmails   bool       `mapstructure:"disable_html_emails"`
+	Timeout             string     `mapstructure:"timeout"`
 	TLS                 *TLSConfig `mapstructure:"tls"`
+
+	Categories SMTPNotifierCategoriesConfiguration `mapstructure:"categories"`
+}
+
+// SMTPNotifierCategoriesConfiguration represents the customization of the emails of each category of notification.
+type SMTPNotifierCategoriesConfiguration struct {
+	Verification  *SMTPNotifierCategoryConfiguration `mapstructure:"verification"`
+	SecurityAlert *SMTPNotifierCategoryConfiguration `mapstructure:"security_alert"`
+}
+
+// SMTPNotifierCategoryConfiguration represents the sender display name, the reply-to address and the subject of the
+// emails of a category of notification. The subject defaults to the subject of the SMTP notifier.
+type SMTPNotifierCategoryConfiguration struct {
+	SenderName string `mapstructure:"sender_name"`
+	ReplyTo    string `mapstructure:"reply_to"`
+	Subject    string `mapstructure:"subject"`
 }
 
 // NotifierConfiguration represents the configuration of the notifier to use when sending notifications to users.
 type NotifierConfiguration struct {
 	DisableStartupCheck bool                             `mapstructure:"disable_startup_check"`
+	Provider            string                           `mapstructure:"provider"`
+	Options             map[string]interface{}           `mapstructure:"options"`
 	FileSystem          *FileSystemNotifierConfiguration `mapstructure:"filesystem"`
 	SMTP                *SMTPNotifierConfiguration       `mapstructure:"smtp"`
+	Queue               *NotifierQueueConfiguration      `mapstructure:"queue"`
+	Throttling          *NotifierThrottlingConfiguration `mapstructure:"throttling"`
+	Operator            *NotifierOperatorConfiguration   `mapstructure:"operator"`
+}
+
+// NotifierQueueConfiguration represents the configuration of the queue the notifications are sent through.
+type NotifierQueueConfiguration struct {
+	Workers    int    `mapstructure:"workers"`
+	Size       int    `mapstructure:"size"`
+	Timeout    string `mapstructure:"timeout"`
+	MaxRetries int    `mapstructure:"max_retries"`
+	Backoff    string `mapstructure:"backoff"`
+}
+
+// NotifierThrottlingConfiguration represents the configuration of the throttling of the security notifications.
+type NotifierThrottlingConfiguration struct {
+	Interval       string `mapstructure:"interval"`
+	FailedAttempts int    `mapstructure:"failed_attempts"`
+}
+
+// NotifierOperatorConfiguration represents the configuration of the alerts sent to the operators of Authelia about the
+// events of the server, by email through the notifier and/or to a webhook.
+type NotifierOperatorConfiguration struct {
+	Email             string `mapstructure:"email"`
+	WebhookURL        string `mapstructure:"webhook_url"`
+	CertificateExpiry string `mapstructure:"certificate_expiry"`
+	CheckInterval     string `mapstructure:"check_interval"`
 }
 
 // DefaultSMTPNotifierConfiguration represents default configuration parameters for the SMTP notifier.
 var DefaultSMTPNotifierConfiguration = SMTPNotifierConfiguration{
 	Subject:    "[Authelia] {title}",
 	Identifier: "localhost",
+	Timeout:    "20s",
 	TLS: &TLSConfig{
 		MinimumVersion: "TLS1.2",
 	},
 }
+
+// DefaultNotifierQueueConfiguration represents default configuration parameters for the notifications queue.
+var DefaultNotifierQueueConfiguration = NotifierQueueConfiguration{
+	Workers:    2,
+	Size:       100,
+	Timeout:    "30s",
+	MaxRetries: 3,
+	Backoff:    "5s",
+}
+
+// DefaultNotifierThrottlingConfiguration represents default configuration parameters for the throttling of the
+// security notifications.
+var DefaultNotifierThrottlingConfiguration = NotifierThrottlingConfiguration{
+	Interval: "1h",
+}
+
+// DefaultNotifierOperatorConfiguration represents default configuration parameters for the alerts of the operators.
+var DefaultNotifierOperatorConfiguration = NotifierOperatorConfiguration{
+	CertificateExpiry: "2w",
+	CheckInterval:     "5m",
+}
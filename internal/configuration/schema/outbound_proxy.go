@@ -0,0 +1,8 @@
+package schema
+
+// OutboundProxyConfiguration represents the configuration of the proxy of the outbound HTTP requests, e.g. to the Duo
+// API, the logout URLs of the applications or the external risk engine.
+type OutboundProxyConfiguration struct {
+	URL     string   `mapstructure:"url"`
+	NoProxy []string `mapstructure:"no_proxy"`
+}
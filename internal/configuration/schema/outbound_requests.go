@@ -0,0 +1,19 @@
+package schema
+
+// OutboundRequestsConfiguration represents the policy of the outbound HTTP requests initiated by the server, e.g. to
+// the logout URLs of the applications or the external risk engine.
+type OutboundRequestsConfiguration struct {
+	AllowedHosts    []string `mapstructure:"allowed_hosts"`
+	BlockedNetworks []string `mapstructure:"blocked_networks"`
+}
+
+// DefaultOutboundRequestsBlockedNetworks are the networks the outbound HTTP requests can never reach: the unspecified
+// and link-local networks which include the metadata services of the cloud providers.
+var DefaultOutboundRequestsBlockedNetworks = []string{
+	"0.0.0.0/8",
+	"169.254.0.0/16",
+	"100.100.100.200/32",
+	"::/128",
+	"fe80::/10",
+	"fd00:ec2::254/128",
+}
@@ -0,0 +1,13 @@
+package schema
+
+// PasswordPolicyConfiguration represents the rules the new passwords of the users must follow. The rules are enforced
+// by the server when a password is reset and exposed to the portal so it can validate the password as it's typed.
+type PasswordPolicyConfiguration struct {
+	MinLength        int  `mapstructure:"min_length"`
+	MaxLength        int  `mapstructure:"max_length"`
+	RequireUppercase bool `mapstructure:"require_uppercase"`
+	RequireLowercase bool `mapstructure:"require_lowercase"`
+	RequireNumber    bool `mapstructure:"require_number"`
+	RequireSpecial   bool `mapstructure:"require_special"`
+	MinScore         int  `mapstructure:"min_score"`
+}
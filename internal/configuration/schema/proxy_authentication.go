@@ -0,0 +1,19 @@
+package schema
+
+// ProxyAuthenticationConfiguration represents the configuration of the shared secrets the reverse proxies must send to
+// the verify endpoint.
+type ProxyAuthenticationConfiguration struct {
+	Header string                    `mapstructure:"header"`
+	Rules  []ProxyAuthenticationRule `mapstructure:"rules"`
+}
+
+// ProxyAuthenticationRule represents the shared secrets required from the reverse proxies of the source networks.
+type ProxyAuthenticationRule struct {
+	Networks []string `mapstructure:"networks"`
+	Secrets  []string `mapstructure:"secrets"`
+}
+
+// DefaultProxyAuthenticationConfiguration represents the default configuration of the proxy authentication.
+var DefaultProxyAuthenticationConfiguration = ProxyAuthenticationConfiguration{
+	Header: "Authelia-Proxy-Secret",
+}
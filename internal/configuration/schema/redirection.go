@@ -0,0 +1,25 @@
+package schema
+
+// RedirectionConfiguration represents the configuration of the redirections to and from the login portal.
+type RedirectionConfiguration struct {
+	LoginURLTemplate string   `mapstructure:"login_url_template"`
+	AllowedDomains   []string `mapstructure:"allowed_domains"`
+	StripParameters  []string `mapstructure:"strip_parameters"`
+	SignedState      bool     `mapstructure:"signed_state"`
+	StateLifespan    string   `mapstructure:"state_lifespan"`
+
+	DefaultURLs []RedirectionDefaultURLConfiguration `mapstructure:"default_urls"`
+}
+
+// RedirectionDefaultURLConfiguration represents the default redirection URL of the users matching the subjects, it
+// replaces the global default_redirection_url for them; "weak" coerces a single value into slice.
+type RedirectionDefaultURLConfiguration struct {
+	URL      string     `mapstructure:"url"`
+	Subjects [][]string `mapstructure:"subject,weak"`
+}
+
+// DefaultRedirectionConfiguration is the default redirection configuration.
+var DefaultRedirectionConfiguration = RedirectionConfiguration{
+	LoginURLTemplate: "{{ .PortalURL }}?rd={{ queryEscape .TargetURL }}{{ with .Method }}&rm={{ . }}{{ end }}{{ with .State }}&state={{ . }}{{ end }}",
+	StateLifespan:    "10m",
+}
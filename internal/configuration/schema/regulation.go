@@ -2,6 +2,15 @@ package schema
 
 // RegulationConfiguration represents the configuration related to regulation.
 type RegulationConfiguration struct {
+	MaxRetries   int                                  `mapstructure:"max_retries"`
+	FindTime     string                               `mapstructure:"find_time"`
+	BanTime      string                               `mapstructure:"ban_time"`
+	SecondFactor *RegulationSecondFactorConfiguration `mapstructure:"second_factor"`
+}
+
+// RegulationSecondFactorConfiguration represents the configuration related to the regulation of the second factor
+// attempts, which has its own thresholds.
+type RegulationSecondFactorConfiguration struct {
 	MaxRetries int    `mapstructure:"max_retries"`
 	FindTime   string `mapstructure:"find_time"`
 	BanTime    string `mapstructure:"ban_time"`
@@ -13,3 +22,11 @@ var DefaultRegulationConfiguration = RegulationConfiguration{
 	FindTime:   "2m",
 	BanTime:    "5m",
 }
+
+// DefaultRegulationSecondFactorConfiguration represents default configuration parameters for the regulation of the
+// second factor attempts.
+var DefaultRegulationSecondFactorConfiguration = RegulationSecondFactorConfiguration{
+	MaxRetries: 5,
+	FindTime:   "5m",
+	BanTime:    "15m",
+}
@@ -0,0 +1,13 @@
+package schema
+
+// ResolverConfiguration represents the configuration of the DNS resolver used by the outbound connections.
+type ResolverConfiguration struct {
+	Addresses []string `mapstructure:"addresses"`
+	Timeout   string   `mapstructure:"timeout"`
+	Rotate    bool     `mapstructure:"rotate"`
+}
+
+// DefaultResolverConfiguration represents the default values of the ResolverConfiguration.
+var DefaultResolverConfiguration = ResolverConfiguration{
+	Timeout: "5s",
+}
@@ -0,0 +1,29 @@
+package schema
+
+// RiskConfiguration represents the configuration related to the risk based authentication.
+type RiskConfiguration struct {
+	History                string                     `mapstructure:"history"`
+	ImpossibleTravelWindow string                     `mapstructure:"impossible_travel_window"`
+	StepUpScore            int                        `mapstructure:"step_up_score"`
+	DenyScore              int                        `mapstructure:"deny_score"`
+	External               *RiskExternalConfiguration `mapstructure:"external"`
+}
+
+// RiskExternalConfiguration represents the configuration of an external risk scoring service.
+type RiskExternalConfiguration struct {
+	URL     string `mapstructure:"url"`
+	Timeout string `mapstructure:"timeout"`
+}
+
+// DefaultRiskConfiguration represents the default configuration parameters of the risk based authentication.
+var DefaultRiskConfiguration = RiskConfiguration{
+	History:                "30d",
+	ImpossibleTravelWindow: "2h",
+	StepUpScore:            30,
+	DenyScore:              80,
+}
+
+// DefaultRiskExternalConfiguration represents the default configuration parameters of an external risk scoring service.
+var DefaultRiskExternalConfiguration = RiskExternalConfiguration{
+	Timeout: "5s",
+}
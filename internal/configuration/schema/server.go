@@ -2,15 +2,85 @@ package schema
 
 // ServerConfiguration represents the configuration of the http server.
 type ServerConfiguration struct {
-	Path            string `mapstructure:"path"`
-	ReadBufferSize  int    `mapstructure:"read_buffer_size"`
-	WriteBufferSize int    `mapstructure:"write_buffer_size"`
-	EnablePprof     bool   `mapstructure:"enable_endpoint_pprof"`
-	EnableExpvars   bool   `mapstructure:"enable_endpoint_expvars"`
+	Path                string                          `mapstructure:"path"`
+	ExternalURL         string                          `mapstructure:"external_url"`
+	ReadBufferSize      int                             `mapstructure:"read_buffer_size"`
+	WriteBufferSize     int                             `mapstructure:"write_buffer_size"`
+	EnablePprof         bool                            `mapstructure:"enable_endpoint_pprof"`
+	EnableExpvars       bool                            `mapstructure:"enable_endpoint_expvars"`
+	AdminGroup          string                          `mapstructure:"admin_group"`
+	AdminRoles          ServerAdminRolesConfiguration   `mapstructure:"admin_roles"`
+	Compression         *ServerCompressionConfiguration `mapstructure:"compression"`
+	RateLimit           *ServerRateLimitConfiguration   `mapstructure:"rate_limit"`
+	CORS                *ServerCORSConfiguration        `mapstructure:"cors"`
+	MaxConnectionsPerIP int                             `mapstructure:"max_connections_per_ip"`
+	MaxRequestBodySize  int                             `mapstructure:"max_request_body_size"`
+	ReadTimeout         string                          `mapstructure:"read_timeout"`
+	WriteTimeout        string                          `mapstructure:"write_timeout"`
+	IdleTimeout         string                          `mapstructure:"idle_timeout"`
+	DisableKeepAlive    bool                            `mapstructure:"disable_keep_alive"`
+	AddressFamily       string                          `mapstructure:"address_family"`
+}
+
+// ServerCompressionConfiguration represents the configuration of the compression of the responses of the http server.
+type ServerCompressionConfiguration struct {
+	Algorithms   []string `mapstructure:"algorithms"`
+	MinimumSize  int      `mapstructure:"minimum_size"`
+	ContentTypes []string `mapstructure:"content_types"`
+}
+
+// ServerRateLimitConfiguration represents the configuration of the number of requests a client address can send to
+// the API in each period.
+type ServerRateLimitConfiguration struct {
+	Requests int    `mapstructure:"requests"`
+	Period   string `mapstructure:"period"`
+}
+
+// ServerCORSConfiguration represents the configuration of the origins allowed to send cross-origin requests to the API.
+type ServerCORSConfiguration struct {
+	AllowedOrigins []string `mapstructure:"allowed_origins"`
+	MaxAge         int      `mapstructure:"max_age"`
+}
+
+// ServerAdminRolesConfiguration represents the groups whose members are granted each role of the admin API.
+type ServerAdminRolesConfiguration struct {
+	Auditor  []string `mapstructure:"auditor"`
+	Helpdesk []string `mapstructure:"helpdesk"`
+	Admin    []string `mapstructure:"admin"`
 }
 
 // DefaultServerConfiguration represents the default values of the ServerConfiguration.
 var DefaultServerConfiguration = ServerConfiguration{
-	ReadBufferSize:  4096,
-	WriteBufferSize: 4096,
+	ReadBufferSize:     4096,
+	WriteBufferSize:    4096,
+	MaxRequestBodySize: 4 * 1024 * 1024,
+	ReadTimeout:        "6s",
+	WriteTimeout:       "0",
+	IdleTimeout:        "30s",
+	AddressFamily:      "dual",
+}
+
+// DefaultServerCompressionConfiguration represents the default values of the ServerCompressionConfiguration.
+var DefaultServerCompressionConfiguration = ServerCompressionConfiguration{
+	Algorithms:  []string{"br", "gzip"},
+	MinimumSize: 1024,
+	ContentTypes: []string{
+		"application/json",
+		"application/javascript",
+		"text/html",
+		"text/css",
+		"text/plain",
+		"image/svg+xml",
+	},
+}
+
+// DefaultServerRateLimitConfiguration represents the default values of the ServerRateLimitConfiguration.
+var DefaultServerRateLimitConfiguration = ServerRateLimitConfiguration{
+	Requests: 100,
+	Period:   "1m",
+}
+
+// DefaultServerCORSConfiguration represents the default values of the ServerCORSConfiguration.
+var DefaultServerCORSConfiguration = ServerCORSConfiguration{
+	MaxAge: 600,
 }
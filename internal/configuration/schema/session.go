@@ -22,6 +22,7 @@ type RedisSessionConfiguration struct {
 	Username                 string                              `mapstructure:"username"`
 	Password                 string                              `mapstructure:"password"`
 	DatabaseIndex            int                                 `mapstructure:"database_index"`
+	KeyPrefix                string                              `mapstructure:"key_prefix"`
 	MaximumActiveConnections int                                 `mapstructure:"maximum_active_connections"`
 	MinimumIdleConnections   int                                 `mapstructure:"minimum_idle_connections"`
 	TLS                      *TLSConfig                          `mapstructure:"tls"`
@@ -38,6 +39,35 @@ type SessionConfiguration struct {
 	Inactivity         string                     `mapstructure:"inactivity"`
 	RememberMeDuration string                     `mapstructure:"remember_me_duration"`
 	Redis              *RedisSessionConfiguration `mapstructure:"redis"`
+
+	// PreviousSecret is the secret the sessions were encrypted with before the secret was rotated, the sessions it
+	// encrypted are accepted until its lifespan has elapsed since Authelia started.
+	PreviousSecret         string `mapstructure:"previous_secret"`
+	PreviousSecretLifespan string `mapstructure:"previous_secret_lifespan"`
+
+	Pinning          SessionPinningConfiguration          `mapstructure:"pinning"`
+	ImpossibleTravel SessionImpossibleTravelConfiguration `mapstructure:"impossible_travel"`
+}
+
+// SessionPinningConfiguration represents the configuration binding the sessions to the client which created them, the
+// network of its IP address and the TLS fingerprint forwarded by the proxy.
+type SessionPinningConfiguration struct {
+	IP                   bool   `mapstructure:"ip"`
+	TLSFingerprint       bool   `mapstructure:"tls_fingerprint"`
+	TLSFingerprintHeader string `mapstructure:"tls_fingerprint_header"`
+	Strictness           string `mapstructure:"strictness"`
+}
+
+// SessionImpossibleTravelConfiguration represents the configuration invalidating the sessions used from two countries
+// within a window too short for the user to have travelled between them.
+type SessionImpossibleTravelConfiguration struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Window  string `mapstructure:"window"`
+}
+
+// DefaultRedisSessionConfiguration is the default redis session configuration.
+var DefaultRedisSessionConfiguration = RedisSessionConfiguration{
+	KeyPrefix: "authelia-session",
 }
 
 // DefaultSessionConfiguration is the default session configuration.
@@ -47,4 +77,17 @@ var DefaultSessionConfiguration = SessionConfiguration{
 	Inactivity:         "5m",
 	RememberMeDuration: "1M",
 	SameSite:           "lax",
+	Pinning:            DefaultSessionPinningConfiguration,
+	ImpossibleTravel:   DefaultSessionImpossibleTravelConfiguration,
+}
+
+// DefaultSessionPinningConfiguration is the default session pinning configuration.
+var DefaultSessionPinningConfiguration = SessionPinningConfiguration{
+	TLSFingerprintHeader: "X-JA3-Fingerprint",
+	Strictness:           "strict",
+}
+
+// DefaultSessionImpossibleTravelConfiguration is the default session impossible travel configuration.
+var DefaultSessionImpossibleTravelConfiguration = SessionImpossibleTravelConfiguration{
+	Window: "2h",
 }
@@ -5,4 +5,34 @@ type TLSConfig struct {
 	MinimumVersion string `mapstructure:"minimum_version"`
 	SkipVerify     bool   `mapstructure:"skip_verify"`
 	ServerName     string `mapstructure:"server_name"`
+
+	// The names of the cipher suites and curves used for the connections, the defaults of crypto/tls when empty.
+	CipherSuites     []string `mapstructure:"cipher_suites"`
+	CurvePreferences []string `mapstructure:"curve_preferences"`
+
+	// The paths of the PEM encoded certificate authorities trusted in addition to the certificates directory.
+	CertificateAuthorities []string `mapstructure:"certificate_authorities"`
+
+	Revocation TLSRevocationConfig `mapstructure:"revocation"`
+
+	// The paths of the PEM encoded certificate and private key presented to the server for client authentication.
+	ClientCertificate string `mapstructure:"client_certificate"`
+	ClientKey         string `mapstructure:"client_key"`
 }
+
+// TLSRevocationConfig is a representation of the configuration of the revocation checks of the server certificates.
+type TLSRevocationConfig struct {
+	OCSP    string `mapstructure:"ocsp"`
+	CRLFile string `mapstructure:"crl_file"`
+}
+
+const (
+	// OCSPModeDisable disables the OCSP checks.
+	OCSPModeDisable = "disable"
+
+	// OCSPModeSoftFail rejects the revoked certificates but trusts the certificates whose status can't be determined.
+	OCSPModeSoftFail = "soft_fail"
+
+	// OCSPModeHardFail only trusts the certificates known to be valid by the OCSP responder.
+	OCSPModeHardFail = "hard_fail"
+)
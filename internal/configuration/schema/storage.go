@@ -12,6 +12,28 @@ type SQLStorageConfiguration struct {
 	Database string `mapstructure:"database"`
 	Username string `mapstructure:"username"`
 	Password string `mapstructure:"password"`
+
+	// ReadReplicas are the addresses of the read replicas of the database, in host or host:port form.
+	ReadReplicas []string `mapstructure:"read_replicas"`
+
+	Pool SQLStoragePoolConfiguration `mapstructure:"pool"`
+}
+
+// SQLStoragePoolConfiguration represents the configuration of the pool of connections to the SQL database, it applies
+// to the connections of the read replicas too.
+type SQLStoragePoolConfiguration struct {
+	MaxOpenConnections    int    `mapstructure:"max_open_connections"`
+	MaxIdleConnections    int    `mapstructure:"max_idle_connections"`
+	ConnectionMaxLifetime string `mapstructure:"connection_max_lifetime"`
+	ConnectionMaxIdleTime string `mapstructure:"connection_max_idle_time"`
+}
+
+// DefaultSQLStoragePoolConfiguration represents the default configuration of the pool of connections to the SQL
+// database, the connections are kept open and reused as long as they work.
+var DefaultSQLStoragePoolConfiguration = SQLStoragePoolConfiguration{
+	MaxIdleConnections:    2,
+	ConnectionMaxLifetime: "0",
+	ConnectionMaxIdleTime: "0",
 }
 
 // MySQLStorageConfiguration represents the configuration of a MySQL database.
@@ -23,11 +45,71 @@ type MySQLStorageConfiguration struct {
 type PostgreSQLStorageConfiguration struct {
 	SQLStorageConfiguration `mapstructure:",squash"`
 	SSLMode                 string `mapstructure:"sslmode"`
+
+	// SSLRootCertificate is the path of the certificate of the authority verifying the certificate of the server,
+	// SSLCertificate and SSLKey are the paths of the client certificate and its key.
+	SSLRootCertificate string `mapstructure:"ssl_root_certificate"`
+	SSLCertificate     string `mapstructure:"ssl_certificate"`
+	SSLKey             string `mapstructure:"ssl_key"`
+
+	// FallbackHosts are the addresses of the standby servers, in host or host:port form. The connections are made to
+	// the first server of the host and the fallback hosts accepting writes, i.e. the primary after a failover.
+	FallbackHosts []string `mapstructure:"fallback_hosts"`
+
+	// DisablePreparedStatements disables the server-side prepared statements, which aren't supported by some
+	// connection poolers such as PgBouncer in transaction mode.
+	DisablePreparedStatements bool `mapstructure:"disable_prepared_statements"`
 }
 
 // StorageConfiguration represents the configuration of the storage backend.
 type StorageConfiguration struct {
+	Provider   string                          `mapstructure:"provider"`
+	Options    map[string]interface{}          `mapstructure:"options"`
 	Local      *LocalStorageConfiguration      `mapstructure:"local"`
 	MySQL      *MySQLStorageConfiguration      `mapstructure:"mysql"`
 	PostgreSQL *PostgreSQLStorageConfiguration `mapstructure:"postgres"`
+	Retention  StorageRetentionConfiguration   `mapstructure:"retention"`
+	Cache      StorageCacheConfiguration       `mapstructure:"cache"`
+
+	// EncryptionKey is the key the secrets of the second factor devices are encrypted with at rest, they're stored in
+	// plaintext when it's empty.
+	EncryptionKey string `mapstructure:"encryption_key"`
+
+	// PreviousEncryptionKey is the key the secrets were encrypted with before the key was rotated, the secrets it
+	// encrypted are still decrypted until they're all encrypted with the encryption key.
+	PreviousEncryptionKey string `mapstructure:"previous_encryption_key"`
+
+	MigrationTarget *StorageMigrationTargetConfiguration `mapstructure:"migration_target"`
+}
+
+// StorageMigrationTargetConfiguration represents the configuration of the storage backend the data is migrated to.
+// The writes are sent to both backends while the reads are still served by the current one.
+type StorageMigrationTargetConfiguration struct {
+	Local      *LocalStorageConfiguration      `mapstructure:"local"`
+	MySQL      *MySQLStorageConfiguration      `mapstructure:"mysql"`
+	PostgreSQL *PostgreSQLStorageConfiguration `mapstructure:"postgres"`
+}
+
+// StorageRetentionConfiguration represents the configuration of the jobs pruning the stale data of the storage.
+type StorageRetentionConfiguration struct {
+	Interval           string `mapstructure:"interval"`
+	AuthenticationLogs string `mapstructure:"authentication_logs"`
+}
+
+// DefaultStorageRetentionConfiguration represents the default configuration of the storage retention jobs.
+var DefaultStorageRetentionConfiguration = StorageRetentionConfiguration{
+	Interval:           "1h",
+	AuthenticationLogs: "0",
+}
+
+// StorageCacheConfiguration represents the configuration of the in-memory cache of the preferences and second factor
+// lookups of the users.
+type StorageCacheConfiguration struct {
+	Disable bool `mapstructure:"disable"`
+	Size    int  `mapstructure:"size"`
+}
+
+// DefaultStorageCacheConfiguration represents the default configuration of the storage cache.
+var DefaultStorageCacheConfiguration = StorageCacheConfiguration{
+	Size: 1000,
 }
@@ -0,0 +1,21 @@
+package schema
+
+// WebauthnConfiguration represents the configuration related to WebAuthn credentials.
+type WebauthnConfiguration struct {
+	DisplayName  string                            `mapstructure:"display_name"`
+	Passwordless WebauthnPasswordlessConfiguration `mapstructure:"passwordless"`
+}
+
+// WebauthnPasswordlessConfiguration represents the configuration of the passwordless login with WebAuthn passkeys.
+type WebauthnPasswordlessConfiguration struct {
+	Enable bool   `mapstructure:"enable"`
+	Policy string `mapstructure:"policy"`
+}
+
+// DefaultWebauthnConfiguration represents default configuration parameters for WebAuthn.
+var DefaultWebauthnConfiguration = WebauthnConfiguration{
+	DisplayName: "Authelia",
+	Passwordless: WebauthnPasswordlessConfiguration{
+		Policy: "two_factor",
+	},
+}
@@ -0,0 +1,109 @@
+package configuration
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"github.com/authelia/authelia/internal/configuration/validator"
+)
+
+// Source provides configuration values in addition to the configuration file.
+type Source interface {
+	Name() string
+	Load(v *viper.Viper) (err error)
+}
+
+// NewDefaultSources returns the sources loaded along with the configuration file, followed by the remote sources
+// configured by the command line flags.
+func NewDefaultSources() []Source {
+	sources := []Source{
+		NewDockerSecretsSource(dockerSecretsDirectory),
+		NewDockerConfigsSource(dockerConfigsDirectory),
+	}
+
+	return append(sources, newRemoteSources()...)
+}
+
+// DockerSecretsSource reads the secrets mounted by Docker under their conventional names.
+type DockerSecretsSource struct {
+	directory string
+}
+
+// NewDockerSecretsSource creates a source reading the secrets mounted in the given directory.
+func NewDockerSecretsSource(directory string) *DockerSecretsSource {
+	return &DockerSecretsSource{directory: directory}
+}
+
+// Name returns the name of the source.
+func (s *DockerSecretsSource) Name() string {
+	return "docker secrets"
+}
+
+// Load registers the secret files found in the directory as the default file of their secret. The environment
+// variables still take precedence over them.
+func (s *DockerSecretsSource) Load(v *viper.Viper) (err error) {
+	var info os.FileInfo
+
+	for _, secretName := range validator.SecretNames {
+		path := filepath.Join(s.directory, DockerSecretName(secretName))
+
+		info, err = os.Stat(path)
+
+		switch {
+		case os.IsNotExist(err):
+			continue
+		case err != nil:
+			return err
+		case info.IsDir():
+			return fmt.Errorf("secret %s is a directory", path)
+		}
+
+		v.SetDefault(validator.SecretNameToEnvName(secretName), path)
+	}
+
+	return nil
+}
+
+// DockerSecretName converts a secret name into the name of its Docker secret.
+func DockerSecretName(secretName string) string {
+	return dockerNamePrefix + strings.ReplaceAll(secretName, ".", "_")
+}
+
+// DockerConfigsSource reads the configuration provided as a Docker swarm config under its conventional name.
+type DockerConfigsSource struct {
+	directory string
+}
+
+// NewDockerConfigsSource creates a source reading the swarm config mounted in the given directory.
+func NewDockerConfigsSource(directory string) *DockerConfigsSource {
+	return &DockerConfigsSource{directory: directory}
+}
+
+// Name returns the name of the source.
+func (s *DockerConfigsSource) Name() string {
+	return "docker configs"
+}
+
+// Load merges the YAML swarm config over the configuration file when it is mounted.
+func (s *DockerConfigsSource) Load(v *viper.Viper) (err error) {
+	path := filepath.Join(s.directory, dockerConfigName)
+
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	defer file.Close()
+
+	v.SetConfigType("yaml")
+
+	return v.MergeConfig(file)
+}
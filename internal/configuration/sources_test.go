@@ -0,0 +1,85 @@
+package configuration
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldConvertSecretNameToDockerSecretName(t *testing.T) {
+	assert.Equal(t, "authelia_jwt_secret", DockerSecretName("jwt_secret"))
+	assert.Equal(t, "authelia_storage_mysql_password", DockerSecretName("storage.mysql.password"))
+}
+
+func TestShouldLoadDockerSecrets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "authelia-secrets")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	createTestingTempFile(t, dir, "authelia_jwt_secret", "secret_from_docker")
+	createTestingTempFile(t, dir, "authelia_session_secret", "session_secret_from_docker")
+
+	v := viper.New()
+
+	require.NoError(t, NewDockerSecretsSource(dir).Load(v))
+
+	assert.Equal(t, filepath.Join(dir, "authelia_jwt_secret"), v.GetString("authelia.jwt_secret.file"))
+	assert.Equal(t, filepath.Join(dir, "authelia_session_secret"), v.GetString("authelia.session.secret.file"))
+	assert.Equal(t, "", v.GetString("authelia.storage.mysql.password.file"))
+}
+
+func TestShouldPreferEnvOverDockerSecrets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "authelia-secrets")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	createTestingTempFile(t, dir, "authelia_jwt_secret", "secret_from_docker")
+
+	v := viper.New()
+	v.Set("authelia.jwt_secret.file", "/config/jwt")
+
+	require.NoError(t, NewDockerSecretsSource(dir).Load(v))
+
+	assert.Equal(t, "/config/jwt", v.GetString("authelia.jwt_secret.file"))
+}
+
+func TestShouldIgnoreMissingDockerSecretsDirectory(t *testing.T) {
+	v := viper.New()
+
+	require.NoError(t, NewDockerSecretsSource("/path/not/exist").Load(v))
+	assert.Len(t, v.AllKeys(), 0)
+}
+
+func TestShouldMergeDockerConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "authelia-configs")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	createTestingTempFile(t, dir, "authelia_configuration", "log:\n  level: trace\n")
+
+	v := viper.New()
+	v.SetConfigType("yaml")
+
+	require.NoError(t, v.ReadConfig(strings.NewReader("port: 9091\nlog:\n  level: debug\n")))
+
+	require.NoError(t, NewDockerConfigsSource(dir).Load(v))
+
+	assert.Equal(t, 9091, v.GetInt("port"))
+	assert.Equal(t, "trace", v.GetString("log.level"))
+}
+
+func TestShouldIgnoreMissingDockerConfig(t *testing.T) {
+	v := viper.New()
+
+	require.NoError(t, NewDockerConfigsSource("/path/not/exist").Load(v))
+	assert.Len(t, v.AllKeys(), 0)
+}
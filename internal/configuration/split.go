@@ -0,0 +1,111 @@
+package configuration
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/configuration/validator"
+)
+
+// readSplitFiles replaces the access control rules and the OpenID Connect clients by the ones of their own files when
+// these files are configured.
+func readSplitFiles(configuration *schema.Configuration, val *schema.StructValidator) {
+	if configuration.AccessControl.RulesFile != "" {
+		if len(configuration.AccessControl.Rules) != 0 {
+			val.Push(fmt.Errorf(errFmtSplitFileConflict, "access_control.rules", "access_control.rules_file"))
+		}
+
+		rules, err := ReadAccessControlRules(configuration.AccessControl.RulesFile)
+		if err != nil {
+			val.Push(err)
+		} else {
+			configuration.AccessControl.Rules = rules
+		}
+	}
+
+	oidc := configuration.IdentityProviders.OIDC
+	if oidc != nil && oidc.ClientsFile != "" {
+		if len(oidc.Clients) != 0 {
+			val.Push(fmt.Errorf(errFmtSplitFileConflict, "identity_providers.oidc.clients", "identity_providers.oidc.clients_file"))
+		}
+
+		clients, err := ReadOpenIDConnectClients(oidc.ClientsFile)
+		if err != nil {
+			val.Push(err)
+		} else {
+			oidc.Clients = clients
+		}
+	}
+}
+
+// ReadAccessControlRules reads the access control rules under the rules key of a YAML file.
+func ReadAccessControlRules(path string) (rules []schema.ACLRule, err error) {
+	err = readSplitFile(path, "rules", &rules)
+
+	return rules, err
+}
+
+// ReadOpenIDConnectClients reads the OpenID Connect clients under the clients key of a YAML file.
+func ReadOpenIDConnectClients(path string) (clients []schema.OpenIDConnectClientConfiguration, err error) {
+	err = readSplitFile(path, "clients", &clients)
+
+	return clients, err
+}
+
+// ReloadAccessControlRules reads the rules file of the access control configuration again and validates its rules.
+func ReloadAccessControlRules(configuration schema.AccessControlConfiguration) (rules []schema.ACLRule, errs []error) {
+	rules, err := ReadAccessControlRules(configuration.RulesFile)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	configuration.Rules = rules
+
+	val := schema.NewStructValidator()
+	validator.ValidateRules(configuration, val)
+
+	if val.HasErrors() {
+		return nil, val.Errors()
+	}
+
+	return rules, nil
+}
+
+// ReloadOpenIDConnectClients reads the clients file of the OpenID Connect configuration again and validates its
+// clients. The defaults of the clients are applied.
+func ReloadOpenIDConnectClients(configuration schema.OpenIDConnectConfiguration) (clients []schema.OpenIDConnectClientConfiguration, errs []error) {
+	clients, err := ReadOpenIDConnectClients(configuration.ClientsFile)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	configuration.Clients = clients
+
+	val := schema.NewStructValidator()
+	validator.ValidateIdentityProviders(&schema.IdentityProvidersConfiguration{OIDC: &configuration}, val)
+
+	if val.HasErrors() {
+		return nil, val.Errors()
+	}
+
+	return configuration.Clients, nil
+}
+
+func readSplitFile(path, key string, value interface{}) (err error) {
+	v := viper.New()
+
+	v.SetConfigFile(path)
+	v.SetConfigType("yaml")
+
+	if err = v.ReadInConfig(); err != nil {
+		return fmt.Errorf("Unable to read %s: %v", path, err)
+	}
+
+	if err = v.UnmarshalKey(key, value); err != nil {
+		return fmt.Errorf("Unable to parse the %s of %s: %v", key, path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package configuration
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+func TestShouldReadAccessControlRules(t *testing.T) {
+	rules, err := ReadAccessControlRules("./test_resources/rules.yml")
+	require.NoError(t, err)
+
+	require.Len(t, rules, 2)
+	assert.Equal(t, []string{"public.example.com"}, rules[0].Domains)
+	assert.Equal(t, "bypass", rules[0].Policy)
+	assert.Equal(t, []string{"secure.example.com", "private.example.com"}, rules[1].Domains)
+	assert.Equal(t, [][]string{{"group:admins"}}, rules[1].Subjects)
+}
+
+func TestShouldReadOpenIDConnectClients(t *testing.T) {
+	clients, err := ReadOpenIDConnectClients("./test_resources/clients.yml")
+	require.NoError(t, err)
+
+	require.Len(t, clients, 1)
+	assert.Equal(t, "myapp", clients[0].ID)
+	assert.Equal(t, "one_factor", clients[0].Policy)
+	assert.Equal(t, []string{"https://myapp.example.com/oauth2/callback"}, clients[0].RedirectURIs)
+}
+
+func TestShouldErrorReadingMissingSplitFile(t *testing.T) {
+	_, err := ReadAccessControlRules("./test_resources/not_exist.yml")
+	assert.Error(t, err)
+}
+
+func TestShouldReplaceRulesFromRulesFile(t *testing.T) {
+	configuration := &schema.Configuration{
+		AccessControl: schema.AccessControlConfiguration{RulesFile: "./test_resources/rules.yml"},
+	}
+
+	val := schema.NewStructValidator()
+	readSplitFiles(configuration, val)
+
+	require.False(t, val.HasErrors())
+	assert.Len(t, configuration.AccessControl.Rules, 2)
+}
+
+func TestShouldErrorWhenRulesAreDefinedTwice(t *testing.T) {
+	configuration := &schema.Configuration{
+		AccessControl: schema.AccessControlConfiguration{
+			RulesFile: "./test_resources/rules.yml",
+			Rules:     []schema.ACLRule{{Domains: []string{"example.com"}, Policy: "bypass"}},
+		},
+	}
+
+	val := schema.NewStructValidator()
+	readSplitFiles(configuration, val)
+
+	require.Len(t, val.Errors(), 1)
+	assert.EqualError(t, val.Errors()[0], "access_control.rules and access_control.rules_file can't both be defined")
+}
+
+func TestShouldNotReloadInvalidAccessControlRules(t *testing.T) {
+	dir, err := ioutil.TempDir("", "authelia-split")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	createTestingTempFile(t, dir, "rules.yml", "rules:\n  - domain: example.com\n    policy: three_factor\n")
+
+	_, errs := ReloadAccessControlRules(schema.AccessControlConfiguration{
+		DefaultPolicy: "deny",
+		RulesFile:     filepath.Join(dir, "rules.yml"),
+	})
+
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "Policy [three_factor] for rule #1 domain: [example.com] is invalid, a policy must either be 'deny', 'two_factor', 'one_factor' or 'bypass'")
+}
+
+func TestShouldReloadOpenIDConnectClientsWithDefaults(t *testing.T) {
+	clients, errs := ReloadOpenIDConnectClients(schema.OpenIDConnectConfiguration{
+		IssuerPrivateKey: "key",
+		ClientsFile:      "./test_resources/clients.yml",
+	})
+
+	require.Len(t, errs, 0)
+	require.Len(t, clients, 1)
+	assert.Equal(t, "myapp", clients[0].Description)
+}
@@ -2,7 +2,6 @@ package validator
 
 import (
 	"fmt"
-	"net"
 	"regexp"
 	"strings"
 
@@ -41,12 +40,9 @@ func IsNetworkGroupValid(configuration schema.AccessControlConfiguration, networ
 
 // IsNetworkValid check if a network is valid.
 func IsNetworkValid(network string) (isValid bool) {
-	if net.ParseIP(network) == nil {
-		_, _, err := net.ParseCIDR(network)
-		return err == nil
-	}
+	_, err := utils.ParseNetwork(network)
 
-	return true
+	return err == nil
 }
 
 // ValidateAccessControl validates access control configuration.
@@ -68,6 +64,40 @@ func ValidateAccessControl(configuration *schema.AccessControlConfiguration, val
 			}
 		}
 	}
+
+	validateRoleMappings(configuration, validator)
+}
+
+func validateRoleMappings(configuration *schema.AccessControlConfiguration, validator *schema.StructValidator) {
+	for i := range configuration.RoleMappings {
+		mapping := &configuration.RoleMappings[i]
+		mappingPosition := i + 1
+
+		if mapping.Header == "" {
+			mapping.Header = schema.DefaultACLRoleMapping.Header
+		}
+
+		if len(mapping.Domains) == 0 {
+			validator.Push(fmt.Errorf("Role mapping #%d is invalid, a role mapping must have one or more domains", mappingPosition))
+		}
+
+		if !roleHeaderRegexp.MatchString(mapping.Header) {
+			validator.Push(fmt.Errorf("Role mapping #%d header '%s' is invalid, it must only contain alphanumeric characters and hyphens", mappingPosition, mapping.Header))
+		} else if utils.IsStringInSliceFold(mapping.Header, reservedForwardedHeaders) {
+			validator.Push(fmt.Errorf("Role mapping #%d header '%s' is invalid, it must not be one of the headers forwarded by Authelia: %s", mappingPosition, mapping.Header, strings.Join(reservedForwardedHeaders, ", ")))
+		}
+
+		if len(mapping.Roles) == 0 && mapping.Default == "" {
+			validator.Push(fmt.Errorf("Role mapping #%d domain: %s is invalid, it must have roles or a default role", mappingPosition, mapping.Domains))
+		}
+
+		for _, role := range mapping.Roles {
+			if role.Group == "" || role.Role == "" {
+				validator.Push(fmt.Errorf("Role mapping #%d domain: %s is invalid, its roles must have a group and a role", mappingPosition, mapping.Domains))
+				break
+			}
+		}
+	}
 }
 
 // ValidateRules validates an ACL Rule configuration.
@@ -97,12 +127,18 @@ func ValidateRules(configuration schema.AccessControlConfiguration, validator *s
 
 		validateNetworks(rulePosition, rule, configuration, validator)
 
+		validateTrustedNetworks(rulePosition, rule, configuration, validator)
+
 		validateResources(rulePosition, rule, validator)
 
 		validateSubjects(rulePosition, rule, validator)
 
 		validateMethods(rulePosition, rule, validator)
 
+		if rule.AccessGrants && rule.Policy != oneFactorPolicy && rule.Policy != twoFactorPolicy {
+			validator.Push(fmt.Errorf(errAccessControlAccessGrantsInvalidPolicy, rulePosition, rule.Domains, rule.Policy))
+		}
+
 		if rule.Policy == bypassPolicy && len(rule.Subjects) != 0 {
 			validator.Push(fmt.Errorf(errAccessControlInvalidPolicyWithSubjects, rulePosition, rule.Domains, rule.Subjects))
 		}
@@ -119,6 +155,22 @@ func validateNetworks(rulePosition int, rule schema.ACLRule, configuration schem
 	}
 }
 
+func validateTrustedNetworks(rulePosition int, rule schema.ACLRule, configuration schema.AccessControlConfiguration, validator *schema.StructValidator) {
+	if len(rule.TrustedNetworks) == 0 {
+		return
+	}
+
+	if rule.Policy != twoFactorPolicy {
+		validator.Push(fmt.Errorf(errAccessControlTrustedNetworksInvalidPolicy, rulePosition, rule.Domains, rule.Policy))
+	}
+
+	for _, network := range rule.TrustedNetworks {
+		if !IsNetworkValid(network) && !IsNetworkGroupValid(configuration, network) {
+			validator.Push(fmt.Errorf("Trusted network %s for rule #%d domain: %s is not a valid network or network group", network, rulePosition, rule.Domains))
+		}
+	}
+}
+
 func validateResources(rulePosition int, rule schema.ACLRule, validator *schema.StructValidator) {
 	for _, resource := range rule.Resources {
 		if err := IsResourceValid(resource); err != nil {
@@ -128,6 +128,64 @@ func (suite *AccessControl) TestShouldRaiseErrorInvalidNetwork() {
 	suite.Assert().EqualError(suite.validator.Errors()[0], "Network [abc.def.ghi.jkl/32] for rule #1 domain: [public.example.com] is not a valid network or network group")
 }
 
+func (suite *AccessControl) TestShouldRaiseErrorInvalidTrustedNetwork() {
+	suite.configuration.Rules = []schema.ACLRule{
+		{
+			Domains:         []string{"public.example.com"},
+			Policy:          "two_factor",
+			TrustedNetworks: []string{"internal", "abc.def.ghi.jkl/32"},
+		},
+	}
+
+	ValidateRules(suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Require().Len(suite.validator.Errors(), 1)
+
+	suite.Assert().EqualError(suite.validator.Errors()[0], "Trusted network abc.def.ghi.jkl/32 for rule #1 domain: [public.example.com] is not a valid network or network group")
+}
+
+func (suite *AccessControl) TestShouldRaiseErrorTrustedNetworksWithoutTwoFactorPolicy() {
+	domains := []string{"public.example.com"}
+	suite.configuration.Rules = []schema.ACLRule{
+		{
+			Domains:         domains,
+			Policy:          "one_factor",
+			TrustedNetworks: []string{"10.0.0.0/8"},
+		},
+	}
+
+	ValidateRules(suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Require().Len(suite.validator.Errors(), 1)
+
+	suite.Assert().EqualError(suite.validator.Errors()[0], fmt.Sprintf(errAccessControlTrustedNetworksInvalidPolicy, 1, domains, "one_factor"))
+}
+
+func (suite *AccessControl) TestShouldRaiseErrorAccessGrantsWithoutFactorPolicy() {
+	domains := []string{"public.example.com"}
+	suite.configuration.Rules = []schema.ACLRule{
+		{
+			Domains:      domains,
+			Policy:       "bypass",
+			AccessGrants: true,
+		},
+		{
+			Domains:      []string{"secure.example.com"},
+			Policy:       "two_factor",
+			AccessGrants: true,
+		},
+	}
+
+	ValidateRules(suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Require().Len(suite.validator.Errors(), 1)
+
+	suite.Assert().EqualError(suite.validator.Errors()[0], fmt.Sprintf(errAccessControlAccessGrantsInvalidPolicy, 1, domains, "bypass"))
+}
+
 func (suite *AccessControl) TestShouldRaiseErrorInvalidMethod() {
 	suite.configuration.Rules = []schema.ACLRule{
 		{
@@ -182,6 +240,48 @@ func (suite *AccessControl) TestShouldRaiseErrorInvalidSubject() {
 	suite.Assert().EqualError(suite.validator.Errors()[1], fmt.Sprintf(errAccessControlInvalidPolicyWithSubjects, 1, domains, subjects))
 }
 
+func (suite *AccessControl) TestShouldSetDefaultRoleMappingHeader() {
+	suite.configuration.RoleMappings = []schema.ACLRoleMapping{
+		{
+			Domains: []string{"grafana.example.com"},
+			Roles:   []schema.ACLRole{{Group: "admins", Role: "Admin"}},
+		},
+	}
+
+	ValidateAccessControl(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasErrors())
+	suite.Assert().Equal("Remote-Role", suite.configuration.RoleMappings[0].Header)
+}
+
+func (suite *AccessControl) TestShouldRaiseErrorsInvalidRoleMappings() {
+	suite.configuration.RoleMappings = []schema.ACLRoleMapping{
+		{
+			Header: "X Role",
+			Roles:  []schema.ACLRole{{Group: "admins", Role: "Admin"}},
+		},
+		{
+			Domains: []string{"grafana.example.com"},
+			Header:  "remote-groups",
+		},
+		{
+			Domains: []string{"wiki.example.com"},
+			Roles:   []schema.ACLRole{{Group: "admins"}},
+		},
+	}
+
+	ValidateAccessControl(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Require().Len(suite.validator.Errors(), 5)
+
+	suite.Assert().EqualError(suite.validator.Errors()[0], "Role mapping #1 is invalid, a role mapping must have one or more domains")
+	suite.Assert().EqualError(suite.validator.Errors()[1], "Role mapping #1 header 'X Role' is invalid, it must only contain alphanumeric characters and hyphens")
+	suite.Assert().EqualError(suite.validator.Errors()[2], "Role mapping #2 header 'remote-groups' is invalid, it must not be one of the headers forwarded by Authelia: Remote-User, Remote-Name, Remote-Email, Remote-Groups")
+	suite.Assert().EqualError(suite.validator.Errors()[3], "Role mapping #2 domain: [grafana.example.com] is invalid, it must have roles or a default role")
+	suite.Assert().EqualError(suite.validator.Errors()[4], "Role mapping #3 domain: [wiki.example.com] is invalid, its roles must have a group and a role")
+}
+
 func TestAccessControl(t *testing.T) {
 	suite.Run(t, new(AccessControl))
 }
@@ -197,3 +297,14 @@ func TestShouldReturnCorrectResultsForValidNetworkGroups(t *testing.T) {
 	assert.True(t, validNetwork)
 	assert.False(t, invalidNetwork)
 }
+
+func TestShouldValidateIPv6Networks(t *testing.T) {
+	assert.True(t, IsNetworkValid("fec0::1"))
+	assert.True(t, IsNetworkValid("fec0::/64"))
+	assert.True(t, IsNetworkValid("fe80::1%eth0"))
+	assert.True(t, IsNetworkValid("fe80::%eth0/10"))
+	assert.True(t, IsNetworkValid("::ffff:10.0.0.0/104"))
+
+	assert.False(t, IsNetworkValid("fec0::1/129"))
+	assert.False(t, IsNetworkValid("::ffff:10.0.0.0/64"))
+}
@@ -0,0 +1,48 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+var accessGrantParameterRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// ValidateAccessGrants validates and updates the access grants configuration.
+func ValidateAccessGrants(configuration *schema.AccessGrantsConfiguration, validator *schema.StructValidator) {
+	if configuration.Parameter == "" {
+		configuration.Parameter = schema.DefaultAccessGrantsConfiguration.Parameter
+	}
+
+	if configuration.DefaultLifespan == "" {
+		configuration.DefaultLifespan = schema.DefaultAccessGrantsConfiguration.DefaultLifespan
+	}
+
+	if configuration.MaxLifespan == "" {
+		configuration.MaxLifespan = schema.DefaultAccessGrantsConfiguration.MaxLifespan
+	}
+
+	if !accessGrantParameterRegexp.MatchString(configuration.Parameter) {
+		validator.Push(fmt.Errorf("The access grants parameter '%s' is invalid, it must only contain alphanumeric characters, underscores and hyphens", configuration.Parameter))
+	}
+
+	defaultLifespan, err := utils.ParseDurationString(configuration.DefaultLifespan)
+	if err != nil {
+		validator.Push(fmt.Errorf("Error occurred parsing access grants default_lifespan string: %s", err))
+	}
+
+	maxLifespan, err := utils.ParseDurationString(configuration.MaxLifespan)
+	if err != nil {
+		validator.Push(fmt.Errorf("Error occurred parsing access grants max_lifespan string: %s", err))
+
+		return
+	}
+
+	if maxLifespan <= 0 {
+		validator.Push(fmt.Errorf("The access grants max_lifespan must be above 0"))
+	} else if defaultLifespan > maxLifespan {
+		validator.Push(fmt.Errorf("The access grants default_lifespan %s must not be above the max_lifespan %s", configuration.DefaultLifespan, configuration.MaxLifespan))
+	}
+}
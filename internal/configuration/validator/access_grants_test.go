@@ -0,0 +1,50 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+func TestShouldSetDefaultAccessGrantsValues(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.AccessGrantsConfiguration{}
+
+	ValidateAccessGrants(config, validator)
+
+	assert.False(t, validator.HasErrors())
+	assert.Equal(t, "authelia_grant", config.Parameter)
+	assert.Equal(t, "1h", config.DefaultLifespan)
+	assert.Equal(t, "1d", config.MaxLifespan)
+}
+
+func TestShouldRaiseErrorsOnInvalidAccessGrants(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.AccessGrantsConfiguration{
+		Parameter:       "grant token",
+		DefaultLifespan: "one hour",
+		MaxLifespan:     "1h",
+	}
+
+	ValidateAccessGrants(config, validator)
+
+	require.Len(t, validator.Errors(), 2)
+	assert.EqualError(t, validator.Errors()[0], "The access grants parameter 'grant token' is invalid, it must only contain alphanumeric characters, underscores and hyphens")
+	assert.EqualError(t, validator.Errors()[1], "Error occurred parsing access grants default_lifespan string: could not convert the input string of one hour into a duration")
+}
+
+func TestShouldRaiseErrorWhenAccessGrantsDefaultLifespanAboveMax(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.AccessGrantsConfiguration{
+		DefaultLifespan: "2d",
+		MaxLifespan:     "1d",
+	}
+
+	ValidateAccessGrants(config, validator)
+
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], "The access grants default_lifespan 2d must not be above the max_lifespan 1d")
+}
@@ -0,0 +1,70 @@
+package validator
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// ValidateAudit validates and updates the configuration of the audit log.
+func ValidateAudit(configuration *schema.AuditConfiguration, validator *schema.StructValidator) {
+	if configuration.BufferSize == 0 {
+		configuration.BufferSize = schema.DefaultAuditConfiguration.BufferSize
+	} else if configuration.BufferSize < 0 {
+		validator.Push(fmt.Errorf("The audit buffer size %d is invalid, it must be positive", configuration.BufferSize))
+	}
+
+	if configuration.File == nil && configuration.Syslog == nil && configuration.Webhook == nil {
+		validator.Push(fmt.Errorf("The audit log must have at least one of the file, syslog or webhook sinks configured"))
+	}
+
+	if configuration.File != nil && configuration.File.Path == "" {
+		validator.Push(fmt.Errorf("The audit file sink must have a path"))
+	}
+
+	if configuration.Syslog != nil {
+		validateAuditSyslog(configuration.Syslog, validator)
+	}
+
+	if configuration.Webhook != nil {
+		validateAuditWebhook(configuration.Webhook, validator)
+	}
+}
+
+func validateAuditSyslog(configuration *schema.AuditSyslogConfiguration, validator *schema.StructValidator) {
+	if configuration.Tag == "" {
+		configuration.Tag = schema.DefaultAuditSyslogConfiguration.Tag
+	}
+
+	switch {
+	case configuration.Network == "" && configuration.Address != "":
+		validator.Push(fmt.Errorf("The audit syslog sink must have a network to send the records to the address '%s'", configuration.Address))
+	case configuration.Network != "" && !utils.IsStringInSlice(configuration.Network, validAuditSyslogNetworks):
+		validator.Push(fmt.Errorf("The audit syslog sink network '%s' is invalid, it must be one of %s", configuration.Network, strings.Join(validAuditSyslogNetworks, ", ")))
+	case configuration.Network != "" && configuration.Address == "":
+		validator.Push(fmt.Errorf("The audit syslog sink must have an address to send the records over %s", configuration.Network))
+	}
+}
+
+func validateAuditWebhook(configuration *schema.AuditWebhookConfiguration, validator *schema.StructValidator) {
+	if configuration.Timeout == "" {
+		configuration.Timeout = schema.DefaultAuditWebhookConfiguration.Timeout
+	}
+
+	if _, err := utils.ParseDurationString(configuration.Timeout); err != nil {
+		validator.Push(fmt.Errorf("Error occurred parsing audit webhook timeout string: %s", err))
+	}
+
+	if configuration.URL == "" {
+		validator.Push(fmt.Errorf("The audit webhook sink must have a url"))
+		return
+	}
+
+	u, err := url.Parse(configuration.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		validator.Push(fmt.Errorf("The audit webhook sink url '%s' must be an absolute http or https URL", configuration.URL))
+	}
+}
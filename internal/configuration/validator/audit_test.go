@@ -0,0 +1,75 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+func TestShouldSetDefaultAuditValues(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.AuditConfiguration{
+		File:    &schema.AuditFileConfiguration{Path: "/var/log/authelia/audit.log"},
+		Syslog:  &schema.AuditSyslogConfiguration{},
+		Webhook: &schema.AuditWebhookConfiguration{URL: "https://siem.example.com/authelia"},
+	}
+
+	ValidateAudit(&config, validator)
+
+	assert.False(t, validator.HasErrors())
+	assert.Equal(t, 1000, config.BufferSize)
+	assert.Equal(t, "authelia", config.Syslog.Tag)
+	assert.Equal(t, "5s", config.Webhook.Timeout)
+}
+
+func TestShouldRaiseErrorWhenNoAuditSinkIsConfigured(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.AuditConfiguration{}
+
+	ValidateAudit(&config, validator)
+
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], "The audit log must have at least one of the file, syslog or webhook sinks configured")
+}
+
+func TestShouldRaiseErrorsOnInvalidAuditSinks(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.AuditConfiguration{
+		BufferSize: -1,
+		File:       &schema.AuditFileConfiguration{},
+		Syslog:     &schema.AuditSyslogConfiguration{Network: "tls", Address: "syslog.example.com:6514"},
+		Webhook:    &schema.AuditWebhookConfiguration{URL: "/audit", Timeout: "abc"},
+	}
+
+	ValidateAudit(&config, validator)
+
+	require.Len(t, validator.Errors(), 5)
+	assert.EqualError(t, validator.Errors()[0], "The audit buffer size -1 is invalid, it must be positive")
+	assert.EqualError(t, validator.Errors()[1], "The audit file sink must have a path")
+	assert.EqualError(t, validator.Errors()[2], "The audit syslog sink network 'tls' is invalid, it must be one of udp, tcp, unix, unixgram")
+	assert.EqualError(t, validator.Errors()[3], "Error occurred parsing audit webhook timeout string: could not convert the input string of abc into a duration")
+	assert.EqualError(t, validator.Errors()[4], "The audit webhook sink url '/audit' must be an absolute http or https URL")
+}
+
+func TestShouldRaiseErrorsOnIncompleteAuditSyslogAddress(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.AuditConfiguration{
+		Syslog: &schema.AuditSyslogConfiguration{Address: "syslog.example.com:514"},
+	}
+
+	ValidateAudit(&config, validator)
+
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], "The audit syslog sink must have a network to send the records to the address 'syslog.example.com:514'")
+
+	validator = schema.NewStructValidator()
+	config.Syslog = &schema.AuditSyslogConfiguration{Network: "udp"}
+
+	ValidateAudit(&config, validator)
+
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], "The audit syslog sink must have an address to send the records over udp")
+}
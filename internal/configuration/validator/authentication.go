@@ -6,16 +6,25 @@ import (
 	"net/url"
 	"strings"
 
+	"github.com/authelia/authelia/internal/authentication"
 	"github.com/authelia/authelia/internal/configuration/schema"
 	"github.com/authelia/authelia/internal/utils"
 )
 
 // ValidateAuthenticationBackend validates and update authentication backend configuration.
 func ValidateAuthenticationBackend(configuration *schema.AuthenticationBackendConfiguration, validator *schema.StructValidator) {
-	if configuration.LDAP == nil && configuration.File == nil {
+	if configuration.Provider != "" {
+		if configuration.LDAP != nil || configuration.File != nil {
+			validator.Push(errors.New("You cannot provide both a custom `provider` and the `ldap` or `file` objects in `authentication_backend`"))
+		}
+	} else if configuration.LDAP == nil && configuration.File == nil {
 		validator.Push(errors.New("Please provide `ldap` or `file` object in `authentication_backend`"))
 	}
 
+	if configuration.Provider == "" && len(configuration.Options) != 0 {
+		validator.Push(errors.New("The `options` of `authentication_backend` can only be used with a custom `provider`"))
+	}
+
 	if configuration.LDAP != nil && configuration.File != nil {
 		validator.Push(errors.New("You cannot provide both `ldap` and `file` objects in `authentication_backend`"))
 	}
@@ -34,6 +43,47 @@ func ValidateAuthenticationBackend(configuration *schema.AuthenticationBackendCo
 			validator.Push(fmt.Errorf("Auth Backend `refresh_interval` is configured to '%s' but it must be either a duration notation or one of 'disable', or 'always'. Error from parser: %s", configuration.RefreshInterval, err))
 		}
 	}
+
+	if configuration.BreakGlass != nil {
+		validateBreakGlass(configuration.BreakGlass, validator)
+	}
+}
+
+func validateBreakGlass(configuration *schema.BreakGlassConfiguration, validator *schema.StructValidator) {
+	if configuration.Window == "" {
+		configuration.Window = schema.DefaultBreakGlassConfiguration.Window
+	}
+
+	window, err := utils.ParseDurationString(configuration.Window)
+	if err != nil {
+		validator.Push(fmt.Errorf("Error occurred parsing break-glass window string: %s", err))
+	} else if window <= 0 {
+		validator.Push(fmt.Errorf("The break-glass window must be above 0"))
+	}
+
+	if len(configuration.Accounts) == 0 {
+		validator.Push(errors.New("Please provide at least one account in `authentication_backend.break_glass`"))
+	}
+
+	usernames := make([]string, 0, len(configuration.Accounts))
+
+	for i, account := range configuration.Accounts {
+		if account.Username == "" {
+			validator.Push(fmt.Errorf("The break-glass account #%d must have a username", i+1))
+
+			continue
+		}
+
+		if utils.IsStringInSliceFold(account.Username, usernames) {
+			validator.Push(fmt.Errorf("The break-glass account %s is configured more than once", account.Username))
+		}
+
+		usernames = append(usernames, account.Username)
+
+		if _, err := authentication.ParseHash(account.Password); err != nil {
+			validator.Push(fmt.Errorf("The break-glass account %s must have a valid password hash: %s", account.Username, err))
+		}
+	}
 }
 
 //nolint:gocyclo // TODO: Consider refactoring/simplifying, time permitting.
@@ -115,6 +165,9 @@ func validateLDAPAuthenticationBackend(configuration *schema.LDAPAuthenticationB
 		validator.Push(fmt.Errorf("error occurred validating the LDAP minimum_tls_version key with value %s: %v", configuration.TLS.MinimumVersion, err))
 	}
 
+	validateTLSParameters(configuration.TLS, "LDAP", validator)
+	validateTLSFiles(configuration.TLS, "LDAP", validator)
+
 	switch configuration.Implementation {
 	case schema.LDAPImplementationCustom:
 		setDefaultImplementationCustomLDAPAuthenticationBackend(configuration)
@@ -135,9 +188,16 @@ func validateLDAPAuthenticationBackend(configuration *schema.LDAPAuthenticationB
 			"placeholders, {0} has been replaced with {input} and {1} has been replaced with {username}"))
 	}
 
-	if configuration.URL == "" {
-		validator.Push(errors.New("Please provide a URL to the LDAP server"))
-	} else {
+	validateLDAPURLs(configuration, validator)
+	validateLDAPServers(configuration, validator)
+	validateLDAPRequiredParameters(configuration, validator)
+}
+
+func validateLDAPURLs(configuration *schema.LDAPAuthenticationBackendConfiguration, validator *schema.StructValidator) {
+	switch {
+	case configuration.URL != "" && len(configuration.URLs) != 0:
+		validator.Push(errors.New("You cannot provide both `url` and `urls` to the LDAP servers"))
+	case configuration.URL != "":
 		ldapURL, serverName := validateLDAPURL(configuration.URL, validator)
 
 		configuration.URL = ldapURL
@@ -145,9 +205,47 @@ func validateLDAPAuthenticationBackend(configuration *schema.LDAPAuthenticationB
 		if configuration.TLS.ServerName == "" {
 			configuration.TLS.ServerName = serverName
 		}
+	case len(configuration.URLs) != 0:
+		// The server name of each server is deduced from its own URL by the provider.
+		for i, ldapURL := range configuration.URLs {
+			configuration.URLs[i] = validateLDAPURLSimple(ldapURL, validator)
+		}
+	default:
+		validator.Push(errors.New("Please provide a URL to the LDAP server"))
+	}
+}
+
+func validateLDAPServers(configuration *schema.LDAPAuthenticationBackendConfiguration, validator *schema.StructValidator) {
+	switch configuration.LoadBalancing {
+	case "":
+		configuration.LoadBalancing = schema.DefaultLDAPAuthenticationBackendConfiguration.LoadBalancing
+	case schema.LDAPLoadBalancingFailover, schema.LDAPLoadBalancingRoundRobin:
+		break
+	default:
+		validator.Push(fmt.Errorf("authentication backend ldap load_balancing must be blank or one of the following values `%s`, `%s`", schema.LDAPLoadBalancingFailover, schema.LDAPLoadBalancingRoundRobin))
 	}
 
-	validateLDAPRequiredParameters(configuration, validator)
+	if configuration.HealthCheckInterval == "" {
+		configuration.HealthCheckInterval = schema.DefaultLDAPAuthenticationBackendConfiguration.HealthCheckInterval
+	} else if _, err := utils.ParseDurationString(configuration.HealthCheckInterval); err != nil {
+		validator.Push(fmt.Errorf("Error occurred parsing LDAP health_check_interval string: %s", err))
+	}
+
+	if configuration.Pool == nil {
+		return
+	}
+
+	if configuration.Pool.Size == 0 {
+		configuration.Pool.Size = schema.DefaultLDAPPoolConfiguration.Size
+	} else if configuration.Pool.Size < 0 {
+		validator.Push(fmt.Errorf("the LDAP pool size must be greater than 0 but it is configured as %d", configuration.Pool.Size))
+	}
+
+	if configuration.Pool.IdleTimeout == "" {
+		configuration.Pool.IdleTimeout = schema.DefaultLDAPPoolConfiguration.IdleTimeout
+	} else if _, err := utils.ParseDurationString(configuration.Pool.IdleTimeout); err != nil {
+		validator.Push(fmt.Errorf("Error occurred parsing LDAP pool idle_timeout string: %s", err))
+	}
 }
 
 // Wrapper for test purposes to exclude the hostname from the return.
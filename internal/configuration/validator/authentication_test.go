@@ -35,6 +35,42 @@ func TestShouldRaiseErrorWhenNoBackendProvided(t *testing.T) {
 	assert.EqualError(t, validator.Errors()[0], "Please provide `ldap` or `file` object in `authentication_backend`")
 }
 
+func TestShouldNotRaiseErrorWhenCustomProviderProvided(t *testing.T) {
+	validator := schema.NewStructValidator()
+	backendConfig := schema.AuthenticationBackendConfiguration{Provider: "custom"}
+
+	ValidateAuthenticationBackend(&backendConfig, validator)
+
+	assert.Len(t, validator.Errors(), 0)
+}
+
+func TestShouldRaiseErrorWhenOptionsProvidedWithoutCustomProvider(t *testing.T) {
+	validator := schema.NewStructValidator()
+	backendConfig := schema.AuthenticationBackendConfiguration{
+		File:    &schema.FileAuthenticationBackendConfiguration{Path: "/tmp"},
+		Options: map[string]interface{}{"url": "https://idp.example.com"},
+	}
+
+	ValidateAuthenticationBackend(&backendConfig, validator)
+
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], "The `options` of `authentication_backend` can only be used with a custom `provider`")
+}
+
+func TestShouldRaiseErrorWhenCustomProviderAndBackendProvided(t *testing.T) {
+	validator := schema.NewStructValidator()
+	backendConfig := schema.AuthenticationBackendConfiguration{Provider: "custom"}
+
+	backendConfig.File = &schema.FileAuthenticationBackendConfiguration{
+		Path: "/tmp",
+	}
+
+	ValidateAuthenticationBackend(&backendConfig, validator)
+
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], "You cannot provide both a custom `provider` and the `ldap` or `file` objects in `authentication_backend`")
+}
+
 type FileBasedAuthenticationBackend struct {
 	suite.Suite
 	configuration schema.AuthenticationBackendConfiguration
@@ -489,6 +525,103 @@ func (suite *LDAPAuthenticationBackendSuite) TestShouldNotAllowInvalidTLSValue()
 	suite.Assert().EqualError(suite.validator.Errors()[0], "error occurred validating the LDAP minimum_tls_version key with value SSL2.0: supplied TLS version isn't supported")
 }
 
+func (suite *LDAPAuthenticationBackendSuite) TestShouldRaiseErrorWhenTLSClientKeyWithoutCertificate() {
+	suite.configuration.LDAP.TLS = &schema.TLSConfig{
+		MinimumVersion: "TLS1.2",
+		ClientKey:      "/config/client.key",
+	}
+
+	ValidateAuthenticationBackend(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Require().Len(suite.validator.Errors(), 1)
+
+	suite.Assert().EqualError(suite.validator.Errors()[0], "the LDAP tls client_certificate must be provided along with the client_key")
+}
+
+func (suite *LDAPAuthenticationBackendSuite) TestShouldValidateSeveralURLs() {
+	suite.configuration.LDAP.URL = ""
+	suite.configuration.LDAP.URLs = []string{"ldaps://ldap1.example.com", "ldaps://ldap2.example.com:636"}
+
+	ValidateAuthenticationBackend(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Assert().False(suite.validator.HasErrors())
+
+	suite.Assert().Equal([]string{"ldaps://ldap1.example.com", "ldaps://ldap2.example.com:636"}, suite.configuration.LDAP.URLs)
+}
+
+func (suite *LDAPAuthenticationBackendSuite) TestShouldRaiseErrorWhenBothURLAndURLsProvided() {
+	suite.configuration.LDAP.URLs = []string{"ldap://ldap1.example.com"}
+
+	ValidateAuthenticationBackend(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Require().Len(suite.validator.Errors(), 1)
+
+	suite.Assert().EqualError(suite.validator.Errors()[0], "You cannot provide both `url` and `urls` to the LDAP servers")
+}
+
+func (suite *LDAPAuthenticationBackendSuite) TestShouldRaiseErrorWhenOneOfURLsIsInvalid() {
+	suite.configuration.LDAP.URL = ""
+	suite.configuration.LDAP.URLs = []string{"ldap://ldap1.example.com", "http://ldap2.example.com"}
+
+	ValidateAuthenticationBackend(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Require().Len(suite.validator.Errors(), 1)
+
+	suite.Assert().EqualError(suite.validator.Errors()[0], "Unknown scheme for ldap url, should be ldap:// or ldaps://")
+}
+
+func (suite *LDAPAuthenticationBackendSuite) TestShouldSetDefaultServersConfiguration() {
+	ValidateAuthenticationBackend(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Assert().False(suite.validator.HasErrors())
+
+	suite.Assert().Equal(schema.LDAPLoadBalancingFailover, suite.configuration.LDAP.LoadBalancing)
+	suite.Assert().Equal("30s", suite.configuration.LDAP.HealthCheckInterval)
+	suite.Assert().Nil(suite.configuration.LDAP.Pool)
+}
+
+func (suite *LDAPAuthenticationBackendSuite) TestShouldRaiseErrorOnInvalidServersConfiguration() {
+	suite.configuration.LDAP.LoadBalancing = "random"
+	suite.configuration.LDAP.HealthCheckInterval = "blah"
+
+	ValidateAuthenticationBackend(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Require().Len(suite.validator.Errors(), 2)
+
+	suite.Assert().EqualError(suite.validator.Errors()[0], "authentication backend ldap load_balancing must be blank or one of the following values `failover`, `round_robin`")
+	suite.Assert().EqualError(suite.validator.Errors()[1], "Error occurred parsing LDAP health_check_interval string: could not convert the input string of blah into a duration")
+}
+
+func (suite *LDAPAuthenticationBackendSuite) TestShouldSetDefaultPoolConfiguration() {
+	suite.configuration.LDAP.Pool = &schema.LDAPPoolConfiguration{}
+
+	ValidateAuthenticationBackend(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Assert().False(suite.validator.HasErrors())
+
+	suite.Assert().Equal(5, suite.configuration.LDAP.Pool.Size)
+	suite.Assert().Equal("5m", suite.configuration.LDAP.Pool.IdleTimeout)
+}
+
+func (suite *LDAPAuthenticationBackendSuite) TestShouldRaiseErrorOnInvalidPoolConfiguration() {
+	suite.configuration.LDAP.Pool = &schema.LDAPPoolConfiguration{Size: -1, IdleTimeout: "blah"}
+
+	ValidateAuthenticationBackend(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Require().Len(suite.validator.Errors(), 2)
+
+	suite.Assert().EqualError(suite.validator.Errors()[0], "the LDAP pool size must be greater than 0 but it is configured as -1")
+	suite.Assert().EqualError(suite.validator.Errors()[1], "Error occurred parsing LDAP pool idle_timeout string: could not convert the input string of blah into a duration")
+}
+
 func TestLdapAuthenticationBackend(t *testing.T) {
 	suite.Run(t, new(LDAPAuthenticationBackendSuite))
 }
@@ -570,3 +703,53 @@ func (suite *ActiveDirectoryAuthenticationBackendSuite) TestShouldOnlySetDefault
 func TestActiveDirectoryAuthenticationBackend(t *testing.T) {
 	suite.Run(t, new(ActiveDirectoryAuthenticationBackendSuite))
 }
+
+const testBreakGlassPasswordHash = "$argon2id$v=19$m=65536,t=3,p=2$BpLnfgDsc2WD8F2q$o/vzA4myCqZZ36bUGsDY//8mKUYNZZaR0t4MFFSs+iM"
+
+func TestShouldSetDefaultBreakGlassWindow(t *testing.T) {
+	validator := schema.NewStructValidator()
+	backendConfig := schema.AuthenticationBackendConfiguration{Provider: "custom"}
+
+	backendConfig.BreakGlass = &schema.BreakGlassConfiguration{
+		Accounts: []schema.BreakGlassAccountConfiguration{{Username: "emergency", Password: testBreakGlassPasswordHash}},
+	}
+
+	ValidateAuthenticationBackend(&backendConfig, validator)
+
+	assert.Len(t, validator.Errors(), 0)
+	assert.Equal(t, schema.DefaultBreakGlassConfiguration.Window, backendConfig.BreakGlass.Window)
+}
+
+func TestShouldRaiseErrorWhenBreakGlassHasNoAccount(t *testing.T) {
+	validator := schema.NewStructValidator()
+	backendConfig := schema.AuthenticationBackendConfiguration{Provider: "custom"}
+
+	backendConfig.BreakGlass = &schema.BreakGlassConfiguration{Window: "0"}
+
+	ValidateAuthenticationBackend(&backendConfig, validator)
+
+	require.Len(t, validator.Errors(), 2)
+	assert.EqualError(t, validator.Errors()[0], "The break-glass window must be above 0")
+	assert.EqualError(t, validator.Errors()[1], "Please provide at least one account in `authentication_backend.break_glass`")
+}
+
+func TestShouldRaiseErrorWhenBreakGlassAccountsAreInvalid(t *testing.T) {
+	validator := schema.NewStructValidator()
+	backendConfig := schema.AuthenticationBackendConfiguration{Provider: "custom"}
+
+	backendConfig.BreakGlass = &schema.BreakGlassConfiguration{
+		Accounts: []schema.BreakGlassAccountConfiguration{
+			{Password: testBreakGlassPasswordHash},
+			{Username: "emergency", Password: testBreakGlassPasswordHash},
+			{Username: "Emergency", Password: testBreakGlassPasswordHash},
+			{Username: "other", Password: "password"},
+		},
+	}
+
+	ValidateAuthenticationBackend(&backendConfig, validator)
+
+	require.Len(t, validator.Errors(), 3)
+	assert.EqualError(t, validator.Errors()[0], "The break-glass account #1 must have a username")
+	assert.EqualError(t, validator.Errors()[1], "The break-glass account Emergency is configured more than once")
+	assert.Contains(t, validator.Errors()[2].Error(), "The break-glass account other must have a valid password hash: ")
+}
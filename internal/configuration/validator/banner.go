@@ -0,0 +1,25 @@
+package validator
+
+import (
+	"fmt"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+// bannerAnnouncementIDMaxLength is the maximum length of the IDs of the announcements, it fits in the column storing the
+// acknowledgments.
+const bannerAnnouncementIDMaxLength = 64
+
+// ValidateBanner validates the configuration of the messages displayed by the login portal.
+func ValidateBanner(configuration *schema.BannerConfiguration, validator *schema.StructValidator) {
+	announcement := configuration.Announcement
+
+	switch {
+	case announcement.Message != "" && announcement.ID == "":
+		validator.Push(fmt.Errorf("The banner announcement must have an id to track the users who acknowledged it"))
+	case announcement.Message == "" && announcement.ID != "":
+		validator.Push(fmt.Errorf("The banner announcement %s must have a message", announcement.ID))
+	case len(announcement.ID) > bannerAnnouncementIDMaxLength:
+		validator.Push(fmt.Errorf("The banner announcement id must not be longer than %d characters", bannerAnnouncementIDMaxLength))
+	}
+}
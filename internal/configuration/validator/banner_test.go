@@ -0,0 +1,59 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+func TestShouldValidateBanner(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.BannerConfiguration{
+		Login: "Authorized use only.",
+		Announcement: schema.BannerAnnouncementConfiguration{
+			ID:      "maintenance",
+			Message: "The portal will be unavailable on Sunday.",
+		},
+	}
+
+	ValidateBanner(&config, validator)
+
+	assert.False(t, validator.HasErrors())
+	assert.False(t, validator.HasWarnings())
+}
+
+func TestShouldRaiseErrorWhenBannerAnnouncementIsIncomplete(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.BannerConfiguration{
+		Announcement: schema.BannerAnnouncementConfiguration{Message: "The portal will be unavailable on Sunday."},
+	}
+
+	ValidateBanner(&config, validator)
+
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], "The banner announcement must have an id to track the users who acknowledged it")
+
+	validator = schema.NewStructValidator()
+	config.Announcement = schema.BannerAnnouncementConfiguration{ID: "maintenance"}
+
+	ValidateBanner(&config, validator)
+
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], "The banner announcement maintenance must have a message")
+}
+
+func TestShouldRaiseErrorWhenBannerAnnouncementIDIsTooLong(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.BannerConfiguration{
+		Announcement: schema.BannerAnnouncementConfiguration{ID: strings.Repeat("a", 65), Message: "Hello."},
+	}
+
+	ValidateBanner(&config, validator)
+
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], "The banner announcement id must not be longer than 64 characters")
+}
@@ -49,6 +49,8 @@ func ValidateConfiguration(configuration *schema.Configuration, validator *schem
 
 	ValidateTheme(configuration, validator)
 
+	ValidateCryptoProfile(configuration, validator)
+
 	if configuration.TOTP == nil {
 		configuration.TOTP = &schema.DefaultTOTPConfiguration
 	}
@@ -57,23 +59,72 @@ func ValidateConfiguration(configuration *schema.Configuration, validator *schem
 
 	ValidateTOTP(configuration.TOTP, validator)
 
+	ValidateWebauthn(&configuration.Webauthn, validator)
+
 	ValidateAuthenticationBackend(&configuration.AuthenticationBackend, validator)
 
 	ValidateAccessControl(&configuration.AccessControl, validator)
 
 	ValidateRules(configuration.AccessControl, validator)
 
+	if configuration.ProxyAuthentication != nil {
+		ValidateProxyAuthentication(configuration, validator)
+	}
+
 	ValidateSession(&configuration.Session, validator)
 
+	ValidateRedirection(&configuration.Redirection, validator)
+
+	ValidateBanner(&configuration.Banner, validator)
+
+	if configuration.Logout != nil {
+		ValidateLogout(configuration.Logout, validator)
+	}
+
 	if configuration.Regulation == nil {
-		configuration.Regulation = &schema.DefaultRegulationConfiguration
+		regulation := schema.DefaultRegulationConfiguration
+		configuration.Regulation = &regulation
 	}
 
 	ValidateRegulation(configuration.Regulation, validator)
 
+	if configuration.GeoIP != nil {
+		ValidateGeoIP(configuration.GeoIP, validator)
+	} else if configuration.Session.ImpossibleTravel.Enabled {
+		validator.Push(fmt.Errorf("The session impossible travel detection requires a GeoIP database to be configured in `geoip`"))
+	}
+
+	if configuration.Risk != nil {
+		ValidateRisk(configuration.Risk, validator)
+	}
+
 	ValidateServer(&configuration.Server, validator)
 
-	ValidateStorage(configuration.Storage, validator)
+	if configuration.GRPC != nil {
+		ValidateGRPC(configuration, validator)
+	}
+
+	if configuration.Metrics != nil {
+		ValidateMetrics(configuration, validator)
+	}
+
+	if configuration.Audit != nil {
+		ValidateAudit(configuration.Audit, validator)
+	}
+
+	if configuration.Resolver != nil {
+		ValidateResolver(configuration.Resolver, validator)
+	}
+
+	if configuration.OutboundProxy != nil {
+		ValidateOutboundProxy(configuration.OutboundProxy, validator)
+	}
+
+	ValidateOutboundRequests(&configuration.OutboundRequests, validator)
+
+	ValidatePasswordPolicy(&configuration.PasswordPolicy, validator)
+
+	ValidateStorage(&configuration.Storage, validator)
 
 	if configuration.Notifier == nil {
 		validator.Push(fmt.Errorf("A notifier configuration must be provided"))
@@ -81,5 +132,19 @@ func ValidateConfiguration(configuration *schema.Configuration, validator *schem
 		ValidateNotifier(configuration.Notifier, validator)
 	}
 
+	if configuration.AuthenticationBackend.BreakGlass != nil && (configuration.Notifier == nil || configuration.Notifier.Operator == nil) {
+		validator.Push(fmt.Errorf("The break-glass accounts require the operator alerts to be configured in `notifier.operator`"))
+	}
+
 	ValidateIdentityProviders(&configuration.IdentityProviders, validator)
+
+	if configuration.IdentityAssertion != nil {
+		ValidateIdentityAssertion(configuration, validator)
+	}
+
+	if configuration.AccessGrants != nil {
+		ValidateAccessGrants(configuration.AccessGrants, validator)
+	}
+
+	validateCryptoProfileCompliance(configuration, validator)
 }
@@ -147,6 +147,28 @@ func TestShouldRaiseErrorWithUndefinedJWTSecretKey(t *testing.T) {
 	assert.EqualError(t, validator.Errors()[0], "Provide a JWT secret using \"jwt_secret\" key")
 }
 
+func TestShouldRaiseErrorWithBreakGlassWithoutOperatorAlerts(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := newDefaultConfig()
+	config.AuthenticationBackend.BreakGlass = &schema.BreakGlassConfiguration{
+		Accounts: []schema.BreakGlassAccountConfiguration{{Username: "emergency", Password: testBreakGlassPasswordHash}},
+	}
+
+	ValidateConfiguration(&config, validator)
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], "The break-glass accounts require the operator alerts to be configured in `notifier.operator`")
+}
+
+func TestShouldRaiseErrorWithSessionImpossibleTravelWithoutGeoIP(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := newDefaultConfig()
+	config.Session.ImpossibleTravel.Enabled = true
+
+	ValidateConfiguration(&config, validator)
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], "The session impossible travel detection requires a GeoIP database to be configured in `geoip`")
+}
+
 func TestShouldRaiseErrorWithBadDefaultRedirectionURL(t *testing.T) {
 	validator := schema.NewStructValidator()
 	config := newDefaultConfig()
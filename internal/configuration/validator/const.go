@@ -1,5 +1,7 @@
 package validator
 
+import "regexp"
+
 const (
 	errFmtDeprecatedConfigurationKey = "[DEPRECATED] The %s configuration option is deprecated and will be " +
 		"removed in %s, please use %s instead"
@@ -7,10 +9,15 @@ const (
 
 	errFmtLoggingLevelInvalid = "the log level '%s' is invalid, must be one of: %s"
 
+	errFmtLoggingMinimizationModeInvalid = "the log minimization of the %s '%s' is invalid, must be one of: %s"
+
 	errFmtSessionSecretRedisProvider      = "The session secret must be set when using the %s session provider"
 	errFmtSessionRedisPortRange           = "The port must be between 1 and 65535 for the %s session provider"
 	errFmtSessionRedisHostRequired        = "The host must be provided when using the %s session provider"
 	errFmtSessionRedisHostOrNodesRequired = "Either the host or a node must be provided when using the %s session provider"
+	errFmtSessionRedisUnixSocketPort      = "The port must not be set when the host is a unix socket path for the %s session provider"
+	errFmtSessionRedisUnixSocketTLS       = "The tls server_name must be set when using TLS over a unix socket for the %s session provider"
+	errFmtSessionRedisUnixSocketSentinel  = "The host must not be a unix socket path for the %s session provider"
 
 	errFmtOIDCServerClientRedirectURI = "OIDC client with ID '%s' redirect URI %s has an invalid scheme '%s', " +
 		"should be http or https"
@@ -23,12 +30,23 @@ const (
 		"must be one of: '%s'"
 	errFmtOIDCServerClientInvalidGrantType = "OIDC client with ID '%s' has an invalid grant type '%s', " +
 		"must be one of: '%s'"
+	errFmtOIDCServerClientInvalidLogoutURI = "OIDC client with ID '%s' has an invalid %s '%s', it must be an " +
+		"absolute http or https URL"
+	errFmtOIDCServerDynamicRegistrationInvalidPolicy = "OIDC Server dynamic registration has an invalid policy '%s', " +
+		"should be either 'one_factor' or 'two_factor'"
+	errFmtOIDCServerDynamicRegistrationInvalidScope = "OIDC Server dynamic registration has an invalid scope '%s', " +
+		"must be one of: '%s'"
+	errFmtOIDCServerClientRefreshTokenGrantType = "OIDC client with ID '%s' has the 'refresh_token' grant type " +
+		"which requires the 'authorization_code' or 'password' grant type"
+	errFmtOIDCServerClientInvalidAudience     = "OIDC client with ID '%s' has an empty audience"
 	errFmtOIDCServerClientInvalidResponseMode = "OIDC client with ID '%s' has an invalid response mode '%s', " +
 		"must be one of: '%s'"
 	errFmtOIDCServerClientInvalidUserinfoAlgorithm = "OIDC client with ID '%s' has an invalid userinfo signing " +
 		"algorithm '%s', must be one of: '%s'"
 	errFmtOIDCServerInsecureParameterEntropy = "SECURITY ISSUE: OIDC minimum parameter entropy is configured to an " +
 		"unsafe value, it should be above 8 but it's configured to %d."
+	errFmtOIDCServerInvalidLifespan  = "OIDC Server %s lifespan must be 0 or more"
+	errFmtOIDCServerInvalidIssuerKey = "OIDC Server issuer private key %d is not a valid RSA, ECDSA P-256 or Ed25519 private key: %w"
 
 	errFileHashing = "config key incorrect: authentication_backend.file.hashing should be " +
 		"authentication_backend.file.password"
@@ -42,6 +60,9 @@ const (
 	twoFactorPolicy = "two_factor"
 	denyPolicy      = "deny"
 
+	// redisDefaultDatabases is the number of databases of redis when it's not configured.
+	redisDefaultDatabases = 16
+
 	argon2id = "argon2id"
 	sha512   = "sha512"
 
@@ -64,29 +85,74 @@ const (
 	errAccessControlInvalidPolicyWithSubjects = "Policy [bypass] for rule #%d domain %s with subjects %s is invalid. " +
 		"It is not supported to configure both policy bypass and subjects. For more information see: " +
 		"https://www.authelia.com/docs/configuration/access-control.html#combining-subjects-and-the-bypass-policy"
+	errAccessControlTrustedNetworksInvalidPolicy = "Policy [%[3]s] for rule #%[1]d domain %[2]s with trusted networks is invalid. " +
+		"Trusted networks only apply to the two_factor policy. For more information see: " +
+		"https://www.authelia.com/docs/configuration/access-control.html#trusted_networks"
+	errAccessControlAccessGrantsInvalidPolicy = "Policy [%[3]s] for rule #%[1]d domain %[2]s with access grants is invalid. " +
+		"Access grants only apply to the one_factor and two_factor policies. For more information see: " +
+		"https://www.authelia.com/docs/configuration/access-control.html#access_grants"
 )
 
 var validLoggingLevels = []string{"trace", "debug", "info", "warn", "error"}
+
+var validLoggingMinimizationModes = []string{"none", "hash", "truncate"}
+var validSessionPinningStrictness = []string{"strict", "log"}
 var validHTTPRequestMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "TRACE", "CONNECT", "OPTIONS"}
 
 var validOIDCScopes = []string{"openid", "email", "profile", "groups", "offline_access"}
 var validOIDCGrantTypes = []string{"implicit", "refresh_token", "authorization_code", "password", "client_credentials"}
 var validOIDCResponseModes = []string{"form_post", "query", "fragment"}
-var validOIDCUserinfoAlgorithms = []string{"none", "RS256"}
+var validOIDCUserinfoAlgorithms = []string{"none", "RS256", "ES256", "EdDSA"}
+
+var roleHeaderRegexp = regexp.MustCompile(`^[a-zA-Z0-9-]+$`)
+
+// reservedForwardedHeaders are the headers forwarded by Authelia which can't be used as role headers.
+var reservedForwardedHeaders = []string{"Remote-User", "Remote-Name", "Remote-Email", "Remote-Groups"}
+
+var validOutboundProxySchemes = []string{"http", "https", "socks5"}
+
+var validAuditSyslogNetworks = []string{"udp", "tcp", "unix", "unixgram"}
+
+var validAddressFamilies = []string{"dual", "ipv4", "ipv6"}
+
+var validCompressionAlgorithms = []string{"br", "gzip"}
+
+// storageEncryptionKeyMinimumLength is the minimum length of the key the secrets of the storage are encrypted with.
+const storageEncryptionKeyMinimumLength = 20
+
+var redisKeyPrefixRegexp = regexp.MustCompile(`^[a-zA-Z0-9:._-]+$`)
 
 // SecretNames contains a map of secret names.
 var SecretNames = map[string]string{
-	"JWTSecret":                     "jwt_secret",
-	"SessionSecret":                 "session.secret",
-	"DUOSecretKey":                  "duo_api.secret_key",
-	"RedisPassword":                 "session.redis.password",
-	"RedisSentinelPassword":         "session.redis.high_availability.sentinel_password",
-	"LDAPPassword":                  "authentication_backend.ldap.password",
-	"SMTPPassword":                  "notifier.smtp.password",
-	"MySQLPassword":                 "storage.mysql.password",
-	"PostgreSQLPassword":            "storage.postgres.password",
-	"OpenIDConnectHMACSecret":       "identity_providers.oidc.hmac_secret",
-	"OpenIDConnectIssuerPrivateKey": "identity_providers.oidc.issuer_private_key",
+	"JWTSecret":                       "jwt_secret",
+	"SessionSecret":                   "session.secret",
+	"SessionPreviousSecret":           "session.previous_secret",
+	"DUOSecretKey":                    "duo_api.secret_key",
+	"RedisPassword":                   "session.redis.password",
+	"RedisSentinelPassword":           "session.redis.high_availability.sentinel_password",
+	"LDAPPassword":                    "authentication_backend.ldap.password",
+	"SMTPPassword":                    "notifier.smtp.password",
+	"MySQLPassword":                   "storage.mysql.password",
+	"PostgreSQLPassword":              "storage.postgres.password",
+	"MigrationMySQLPassword":          "storage.migration_target.mysql.password",
+	"MigrationPostgreSQLPassword":     "storage.migration_target.postgres.password",
+	"StorageEncryptionKey":            "storage.encryption_key",
+	"StoragePreviousEncryptionKey":    "storage.previous_encryption_key",
+	"OpenIDConnectHMACSecret":         "identity_providers.oidc.hmac_secret",
+	"OpenIDConnectPreviousHMACSecret": "identity_providers.oidc.previous_hmac_secret",
+	"OpenIDConnectIssuerPrivateKey":   "identity_providers.oidc.issuer_private_key",
+	"GRPCAdminToken":                  "grpc.admin_token",
+	"OutboundProxyURL":                "outbound_proxy.url",
+	"AuditWebhookSecret":              "audit.webhook.secret",
+	"LogMinimizationHMACKey":          "log.minimization.hmac_key",
+}
+
+// validKeyPrefixes is a list of the prefixes of the valid keys which are decoded by the custom providers, such as their
+// options.
+var validKeyPrefixes = []string{
+	"authentication_backend.options.",
+	"notifier.options.",
+	"storage.options.",
 }
 
 // validKeys is a list of valid keys that are not secret names. For the sake of consistency please place any secret in
@@ -100,12 +166,16 @@ var validKeys = []string{
 	"tls_key",
 	"tls_cert",
 	"certificates_directory",
+	"crypto_profile",
 
 	// Log keys.
 	"log.level",
 	"log.format",
 	"log.file_path",
 	"log.keep_stdout",
+	"log.minimization.usernames",
+	"log.minimization.ip_addresses",
+	"log.minimization.emails",
 
 	// TODO: DEPRECATED START. Remove in 4.33.0.
 	"log_level",
@@ -117,18 +187,117 @@ var validKeys = []string{
 	"server.read_buffer_size",
 	"server.write_buffer_size",
 	"server.path",
+	"server.external_url",
 	"server.enable_pprof",
 	"server.enable_expvars",
+	"server.admin_group",
+	"server.admin_roles.auditor",
+	"server.admin_roles.helpdesk",
+	"server.admin_roles.admin",
+	"server.compression.algorithms",
+	"server.compression.minimum_size",
+	"server.compression.content_types",
+	"server.rate_limit.requests",
+	"server.rate_limit.period",
+	"server.cors.allowed_origins",
+	"server.cors.max_age",
+	"server.max_connections_per_ip",
+	"server.max_request_body_size",
+	"server.read_timeout",
+	"server.write_timeout",
+	"server.idle_timeout",
+	"server.disable_keep_alive",
+	"server.address_family",
+
+	// Redirection Keys.
+	"redirection.login_url_template",
+	"redirection.allowed_domains",
+	"redirection.strip_parameters",
+	"redirection.signed_state",
+	"redirection.state_lifespan",
+	"redirection.default_urls",
+
+	// Banner Keys.
+	"banner.login",
+	"banner.announcement.id",
+	"banner.announcement.message",
+
+	// Logout Keys.
+	"logout.timeout",
+	"logout.applications",
+
+	// Identity Assertion Keys.
+	"identity_assertion.header",
+	"identity_assertion.issuer",
+	"identity_assertion.lifespan",
+
+	// Access Grants Keys.
+	"access_grants.parameter",
+	"access_grants.default_lifespan",
+	"access_grants.max_lifespan",
+
+	// Proxy Authentication Keys.
+	"proxy_authentication.header",
+	"proxy_authentication.rules",
+
+	// gRPC Keys.
+	"grpc.host",
+	"grpc.port",
+	"grpc.address_family",
+	"grpc.tls_cert",
+	"grpc.tls_key",
+
+	// Metrics Keys.
+	"metrics.host",
+	"metrics.port",
+	"metrics.address_family",
+
+	// Audit Keys.
+	"audit.buffer_size",
+	"audit.file.path",
+	"audit.syslog.network",
+	"audit.syslog.address",
+	"audit.syslog.tag",
+	"audit.webhook.url",
+	"audit.webhook.timeout",
+
+	// Resolver Keys.
+	"resolver.addresses",
+	"resolver.timeout",
+	"resolver.rotate",
+
+	// Outbound Proxy Keys.
+	"outbound_proxy.no_proxy",
+
+	// Outbound Requests Keys.
+	"outbound_requests.allowed_hosts",
+	"outbound_requests.blocked_networks",
+
+	// Password Policy Keys.
+	"password_policy.min_length",
+	"password_policy.max_length",
+	"password_policy.require_uppercase",
+	"password_policy.require_lowercase",
+	"password_policy.require_number",
+	"password_policy.require_special",
+	"password_policy.min_score",
 
 	// TOTP Keys.
 	"totp.issuer",
 	"totp.period",
 	"totp.skew",
 
+	// WebAuthn Keys.
+	"webauthn.display_name",
+	"webauthn.passwordless.enable",
+	"webauthn.passwordless.policy",
+
 	// Access Control Keys.
 	"access_control.rules",
 	"access_control.default_policy",
 	"access_control.networks",
+	"access_control.role_mappings",
+	"access_control.rules_file",
 
 	// Session Keys.
 	"session.name",
@@ -137,17 +306,32 @@ var validKeys = []string{
 	"session.expiration",
 	"session.inactivity",
 	"session.remember_me_duration",
+	"session.previous_secret_lifespan",
+	"session.pinning.ip",
+	"session.pinning.tls_fingerprint",
+	"session.pinning.tls_fingerprint_header",
+	"session.pinning.strictness",
+	"session.impossible_travel.enabled",
+	"session.impossible_travel.window",
 
 	// Redis Session Keys.
 	"session.redis.host",
 	"session.redis.port",
 	"session.redis.username",
 	"session.redis.database_index",
+	"session.redis.key_prefix",
 	"session.redis.maximum_active_connections",
 	"session.redis.minimum_idle_connections",
 	"session.redis.tls.minimum_version",
 	"session.redis.tls.skip_verify",
 	"session.redis.tls.server_name",
+	"session.redis.tls.cipher_suites",
+	"session.redis.tls.curve_preferences",
+	"session.redis.tls.client_certificate",
+	"session.redis.tls.client_key",
+	"session.redis.tls.certificate_authorities",
+	"session.redis.tls.revocation.ocsp",
+	"session.redis.tls.revocation.crl_file",
 	"session.redis.high_availability.sentinel_name",
 	"session.redis.high_availability.nodes",
 	"session.redis.high_availability.route_by_latency",
@@ -158,6 +342,14 @@ var validKeys = []string{
 	"session.redis.timeouts.read",
 	"session.redis.timeouts.write",
 
+	// Storage Keys.
+	"storage.provider",
+	"storage.options",
+	"storage.retention.interval",
+	"storage.retention.authentication_logs",
+	"storage.cache.disable",
+	"storage.cache.size",
+
 	// Local Storage Keys.
 	"storage.local.path",
 
@@ -166,17 +358,74 @@ var validKeys = []string{
 	"storage.mysql.port",
 	"storage.mysql.database",
 	"storage.mysql.username",
+	"storage.mysql.read_replicas",
+	"storage.mysql.pool.max_open_connections",
+	"storage.mysql.pool.max_idle_connections",
+	"storage.mysql.pool.connection_max_lifetime",
+	"storage.mysql.pool.connection_max_idle_time",
 
 	// PostgreSQL Storage Keys.
 	"storage.postgres.host",
 	"storage.postgres.port",
 	"storage.postgres.database",
 	"storage.postgres.username",
+	"storage.postgres.read_replicas",
+	"storage.postgres.pool.max_open_connections",
+	"storage.postgres.pool.max_idle_connections",
+	"storage.postgres.pool.connection_max_lifetime",
+	"storage.postgres.pool.connection_max_idle_time",
 	"storage.postgres.sslmode",
+	"storage.postgres.ssl_root_certificate",
+	"storage.postgres.ssl_certificate",
+	"storage.postgres.ssl_key",
+	"storage.postgres.fallback_hosts",
+	"storage.postgres.disable_prepared_statements",
+
+	// Storage Migration Target Keys.
+	"storage.migration_target.local.path",
+	"storage.migration_target.mysql.host",
+	"storage.migration_target.mysql.port",
+	"storage.migration_target.mysql.database",
+	"storage.migration_target.mysql.username",
+	"storage.migration_target.mysql.read_replicas",
+	"storage.migration_target.mysql.pool.max_open_connections",
+	"storage.migration_target.mysql.pool.max_idle_connections",
+	"storage.migration_target.mysql.pool.connection_max_lifetime",
+	"storage.migration_target.mysql.pool.connection_max_idle_time",
+	"storage.migration_target.postgres.host",
+	"storage.migration_target.postgres.port",
+	"storage.migration_target.postgres.database",
+	"storage.migration_target.postgres.username",
+	"storage.migration_target.postgres.read_replicas",
+	"storage.migration_target.postgres.pool.max_open_connections",
+	"storage.migration_target.postgres.pool.max_idle_connections",
+	"storage.migration_target.postgres.pool.connection_max_lifetime",
+	"storage.migration_target.postgres.pool.connection_max_idle_time",
+	"storage.migration_target.postgres.sslmode",
+	"storage.migration_target.postgres.ssl_root_certificate",
+	"storage.migration_target.postgres.ssl_certificate",
+	"storage.migration_target.postgres.ssl_key",
+	"storage.migration_target.postgres.fallback_hosts",
+	"storage.migration_target.postgres.disable_prepared_statements",
 
 	// FileSystem Notifier Keys.
 	"notifier.filesystem.filename",
 	"notifier.disable_startup_check",
+	"notifier.provider",
+	"notifier.options",
+
+	// Notifier Queue Keys.
+	"notifier.queue.workers",
+	"notifier.queue.size",
+	"notifier.queue.timeout",
+	"notifier.queue.max_retries",
+	"notifier.queue.backoff",
+	"notifier.throttling.interval",
+	"notifier.throttling.failed_attempts",
+	"notifier.operator.email",
+	"notifier.operator.webhook_url",
+	"notifier.operator.certificate_expiry",
+	"notifier.operator.check_interval",
 
 	// SMTP Notifier Keys.
 	"notifier.smtp.username",
@@ -188,14 +437,42 @@ var validKeys = []string{
 	"notifier.smtp.startup_check_address",
 	"notifier.smtp.disable_require_tls",
 	"notifier.smtp.disable_html_emails",
+	"notifier.smtp.timeout",
+	"notifier.smtp.categories.verification.sender_name",
+	"notifier.smtp.categories.verification.reply_to",
+	"notifier.smtp.categories.verification.subject",
+	"notifier.smtp.categories.security_alert.sender_name",
+	"notifier.smtp.categories.security_alert.reply_to",
+	"notifier.smtp.categories.security_alert.subject",
 	"notifier.smtp.tls.minimum_version",
 	"notifier.smtp.tls.skip_verify",
 	"notifier.smtp.tls.server_name",
+	"notifier.smtp.tls.cipher_suites",
+	"notifier.smtp.tls.curve_preferences",
+	"notifier.smtp.tls.client_certificate",
+	"notifier.smtp.tls.client_key",
+	"notifier.smtp.tls.certificate_authorities",
+	"notifier.smtp.tls.revocation.ocsp",
+	"notifier.smtp.tls.revocation.crl_file",
 
 	// Regulation Keys.
 	"regulation.max_retries",
 	"regulation.find_time",
 	"regulation.ban_time",
+	"regulation.second_factor.max_retries",
+	"regulation.second_factor.find_time",
+	"regulation.second_factor.ban_time",
+
+	// GeoIP Keys.
+	"geoip.database",
+
+	// Risk Keys.
+	"risk.history",
+	"risk.impossible_travel_window",
+	"risk.step_up_score",
+	"risk.deny_score",
+	"risk.external.url",
+	"risk.external.timeout",
 
 	// DUO API Keys.
 	"duo_api.hostname",
@@ -204,10 +481,17 @@ var validKeys = []string{
 	// Authentication Backend Keys.
 	"authentication_backend.disable_reset_password",
 	"authentication_backend.refresh_interval",
+	"authentication_backend.provider",
+	"authentication_backend.options",
 
 	// LDAP Authentication Backend Keys.
 	"authentication_backend.ldap.implementation",
 	"authentication_backend.ldap.url",
+	"authentication_backend.ldap.urls",
+	"authentication_backend.ldap.load_balancing",
+	"authentication_backend.ldap.health_check_interval",
+	"authentication_backend.ldap.pool.size",
+	"authentication_backend.ldap.pool.idle_timeout",
 	"authentication_backend.ldap.base_dn",
 	"authentication_backend.ldap.username_attribute",
 	"authentication_backend.ldap.additional_users_dn",
@@ -222,9 +506,17 @@ var validKeys = []string{
 	"authentication_backend.ldap.tls.minimum_version",
 	"authentication_backend.ldap.tls.skip_verify",
 	"authentication_backend.ldap.tls.server_name",
+	"authentication_backend.ldap.tls.cipher_suites",
+	"authentication_backend.ldap.tls.curve_preferences",
+	"authentication_backend.ldap.tls.client_certificate",
+	"authentication_backend.ldap.tls.client_key",
+	"authentication_backend.ldap.tls.certificate_authorities",
+	"authentication_backend.ldap.tls.revocation.ocsp",
+	"authentication_backend.ldap.tls.revocation.crl_file",
 
 	// File Authentication Backend Keys.
 	"authentication_backend.file.path",
+	"authentication_backend.file.watch",
 	"authentication_backend.file.password.algorithm",
 	"authentication_backend.file.password.iterations",
 	"authentication_backend.file.password.key_length",
@@ -232,12 +524,23 @@ var validKeys = []string{
 	"authentication_backend.file.password.memory",
 	"authentication_backend.file.password.parallelism",
 
+	// Break-glass Keys.
+	"authentication_backend.break_glass.window",
+	"authentication_backend.break_glass.accounts",
+
 	// Identity Provider Keys.
 	"identity_providers.oidc.clients",
+	"identity_providers.oidc.clients_file",
+	"identity_providers.oidc.dynamic_registration.authorization_policy",
+	"identity_providers.oidc.dynamic_registration.scopes",
+	"identity_providers.oidc.issuer_private_keys",
+	"identity_providers.oidc.key_rotation_interval",
+	"identity_providers.oidc.previous_hmac_secret_lifespan",
 	"identity_providers.oidc.id_token_lifespan",
 	"identity_providers.oidc.access_token_lifespan",
 	"identity_providers.oidc.refresh_token_lifespan",
 	"identity_providers.oidc.authorize_code_lifespan",
+	"identity_providers.oidc.remember_consent_lifespan",
 	"identity_providers.oidc.enable_client_debug_messages",
 }
 
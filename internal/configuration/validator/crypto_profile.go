@@ -0,0 +1,185 @@
+package validator
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/tls"
+	"fmt"
+	"strings"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// ValidateCryptoProfile validates the crypto profile and applies its defaults. It must be called before the other
+// sections are validated so their defaults comply with the profile.
+func ValidateCryptoProfile(configuration *schema.Configuration, validator *schema.StructValidator) {
+	if configuration.CryptoProfile == "" {
+		configuration.CryptoProfile = schema.DefaultCryptoProfile
+	}
+
+	configuration.CryptoProfile = strings.ToLower(configuration.CryptoProfile)
+
+	switch configuration.CryptoProfile {
+	case schema.CryptoProfileDefault:
+		return
+	case schema.CryptoProfileFIPS, schema.CryptoProfileStrict:
+		break
+	default:
+		validator.Push(fmt.Errorf("crypto_profile must be one of %s, %s or %s but it is configured as '%s'", schema.CryptoProfileDefault, schema.CryptoProfileFIPS, schema.CryptoProfileStrict, configuration.CryptoProfile))
+		return
+	}
+
+	if file := configuration.AuthenticationBackend.File; file != nil {
+		if file.Password == nil {
+			password := schema.DefaultPasswordSHA512Configuration
+			file.Password = &password
+		} else if file.Password.Algorithm == "" {
+			file.Password.Algorithm = sha512
+		}
+	}
+
+	if ldap := configuration.AuthenticationBackend.LDAP; ldap != nil {
+		ldap.TLS = cryptoProfileTLSDefaults(ldap.TLS, configuration.CryptoProfile)
+	}
+
+	if configuration.Notifier != nil && configuration.Notifier.SMTP != nil {
+		configuration.Notifier.SMTP.TLS = cryptoProfileTLSDefaults(configuration.Notifier.SMTP.TLS, configuration.CryptoProfile)
+	}
+
+	if redis := configuration.Session.Redis; redis != nil && redis.TLS != nil {
+		redis.TLS = cryptoProfileTLSDefaults(redis.TLS, configuration.CryptoProfile)
+	}
+}
+
+// cryptoProfileTLSDefaults returns a copy of the TLS configuration with the defaults of the crypto profile, the
+// configuration may be shared with the defaults of the schema so it's not modified.
+func cryptoProfileTLSDefaults(configuration *schema.TLSConfig, profile string) *schema.TLSConfig {
+	defaults := schema.TLSConfig{}
+
+	if configuration != nil {
+		defaults = *configuration
+	}
+
+	if defaults.MinimumVersion == "" {
+		defaults.MinimumVersion = "TLS1.2"
+
+		if profile == schema.CryptoProfileStrict {
+			defaults.MinimumVersion = "TLS1.3"
+		}
+	}
+
+	// The cipher suites can't be configured for TLS 1.3.
+	if len(defaults.CipherSuites) == 0 && profile != schema.CryptoProfileStrict {
+		for _, cipherSuite := range utils.FIPSCipherSuites {
+			defaults.CipherSuites = append(defaults.CipherSuites, tls.CipherSuiteName(cipherSuite))
+		}
+	}
+
+	if len(defaults.CurvePreferences) == 0 {
+		defaults.CurvePreferences = []string{"P-256", "P-384", "P-521"}
+	}
+
+	return &defaults
+}
+
+// validateCryptoProfileCompliance validates the configuration complies with the crypto profile once the defaults of
+// all the sections have been applied.
+func validateCryptoProfileCompliance(configuration *schema.Configuration, validator *schema.StructValidator) {
+	profile := configuration.CryptoProfile
+
+	if profile != schema.CryptoProfileFIPS && profile != schema.CryptoProfileStrict {
+		return
+	}
+
+	if file := configuration.AuthenticationBackend.File; file != nil && file.Password != nil && file.Password.Algorithm != sha512 {
+		validator.Push(fmt.Errorf("the %s crypto profile requires the %s password hashing algorithm but it is configured as '%s'", profile, sha512, file.Password.Algorithm))
+	}
+
+	if ldap := configuration.AuthenticationBackend.LDAP; ldap != nil && ldap.TLS != nil {
+		validateCryptoProfileTLS(ldap.TLS, "LDAP", profile, validator)
+	}
+
+	if configuration.Notifier != nil && configuration.Notifier.SMTP != nil && configuration.Notifier.SMTP.TLS != nil {
+		validateCryptoProfileTLS(configuration.Notifier.SMTP.TLS, "SMTP notifier", profile, validator)
+	}
+
+	if redis := configuration.Session.Redis; redis != nil {
+		if redis.TLS != nil {
+			validateCryptoProfileTLS(redis.TLS, "redis", profile, validator)
+		} else {
+			validator.PushWarning(fmt.Errorf("the %s crypto profile doesn't apply to the redis connection which doesn't use TLS", profile))
+		}
+	}
+
+	if oidc := configuration.IdentityProviders.OIDC; oidc != nil {
+		keys := make([]string, 0, len(oidc.IssuerPrivateKeys)+1)
+
+		if oidc.IssuerPrivateKey != "" {
+			keys = append(keys, oidc.IssuerPrivateKey)
+		}
+
+		for _, key := range oidc.IssuerPrivateKeys {
+			keys = append(keys, key.Key)
+		}
+
+		for _, data := range keys {
+			// The errors parsing the key are reported when the OpenID Connect provider starts.
+			key, err := utils.ParsePrivateKeyFromPemStr(data)
+			if err != nil {
+				continue
+			}
+
+			switch key := key.(type) {
+			case *rsa.PrivateKey:
+				if key.N.BitLen() < 2048 {
+					validator.Push(fmt.Errorf("the %s crypto profile requires an OIDC issuer private key of 2048 bits or more but it has %d bits", profile, key.N.BitLen()))
+				}
+			case ed25519.PrivateKey:
+				validator.Push(fmt.Errorf("the %s crypto profile doesn't allow the Ed25519 OIDC issuer private keys", profile))
+			}
+		}
+	}
+}
+
+func validateCryptoProfileTLS(configuration *schema.TLSConfig, name, profile string, validator *schema.StructValidator) {
+	if version, err := utils.TLSStringToTLSConfigVersion(configuration.MinimumVersion); err == nil && version < utils.CryptoProfileMinimumTLSVersion(profile) {
+		validator.Push(fmt.Errorf("the %s crypto profile doesn't allow the %s tls minimum_version %s", profile, name, configuration.MinimumVersion))
+	}
+
+	for _, cipherSuiteName := range configuration.CipherSuites {
+		if cipherSuite, err := utils.TLSStringToCipherSuite(cipherSuiteName); err == nil && !isFIPSCipherSuite(cipherSuite) {
+			validator.Push(fmt.Errorf("the %s crypto profile doesn't allow the %s tls cipher suite %s", profile, name, cipherSuiteName))
+		}
+	}
+
+	for _, curveName := range configuration.CurvePreferences {
+		if curve, err := utils.TLSStringToCurveID(curveName); err == nil && !isFIPSCurve(curve) {
+			validator.Push(fmt.Errorf("the %s crypto profile doesn't allow the %s tls curve %s", profile, name, curveName))
+		}
+	}
+
+	if configuration.SkipVerify {
+		validator.PushWarning(fmt.Errorf("the %s tls skip_verify disables the verification of the certificates required by the %s crypto profile", name, profile))
+	}
+}
+
+func isFIPSCipherSuite(cipherSuite uint16) bool {
+	for _, fipsCipherSuite := range utils.FIPSCipherSuites {
+		if cipherSuite == fipsCipherSuite {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isFIPSCurve(curve tls.CurveID) bool {
+	for _, fipsCurve := range utils.FIPSCurves {
+		if curve == fipsCurve {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,153 @@
+package validator
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+func TestShouldSetDefaultCryptoProfile(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := newDefaultConfig()
+
+	ValidateConfiguration(&config, validator)
+
+	require.Len(t, validator.Errors(), 0)
+	assert.Equal(t, schema.CryptoProfileDefault, config.CryptoProfile)
+	assert.Equal(t, argon2id, config.AuthenticationBackend.File.Password.Algorithm)
+}
+
+func TestShouldRaiseErrorOnInvalidCryptoProfile(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := newDefaultConfig()
+	config.CryptoProfile = "fips-140-3"
+
+	ValidateConfiguration(&config, validator)
+
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], "crypto_profile must be one of default, fips or strict but it is configured as 'fips-140-3'")
+}
+
+func TestShouldApplyFIPSCryptoProfileDefaults(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := newDefaultConfig()
+	config.CryptoProfile = "FIPS"
+	config.Notifier = &schema.NotifierConfiguration{
+		SMTP: &schema.SMTPNotifierConfiguration{
+			Host:   "smtp.example.com",
+			Port:   25,
+			Sender: "admin@example.com",
+		},
+	}
+
+	ValidateConfiguration(&config, validator)
+
+	require.Len(t, validator.Errors(), 0)
+	assert.Equal(t, schema.CryptoProfileFIPS, config.CryptoProfile)
+	assert.Equal(t, sha512, config.AuthenticationBackend.File.Password.Algorithm)
+	assert.Equal(t, schema.DefaultPasswordSHA512Configuration.Iterations, config.AuthenticationBackend.File.Password.Iterations)
+
+	tlsConfig := config.Notifier.SMTP.TLS
+	assert.Equal(t, "TLS1.2", tlsConfig.MinimumVersion)
+	assert.Equal(t, "smtp.example.com", tlsConfig.ServerName)
+	assert.Len(t, tlsConfig.CipherSuites, len(utils.FIPSCipherSuites))
+	assert.Equal(t, []string{"P-256", "P-384", "P-521"}, tlsConfig.CurvePreferences)
+
+	// The defaults of the schema are left untouched.
+	assert.Nil(t, schema.DefaultSMTPNotifierConfiguration.TLS.CipherSuites)
+}
+
+func TestShouldApplyStrictCryptoProfileDefaults(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := newDefaultConfig()
+	config.CryptoProfile = schema.CryptoProfileStrict
+	config.AuthenticationBackend.File = nil
+	config.AuthenticationBackend.LDAP = &schema.LDAPAuthenticationBackendConfiguration{
+		URL:          "ldaps://ldap.example.com",
+		User:         "cn=admin,dc=example,dc=com",
+		Password:     "password",
+		BaseDN:       "dc=example,dc=com",
+		UsersFilter:  "(&({username_attribute}={input})(objectClass=person))",
+		GroupsFilter: "(&(member={dn})(objectClass=groupOfNames))",
+	}
+
+	ValidateConfiguration(&config, validator)
+
+	require.Len(t, validator.Errors(), 0)
+	assert.Equal(t, "TLS1.3", config.AuthenticationBackend.LDAP.TLS.MinimumVersion)
+	assert.Nil(t, config.AuthenticationBackend.LDAP.TLS.CipherSuites)
+}
+
+func TestShouldRaiseErrorsWhenNotCompliantWithCryptoProfile(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	validator := schema.NewStructValidator()
+	config := newDefaultConfig()
+	config.CryptoProfile = schema.CryptoProfileStrict
+	config.AuthenticationBackend.File.Password = &schema.PasswordConfiguration{Algorithm: argon2id}
+	config.Session.Redis = &schema.RedisSessionConfiguration{
+		Host: "redis.example.com",
+		Port: 6379,
+		TLS: &schema.TLSConfig{
+			MinimumVersion:   "TLS1.2",
+			CipherSuites:     []string{"TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256"},
+			CurvePreferences: []string{"X25519"},
+		},
+	}
+	config.IdentityProviders.OIDC = &schema.OpenIDConnectConfiguration{
+		HMACSecret:       "rLABDrx87et5KvRHVUgTm3pezWWd8LMN",
+		IssuerPrivateKey: utils.ExportRsaPrivateKeyAsPemStr(key),
+		Clients: []schema.OpenIDConnectClientConfiguration{
+			{
+				ID:           "a-client",
+				Secret:       "a-secret",
+				RedirectURIs: []string{"https://google.com"},
+			},
+		},
+	}
+
+	ValidateConfiguration(&config, validator)
+
+	require.Len(t, validator.Errors(), 5)
+	assert.EqualError(t, validator.Errors()[0], "the strict crypto profile requires the sha512 password hashing algorithm but it is configured as 'argon2id'")
+	assert.EqualError(t, validator.Errors()[1], "the strict crypto profile doesn't allow the redis tls minimum_version TLS1.2")
+	assert.EqualError(t, validator.Errors()[2], "the strict crypto profile doesn't allow the redis tls cipher suite TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256")
+	assert.EqualError(t, validator.Errors()[3], "the strict crypto profile doesn't allow the redis tls curve X25519")
+	assert.EqualError(t, validator.Errors()[4], "the strict crypto profile requires an OIDC issuer private key of 2048 bits or more but it has 1024 bits")
+}
+
+func TestShouldRaiseErrorWhenEd25519IssuerKeyWithStrictCryptoProfile(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	validator := schema.NewStructValidator()
+	config := newDefaultConfig()
+	config.CryptoProfile = schema.CryptoProfileStrict
+	config.AuthenticationBackend.File.Password = nil
+	config.IdentityProviders.OIDC = &schema.OpenIDConnectConfiguration{
+		HMACSecret: "rLABDrx87et5KvRHVUgTm3pezWWd8LMN",
+		IssuerPrivateKeys: []schema.OpenIDConnectIssuerKeyConfiguration{
+			{Key: exportPKCS8PrivateKeyAsPemStr(t, key), Active: true},
+		},
+		Clients: []schema.OpenIDConnectClientConfiguration{
+			{
+				ID:           "a-client",
+				Secret:       "a-secret",
+				RedirectURIs: []string{"https://google.com"},
+			},
+		},
+	}
+
+	ValidateConfiguration(&config, validator)
+
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], "the strict crypto profile doesn't allow the Ed25519 OIDC issuer private keys")
+}
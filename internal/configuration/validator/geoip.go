@@ -0,0 +1,20 @@
+package validator
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+// ValidateGeoIP validates the GeoIP configuration.
+func ValidateGeoIP(configuration *schema.GeoIPConfiguration, validator *schema.StructValidator) {
+	if configuration.Database == "" {
+		validator.Push(fmt.Errorf("A GeoIP database must be provided with the 'database' option"))
+		return
+	}
+
+	if _, err := os.Stat(configuration.Database); err != nil {
+		validator.Push(fmt.Errorf("Error occurred checking the GeoIP database: %v", err))
+	}
+}
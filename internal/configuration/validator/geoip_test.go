@@ -0,0 +1,46 @@
+package validator
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+func TestShouldRaiseErrorWhenGeoIPDatabaseNotProvided(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.GeoIPConfiguration{}
+
+	ValidateGeoIP(&config, validator)
+
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], "A GeoIP database must be provided with the 'database' option")
+}
+
+func TestShouldRaiseErrorWhenGeoIPDatabaseDoesNotExist(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.GeoIPConfiguration{Database: "/path/does/not/exist.csv"}
+
+	ValidateGeoIP(&config, validator)
+
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], "Error occurred checking the GeoIP database: stat /path/does/not/exist.csv: no such file or directory")
+}
+
+func TestShouldNotRaiseErrorWhenGeoIPDatabaseExists(t *testing.T) {
+	file, err := ioutil.TempFile("", "geoip-*.csv")
+	require.NoError(t, err)
+
+	defer os.Remove(file.Name())
+
+	validator := schema.NewStructValidator()
+	config := schema.GeoIPConfiguration{Database: file.Name()}
+
+	ValidateGeoIP(&config, validator)
+
+	assert.Len(t, validator.Errors(), 0)
+}
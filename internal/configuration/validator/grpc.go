@@ -0,0 +1,51 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// grpcAdminTokenMinimumLength is the length under which the admin token of the gRPC server is considered weak.
+const grpcAdminTokenMinimumLength = 32
+
+// ValidateGRPC validates and updates the gRPC server configuration.
+func ValidateGRPC(configuration *schema.Configuration, validator *schema.StructValidator) {
+	grpc := configuration.GRPC
+
+	if grpc.Host == "" {
+		grpc.Host = schema.DefaultGRPCConfiguration.Host
+	}
+
+	if grpc.Port == 0 {
+		grpc.Port = schema.DefaultGRPCConfiguration.Port
+	}
+
+	if grpc.Port < 0 || grpc.Port > 65535 {
+		validator.Push(fmt.Errorf("The gRPC server port %d is invalid, it must be between 1 and 65535", grpc.Port))
+	} else if grpc.Port == configuration.Port {
+		validator.Push(fmt.Errorf("The gRPC server port %d is invalid, it must be different from the port of the HTTP server", grpc.Port))
+	}
+
+	if grpc.AddressFamily == "" {
+		grpc.AddressFamily = schema.DefaultGRPCConfiguration.AddressFamily
+	} else if !utils.IsStringInSlice(grpc.AddressFamily, validAddressFamilies) {
+		validator.Push(fmt.Errorf("The gRPC server address family '%s' is invalid, it must be one of %s", grpc.AddressFamily, strings.Join(validAddressFamilies, ", ")))
+	}
+
+	if grpc.TLSKey != "" && grpc.TLSCert == "" {
+		validator.Push(fmt.Errorf("No TLS certificate provided for the gRPC server, please check the \"grpc.tls_cert\" which has been configured"))
+	} else if grpc.TLSKey == "" && grpc.TLSCert != "" {
+		validator.Push(fmt.Errorf("No TLS key provided for the gRPC server, please check the \"grpc.tls_key\" which has been configured"))
+	}
+
+	if grpc.AdminToken != "" && len(grpc.AdminToken) < grpcAdminTokenMinimumLength {
+		validator.Push(fmt.Errorf("The gRPC admin token must be at least %d characters long", grpcAdminTokenMinimumLength))
+	}
+
+	if grpc.AdminToken != "" && grpc.TLSCert == "" {
+		validator.PushWarning(fmt.Errorf("The gRPC admin API is enabled without TLS, the admin token is sent in clear text"))
+	}
+}
@@ -0,0 +1,65 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+func TestShouldSetDefaultGRPCValues(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.Configuration{Port: 9091, GRPC: &schema.GRPCConfiguration{}}
+
+	ValidateGRPC(config, validator)
+
+	assert.False(t, validator.HasErrors())
+	assert.False(t, validator.HasWarnings())
+	assert.Equal(t, "0.0.0.0", config.GRPC.Host)
+	assert.Equal(t, 9092, config.GRPC.Port)
+	assert.Equal(t, "dual", config.GRPC.AddressFamily)
+}
+
+func TestShouldRaiseErrorsOnInvalidGRPCConfiguration(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.Configuration{
+		Port: 9091,
+		GRPC: &schema.GRPCConfiguration{
+			Port:       9091,
+			TLSKey:     "/config/grpc.key",
+			AdminToken: "a_short_token",
+		},
+	}
+
+	ValidateGRPC(config, validator)
+
+	require.Len(t, validator.Errors(), 3)
+	assert.EqualError(t, validator.Errors()[0], "The gRPC server port 9091 is invalid, it must be different from the port of the HTTP server")
+	assert.EqualError(t, validator.Errors()[1], "No TLS certificate provided for the gRPC server, please check the \"grpc.tls_cert\" which has been configured")
+	assert.EqualError(t, validator.Errors()[2], "The gRPC admin token must be at least 32 characters long")
+
+	validator = schema.NewStructValidator()
+	config.GRPC = &schema.GRPCConfiguration{Port: 70000, AddressFamily: "ipv5"}
+
+	ValidateGRPC(config, validator)
+
+	require.Len(t, validator.Errors(), 2)
+	assert.EqualError(t, validator.Errors()[0], "The gRPC server port 70000 is invalid, it must be between 1 and 65535")
+	assert.EqualError(t, validator.Errors()[1], "The gRPC server address family 'ipv5' is invalid, it must be one of dual, ipv4, ipv6")
+}
+
+func TestShouldWarnWhenGRPCAdminTokenIsSentInClearText(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.Configuration{
+		Port: 9091,
+		GRPC: &schema.GRPCConfiguration{AdminToken: "a_very_long_admin_token_of_the_grpc_api"},
+	}
+
+	ValidateGRPC(config, validator)
+
+	assert.False(t, validator.HasErrors())
+	require.Len(t, validator.Warnings(), 1)
+	assert.EqualError(t, validator.Warnings()[0], "The gRPC admin API is enabled without TLS, the admin token is sent in clear text")
+}
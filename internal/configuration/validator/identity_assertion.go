@@ -0,0 +1,51 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// identityAssertionMaximumLifespan is the lifespan above which the identity assertion is no longer short-lived and
+// could be replayed for too long if it leaks.
+const identityAssertionMaximumLifespan = 5 * time.Minute
+
+// ValidateIdentityAssertion validates and updates the identity assertion configuration. The assertion is signed with
+// the keys of the OpenID Connect provider which must be configured.
+func ValidateIdentityAssertion(configuration *schema.Configuration, validator *schema.StructValidator) {
+	assertion := configuration.IdentityAssertion
+
+	if assertion.Header == "" {
+		assertion.Header = schema.DefaultIdentityAssertionConfiguration.Header
+	}
+
+	if assertion.Issuer == "" {
+		assertion.Issuer = schema.DefaultIdentityAssertionConfiguration.Issuer
+	}
+
+	if assertion.Lifespan == "" {
+		assertion.Lifespan = schema.DefaultIdentityAssertionConfiguration.Lifespan
+	}
+
+	if !roleHeaderRegexp.MatchString(assertion.Header) {
+		validator.Push(fmt.Errorf("The identity assertion header '%s' is invalid, it must only contain alphanumeric characters and hyphens", assertion.Header))
+	} else if utils.IsStringInSliceFold(assertion.Header, reservedForwardedHeaders) {
+		validator.Push(fmt.Errorf("The identity assertion header '%s' is invalid, it must not be one of the headers forwarded by Authelia: %s", assertion.Header, strings.Join(reservedForwardedHeaders, ", ")))
+	}
+
+	lifespan, err := utils.ParseDurationString(assertion.Lifespan)
+
+	switch {
+	case err != nil:
+		validator.Push(fmt.Errorf("Error occurred parsing identity assertion lifespan string: %s", err))
+	case lifespan > identityAssertionMaximumLifespan:
+		validator.PushWarning(fmt.Errorf("The identity assertion lifespan %s is above %s, a leaked assertion could be replayed for a long time", assertion.Lifespan, identityAssertionMaximumLifespan))
+	}
+
+	if configuration.IdentityProviders.OIDC == nil {
+		validator.Push(fmt.Errorf("The identity assertion requires the OpenID Connect identity provider to be configured since it's signed with its issuer private key"))
+	}
+}
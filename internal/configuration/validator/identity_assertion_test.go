@@ -0,0 +1,70 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+func TestShouldSetDefaultIdentityAssertionValues(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.Configuration{
+		IdentityAssertion: &schema.IdentityAssertionConfiguration{},
+		IdentityProviders: schema.IdentityProvidersConfiguration{
+			OIDC: &schema.OpenIDConnectConfiguration{},
+		},
+	}
+
+	ValidateIdentityAssertion(config, validator)
+
+	assert.False(t, validator.HasErrors())
+	assert.False(t, validator.HasWarnings())
+	assert.Equal(t, "Remote-Assertion", config.IdentityAssertion.Header)
+	assert.Equal(t, "authelia", config.IdentityAssertion.Issuer)
+	assert.Equal(t, "1m", config.IdentityAssertion.Lifespan)
+}
+
+func TestShouldRaiseErrorsOnInvalidIdentityAssertion(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.Configuration{
+		IdentityAssertion: &schema.IdentityAssertionConfiguration{
+			Header:   "Remote-User",
+			Lifespan: "one minute",
+		},
+	}
+
+	ValidateIdentityAssertion(config, validator)
+
+	require.Len(t, validator.Errors(), 3)
+	assert.EqualError(t, validator.Errors()[0], "The identity assertion header 'Remote-User' is invalid, it must not be one of the headers forwarded by Authelia: Remote-User, Remote-Name, Remote-Email, Remote-Groups")
+	assert.EqualError(t, validator.Errors()[1], "Error occurred parsing identity assertion lifespan string: could not convert the input string of one minute into a duration")
+	assert.EqualError(t, validator.Errors()[2], "The identity assertion requires the OpenID Connect identity provider to be configured since it's signed with its issuer private key")
+
+	validator = schema.NewStructValidator()
+	config.IdentityAssertion.Header = "Remote Assertion"
+
+	ValidateIdentityAssertion(config, validator)
+
+	assert.EqualError(t, validator.Errors()[0], "The identity assertion header 'Remote Assertion' is invalid, it must only contain alphanumeric characters and hyphens")
+}
+
+func TestShouldWarnOnLongIdentityAssertionLifespan(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.Configuration{
+		IdentityAssertion: &schema.IdentityAssertionConfiguration{
+			Lifespan: "1h",
+		},
+		IdentityProviders: schema.IdentityProvidersConfiguration{
+			OIDC: &schema.OpenIDConnectConfiguration{},
+		},
+	}
+
+	ValidateIdentityAssertion(config, validator)
+
+	assert.False(t, validator.HasErrors())
+	require.Len(t, validator.Warnings(), 1)
+	assert.EqualError(t, validator.Warnings()[0], "The identity assertion lifespan 1h is above 5m0s, a leaked assertion could be replayed for a long time")
+}
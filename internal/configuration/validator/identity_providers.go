@@ -17,35 +17,104 @@ func ValidateIdentityProviders(configuration *schema.IdentityProvidersConfigurat
 
 func validateOIDC(configuration *schema.OpenIDConnectConfiguration, validator *schema.StructValidator) {
 	if configuration != nil {
-		if configuration.IssuerPrivateKey == "" {
-			validator.Push(fmt.Errorf("OIDC Server issuer private key must be provided"))
-		}
+		validateOIDCIssuerKeys(configuration, validator)
+
+		validateOIDCLifespans(configuration, validator)
+
+		validateOIDCPreviousHMACSecret(configuration, validator)
 
-		if configuration.AccessTokenLifespan == time.Duration(0) {
-			configuration.AccessTokenLifespan = schema.DefaultOpenIDConnectConfiguration.AccessTokenLifespan
+		if configuration.MinimumParameterEntropy != 0 && configuration.MinimumParameterEntropy < 8 {
+			validator.PushWarning(fmt.Errorf(errFmtOIDCServerInsecureParameterEntropy, configuration.MinimumParameterEntropy))
 		}
 
-		if configuration.AuthorizeCodeLifespan == time.Duration(0) {
-			configuration.AuthorizeCodeLifespan = schema.DefaultOpenIDConnectConfiguration.AuthorizeCodeLifespan
+		validateOIDCClients(configuration, validator)
+		validateOIDCDynamicRegistration(configuration, validator)
+
+		// The clients may all be registered at runtime when the dynamic registration is enabled.
+		if len(configuration.Clients) == 0 && configuration.DynamicRegistration == nil {
+			validator.Push(fmt.Errorf("OIDC Server has no clients defined"))
 		}
+	}
+}
+
+// validateOIDCLifespans sets the default lifespan of the tokens and remembered consents which aren't configured.
+func validateOIDCLifespans(configuration *schema.OpenIDConnectConfiguration, validator *schema.StructValidator) {
+	lifespans := []struct {
+		name     string
+		value    *time.Duration
+		fallback time.Duration
+	}{
+		{"access token", &configuration.AccessTokenLifespan, schema.DefaultOpenIDConnectConfiguration.AccessTokenLifespan},
+		{"authorize code", &configuration.AuthorizeCodeLifespan, schema.DefaultOpenIDConnectConfiguration.AuthorizeCodeLifespan},
+		{"ID token", &configuration.IDTokenLifespan, schema.DefaultOpenIDConnectConfiguration.IDTokenLifespan},
+		{"refresh token", &configuration.RefreshTokenLifespan, schema.DefaultOpenIDConnectConfiguration.RefreshTokenLifespan},
+		{"remember consent", &configuration.RememberConsentLifespan, schema.DefaultOpenIDConnectConfiguration.RememberConsentLifespan},
+	}
 
-		if configuration.IDTokenLifespan == time.Duration(0) {
-			configuration.IDTokenLifespan = schema.DefaultOpenIDConnectConfiguration.IDTokenLifespan
+	for _, lifespan := range lifespans {
+		switch {
+		case *lifespan.value < 0:
+			validator.Push(fmt.Errorf(errFmtOIDCServerInvalidLifespan, lifespan.name))
+		case *lifespan.value == 0:
+			*lifespan.value = lifespan.fallback
 		}
+	}
+}
+
+// validateOIDCPreviousHMACSecret must be called once the lifespans have been validated, the tokens signed with the
+// previous HMAC secret are accepted for the lifespan of the refresh tokens by default.
+func validateOIDCPreviousHMACSecret(configuration *schema.OpenIDConnectConfiguration, validator *schema.StructValidator) {
+	if configuration.PreviousHMACSecret == "" {
+		return
+	}
 
-		if configuration.RefreshTokenLifespan == time.Duration(0) {
-			configuration.RefreshTokenLifespan = schema.DefaultOpenIDConnectConfiguration.RefreshTokenLifespan
+	if configuration.PreviousHMACSecret == configuration.HMACSecret {
+		validator.Push(fmt.Errorf("OIDC Server previous HMAC secret must be different from the HMAC secret"))
+	}
+
+	switch {
+	case configuration.PreviousHMACSecretLifespan < 0:
+		validator.Push(fmt.Errorf("OIDC Server previous HMAC secret lifespan must be 0 or more"))
+	case configuration.PreviousHMACSecretLifespan == 0:
+		configuration.PreviousHMACSecretLifespan = configuration.RefreshTokenLifespan
+	}
+}
+
+func validateOIDCIssuerKeys(configuration *schema.OpenIDConnectConfiguration, validator *schema.StructValidator) {
+	if configuration.IssuerPrivateKey == "" && len(configuration.IssuerPrivateKeys) == 0 {
+		validator.Push(fmt.Errorf("OIDC Server issuer private key must be provided"))
+		return
+	}
+
+	active := 0
+
+	for i, key := range configuration.IssuerPrivateKeys {
+		if key.Active {
+			active++
 		}
 
-		if configuration.MinimumParameterEntropy != 0 && configuration.MinimumParameterEntropy < 8 {
-			validator.PushWarning(fmt.Errorf(errFmtOIDCServerInsecureParameterEntropy, configuration.MinimumParameterEntropy))
+		if _, err := utils.ParsePrivateKeyFromPemStr(key.Key); err != nil {
+			validator.Push(fmt.Errorf(errFmtOIDCServerInvalidIssuerKey, i+1, err))
 		}
+	}
 
-		validateOIDCClients(configuration, validator)
+	switch {
+	case configuration.IssuerPrivateKey != "" && active != 0:
+		validator.Push(fmt.Errorf("OIDC Server issuer private key is the active key, none of the issuer private keys can be marked active"))
+	case configuration.IssuerPrivateKey == "" && active != 1:
+		validator.Push(fmt.Errorf("OIDC Server must have exactly one active issuer private key but %d are marked active", active))
+	}
 
-		if len(configuration.Clients) == 0 {
-			validator.Push(fmt.Errorf("OIDC Server has no clients defined"))
-		}
+	keys := len(configuration.IssuerPrivateKeys)
+	if configuration.IssuerPrivateKey != "" {
+		keys++
+	}
+
+	switch {
+	case configuration.KeyRotationInterval < 0:
+		validator.Push(fmt.Errorf("OIDC Server key rotation interval must be 0 or more"))
+	case configuration.KeyRotationInterval > 0 && keys < 2:
+		validator.Push(fmt.Errorf("OIDC Server key rotation requires at least 2 issuer private keys"))
 	}
 }
 
@@ -80,11 +149,13 @@ func validateOIDCClients(configuration *schema.OpenIDConnectConfiguration, valid
 
 		validateOIDCClientScopes(c, configuration, validator)
 		validateOIDCClientGrantTypes(c, configuration, validator)
+		validateOIDCClientAudience(c, configuration, validator)
 		validateOIDCClientResponseTypes(c, configuration, validator)
 		validateOIDCClientResponseModes(c, configuration, validator)
 		validateOIDDClientUserinfoAlgorithm(c, configuration, validator)
 
 		validateOIDCClientRedirectURIs(client, validator)
+		validateOIDCClientLogoutURIs(client, validator)
 	}
 
 	if invalidID {
@@ -96,6 +167,35 @@ func validateOIDCClients(configuration *schema.OpenIDConnectConfiguration, valid
 	}
 }
 
+// validateOIDCDynamicRegistration sets the default policy and scopes of the registered clients.
+func validateOIDCDynamicRegistration(configuration *schema.OpenIDConnectConfiguration, validator *schema.StructValidator) {
+	registration := configuration.DynamicRegistration
+	if registration == nil {
+		return
+	}
+
+	if registration.Policy == "" {
+		registration.Policy = schema.DefaultOpenIDConnectClientConfiguration.Policy
+	} else if registration.Policy != oneFactorPolicy && registration.Policy != twoFactorPolicy {
+		validator.Push(fmt.Errorf(errFmtOIDCServerDynamicRegistrationInvalidPolicy, registration.Policy))
+	}
+
+	if len(registration.Scopes) == 0 {
+		registration.Scopes = schema.DefaultOpenIDConnectClientConfiguration.Scopes
+		return
+	}
+
+	if !utils.IsStringInSlice("openid", registration.Scopes) {
+		registration.Scopes = append(registration.Scopes, "openid")
+	}
+
+	for _, scope := range registration.Scopes {
+		if !utils.IsStringInSlice(scope, validOIDCScopes) {
+			validator.Push(fmt.Errorf(errFmtOIDCServerDynamicRegistrationInvalidScope, scope, strings.Join(validOIDCScopes, "', '")))
+		}
+	}
+}
+
 func validateOIDCClientScopes(c int, configuration *schema.OpenIDConnectConfiguration, validator *schema.StructValidator) {
 	if len(configuration.Clients[c].Scopes) == 0 {
 		configuration.Clients[c].Scopes = schema.DefaultOpenIDConnectClientConfiguration.Scopes
@@ -121,13 +221,30 @@ func validateOIDCClientGrantTypes(c int, configuration *schema.OpenIDConnectConf
 		return
 	}
 
-	for _, grantType := range configuration.Clients[c].GrantTypes {
+	grantTypes := configuration.Clients[c].GrantTypes
+
+	for _, grantType := range grantTypes {
 		if !utils.IsStringInSlice(grantType, validOIDCGrantTypes) {
 			validator.Push(fmt.Errorf(
 				errFmtOIDCServerClientInvalidGrantType,
 				configuration.Clients[c].ID, grantType, strings.Join(validOIDCGrantTypes, "', '")))
 		}
 	}
+
+	// The refresh tokens are only issued by the authorization_code and password grants.
+	if utils.IsStringInSlice("refresh_token", grantTypes) &&
+		!utils.IsStringInSlice("authorization_code", grantTypes) && !utils.IsStringInSlice("password", grantTypes) {
+		validator.Push(fmt.Errorf(errFmtOIDCServerClientRefreshTokenGrantType, configuration.Clients[c].ID))
+	}
+}
+
+func validateOIDCClientAudience(c int, configuration *schema.OpenIDConnectConfiguration, validator *schema.StructValidator) {
+	for _, audience := range configuration.Clients[c].Audience {
+		if strings.TrimSpace(audience) == "" {
+			validator.Push(fmt.Errorf(errFmtOIDCServerClientInvalidAudience, configuration.Clients[c].ID))
+			return
+		}
+	}
 }
 
 func validateOIDCClientResponseTypes(c int, configuration *schema.OpenIDConnectConfiguration, _ *schema.StructValidator) {
@@ -175,3 +292,25 @@ func validateOIDCClientRedirectURIs(client schema.OpenIDConnectClientConfigurati
 		}
 	}
 }
+
+func validateOIDCClientLogoutURIs(client schema.OpenIDConnectClientConfiguration, validator *schema.StructValidator) {
+	if client.BackChannelLogoutURI != "" {
+		validateOIDCClientLogoutURI(client.ID, "back-channel logout URI", client.BackChannelLogoutURI, validator)
+	}
+
+	if client.FrontChannelLogoutURI != "" {
+		validateOIDCClientLogoutURI(client.ID, "front-channel logout URI", client.FrontChannelLogoutURI, validator)
+	}
+
+	for _, uri := range client.PostLogoutRedirectURIs {
+		validateOIDCClientLogoutURI(client.ID, "post logout redirect URI", uri, validator)
+	}
+}
+
+func validateOIDCClientLogoutURI(id, name, uri string, validator *schema.StructValidator) {
+	parsedURI, err := url.Parse(uri)
+	if err != nil || !parsedURI.IsAbs() || parsedURI.Host == "" ||
+		(parsedURI.Scheme != schemeHTTPS && parsedURI.Scheme != schemeHTTP) {
+		validator.Push(fmt.Errorf(errFmtOIDCServerClientInvalidLogoutURI, id, name, uri))
+	}
+}
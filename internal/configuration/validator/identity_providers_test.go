@@ -1,6 +1,13 @@
 package validator
 
 import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"testing"
@@ -10,6 +17,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/utils"
 )
 
 func TestShouldRaiseErrorWhenInvalidOIDCServerConfiguration(t *testing.T) {
@@ -45,6 +53,36 @@ func TestShouldRaiseErrorWhenOIDCServerIssuerPrivateKeyPathInvalid(t *testing.T)
 	assert.EqualError(t, validator.Errors()[0], "OIDC Server has no clients defined")
 }
 
+func TestShouldValidateOIDCServerDynamicRegistration(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.IdentityProvidersConfiguration{
+		OIDC: &schema.OpenIDConnectConfiguration{
+			HMACSecret:          "rLABDrx87et5KvRHVUgTm3pezWWd8LMN",
+			IssuerPrivateKey:    "key-material",
+			DynamicRegistration: &schema.OpenIDConnectDynamicRegistrationConfiguration{},
+		},
+	}
+
+	ValidateIdentityProviders(config, validator)
+
+	assert.Len(t, validator.Errors(), 0)
+	assert.Equal(t, "two_factor", config.OIDC.DynamicRegistration.Policy)
+	assert.Equal(t, schema.DefaultOpenIDConnectClientConfiguration.Scopes, config.OIDC.DynamicRegistration.Scopes)
+
+	validator = schema.NewStructValidator()
+	config.OIDC.DynamicRegistration = &schema.OpenIDConnectDynamicRegistrationConfiguration{
+		Policy: "bypass",
+		Scopes: []string{"groups", "admin"},
+	}
+
+	ValidateIdentityProviders(config, validator)
+
+	require.Len(t, validator.Errors(), 2)
+	assert.EqualError(t, validator.Errors()[0], "OIDC Server dynamic registration has an invalid policy 'bypass', should be either 'one_factor' or 'two_factor'")
+	assert.EqualError(t, validator.Errors()[1], "OIDC Server dynamic registration has an invalid scope 'admin', must be one of: 'openid', 'email', 'profile', 'groups', 'offline_access'")
+	assert.Equal(t, []string{"groups", "admin", "openid"}, config.OIDC.DynamicRegistration.Scopes)
+}
+
 func TestShouldRaiseErrorWhenOIDCServerClientBadValues(t *testing.T) {
 	validator := schema.NewStructValidator()
 	config := &schema.IdentityProvidersConfiguration{
@@ -157,6 +195,127 @@ func TestShouldRaiseErrorWhenOIDCClientConfiguredWithBadGrantTypes(t *testing.T)
 		"'password', 'client_credentials'")
 }
 
+func TestShouldRaiseErrorWhenOIDCClientConfiguredWithRefreshTokenGrantTypeOnly(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.IdentityProvidersConfiguration{
+		OIDC: &schema.OpenIDConnectConfiguration{
+			HMACSecret:       "rLABDrx87et5KvRHVUgTm3pezWWd8LMN",
+			IssuerPrivateKey: "key-material",
+			Clients: []schema.OpenIDConnectClientConfiguration{
+				{
+					ID:         "service",
+					Secret:     "good_secret",
+					Policy:     "two_factor",
+					GrantTypes: []string{"client_credentials", "refresh_token"},
+				},
+			},
+		},
+	}
+
+	ValidateIdentityProviders(config, validator)
+
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], "OIDC client with ID 'service' has the 'refresh_token' grant type "+
+		"which requires the 'authorization_code' or 'password' grant type")
+}
+
+func TestShouldValidateOIDCClientAudience(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.IdentityProvidersConfiguration{
+		OIDC: &schema.OpenIDConnectConfiguration{
+			HMACSecret:       "rLABDrx87et5KvRHVUgTm3pezWWd8LMN",
+			IssuerPrivateKey: "key-material",
+			Clients: []schema.OpenIDConnectClientConfiguration{
+				{
+					ID:         "service",
+					Secret:     "good_secret",
+					GrantTypes: []string{"client_credentials"},
+					Audience:   []string{"https://api.example.com"},
+				},
+				{
+					ID:       "bad_audience",
+					Secret:   "good_secret",
+					Audience: []string{"https://api.example.com", " "},
+				},
+			},
+		},
+	}
+
+	ValidateIdentityProviders(config, validator)
+
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], "OIDC client with ID 'bad_audience' has an empty audience")
+}
+
+func TestShouldRaiseErrorWhenOIDCClientConfiguredWithBadLogoutURIs(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.IdentityProvidersConfiguration{
+		OIDC: &schema.OpenIDConnectConfiguration{
+			HMACSecret:       "rLABDrx87et5KvRHVUgTm3pezWWd8LMN",
+			IssuerPrivateKey: "key-material",
+			Clients: []schema.OpenIDConnectClientConfiguration{
+				{
+					ID:                     "good_id",
+					Secret:                 "good_secret",
+					RedirectURIs:           []string{"https://google.com/callback"},
+					BackChannelLogoutURI:   "https://google.com/logout/backchannel",
+					FrontChannelLogoutURI:  "https://google.com/logout/frontchannel",
+					PostLogoutRedirectURIs: []string{"https://google.com/logged-out"},
+				},
+				{
+					ID:                     "bad_id",
+					Secret:                 "good_secret",
+					RedirectURIs:           []string{"https://google.com/callback"},
+					BackChannelLogoutURI:   "/logout",
+					FrontChannelLogoutURI:  "ftp://google.com/logout",
+					PostLogoutRedirectURIs: []string{"https://google.com/logged-out", "google.com"},
+				},
+			},
+		},
+	}
+
+	ValidateIdentityProviders(config, validator)
+
+	require.Len(t, validator.Errors(), 3)
+	assert.EqualError(t, validator.Errors()[0], "OIDC client with ID 'bad_id' has an invalid back-channel logout URI "+
+		"'/logout', it must be an absolute http or https URL")
+	assert.EqualError(t, validator.Errors()[1], "OIDC client with ID 'bad_id' has an invalid front-channel logout URI "+
+		"'ftp://google.com/logout', it must be an absolute http or https URL")
+	assert.EqualError(t, validator.Errors()[2], "OIDC client with ID 'bad_id' has an invalid post logout redirect URI "+
+		"'google.com', it must be an absolute http or https URL")
+}
+
+func TestShouldRaiseErrorWhenOIDCServerLifespansNegative(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.IdentityProvidersConfiguration{
+		OIDC: &schema.OpenIDConnectConfiguration{
+			HMACSecret:           "rLABDrx87et5KvRHVUgTm3pezWWd8LMN",
+			IssuerPrivateKey:     "key-material",
+			AccessTokenLifespan:  -time.Minute,
+			RefreshTokenLifespan: -time.Hour,
+			IDTokenLifespan:      time.Minute * 5,
+
+			RememberConsentLifespan: -time.Hour,
+			Clients: []schema.OpenIDConnectClientConfiguration{
+				{
+					ID:     "good_id",
+					Secret: "good_secret",
+				},
+			},
+		},
+	}
+
+	ValidateIdentityProviders(config, validator)
+
+	require.Len(t, validator.Errors(), 3)
+	assert.EqualError(t, validator.Errors()[0], "OIDC Server access token lifespan must be 0 or more")
+	assert.EqualError(t, validator.Errors()[1], "OIDC Server refresh token lifespan must be 0 or more")
+	assert.EqualError(t, validator.Errors()[2], "OIDC Server remember consent lifespan must be 0 or more")
+
+	assert.Equal(t, time.Minute*5, config.OIDC.IDTokenLifespan)
+	assert.Equal(t, schema.DefaultOpenIDConnectConfiguration.AuthorizeCodeLifespan, config.OIDC.AuthorizeCodeLifespan)
+}
+
 func TestShouldRaiseErrorWhenOIDCClientConfiguredWithBadResponseModes(t *testing.T) {
 	validator := schema.NewStructValidator()
 	config := &schema.IdentityProvidersConfiguration{
@@ -208,7 +367,7 @@ func TestShouldRaiseErrorWhenOIDCClientConfiguredWithBadUserinfoAlg(t *testing.T
 
 	require.Len(t, validator.Errors(), 1)
 	assert.EqualError(t, validator.Errors()[0], "OIDC client with ID 'good_id' has an invalid userinfo "+
-		"signing algorithm 'rs256', must be one of: 'none, RS256'")
+		"signing algorithm 'rs256', must be one of: 'none, RS256, ES256, EdDSA'")
 }
 
 func TestValidateIdentityProvidersShouldRaiseWarningOnSecurityIssue(t *testing.T) {
@@ -266,7 +425,7 @@ func TestValidateIdentityProvidersShouldSetDefaultValues(t *testing.T) {
 						"groups",
 					},
 					GrantTypes: []string{
-						"refresh_token",
+						"client_credentials",
 					},
 					ResponseTypes: []string{
 						"token",
@@ -316,7 +475,7 @@ func TestValidateIdentityProvidersShouldSetDefaultValues(t *testing.T) {
 
 	// Assert Clients[1] ends up configured with only the configured GrantTypes.
 	require.Len(t, config.OIDC.Clients[1].GrantTypes, 1)
-	assert.Equal(t, "refresh_token", config.OIDC.Clients[1].GrantTypes[0])
+	assert.Equal(t, "client_credentials", config.OIDC.Clients[1].GrantTypes[0])
 
 	// Assert Clients[0] ends up configured with the default ResponseTypes.
 	require.Len(t, config.OIDC.Clients[0].ResponseTypes, 1)
@@ -343,4 +502,153 @@ func TestValidateIdentityProvidersShouldSetDefaultValues(t *testing.T) {
 	assert.Equal(t, time.Minute, config.OIDC.AuthorizeCodeLifespan)
 	assert.Equal(t, time.Hour, config.OIDC.IDTokenLifespan)
 	assert.Equal(t, time.Minute*90, config.OIDC.RefreshTokenLifespan)
+	assert.Equal(t, time.Hour*24*30, config.OIDC.RememberConsentLifespan)
+}
+
+func TestShouldRaiseErrorWhenOIDCServerIssuerPrivateKeysInvalid(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	pem := utils.ExportRsaPrivateKeyAsPemStr(key)
+
+	ecdsaP256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	ecdsaP384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		config   schema.OpenIDConnectConfiguration
+		expected []string
+	}{
+		{
+			name: "ShouldNotAllowActiveKeyWithIssuerPrivateKey",
+			config: schema.OpenIDConnectConfiguration{
+				IssuerPrivateKey:  "key-material",
+				IssuerPrivateKeys: []schema.OpenIDConnectIssuerKeyConfiguration{{Key: pem, Active: true}},
+			},
+			expected: []string{"OIDC Server issuer private key is the active key, none of the issuer private keys can be marked active"},
+		},
+		{
+			name: "ShouldRequireExactlyOneActiveKey",
+			config: schema.OpenIDConnectConfiguration{
+				IssuerPrivateKeys: []schema.OpenIDConnectIssuerKeyConfiguration{{Key: pem, Active: true}, {Key: pem, Active: true}},
+			},
+			expected: []string{"OIDC Server must have exactly one active issuer private key but 2 are marked active"},
+		},
+		{
+			name: "ShouldRequireValidKeys",
+			config: schema.OpenIDConnectConfiguration{
+				IssuerPrivateKeys: []schema.OpenIDConnectIssuerKeyConfiguration{{Key: pem, Active: true}, {Key: "invalid"}},
+			},
+			expected: []string{"OIDC Server issuer private key 2 is not a valid RSA, ECDSA P-256 or Ed25519 private key: failed to parse PEM block containing the key"},
+		},
+		{
+			name: "ShouldAllowECDSAAndEd25519Keys",
+			config: schema.OpenIDConnectConfiguration{
+				IssuerPrivateKeys: []schema.OpenIDConnectIssuerKeyConfiguration{
+					{Key: exportPKCS8PrivateKeyAsPemStr(t, ecdsaP256Key), Active: true},
+					{Key: exportPKCS8PrivateKeyAsPemStr(t, ed25519Key)},
+				},
+			},
+		},
+		{
+			name: "ShouldNotAllowECDSAKeysOfOtherCurves",
+			config: schema.OpenIDConnectConfiguration{
+				IssuerPrivateKeys: []schema.OpenIDConnectIssuerKeyConfiguration{
+					{Key: exportPKCS8PrivateKeyAsPemStr(t, ecdsaP384Key), Active: true},
+				},
+			},
+			expected: []string{"OIDC Server issuer private key 1 is not a valid RSA, ECDSA P-256 or Ed25519 private key: ECDSA key curve P-384 is not supported, only P-256 is"},
+		},
+		{
+			name: "ShouldRequireTwoKeysToRotate",
+			config: schema.OpenIDConnectConfiguration{
+				IssuerPrivateKey:    "key-material",
+				KeyRotationInterval: time.Hour,
+			},
+			expected: []string{"OIDC Server key rotation requires at least 2 issuer private keys"},
+		},
+		{
+			name: "ShouldNotAllowNegativeRotationInterval",
+			config: schema.OpenIDConnectConfiguration{
+				IssuerPrivateKeys:   []schema.OpenIDConnectIssuerKeyConfiguration{{Key: pem, Active: true}},
+				KeyRotationInterval: -time.Hour,
+			},
+			expected: []string{"OIDC Server key rotation interval must be 0 or more"},
+		},
+		{
+			name: "ShouldAllowRotationOfSeveralKeys",
+			config: schema.OpenIDConnectConfiguration{
+				IssuerPrivateKey:    "key-material",
+				IssuerPrivateKeys:   []schema.OpenIDConnectIssuerKeyConfiguration{{Key: pem}},
+				KeyRotationInterval: time.Hour,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			validator := schema.NewStructValidator()
+
+			tc.config.HMACSecret = "rLABDrx87et5KvRHVUgTm3pezWWd8LMN"
+			tc.config.Clients = []schema.OpenIDConnectClientConfiguration{
+				{
+					ID:           "a-client",
+					Secret:       "a-secret",
+					RedirectURIs: []string{"https://google.com"},
+				},
+			}
+
+			ValidateIdentityProviders(&schema.IdentityProvidersConfiguration{OIDC: &tc.config}, validator)
+
+			require.Len(t, validator.Errors(), len(tc.expected))
+
+			for i, expected := range tc.expected {
+				assert.EqualError(t, validator.Errors()[i], expected)
+			}
+		})
+	}
+}
+
+func exportPKCS8PrivateKeyAsPemStr(t *testing.T, key interface{}) string {
+	data, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: data}))
+}
+
+func TestShouldValidateOIDCServerPreviousHMACSecret(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.IdentityProvidersConfiguration{
+		OIDC: &schema.OpenIDConnectConfiguration{
+			HMACSecret:                 "rLABDrx87et5KvRHVUgTm3pezWWd8LMN",
+			PreviousHMACSecret:         "rLABDrx87et5KvRHVUgTm3pezWWd8LMN",
+			PreviousHMACSecretLifespan: -time.Hour,
+			IssuerPrivateKey:           "key-material",
+		},
+	}
+
+	ValidateIdentityProviders(config, validator)
+
+	require.Len(t, validator.Errors(), 3)
+
+	assert.EqualError(t, validator.Errors()[0], "OIDC Server previous HMAC secret must be different from the HMAC secret")
+	assert.EqualError(t, validator.Errors()[1], "OIDC Server previous HMAC secret lifespan must be 0 or more")
+	assert.EqualError(t, validator.Errors()[2], "OIDC Server has no clients defined")
+
+	validator = schema.NewStructValidator()
+	config.OIDC.PreviousHMACSecret = "ZHjqKc5nLmkMD3PbXTzN8rEsVg6WyaFt"
+	config.OIDC.PreviousHMACSecretLifespan = 0
+
+	ValidateIdentityProviders(config, validator)
+
+	require.Len(t, validator.Errors(), 1)
+
+	// The tokens signed with the previous HMAC secret are accepted for the lifespan of the refresh tokens by default.
+	assert.Equal(t, schema.DefaultOpenIDConnectConfiguration.RefreshTokenLifespan, config.OIDC.PreviousHMACSecretLifespan)
 }
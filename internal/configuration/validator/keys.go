@@ -3,6 +3,7 @@ package validator
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/authelia/authelia/internal/configuration/schema"
 	"github.com/authelia/authelia/internal/utils"
@@ -17,7 +18,7 @@ func ValidateKeys(validator *schema.StructValidator, keys []string) {
 			continue
 		}
 
-		if isSecretKey(key) {
+		if isSecretKey(key) || hasValidKeyPrefix(key) {
 			continue
 		}
 
@@ -39,3 +40,13 @@ func ValidateKeys(validator *schema.StructValidator, keys []string) {
 		validator.Push(errors.New(err))
 	}
 }
+
+func hasValidKeyPrefix(key string) bool {
+	for _, prefix := range validKeyPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -33,6 +33,22 @@ func TestShouldNotValidateBadKeys(t *testing.T) {
 	assert.EqualError(t, errs[1], "config key not expected: totp.skewy")
 }
 
+func TestShouldValidateOptionsKeysOfCustomProviders(t *testing.T) {
+	configKeys := []string{
+		"authentication_backend.options.url",
+		"notifier.options.webhook.url",
+		"storage.options.bucket",
+		"storage.optionsx",
+	}
+	val := schema.NewStructValidator()
+	ValidateKeys(val, configKeys)
+
+	errs := val.Errors()
+	require.Len(t, errs, 1)
+
+	assert.EqualError(t, errs[0], "config key not expected: storage.optionsx")
+}
+
 func TestAllSpecificErrorKeys(t *testing.T) {
 	var configKeys []string //nolint:prealloc // This is because the test is dynamic based on the keys that exist in the map.
 
@@ -23,6 +23,42 @@ func ValidateLogging(configuration *schema.Configuration, validator *schema.Stru
 	if !utils.IsStringInSlice(configuration.Logging.Level, validLoggingLevels) {
 		validator.Push(fmt.Errorf(errFmtLoggingLevelInvalid, configuration.Logging.Level, strings.Join(validLoggingLevels, ", ")))
 	}
+
+	if configuration.Logging.Minimization != nil {
+		validateLoggingMinimization(configuration.Logging.Minimization, validator)
+	}
+}
+
+func validateLoggingMinimization(configuration *schema.LogMinimizationConfiguration, validator *schema.StructValidator) {
+	if configuration.Usernames == "" {
+		configuration.Usernames = schema.DefaultLogMinimizationConfiguration.Usernames
+	}
+
+	if configuration.IPAddresses == "" {
+		configuration.IPAddresses = schema.DefaultLogMinimizationConfiguration.IPAddresses
+	}
+
+	if configuration.Emails == "" {
+		configuration.Emails = schema.DefaultLogMinimizationConfiguration.Emails
+	}
+
+	hashed := false
+
+	for _, option := range []struct{ key, mode string }{
+		{"usernames", configuration.Usernames},
+		{"ip_addresses", configuration.IPAddresses},
+		{"emails", configuration.Emails},
+	} {
+		if !utils.IsStringInSlice(option.mode, validLoggingMinimizationModes) {
+			validator.Push(fmt.Errorf(errFmtLoggingMinimizationModeInvalid, option.key, option.mode, strings.Join(validLoggingMinimizationModes, ", ")))
+		}
+
+		hashed = hashed || option.mode == "hash"
+	}
+
+	if hashed && configuration.HMACKey == "" {
+		validator.Push(fmt.Errorf("the log minimization must have a hmac_key to hash the data"))
+	}
 }
 
 // TODO: DEPRECATED FUNCTION. Remove in 4.33.0.
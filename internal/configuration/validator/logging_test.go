@@ -48,6 +48,71 @@ func TestShouldRaiseErrorOnInvalidLoggingLevel(t *testing.T) {
 	assert.EqualError(t, validator.Errors()[0], "the log level 'TRACE' is invalid, must be one of: trace, debug, info, warn, error")
 }
 
+func TestShouldSetDefaultLoggingMinimizationValues(t *testing.T) {
+	config := &schema.Configuration{
+		Logging: schema.LogConfiguration{
+			Minimization: &schema.LogMinimizationConfiguration{},
+		},
+	}
+
+	validator := schema.NewStructValidator()
+
+	ValidateLogging(config, validator)
+
+	assert.Len(t, validator.Warnings(), 0)
+	assert.Len(t, validator.Errors(), 0)
+
+	assert.Equal(t, "none", config.Logging.Minimization.Usernames)
+	assert.Equal(t, "none", config.Logging.Minimization.IPAddresses)
+	assert.Equal(t, "none", config.Logging.Minimization.Emails)
+}
+
+func TestShouldRaiseErrorOnInvalidLoggingMinimizationMode(t *testing.T) {
+	config := &schema.Configuration{
+		Logging: schema.LogConfiguration{
+			Minimization: &schema.LogMinimizationConfiguration{
+				Usernames:   "truncate",
+				IPAddresses: "mask",
+			},
+		},
+	}
+
+	validator := schema.NewStructValidator()
+
+	ValidateLogging(config, validator)
+
+	assert.Len(t, validator.Warnings(), 0)
+	require.Len(t, validator.Errors(), 1)
+
+	assert.EqualError(t, validator.Errors()[0], "the log minimization of the ip_addresses 'mask' is invalid, must be one of: none, hash, truncate")
+}
+
+func TestShouldRaiseErrorWhenLoggingMinimizationHashesWithoutHMACKey(t *testing.T) {
+	config := &schema.Configuration{
+		Logging: schema.LogConfiguration{
+			Minimization: &schema.LogMinimizationConfiguration{
+				Emails: "hash",
+			},
+		},
+	}
+
+	validator := schema.NewStructValidator()
+
+	ValidateLogging(config, validator)
+
+	assert.Len(t, validator.Warnings(), 0)
+	require.Len(t, validator.Errors(), 1)
+
+	assert.EqualError(t, validator.Errors()[0], "the log minimization must have a hmac_key to hash the data")
+
+	validator = schema.NewStructValidator()
+	config.Logging.Minimization.HMACKey = "a-very-long-key"
+
+	ValidateLogging(config, validator)
+
+	assert.Len(t, validator.Errors(), 0)
+}
+
 // TODO: DEPRECATED TEST. Remove in 4.33.0.
 func TestShouldMigrateDeprecatedLoggingConfig(t *testing.T) {
 	config := &schema.Configuration{
@@ -0,0 +1,104 @@
+package validator
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/logout"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// ValidateLogout validates and updates the configuration of the logout of the applications.
+func ValidateLogout(configuration *schema.LogoutConfiguration, validator *schema.StructValidator) {
+	if configuration.Timeout == "" {
+		configuration.Timeout = schema.DefaultLogoutConfiguration.Timeout
+	}
+
+	if _, err := utils.ParseDurationString(configuration.Timeout); err != nil {
+		validator.Push(fmt.Errorf("Error occurred parsing logout timeout string: %s", err))
+	}
+
+	names := make([]string, 0, len(configuration.Applications))
+
+	for i := range configuration.Applications {
+		app := &configuration.Applications[i]
+
+		if app.Name == "" {
+			validator.Push(fmt.Errorf("The logout application at index %d must have a name", i))
+			continue
+		}
+
+		if utils.IsStringInSlice(app.Name, names) {
+			validator.Push(fmt.Errorf("The logout application name '%s' is used by several applications", app.Name))
+		}
+
+		names = append(names, app.Name)
+
+		validateLogoutApplication(app, validator)
+	}
+}
+
+func validateLogoutApplication(configuration *schema.LogoutApplicationConfiguration, validator *schema.StructValidator) {
+	if configuration.Method == "" {
+		configuration.Method = schema.DefaultLogoutApplicationConfiguration.Method
+	}
+
+	configuration.Method = strings.ToUpper(configuration.Method)
+
+	switch configuration.Method {
+	case "GET":
+		if configuration.Body != "" {
+			validator.Push(fmt.Errorf("The logout application '%s' must use the POST method to send a body", configuration.Name))
+		}
+	case "POST":
+		if configuration.ContentType == "" {
+			configuration.ContentType = schema.DefaultLogoutApplicationConfiguration.ContentType
+		}
+	default:
+		validator.Push(fmt.Errorf("The logout application '%s' method must be either GET or POST but it is configured as '%s'", configuration.Name, configuration.Method))
+	}
+
+	if configuration.URL == "" {
+		validator.Push(fmt.Errorf("The logout application '%s' must have a url", configuration.Name))
+		return
+	}
+
+	rawURL, err := renderLogoutTemplate(configuration.Name, configuration.URL)
+	if err != nil {
+		validator.Push(fmt.Errorf("Error occurred parsing the logout application '%s' url: %s", configuration.Name, err))
+		return
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		validator.Push(fmt.Errorf("The logout application '%s' url '%s' must be an absolute http or https URL", configuration.Name, configuration.URL))
+	}
+
+	if configuration.Body != "" {
+		if _, err = renderLogoutTemplate(configuration.Name, configuration.Body); err != nil {
+			validator.Push(fmt.Errorf("Error occurred parsing the logout application '%s' body: %s", configuration.Name, err))
+		}
+	}
+}
+
+// renderLogoutTemplate renders the template with a sample user to detect the references to unknown fields.
+func renderLogoutTemplate(name, text string) (string, error) {
+	tmpl, err := utils.NewURLTemplate(name, text)
+	if err != nil {
+		return "", err
+	}
+
+	buf := new(bytes.Buffer)
+
+	err = tmpl.Execute(buf, logout.User{
+		Username:    "john",
+		DisplayName: "John Doe",
+		Emails:      []string{"john@example.com"},
+		Groups:      []string{"admins"},
+	})
+
+	return strings.TrimSpace(buf.String()), err
+}
@@ -0,0 +1,58 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+func TestShouldSetDefaultLogoutValues(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.LogoutConfiguration{
+		Applications: []schema.LogoutApplicationConfiguration{
+			{Name: "grafana", URL: "https://grafana.example.com/logout"},
+			{Name: "wiki", URL: "https://wiki.example.com/logout", Method: "post", Body: "user={{ queryEscape .Username }}"},
+		},
+	}
+
+	ValidateLogout(&config, validator)
+
+	assert.False(t, validator.HasErrors())
+	assert.Equal(t, "5s", config.Timeout)
+	assert.Equal(t, "GET", config.Applications[0].Method)
+	assert.Equal(t, "", config.Applications[0].ContentType)
+	assert.Equal(t, "POST", config.Applications[1].Method)
+	assert.Equal(t, "application/x-www-form-urlencoded", config.Applications[1].ContentType)
+}
+
+func TestShouldRaiseErrorsOnInvalidLogoutApplications(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.LogoutConfiguration{
+		Timeout: "abc",
+		Applications: []schema.LogoutApplicationConfiguration{
+			{URL: "https://app.example.com/logout"},
+			{Name: "app", URL: "https://app.example.com/logout", Method: "DELETE"},
+			{Name: "app", URL: "https://app.example.com/logout", Body: "user={{ .Username }}"},
+			{Name: "no-url"},
+			{Name: "relative", URL: "/logout"},
+			{Name: "template", URL: "https://app.example.com/logout?user={{ .Unknown }}"},
+			{Name: "body", URL: "https://app.example.com/logout", Method: "POST", Body: "{{ .Username "},
+		},
+	}
+
+	ValidateLogout(&config, validator)
+
+	require.Len(t, validator.Errors(), 9)
+	assert.EqualError(t, validator.Errors()[0], "Error occurred parsing logout timeout string: could not convert the input string of abc into a duration")
+	assert.EqualError(t, validator.Errors()[1], "The logout application at index 0 must have a name")
+	assert.EqualError(t, validator.Errors()[2], "The logout application 'app' method must be either GET or POST but it is configured as 'DELETE'")
+	assert.EqualError(t, validator.Errors()[3], "The logout application name 'app' is used by several applications")
+	assert.EqualError(t, validator.Errors()[4], "The logout application 'app' must use the POST method to send a body")
+	assert.EqualError(t, validator.Errors()[5], "The logout application 'no-url' must have a url")
+	assert.EqualError(t, validator.Errors()[6], "The logout application 'relative' url '/logout' must be an absolute http or https URL")
+	assert.Contains(t, validator.Errors()[7].Error(), "Error occurred parsing the logout application 'template' url: ")
+	assert.EqualError(t, validator.Errors()[8], "Error occurred parsing the logout application 'body' body: template: body:1: unclosed action")
+}
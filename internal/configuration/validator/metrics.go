@@ -0,0 +1,37 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// ValidateMetrics validates and updates the metrics server configuration.
+func ValidateMetrics(configuration *schema.Configuration, validator *schema.StructValidator) {
+	metrics := configuration.Metrics
+
+	if metrics.Host == "" {
+		metrics.Host = schema.DefaultMetricsConfiguration.Host
+	}
+
+	if metrics.Port == 0 {
+		metrics.Port = schema.DefaultMetricsConfiguration.Port
+	}
+
+	switch {
+	case metrics.Port < 0 || metrics.Port > 65535:
+		validator.Push(fmt.Errorf("The metrics server port %d is invalid, it must be between 1 and 65535", metrics.Port))
+	case metrics.Port == configuration.Port:
+		validator.Push(fmt.Errorf("The metrics server port %d is invalid, it must be different from the port of the HTTP server", metrics.Port))
+	case configuration.GRPC != nil && metrics.Port == configuration.GRPC.Port:
+		validator.Push(fmt.Errorf("The metrics server port %d is invalid, it must be different from the port of the gRPC server", metrics.Port))
+	}
+
+	if metrics.AddressFamily == "" {
+		metrics.AddressFamily = schema.DefaultMetricsConfiguration.AddressFamily
+	} else if !utils.IsStringInSlice(metrics.AddressFamily, validAddressFamilies) {
+		validator.Push(fmt.Errorf("The metrics server address family '%s' is invalid, it must be one of %s", metrics.AddressFamily, strings.Join(validAddressFamilies, ", ")))
+	}
+}
@@ -0,0 +1,53 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+func TestShouldSetDefaultMetricsValues(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.Configuration{Port: 9091, Metrics: &schema.MetricsConfiguration{}}
+
+	ValidateMetrics(config, validator)
+
+	assert.False(t, validator.HasErrors())
+	assert.Equal(t, "0.0.0.0", config.Metrics.Host)
+	assert.Equal(t, 9959, config.Metrics.Port)
+	assert.Equal(t, "dual", config.Metrics.AddressFamily)
+}
+
+func TestShouldRaiseErrorsOnInvalidMetricsConfiguration(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.Configuration{
+		Port:    9091,
+		GRPC:    &schema.GRPCConfiguration{Port: 9092},
+		Metrics: &schema.MetricsConfiguration{Port: 9091, AddressFamily: "ipv5"},
+	}
+
+	ValidateMetrics(config, validator)
+
+	require.Len(t, validator.Errors(), 2)
+	assert.EqualError(t, validator.Errors()[0], "The metrics server port 9091 is invalid, it must be different from the port of the HTTP server")
+	assert.EqualError(t, validator.Errors()[1], "The metrics server address family 'ipv5' is invalid, it must be one of dual, ipv4, ipv6")
+
+	validator = schema.NewStructValidator()
+	config.Metrics = &schema.MetricsConfiguration{Port: 9092}
+
+	ValidateMetrics(config, validator)
+
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], "The metrics server port 9092 is invalid, it must be different from the port of the gRPC server")
+
+	validator = schema.NewStructValidator()
+	config.Metrics = &schema.MetricsConfiguration{Port: -1}
+
+	ValidateMetrics(config, validator)
+
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], "The metrics server port -1 is invalid, it must be between 1 and 65535")
+}
@@ -2,12 +2,45 @@ package validator
 
 import (
 	"fmt"
+	"net/mail"
+	"net/url"
 
 	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/utils"
 )
 
 // ValidateNotifier validates and update notifier configuration.
 func ValidateNotifier(configuration *schema.NotifierConfiguration, validator *schema.StructValidator) {
+	if configuration.Queue == nil {
+		queue := schema.DefaultNotifierQueueConfiguration
+		configuration.Queue = &queue
+	}
+
+	validateNotifierQueue(configuration.Queue, validator)
+
+	if configuration.Throttling == nil {
+		throttling := schema.DefaultNotifierThrottlingConfiguration
+		configuration.Throttling = &throttling
+	}
+
+	validateNotifierThrottling(configuration.Throttling, validator)
+
+	if configuration.Operator != nil {
+		validateNotifierOperator(configuration.Operator, validator)
+	}
+
+	if configuration.Provider == "" && len(configuration.Options) != 0 {
+		validator.Push(fmt.Errorf("Notifier `options` can only be used with a custom `provider`"))
+	}
+
+	if configuration.Provider != "" {
+		if configuration.SMTP != nil || configuration.FileSystem != nil {
+			validator.Push(fmt.Errorf("Notifier should be either a custom `provider`, `smtp` or `filesystem`"))
+		}
+
+		return
+	}
+
 	if configuration.SMTP == nil && configuration.FileSystem == nil ||
 		configuration.SMTP != nil && configuration.FileSystem != nil {
 		validator.Push(fmt.Errorf("Notifier should be either `smtp` or `filesystem`"))
@@ -26,6 +59,95 @@ func ValidateNotifier(configuration *schema.NotifierConfiguration, validator *sc
 	validateSMTPNotifier(configuration.SMTP, validator)
 }
 
+func validateNotifierQueue(configuration *schema.NotifierQueueConfiguration, validator *schema.StructValidator) {
+	if configuration.Workers < 0 {
+		validator.Push(fmt.Errorf("Workers of the notifier queue must be 0 or more"))
+	}
+
+	if configuration.Size < 0 {
+		validator.Push(fmt.Errorf("Size of the notifier queue must be 0 or more"))
+	} else if configuration.Size == 0 {
+		configuration.Size = schema.DefaultNotifierQueueConfiguration.Size
+	}
+
+	if configuration.MaxRetries < 0 {
+		validator.Push(fmt.Errorf("Max retries of the notifier queue must be 0 or more"))
+	}
+
+	if configuration.Timeout == "" {
+		configuration.Timeout = schema.DefaultNotifierQueueConfiguration.Timeout // 30 seconds
+	}
+
+	if configuration.Backoff == "" {
+		configuration.Backoff = schema.DefaultNotifierQueueConfiguration.Backoff // 5 seconds
+	}
+
+	timeout, err := utils.ParseDurationString(configuration.Timeout)
+	if err != nil {
+		validator.Push(fmt.Errorf("Error occurred parsing notifier queue timeout string: %s", err))
+	} else if timeout <= 0 {
+		validator.Push(fmt.Errorf("Timeout of the notifier queue must be greater than 0"))
+	}
+
+	if _, err := utils.ParseDurationString(configuration.Backoff); err != nil {
+		validator.Push(fmt.Errorf("Error occurred parsing notifier queue backoff string: %s", err))
+	}
+}
+
+func validateNotifierThrottling(configuration *schema.NotifierThrottlingConfiguration, validator *schema.StructValidator) {
+	if configuration.Interval == "" {
+		configuration.Interval = schema.DefaultNotifierThrottlingConfiguration.Interval // 1 hour
+	}
+
+	interval, err := utils.ParseDurationString(configuration.Interval)
+	if err != nil {
+		validator.Push(fmt.Errorf("Error occurred parsing notifier throttling interval string: %s", err))
+	}
+
+	if configuration.FailedAttempts < 0 {
+		validator.Push(fmt.Errorf("Failed attempts of the notifier throttling must be 0 or more"))
+	} else if configuration.FailedAttempts > 0 && err == nil && interval == 0 {
+		validator.Push(fmt.Errorf("Failed attempts of the notifier throttling require an interval greater than 0"))
+	}
+}
+
+func validateNotifierOperator(configuration *schema.NotifierOperatorConfiguration, validator *schema.StructValidator) {
+	if configuration.Email == "" && configuration.WebhookURL == "" {
+		validator.Push(fmt.Errorf("Operator alerts of the notifier require an email or a webhook_url"))
+	}
+
+	if configuration.Email != "" {
+		if _, err := mail.ParseAddress(configuration.Email); err != nil {
+			validator.Push(fmt.Errorf("Email '%s' of the operator alerts of the notifier is invalid: %s", configuration.Email, err))
+		}
+	}
+
+	if configuration.WebhookURL != "" {
+		if u, err := url.Parse(configuration.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			validator.Push(fmt.Errorf("Webhook URL '%s' of the operator alerts of the notifier must be an absolute http or https URL", configuration.WebhookURL))
+		}
+	}
+
+	if configuration.CertificateExpiry == "" {
+		configuration.CertificateExpiry = schema.DefaultNotifierOperatorConfiguration.CertificateExpiry // 2 weeks
+	}
+
+	if _, err := utils.ParseDurationString(configuration.CertificateExpiry); err != nil {
+		validator.Push(fmt.Errorf("Error occurred parsing notifier operator certificate expiry string: %s", err))
+	}
+
+	if configuration.CheckInterval == "" {
+		configuration.CheckInterval = schema.DefaultNotifierOperatorConfiguration.CheckInterval // 5 minutes
+	}
+
+	interval, err := utils.ParseDurationString(configuration.CheckInterval)
+	if err != nil {
+		validator.Push(fmt.Errorf("Error occurred parsing notifier operator check interval string: %s", err))
+	} else if interval <= 0 {
+		validator.Push(fmt.Errorf("Check interval of the notifier operator alerts must be greater than 0"))
+	}
+}
+
 func validateSMTPNotifier(configuration *schema.SMTPNotifierConfiguration, validator *schema.StructValidator) {
 	if configuration.StartupCheckAddress == "" {
 		configuration.StartupCheckAddress = "test@authelia.com"
@@ -51,6 +173,16 @@ func validateSMTPNotifier(configuration *schema.SMTPNotifierConfiguration, valid
 		configuration.Identifier = schema.DefaultSMTPNotifierConfiguration.Identifier
 	}
 
+	if configuration.Timeout == "" {
+		configuration.Timeout = schema.DefaultSMTPNotifierConfiguration.Timeout
+	}
+
+	if timeout, err := utils.ParseDurationString(configuration.Timeout); err != nil {
+		validator.Push(fmt.Errorf("Error occurred parsing SMTP notifier timeout string: %s", err))
+	} else if timeout <= 0 {
+		validator.Push(fmt.Errorf("Timeout of SMTP notifier must be greater than 0"))
+	}
+
 	if configuration.TLS == nil {
 		configuration.TLS = schema.DefaultSMTPNotifierConfiguration.TLS
 	}
@@ -58,4 +190,19 @@ func validateSMTPNotifier(configuration *schema.SMTPNotifierConfiguration, valid
 	if configuration.TLS.ServerName == "" {
 		configuration.TLS.ServerName = configuration.Host
 	}
+
+	validateTLSConfiguration(configuration.TLS, "SMTP notifier", validator)
+
+	validateSMTPNotifierCategory(configuration.Categories.Verification, "verification", validator)
+	validateSMTPNotifierCategory(configuration.Categories.SecurityAlert, "security_alert", validator)
+}
+
+func validateSMTPNotifierCategory(configuration *schema.SMTPNotifierCategoryConfiguration, name string, validator *schema.StructValidator) {
+	if configuration == nil || configuration.ReplyTo == "" {
+		return
+	}
+
+	if _, err := mail.ParseAddress(configuration.ReplyTo); err != nil {
+		validator.Push(fmt.Errorf("Reply-to address '%s' of the %s notifications of the SMTP notifier is invalid: %s", configuration.ReplyTo, name, err))
+	}
 }
@@ -23,6 +23,9 @@ func (suite *NotifierSuite) SetupTest() {
 		Host:     "example.com",
 		Port:     25,
 	}
+	suite.configuration.Queue = nil
+	suite.configuration.Throttling = nil
+	suite.configuration.Operator = nil
 }
 
 func (suite *NotifierSuite) TestShouldEnsureAtLeastSMTPOrFilesystemIsProvided() {
@@ -43,6 +46,41 @@ func (suite *NotifierSuite) TestShouldEnsureAtLeastSMTPOrFilesystemIsProvided()
 	suite.Assert().EqualError(suite.validator.Errors()[0], "Notifier should be either `smtp` or `filesystem`")
 }
 
+func (suite *NotifierSuite) TestShouldEnsureCustomProviderIsExclusive() {
+	suite.configuration.Provider = "custom"
+
+	defer func() {
+		suite.configuration.Provider = ""
+	}()
+
+	ValidateNotifier(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Require().Len(suite.validator.Errors(), 1)
+	suite.Assert().EqualError(suite.validator.Errors()[0], "Notifier should be either a custom `provider`, `smtp` or `filesystem`")
+
+	suite.validator.Clear()
+	suite.configuration.SMTP = nil
+
+	ValidateNotifier(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasErrors())
+}
+
+func (suite *NotifierSuite) TestShouldEnsureOptionsRequireCustomProvider() {
+	suite.configuration.Options = map[string]interface{}{"webhook": "https://chat.example.com/hook"}
+
+	defer func() {
+		suite.configuration.Options = nil
+	}()
+
+	ValidateNotifier(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Require().Len(suite.validator.Errors(), 1)
+	suite.Assert().EqualError(suite.validator.Errors()[0], "Notifier `options` can only be used with a custom `provider`")
+}
+
 func (suite *NotifierSuite) TestShouldEnsureEitherSMTPOrFilesystemIsProvided() {
 	ValidateNotifier(&suite.configuration, suite.validator)
 
@@ -127,6 +165,253 @@ func (suite *NotifierSuite) TestShouldEnsureSenderOfSMTPNotifierAreProvided() {
 	suite.Assert().EqualError(suite.validator.Errors()[0], "Sender of SMTP notifier must be provided")
 }
 
+func (suite *NotifierSuite) TestShouldSetDefaultQueue() {
+	ValidateNotifier(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Assert().False(suite.validator.HasErrors())
+
+	suite.Require().NotNil(suite.configuration.Queue)
+	suite.Assert().Equal(schema.DefaultNotifierQueueConfiguration, *suite.configuration.Queue)
+}
+
+func (suite *NotifierSuite) TestShouldSetDefaultQueueValues() {
+	suite.configuration.Queue = &schema.NotifierQueueConfiguration{Workers: 4}
+
+	ValidateNotifier(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Assert().False(suite.validator.HasErrors())
+
+	suite.Assert().Equal(4, suite.configuration.Queue.Workers)
+	suite.Assert().Equal(100, suite.configuration.Queue.Size)
+	suite.Assert().Equal(0, suite.configuration.Queue.MaxRetries)
+	suite.Assert().Equal("30s", suite.configuration.Queue.Timeout)
+	suite.Assert().Equal("5s", suite.configuration.Queue.Backoff)
+}
+
+func (suite *NotifierSuite) TestShouldRaiseErrorsOnInvalidQueue() {
+	suite.configuration.Queue = &schema.NotifierQueueConfiguration{
+		Workers:    -1,
+		Size:       -1,
+		MaxRetries: -1,
+		Timeout:    "0",
+		Backoff:    "5 seconds",
+	}
+
+	ValidateNotifier(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Require().Len(suite.validator.Errors(), 5)
+
+	suite.Assert().EqualError(suite.validator.Errors()[0], "Workers of the notifier queue must be 0 or more")
+	suite.Assert().EqualError(suite.validator.Errors()[1], "Size of the notifier queue must be 0 or more")
+	suite.Assert().EqualError(suite.validator.Errors()[2], "Max retries of the notifier queue must be 0 or more")
+	suite.Assert().EqualError(suite.validator.Errors()[3], "Timeout of the notifier queue must be greater than 0")
+	suite.Assert().EqualError(suite.validator.Errors()[4], "Error occurred parsing notifier queue backoff string: could not convert the input string of 5 seconds into a duration")
+}
+
+func (suite *NotifierSuite) TestShouldSetDefaultThrottling() {
+	ValidateNotifier(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Assert().False(suite.validator.HasErrors())
+
+	suite.Require().NotNil(suite.configuration.Throttling)
+	suite.Assert().Equal(schema.DefaultNotifierThrottlingConfiguration, *suite.configuration.Throttling)
+}
+
+func (suite *NotifierSuite) TestShouldRaiseErrorsOnInvalidThrottling() {
+	suite.configuration.Throttling = &schema.NotifierThrottlingConfiguration{
+		Interval:       "1 hour",
+		FailedAttempts: -1,
+	}
+
+	ValidateNotifier(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Require().Len(suite.validator.Errors(), 2)
+
+	suite.Assert().EqualError(suite.validator.Errors()[0], "Error occurred parsing notifier throttling interval string: could not convert the input string of 1 hour into a duration")
+	suite.Assert().EqualError(suite.validator.Errors()[1], "Failed attempts of the notifier throttling must be 0 or more")
+}
+
+func (suite *NotifierSuite) TestShouldRaiseErrorWhenFailedAttemptsDigestIsNotThrottled() {
+	suite.configuration.Throttling = &schema.NotifierThrottlingConfiguration{
+		Interval:       "0",
+		FailedAttempts: 5,
+	}
+
+	ValidateNotifier(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Require().Len(suite.validator.Errors(), 1)
+
+	suite.Assert().EqualError(suite.validator.Errors()[0], "Failed attempts of the notifier throttling require an interval greater than 0")
+}
+
+func (suite *NotifierSuite) TestShouldSetDefaultOperatorAlerts() {
+	suite.configuration.Operator = &schema.NotifierOperatorConfiguration{
+		Email: "ops@example.com",
+	}
+
+	ValidateNotifier(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Assert().False(suite.validator.HasErrors())
+
+	suite.Assert().Equal("2w", suite.configuration.Operator.CertificateExpiry)
+	suite.Assert().Equal("5m", suite.configuration.Operator.CheckInterval)
+}
+
+func (suite *NotifierSuite) TestShouldRaiseErrorWhenOperatorAlertsHaveNoTarget() {
+	suite.configuration.Operator = &schema.NotifierOperatorConfiguration{}
+
+	ValidateNotifier(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Require().Len(suite.validator.Errors(), 1)
+
+	suite.Assert().EqualError(suite.validator.Errors()[0], "Operator alerts of the notifier require an email or a webhook_url")
+}
+
+func (suite *NotifierSuite) TestShouldRaiseErrorsOnInvalidOperatorAlerts() {
+	suite.configuration.Operator = &schema.NotifierOperatorConfiguration{
+		Email:             "ops",
+		WebhookURL:        "hooks.example.com/alerts",
+		CertificateExpiry: "2 weeks",
+		CheckInterval:     "0",
+	}
+
+	ValidateNotifier(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Require().Len(suite.validator.Errors(), 4)
+
+	suite.Assert().EqualError(suite.validator.Errors()[0], "Email 'ops' of the operator alerts of the notifier is invalid: mail: missing '@' or angle-addr")
+	suite.Assert().EqualError(suite.validator.Errors()[1], "Webhook URL 'hooks.example.com/alerts' of the operator alerts of the notifier must be an absolute http or https URL")
+	suite.Assert().EqualError(suite.validator.Errors()[2], "Error occurred parsing notifier operator certificate expiry string: could not convert the input string of 2 weeks into a duration")
+	suite.Assert().EqualError(suite.validator.Errors()[3], "Check interval of the notifier operator alerts must be greater than 0")
+}
+
+func (suite *NotifierSuite) TestShouldSetDefaultSMTPNotifierTimeout() {
+	ValidateNotifier(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasErrors())
+	suite.Assert().Equal("20s", suite.configuration.SMTP.Timeout)
+}
+
+func (suite *NotifierSuite) TestShouldRaiseErrorsOnInvalidSMTPNotifierTimeout() {
+	suite.configuration.SMTP.Timeout = "0"
+
+	ValidateNotifier(&suite.configuration, suite.validator)
+
+	suite.Require().Len(suite.validator.Errors(), 1)
+	suite.Assert().EqualError(suite.validator.Errors()[0], "Timeout of SMTP notifier must be greater than 0")
+
+	suite.validator = schema.NewStructValidator()
+	suite.configuration.SMTP.Timeout = "abc"
+
+	ValidateNotifier(&suite.configuration, suite.validator)
+
+	suite.Require().Len(suite.validator.Errors(), 1)
+	suite.Assert().Contains(suite.validator.Errors()[0].Error(), "Error occurred parsing SMTP notifier timeout string: ")
+}
+
+func (suite *NotifierSuite) TestShouldValidateSMTPNotifierTLS() {
+	suite.configuration.SMTP.TLS = &schema.TLSConfig{
+		MinimumVersion:    "SSL2.0",
+		ClientCertificate: "/config/client.pem",
+	}
+
+	ValidateNotifier(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Require().Len(suite.validator.Errors(), 2)
+
+	suite.Assert().EqualError(suite.validator.Errors()[0], "error occurred validating the SMTP notifier tls minimum_version key with value SSL2.0: supplied TLS version isn't supported")
+	suite.Assert().EqualError(suite.validator.Errors()[1], "the SMTP notifier tls client_key must be provided along with the client_certificate")
+}
+
+func (suite *NotifierSuite) TestShouldValidateSMTPNotifierCipherSuitesAndCurves() {
+	suite.configuration.SMTP.TLS = &schema.TLSConfig{
+		MinimumVersion:   "TLS1.3",
+		CipherSuites:     []string{"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384", "TLS_RSA_WITH_RC4_128_SHA"},
+		CurvePreferences: []string{"X25519", "P224"},
+	}
+
+	ValidateNotifier(&suite.configuration, suite.validator)
+
+	suite.Require().Len(suite.validator.Warnings(), 1)
+	suite.Require().Len(suite.validator.Errors(), 2)
+
+	suite.Assert().EqualError(suite.validator.Warnings()[0], "the SMTP notifier tls cipher_suites have no effect when the minimum_version is TLS1.3")
+	suite.Assert().EqualError(suite.validator.Errors()[0], "error occurred validating the SMTP notifier tls cipher_suites key with value TLS_RSA_WITH_RC4_128_SHA: supplied TLS cipher suite isn't supported")
+	suite.Assert().EqualError(suite.validator.Errors()[1], "error occurred validating the SMTP notifier tls curve_preferences key with value P224: supplied TLS curve isn't supported")
+}
+
+func (suite *NotifierSuite) TestShouldValidateSMTPNotifierCertificateAuthorities() {
+	suite.configuration.SMTP.TLS = &schema.TLSConfig{
+		CertificateAuthorities: []string{"/path/not/exist/ca.pem", "notifier_test.go"},
+	}
+
+	ValidateNotifier(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Require().Len(suite.validator.Errors(), 2)
+
+	suite.Assert().EqualError(suite.validator.Errors()[0], "the SMTP notifier tls certificate authority /path/not/exist/ca.pem can't be read: open /path/not/exist/ca.pem: no such file or directory")
+	suite.Assert().EqualError(suite.validator.Errors()[1], "the SMTP notifier tls certificate authority notifier_test.go doesn't contain any PEM encoded certificate")
+}
+
+func (suite *NotifierSuite) TestShouldSetDefaultSMTPNotifierRevocation() {
+	suite.configuration.SMTP.TLS = &schema.TLSConfig{}
+
+	ValidateNotifier(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Assert().False(suite.validator.HasErrors())
+
+	suite.Assert().Equal(schema.OCSPModeDisable, suite.configuration.SMTP.TLS.Revocation.OCSP)
+}
+
+func (suite *NotifierSuite) TestShouldValidateSMTPNotifierRevocation() {
+	suite.configuration.SMTP.TLS = &schema.TLSConfig{
+		SkipVerify: true,
+		Revocation: schema.TLSRevocationConfig{
+			OCSP:    "strict",
+			CRLFile: "notifier_test.go",
+		},
+	}
+
+	ValidateNotifier(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Require().Len(suite.validator.Errors(), 3)
+
+	suite.Assert().EqualError(suite.validator.Errors()[0], "the SMTP notifier tls revocation ocsp must be one of disable, soft_fail or hard_fail but it is configured as 'strict'")
+	suite.Assert().Contains(suite.validator.Errors()[1].Error(), "the SMTP notifier tls revocation crl_file notifier_test.go can't be parsed: ")
+	suite.Assert().EqualError(suite.validator.Errors()[2], "the SMTP notifier tls revocation can't be checked when skip_verify is enabled")
+}
+
+func (suite *NotifierSuite) TestShouldValidateSMTPNotifierCategories() {
+	suite.configuration.SMTP.Categories = schema.SMTPNotifierCategoriesConfiguration{
+		Verification: &schema.SMTPNotifierCategoryConfiguration{
+			SenderName: "Authelia",
+			ReplyTo:    "Support <support@example.com>",
+		},
+		SecurityAlert: &schema.SMTPNotifierCategoryConfiguration{
+			ReplyTo: "security",
+		},
+	}
+
+	ValidateNotifier(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Require().Len(suite.validator.Errors(), 1)
+	suite.Assert().EqualError(suite.validator.Errors()[0], "Reply-to address 'security' of the security_alert notifications of the SMTP notifier is invalid: mail: missing '@' or angle-addr")
+}
+
 func TestNotifierSuite(t *testing.T) {
 	suite.Run(t, new(NotifierSuite))
 }
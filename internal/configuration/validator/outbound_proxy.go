@@ -0,0 +1,27 @@
+package validator
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// ValidateOutboundProxy validates the configuration of the proxy of the outbound HTTP requests.
+func ValidateOutboundProxy(configuration *schema.OutboundProxyConfiguration, validator *schema.StructValidator) {
+	if configuration.URL == "" {
+		validator.Push(fmt.Errorf("The outbound proxy url must be provided"))
+	} else if u, err := url.Parse(configuration.URL); err != nil {
+		validator.Push(fmt.Errorf("Error occurred parsing the outbound proxy url: %s", err))
+	} else if !utils.IsStringInSlice(u.Scheme, validOutboundProxySchemes) || u.Host == "" {
+		validator.Push(fmt.Errorf("The outbound proxy url must be an absolute URL with one of the schemes %s", strings.Join(validOutboundProxySchemes, ", ")))
+	}
+
+	for _, entry := range configuration.NoProxy {
+		if !utils.IsHostEntryValid(entry) {
+			validator.Push(fmt.Errorf("The outbound proxy no_proxy entry '%s' is invalid, it must be a domain, an IP address or a network in CIDR notation", entry))
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+func TestShouldValidateOutboundProxyConfiguration(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.OutboundProxyConfiguration{
+		URL:     "http://proxy.example.com:3128",
+		NoProxy: []string{"internal.example.com", "10.0.0.0/8"},
+	}
+
+	ValidateOutboundProxy(config, validator)
+
+	assert.False(t, validator.HasErrors())
+}
+
+func TestShouldRaiseErrorsOnInvalidOutboundProxyConfiguration(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.OutboundProxyConfiguration{
+		URL:     "ftp://proxy.example.com",
+		NoProxy: []string{"https://internal.example.com"},
+	}
+
+	ValidateOutboundProxy(config, validator)
+
+	require.Len(t, validator.Errors(), 2)
+	assert.EqualError(t, validator.Errors()[0], "The outbound proxy url must be an absolute URL with one of the schemes http, https, socks5")
+	assert.EqualError(t, validator.Errors()[1], "The outbound proxy no_proxy entry 'https://internal.example.com' is invalid, it must be a domain, an IP address or a network in CIDR notation")
+
+	validator = schema.NewStructValidator()
+	config = &schema.OutboundProxyConfiguration{}
+
+	ValidateOutboundProxy(config, validator)
+
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], "The outbound proxy url must be provided")
+}
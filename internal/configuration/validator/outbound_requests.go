@@ -0,0 +1,23 @@
+package validator
+
+import (
+	"fmt"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// ValidateOutboundRequests validates the policy of the outbound HTTP requests.
+func ValidateOutboundRequests(configuration *schema.OutboundRequestsConfiguration, validator *schema.StructValidator) {
+	for _, entry := range configuration.AllowedHosts {
+		if !utils.IsHostEntryValid(entry) {
+			validator.Push(fmt.Errorf("The outbound requests allowed host '%s' is invalid, it must be a domain, an IP address or a network in CIDR notation", entry))
+		}
+	}
+
+	for _, entry := range configuration.BlockedNetworks {
+		if _, err := utils.ParseNetwork(entry); err != nil {
+			validator.Push(fmt.Errorf("The outbound requests blocked network '%s' is invalid, it must be an IP address or a network in CIDR notation", entry))
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+func TestShouldValidateOutboundRequestsConfiguration(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.OutboundRequestsConfiguration{
+		AllowedHosts:    []string{"example.com", "10.0.0.0/8"},
+		BlockedNetworks: []string{"127.0.0.0/8", "::1"},
+	}
+
+	ValidateOutboundRequests(config, validator)
+
+	assert.False(t, validator.HasErrors())
+}
+
+func TestShouldRaiseErrorsOnInvalidOutboundRequestsConfiguration(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.OutboundRequestsConfiguration{
+		AllowedHosts:    []string{"https://example.com"},
+		BlockedNetworks: []string{"example.com"},
+	}
+
+	ValidateOutboundRequests(config, validator)
+
+	require.Len(t, validator.Errors(), 2)
+	assert.EqualError(t, validator.Errors()[0], "The outbound requests allowed host 'https://example.com' is invalid, it must be a domain, an IP address or a network in CIDR notation")
+	assert.EqualError(t, validator.Errors()[1], "The outbound requests blocked network 'example.com' is invalid, it must be an IP address or a network in CIDR notation")
+}
@@ -0,0 +1,54 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+func TestShouldValidatePasswordPolicy(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.PasswordPolicyConfiguration{
+		MinLength:        8,
+		MaxLength:        64,
+		RequireUppercase: true,
+		RequireNumber:    true,
+		MinScore:         3,
+	}
+
+	ValidatePasswordPolicy(config, validator)
+
+	assert.False(t, validator.HasErrors())
+}
+
+func TestShouldRaiseErrorsOnInvalidPasswordPolicy(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.PasswordPolicyConfiguration{
+		MinLength: 12,
+		MaxLength: 8,
+		MinScore:  5,
+	}
+
+	ValidatePasswordPolicy(config, validator)
+
+	require.Len(t, validator.Errors(), 2)
+	assert.EqualError(t, validator.Errors()[0], "The password policy max_length must be greater than or equal to min_length (12) but it is configured as 8")
+	assert.EqualError(t, validator.Errors()[1], "The password policy min_score must be between 0 and 4 but it is configured as 5")
+}
+
+func TestShouldRaiseErrorsOnNegativePasswordPolicyLengths(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.PasswordPolicyConfiguration{
+		MinLength: -1,
+		MaxLength: -1,
+	}
+
+	ValidatePasswordPolicy(config, validator)
+
+	require.Len(t, validator.Errors(), 2)
+	assert.EqualError(t, validator.Errors()[0], "The password policy min_length must be 0 or greater but it is configured as -1")
+	assert.EqualError(t, validator.Errors()[1], "The password policy max_length must be 0 or greater but it is configured as -1")
+}
@@ -0,0 +1,52 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// proxySecretMinimumLength is the length under which a proxy shared secret is considered weak.
+const proxySecretMinimumLength = 16
+
+// ValidateProxyAuthentication validates and updates the proxy authentication configuration.
+func ValidateProxyAuthentication(configuration *schema.Configuration, validator *schema.StructValidator) {
+	proxy := configuration.ProxyAuthentication
+
+	if proxy.Header == "" {
+		proxy.Header = schema.DefaultProxyAuthenticationConfiguration.Header
+	}
+
+	if !roleHeaderRegexp.MatchString(proxy.Header) {
+		validator.Push(fmt.Errorf("The proxy authentication header '%s' is invalid, it must only contain alphanumeric characters and hyphens", proxy.Header))
+	} else if utils.IsStringInSliceFold(proxy.Header, reservedForwardedHeaders) {
+		validator.Push(fmt.Errorf("The proxy authentication header '%s' is invalid, it must not be one of the headers forwarded by Authelia: %s", proxy.Header, strings.Join(reservedForwardedHeaders, ", ")))
+	}
+
+	if len(proxy.Rules) == 0 {
+		validator.Push(fmt.Errorf("The proxy authentication must have one or more rules"))
+	}
+
+	for i, rule := range proxy.Rules {
+		rulePosition := i + 1
+
+		for _, network := range rule.Networks {
+			if !IsNetworkValid(network) && !IsNetworkGroupValid(configuration.AccessControl, network) {
+				validator.Push(fmt.Errorf("Network %s for proxy authentication rule #%d is not a valid network or network group", network, rulePosition))
+			}
+		}
+
+		if len(rule.Secrets) == 0 {
+			validator.Push(fmt.Errorf("Proxy authentication rule #%d is invalid, it must have one or more secrets", rulePosition))
+		}
+
+		for _, secret := range rule.Secrets {
+			if len(secret) < proxySecretMinimumLength {
+				validator.Push(fmt.Errorf("Proxy authentication rule #%d is invalid, its secrets must be at least %d characters long", rulePosition, proxySecretMinimumLength))
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+func TestShouldSetDefaultProxyAuthenticationHeader(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.Configuration{
+		AccessControl: schema.AccessControlConfiguration{Networks: schema.DefaultACLNetwork},
+		ProxyAuthentication: &schema.ProxyAuthenticationConfiguration{
+			Rules: []schema.ProxyAuthenticationRule{
+				{Networks: []string{"internal", "192.168.0.0/16"}, Secrets: []string{"a_very_long_proxy_secret"}},
+			},
+		},
+	}
+
+	ValidateProxyAuthentication(config, validator)
+
+	assert.False(t, validator.HasErrors())
+	assert.Equal(t, "Authelia-Proxy-Secret", config.ProxyAuthentication.Header)
+}
+
+func TestShouldRaiseErrorsOnInvalidProxyAuthentication(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.Configuration{
+		ProxyAuthentication: &schema.ProxyAuthenticationConfiguration{
+			Header: "Remote-Groups",
+		},
+	}
+
+	ValidateProxyAuthentication(config, validator)
+
+	require.Len(t, validator.Errors(), 2)
+	assert.EqualError(t, validator.Errors()[0], "The proxy authentication header 'Remote-Groups' is invalid, it must not be one of the headers forwarded by Authelia: Remote-User, Remote-Name, Remote-Email, Remote-Groups")
+	assert.EqualError(t, validator.Errors()[1], "The proxy authentication must have one or more rules")
+
+	validator = schema.NewStructValidator()
+	config.ProxyAuthentication = &schema.ProxyAuthenticationConfiguration{
+		Header: "Proxy Secret",
+		Rules: []schema.ProxyAuthenticationRule{
+			{Networks: []string{"proxies"}},
+			{Secrets: []string{"a_very_long_proxy_secret", "short"}},
+		},
+	}
+
+	ValidateProxyAuthentication(config, validator)
+
+	require.Len(t, validator.Errors(), 4)
+	assert.EqualError(t, validator.Errors()[0], "The proxy authentication header 'Proxy Secret' is invalid, it must only contain alphanumeric characters and hyphens")
+	assert.EqualError(t, validator.Errors()[1], "Network proxies for proxy authentication rule #1 is not a valid network or network group")
+	assert.EqualError(t, validator.Errors()[2], "Proxy authentication rule #1 is invalid, it must have one or more secrets")
+	assert.EqualError(t, validator.Errors()[3], "Proxy authentication rule #2 is invalid, its secrets must be at least 16 characters long")
+}
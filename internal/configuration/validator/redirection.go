@@ -0,0 +1,91 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// ValidateRedirection validates and update the redirection configuration.
+func ValidateRedirection(configuration *schema.RedirectionConfiguration, validator *schema.StructValidator) {
+	if configuration.LoginURLTemplate == "" {
+		configuration.LoginURLTemplate = schema.DefaultRedirectionConfiguration.LoginURLTemplate
+	}
+
+	if configuration.StateLifespan == "" {
+		configuration.StateLifespan = schema.DefaultRedirectionConfiguration.StateLifespan
+	}
+
+	validateRedirectionLoginURLTemplate(configuration.LoginURLTemplate, validator)
+
+	if lifespan, err := utils.ParseDurationString(configuration.StateLifespan); err != nil {
+		validator.Push(fmt.Errorf("Error occurred parsing redirection state_lifespan string: %s", err))
+	} else if lifespan <= 0 {
+		validator.Push(fmt.Errorf("The redirection state_lifespan must be greater than 0"))
+	}
+
+	if configuration.SignedState && !strings.Contains(configuration.LoginURLTemplate, ".State") {
+		validator.Push(fmt.Errorf("The redirection login_url_template must pass the .State field to the login portal when signed_state is enabled"))
+	}
+
+	for _, domain := range configuration.AllowedDomains {
+		if domain == "" || strings.ContainsAny(domain, "/:?#@ ") {
+			validator.Push(fmt.Errorf("The redirection allowed_domains must only contain domain names but '%s' is not a domain name", domain))
+		}
+	}
+
+	for _, parameter := range configuration.StripParameters {
+		if parameter == "" {
+			validator.Push(fmt.Errorf("The redirection strip_parameters must not contain empty parameter names"))
+		}
+	}
+
+	for i, defaultURL := range configuration.DefaultURLs {
+		validateRedirectionDefaultURL(i+1, defaultURL, validator)
+	}
+}
+
+func validateRedirectionDefaultURL(position int, defaultURL schema.RedirectionDefaultURLConfiguration, validator *schema.StructValidator) {
+	if defaultURL.URL == "" {
+		validator.Push(fmt.Errorf("The redirection default URL #%d must have an url", position))
+	} else if err := utils.IsStringAbsURL(defaultURL.URL); err != nil {
+		validator.Push(fmt.Errorf("The redirection default URL #%d is invalid: %+v", position, err))
+	}
+
+	if len(defaultURL.Subjects) == 0 {
+		validator.Push(fmt.Errorf("The redirection default URL #%d must have a subject, the default_redirection_url applies to all the users", position))
+	}
+
+	for _, subjectRule := range defaultURL.Subjects {
+		for _, subject := range subjectRule {
+			if subject == "" || !IsSubjectValid(subject) {
+				validator.Push(fmt.Errorf("Subject %s for redirection default URL #%d is invalid, must start with 'user:' or 'group:'", subjectRule, position))
+			}
+		}
+	}
+}
+
+func validateRedirectionLoginURLTemplate(loginURLTemplate string, validator *schema.StructValidator) {
+	if _, err := utils.ParseRedirectionTemplate(loginURLTemplate); err != nil {
+		validator.Push(fmt.Errorf("Error occurred parsing redirection login_url_template: %s", err))
+		return
+	}
+
+	// Render the template with sample data to detect the references to unknown fields or functions misused.
+	_, err := utils.ExecuteRedirectionTemplate(loginURLTemplate, utils.RedirectionTemplateData{
+		PortalURL: "https://login.example.com",
+		TargetURL: "https://home.example.com/path?query=value",
+		Method:    "GET",
+		State:     "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.e30.signature",
+	})
+	if err != nil {
+		validator.Push(fmt.Errorf("Error occurred rendering redirection login_url_template: %s", err))
+		return
+	}
+
+	if !strings.Contains(loginURLTemplate, ".Target") {
+		validator.PushWarning(fmt.Errorf("The redirection login_url_template doesn't use the target URL so the users won't be redirected to the page they requested once authenticated"))
+	}
+}
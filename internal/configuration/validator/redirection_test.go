@@ -0,0 +1,122 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+func TestShouldSetDefaultRedirectionLoginURLTemplate(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.RedirectionConfiguration{}
+
+	ValidateRedirection(&config, validator)
+
+	assert.False(t, validator.HasErrors())
+	assert.False(t, validator.HasWarnings())
+	assert.Equal(t, schema.DefaultRedirectionConfiguration.LoginURLTemplate, config.LoginURLTemplate)
+}
+
+func TestShouldRaiseErrorOnInvalidRedirectionLoginURLTemplate(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.RedirectionConfiguration{
+		LoginURLTemplate: "{{ .PortalURL }}?rd={{ .TargetURL ",
+	}
+
+	ValidateRedirection(&config, validator)
+
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], "Error occurred parsing redirection login_url_template: template: redirection:1: unclosed action")
+
+	validator = schema.NewStructValidator()
+	config.LoginURLTemplate = "{{ .PortalURL }}?rd={{ .Target.Unknown }}"
+
+	ValidateRedirection(&config, validator)
+
+	require.Len(t, validator.Errors(), 1)
+	assert.Contains(t, validator.Errors()[0].Error(), "Error occurred rendering redirection login_url_template: ")
+}
+
+func TestShouldWarnWhenRedirectionLoginURLTemplateDoesntUseTarget(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.RedirectionConfiguration{
+		LoginURLTemplate: "{{ .PortalURL }}",
+	}
+
+	ValidateRedirection(&config, validator)
+
+	assert.False(t, validator.HasErrors())
+	require.Len(t, validator.Warnings(), 1)
+	assert.EqualError(t, validator.Warnings()[0], "The redirection login_url_template doesn't use the target URL so the users won't be redirected to the page they requested once authenticated")
+}
+
+func TestShouldRaiseErrorOnInvalidRedirectionPolicy(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.RedirectionConfiguration{
+		AllowedDomains:  []string{"example.com", "https://example.org", ""},
+		StripParameters: []string{"token", ""},
+	}
+
+	ValidateRedirection(&config, validator)
+
+	require.Len(t, validator.Errors(), 3)
+	assert.EqualError(t, validator.Errors()[0], "The redirection allowed_domains must only contain domain names but 'https://example.org' is not a domain name")
+	assert.EqualError(t, validator.Errors()[1], "The redirection allowed_domains must only contain domain names but '' is not a domain name")
+	assert.EqualError(t, validator.Errors()[2], "The redirection strip_parameters must not contain empty parameter names")
+}
+
+func TestShouldSetDefaultRedirectionStateLifespan(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.RedirectionConfiguration{SignedState: true}
+
+	ValidateRedirection(&config, validator)
+
+	assert.False(t, validator.HasErrors())
+	assert.Equal(t, "10m", config.StateLifespan)
+}
+
+func TestShouldRaiseErrorOnInvalidRedirectionStateLifespan(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.RedirectionConfiguration{StateLifespan: "ten minutes"}
+
+	ValidateRedirection(&config, validator)
+
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], "Error occurred parsing redirection state_lifespan string: could not convert the input string of ten minutes into a duration")
+}
+
+func TestShouldRaiseErrorWhenSignedStateIsNotPassedToThePortal(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.RedirectionConfiguration{
+		LoginURLTemplate: "{{ .PortalURL }}?rd={{ queryEscape .TargetURL }}",
+		SignedState:      true,
+	}
+
+	ValidateRedirection(&config, validator)
+
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], "The redirection login_url_template must pass the .State field to the login portal when signed_state is enabled")
+}
+
+func TestShouldRaiseErrorOnInvalidRedirectionDefaultURLs(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.RedirectionConfiguration{
+		DefaultURLs: []schema.RedirectionDefaultURLConfiguration{
+			{URL: "https://grafana.example.com", Subjects: [][]string{{"group:ops"}}},
+			{Subjects: [][]string{{"user:john"}}},
+			{URL: "grafana.example.com", Subjects: [][]string{{"ops"}}},
+			{URL: "https://home.example.com"},
+		},
+	}
+
+	ValidateRedirection(&config, validator)
+
+	require.Len(t, validator.Errors(), 4)
+	assert.EqualError(t, validator.Errors()[0], "The redirection default URL #2 must have an url")
+	assert.EqualError(t, validator.Errors()[1], "The redirection default URL #3 is invalid: the url 'grafana.example.com' is not absolute because it doesn't start with a scheme like 'http://' or 'https://'")
+	assert.EqualError(t, validator.Errors()[2], "Subject [ops] for redirection default URL #3 is invalid, must start with 'user:' or 'group:'")
+	assert.EqualError(t, validator.Errors()[3], "The redirection default URL #4 must have a subject, the default_redirection_url applies to all the users")
+}
@@ -30,4 +30,35 @@ func ValidateRegulation(configuration *schema.RegulationConfiguration, validator
 	if findTime > banTime {
 		validator.Push(fmt.Errorf("find_time cannot be greater than ban_time"))
 	}
+
+	if configuration.SecondFactor == nil {
+		secondFactor := schema.DefaultRegulationSecondFactorConfiguration
+		configuration.SecondFactor = &secondFactor
+	}
+
+	validateRegulationSecondFactor(configuration.SecondFactor, validator)
+}
+
+func validateRegulationSecondFactor(configuration *schema.RegulationSecondFactorConfiguration, validator *schema.StructValidator) {
+	if configuration.FindTime == "" {
+		configuration.FindTime = schema.DefaultRegulationSecondFactorConfiguration.FindTime // 5 min
+	}
+
+	if configuration.BanTime == "" {
+		configuration.BanTime = schema.DefaultRegulationSecondFactorConfiguration.BanTime // 15 min
+	}
+
+	findTime, err := utils.ParseDurationString(configuration.FindTime)
+	if err != nil {
+		validator.Push(fmt.Errorf("Error occurred parsing regulation second_factor find_time string: %s", err))
+	}
+
+	banTime, err := utils.ParseDurationString(configuration.BanTime)
+	if err != nil {
+		validator.Push(fmt.Errorf("Error occurred parsing regulation second_factor ban_time string: %s", err))
+	}
+
+	if findTime > banTime {
+		validator.Push(fmt.Errorf("second_factor find_time cannot be greater than second_factor ban_time"))
+	}
 }
@@ -57,3 +57,42 @@ func TestShouldRaiseErrorOnBadDurationStrings(t *testing.T) {
 	assert.EqualError(t, validator.Errors()[0], "Error occurred parsing regulation find_time string: could not convert the input string of a year into a duration")
 	assert.EqualError(t, validator.Errors()[1], "Error occurred parsing regulation ban_time string: could not convert the input string of forever into a duration")
 }
+
+func TestShouldSetDefaultRegulationSecondFactor(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := newDefaultRegulationConfig()
+
+	ValidateRegulation(&config, validator)
+
+	assert.Len(t, validator.Errors(), 0)
+	assert.Equal(t, schema.DefaultRegulationSecondFactorConfiguration, *config.SecondFactor)
+}
+
+func TestShouldSetDefaultRegulationSecondFactorDurations(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := newDefaultRegulationConfig()
+	config.SecondFactor = &schema.RegulationSecondFactorConfiguration{MaxRetries: 10}
+
+	ValidateRegulation(&config, validator)
+
+	assert.Len(t, validator.Errors(), 0)
+	assert.Equal(t, 10, config.SecondFactor.MaxRetries)
+	assert.Equal(t, schema.DefaultRegulationSecondFactorConfiguration.FindTime, config.SecondFactor.FindTime)
+	assert.Equal(t, schema.DefaultRegulationSecondFactorConfiguration.BanTime, config.SecondFactor.BanTime)
+}
+
+func TestShouldRaiseErrorOnBadSecondFactorRegulation(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := newDefaultRegulationConfig()
+	config.SecondFactor = &schema.RegulationSecondFactorConfiguration{
+		MaxRetries: 5,
+		FindTime:   "1h",
+		BanTime:    "forever",
+	}
+
+	ValidateRegulation(&config, validator)
+
+	assert.Len(t, validator.Errors(), 2)
+	assert.EqualError(t, validator.Errors()[0], "Error occurred parsing regulation second_factor ban_time string: could not convert the input string of forever into a duration")
+	assert.EqualError(t, validator.Errors()[1], "second_factor find_time cannot be greater than second_factor ban_time")
+}
@@ -0,0 +1,36 @@
+package validator
+
+import (
+	"fmt"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// ValidateResolver validates and updates the DNS resolver configuration.
+func ValidateResolver(configuration *schema.ResolverConfiguration, validator *schema.StructValidator) {
+	if len(configuration.Addresses) == 0 {
+		validator.Push(fmt.Errorf("The resolver requires at least one address"))
+	}
+
+	for i, address := range configuration.Addresses {
+		normalized, err := utils.NormalizeResolverAddress(address)
+		if err != nil {
+			validator.Push(fmt.Errorf("The resolver address '%s' is invalid: %w", address, err))
+			continue
+		}
+
+		configuration.Addresses[i] = normalized
+	}
+
+	if configuration.Timeout == "" {
+		configuration.Timeout = schema.DefaultResolverConfiguration.Timeout
+	}
+
+	timeout, err := utils.ParseDurationString(configuration.Timeout)
+	if err != nil {
+		validator.Push(fmt.Errorf("Error occurred parsing resolver timeout string: %s", err))
+	} else if timeout <= 0 {
+		validator.Push(fmt.Errorf("The resolver timeout must be greater than 0"))
+	}
+}
@@ -0,0 +1,46 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+func TestShouldSetDefaultResolverValues(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.ResolverConfiguration{Addresses: []string{"10.0.0.53", "[fec0::53]:5353"}}
+
+	ValidateResolver(config, validator)
+
+	assert.False(t, validator.HasErrors())
+	assert.Equal(t, []string{"10.0.0.53:53", "[fec0::53]:5353"}, config.Addresses)
+	assert.Equal(t, "5s", config.Timeout)
+	assert.False(t, config.Rotate)
+}
+
+func TestShouldRaiseErrorsOnInvalidResolverConfiguration(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := &schema.ResolverConfiguration{
+		Addresses: []string{"dns.example.com", "10.0.0.53:0"},
+		Timeout:   "abc",
+	}
+
+	ValidateResolver(config, validator)
+
+	require.Len(t, validator.Errors(), 3)
+	assert.EqualError(t, validator.Errors()[0], "The resolver address 'dns.example.com' is invalid: the host must be an IP address")
+	assert.EqualError(t, validator.Errors()[1], "The resolver address '10.0.0.53:0' is invalid: the port must be between 1 and 65535")
+	assert.EqualError(t, validator.Errors()[2], "Error occurred parsing resolver timeout string: could not convert the input string of abc into a duration")
+
+	validator = schema.NewStructValidator()
+	config = &schema.ResolverConfiguration{Timeout: "0"}
+
+	ValidateResolver(config, validator)
+
+	require.Len(t, validator.Errors(), 2)
+	assert.EqualError(t, validator.Errors()[0], "The resolver requires at least one address")
+	assert.EqualError(t, validator.Errors()[1], "The resolver timeout must be greater than 0")
+}
@@ -0,0 +1,68 @@
+package validator
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// ValidateRisk validates and updates the risk based authentication configuration.
+func ValidateRisk(configuration *schema.RiskConfiguration, validator *schema.StructValidator) {
+	if configuration.History == "" {
+		configuration.History = schema.DefaultRiskConfiguration.History
+	}
+
+	if configuration.ImpossibleTravelWindow == "" {
+		configuration.ImpossibleTravelWindow = schema.DefaultRiskConfiguration.ImpossibleTravelWindow
+	}
+
+	if configuration.StepUpScore == 0 {
+		configuration.StepUpScore = schema.DefaultRiskConfiguration.StepUpScore
+	}
+
+	if configuration.DenyScore == 0 {
+		configuration.DenyScore = schema.DefaultRiskConfiguration.DenyScore
+	}
+
+	if _, err := utils.ParseDurationString(configuration.History); err != nil {
+		validator.Push(fmt.Errorf("Error occurred parsing risk history string: %s", err))
+	}
+
+	if _, err := utils.ParseDurationString(configuration.ImpossibleTravelWindow); err != nil {
+		validator.Push(fmt.Errorf("Error occurred parsing risk impossible_travel_window string: %s", err))
+	}
+
+	if configuration.StepUpScore < 0 {
+		validator.Push(fmt.Errorf("Risk step_up_score must be greater than 0 but it is %d", configuration.StepUpScore))
+	}
+
+	if configuration.DenyScore < configuration.StepUpScore {
+		validator.Push(fmt.Errorf("Risk deny_score (%d) cannot be lower than step_up_score (%d)", configuration.DenyScore, configuration.StepUpScore))
+	}
+
+	if configuration.External != nil {
+		validateRiskExternal(configuration.External, validator)
+	}
+}
+
+func validateRiskExternal(configuration *schema.RiskExternalConfiguration, validator *schema.StructValidator) {
+	if configuration.Timeout == "" {
+		configuration.Timeout = schema.DefaultRiskExternalConfiguration.Timeout
+	}
+
+	if _, err := utils.ParseDurationString(configuration.Timeout); err != nil {
+		validator.Push(fmt.Errorf("Error occurred parsing risk external timeout string: %s", err))
+	}
+
+	if configuration.URL == "" {
+		validator.Push(fmt.Errorf("An external risk engine URL must be provided with the 'url' option"))
+		return
+	}
+
+	u, err := url.Parse(configuration.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		validator.Push(fmt.Errorf("The external risk engine URL '%s' must be an absolute http or https URL", configuration.URL))
+	}
+}
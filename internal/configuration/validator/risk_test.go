@@ -0,0 +1,63 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+func TestShouldSetDefaultRiskValues(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.RiskConfiguration{}
+
+	ValidateRisk(&config, validator)
+
+	require.Len(t, validator.Errors(), 0)
+	assert.Equal(t, schema.DefaultRiskConfiguration, config)
+}
+
+func TestShouldRaiseErrorsOnInvalidRiskValues(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.RiskConfiguration{
+		History:                "abc",
+		ImpossibleTravelWindow: "1z",
+		StepUpScore:            50,
+		DenyScore:              40,
+	}
+
+	ValidateRisk(&config, validator)
+
+	require.Len(t, validator.Errors(), 3)
+	assert.EqualError(t, validator.Errors()[0], "Error occurred parsing risk history string: could not convert the input string of abc into a duration")
+	assert.EqualError(t, validator.Errors()[1], "Error occurred parsing risk impossible_travel_window string: could not convert the input string of 1z into a duration")
+	assert.EqualError(t, validator.Errors()[2], "Risk deny_score (40) cannot be lower than step_up_score (50)")
+}
+
+func TestShouldValidateRiskExternal(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.RiskConfiguration{External: &schema.RiskExternalConfiguration{}}
+
+	ValidateRisk(&config, validator)
+
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], "An external risk engine URL must be provided with the 'url' option")
+	assert.Equal(t, "5s", config.External.Timeout)
+
+	validator = schema.NewStructValidator()
+	config.External.URL = "risk.example.com/score"
+
+	ValidateRisk(&config, validator)
+
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], "The external risk engine URL 'risk.example.com/score' must be an absolute http or https URL")
+
+	validator = schema.NewStructValidator()
+	config.External.URL = "https://risk.example.com/score"
+
+	ValidateRisk(&config, validator)
+
+	assert.Len(t, validator.Errors(), 0)
+}
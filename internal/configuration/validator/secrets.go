@@ -29,6 +29,7 @@ func isSecretKey(value string) (isSecretKey bool) {
 func ValidateSecrets(configuration *schema.Configuration, validator *schema.StructValidator, viper *viper.Viper) {
 	configuration.JWTSecret = getSecretValue(SecretNames["JWTSecret"], validator, viper)
 	configuration.Session.Secret = getSecretValue(SecretNames["SessionSecret"], validator, viper)
+	configuration.Session.PreviousSecret = getSecretValue(SecretNames["SessionPreviousSecret"], validator, viper)
 
 	if configuration.DuoAPI != nil {
 		configuration.DuoAPI.SecretKey = getSecretValue(SecretNames["DUOSecretKey"], validator, viper)
@@ -59,10 +60,40 @@ func ValidateSecrets(configuration *schema.Configuration, validator *schema.Stru
 		configuration.Storage.PostgreSQL.Password = getSecretValue(SecretNames["PostgreSQLPassword"], validator, viper)
 	}
 
+	configuration.Storage.EncryptionKey = getSecretValue(SecretNames["StorageEncryptionKey"], validator, viper)
+	configuration.Storage.PreviousEncryptionKey = getSecretValue(SecretNames["StoragePreviousEncryptionKey"], validator, viper)
+
+	if target := configuration.Storage.MigrationTarget; target != nil {
+		if target.MySQL != nil {
+			target.MySQL.Password = getSecretValue(SecretNames["MigrationMySQLPassword"], validator, viper)
+		}
+
+		if target.PostgreSQL != nil {
+			target.PostgreSQL.Password = getSecretValue(SecretNames["MigrationPostgreSQLPassword"], validator, viper)
+		}
+	}
+
 	if configuration.IdentityProviders.OIDC != nil {
 		configuration.IdentityProviders.OIDC.HMACSecret = getSecretValue(SecretNames["OpenIDConnectHMACSecret"], validator, viper)
+		configuration.IdentityProviders.OIDC.PreviousHMACSecret = getSecretValue(SecretNames["OpenIDConnectPreviousHMACSecret"], validator, viper)
 		configuration.IdentityProviders.OIDC.IssuerPrivateKey = getSecretValue(SecretNames["OpenIDConnectIssuerPrivateKey"], validator, viper)
 	}
+
+	if configuration.GRPC != nil {
+		configuration.GRPC.AdminToken = getSecretValue(SecretNames["GRPCAdminToken"], validator, viper)
+	}
+
+	if configuration.OutboundProxy != nil {
+		configuration.OutboundProxy.URL = getSecretValue(SecretNames["OutboundProxyURL"], validator, viper)
+	}
+
+	if configuration.Audit != nil && configuration.Audit.Webhook != nil {
+		configuration.Audit.Webhook.Secret = getSecretValue(SecretNames["AuditWebhookSecret"], validator, viper)
+	}
+
+	if configuration.Logging.Minimization != nil {
+		configuration.Logging.Minimization.HMACKey = getSecretValue(SecretNames["LogMinimizationHMACKey"], validator, viper)
+	}
 }
 
 func getSecretValue(name string, validator *schema.StructValidator, viper *viper.Viper) string {
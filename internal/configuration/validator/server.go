@@ -2,6 +2,7 @@ package validator
 
 import (
 	"fmt"
+	"net/url"
 	"path"
 	"strings"
 
@@ -24,6 +25,10 @@ func ValidateServer(configuration *schema.ServerConfiguration, validator *schema
 		configuration.Path = path.Clean("/" + configuration.Path)
 	}
 
+	if configuration.ExternalURL != "" {
+		validateServerExternalURL(configuration, validator)
+	}
+
 	if configuration.ReadBufferSize == 0 {
 		configuration.ReadBufferSize = defaultReadBufferSize
 	} else if configuration.ReadBufferSize < 0 {
@@ -35,4 +40,157 @@ func ValidateServer(configuration *schema.ServerConfiguration, validator *schema
 	} else if configuration.WriteBufferSize < 0 {
 		validator.Push(fmt.Errorf("server write buffer size must be above 0"))
 	}
+
+	if configuration.MaxConnectionsPerIP < 0 {
+		validator.Push(fmt.Errorf("server max connections per ip must be 0 or above"))
+	}
+
+	if configuration.MaxRequestBodySize == 0 {
+		configuration.MaxRequestBodySize = schema.DefaultServerConfiguration.MaxRequestBodySize
+	} else if configuration.MaxRequestBodySize < 0 {
+		validator.Push(fmt.Errorf("server max request body size must be above 0"))
+	}
+
+	if configuration.ReadTimeout == "" {
+		configuration.ReadTimeout = schema.DefaultServerConfiguration.ReadTimeout
+	}
+
+	if configuration.WriteTimeout == "" {
+		configuration.WriteTimeout = schema.DefaultServerConfiguration.WriteTimeout
+	}
+
+	if configuration.IdleTimeout == "" {
+		configuration.IdleTimeout = schema.DefaultServerConfiguration.IdleTimeout
+	}
+
+	validateServerTimeout("read_timeout", configuration.ReadTimeout, validator)
+	validateServerTimeout("write_timeout", configuration.WriteTimeout, validator)
+	validateServerTimeout("idle_timeout", configuration.IdleTimeout, validator)
+
+	if configuration.AddressFamily == "" {
+		configuration.AddressFamily = schema.DefaultServerConfiguration.AddressFamily
+	} else if !utils.IsStringInSlice(configuration.AddressFamily, validAddressFamilies) {
+		validator.Push(fmt.Errorf("server address family '%s' is invalid, it must be one of %s", configuration.AddressFamily, strings.Join(validAddressFamilies, ", ")))
+	}
+
+	if configuration.Compression != nil {
+		validateServerCompression(configuration.Compression, validator)
+	}
+
+	if configuration.RateLimit != nil {
+		validateServerRateLimit(configuration.RateLimit, validator)
+	}
+
+	if configuration.CORS != nil {
+		validateServerCORS(configuration.CORS, validator)
+	}
+
+	validateServerAdminRoles(configuration.AdminRoles, validator)
+}
+
+func validateServerExternalURL(configuration *schema.ServerConfiguration, validator *schema.StructValidator) {
+	externalURL, err := url.Parse(configuration.ExternalURL)
+
+	switch {
+	case err != nil:
+		validator.Push(fmt.Errorf("server external url '%s' is invalid: %s", configuration.ExternalURL, err))
+	case externalURL.Scheme != "https" && externalURL.Scheme != "http":
+		validator.Push(fmt.Errorf("server external url '%s' must have the scheme https or http", configuration.ExternalURL))
+	case externalURL.Host == "" || externalURL.RawQuery != "" || externalURL.Fragment != "":
+		validator.Push(fmt.Errorf("server external url '%s' must be an absolute url without query nor fragment", configuration.ExternalURL))
+	default:
+		configuration.ExternalURL = strings.TrimSuffix(externalURL.String(), "/")
+	}
+}
+
+func validateServerAdminRoles(configuration schema.ServerAdminRolesConfiguration, validator *schema.StructValidator) {
+	roles := map[string][]string{
+		"auditor":  configuration.Auditor,
+		"helpdesk": configuration.Helpdesk,
+		"admin":    configuration.Admin,
+	}
+
+	for _, role := range []string{"auditor", "helpdesk", "admin"} {
+		for _, group := range roles[role] {
+			if strings.TrimSpace(group) == "" {
+				validator.Push(fmt.Errorf("server admin role %s must not be granted to an empty group", role))
+			}
+		}
+	}
+}
+
+func validateServerTimeout(name, timeout string, validator *schema.StructValidator) {
+	if _, err := utils.ParseDurationString(timeout); err != nil {
+		validator.Push(fmt.Errorf("Error occurred parsing server %s string: %s", name, err))
+	}
+}
+
+func validateServerCompression(configuration *schema.ServerCompressionConfiguration, validator *schema.StructValidator) {
+	if len(configuration.Algorithms) == 0 {
+		configuration.Algorithms = schema.DefaultServerCompressionConfiguration.Algorithms
+	}
+
+	for _, algorithm := range configuration.Algorithms {
+		if !utils.IsStringInSlice(algorithm, validCompressionAlgorithms) {
+			validator.Push(fmt.Errorf("server compression algorithm '%s' is invalid, it must be one of %s", algorithm, strings.Join(validCompressionAlgorithms, ", ")))
+		}
+	}
+
+	if configuration.MinimumSize == 0 {
+		configuration.MinimumSize = schema.DefaultServerCompressionConfiguration.MinimumSize
+	} else if configuration.MinimumSize < 0 {
+		validator.Push(fmt.Errorf("server compression minimum size must be above 0"))
+	}
+
+	if len(configuration.ContentTypes) == 0 {
+		configuration.ContentTypes = schema.DefaultServerCompressionConfiguration.ContentTypes
+	}
+
+	for _, contentType := range configuration.ContentTypes {
+		if !strings.Contains(contentType, "/") || strings.ContainsAny(contentType, "; ") {
+			validator.Push(fmt.Errorf("server compression content type '%s' is invalid, it must be a media type without parameters such as application/json or text/*", contentType))
+		}
+	}
+}
+
+func validateServerRateLimit(configuration *schema.ServerRateLimitConfiguration, validator *schema.StructValidator) {
+	if configuration.Requests == 0 {
+		configuration.Requests = schema.DefaultServerRateLimitConfiguration.Requests
+	} else if configuration.Requests < 0 {
+		validator.Push(fmt.Errorf("server rate limit requests must be above 0"))
+	}
+
+	if configuration.Period == "" {
+		configuration.Period = schema.DefaultServerRateLimitConfiguration.Period
+	}
+
+	if period, err := utils.ParseDurationString(configuration.Period); err != nil {
+		validator.Push(fmt.Errorf("Error occurred parsing server rate limit period string: %s", err))
+	} else if period <= 0 {
+		validator.Push(fmt.Errorf("server rate limit period must be above 0"))
+	}
+}
+
+func validateServerCORS(configuration *schema.ServerCORSConfiguration, validator *schema.StructValidator) {
+	if len(configuration.AllowedOrigins) == 0 {
+		validator.Push(fmt.Errorf("server cors allowed origins must contain at least one origin"))
+	}
+
+	for i, origin := range configuration.AllowedOrigins {
+		originURL, err := url.Parse(origin)
+
+		if err != nil || (originURL.Scheme != "https" && originURL.Scheme != "http") || originURL.Host == "" ||
+			(originURL.Path != "" && originURL.Path != "/") || originURL.RawQuery != "" || originURL.Fragment != "" {
+			validator.Push(fmt.Errorf("server cors allowed origin '%s' is invalid, it must be a scheme and a host such as https://app.example.com", origin))
+			continue
+		}
+
+		configuration.AllowedOrigins[i] = originURL.Scheme + "://" + originURL.Host
+	}
+
+	if configuration.MaxAge == 0 {
+		configuration.MaxAge = schema.DefaultServerCORSConfiguration.MaxAge
+	} else if configuration.MaxAge < 0 {
+		validator.Push(fmt.Errorf("server cors max age must be above 0"))
+	}
 }
@@ -16,6 +16,13 @@ func TestShouldSetDefaultConfig(t *testing.T) {
 	require.Len(t, validator.Errors(), 0)
 	assert.Equal(t, defaultReadBufferSize, config.ReadBufferSize)
 	assert.Equal(t, defaultWriteBufferSize, config.WriteBufferSize)
+	assert.Equal(t, 0, config.MaxConnectionsPerIP)
+	assert.Equal(t, 4194304, config.MaxRequestBodySize)
+	assert.Equal(t, "6s", config.ReadTimeout)
+	assert.Equal(t, "0", config.WriteTimeout)
+	assert.Equal(t, "30s", config.IdleTimeout)
+	assert.False(t, config.DisableKeepAlive)
+	assert.Equal(t, "dual", config.AddressFamily)
 }
 
 func TestShouldParsePathCorrectly(t *testing.T) {
@@ -61,3 +68,169 @@ func TestShouldRaiseOnForwardSlashInPath(t *testing.T) {
 	assert.Len(t, validator.Errors(), 1)
 	assert.Error(t, validator.Errors()[0], "server path must not contain any forward slashes")
 }
+
+func TestShouldTrimTrailingSlashOfExternalURL(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.ServerConfiguration{
+		ExternalURL: "https://auth.example.com/authelia/",
+	}
+	ValidateServer(&config, validator)
+	require.Len(t, validator.Errors(), 0)
+	assert.Equal(t, "https://auth.example.com/authelia", config.ExternalURL)
+}
+
+func TestShouldRaiseOnInvalidExternalURL(t *testing.T) {
+	for externalURL, expected := range map[string]string{
+		"ftp://auth.example.com":        "server external url 'ftp://auth.example.com' must have the scheme https or http",
+		"auth.example.com":              "server external url 'auth.example.com' must have the scheme https or http",
+		"https://auth.example.com/?a=b": "server external url 'https://auth.example.com/?a=b' must be an absolute url without query nor fragment",
+		"https:///authelia":             "server external url 'https:///authelia' must be an absolute url without query nor fragment",
+		"https://auth.example.com/%zz":  "server external url 'https://auth.example.com/%zz' is invalid: parse \"https://auth.example.com/%zz\": invalid URL escape \"%zz\"",
+	} {
+		validator := schema.NewStructValidator()
+		config := schema.ServerConfiguration{
+			ExternalURL: externalURL,
+		}
+		ValidateServer(&config, validator)
+		require.Len(t, validator.Errors(), 1)
+		assert.EqualError(t, validator.Errors()[0], expected)
+	}
+}
+
+func TestShouldSetDefaultCompressionConfig(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.ServerConfiguration{
+		Compression: &schema.ServerCompressionConfiguration{},
+	}
+
+	ValidateServer(&config, validator)
+	require.Len(t, validator.Errors(), 0)
+
+	assert.Equal(t, []string{"br", "gzip"}, config.Compression.Algorithms)
+	assert.Equal(t, 1024, config.Compression.MinimumSize)
+	assert.Equal(t, schema.DefaultServerCompressionConfiguration.ContentTypes, config.Compression.ContentTypes)
+}
+
+func TestShouldRaiseOnInvalidCompressionConfig(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.ServerConfiguration{
+		Compression: &schema.ServerCompressionConfiguration{
+			Algorithms:   []string{"gzip", "deflate"},
+			MinimumSize:  -1,
+			ContentTypes: []string{"text/*", "json", "application/json; charset=utf-8"},
+		},
+	}
+
+	ValidateServer(&config, validator)
+	require.Len(t, validator.Errors(), 4)
+
+	assert.EqualError(t, validator.Errors()[0], "server compression algorithm 'deflate' is invalid, it must be one of br, gzip")
+	assert.EqualError(t, validator.Errors()[1], "server compression minimum size must be above 0")
+	assert.EqualError(t, validator.Errors()[2], "server compression content type 'json' is invalid, it must be a media type without parameters such as application/json or text/*")
+	assert.EqualError(t, validator.Errors()[3], "server compression content type 'application/json; charset=utf-8' is invalid, it must be a media type without parameters such as application/json or text/*")
+}
+
+func TestShouldSetDefaultRateLimitAndCORSConfig(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.ServerConfiguration{
+		RateLimit: &schema.ServerRateLimitConfiguration{},
+		CORS: &schema.ServerCORSConfiguration{
+			AllowedOrigins: []string{"https://app.example.com/"},
+		},
+	}
+
+	ValidateServer(&config, validator)
+	require.Len(t, validator.Errors(), 0)
+
+	assert.Equal(t, 100, config.RateLimit.Requests)
+	assert.Equal(t, "1m", config.RateLimit.Period)
+	assert.Equal(t, []string{"https://app.example.com"}, config.CORS.AllowedOrigins)
+	assert.Equal(t, 600, config.CORS.MaxAge)
+}
+
+func TestShouldRaiseOnInvalidRateLimitAndCORSConfig(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.ServerConfiguration{
+		RateLimit: &schema.ServerRateLimitConfiguration{
+			Requests: -1,
+			Period:   "abc",
+		},
+		CORS: &schema.ServerCORSConfiguration{
+			AllowedOrigins: []string{"*", "https://app.example.com/path"},
+			MaxAge:         -1,
+		},
+	}
+
+	ValidateServer(&config, validator)
+	require.Len(t, validator.Errors(), 5)
+
+	assert.EqualError(t, validator.Errors()[0], "server rate limit requests must be above 0")
+	assert.EqualError(t, validator.Errors()[1], "Error occurred parsing server rate limit period string: could not convert the input string of abc into a duration")
+	assert.EqualError(t, validator.Errors()[2], "server cors allowed origin '*' is invalid, it must be a scheme and a host such as https://app.example.com")
+	assert.EqualError(t, validator.Errors()[3], "server cors allowed origin 'https://app.example.com/path' is invalid, it must be a scheme and a host such as https://app.example.com")
+	assert.EqualError(t, validator.Errors()[4], "server cors max age must be above 0")
+}
+
+func TestShouldRaiseOnCORSConfigWithoutOrigins(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.ServerConfiguration{
+		CORS: &schema.ServerCORSConfiguration{},
+	}
+
+	ValidateServer(&config, validator)
+	require.Len(t, validator.Errors(), 1)
+
+	assert.EqualError(t, validator.Errors()[0], "server cors allowed origins must contain at least one origin")
+}
+
+func TestShouldRaiseOnInvalidConnectionOptions(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.ServerConfiguration{
+		MaxConnectionsPerIP: -1,
+		MaxRequestBodySize:  -1,
+		ReadTimeout:         "abc",
+		WriteTimeout:        "10s",
+		IdleTimeout:         "-1m",
+	}
+
+	ValidateServer(&config, validator)
+	require.Len(t, validator.Errors(), 4)
+
+	assert.EqualError(t, validator.Errors()[0], "server max connections per ip must be 0 or above")
+	assert.EqualError(t, validator.Errors()[1], "server max request body size must be above 0")
+	assert.EqualError(t, validator.Errors()[2], "Error occurred parsing server read_timeout string: could not convert the input string of abc into a duration")
+	assert.EqualError(t, validator.Errors()[3], "Error occurred parsing server idle_timeout string: could not convert the input string of -1m into a duration")
+}
+
+func TestShouldRaiseOnInvalidAddressFamily(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.ServerConfiguration{
+		AddressFamily: "ipv5",
+	}
+
+	ValidateServer(&config, validator)
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], "server address family 'ipv5' is invalid, it must be one of dual, ipv4, ipv6")
+
+	validator = schema.NewStructValidator()
+	config.AddressFamily = "ipv6"
+
+	ValidateServer(&config, validator)
+	assert.Len(t, validator.Errors(), 0)
+}
+
+func TestShouldRaiseOnEmptyAdminRoleGroup(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.ServerConfiguration{
+		AdminRoles: schema.ServerAdminRolesConfiguration{
+			Auditor:  []string{"security"},
+			Helpdesk: []string{"support", " "},
+			Admin:    []string{""},
+		},
+	}
+
+	ValidateServer(&config, validator)
+	require.Len(t, validator.Errors(), 2)
+	assert.EqualError(t, validator.Errors()[0], "server admin role helpdesk must not be granted to an empty group")
+	assert.EqualError(t, validator.Errors()[1], "server admin role admin must not be granted to an empty group")
+}
@@ -16,6 +16,13 @@ func ValidateSession(configuration *schema.SessionConfiguration, validator *sche
 	}
 
 	if configuration.Redis != nil {
+		validateRedisDatabaseIndex(configuration.Redis, validator)
+		validateRedisKeyPrefix(configuration.Redis, validator)
+
+		if configuration.Redis.TLS != nil {
+			validateTLSConfiguration(configuration.Redis.TLS, "redis", validator)
+		}
+
 		if configuration.Redis.HighAvailability != nil {
 			if configuration.Redis.HighAvailability.SentinelName != "" {
 				validateRedisSentinel(configuration, validator)
@@ -62,6 +69,48 @@ func validateSession(configuration *schema.SessionConfiguration, validator *sche
 	} else if configuration.SameSite != "none" && configuration.SameSite != "lax" && configuration.SameSite != "strict" {
 		validator.Push(errors.New("session same_site is configured incorrectly, must be one of 'none', 'lax', or 'strict'"))
 	}
+
+	if configuration.PreviousSecret != "" {
+		validateSessionPreviousSecret(configuration, validator)
+	}
+
+	validateSessionPinning(&configuration.Pinning, validator)
+	validateSessionImpossibleTravel(&configuration.ImpossibleTravel, validator)
+}
+
+// validateSessionPreviousSecret must be called once the remember me duration has been validated, the sessions encrypted
+// with the previous secret are accepted for the longest duration of a session by default.
+func validateSessionPreviousSecret(configuration *schema.SessionConfiguration, validator *schema.StructValidator) {
+	if configuration.PreviousSecret == configuration.Secret {
+		validator.Push(errors.New("The previous secret of the session must be different from its secret"))
+	}
+
+	if configuration.PreviousSecretLifespan == "" {
+		configuration.PreviousSecretLifespan = configuration.RememberMeDuration
+	} else if _, err := utils.ParseDurationString(configuration.PreviousSecretLifespan); err != nil {
+		validator.Push(fmt.Errorf("Error occurred parsing session previous_secret_lifespan string: %s", err))
+	}
+}
+
+func validateSessionPinning(configuration *schema.SessionPinningConfiguration, validator *schema.StructValidator) {
+	if configuration.TLSFingerprintHeader == "" {
+		configuration.TLSFingerprintHeader = schema.DefaultSessionPinningConfiguration.TLSFingerprintHeader
+	}
+
+	if configuration.Strictness == "" {
+		configuration.Strictness = schema.DefaultSessionPinningConfiguration.Strictness
+	} else if !utils.IsStringInSlice(configuration.Strictness, validSessionPinningStrictness) {
+		validator.Push(fmt.Errorf("session pinning strictness is configured incorrectly, must be one of '%s'",
+			strings.Join(validSessionPinningStrictness, "', '")))
+	}
+}
+
+func validateSessionImpossibleTravel(configuration *schema.SessionImpossibleTravelConfiguration, validator *schema.StructValidator) {
+	if configuration.Window == "" {
+		configuration.Window = schema.DefaultSessionImpossibleTravelConfiguration.Window
+	} else if _, err := utils.ParseDurationString(configuration.Window); err != nil {
+		validator.Push(fmt.Errorf("Error occurred parsing session impossible_travel window string: %s", err))
+	}
 }
 
 func validateRedis(configuration *schema.SessionConfiguration, validator *schema.StructValidator) {
@@ -73,9 +122,19 @@ func validateRedis(configuration *schema.SessionConfiguration, validator *schema
 		validator.Push(fmt.Errorf(errFmtSessionSecretRedisProvider, "redis"))
 	}
 
-	if !strings.HasPrefix(configuration.Redis.Host, "/") && configuration.Redis.Port == 0 {
+	switch {
+	case isUnixSocketPath(configuration.Redis.Host):
+		if configuration.Redis.Port != 0 {
+			validator.Push(fmt.Errorf(errFmtSessionRedisUnixSocketPort, "redis"))
+		}
+
+		// The server name can't be deduced from the address of a unix socket.
+		if tlsConfig := configuration.Redis.TLS; tlsConfig != nil && !tlsConfig.SkipVerify && tlsConfig.ServerName == "" {
+			validator.Push(fmt.Errorf(errFmtSessionRedisUnixSocketTLS, "redis"))
+		}
+	case configuration.Redis.Port == 0:
 		validator.Push(errors.New("A redis port different than 0 must be provided"))
-	} else if configuration.Redis.Port < 0 || configuration.Redis.Port > 65535 {
+	case configuration.Redis.Port < 0 || configuration.Redis.Port > 65535:
 		validator.Push(fmt.Errorf(errFmtSessionRedisPortRange, "redis"))
 	}
 
@@ -84,6 +143,23 @@ func validateRedis(configuration *schema.SessionConfiguration, validator *schema
 	}
 }
 
+func validateRedisDatabaseIndex(configuration *schema.RedisSessionConfiguration, validator *schema.StructValidator) {
+	switch {
+	case configuration.DatabaseIndex < 0:
+		validator.Push(fmt.Errorf("The redis database_index must be 0 or more but it is configured as %d", configuration.DatabaseIndex))
+	case configuration.DatabaseIndex > redisDefaultDatabases-1:
+		validator.PushWarning(fmt.Errorf("The redis database_index %d is above %d which requires redis to be configured with more than the default %d databases", configuration.DatabaseIndex, redisDefaultDatabases-1, redisDefaultDatabases))
+	}
+}
+
+func validateRedisKeyPrefix(configuration *schema.RedisSessionConfiguration, validator *schema.StructValidator) {
+	if configuration.KeyPrefix == "" {
+		configuration.KeyPrefix = schema.DefaultRedisSessionConfiguration.KeyPrefix
+	} else if !redisKeyPrefixRegexp.MatchString(configuration.KeyPrefix) {
+		validator.Push(fmt.Errorf("The redis key_prefix must only contain alphanumeric characters, colons, dots, underscores and hyphens but it is configured as '%s'", configuration.KeyPrefix))
+	}
+}
+
 func validateRedisSentinel(configuration *schema.SessionConfiguration, validator *schema.StructValidator) {
 	if configuration.Redis.Port == 0 {
 		configuration.Redis.Port = 26379
@@ -99,6 +175,10 @@ func validateHighAvailability(configuration *schema.SessionConfiguration, valida
 		validator.Push(fmt.Errorf(errFmtSessionRedisHostOrNodesRequired, provider))
 	}
 
+	if isUnixSocketPath(configuration.Redis.Host) {
+		validator.Push(fmt.Errorf(errFmtSessionRedisUnixSocketSentinel, provider))
+	}
+
 	if configuration.Secret == "" {
 		validator.Push(fmt.Errorf(errFmtSessionSecretRedisProvider, provider))
 	}
@@ -116,3 +196,8 @@ func validateHighAvailability(configuration *schema.SessionConfiguration, valida
 		}
 	}
 }
+
+// isUnixSocketPath returns true when the redis host is the path of a unix socket.
+func isUnixSocketPath(host string) bool {
+	return strings.HasPrefix(host, "/")
+}
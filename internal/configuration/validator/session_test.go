@@ -165,6 +165,106 @@ func TestShouldRaiseErrorWhenRedisHasHostnameButNoPort(t *testing.T) {
 	assert.EqualError(t, validator.Errors()[0], "A redis port different than 0 must be provided")
 }
 
+func TestShouldHandleRedisUnixSocketConfigSuccessfully(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := newDefaultSessionConfig()
+
+	config.Redis = &schema.RedisSessionConfiguration{
+		Host: "/var/run/redis/redis.sock",
+		TLS: &schema.TLSConfig{
+			ServerName: "redis.example.com",
+		},
+	}
+
+	ValidateSession(&config, validator)
+
+	assert.False(t, validator.HasWarnings())
+	assert.False(t, validator.HasErrors())
+}
+
+func TestShouldRaiseErrorsWhenRedisUnixSocketIncorrectlyConfigured(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := newDefaultSessionConfig()
+
+	config.Redis = &schema.RedisSessionConfiguration{
+		Host: "/var/run/redis/redis.sock",
+		Port: 6379,
+		TLS:  &schema.TLSConfig{},
+	}
+
+	ValidateSession(&config, validator)
+
+	assert.False(t, validator.HasWarnings())
+	require.Len(t, validator.Errors(), 2)
+	assert.EqualError(t, validator.Errors()[0], fmt.Sprintf(errFmtSessionRedisUnixSocketPort, "redis"))
+	assert.EqualError(t, validator.Errors()[1], fmt.Sprintf(errFmtSessionRedisUnixSocketTLS, "redis"))
+
+	validator.Clear()
+
+	config.Redis = &schema.RedisSessionConfiguration{
+		Host: "/var/run/redis/sentinel.sock",
+		HighAvailability: &schema.RedisHighAvailabilityConfiguration{
+			SentinelName: "authelia",
+		},
+	}
+
+	ValidateSession(&config, validator)
+
+	assert.False(t, validator.HasWarnings())
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], fmt.Sprintf(errFmtSessionRedisUnixSocketSentinel, "redis sentinel"))
+}
+
+func TestShouldValidateRedisDatabaseIndex(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := newDefaultSessionConfig()
+
+	config.Redis = &schema.RedisSessionConfiguration{
+		Host:          "redis.localhost",
+		Port:          6379,
+		Username:      "authelia",
+		DatabaseIndex: -1,
+	}
+
+	ValidateSession(&config, validator)
+
+	assert.False(t, validator.HasWarnings())
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], "The redis database_index must be 0 or more but it is configured as -1")
+
+	validator.Clear()
+
+	config.Redis.DatabaseIndex = 16
+
+	ValidateSession(&config, validator)
+
+	assert.False(t, validator.HasErrors())
+	require.Len(t, validator.Warnings(), 1)
+	assert.EqualError(t, validator.Warnings()[0], "The redis database_index 16 is above 15 which requires redis to be configured with more than the default 16 databases")
+}
+
+func TestShouldValidateRedisKeyPrefix(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := newDefaultSessionConfig()
+
+	config.Redis = &schema.RedisSessionConfiguration{
+		Host: "redis.localhost",
+		Port: 6379,
+	}
+
+	ValidateSession(&config, validator)
+
+	assert.False(t, validator.HasErrors())
+	assert.Equal(t, "authelia-session", config.Redis.KeyPrefix)
+
+	config.Redis.KeyPrefix = "authelia session"
+
+	ValidateSession(&config, validator)
+
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], "The redis key_prefix must only contain alphanumeric characters, colons, dots, underscores and hyphens but it is configured as 'authelia session'")
+}
+
 func TestShouldRaiseOneErrorWhenRedisHighAvailabilityHasNodesWithNoHost(t *testing.T) {
 	validator := schema.NewStructValidator()
 	config := newDefaultSessionConfig()
@@ -456,3 +556,65 @@ func TestShouldSetDefaultRememberMeDuration(t *testing.T) {
 	assert.False(t, validator.HasErrors())
 	assert.Equal(t, config.RememberMeDuration, schema.DefaultSessionConfiguration.RememberMeDuration)
 }
+
+func TestShouldValidateSessionPreviousSecret(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := newDefaultSessionConfig()
+	config.PreviousSecret = config.Secret
+	config.PreviousSecretLifespan = "1 day"
+
+	ValidateSession(&config, validator)
+
+	require.Len(t, validator.Errors(), 2)
+	assert.EqualError(t, validator.Errors()[0], "The previous secret of the session must be different from its secret")
+	assert.EqualError(t, validator.Errors()[1], "Error occurred parsing session previous_secret_lifespan string: could not convert the input string of 1 day into a duration")
+
+	validator = schema.NewStructValidator()
+	config = newDefaultSessionConfig()
+	config.PreviousSecret = "previous_secret"
+
+	ValidateSession(&config, validator)
+
+	assert.False(t, validator.HasErrors())
+
+	// The sessions encrypted with the previous secret are accepted for the longest duration of a session by default.
+	assert.Equal(t, schema.DefaultSessionConfiguration.RememberMeDuration, config.PreviousSecretLifespan)
+}
+
+func TestShouldValidateSessionPinning(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := newDefaultSessionConfig()
+
+	ValidateSession(&config, validator)
+
+	assert.False(t, validator.HasErrors())
+	assert.Equal(t, schema.DefaultSessionPinningConfiguration, config.Pinning)
+
+	validator = schema.NewStructValidator()
+	config = newDefaultSessionConfig()
+	config.Pinning.Strictness = "lenient"
+
+	ValidateSession(&config, validator)
+
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], "session pinning strictness is configured incorrectly, must be one of 'strict', 'log'")
+}
+
+func TestShouldValidateSessionImpossibleTravel(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := newDefaultSessionConfig()
+
+	ValidateSession(&config, validator)
+
+	assert.False(t, validator.HasErrors())
+	assert.Equal(t, schema.DefaultSessionImpossibleTravelConfiguration, config.ImpossibleTravel)
+
+	validator = schema.NewStructValidator()
+	config = newDefaultSessionConfig()
+	config.ImpossibleTravel.Window = "abc"
+
+	ValidateSession(&config, validator)
+
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], "Error occurred parsing session impossible_travel window string: could not convert the input string of abc into a duration")
+}
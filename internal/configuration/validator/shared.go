@@ -0,0 +1,90 @@
+package validator
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// validateTLSConfiguration validates the TLS configuration of the connections to the service with the given name.
+func validateTLSConfiguration(configuration *schema.TLSConfig, name string, validator *schema.StructValidator) {
+	if configuration.MinimumVersion != "" {
+		if _, err := utils.TLSStringToTLSConfigVersion(configuration.MinimumVersion); err != nil {
+			validator.Push(fmt.Errorf("error occurred validating the %s tls minimum_version key with value %s: %v", name, configuration.MinimumVersion, err))
+		}
+	}
+
+	validateTLSParameters(configuration, name, validator)
+	validateTLSFiles(configuration, name, validator)
+}
+
+// validateTLSParameters validates the cipher suites and curves of the connections are supported.
+func validateTLSParameters(configuration *schema.TLSConfig, name string, validator *schema.StructValidator) {
+	for _, cipherSuite := range configuration.CipherSuites {
+		if _, err := utils.TLSStringToCipherSuite(cipherSuite); err != nil {
+			validator.Push(fmt.Errorf("error occurred validating the %s tls cipher_suites key with value %s: %v", name, cipherSuite, err))
+		}
+	}
+
+	for _, curve := range configuration.CurvePreferences {
+		if _, err := utils.TLSStringToCurveID(curve); err != nil {
+			validator.Push(fmt.Errorf("error occurred validating the %s tls curve_preferences key with value %s: %v", name, curve, err))
+		}
+	}
+
+	if len(configuration.CipherSuites) != 0 {
+		if version, err := utils.TLSStringToTLSConfigVersion(configuration.MinimumVersion); err == nil && version == tls.VersionTLS13 {
+			validator.PushWarning(fmt.Errorf("the %s tls cipher_suites have no effect when the minimum_version is TLS1.3", name))
+		}
+	}
+}
+
+// validateTLSFiles validates the client certificate and key are provided together, the certificate authorities can
+// be loaded and the revocation checks are valid.
+func validateTLSFiles(configuration *schema.TLSConfig, name string, validator *schema.StructValidator) {
+	switch {
+	case configuration.ClientCertificate != "" && configuration.ClientKey == "":
+		validator.Push(fmt.Errorf("the %s tls client_key must be provided along with the client_certificate", name))
+	case configuration.ClientCertificate == "" && configuration.ClientKey != "":
+		validator.Push(fmt.Errorf("the %s tls client_certificate must be provided along with the client_key", name))
+	}
+
+	for _, path := range configuration.CertificateAuthorities {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			validator.Push(fmt.Errorf("the %s tls certificate authority %s can't be read: %v", name, path, err))
+		} else if !x509.NewCertPool().AppendCertsFromPEM(content) {
+			validator.Push(fmt.Errorf("the %s tls certificate authority %s doesn't contain any PEM encoded certificate", name, path))
+		}
+	}
+
+	validateTLSRevocation(configuration, name, validator)
+}
+
+func validateTLSRevocation(configuration *schema.TLSConfig, name string, validator *schema.StructValidator) {
+	switch configuration.Revocation.OCSP {
+	case "":
+		configuration.Revocation.OCSP = schema.OCSPModeDisable
+	case schema.OCSPModeDisable, schema.OCSPModeSoftFail, schema.OCSPModeHardFail:
+		break
+	default:
+		validator.Push(fmt.Errorf("the %s tls revocation ocsp must be one of %s, %s or %s but it is configured as '%s'", name, schema.OCSPModeDisable, schema.OCSPModeSoftFail, schema.OCSPModeHardFail, configuration.Revocation.OCSP))
+	}
+
+	if configuration.Revocation.CRLFile != "" {
+		content, err := ioutil.ReadFile(configuration.Revocation.CRLFile)
+		if err != nil {
+			validator.Push(fmt.Errorf("the %s tls revocation crl_file %s can't be read: %v", name, configuration.Revocation.CRLFile, err))
+		} else if _, err = x509.ParseCRL(content); err != nil {
+			validator.Push(fmt.Errorf("the %s tls revocation crl_file %s can't be parsed: %v", name, configuration.Revocation.CRLFile, err))
+		}
+	}
+
+	if configuration.SkipVerify && (configuration.Revocation.CRLFile != "" || configuration.Revocation.OCSP != schema.OCSPModeDisable) {
+		validator.Push(fmt.Errorf("the %s tls revocation can't be checked when skip_verify is enabled", name))
+	}
+}
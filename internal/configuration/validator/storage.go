@@ -2,12 +2,48 @@ package validator
 
 import (
 	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
 
 	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/utils"
 )
 
-// ValidateStorage validates storage configuration.
-func ValidateStorage(configuration schema.StorageConfiguration, validator *schema.StructValidator) {
+// ValidateStorage validates and update storage configuration.
+func ValidateStorage(configuration *schema.StorageConfiguration, validator *schema.StructValidator) {
+	validateStorageRetentionConfiguration(&configuration.Retention, validator)
+	validateStorageCacheConfiguration(&configuration.Cache, validator)
+
+	if configuration.Provider == "" && len(configuration.Options) != 0 {
+		validator.Push(errors.New("The storage 'options' can only be used with a custom storage 'provider'"))
+	}
+
+	if configuration.Provider != "" {
+		if configuration.Local != nil || configuration.MySQL != nil || configuration.PostgreSQL != nil {
+			validator.Push(errors.New("A custom storage 'provider' cannot be used alongside 'local', 'mysql' or 'postgres'"))
+		}
+
+		if configuration.MigrationTarget != nil {
+			validator.Push(errors.New("A custom storage 'provider' cannot be migrated with 'migration_target'"))
+		}
+
+		if configuration.EncryptionKey != "" || configuration.PreviousEncryptionKey != "" {
+			validator.Push(errors.New("A custom storage 'provider' cannot encrypt the secrets with 'encryption_key'"))
+		}
+
+		return
+	}
+
+	if configuration.EncryptionKey != "" && len(configuration.EncryptionKey) < storageEncryptionKeyMinimumLength {
+		validator.Push(fmt.Errorf("the storage encryption_key must be at least %d characters long", storageEncryptionKeyMinimumLength))
+	}
+
+	if configuration.PreviousEncryptionKey != "" {
+		validateStoragePreviousEncryptionKey(configuration, validator)
+	}
+
 	if configuration.Local == nil && configuration.MySQL == nil && configuration.PostgreSQL == nil {
 		validator.Push(errors.New("A storage configuration must be provided. It could be 'local', 'mysql' or 'postgres'"))
 	}
@@ -20,6 +56,29 @@ func ValidateStorage(configuration schema.StorageConfiguration, validator *schem
 	case configuration.Local != nil:
 		validateLocalStorageConfiguration(configuration.Local, validator)
 	}
+
+	if configuration.MigrationTarget != nil {
+		validateStorageMigrationTarget(configuration, validator)
+	}
+}
+
+func validateStorageMigrationTarget(configuration *schema.StorageConfiguration, validator *schema.StructValidator) {
+	target := configuration.MigrationTarget
+
+	switch {
+	case target.Local == nil && target.MySQL == nil && target.PostgreSQL == nil:
+		validator.Push(errors.New("A storage migration target must be provided. It could be 'local', 'mysql' or 'postgres'"))
+	case target.MySQL != nil:
+		validateSQLConfiguration(&target.MySQL.SQLStorageConfiguration, validator)
+	case target.PostgreSQL != nil:
+		validatePostgreSQLConfiguration(target.PostgreSQL, validator)
+	case target.Local != nil:
+		validateLocalStorageConfiguration(target.Local, validator)
+
+		if configuration.Local != nil && configuration.Local.Path == target.Local.Path {
+			validator.Push(errors.New("The storage migration target must not be the current storage"))
+		}
+	}
 }
 
 func validateSQLConfiguration(configuration *schema.SQLStorageConfiguration, validator *schema.StructValidator) {
@@ -30,6 +89,63 @@ func validateSQLConfiguration(configuration *schema.SQLStorageConfiguration, val
 	if configuration.Database == "" {
 		validator.Push(errors.New("the SQL database must be provided"))
 	}
+
+	for _, replica := range configuration.ReadReplicas {
+		if !isReadReplicaAddressValid(replica) {
+			validator.Push(fmt.Errorf("the SQL read replica '%s' must be a host optionally followed by a port", replica))
+		}
+	}
+
+	validateSQLPoolConfiguration(&configuration.Pool, validator)
+}
+
+func validateSQLPoolConfiguration(configuration *schema.SQLStoragePoolConfiguration, validator *schema.StructValidator) {
+	if configuration.MaxIdleConnections == 0 {
+		configuration.MaxIdleConnections = schema.DefaultSQLStoragePoolConfiguration.MaxIdleConnections
+	}
+
+	if configuration.ConnectionMaxLifetime == "" {
+		configuration.ConnectionMaxLifetime = schema.DefaultSQLStoragePoolConfiguration.ConnectionMaxLifetime
+	}
+
+	if configuration.ConnectionMaxIdleTime == "" {
+		configuration.ConnectionMaxIdleTime = schema.DefaultSQLStoragePoolConfiguration.ConnectionMaxIdleTime
+	}
+
+	if configuration.MaxOpenConnections < 0 {
+		validator.Push(fmt.Errorf("the SQL pool max_open_connections must be 0 for no limit or greater but it is configured as %d", configuration.MaxOpenConnections))
+	}
+
+	if configuration.MaxIdleConnections < 0 {
+		validator.Push(fmt.Errorf("the SQL pool max_idle_connections must be greater than 0 but it is configured as %d", configuration.MaxIdleConnections))
+	} else if configuration.MaxOpenConnections > 0 && configuration.MaxIdleConnections > configuration.MaxOpenConnections {
+		validator.Push(fmt.Errorf("the SQL pool max_idle_connections %d must not be greater than max_open_connections %d",
+			configuration.MaxIdleConnections, configuration.MaxOpenConnections))
+	}
+
+	if _, err := utils.ParseDurationString(configuration.ConnectionMaxLifetime); err != nil {
+		validator.Push(fmt.Errorf("Error occurred parsing SQL pool connection_max_lifetime string: %s", err))
+	}
+
+	if _, err := utils.ParseDurationString(configuration.ConnectionMaxIdleTime); err != nil {
+		validator.Push(fmt.Errorf("Error occurred parsing SQL pool connection_max_idle_time string: %s", err))
+	}
+}
+
+func isReadReplicaAddressValid(address string) bool {
+	if address == "" {
+		return false
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		// The address doesn't have a port.
+		return !strings.Contains(address, ":") || net.ParseIP(address) != nil
+	}
+
+	number, err := strconv.Atoi(port)
+
+	return err == nil && host != "" && number > 0 && number < 65536
 }
 
 func validatePostgreSQLConfiguration(configuration *schema.PostgreSQLStorageConfiguration, validator *schema.StructValidator) {
@@ -43,6 +159,16 @@ func validatePostgreSQLConfiguration(configuration *schema.PostgreSQLStorageConf
 		configuration.SSLMode == "verify-ca" || configuration.SSLMode == "verify-full") {
 		validator.Push(errors.New("SSL mode must be 'disable', 'require', 'verify-ca', or 'verify-full'"))
 	}
+
+	if (configuration.SSLCertificate == "") != (configuration.SSLKey == "") {
+		validator.Push(errors.New("the PostgreSQL ssl_certificate and ssl_key must be provided together"))
+	}
+
+	for _, host := range configuration.FallbackHosts {
+		if !isReadReplicaAddressValid(host) {
+			validator.Push(fmt.Errorf("the PostgreSQL fallback host '%s' must be a host optionally followed by a port", host))
+		}
+	}
 }
 
 func validateLocalStorageConfiguration(configuration *schema.LocalStorageConfiguration, validator *schema.StructValidator) {
@@ -50,3 +176,48 @@ func validateLocalStorageConfiguration(configuration *schema.LocalStorageConfigu
 		validator.Push(errors.New("A file path must be provided with key 'path'"))
 	}
 }
+
+func validateStorageRetentionConfiguration(configuration *schema.StorageRetentionConfiguration, validator *schema.StructValidator) {
+	if configuration.Interval == "" {
+		configuration.Interval = schema.DefaultStorageRetentionConfiguration.Interval // 1 hour
+	}
+
+	if configuration.AuthenticationLogs == "" {
+		configuration.AuthenticationLogs = schema.DefaultStorageRetentionConfiguration.AuthenticationLogs // Forever
+	}
+
+	if _, err := utils.ParseDurationString(configuration.Interval); err != nil {
+		validator.Push(fmt.Errorf("Error occurred parsing storage retention interval string: %s", err))
+	}
+
+	authenticationLogs, err := utils.ParseDurationString(configuration.AuthenticationLogs)
+	if err != nil {
+		validator.Push(fmt.Errorf("Error occurred parsing storage retention authentication_logs string: %s", err))
+		return
+	}
+
+	// The regulation and the security events of the users rely on the recent authentication logs.
+	if authenticationLogs != 0 && authenticationLogs < utils.Day {
+		validator.Push(errors.New("storage retention authentication_logs must be at least 1 day or 0 to keep them forever"))
+	}
+}
+
+func validateStorageCacheConfiguration(configuration *schema.StorageCacheConfiguration, validator *schema.StructValidator) {
+	switch {
+	case configuration.Size == 0:
+		configuration.Size = schema.DefaultStorageCacheConfiguration.Size
+	case configuration.Size < 0:
+		validator.Push(fmt.Errorf("storage cache size must be greater than 0 but it is configured as %d", configuration.Size))
+	}
+}
+
+// validateStoragePreviousEncryptionKey validates the key the secrets are rotated from, they're rotated to the encryption
+// key so it must be configured too.
+func validateStoragePreviousEncryptionKey(configuration *schema.StorageConfiguration, validator *schema.StructValidator) {
+	switch configuration.EncryptionKey {
+	case "":
+		validator.Push(errors.New("the storage previous_encryption_key requires the encryption_key the secrets are rotated to, use the decrypt command to store them in plaintext"))
+	case configuration.PreviousEncryptionKey:
+		validator.Push(errors.New("the storage previous_encryption_key must be different from the encryption_key"))
+	}
+}
@@ -19,22 +19,59 @@ func (suite *StorageSuite) SetupTest() {
 	suite.configuration.Local = &schema.LocalStorageConfiguration{
 		Path: "/this/is/a/path",
 	}
+	suite.configuration.Retention = schema.StorageRetentionConfiguration{}
+	suite.configuration.Cache = schema.StorageCacheConfiguration{}
 }
 
 func (suite *StorageSuite) TestShouldValidateOneStorageIsConfigured() {
 	suite.configuration.Local = nil
 
-	ValidateStorage(suite.configuration, suite.validator)
+	ValidateStorage(&suite.configuration, suite.validator)
 
 	suite.Assert().False(suite.validator.HasWarnings())
 	suite.Require().Len(suite.validator.Errors(), 1)
 	suite.Assert().EqualError(suite.validator.Errors()[0], "A storage configuration must be provided. It could be 'local', 'mysql' or 'postgres'")
 }
 
+func (suite *StorageSuite) TestShouldValidateCustomProviderIsExclusive() {
+	suite.configuration.Provider = "custom"
+
+	defer func() {
+		suite.configuration.Provider = ""
+	}()
+
+	ValidateStorage(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Require().Len(suite.validator.Errors(), 1)
+	suite.Assert().EqualError(suite.validator.Errors()[0], "A custom storage 'provider' cannot be used alongside 'local', 'mysql' or 'postgres'")
+
+	suite.validator.Clear()
+	suite.configuration.Local = nil
+
+	ValidateStorage(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasErrors())
+}
+
+func (suite *StorageSuite) TestShouldValidateOptionsRequireCustomProvider() {
+	suite.configuration.Options = map[string]interface{}{"bucket": "authelia"}
+
+	defer func() {
+		suite.configuration.Options = nil
+	}()
+
+	ValidateStorage(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Require().Len(suite.validator.Errors(), 1)
+	suite.Assert().EqualError(suite.validator.Errors()[0], "The storage 'options' can only be used with a custom storage 'provider'")
+}
+
 func (suite *StorageSuite) TestShouldValidateLocalPathIsProvided() {
 	suite.configuration.Local.Path = ""
 
-	ValidateStorage(suite.configuration, suite.validator)
+	ValidateStorage(&suite.configuration, suite.validator)
 
 	suite.Assert().False(suite.validator.HasWarnings())
 	suite.Require().Len(suite.validator.Errors(), 1)
@@ -44,7 +81,7 @@ func (suite *StorageSuite) TestShouldValidateLocalPathIsProvided() {
 	suite.validator.Clear()
 	suite.configuration.Local.Path = "/myapth"
 
-	ValidateStorage(suite.configuration, suite.validator)
+	ValidateStorage(&suite.configuration, suite.validator)
 
 	suite.Assert().False(suite.validator.HasWarnings())
 	suite.Assert().False(suite.validator.HasErrors())
@@ -52,7 +89,7 @@ func (suite *StorageSuite) TestShouldValidateLocalPathIsProvided() {
 
 func (suite *StorageSuite) TestShouldValidateSQLUsernamePasswordAndDatabaseAreProvided() {
 	suite.configuration.MySQL = &schema.MySQLStorageConfiguration{}
-	ValidateStorage(suite.configuration, suite.validator)
+	ValidateStorage(&suite.configuration, suite.validator)
 
 	suite.Require().Len(suite.validator.Errors(), 2)
 	suite.Assert().EqualError(suite.validator.Errors()[0], "the SQL username and password must be provided")
@@ -66,7 +103,7 @@ func (suite *StorageSuite) TestShouldValidateSQLUsernamePasswordAndDatabaseArePr
 			Database: "database",
 		},
 	}
-	ValidateStorage(suite.configuration, suite.validator)
+	ValidateStorage(&suite.configuration, suite.validator)
 
 	suite.Assert().False(suite.validator.HasWarnings())
 	suite.Assert().False(suite.validator.HasErrors())
@@ -81,7 +118,7 @@ func (suite *StorageSuite) TestShouldValidatePostgresSSLModeIsDisableByDefault()
 		},
 	}
 
-	ValidateStorage(suite.configuration, suite.validator)
+	ValidateStorage(&suite.configuration, suite.validator)
 
 	suite.Assert().False(suite.validator.HasWarnings())
 	suite.Assert().False(suite.validator.HasErrors())
@@ -99,13 +136,266 @@ func (suite *StorageSuite) TestShouldValidatePostgresSSLModeMustBeValid() {
 		SSLMode: "unknown",
 	}
 
-	ValidateStorage(suite.configuration, suite.validator)
+	ValidateStorage(&suite.configuration, suite.validator)
 
 	suite.Assert().False(suite.validator.HasWarnings())
 	suite.Require().Len(suite.validator.Errors(), 1)
 	suite.Assert().EqualError(suite.validator.Errors()[0], "SSL mode must be 'disable', 'require', 'verify-ca', or 'verify-full'")
 }
 
+func (suite *StorageSuite) TestShouldValidatePostgresSSLCertificateAndFallbackHosts() {
+	defer func() {
+		suite.configuration.PostgreSQL = nil
+	}()
+
+	suite.configuration.PostgreSQL = &schema.PostgreSQLStorageConfiguration{
+		SQLStorageConfiguration: schema.SQLStorageConfiguration{
+			Username: "myuser",
+			Password: "pass",
+			Database: "database",
+		},
+		SSLCertificate: "/certs/authelia.pem",
+		FallbackHosts:  []string{"postgres2:5433", "postgres3:0"},
+	}
+
+	ValidateStorage(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Require().Len(suite.validator.Errors(), 2)
+	suite.Assert().EqualError(suite.validator.Errors()[0], "the PostgreSQL ssl_certificate and ssl_key must be provided together")
+	suite.Assert().EqualError(suite.validator.Errors()[1], "the PostgreSQL fallback host 'postgres3:0' must be a host optionally followed by a port")
+}
+
+func (suite *StorageSuite) TestShouldSetDefaultSQLPool() {
+	defer func() {
+		suite.configuration.MySQL = nil
+	}()
+
+	suite.configuration.MySQL = &schema.MySQLStorageConfiguration{
+		SQLStorageConfiguration: schema.SQLStorageConfiguration{
+			Username: "myuser",
+			Password: "pass",
+			Database: "database",
+		},
+	}
+
+	ValidateStorage(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Assert().False(suite.validator.HasErrors())
+
+	suite.Assert().Equal(schema.DefaultSQLStoragePoolConfiguration, suite.configuration.MySQL.Pool)
+}
+
+func (suite *StorageSuite) TestShouldRaiseErrorOnInvalidSQLPool() {
+	defer func() {
+		suite.configuration.MySQL = nil
+	}()
+
+	suite.configuration.MySQL = &schema.MySQLStorageConfiguration{
+		SQLStorageConfiguration: schema.SQLStorageConfiguration{
+			Username: "myuser",
+			Password: "pass",
+			Database: "database",
+			Pool: schema.SQLStoragePoolConfiguration{
+				MaxOpenConnections:    5,
+				MaxIdleConnections:    10,
+				ConnectionMaxLifetime: "1 hour",
+				ConnectionMaxIdleTime: "5m",
+			},
+		},
+	}
+
+	ValidateStorage(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Require().Len(suite.validator.Errors(), 2)
+	suite.Assert().EqualError(suite.validator.Errors()[0], "the SQL pool max_idle_connections 10 must not be greater than max_open_connections 5")
+	suite.Assert().EqualError(suite.validator.Errors()[1], "Error occurred parsing SQL pool connection_max_lifetime string: could not convert the input string of 1 hour into a duration")
+}
+
+func (suite *StorageSuite) TestShouldSetDefaultRetention() {
+	ValidateStorage(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Assert().False(suite.validator.HasErrors())
+
+	suite.Assert().Equal("1h", suite.configuration.Retention.Interval)
+	suite.Assert().Equal("0", suite.configuration.Retention.AuthenticationLogs)
+}
+
+func (suite *StorageSuite) TestShouldRaiseErrorOnInvalidRetentionDurations() {
+	suite.configuration.Retention = schema.StorageRetentionConfiguration{
+		Interval:           "1 hour",
+		AuthenticationLogs: "1x",
+	}
+
+	ValidateStorage(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Require().Len(suite.validator.Errors(), 2)
+	suite.Assert().EqualError(suite.validator.Errors()[0], "Error occurred parsing storage retention interval string: could not convert the input string of 1 hour into a duration")
+	suite.Assert().EqualError(suite.validator.Errors()[1], "Error occurred parsing storage retention authentication_logs string: could not convert the input string of 1x into a duration")
+}
+
+func (suite *StorageSuite) TestShouldRaiseErrorWhenAuthenticationLogsRetentionIsTooShort() {
+	suite.configuration.Retention = schema.StorageRetentionConfiguration{
+		AuthenticationLogs: "12h",
+	}
+
+	ValidateStorage(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Require().Len(suite.validator.Errors(), 1)
+	suite.Assert().EqualError(suite.validator.Errors()[0], "storage retention authentication_logs must be at least 1 day or 0 to keep them forever")
+}
+
+func (suite *StorageSuite) TestShouldSetDefaultCacheSize() {
+	ValidateStorage(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Assert().False(suite.validator.HasErrors())
+
+	suite.Assert().False(suite.configuration.Cache.Disable)
+	suite.Assert().Equal(1000, suite.configuration.Cache.Size)
+}
+
+func (suite *StorageSuite) TestShouldRaiseErrorOnNegativeCacheSize() {
+	suite.configuration.Cache = schema.StorageCacheConfiguration{
+		Size: -1,
+	}
+
+	ValidateStorage(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasWarnings())
+	suite.Require().Len(suite.validator.Errors(), 1)
+	suite.Assert().EqualError(suite.validator.Errors()[0], "storage cache size must be greater than 0 but it is configured as -1")
+}
+
+func (suite *StorageSuite) TestShouldValidateSQLReadReplicas() {
+	suite.configuration.Local = nil
+	suite.configuration.PostgreSQL = &schema.PostgreSQLStorageConfiguration{
+		SQLStorageConfiguration: schema.SQLStorageConfiguration{
+			Username:     "myuser",
+			Password:     "pass",
+			Database:     "database",
+			ReadReplicas: []string{"replica1", "replica2:5433", "[::1]:5432", "::1", "replica3:abc", ""},
+		},
+	}
+
+	defer func() {
+		suite.configuration.PostgreSQL = nil
+	}()
+
+	ValidateStorage(&suite.configuration, suite.validator)
+
+	suite.Require().Len(suite.validator.Errors(), 2)
+	suite.Assert().EqualError(suite.validator.Errors()[0], "the SQL read replica 'replica3:abc' must be a host optionally followed by a port")
+	suite.Assert().EqualError(suite.validator.Errors()[1], "the SQL read replica '' must be a host optionally followed by a port")
+}
+
+func (suite *StorageSuite) TestShouldValidateMigrationTarget() {
+	suite.configuration.MigrationTarget = &schema.StorageMigrationTargetConfiguration{}
+
+	defer func() {
+		suite.configuration.MigrationTarget = nil
+	}()
+
+	ValidateStorage(&suite.configuration, suite.validator)
+
+	suite.Require().Len(suite.validator.Errors(), 1)
+	suite.Assert().EqualError(suite.validator.Errors()[0], "A storage migration target must be provided. It could be 'local', 'mysql' or 'postgres'")
+
+	suite.validator.Clear()
+	suite.configuration.MigrationTarget.PostgreSQL = &schema.PostgreSQLStorageConfiguration{
+		SQLStorageConfiguration: schema.SQLStorageConfiguration{
+			Username: "myuser",
+			Password: "pass",
+			Database: "database",
+		},
+	}
+
+	ValidateStorage(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasErrors())
+	suite.Assert().Equal("disable", suite.configuration.MigrationTarget.PostgreSQL.SSLMode)
+}
+
+func (suite *StorageSuite) TestShouldValidateMigrationTargetIsNotTheCurrentStorage() {
+	suite.configuration.MigrationTarget = &schema.StorageMigrationTargetConfiguration{
+		Local: &schema.LocalStorageConfiguration{Path: suite.configuration.Local.Path},
+	}
+
+	defer func() {
+		suite.configuration.MigrationTarget = nil
+	}()
+
+	ValidateStorage(&suite.configuration, suite.validator)
+
+	suite.Require().Len(suite.validator.Errors(), 1)
+	suite.Assert().EqualError(suite.validator.Errors()[0], "The storage migration target must not be the current storage")
+}
+
+func (suite *StorageSuite) TestShouldValidateEncryptionKey() {
+	suite.configuration.EncryptionKey = "short"
+
+	defer func() {
+		suite.configuration.EncryptionKey = ""
+		suite.configuration.Provider = ""
+	}()
+
+	ValidateStorage(&suite.configuration, suite.validator)
+
+	suite.Require().Len(suite.validator.Errors(), 1)
+	suite.Assert().EqualError(suite.validator.Errors()[0], "the storage encryption_key must be at least 20 characters long")
+
+	suite.validator.Clear()
+	suite.configuration.EncryptionKey = "a-very-long-encryption-key"
+
+	ValidateStorage(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasErrors())
+
+	suite.configuration.Local = nil
+	suite.configuration.Provider = "custom"
+
+	ValidateStorage(&suite.configuration, suite.validator)
+
+	suite.Require().Len(suite.validator.Errors(), 1)
+	suite.Assert().EqualError(suite.validator.Errors()[0], "A custom storage 'provider' cannot encrypt the secrets with 'encryption_key'")
+}
+
+func (suite *StorageSuite) TestShouldValidatePreviousEncryptionKey() {
+	suite.configuration.MySQL = nil
+	suite.configuration.PostgreSQL = nil
+	suite.configuration.PreviousEncryptionKey = "a-very-long-encryption-key"
+
+	defer func() {
+		suite.configuration.EncryptionKey = ""
+		suite.configuration.PreviousEncryptionKey = ""
+	}()
+
+	ValidateStorage(&suite.configuration, suite.validator)
+
+	suite.Require().Len(suite.validator.Errors(), 1)
+	suite.Assert().EqualError(suite.validator.Errors()[0], "the storage previous_encryption_key requires the encryption_key the secrets are rotated to, use the decrypt command to store them in plaintext")
+
+	suite.validator.Clear()
+	suite.configuration.EncryptionKey = "a-very-long-encryption-key"
+
+	ValidateStorage(&suite.configuration, suite.validator)
+
+	suite.Require().Len(suite.validator.Errors(), 1)
+	suite.Assert().EqualError(suite.validator.Errors()[0], "the storage previous_encryption_key must be different from the encryption_key")
+
+	suite.validator.Clear()
+	suite.configuration.EncryptionKey = "another-very-long-encryption-key"
+
+	ValidateStorage(&suite.configuration, suite.validator)
+
+	suite.Assert().False(suite.validator.HasErrors())
+}
+
 func TestShouldRunStorageSuite(t *testing.T) {
 	suite.Run(t, new(StorageSuite))
 }
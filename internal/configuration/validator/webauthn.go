@@ -0,0 +1,23 @@
+package validator
+
+import (
+	"fmt"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+// ValidateWebauthn validates and update WebAuthn configuration.
+func ValidateWebauthn(configuration *schema.WebauthnConfiguration, validator *schema.StructValidator) {
+	if configuration.DisplayName == "" {
+		configuration.DisplayName = schema.DefaultWebauthnConfiguration.DisplayName
+	}
+
+	switch configuration.Passwordless.Policy {
+	case "":
+		configuration.Passwordless.Policy = schema.DefaultWebauthnConfiguration.Passwordless.Policy
+	case oneFactorPolicy, twoFactorPolicy:
+		break
+	default:
+		validator.Push(fmt.Errorf("WebAuthn passwordless policy must be either '%s' or '%s' but it is '%s'", oneFactorPolicy, twoFactorPolicy, configuration.Passwordless.Policy))
+	}
+}
@@ -0,0 +1,48 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+func TestShouldSetDefaultWebauthnValues(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.WebauthnConfiguration{}
+
+	ValidateWebauthn(&config, validator)
+
+	assert.Len(t, validator.Errors(), 0)
+	assert.Equal(t, schema.DefaultWebauthnConfiguration.DisplayName, config.DisplayName)
+	assert.Equal(t, schema.DefaultWebauthnConfiguration.Passwordless.Policy, config.Passwordless.Policy)
+	assert.False(t, config.Passwordless.Enable)
+}
+
+func TestShouldAllowOneFactorWebauthnPasswordlessPolicy(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.WebauthnConfiguration{
+		DisplayName:  "Example",
+		Passwordless: schema.WebauthnPasswordlessConfiguration{Enable: true, Policy: oneFactorPolicy},
+	}
+
+	ValidateWebauthn(&config, validator)
+
+	assert.Len(t, validator.Errors(), 0)
+	assert.Equal(t, "Example", config.DisplayName)
+	assert.Equal(t, oneFactorPolicy, config.Passwordless.Policy)
+}
+
+func TestShouldRaiseErrorWhenInvalidWebauthnPasswordlessPolicy(t *testing.T) {
+	validator := schema.NewStructValidator()
+	config := schema.WebauthnConfiguration{
+		Passwordless: schema.WebauthnPasswordlessConfiguration{Enable: true, Policy: "bypass"},
+	}
+
+	ValidateWebauthn(&config, validator)
+
+	require.Len(t, validator.Errors(), 1)
+	assert.EqualError(t, validator.Errors()[0], "WebAuthn passwordless policy must be either 'one_factor' or 'two_factor' but it is 'bypass'")
+}
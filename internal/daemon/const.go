@@ -0,0 +1,12 @@
+package daemon
+
+const (
+	envNotifySocket = "NOTIFY_SOCKET"
+	envWatchdogUSec = "WATCHDOG_USEC"
+	envWatchdogPID  = "WATCHDOG_PID"
+)
+
+const (
+	stateReady    = "READY=1"
+	stateWatchdog = "WATCHDOG=1"
+)
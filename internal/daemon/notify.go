@@ -0,0 +1,85 @@
+package daemon
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/authelia/authelia/internal/logging"
+)
+
+var (
+	ready     = make(chan struct{})
+	readyOnce sync.Once
+)
+
+// NotifyReady notifies the service manager Authelia is ready to serve the requests, i.e. systemd when the unit is of
+// the notify type or the Windows service control manager. It also starts notifying the systemd watchdog when it's
+// enabled. Only the first call has an effect.
+func NotifyReady() {
+	readyOnce.Do(func() {
+		close(ready)
+
+		logger := logging.Logger()
+
+		if err := notify(stateReady); err != nil {
+			logger.Errorf("Unable to notify systemd that Authelia is ready: %v", err)
+			return
+		}
+
+		if interval := watchdogInterval(); interval > 0 {
+			logger.Debugf("Notifying the systemd watchdog every %s", interval)
+
+			go watchdog(interval)
+		}
+	})
+}
+
+// notify sends the state to the socket of the service manager, it does nothing when Authelia isn't started by
+// systemd with the notify type.
+func notify(state string) error {
+	socket := os.Getenv(envNotifySocket)
+	if socket == "" {
+		return nil
+	}
+
+	// The sockets starting with @ are in the abstract namespace, the net package handles them.
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(state))
+
+	return err
+}
+
+// watchdogInterval returns the interval between the notifications of the systemd watchdog, half of its timeout as
+// recommended by systemd, or 0 when the watchdog isn't enabled for this process.
+func watchdogInterval() time.Duration {
+	usec, err := strconv.ParseInt(os.Getenv(envWatchdogUSec), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+
+	if pid := os.Getenv(envWatchdogPID); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+
+	return time.Duration(usec) * time.Microsecond / 2
+}
+
+func watchdog(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := notify(stateWatchdog); err != nil {
+			logging.Logger().Errorf("Unable to notify the systemd watchdog: %v", err)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package daemon
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldNotNotifyWithoutSocket(t *testing.T) {
+	require.NoError(t, os.Unsetenv(envNotifySocket))
+
+	assert.NoError(t, notify(stateReady))
+}
+
+func TestShouldNotifyTheSocket(t *testing.T) {
+	dir, err := os.MkdirTemp("", "authelia-notify")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	socket := filepath.Join(dir, "notify.sock")
+
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	require.NoError(t, err)
+
+	defer conn.Close()
+
+	require.NoError(t, os.Setenv(envNotifySocket, socket))
+
+	defer os.Unsetenv(envNotifySocket)
+
+	require.NoError(t, notify(stateReady))
+
+	buffer := make([]byte, 64)
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+
+	n, err := conn.Read(buffer)
+	require.NoError(t, err)
+	assert.Equal(t, stateReady, string(buffer[:n]))
+}
+
+func TestShouldFailToNotifyMissingSocket(t *testing.T) {
+	require.NoError(t, os.Setenv(envNotifySocket, "/nonexistent/notify.sock"))
+
+	defer os.Unsetenv(envNotifySocket)
+
+	assert.Error(t, notify(stateReady))
+}
+
+func TestShouldComputeWatchdogInterval(t *testing.T) {
+	defer os.Unsetenv(envWatchdogUSec)
+	defer os.Unsetenv(envWatchdogPID)
+
+	require.NoError(t, os.Unsetenv(envWatchdogUSec))
+	assert.Equal(t, time.Duration(0), watchdogInterval())
+
+	require.NoError(t, os.Setenv(envWatchdogUSec, "30000000"))
+	assert.Equal(t, 15*time.Second, watchdogInterval())
+
+	require.NoError(t, os.Setenv(envWatchdogPID, strconv.Itoa(os.Getpid())))
+	assert.Equal(t, 15*time.Second, watchdogInterval())
+
+	require.NoError(t, os.Setenv(envWatchdogPID, strconv.Itoa(os.Getpid()+1)))
+	assert.Equal(t, time.Duration(0), watchdogInterval())
+
+	require.NoError(t, os.Setenv(envWatchdogPID, ""))
+	require.NoError(t, os.Setenv(envWatchdogUSec, "invalid"))
+	assert.Equal(t, time.Duration(0), watchdogInterval())
+}
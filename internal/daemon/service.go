@@ -0,0 +1,11 @@
+//go:build !windows
+// +build !windows
+
+package daemon
+
+// Run runs Authelia in the foreground, which is how both systemd and launchd expect the daemons to run.
+func Run(name string, run func()) error {
+	run()
+
+	return nil
+}
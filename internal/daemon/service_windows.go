@@ -0,0 +1,100 @@
+//go:build windows
+// +build windows
+
+package daemon
+
+import (
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/eventlog"
+
+	"github.com/authelia/authelia/internal/logging"
+)
+
+// eventID is the identifier of the events Authelia writes to the Windows event log.
+const eventID = 1
+
+// Run runs Authelia as a Windows service when it's started by the service control manager, in which case the logs
+// are also written to the Windows event log under the name of the service. Otherwise it runs it in the foreground.
+func Run(name string, run func()) error {
+	isService, err := svc.IsWindowsService()
+	if err != nil {
+		return err
+	}
+
+	if !isService {
+		run()
+
+		return nil
+	}
+
+	logger := logging.Logger()
+
+	log, err := eventlog.Open(name)
+	if err != nil {
+		logger.Warnf("Unable to open the event log, the logs won't be written to it: %v", err)
+	} else {
+		defer log.Close()
+
+		logger.AddHook(&eventLogHook{log: log})
+	}
+
+	return svc.Run(name, &service{run: run})
+}
+
+type service struct {
+	run func()
+}
+
+// Execute starts Authelia and reports it's running once it's ready. Authelia exits when the service is stopped.
+func (s *service) Execute(args []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.StartPending}
+
+	go s.run()
+
+	started := ready
+
+	for {
+		select {
+		case <-started:
+			started = nil
+			status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+		case request := <-requests:
+			switch request.Cmd {
+			case svc.Interrogate:
+				status <- request.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				logging.Logger().Info("Authelia is stopping")
+
+				status <- svc.Status{State: svc.StopPending}
+
+				return false, 0
+			}
+		}
+	}
+}
+
+// eventLogHook writes the log entries to the Windows event log.
+type eventLogHook struct {
+	log *eventlog.Log
+}
+
+func (h *eventLogHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *eventLogHook) Fire(entry *logrus.Entry) error {
+	message, err := entry.String()
+	if err != nil {
+		return err
+	}
+
+	switch entry.Level {
+	case logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel:
+		return h.log.Error(eventID, message)
+	case logrus.WarnLevel:
+		return h.log.Warning(eventID, message)
+	default:
+		return h.log.Info(eventID, message)
+	}
+}
@@ -0,0 +1,56 @@
+package events
+
+import (
+	"github.com/authelia/authelia/internal/logging"
+)
+
+// listenerBufferSize is the number of events a listener can lag behind before its events are dropped.
+const listenerBufferSize = 16
+
+// NewBroker creates a broker without any listener.
+func NewBroker() *Broker {
+	return &Broker{listeners: map[string]map[chan Event]struct{}{}}
+}
+
+// Subscriber creates the subscriber forwarding the events of the bus to the listeners of their user. The events are
+// dropped for the listeners which are too slow to consume them so the publishers are never blocked.
+func (b *Broker) Subscriber() Subscriber {
+	return func(event Event) {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+
+		for listener := range b.listeners[event.Username] {
+			select {
+			case listener <- event:
+			default:
+				logging.Logger().Warnf("Dropped the %s event of user %s since a listener is too slow", event.Type, event.Username)
+			}
+		}
+	}
+}
+
+// Listen registers a listener of the events of the user. The returned function unregisters the listener and must be
+// called once the listener stops consuming the events.
+func (b *Broker) Listen(username string) (events <-chan Event, stop func()) {
+	listener := make(chan Event, listenerBufferSize)
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.listeners[username] == nil {
+		b.listeners[username] = map[chan Event]struct{}{}
+	}
+
+	b.listeners[username][listener] = struct{}{}
+
+	return listener, func() {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+
+		delete(b.listeners[username], listener)
+
+		if len(b.listeners[username]) == 0 {
+			delete(b.listeners, username)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldForwardEventsToListenersOfTheirUser(t *testing.T) {
+	broker := NewBroker()
+	bus := NewBus()
+	bus.Subscribe(broker.Subscriber(), PushRequested, SessionsRevoked)
+
+	john, stopJohn := broker.Listen("john")
+	harry, stopHarry := broker.Listen("harry")
+
+	defer stopHarry()
+
+	bus.Publish(Event{Type: PushRequested, Username: "john"})
+	bus.Publish(Event{Type: LoginSucceeded, Username: "john"})
+	bus.Publish(Event{Type: SessionsRevoked, Username: "harry"})
+
+	require.Len(t, john, 1)
+	assert.Equal(t, PushRequested, (<-john).Type)
+
+	require.Len(t, harry, 1)
+	assert.Equal(t, SessionsRevoked, (<-harry).Type)
+
+	stopJohn()
+
+	bus.Publish(Event{Type: PushRequested, Username: "john"})
+
+	assert.Len(t, john, 0)
+	assert.NotContains(t, broker.listeners, "john")
+}
+
+func TestShouldDropEventsOfSlowListeners(t *testing.T) {
+	broker := NewBroker()
+	subscriber := broker.Subscriber()
+
+	listener, stop := broker.Listen("john")
+	defer stop()
+
+	for i := 0; i < listenerBufferSize+5; i++ {
+		subscriber(Event{Type: PushRequested, Username: "john"})
+	}
+
+	assert.Len(t, listener, listenerBufferSize)
+}
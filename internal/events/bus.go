@@ -0,0 +1,66 @@
+package events
+
+import (
+	"time"
+
+	"github.com/authelia/authelia/internal/logging"
+)
+
+// NewBus creates an event bus without any subscriber.
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe registers the subscriber for the events of the given types, or for all the events when no type is given.
+func (b *Bus) Subscribe(subscriber Subscriber, types ...Type) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.subscriptions = append(b.subscriptions, subscription{subscriber: subscriber, types: types})
+}
+
+// Publish dispatches the event to its subscribers in the order they subscribed. A subscriber which panics doesn't
+// prevent the other subscribers from receiving the event. Publishing to a nil bus does nothing.
+func (b *Bus) Publish(event Event) {
+	if b == nil {
+		return
+	}
+
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	b.mutex.RLock()
+	subscriptions := b.subscriptions
+	b.mutex.RUnlock()
+
+	for _, subscription := range subscriptions {
+		if subscription.accepts(event.Type) {
+			dispatch(subscription.subscriber, event)
+		}
+	}
+}
+
+func dispatch(subscriber Subscriber, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			logging.Logger().Errorf("A subscriber of the %s events panicked: %v", event.Type, r)
+		}
+	}()
+
+	subscriber(event)
+}
+
+func (s subscription) accepts(eventType Type) bool {
+	if len(s.types) == 0 {
+		return true
+	}
+
+	for _, t := range s.types {
+		if t == eventType {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,92 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldDispatchEventsToSubscribers(t *testing.T) {
+	bus := NewBus()
+
+	var all, logins []Type
+
+	bus.Subscribe(func(event Event) {
+		all = append(all, event.Type)
+	})
+
+	bus.Subscribe(func(event Event) {
+		logins = append(logins, event.Type)
+	}, LoginSucceeded, LoginFailed)
+
+	bus.Publish(Event{Type: LoginFailed, Username: "john"})
+	bus.Publish(Event{Type: UserBanned, Username: "john"})
+	bus.Publish(Event{Type: LoginSucceeded, Username: "john"})
+
+	assert.Equal(t, []Type{LoginFailed, UserBanned, LoginSucceeded}, all)
+	assert.Equal(t, []Type{LoginFailed, LoginSucceeded}, logins)
+}
+
+func TestShouldDispatchEventsWhenSubscriberPanics(t *testing.T) {
+	bus := NewBus()
+
+	var received Event
+
+	bus.Subscribe(func(event Event) {
+		panic("failed")
+	})
+
+	bus.Subscribe(func(event Event) {
+		received = event
+	})
+
+	bus.Publish(Event{Type: DeviceRegistered, Username: "john"})
+
+	assert.Equal(t, "john", received.Username)
+	assert.False(t, received.Time.IsZero())
+}
+
+func TestShouldIgnoreEventsPublishedToNilBus(t *testing.T) {
+	var bus *Bus
+
+	assert.NotPanics(t, func() {
+		bus.Publish(Event{Type: LoginSucceeded})
+	})
+}
+
+func TestShouldCountAndLogEvents(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+
+	bus := NewBus()
+	bus.Subscribe(NewMetricsSubscriber())
+	bus.Subscribe(NewAuditSubscriber(logger))
+
+	before := publishedEvents.Get(string(UserBanned))
+
+	bus.Publish(Event{
+		Type:     UserBanned,
+		Username: "john",
+		RemoteIP: "127.0.0.1",
+		Details: map[string]string{
+			DetailBannedUntil: "2021-01-01T00:05:00Z",
+			DetailEmail:       "john@example.com",
+		},
+	})
+
+	if before == nil {
+		assert.Equal(t, "1", publishedEvents.Get(string(UserBanned)).String())
+	}
+
+	require.NotNil(t, hook.LastEntry())
+	assert.Equal(t, logrus.InfoLevel, hook.LastEntry().Level)
+	assert.Equal(t, "Event user_banned of user john", hook.LastEntry().Message)
+	assert.Equal(t, logrus.Fields{
+		"event":        UserBanned,
+		"username":     "john",
+		"remote_ip":    "127.0.0.1",
+		"banned_until": "2021-01-01T00:05:00Z",
+	}, hook.LastEntry().Data)
+}
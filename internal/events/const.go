@@ -0,0 +1,150 @@
+package events
+
+const (
+	// LoginSucceeded is published when a user succeeds an authentication attempt, of the first or second factor.
+	LoginSucceeded Type = "login_succeeded"
+
+	// LoginFailed is published when a user fails an authentication attempt, of the first or second factor.
+	LoginFailed Type = "login_failed"
+
+	// UserBanned is published when the failed authentication attempts of a user trigger a ban by the regulation.
+	UserBanned Type = "user_banned"
+
+	// DeviceRegistered is published when a user registers a second factor device.
+	DeviceRegistered Type = "device_registered"
+
+	// DeviceRevoked is published when a second factor device of a user is deleted, by the user or by an administrator
+	// who is then the actor of the event.
+	DeviceRevoked Type = "device_revoked"
+
+	// UserUnbanned is published when an administrator lifts the bans of the regulation of a user.
+	UserUnbanned Type = "user_unbanned"
+
+	// SecondFactorResetRequested is published when an operator requests the reset of the second factor devices of a
+	// user, the devices are only deleted once the reset is approved.
+	SecondFactorResetRequested Type = "second_factor_reset_requested"
+
+	// SecondFactorResetApproved is published when a second operator or the user approves the reset of the second factor
+	// devices of the user, the devices have been deleted.
+	SecondFactorResetApproved Type = "second_factor_reset_approved"
+
+	// SecondFactorResetRejected is published when an operator rejects the reset of the second factor devices of a user.
+	SecondFactorResetRejected Type = "second_factor_reset_rejected"
+
+	// AccessGrantCreated is published when a user or an administrator gives a guest a time-boxed access to a protected
+	// resource.
+	AccessGrantCreated Type = "access_grant_created"
+
+	// AccessGrantRevoked is published when a user or an administrator revokes an access grant before it expires.
+	AccessGrantRevoked Type = "access_grant_revoked"
+
+	// BreakGlassUsed is published when a break-glass account logs in, the operators are alerted of every use.
+	BreakGlassUsed Type = "break_glass_used"
+
+	// BreakGlassDenied is published when a break-glass account attempts to log in after its window elapsed.
+	BreakGlassDenied Type = "break_glass_denied"
+
+	// PushRequested is published when a push notification is sent to the device of a user, the approval is pending
+	// until the login events of the push are published.
+	PushRequested Type = "push_requested"
+
+	// SessionsRevoked is published when all the sessions of a user are revoked, e.g. when the user is purged.
+	SessionsRevoked Type = "sessions_revoked"
+
+	// PasswordReset is published when a user resets their password.
+	PasswordReset Type = "password_reset"
+
+	// PreferenceChanged is published when a user changes one of their preferences, e.g. their preferred second factor
+	// method.
+	PreferenceChanged Type = "preference_changed"
+
+	// ConsentGranted is published when a user grants the scopes requested by an OpenID Connect client.
+	ConsentGranted Type = "consent_granted"
+
+	// ConsentRejected is published when a user rejects the scopes requested by an OpenID Connect client.
+	ConsentRejected Type = "consent_rejected"
+
+	// ConsentRevoked is published when a user revokes the consent they remembered for an OpenID Connect client.
+	ConsentRevoked Type = "consent_revoked"
+
+	// UserDataExported is published when the personal data of a user is exported, by the user or an administrator.
+	UserDataExported Type = "user_data_exported"
+
+	// UserDataErased is published when the personal data of a user is erased, i.e. when the user is purged.
+	UserDataErased Type = "user_data_erased"
+
+	// SessionPinMismatched is published when a session is used by another client than the one it's pinned to, the
+	// session cookie may have been stolen.
+	SessionPinMismatched Type = "session_pin_mismatched"
+
+	// SessionImpossibleTravel is published when a session is used from another country too soon after it was used from
+	// the previous one for the user to have travelled, the session is invalidated.
+	SessionImpossibleTravel Type = "session_impossible_travel"
+)
+
+const (
+	// DetailAuthenticationType is the type of authentication of the login events, e.g. 1FA or TOTP.
+	DetailAuthenticationType = "authentication_type"
+
+	// DetailBannedUntil is the time until when the user is banned, formatted with RFC 3339.
+	DetailBannedUntil = "banned_until"
+
+	// DetailDevice is the kind of device registered, e.g. one-time password.
+	DetailDevice = "device"
+
+	// DetailReplaced is "true" when the registered device replaces a device of the same kind.
+	DetailReplaced = "replaced"
+
+	// DetailEmail is the email address of the user.
+	DetailEmail = "email"
+
+	// DetailLocation is the location of the user determined from their IP address.
+	DetailLocation = "location"
+
+	// DetailPreviousLocation is the location the session was used from before the impossible travel events.
+	DetailPreviousLocation = "previous_location"
+
+	// DetailResetPasswordURL is the URL the user can reset their password at.
+	DetailResetPasswordURL = "reset_password_url"
+
+	// DetailActor is the user or admin API token which made the change, when it's not the user of the event.
+	DetailActor = "actor"
+
+	// DetailPreference is the name of the preference changed, e.g. second_factor_method.
+	DetailPreference = "preference"
+
+	// DetailValue is the new value of the preference changed.
+	DetailValue = "value"
+
+	// DetailResetID is the ID of the second factor reset of the reset events.
+	DetailResetID = "reset_id"
+
+	// DetailRequestedBy is the operator who requested the second factor reset of the reset events.
+	DetailRequestedBy = "requested_by"
+
+	// DetailApproval is who approves the second factor reset of the reset events, i.e. admin or user.
+	DetailApproval = "approval"
+
+	// DetailReason is the reason given by the operator who requested the second factor reset of the reset events.
+	DetailReason = "reason"
+
+	// DetailGrantID is the ID of the access grant of the access grant events.
+	DetailGrantID = "grant_id"
+
+	// DetailTargetURL is the URL of the resource of the access grant events.
+	DetailTargetURL = "target_url"
+
+	// DetailExpiresAt is the time the access grant of the access grant events, the window of the break-glass events or
+	// the remembered consent of the consent events expires, formatted with RFC 3339.
+	DetailExpiresAt = "expires_at"
+
+	// DetailClientID is the ID of the OpenID Connect client of the consent events.
+	DetailClientID = "client_id"
+
+	// DetailScopes is the space separated list of the scopes of the consent events.
+	DetailScopes = "scopes"
+
+	// DetailStrictness is the strictness of the session pinning of the session pin events, i.e. whether the session was
+	// invalidated.
+	DetailStrictness = "strictness"
+)
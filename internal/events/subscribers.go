@@ -0,0 +1,42 @@
+package events
+
+import (
+	"expvar"
+
+	"github.com/sirupsen/logrus"
+)
+
+// publishedEvents counts the events published per type, it's exposed by the expvars endpoint.
+var publishedEvents = expvar.NewMap("authelia_events")
+
+// NewMetricsSubscriber creates a subscriber counting the events per type in the expvar authelia_events.
+func NewMetricsSubscriber() Subscriber {
+	return func(event Event) {
+		publishedEvents.Add(string(event.Type), 1)
+	}
+}
+
+// NewAuditSubscriber creates a subscriber logging the events with their details as fields.
+func NewAuditSubscriber(logger *logrus.Logger) Subscriber {
+	return func(event Event) {
+		fields := logrus.Fields{
+			"event":    event.Type,
+			"username": event.Username,
+		}
+
+		if event.RemoteIP != "" {
+			fields["remote_ip"] = event.RemoteIP
+		}
+
+		for key, value := range event.Details {
+			// The email address and URLs are only useful to the notifications.
+			if key == DetailEmail || key == DetailResetPasswordURL {
+				continue
+			}
+
+			fields[key] = value
+		}
+
+		logger.WithFields(fields).Infof("Event %s of user %s", event.Type, event.Username)
+	}
+}
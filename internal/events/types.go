@@ -0,0 +1,49 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Type is the type of an event.
+type Type string
+
+// Event is something which happened in Authelia the subsystems may react to, such as sending a notification or
+// counting it in the metrics.
+type Event struct {
+	Type Type
+	Time time.Time
+
+	Username  string
+	RemoteIP  string
+	UserAgent string
+
+	// RequestID is the ID of the request which triggered the event, empty when the event wasn't triggered by a request.
+	RequestID string
+
+	// Details holds the information specific to the type of the event, see the constants of the details keys.
+	Details map[string]string
+}
+
+// Subscriber handles the events it subscribed to. The subscribers are called synchronously by the request publishing
+// the event so the slow side effects must be done in the background, like the notifications queued by the notifier.
+type Subscriber func(event Event)
+
+// Bus dispatches the events published by the handlers to the subscribers, so the handlers don't depend on the
+// subsystems reacting to the events.
+type Bus struct {
+	mutex         sync.RWMutex
+	subscriptions []subscription
+}
+
+type subscription struct {
+	subscriber Subscriber
+	types      []Type
+}
+
+// Broker streams the events of the users to the listeners of their user, such as the portal listening to the event
+// stream of the logged in user.
+type Broker struct {
+	mutex     sync.Mutex
+	listeners map[string]map[chan Event]struct{}
+}
@@ -0,0 +1,143 @@
+package geoip
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strings"
+)
+
+// CSVProvider is a GeoIP provider backed by a CSV database in memory. Each record of the database has the format
+// 'network,country,city' where the network is in CIDR notation. The header line is optional.
+type CSVProvider struct {
+	// prefixes are the networks grouped by prefix, the most specific first, so the first match is always the longest
+	// prefix. A lookup is a binary search per prefix rather than a scan of the whole database.
+	prefixes []*csvPrefix
+}
+
+// csvPrefix holds the networks sharing the same mask sorted by address.
+type csvPrefix struct {
+	mask    net.IPMask
+	entries []csvEntry
+}
+
+type csvEntry struct {
+	network  *net.IPNet
+	location Location
+}
+
+// lookup returns the entry of the network containing the IP, if any.
+func (p *csvPrefix) lookup(ip net.IP) *csvEntry {
+	if len(p.mask) == net.IPv4len {
+		if ip = ip.To4(); ip == nil {
+			return nil
+		}
+	} else {
+		ip = ip.To16()
+	}
+
+	network := ip.Mask(p.mask)
+
+	i := sort.Search(len(p.entries), func(i int) bool {
+		return bytes.Compare(p.entries[i].network.IP, network) >= 0
+	})
+
+	if i < len(p.entries) && p.entries[i].network.IP.Equal(network) {
+		return &p.entries[i]
+	}
+
+	return nil
+}
+
+// NewCSVProvider creates a new CSVProvider from the database at the given path.
+func NewCSVProvider(path string) (provider *CSVProvider, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	return newCSVProvider(file)
+}
+
+func newCSVProvider(r io.Reader) (provider *CSVProvider, err error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+
+	provider = &CSVProvider{}
+	prefixes := map[string]*csvPrefix{}
+
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse GeoIP database: %w", err)
+		}
+
+		if line == 1 && strings.EqualFold(record[0], "network") {
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(record[0])
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse GeoIP database network on line %d: %w", line, err)
+		}
+
+		prefix, ok := prefixes[network.Mask.String()]
+		if !ok {
+			prefix = &csvPrefix{mask: network.Mask}
+			prefixes[network.Mask.String()] = prefix
+			provider.prefixes = append(provider.prefixes, prefix)
+		}
+
+		prefix.entries = append(prefix.entries, csvEntry{
+			network:  network,
+			location: Location{Country: record[1], City: record[2]},
+		})
+	}
+
+	// Sort the most specific prefixes first, and the networks of each prefix by address. The sorts are stable so the
+	// first of the duplicated networks wins.
+	sort.SliceStable(provider.prefixes, func(i, j int) bool {
+		a, _ := provider.prefixes[i].mask.Size()
+		b, _ := provider.prefixes[j].mask.Size()
+
+		return a > b
+	})
+
+	for _, prefix := range provider.prefixes {
+		entries := prefix.entries
+
+		sort.SliceStable(entries, func(i, j int) bool {
+			return bytes.Compare(entries[i].network.IP, entries[j].network.IP) < 0
+		})
+	}
+
+	return provider, nil
+}
+
+// Lookup the location of the given IP. The location is nil if the IP is not in the database.
+func (p *CSVProvider) Lookup(ip net.IP) (location *Location, err error) {
+	if ip == nil {
+		return nil, nil
+	}
+
+	for _, prefix := range p.prefixes {
+		if entry := prefix.lookup(ip); entry != nil {
+			location := entry.location
+
+			return &location, nil
+		}
+	}
+
+	return nil, nil
+}
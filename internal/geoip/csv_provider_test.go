@@ -0,0 +1,96 @@
+package geoip
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCSVDatabase = `network,country,city
+192.168.0.0/16,France,
+192.168.1.0/24,France,Paris
+10.0.0.0/8,Germany,Berlin
+2001:db8::/32,Japan,Tokyo
+`
+
+func TestShouldLookupMostSpecificNetwork(t *testing.T) {
+	provider, err := newCSVProvider(strings.NewReader(testCSVDatabase))
+	require.NoError(t, err)
+
+	location, err := provider.Lookup(net.ParseIP("192.168.1.10"))
+	require.NoError(t, err)
+	require.NotNil(t, location)
+	assert.Equal(t, "Paris, France", location.String())
+
+	location, err = provider.Lookup(net.ParseIP("192.168.2.10"))
+	require.NoError(t, err)
+	require.NotNil(t, location)
+	assert.Equal(t, "France", location.String())
+
+	location, err = provider.Lookup(net.ParseIP("2001:db8::1"))
+	require.NoError(t, err)
+	require.NotNil(t, location)
+	assert.Equal(t, "Tokyo, Japan", location.String())
+}
+
+func TestShouldReturnNilLocationWhenNotFound(t *testing.T) {
+	provider, err := newCSVProvider(strings.NewReader(testCSVDatabase))
+	require.NoError(t, err)
+
+	location, err := provider.Lookup(net.ParseIP("172.16.0.1"))
+	assert.NoError(t, err)
+	assert.Nil(t, location)
+
+	location, err = provider.Lookup(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, location)
+}
+
+func TestShouldFailToParseInvalidDatabase(t *testing.T) {
+	_, err := newCSVProvider(strings.NewReader("network,country,city\nnot-a-network,France,Paris\n"))
+	assert.EqualError(t, err, "unable to parse GeoIP database network on line 2: invalid CIDR address: not-a-network")
+
+	_, err = newCSVProvider(strings.NewReader("192.168.0.0/16,France\n"))
+	assert.EqualError(t, err, "unable to parse GeoIP database: record on line 1: wrong number of fields")
+}
+
+func TestShouldLookupAmongManyNetworks(t *testing.T) {
+	var database strings.Builder
+
+	for i := 255; i >= 0; i-- {
+		fmt.Fprintf(&database, "10.%d.0.0/16,Country%d,\n", i, i)
+		fmt.Fprintf(&database, "10.%d.%d.0/24,Country%d,City%d\n", i, i, i, i)
+	}
+
+	database.WriteString("10.1.0.0/16,Duplicate,\n")
+	database.WriteString("0.0.0.0/0,World,\n")
+
+	provider, err := newCSVProvider(strings.NewReader(database.String()))
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		ip       string
+		expected string
+	}{
+		{"10.0.0.1", "City0, Country0"},
+		{"10.0.1.1", "Country0"},
+		{"10.1.1.255", "City1, Country1"},
+		{"10.1.2.1", "Country1"},
+		{"10.255.255.1", "City255, Country255"},
+		{"10.255.0.1", "Country255"},
+		{"192.168.0.1", "World"},
+	} {
+		location, err := provider.Lookup(net.ParseIP(tc.ip))
+		require.NoError(t, err)
+		require.NotNil(t, location, tc.ip)
+		assert.Equal(t, tc.expected, location.String(), tc.ip)
+	}
+
+	location, err := provider.Lookup(net.ParseIP("2001:db8::1"))
+	assert.NoError(t, err)
+	assert.Nil(t, location)
+}
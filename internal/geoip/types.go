@@ -0,0 +1,29 @@
+package geoip
+
+import (
+	"fmt"
+	"net"
+)
+
+// Provider is an interface for looking up the geographical location of an IP address.
+type Provider interface {
+	Lookup(ip net.IP) (location *Location, err error)
+}
+
+// Location represents the geographical location of an IP address.
+type Location struct {
+	Country string
+	City    string
+}
+
+// String returns a human readable representation of the location such as 'Paris, France'.
+func (l Location) String() string {
+	switch {
+	case l.City == "":
+		return l.Country
+	case l.Country == "":
+		return l.City
+	default:
+		return fmt.Sprintf("%s, %s", l.City, l.Country)
+	}
+}
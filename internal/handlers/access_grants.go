@@ -0,0 +1,300 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"github.com/valyala/fasthttp"
+
+	"github.com/authelia/authelia/internal/authorization"
+	"github.com/authelia/authelia/internal/events"
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+var (
+	errAccessGrantExpired        = errors.New("access grant has expired")
+	errAccessGrantTargetMismatch = errors.New("access grant was issued for another resource")
+	errAccessGrantNotPermitted   = errors.New("access grants are not permitted by the rule matching the resource")
+)
+
+// accessGrantClaims are the claims of the signed token of an access grant. The ID of the token is the ID of the grant
+// which is checked against the storage so the grant can be revoked before it expires.
+type accessGrantClaims struct {
+	jwt.StandardClaims
+
+	TargetURL string `json:"target_url"`
+}
+
+// parseAccessGrantTargetURL parses the URL of the resource of an access grant, the query and the fragment are dropped
+// since the grant covers the path.
+func parseAccessGrantTargetURL(ctx *middlewares.AutheliaCtx, value string) (*url.URL, error) {
+	targetURL, err := url.ParseRequestURI(value)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to parse the target URL %s of the access grant: %s", value, err)
+	}
+
+	if !isSchemeHTTPS(targetURL) || !isURLUnderProtectedDomain(targetURL, ctx.Configuration.Session.Domain) {
+		return nil, fmt.Errorf("The target URL %s of the access grant must be a https URL under the protected domain %s",
+			value, ctx.Configuration.Session.Domain)
+	}
+
+	if targetURL.Path == "" {
+		targetURL.Path = "/"
+	}
+
+	return &url.URL{Scheme: targetURL.Scheme, Host: targetURL.Host, Path: targetURL.Path}, nil
+}
+
+// isAccessGrantPermitted returns true when the access grants are permitted by the rule matching a guest, i.e. an
+// anonymous subject, requesting the target URL from the IP of the request.
+func isAccessGrantPermitted(ctx *middlewares.AutheliaCtx, targetURL *url.URL, method string) bool {
+	return ctx.Providers.Authorizer.IsAccessGrantPermitted(authorization.Subject{IP: ctx.RemoteIP()},
+		authorization.NewObject(targetURL, method))
+}
+
+// isAccessGrantCovering returns true when the access grant to the grant URL covers the target URL, i.e. the target has
+// the same path or is below the path of the grant when it ends with a slash.
+func isAccessGrantCovering(grantURL, targetURL *url.URL) bool {
+	if grantURL.Scheme != targetURL.Scheme || !utils.IsStringInSliceFold(targetURL.Host, []string{grantURL.Host}) {
+		return false
+	}
+
+	if grantURL.Path == targetURL.Path {
+		return true
+	}
+
+	return grantURL.Path[len(grantURL.Path)-1] == '/' && len(targetURL.Path) > len(grantURL.Path) &&
+		targetURL.Path[:len(grantURL.Path)] == grantURL.Path
+}
+
+// createAccessGrant creates an access grant to the target URL on behalf of the given user or admin API token and
+// replies with the link to give to the guest.
+func createAccessGrant(ctx *middlewares.AutheliaCtx, createdBy string, targetURL *url.URL, requestBody accessGrantRequestBody) {
+	config := ctx.Configuration.AccessGrants
+
+	if !isAccessGrantPermitted(ctx, targetURL, fasthttp.MethodGet) {
+		ctx.Logger.Infof("Access grant to %s requested by %s is not permitted by the access control rules", targetURL.String(), createdBy)
+		ctx.ReplyForbidden()
+
+		return
+	}
+
+	lifespanValue := requestBody.Lifespan
+	if lifespanValue == "" {
+		lifespanValue = config.DefaultLifespan
+	}
+
+	lifespan, err := utils.ParseDurationString(lifespanValue)
+	if err != nil {
+		ctx.Error(fmt.Errorf("Unable to parse the lifespan of the access grant requested by %s: %s", createdBy, err), operationFailedMessage)
+		return
+	}
+
+	maxLifespan, _ := utils.ParseDurationString(config.MaxLifespan)
+
+	if lifespan <= 0 || lifespan > maxLifespan {
+		ctx.Error(fmt.Errorf("Lifespan %s of the access grant requested by %s must be above 0 and not above %s", lifespanValue, createdBy, config.MaxLifespan), operationFailedMessage)
+		return
+	}
+
+	if len(requestBody.Description) > accessGrantDescriptionMaxLength {
+		ctx.Error(fmt.Errorf("Description of the access grant requested by %s must not be above %d characters", createdBy, accessGrantDescriptionMaxLength), operationFailedMessage)
+		return
+	}
+
+	now := ctx.Clock.Now()
+	grant := models.AccessGrant{
+		ID:          uuid.New().String(),
+		Username:    createdBy,
+		TargetURL:   targetURL.String(),
+		Description: requestBody.Description,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(lifespan),
+	}
+
+	link, err := accessGrantLink(ctx, grant, targetURL)
+	if err != nil {
+		ctx.Error(fmt.Errorf("Unable to sign the access grant to %s requested by %s: %s", grant.TargetURL, createdBy, err), operationFailedMessage)
+		return
+	}
+
+	if err = ctx.Providers.StorageProvider.SaveAccessGrant(grant); err != nil {
+		ctx.Error(fmt.Errorf("Unable to save the access grant to %s requested by %s: %s", grant.TargetURL, createdBy, err), operationFailedMessage)
+		return
+	}
+
+	ctx.Logger.Infof("Access grant %s to %s has been created by %s until %s", grant.ID, grant.TargetURL, createdBy, grant.ExpiresAt.UTC().Format(time.RFC3339))
+
+	ctx.PublishEvent(events.AccessGrantCreated, createdBy, map[string]string{
+		events.DetailGrantID:   grant.ID,
+		events.DetailTargetURL: grant.TargetURL,
+		events.DetailExpiresAt: grant.ExpiresAt.UTC().Format(time.RFC3339),
+	})
+
+	response := newAccessGrant(grant)
+	response.URL = link
+
+	if err = ctx.SetJSONBody(response); err != nil {
+		ctx.Logger.Errorf("Unable to set the access grant response in body: %s", err)
+	}
+}
+
+// accessGrantLink signs the token of the access grant and returns the target URL with the token in its parameter.
+func accessGrantLink(ctx *middlewares.AutheliaCtx, grant models.AccessGrant, targetURL *url.URL) (string, error) {
+	claims := accessGrantClaims{
+		StandardClaims: jwt.StandardClaims{
+			Id:        grant.ID,
+			Audience:  accessGrantAudience,
+			IssuedAt:  grant.CreatedAt.Unix(),
+			ExpiresAt: grant.ExpiresAt.Unix(),
+		},
+		TargetURL: grant.TargetURL,
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(ctx.Configuration.JWTSecret))
+	if err != nil {
+		return "", err
+	}
+
+	link := *targetURL
+	query := link.Query()
+	query.Set(ctx.Configuration.AccessGrants.Parameter, token)
+	link.RawQuery = query.Encode()
+
+	return link.String(), nil
+}
+
+// revokeAccessGrant deletes the access grant so the guest can't use it anymore.
+func revokeAccessGrant(ctx *middlewares.AutheliaCtx, grant *models.AccessGrant, revokedBy string) bool {
+	if err := ctx.Providers.StorageProvider.DeleteAccessGrant(grant.ID); err != nil {
+		ctx.Error(fmt.Errorf("Unable to revoke the access grant %s of %s: %s", grant.ID, grant.Username, err), operationFailedMessage)
+		return false
+	}
+
+	ctx.Logger.Infof("Access grant %s to %s of %s has been revoked by %s", grant.ID, grant.TargetURL, grant.Username, revokedBy)
+
+	details := map[string]string{
+		events.DetailGrantID:   grant.ID,
+		events.DetailTargetURL: grant.TargetURL,
+	}
+
+	if revokedBy != grant.Username {
+		details[events.DetailActor] = revokedBy
+	}
+
+	ctx.PublishEvent(events.AccessGrantRevoked, grant.Username, details)
+
+	return true
+}
+
+// isAccessGranted determines if the target URL carries the token of an access grant covering it. The rejected tokens
+// are logged.
+func isAccessGranted(ctx *middlewares.AutheliaCtx, targetURL *url.URL, method []byte) bool {
+	config := ctx.Configuration.AccessGrants
+
+	if config == nil {
+		return false
+	}
+
+	query := targetURL.Query()
+
+	token := query.Get(config.Parameter)
+	if token == "" {
+		return false
+	}
+
+	// The resource is the target URL without the token, so the access control rules match the resource the guest
+	// reaches.
+	query.Del(config.Parameter)
+
+	resourceURL := *targetURL
+	resourceURL.RawQuery = query.Encode()
+
+	grant, err := verifyAccessGrant(ctx, token, &resourceURL, string(method))
+	if err != nil {
+		ctx.Logger.Warnf("Rejected the access grant to %s: %s", resourceURL.String(), err)
+
+		return false
+	}
+
+	ctx.Logger.Infof("Access to %s is granted to a guest by access grant %s of %s", resourceURL.String(), grant.ID, grant.Username)
+
+	return true
+}
+
+func verifyAccessGrant(ctx *middlewares.AutheliaCtx, token string, resourceURL *url.URL, method string) (*models.AccessGrant, error) {
+	// The expiration is verified against the clock of the context rather than the one of the JWT library.
+	parser := jwt.Parser{
+		ValidMethods:         []string{jwt.SigningMethodHS256.Alg()},
+		SkipClaimsValidation: true,
+	}
+
+	claims := &accessGrantClaims{}
+
+	if _, err := parser.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(ctx.Configuration.JWTSecret), nil
+	}); err != nil {
+		return nil, fmt.Errorf("access grant is invalid: %w", err)
+	}
+
+	if !claims.VerifyAudience(accessGrantAudience, true) {
+		return nil, fmt.Errorf("access grant is invalid: the token isn't an access grant")
+	}
+
+	now := ctx.Clock.Now()
+
+	if !claims.VerifyExpiresAt(now.Unix(), true) {
+		return nil, errAccessGrantExpired
+	}
+
+	grantURL, err := url.Parse(claims.TargetURL)
+	if err != nil || !isAccessGrantCovering(grantURL, resourceURL) {
+		return nil, errAccessGrantTargetMismatch
+	}
+
+	// The access control rules are checked on every request so the access grants can be disabled for a resource at
+	// any time.
+	if !isAccessGrantPermitted(ctx, resourceURL, method) {
+		return nil, errAccessGrantNotPermitted
+	}
+
+	grant, err := ctx.Providers.StorageProvider.LoadAccessGrant(claims.Id)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the access grant %s: %w", claims.Id, err)
+	}
+
+	if grant.TargetURL != claims.TargetURL || !grant.ExpiresAt.After(now) {
+		return nil, errAccessGrantExpired
+	}
+
+	return grant, nil
+}
+
+// newAccessGrant returns the model of the access grant returned to the users and the administrators.
+func newAccessGrant(grant models.AccessGrant) AccessGrant {
+	return AccessGrant{
+		ID:          grant.ID,
+		CreatedBy:   grant.Username,
+		TargetURL:   grant.TargetURL,
+		Description: grant.Description,
+		CreatedAt:   grant.CreatedAt.Unix(),
+		ExpiresAt:   grant.ExpiresAt.Unix(),
+	}
+}
+
+// newAccessGrants returns the models of the access grants returned to the users and the administrators.
+func newAccessGrants(grants []models.AccessGrant) []AccessGrant {
+	body := make([]AccessGrant, 0, len(grants))
+
+	for _, grant := range grants {
+		body = append(body, newAccessGrant(grant))
+	}
+
+	return body
+}
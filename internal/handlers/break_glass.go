@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/authelia/authelia/internal/events"
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/storage"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// breakGlassAccount returns the username of the break-glass account matching the username as it's configured, ok is
+// false when the user isn't a break-glass account.
+func breakGlassAccount(ctx *middlewares.AutheliaCtx, username string) (account string, ok bool) {
+	if ctx.Configuration.AuthenticationBackend.BreakGlass == nil {
+		return "", false
+	}
+
+	for _, a := range ctx.Configuration.AuthenticationBackend.BreakGlass.Accounts {
+		if strings.EqualFold(a.Username, username) {
+			return a.Username, true
+		}
+	}
+
+	return "", false
+}
+
+// breakGlassWindow returns the time the window of the break-glass account logging in elapses, it's zero when the user
+// isn't a break-glass account. The window opens on the first use of the account since its last reset, the login is
+// refused and the operators are alerted once it has elapsed.
+func breakGlassWindow(ctx *middlewares.AutheliaCtx, username string) (expiresAt time.Time, err error) {
+	account, ok := breakGlassAccount(ctx, username)
+	if !ok {
+		return time.Time{}, nil
+	}
+
+	window, err := utils.ParseDurationString(ctx.Configuration.AuthenticationBackend.BreakGlass.Window)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Unable to parse the break-glass window: %s", err)
+	}
+
+	now := ctx.Clock.Now()
+
+	activation, err := ctx.Providers.StorageProvider.LoadBreakGlassActivation(account)
+
+	switch {
+	case err == storage.ErrNoBreakGlassActivation:
+		activation = &models.BreakGlassActivation{Username: account, ActivatedAt: now}
+
+		if err = ctx.Providers.StorageProvider.SaveBreakGlassActivation(*activation); err != nil {
+			return time.Time{}, fmt.Errorf("Unable to save the activation of break-glass account %s: %s", account, err)
+		}
+	case err != nil:
+		return time.Time{}, fmt.Errorf("Unable to load the activation of break-glass account %s: %s", account, err)
+	}
+
+	expiresAt = activation.ActivatedAt.Add(window)
+
+	if !now.Before(expiresAt) {
+		ctx.PublishEvent(events.BreakGlassDenied, account, map[string]string{
+			events.DetailExpiresAt: expiresAt.UTC().Format(time.RFC3339),
+		})
+
+		return time.Time{}, fmt.Errorf("The window of break-glass account %s has elapsed at %s", account, expiresAt)
+	}
+
+	return expiresAt, nil
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/events"
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/storage"
+	"github.com/authelia/authelia/pkg/autheliatest"
+)
+
+type BreakGlassSuite struct {
+	suite.Suite
+
+	mock      *mocks.MockAutheliaCtx
+	published []events.Event
+}
+
+func (s *BreakGlassSuite) SetupTest() {
+	s.mock = mocks.NewMockAutheliaCtx(s.T())
+	s.mock.Clock.Set(time.Unix(1600000000, 0))
+	s.mock.Ctx.Clock = &s.mock.Clock
+
+	s.mock.Ctx.Configuration.AuthenticationBackend.BreakGlass = &schema.BreakGlassConfiguration{
+		Window:   "4h",
+		Accounts: []schema.BreakGlassAccountConfiguration{{Username: "emergency"}},
+	}
+
+	s.published = nil
+	s.mock.Ctx.Providers.Events.Subscribe(func(event events.Event) {
+		s.published = append(s.published, event)
+	}, events.BreakGlassUsed, events.BreakGlassDenied)
+}
+
+func (s *BreakGlassSuite) TearDownTest() {
+	s.mock.Close()
+}
+
+func (s *BreakGlassSuite) expectLogin() {
+	s.mock.UserProviderMock.
+		EXPECT().
+		CheckUserPassword(gomock.Eq("Emergency"), gomock.Eq("password")).
+		Return(true, nil)
+
+	s.mock.StorageProviderMock.
+		EXPECT().
+		LoadLatestAuthenticationLogs(gomock.Any(), gomock.Any()).
+		Return(nil, nil).
+		AnyTimes()
+
+	s.mock.Ctx.Request.SetBodyString(`{
+		"username": "Emergency",
+		"password": "password",
+		"targetURL": "https://one-factor.example.com"
+	}`)
+}
+
+func (s *BreakGlassSuite) expectSuccessfulLogin() {
+	s.expectLogin()
+
+	s.mock.UserProviderMock.
+		EXPECT().
+		GetDetails(gomock.Eq("Emergency")).
+		Return(&authentication.UserDetails{Username: "emergency", Groups: []string{"admins"}}, nil)
+
+	s.mock.StorageProviderMock.
+		EXPECT().
+		AppendAuthenticationLog(gomock.Any()).
+		Return(nil)
+}
+
+func (s *BreakGlassSuite) TestShouldOpenWindowAndRequireSecondFactorOnFirstUse() {
+	s.expectSuccessfulLogin()
+
+	s.mock.StorageProviderMock.
+		EXPECT().
+		LoadBreakGlassActivation(gomock.Eq("emergency")).
+		Return(nil, storage.ErrNoBreakGlassActivation)
+
+	s.mock.StorageProviderMock.
+		EXPECT().
+		SaveBreakGlassActivation(gomock.Eq(models.BreakGlassActivation{Username: "emergency", ActivatedAt: s.mock.Clock.Now()})).
+		Return(nil)
+
+	FirstFactorPost(0, false)(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), nil)
+
+	userSession := s.mock.Ctx.GetSession()
+	s.Assert().Equal("emergency", userSession.Username)
+	s.Assert().Equal(authentication.OneFactor, userSession.AuthenticationLevel)
+	s.Assert().True(userSession.IsStepUpPending())
+	s.Assert().Equal(int64(1600014400), userSession.BreakGlassExpiresAt)
+
+	s.Require().Len(s.published, 1)
+	s.Assert().Equal(events.BreakGlassUsed, s.published[0].Type)
+	s.Assert().Equal("emergency", s.published[0].Username)
+	s.Assert().Equal("2020-09-13T16:26:40Z", s.published[0].Details[events.DetailExpiresAt])
+}
+
+func (s *BreakGlassSuite) TestShouldKeepWindowOpenedOnFirstUse() {
+	s.expectSuccessfulLogin()
+
+	s.mock.StorageProviderMock.
+		EXPECT().
+		LoadBreakGlassActivation(gomock.Eq("emergency")).
+		Return(&models.BreakGlassActivation{Username: "emergency", ActivatedAt: s.mock.Clock.Now().Add(-time.Hour)}, nil)
+
+	FirstFactorPost(0, false)(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), nil)
+	s.Assert().Equal(int64(1600010800), s.mock.Ctx.GetSession().BreakGlassExpiresAt)
+	s.Require().Len(s.published, 1)
+	s.Assert().Equal(events.BreakGlassUsed, s.published[0].Type)
+}
+
+func (s *BreakGlassSuite) TestShouldRefuseLoginOnceWindowElapsed() {
+	s.expectLogin()
+
+	s.mock.StorageProviderMock.
+		EXPECT().
+		LoadBreakGlassActivation(gomock.Eq("emergency")).
+		Return(&models.BreakGlassActivation{Username: "emergency", ActivatedAt: s.mock.Clock.Now().Add(-5 * time.Hour)}, nil)
+
+	s.mock.StorageProviderMock.
+		EXPECT().
+		AppendAuthenticationLog(gomock.Any()).
+		DoAndReturn(func(attempt models.AuthenticationAttempt) error {
+			s.Assert().False(attempt.Successful)
+			return nil
+		})
+
+	FirstFactorPost(0, false)(s.mock.Ctx)
+
+	s.mock.Assert401KO(s.T(), "Authentication failed. Check your credentials.")
+	s.Assert().Equal(authentication.NotAuthenticated, s.mock.Ctx.GetSession().AuthenticationLevel)
+
+	s.Require().Len(s.published, 1)
+	s.Assert().Equal(events.BreakGlassDenied, s.published[0].Type)
+	s.Assert().Equal("2020-09-13T11:26:40Z", s.published[0].Details[events.DetailExpiresAt])
+}
+
+func (s *BreakGlassSuite) TestShouldNotAuthenticateSessionOnceWindowElapsed() {
+	userSession := autheliatest.NewUserSessionBuilder("emergency").TwoFactor(s.mock.Clock.Now()).Build()
+	userSession.BreakGlassExpiresAt = s.mock.Clock.Now().Add(time.Minute).Unix()
+
+	targetURL, _ := url.ParseRequestURI("https://two-factor.example.com")
+
+	_, _, _, _, level, err := verifySessionCookie(s.mock.Ctx, targetURL, &userSession, false, 0)
+	s.Require().NoError(err)
+	s.Assert().Equal(authentication.TwoFactor, level)
+
+	s.mock.Clock.Set(s.mock.Clock.Now().Add(time.Minute))
+
+	_, _, _, _, level, err = verifySessionCookie(s.mock.Ctx, targetURL, &userSession, false, 0)
+	s.Assert().EqualError(err, "The break-glass window of user emergency has elapsed")
+	s.Assert().Equal(authentication.NotAuthenticated, level)
+}
+
+func (s *BreakGlassSuite) TestShouldRefuseBasicAuthentication() {
+	targetURL, _ := url.ParseRequestURI("https://one-factor.example.com")
+
+	_, _, _, _, _, err := verifyBasicAuth(ProxyAuthorizationHeader, []byte("Basic ZW1lcmdlbmN5OnBhc3N3b3Jk"), *targetURL, s.mock.Ctx)
+
+	s.Assert().EqualError(err, "Break-glass account emergency can't use the Proxy-Authorization header")
+}
+
+func TestRunBreakGlassSuite(t *testing.T) {
+	suite.Run(t, new(BreakGlassSuite))
+}
@@ -1,14 +1,65 @@
 package handlers
 
+import (
+	"time"
+)
+
 // TOTPRegistrationAction is the string representation of the action for which the token has been produced.
 const TOTPRegistrationAction = "RegisterTOTPDevice"
 
 // U2FRegistrationAction is the string representation of the action for which the token has been produced.
 const U2FRegistrationAction = "RegisterU2FDevice"
 
+// WebauthnRegistrationAction is the string representation of the action for which the token has been produced.
+const WebauthnRegistrationAction = "RegisterWebauthnDevice"
+
 // ResetPasswordAction is the string representation of the action for which the token has been produced.
 const ResetPasswordAction = "ResetPassword"
 
+// resetPasswordPath is the path of the portal page initiating the reset password process.
+const resetPasswordPath = "/reset-password/step1"
+
+// signOutPath is the path of the portal page signing the users out of Authelia and the OpenID Connect clients.
+const signOutPath = "/logout"
+
+// secondFactorResetApprovalPath is the path of the portal page the users approve the reset of their second factor at.
+const secondFactorResetApprovalPath = "/2fa-reset/approve"
+
+const (
+	// secondFactorResetLifespan is the time the reset of the second factor of a user can be approved for once requested.
+	secondFactorResetLifespan = 24 * time.Hour
+
+	// secondFactorResetTokenLength is the length of the token sent to the users approving the reset by email.
+	secondFactorResetTokenLength = 64
+)
+
+const (
+	// accessGrantAudience is the audience of the tokens of the access grants, it prevents other tokens signed with the
+	// JWT secret from being used as access grants.
+	accessGrantAudience = "access_grant"
+
+	// accessGrantDescriptionMaxLength is the maximum length of the description of an access grant.
+	accessGrantDescriptionMaxLength = 255
+)
+
+const (
+	deviceOneTimePassword = "one-time password device"
+	deviceSecurityKey     = "security key"
+	devicePasskey         = "passkey"
+
+	deviceWebauthnCredential = "WebAuthn credential"
+)
+
+// preferenceSecondFactorMethod is the name of the preferred second factor method in the preference events.
+const preferenceSecondFactorMethod = "second_factor_method"
+
+// The flows the users are redirected in, they're logged with the rejected redirection targets.
+const (
+	redirectionFlowLogin   = "login"
+	redirectionFlowLogout  = "logout"
+	redirectionFlowConsent = "consent"
+)
+
 const authPrefix = "Basic "
 
 // ProxyAuthorizationHeader is the basic-auth HTTP header Authelia utilises.
@@ -37,11 +88,69 @@ const (
 const operationFailedMessage = "Operation failed."
 const authenticationFailedMessage = "Authentication failed. Check your credentials."
 const userBannedMessage = "Please retry in a few minutes."
+const passwordExpiredMessage = "Your password has expired, please reset it." //nolint:gosec
+const accountDisabledMessage = "Your account is disabled."
+const accountLockedMessage = "Your account is locked out, please retry later."
 const unableToRegisterOneTimePasswordMessage = "Unable to set up one-time passwords." //nolint:gosec
 const unableToRegisterSecurityKeyMessage = "Unable to register your security key."
 const unableToResetPasswordMessage = "Unable to reset your password."
+const passwordPolicyMessage = "Your supplied password does not meet the password policy requirements."
 const mfaValidationFailedMessage = "Authentication failed, please retry later."
 
+const (
+	userEventsDefaultPerPage = 20
+	userEventsMaxPerPage     = 100
+)
+
+// userStreamKeepAliveInterval is the interval of the comments sent on the idle event streams, so the proxies don't
+// close them and the streams of the disconnected clients are released.
+const userStreamKeepAliveInterval = 15 * time.Second
+
+// userInfoLookups is the number of lookups made in parallel to load the user info.
+const userInfoLookups = 7
+
+const (
+	userInfoMethodWarning      = "Unable to load the preferred second factor method"
+	userInfoU2FWarning         = "Unable to check whether a security key is registered"
+	userInfoTOTPWarning        = "Unable to load the one-time password devices"
+	userInfoWebauthnWarning    = "Unable to check whether a WebAuthn credential is registered"
+	userInfoBackupCodesWarning = "Unable to count the remaining backup codes"
+	userInfoPushWarning        = "Unable to load the push enrollment"
+	userInfoRegulationWarning  = "Unable to load the regulation state"
+
+	adminUserDetailsWarning = "Unable to load the details of the user from the authentication backend"
+)
+
+// totpDevicesMaxCount is the maximum number of TOTP devices a user can register. Every device is tried when the user
+// authenticates so each of them increases the chances of guessing a passcode.
+const totpDevicesMaxCount = 10
+
+// loginStateNonceLength is the length of the random nonces of the signed login states, it fits in the column storing
+// them.
+const loginStateNonceLength = 32
+
+// totpDeviceIDLength is the length of the random IDs of the TOTP devices, it fits in the column storing them.
+const totpDeviceIDLength = 16
+
+// totpDescriptionMaxLength is the maximum length of the friendly name of a TOTP device, it's the size of the column
+// storing it.
+const totpDescriptionMaxLength = 64
+
+// backupCodesCount is the number of backup codes generated at once, the previous codes are replaced.
+const backupCodesCount = 10
+
+// backupCodeLength is the number of random characters of a backup code, the codes are displayed in two groups of
+// characters separated by a dash which is ignored when they're verified.
+const backupCodeLength = 10
+
+// backupCodeCharacters are the characters of the backup codes, the ones easily mistaken for one another are left out
+// since the codes are usually written down.
+var backupCodeCharacters = []rune("abcdefghjkmnpqrstuvwxyz23456789")
+
+// webauthnDescriptionMaxLength is the maximum length of the friendly name of a WebAuthn credential, it's the size of
+// the column storing it.
+const webauthnDescriptionMaxLength = 64
+
 const ldapPasswordComplexityCode = "0000052D."
 
 var ldapPasswordComplexityCodes = []string{
@@ -69,11 +178,20 @@ const (
 	oidcIntrospectPath = "/api/oidc/introspect"
 	oidcRevokePath     = "/api/oidc/revoke"
 	oidcUserinfoPath   = "/api/oidc/userinfo"
+	oidcLogoutPath     = "/api/oidc/logout"
+
+	// OIDCRegistrationPath is the path of the dynamic registration endpoint, it's only registered when the dynamic
+	// registration is enabled.
+	OIDCRegistrationPath = "/api/oidc/registration"
 
 	// Note: If you change this const you must also do so in the frontend at web/src/services/Api.ts.
 	oidcConsentPath = "/api/oidc/consent"
 )
 
+// oidcClientSecretLength is the length of the random secrets of the clients registered with the dynamic registration
+// endpoint.
+const oidcClientSecretLength = 64
+
 const (
 	accept = "accept"
 	reject = "reject"
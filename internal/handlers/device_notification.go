@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/authelia/authelia/internal/events"
+	"github.com/authelia/authelia/internal/middlewares"
+)
+
+// publishDeviceRegistration publishes the registration of a second factor device by the user of the current session,
+// the user is notified by the subscriber of the notifier.
+func publishDeviceRegistration(ctx *middlewares.AutheliaCtx, device string, replaced bool) {
+	userSession := ctx.GetSession()
+	details := deviceEventDetails(ctx, device)
+
+	details[events.DetailReplaced] = strconv.FormatBool(replaced)
+
+	ctx.PublishEvent(events.DeviceRegistered, userSession.Username, details)
+}
+
+// publishDeviceRemoval publishes the deletion of a second factor device by the user of the current session, the user
+// is notified by the subscriber of the notifier.
+func publishDeviceRemoval(ctx *middlewares.AutheliaCtx, device string) {
+	userSession := ctx.GetSession()
+
+	ctx.PublishEvent(events.DeviceRevoked, userSession.Username, deviceEventDetails(ctx, device))
+}
+
+// deviceEventDetails returns the details of the device events of the user of the current session.
+func deviceEventDetails(ctx *middlewares.AutheliaCtx, device string) map[string]string {
+	userSession := ctx.GetSession()
+	client := ctx.ClientMetadata()
+
+	details := map[string]string{
+		events.DetailDevice:   device,
+		events.DetailLocation: client.Location,
+	}
+
+	if len(userSession.Emails) != 0 {
+		details[events.DetailEmail] = userSession.Emails[0]
+	}
+
+	if !ctx.Configuration.AuthenticationBackend.DisableResetPassword {
+		if uri, err := ctx.ForwardedProtoHost(); err == nil {
+			details[events.DetailResetPasswordURL] = fmt.Sprintf("%s%s%s", uri, ctx.Configuration.Server.Path, resetPasswordPath)
+		}
+	}
+
+	return details
+}
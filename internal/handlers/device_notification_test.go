@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/pkg/autheliatest"
+)
+
+func TestShouldPublishDeviceRegistration(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	mock.Ctx.Request.Header.Set("X-Forwarded-Proto", "https")
+	mock.Ctx.Request.Header.Set("X-Forwarded-Host", "login.example.com")
+	mock.SetUserSession(t, autheliatest.NewUserSessionBuilder(testUsername).WithEmails("john@example.com").Build())
+
+	var body string
+
+	mock.NotifierMock.EXPECT().
+		Send(gomock.Eq("john@example.com"), gomock.Eq("New security key registered"), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_, _, b, _ string) error {
+			body = b
+			return nil
+		})
+
+	publishDeviceRegistration(mock.Ctx, deviceSecurityKey, true)
+
+	assert.Contains(t, body, "A new security key has been registered on your account. It replaces the security key previously registered")
+	assert.Contains(t, body, "https://login.example.com/reset-password/step1")
+}
+
+func TestShouldPublishDeviceRegistrationWithoutResetPasswordLink(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	mock.Ctx.Configuration.AuthenticationBackend.DisableResetPassword = true
+	mock.SetUserSession(t, autheliatest.NewUserSessionBuilder(testUsername).WithEmails("john@example.com").Build())
+
+	var body, htmlBody string
+
+	mock.NotifierMock.EXPECT().
+		Send(gomock.Eq("john@example.com"), gomock.Eq("New passkey registered"), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_, _, b, h string) error {
+			body, htmlBody = b, h
+			return nil
+		})
+
+	publishDeviceRegistration(mock.Ctx, devicePasskey, false)
+
+	assert.Contains(t, body, "A new passkey has been registered on your account. If you did not register it")
+	assert.NotContains(t, body, "reset-password")
+	assert.NotContains(t, htmlBody, "class=\"button\"")
+}
+
+func TestShouldNotNotifyDeviceRegistrationWithoutEmail(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	mock.SetUserSession(t, autheliatest.NewUserSessionBuilder(testUsername).Build())
+
+	// The notifier mock fails the test if a notification is sent.
+	publishDeviceRegistration(mock.Ctx, deviceOneTimePassword, false)
+}
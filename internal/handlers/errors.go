@@ -11,3 +11,5 @@ const UnauthorizedError = "You're not authorized."
 
 var errMissingXForwardedHost = errors.New("Missing header X-Forwarded-Host")
 var errMissingXForwardedProto = errors.New("Missing header X-Forwarded-Proto")
+var errMissingExternalURL = errors.New("The server external url must be configured to use WebAuthn")
+var errSessionRevoked = errors.New("Sessions of the user have been revoked")
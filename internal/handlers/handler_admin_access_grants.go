@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/authelia/authelia/internal/middlewares"
+)
+
+// AdminAccessGrantsPost handler creating an access grant to a resource for a guest on behalf of the admin API token.
+// The rule matching the guest must permit the grants. It requires the access_grants.manage admin permission.
+func AdminAccessGrantsPost(ctx *middlewares.AutheliaCtx) {
+	caller := ctx.AdminCaller()
+
+	var requestBody accessGrantRequestBody
+
+	if err := ctx.ParseBody(&requestBody); err != nil {
+		ctx.Error(fmt.Errorf("Unable to parse the access grant requested by %s: %s", caller, err), operationFailedMessage)
+		return
+	}
+
+	targetURL, err := parseAccessGrantTargetURL(ctx, requestBody.TargetURL)
+	if err != nil {
+		ctx.Error(err, operationFailedMessage)
+		return
+	}
+
+	createAccessGrant(ctx, caller, targetURL, requestBody)
+}
+
+// AdminAccessGrantsGet handler returning the unexpired access grants of all the users and admin API tokens. It
+// requires the access_grants.read admin permission.
+func AdminAccessGrantsGet(ctx *middlewares.AutheliaCtx) {
+	grants, err := ctx.Providers.StorageProvider.LoadAccessGrants(ctx.Clock.Now())
+	if err != nil {
+		ctx.Error(fmt.Errorf("Unable to load the access grants: %s", err), operationFailedMessage)
+		return
+	}
+
+	if err = ctx.SetJSONBody(newAccessGrants(grants)); err != nil {
+		ctx.Logger.Errorf("Unable to set the access grants response in body: %s", err)
+	}
+}
+
+// AdminAccessGrantDelete handler revoking the access grant whose ID is in the path. It requires the
+// access_grants.manage admin permission.
+func AdminAccessGrantDelete(ctx *middlewares.AutheliaCtx) {
+	grant, ok := loadAccessGrant(ctx)
+	if !ok {
+		return
+	}
+
+	if revokeAccessGrant(ctx, grant, ctx.AdminCaller()) {
+		ctx.ReplyOK()
+	}
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/authelia/authelia/internal/events"
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/pkg/autheliatest"
+)
+
+type AdminAccessGrantsSuite struct {
+	suite.Suite
+
+	mock      *mocks.MockAutheliaCtx
+	storage   *autheliatest.MemoryStorageProvider
+	published []events.Event
+}
+
+func (s *AdminAccessGrantsSuite) SetupTest() {
+	s.mock = mocks.NewMockAutheliaCtxWithConfiguration(s.T(), newAccessGrantsConfiguration())
+	s.mock.Clock.Set(time.Unix(1600000000, 0))
+	s.mock.Ctx.Clock = &s.mock.Clock
+	s.mock.Ctx.Configuration.Server.AdminGroup = "admins"
+	s.mock.Ctx.Configuration.Server.AdminRoles.Auditor = []string{"audit"}
+
+	s.storage = autheliatest.NewMemoryStorageProvider()
+	s.mock.Ctx.Providers.StorageProvider = s.storage
+
+	s.published = nil
+	s.mock.Ctx.Providers.Events.Subscribe(func(event events.Event) {
+		s.published = append(s.published, event)
+	})
+
+	s.Require().NoError(s.storage.SaveAccessGrant(models.AccessGrant{
+		ID:        "grant-1",
+		Username:  "john",
+		TargetURL: "https://share.example.com/",
+		CreatedAt: time.Unix(1600000000-60, 0),
+		ExpiresAt: time.Unix(1600000000+3600, 0),
+	}))
+	s.Require().NoError(s.storage.SaveAccessGrant(models.AccessGrant{
+		ID:        "grant-2",
+		Username:  "bob",
+		TargetURL: "https://share.example.com/old",
+		CreatedAt: time.Unix(1600000000-7200, 0),
+		ExpiresAt: time.Unix(1600000000-3600, 0),
+	}))
+}
+
+func (s *AdminAccessGrantsSuite) TearDownTest() {
+	s.mock.Close()
+}
+
+func (s *AdminAccessGrantsSuite) loginAs(username string, groups ...string) {
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder(username).WithGroups(groups...).TwoFactor(s.mock.Clock.Now()).Build())
+	s.mock.Ctx.Response.Reset()
+}
+
+func (s *AdminAccessGrantsSuite) TestShouldCreateAccessGrantOnBehalfOfAdmin() {
+	s.loginAs("harry", "admins")
+	s.mock.Ctx.Request.SetBodyString(`{"target_url":"https://share.example.com/reports","lifespan":"2h"}`)
+
+	middlewares.RequireAdminPermission(models.AdminPermissionAccessGrantsManage)(AdminAccessGrantsPost)(s.mock.Ctx)
+	s.Require().Equal(200, s.mock.Ctx.Response.StatusCode())
+
+	var grant AccessGrant
+
+	s.mock.GetResponseData(s.T(), &grant)
+	s.Assert().Equal("harry", grant.CreatedBy)
+	s.Assert().Equal(int64(1600000000+7200), grant.ExpiresAt)
+	s.Assert().NotEmpty(grant.URL)
+
+	saved, err := s.storage.LoadAccessGrant(grant.ID)
+	s.Require().NoError(err)
+	s.Assert().Equal("https://share.example.com/reports", saved.TargetURL)
+}
+
+func (s *AdminAccessGrantsSuite) TestShouldForbidAccessGrantNotPermittedByRule() {
+	s.loginAs("harry", "admins")
+	s.mock.Ctx.Request.SetBodyString(`{"target_url":"https://private.example.com/"}`)
+
+	middlewares.RequireAdminPermission(models.AdminPermissionAccessGrantsManage)(AdminAccessGrantsPost)(s.mock.Ctx)
+
+	s.Assert().Equal(403, s.mock.Ctx.Response.StatusCode())
+}
+
+func (s *AdminAccessGrantsSuite) TestShouldListUnexpiredAccessGrants() {
+	s.loginAs("alice", "audit")
+
+	middlewares.RequireAdminPermission(models.AdminPermissionAccessGrantsRead)(AdminAccessGrantsGet)(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), []AccessGrant{{
+		ID:        "grant-1",
+		CreatedBy: "john",
+		TargetURL: "https://share.example.com/",
+		CreatedAt: 1600000000 - 60,
+		ExpiresAt: 1600000000 + 3600,
+	}})
+}
+
+func (s *AdminAccessGrantsSuite) TestShouldRevokeAccessGrantOfUser() {
+	s.loginAs("harry", "admins")
+	s.mock.Ctx.SetUserValue("id", "grant-1")
+
+	middlewares.RequireAdminPermission(models.AdminPermissionAccessGrantsManage)(AdminAccessGrantDelete)(s.mock.Ctx)
+	s.mock.Assert200OK(s.T(), nil)
+
+	_, err := s.storage.LoadAccessGrant("grant-1")
+	s.Assert().Error(err)
+
+	s.Require().Len(s.published, 1)
+	s.Assert().Equal(events.AccessGrantRevoked, s.published[0].Type)
+	s.Assert().Equal("john", s.published[0].Username)
+	s.Assert().Equal("harry", s.published[0].Details[events.DetailActor])
+}
+
+func (s *AdminAccessGrantsSuite) TestShouldForbidAuditorToRevokeAccessGrant() {
+	s.loginAs("alice", "audit")
+	s.mock.Ctx.SetUserValue("id", "grant-1")
+
+	middlewares.RequireAdminPermission(models.AdminPermissionAccessGrantsManage)(AdminAccessGrantDelete)(s.mock.Ctx)
+
+	s.Assert().Equal(403, s.mock.Ctx.Response.StatusCode())
+
+	_, err := s.storage.LoadAccessGrant("grant-1")
+	s.Assert().NoError(err)
+}
+
+func TestRunAdminAccessGrantsSuite(t *testing.T) {
+	suite.Run(t, new(AdminAccessGrantsSuite))
+}
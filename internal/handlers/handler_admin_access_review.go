@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/review"
+)
+
+// AdminAccessReviewGet returns the access review report of the users, as JSON or as CSV when the format query
+// parameter is csv. It requires the users.read admin permission.
+func AdminAccessReviewGet(ctx *middlewares.AutheliaCtx) {
+	format := string(ctx.QueryArgs().Peek("format"))
+
+	switch format {
+	case "":
+		format = review.FormatJSON
+	case review.FormatJSON, review.FormatCSV:
+	default:
+		ctx.Error(fmt.Errorf("Invalid access review format '%s'", format), operationFailedMessage)
+		return
+	}
+
+	report, err := review.NewReport(ctx.Providers.StorageProvider, ctx.Providers.UserProvider, ctx.Providers.Authorizer, ctx.Clock.Now())
+	if err != nil {
+		ctx.Error(fmt.Errorf("Unable to generate the access review report: %s", err), operationFailedMessage)
+		return
+	}
+
+	ctx.Logger.Infof("Access review report of %d users has been exported by %s", len(report.Users), ctx.AdminCaller())
+
+	if format == review.FormatJSON {
+		if err = ctx.SetJSONBody(report); err != nil {
+			ctx.Logger.Errorf("Unable to set access review response in body: %s", err)
+		}
+
+		return
+	}
+
+	ctx.SetContentType("text/csv; charset=utf-8")
+	ctx.Response.Header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"access-review-%s.csv\"", report.GeneratedAt.UTC().Format("20060102")))
+
+	if err = report.WriteCSV(ctx); err != nil {
+		ctx.Logger.Errorf("Unable to write access review response in body: %s", err)
+	}
+}
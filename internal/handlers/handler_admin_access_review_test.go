@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/authorization"
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/review"
+	"github.com/authelia/authelia/pkg/autheliatest"
+)
+
+type AdminAccessReviewSuite struct {
+	suite.Suite
+
+	mock    *mocks.MockAutheliaCtx
+	storage *autheliatest.MemoryStorageProvider
+}
+
+func (s *AdminAccessReviewSuite) SetupTest() {
+	s.mock = mocks.NewMockAutheliaCtx(s.T())
+	s.mock.Clock.Set(time.Unix(1600000000, 0))
+	s.mock.Ctx.Clock = &s.mock.Clock
+	s.mock.Ctx.Configuration.Server.AdminGroup = "admins"
+
+	s.storage = autheliatest.NewMemoryStorageProvider()
+	s.mock.Ctx.Providers.StorageProvider = s.storage
+	s.mock.Ctx.Providers.Authorizer = authorization.NewAuthorizer(&schema.Configuration{
+		AccessControl: schema.AccessControlConfiguration{
+			DefaultPolicy: "deny",
+			Rules: []schema.ACLRule{
+				{Domains: []string{"admin.example.com"}, Policy: "two_factor", Subjects: [][]string{{"group:admins"}}},
+			},
+		},
+	})
+
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder(testUsername).WithGroups("admins").TwoFactor(s.mock.Clock.Now()).Build())
+
+	s.Require().NoError(s.storage.SaveTOTPDevice(models.TOTPDevice{ID: "phone", Username: "harry", Description: "Phone"}))
+
+	s.mock.UserProviderMock.EXPECT().
+		GetDetails("harry").
+		Return(&authentication.UserDetails{Username: "harry", DisplayName: "Harry Potter", Groups: []string{"admins"}}, nil)
+}
+
+func (s *AdminAccessReviewSuite) TearDownTest() {
+	s.mock.Close()
+}
+
+func (s *AdminAccessReviewSuite) TestShouldGetAccessReviewAsJSON() {
+	middlewares.RequireAdminPermission(models.AdminPermissionUsersRead)(AdminAccessReviewGet)(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), review.Report{
+		GeneratedAt: time.Unix(1600000000, 0),
+		Users: []review.User{
+			{
+				Username:    "harry",
+				DisplayName: "Harry Potter",
+				Groups:      []string{"admins"},
+				Factors:     []string{authentication.TOTP},
+				Rules:       []review.Rule{{Position: 1, Domains: []string{"admin.example.com"}, Policy: "two_factor"}},
+			},
+		},
+	})
+	s.Assert().Equal("Access review report of 1 users has been exported by john", s.mock.Hook.LastEntry().Message)
+}
+
+func (s *AdminAccessReviewSuite) TestShouldGetAccessReviewAsCSV() {
+	s.mock.Ctx.QueryArgs().Add("format", "csv")
+
+	middlewares.RequireAdminPermission(models.AdminPermissionUsersRead)(AdminAccessReviewGet)(s.mock.Ctx)
+
+	s.Assert().Equal(200, s.mock.Ctx.Response.StatusCode())
+	s.Assert().Equal("text/csv; charset=utf-8", string(s.mock.Ctx.Response.Header.ContentType()))
+	s.Assert().Equal("attachment; filename=\"access-review-20200913.csv\"", string(s.mock.Ctx.Response.Header.Peek("Content-Disposition")))
+	s.Assert().Equal("username,display_name,emails,groups,deleted,factors,last_login,rules\n"+
+		"harry,Harry Potter,,admins,false,totp,,1:two_factor:admin.example.com\n", string(s.mock.Ctx.Response.Body()))
+}
+
+func TestRunAdminAccessReviewSuite(t *testing.T) {
+	s := new(AdminAccessReviewSuite)
+	suite.Run(t, s)
+}
+
+func TestShouldRejectUnknownAccessReviewFormat(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	mock.Ctx.QueryArgs().Add("format", "xml")
+
+	AdminAccessReviewGet(mock.Ctx)
+
+	mock.Assert200KO(t, operationFailedMessage)
+	assert.Equal(t, "Invalid access review format 'xml'", mock.Hook.LastEntry().Message)
+}
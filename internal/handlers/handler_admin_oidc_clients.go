@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/storage"
+)
+
+// AdminOIDCClientsGet handler listing the OpenID Connect clients registered with the dynamic registration endpoint. It
+// requires the oidc_clients.read admin permission.
+func AdminOIDCClientsGet(ctx *middlewares.AutheliaCtx) {
+	clients, err := ctx.Providers.StorageProvider.LoadOIDCClients()
+	if err != nil {
+		ctx.Error(fmt.Errorf("Unable to load the registered OpenID Connect clients: %s", err), operationFailedMessage)
+		return
+	}
+
+	body := make([]AdminOIDCClient, 0, len(clients))
+
+	for _, client := range clients {
+		body = append(body, AdminOIDCClient{
+			ID:                     client.ID,
+			Description:            client.Description,
+			Policy:                 client.Policy,
+			RedirectURIs:           client.RedirectURIs,
+			GrantTypes:             client.GrantTypes,
+			ResponseTypes:          client.ResponseTypes,
+			Scopes:                 client.Scopes,
+			PostLogoutRedirectURIs: client.PostLogoutRedirectURIs,
+			BackChannelLogoutURI:   client.BackChannelLogoutURI,
+			FrontChannelLogoutURI:  client.FrontChannelLogoutURI,
+			RegisteredBy:           client.RegisteredBy,
+			CreatedAt:              client.CreatedAt.Unix(),
+		})
+	}
+
+	if err = ctx.SetJSONBody(body); err != nil {
+		ctx.Logger.Errorf("Unable to set the registered OpenID Connect clients response in body: %s", err)
+	}
+}
+
+// AdminOIDCClientDelete handler deleting the registered OpenID Connect client whose ID is in the path, the tokens
+// already issued to the client are no longer accepted once it's deleted. It requires the oidc_clients.delete admin
+// permission.
+func AdminOIDCClientDelete(ctx *middlewares.AutheliaCtx) {
+	id, _ := ctx.UserValue("id").(string)
+
+	if id == "" {
+		ctx.Error(fmt.Errorf("No OpenID Connect client ID provided"), operationFailedMessage)
+		return
+	}
+
+	err := ctx.Providers.StorageProvider.DeleteOIDCClient(id)
+
+	switch {
+	case errors.Is(err, storage.ErrNoOIDCClient):
+		ctx.Error(fmt.Errorf("OpenID Connect client %s is not a registered client", id), operationFailedMessage)
+		return
+	case err != nil:
+		ctx.Error(fmt.Errorf("Unable to delete the OpenID Connect client %s: %s", id, err), operationFailedMessage)
+		return
+	}
+
+	if ctx.Providers.OpenIDConnect.Store != nil {
+		ctx.Providers.OpenIDConnect.Store.DeleteRegisteredClient(id)
+	}
+
+	ctx.Logger.Infof("OpenID Connect client %s has been deleted by %s", id, ctx.AdminCaller())
+
+	ctx.ReplyOK()
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/authelia/authelia/internal/middlewares"
+)
+
+// AdminOIDCKeysGet handler listing the OpenID Connect issuer keys and the active one. It requires the oidc_keys.read
+// admin permission.
+func AdminOIDCKeysGet(ctx *middlewares.AutheliaCtx) {
+	manager := ctx.Providers.OpenIDConnect.KeyManager
+	if manager == nil {
+		ctx.Error(fmt.Errorf("OpenID Connect is not enabled"), operationFailedMessage)
+		return
+	}
+
+	body := AdminOIDCKeysBody{ActiveKeyID: manager.GetActiveKeyID(), KeyIDs: []string{}}
+
+	for _, key := range manager.GetKeySet().Keys {
+		body.KeyIDs = append(body.KeyIDs, key.KeyID)
+	}
+
+	if err := ctx.SetJSONBody(body); err != nil {
+		ctx.Logger.Errorf("Unable to set the OpenID Connect keys response in body: %s", err)
+	}
+}
+
+// AdminOIDCKeyRotatePost handler making the next OpenID Connect issuer key, or the one requested, the active key
+// without a restart. It requires the oidc_keys.rotate admin permission.
+func AdminOIDCKeyRotatePost(ctx *middlewares.AutheliaCtx) {
+	manager := ctx.Providers.OpenIDConnect.KeyManager
+	if manager == nil {
+		ctx.Error(fmt.Errorf("OpenID Connect is not enabled"), operationFailedMessage)
+		return
+	}
+
+	var (
+		body adminOIDCKeyRotateRequestBody
+		err  error
+	)
+
+	if len(ctx.PostBody()) != 0 {
+		if err = ctx.ParseBody(&body); err != nil {
+			ctx.Error(err, operationFailedMessage)
+			return
+		}
+	}
+
+	if body.KeyID != "" {
+		err = manager.SetActiveKey(body.KeyID)
+	} else {
+		body.KeyID, err = manager.RotateActiveKey()
+	}
+
+	if err != nil {
+		ctx.Error(fmt.Errorf("Unable to rotate the OpenID Connect issuer key: %s", err), operationFailedMessage)
+		return
+	}
+
+	ctx.Logger.Infof("OpenID Connect issuer key %s has been made active by %s", body.KeyID, ctx.AdminCaller())
+
+	if err = ctx.SetJSONBody(AdminOIDCKeyRotateBody{KeyID: body.KeyID}); err != nil {
+		ctx.Logger.Errorf("Unable to set the key rotation response in body: %s", err)
+	}
+}
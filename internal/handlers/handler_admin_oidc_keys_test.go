@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/oidc"
+	"github.com/authelia/authelia/pkg/autheliatest"
+)
+
+type AdminOIDCKeyRotateSuite struct {
+	suite.Suite
+
+	mock *mocks.MockAutheliaCtx
+
+	keyManager *oidc.KeyManager
+	keyIDs     []string
+}
+
+func (s *AdminOIDCKeyRotateSuite) SetupTest() {
+	s.mock = mocks.NewMockAutheliaCtx(s.T())
+	s.mock.Clock.Set(time.Unix(1600000000, 0))
+	s.mock.Ctx.Clock = &s.mock.Clock
+	s.mock.Ctx.Configuration.Server.AdminGroup = "admins"
+
+	s.keyManager = oidc.NewKeyManager()
+	s.keyIDs = nil
+
+	for i := 0; i < 3; i++ {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		s.Require().NoError(err)
+
+		webKey, err := s.keyManager.AddPrivateKey(key)
+		s.Require().NoError(err)
+
+		s.keyIDs = append(s.keyIDs, webKey.KeyID)
+	}
+
+	s.Require().NoError(s.keyManager.SetActiveKey(s.keyIDs[0]))
+
+	s.mock.Ctx.Providers.OpenIDConnect.KeyManager = s.keyManager
+}
+
+func (s *AdminOIDCKeyRotateSuite) TearDownTest() {
+	s.mock.Close()
+}
+
+func (s *AdminOIDCKeyRotateSuite) TestShouldListKeys() {
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder(testUsername).WithGroups("admins").TwoFactor(s.mock.Clock.Now()).Build())
+
+	middlewares.RequireAdminPermission(models.AdminPermissionOIDCKeysRead)(AdminOIDCKeysGet)(s.mock.Ctx)
+
+	var body AdminOIDCKeysBody
+
+	s.mock.GetResponseData(s.T(), &body)
+	s.Assert().Equal(s.keyIDs[0], body.ActiveKeyID)
+	s.Assert().ElementsMatch(s.keyIDs, body.KeyIDs)
+}
+
+func (s *AdminOIDCKeyRotateSuite) TestShouldRotateToTheNextKey() {
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder(testUsername).WithGroups("admins").TwoFactor(s.mock.Clock.Now()).Build())
+
+	middlewares.RequireAdminPermission(models.AdminPermissionOIDCKeysRotate)(AdminOIDCKeyRotatePost)(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), AdminOIDCKeyRotateBody{KeyID: s.keyIDs[1]})
+	s.Assert().Equal(s.keyIDs[1], s.keyManager.GetActiveKeyID())
+}
+
+func (s *AdminOIDCKeyRotateSuite) TestShouldRotateToTheRequestedKey() {
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder(testUsername).WithGroups("admins").TwoFactor(s.mock.Clock.Now()).Build())
+	s.mock.Ctx.Request.SetBodyString(`{"key_id":"` + s.keyIDs[2] + `"}`)
+
+	middlewares.RequireAdminPermission(models.AdminPermissionOIDCKeysRotate)(AdminOIDCKeyRotatePost)(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), AdminOIDCKeyRotateBody{KeyID: s.keyIDs[2]})
+	s.Assert().Equal(s.keyIDs[2], s.keyManager.GetActiveKeyID())
+	s.Assert().Len(s.keyManager.GetKeySet().Keys, 3)
+}
+
+func (s *AdminOIDCKeyRotateSuite) TestShouldFailWhenKeyDoesNotExist() {
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder(testUsername).WithGroups("admins").TwoFactor(s.mock.Clock.Now()).Build())
+	s.mock.Ctx.Request.SetBodyString(`{"key_id":"abcdef"}`)
+
+	middlewares.RequireAdminPermission(models.AdminPermissionOIDCKeysRotate)(AdminOIDCKeyRotatePost)(s.mock.Ctx)
+
+	s.mock.Assert200KO(s.T(), "Operation failed.")
+	s.Assert().Equal("Unable to rotate the OpenID Connect issuer key: key id abcdef does not exist", s.mock.Hook.LastEntry().Message)
+	s.Assert().Equal(s.keyIDs[0], s.keyManager.GetActiveKeyID())
+}
+
+func (s *AdminOIDCKeyRotateSuite) TestShouldForbidUserNotInAdminGroup() {
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder(testUsername).WithGroups("dev").TwoFactor(s.mock.Clock.Now()).Build())
+
+	middlewares.RequireAdminPermission(models.AdminPermissionOIDCKeysRotate)(AdminOIDCKeyRotatePost)(s.mock.Ctx)
+
+	s.Assert().Equal(403, s.mock.Ctx.Response.StatusCode())
+	s.Assert().Equal(s.keyIDs[0], s.keyManager.GetActiveKeyID())
+}
+
+func (s *AdminOIDCKeyRotateSuite) TestShouldForbidAdminWithoutSecondFactor() {
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder(testUsername).WithGroups("admins").OneFactor(s.mock.Clock.Now()).Build())
+
+	middlewares.RequireAdminPermission(models.AdminPermissionOIDCKeysRotate)(AdminOIDCKeyRotatePost)(s.mock.Ctx)
+
+	s.Assert().Equal(403, s.mock.Ctx.Response.StatusCode())
+	s.Assert().Equal(s.keyIDs[0], s.keyManager.GetActiveKeyID())
+}
+
+func TestRunAdminOIDCKeyRotateSuite(t *testing.T) {
+	suite.Run(t, new(AdminOIDCKeyRotateSuite))
+}
@@ -0,0 +1,329 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"fmt"
+	"html/template"
+	"net/url"
+
+	"github.com/google/uuid"
+
+	"github.com/authelia/authelia/internal/events"
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/notification"
+	"github.com/authelia/authelia/internal/storage"
+	"github.com/authelia/authelia/internal/templates"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// AdminSecondFactorResetPost handler requesting the reset of the second factor devices of the user given in the path.
+// The devices are only deleted once another operator, or the user following the link sent by email, approved it so a
+// single operator can't take over an account. It requires the second_factor_resets.request admin permission.
+func AdminSecondFactorResetPost(ctx *middlewares.AutheliaCtx) {
+	caller, username, ok := adminUserChange(ctx)
+	if !ok {
+		return
+	}
+
+	var requestBody adminSecondFactorResetRequestBody
+
+	if err := ctx.ParseBody(&requestBody); err != nil {
+		ctx.Error(fmt.Errorf("Unable to parse the second factor reset of user %s: %s", username, err), operationFailedMessage)
+		return
+	}
+
+	switch requestBody.Approval {
+	case "":
+		requestBody.Approval = models.SecondFactorResetApprovalAdmin
+	case models.SecondFactorResetApprovalAdmin:
+	case models.SecondFactorResetApprovalUser:
+		// The link sent to the user is built from the configuration, the forwarded headers of the request can be forged.
+		if ctx.Configuration.Server.ExternalURL == "" {
+			ctx.Error(fmt.Errorf("The second factor reset of user %s can't be approved by the user without the server external url", username), operationFailedMessage)
+			return
+		}
+	default:
+		ctx.Error(fmt.Errorf("Invalid second factor reset approval '%s'", requestBody.Approval), operationFailedMessage)
+		return
+	}
+
+	now := ctx.Clock.Now()
+	reset := models.SecondFactorReset{
+		ID:          uuid.New().String(),
+		Username:    username,
+		RequestedBy: caller,
+		Reason:      requestBody.Reason,
+		Approval:    requestBody.Approval,
+		Status:      models.SecondFactorResetPending,
+		RequestedAt: now,
+		ExpiresAt:   now.Add(secondFactorResetLifespan),
+	}
+
+	var email, token string
+
+	if reset.Approval == models.SecondFactorResetApprovalUser {
+		details, err := ctx.Providers.UserProvider.GetDetails(username)
+		if err != nil {
+			ctx.Error(fmt.Errorf("Unable to load the details of user %s: %s", username, err), operationFailedMessage)
+			return
+		}
+
+		if len(details.Emails) == 0 {
+			ctx.Error(fmt.Errorf("User %s does not have any email address to approve the second factor reset", username), operationFailedMessage)
+			return
+		}
+
+		email = details.Emails[0]
+		token = utils.RandomString(secondFactorResetTokenLength, utils.AlphaNumericCharacters)
+		reset.ApprovalTokenHash = utils.HashSHA256FromString(token)
+	}
+
+	if err := ctx.Providers.StorageProvider.SaveSecondFactorReset(reset); err != nil {
+		ctx.Error(fmt.Errorf("Unable to save the second factor reset of user %s: %s", username, err), operationFailedMessage)
+		return
+	}
+
+	if token != "" {
+		if err := sendSecondFactorResetApproval(ctx, reset, email, token); err != nil {
+			// The reset can't be approved without the email, it's rejected so it doesn't stay pending.
+			if rejectErr := ctx.Providers.StorageProvider.RejectSecondFactorReset(reset.ID, username, caller, now); rejectErr != nil {
+				ctx.Logger.Errorf("Unable to reject the second factor reset %s of user %s: %s", reset.ID, username, rejectErr)
+			}
+
+			ctx.Error(fmt.Errorf("Unable to send the second factor reset approval to user %s: %s", username, err), operationFailedMessage)
+
+			return
+		}
+	}
+
+	ctx.Logger.Infof("Second factor reset %s of user %s has been requested by %s, it awaits the approval of the %s",
+		reset.ID, username, caller, reset.Approval)
+
+	publishSecondFactorReset(ctx, events.SecondFactorResetRequested, reset, caller)
+
+	if err := ctx.SetJSONBody(newAdminSecondFactorReset(reset)); err != nil {
+		ctx.Logger.Errorf("Unable to set second factor reset response in body: %s", err)
+	}
+}
+
+// AdminSecondFactorResetsGet returns the second factor resets awaiting the approval, the oldest first. It requires the
+// users.read admin permission.
+func AdminSecondFactorResetsGet(ctx *middlewares.AutheliaCtx) {
+	resets, err := ctx.Providers.StorageProvider.LoadPendingSecondFactorResets(ctx.Clock.Now())
+	if err != nil {
+		ctx.Error(fmt.Errorf("Unable to load the pending second factor resets: %s", err), operationFailedMessage)
+		return
+	}
+
+	body := make([]AdminSecondFactorReset, 0, len(resets))
+
+	for _, reset := range resets {
+		body = append(body, newAdminSecondFactorReset(reset))
+	}
+
+	if err = ctx.SetJSONBody(body); err != nil {
+		ctx.Logger.Errorf("Unable to set second factor resets response in body: %s", err)
+	}
+}
+
+// AdminSecondFactorResetApprovePost handler approving the second factor reset given in the path and deleting the
+// second factor devices of the user. The reset must be approved by another operator than the one who requested it. It
+// requires the second_factor_resets.approve admin permission, which is never granted to the admin API tokens.
+func AdminSecondFactorResetApprovePost(ctx *middlewares.AutheliaCtx) {
+	caller := ctx.AdminCaller()
+
+	reset, ok := loadPendingSecondFactorReset(ctx, ctx.UserValue("id"))
+	if !ok {
+		return
+	}
+
+	if reset.Approval != models.SecondFactorResetApprovalAdmin {
+		ctx.Error(fmt.Errorf("Second factor reset %s of user %s must be approved by the user", reset.ID, reset.Username), operationFailedMessage)
+		return
+	}
+
+	if reset.RequestedBy == caller {
+		ctx.Logger.Warnf("Second factor reset %s of user %s can't be approved by %s who requested it", reset.ID, reset.Username, caller)
+		ctx.ReplyForbidden()
+
+		return
+	}
+
+	if approveSecondFactorReset(ctx, reset, caller) {
+		ctx.ReplyOK()
+	}
+}
+
+// AdminSecondFactorResetRejectPost handler rejecting the second factor reset given in the path, e.g. when it has been
+// requested by mistake. It requires the second_factor_resets.request admin permission.
+func AdminSecondFactorResetRejectPost(ctx *middlewares.AutheliaCtx) {
+	caller := ctx.AdminCaller()
+
+	reset, ok := loadPendingSecondFactorReset(ctx, ctx.UserValue("id"))
+	if !ok {
+		return
+	}
+
+	err := ctx.Providers.StorageProvider.RejectSecondFactorReset(reset.ID, reset.Username, caller, ctx.Clock.Now())
+	if err != nil {
+		ctx.Error(fmt.Errorf("Unable to reject the second factor reset %s of user %s: %s", reset.ID, reset.Username, err), operationFailedMessage)
+		return
+	}
+
+	ctx.Logger.Infof("Second factor reset %s of user %s has been rejected by %s", reset.ID, reset.Username, caller)
+
+	publishSecondFactorReset(ctx, events.SecondFactorResetRejected, *reset, caller)
+
+	ctx.ReplyOK()
+}
+
+// SecondFactorResetApprovalPost handler approving the reset of the second factor devices of the user with the token
+// sent by email when an operator requested it. The user must have completed the first factor.
+func SecondFactorResetApprovalPost(ctx *middlewares.AutheliaCtx) {
+	var requestBody secondFactorResetApprovalBody
+
+	if err := ctx.ParseBody(&requestBody); err != nil {
+		ctx.Error(err, operationFailedMessage)
+		return
+	}
+
+	username := ctx.GetSession().Username
+
+	reset, ok := loadPendingSecondFactorReset(ctx, requestBody.ID)
+	if !ok {
+		return
+	}
+
+	if reset.Approval != models.SecondFactorResetApprovalUser || reset.Username != username ||
+		subtle.ConstantTimeCompare([]byte(utils.HashSHA256FromString(requestBody.Token)), []byte(reset.ApprovalTokenHash)) != 1 {
+		ctx.Error(fmt.Errorf("User %s provided an invalid token to approve the second factor reset %s", username, reset.ID), operationFailedMessage)
+		return
+	}
+
+	if approveSecondFactorReset(ctx, reset, username) {
+		ctx.ReplyOK()
+	}
+}
+
+// loadPendingSecondFactorReset returns the second factor reset with the given ID, it replies with an error when the
+// reset is unknown or can't be approved anymore.
+func loadPendingSecondFactorReset(ctx *middlewares.AutheliaCtx, value interface{}) (reset *models.SecondFactorReset, ok bool) {
+	id, _ := value.(string)
+
+	if id == "" {
+		ctx.Error(fmt.Errorf("No second factor reset provided"), operationFailedMessage)
+		return nil, false
+	}
+
+	reset, err := ctx.Providers.StorageProvider.LoadSecondFactorReset(id)
+	if err != nil {
+		ctx.Error(fmt.Errorf("Unable to load the second factor reset %s: %s", id, err), operationFailedMessage)
+		return nil, false
+	}
+
+	if !reset.IsPending(ctx.Clock.Now()) {
+		ctx.Error(fmt.Errorf("Second factor reset %s of user %s is not pending anymore", id, reset.Username), operationFailedMessage)
+		return nil, false
+	}
+
+	return reset, true
+}
+
+// approveSecondFactorReset approves the reset and deletes the second factor devices of the user, it replies with an
+// error when the reset couldn't be approved, e.g. because it has been approved or rejected concurrently.
+func approveSecondFactorReset(ctx *middlewares.AutheliaCtx, reset *models.SecondFactorReset, approvedBy string) bool {
+	err := ctx.Providers.StorageProvider.ApproveSecondFactorReset(reset.ID, reset.Username, approvedBy, ctx.Clock.Now())
+
+	switch {
+	case err == storage.ErrNoSecondFactorReset:
+		ctx.Error(fmt.Errorf("Second factor reset %s of user %s is not pending anymore", reset.ID, reset.Username), operationFailedMessage)
+		return false
+	case err != nil:
+		ctx.Error(fmt.Errorf("Unable to approve the second factor reset %s of user %s: %s", reset.ID, reset.Username, err), operationFailedMessage)
+		return false
+	}
+
+	ctx.Logger.Infof("Second factor reset %s of user %s requested by %s has been approved by %s, the second factor devices of the user have been deleted",
+		reset.ID, reset.Username, reset.RequestedBy, approvedBy)
+
+	appendUserEvent(ctx, reset.Username, models.UserEventSecondFactorReset)
+	publishSecondFactorReset(ctx, events.SecondFactorResetApproved, *reset, approvedBy)
+
+	return true
+}
+
+func publishSecondFactorReset(ctx *middlewares.AutheliaCtx, eventType events.Type, reset models.SecondFactorReset, actor string) {
+	details := map[string]string{
+		events.DetailResetID:     reset.ID,
+		events.DetailRequestedBy: reset.RequestedBy,
+		events.DetailApproval:    reset.Approval,
+	}
+
+	if eventType == events.SecondFactorResetRequested {
+		details[events.DetailReason] = reset.Reason
+	}
+
+	if actor != reset.Username {
+		details[events.DetailActor] = actor
+	}
+
+	ctx.PublishEvent(eventType, reset.Username, details)
+}
+
+// sendSecondFactorResetApproval sends the link to approve the second factor reset to the user.
+func sendSecondFactorResetApproval(ctx *middlewares.AutheliaCtx, reset models.SecondFactorReset, email, token string) error {
+	title := "Approve the reset of your second factor"
+	body := fmt.Sprintf("An operator requested the reset of the second factor devices of your account for the following reason: %s. "+
+		"Your devices are only deleted once you approve it, you then register new ones.", reset.Reason)
+	link := fmt.Sprintf("%s%s?id=%s&token=%s", ctx.Configuration.Server.ExternalURL, secondFactorResetApprovalPath,
+		url.QueryEscape(reset.ID), url.QueryEscape(token))
+
+	bufHTML := new(bytes.Buffer)
+
+	// The HTML template is a text template, the reason given by the operator is escaped so it can't inject markup.
+	if ctx.Configuration.Notifier == nil || ctx.Configuration.Notifier.SMTP == nil || !ctx.Configuration.Notifier.SMTP.DisableHTMLEmails {
+		params := map[string]interface{}{
+			"title":  title,
+			"body":   template.HTMLEscapeString(body),
+			"url":    template.HTMLEscapeString(link),
+			"button": "Approve reset",
+		}
+
+		if err := templates.HTMLEmailTemplate.Execute(bufHTML, params); err != nil {
+			return err
+		}
+	}
+
+	bufText := new(bytes.Buffer)
+
+	if err := templates.PlainTextApprovalEmailTemplate.Execute(bufText, map[string]interface{}{"body": body, "url": link}); err != nil {
+		return err
+	}
+
+	ctx.Logger.Debugf("Sending an email to user %s (%s) to approve the second factor reset %s", reset.Username, email, reset.ID)
+
+	return notification.SendWithCategory(ctx.Providers.Notifier, notification.CategoryVerification, email, title,
+		bufText.String(), bufHTML.String())
+}
+
+func newAdminSecondFactorReset(reset models.SecondFactorReset) AdminSecondFactorReset {
+	adminReset := AdminSecondFactorReset{
+		ID:          reset.ID,
+		Username:    reset.Username,
+		RequestedBy: reset.RequestedBy,
+		Reason:      reset.Reason,
+		Approval:    reset.Approval,
+		Status:      reset.Status,
+		DecidedBy:   reset.DecidedBy,
+		RequestedAt: reset.RequestedAt.Unix(),
+		ExpiresAt:   reset.ExpiresAt.Unix(),
+	}
+
+	if !reset.DecidedAt.IsZero() {
+		adminReset.DecidedAt = reset.DecidedAt.Unix()
+	}
+
+	return adminReset
+}
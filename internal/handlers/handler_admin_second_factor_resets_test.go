@@ -0,0 +1,368 @@
+package handlers
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/events"
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/pkg/autheliatest"
+)
+
+type AdminSecondFactorResetsSuite struct {
+	suite.Suite
+
+	mock      *mocks.MockAutheliaCtx
+	storage   *autheliatest.MemoryStorageProvider
+	published []events.Event
+}
+
+func (s *AdminSecondFactorResetsSuite) SetupTest() {
+	s.mock = mocks.NewMockAutheliaCtx(s.T())
+	s.mock.Clock.Set(time.Unix(1600000000, 0))
+	s.mock.Ctx.Clock = &s.mock.Clock
+	s.mock.Ctx.Configuration.Server.AdminGroup = "admins"
+	s.mock.Ctx.Configuration.Server.AdminRoles.Helpdesk = []string{"support"}
+	s.mock.Ctx.Configuration.Server.ExternalURL = "https://login.example.com"
+
+	// The links sent by email are built from the configuration rather than the forwarded headers.
+	s.mock.Ctx.Request.Header.Set("X-Forwarded-Proto", "https")
+	s.mock.Ctx.Request.Header.Set("X-Forwarded-Host", "attacker.example.com")
+
+	s.storage = autheliatest.NewMemoryStorageProvider()
+	s.mock.Ctx.Providers.StorageProvider = s.storage
+
+	s.published = nil
+	s.mock.Ctx.Providers.Events.Subscribe(func(event events.Event) {
+		s.published = append(s.published, event)
+	})
+
+	s.Require().NoError(s.storage.SaveTOTPDevice(models.TOTPDevice{ID: "phone", Username: "harry", Description: "Phone"}))
+	s.Require().NoError(s.storage.SaveU2FDeviceHandle("harry", []byte("handle"), []byte("key")))
+	s.Require().NoError(s.storage.SaveTOTPDevice(models.TOTPDevice{ID: "phone", Username: "bob", Description: "Phone"}))
+}
+
+func (s *AdminSecondFactorResetsSuite) TearDownTest() {
+	s.mock.Close()
+}
+
+func (s *AdminSecondFactorResetsSuite) loginAs(username string, groups ...string) {
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder(username).WithGroups(groups...).TwoFactor(s.mock.Clock.Now()).Build())
+	s.mock.Ctx.Response.Reset()
+}
+
+// request requests the reset of the second factor of harry as a member of the helpdesk and returns the reset.
+func (s *AdminSecondFactorResetsSuite) request(body string) AdminSecondFactorReset {
+	s.loginAs("john", "support")
+	s.mock.Ctx.SetUserValue("username", "harry")
+	s.mock.Ctx.Request.SetBodyString(body)
+
+	middlewares.RequireAdminPermission(models.AdminPermissionSecondFactorResetsRequest)(AdminSecondFactorResetPost)(s.mock.Ctx)
+	s.Require().Equal(200, s.mock.Ctx.Response.StatusCode())
+
+	var reset AdminSecondFactorReset
+
+	s.mock.GetResponseData(s.T(), &reset)
+
+	return reset
+}
+
+func (s *AdminSecondFactorResetsSuite) approve(id string) {
+	s.mock.Ctx.SetUserValue("id", id)
+
+	middlewares.RequireAdminPermission(models.AdminPermissionSecondFactorResetsApprove)(AdminSecondFactorResetApprovePost)(s.mock.Ctx)
+}
+
+func (s *AdminSecondFactorResetsSuite) assertDevicesDeleted(deleted bool) {
+	devices, err := s.storage.LoadTOTPDevices("harry")
+	s.Require().NoError(err)
+
+	_, _, err = s.storage.LoadU2FDeviceHandle("harry")
+
+	if deleted {
+		s.Assert().Len(devices, 0)
+		s.Assert().Error(err)
+	} else {
+		s.Assert().Len(devices, 1)
+		s.Assert().NoError(err)
+	}
+
+	// The devices of the other users are never deleted.
+	devices, err = s.storage.LoadTOTPDevices("bob")
+	s.Require().NoError(err)
+	s.Assert().Len(devices, 1)
+}
+
+func (s *AdminSecondFactorResetsSuite) TestShouldRequestResetApprovedByAnotherOperator() {
+	reset := s.request(`{"reason": "Lost phone"}`)
+
+	s.Assert().Equal(AdminSecondFactorReset{
+		ID:          reset.ID,
+		Username:    "harry",
+		RequestedBy: "john",
+		Reason:      "Lost phone",
+		Approval:    models.SecondFactorResetApprovalAdmin,
+		Status:      models.SecondFactorResetPending,
+		RequestedAt: 1600000000,
+		ExpiresAt:   1600086400,
+	}, reset)
+
+	s.Require().Len(s.published, 1)
+	s.Assert().Equal(events.SecondFactorResetRequested, s.published[0].Type)
+	s.Assert().Equal("harry", s.published[0].Username)
+	s.Assert().Equal(map[string]string{
+		events.DetailResetID:     reset.ID,
+		events.DetailRequestedBy: "john",
+		events.DetailApproval:    models.SecondFactorResetApprovalAdmin,
+		events.DetailReason:      "Lost phone",
+		events.DetailActor:       "john",
+	}, s.published[0].Details)
+
+	s.assertDevicesDeleted(false)
+
+	s.loginAs("alice", "admins")
+	s.approve(reset.ID)
+
+	s.mock.Assert200OK(s.T(), nil)
+	s.assertDevicesDeleted(true)
+
+	approved, err := s.storage.LoadSecondFactorReset(reset.ID)
+	s.Require().NoError(err)
+	s.Assert().Equal(models.SecondFactorResetApproved, approved.Status)
+	s.Assert().Equal("alice", approved.DecidedBy)
+
+	s.Require().Len(s.published, 2)
+	s.Assert().Equal(events.SecondFactorResetApproved, s.published[1].Type)
+	s.Assert().Equal("alice", s.published[1].Details[events.DetailActor])
+	s.Assert().Equal("john", s.published[1].Details[events.DetailRequestedBy])
+
+	userEvents, err := s.storage.LoadUserEvents("harry", models.UserEventSecondFactorReset, 10, 0)
+	s.Require().NoError(err)
+	s.Assert().Len(userEvents, 1)
+}
+
+func (s *AdminSecondFactorResetsSuite) TestShouldForbidOperatorToApproveTheirOwnReset() {
+	s.loginAs("alice", "admins")
+	s.mock.Ctx.SetUserValue("username", "harry")
+	s.mock.Ctx.Request.SetBodyString(`{"reason": "Lost phone"}`)
+
+	middlewares.RequireAdminPermission(models.AdminPermissionSecondFactorResetsRequest)(AdminSecondFactorResetPost)(s.mock.Ctx)
+	s.Require().Equal(200, s.mock.Ctx.Response.StatusCode())
+
+	var reset AdminSecondFactorReset
+
+	s.mock.GetResponseData(s.T(), &reset)
+
+	s.mock.Ctx.Response.Reset()
+	s.approve(reset.ID)
+
+	s.Assert().Equal(403, s.mock.Ctx.Response.StatusCode())
+	s.assertDevicesDeleted(false)
+}
+
+func (s *AdminSecondFactorResetsSuite) TestShouldForbidHelpdeskToApprove() {
+	reset := s.request(`{"reason": "Lost phone"}`)
+
+	s.loginAs("ron", "support")
+	s.approve(reset.ID)
+
+	s.Assert().Equal(403, s.mock.Ctx.Response.StatusCode())
+	s.assertDevicesDeleted(false)
+}
+
+func (s *AdminSecondFactorResetsSuite) TestShouldNotApproveRejectedReset() {
+	reset := s.request(`{"reason": "Lost phone"}`)
+
+	s.mock.Ctx.Response.Reset()
+	s.mock.Ctx.SetUserValue("id", reset.ID)
+	middlewares.RequireAdminPermission(models.AdminPermissionSecondFactorResetsRequest)(AdminSecondFactorResetRejectPost)(s.mock.Ctx)
+	s.mock.Assert200OK(s.T(), nil)
+
+	s.Require().Len(s.published, 2)
+	s.Assert().Equal(events.SecondFactorResetRejected, s.published[1].Type)
+
+	s.loginAs("alice", "admins")
+	s.approve(reset.ID)
+
+	s.mock.Assert200KO(s.T(), "Operation failed.")
+	s.assertDevicesDeleted(false)
+}
+
+func (s *AdminSecondFactorResetsSuite) TestShouldNotApproveExpiredReset() {
+	reset := s.request(`{"reason": "Lost phone"}`)
+
+	s.mock.Clock.Set(time.Unix(1600086400, 0))
+
+	s.loginAs("alice", "admins")
+	s.approve(reset.ID)
+
+	s.mock.Assert200KO(s.T(), "Operation failed.")
+	s.assertDevicesDeleted(false)
+}
+
+func (s *AdminSecondFactorResetsSuite) TestShouldListPendingResets() {
+	reset := s.request(`{"reason": "Lost phone"}`)
+
+	s.loginAs("alice", "admins")
+	middlewares.RequireAdminPermission(models.AdminPermissionUsersRead)(AdminSecondFactorResetsGet)(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), []AdminSecondFactorReset{reset})
+}
+
+func (s *AdminSecondFactorResetsSuite) TestShouldRejectInvalidApproval() {
+	s.loginAs("john", "support")
+	s.mock.Ctx.SetUserValue("username", "harry")
+	s.mock.Ctx.Request.SetBodyString(`{"reason": "Lost phone", "approval": "anyone"}`)
+
+	middlewares.RequireAdminPermission(models.AdminPermissionSecondFactorResetsRequest)(AdminSecondFactorResetPost)(s.mock.Ctx)
+
+	s.mock.Assert200KO(s.T(), "Operation failed.")
+
+	resets, err := s.storage.LoadPendingSecondFactorResets(s.mock.Clock.Now())
+	s.Require().NoError(err)
+	s.Assert().Len(resets, 0)
+}
+
+// requestUserApproval requests a reset approved by the user and returns the reset and the token sent by email.
+func (s *AdminSecondFactorResetsSuite) requestUserApproval() (reset AdminSecondFactorReset, token string) {
+	s.mock.UserProviderMock.EXPECT().
+		GetDetails("harry").
+		Return(&authentication.UserDetails{Username: "harry", Emails: []string{"harry@example.com"}}, nil)
+
+	var body string
+
+	s.mock.NotifierMock.EXPECT().
+		Send(gomock.Eq("harry@example.com"), gomock.Eq("Approve the reset of your second factor"), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(recipient, subject, text, html string) error {
+			body = text
+			return nil
+		})
+
+	reset = s.request(`{"reason": "Lost phone", "approval": "user"}`)
+
+	matches := regexp.MustCompile(fmt.Sprintf(`https://login\.example\.com/2fa-reset/approve\?id=%s&token=(\w+)`, reset.ID)).FindStringSubmatch(body)
+	s.Require().Len(matches, 2)
+
+	return reset, matches[1]
+}
+
+func (s *AdminSecondFactorResetsSuite) approveAsUser(id, token string) {
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder("harry").OneFactor(s.mock.Clock.Now()).Build())
+	s.mock.Ctx.Response.Reset()
+	s.mock.Ctx.Request.SetBodyString(fmt.Sprintf(`{"id": "%s", "token": "%s"}`, id, token))
+
+	SecondFactorResetApprovalPost(s.mock.Ctx)
+}
+
+func (s *AdminSecondFactorResetsSuite) TestShouldRequestResetApprovedByUser() {
+	reset, token := s.requestUserApproval()
+
+	s.approveAsUser(reset.ID, token)
+
+	s.mock.Assert200OK(s.T(), nil)
+	s.assertDevicesDeleted(true)
+
+	s.Require().Len(s.published, 2)
+	s.Assert().Equal(events.SecondFactorResetApproved, s.published[1].Type)
+	s.Assert().NotContains(s.published[1].Details, events.DetailActor)
+}
+
+func (s *AdminSecondFactorResetsSuite) TestShouldNotApproveResetWithInvalidToken() {
+	reset, _ := s.requestUserApproval()
+
+	s.approveAsUser(reset.ID, "invalid")
+
+	s.mock.Assert200KO(s.T(), "Operation failed.")
+	s.assertDevicesDeleted(false)
+}
+
+func (s *AdminSecondFactorResetsSuite) TestShouldNotApproveResetOfAnotherUser() {
+	reset, token := s.requestUserApproval()
+
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder("bob").OneFactor(s.mock.Clock.Now()).Build())
+	s.mock.Ctx.Response.Reset()
+	s.mock.Ctx.Request.SetBodyString(fmt.Sprintf(`{"id": "%s", "token": "%s"}`, reset.ID, token))
+
+	SecondFactorResetApprovalPost(s.mock.Ctx)
+
+	s.mock.Assert200KO(s.T(), "Operation failed.")
+	s.assertDevicesDeleted(false)
+}
+
+func (s *AdminSecondFactorResetsSuite) TestShouldNotApproveUserResetAsOperator() {
+	reset, _ := s.requestUserApproval()
+
+	s.loginAs("alice", "admins")
+	s.approve(reset.ID)
+
+	s.mock.Assert200KO(s.T(), "Operation failed.")
+	s.assertDevicesDeleted(false)
+}
+
+func (s *AdminSecondFactorResetsSuite) TestShouldEscapeReasonInApprovalEmail() {
+	s.mock.UserProviderMock.EXPECT().
+		GetDetails("harry").
+		Return(&authentication.UserDetails{Username: "harry", Emails: []string{"harry@example.com"}}, nil)
+
+	var text, html string
+
+	s.mock.NotifierMock.EXPECT().
+		Send(gomock.Eq("harry@example.com"), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(recipient, subject, textBody, htmlBody string) error {
+			text, html = textBody, htmlBody
+			return nil
+		})
+
+	reset := s.request(`{"reason": "<a href=\"https://attacker.example.com\">Lost phone</a>", "approval": "user"}`)
+
+	s.Assert().Contains(text, `<a href="https://attacker.example.com">Lost phone</a>`)
+	s.Assert().NotContains(html, `<a href="https://attacker.example.com">`)
+	s.Assert().Contains(html, `&lt;a href=&#34;https://attacker.example.com&#34;&gt;Lost phone&lt;/a&gt;`)
+	s.Assert().Contains(html, fmt.Sprintf(`https://login.example.com/2fa-reset/approve?id=%s&amp;token=`, reset.ID))
+}
+
+func (s *AdminSecondFactorResetsSuite) TestShouldRefuseUserApprovalWithoutExternalURL() {
+	s.mock.Ctx.Configuration.Server.ExternalURL = ""
+
+	s.loginAs("john", "support")
+	s.mock.Ctx.SetUserValue("username", "harry")
+	s.mock.Ctx.Request.SetBodyString(`{"reason": "Lost phone", "approval": "user"}`)
+
+	middlewares.RequireAdminPermission(models.AdminPermissionSecondFactorResetsRequest)(AdminSecondFactorResetPost)(s.mock.Ctx)
+
+	s.mock.Assert200KO(s.T(), "Operation failed.")
+}
+
+func (s *AdminSecondFactorResetsSuite) TestShouldRejectResetWhenApprovalCantBeSent() {
+	s.mock.UserProviderMock.EXPECT().
+		GetDetails("harry").
+		Return(&authentication.UserDetails{Username: "harry", Emails: []string{"harry@example.com"}}, nil)
+
+	s.mock.NotifierMock.EXPECT().
+		Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(fmt.Errorf("connection refused"))
+
+	s.loginAs("john", "support")
+	s.mock.Ctx.SetUserValue("username", "harry")
+	s.mock.Ctx.Request.SetBodyString(`{"reason": "Lost phone", "approval": "user"}`)
+
+	middlewares.RequireAdminPermission(models.AdminPermissionSecondFactorResetsRequest)(AdminSecondFactorResetPost)(s.mock.Ctx)
+
+	s.mock.Assert200KO(s.T(), "Operation failed.")
+
+	resets, err := s.storage.LoadPendingSecondFactorResets(s.mock.Clock.Now())
+	s.Require().NoError(err)
+	s.Assert().Len(resets, 0)
+}
+
+func TestRunAdminSecondFactorResetsSuite(t *testing.T) {
+	suite.Run(t, new(AdminSecondFactorResetsSuite))
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/authelia/authelia/internal/events"
+	"github.com/authelia/authelia/internal/middlewares"
+)
+
+// AdminUserPurgePost handler deleting all the data of a departing user and revoking the sessions of the user. It
+// requires the users.purge admin permission.
+func AdminUserPurgePost(ctx *middlewares.AutheliaCtx) {
+	caller := ctx.AdminCaller()
+
+	username, ok := adminUsername(ctx)
+	if !ok {
+		return
+	}
+
+	if err := ctx.Providers.StorageProvider.PurgeUser(username, ctx.Clock.Now()); err != nil {
+		ctx.Error(fmt.Errorf("Unable to purge user %s: %s", username, err), operationFailedMessage)
+		return
+	}
+
+	ctx.Logger.Infof("User %s has been purged by %s", username, caller)
+
+	ctx.PublishEvent(events.UserDataErased, username, map[string]string{events.DetailActor: caller})
+	ctx.PublishEvent(events.SessionsRevoked, username, map[string]string{events.DetailActor: caller})
+
+	ctx.ReplyOK()
+}
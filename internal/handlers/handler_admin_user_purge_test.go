@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/authelia/authelia/internal/events"
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/pkg/autheliatest"
+)
+
+type AdminUserPurgeSuite struct {
+	suite.Suite
+
+	mock *mocks.MockAutheliaCtx
+
+	published []events.Event
+}
+
+func (s *AdminUserPurgeSuite) SetupTest() {
+	s.mock = mocks.NewMockAutheliaCtx(s.T())
+	s.mock.Clock.Set(time.Unix(1600000000, 0))
+	s.mock.Ctx.Clock = &s.mock.Clock
+	s.mock.Ctx.Configuration.Server.AdminGroup = "admins"
+	s.mock.Ctx.SetUserValue("username", "harry")
+
+	s.published = nil
+	s.mock.Ctx.Providers.Events.Subscribe(func(event events.Event) {
+		s.published = append(s.published, event)
+	})
+}
+
+func (s *AdminUserPurgeSuite) TearDownTest() {
+	s.mock.Close()
+}
+
+func (s *AdminUserPurgeSuite) TestShouldPurgeUser() {
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder(testUsername).WithGroups("admins").TwoFactor(s.mock.Clock.Now()).Build())
+
+	s.mock.StorageProviderMock.EXPECT().
+		PurgeUser("harry", s.mock.Clock.Now()).
+		Return(nil)
+
+	middlewares.RequireAdminPermission(models.AdminPermissionUsersPurge)(AdminUserPurgePost)(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), nil)
+	s.Assert().Equal("User harry has been purged by john", s.mock.Hook.LastEntry().Message)
+
+	s.Require().Len(s.published, 2)
+	s.Assert().Equal(events.UserDataErased, s.published[0].Type)
+	s.Assert().Equal("harry", s.published[0].Username)
+	s.Assert().Equal(map[string]string{events.DetailActor: "john"}, s.published[0].Details)
+	s.Assert().Equal(events.SessionsRevoked, s.published[1].Type)
+}
+
+func (s *AdminUserPurgeSuite) TestShouldForbidUserNotInAdminGroup() {
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder(testUsername).WithGroups("dev").TwoFactor(s.mock.Clock.Now()).Build())
+
+	s.mock.StorageProviderMock.EXPECT().PurgeUser(gomock.Any(), gomock.Any()).Times(0)
+
+	middlewares.RequireAdminPermission(models.AdminPermissionUsersPurge)(AdminUserPurgePost)(s.mock.Ctx)
+
+	s.Assert().Equal(403, s.mock.Ctx.Response.StatusCode())
+}
+
+func (s *AdminUserPurgeSuite) TestShouldForbidAdminWithoutSecondFactor() {
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder(testUsername).WithGroups("admins").OneFactor(s.mock.Clock.Now()).Build())
+
+	s.mock.StorageProviderMock.EXPECT().PurgeUser(gomock.Any(), gomock.Any()).Times(0)
+
+	middlewares.RequireAdminPermission(models.AdminPermissionUsersPurge)(AdminUserPurgePost)(s.mock.Ctx)
+
+	s.Assert().Equal(403, s.mock.Ctx.Response.StatusCode())
+}
+
+func (s *AdminUserPurgeSuite) TestShouldFailWhenStorageFails() {
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder(testUsername).WithGroups("admins").TwoFactor(s.mock.Clock.Now()).Build())
+
+	s.mock.StorageProviderMock.EXPECT().
+		PurgeUser("harry", s.mock.Clock.Now()).
+		Return(fmt.Errorf("failed"))
+
+	middlewares.RequireAdminPermission(models.AdminPermissionUsersPurge)(AdminUserPurgePost)(s.mock.Ctx)
+
+	s.mock.Assert200KO(s.T(), "Operation failed.")
+	s.Assert().Equal("Unable to purge user harry: failed", s.mock.Hook.LastEntry().Message)
+}
+
+func TestRunAdminUserPurgeSuite(t *testing.T) {
+	suite.Run(t, new(AdminUserPurgeSuite))
+}
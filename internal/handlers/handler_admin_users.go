@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/authelia/authelia/internal/events"
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/models"
+)
+
+// AdminUserGet returns the second factor devices, the preferred method and the regulation state of the user given in
+// the path. It requires the users.read admin permission.
+func AdminUserGet(ctx *middlewares.AutheliaCtx) {
+	username, ok := adminUsername(ctx)
+	if !ok {
+		return
+	}
+
+	body := AdminUserBody{Username: username}
+
+	warnings := loadInfo(username, ctx.Providers.StorageProvider, ctx.Providers.Regulator, &body.UserInfo, ctx.Logger)
+	if len(warnings) == userInfoLookups {
+		ctx.Error(fmt.Errorf("Unable to load information of user %s", username), operationFailedMessage)
+		return
+	}
+
+	if details, err := ctx.Providers.UserProvider.GetDetails(username); err != nil {
+		ctx.Logger.Errorf("%s: %s", adminUserDetailsWarning, err)
+
+		warnings = append(warnings, adminUserDetailsWarning)
+	} else {
+		body.DisplayName = details.DisplayName
+	}
+
+	if credentials, err := ctx.Providers.StorageProvider.LoadWebauthnCredentials(username); err != nil {
+		ctx.Logger.Errorf("%s: %s", userInfoWebauthnWarning, err)
+
+		if !body.HasWebauthn {
+			warnings = append(warnings, userInfoWebauthnWarning)
+		}
+	} else {
+		body.WebauthnCredentials = newAdminWebauthnCredentials(credentials)
+	}
+
+	body.Warnings = warnings
+
+	if err := ctx.SetJSONBody(body); err != nil {
+		ctx.Logger.Errorf("Unable to set admin user response in body: %s", err)
+	}
+}
+
+// AdminUserTOTPDeviceDelete handler deleting the TOTP device given in the path of the user given in the path. Like the
+// other device deletions below, it requires the users.devices.delete admin permission, the other handlers changing the
+// users require the users.manage admin permission.
+func AdminUserTOTPDeviceDelete(ctx *middlewares.AutheliaCtx) {
+	caller, username, ok := adminUserChange(ctx)
+	if !ok {
+		return
+	}
+
+	deviceID, _ := ctx.UserValue("id").(string)
+
+	devices, err := ctx.Providers.StorageProvider.LoadTOTPDevices(username)
+	if err != nil {
+		ctx.Error(fmt.Errorf("Unable to load TOTP devices of user %s: %s", username, err), operationFailedMessage)
+		return
+	}
+
+	if !hasTOTPDevice(devices, deviceID) {
+		ctx.Error(fmt.Errorf("User %s has no TOTP device %s", username, deviceID), operationFailedMessage)
+		return
+	}
+
+	if err = ctx.Providers.StorageProvider.DeleteTOTPDevice(username, deviceID); err != nil {
+		ctx.Error(fmt.Errorf("Unable to delete TOTP device %s of user %s: %s", deviceID, username, err), operationFailedMessage)
+		return
+	}
+
+	ctx.Logger.Infof("TOTP device %s of user %s has been deleted by %s", deviceID, username, caller)
+
+	appendUserEvent(ctx, username, models.UserEventTOTPDeleted)
+	publishAdminDeviceRevocation(ctx, caller, username, deviceOneTimePassword)
+
+	ctx.ReplyOK()
+}
+
+// AdminUserU2FDeviceDelete handler deleting the U2F device of the user given in the path.
+func AdminUserU2FDeviceDelete(ctx *middlewares.AutheliaCtx) {
+	caller, username, ok := adminUserChange(ctx)
+	if !ok {
+		return
+	}
+
+	if err := ctx.Providers.StorageProvider.DeleteU2FDeviceHandle(username); err != nil {
+		ctx.Error(fmt.Errorf("Unable to delete U2F device of user %s: %s", username, err), operationFailedMessage)
+		return
+	}
+
+	ctx.Logger.Infof("U2F device of user %s has been deleted by %s", username, caller)
+
+	appendUserEvent(ctx, username, models.UserEventU2FDeleted)
+	publishAdminDeviceRevocation(ctx, caller, username, deviceSecurityKey)
+
+	ctx.ReplyOK()
+}
+
+// AdminUserWebauthnCredentialDelete handler deleting the WebAuthn credential given in the path, encoded with the URL
+// safe base64 encoding without padding, of the user given in the path.
+func AdminUserWebauthnCredentialDelete(ctx *middlewares.AutheliaCtx) {
+	caller, username, ok := adminUserChange(ctx)
+	if !ok {
+		return
+	}
+
+	encodedID, _ := ctx.UserValue("id").(string)
+
+	credentialID, err := base64.RawURLEncoding.DecodeString(encodedID)
+	if err != nil || len(credentialID) == 0 {
+		ctx.Error(fmt.Errorf("Invalid WebAuthn credential ID '%s'", encodedID), operationFailedMessage)
+		return
+	}
+
+	if err = ctx.Providers.StorageProvider.DeleteWebauthnCredential(username, credentialID); err != nil {
+		ctx.Error(fmt.Errorf("Unable to delete WebAuthn credential %s of user %s: %s", encodedID, username, err), operationFailedMessage)
+		return
+	}
+
+	ctx.Logger.Infof("WebAuthn credential %s of user %s has been deleted by %s", encodedID, username, caller)
+
+	appendUserEvent(ctx, username, models.UserEventWebauthnDeleted)
+	publishAdminDeviceRevocation(ctx, caller, username, deviceWebauthnCredential)
+
+	ctx.ReplyOK()
+}
+
+// AdminUserMethodDelete handler resetting the preferred second factor method of the user given in the path, the
+// default method applies again.
+func AdminUserMethodDelete(ctx *middlewares.AutheliaCtx) {
+	caller, username, ok := adminUserChange(ctx)
+	if !ok {
+		return
+	}
+
+	if err := ctx.Providers.StorageProvider.DeletePreferred2FAMethod(username); err != nil {
+		ctx.Error(fmt.Errorf("Unable to reset preferred 2FA method of user %s: %s", username, err), operationFailedMessage)
+		return
+	}
+
+	ctx.Logger.Infof("Preferred 2FA method of user %s has been reset by %s", username, caller)
+
+	ctx.PublishEvent(events.PreferenceChanged, username, map[string]string{
+		events.DetailPreference: preferenceSecondFactorMethod,
+		events.DetailValue:      "",
+		events.DetailActor:      caller,
+	})
+
+	ctx.ReplyOK()
+}
+
+// AdminUserBanDelete handler lifting the bans of the regulation of the user given in the path by deleting the failed
+// authentication attempts of the user.
+func AdminUserBanDelete(ctx *middlewares.AutheliaCtx) {
+	caller, username, ok := adminUserChange(ctx)
+	if !ok {
+		return
+	}
+
+	deleted, err := ctx.Providers.StorageProvider.DeleteFailedAuthenticationLogs(username)
+	if err != nil {
+		ctx.Error(fmt.Errorf("Unable to delete failed authentication attempts of user %s: %s", username, err), operationFailedMessage)
+		return
+	}
+
+	ctx.Logger.Infof("Bans of user %s have been lifted by %s, %d failed authentication attempts deleted", username, caller, deleted)
+
+	ctx.PublishEvent(events.UserUnbanned, username, map[string]string{events.DetailActor: caller})
+
+	ctx.ReplyOK()
+}
+
+// AdminUserSessionsDelete handler terminating the sessions of the user given in the path, the sessions are terminated
+// the next time they're checked against the authentication backend according to the refresh interval.
+func AdminUserSessionsDelete(ctx *middlewares.AutheliaCtx) {
+	caller, username, ok := adminUserChange(ctx)
+	if !ok {
+		return
+	}
+
+	if err := ctx.Providers.StorageProvider.RevokeSessions(username, ctx.Clock.Now()); err != nil {
+		ctx.Error(fmt.Errorf("Unable to revoke sessions of user %s: %s", username, err), operationFailedMessage)
+		return
+	}
+
+	ctx.Logger.Infof("Sessions of user %s have been revoked by %s", username, caller)
+
+	ctx.PublishEvent(events.SessionsRevoked, username, map[string]string{events.DetailActor: caller})
+
+	ctx.ReplyOK()
+}
+
+// adminUserChange returns the caller and the user given in the path of the endpoints changing the users, it replies
+// with an error when the user is missing.
+func adminUserChange(ctx *middlewares.AutheliaCtx) (caller, username string, ok bool) {
+	if username, ok = adminUsername(ctx); !ok {
+		return "", "", false
+	}
+
+	return ctx.AdminCaller(), username, true
+}
+
+// adminUsername returns the user given in the path of the admin endpoints, it replies with an error when it's missing.
+func adminUsername(ctx *middlewares.AutheliaCtx) (username string, ok bool) {
+	username, _ = ctx.UserValue("username").(string)
+
+	if username == "" {
+		ctx.Error(fmt.Errorf("No username provided"), operationFailedMessage)
+		return "", false
+	}
+
+	return username, true
+}
+
+func publishAdminDeviceRevocation(ctx *middlewares.AutheliaCtx, caller, username, device string) {
+	ctx.PublishEvent(events.DeviceRevoked, username, map[string]string{
+		events.DetailDevice: device,
+		events.DetailActor:  caller,
+	})
+}
+
+func hasTOTPDevice(devices []models.TOTPDevice, deviceID string) bool {
+	for _, device := range devices {
+		if device.ID == deviceID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func newAdminWebauthnCredentials(credentials []models.WebauthnCredential) []AdminWebauthnCredential {
+	adminCredentials := make([]AdminWebauthnCredential, 0, len(credentials))
+
+	for _, credential := range credentials {
+		adminCredential := AdminWebauthnCredential{
+			ID:          base64.RawURLEncoding.EncodeToString(credential.ID),
+			Description: credential.Description,
+		}
+
+		if !credential.CreatedAt.IsZero() {
+			adminCredential.CreatedAt = credential.CreatedAt.Unix()
+		}
+
+		adminCredentials = append(adminCredentials, adminCredential)
+	}
+
+	return adminCredentials
+}
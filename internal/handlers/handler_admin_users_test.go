@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/events"
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/regulation"
+	"github.com/authelia/authelia/internal/storage"
+	"github.com/authelia/authelia/pkg/autheliatest"
+)
+
+type AdminUsersSuite struct {
+	suite.Suite
+
+	mock      *mocks.MockAutheliaCtx
+	storage   *autheliatest.MemoryStorageProvider
+	published []events.Event
+}
+
+func (s *AdminUsersSuite) SetupTest() {
+	s.mock = mocks.NewMockAutheliaCtx(s.T())
+	s.mock.Clock.Set(time.Unix(1600000000, 0))
+	s.mock.Ctx.Clock = &s.mock.Clock
+	s.mock.Ctx.Configuration.Server.AdminGroup = "admins"
+	s.mock.Ctx.SetUserValue("username", "harry")
+
+	s.storage = autheliatest.NewMemoryStorageProvider()
+	s.mock.Ctx.Providers.StorageProvider = s.storage
+	s.mock.Ctx.Providers.Regulator = regulation.NewRegulator(&schema.RegulationConfiguration{
+		MaxRetries: 3,
+		FindTime:   "2m",
+		BanTime:    "5m",
+	}, s.storage, &s.mock.Clock)
+
+	s.published = nil
+	s.mock.Ctx.Providers.Events.Subscribe(func(event events.Event) {
+		s.published = append(s.published, event)
+	})
+
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder(testUsername).WithGroups("admins").TwoFactor(s.mock.Clock.Now()).Build())
+}
+
+func (s *AdminUsersSuite) TearDownTest() {
+	s.mock.Close()
+}
+
+func (s *AdminUsersSuite) TestShouldGetUser() {
+	s.Require().NoError(s.storage.SavePreferred2FAMethod("harry", "webauthn"))
+	s.Require().NoError(s.storage.SaveTOTPDevice(models.TOTPDevice{ID: "phone", Username: "harry", Description: "Phone", CreatedAt: time.Unix(1500000000, 0)}))
+	s.Require().NoError(s.storage.SaveWebauthnCredential(models.WebauthnCredential{ID: []byte{0xfb, 0xff}, Username: "harry", Type: models.WebauthnCredentialTypeSecondFactor, Description: "YubiKey", CreatedAt: time.Unix(1500000001, 0)}))
+
+	s.mock.UserProviderMock.EXPECT().
+		GetDetails("harry").
+		Return(&authentication.UserDetails{Username: "harry", DisplayName: "Harry Potter"}, nil)
+
+	middlewares.RequireAdminPermission(models.AdminPermissionUsersRead)(AdminUserGet)(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), AdminUserBody{
+		Username: "harry",
+		UserInfo: UserInfo{
+			DisplayName: "Harry Potter",
+			Method:      "webauthn",
+			HasTOTP:     true,
+			TOTPDevices: []UserTOTPDevice{{ID: "phone", Description: "Phone", CreatedAt: 1500000000}},
+			HasWebauthn: true,
+		},
+		WebauthnCredentials: []AdminWebauthnCredential{{ID: "-_8", Description: "YubiKey", CreatedAt: 1500000001}},
+	})
+}
+
+func (s *AdminUsersSuite) TestShouldGetUserWithWarningWhenDetailsFail() {
+	s.mock.UserProviderMock.EXPECT().
+		GetDetails("harry").
+		Return(nil, fmt.Errorf("user not found"))
+
+	middlewares.RequireAdminPermission(models.AdminPermissionUsersRead)(AdminUserGet)(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), AdminUserBody{
+		Username: "harry",
+		UserInfo: UserInfo{
+			Method:      "totp",
+			TOTPDevices: []UserTOTPDevice{},
+			Warnings:    []string{adminUserDetailsWarning},
+		},
+		WebauthnCredentials: []AdminWebauthnCredential{},
+	})
+}
+
+func (s *AdminUsersSuite) TestShouldListUserEventsForHelpdesk() {
+	s.mock.Ctx.Configuration.Server.AdminRoles.Helpdesk = []string{"support"}
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder(testUsername).WithGroups("support").TwoFactor(s.mock.Clock.Now()).Build())
+	s.Require().NoError(s.storage.AppendUserEvent(models.UserEvent{Username: "harry", Type: models.UserEventTOTPDeleted, Time: s.mock.Clock.Now()}))
+
+	middlewares.RequireAdminPermission(models.AdminPermissionUsersRead)(AdminUserEventsGet)(s.mock.Ctx)
+
+	s.Assert().Equal(200, s.mock.Ctx.Response.StatusCode())
+	s.Assert().Contains(string(s.mock.Ctx.Response.Body()), models.UserEventTOTPDeleted)
+}
+
+func (s *AdminUsersSuite) TestShouldForbidNonAdministrators() {
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder(testUsername).WithGroups("dev").TwoFactor(s.mock.Clock.Now()).Build())
+	s.Require().NoError(s.storage.SaveU2FDeviceHandle("harry", []byte("handle"), []byte("key")))
+
+	middlewares.RequireAdminPermission(models.AdminPermissionUsersRead)(AdminUserGet)(s.mock.Ctx)
+	s.Assert().Equal(403, s.mock.Ctx.Response.StatusCode())
+
+	middlewares.RequireAdminPermission(models.AdminPermissionUsersManage)(AdminUserU2FDeviceDelete)(s.mock.Ctx)
+	s.Assert().Equal(403, s.mock.Ctx.Response.StatusCode())
+
+	_, _, err := s.storage.LoadU2FDeviceHandle("harry")
+	s.Assert().NoError(err)
+}
+
+func (s *AdminUsersSuite) TestShouldDeleteTOTPDevice() {
+	s.Require().NoError(s.storage.SaveTOTPDevice(models.TOTPDevice{ID: "phone", Username: "harry"}))
+	s.Require().NoError(s.storage.SaveTOTPDevice(models.TOTPDevice{ID: "tablet", Username: "harry"}))
+	s.mock.Ctx.SetUserValue("id", "phone")
+
+	middlewares.RequireAdminPermission(models.AdminPermissionUsersManage)(AdminUserTOTPDeviceDelete)(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), nil)
+	s.Assert().Equal("TOTP device phone of user harry has been deleted by john", s.mock.Hook.LastEntry().Message)
+
+	devices, err := s.storage.LoadTOTPDevices("harry")
+	s.Require().NoError(err)
+	s.Require().Len(devices, 1)
+	s.Assert().Equal("tablet", devices[0].ID)
+
+	userEvents, err := s.storage.LoadUserEvents("harry", models.UserEventTOTPDeleted, 10, 0)
+	s.Require().NoError(err)
+	s.Assert().Len(userEvents, 1)
+
+	s.Require().Len(s.published, 1)
+	s.Assert().Equal(events.DeviceRevoked, s.published[0].Type)
+	s.Assert().Equal("harry", s.published[0].Username)
+	s.Assert().Equal(map[string]string{events.DetailDevice: "one-time password device", events.DetailActor: "john"}, s.published[0].Details)
+}
+
+func (s *AdminUsersSuite) TestShouldFailToDeleteUnknownTOTPDevice() {
+	s.Require().NoError(s.storage.SaveTOTPDevice(models.TOTPDevice{ID: "phone", Username: "john"}))
+	s.mock.Ctx.SetUserValue("id", "phone")
+
+	middlewares.RequireAdminPermission(models.AdminPermissionUsersManage)(AdminUserTOTPDeviceDelete)(s.mock.Ctx)
+
+	s.mock.Assert200KO(s.T(), "Operation failed.")
+	s.Assert().Equal("User harry has no TOTP device phone", s.mock.Hook.LastEntry().Message)
+
+	devices, err := s.storage.LoadTOTPDevices("john")
+	s.Require().NoError(err)
+	s.Assert().Len(devices, 1)
+}
+
+func (s *AdminUsersSuite) TestShouldDeleteU2FDevice() {
+	s.Require().NoError(s.storage.SaveU2FDeviceHandle("harry", []byte("handle"), []byte("key")))
+
+	middlewares.RequireAdminPermission(models.AdminPermissionUsersManage)(AdminUserU2FDeviceDelete)(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), nil)
+
+	_, _, err := s.storage.LoadU2FDeviceHandle("harry")
+	s.Assert().Equal(storage.ErrNoU2FDeviceHandle, err)
+
+	middlewares.RequireAdminPermission(models.AdminPermissionUsersManage)(AdminUserU2FDeviceDelete)(s.mock.Ctx)
+
+	s.mock.Assert200KO(s.T(), "Operation failed.")
+	s.Assert().Equal("Unable to delete U2F device of user harry: No U2F device handle found", s.mock.Hook.LastEntry().Message)
+}
+
+func (s *AdminUsersSuite) TestShouldDeleteWebauthnCredential() {
+	s.Require().NoError(s.storage.SaveWebauthnCredential(models.WebauthnCredential{ID: []byte{0xfb, 0xff}, Username: "harry"}))
+	s.mock.Ctx.SetUserValue("id", "-_8")
+
+	middlewares.RequireAdminPermission(models.AdminPermissionUsersManage)(AdminUserWebauthnCredentialDelete)(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), nil)
+
+	credentials, err := s.storage.LoadWebauthnCredentials("harry")
+	s.Require().NoError(err)
+	s.Assert().Len(credentials, 0)
+
+	s.Require().Len(s.published, 1)
+	s.Assert().Equal("WebAuthn credential", s.published[0].Details[events.DetailDevice])
+}
+
+func (s *AdminUsersSuite) TestShouldFailToDeleteWebauthnCredentialWithInvalidID() {
+	s.mock.Ctx.SetUserValue("id", "not base64!")
+
+	middlewares.RequireAdminPermission(models.AdminPermissionUsersManage)(AdminUserWebauthnCredentialDelete)(s.mock.Ctx)
+
+	s.mock.Assert200KO(s.T(), "Operation failed.")
+	s.Assert().Equal("Invalid WebAuthn credential ID 'not base64!'", s.mock.Hook.LastEntry().Message)
+}
+
+func (s *AdminUsersSuite) TestShouldResetPreferredMethod() {
+	s.Require().NoError(s.storage.SavePreferred2FAMethod("harry", "webauthn"))
+
+	middlewares.RequireAdminPermission(models.AdminPermissionUsersManage)(AdminUserMethodDelete)(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), nil)
+
+	method, err := s.storage.LoadPreferred2FAMethod("harry")
+	s.Require().NoError(err)
+	s.Assert().Equal("", method)
+
+	s.Require().Len(s.published, 1)
+	s.Assert().Equal(events.PreferenceChanged, s.published[0].Type)
+	s.Assert().Equal("john", s.published[0].Details[events.DetailActor])
+}
+
+func (s *AdminUsersSuite) TestShouldLiftBan() {
+	for i := 0; i < 3; i++ {
+		s.Require().NoError(s.storage.AppendAuthenticationLog(models.AuthenticationAttempt{
+			Username: "harry",
+			Time:     s.mock.Clock.Now().Add(-time.Duration(i) * time.Second),
+			Type:     models.AuthenticationTypeFirstFactor,
+		}))
+	}
+
+	_, err := s.mock.Ctx.Providers.Regulator.Regulate("harry")
+	s.Require().Equal(regulation.ErrUserIsBanned, err)
+
+	middlewares.RequireAdminPermission(models.AdminPermissionUsersManage)(AdminUserBanDelete)(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), nil)
+	s.Assert().Equal("Bans of user harry have been lifted by john, 3 failed authentication attempts deleted", s.mock.Hook.LastEntry().Message)
+
+	_, err = s.mock.Ctx.Providers.Regulator.Regulate("harry")
+	s.Assert().NoError(err)
+
+	s.Require().Len(s.published, 1)
+	s.Assert().Equal(events.UserUnbanned, s.published[0].Type)
+}
+
+func (s *AdminUsersSuite) TestShouldRevokeSessions() {
+	middlewares.RequireAdminPermission(models.AdminPermissionUsersManage)(AdminUserSessionsDelete)(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), nil)
+
+	revokedAt, err := s.storage.LoadSessionsRevocation("harry")
+	s.Require().NoError(err)
+	s.Assert().Equal(s.mock.Clock.Now(), revokedAt)
+
+	s.Require().Len(s.published, 1)
+	s.Assert().Equal(events.SessionsRevoked, s.published[0].Type)
+	s.Assert().Equal("john", s.published[0].Details[events.DetailActor])
+}
+
+func (s *AdminUsersSuite) TestShouldFailWithoutUsername() {
+	s.mock.Ctx.SetUserValue("username", "")
+
+	middlewares.RequireAdminPermission(models.AdminPermissionUsersManage)(AdminUserSessionsDelete)(s.mock.Ctx)
+
+	s.mock.Assert200KO(s.T(), "Operation failed.")
+	s.Assert().Equal("No username provided", s.mock.Hook.LastEntry().Message)
+}
+
+func TestRunAdminUsersSuite(t *testing.T) {
+	suite.Run(t, new(AdminUsersSuite))
+}
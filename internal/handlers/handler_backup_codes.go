@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"strings"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/storage"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// UserBackupCodesPost handler generating a new batch of backup codes for the user, the previous codes can't be used
+// anymore. The codes are only returned once, only their salted hashes are saved. The user must be authenticated with
+// two factors to generate them since they allow to complete the second factor.
+func UserBackupCodesPost(ctx *middlewares.AutheliaCtx) {
+	userSession := ctx.GetSession()
+
+	if userSession.AuthenticationLevel < authentication.TwoFactor {
+		ctx.Logger.Debugf("User %s must be authenticated with two factors to generate backup codes", userSession.Username)
+		ctx.ReplyForbidden()
+
+		return
+	}
+
+	codes := make([]string, 0, backupCodesCount)
+	codeHashes := make([]string, 0, backupCodesCount)
+
+	for i := 0; i < backupCodesCount; i++ {
+		code, err := utils.RandomSecureString(backupCodeLength, backupCodeCharacters)
+		if err != nil {
+			ctx.Error(fmt.Errorf("Unable to generate backup code: %s", err), operationFailedMessage)
+			return
+		}
+
+		codeHash, err := hashBackupCode(backupCodePasswordConfiguration(ctx), code)
+		if err != nil {
+			ctx.Error(fmt.Errorf("Unable to hash backup code: %s", err), operationFailedMessage)
+			return
+		}
+
+		codes = append(codes, code[:backupCodeLength/2]+"-"+code[backupCodeLength/2:])
+		codeHashes = append(codeHashes, codeHash)
+	}
+
+	if err := ctx.Providers.StorageProvider.SaveBackupCodes(userSession.Username, codeHashes, ctx.Clock.Now()); err != nil {
+		ctx.Error(fmt.Errorf("Unable to save the backup codes of user %s: %s", userSession.Username, err), operationFailedMessage)
+		return
+	}
+
+	ctx.Logger.Debugf("Backup codes of user %s have been generated", userSession.Username)
+
+	appendUserEvent(ctx, userSession.Username, models.UserEventBackupCodesGenerated)
+
+	if err := ctx.SetJSONBody(BackupCodesResponse{Codes: codes}); err != nil {
+		ctx.Logger.Errorf("Unable to set backup codes response in body: %s", err)
+	}
+}
+
+// SecondFactorBackupCodePost handler completing the second factor with one of the backup codes of the user when the
+// other methods are unavailable. The code can't be used again.
+func SecondFactorBackupCodePost(ctx *middlewares.AutheliaCtx) {
+	var bodyJSON signBackupCodeRequestBody
+
+	if err := ctx.ParseBody(&bodyJSON); err != nil {
+		handleAuthenticationUnauthorized(ctx, err, mfaValidationFailedMessage)
+		return
+	}
+
+	userSession := ctx.GetSession()
+
+	if isSecondFactorBanned(ctx, userSession.Username) {
+		return
+	}
+
+	codeHash, err := matchBackupCode(ctx.Providers.StorageProvider, userSession.Username, bodyJSON.Code)
+	if err == nil {
+		err = ctx.Providers.StorageProvider.ConsumeBackupCode(userSession.Username, codeHash)
+	}
+
+	if err != nil {
+		if err == storage.ErrNoBackupCode {
+			markSecondFactorAttempt(ctx, userSession.Username, false, models.AuthenticationTypeBackupCode)
+			handleAuthenticationUnauthorized(ctx, fmt.Errorf("Wrong or already used backup code for user %s", userSession.Username), mfaValidationFailedMessage)
+
+			return
+		}
+
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to consume backup code of user %s: %s", userSession.Username, err), mfaValidationFailedMessage)
+
+		return
+	}
+
+	markSecondFactorAttempt(ctx, userSession.Username, true, models.AuthenticationTypeBackupCode)
+
+	if err = ctx.Providers.SessionProvider.RegenerateSession(ctx.RequestCtx); err != nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to regenerate session for user %s: %s", userSession.Username, err), mfaValidationFailedMessage)
+		return
+	}
+
+	userSession.SetTwoFactor(ctx.Clock.Now())
+	userSession.SetSecondFactorClient(ctx.ClientMetadata())
+
+	if err = ctx.SaveSession(userSession); err != nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to update the authentication level with a backup code: %s", err), mfaValidationFailedMessage)
+		return
+	}
+
+	if userSession.OIDCWorkflowSession != nil {
+		handleOIDCWorkflowResponse(ctx)
+	} else {
+		Handle2FAResponse(ctx, bodyJSON.TargetURL, bodyJSON.State)
+	}
+}
+
+// backupCodePasswordConfiguration returns the configuration of the password hasher the backup codes are hashed with,
+// the one of the file authentication backend or the default one.
+func backupCodePasswordConfiguration(ctx *middlewares.AutheliaCtx) schema.PasswordConfiguration {
+	if file := ctx.Configuration.AuthenticationBackend.File; file != nil && file.Password != nil {
+		return *file.Password
+	}
+
+	return schema.DefaultPasswordConfiguration
+}
+
+// normalizeBackupCode returns the code typed by the user without its dashes and spaces and in lower case.
+func normalizeBackupCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+// hashBackupCode returns the salted hash of the code saved in the storage.
+func hashBackupCode(config schema.PasswordConfiguration, code string) (string, error) {
+	algorithm, err := authentication.ConfigAlgoToCryptoAlgo(config.Algorithm)
+	if err != nil {
+		return "", err
+	}
+
+	return authentication.HashPassword(normalizeBackupCode(code), "", algorithm, config.Iterations, config.Memory*1024,
+		config.Parallelism, config.KeyLength, config.SaltLength)
+}
+
+// matchBackupCode returns the hash of the unused backup code of the user matching the code typed by the user, or
+// storage.ErrNoBackupCode when none matches. The codes generated before they were salted are hashed with SHA-256.
+func matchBackupCode(storageProvider storage.Provider, username, code string) (string, error) {
+	codeHashes, err := storageProvider.LoadBackupCodeHashes(username)
+	if err != nil {
+		return "", err
+	}
+
+	code = normalizeBackupCode(code)
+	legacyHash := utils.HashSHA256FromString(code)
+
+	for _, codeHash := range codeHashes {
+		if !strings.HasPrefix(codeHash, "$") {
+			if subtle.ConstantTimeCompare([]byte(codeHash), []byte(legacyHash)) == 1 {
+				return codeHash, nil
+			}
+
+			continue
+		}
+
+		ok, err := authentication.CheckPassword(code, codeHash)
+		if err != nil {
+			return "", err
+		}
+
+		if ok {
+			return codeHash, nil
+		}
+	}
+
+	return "", storage.ErrNoBackupCode
+}
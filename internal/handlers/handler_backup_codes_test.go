@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/metrics"
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/regulation"
+	"github.com/authelia/authelia/internal/utils"
+	"github.com/authelia/authelia/pkg/autheliatest"
+)
+
+type BackupCodesSuite struct {
+	suite.Suite
+
+	mock    *mocks.MockAutheliaCtx
+	storage *autheliatest.MemoryStorageProvider
+}
+
+func (s *BackupCodesSuite) SetupTest() {
+	s.mock = mocks.NewMockAutheliaCtx(s.T())
+	s.mock.Ctx.Clock = &s.mock.Clock
+
+	s.storage = autheliatest.NewMemoryStorageProvider()
+	s.mock.Ctx.Providers.StorageProvider = s.storage
+	s.mock.Ctx.Providers.Regulator = regulation.NewRegulator(nil, s.storage, &s.mock.Clock)
+
+	// The codes are hashed with few rounds to keep the tests fast.
+	s.mock.Ctx.Configuration.AuthenticationBackend.File = &schema.FileAuthenticationBackendConfiguration{
+		Password: &schema.PasswordConfiguration{Algorithm: "sha512", Iterations: 1000, SaltLength: 16},
+	}
+}
+
+func (s *BackupCodesSuite) TearDownTest() {
+	s.mock.Close()
+}
+
+func (s *BackupCodesSuite) generateCodes() []string {
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder(testUsername).TwoFactor(s.mock.Clock.Now()).Build())
+
+	UserBackupCodesPost(s.mock.Ctx)
+	s.Require().Equal(200, s.mock.Ctx.Response.StatusCode())
+
+	var response BackupCodesResponse
+
+	s.mock.GetResponseData(s.T(), &response)
+	s.mock.Ctx.Response.Reset()
+
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder(testUsername).OneFactor(s.mock.Clock.Now()).Build())
+
+	return response.Codes
+}
+
+func (s *BackupCodesSuite) TestShouldGenerateCodes() {
+	codes := s.generateCodes()
+
+	s.Require().Len(codes, backupCodesCount)
+	s.Assert().Regexp("^[a-z0-9]{5}-[a-z0-9]{5}$", codes[0])
+
+	count, err := s.storage.CountBackupCodes(testUsername)
+	s.Require().NoError(err)
+	s.Assert().Equal(backupCodesCount, count)
+
+	events, err := s.storage.LoadUserEvents(testUsername, "", 10, 0)
+	s.Require().NoError(err)
+	s.Require().Len(events, 1)
+	s.Assert().Equal(models.UserEventBackupCodesGenerated, events[0].Type)
+}
+
+func (s *BackupCodesSuite) TestShouldSaltCodesWithPasswordHasher() {
+	codes := s.generateCodes()
+
+	codeHashes, err := s.storage.LoadBackupCodeHashes(testUsername)
+	s.Require().NoError(err)
+	s.Require().Len(codeHashes, backupCodesCount)
+
+	salts := map[string]bool{}
+
+	for _, codeHash := range codeHashes {
+		s.Require().True(strings.HasPrefix(codeHash, "$6$rounds=1000$"), codeHash)
+		s.Assert().NotEqual(utils.HashSHA256FromString(strings.ReplaceAll(codes[0], "-", "")), codeHash)
+
+		salts[strings.Split(codeHash, "$")[3]] = true
+	}
+
+	s.Assert().Len(salts, backupCodesCount)
+}
+
+func (s *BackupCodesSuite) TestShouldHashCodesWithDefaultPasswordHasher() {
+	s.mock.Ctx.Configuration.AuthenticationBackend.File = nil
+
+	codeHash, err := hashBackupCode(backupCodePasswordConfiguration(s.mock.Ctx), "abcde-fghjk")
+	s.Require().NoError(err)
+	s.Assert().True(strings.HasPrefix(codeHash, "$argon2id$v=19$m=65536,t=1,p=8$"), codeHash)
+
+	s.Require().NoError(s.storage.SaveBackupCodes(testUsername, []string{codeHash}, s.mock.Clock.Now()))
+
+	matched, err := matchBackupCode(s.storage, testUsername, "ABCDEFGHJK")
+	s.Require().NoError(err)
+	s.Assert().Equal(codeHash, matched)
+}
+
+func (s *BackupCodesSuite) TestShouldAuthenticateWithCodeHashedBeforeSalting() {
+	s.Require().NoError(s.storage.SaveBackupCodes(testUsername, []string{utils.HashSHA256FromString("abcdefghjk")}, s.mock.Clock.Now()))
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder(testUsername).OneFactor(s.mock.Clock.Now()).Build())
+
+	s.mock.Ctx.Request.SetBodyString(`{"code":"abcde-fghjk"}`)
+
+	SecondFactorBackupCodePost(s.mock.Ctx)
+
+	s.Assert().Equal([]byte("{\"status\":\"OK\"}"), s.mock.Ctx.Response.Body())
+
+	count, err := s.storage.CountBackupCodes(testUsername)
+	s.Require().NoError(err)
+	s.Assert().Equal(0, count)
+}
+
+func (s *BackupCodesSuite) TestShouldForbidGenerationWithoutSecondFactor() {
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder(testUsername).OneFactor(s.mock.Clock.Now()).Build())
+
+	UserBackupCodesPost(s.mock.Ctx)
+
+	s.Assert().Equal(403, s.mock.Ctx.Response.StatusCode())
+
+	count, err := s.storage.CountBackupCodes(testUsername)
+	s.Require().NoError(err)
+	s.Assert().Equal(0, count)
+}
+
+func (s *BackupCodesSuite) TestShouldReplacePreviousCodes() {
+	previous := s.generateCodes()
+	s.generateCodes()
+
+	s.mock.Ctx.Request.SetBodyString(`{"code":"` + previous[0] + `"}`)
+
+	SecondFactorBackupCodePost(s.mock.Ctx)
+
+	s.Assert().Equal(401, s.mock.Ctx.Response.StatusCode())
+}
+
+func (s *BackupCodesSuite) TestShouldAuthenticateWithCodeOnlyOnce() {
+	codes := s.generateCodes()
+
+	s.mock.Ctx.Request.SetBodyString(`{"code":"` + codes[0] + `"}`)
+
+	successes := metrics.SecondFactorAttempts.Value(models.AuthenticationTypeBackupCode, "true")
+
+	SecondFactorBackupCodePost(s.mock.Ctx)
+
+	s.Assert().Equal([]byte("{\"status\":\"OK\"}"), s.mock.Ctx.Response.Body())
+	s.Assert().Equal(successes+1, metrics.SecondFactorAttempts.Value(models.AuthenticationTypeBackupCode, "true"))
+	s.Assert().Equal(authentication.TwoFactor, s.mock.Ctx.GetSession().AuthenticationLevel)
+
+	count, err := s.storage.CountBackupCodes(testUsername)
+	s.Require().NoError(err)
+	s.Assert().Equal(backupCodesCount-1, count)
+
+	s.mock.Ctx.Response.Reset()
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder(testUsername).OneFactor(s.mock.Clock.Now()).Build())
+
+	SecondFactorBackupCodePost(s.mock.Ctx)
+
+	s.Assert().Equal(401, s.mock.Ctx.Response.StatusCode())
+	s.Assert().Equal("Wrong or already used backup code for user john", s.mock.Hook.LastEntry().Message)
+	s.Assert().Equal(authentication.OneFactor, s.mock.Ctx.GetSession().AuthenticationLevel)
+
+	attempts, err := s.storage.LoadLatestAuthenticationLogs(testUsername, s.mock.Clock.Now().Add(-1))
+	s.Require().NoError(err)
+	s.Require().Len(attempts, 2)
+	s.Assert().Equal(models.AuthenticationTypeBackupCode, attempts[0].Type)
+}
+
+func (s *BackupCodesSuite) TestShouldIgnoreDashesAndCase() {
+	codes := s.generateCodes()
+
+	s.mock.Ctx.Request.SetBodyString(`{"code":" ` + strings.ToUpper(strings.ReplaceAll(codes[1], "-", "")) + `"}`)
+
+	SecondFactorBackupCodePost(s.mock.Ctx)
+
+	s.Assert().Equal([]byte("{\"status\":\"OK\"}"), s.mock.Ctx.Response.Body())
+}
+
+func TestRunBackupCodesSuite(t *testing.T) {
+	suite.Run(t, new(BackupCodesSuite))
+}
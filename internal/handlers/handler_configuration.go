@@ -3,20 +3,31 @@ package handlers
 import (
 	"github.com/authelia/authelia/internal/authentication"
 	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/session"
 )
 
 // ConfigurationBody the content returned by the configuration endpoint.
 type ConfigurationBody struct {
-	AvailableMethods    MethodList `json:"available_methods"`
-	SecondFactorEnabled bool       `json:"second_factor_enabled"` // whether second factor is enabled or not.
-	TOTPPeriod          int        `json:"totp_period"`
+	AvailableMethods    MethodList        `json:"available_methods"`
+	SecondFactorEnabled bool              `json:"second_factor_enabled"` // whether second factor is enabled or not.
+	TOTPPeriod          int               `json:"totp_period"`
+	LoginBanner         string            `json:"login_banner,omitempty"`
+	Announcement        *AnnouncementBody `json:"announcement,omitempty"`
 }
 
-// ConfigurationGet get the configuration accessible to authenticated users.
-func ConfigurationGet(ctx *middlewares.AutheliaCtx) {
+// AnnouncementBody the announcement displayed to the user until the user acknowledges it.
+type AnnouncementBody struct {
+	ID           string `json:"id"`
+	Message      string `json:"message"`
+	Acknowledged bool   `json:"acknowledged"`
+}
+
+func newConfigurationBody(ctx *middlewares.AutheliaCtx, userSession *session.UserSession) ConfigurationBody {
 	body := ConfigurationBody{}
-	body.AvailableMethods = MethodList{authentication.TOTP, authentication.U2F}
+	body.AvailableMethods = MethodList{authentication.TOTP, authentication.U2F, authentication.Webauthn}
 	body.TOTPPeriod = ctx.Configuration.TOTP.Period
+	body.LoginBanner = ctx.Configuration.Banner.Login
+	body.Announcement = newAnnouncementBody(ctx, userSession)
 
 	if ctx.Configuration.DuoAPI != nil {
 		body.AvailableMethods = append(body.AvailableMethods, authentication.Push)
@@ -28,7 +39,35 @@ func ConfigurationGet(ctx *middlewares.AutheliaCtx) {
 
 	ctx.Logger.Tracef("Available methods are %s", body.AvailableMethods)
 
-	err := ctx.SetJSONBody(body)
+	return body
+}
+
+// newAnnouncementBody returns the configured announcement along with whether the user acknowledged it, nil is returned
+// when there is no announcement. The announcement is considered not acknowledged when the acknowledgment can't be
+// loaded so it's displayed again rather than missed.
+func newAnnouncementBody(ctx *middlewares.AutheliaCtx, userSession *session.UserSession) *AnnouncementBody {
+	announcement := ctx.Configuration.Banner.Announcement
+	if announcement.ID == "" {
+		return nil
+	}
+
+	acknowledgedAt, err := ctx.Providers.StorageProvider.LoadAnnouncementAcknowledgment(userSession.Username, announcement.ID)
+	if err != nil {
+		ctx.Logger.Errorf("Unable to load the acknowledgment of announcement %s by user %s: %s", announcement.ID, userSession.Username, err)
+	}
+
+	return &AnnouncementBody{
+		ID:           announcement.ID,
+		Message:      announcement.Message,
+		Acknowledged: err == nil && !acknowledgedAt.IsZero(),
+	}
+}
+
+// ConfigurationGet get the configuration accessible to authenticated users.
+func ConfigurationGet(ctx *middlewares.AutheliaCtx) {
+	userSession := ctx.GetSession()
+
+	err := ctx.SetJSONBody(newConfigurationBody(ctx, &userSession))
 	if err != nil {
 		ctx.Logger.Errorf("Unable to set configuration response in body: %s", err)
 	}
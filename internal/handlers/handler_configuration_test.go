@@ -16,8 +16,7 @@ type SecondFactorAvailableMethodsFixture struct {
 }
 
 func (s *SecondFactorAvailableMethodsFixture) SetupTest() {
-	s.mock = mocks.NewMockAutheliaCtx(s.T())
-	s.mock.Ctx.Providers.Authorizer = authorization.NewAuthorizer(&schema.Configuration{
+	s.mock = mocks.NewMockAutheliaCtxWithConfiguration(s.T(), schema.Configuration{
 		AccessControl: schema.AccessControlConfiguration{
 			DefaultPolicy: "deny",
 			Rules:         []schema.ACLRule{},
@@ -35,7 +34,7 @@ func (s *SecondFactorAvailableMethodsFixture) TestShouldServeDefaultMethods() {
 		},
 	}
 	expectedBody := ConfigurationBody{
-		AvailableMethods:    []string{"totp", "u2f"},
+		AvailableMethods:    []string{"totp", "u2f", "webauthn"},
 		SecondFactorEnabled: false,
 		TOTPPeriod:          schema.DefaultTOTPConfiguration.Period,
 	}
@@ -52,7 +51,7 @@ func (s *SecondFactorAvailableMethodsFixture) TestShouldServeDefaultMethodsAndMo
 		},
 	}
 	expectedBody := ConfigurationBody{
-		AvailableMethods:    []string{"totp", "u2f", "mobile_push"},
+		AvailableMethods:    []string{"totp", "u2f", "webauthn", "mobile_push"},
 		SecondFactorEnabled: false,
 		TOTPPeriod:          schema.DefaultTOTPConfiguration.Period,
 	}
@@ -88,7 +87,7 @@ func (s *SecondFactorAvailableMethodsFixture) TestShouldCheckSecondFactorIsDisab
 			}})
 	ConfigurationGet(s.mock.Ctx)
 	s.mock.Assert200OK(s.T(), ConfigurationBody{
-		AvailableMethods:    []string{"totp", "u2f"},
+		AvailableMethods:    []string{"totp", "u2f", "webauthn"},
 		SecondFactorEnabled: false,
 		TOTPPeriod:          schema.DefaultTOTPConfiguration.Period,
 	})
@@ -120,7 +119,7 @@ func (s *SecondFactorAvailableMethodsFixture) TestShouldCheckSecondFactorIsEnabl
 		}})
 	ConfigurationGet(s.mock.Ctx)
 	s.mock.Assert200OK(s.T(), ConfigurationBody{
-		AvailableMethods:    []string{"totp", "u2f"},
+		AvailableMethods:    []string{"totp", "u2f", "webauthn"},
 		SecondFactorEnabled: true,
 		TOTPPeriod:          schema.DefaultTOTPConfiguration.Period,
 	})
@@ -153,7 +152,7 @@ func (s *SecondFactorAvailableMethodsFixture) TestShouldCheckSecondFactorIsEnabl
 			}})
 	ConfigurationGet(s.mock.Ctx)
 	s.mock.Assert200OK(s.T(), ConfigurationBody{
-		AvailableMethods:    []string{"totp", "u2f"},
+		AvailableMethods:    []string{"totp", "u2f", "webauthn"},
 		SecondFactorEnabled: true,
 		TOTPPeriod:          schema.DefaultTOTPConfiguration.Period,
 	})
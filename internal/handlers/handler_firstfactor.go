@@ -1,14 +1,19 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
 	"sync"
 	"time"
 
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/events"
 	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/models"
 	"github.com/authelia/authelia/internal/regulation"
+	"github.com/authelia/authelia/internal/risk"
 	"github.com/authelia/authelia/internal/session"
 )
 
@@ -45,6 +50,21 @@ func delayToPreventTimingAttacks(ctx *middlewares.AutheliaCtx, requestTime time.
 	time.Sleep(time.Duration(actualDelayMs) * time.Millisecond)
 }
 
+// checkUserPasswordErrorMessage returns the message telling the user why the check of their password failed, the
+// errors which aren't caused by the state of their account are reported as failed authentications.
+func checkUserPasswordErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, authentication.ErrPasswordExpired):
+		return passwordExpiredMessage
+	case errors.Is(err, authentication.ErrAccountDisabled):
+		return accountDisabledMessage
+	case errors.Is(err, authentication.ErrAccountLocked):
+		return accountLockedMessage
+	default:
+		return authenticationFailedMessage
+	}
+}
+
 // FirstFactorPost is the handler performing the first factory.
 //nolint:gocyclo // TODO: Consider refactoring time permitting.
 func FirstFactorPost(msInitialDelay time.Duration, delayEnabled bool) middlewares.RequestHandler {
@@ -93,21 +113,17 @@ func FirstFactorPost(msInitialDelay time.Duration, delayEnabled bool) middleware
 		userPasswordOk, err := ctx.Providers.UserProvider.CheckUserPassword(bodyJSON.Username, bodyJSON.Password)
 
 		if err != nil {
-			ctx.Logger.Debugf("Mark authentication attempt made by user %s", bodyJSON.Username)
-
-			if err := ctx.Providers.Regulator.Mark(bodyJSON.Username, false); err != nil {
+			if err := markAuthenticationAttempt(ctx, bodyJSON.Username, false, models.AuthenticationTypeFirstFactor); err != nil {
 				ctx.Logger.Errorf("Unable to mark authentication: %s", err.Error())
 			}
 
-			handleAuthenticationUnauthorized(ctx, fmt.Errorf("Error while checking password for user %s: %s", bodyJSON.Username, err.Error()), authenticationFailedMessage)
+			handleAuthenticationUnauthorized(ctx, fmt.Errorf("Error while checking password for user %s: %s", bodyJSON.Username, err.Error()), checkUserPasswordErrorMessage(err))
 
 			return
 		}
 
 		if !userPasswordOk {
-			ctx.Logger.Debugf("Mark authentication attempt made by user %s", bodyJSON.Username)
-
-			if err := ctx.Providers.Regulator.Mark(bodyJSON.Username, false); err != nil {
+			if err := markAuthenticationAttempt(ctx, bodyJSON.Username, false, models.AuthenticationTypeFirstFactor); err != nil {
 				ctx.Logger.Errorf("Unable to mark authentication: %s", err.Error())
 			}
 
@@ -116,8 +132,31 @@ func FirstFactorPost(msInitialDelay time.Duration, delayEnabled bool) middleware
 			return
 		}
 
-		ctx.Logger.Debugf("Mark authentication attempt made by user %s", bodyJSON.Username)
-		err = ctx.Providers.Regulator.Mark(bodyJSON.Username, true)
+		riskDecision := assessLoginRisk(ctx, bodyJSON.Username)
+
+		if riskDecision == risk.Deny {
+			if err := markAuthenticationAttempt(ctx, bodyJSON.Username, false, models.AuthenticationTypeFirstFactor); err != nil {
+				ctx.Logger.Errorf("Unable to mark authentication: %s", err.Error())
+			}
+
+			handleAuthenticationUnauthorized(ctx, fmt.Errorf("Login of user %s was denied by the risk engine", bodyJSON.Username), authenticationFailedMessage)
+
+			return
+		}
+
+		breakGlassExpiresAt, err := breakGlassWindow(ctx, bodyJSON.Username)
+
+		if err != nil {
+			if err := markAuthenticationAttempt(ctx, bodyJSON.Username, false, models.AuthenticationTypeFirstFactor); err != nil {
+				ctx.Logger.Errorf("Unable to mark authentication: %s", err.Error())
+			}
+
+			handleAuthenticationUnauthorized(ctx, err, authenticationFailedMessage)
+
+			return
+		}
+
+		err = markAuthenticationAttempt(ctx, bodyJSON.Username, true, models.AuthenticationTypeFirstFactor)
 
 		if err != nil {
 			handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to mark authentication: %s", err.Error()), authenticationFailedMessage)
@@ -168,6 +207,15 @@ func FirstFactorPost(msInitialDelay time.Duration, delayEnabled bool) middleware
 		ctx.Logger.Tracef("Details for user %s => groups: %s, emails %s", bodyJSON.Username, userDetails.Groups, userDetails.Emails)
 
 		userSession.SetOneFactor(ctx.Clock.Now(), userDetails, keepMeLoggedIn)
+		userSession.SetFirstFactorClient(ctx.ClientMetadata())
+		userSession.Pin = ctx.SessionPin()
+		userSession.StepUpRequired = riskDecision == risk.StepUp
+
+		if !breakGlassExpiresAt.IsZero() {
+			// The break-glass accounts bypass the authentication backend so they always need the second factor.
+			userSession.StepUpRequired = true
+			userSession.BreakGlassExpiresAt = breakGlassExpiresAt.Unix()
+		}
 
 		if refresh, refreshInterval := getProfileRefreshSettings(ctx.Configuration.AuthenticationBackend); refresh {
 			userSession.RefreshTTL = ctx.Clock.Now().Add(refreshInterval)
@@ -181,10 +229,30 @@ func FirstFactorPost(msInitialDelay time.Duration, delayEnabled bool) middleware
 
 		successful = true
 
+		if !breakGlassExpiresAt.IsZero() {
+			ctx.PublishEvent(events.BreakGlassUsed, userSession.Username, map[string]string{
+				events.DetailExpiresAt: breakGlassExpiresAt.UTC().Format(time.RFC3339),
+			})
+		}
+
 		if userSession.OIDCWorkflowSession != nil {
 			handleOIDCWorkflowResponse(ctx)
 		} else {
-			Handle1FAResponse(ctx, bodyJSON.TargetURL, bodyJSON.RequestMethod, userSession.Username, userSession.Groups)
+			Handle1FAResponse(ctx, bodyJSON.TargetURL, bodyJSON.State, bodyJSON.RequestMethod, userSession.Username, userSession.Groups)
 		}
 	}
 }
+
+// newAuthenticationAttempt creates an authentication attempt of the given type for the given user including the client
+// information.
+func newAuthenticationAttempt(ctx *middlewares.AutheliaCtx, username string, successful bool, authType string) models.AuthenticationAttempt {
+	client := ctx.ClientMetadata()
+
+	return models.AuthenticationAttempt{
+		Username:   username,
+		Successful: successful,
+		RemoteIP:   client.RemoteIP,
+		UserAgent:  client.UserAgent,
+		Type:       authType,
+	}
+}
@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/authelia/authelia/internal/events"
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/regulation"
+	"github.com/authelia/authelia/internal/risk"
+	"github.com/authelia/authelia/internal/session"
+	"github.com/authelia/authelia/internal/storage"
+	"github.com/authelia/authelia/internal/webauthn"
+)
+
+// FirstFactorPasskeyOptionsPost handler for initiating a passwordless login with a passkey. The options don't list any
+// credential so the authenticator lets the user pick one of the discoverable credentials it holds for the domain.
+func FirstFactorPasskeyOptionsPost(ctx *middlewares.AutheliaCtx) {
+	rp, err := newWebauthnRelyingParty(ctx)
+	if err != nil {
+		handleAuthenticationUnauthorized(ctx, err, authenticationFailedMessage)
+		return
+	}
+
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to create WebAuthn challenge: %s", err), authenticationFailedMessage)
+		return
+	}
+
+	userSession := ctx.GetSession()
+	userSession.WebauthnChallenge = challenge
+
+	if err = ctx.SaveSession(userSession); err != nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to save WebAuthn challenge in session: %s", err), authenticationFailedMessage)
+		return
+	}
+
+	options := rp.NewRequestOptions(challenge, webauthnUserVerification(isPasskeyTwoFactor(ctx)))
+
+	if err = ctx.SetJSONBody(options); err != nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to set WebAuthn request options in body: %s", err), authenticationFailedMessage)
+		return
+	}
+}
+
+// FirstFactorPasskeyPost handler for completing a passwordless login with a passkey. Depending on the configured
+// policy the passkey satisfies either the first factor only or both factors.
+//
+//nolint:gocyclo // TODO: Consider refactoring/simplifying, time permitting.
+func FirstFactorPasskeyPost(ctx *middlewares.AutheliaCtx) {
+	bodyJSON := firstFactorPasskeyRequestBody{}
+
+	if err := ctx.ParseBody(&bodyJSON); err != nil {
+		handleAuthenticationUnauthorized(ctx, err, authenticationFailedMessage)
+		return
+	}
+
+	userSession := ctx.GetSession()
+
+	if userSession.WebauthnChallenge == nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Passkey login has not been initiated yet (no challenge)"), authenticationFailedMessage)
+		return
+	}
+
+	challenge := userSession.WebauthnChallenge
+	userSession.WebauthnChallenge = nil
+
+	if err := ctx.SaveSession(userSession); err != nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to clear WebAuthn challenge from session: %s", err), authenticationFailedMessage)
+		return
+	}
+
+	credential, err := ctx.Providers.StorageProvider.LoadWebauthnCredential(bodyJSON.Credential.RawID)
+	if err != nil {
+		if err == storage.ErrNoWebauthnCredential {
+			handleAuthenticationUnauthorized(ctx, fmt.Errorf("Passkey is not registered"), authenticationFailedMessage)
+			return
+		}
+
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to load WebAuthn credential: %s", err), authenticationFailedMessage)
+
+		return
+	}
+
+	if !credential.IsPasskey() {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("WebAuthn credential of user %s is not a passkey", credential.Username), authenticationFailedMessage)
+		return
+	}
+
+	username := credential.Username
+
+	if userHandle := bodyJSON.Credential.Response.UserHandle; userHandle != nil && !bytes.Equal(userHandle, []byte(username)) {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Passkey user handle does not match user %s", username), authenticationFailedMessage)
+		return
+	}
+
+	bannedUntil, err := ctx.Providers.Regulator.Regulate(username)
+	if err != nil {
+		if err == regulation.ErrUserIsBanned {
+			handleAuthenticationUnauthorized(ctx, fmt.Errorf("User %s is banned until %s", username, bannedUntil), userBannedMessage)
+			return
+		}
+
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to regulate authentication: %s", err), authenticationFailedMessage)
+
+		return
+	}
+
+	rp, err := newWebauthnRelyingParty(ctx)
+	if err != nil {
+		handleAuthenticationUnauthorized(ctx, err, authenticationFailedMessage)
+		return
+	}
+
+	twoFactor := isPasskeyTwoFactor(ctx)
+
+	signCount, err := rp.VerifyAssertion(challenge, webauthn.Credential{
+		ID:        credential.ID,
+		PublicKey: credential.PublicKey,
+		SignCount: credential.SignCount,
+	}, bodyJSON.Credential, twoFactor)
+	if err != nil {
+		if err := markAuthenticationAttempt(ctx, username, false, models.AuthenticationTypePasskey); err != nil {
+			ctx.Logger.Errorf("Unable to mark authentication: %s", err)
+		}
+
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to verify passkey of user %s: %s", username, err), authenticationFailedMessage)
+
+		return
+	}
+
+	riskDecision := assessLoginRisk(ctx, username)
+
+	if riskDecision == risk.Deny {
+		if err := markAuthenticationAttempt(ctx, username, false, models.AuthenticationTypePasskey); err != nil {
+			ctx.Logger.Errorf("Unable to mark authentication: %s", err)
+		}
+
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Login of user %s was denied by the risk engine", username), authenticationFailedMessage)
+
+		return
+	}
+
+	breakGlassExpiresAt, err := breakGlassWindow(ctx, username)
+	if err != nil {
+		if err := markAuthenticationAttempt(ctx, username, false, models.AuthenticationTypePasskey); err != nil {
+			ctx.Logger.Errorf("Unable to mark authentication: %s", err)
+		}
+
+		handleAuthenticationUnauthorized(ctx, err, authenticationFailedMessage)
+
+		return
+	}
+
+	if err = markAuthenticationAttempt(ctx, username, true, models.AuthenticationTypePasskey); err != nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to mark authentication: %s", err), authenticationFailedMessage)
+		return
+	}
+
+	if err = ctx.Providers.StorageProvider.UpdateWebauthnCredentialSignCount(credential.ID, signCount); err != nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to update passkey signature counter of user %s: %s", username, err), authenticationFailedMessage)
+		return
+	}
+
+	ctx.Logger.Debugf("Passkey validation of user %s is ok", username)
+
+	newSession := session.NewDefaultUserSession()
+	newSession.OIDCWorkflowSession = userSession.OIDCWorkflowSession
+
+	// Reset all values from previous session except OIDC workflow before regenerating the cookie.
+	if err = ctx.SaveSession(newSession); err != nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to reset the session for user %s: %s", username, err), authenticationFailedMessage)
+		return
+	}
+
+	if err = ctx.Providers.SessionProvider.RegenerateSession(ctx.RequestCtx); err != nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to regenerate session for user %s: %s", username, err), authenticationFailedMessage)
+		return
+	}
+
+	keepMeLoggedIn := ctx.Providers.SessionProvider.RememberMe != 0 && bodyJSON.KeepMeLoggedIn != nil && *bodyJSON.KeepMeLoggedIn
+
+	if keepMeLoggedIn {
+		err = ctx.Providers.SessionProvider.UpdateExpiration(ctx.RequestCtx, ctx.Providers.SessionProvider.RememberMe)
+		if err != nil {
+			handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to update expiration timer for user %s: %s", username, err), authenticationFailedMessage)
+			return
+		}
+	}
+
+	userDetails, err := ctx.Providers.UserProvider.GetDetails(username)
+	if err != nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Error while retrieving details from user %s: %s", username, err), authenticationFailedMessage)
+		return
+	}
+
+	client := ctx.ClientMetadata()
+
+	newSession.SetOneFactor(ctx.Clock.Now(), userDetails, keepMeLoggedIn)
+	newSession.SetFirstFactorClient(client)
+	newSession.Pin = ctx.SessionPin()
+	newSession.FirstFactorWebauthnCredentialID = credential.ID
+
+	if twoFactor {
+		newSession.SetTwoFactor(ctx.Clock.Now())
+		newSession.SetSecondFactorClient(client)
+	} else {
+		newSession.StepUpRequired = riskDecision == risk.StepUp || !breakGlassExpiresAt.IsZero()
+	}
+
+	if !breakGlassExpiresAt.IsZero() {
+		newSession.BreakGlassExpiresAt = breakGlassExpiresAt.Unix()
+	}
+
+	if refresh, refreshInterval := getProfileRefreshSettings(ctx.Configuration.AuthenticationBackend); refresh {
+		newSession.RefreshTTL = ctx.Clock.Now().Add(refreshInterval)
+	}
+
+	if err = ctx.SaveSession(newSession); err != nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to save session of user %s", username), authenticationFailedMessage)
+		return
+	}
+
+	if !breakGlassExpiresAt.IsZero() {
+		ctx.PublishEvent(events.BreakGlassUsed, newSession.Username, map[string]string{
+			events.DetailExpiresAt: breakGlassExpiresAt.UTC().Format(time.RFC3339),
+		})
+	}
+
+	switch {
+	case newSession.OIDCWorkflowSession != nil:
+		handleOIDCWorkflowResponse(ctx)
+	case twoFactor:
+		Handle2FAResponse(ctx, bodyJSON.TargetURL, bodyJSON.State)
+	default:
+		Handle1FAResponse(ctx, bodyJSON.TargetURL, bodyJSON.State, bodyJSON.RequestMethod, newSession.Username, newSession.Groups)
+	}
+}
@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/regulation"
+	"github.com/authelia/authelia/internal/webauthn"
+	"github.com/authelia/authelia/pkg/autheliatest"
+)
+
+type FirstFactorPasskeySuite struct {
+	suite.Suite
+
+	mock          *mocks.MockAutheliaCtx
+	storage       *autheliatest.MemoryStorageProvider
+	authenticator *mocks.WebauthnAuthenticator
+}
+
+func (s *FirstFactorPasskeySuite) SetupTest() {
+	s.mock = mocks.NewMockAutheliaCtx(s.T())
+	s.mock.Ctx.Configuration.Session.Domain = "example.com"
+	s.mock.Ctx.Configuration.Webauthn = newTestWebauthnConfiguration("two_factor")
+	s.mock.Ctx.Configuration.Server.ExternalURL = "https://login.example.com"
+
+	s.storage = autheliatest.NewMemoryStorageProvider()
+	s.mock.Ctx.Providers.StorageProvider = s.storage
+	s.mock.Ctx.Providers.Regulator = regulation.NewRegulator(nil, s.storage, &s.mock.Clock)
+
+	s.authenticator = mocks.NewWebauthnAuthenticator([]byte(testUsername))
+
+	s.Require().NoError(s.storage.SaveWebauthnCredential(models.WebauthnCredential{
+		ID:        s.authenticator.CredentialID,
+		Username:  testUsername,
+		Type:      models.WebauthnCredentialTypePasskey,
+		PublicKey: s.authenticator.PublicKey(),
+	}))
+}
+
+func (s *FirstFactorPasskeySuite) TearDownTest() {
+	s.mock.Close()
+}
+
+func (s *FirstFactorPasskeySuite) startLogin() []byte {
+	FirstFactorPasskeyOptionsPost(s.mock.Ctx)
+	s.Require().Equal(200, s.mock.Ctx.Response.StatusCode())
+
+	challenge := s.mock.Ctx.GetSession().WebauthnChallenge
+	s.Require().Len(challenge, 32)
+
+	s.mock.Ctx.Response.Reset()
+
+	return challenge
+}
+
+func (s *FirstFactorPasskeySuite) setBody(assertion webauthn.CredentialAssertion) {
+	body, err := json.Marshal(firstFactorPasskeyRequestBody{Credential: assertion})
+	s.Require().NoError(err)
+	s.mock.Ctx.Request.SetBody(body)
+}
+
+func (s *FirstFactorPasskeySuite) TestShouldAuthenticateWithBothFactors() {
+	s.mock.UserProviderMock.
+		EXPECT().
+		GetDetails(gomock.Eq(testUsername)).
+		Return(&authentication.UserDetails{
+			Username: testUsername,
+			Emails:   []string{"john@example.com"},
+			Groups:   []string{"dev"},
+		}, nil)
+
+	challenge := s.startLogin()
+	s.setBody(s.authenticator.Get("example.com", "https://login.example.com", challenge))
+
+	FirstFactorPasskeyPost(s.mock.Ctx)
+
+	s.Assert().Equal(200, s.mock.Ctx.Response.StatusCode())
+	s.Assert().Equal([]byte("{\"status\":\"OK\"}"), s.mock.Ctx.Response.Body())
+
+	userSession := s.mock.Ctx.GetSession()
+	s.Assert().Equal(testUsername, userSession.Username)
+	s.Assert().Equal(authentication.TwoFactor, userSession.AuthenticationLevel)
+	s.Assert().NotNil(userSession.SecondFactorClient)
+	s.Assert().Nil(userSession.WebauthnChallenge)
+
+	credential, err := s.storage.LoadWebauthnCredential(s.authenticator.CredentialID)
+	s.Require().NoError(err)
+	s.Assert().Equal(uint32(1), credential.SignCount)
+
+	attempts, err := s.storage.LoadLatestAuthenticationLogs(testUsername, s.mock.Clock.Now().Add(-1))
+	s.Require().NoError(err)
+	s.Require().Len(attempts, 1)
+	s.Assert().True(attempts[0].Successful)
+}
+
+func (s *FirstFactorPasskeySuite) TestShouldAuthenticateWithOneFactorPolicy() {
+	s.mock.Ctx.Configuration.Webauthn = newTestWebauthnConfiguration("one_factor")
+	s.authenticator.UserVerified = false
+
+	s.mock.UserProviderMock.
+		EXPECT().
+		GetDetails(gomock.Eq(testUsername)).
+		Return(&authentication.UserDetails{Username: testUsername}, nil)
+
+	challenge := s.startLogin()
+	s.setBody(s.authenticator.Get("example.com", "https://login.example.com", challenge))
+
+	FirstFactorPasskeyPost(s.mock.Ctx)
+
+	s.Assert().Equal(200, s.mock.Ctx.Response.StatusCode())
+	s.Assert().Equal(authentication.OneFactor, s.mock.Ctx.GetSession().AuthenticationLevel)
+}
+
+func (s *FirstFactorPasskeySuite) TestShouldNotUsePasskeyAsSecondFactorOfSameSession() {
+	s.mock.Ctx.Configuration.Webauthn = newTestWebauthnConfiguration("one_factor")
+	s.authenticator.UserVerified = false
+
+	s.mock.UserProviderMock.
+		EXPECT().
+		GetDetails(gomock.Eq(testUsername)).
+		Return(&authentication.UserDetails{Username: testUsername}, nil)
+
+	challenge := s.startLogin()
+	s.setBody(s.authenticator.Get("example.com", "https://login.example.com", challenge))
+
+	FirstFactorPasskeyPost(s.mock.Ctx)
+
+	s.Require().Equal(200, s.mock.Ctx.Response.StatusCode())
+
+	userSession := s.mock.Ctx.GetSession()
+	s.Require().Equal(authentication.OneFactor, userSession.AuthenticationLevel)
+	s.Assert().Equal(s.authenticator.CredentialID, userSession.FirstFactorWebauthnCredentialID)
+
+	// The passkey isn't offered as a second factor.
+	s.mock.Ctx.Response.Reset()
+
+	SecondFactorWebauthnAssertionOptionsPost(s.mock.Ctx)
+
+	s.Assert().Equal(401, s.mock.Ctx.Response.StatusCode())
+	s.Assert().Equal("No WebAuthn credential found for user john", s.mock.Hook.LastEntry().Message)
+
+	// Nor is it accepted when it's asserted anyway.
+	s.mock.Ctx.Response.Reset()
+
+	userSession = s.mock.Ctx.GetSession()
+	userSession.WebauthnChallenge = []byte("0123456789abcdef0123456789abcdef")
+	s.Require().NoError(s.mock.Ctx.SaveSession(userSession))
+
+	body, err := json.Marshal(signWebauthnRequestBody{
+		Credential: s.authenticator.Get("example.com", "https://login.example.com", userSession.WebauthnChallenge),
+	})
+	s.Require().NoError(err)
+	s.mock.Ctx.Request.SetBody(body)
+
+	SecondFactorWebauthnPost(s.mock.Ctx)
+
+	s.Assert().Equal(401, s.mock.Ctx.Response.StatusCode())
+	s.Assert().Equal("WebAuthn credential of user john can't be used as a second factor", s.mock.Hook.LastEntry().Message)
+	s.Assert().Equal(authentication.OneFactor, s.mock.Ctx.GetSession().AuthenticationLevel)
+}
+
+func (s *FirstFactorPasskeySuite) TestShouldFailWithSecondFactorCredential() {
+	authenticator := mocks.NewWebauthnAuthenticator([]byte(testUsername))
+
+	s.Require().NoError(s.storage.SaveWebauthnCredential(models.WebauthnCredential{
+		ID:        authenticator.CredentialID,
+		Username:  testUsername,
+		Type:      models.WebauthnCredentialTypeSecondFactor,
+		PublicKey: authenticator.PublicKey(),
+	}))
+
+	challenge := s.startLogin()
+	s.setBody(authenticator.Get("example.com", "https://login.example.com", challenge))
+
+	FirstFactorPasskeyPost(s.mock.Ctx)
+
+	s.Assert().Equal(401, s.mock.Ctx.Response.StatusCode())
+	s.Assert().Equal("WebAuthn credential of user john is not a passkey", s.mock.Hook.LastEntry().Message)
+	s.Assert().Equal(authentication.NotAuthenticated, s.mock.Ctx.GetSession().AuthenticationLevel)
+}
+
+func (s *FirstFactorPasskeySuite) TestShouldFailWhenUserIsNotVerified() {
+	s.authenticator.UserVerified = false
+
+	challenge := s.startLogin()
+	s.setBody(s.authenticator.Get("example.com", "https://login.example.com", challenge))
+
+	FirstFactorPasskeyPost(s.mock.Ctx)
+
+	s.mock.Assert401KO(s.T(), "Authentication failed. Check your credentials.")
+	s.Assert().Equal("Unable to verify passkey of user john: user verification is required", s.mock.Hook.LastEntry().Message)
+
+	attempts, err := s.storage.LoadLatestAuthenticationLogs(testUsername, s.mock.Clock.Now().Add(-1))
+	s.Require().NoError(err)
+	s.Require().Len(attempts, 1)
+	s.Assert().False(attempts[0].Successful)
+}
+
+func (s *FirstFactorPasskeySuite) TestShouldFailWhenChallengeIsReplayed() {
+	s.mock.UserProviderMock.
+		EXPECT().
+		GetDetails(gomock.Eq(testUsername)).
+		Return(&authentication.UserDetails{Username: testUsername}, nil)
+
+	challenge := s.startLogin()
+	assertion := s.authenticator.Get("example.com", "https://login.example.com", challenge)
+
+	s.setBody(assertion)
+	FirstFactorPasskeyPost(s.mock.Ctx)
+	s.Require().Equal(200, s.mock.Ctx.Response.StatusCode())
+
+	s.mock.Ctx.Response.Reset()
+	s.setBody(assertion)
+	FirstFactorPasskeyPost(s.mock.Ctx)
+
+	s.mock.Assert401KO(s.T(), "Authentication failed. Check your credentials.")
+	s.Assert().Equal("Passkey login has not been initiated yet (no challenge)", s.mock.Hook.LastEntry().Message)
+}
+
+func (s *FirstFactorPasskeySuite) TestShouldFailWhenPasskeyIsNotRegistered() {
+	other := mocks.NewWebauthnAuthenticator([]byte(testUsername))
+
+	challenge := s.startLogin()
+	s.setBody(other.Get("example.com", "https://login.example.com", challenge))
+
+	FirstFactorPasskeyPost(s.mock.Ctx)
+
+	s.mock.Assert401KO(s.T(), "Authentication failed. Check your credentials.")
+	s.Assert().Equal("Passkey is not registered", s.mock.Hook.LastEntry().Message)
+}
+
+func newTestWebauthnConfiguration(policy string) schema.WebauthnConfiguration {
+	return schema.WebauthnConfiguration{
+		DisplayName:  "Authelia",
+		Passwordless: schema.WebauthnPasswordlessConfiguration{Enable: true, Policy: policy},
+	}
+}
+
+func TestRunFirstFactorPasskeySuite(t *testing.T) {
+	suite.Run(t, new(FirstFactorPasskeySuite))
+}
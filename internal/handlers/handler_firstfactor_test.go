@@ -13,10 +13,18 @@ import (
 	"github.com/authelia/authelia/internal/authentication"
 	"github.com/authelia/authelia/internal/authorization"
 	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/metrics"
 	"github.com/authelia/authelia/internal/mocks"
 	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/risk"
 )
 
+type fixedRiskEngine int
+
+func (e fixedRiskEngine) Score(_ risk.Evaluation) (score int, err error) {
+	return int(e), nil
+}
+
 type FirstFactorSuite struct {
 	suite.Suite
 
@@ -62,6 +70,8 @@ func (s *FirstFactorSuite) TestShouldFailIfUserProviderCheckPasswordFail() {
 			Username:   "test",
 			Successful: false,
 			Time:       s.mock.Clock.Now(),
+			RemoteIP:   "0.0.0.0",
+			Type:       models.AuthenticationTypeFirstFactor,
 		}))
 
 	s.mock.Ctx.Request.SetBodyString(`{
@@ -75,6 +85,40 @@ func (s *FirstFactorSuite) TestShouldFailIfUserProviderCheckPasswordFail() {
 	s.mock.Assert401KO(s.T(), "Authentication failed. Check your credentials.")
 }
 
+func (s *FirstFactorSuite) TestShouldTellUserWhyTheirAccountCannotLogIn() {
+	testCases := []struct {
+		err     error
+		message string
+	}{
+		{authentication.ErrPasswordExpired, "Your password has expired, please reset it."},
+		{authentication.ErrAccountDisabled, "Your account is disabled."},
+		{authentication.ErrAccountLocked, "Your account is locked out, please retry later."},
+	}
+
+	for _, tc := range testCases {
+		mock := mocks.NewMockAutheliaCtx(s.T())
+
+		mock.UserProviderMock.
+			EXPECT().
+			CheckUserPassword(gomock.Eq("test"), gomock.Eq("hello")).
+			Return(false, fmt.Errorf("Authentication of user test failed. Cause: %w", tc.err))
+
+		mock.StorageProviderMock.
+			EXPECT().
+			AppendAuthenticationLog(gomock.Any())
+
+		mock.Ctx.Request.SetBodyString(`{
+			"username": "test",
+			"password": "hello",
+			"keepMeLoggedIn": true
+		}`)
+		FirstFactorPost(0, false)(mock.Ctx)
+
+		mock.Assert401KO(s.T(), tc.message)
+		mock.Close()
+	}
+}
+
 func (s *FirstFactorSuite) TestShouldCheckAuthenticationIsMarkedWhenInvalidCredentials() {
 	s.mock.UserProviderMock.
 		EXPECT().
@@ -87,15 +131,25 @@ func (s *FirstFactorSuite) TestShouldCheckAuthenticationIsMarkedWhenInvalidCrede
 			Username:   "test",
 			Successful: false,
 			Time:       s.mock.Clock.Now(),
+			RemoteIP:   "192.168.0.10",
+			UserAgent:  "Mozilla/5.0 (X11; Linux x86_64; rv:88.0) Gecko/20100101 Firefox/88.0",
+			Type:       models.AuthenticationTypeFirstFactor,
 		}))
 
+	s.mock.Ctx.Request.Header.Set("X-Forwarded-For", "192.168.0.10")
+	s.mock.Ctx.Request.Header.SetUserAgent("Mozilla/5.0 (X11; Linux x86_64; rv:88.0) Gecko/20100101 Firefox/88.0")
+
 	s.mock.Ctx.Request.SetBodyString(`{
 		"username": "test",
 		"password": "hello",
 		"keepMeLoggedIn": true
 	}`)
 
+	failures := metrics.FirstFactorAttempts.Value("false")
+
 	FirstFactorPost(0, false)(s.mock.Ctx)
+
+	s.Assert().Equal(failures+1, metrics.FirstFactorAttempts.Value("false"))
 }
 
 func (s *FirstFactorSuite) TestShouldFailIfUserProviderGetDetailsFail() {
@@ -172,6 +226,7 @@ func (s *FirstFactorSuite) TestShouldAuthenticateUserWithRememberMeChecked() {
 		"password": "hello",
 		"keepMeLoggedIn": true
 	}`)
+	s.mock.Ctx.Request.Header.SetUserAgent("Mozilla/5.0 (X11; Linux x86_64; rv:88.0) Gecko/20100101 Firefox/88.0")
 	FirstFactorPost(0, false)(s.mock.Ctx)
 
 	// Respond with 200.
@@ -185,6 +240,12 @@ func (s *FirstFactorSuite) TestShouldAuthenticateUserWithRememberMeChecked() {
 	assert.Equal(s.T(), authentication.OneFactor, session.AuthenticationLevel)
 	assert.Equal(s.T(), []string{"test@example.com"}, session.Emails)
 	assert.Equal(s.T(), []string{"dev", "admins"}, session.Groups)
+
+	// And record the client which authenticated.
+	s.Require().NotNil(session.FirstFactorClient)
+	assert.Equal(s.T(), "0.0.0.0", session.FirstFactorClient.RemoteIP)
+	assert.Equal(s.T(), "Firefox on Linux", session.FirstFactorClient.Device)
+	assert.InDelta(s.T(), session.FirstFactorAuthnTimestamp, session.FirstFactorClient.Timestamp, 1)
 }
 
 func (s *FirstFactorSuite) TestShouldAuthenticateUserWithRememberMeUnchecked() {
@@ -228,6 +289,123 @@ func (s *FirstFactorSuite) TestShouldAuthenticateUserWithRememberMeUnchecked() {
 	assert.Equal(s.T(), []string{"dev", "admins"}, session.Groups)
 }
 
+func (s *FirstFactorSuite) TestShouldPinSessionToClient() {
+	s.mock.Ctx.Configuration.Session.Pinning = schema.SessionPinningConfiguration{
+		IP:                   true,
+		TLSFingerprint:       true,
+		TLSFingerprintHeader: "X-JA3-Fingerprint",
+	}
+
+	s.mock.UserProviderMock.
+		EXPECT().
+		CheckUserPassword(gomock.Eq("test"), gomock.Eq("hello")).
+		Return(true, nil)
+
+	s.mock.UserProviderMock.
+		EXPECT().
+		GetDetails(gomock.Eq("test")).
+		Return(&authentication.UserDetails{Username: "test"}, nil)
+
+	s.mock.StorageProviderMock.
+		EXPECT().
+		AppendAuthenticationLog(gomock.Any()).
+		Return(nil)
+
+	s.mock.Ctx.Request.SetBodyString(`{
+		"username": "test",
+		"password": "hello",
+		"keepMeLoggedIn": false
+	}`)
+	s.mock.Ctx.Request.Header.Set("X-Forwarded-For", "192.168.1.20")
+	s.mock.Ctx.Request.Header.Set("X-JA3-Fingerprint", "e7d705a3286e19ea42f587b344ee6865")
+	FirstFactorPost(0, false)(s.mock.Ctx)
+
+	assert.Equal(s.T(), 200, s.mock.Ctx.Response.StatusCode())
+
+	session := s.mock.Ctx.GetSession()
+	s.Require().NotNil(session.Pin)
+	assert.Equal(s.T(), "192.168.1.0", session.Pin.IPNetwork)
+	assert.Equal(s.T(), "e7d705a3286e19ea42f587b344ee6865", session.Pin.TLSFingerprint)
+}
+
+func (s *FirstFactorSuite) TestShouldDenyAuthenticationWhenRiskIsTooHigh() {
+	s.mock.Ctx.Providers.Risk = risk.NewEvaluatorWithEngine(fixedRiskEngine(100), s.mock.StorageProviderMock, nil, time.Hour, time.Hour, 30, 80)
+
+	s.mock.UserProviderMock.
+		EXPECT().
+		CheckUserPassword(gomock.Eq("test"), gomock.Eq("hello")).
+		Return(true, nil)
+
+	s.mock.StorageProviderMock.
+		EXPECT().
+		LoadLatestAuthenticationLogs(gomock.Eq("test"), gomock.Any()).
+		Return(nil, nil)
+
+	s.mock.StorageProviderMock.
+		EXPECT().
+		AppendAuthenticationLog(gomock.Any()).
+		DoAndReturn(func(attempt models.AuthenticationAttempt) error {
+			assert.False(s.T(), attempt.Successful)
+			return nil
+		})
+
+	s.mock.Ctx.Request.SetBodyString(`{
+		"username": "test",
+		"password": "hello",
+		"keepMeLoggedIn": false
+	}`)
+	FirstFactorPost(0, false)(s.mock.Ctx)
+
+	assert.Equal(s.T(), "Login of user test was denied by the risk engine", s.mock.Hook.LastEntry().Message)
+	s.mock.Assert401KO(s.T(), "Authentication failed. Check your credentials.")
+
+	assert.Equal(s.T(), authentication.NotAuthenticated, s.mock.Ctx.GetSession().AuthenticationLevel)
+}
+
+func (s *FirstFactorSuite) TestShouldRequireStepUpWhenRiskIsElevated() {
+	s.mock.Ctx.Providers.Risk = risk.NewEvaluatorWithEngine(fixedRiskEngine(50), s.mock.StorageProviderMock, nil, time.Hour, time.Hour, 30, 80)
+
+	s.mock.UserProviderMock.
+		EXPECT().
+		CheckUserPassword(gomock.Eq("test"), gomock.Eq("hello")).
+		Return(true, nil)
+
+	s.mock.UserProviderMock.
+		EXPECT().
+		GetDetails(gomock.Eq("test")).
+		Return(&authentication.UserDetails{
+			Username: "test",
+			Emails:   []string{"test@example.com"},
+			Groups:   []string{"dev", "admins"},
+		}, nil)
+
+	s.mock.StorageProviderMock.
+		EXPECT().
+		LoadLatestAuthenticationLogs(gomock.Eq("test"), gomock.Any()).
+		Return(nil, nil)
+
+	s.mock.StorageProviderMock.
+		EXPECT().
+		AppendAuthenticationLog(gomock.Any()).
+		Return(nil)
+
+	s.mock.Ctx.Request.SetBodyString(`{
+		"username": "test",
+		"password": "hello",
+		"targetURL": "https://one-factor.example.com",
+		"keepMeLoggedIn": false
+	}`)
+	FirstFactorPost(0, false)(s.mock.Ctx)
+
+	// Respond with 200 without redirecting since the second factor is required.
+	s.mock.Assert200OK(s.T(), nil)
+
+	session := s.mock.Ctx.GetSession()
+	assert.Equal(s.T(), authentication.OneFactor, session.AuthenticationLevel)
+	assert.True(s.T(), session.StepUpRequired)
+	assert.True(s.T(), session.IsStepUpPending())
+}
+
 func (s *FirstFactorSuite) TestShouldSaveUsernameFromAuthenticationBackendInSession() {
 	s.mock.UserProviderMock.
 		EXPECT().
@@ -4,8 +4,8 @@ import (
 	"fmt"
 	"net/url"
 
+	"github.com/authelia/authelia/internal/logout"
 	"github.com/authelia/authelia/internal/middlewares"
-	"github.com/authelia/authelia/internal/utils"
 )
 
 type logoutBody struct {
@@ -14,6 +14,11 @@ type logoutBody struct {
 
 type logoutResponseBody struct {
 	SafeTargetURL bool `json:"safeTargetURL"`
+
+	// The front-channel logout URIs of the OpenID Connect clients the portal loads in frames and the URI the client
+	// which initiated the logout redirects the user to.
+	FrontChannelLogoutURIs []string `json:"frontChannelLogoutURIs,omitempty"`
+	PostLogoutRedirectURI  string   `json:"postLogoutRedirectURI,omitempty"`
 }
 
 // LogoutPost is the handler logging out the user attached to the given cookie.
@@ -28,6 +33,8 @@ func LogoutPost(ctx *middlewares.AutheliaCtx) {
 		ctx.Error(fmt.Errorf("Unable to parse body during logout: %s", err), operationFailedMessage)
 	}
 
+	userSession := ctx.GetSession()
+
 	ctx.Logger.Tracef("Attempting to destroy session")
 
 	err = ctx.Providers.SessionProvider.DestroySession(ctx.RequestCtx)
@@ -35,9 +42,30 @@ func LogoutPost(ctx *middlewares.AutheliaCtx) {
 		ctx.Error(fmt.Errorf("Unable to destroy session during logout: %s", err), operationFailedMessage)
 	}
 
-	redirectionURL, err := url.Parse(body.TargetURL)
-	if err == nil {
-		responseBody.SafeTargetURL = utils.IsRedirectionSafe(*redirectionURL, ctx.Configuration.Session.Domain)
+	if ctx.Providers.Logout != nil && userSession.Username != "" {
+		ctx.Logger.Tracef("Attempting to log user %s out of the applications", userSession.Username)
+
+		ctx.Providers.Logout.Logout(logout.User{
+			Username:    userSession.Username,
+			DisplayName: userSession.DisplayName,
+			Emails:      userSession.Emails,
+			Groups:      userSession.Groups,
+		})
+	}
+
+	if ctx.Providers.OpenIDConnect.Store != nil && len(userSession.OIDCClients) != 0 {
+		responseBody.FrontChannelLogoutURIs = oidcLogoutClients(ctx, userSession.Username, userSession.OIDCSessionID, userSession.OIDCClients)
+	}
+
+	responseBody.PostLogoutRedirectURI = userSession.OIDCPostLogoutRedirectURI
+
+	if body.TargetURL != "" {
+		redirectionURL, err := url.Parse(body.TargetURL)
+		if err != nil {
+			logRejectedRedirection(ctx, redirectionFlowLogout, body.TargetURL, err)
+		} else {
+			responseBody.SafeTargetURL = isRedirectionSafe(ctx, redirectionFlowLogout, *redirectionURL)
+		}
 	}
 
 	if body.TargetURL != "" {
@@ -49,3 +77,20 @@ func LogoutPost(ctx *middlewares.AutheliaCtx) {
 		ctx.Error(fmt.Errorf("Unable to set body during logout: %s", err), operationFailedMessage)
 	}
 }
+
+// oidcLogoutClients logs the user out of the OpenID Connect clients they authorized during the session. The logout
+// tokens are sent to the back-channel logout URIs and the front-channel logout URIs loaded by the portal are returned.
+func oidcLogoutClients(ctx *middlewares.AutheliaCtx, username, sessionID string, clientIDs []string) (frontChannelLogoutURIs []string) {
+	issuer, err := ctx.ForwardedProtoHost()
+	if err != nil {
+		ctx.Logger.Errorf("Unable to log user %s out of the OpenID Connect clients: %v", username, err)
+
+		return nil
+	}
+
+	ctx.Logger.Tracef("Attempting to log user %s out of the OpenID Connect clients", username)
+
+	ctx.Providers.OpenIDConnect.BackChannelLogout(issuer, username, sessionID, clientIDs, ctx.Clock.Now())
+
+	return ctx.Providers.OpenIDConnect.FrontChannelLogoutURIs(issuer, sessionID, clientIDs)
+}
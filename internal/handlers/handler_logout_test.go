@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
@@ -8,6 +10,8 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/logout"
 	"github.com/authelia/authelia/internal/mocks"
 )
 
@@ -38,6 +42,54 @@ func (s *LogoutSuite) TestShouldDestroySession() {
 	assert.True(s.T(), strings.HasPrefix(string(b), "authelia_session=;"))
 }
 
+func (s *LogoutSuite) TestShouldRejectUnsafeTargetURL() {
+	s.mock.Ctx.Configuration.Session.Domain = "example.com"
+	s.mock.Ctx.Request.SetBodyString(`{"targetURL": "https://example.com.evil.net/"}`)
+
+	LogoutPost(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), logoutResponseBody{SafeTargetURL: false})
+
+	entry := s.mock.Hook.LastEntry()
+	s.Require().NotNil(entry)
+	s.Assert().Equal("Rejected an unsafe redirection target", entry.Message)
+	s.Assert().Equal("logout", entry.Data["flow"])
+	s.Assert().Equal("https://example.com.evil.net/", entry.Data["target"])
+	s.Assert().Equal("redirection URL domain isn't allowed", entry.Data["reason"])
+}
+
+func (s *LogoutSuite) TestShouldAcceptSafeTargetURL() {
+	s.mock.Ctx.Configuration.Session.Domain = "example.com"
+	s.mock.Ctx.Request.SetBodyString(`{"targetURL": "https://home.example.com/"}`)
+
+	LogoutPost(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), logoutResponseBody{SafeTargetURL: true})
+}
+
+func (s *LogoutSuite) TestShouldLogUserOutOfApplications() {
+	var requestURI string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestURI = r.URL.RequestURI()
+	}))
+	defer server.Close()
+
+	provider, err := logout.NewProvider(&schema.LogoutConfiguration{
+		Timeout: "1s",
+		Applications: []schema.LogoutApplicationConfiguration{
+			{Name: "app", URL: server.URL + "/logout?user={{ .Username }}", Method: "GET"},
+		},
+	})
+	s.Require().NoError(err)
+
+	s.mock.Ctx.Providers.Logout = provider
+
+	LogoutPost(s.mock.Ctx)
+
+	s.Assert().Equal("/logout?user=john", requestURI)
+}
+
 func TestRunLogoutSuite(t *testing.T) {
 	s := new(LogoutSuite)
 	suite.Run(t, s)
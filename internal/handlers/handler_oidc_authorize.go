@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/ory/fosite"
 	"github.com/ory/fosite/handler/openid"
 	"github.com/ory/fosite/token/jwt"
@@ -42,9 +43,10 @@ func oidcAuthorize(ctx *middlewares.AutheliaCtx, rw http.ResponseWriter, r *http
 	requestedScopes := ar.GetRequestedScopes()
 	requestedAudience := ar.GetRequestedAudience()
 
-	isAuthInsufficient := !client.IsAuthenticationLevelSufficient(userSession.AuthenticationLevel)
+	isAuthInsufficient := !client.IsAuthenticationLevelSufficient(userSession.AuthenticationLevel) || userSession.IsStepUpPending()
 
-	if isAuthInsufficient || (isConsentMissing(userSession.OIDCWorkflowSession, requestedScopes, requestedAudience)) {
+	if isAuthInsufficient || (isConsentMissing(userSession.OIDCWorkflowSession, requestedScopes, requestedAudience) &&
+		!isConsentRemembered(ctx, userSession.Username, client.ID, requestedScopes, requestedAudience)) {
 		oidcAuthorizeHandleAuthorizationOrConsentInsufficient(ctx, userSession, client, isAuthInsufficient, rw, r, ar)
 
 		return
@@ -52,7 +54,22 @@ func oidcAuthorize(ctx *middlewares.AutheliaCtx, rw http.ResponseWriter, r *http
 
 	extraClaims := oidcGrantRequests(ar, requestedScopes, requestedAudience, &userSession)
 
-	workflowCreated := time.Unix(userSession.OIDCWorkflowSession.CreatedTimestamp, 0)
+	// The workflow isn't initiated when the consent the user remembered is given without redirecting the user.
+	workflowCreated := ctx.Clock.Now()
+	if userSession.OIDCWorkflowSession != nil {
+		workflowCreated = time.Unix(userSession.OIDCWorkflowSession.CreatedTimestamp, 0)
+	}
+
+	// The clients the user authorized are tracked in the session so they can be logged out when the session ends.
+	if userSession.OIDCSessionID == "" {
+		userSession.OIDCSessionID = uuid.New().String()
+	}
+
+	if !utils.IsStringInSlice(clientID, userSession.OIDCClients) {
+		userSession.OIDCClients = append(userSession.OIDCClients, clientID)
+	}
+
+	extraClaims["sid"] = userSession.OIDCSessionID
 
 	userSession.OIDCWorkflowSession = nil
 	if err := ctx.SaveSession(userSession); err != nil {
@@ -85,7 +102,7 @@ func oidcAuthorize(ctx *middlewares.AutheliaCtx, rw http.ResponseWriter, r *http
 				Issuer:      issuer,
 				AuthTime:    authTime,
 				RequestedAt: workflowCreated,
-				IssuedAt:    time.Now(),
+				IssuedAt:    ctx.Clock.Now(),
 				Nonce:       ar.GetRequestForm().Get("nonce"),
 				Audience:    ar.GetGrantedAudience(),
 				Extra:       extraClaims,
@@ -165,7 +182,7 @@ func oidcAuthorizeHandleAuthorizationOrConsentInsufficient(
 		AuthURI:                    redirectURL,
 		TargetURI:                  ar.GetRedirectURI().String(),
 		RequiredAuthorizationLevel: client.Policy,
-		CreatedTimestamp:           time.Now().Unix(),
+		CreatedTimestamp:           ctx.Clock.Now().Unix(),
 	}
 
 	if err := ctx.SaveSession(userSession); err != nil {
@@ -3,8 +3,17 @@ package handlers
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"strings"
+	"time"
 
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/events"
 	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/oidc"
+	"github.com/authelia/authelia/internal/session"
+	"github.com/authelia/authelia/internal/utils"
 )
 
 func oidcConsent(ctx *middlewares.AutheliaCtx) {
@@ -27,14 +36,19 @@ func oidcConsent(ctx *middlewares.AutheliaCtx) {
 		return
 	}
 
-	if !client.IsAuthenticationLevelSufficient(userSession.AuthenticationLevel) {
+	if !client.IsAuthenticationLevelSufficient(userSession.AuthenticationLevel) || userSession.IsStepUpPending() {
 		ctx.Logger.Debugf("Insufficient permissions to give consent v2 %d -> %d", userSession.AuthenticationLevel, userSession.OIDCWorkflowSession.RequiredAuthorizationLevel)
 		ctx.ReplyForbidden()
 
 		return
 	}
 
-	if err := ctx.SetJSONBody(client.GetConsentResponseBody(userSession.OIDCWorkflowSession)); err != nil {
+	workflow := userSession.OIDCWorkflowSession
+
+	body := client.GetConsentResponseBody(workflow)
+	body.PreAuthorized = isConsentRemembered(ctx, userSession.Username, clientID, workflow.RequestedScopes, workflow.RequestedAudience)
+
+	if err := ctx.SetJSONBody(body); err != nil {
 		ctx.Error(fmt.Errorf("Unable to set JSON body: %v", err), "Operation failed")
 	}
 }
@@ -58,7 +72,7 @@ func oidcConsentPOST(ctx *middlewares.AutheliaCtx) {
 		return
 	}
 
-	if !client.IsAuthenticationLevelSufficient(userSession.AuthenticationLevel) {
+	if !client.IsAuthenticationLevelSufficient(userSession.AuthenticationLevel) || userSession.IsStepUpPending() {
 		ctx.Logger.Debugf("Insufficient permissions to give consent v1 %d -> %d", userSession.AuthenticationLevel, userSession.OIDCWorkflowSession.RequiredAuthorizationLevel)
 		ctx.ReplyForbidden()
 
@@ -88,17 +102,40 @@ func oidcConsentPOST(ctx *middlewares.AutheliaCtx) {
 		return
 	}
 
+	if !isConsentRedirectionSafe(ctx, client, userSession.OIDCWorkflowSession, body.AcceptOrReject) {
+		ctx.ReplyForbidden()
+
+		return
+	}
+
 	var redirectionURL string
 
+	details := map[string]string{
+		events.DetailClientID: body.ClientID,
+		events.DetailScopes:   strings.Join(userSession.OIDCWorkflowSession.RequestedScopes, " "),
+	}
+
 	if body.AcceptOrReject == accept {
 		redirectionURL = userSession.OIDCWorkflowSession.AuthURI
 		userSession.OIDCWorkflowSession.GrantedScopes = userSession.OIDCWorkflowSession.RequestedScopes
 		userSession.OIDCWorkflowSession.GrantedAudience = userSession.OIDCWorkflowSession.RequestedAudience
 
+		if body.RememberConsent {
+			expiresAt, err := rememberConsent(ctx, userSession.Username, userSession.OIDCWorkflowSession)
+			if err != nil {
+				ctx.Error(fmt.Errorf("Unable to remember the consent of user %s to client %s: %v", userSession.Username, body.ClientID, err), "Operation failed")
+				return
+			}
+
+			details[events.DetailExpiresAt] = expiresAt.UTC().Format(time.RFC3339)
+		}
+
 		if err := ctx.SaveSession(userSession); err != nil {
 			ctx.Error(fmt.Errorf("Unable to write session: %v", err), "Operation failed")
 			return
 		}
+
+		ctx.PublishEvent(events.ConsentGranted, userSession.Username, details)
 	} else if body.AcceptOrReject == reject {
 		redirectionURL = fmt.Sprintf("%s?error=access_denied&error_description=%s",
 			userSession.OIDCWorkflowSession.TargetURI, "User has rejected the scopes")
@@ -108,6 +145,8 @@ func oidcConsentPOST(ctx *middlewares.AutheliaCtx) {
 			ctx.Error(fmt.Errorf("Unable to write session: %v", err), "Operation failed")
 			return
 		}
+
+		ctx.PublishEvent(events.ConsentRejected, userSession.Username, details)
 	}
 
 	response := ConsentPostResponseBody{RedirectURI: redirectionURL}
@@ -116,3 +155,51 @@ func oidcConsentPOST(ctx *middlewares.AutheliaCtx) {
 		ctx.Error(fmt.Errorf("Unable to set JSON body in response"), "Operation failed")
 	}
 }
+
+// rememberConsent saves the consent of the user to the scopes and audience requested in the workflow, it's given again
+// without asking the user until the remember consent lifespan elapses.
+func rememberConsent(ctx *middlewares.AutheliaCtx, username string, workflow *session.OIDCWorkflowSession) (expiresAt time.Time, err error) {
+	lifespan := schema.DefaultOpenIDConnectConfiguration.RememberConsentLifespan
+
+	if config := ctx.Configuration.IdentityProviders.OIDC; config != nil && config.RememberConsentLifespan > 0 {
+		lifespan = config.RememberConsentLifespan
+	}
+
+	now := ctx.Clock.Now()
+	expiresAt = now.Add(lifespan)
+
+	err = ctx.Providers.StorageProvider.SaveOIDCConsent(models.OIDCConsent{
+		Username:  username,
+		ClientID:  workflow.ClientID,
+		Scopes:    workflow.RequestedScopes,
+		Audience:  workflow.RequestedAudience,
+		CreatedAt: now,
+		ExpiresAt: expiresAt,
+	})
+
+	return expiresAt, err
+}
+
+// isConsentRedirectionSafe determines if the user can be redirected once they replied to the consent. The authorization
+// URI must be on the protected or allowed domains and the redirect URI must be registered for the client.
+func isConsentRedirectionSafe(ctx *middlewares.AutheliaCtx, client *oidc.InternalClient,
+	workflow *session.OIDCWorkflowSession, acceptOrReject string) bool {
+	if acceptOrReject == reject {
+		if !utils.IsStringInSlice(workflow.TargetURI, client.GetRedirectURIs()) {
+			logRejectedRedirection(ctx, redirectionFlowConsent, workflow.TargetURI, errRedirectionNotRegistered)
+
+			return false
+		}
+
+		return true
+	}
+
+	authURL, err := url.Parse(workflow.AuthURI)
+	if err != nil {
+		logRejectedRedirection(ctx, redirectionFlowConsent, workflow.AuthURI, err)
+
+		return false
+	}
+
+	return isRedirectionSafe(ctx, redirectionFlowConsent, *authURL)
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/authorization"
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/internal/oidc"
+	"github.com/authelia/authelia/internal/session"
+)
+
+type ConsentPostSuite struct {
+	suite.Suite
+
+	mock *mocks.MockAutheliaCtx
+}
+
+func (s *ConsentPostSuite) SetupTest() {
+	s.mock = mocks.NewMockAutheliaCtx(s.T())
+	s.mock.Ctx.Configuration.Session.Domain = "example.com"
+
+	store, err := oidc.NewOpenIDConnectStore(&schema.OpenIDConnectConfiguration{
+		Clients: []schema.OpenIDConnectClientConfiguration{
+			{
+				ID:           "client",
+				Policy:       "one_factor",
+				RedirectURIs: []string{"https://app.example.net/callback"},
+			},
+		},
+	})
+	s.Require().NoError(err)
+
+	s.mock.Ctx.Providers.OpenIDConnect = oidc.OpenIDConnectProvider{Store: store}
+
+	userSession := s.mock.Ctx.GetSession()
+	userSession.Username = testUsername
+	userSession.AuthenticationLevel = authentication.OneFactor
+	userSession.OIDCWorkflowSession = &session.OIDCWorkflowSession{
+		ClientID:                   "client",
+		AuthURI:                    "https://auth.example.com/api/oidc/authorize?client_id=client",
+		TargetURI:                  "https://app.example.net/callback",
+		RequiredAuthorizationLevel: authorization.OneFactor,
+	}
+	s.Require().NoError(s.mock.Ctx.SaveSession(userSession))
+}
+
+func (s *ConsentPostSuite) TearDownTest() {
+	s.mock.Close()
+}
+
+func (s *ConsentPostSuite) setBody(acceptOrReject string) {
+	bodyBytes, err := json.Marshal(ConsentPostRequestBody{ClientID: "client", AcceptOrReject: acceptOrReject})
+	s.Require().NoError(err)
+	s.mock.Ctx.Request.SetBody(bodyBytes)
+}
+
+func (s *ConsentPostSuite) TestShouldRedirectToAuthorizationURIOnAccept() {
+	s.setBody(accept)
+
+	oidcConsentPOST(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), ConsentPostResponseBody{
+		RedirectURI: "https://auth.example.com/api/oidc/authorize?client_id=client",
+	})
+}
+
+func (s *ConsentPostSuite) TestShouldRedirectToClientOnReject() {
+	s.setBody(reject)
+
+	oidcConsentPOST(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), ConsentPostResponseBody{
+		RedirectURI: "https://app.example.net/callback?error=access_denied&error_description=User has rejected the scopes",
+	})
+}
+
+func (s *ConsentPostSuite) TestShouldRejectUnsafeAuthorizationURI() {
+	userSession := s.mock.Ctx.GetSession()
+	userSession.OIDCWorkflowSession.AuthURI = "https://auth.evil.net/api/oidc/authorize?client_id=client"
+	s.Require().NoError(s.mock.Ctx.SaveSession(userSession))
+
+	s.setBody(accept)
+
+	oidcConsentPOST(s.mock.Ctx)
+
+	s.Assert().Equal(403, s.mock.Ctx.Response.StatusCode())
+
+	entry := s.mock.Hook.LastEntry()
+	s.Require().NotNil(entry)
+	s.Assert().Equal("consent", entry.Data["flow"])
+	s.Assert().Equal("https://auth.evil.net/api/oidc/authorize?client_id=client", entry.Data["target"])
+}
+
+func (s *ConsentPostSuite) TestShouldRejectUnregisteredRedirectURI() {
+	userSession := s.mock.Ctx.GetSession()
+	userSession.OIDCWorkflowSession.TargetURI = "https://evil.net/callback"
+	s.Require().NoError(s.mock.Ctx.SaveSession(userSession))
+
+	s.setBody(reject)
+
+	oidcConsentPOST(s.mock.Ctx)
+
+	s.Assert().Equal(403, s.mock.Ctx.Response.StatusCode())
+
+	entry := s.mock.Hook.LastEntry()
+	s.Require().NotNil(entry)
+	s.Assert().Equal("redirection URL isn't registered for the client", entry.Data["reason"])
+}
+
+func TestRunConsentPostSuite(t *testing.T) {
+	s := new(ConsentPostSuite)
+	suite.Run(t, s)
+}
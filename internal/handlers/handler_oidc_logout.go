@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/ory/fosite/token/jwt"
+	"github.com/valyala/fasthttp"
+
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// oidcLogout is the end session endpoint the clients send the users to in order to log them out. The users are
+// redirected to the sign out page of the portal logging them out of the clients and then to the post logout redirect
+// URI registered by the client.
+//
+// See https://openid.net/specs/openid-connect-rpinitiated-1_0.html
+func oidcLogout(ctx *middlewares.AutheliaCtx) {
+	issuer, err := ctx.ForwardedProtoHost()
+	if err != nil {
+		ctx.Logger.Errorf("Error occurred in ForwardedProtoHost: %+v", err)
+		ctx.ReplyBadRequest()
+
+		return
+	}
+
+	var (
+		subject  string
+		clientID = string(ctx.FormValue("client_id"))
+	)
+
+	if hint := string(ctx.FormValue("id_token_hint")); hint != "" {
+		if subject, clientID, err = oidcVerifyIDTokenHint(ctx, issuer, hint, clientID); err != nil {
+			ctx.Logger.Errorf("Unable to verify the ID token hint of the logout request: %v", err)
+			ctx.ReplyBadRequest()
+
+			return
+		}
+	}
+
+	redirectURI := string(ctx.FormValue("post_logout_redirect_uri"))
+
+	if redirectURI != "" {
+		if redirectURI, err = oidcPostLogoutRedirectURI(ctx, clientID, redirectURI, string(ctx.FormValue("state"))); err != nil {
+			ctx.Logger.Errorf("Unable to accept the post logout redirect URI of the logout request: %v", err)
+			ctx.ReplyBadRequest()
+
+			return
+		}
+	}
+
+	userSession := ctx.GetSession()
+
+	if userSession.Username == "" {
+		if redirectURI == "" {
+			redirectURI = issuer
+		}
+
+		ctx.Redirect(redirectURI, fasthttp.StatusFound)
+
+		return
+	}
+
+	if subject != "" && subject != userSession.Username {
+		ctx.Logger.Errorf("Unable to log user %s out since the ID token hint of the logout request was issued to user %s", userSession.Username, subject)
+		ctx.ReplyBadRequest()
+
+		return
+	}
+
+	userSession.OIDCPostLogoutRedirectURI = redirectURI
+
+	if err = ctx.SaveSession(userSession); err != nil {
+		ctx.Logger.Errorf("Unable to save the session of user %s: %v", userSession.Username, err)
+		ctx.Response.SetStatusCode(fasthttp.StatusInternalServerError)
+
+		return
+	}
+
+	ctx.Redirect(fmt.Sprintf("%s%s", issuer, signOutPath), fasthttp.StatusFound)
+}
+
+// oidcVerifyIDTokenHint verifies the ID token hint was issued by this issuer and returns its subject and the client it
+// was issued to. The hint is accepted once expired since the clients usually log the users out after it expired.
+func oidcVerifyIDTokenHint(ctx *middlewares.AutheliaCtx, issuer, hint, clientID string) (subject, audience string, err error) {
+	token, err := ctx.Providers.OpenIDConnect.KeyManager.Strategy().Decode(ctx, hint)
+	if err != nil {
+		var validationErr *jwt.ValidationError
+		if token == nil || !errors.As(err, &validationErr) || validationErr.Errors != jwt.ValidationErrorExpired {
+			return "", "", err
+		}
+	}
+
+	if !token.Claims.VerifyIssuer(issuer, true) {
+		return "", "", fmt.Errorf("the ID token hint wasn't issued by %s", issuer)
+	}
+
+	if clientID != "" {
+		if !token.Claims.VerifyAudience(clientID, true) {
+			return "", "", fmt.Errorf("the ID token hint wasn't issued to client %s", clientID)
+		}
+
+		audience = clientID
+	} else {
+		// The audience of the ID tokens may include the audience granted to the client besides the client itself.
+		for _, aud := range oidcClaimStrings(token.Claims["aud"]) {
+			if ctx.Providers.OpenIDConnect.Store.IsValidClientID(aud) {
+				audience = aud
+				break
+			}
+		}
+	}
+
+	subject, _ = token.Claims["sub"].(string)
+
+	return subject, audience, nil
+}
+
+// oidcPostLogoutRedirectURI returns the post logout redirect URI with the state once checked it's registered by the
+// client.
+func oidcPostLogoutRedirectURI(ctx *middlewares.AutheliaCtx, clientID, redirectURI, state string) (string, error) {
+	if clientID == "" {
+		return "", errors.New("the client is required along with the post logout redirect URI")
+	}
+
+	client, err := ctx.Providers.OpenIDConnect.Store.GetInternalClient(clientID)
+	if err != nil {
+		return "", fmt.Errorf("unable to find the client %s: %w", clientID, err)
+	}
+
+	if !utils.IsStringInSlice(redirectURI, client.PostLogoutRedirectURIs) {
+		return "", fmt.Errorf("the post logout redirect URI %s isn't registered by client %s", redirectURI, clientID)
+	}
+
+	if state == "" {
+		return redirectURI, nil
+	}
+
+	uri, err := url.Parse(redirectURI)
+	if err != nil {
+		return "", err
+	}
+
+	query := uri.Query()
+	query.Set("state", state)
+	uri.RawQuery = query.Encode()
+
+	return uri.String(), nil
+}
+
+func oidcClaimStrings(claim interface{}) (values []string) {
+	switch v := claim.(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []interface{}:
+		for _, value := range v {
+			if s, ok := value.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ory/fosite/token/jwt"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/internal/oidc"
+)
+
+type OIDCLogoutSuite struct {
+	suite.Suite
+
+	mock       *mocks.MockAutheliaCtx
+	keyManager *oidc.KeyManager
+	server     *httptest.Server
+
+	mutex        sync.Mutex
+	logoutTokens []string
+}
+
+func (s *OIDCLogoutSuite) SetupTest() {
+	s.mock = mocks.NewMockAutheliaCtx(s.T())
+	s.mock.Ctx.Request.Header.Set("X-Forwarded-Proto", "https")
+	s.mock.Ctx.Request.Header.Set("X-Forwarded-Host", "auth.example.com")
+
+	s.mock.Clock.Set(time.Now())
+	s.mock.Ctx.Clock = &s.mock.Clock
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	s.Require().NoError(err)
+
+	s.keyManager = oidc.NewKeyManager()
+	_, err = s.keyManager.AddActivePrivateKey(key)
+	s.Require().NoError(err)
+
+	s.logoutTokens = nil
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		s.logoutTokens = append(s.logoutTokens, r.FormValue("logout_token"))
+		s.mutex.Unlock()
+	}))
+
+	store, err := oidc.NewOpenIDConnectStore(&schema.OpenIDConnectConfiguration{
+		Clients: []schema.OpenIDConnectClientConfiguration{
+			{
+				ID:                     "app",
+				Policy:                 "one_factor",
+				RedirectURIs:           []string{"https://app.example.com/callback"},
+				BackChannelLogoutURI:   s.server.URL + "/logout",
+				FrontChannelLogoutURI:  "https://app.example.com/logout",
+				PostLogoutRedirectURIs: []string{"https://app.example.com/logged-out"},
+			},
+			{
+				ID:           "other",
+				Policy:       "one_factor",
+				RedirectURIs: []string{"https://other.example.com/callback"},
+			},
+		},
+	})
+	s.Require().NoError(err)
+
+	s.mock.Ctx.Providers.OpenIDConnect = oidc.OpenIDConnectProvider{Store: store, KeyManager: s.keyManager}
+
+	userSession := s.mock.Ctx.GetSession()
+	userSession.Username = testUsername
+	userSession.OIDCSessionID = "session-id"
+	userSession.OIDCClients = []string{"app", "other"}
+	s.Require().NoError(s.mock.Ctx.SaveSession(userSession))
+}
+
+func (s *OIDCLogoutSuite) TearDownTest() {
+	s.server.Close()
+	s.mock.Close()
+}
+
+func (s *OIDCLogoutSuite) idTokenHint(subject, audience string, expiresAt time.Time) string {
+	token, _, err := s.keyManager.Strategy().Generate(context.Background(), jwt.MapClaims{
+		"iss": "https://auth.example.com",
+		"aud": []string{audience, "https://api.example.com"},
+		"sub": subject,
+		"exp": expiresAt.Unix(),
+	}, &jwt.Headers{})
+	s.Require().NoError(err)
+
+	return token
+}
+
+func (s *OIDCLogoutSuite) TestShouldRedirectToTheSignOutPageWithThePostLogoutRedirectURI() {
+	s.mock.Ctx.QueryArgs().Add("id_token_hint", s.idTokenHint(testUsername, "app", time.Now().Add(-time.Hour)))
+	s.mock.Ctx.QueryArgs().Add("post_logout_redirect_uri", "https://app.example.com/logged-out")
+	s.mock.Ctx.QueryArgs().Add("state", "abc")
+
+	oidcLogout(s.mock.Ctx)
+
+	s.Assert().Equal(302, s.mock.Ctx.Response.StatusCode())
+	s.Assert().Equal("https://auth.example.com/logout", string(s.mock.Ctx.Response.Header.Peek("Location")))
+	s.Assert().Equal("https://app.example.com/logged-out?state=abc", s.mock.Ctx.GetSession().OIDCPostLogoutRedirectURI)
+}
+
+func (s *OIDCLogoutSuite) TestShouldRejectUnregisteredPostLogoutRedirectURI() {
+	s.mock.Ctx.QueryArgs().Add("client_id", "app")
+	s.mock.Ctx.QueryArgs().Add("post_logout_redirect_uri", "https://evil.example.com")
+
+	oidcLogout(s.mock.Ctx)
+
+	s.Assert().Equal(400, s.mock.Ctx.Response.StatusCode())
+	s.Assert().Equal("", s.mock.Ctx.GetSession().OIDCPostLogoutRedirectURI)
+}
+
+func (s *OIDCLogoutSuite) TestShouldRejectPostLogoutRedirectURIWithoutClient() {
+	s.mock.Ctx.QueryArgs().Add("post_logout_redirect_uri", "https://app.example.com/logged-out")
+
+	oidcLogout(s.mock.Ctx)
+
+	s.Assert().Equal(400, s.mock.Ctx.Response.StatusCode())
+}
+
+func (s *OIDCLogoutSuite) TestShouldRejectIDTokenHintOfAnotherUser() {
+	s.mock.Ctx.QueryArgs().Add("id_token_hint", s.idTokenHint("harry", "app", time.Now().Add(time.Hour)))
+
+	oidcLogout(s.mock.Ctx)
+
+	s.Assert().Equal(400, s.mock.Ctx.Response.StatusCode())
+	s.Assert().Equal("Unable to log user john out since the ID token hint of the logout request was issued to user harry", s.mock.Hook.LastEntry().Message)
+}
+
+func (s *OIDCLogoutSuite) TestShouldRejectIDTokenHintOfAnotherClient() {
+	s.mock.Ctx.QueryArgs().Add("id_token_hint", s.idTokenHint(testUsername, "other", time.Now().Add(time.Hour)))
+	s.mock.Ctx.QueryArgs().Add("client_id", "app")
+
+	oidcLogout(s.mock.Ctx)
+
+	s.Assert().Equal(400, s.mock.Ctx.Response.StatusCode())
+	s.Assert().Equal("Unable to verify the ID token hint of the logout request: the ID token hint wasn't issued to client app", s.mock.Hook.LastEntry().Message)
+}
+
+func (s *OIDCLogoutSuite) TestShouldRejectIDTokenHintNotSignedByTheIssuer() {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	s.Require().NoError(err)
+
+	s.keyManager = oidc.NewKeyManager()
+	_, err = s.keyManager.AddActivePrivateKey(key)
+	s.Require().NoError(err)
+
+	s.mock.Ctx.QueryArgs().Add("id_token_hint", s.idTokenHint(testUsername, "app", time.Now().Add(time.Hour)))
+
+	oidcLogout(s.mock.Ctx)
+
+	s.Assert().Equal(400, s.mock.Ctx.Response.StatusCode())
+}
+
+func (s *OIDCLogoutSuite) TestShouldRedirectToThePostLogoutRedirectURIWithoutSession() {
+	userSession := s.mock.Ctx.GetSession()
+	userSession.Username = ""
+	s.Require().NoError(s.mock.Ctx.SaveSession(userSession))
+
+	s.mock.Ctx.QueryArgs().Add("client_id", "app")
+	s.mock.Ctx.QueryArgs().Add("post_logout_redirect_uri", "https://app.example.com/logged-out")
+
+	oidcLogout(s.mock.Ctx)
+
+	s.Assert().Equal(302, s.mock.Ctx.Response.StatusCode())
+	s.Assert().Equal("https://app.example.com/logged-out", string(s.mock.Ctx.Response.Header.Peek("Location")))
+}
+
+func (s *OIDCLogoutSuite) TestShouldLogUserOutOfTheClients() {
+	userSession := s.mock.Ctx.GetSession()
+	userSession.OIDCPostLogoutRedirectURI = "https://app.example.com/logged-out"
+	s.Require().NoError(s.mock.Ctx.SaveSession(userSession))
+
+	LogoutPost(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), logoutResponseBody{
+		FrontChannelLogoutURIs: []string{"https://app.example.com/logout?iss=https%3A%2F%2Fauth.example.com&sid=session-id"},
+		PostLogoutRedirectURI:  "https://app.example.com/logged-out",
+	})
+
+	s.Require().Len(s.logoutTokens, 1)
+
+	token, err := s.keyManager.Strategy().Decode(context.Background(), s.logoutTokens[0])
+	s.Require().NoError(err)
+
+	s.Assert().Equal(testUsername, token.Claims["sub"])
+	s.Assert().Equal("session-id", token.Claims["sid"])
+}
+
+func TestRunOIDCLogoutSuite(t *testing.T) {
+	s := new(OIDCLogoutSuite)
+	suite.Run(t, s)
+}
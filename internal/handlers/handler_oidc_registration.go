@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/valyala/fasthttp"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/oidc"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// OIDCRegistrationPost handler registering an OpenID Connect client with the metadata of the request, the client is
+// persisted in the storage and can be used right away. It requires the oidc_clients.register admin permission.
+//
+// See https://datatracker.ietf.org/doc/html/rfc7591#section-3
+func OIDCRegistrationPost(ctx *middlewares.AutheliaCtx) {
+	config := ctx.Configuration.IdentityProviders.OIDC
+	if config == nil || config.DynamicRegistration == nil || ctx.Providers.OpenIDConnect.Store == nil {
+		ctx.Logger.Errorf("Unable to register an OpenID Connect client: the dynamic registration is not enabled")
+		ctx.Response.SetStatusCode(fasthttp.StatusNotFound)
+
+		return
+	}
+
+	caller := ctx.AdminCaller()
+
+	request, err := oidc.ParseRegistrationRequest(ctx.PostBody())
+	if err == nil {
+		err = request.Validate(config.DynamicRegistration)
+	}
+
+	var registrationErr *oidc.RegistrationError
+
+	if errors.As(err, &registrationErr) {
+		ctx.Logger.Infof("OpenID Connect client metadata sent by %s is invalid: %s", caller, err)
+
+		if err = ctx.SetJSONRawBody(registrationErr); err != nil {
+			ctx.Logger.Errorf("Unable to set the OpenID Connect client registration error in body: %s", err)
+		}
+
+		ctx.Response.SetStatusCode(fasthttp.StatusBadRequest)
+
+		return
+	}
+
+	secret, err := utils.RandomSecureString(oidcClientSecretLength, utils.AlphaNumericCharacters)
+	if err != nil {
+		ctx.Logger.Errorf("Unable to generate the secret of an OpenID Connect client registered by %s: %s", caller, err)
+		ctx.Response.SetStatusCode(fasthttp.StatusInternalServerError)
+
+		return
+	}
+
+	passwordConfig := schema.DefaultPasswordConfiguration
+
+	hash, err := authentication.HashPassword(secret, "", authentication.HashingAlgorithmArgon2id, passwordConfig.Iterations,
+		passwordConfig.Memory*1024, passwordConfig.Parallelism, passwordConfig.KeyLength, passwordConfig.SaltLength)
+	if err != nil {
+		ctx.Logger.Errorf("Unable to hash the secret of an OpenID Connect client registered by %s: %s", caller, err)
+		ctx.Response.SetStatusCode(fasthttp.StatusInternalServerError)
+
+		return
+	}
+
+	client := request.Client(uuid.New().String(), hash, config.DynamicRegistration.Policy)
+	client.RegisteredBy = caller
+	client.CreatedAt = ctx.Clock.Now()
+
+	if err = ctx.Providers.StorageProvider.SaveOIDCClient(client); err != nil {
+		ctx.Logger.Errorf("Unable to save the OpenID Connect client registered by %s: %s", caller, err)
+		ctx.Response.SetStatusCode(fasthttp.StatusInternalServerError)
+
+		return
+	}
+
+	ctx.Providers.OpenIDConnect.Store.AddRegisteredClient(client)
+
+	ctx.Logger.Infof("OpenID Connect client %s (%s) has been registered by %s", client.ID, client.Description, caller)
+
+	if err = ctx.SetJSONRawBody(oidc.RegistrationResponse{
+		ClientID:            client.ID,
+		ClientSecret:        secret,
+		ClientIDIssuedAt:    client.CreatedAt.Unix(),
+		RegistrationRequest: request,
+	}); err != nil {
+		ctx.Logger.Errorf("Unable to set the OpenID Connect client registration response in body: %s", err)
+		return
+	}
+
+	ctx.Response.Header.Set(fasthttp.HeaderCacheControl, "no-store")
+	ctx.Response.SetStatusCode(fasthttp.StatusCreated)
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/oidc"
+	"github.com/authelia/authelia/pkg/autheliatest"
+)
+
+type OIDCClientsSuite struct {
+	suite.Suite
+
+	mock    *mocks.MockAutheliaCtx
+	storage *autheliatest.MemoryStorageProvider
+	store   *oidc.OpenIDConnectStore
+}
+
+func (s *OIDCClientsSuite) SetupTest() {
+	s.mock = mocks.NewMockAutheliaCtx(s.T())
+	s.mock.Clock.Set(time.Unix(1600000000, 0))
+	s.mock.Ctx.Clock = &s.mock.Clock
+	s.mock.Ctx.Configuration.Server.AdminGroup = "admins"
+	s.mock.Ctx.Configuration.IdentityProviders.OIDC = &schema.OpenIDConnectConfiguration{
+		DynamicRegistration: &schema.OpenIDConnectDynamicRegistrationConfiguration{
+			Policy: "two_factor",
+			Scopes: []string{"openid", "profile"},
+		},
+	}
+
+	store, err := oidc.NewOpenIDConnectStore(&schema.OpenIDConnectConfiguration{})
+	s.Require().NoError(err)
+
+	s.store = store
+	s.mock.Ctx.Providers.OpenIDConnect = oidc.OpenIDConnectProvider{Store: store}
+
+	s.storage = autheliatest.NewMemoryStorageProvider()
+	s.mock.Ctx.Providers.StorageProvider = s.storage
+
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder(testUsername).WithGroups("admins").TwoFactor(s.mock.Clock.Now()).Build())
+}
+
+func (s *OIDCClientsSuite) TearDownTest() {
+	s.mock.Close()
+}
+
+func (s *OIDCClientsSuite) TestShouldRegisterClient() {
+	s.mock.Ctx.Request.SetBodyString(`{"redirect_uris":["https://app.example.com/callback"],"client_name":"My App"}`)
+
+	middlewares.RequireAdminPermission(models.AdminPermissionOIDCClientsRegister)(OIDCRegistrationPost)(s.mock.Ctx)
+
+	s.Require().Equal(201, s.mock.Ctx.Response.StatusCode())
+	s.Assert().Equal("no-store", string(s.mock.Ctx.Response.Header.Peek("Cache-Control")))
+
+	var response oidc.RegistrationResponse
+
+	s.Require().NoError(json.Unmarshal(s.mock.Ctx.Response.Body(), &response))
+	s.Assert().NotEmpty(response.ClientID)
+	s.Assert().Len(response.ClientSecret, oidcClientSecretLength)
+	s.Assert().Equal(int64(1600000000), response.ClientIDIssuedAt)
+	s.Assert().Equal(int64(0), response.ClientSecretExpiresAt)
+	s.Assert().Equal("openid profile", response.Scope)
+	s.Assert().Equal([]string{"authorization_code"}, response.GrantTypes)
+
+	clients, err := s.storage.LoadOIDCClients()
+	s.Require().NoError(err)
+	s.Require().Len(clients, 1)
+	s.Assert().Equal(response.ClientID, clients[0].ID)
+	s.Assert().Equal("My App", clients[0].Description)
+	s.Assert().Equal("two_factor", clients[0].Policy)
+	s.Assert().Equal(testUsername, clients[0].RegisteredBy)
+	s.Assert().NotContains(clients[0].SecretHash, response.ClientSecret)
+
+	client, err := s.store.GetInternalClient(response.ClientID)
+	s.Require().NoError(err)
+	s.Assert().Equal([]string{"https://app.example.com/callback"}, client.GetRedirectURIs())
+
+	s.Assert().Equal("OpenID Connect client "+response.ClientID+" (My App) has been registered by john", s.mock.Hook.LastEntry().Message)
+}
+
+func (s *OIDCClientsSuite) TestShouldRejectInvalidMetadata() {
+	s.mock.Ctx.Request.SetBodyString(`{"redirect_uris":["https://app.example.com/callback"],"scope":"openid email"}`)
+
+	middlewares.RequireAdminPermission(models.AdminPermissionOIDCClientsRegister)(OIDCRegistrationPost)(s.mock.Ctx)
+
+	s.Assert().Equal(400, s.mock.Ctx.Response.StatusCode())
+	s.Assert().JSONEq(`{"error":"invalid_client_metadata","error_description":"the scope 'email' isn't allowed, must be one of: 'openid', 'profile'"}`,
+		string(s.mock.Ctx.Response.Body()))
+
+	clients, err := s.storage.LoadOIDCClients()
+	s.Require().NoError(err)
+	s.Assert().Empty(clients)
+}
+
+func (s *OIDCClientsSuite) TestShouldRejectInvalidJSON() {
+	s.mock.Ctx.Request.SetBodyString(`[]`)
+
+	middlewares.RequireAdminPermission(models.AdminPermissionOIDCClientsRegister)(OIDCRegistrationPost)(s.mock.Ctx)
+
+	s.Assert().Equal(400, s.mock.Ctx.Response.StatusCode())
+	s.Assert().Contains(string(s.mock.Ctx.Response.Body()), `"error":"invalid_client_metadata"`)
+}
+
+func (s *OIDCClientsSuite) TestShouldListAndDeleteClients() {
+	client := models.OIDCClient{
+		ID:            "registered",
+		Description:   "My App",
+		SecretHash:    "$argon2id$hash",
+		Policy:        "two_factor",
+		RedirectURIs:  []string{"https://app.example.com/callback"},
+		GrantTypes:    []string{"authorization_code"},
+		ResponseTypes: []string{"code"},
+		Scopes:        []string{"openid"},
+		RegisteredBy:  "admin API token 1a2b3c",
+		CreatedAt:     time.Unix(1500000000, 0),
+	}
+
+	s.Require().NoError(s.storage.SaveOIDCClient(client))
+	s.store.AddRegisteredClient(client)
+
+	middlewares.RequireAdminPermission(models.AdminPermissionOIDCClientsRead)(AdminOIDCClientsGet)(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), []AdminOIDCClient{
+		{
+			ID:            "registered",
+			Description:   "My App",
+			Policy:        "two_factor",
+			RedirectURIs:  []string{"https://app.example.com/callback"},
+			GrantTypes:    []string{"authorization_code"},
+			ResponseTypes: []string{"code"},
+			Scopes:        []string{"openid"},
+			RegisteredBy:  "admin API token 1a2b3c",
+			CreatedAt:     1500000000,
+		},
+	})
+
+	s.mock.Ctx.Response.Reset()
+	s.mock.Ctx.SetUserValue("id", "registered")
+
+	middlewares.RequireAdminPermission(models.AdminPermissionOIDCClientsDelete)(AdminOIDCClientDelete)(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), nil)
+	s.Assert().Equal("OpenID Connect client registered has been deleted by john", s.mock.Hook.LastEntry().Message)
+	s.Assert().False(s.store.IsValidClientID("registered"))
+
+	clients, err := s.storage.LoadOIDCClients()
+	s.Require().NoError(err)
+	s.Assert().Empty(clients)
+}
+
+func (s *OIDCClientsSuite) TestShouldFailToDeleteUnknownClient() {
+	s.mock.Ctx.SetUserValue("id", "unknown")
+
+	middlewares.RequireAdminPermission(models.AdminPermissionOIDCClientsDelete)(AdminOIDCClientDelete)(s.mock.Ctx)
+
+	s.mock.Assert200KO(s.T(), operationFailedMessage)
+	s.Assert().Equal("OpenID Connect client unknown is not a registered client", s.mock.Hook.LastEntry().Message)
+}
+
+func TestRunOIDCClientsSuite(t *testing.T) {
+	s := new(OIDCClientsSuite)
+	suite.Run(t, s)
+}
+
+func TestShouldNotRegisterClientWhenDynamicRegistrationIsDisabled(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	OIDCRegistrationPost(mock.Ctx)
+
+	assert.Equal(t, 404, mock.Ctx.Response.StatusCode())
+}
@@ -2,10 +2,13 @@ package handlers
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/ory/fosite"
 
+	"github.com/authelia/authelia/internal/metrics"
 	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/utils"
 )
 
 func oidcToken(ctx *middlewares.AutheliaCtx, rw http.ResponseWriter, req *http.Request) {
@@ -13,28 +16,54 @@ func oidcToken(ctx *middlewares.AutheliaCtx, rw http.ResponseWriter, req *http.R
 
 	accessRequest, accessReqErr := ctx.Providers.OpenIDConnect.Fosite.NewAccessRequest(ctx, req, oidcSession)
 	if accessReqErr != nil {
+		metrics.OIDCTokensIssued.Inc(oidcGrantTypeLabel(req.PostForm.Get("grant_type")), "false")
+
 		ctx.Logger.Errorf("Error occurred in NewAccessRequest: %+v", accessRequest)
 		ctx.Providers.OpenIDConnect.Fosite.WriteAccessError(rw, accessRequest, accessReqErr)
 
 		return
 	}
 
-	// If this is a client_credentials grant, grant all scopes the client is allowed to perform.
+	// If this is a client_credentials grant, grant all scopes the client is allowed to perform and the requested
+	// audiences, they have already been matched against the audiences of the client.
 	if accessRequest.GetGrantTypes().ExactOne("client_credentials") {
 		for _, scope := range accessRequest.GetRequestedScopes() {
 			if fosite.HierarchicScopeStrategy(accessRequest.GetClient().GetScopes(), scope) {
 				accessRequest.GrantScope(scope)
 			}
 		}
+
+		for _, audience := range accessRequest.GetRequestedAudience() {
+			accessRequest.GrantAudience(audience)
+		}
+
+		if !utils.IsStringInSlice(accessRequest.GetClient().GetID(), accessRequest.GetGrantedAudience()) {
+			accessRequest.GrantAudience(accessRequest.GetClient().GetID())
+		}
 	}
 
 	response, err := ctx.Providers.OpenIDConnect.Fosite.NewAccessResponse(ctx, accessRequest)
 	if err != nil {
+		metrics.OIDCTokensIssued.Inc(strings.Join(accessRequest.GetGrantTypes(), " "), "false")
+
 		ctx.Logger.Errorf("Error occurred in NewAccessResponse: %+v", err)
 		ctx.Providers.OpenIDConnect.Fosite.WriteAccessError(rw, accessRequest, err)
 
 		return
 	}
 
+	metrics.OIDCTokensIssued.Inc(strings.Join(accessRequest.GetGrantTypes(), " "), "true")
+
 	ctx.Providers.OpenIDConnect.Fosite.WriteAccessResponse(rw, accessRequest, response)
 }
+
+// oidcGrantTypeLabel returns the grant type of a rejected token request as a metric label, the grant types which aren't
+// known are grouped so the requests can't create an unbounded number of series.
+func oidcGrantTypeLabel(grantType string) string {
+	switch grantType {
+	case "authorization_code", "refresh_token", "client_credentials", "implicit", "password":
+		return grantType
+	default:
+		return "other"
+	}
+}
@@ -3,7 +3,6 @@ package handlers
 import (
 	"fmt"
 	"net/http"
-	"time"
 
 	"github.com/google/uuid"
 	"github.com/ory/fosite"
@@ -57,21 +56,13 @@ func oidcUserinfo(ctx *middlewares.AutheliaCtx, rw http.ResponseWriter, req *htt
 	}
 
 	switch client.UserinfoSigningAlgorithm {
-	case "RS256":
+	case "RS256", "ES256", "EdDSA":
 		claims["jti"] = uuid.New()
-		claims["iat"] = time.Now().Unix()
+		claims["iat"] = ctx.Clock.Now().Unix()
 
-		keyID, err := ctx.Providers.OpenIDConnect.KeyManager.Strategy().GetPublicKeyID(req.Context())
-		if err != nil {
-			ctx.Providers.OpenIDConnect.WriteError(rw, req, err)
-
-			return
-		}
-
-		token, _, err := ctx.Providers.OpenIDConnect.KeyManager.Strategy().Generate(req.Context(), claims,
-			&jwt.Headers{
-				Extra: map[string]interface{}{"kid": keyID},
-			})
+		// The key signing the response is chosen by the algorithm requested by the client rather than the active key.
+		token, _, err := ctx.Providers.OpenIDConnect.KeyManager.Strategy().GenerateWithAlgorithm(req.Context(),
+			client.UserinfoSigningAlgorithm, claims, &jwt.Headers{})
 		if err != nil {
 			ctx.Providers.OpenIDConnect.WriteError(rw, req, err)
 
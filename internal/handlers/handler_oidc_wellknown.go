@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"encoding/json"
 	"fmt"
 
 	"github.com/valyala/fasthttp"
@@ -28,9 +27,10 @@ func oidcWellKnown(ctx *middlewares.AutheliaCtx) {
 		TokenEndpoint:         fmt.Sprintf("%s%s", issuer, oidcTokenPath),
 		RevocationEndpoint:    fmt.Sprintf("%s%s", issuer, oidcRevokePath),
 		UserinfoEndpoint:      fmt.Sprintf("%s%s", issuer, oidcUserinfoPath),
+		EndSessionEndpoint:    fmt.Sprintf("%s%s", issuer, oidcLogoutPath),
 
-		Algorithms:         []string{"RS256"},
-		UserinfoAlgorithms: []string{"none", "RS256"},
+		Algorithms:         ctx.Providers.OpenIDConnect.KeyManager.GetAlgorithms(),
+		UserinfoAlgorithms: append([]string{"none"}, ctx.Providers.OpenIDConnect.KeyManager.GetAlgorithms()...),
 
 		SubjectTypesSupported: []string{
 			"public",
@@ -50,6 +50,16 @@ func oidcWellKnown(ctx *middlewares.AutheliaCtx) {
 			"query",
 			"fragment",
 		},
+		GrantTypesSupported: []string{
+			"authorization_code",
+			"implicit",
+			"refresh_token",
+			"client_credentials",
+		},
+		TokenEndpointAuthMethodsSupported: []string{
+			"client_secret_basic",
+			"client_secret_post",
+		},
 		ScopesSupported: []string{
 			"openid",
 			"offline_access",
@@ -72,18 +82,27 @@ func oidcWellKnown(ctx *middlewares.AutheliaCtx) {
 			"alt_emails",
 			"groups",
 			"name",
+			"sid",
 		},
 
 		RequestURIParameterSupported:       false,
-		BackChannelLogoutSupported:         false,
-		FrontChannelLogoutSupported:        false,
-		BackChannelLogoutSessionSupported:  false,
-		FrontChannelLogoutSessionSupported: false,
+		BackChannelLogoutSupported:         true,
+		FrontChannelLogoutSupported:        true,
+		BackChannelLogoutSessionSupported:  true,
+		FrontChannelLogoutSessionSupported: true,
 	}
 
-	ctx.SetContentType("application/json")
+	if config := ctx.Configuration.IdentityProviders.OIDC; config != nil {
+		wellKnown.AccessTokenLifespan = int64(config.AccessTokenLifespan.Seconds())
+		wellKnown.IDTokenLifespan = int64(config.IDTokenLifespan.Seconds())
+		wellKnown.RefreshTokenLifespan = int64(config.RefreshTokenLifespan.Seconds())
+
+		if config.DynamicRegistration != nil {
+			wellKnown.RegistrationEndpoint = fmt.Sprintf("%s%s", issuer, OIDCRegistrationPath)
+		}
+	}
 
-	if err := json.NewEncoder(ctx).Encode(wellKnown); err != nil {
+	if err := ctx.SetJSONRawBody(wellKnown); err != nil {
 		ctx.Logger.Errorf("Error occurred in json Encode: %+v", err)
 		// TODO: Determine if this is the appropriate error code here.
 		ctx.Response.SetStatusCode(fasthttp.StatusInternalServerError)
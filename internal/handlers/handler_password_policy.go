@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/middlewares"
+)
+
+// PasswordPolicyGet returns the password policy enforced by the server when a password is reset.
+func PasswordPolicyGet(ctx *middlewares.AutheliaCtx) {
+	policy := ctx.Configuration.PasswordPolicy
+
+	err := ctx.SetJSONBody(PasswordPolicyBody{
+		MinLength:        policy.MinLength,
+		MaxLength:        policy.MaxLength,
+		RequireUppercase: policy.RequireUppercase,
+		RequireLowercase: policy.RequireLowercase,
+		RequireNumber:    policy.RequireNumber,
+		RequireSpecial:   policy.RequireSpecial,
+		MinScore:         policy.MinScore,
+	})
+	if err != nil {
+		ctx.Logger.Errorf("Unable to set password policy response in body: %s", err)
+	}
+}
+
+// PasswordStrengthPost evaluates the password of the request against the password policy. It's only available to the
+// users resetting their password so it can't be used to evaluate arbitrary passwords.
+func PasswordStrengthPost(ctx *middlewares.AutheliaCtx) {
+	userSession := ctx.GetSession()
+
+	if userSession.PasswordResetUsername == nil {
+		ctx.Error(fmt.Errorf("No identity verification process has been initiated"), operationFailedMessage)
+		return
+	}
+
+	var requestBody passwordStrengthRequestBody
+
+	if err := ctx.ParseBody(&requestBody); err != nil {
+		ctx.Error(err, operationFailedMessage)
+		return
+	}
+
+	unmet := authentication.NewPasswordPolicy(ctx.Configuration.PasswordPolicy).Check(requestBody.Password)
+	if unmet == nil {
+		unmet = []string{}
+	}
+
+	err := ctx.SetJSONBody(PasswordStrengthBody{
+		Score: authentication.PasswordStrength(requestBody.Password),
+		Valid: len(unmet) == 0,
+		Unmet: unmet,
+	})
+	if err != nil {
+		ctx.Logger.Errorf("Unable to set password strength response in body: %s", err)
+	}
+}
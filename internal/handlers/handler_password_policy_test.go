@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/mocks"
+)
+
+type PasswordPolicySuite struct {
+	suite.Suite
+	mock *mocks.MockAutheliaCtx
+}
+
+func (s *PasswordPolicySuite) SetupTest() {
+	s.mock = mocks.NewMockAutheliaCtx(s.T())
+	s.mock.Ctx.Configuration.PasswordPolicy = schema.PasswordPolicyConfiguration{
+		MinLength:      8,
+		RequireNumber:  true,
+		RequireSpecial: true,
+	}
+}
+
+func (s *PasswordPolicySuite) TearDownTest() {
+	s.mock.Close()
+}
+
+func (s *PasswordPolicySuite) setPasswordResetUsername() {
+	username := testUsername
+
+	userSession := s.mock.Ctx.GetSession()
+	userSession.PasswordResetUsername = &username
+	s.mock.SetUserSession(s.T(), userSession)
+}
+
+func (s *PasswordPolicySuite) TestShouldServePasswordPolicy() {
+	PasswordPolicyGet(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), PasswordPolicyBody{
+		MinLength:      8,
+		RequireNumber:  true,
+		RequireSpecial: true,
+	})
+}
+
+func (s *PasswordPolicySuite) TestShouldEvaluatePasswordStrength() {
+	s.setPasswordResetUsername()
+	s.mock.Ctx.Request.SetBodyString(`{"password":"password"}`)
+
+	PasswordStrengthPost(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), PasswordStrengthBody{
+		Score: 1,
+		Valid: false,
+		Unmet: []string{"number", "special"},
+	})
+}
+
+func (s *PasswordPolicySuite) TestShouldNotEvaluatePasswordStrengthWithoutPasswordReset() {
+	s.mock.Ctx.Request.SetBodyString(`{"password":"password"}`)
+
+	PasswordStrengthPost(s.mock.Ctx)
+
+	s.mock.Assert200KO(s.T(), operationFailedMessage)
+}
+
+func (s *PasswordPolicySuite) TestShouldRejectPasswordResetNotFollowingPolicy() {
+	s.setPasswordResetUsername()
+	s.mock.Ctx.Request.SetBodyString(`{"password":"p4ssword"}`)
+
+	ResetPasswordPost(s.mock.Ctx)
+
+	s.mock.Assert200KO(s.T(), passwordPolicyMessage)
+	s.Assert().Equal("Password doesn't meet the password policy rules: special", s.mock.Hook.LastEntry().Message)
+}
+
+func TestRunPasswordPolicySuite(t *testing.T) {
+	suite.Run(t, new(PasswordPolicySuite))
+}
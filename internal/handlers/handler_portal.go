@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/middlewares"
+)
+
+// PortalGet is the handler serving everything the portal needs to render in a single response: the user state, the
+// login banner and, once the user is authenticated, the configuration and the user info. The response has an ETag so the portal can
+// revalidate it cheaply with a conditional request.
+func PortalGet(ctx *middlewares.AutheliaCtx) {
+	userSession := ctx.GetSession()
+
+	response := PortalResponse{
+		State:       newStateResponse(ctx, &userSession),
+		LoginBanner: ctx.Configuration.Banner.Login,
+	}
+
+	if userSession.AuthenticationLevel >= authentication.OneFactor {
+		configuration := newConfigurationBody(ctx, &userSession)
+		response.Configuration = &configuration
+
+		userInfo, err := newUserInfo(ctx, &userSession)
+		if err != nil {
+			ctx.Error(err, operationFailedMessage)
+			return
+		}
+
+		response.UserInfo = &userInfo
+	}
+
+	err := ctx.SetConditionalJSONBody(response)
+	if err != nil {
+		ctx.Logger.Errorf("Unable to set portal response in body: %s", err)
+	}
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/internal/regulation"
+	"github.com/authelia/authelia/pkg/autheliatest"
+)
+
+type portalTestResponse struct {
+	middlewares.OKResponse
+
+	Data PortalResponse `json:"data"`
+}
+
+func parsePortalResponse(t *testing.T, mock *mocks.MockAutheliaCtx) PortalResponse {
+	response := portalTestResponse{}
+	require.NoError(t, json.Unmarshal(mock.Ctx.Response.Body(), &response))
+
+	return response.Data
+}
+
+func TestShouldServeOnlyStateToAnonymousUser(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	PortalGet(mock.Ctx)
+
+	assert.Equal(t, fasthttp.StatusOK, mock.Ctx.Response.StatusCode())
+	assert.NotEmpty(t, mock.Ctx.Response.Header.Peek("ETag"))
+
+	response := parsePortalResponse(t, mock)
+	assert.Equal(t, authentication.NotAuthenticated, response.State.AuthenticationLevel)
+	assert.Nil(t, response.Configuration)
+	assert.Nil(t, response.UserInfo)
+}
+
+func TestShouldServeLoginBannerToAnonymousUser(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	mock.Ctx.Configuration.Banner.Login = "Authorized use only."
+
+	PortalGet(mock.Ctx)
+
+	response := parsePortalResponse(t, mock)
+	assert.Equal(t, "Authorized use only.", response.LoginBanner)
+	assert.Nil(t, response.Configuration)
+}
+
+func TestShouldServePortalToAuthenticatedUserAndRevalidateIt(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	storage := autheliatest.NewMemoryStorageProvider()
+	require.NoError(t, storage.SavePreferred2FAMethod(testUsername, authentication.U2F))
+
+	mock.Ctx.Configuration.TOTP = &schema.TOTPConfiguration{Period: schema.DefaultTOTPConfiguration.Period}
+	mock.Ctx.Providers.StorageProvider = storage
+	mock.Ctx.Providers.Regulator = regulation.NewRegulator(nil, storage, &mock.Clock)
+
+	userSession := autheliatest.NewUserSessionBuilder(testUsername).OneFactor(mock.Clock.Now()).Build()
+	userSession.DisplayName = "John Doe"
+	mock.SetUserSession(t, userSession)
+
+	PortalGet(mock.Ctx)
+
+	assert.Equal(t, fasthttp.StatusOK, mock.Ctx.Response.StatusCode())
+
+	response := parsePortalResponse(t, mock)
+	assert.Equal(t, testUsername, response.State.Username)
+	assert.Equal(t, authentication.OneFactor, response.State.AuthenticationLevel)
+	require.NotNil(t, response.Configuration)
+	assert.Equal(t, schema.DefaultTOTPConfiguration.Period, response.Configuration.TOTPPeriod)
+	require.NotNil(t, response.UserInfo)
+	assert.Equal(t, "John Doe", response.UserInfo.DisplayName)
+	assert.Equal(t, authentication.U2F, response.UserInfo.Method)
+
+	etag := string(mock.Ctx.Response.Header.Peek("ETag"))
+
+	mock.Ctx.Response.Reset()
+	mock.Ctx.Request.Header.Set("If-None-Match", etag)
+
+	PortalGet(mock.Ctx)
+
+	assert.Equal(t, fasthttp.StatusNotModified, mock.Ctx.Response.StatusCode())
+	assert.Empty(t, mock.Ctx.Response.Body())
+
+	// The portal changes once the user changes their preferred method.
+	require.NoError(t, storage.SavePreferred2FAMethod(testUsername, authentication.TOTP))
+
+	mock.Ctx.Response.Reset()
+
+	PortalGet(mock.Ctx)
+
+	assert.Equal(t, fasthttp.StatusOK, mock.Ctx.Response.StatusCode())
+	assert.NotEqual(t, etag, string(mock.Ctx.Response.Header.Peek("ETag")))
+	assert.Equal(t, authentication.TOTP, parsePortalResponse(t, mock).UserInfo.Method)
+}
@@ -6,7 +6,9 @@ import (
 	"github.com/pquerna/otp/totp"
 
 	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/models"
 	"github.com/authelia/authelia/internal/session"
+	"github.com/authelia/authelia/internal/utils"
 )
 
 // identityRetrieverFromSession retriever computing the identity from the cookie session.
@@ -49,12 +51,39 @@ func secondFactorTOTPIdentityFinish(ctx *middlewares.AutheliaCtx, username strin
 		return
 	}
 
-	err = ctx.Providers.StorageProvider.SaveTOTPSecret(username, key.Secret())
+	devices, err := ctx.Providers.StorageProvider.LoadTOTPDevices(username)
 	if err != nil {
-		ctx.Error(fmt.Errorf("Unable to save TOTP secret in DB: %s", err), unableToRegisterOneTimePasswordMessage)
+		ctx.Error(fmt.Errorf("Unable to load the TOTP devices of user %s: %s", username, err), unableToRegisterOneTimePasswordMessage)
 		return
 	}
 
+	if len(devices) >= totpDevicesMaxCount {
+		ctx.Error(fmt.Errorf("User %s has already registered %d TOTP devices", username, len(devices)), unableToRegisterOneTimePasswordMessage)
+		return
+	}
+
+	id, err := utils.RandomSecureString(totpDeviceIDLength, utils.AlphaNumericCharacters)
+	if err != nil {
+		ctx.Error(fmt.Errorf("Unable to generate TOTP device ID: %s", err), unableToRegisterOneTimePasswordMessage)
+		return
+	}
+
+	// The device is named after the number of devices of the user, the user is able to rename it afterwards.
+	err = ctx.Providers.StorageProvider.SaveTOTPDevice(models.TOTPDevice{
+		ID:          id,
+		Username:    username,
+		Description: fmt.Sprintf("One-time password device %d", len(devices)+1),
+		Secret:      key.Secret(),
+		CreatedAt:   ctx.Clock.Now(),
+	})
+	if err != nil {
+		ctx.Error(fmt.Errorf("Unable to save TOTP device in DB: %s", err), unableToRegisterOneTimePasswordMessage)
+		return
+	}
+
+	appendUserEvent(ctx, username, models.UserEventTOTPRegistered)
+	publishDeviceRegistration(ctx, deviceOneTimePassword, false)
+
 	response := TOTPKeyResponse{
 		OTPAuthURL:   key.URL(),
 		Base32Secret: key.Secret(),
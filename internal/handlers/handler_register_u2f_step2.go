@@ -7,6 +7,7 @@ import (
 	"github.com/tstranex/u2f"
 
 	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/models"
 )
 
 // SecondFactorU2FRegister handler validating the client has successfully validated the challenge
@@ -44,6 +45,9 @@ func SecondFactorU2FRegister(ctx *middlewares.AutheliaCtx) {
 
 	ctx.Logger.Debugf("Register U2F device for user %s", userSession.Username)
 
+	_, _, err = ctx.Providers.StorageProvider.LoadU2FDeviceHandle(userSession.Username)
+	replaced := err == nil
+
 	publicKey := elliptic.Marshal(elliptic.P256(), registration.PubKey.X, registration.PubKey.Y)
 	err = ctx.Providers.StorageProvider.SaveU2FDeviceHandle(userSession.Username, registration.KeyHandle, publicKey)
 
@@ -52,5 +56,8 @@ func SecondFactorU2FRegister(ctx *middlewares.AutheliaCtx) {
 		return
 	}
 
+	appendUserEvent(ctx, userSession.Username, models.UserEventU2FRegistered)
+	publishDeviceRegistration(ctx, deviceSecurityKey, replaced)
+
 	ctx.ReplyOK()
 }
@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/session"
+	"github.com/authelia/authelia/internal/webauthn"
+)
+
+// WebauthnRegistrationOptionsPost handler for initiating the registration of a passkey. Passkeys can be used to login
+// without a password so the user must be authenticated with two factors to register one.
+func WebauthnRegistrationOptionsPost(ctx *middlewares.AutheliaCtx) {
+	userSession := ctx.GetSession()
+
+	if userSession.AuthenticationLevel < authentication.TwoFactor {
+		ctx.Logger.Debugf("User %s must be authenticated with two factors to register a passkey", userSession.Username)
+		ctx.ReplyForbidden()
+
+		return
+	}
+
+	rp, existing, challenge, err := newWebauthnRegistration(ctx, &userSession)
+	if err != nil {
+		ctx.Error(err, unableToRegisterSecurityKeyMessage)
+		return
+	}
+
+	userSession.WebauthnChallenge = challenge
+
+	if err = ctx.SaveSession(userSession); err != nil {
+		ctx.Error(fmt.Errorf("Unable to save WebAuthn challenge in session: %s", err), unableToRegisterSecurityKeyMessage)
+		return
+	}
+
+	options := rp.NewCreationOptions(challenge, []byte(userSession.Username), userSession.Username, webauthnDisplayName(&userSession),
+		existing, webauthnUserVerification(isPasskeyTwoFactor(ctx)))
+
+	if err = ctx.SetJSONBody(options); err != nil {
+		ctx.Error(fmt.Errorf("Unable to set WebAuthn creation options in body: %s", err), unableToRegisterSecurityKeyMessage)
+		return
+	}
+}
+
+// WebauthnRegistrationPost handler for completing the registration of a passkey.
+func WebauthnRegistrationPost(ctx *middlewares.AutheliaCtx) {
+	var bodyJSON webauthnRegistrationRequestBody
+
+	if err := ctx.ParseBody(&bodyJSON); err != nil {
+		ctx.Error(err, unableToRegisterSecurityKeyMessage)
+		return
+	}
+
+	userSession := ctx.GetSession()
+
+	if userSession.AuthenticationLevel < authentication.TwoFactor {
+		ctx.ReplyForbidden()
+		return
+	}
+
+	if userSession.WebauthnChallenge == nil {
+		ctx.Error(fmt.Errorf("WebAuthn registration has not been initiated yet (no challenge)"), unableToRegisterSecurityKeyMessage)
+		return
+	}
+
+	challenge := userSession.WebauthnChallenge
+	userSession.WebauthnChallenge = nil
+
+	if err := ctx.SaveSession(userSession); err != nil {
+		ctx.Error(fmt.Errorf("Unable to clear WebAuthn challenge from session: %s", err), unableToRegisterSecurityKeyMessage)
+		return
+	}
+
+	if err := saveWebauthnRegistration(ctx, &userSession, challenge, bodyJSON, models.WebauthnCredentialTypePasskey, isPasskeyTwoFactor(ctx)); err != nil {
+		ctx.Error(err, unableToRegisterSecurityKeyMessage)
+		return
+	}
+
+	appendUserEvent(ctx, userSession.Username, models.UserEventPasskeyRegistered)
+	publishDeviceRegistration(ctx, devicePasskey, false)
+
+	ctx.ReplyOK()
+}
+
+// SecondFactorWebauthnIdentityStart the handler for initiating the identity validation.
+var SecondFactorWebauthnIdentityStart = middlewares.IdentityVerificationStart(middlewares.IdentityVerificationStartArgs{
+	MailTitle:             "Register your security key",
+	MailButtonContent:     "Register",
+	TargetEndpoint:        "/webauthn/register",
+	ActionClaim:           WebauthnRegistrationAction,
+	IdentityRetrieverFunc: identityRetrieverFromSession,
+})
+
+func secondFactorWebauthnIdentityFinish(ctx *middlewares.AutheliaCtx, username string) {
+	userSession := ctx.GetSession()
+
+	rp, existing, challenge, err := newWebauthnRegistration(ctx, &userSession)
+	if err != nil {
+		ctx.Error(err, operationFailedMessage)
+		return
+	}
+
+	userSession.WebauthnRegistrationChallenge = challenge
+
+	if err = ctx.SaveSession(userSession); err != nil {
+		ctx.Error(fmt.Errorf("Unable to save WebAuthn challenge in session: %s", err), operationFailedMessage)
+		return
+	}
+
+	options := rp.NewSecondFactorCreationOptions(challenge, []byte(username), username, webauthnDisplayName(&userSession), existing)
+
+	if err = ctx.SetJSONBody(options); err != nil {
+		ctx.Logger.Errorf("Unable to set WebAuthn creation options in body: %s", err)
+	}
+}
+
+// SecondFactorWebauthnIdentityFinish the handler for finishing the identity validation.
+var SecondFactorWebauthnIdentityFinish = middlewares.IdentityVerificationFinish(
+	middlewares.IdentityVerificationFinishArgs{
+		ActionClaim:          WebauthnRegistrationAction,
+		IsTokenUserValidFunc: isTokenUserValidFor2FARegistration,
+	}, secondFactorWebauthnIdentityFinish)
+
+// SecondFactorWebauthnRegister handler for completing the registration of a WebAuthn credential used as a second
+// factor. The registration is initiated by the identity verification.
+func SecondFactorWebauthnRegister(ctx *middlewares.AutheliaCtx) {
+	var bodyJSON webauthnRegistrationRequestBody
+
+	if err := ctx.ParseBody(&bodyJSON); err != nil {
+		ctx.Error(err, unableToRegisterSecurityKeyMessage)
+		return
+	}
+
+	userSession := ctx.GetSession()
+
+	if userSession.WebauthnRegistrationChallenge == nil {
+		ctx.Error(fmt.Errorf("WebAuthn registration has not been initiated yet (no challenge)"), unableToRegisterSecurityKeyMessage)
+		return
+	}
+
+	challenge := userSession.WebauthnRegistrationChallenge
+	userSession.WebauthnRegistrationChallenge = nil
+
+	if err := ctx.SaveSession(userSession); err != nil {
+		ctx.Error(fmt.Errorf("Unable to clear WebAuthn challenge from session: %s", err), unableToRegisterSecurityKeyMessage)
+		return
+	}
+
+	if err := saveWebauthnRegistration(ctx, &userSession, challenge, bodyJSON, models.WebauthnCredentialTypeSecondFactor, false); err != nil {
+		ctx.Error(err, unableToRegisterSecurityKeyMessage)
+		return
+	}
+
+	appendUserEvent(ctx, userSession.Username, models.UserEventWebauthnRegistered)
+	publishDeviceRegistration(ctx, deviceSecurityKey, false)
+
+	ctx.ReplyOK()
+}
+
+// newWebauthnRegistration returns the relying party, the IDs of the credentials already registered by the user of the
+// session and a new challenge to initiate the registration of a credential.
+func newWebauthnRegistration(ctx *middlewares.AutheliaCtx, userSession *session.UserSession) (rp *webauthn.RelyingParty, existing [][]byte, challenge []byte, err error) {
+	if rp, err = newWebauthnRelyingParty(ctx); err != nil {
+		return nil, nil, nil, err
+	}
+
+	if challenge, err = webauthn.NewChallenge(); err != nil {
+		return nil, nil, nil, fmt.Errorf("Unable to create WebAuthn challenge: %s", err)
+	}
+
+	credentials, err := ctx.Providers.StorageProvider.LoadWebauthnCredentials(userSession.Username)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("Unable to load the WebAuthn credentials of user %s: %s", userSession.Username, err)
+	}
+
+	existing = make([][]byte, 0, len(credentials))
+	for _, credential := range credentials {
+		existing = append(existing, credential.ID)
+	}
+
+	return rp, existing, challenge, nil
+}
+
+// saveWebauthnRegistration verifies the credential registered with the challenge and saves it along with its type and
+// friendly name.
+func saveWebauthnRegistration(ctx *middlewares.AutheliaCtx, userSession *session.UserSession, challenge []byte,
+	bodyJSON webauthnRegistrationRequestBody, credentialType string, requireUserVerification bool) (err error) {
+	description := strings.TrimSpace(bodyJSON.Description)
+
+	if len(description) > webauthnDescriptionMaxLength {
+		return fmt.Errorf("Description of the WebAuthn credential of user %s is longer than %d characters", userSession.Username, webauthnDescriptionMaxLength)
+	}
+
+	rp, err := newWebauthnRelyingParty(ctx)
+	if err != nil {
+		return err
+	}
+
+	credential, err := rp.VerifyRegistration(challenge, bodyJSON.CredentialAttestation, requireUserVerification)
+	if err != nil {
+		return fmt.Errorf("Unable to verify WebAuthn registration of user %s: %s", userSession.Username, err)
+	}
+
+	err = ctx.Providers.StorageProvider.SaveWebauthnCredential(models.WebauthnCredential{
+		ID:          credential.ID,
+		Username:    userSession.Username,
+		Type:        credentialType,
+		Description: description,
+		PublicKey:   credential.PublicKey,
+		SignCount:   credential.SignCount,
+		CreatedAt:   ctx.Clock.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("Unable to save WebAuthn credential of user %s: %s", userSession.Username, err)
+	}
+
+	return nil
+}
+
+func webauthnDisplayName(userSession *session.UserSession) string {
+	if userSession.DisplayName == "" {
+		return userSession.Username
+	}
+
+	return userSession.DisplayName
+}
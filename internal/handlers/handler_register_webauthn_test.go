@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/pkg/autheliatest"
+)
+
+type RegisterWebauthnSuite struct {
+	suite.Suite
+
+	mock    *mocks.MockAutheliaCtx
+	storage *autheliatest.MemoryStorageProvider
+}
+
+func (s *RegisterWebauthnSuite) SetupTest() {
+	s.mock = mocks.NewMockAutheliaCtx(s.T())
+	s.mock.Ctx.Configuration.Session.Domain = "example.com"
+	s.mock.Ctx.Configuration.Webauthn = newTestWebauthnConfiguration("two_factor")
+	s.mock.Ctx.Configuration.Server.ExternalURL = "https://login.example.com"
+
+	s.storage = autheliatest.NewMemoryStorageProvider()
+	s.mock.Ctx.Providers.StorageProvider = s.storage
+}
+
+func (s *RegisterWebauthnSuite) TearDownTest() {
+	s.mock.Close()
+}
+
+func (s *RegisterWebauthnSuite) TestShouldRegisterPasskey() {
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder(testUsername).WithEmails("john@example.com").TwoFactor(s.mock.Clock.Now()).Build())
+
+	s.mock.NotifierMock.EXPECT().
+		Send(gomock.Eq("john@example.com"), gomock.Eq("New passkey registered"), gomock.Any(), gomock.Any()).
+		Return(nil)
+
+	WebauthnRegistrationOptionsPost(s.mock.Ctx)
+	s.Require().Equal(200, s.mock.Ctx.Response.StatusCode())
+
+	challenge := s.mock.Ctx.GetSession().WebauthnChallenge
+	s.Require().Len(challenge, 32)
+
+	authenticator := mocks.NewWebauthnAuthenticator([]byte(testUsername))
+
+	body, err := json.Marshal(authenticator.Create("example.com", "https://login.example.com", challenge))
+	s.Require().NoError(err)
+
+	s.mock.Ctx.Response.Reset()
+	s.mock.Ctx.Request.SetBody(body)
+
+	WebauthnRegistrationPost(s.mock.Ctx)
+
+	s.Assert().Equal(200, s.mock.Ctx.Response.StatusCode())
+	s.Assert().Equal([]byte("{\"status\":\"OK\"}"), s.mock.Ctx.Response.Body())
+	s.Assert().Nil(s.mock.Ctx.GetSession().WebauthnChallenge)
+
+	credentials, err := s.storage.LoadWebauthnCredentials(testUsername)
+	s.Require().NoError(err)
+	s.Require().Len(credentials, 1)
+	s.Assert().Equal(authenticator.CredentialID, credentials[0].ID)
+	s.Assert().Equal(authenticator.PublicKey(), credentials[0].PublicKey)
+	s.Assert().Equal(models.WebauthnCredentialTypePasskey, credentials[0].Type)
+	s.Assert().False(credentials[0].CreatedAt.IsZero())
+
+	events, err := s.storage.LoadUserEvents(testUsername, models.UserEventPasskeyRegistered, 10, 0)
+	s.Require().NoError(err)
+	s.Assert().Len(events, 1)
+}
+
+func (s *RegisterWebauthnSuite) TestShouldRefuseRegistrationWithOneFactor() {
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder(testUsername).OneFactor(s.mock.Clock.Now()).Build())
+
+	WebauthnRegistrationOptionsPost(s.mock.Ctx)
+
+	s.Assert().Equal(403, s.mock.Ctx.Response.StatusCode())
+	s.Assert().Nil(s.mock.Ctx.GetSession().WebauthnChallenge)
+}
+
+func (s *RegisterWebauthnSuite) TestShouldRegisterSecondFactorCredentialWithDescription() {
+	userSession := autheliatest.NewUserSessionBuilder(testUsername).WithEmails("john@example.com").OneFactor(s.mock.Clock.Now()).Build()
+	userSession.WebauthnRegistrationChallenge = []byte("registration challenge")
+	s.mock.SetUserSession(s.T(), userSession)
+
+	s.mock.NotifierMock.EXPECT().
+		Send(gomock.Eq("john@example.com"), gomock.Eq("New security key registered"), gomock.Any(), gomock.Any()).
+		Return(nil)
+
+	authenticator := mocks.NewWebauthnAuthenticator([]byte(testUsername))
+
+	body, err := json.Marshal(webauthnRegistrationRequestBody{
+		CredentialAttestation: authenticator.Create("example.com", "https://login.example.com", userSession.WebauthnRegistrationChallenge),
+		Description:           " YubiKey ",
+	})
+	s.Require().NoError(err)
+
+	s.mock.Ctx.Request.SetBody(body)
+
+	SecondFactorWebauthnRegister(s.mock.Ctx)
+
+	s.Assert().Equal([]byte("{\"status\":\"OK\"}"), s.mock.Ctx.Response.Body())
+	s.Assert().Nil(s.mock.Ctx.GetSession().WebauthnRegistrationChallenge)
+
+	credentials, err := s.storage.LoadWebauthnCredentials(testUsername)
+	s.Require().NoError(err)
+	s.Require().Len(credentials, 1)
+	s.Assert().Equal(authenticator.CredentialID, credentials[0].ID)
+	s.Assert().Equal("YubiKey", credentials[0].Description)
+	s.Assert().Equal(models.WebauthnCredentialTypeSecondFactor, credentials[0].Type)
+
+	events, err := s.storage.LoadUserEvents(testUsername, models.UserEventWebauthnRegistered, 10, 0)
+	s.Require().NoError(err)
+	s.Assert().Len(events, 1)
+}
+
+func (s *RegisterWebauthnSuite) TestShouldRefuseSecondFactorRegistrationWithoutIdentityVerification() {
+	// The challenge of another ceremony, like the passkey login, can't be used to register a credential.
+	userSession := autheliatest.NewUserSessionBuilder(testUsername).OneFactor(s.mock.Clock.Now()).Build()
+	userSession.WebauthnChallenge = []byte("login challenge")
+	s.mock.SetUserSession(s.T(), userSession)
+
+	authenticator := mocks.NewWebauthnAuthenticator([]byte(testUsername))
+
+	body, err := json.Marshal(authenticator.Create("example.com", "https://login.example.com", userSession.WebauthnChallenge))
+	s.Require().NoError(err)
+
+	s.mock.Ctx.Request.SetBody(body)
+
+	SecondFactorWebauthnRegister(s.mock.Ctx)
+
+	s.mock.Assert200KO(s.T(), "Unable to register your security key.")
+	s.Assert().Equal("WebAuthn registration has not been initiated yet (no challenge)", s.mock.Hook.LastEntry().Message)
+
+	credentials, err := s.storage.LoadWebauthnCredentials(testUsername)
+	s.Require().NoError(err)
+	s.Assert().Len(credentials, 0)
+}
+
+func TestRunRegisterWebauthnSuite(t *testing.T) {
+	suite.Run(t, new(RegisterWebauthnSuite))
+}
@@ -2,8 +2,12 @@ package handlers
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/events"
 	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/models"
 	"github.com/authelia/authelia/internal/utils"
 )
 
@@ -27,6 +31,11 @@ func ResetPasswordPost(ctx *middlewares.AutheliaCtx) {
 		return
 	}
 
+	if unmet := authentication.NewPasswordPolicy(ctx.Configuration.PasswordPolicy).Check(requestBody.Password); len(unmet) != 0 {
+		ctx.Error(fmt.Errorf("Password doesn't meet the password policy rules: %s", strings.Join(unmet, ", ")), passwordPolicyMessage)
+		return
+	}
+
 	err = ctx.Providers.UserProvider.UpdatePassword(*userSession.PasswordResetUsername, requestBody.Password)
 
 	if err != nil {
@@ -43,6 +52,9 @@ func ResetPasswordPost(ctx *middlewares.AutheliaCtx) {
 
 	ctx.Logger.Debugf("Password of user %s has been reset", *userSession.PasswordResetUsername)
 
+	appendUserEvent(ctx, *userSession.PasswordResetUsername, models.UserEventPasswordReset)
+	ctx.PublishEvent(events.PasswordReset, *userSession.PasswordResetUsername, nil)
+
 	// Reset the request.
 	userSession.PasswordResetUsername = nil
 	err = ctx.SaveSession(userSession)
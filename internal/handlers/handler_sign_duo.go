@@ -5,7 +5,9 @@ import (
 	"net/url"
 
 	"github.com/authelia/authelia/internal/duo"
+	"github.com/authelia/authelia/internal/events"
 	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/models"
 )
 
 // SecondFactorDuoPost handler for sending a push notification via duo api.
@@ -22,6 +24,10 @@ func SecondFactorDuoPost(duoAPI duo.API) middlewares.RequestHandler {
 		userSession := ctx.GetSession()
 		remoteIP := ctx.RemoteIP().String()
 
+		if isSecondFactorBanned(ctx, userSession.Username) {
+			return
+		}
+
 		ctx.Logger.Debugf("Starting Duo Push Auth Attempt for %s from IP %s", userSession.Username, remoteIP)
 
 		values := url.Values{}
@@ -35,6 +41,8 @@ func SecondFactorDuoPost(duoAPI duo.API) middlewares.RequestHandler {
 			values.Set("pushinfo", fmt.Sprintf("target%%20url=%s", requestBody.TargetURL))
 		}
 
+		ctx.PublishEvent(events.PushRequested, userSession.Username, nil)
+
 		duoResponse, err := duoAPI.Call(values, ctx)
 		if err != nil {
 			handleAuthenticationUnauthorized(ctx, fmt.Errorf("Duo API errored: %s", err), mfaValidationFailedMessage)
@@ -53,10 +61,14 @@ func SecondFactorDuoPost(duoAPI duo.API) middlewares.RequestHandler {
 		}
 
 		if duoResponse.Response.Result != testResultAllow {
+			markSecondFactorAttempt(ctx, userSession.Username, false, models.AuthenticationTypeDuo)
 			ctx.ReplyUnauthorized()
+
 			return
 		}
 
+		markSecondFactorAttempt(ctx, userSession.Username, true, models.AuthenticationTypeDuo)
+
 		err = ctx.Providers.SessionProvider.RegenerateSession(ctx.RequestCtx)
 
 		if err != nil {
@@ -65,6 +77,7 @@ func SecondFactorDuoPost(duoAPI duo.API) middlewares.RequestHandler {
 		}
 
 		userSession.SetTwoFactor(ctx.Clock.Now())
+		userSession.SetSecondFactorClient(ctx.ClientMetadata())
 
 		err = ctx.SaveSession(userSession)
 		if err != nil {
@@ -75,7 +88,7 @@ func SecondFactorDuoPost(duoAPI duo.API) middlewares.RequestHandler {
 		if userSession.OIDCWorkflowSession != nil {
 			handleOIDCWorkflowResponse(ctx)
 		} else {
-			Handle2FAResponse(ctx, requestBody.TargetURL)
+			Handle2FAResponse(ctx, requestBody.TargetURL, requestBody.State)
 		}
 	}
 }
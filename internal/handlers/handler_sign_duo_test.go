@@ -49,6 +49,10 @@ func (s *SecondFactorDuoPostSuite) TestShouldCallDuoAPIAndAllowAccess() {
 
 	duoMock.EXPECT().Call(gomock.Eq(values), s.mock.Ctx).Return(&response, nil)
 
+	s.mock.StorageProviderMock.EXPECT().
+		AppendAuthenticationLog(gomock.Any()).
+		Return(nil)
+
 	s.mock.Ctx.Request.SetBodyString("{\"targetURL\": \"https://target.example.com\"}")
 
 	SecondFactorDuoPost(duoMock)(s.mock.Ctx)
@@ -71,6 +75,10 @@ func (s *SecondFactorDuoPostSuite) TestShouldCallDuoAPIAndDenyAccess() {
 
 	duoMock.EXPECT().Call(gomock.Eq(values), s.mock.Ctx).Return(&response, nil)
 
+	s.mock.StorageProviderMock.EXPECT().
+		AppendAuthenticationLog(gomock.Any()).
+		Return(nil)
+
 	s.mock.Ctx.Request.SetBodyString("{\"targetURL\": \"https://target.example.com\"}")
 
 	SecondFactorDuoPost(duoMock)(s.mock.Ctx)
@@ -105,6 +113,10 @@ func (s *SecondFactorDuoPostSuite) TestShouldRedirectUserToDefaultURL() {
 
 	duoMock.EXPECT().Call(gomock.Any(), s.mock.Ctx).Return(&response, nil)
 
+	s.mock.StorageProviderMock.EXPECT().
+		AppendAuthenticationLog(gomock.Any()).
+		Return(nil)
+
 	s.mock.Ctx.Configuration.DefaultRedirectionURL = testRedirectionURL
 
 	bodyBytes, err := json.Marshal(signDuoRequestBody{})
@@ -125,6 +137,10 @@ func (s *SecondFactorDuoPostSuite) TestShouldNotReturnRedirectURL() {
 
 	duoMock.EXPECT().Call(gomock.Any(), s.mock.Ctx).Return(&response, nil)
 
+	s.mock.StorageProviderMock.EXPECT().
+		AppendAuthenticationLog(gomock.Any()).
+		Return(nil)
+
 	bodyBytes, err := json.Marshal(signDuoRequestBody{})
 	s.Require().NoError(err)
 	s.mock.Ctx.Request.SetBody(bodyBytes)
@@ -134,6 +150,8 @@ func (s *SecondFactorDuoPostSuite) TestShouldNotReturnRedirectURL() {
 }
 
 func (s *SecondFactorDuoPostSuite) TestShouldRedirectUserToSafeTargetURL() {
+	s.mock.Ctx.Configuration.Session.Domain = "mydomain.local"
+
 	duoMock := mocks.NewMockAPI(s.mock.Ctrl)
 
 	response := duo.Response{}
@@ -141,6 +159,10 @@ func (s *SecondFactorDuoPostSuite) TestShouldRedirectUserToSafeTargetURL() {
 
 	duoMock.EXPECT().Call(gomock.Any(), s.mock.Ctx).Return(&response, nil)
 
+	s.mock.StorageProviderMock.EXPECT().
+		AppendAuthenticationLog(gomock.Any()).
+		Return(nil)
+
 	bodyBytes, err := json.Marshal(signDuoRequestBody{
 		TargetURL: "https://mydomain.local",
 	})
@@ -161,6 +183,10 @@ func (s *SecondFactorDuoPostSuite) TestShouldNotRedirectToUnsafeURL() {
 
 	duoMock.EXPECT().Call(gomock.Any(), s.mock.Ctx).Return(&response, nil)
 
+	s.mock.StorageProviderMock.EXPECT().
+		AppendAuthenticationLog(gomock.Any()).
+		Return(nil)
+
 	bodyBytes, err := json.Marshal(signDuoRequestBody{
 		TargetURL: "http://mydomain.local",
 	})
@@ -179,6 +205,10 @@ func (s *SecondFactorDuoPostSuite) TestShouldRegenerateSessionForPreventingSessi
 
 	duoMock.EXPECT().Call(gomock.Any(), s.mock.Ctx).Return(&response, nil)
 
+	s.mock.StorageProviderMock.EXPECT().
+		AppendAuthenticationLog(gomock.Any()).
+		Return(nil)
+
 	bodyBytes, err := json.Marshal(signDuoRequestBody{
 		TargetURL: "http://mydomain.local",
 	})
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/authelia/authelia/internal/events"
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/push"
+)
+
+// SecondFactorPushPost handler sending a push notification to the devices of the user without waiting for the user to
+// approve it, the approval is polled with SecondFactorPushStatusPost. The enrollment state of the user is checked with
+// the provider and saved first, the notification is only sent to the enrolled users.
+func SecondFactorPushPost(provider push.Provider) middlewares.RequestHandler {
+	return func(ctx *middlewares.AutheliaCtx) {
+		var requestBody signDuoRequestBody
+
+		if err := ctx.ParseBody(&requestBody); err != nil {
+			handleAuthenticationUnauthorized(ctx, err, mfaValidationFailedMessage)
+			return
+		}
+
+		userSession := ctx.GetSession()
+		remoteIP := ctx.RemoteIP().String()
+
+		if isSecondFactorBanned(ctx, userSession.Username) {
+			return
+		}
+
+		enrollment, err := provider.Enrollment(userSession.Username, remoteIP)
+		if err != nil {
+			handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to check the enrollment of user %s with the push provider %s: %s",
+				userSession.Username, provider.Name(), err), mfaValidationFailedMessage)
+
+			return
+		}
+
+		if err = ctx.Providers.StorageProvider.SavePushEnrollment(models.PushEnrollment{
+			Username:  userSession.Username,
+			Provider:  provider.Name(),
+			Status:    enrollment.Status,
+			Devices:   enrollment.Devices,
+			CheckedAt: ctx.Clock.Now(),
+		}); err != nil {
+			ctx.Logger.Errorf("Unable to save the push enrollment of user %s: %s", userSession.Username, err)
+		}
+
+		switch enrollment.Status {
+		case models.PushEnrollmentNotEnrolled:
+			ctx.Logger.Debugf("User %s is not enrolled with the push provider %s", userSession.Username, provider.Name())
+
+			if err = ctx.SetJSONBody(pushStartResponseBody{Enrollment: enrollment.Status, EnrollURL: enrollment.EnrollURL}); err != nil {
+				ctx.Logger.Errorf("Unable to set push response in body: %s", err)
+			}
+
+			return
+		case models.PushEnrollmentDenied:
+			ctx.Logger.Warnf("Push provider %s denied the authentication of user %s", provider.Name(), userSession.Username)
+			markSecondFactorAttempt(ctx, userSession.Username, false, models.AuthenticationTypePush)
+			ctx.ReplyUnauthorized()
+
+			return
+		}
+
+		transactionID, err := provider.Start(push.Request{
+			Username:  userSession.Username,
+			RemoteIP:  remoteIP,
+			TargetURL: requestBody.TargetURL,
+		})
+		if err != nil {
+			handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to send a push notification to user %s: %s", userSession.Username, err), mfaValidationFailedMessage)
+			return
+		}
+
+		ctx.PublishEvent(events.PushRequested, userSession.Username, nil)
+
+		userSession.PushTransactionID = transactionID
+
+		if err = ctx.SaveSession(userSession); err != nil {
+			handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to save the push transaction of user %s: %s", userSession.Username, err), mfaValidationFailedMessage)
+			return
+		}
+
+		if err = ctx.SetJSONBody(pushStartResponseBody{Enrollment: enrollment.Status}); err != nil {
+			ctx.Logger.Errorf("Unable to set push response in body: %s", err)
+		}
+	}
+}
+
+// SecondFactorPushStatusPost handler polling the push notification sent by SecondFactorPushPost. The result is
+// returned while the user hasn't approved or denied it, the user is authenticated like with the other second factors
+// once approved.
+func SecondFactorPushStatusPost(provider push.Provider) middlewares.RequestHandler {
+	return func(ctx *middlewares.AutheliaCtx) {
+		var requestBody signDuoRequestBody
+
+		if err := ctx.ParseBody(&requestBody); err != nil {
+			handleAuthenticationUnauthorized(ctx, err, mfaValidationFailedMessage)
+			return
+		}
+
+		userSession := ctx.GetSession()
+
+		if userSession.PushTransactionID == "" {
+			handleAuthenticationUnauthorized(ctx, fmt.Errorf("No push notification has been sent to user %s", userSession.Username), mfaValidationFailedMessage)
+			return
+		}
+
+		result, err := provider.Status(userSession.PushTransactionID)
+		if err != nil {
+			handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to check the push notification of user %s: %s", userSession.Username, err), mfaValidationFailedMessage)
+			return
+		}
+
+		if result == push.ResultWaiting {
+			if err = ctx.SetJSONBody(pushStatusResponseBody{Result: string(result)}); err != nil {
+				ctx.Logger.Errorf("Unable to set push status response in body: %s", err)
+			}
+
+			return
+		}
+
+		userSession.PushTransactionID = ""
+
+		if result != push.ResultAllow {
+			markSecondFactorAttempt(ctx, userSession.Username, false, models.AuthenticationTypePush)
+
+			if err = ctx.SaveSession(userSession); err != nil {
+				ctx.Logger.Errorf("Unable to clear the push transaction of user %s: %s", userSession.Username, err)
+			}
+
+			ctx.ReplyUnauthorized()
+
+			return
+		}
+
+		markSecondFactorAttempt(ctx, userSession.Username, true, models.AuthenticationTypePush)
+
+		if err = ctx.Providers.SessionProvider.RegenerateSession(ctx.RequestCtx); err != nil {
+			handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to regenerate session for user %s: %s", userSession.Username, err), mfaValidationFailedMessage)
+			return
+		}
+
+		userSession.SetTwoFactor(ctx.Clock.Now())
+		userSession.SetSecondFactorClient(ctx.ClientMetadata())
+
+		if err = ctx.SaveSession(userSession); err != nil {
+			handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to update authentication level with push: %s", err), mfaValidationFailedMessage)
+			return
+		}
+
+		if userSession.OIDCWorkflowSession != nil {
+			handleOIDCWorkflowResponse(ctx)
+		} else {
+			Handle2FAResponse(ctx, requestBody.TargetURL, requestBody.State)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/push"
+)
+
+type SecondFactorPushSuite struct {
+	suite.Suite
+
+	mock     *mocks.MockAutheliaCtx
+	provider *mocks.MockPushProvider
+}
+
+func (s *SecondFactorPushSuite) SetupTest() {
+	s.mock = mocks.NewMockAutheliaCtx(s.T())
+	s.provider = mocks.NewMockPushProvider(s.mock.Ctrl)
+	s.provider.EXPECT().Name().Return(push.ProviderNameDuo).AnyTimes()
+
+	userSession := s.mock.Ctx.GetSession()
+	userSession.Username = testUsername
+	err := s.mock.Ctx.SaveSession(userSession)
+	require.NoError(s.T(), err)
+}
+
+func (s *SecondFactorPushSuite) TearDownTest() {
+	s.mock.Close()
+}
+
+func (s *SecondFactorPushSuite) setTransaction(transactionID string) {
+	userSession := s.mock.Ctx.GetSession()
+	userSession.PushTransactionID = transactionID
+	s.Require().NoError(s.mock.Ctx.SaveSession(userSession))
+}
+
+func (s *SecondFactorPushSuite) TestShouldSendPushNotificationToEnrolledUser() {
+	s.provider.EXPECT().
+		Enrollment(gomock.Eq("john"), gomock.Eq(s.mock.Ctx.RemoteIP().String())).
+		Return(&push.Enrollment{Status: models.PushEnrollmentEnrolled, Devices: 2}, nil)
+
+	s.mock.StorageProviderMock.EXPECT().
+		SavePushEnrollment(gomock.Any()).
+		DoAndReturn(func(enrollment models.PushEnrollment) error {
+			s.Assert().Equal("john", enrollment.Username)
+			s.Assert().Equal(push.ProviderNameDuo, enrollment.Provider)
+			s.Assert().Equal(models.PushEnrollmentEnrolled, enrollment.Status)
+			s.Assert().Equal(2, enrollment.Devices)
+
+			return nil
+		})
+
+	s.provider.EXPECT().
+		Start(gomock.Eq(push.Request{
+			Username:  "john",
+			RemoteIP:  s.mock.Ctx.RemoteIP().String(),
+			TargetURL: "https://target.example.com",
+		})).
+		Return("tx-123", nil)
+
+	s.mock.Ctx.Request.SetBodyString("{\"targetURL\": \"https://target.example.com\"}")
+
+	SecondFactorPushPost(s.provider)(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), pushStartResponseBody{Enrollment: models.PushEnrollmentEnrolled})
+	s.Assert().Equal("tx-123", s.mock.Ctx.GetSession().PushTransactionID)
+}
+
+func (s *SecondFactorPushSuite) TestShouldReturnEnrollURLToUserNotEnrolled() {
+	s.provider.EXPECT().
+		Enrollment(gomock.Eq("john"), gomock.Any()).
+		Return(&push.Enrollment{Status: models.PushEnrollmentNotEnrolled, EnrollURL: "https://duo.example.com/enroll"}, nil)
+
+	s.mock.StorageProviderMock.EXPECT().
+		SavePushEnrollment(gomock.Any()).
+		Return(nil)
+
+	s.mock.Ctx.Request.SetBodyString("{}")
+
+	SecondFactorPushPost(s.provider)(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), pushStartResponseBody{
+		Enrollment: models.PushEnrollmentNotEnrolled,
+		EnrollURL:  "https://duo.example.com/enroll",
+	})
+	s.Assert().Equal("", s.mock.Ctx.GetSession().PushTransactionID)
+}
+
+func (s *SecondFactorPushSuite) TestShouldDenyUserDeniedByProvider() {
+	s.provider.EXPECT().
+		Enrollment(gomock.Eq("john"), gomock.Any()).
+		Return(&push.Enrollment{Status: models.PushEnrollmentDenied}, nil)
+
+	s.mock.StorageProviderMock.EXPECT().
+		SavePushEnrollment(gomock.Any()).
+		Return(nil)
+
+	s.mock.StorageProviderMock.EXPECT().
+		AppendAuthenticationLog(gomock.Any()).
+		Return(nil)
+
+	s.mock.Ctx.Request.SetBodyString("{}")
+
+	SecondFactorPushPost(s.provider)(s.mock.Ctx)
+
+	assert.Equal(s.T(), 401, s.mock.Ctx.Response.StatusCode())
+}
+
+func (s *SecondFactorPushSuite) TestShouldFailWhenEnrollmentCheckFails() {
+	s.provider.EXPECT().
+		Enrollment(gomock.Eq("john"), gomock.Any()).
+		Return(nil, fmt.Errorf("connection error"))
+
+	s.mock.Ctx.Request.SetBodyString("{}")
+
+	SecondFactorPushPost(s.provider)(s.mock.Ctx)
+
+	s.mock.Assert401KO(s.T(), "Authentication failed, please retry later.")
+}
+
+func (s *SecondFactorPushSuite) TestShouldReturnWaitingWhilePending() {
+	s.setTransaction("tx-123")
+
+	s.provider.EXPECT().
+		Status(gomock.Eq("tx-123")).
+		Return(push.ResultWaiting, nil)
+
+	s.mock.Ctx.Request.SetBodyString("{}")
+
+	SecondFactorPushStatusPost(s.provider)(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), pushStatusResponseBody{Result: "waiting"})
+	s.Assert().Equal("tx-123", s.mock.Ctx.GetSession().PushTransactionID)
+}
+
+func (s *SecondFactorPushSuite) TestShouldAuthenticateUserOnceApproved() {
+	s.setTransaction("tx-123")
+	s.mock.Ctx.Configuration.DefaultRedirectionURL = testRedirectionURL
+
+	s.provider.EXPECT().
+		Status(gomock.Eq("tx-123")).
+		Return(push.ResultAllow, nil)
+
+	s.mock.StorageProviderMock.EXPECT().
+		AppendAuthenticationLog(gomock.Any()).
+		Return(nil)
+
+	s.mock.Ctx.Request.SetBodyString("{}")
+
+	SecondFactorPushStatusPost(s.provider)(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), redirectResponse{Redirect: testRedirectionURL})
+
+	userSession := s.mock.Ctx.GetSession()
+	s.Assert().Equal("", userSession.PushTransactionID)
+	s.Assert().Equal(authentication.TwoFactor, userSession.AuthenticationLevel)
+}
+
+func (s *SecondFactorPushSuite) TestShouldDenyUserOnceDenied() {
+	s.setTransaction("tx-123")
+
+	s.provider.EXPECT().
+		Status(gomock.Eq("tx-123")).
+		Return(push.ResultDeny, nil)
+
+	s.mock.StorageProviderMock.EXPECT().
+		AppendAuthenticationLog(gomock.Any()).
+		Return(nil)
+
+	s.mock.Ctx.Request.SetBodyString("{}")
+
+	SecondFactorPushStatusPost(s.provider)(s.mock.Ctx)
+
+	assert.Equal(s.T(), 401, s.mock.Ctx.Response.StatusCode())
+	s.Assert().Equal("", s.mock.Ctx.GetSession().PushTransactionID)
+}
+
+func (s *SecondFactorPushSuite) TestShouldFailPollingWithoutTransaction() {
+	s.mock.Ctx.Request.SetBodyString("{}")
+
+	SecondFactorPushStatusPost(s.provider)(s.mock.Ctx)
+
+	s.mock.Assert401KO(s.T(), "Authentication failed, please retry later.")
+}
+
+func TestRunSecondFactorPushSuite(t *testing.T) {
+	s := new(SecondFactorPushSuite)
+	suite.Run(t, s)
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 
 	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/storage"
 )
 
 // SecondFactorTOTPPost validate the TOTP passcode provided by the user.
@@ -19,23 +21,48 @@ func SecondFactorTOTPPost(totpVerifier TOTPVerifier) middlewares.RequestHandler
 
 		userSession := ctx.GetSession()
 
-		secret, err := ctx.Providers.StorageProvider.LoadTOTPSecret(userSession.Username)
+		if isSecondFactorBanned(ctx, userSession.Username) {
+			return
+		}
+
+		devices, err := ctx.Providers.StorageProvider.LoadTOTPDevices(userSession.Username)
 		if err != nil {
-			handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to load TOTP secret: %s", err), mfaValidationFailedMessage)
+			handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to load TOTP devices: %s", err), mfaValidationFailedMessage)
 			return
 		}
 
-		isValid, err := totpVerifier.Verify(requestBody.Token, secret)
+		if len(devices) == 0 {
+			handleAuthenticationUnauthorized(ctx, fmt.Errorf("No TOTP device registered for user %s", userSession.Username), mfaValidationFailedMessage)
+			return
+		}
+
+		device, step, isValid, err := verifyTOTPDevices(totpVerifier, requestBody.Token, devices)
 		if err != nil {
 			handleAuthenticationUnauthorized(ctx, fmt.Errorf("Error occurred during OTP validation for user %s: %s", userSession.Username, err), mfaValidationFailedMessage)
 			return
 		}
 
 		if !isValid {
+			markSecondFactorAttempt(ctx, userSession.Username, false, models.AuthenticationTypeTOTP)
 			handleAuthenticationUnauthorized(ctx, fmt.Errorf("Wrong passcode during TOTP validation for user %s", userSession.Username), mfaValidationFailedMessage)
 			return
 		}
 
+		if err = ctx.Providers.StorageProvider.SaveTOTPDeviceLastUsed(userSession.Username, device.ID, step, ctx.Clock.Now()); err != nil {
+			if err == storage.ErrTOTPStepAlreadyUsed {
+				markSecondFactorAttempt(ctx, userSession.Username, false, models.AuthenticationTypeTOTP)
+				handleAuthenticationUnauthorized(ctx, fmt.Errorf("Passcode of user %s has already been used", userSession.Username), mfaValidationFailedMessage)
+
+				return
+			}
+
+			handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to save last used TOTP step of user %s: %s", userSession.Username, err), mfaValidationFailedMessage)
+
+			return
+		}
+
+		markSecondFactorAttempt(ctx, userSession.Username, true, models.AuthenticationTypeTOTP)
+
 		err = ctx.Providers.SessionProvider.RegenerateSession(ctx.RequestCtx)
 
 		if err != nil {
@@ -44,6 +71,7 @@ func SecondFactorTOTPPost(totpVerifier TOTPVerifier) middlewares.RequestHandler
 		}
 
 		userSession.SetTwoFactor(ctx.Clock.Now())
+		userSession.SetSecondFactorClient(ctx.ClientMetadata())
 
 		err = ctx.SaveSession(userSession)
 		if err != nil {
@@ -54,7 +82,19 @@ func SecondFactorTOTPPost(totpVerifier TOTPVerifier) middlewares.RequestHandler
 		if userSession.OIDCWorkflowSession != nil {
 			handleOIDCWorkflowResponse(ctx)
 		} else {
-			Handle2FAResponse(ctx, requestBody.TargetURL)
+			Handle2FAResponse(ctx, requestBody.TargetURL, requestBody.State)
 		}
 	}
 }
+
+// verifyTOTPDevices checks the passcode against the secret of every device and returns the first device generating it
+// along with the time step of the passcode.
+func verifyTOTPDevices(totpVerifier TOTPVerifier, token string, devices []models.TOTPDevice) (device models.TOTPDevice, step uint64, isValid bool, err error) {
+	for _, device = range devices {
+		if step, isValid, err = totpVerifier.Verify(token, device.Secret); err != nil || isValid {
+			return device, step, isValid, err
+		}
+	}
+
+	return models.TOTPDevice{}, 0, false, nil
+}
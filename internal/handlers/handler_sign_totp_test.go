@@ -4,14 +4,19 @@ import (
 	"encoding/json"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"github.com/tstranex/u2f"
 
+	"github.com/authelia/authelia/internal/configuration/schema"
 	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/regulation"
 	"github.com/authelia/authelia/internal/session"
+	"github.com/authelia/authelia/internal/storage"
 )
 
 type HandlerSignTOTPSuite struct {
@@ -22,6 +27,8 @@ type HandlerSignTOTPSuite struct {
 
 func (s *HandlerSignTOTPSuite) SetupTest() {
 	s.mock = mocks.NewMockAutheliaCtx(s.T())
+	s.mock.Ctx.Clock = &s.mock.Clock
+
 	userSession := s.mock.Ctx.GetSession()
 	userSession.Username = testUsername
 	userSession.U2FChallenge = &u2f.Challenge{}
@@ -38,12 +45,20 @@ func (s *HandlerSignTOTPSuite) TestShouldRedirectUserToDefaultURL() {
 	verifier := NewMockTOTPVerifier(s.mock.Ctrl)
 
 	s.mock.StorageProviderMock.EXPECT().
-		LoadTOTPSecret(gomock.Any()).
-		Return("secret", nil)
+		LoadTOTPDevices(gomock.Any()).
+		Return([]models.TOTPDevice{{ID: "phone", Username: testUsername, Secret: "secret"}}, nil)
 
 	verifier.EXPECT().
 		Verify(gomock.Eq("abc"), gomock.Eq("secret")).
-		Return(true, nil)
+		Return(uint64(1000), true, nil)
+
+	s.mock.StorageProviderMock.EXPECT().
+		SaveTOTPDeviceLastUsed(gomock.Eq(testUsername), gomock.Eq("phone"), gomock.Eq(uint64(1000)), gomock.Eq(s.mock.Clock.Now())).
+		Return(nil)
+
+	s.mock.StorageProviderMock.EXPECT().
+		AppendAuthenticationLog(gomock.Any()).
+		Return(nil)
 
 	s.mock.Ctx.Configuration.DefaultRedirectionURL = testRedirectionURL
 
@@ -63,12 +78,20 @@ func (s *HandlerSignTOTPSuite) TestShouldNotReturnRedirectURL() {
 	verifier := NewMockTOTPVerifier(s.mock.Ctrl)
 
 	s.mock.StorageProviderMock.EXPECT().
-		LoadTOTPSecret(gomock.Any()).
-		Return("secret", nil)
+		LoadTOTPDevices(gomock.Any()).
+		Return([]models.TOTPDevice{{ID: "phone", Username: testUsername, Secret: "secret"}}, nil)
 
 	verifier.EXPECT().
 		Verify(gomock.Eq("abc"), gomock.Eq("secret")).
-		Return(true, nil)
+		Return(uint64(1000), true, nil)
+
+	s.mock.StorageProviderMock.EXPECT().
+		SaveTOTPDeviceLastUsed(gomock.Eq(testUsername), gomock.Eq("phone"), gomock.Eq(uint64(1000)), gomock.Eq(s.mock.Clock.Now())).
+		Return(nil)
+
+	s.mock.StorageProviderMock.EXPECT().
+		AppendAuthenticationLog(gomock.Any()).
+		Return(nil)
 
 	bodyBytes, err := json.Marshal(signTOTPRequestBody{
 		Token: "abc",
@@ -81,15 +104,25 @@ func (s *HandlerSignTOTPSuite) TestShouldNotReturnRedirectURL() {
 }
 
 func (s *HandlerSignTOTPSuite) TestShouldRedirectUserToSafeTargetURL() {
+	s.mock.Ctx.Configuration.Session.Domain = "mydomain.local"
+
 	verifier := NewMockTOTPVerifier(s.mock.Ctrl)
 
 	s.mock.StorageProviderMock.EXPECT().
-		LoadTOTPSecret(gomock.Any()).
-		Return("secret", nil)
+		LoadTOTPDevices(gomock.Any()).
+		Return([]models.TOTPDevice{{ID: "phone", Username: testUsername, Secret: "secret"}}, nil)
 
 	verifier.EXPECT().
 		Verify(gomock.Eq("abc"), gomock.Eq("secret")).
-		Return(true, nil)
+		Return(uint64(1000), true, nil)
+
+	s.mock.StorageProviderMock.EXPECT().
+		SaveTOTPDeviceLastUsed(gomock.Eq(testUsername), gomock.Eq("phone"), gomock.Eq(uint64(1000)), gomock.Eq(s.mock.Clock.Now())).
+		Return(nil)
+
+	s.mock.StorageProviderMock.EXPECT().
+		AppendAuthenticationLog(gomock.Any()).
+		Return(nil)
 
 	bodyBytes, err := json.Marshal(signTOTPRequestBody{
 		Token:     "abc",
@@ -108,12 +141,20 @@ func (s *HandlerSignTOTPSuite) TestShouldNotRedirectToUnsafeURL() {
 	verifier := NewMockTOTPVerifier(s.mock.Ctrl)
 
 	s.mock.StorageProviderMock.EXPECT().
-		LoadTOTPSecret(gomock.Any()).
-		Return("secret", nil)
+		LoadTOTPDevices(gomock.Any()).
+		Return([]models.TOTPDevice{{ID: "phone", Username: testUsername, Secret: "secret"}}, nil)
 
 	verifier.EXPECT().
 		Verify(gomock.Eq("abc"), gomock.Eq("secret")).
-		Return(true, nil)
+		Return(uint64(1000), true, nil)
+
+	s.mock.StorageProviderMock.EXPECT().
+		SaveTOTPDeviceLastUsed(gomock.Eq(testUsername), gomock.Eq("phone"), gomock.Eq(uint64(1000)), gomock.Eq(s.mock.Clock.Now())).
+		Return(nil)
+
+	s.mock.StorageProviderMock.EXPECT().
+		AppendAuthenticationLog(gomock.Any()).
+		Return(nil)
 
 	bodyBytes, err := json.Marshal(signTOTPRequestBody{
 		Token:     "abc",
@@ -130,12 +171,20 @@ func (s *HandlerSignTOTPSuite) TestShouldRegenerateSessionForPreventingSessionFi
 	verifier := NewMockTOTPVerifier(s.mock.Ctrl)
 
 	s.mock.StorageProviderMock.EXPECT().
-		LoadTOTPSecret(gomock.Any()).
-		Return("secret", nil)
+		LoadTOTPDevices(gomock.Any()).
+		Return([]models.TOTPDevice{{ID: "phone", Username: testUsername, Secret: "secret"}}, nil)
 
 	verifier.EXPECT().
 		Verify(gomock.Eq("abc"), gomock.Eq("secret")).
-		Return(true, nil)
+		Return(uint64(1000), true, nil)
+
+	s.mock.StorageProviderMock.EXPECT().
+		SaveTOTPDeviceLastUsed(gomock.Eq(testUsername), gomock.Eq("phone"), gomock.Eq(uint64(1000)), gomock.Eq(s.mock.Clock.Now())).
+		Return(nil)
+
+	s.mock.StorageProviderMock.EXPECT().
+		AppendAuthenticationLog(gomock.Any()).
+		Return(nil)
 
 	bodyBytes, err := json.Marshal(signTOTPRequestBody{
 		Token: "abc",
@@ -154,6 +203,159 @@ func (s *HandlerSignTOTPSuite) TestShouldRegenerateSessionForPreventingSessionFi
 		string(s.mock.Ctx.Request.Header.Cookie("authelia_session")))
 }
 
+func (s *HandlerSignTOTPSuite) TestShouldMarkFailedAttemptOnWrongPasscode() {
+	verifier := NewMockTOTPVerifier(s.mock.Ctrl)
+
+	s.mock.StorageProviderMock.EXPECT().
+		LoadTOTPDevices(gomock.Any()).
+		Return([]models.TOTPDevice{{ID: "phone", Username: testUsername, Secret: "secret"}}, nil)
+
+	verifier.EXPECT().
+		Verify(gomock.Eq("abc"), gomock.Eq("secret")).
+		Return(uint64(0), false, nil)
+
+	s.mock.StorageProviderMock.EXPECT().
+		AppendAuthenticationLog(gomock.Eq(models.AuthenticationAttempt{
+			Username:   testUsername,
+			Successful: false,
+			Time:       s.mock.Clock.Now(),
+			RemoteIP:   "0.0.0.0",
+			Type:       models.AuthenticationTypeTOTP,
+		})).
+		Return(nil)
+
+	bodyBytes, err := json.Marshal(signTOTPRequestBody{
+		Token: "abc",
+	})
+	s.Require().NoError(err)
+	s.mock.Ctx.Request.SetBody(bodyBytes)
+
+	SecondFactorTOTPPost(verifier)(s.mock.Ctx)
+	s.mock.Assert401KO(s.T(), mfaValidationFailedMessage)
+}
+
+func (s *HandlerSignTOTPSuite) TestShouldRejectUserBannedFromSecondFactor() {
+	verifier := NewMockTOTPVerifier(s.mock.Ctrl)
+
+	s.mock.Ctx.Providers.Regulator = regulation.NewRegulator(&schema.RegulationConfiguration{
+		MaxRetries: 3,
+		FindTime:   "2m",
+		BanTime:    "5m",
+		SecondFactor: &schema.RegulationSecondFactorConfiguration{
+			MaxRetries: 2,
+			FindTime:   "2m",
+			BanTime:    "5m",
+		},
+	}, s.mock.StorageProviderMock, &s.mock.Clock)
+
+	s.mock.StorageProviderMock.EXPECT().
+		LoadLatestAuthenticationLogs(gomock.Eq(testUsername), gomock.Any()).
+		Return([]models.AuthenticationAttempt{
+			{Username: testUsername, Successful: false, Time: s.mock.Clock.Now().Add(-10 * time.Second), Type: models.AuthenticationTypeTOTP},
+			{Username: testUsername, Successful: false, Time: s.mock.Clock.Now().Add(-20 * time.Second), Type: models.AuthenticationTypeTOTP},
+		}, nil)
+
+	bodyBytes, err := json.Marshal(signTOTPRequestBody{
+		Token: "abc",
+	})
+	s.Require().NoError(err)
+	s.mock.Ctx.Request.SetBody(bodyBytes)
+
+	SecondFactorTOTPPost(verifier)(s.mock.Ctx)
+	s.mock.Assert401KO(s.T(), userBannedMessage)
+}
+
+func (s *HandlerSignTOTPSuite) TestShouldRejectReplayedPasscode() {
+	verifier := NewMockTOTPVerifier(s.mock.Ctrl)
+
+	s.mock.StorageProviderMock.EXPECT().
+		LoadTOTPDevices(gomock.Any()).
+		Return([]models.TOTPDevice{{ID: "phone", Username: testUsername, Secret: "secret"}}, nil)
+
+	verifier.EXPECT().
+		Verify(gomock.Eq("abc"), gomock.Eq("secret")).
+		Return(uint64(1000), true, nil)
+
+	s.mock.StorageProviderMock.EXPECT().
+		SaveTOTPDeviceLastUsed(gomock.Eq(testUsername), gomock.Eq("phone"), gomock.Eq(uint64(1000)), gomock.Eq(s.mock.Clock.Now())).
+		Return(storage.ErrTOTPStepAlreadyUsed)
+
+	s.mock.StorageProviderMock.EXPECT().
+		AppendAuthenticationLog(gomock.Eq(models.AuthenticationAttempt{
+			Username:   testUsername,
+			Successful: false,
+			Time:       s.mock.Clock.Now(),
+			RemoteIP:   "0.0.0.0",
+			Type:       models.AuthenticationTypeTOTP,
+		})).
+		Return(nil)
+
+	bodyBytes, err := json.Marshal(signTOTPRequestBody{
+		Token: "abc",
+	})
+	s.Require().NoError(err)
+	s.mock.Ctx.Request.SetBody(bodyBytes)
+
+	SecondFactorTOTPPost(verifier)(s.mock.Ctx)
+	s.mock.Assert401KO(s.T(), mfaValidationFailedMessage)
+	s.Assert().Equal("Passcode of user john has already been used", s.mock.Hook.LastEntry().Message)
+}
+
+func (s *HandlerSignTOTPSuite) TestShouldAuthenticateWithAnyRegisteredDevice() {
+	verifier := NewMockTOTPVerifier(s.mock.Ctrl)
+
+	s.mock.StorageProviderMock.EXPECT().
+		LoadTOTPDevices(gomock.Eq(testUsername)).
+		Return([]models.TOTPDevice{
+			{ID: "phone", Username: testUsername, Secret: "secret"},
+			{ID: "tablet", Username: testUsername, Secret: "other"},
+		}, nil)
+
+	gomock.InOrder(
+		verifier.EXPECT().
+			Verify(gomock.Eq("abc"), gomock.Eq("secret")).
+			Return(uint64(0), false, nil),
+		verifier.EXPECT().
+			Verify(gomock.Eq("abc"), gomock.Eq("other")).
+			Return(uint64(1000), true, nil),
+	)
+
+	s.mock.StorageProviderMock.EXPECT().
+		SaveTOTPDeviceLastUsed(gomock.Eq(testUsername), gomock.Eq("tablet"), gomock.Eq(uint64(1000)), gomock.Eq(s.mock.Clock.Now())).
+		Return(nil)
+
+	s.mock.StorageProviderMock.EXPECT().
+		AppendAuthenticationLog(gomock.Any()).
+		Return(nil)
+
+	bodyBytes, err := json.Marshal(signTOTPRequestBody{
+		Token: "abc",
+	})
+	s.Require().NoError(err)
+	s.mock.Ctx.Request.SetBody(bodyBytes)
+
+	SecondFactorTOTPPost(verifier)(s.mock.Ctx)
+	s.mock.Assert200OK(s.T(), nil)
+}
+
+func (s *HandlerSignTOTPSuite) TestShouldFailWithoutRegisteredDevice() {
+	verifier := NewMockTOTPVerifier(s.mock.Ctrl)
+
+	s.mock.StorageProviderMock.EXPECT().
+		LoadTOTPDevices(gomock.Eq(testUsername)).
+		Return([]models.TOTPDevice{}, nil)
+
+	bodyBytes, err := json.Marshal(signTOTPRequestBody{
+		Token: "abc",
+	})
+	s.Require().NoError(err)
+	s.mock.Ctx.Request.SetBody(bodyBytes)
+
+	SecondFactorTOTPPost(verifier)(s.mock.Ctx)
+	s.mock.Assert401KO(s.T(), mfaValidationFailedMessage)
+	s.Assert().Equal("No TOTP device registered for user john", s.mock.Hook.LastEntry().Message)
+}
+
 func TestRunHandlerSignTOTPSuite(t *testing.T) {
 	suite.Run(t, new(HandlerSignTOTPSuite))
 }
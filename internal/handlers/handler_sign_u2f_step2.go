@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/models"
 )
 
 // SecondFactorU2FSignPost handler for completing a signing request.
@@ -18,6 +19,11 @@ func SecondFactorU2FSignPost(u2fVerifier U2FVerifier) middlewares.RequestHandler
 		}
 
 		userSession := ctx.GetSession()
+
+		if isSecondFactorBanned(ctx, userSession.Username) {
+			return
+		}
+
 		if userSession.U2FChallenge == nil {
 			handleAuthenticationUnauthorized(ctx, fmt.Errorf("U2F signing has not been initiated yet (no challenge)"), mfaValidationFailedMessage)
 			return
@@ -35,10 +41,14 @@ func SecondFactorU2FSignPost(u2fVerifier U2FVerifier) middlewares.RequestHandler
 			*userSession.U2FChallenge)
 
 		if err != nil {
+			markSecondFactorAttempt(ctx, userSession.Username, false, models.AuthenticationTypeU2F)
 			ctx.Error(err, mfaValidationFailedMessage)
+
 			return
 		}
 
+		markSecondFactorAttempt(ctx, userSession.Username, true, models.AuthenticationTypeU2F)
+
 		err = ctx.Providers.SessionProvider.RegenerateSession(ctx.RequestCtx)
 
 		if err != nil {
@@ -47,6 +57,7 @@ func SecondFactorU2FSignPost(u2fVerifier U2FVerifier) middlewares.RequestHandler
 		}
 
 		userSession.SetTwoFactor(ctx.Clock.Now())
+		userSession.SetSecondFactorClient(ctx.ClientMetadata())
 
 		err = ctx.SaveSession(userSession)
 		if err != nil {
@@ -57,7 +68,7 @@ func SecondFactorU2FSignPost(u2fVerifier U2FVerifier) middlewares.RequestHandler
 		if userSession.OIDCWorkflowSession != nil {
 			handleOIDCWorkflowResponse(ctx)
 		} else {
-			Handle2FAResponse(ctx, requestBody.TargetURL)
+			Handle2FAResponse(ctx, requestBody.TargetURL, requestBody.State)
 		}
 	}
 }
@@ -41,6 +41,10 @@ func (s *HandlerSignU2FStep2Suite) TestShouldRedirectUserToDefaultURL() {
 		Verify(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 		Return(nil)
 
+	s.mock.StorageProviderMock.EXPECT().
+		AppendAuthenticationLog(gomock.Any()).
+		Return(nil)
+
 	s.mock.Ctx.Configuration.DefaultRedirectionURL = testRedirectionURL
 
 	bodyBytes, err := json.Marshal(signU2FRequestBody{
@@ -62,6 +66,10 @@ func (s *HandlerSignU2FStep2Suite) TestShouldNotReturnRedirectURL() {
 		Verify(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 		Return(nil)
 
+	s.mock.StorageProviderMock.EXPECT().
+		AppendAuthenticationLog(gomock.Any()).
+		Return(nil)
+
 	bodyBytes, err := json.Marshal(signU2FRequestBody{
 		SignResponse: u2f.SignResponse{},
 	})
@@ -73,12 +81,18 @@ func (s *HandlerSignU2FStep2Suite) TestShouldNotReturnRedirectURL() {
 }
 
 func (s *HandlerSignU2FStep2Suite) TestShouldRedirectUserToSafeTargetURL() {
+	s.mock.Ctx.Configuration.Session.Domain = "mydomain.local"
+
 	u2fVerifier := NewMockU2FVerifier(s.mock.Ctrl)
 
 	u2fVerifier.EXPECT().
 		Verify(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 		Return(nil)
 
+	s.mock.StorageProviderMock.EXPECT().
+		AppendAuthenticationLog(gomock.Any()).
+		Return(nil)
+
 	bodyBytes, err := json.Marshal(signU2FRequestBody{
 		SignResponse: u2f.SignResponse{},
 		TargetURL:    "https://mydomain.local",
@@ -99,6 +113,10 @@ func (s *HandlerSignU2FStep2Suite) TestShouldNotRedirectToUnsafeURL() {
 		Verify(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 		Return(nil)
 
+	s.mock.StorageProviderMock.EXPECT().
+		AppendAuthenticationLog(gomock.Any()).
+		Return(nil)
+
 	bodyBytes, err := json.Marshal(signU2FRequestBody{
 		SignResponse: u2f.SignResponse{},
 		TargetURL:    "http://mydomain.local",
@@ -117,6 +135,10 @@ func (s *HandlerSignU2FStep2Suite) TestShouldRegenerateSessionForPreventingSessi
 		Verify(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 		Return(nil)
 
+	s.mock.StorageProviderMock.EXPECT().
+		AppendAuthenticationLog(gomock.Any()).
+		Return(nil)
+
 	bodyBytes, err := json.Marshal(signU2FRequestBody{
 		SignResponse: u2f.SignResponse{},
 	})
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/storage"
+	"github.com/authelia/authelia/internal/webauthn"
+)
+
+// SecondFactorWebauthnAssertionOptionsPost handler for initiating the authentication with one of the WebAuthn
+// credentials of the user as a second factor.
+func SecondFactorWebauthnAssertionOptionsPost(ctx *middlewares.AutheliaCtx) {
+	userSession := ctx.GetSession()
+
+	rp, err := newWebauthnRelyingParty(ctx)
+	if err != nil {
+		handleAuthenticationUnauthorized(ctx, err, mfaValidationFailedMessage)
+		return
+	}
+
+	credentials, err := ctx.Providers.StorageProvider.LoadWebauthnCredentials(userSession.Username)
+	if err != nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to load the WebAuthn credentials of user %s: %s", userSession.Username, err), mfaValidationFailedMessage)
+		return
+	}
+
+	allowed := make([][]byte, 0, len(credentials))
+	for _, credential := range credentials {
+		if credential.IsSecondFactor() && !bytes.Equal(credential.ID, userSession.FirstFactorWebauthnCredentialID) {
+			allowed = append(allowed, credential.ID)
+		}
+	}
+
+	if len(allowed) == 0 {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("No WebAuthn credential found for user %s", userSession.Username), mfaValidationFailedMessage)
+		return
+	}
+
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to create WebAuthn challenge: %s", err), mfaValidationFailedMessage)
+		return
+	}
+
+	userSession.WebauthnChallenge = challenge
+
+	if err = ctx.SaveSession(userSession); err != nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to save WebAuthn challenge in session: %s", err), mfaValidationFailedMessage)
+		return
+	}
+
+	if err = ctx.SetJSONBody(rp.NewSecondFactorRequestOptions(challenge, allowed)); err != nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to set WebAuthn request options in body: %s", err), mfaValidationFailedMessage)
+		return
+	}
+}
+
+// SecondFactorWebauthnPost handler for completing the authentication with a WebAuthn credential as a second factor.
+func SecondFactorWebauthnPost(ctx *middlewares.AutheliaCtx) {
+	var bodyJSON signWebauthnRequestBody
+
+	if err := ctx.ParseBody(&bodyJSON); err != nil {
+		ctx.Error(err, mfaValidationFailedMessage)
+		return
+	}
+
+	userSession := ctx.GetSession()
+
+	if isSecondFactorBanned(ctx, userSession.Username) {
+		return
+	}
+
+	if userSession.WebauthnChallenge == nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("WebAuthn authentication has not been initiated yet (no challenge)"), mfaValidationFailedMessage)
+		return
+	}
+
+	challenge := userSession.WebauthnChallenge
+	userSession.WebauthnChallenge = nil
+
+	if err := ctx.SaveSession(userSession); err != nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to clear WebAuthn challenge from session: %s", err), mfaValidationFailedMessage)
+		return
+	}
+
+	credential, err := ctx.Providers.StorageProvider.LoadWebauthnCredential(bodyJSON.Credential.RawID)
+	if err != nil {
+		if err == storage.ErrNoWebauthnCredential {
+			markSecondFactorAttempt(ctx, userSession.Username, false, models.AuthenticationTypeWebauthn)
+			handleAuthenticationUnauthorized(ctx, fmt.Errorf("WebAuthn credential is not registered"), mfaValidationFailedMessage)
+
+			return
+		}
+
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to load WebAuthn credential: %s", err), mfaValidationFailedMessage)
+
+		return
+	}
+
+	if credential.Username != userSession.Username {
+		markSecondFactorAttempt(ctx, userSession.Username, false, models.AuthenticationTypeWebauthn)
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("WebAuthn credential is not registered by user %s", userSession.Username), mfaValidationFailedMessage)
+
+		return
+	}
+
+	if !credential.IsSecondFactor() || bytes.Equal(credential.ID, userSession.FirstFactorWebauthnCredentialID) {
+		markSecondFactorAttempt(ctx, userSession.Username, false, models.AuthenticationTypeWebauthn)
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("WebAuthn credential of user %s can't be used as a second factor", userSession.Username), mfaValidationFailedMessage)
+
+		return
+	}
+
+	rp, err := newWebauthnRelyingParty(ctx)
+	if err != nil {
+		handleAuthenticationUnauthorized(ctx, err, mfaValidationFailedMessage)
+		return
+	}
+
+	signCount, err := rp.VerifyAssertion(challenge, webauthn.Credential{
+		ID:        credential.ID,
+		PublicKey: credential.PublicKey,
+		SignCount: credential.SignCount,
+	}, bodyJSON.Credential, false)
+	if err != nil {
+		markSecondFactorAttempt(ctx, userSession.Username, false, models.AuthenticationTypeWebauthn)
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to verify WebAuthn credential of user %s: %s", userSession.Username, err), mfaValidationFailedMessage)
+
+		return
+	}
+
+	markSecondFactorAttempt(ctx, userSession.Username, true, models.AuthenticationTypeWebauthn)
+
+	if err = ctx.Providers.StorageProvider.UpdateWebauthnCredentialSignCount(credential.ID, signCount); err != nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to update WebAuthn signature counter of user %s: %s", userSession.Username, err), mfaValidationFailedMessage)
+		return
+	}
+
+	if err = ctx.Providers.SessionProvider.RegenerateSession(ctx.RequestCtx); err != nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to regenerate session for user %s: %s", userSession.Username, err), mfaValidationFailedMessage)
+		return
+	}
+
+	userSession.SetTwoFactor(ctx.Clock.Now())
+	userSession.SetSecondFactorClient(ctx.ClientMetadata())
+
+	if err = ctx.SaveSession(userSession); err != nil {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to update authentication level with WebAuthn: %s", err), mfaValidationFailedMessage)
+		return
+	}
+
+	if userSession.OIDCWorkflowSession != nil {
+		handleOIDCWorkflowResponse(ctx)
+	} else {
+		Handle2FAResponse(ctx, bodyJSON.TargetURL, bodyJSON.State)
+	}
+}
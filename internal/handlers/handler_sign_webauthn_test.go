@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/regulation"
+	"github.com/authelia/authelia/internal/webauthn"
+	"github.com/authelia/authelia/pkg/autheliatest"
+)
+
+type SecondFactorWebauthnSuite struct {
+	suite.Suite
+
+	mock          *mocks.MockAutheliaCtx
+	storage       *autheliatest.MemoryStorageProvider
+	authenticator *mocks.WebauthnAuthenticator
+}
+
+func (s *SecondFactorWebauthnSuite) SetupTest() {
+	s.mock = mocks.NewMockAutheliaCtx(s.T())
+	s.mock.Ctx.Configuration.Session.Domain = "example.com"
+	s.mock.Ctx.Configuration.Server.ExternalURL = "https://login.example.com"
+
+	s.storage = autheliatest.NewMemoryStorageProvider()
+	s.mock.Ctx.Providers.StorageProvider = s.storage
+	s.mock.Ctx.Providers.Regulator = regulation.NewRegulator(nil, s.storage, &s.mock.Clock)
+
+	s.authenticator = mocks.NewWebauthnAuthenticator([]byte(testUsername))
+	s.authenticator.UserVerified = false
+
+	s.Require().NoError(s.storage.SaveWebauthnCredential(models.WebauthnCredential{
+		ID:          s.authenticator.CredentialID,
+		Username:    testUsername,
+		Type:        models.WebauthnCredentialTypeSecondFactor,
+		Description: "YubiKey",
+		PublicKey:   s.authenticator.PublicKey(),
+	}))
+
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder(testUsername).OneFactor(s.mock.Clock.Now()).Build())
+}
+
+func (s *SecondFactorWebauthnSuite) TearDownTest() {
+	s.mock.Close()
+}
+
+func (s *SecondFactorWebauthnSuite) startAssertion() []byte {
+	SecondFactorWebauthnAssertionOptionsPost(s.mock.Ctx)
+	s.Require().Equal(200, s.mock.Ctx.Response.StatusCode())
+
+	var options webauthn.RequestOptions
+
+	s.mock.GetResponseData(s.T(), &options)
+	s.Require().Equal([]webauthn.CredentialDescriptor{{Type: "public-key", ID: s.authenticator.CredentialID}}, options.AllowCredentials)
+
+	challenge := s.mock.Ctx.GetSession().WebauthnChallenge
+	s.Require().Equal([]byte(options.Challenge), challenge)
+
+	s.mock.Ctx.Response.Reset()
+
+	return challenge
+}
+
+func (s *SecondFactorWebauthnSuite) setBody(assertion webauthn.CredentialAssertion) {
+	body, err := json.Marshal(signWebauthnRequestBody{Credential: assertion})
+	s.Require().NoError(err)
+	s.mock.Ctx.Request.SetBody(body)
+}
+
+func (s *SecondFactorWebauthnSuite) TestShouldAuthenticateWithSecondFactor() {
+	challenge := s.startAssertion()
+	s.setBody(s.authenticator.Get("example.com", "https://login.example.com", challenge))
+
+	SecondFactorWebauthnPost(s.mock.Ctx)
+
+	s.Assert().Equal([]byte("{\"status\":\"OK\"}"), s.mock.Ctx.Response.Body())
+
+	userSession := s.mock.Ctx.GetSession()
+	s.Assert().Equal(authentication.TwoFactor, userSession.AuthenticationLevel)
+	s.Assert().NotNil(userSession.SecondFactorClient)
+	s.Assert().Nil(userSession.WebauthnChallenge)
+
+	credential, err := s.storage.LoadWebauthnCredential(s.authenticator.CredentialID)
+	s.Require().NoError(err)
+	s.Assert().Equal(uint32(1), credential.SignCount)
+
+	attempts, err := s.storage.LoadLatestAuthenticationLogs(testUsername, s.mock.Clock.Now().Add(-1))
+	s.Require().NoError(err)
+	s.Require().Len(attempts, 1)
+	s.Assert().True(attempts[0].Successful)
+	s.Assert().Equal(models.AuthenticationTypeWebauthn, attempts[0].Type)
+}
+
+func (s *SecondFactorWebauthnSuite) TestShouldFailWithCredentialOfAnotherUser() {
+	other := mocks.NewWebauthnAuthenticator([]byte("harry"))
+
+	s.Require().NoError(s.storage.SaveWebauthnCredential(models.WebauthnCredential{
+		ID:        other.CredentialID,
+		Username:  "harry",
+		Type:      models.WebauthnCredentialTypeSecondFactor,
+		PublicKey: other.PublicKey(),
+	}))
+
+	challenge := s.startAssertion()
+	s.setBody(other.Get("example.com", "https://login.example.com", challenge))
+
+	SecondFactorWebauthnPost(s.mock.Ctx)
+
+	s.Assert().Equal(401, s.mock.Ctx.Response.StatusCode())
+	s.Assert().Equal("WebAuthn credential is not registered by user john", s.mock.Hook.LastEntry().Message)
+	s.Assert().Equal(authentication.OneFactor, s.mock.Ctx.GetSession().AuthenticationLevel)
+
+	attempts, err := s.storage.LoadLatestAuthenticationLogs(testUsername, s.mock.Clock.Now().Add(-1))
+	s.Require().NoError(err)
+	s.Require().Len(attempts, 1)
+	s.Assert().False(attempts[0].Successful)
+}
+
+func (s *SecondFactorWebauthnSuite) TestShouldFailWithoutChallenge() {
+	s.setBody(s.authenticator.Get("example.com", "https://login.example.com", []byte("challenge")))
+
+	SecondFactorWebauthnPost(s.mock.Ctx)
+
+	s.Assert().Equal(401, s.mock.Ctx.Response.StatusCode())
+	s.Assert().Equal("WebAuthn authentication has not been initiated yet (no challenge)", s.mock.Hook.LastEntry().Message)
+}
+
+func (s *SecondFactorWebauthnSuite) TestShouldFailOptionsWithoutCredential() {
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder("harry").OneFactor(s.mock.Clock.Now()).Build())
+
+	SecondFactorWebauthnAssertionOptionsPost(s.mock.Ctx)
+
+	s.Assert().Equal(401, s.mock.Ctx.Response.StatusCode())
+	s.Assert().Equal("No WebAuthn credential found for user harry", s.mock.Hook.LastEntry().Message)
+	s.Assert().Nil(s.mock.Ctx.GetSession().WebauthnChallenge)
+}
+
+func (s *SecondFactorWebauthnSuite) TestShouldCheckOriginOfExternalURLRatherThanForwardedHeaders() {
+	s.mock.Ctx.Request.Header.Set("X-Forwarded-Proto", "https")
+	s.mock.Ctx.Request.Header.Set("X-Forwarded-Host", "evil.example.com")
+
+	challenge := s.startAssertion()
+	s.setBody(s.authenticator.Get("example.com", "https://evil.example.com", challenge))
+
+	SecondFactorWebauthnPost(s.mock.Ctx)
+
+	s.Assert().Equal(401, s.mock.Ctx.Response.StatusCode())
+	s.Assert().Equal("Unable to verify WebAuthn credential of user john: origin mismatch: 'https://evil.example.com'", s.mock.Hook.LastEntry().Message)
+	s.Assert().Equal(authentication.OneFactor, s.mock.Ctx.GetSession().AuthenticationLevel)
+}
+
+func (s *SecondFactorWebauthnSuite) TestShouldFailOptionsWithoutExternalURL() {
+	s.mock.Ctx.Configuration.Server.ExternalURL = ""
+
+	SecondFactorWebauthnAssertionOptionsPost(s.mock.Ctx)
+
+	s.Assert().Equal(errMissingExternalURL.Error(), s.mock.Hook.LastEntry().Message)
+	s.Assert().Nil(s.mock.Ctx.GetSession().WebauthnChallenge)
+}
+
+func TestRunSecondFactorWebauthnSuite(t *testing.T) {
+	suite.Run(t, new(SecondFactorWebauthnSuite))
+}
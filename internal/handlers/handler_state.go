@@ -2,18 +2,23 @@ package handlers
 
 import (
 	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/session"
 )
 
-// StateGet is the handler serving the user state.
-func StateGet(ctx *middlewares.AutheliaCtx) {
-	userSession := ctx.GetSession()
-	stateResponse := StateResponse{
+func newStateResponse(ctx *middlewares.AutheliaCtx, userSession *session.UserSession) StateResponse {
+	return StateResponse{
 		Username:              userSession.Username,
 		AuthenticationLevel:   userSession.AuthenticationLevel,
-		DefaultRedirectionURL: ctx.Configuration.DefaultRedirectionURL,
+		StepUpRequired:        userSession.IsStepUpPending(),
+		DefaultRedirectionURL: defaultRedirectionURL(ctx, userSession.Username, userSession.Groups),
 	}
+}
+
+// StateGet is the handler serving the user state.
+func StateGet(ctx *middlewares.AutheliaCtx) {
+	userSession := ctx.GetSession()
 
-	err := ctx.SetJSONBody(stateResponse)
+	err := ctx.SetJSONBody(newStateResponse(ctx, &userSession))
 	if err != nil {
 		ctx.Logger.Errorf("Unable to set state response in body: %s", err)
 	}
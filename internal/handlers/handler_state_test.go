@@ -10,6 +10,7 @@ import (
 
 	"github.com/authelia/authelia/internal/authentication"
 	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/pkg/autheliatest"
 )
 
 type StateGetSuite struct {
@@ -27,10 +28,7 @@ func (s *StateGetSuite) TearDownTest() {
 }
 
 func (s *StateGetSuite) TestShouldReturnUsernameFromSession() {
-	userSession := s.mock.Ctx.GetSession()
-	userSession.Username = "username"
-	err := s.mock.Ctx.SaveSession(userSession)
-	require.NoError(s.T(), err)
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder("username").Build())
 
 	StateGet(s.mock.Ctx)
 
@@ -49,7 +47,7 @@ func (s *StateGetSuite) TestShouldReturnUsernameFromSession() {
 	}
 	actualBody := Response{}
 
-	err = json.Unmarshal(s.mock.Ctx.Response.Body(), &actualBody)
+	err := json.Unmarshal(s.mock.Ctx.Response.Body(), &actualBody)
 	require.NoError(s.T(), err)
 	assert.Equal(s.T(), 200, s.mock.Ctx.Response.StatusCode())
 	assert.Equal(s.T(), []byte("application/json"), s.mock.Ctx.Response.Header.ContentType())
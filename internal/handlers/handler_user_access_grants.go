@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/storage"
+)
+
+// UserAccessGrantsPost handler creating an access grant to a resource for a guest. The users can only grant access to
+// the resources they're authorized to access themselves and the rule matching the guest must permit the grants.
+func UserAccessGrantsPost(ctx *middlewares.AutheliaCtx) {
+	userSession := ctx.GetSession()
+
+	var requestBody accessGrantRequestBody
+
+	if err := ctx.ParseBody(&requestBody); err != nil {
+		ctx.Error(fmt.Errorf("Unable to parse the access grant requested by %s: %s", userSession.Username, err), operationFailedMessage)
+		return
+	}
+
+	targetURL, err := parseAccessGrantTargetURL(ctx, requestBody.TargetURL)
+	if err != nil {
+		ctx.Error(err, operationFailedMessage)
+		return
+	}
+
+	if isTargetURLAuthorized(ctx.Providers.Authorizer, *targetURL, userSession.Username, userSession.Groups,
+		ctx.RemoteIP(), []byte(fasthttp.MethodGet), userSession.AuthenticationLevel) != Authorized {
+		ctx.Logger.Infof("User %s is not authorized to grant access to %s", userSession.Username, targetURL.String())
+		ctx.ReplyForbidden()
+
+		return
+	}
+
+	createAccessGrant(ctx, userSession.Username, targetURL, requestBody)
+}
+
+// UserAccessGrantsGet handler returning the unexpired access grants created by the user.
+func UserAccessGrantsGet(ctx *middlewares.AutheliaCtx) {
+	userSession := ctx.GetSession()
+
+	grants, err := ctx.Providers.StorageProvider.LoadUserAccessGrants(userSession.Username, ctx.Clock.Now())
+	if err != nil {
+		ctx.Error(fmt.Errorf("Unable to load the access grants of user %s: %s", userSession.Username, err), operationFailedMessage)
+		return
+	}
+
+	if err = ctx.SetJSONBody(newAccessGrants(grants)); err != nil {
+		ctx.Logger.Errorf("Unable to set the access grants response in body: %s", err)
+	}
+}
+
+// UserAccessGrantDelete handler revoking one of the access grants created by the user.
+func UserAccessGrantDelete(ctx *middlewares.AutheliaCtx) {
+	userSession := ctx.GetSession()
+
+	grant, ok := loadAccessGrant(ctx)
+	if !ok {
+		return
+	}
+
+	if grant.Username != userSession.Username {
+		ctx.Logger.Infof("User %s is not allowed to revoke the access grant %s of %s", userSession.Username, grant.ID, grant.Username)
+		ctx.ReplyForbidden()
+
+		return
+	}
+
+	if revokeAccessGrant(ctx, grant, userSession.Username) {
+		ctx.ReplyOK()
+	}
+}
+
+// loadAccessGrant returns the access grant whose ID is in the path. The response is written when it's not found.
+func loadAccessGrant(ctx *middlewares.AutheliaCtx) (grant *models.AccessGrant, ok bool) {
+	id, _ := ctx.UserValue("id").(string)
+
+	if id == "" {
+		ctx.Error(fmt.Errorf("No access grant ID provided"), operationFailedMessage)
+		return nil, false
+	}
+
+	grant, err := ctx.Providers.StorageProvider.LoadAccessGrant(id)
+
+	switch {
+	case errors.Is(err, storage.ErrNoAccessGrant):
+		ctx.Error(fmt.Errorf("Access grant %s does not exist", id), operationFailedMessage)
+		return nil, false
+	case err != nil:
+		ctx.Error(fmt.Errorf("Unable to load the access grant %s: %s", id, err), operationFailedMessage)
+		return nil, false
+	}
+
+	return grant, true
+}
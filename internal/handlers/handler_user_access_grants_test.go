@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/events"
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/pkg/autheliatest"
+)
+
+type UserAccessGrantsSuite struct {
+	suite.Suite
+
+	mock      *mocks.MockAutheliaCtx
+	storage   *autheliatest.MemoryStorageProvider
+	published []events.Event
+}
+
+func newAccessGrantsConfiguration() schema.Configuration {
+	configuration := schema.Configuration{}
+	configuration.JWTSecret = "a_secret"
+	configuration.Session.Name = "authelia_session"
+	configuration.Session.Domain = "example.com"
+	configuration.AccessGrants = &schema.AccessGrantsConfiguration{
+		Parameter:       "authelia_grant",
+		DefaultLifespan: "1h",
+		MaxLifespan:     "1d",
+	}
+	configuration.AccessControl.DefaultPolicy = "deny"
+	configuration.AccessControl.Rules = []schema.ACLRule{{
+		Domains:      []string{"share.example.com"},
+		Policy:       "one_factor",
+		AccessGrants: true,
+	}, {
+		Domains: []string{"private.example.com"},
+		Policy:  "one_factor",
+	}, {
+		Domains:      []string{"secure.example.com"},
+		Policy:       "two_factor",
+		AccessGrants: true,
+	}}
+
+	return configuration
+}
+
+func (s *UserAccessGrantsSuite) SetupTest() {
+	s.mock = mocks.NewMockAutheliaCtxWithConfiguration(s.T(), newAccessGrantsConfiguration())
+	s.mock.Clock.Set(time.Unix(1600000000, 0))
+	s.mock.Ctx.Clock = &s.mock.Clock
+
+	s.storage = autheliatest.NewMemoryStorageProvider()
+	s.mock.Ctx.Providers.StorageProvider = s.storage
+
+	s.published = nil
+	s.mock.Ctx.Providers.Events.Subscribe(func(event events.Event) {
+		s.published = append(s.published, event)
+	})
+
+	s.loginAs("john")
+}
+
+func (s *UserAccessGrantsSuite) TearDownTest() {
+	s.mock.Close()
+}
+
+func (s *UserAccessGrantsSuite) loginAs(username string) {
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder(username).TwoFactor(s.mock.Clock.Now()).Build())
+	s.mock.Ctx.Response.Reset()
+}
+
+// create creates an access grant as the logged in user and returns it.
+func (s *UserAccessGrantsSuite) create(body string) AccessGrant {
+	s.mock.Ctx.Request.SetBodyString(body)
+
+	UserAccessGrantsPost(s.mock.Ctx)
+	s.Require().Equal(200, s.mock.Ctx.Response.StatusCode())
+
+	var grant AccessGrant
+
+	s.mock.GetResponseData(s.T(), &grant)
+
+	return grant
+}
+
+// verify verifies the request of a guest without any session to the given URL and returns the status code.
+func (s *UserAccessGrantsSuite) verify(targetURL string) int {
+	mock := mocks.NewMockAutheliaCtxWithConfiguration(s.T(), newAccessGrantsConfiguration())
+	defer mock.Close()
+
+	mock.Clock.Set(s.mock.Clock.Now())
+	mock.Ctx.Clock = &mock.Clock
+	mock.Ctx.Providers.StorageProvider = s.storage
+	mock.Ctx.Request.Header.Set("X-Original-URL", targetURL)
+
+	VerifyGet(verifyGetCfg)(mock.Ctx)
+
+	s.Assert().Empty(mock.Ctx.Response.Header.Peek(remoteUserHeader))
+
+	return mock.Ctx.Response.StatusCode()
+}
+
+func (s *UserAccessGrantsSuite) TestShouldCreateAccessGrantGivingGuestAccess() {
+	grant := s.create(`{"target_url":"https://share.example.com/files/?view=list","description":"Contractor"}`)
+
+	s.Assert().NotEmpty(grant.ID)
+	s.Assert().Equal("john", grant.CreatedBy)
+	s.Assert().Equal("https://share.example.com/files/", grant.TargetURL)
+	s.Assert().Equal("Contractor", grant.Description)
+	s.Assert().Equal(int64(1600000000+3600), grant.ExpiresAt)
+
+	link, err := url.Parse(grant.URL)
+	s.Require().NoError(err)
+	s.Require().NotEmpty(link.Query().Get("authelia_grant"))
+
+	s.Require().Len(s.published, 1)
+	s.Assert().Equal(events.AccessGrantCreated, s.published[0].Type)
+	s.Assert().Equal(grant.ID, s.published[0].Details[events.DetailGrantID])
+
+	s.Assert().Equal(200, s.verify(grant.URL))
+	s.Assert().Equal(200, s.verify("https://share.example.com/files/report.pdf?authelia_grant="+link.Query().Get("authelia_grant")))
+	s.Assert().Equal(401, s.verify("https://share.example.com/other?authelia_grant="+link.Query().Get("authelia_grant")))
+	s.Assert().Equal(401, s.verify("https://share.example.com/files/"))
+
+	s.mock.Clock.Set(s.mock.Clock.Now().Add(time.Hour))
+	s.Assert().Equal(401, s.verify(grant.URL))
+}
+
+func (s *UserAccessGrantsSuite) TestShouldRejectRevokedAccessGrant() {
+	grant := s.create(`{"target_url":"https://share.example.com/","lifespan":"1d"}`)
+	s.Assert().Equal(int64(1600000000+86400), grant.ExpiresAt)
+
+	s.mock.Ctx.Response.Reset()
+	s.mock.Ctx.SetUserValue("id", grant.ID)
+
+	UserAccessGrantDelete(s.mock.Ctx)
+	s.mock.Assert200OK(s.T(), nil)
+
+	s.Require().Len(s.published, 2)
+	s.Assert().Equal(events.AccessGrantRevoked, s.published[1].Type)
+	s.Assert().Equal(401, s.verify(grant.URL))
+}
+
+func (s *UserAccessGrantsSuite) TestShouldListAccessGrantsOfUser() {
+	grant := s.create(`{"target_url":"https://share.example.com/"}`)
+
+	s.loginAs("bob")
+	s.create(`{"target_url":"https://share.example.com/bob"}`)
+
+	s.loginAs("john")
+
+	UserAccessGrantsGet(s.mock.Ctx)
+
+	var grants []AccessGrant
+
+	s.mock.GetResponseData(s.T(), &grants)
+	s.Require().Len(grants, 1)
+	s.Assert().Equal(grant.ID, grants[0].ID)
+	s.Assert().Empty(grants[0].URL)
+}
+
+func (s *UserAccessGrantsSuite) TestShouldNotRevokeAccessGrantOfAnotherUser() {
+	grant := s.create(`{"target_url":"https://share.example.com/"}`)
+
+	s.loginAs("bob")
+	s.mock.Ctx.SetUserValue("id", grant.ID)
+
+	UserAccessGrantDelete(s.mock.Ctx)
+
+	s.Assert().Equal(403, s.mock.Ctx.Response.StatusCode())
+	s.Assert().Equal(200, s.verify(grant.URL))
+}
+
+func (s *UserAccessGrantsSuite) TestShouldForbidAccessGrantNotPermittedByRule() {
+	s.mock.Ctx.Request.SetBodyString(`{"target_url":"https://private.example.com/"}`)
+
+	UserAccessGrantsPost(s.mock.Ctx)
+
+	s.Assert().Equal(403, s.mock.Ctx.Response.StatusCode())
+	s.Assert().Empty(s.published)
+}
+
+func (s *UserAccessGrantsSuite) TestShouldForbidAccessGrantToResourceUnauthorizedToUser() {
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder("john").OneFactor(s.mock.Clock.Now()).Build())
+	s.mock.Ctx.Request.SetBodyString(`{"target_url":"https://secure.example.com/"}`)
+
+	UserAccessGrantsPost(s.mock.Ctx)
+
+	s.Assert().Equal(403, s.mock.Ctx.Response.StatusCode())
+
+	s.loginAs("john")
+	grant := s.create(`{"target_url":"https://secure.example.com/"}`)
+
+	s.Assert().Equal("https://secure.example.com/", grant.TargetURL)
+}
+
+func (s *UserAccessGrantsSuite) TestShouldFailAccessGrantWithInvalidLifespan() {
+	for _, lifespan := range []string{"2d", "0s", "abc"} {
+		s.mock.Ctx.Response.Reset()
+		s.mock.Ctx.Request.SetBodyString(`{"target_url":"https://share.example.com/","lifespan":"` + lifespan + `"}`)
+
+		UserAccessGrantsPost(s.mock.Ctx)
+
+		s.mock.Assert200KO(s.T(), operationFailedMessage)
+	}
+
+	s.Assert().Empty(s.published)
+}
+
+func (s *UserAccessGrantsSuite) TestShouldFailAccessGrantOutsideProtectedDomain() {
+	for _, targetURL := range []string{"https://share.example.org/", "http://share.example.com/", "share"} {
+		s.mock.Ctx.Response.Reset()
+		s.mock.Ctx.Request.SetBodyString(`{"target_url":"` + targetURL + `"}`)
+
+		UserAccessGrantsPost(s.mock.Ctx)
+
+		s.mock.Assert200KO(s.T(), operationFailedMessage)
+	}
+}
+
+func TestRunUserAccessGrantsSuite(t *testing.T) {
+	suite.Run(t, new(UserAccessGrantsSuite))
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/authelia/authelia/internal/middlewares"
+)
+
+// UserAnnouncementAcknowledgmentPost handler saving the acknowledgment of the announcement by the user so it's not
+// displayed to the user anymore.
+func UserAnnouncementAcknowledgmentPost(ctx *middlewares.AutheliaCtx) {
+	var bodyJSON announcementAcknowledgmentRequestBody
+
+	if err := ctx.ParseBody(&bodyJSON); err != nil {
+		ctx.Error(err, operationFailedMessage)
+		return
+	}
+
+	userSession := ctx.GetSession()
+
+	if bodyJSON.ID != ctx.Configuration.Banner.Announcement.ID {
+		ctx.Error(fmt.Errorf("Announcement %s acknowledged by user %s isn't the current announcement", bodyJSON.ID, userSession.Username), operationFailedMessage)
+		return
+	}
+
+	if err := ctx.Providers.StorageProvider.SaveAnnouncementAcknowledgment(userSession.Username, bodyJSON.ID, ctx.Clock.Now()); err != nil {
+		ctx.Error(fmt.Errorf("Unable to save the acknowledgment of announcement %s by user %s: %s", bodyJSON.ID, userSession.Username, err), operationFailedMessage)
+		return
+	}
+
+	ctx.Logger.Debugf("Announcement %s has been acknowledged by user %s", bodyJSON.ID, userSession.Username)
+
+	ctx.ReplyOK()
+}
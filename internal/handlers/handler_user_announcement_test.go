@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/pkg/autheliatest"
+)
+
+type UserAnnouncementSuite struct {
+	suite.Suite
+
+	mock    *mocks.MockAutheliaCtx
+	storage *autheliatest.MemoryStorageProvider
+}
+
+func (s *UserAnnouncementSuite) SetupTest() {
+	s.mock = mocks.NewMockAutheliaCtx(s.T())
+	s.mock.Clock.Set(time.Unix(1600000000, 0))
+	s.mock.Ctx.Clock = &s.mock.Clock
+	s.mock.Ctx.Configuration.TOTP = &schema.TOTPConfiguration{Period: schema.DefaultTOTPConfiguration.Period}
+	s.mock.Ctx.Configuration.Banner = schema.BannerConfiguration{
+		Login: "Authorized use only.",
+		Announcement: schema.BannerAnnouncementConfiguration{
+			ID:      "maintenance",
+			Message: "The portal will be unavailable on Sunday.",
+		},
+	}
+
+	s.storage = autheliatest.NewMemoryStorageProvider()
+	s.mock.Ctx.Providers.StorageProvider = s.storage
+
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder(testUsername).OneFactor(s.mock.Clock.Now()).Build())
+}
+
+func (s *UserAnnouncementSuite) TearDownTest() {
+	s.mock.Close()
+}
+
+func (s *UserAnnouncementSuite) TestShouldServeAnnouncementUntilAcknowledged() {
+	ConfigurationGet(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), ConfigurationBody{
+		AvailableMethods:    []string{"totp", "u2f", "webauthn"},
+		SecondFactorEnabled: true,
+		TOTPPeriod:          schema.DefaultTOTPConfiguration.Period,
+		LoginBanner:         "Authorized use only.",
+		Announcement: &AnnouncementBody{
+			ID:      "maintenance",
+			Message: "The portal will be unavailable on Sunday.",
+		},
+	})
+
+	s.mock.Ctx.Response.Reset()
+	s.mock.Ctx.Request.SetBodyString(`{"id":"maintenance"}`)
+
+	UserAnnouncementAcknowledgmentPost(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), nil)
+
+	acknowledgedAt, err := s.storage.LoadAnnouncementAcknowledgment(testUsername, "maintenance")
+	s.Require().NoError(err)
+	s.Assert().Equal(s.mock.Clock.Now(), acknowledgedAt)
+
+	s.mock.Ctx.Response.Reset()
+
+	ConfigurationGet(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), ConfigurationBody{
+		AvailableMethods:    []string{"totp", "u2f", "webauthn"},
+		SecondFactorEnabled: true,
+		TOTPPeriod:          schema.DefaultTOTPConfiguration.Period,
+		LoginBanner:         "Authorized use only.",
+		Announcement: &AnnouncementBody{
+			ID:           "maintenance",
+			Message:      "The portal will be unavailable on Sunday.",
+			Acknowledged: true,
+		},
+	})
+}
+
+func (s *UserAnnouncementSuite) TestShouldNotAcknowledgeAnotherAnnouncement() {
+	s.mock.Ctx.Request.SetBodyString(`{"id":"outdated"}`)
+
+	UserAnnouncementAcknowledgmentPost(s.mock.Ctx)
+
+	s.mock.Assert200KO(s.T(), "Operation failed.")
+	s.Assert().Equal("Announcement outdated acknowledged by user john isn't the current announcement", s.mock.Hook.LastEntry().Message)
+
+	acknowledgedAt, err := s.storage.LoadAnnouncementAcknowledgment(testUsername, "outdated")
+	s.Require().NoError(err)
+	s.Assert().True(acknowledgedAt.IsZero())
+}
+
+func TestRunUserAnnouncementSuite(t *testing.T) {
+	suite.Run(t, new(UserAnnouncementSuite))
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/authelia/authelia/internal/events"
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/privacy"
+)
+
+// UserDataGet handler exporting the personal data stored about the logged in user.
+func UserDataGet(ctx *middlewares.AutheliaCtx) {
+	userSession := ctx.GetSession()
+
+	exportUserData(ctx, userSession.Username, nil)
+}
+
+// AdminUserDataGet handler exporting the personal data stored about a user, e.g. when the user requests it from the
+// administrators. It requires the users.read admin permission.
+func AdminUserDataGet(ctx *middlewares.AutheliaCtx) {
+	username, ok := adminUsername(ctx)
+	if !ok {
+		return
+	}
+
+	exportUserData(ctx, username, map[string]string{events.DetailActor: ctx.AdminCaller()})
+}
+
+func exportUserData(ctx *middlewares.AutheliaCtx, username string, details map[string]string) {
+	export, err := privacy.NewExport(ctx.Providers.StorageProvider, username, ctx.Clock.Now())
+	if err != nil {
+		ctx.Error(fmt.Errorf("Unable to export the personal data of user %s: %s", username, err), operationFailedMessage)
+		return
+	}
+
+	ctx.PublishEvent(events.UserDataExported, username, details)
+
+	if err = ctx.SetJSONBody(export); err != nil {
+		ctx.Logger.Errorf("Unable to set the personal data export response in body: %s", err)
+	}
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/authelia/authelia/internal/events"
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/privacy"
+	"github.com/authelia/authelia/pkg/autheliatest"
+)
+
+type UserDataSuite struct {
+	suite.Suite
+
+	mock      *mocks.MockAutheliaCtx
+	published []events.Event
+}
+
+func (s *UserDataSuite) SetupTest() {
+	s.mock = mocks.NewMockAutheliaCtx(s.T())
+	s.mock.Clock.Set(time.Unix(1600000000, 0))
+	s.mock.Ctx.Clock = &s.mock.Clock
+	s.mock.Ctx.Configuration.Server.AdminGroup = "admins"
+
+	storage := autheliatest.NewMemoryStorageProvider()
+	s.Require().NoError(storage.SavePreferred2FAMethod(testUsername, "totp"))
+	s.Require().NoError(storage.SaveTOTPDevice(models.TOTPDevice{ID: "phone", Username: "harry", Description: "Phone"}))
+	s.mock.Ctx.Providers.StorageProvider = storage
+
+	s.published = nil
+	s.mock.Ctx.Providers.Events.Subscribe(func(event events.Event) {
+		s.published = append(s.published, event)
+	}, events.UserDataExported)
+
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder(testUsername).WithGroups("admins").TwoFactor(s.mock.Clock.Now()).Build())
+}
+
+func (s *UserDataSuite) TearDownTest() {
+	s.mock.Close()
+}
+
+func (s *UserDataSuite) getExport() privacy.Export {
+	var response struct {
+		Status string         `json:"status"`
+		Data   privacy.Export `json:"data"`
+	}
+
+	s.Require().NoError(json.Unmarshal(s.mock.Ctx.Response.Body(), &response))
+	s.Require().Equal("OK", response.Status)
+
+	return response.Data
+}
+
+func (s *UserDataSuite) TestShouldExportDataOfLoggedInUser() {
+	UserDataGet(s.mock.Ctx)
+
+	export := s.getExport()
+
+	s.Assert().Equal(testUsername, export.Username)
+	s.Assert().Equal("totp", export.Preferences.SecondFactorMethod)
+	s.Assert().Empty(export.TOTPDevices)
+
+	s.Require().Len(s.published, 1)
+	s.Assert().Equal(testUsername, s.published[0].Username)
+	s.Assert().Nil(s.published[0].Details)
+}
+
+func (s *UserDataSuite) TestShouldExportDataOfUserForAdmin() {
+	s.mock.Ctx.SetUserValue("username", "harry")
+
+	middlewares.RequireAdminPermission(models.AdminPermissionUsersRead)(AdminUserDataGet)(s.mock.Ctx)
+
+	export := s.getExport()
+
+	s.Assert().Equal("harry", export.Username)
+	s.Require().Len(export.TOTPDevices, 1)
+	s.Assert().Equal("Phone", export.TOTPDevices[0].Description)
+
+	s.Require().Len(s.published, 1)
+	s.Assert().Equal("harry", s.published[0].Username)
+	s.Assert().Equal(map[string]string{events.DetailActor: testUsername}, s.published[0].Details)
+}
+
+func (s *UserDataSuite) TestShouldForbidUserNotAdmin() {
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder(testUsername).WithGroups("dev").TwoFactor(s.mock.Clock.Now()).Build())
+	s.mock.Ctx.SetUserValue("username", "harry")
+
+	middlewares.RequireAdminPermission(models.AdminPermissionUsersRead)(AdminUserDataGet)(s.mock.Ctx)
+
+	s.Assert().Equal(403, s.mock.Ctx.Response.StatusCode())
+	s.Assert().Empty(s.published)
+}
+
+func TestRunUserDataSuite(t *testing.T) {
+	s := new(UserDataSuite)
+	suite.Run(t, s)
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/authelia/authelia/internal/middlewares"
+)
+
+// parsePositiveQueryArg parses a positive integer query argument, the default value is returned when it's missing.
+func parsePositiveQueryArg(ctx *middlewares.AutheliaCtx, name string, defaultValue int) (int, error) {
+	raw := ctx.QueryArgs().Peek(name)
+	if len(raw) == 0 {
+		return defaultValue, nil
+	}
+
+	value, err := strconv.Atoi(string(raw))
+	if err != nil || value < 1 {
+		return 0, fmt.Errorf("Query argument %s must be a positive integer but it's '%s'", name, raw)
+	}
+
+	return value, nil
+}
+
+// UserEventsGet returns a page of the security events of the user identified by the session, the most recent first.
+// The events can be filtered by type with the type query argument.
+func UserEventsGet(ctx *middlewares.AutheliaCtx) {
+	replyUserEvents(ctx, ctx.GetSession().Username)
+}
+
+// AdminUserEventsGet returns a page of the security events of the user given in the path, like UserEventsGet. It
+// requires the users.read admin permission.
+func AdminUserEventsGet(ctx *middlewares.AutheliaCtx) {
+	username, ok := adminUsername(ctx)
+	if !ok {
+		return
+	}
+
+	replyUserEvents(ctx, username)
+}
+
+func replyUserEvents(ctx *middlewares.AutheliaCtx, username string) {
+	page, err := parsePositiveQueryArg(ctx, "page", 1)
+	if err != nil {
+		ctx.Error(err, operationFailedMessage)
+		return
+	}
+
+	perPage, err := parsePositiveQueryArg(ctx, "per_page", userEventsDefaultPerPage)
+	if err != nil {
+		ctx.Error(err, operationFailedMessage)
+		return
+	}
+
+	if perPage > userEventsMaxPerPage {
+		perPage = userEventsMaxPerPage
+	}
+
+	eventType := string(ctx.QueryArgs().Peek("type"))
+
+	// Load one more event than requested to know whether there is a next page.
+	events, err := ctx.Providers.StorageProvider.LoadUserEvents(username, eventType, perPage+1, (page-1)*perPage)
+	if err != nil {
+		ctx.Error(fmt.Errorf("Unable to load events of user %s: %s", username, err), operationFailedMessage)
+		return
+	}
+
+	response := UserEventsResponse{
+		Events:  make([]UserEventResponse, 0, perPage),
+		Page:    page,
+		PerPage: perPage,
+		HasMore: len(events) > perPage,
+	}
+
+	for i, event := range events {
+		if i == perPage {
+			break
+		}
+
+		response.Events = append(response.Events, UserEventResponse{
+			Type:       event.Type,
+			Successful: event.Successful,
+			Time:       event.Time.Unix(),
+			RemoteIP:   event.RemoteIP,
+			UserAgent:  event.UserAgent,
+		})
+	}
+
+	if err = ctx.SetJSONBody(response); err != nil {
+		ctx.Logger.Errorf("Unable to set user events response in body: %s", err)
+	}
+}
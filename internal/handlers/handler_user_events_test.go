@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/pkg/autheliatest"
+)
+
+type UserEventsSuite struct {
+	suite.Suite
+
+	mock    *mocks.MockAutheliaCtx
+	storage *autheliatest.MemoryStorageProvider
+}
+
+func (s *UserEventsSuite) SetupTest() {
+	s.mock = mocks.NewMockAutheliaCtx(s.T())
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder(testUsername).OneFactor(s.mock.Clock.Now()).Build())
+
+	s.storage = autheliatest.NewMemoryStorageProvider()
+	s.mock.Ctx.Providers.StorageProvider = s.storage
+
+	now := s.mock.Clock.Now()
+
+	s.Require().NoError(s.storage.AppendAuthenticationLog(models.AuthenticationAttempt{
+		Username: testUsername, Successful: false, Time: now.Add(-3 * time.Minute), RemoteIP: "10.0.0.1",
+		Type: models.AuthenticationTypeFirstFactor,
+	}))
+	s.Require().NoError(s.storage.AppendAuthenticationLog(models.AuthenticationAttempt{
+		Username: testUsername, Successful: true, Time: now.Add(-2 * time.Minute), RemoteIP: "10.0.0.1",
+		Type: models.AuthenticationTypeFirstFactor,
+	}))
+	s.Require().NoError(s.storage.AppendUserEvent(models.UserEvent{
+		Username: testUsername, Successful: true, Time: now.Add(-1 * time.Minute), RemoteIP: "10.0.0.1",
+		Type: models.UserEventTOTPRegistered,
+	}))
+	s.Require().NoError(s.storage.AppendAuthenticationLog(models.AuthenticationAttempt{
+		Username: "harry", Successful: true, Time: now, Type: models.AuthenticationTypeFirstFactor,
+	}))
+}
+
+func (s *UserEventsSuite) TearDownTest() {
+	s.mock.Close()
+}
+
+func (s *UserEventsSuite) TestShouldReturnEventsOfUserMostRecentFirst() {
+	UserEventsGet(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), UserEventsResponse{
+		Events: []UserEventResponse{
+			{Type: models.UserEventTOTPRegistered, Successful: true, Time: s.mock.Clock.Now().Add(-1 * time.Minute).Unix(), RemoteIP: "10.0.0.1"},
+			{Type: models.AuthenticationTypeFirstFactor, Successful: true, Time: s.mock.Clock.Now().Add(-2 * time.Minute).Unix(), RemoteIP: "10.0.0.1"},
+			{Type: models.AuthenticationTypeFirstFactor, Successful: false, Time: s.mock.Clock.Now().Add(-3 * time.Minute).Unix(), RemoteIP: "10.0.0.1"},
+		},
+		Page:    1,
+		PerPage: 20,
+	})
+}
+
+func (s *UserEventsSuite) TestShouldPaginateEvents() {
+	s.mock.Ctx.Request.SetRequestURI("/api/user/events?page=1&per_page=2")
+
+	UserEventsGet(s.mock.Ctx)
+
+	response := UserEventsResponse{}
+	s.mock.GetResponseData(s.T(), &response)
+
+	s.Assert().Len(response.Events, 2)
+	s.Assert().True(response.HasMore)
+	s.Assert().Equal(models.UserEventTOTPRegistered, response.Events[0].Type)
+
+	s.mock.Ctx.Response.Reset()
+	s.mock.Ctx.Request.SetRequestURI("/api/user/events?page=2&per_page=2")
+
+	UserEventsGet(s.mock.Ctx)
+
+	response = UserEventsResponse{}
+	s.mock.GetResponseData(s.T(), &response)
+
+	s.Assert().Len(response.Events, 1)
+	s.Assert().False(response.HasMore)
+	s.Assert().Equal(2, response.Page)
+	s.Assert().False(response.Events[0].Successful)
+}
+
+func (s *UserEventsSuite) TestShouldFilterEventsByType() {
+	s.mock.Ctx.Request.SetRequestURI("/api/user/events?type=TOTPRegistered")
+
+	UserEventsGet(s.mock.Ctx)
+
+	response := UserEventsResponse{}
+	s.mock.GetResponseData(s.T(), &response)
+
+	s.Require().Len(response.Events, 1)
+	s.Assert().Equal(models.UserEventTOTPRegistered, response.Events[0].Type)
+}
+
+func (s *UserEventsSuite) TestShouldLimitNumberOfEventsPerPage() {
+	s.mock.Ctx.Request.SetRequestURI("/api/user/events?per_page=1000")
+
+	UserEventsGet(s.mock.Ctx)
+
+	response := UserEventsResponse{}
+	s.mock.GetResponseData(s.T(), &response)
+
+	s.Assert().Equal(100, response.PerPage)
+}
+
+func (s *UserEventsSuite) TestShouldRefuseInvalidPage() {
+	s.mock.Ctx.Request.SetRequestURI("/api/user/events?page=0")
+
+	UserEventsGet(s.mock.Ctx)
+
+	s.mock.Assert200KO(s.T(), operationFailedMessage)
+	s.Assert().Equal("Query argument page must be a positive integer but it's '0'", s.mock.Hook.LastEntry().Message)
+}
+
+func TestRunUserEventsSuite(t *testing.T) {
+	suite.Run(t, new(UserEventsSuite))
+}
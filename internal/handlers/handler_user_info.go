@@ -8,25 +8,79 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/events"
 	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/regulation"
+	"github.com/authelia/authelia/internal/session"
 	"github.com/authelia/authelia/internal/storage"
 	"github.com/authelia/authelia/internal/utils"
 )
 
-func loadInfo(username string, storageProvider storage.Provider, userInfo *UserInfo, logger *logrus.Entry) []error {
-	var wg sync.WaitGroup
+func newUserRegulationInfo(status regulation.Status) UserRegulationInfo {
+	info := UserRegulationInfo{
+		Banned:         status.Banned,
+		FailedAttempts: status.FailedAttempts,
+	}
+
+	if status.Banned {
+		info.BannedUntil = status.BannedUntil.Unix()
+	}
+
+	return info
+}
+
+func newUserTOTPDevices(devices []models.TOTPDevice) []UserTOTPDevice {
+	userDevices := make([]UserTOTPDevice, 0, len(devices))
+
+	for _, device := range devices {
+		userDevice := UserTOTPDevice{
+			ID:          device.ID,
+			Description: device.Description,
+		}
+
+		if !device.CreatedAt.IsZero() {
+			userDevice.CreatedAt = device.CreatedAt.Unix()
+		}
 
-	wg.Add(3)
+		if !device.LastUsedAt.IsZero() {
+			userDevice.LastUsedAt = device.LastUsedAt.Unix()
+		}
+
+		userDevices = append(userDevices, userDevice)
+	}
+
+	return userDevices
+}
 
-	errors := make([]error, 0)
+// loadInfo loads the info of the user in parallel. A lookup which fails doesn't prevent the others from loading, the
+// returned warnings describe the lookups which failed.
+func loadInfo(username string, storageProvider storage.Provider, regulator *regulation.Regulator, userInfo *UserInfo, logger *logrus.Entry) (warnings []string) {
+	var (
+		wg    sync.WaitGroup
+		mutex sync.Mutex
+	)
+
+	wg.Add(userInfoLookups)
+
+	warn := func(warning string, err error) {
+		logger.Errorf("%s: %s", warning, err)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		warnings = append(warnings, warning)
+	}
 
 	go func() {
 		defer wg.Done()
 
 		method, err := storageProvider.LoadPreferred2FAMethod(username)
 		if err != nil {
-			errors = append(errors, err)
-			logger.Error(err)
+			// Fallback to the default method so the user is still able to authenticate.
+			userInfo.Method = authentication.PossibleMethods[0]
+
+			warn(userInfoMethodWarning, err)
 
 			return
 		}
@@ -47,8 +101,7 @@ func loadInfo(username string, storageProvider storage.Provider, userInfo *UserI
 				return
 			}
 
-			errors = append(errors, err)
-			logger.Error(err)
+			warn(userInfoU2FWarning, err)
 
 			return
 		}
@@ -59,41 +112,113 @@ func loadInfo(username string, storageProvider storage.Provider, userInfo *UserI
 	go func() {
 		defer wg.Done()
 
-		_, err := storageProvider.LoadTOTPSecret(username)
+		devices, err := storageProvider.LoadTOTPDevices(username)
+		if err != nil {
+			warn(userInfoTOTPWarning, err)
+			return
+		}
+
+		userInfo.HasTOTP = len(devices) != 0
+		userInfo.TOTPDevices = newUserTOTPDevices(devices)
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		credentials, err := storageProvider.LoadWebauthnCredentials(username)
+		if err != nil {
+			warn(userInfoWebauthnWarning, err)
+			return
+		}
+
+		for _, credential := range credentials {
+			if credential.IsSecondFactor() {
+				userInfo.HasWebauthn = true
+				break
+			}
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		count, err := storageProvider.CountBackupCodes(username)
+		if err != nil {
+			warn(userInfoBackupCodesWarning, err)
+			return
+		}
+
+		userInfo.BackupCodesRemaining = count
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		enrollment, err := storageProvider.LoadPushEnrollment(username)
 		if err != nil {
-			if err == storage.ErrNoTOTPSecret {
+			if err == storage.ErrNoPushEnrollment {
 				return
 			}
 
-			errors = append(errors, err)
-			logger.Error(err)
+			warn(userInfoPushWarning, err)
 
 			return
 		}
 
-		userInfo.HasTOTP = true
+		userInfo.PushEnrollment = enrollment.Status
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		status, err := regulator.Status(username)
+		if err != nil {
+			warn(userInfoRegulationWarning, err)
+			return
+		}
+
+		userInfo.FirstFactorRegulation = newUserRegulationInfo(status)
+
+		status, err = regulator.SecondFactorStatus(username)
+		if err != nil {
+			warn(userInfoRegulationWarning, err)
+			return
+		}
+
+		userInfo.SecondFactorRegulation = newUserRegulationInfo(status)
 	}()
 
 	wg.Wait()
 
-	return errors
+	return warnings
 }
 
-// UserInfoGet get the info related to the user identified by the session.
+// newUserInfo loads the info of the user of the session, an error is only returned when none of it could be loaded.
+func newUserInfo(ctx *middlewares.AutheliaCtx, userSession *session.UserSession) (userInfo UserInfo, err error) {
+	warnings := loadInfo(userSession.Username, ctx.Providers.StorageProvider, ctx.Providers.Regulator, &userInfo, ctx.Logger)
+
+	if len(warnings) == userInfoLookups {
+		return userInfo, fmt.Errorf("Unable to load user information")
+	}
+
+	userInfo.DisplayName = userSession.DisplayName
+	userInfo.Warnings = warnings
+
+	return userInfo, nil
+}
+
+// UserInfoGet get the info related to the user identified by the session. The info which loaded is returned along with
+// warnings when some of it couldn't be loaded, an error is only returned when none of it could be loaded.
 func UserInfoGet(ctx *middlewares.AutheliaCtx) {
 	userSession := ctx.GetSession()
 
-	userInfo := UserInfo{}
-	errors := loadInfo(userSession.Username, ctx.Providers.StorageProvider, &userInfo, ctx.Logger)
-
-	if len(errors) > 0 {
-		ctx.Error(fmt.Errorf("Unable to load user information"), operationFailedMessage)
+	userInfo, err := newUserInfo(ctx, &userSession)
+	if err != nil {
+		ctx.Error(err, operationFailedMessage)
 		return
 	}
 
-	userInfo.DisplayName = userSession.DisplayName
-
-	err := ctx.SetJSONBody(userInfo)
+	err = ctx.SetJSONBody(userInfo)
 	if err != nil {
 		ctx.Logger.Errorf("Unable to set user info response in body: %s", err)
 	}
@@ -128,5 +253,10 @@ func MethodPreferencePost(ctx *middlewares.AutheliaCtx) {
 		return
 	}
 
+	ctx.PublishEvent(events.PreferenceChanged, userSession.Username, map[string]string{
+		events.DetailPreference: preferenceSecondFactorMethod,
+		events.DetailValue:      bodyJSON.Method,
+	})
+
 	ctx.ReplyOK()
 }
@@ -3,6 +3,7 @@ package handlers
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/sirupsen/logrus"
@@ -10,7 +11,11 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/events"
 	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/regulation"
 	"github.com/authelia/authelia/internal/storage"
 )
 
@@ -53,16 +58,44 @@ func setPreferencesExpectations(preferences UserInfo, provider *storage.MockProv
 	}
 
 	if preferences.HasTOTP {
-		totpSecret := "secret"
 		provider.
 			EXPECT().
-			LoadTOTPSecret(gomock.Eq("john")).
-			Return(totpSecret, nil)
+			LoadTOTPDevices(gomock.Eq("john")).
+			Return([]models.TOTPDevice{{ID: "phone", Username: "john", Description: "Phone", Secret: "secret", CreatedAt: time.Unix(1577880000, 0)}}, nil)
 	} else {
 		provider.
 			EXPECT().
-			LoadTOTPSecret(gomock.Eq("john")).
-			Return("", storage.ErrNoTOTPSecret)
+			LoadTOTPDevices(gomock.Eq("john")).
+			Return([]models.TOTPDevice{}, nil)
+	}
+
+	if preferences.HasWebauthn {
+		provider.
+			EXPECT().
+			LoadWebauthnCredentials(gomock.Eq("john")).
+			Return([]models.WebauthnCredential{{ID: []byte("abc"), Username: "john", Type: models.WebauthnCredentialTypeSecondFactor, Description: "YubiKey"}}, nil)
+	} else {
+		provider.
+			EXPECT().
+			LoadWebauthnCredentials(gomock.Eq("john")).
+			Return([]models.WebauthnCredential{}, nil)
+	}
+
+	provider.
+		EXPECT().
+		CountBackupCodes(gomock.Eq("john")).
+		Return(preferences.BackupCodesRemaining, nil)
+
+	if preferences.PushEnrollment != "" {
+		provider.
+			EXPECT().
+			LoadPushEnrollment(gomock.Eq("john")).
+			Return(&models.PushEnrollment{Username: "john", Provider: "duo", Status: preferences.PushEnrollment, Devices: 1}, nil)
+	} else {
+		provider.
+			EXPECT().
+			LoadPushEnrollment(gomock.Eq("john")).
+			Return(nil, storage.ErrNoPushEnrollment)
 	}
 }
 
@@ -86,6 +119,19 @@ func TestMethodSetToU2F(t *testing.T) {
 			HasU2F:  false,
 			HasTOTP: false,
 		},
+		{
+			Method:         "mobile_push",
+			PushEnrollment: "enrolled",
+		},
+		{
+			Method:      "webauthn",
+			HasWebauthn: true,
+		},
+		{
+			Method:               "totp",
+			HasTOTP:              true,
+			BackupCodesRemaining: 8,
+		},
 	}
 
 	for _, expectedPreferences := range table {
@@ -114,6 +160,22 @@ func TestMethodSetToU2F(t *testing.T) {
 		t.Run("registered totp", func(t *testing.T) {
 			assert.Equal(t, expectedPreferences.HasTOTP, actualPreferences.HasTOTP)
 		})
+
+		t.Run("registered totp devices", func(t *testing.T) {
+			assert.Equal(t, expectedPreferences.HasTOTP, len(actualPreferences.TOTPDevices) != 0)
+		})
+
+		t.Run("registered webauthn", func(t *testing.T) {
+			assert.Equal(t, expectedPreferences.HasWebauthn, actualPreferences.HasWebauthn)
+		})
+
+		t.Run("remaining backup codes", func(t *testing.T) {
+			assert.Equal(t, expectedPreferences.BackupCodesRemaining, actualPreferences.BackupCodesRemaining)
+		})
+
+		t.Run("push enrollment", func(t *testing.T) {
+			assert.Equal(t, expectedPreferences.PushEnrollment, actualPreferences.PushEnrollment)
+		})
 		mock.Close()
 	}
 }
@@ -131,25 +193,115 @@ func (s *FetchSuite) TestShouldGetDefaultPreferenceIfNotInDB() {
 
 	s.mock.StorageProviderMock.
 		EXPECT().
-		LoadTOTPSecret(gomock.Eq("john")).
-		Return("", storage.ErrNoTOTPSecret)
+		LoadTOTPDevices(gomock.Eq("john")).
+		Return([]models.TOTPDevice{}, nil)
+
+	s.mock.StorageProviderMock.
+		EXPECT().
+		LoadWebauthnCredentials(gomock.Eq("john")).
+		Return([]models.WebauthnCredential{}, nil)
+
+	s.mock.StorageProviderMock.
+		EXPECT().
+		CountBackupCodes(gomock.Eq("john")).
+		Return(0, nil)
+
+	s.mock.StorageProviderMock.
+		EXPECT().
+		LoadPushEnrollment(gomock.Eq("john")).
+		Return(nil, storage.ErrNoPushEnrollment)
+
+	UserInfoGet(s.mock.Ctx)
+	s.mock.Assert200OK(s.T(), UserInfo{Method: "totp", TOTPDevices: []UserTOTPDevice{}})
+}
+
+func (s *FetchSuite) TestShouldReturnWarningsWhenStorageFailsToLoadSomeInfo() {
+	s.mock.StorageProviderMock.EXPECT().
+		LoadPreferred2FAMethod(gomock.Eq("john")).
+		Return("", fmt.Errorf("Failure"))
+
+	s.mock.StorageProviderMock.
+		EXPECT().
+		LoadU2FDeviceHandle(gomock.Eq("john")).
+		Return(nil, nil, storage.ErrNoU2FDeviceHandle)
+
+	s.mock.StorageProviderMock.
+		EXPECT().
+		LoadTOTPDevices(gomock.Eq("john")).
+		Return(nil, fmt.Errorf("Failure"))
+
+	s.mock.StorageProviderMock.
+		EXPECT().
+		LoadWebauthnCredentials(gomock.Eq("john")).
+		Return(nil, fmt.Errorf("Failure"))
+
+	s.mock.StorageProviderMock.
+		EXPECT().
+		CountBackupCodes(gomock.Eq("john")).
+		Return(0, fmt.Errorf("Failure"))
+
+	s.mock.StorageProviderMock.
+		EXPECT().
+		LoadPushEnrollment(gomock.Eq("john")).
+		Return(nil, fmt.Errorf("Failure"))
 
 	UserInfoGet(s.mock.Ctx)
-	s.mock.Assert200OK(s.T(), UserInfo{Method: "totp"})
+
+	actualPreferences := UserInfo{}
+	s.mock.GetResponseData(s.T(), &actualPreferences)
+
+	s.Assert().Equal("totp", actualPreferences.Method)
+	s.Assert().False(actualPreferences.HasU2F)
+	s.Assert().ElementsMatch([]string{
+		"Unable to load the preferred second factor method",
+		"Unable to load the one-time password devices",
+		"Unable to check whether a WebAuthn credential is registered",
+		"Unable to count the remaining backup codes",
+		"Unable to load the push enrollment",
+	}, actualPreferences.Warnings)
+	s.Assert().Equal(logrus.ErrorLevel, s.mock.Hook.LastEntry().Level)
 }
 
-func (s *FetchSuite) TestShouldReturnError500WhenStorageFailsToLoad() {
+func (s *FetchSuite) TestShouldReturnError500WhenStorageFailsToLoadAllInfo() {
+	s.mock.Ctx.Providers.Regulator = regulation.NewRegulator(&schema.RegulationConfiguration{
+		MaxRetries: 3,
+		FindTime:   "2m",
+		BanTime:    "5m",
+	}, s.mock.StorageProviderMock, &s.mock.Clock)
+
 	s.mock.StorageProviderMock.EXPECT().
 		LoadPreferred2FAMethod(gomock.Eq("john")).
 		Return("", fmt.Errorf("Failure"))
 
 	s.mock.StorageProviderMock.
 		EXPECT().
-		LoadU2FDeviceHandle(gomock.Eq("john"))
+		LoadU2FDeviceHandle(gomock.Eq("john")).
+		Return(nil, nil, fmt.Errorf("Failure"))
+
+	s.mock.StorageProviderMock.
+		EXPECT().
+		LoadTOTPDevices(gomock.Eq("john")).
+		Return(nil, fmt.Errorf("Failure"))
+
+	s.mock.StorageProviderMock.
+		EXPECT().
+		LoadWebauthnCredentials(gomock.Eq("john")).
+		Return(nil, fmt.Errorf("Failure"))
+
+	s.mock.StorageProviderMock.
+		EXPECT().
+		CountBackupCodes(gomock.Eq("john")).
+		Return(0, fmt.Errorf("Failure"))
+
+	s.mock.StorageProviderMock.
+		EXPECT().
+		LoadPushEnrollment(gomock.Eq("john")).
+		Return(nil, fmt.Errorf("Failure"))
 
 	s.mock.StorageProviderMock.
 		EXPECT().
-		LoadTOTPSecret(gomock.Eq("john"))
+		LoadLatestAuthenticationLogs(gomock.Eq("john"), gomock.Any()).
+		Return(nil, fmt.Errorf("Failure"))
 
 	UserInfoGet(s.mock.Ctx)
 
@@ -158,6 +310,47 @@ func (s *FetchSuite) TestShouldReturnError500WhenStorageFailsToLoad() {
 	assert.Equal(s.T(), logrus.ErrorLevel, s.mock.Hook.LastEntry().Level)
 }
 
+func (s *FetchSuite) TestShouldReturnRegulationState() {
+	s.mock.Ctx.Providers.Regulator = regulation.NewRegulator(&schema.RegulationConfiguration{
+		MaxRetries: 3,
+		FindTime:   "2m",
+		BanTime:    "5m",
+		SecondFactor: &schema.RegulationSecondFactorConfiguration{
+			MaxRetries: 2,
+			FindTime:   "2m",
+			BanTime:    "5m",
+		},
+	}, s.mock.StorageProviderMock, &s.mock.Clock)
+
+	now := s.mock.Clock.Now()
+
+	setPreferencesExpectations(UserInfo{Method: "totp", HasTOTP: true}, s.mock.StorageProviderMock)
+
+	s.mock.StorageProviderMock.
+		EXPECT().
+		LoadLatestAuthenticationLogs(gomock.Eq("john"), gomock.Any()).
+		Return([]models.AuthenticationAttempt{
+			{Username: "john", Successful: false, Time: now.Add(-10 * time.Second), Type: models.AuthenticationTypeTOTP},
+			{Username: "john", Successful: false, Time: now.Add(-20 * time.Second), Type: models.AuthenticationTypeTOTP},
+			{Username: "john", Successful: true, Time: now.Add(-30 * time.Second), Type: models.AuthenticationTypeFirstFactor},
+			{Username: "john", Successful: false, Time: now.Add(-40 * time.Second), Type: models.AuthenticationTypeFirstFactor},
+		}, nil).
+		Times(2)
+
+	UserInfoGet(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), UserInfo{
+		Method:      "totp",
+		HasTOTP:     true,
+		TOTPDevices: []UserTOTPDevice{{ID: "phone", Description: "Phone", CreatedAt: 1577880000}},
+		SecondFactorRegulation: UserRegulationInfo{
+			Banned:         true,
+			BannedUntil:    now.Add(-10 * time.Second).Add(5 * time.Minute).Unix(),
+			FailedAttempts: 2,
+		},
+	})
+}
+
 func TestFetchSuite(t *testing.T) {
 	suite.Run(t, &FetchSuite{})
 }
@@ -213,7 +406,7 @@ func (s *SaveSuite) TestShouldReturnError500WhenBadMethodProvided() {
 	MethodPreferencePost(s.mock.Ctx)
 
 	s.mock.Assert200KO(s.T(), "Operation failed.")
-	assert.Equal(s.T(), "Unknown method 'abc', it should be one of totp, u2f, mobile_push", s.mock.Hook.LastEntry().Message)
+	assert.Equal(s.T(), "Unknown method 'abc', it should be one of totp, u2f, mobile_push, webauthn", s.mock.Hook.LastEntry().Message)
 	assert.Equal(s.T(), logrus.ErrorLevel, s.mock.Hook.LastEntry().Level)
 }
 
@@ -241,6 +434,31 @@ func (s *SaveSuite) TestShouldReturn200WhenMethodIsSuccessfullySaved() {
 	assert.Equal(s.T(), 200, s.mock.Ctx.Response.StatusCode())
 }
 
+func (s *SaveSuite) TestShouldPublishPreferenceChangedEvent() {
+	var published []events.Event
+
+	s.mock.Ctx.Providers.Events.Subscribe(func(event events.Event) {
+		published = append(published, event)
+	}, events.PreferenceChanged)
+
+	s.mock.Ctx.Request.Header.Set("X-Request-Id", "abc-123")
+	s.mock.Ctx.Request.SetBody([]byte("{\"method\":\"totp\"}"))
+	s.mock.StorageProviderMock.EXPECT().
+		SavePreferred2FAMethod(gomock.Eq("john"), gomock.Eq("totp")).
+		Return(nil)
+
+	MethodPreferencePost(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), nil)
+	require.Len(s.T(), published, 1)
+	assert.Equal(s.T(), "john", published[0].Username)
+	assert.Equal(s.T(), "abc-123", published[0].RequestID)
+	assert.Equal(s.T(), map[string]string{
+		events.DetailPreference: "second_factor_method",
+		events.DetailValue:      "totp",
+	}, published[0].Details)
+}
+
 func TestSaveSuite(t *testing.T) {
 	suite.Run(t, &SaveSuite{})
 }
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/authelia/authelia/internal/events"
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/storage"
+)
+
+// UserOIDCConsentsGet handler returning the unexpired consents the user remembered for the OpenID Connect clients.
+func UserOIDCConsentsGet(ctx *middlewares.AutheliaCtx) {
+	userSession := ctx.GetSession()
+
+	consents, err := ctx.Providers.StorageProvider.LoadUserOIDCConsents(userSession.Username, ctx.Clock.Now())
+	if err != nil {
+		ctx.Error(fmt.Errorf("Unable to load the OpenID Connect consents of user %s: %s", userSession.Username, err), operationFailedMessage)
+		return
+	}
+
+	if err = ctx.SetJSONBody(newOIDCConsents(ctx, consents)); err != nil {
+		ctx.Logger.Errorf("Unable to set the OpenID Connect consents response in body: %s", err)
+	}
+}
+
+// UserOIDCConsentDelete handler revoking the consent the user remembered for an OpenID Connect client, the consent is
+// asked again on the next authorization of the client.
+func UserOIDCConsentDelete(ctx *middlewares.AutheliaCtx) {
+	userSession := ctx.GetSession()
+
+	clientID, _ := ctx.UserValue("client_id").(string)
+
+	if clientID == "" {
+		ctx.Error(fmt.Errorf("No OpenID Connect client ID provided"), operationFailedMessage)
+		return
+	}
+
+	err := ctx.Providers.StorageProvider.DeleteOIDCConsent(userSession.Username, clientID)
+
+	switch {
+	case errors.Is(err, storage.ErrNoOIDCConsent):
+		ctx.Error(fmt.Errorf("User %s has no consent to client %s", userSession.Username, clientID), operationFailedMessage)
+		return
+	case err != nil:
+		ctx.Error(fmt.Errorf("Unable to revoke the consent of user %s to client %s: %s", userSession.Username, clientID, err), operationFailedMessage)
+		return
+	}
+
+	ctx.Logger.Infof("Consent of user %s to client %s has been revoked", userSession.Username, clientID)
+
+	ctx.PublishEvent(events.ConsentRevoked, userSession.Username, map[string]string{
+		events.DetailClientID: clientID,
+	})
+
+	ctx.ReplyOK()
+}
+
+// newOIDCConsents returns the models of the consents returned to the user, the description of the clients which are
+// still configured is added.
+func newOIDCConsents(ctx *middlewares.AutheliaCtx, consents []models.OIDCConsent) []OIDCConsent {
+	body := make([]OIDCConsent, 0, len(consents))
+
+	for _, consent := range consents {
+		item := OIDCConsent{
+			ClientID:  consent.ClientID,
+			Scopes:    consent.Scopes,
+			Audience:  consent.Audience,
+			CreatedAt: consent.CreatedAt.Unix(),
+			ExpiresAt: consent.ExpiresAt.Unix(),
+		}
+
+		if ctx.Providers.OpenIDConnect.Store != nil {
+			if client, err := ctx.Providers.OpenIDConnect.Store.GetInternalClient(consent.ClientID); err == nil {
+				item.ClientDescription = client.Description
+			}
+		}
+
+		body = append(body, item)
+	}
+
+	return body
+}
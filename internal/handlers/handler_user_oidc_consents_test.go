@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/authorization"
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/events"
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/oidc"
+	"github.com/authelia/authelia/internal/session"
+	"github.com/authelia/authelia/pkg/autheliatest"
+)
+
+type UserOIDCConsentsSuite struct {
+	suite.Suite
+
+	mock      *mocks.MockAutheliaCtx
+	storage   *autheliatest.MemoryStorageProvider
+	published []events.Event
+}
+
+func (s *UserOIDCConsentsSuite) SetupTest() {
+	s.mock = mocks.NewMockAutheliaCtx(s.T())
+	s.mock.Ctx.Configuration.Session.Domain = "example.com"
+	s.mock.Ctx.Configuration.IdentityProviders.OIDC = &schema.OpenIDConnectConfiguration{
+		RememberConsentLifespan: time.Hour * 24,
+	}
+
+	s.mock.Clock.Set(time.Unix(1600000000, 0))
+	s.mock.Ctx.Clock = &s.mock.Clock
+
+	s.storage = autheliatest.NewMemoryStorageProvider()
+	s.mock.Ctx.Providers.StorageProvider = s.storage
+
+	store, err := oidc.NewOpenIDConnectStore(&schema.OpenIDConnectConfiguration{
+		Clients: []schema.OpenIDConnectClientConfiguration{
+			{
+				ID:           "client",
+				Description:  "Application",
+				Policy:       "one_factor",
+				RedirectURIs: []string{"https://app.example.net/callback"},
+			},
+		},
+	})
+	s.Require().NoError(err)
+
+	s.mock.Ctx.Providers.OpenIDConnect = oidc.OpenIDConnectProvider{Store: store}
+
+	s.published = nil
+	s.mock.Ctx.Providers.Events.Subscribe(func(event events.Event) {
+		s.published = append(s.published, event)
+	}, events.ConsentGranted, events.ConsentRevoked)
+
+	s.startWorkflow([]string{"openid", "groups"})
+}
+
+func (s *UserOIDCConsentsSuite) TearDownTest() {
+	s.mock.Close()
+}
+
+// startWorkflow initiates the workflow of the client requesting the given scopes.
+func (s *UserOIDCConsentsSuite) startWorkflow(scopes []string) {
+	userSession := s.mock.Ctx.GetSession()
+	userSession.Username = testUsername
+	userSession.AuthenticationLevel = authentication.OneFactor
+	userSession.OIDCWorkflowSession = &session.OIDCWorkflowSession{
+		ClientID:                   "client",
+		RequestedScopes:            scopes,
+		AuthURI:                    "https://auth.example.com/api/oidc/authorize?client_id=client",
+		TargetURI:                  "https://app.example.net/callback",
+		RequiredAuthorizationLevel: authorization.OneFactor,
+	}
+	s.Require().NoError(s.mock.Ctx.SaveSession(userSession))
+	s.mock.Ctx.Response.Reset()
+}
+
+// isPreAuthorized returns the pre-authorized flag of the consent of the current workflow.
+func (s *UserOIDCConsentsSuite) isPreAuthorized() bool {
+	s.mock.Ctx.Response.Reset()
+
+	oidcConsent(s.mock.Ctx)
+	s.Require().Equal(200, s.mock.Ctx.Response.StatusCode())
+
+	var body oidc.ConsentGetResponseBody
+
+	s.mock.GetResponseData(s.T(), &body)
+
+	return body.PreAuthorized
+}
+
+func (s *UserOIDCConsentsSuite) accept(remember bool) {
+	bodyBytes, err := json.Marshal(ConsentPostRequestBody{ClientID: "client", AcceptOrReject: accept, RememberConsent: remember})
+	s.Require().NoError(err)
+	s.mock.Ctx.Request.SetBody(bodyBytes)
+
+	oidcConsentPOST(s.mock.Ctx)
+	s.Require().Equal(200, s.mock.Ctx.Response.StatusCode())
+}
+
+func (s *UserOIDCConsentsSuite) TestShouldNotRememberConsentByDefault() {
+	s.accept(false)
+
+	consents, err := s.storage.LoadUserOIDCConsents(testUsername, s.mock.Clock.Now())
+	s.Require().NoError(err)
+	s.Assert().Len(consents, 0)
+
+	s.Require().Len(s.published, 1)
+	s.Assert().NotContains(s.published[0].Details, events.DetailExpiresAt)
+
+	s.startWorkflow([]string{"openid", "groups"})
+	s.Assert().False(s.isPreAuthorized())
+}
+
+func (s *UserOIDCConsentsSuite) TestShouldPreAuthorizeRememberedConsent() {
+	s.Assert().False(s.isPreAuthorized())
+
+	s.accept(true)
+
+	s.Require().Len(s.published, 1)
+	s.Assert().Equal(events.ConsentGranted, s.published[0].Type)
+	s.Assert().Equal("2020-09-14T12:26:40Z", s.published[0].Details[events.DetailExpiresAt])
+
+	s.startWorkflow([]string{"openid"})
+	s.Assert().True(s.isPreAuthorized())
+
+	s.startWorkflow([]string{"openid", "groups", "email"})
+	s.Assert().False(s.isPreAuthorized())
+
+	s.startWorkflow([]string{"openid", "groups"})
+	s.mock.Clock.Set(s.mock.Clock.Now().Add(time.Hour * 24))
+	s.Assert().False(s.isPreAuthorized())
+}
+
+func (s *UserOIDCConsentsSuite) TestShouldListRememberedConsents() {
+	s.accept(true)
+	s.mock.Ctx.Response.Reset()
+
+	s.Require().NoError(s.storage.SaveOIDCConsent(models.OIDCConsent{
+		Username:  "bob",
+		ClientID:  "client",
+		Scopes:    []string{"openid"},
+		CreatedAt: s.mock.Clock.Now(),
+		ExpiresAt: s.mock.Clock.Now().Add(time.Hour),
+	}))
+
+	UserOIDCConsentsGet(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), []OIDCConsent{{
+		ClientID:          "client",
+		ClientDescription: "Application",
+		Scopes:            []string{"openid", "groups"},
+		CreatedAt:         1600000000,
+		ExpiresAt:         1600000000 + 86400,
+	}})
+}
+
+func (s *UserOIDCConsentsSuite) TestShouldRevokeRememberedConsent() {
+	s.accept(true)
+	s.mock.Ctx.Response.Reset()
+
+	s.mock.Ctx.SetUserValue("client_id", "client")
+
+	UserOIDCConsentDelete(s.mock.Ctx)
+	s.mock.Assert200OK(s.T(), nil)
+
+	s.Require().Len(s.published, 2)
+	s.Assert().Equal(events.ConsentRevoked, s.published[1].Type)
+	s.Assert().Equal("client", s.published[1].Details[events.DetailClientID])
+
+	s.startWorkflow([]string{"openid", "groups"})
+	s.Assert().False(s.isPreAuthorized())
+
+	s.mock.Ctx.Response.Reset()
+
+	UserOIDCConsentDelete(s.mock.Ctx)
+	s.mock.Assert200KO(s.T(), operationFailedMessage)
+	s.Assert().Equal("User john has no consent to client client", s.mock.Hook.LastEntry().Message)
+}
+
+func TestRunUserOIDCConsentsSuite(t *testing.T) {
+	s := new(UserOIDCConsentsSuite)
+	suite.Run(t, s)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/authelia/authelia/internal/events"
+	"github.com/authelia/authelia/internal/middlewares"
+)
+
+type userStreamEvent struct {
+	Type events.Type `json:"type"`
+	Time int64       `json:"time"`
+}
+
+// UserStreamGet streams the events of the user identified by the session with server-sent events, such as the pending
+// push approvals or the revocation of the sessions, so the portal doesn't have to poll the state endpoint. The stream
+// ends once the sessions of the user are revoked.
+func UserStreamGet(ctx *middlewares.AutheliaCtx) {
+	userSession := ctx.GetSession()
+
+	listener, stop := ctx.Providers.EventStream.Listen(userSession.Username)
+
+	ctx.Logger.Debugf("User %s is listening to the event stream", userSession.Username)
+
+	ctx.SetContentType("text/event-stream")
+	ctx.Response.Header.Set("Cache-Control", "no-cache")
+	// Prevents NGINX from buffering the stream.
+	ctx.Response.Header.Set("X-Accel-Buffering", "no")
+
+	ctx.SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer stop()
+
+		ticker := time.NewTicker(userStreamKeepAliveInterval)
+		defer ticker.Stop()
+
+		if err := writeUserStreamComment(w, "connected"); err != nil {
+			return
+		}
+
+		for {
+			select {
+			case event := <-listener:
+				if err := writeUserStreamEvent(w, event); err != nil {
+					return
+				}
+
+				if event.Type == events.SessionsRevoked {
+					return
+				}
+			case <-ticker.C:
+				if err := writeUserStreamComment(w, "keep-alive"); err != nil {
+					return
+				}
+			}
+		}
+	})
+}
+
+func writeUserStreamEvent(w *bufio.Writer, event events.Event) error {
+	data, err := json.Marshal(userStreamEvent{Type: event.Type, Time: event.Time.Unix()})
+	if err != nil {
+		return err
+	}
+
+	if _, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
+
+func writeUserStreamComment(w *bufio.Writer, comment string) error {
+	if _, err := fmt.Fprintf(w, ": %s\n\n", comment); err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/authelia/authelia/internal/events"
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/pkg/autheliatest"
+)
+
+func TestShouldStreamEventsOfUserUntilSessionsAreRevoked(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	mock.SetUserSession(t, autheliatest.NewUserSessionBuilder(testUsername).OneFactor(mock.Clock.Now()).Build())
+
+	broker := events.NewBroker()
+	mock.Ctx.Providers.EventStream = broker
+	mock.Ctx.Providers.Events.Subscribe(broker.Subscriber(), events.PushRequested, events.SessionsRevoked)
+
+	UserStreamGet(mock.Ctx)
+
+	assert.Equal(t, "text/event-stream", string(mock.Ctx.Response.Header.ContentType()))
+	assert.Equal(t, "no-cache", string(mock.Ctx.Response.Header.Peek("Cache-Control")))
+
+	mock.Ctx.Providers.Events.Publish(events.Event{Type: events.PushRequested, Time: time.Unix(1000, 0), Username: testUsername})
+	mock.Ctx.Providers.Events.Publish(events.Event{Type: events.PushRequested, Time: time.Unix(1001, 0), Username: "harry"})
+	mock.Ctx.Providers.Events.Publish(events.Event{Type: events.LoginSucceeded, Time: time.Unix(1002, 0), Username: testUsername})
+	mock.Ctx.Providers.Events.Publish(events.Event{Type: events.SessionsRevoked, Time: time.Unix(1003, 0), Username: testUsername})
+
+	// The body is read until the stream ends, after the revocation of the sessions.
+	assert.Equal(t, ": connected\n\n"+
+		"event: push_requested\ndata: {\"type\":\"push_requested\",\"time\":1000}\n\n"+
+		"event: sessions_revoked\ndata: {\"type\":\"sessions_revoked\",\"time\":1003}\n\n",
+		string(mock.Ctx.Response.Body()))
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/session"
+)
+
+// UserTOTPDevicePut handler renaming one of the TOTP devices of the user. The user must be authenticated with two
+// factors to manage the devices.
+func UserTOTPDevicePut(ctx *middlewares.AutheliaCtx) {
+	var bodyJSON totpDeviceRequestBody
+
+	if err := ctx.ParseBody(&bodyJSON); err != nil {
+		ctx.Error(err, operationFailedMessage)
+		return
+	}
+
+	userSession := ctx.GetSession()
+
+	deviceID, ok := loadUserTOTPDeviceID(ctx, &userSession)
+	if !ok {
+		return
+	}
+
+	description := strings.TrimSpace(bodyJSON.Description)
+
+	if description == "" || len(description) > totpDescriptionMaxLength {
+		ctx.Error(fmt.Errorf("Description of the TOTP device of user %s must be between 1 and %d characters", userSession.Username, totpDescriptionMaxLength), operationFailedMessage)
+		return
+	}
+
+	if err := ctx.Providers.StorageProvider.UpdateTOTPDeviceDescription(userSession.Username, deviceID, description); err != nil {
+		ctx.Error(fmt.Errorf("Unable to rename TOTP device %s of user %s: %s", deviceID, userSession.Username, err), operationFailedMessage)
+		return
+	}
+
+	ctx.ReplyOK()
+}
+
+// UserTOTPDeviceDelete handler deleting one of the TOTP devices of the user. The user must be authenticated with two
+// factors to manage the devices.
+func UserTOTPDeviceDelete(ctx *middlewares.AutheliaCtx) {
+	userSession := ctx.GetSession()
+
+	deviceID, ok := loadUserTOTPDeviceID(ctx, &userSession)
+	if !ok {
+		return
+	}
+
+	if err := ctx.Providers.StorageProvider.DeleteTOTPDevice(userSession.Username, deviceID); err != nil {
+		ctx.Error(fmt.Errorf("Unable to delete TOTP device %s of user %s: %s", deviceID, userSession.Username, err), operationFailedMessage)
+		return
+	}
+
+	ctx.Logger.Debugf("TOTP device %s of user %s has been deleted", deviceID, userSession.Username)
+
+	appendUserEvent(ctx, userSession.Username, models.UserEventTOTPDeleted)
+	publishDeviceRemoval(ctx, deviceOneTimePassword)
+
+	ctx.ReplyOK()
+}
+
+// loadUserTOTPDeviceID returns the ID of the device from the path after checking the user is allowed to manage it.
+// The response is written when the user isn't allowed to.
+func loadUserTOTPDeviceID(ctx *middlewares.AutheliaCtx, userSession *session.UserSession) (deviceID string, ok bool) {
+	if userSession.AuthenticationLevel < authentication.TwoFactor {
+		ctx.Logger.Debugf("User %s must be authenticated with two factors to manage the TOTP devices", userSession.Username)
+		ctx.ReplyForbidden()
+
+		return "", false
+	}
+
+	deviceID, _ = ctx.UserValue("id").(string)
+
+	if deviceID == "" {
+		ctx.Error(fmt.Errorf("No TOTP device ID provided"), operationFailedMessage)
+		return "", false
+	}
+
+	devices, err := ctx.Providers.StorageProvider.LoadTOTPDevices(userSession.Username)
+	if err != nil {
+		ctx.Error(fmt.Errorf("Unable to load the TOTP devices of user %s: %s", userSession.Username, err), operationFailedMessage)
+		return "", false
+	}
+
+	for _, device := range devices {
+		if device.ID == deviceID {
+			return deviceID, true
+		}
+	}
+
+	ctx.Error(fmt.Errorf("TOTP device %s is not registered by user %s", deviceID, userSession.Username), operationFailedMessage)
+
+	return "", false
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/authelia/authelia/internal/events"
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/pkg/autheliatest"
+)
+
+type UserTOTPDevicesSuite struct {
+	suite.Suite
+
+	mock    *mocks.MockAutheliaCtx
+	storage *autheliatest.MemoryStorageProvider
+}
+
+func (s *UserTOTPDevicesSuite) SetupTest() {
+	s.mock = mocks.NewMockAutheliaCtx(s.T())
+	s.mock.Clock.Set(time.Unix(1600000000, 0))
+	s.mock.Ctx.Clock = &s.mock.Clock
+	s.mock.Ctx.SetUserValue("id", "phone")
+
+	s.storage = autheliatest.NewMemoryStorageProvider()
+	s.mock.Ctx.Providers.StorageProvider = s.storage
+
+	s.Require().NoError(s.storage.SaveTOTPDevice(models.TOTPDevice{ID: "phone", Username: testUsername, Description: "Phone", Secret: "secret"}))
+	s.Require().NoError(s.storage.SaveTOTPDevice(models.TOTPDevice{ID: "tablet", Username: "harry", Description: "Tablet", Secret: "other"}))
+
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder(testUsername).TwoFactor(s.mock.Clock.Now()).Build())
+}
+
+func (s *UserTOTPDevicesSuite) TearDownTest() {
+	s.mock.Close()
+}
+
+func (s *UserTOTPDevicesSuite) loadDevices(username string) []models.TOTPDevice {
+	devices, err := s.storage.LoadTOTPDevices(username)
+	s.Require().NoError(err)
+
+	return devices
+}
+
+func (s *UserTOTPDevicesSuite) TestShouldRenameDevice() {
+	s.mock.Ctx.Request.SetBodyString(`{"description":"  Work phone "}`)
+
+	UserTOTPDevicePut(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), nil)
+	s.Assert().Equal("Work phone", s.loadDevices(testUsername)[0].Description)
+}
+
+func (s *UserTOTPDevicesSuite) TestShouldRefuseTooLongDescription() {
+	s.mock.Ctx.Request.SetBodyString(`{"description":"` + strings.Repeat("a", totpDescriptionMaxLength+1) + `"}`)
+
+	UserTOTPDevicePut(s.mock.Ctx)
+
+	s.mock.Assert200KO(s.T(), "Operation failed.")
+	s.Assert().Equal("Phone", s.loadDevices(testUsername)[0].Description)
+}
+
+func (s *UserTOTPDevicesSuite) TestShouldDeleteDevice() {
+	var published []events.Event
+
+	s.mock.Ctx.Providers.Events.Subscribe(func(event events.Event) {
+		published = append(published, event)
+	}, events.DeviceRevoked)
+
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder(testUsername).WithEmails("john@example.com").TwoFactor(s.mock.Clock.Now()).Build())
+
+	UserTOTPDeviceDelete(s.mock.Ctx)
+
+	s.mock.Assert200OK(s.T(), nil)
+	s.Assert().Len(s.loadDevices(testUsername), 0)
+
+	userEvents, err := s.storage.LoadUserEvents(testUsername, "", 10, 0)
+	s.Require().NoError(err)
+	s.Require().Len(userEvents, 1)
+	s.Assert().Equal(models.UserEventTOTPDeleted, userEvents[0].Type)
+
+	// The user is notified of the removal like of the registration of a device.
+	s.Require().Len(published, 1)
+	s.Assert().Equal(testUsername, published[0].Username)
+	s.Assert().Equal(deviceOneTimePassword, published[0].Details[events.DetailDevice])
+	s.Assert().Equal("john@example.com", published[0].Details[events.DetailEmail])
+	s.Assert().NotContains(published[0].Details, events.DetailActor)
+}
+
+func (s *UserTOTPDevicesSuite) TestShouldNotManageDeviceOfAnotherUser() {
+	s.mock.Ctx.SetUserValue("id", "tablet")
+
+	UserTOTPDeviceDelete(s.mock.Ctx)
+
+	s.mock.Assert200KO(s.T(), "Operation failed.")
+	s.Assert().Equal("TOTP device tablet is not registered by user john", s.mock.Hook.LastEntry().Message)
+	s.Assert().Len(s.loadDevices("harry"), 1)
+}
+
+func (s *UserTOTPDevicesSuite) TestShouldForbidUserWithoutSecondFactor() {
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder(testUsername).OneFactor(s.mock.Clock.Now()).Build())
+
+	UserTOTPDeviceDelete(s.mock.Ctx)
+
+	s.Assert().Equal(403, s.mock.Ctx.Response.StatusCode())
+	s.Assert().Len(s.loadDevices(testUsername), 1)
+}
+
+func TestRunUserTOTPDevicesSuite(t *testing.T) {
+	suite.Run(t, new(UserTOTPDevicesSuite))
+}
@@ -92,6 +92,11 @@ func verifyBasicAuth(header string, auth []byte, targetURL url.URL, ctx *middlew
 		return "", "", nil, nil, authentication.NotAuthenticated, fmt.Errorf("Unable to parse content of %s header: %s", header, err)
 	}
 
+	// The break-glass accounts must log in through the portal so their use is audited and needs the second factor.
+	if _, ok := breakGlassAccount(ctx, username); ok {
+		return "", "", nil, nil, authentication.NotAuthenticated, fmt.Errorf("Break-glass account %s can't use the %s header", username, header)
+	}
+
 	authenticated, err := ctx.Providers.UserProvider.CheckUserPassword(username, password)
 
 	if err != nil {
@@ -128,6 +133,25 @@ func setForwardedHeaders(headers *fasthttp.ResponseHeader, username, name string
 	}
 }
 
+// setRoleHeaders sets the role headers of the role mappings matching the target URL.
+func setRoleHeaders(ctx *middlewares.AutheliaCtx, targetURL *url.URL, username string, groups []string) {
+	if username == "" {
+		return
+	}
+
+	headers := ctx.Providers.Authorizer.GetRoleHeaders(
+		authorization.Subject{
+			Username: username,
+			Groups:   groups,
+			IP:       ctx.RemoteIP(),
+		},
+		authorization.NewObject(targetURL, ""))
+
+	for _, header := range headers {
+		ctx.Response.Header.Set(header.Name, header.Value)
+	}
+}
+
 // hasUserBeenInactiveTooLong checks whether the user has been inactive for too long.
 func hasUserBeenInactiveTooLong(ctx *middlewares.AutheliaCtx) (bool, error) { //nolint:unparam
 	maxInactivityPeriod := int64(ctx.Providers.SessionProvider.Inactivity.Seconds())
@@ -175,12 +199,43 @@ func verifySessionCookie(ctx *middlewares.AutheliaCtx, targetURL *url.URL, userS
 		}
 	}
 
+	if userSession.IsBreakGlassExpired(ctx.Clock.Now()) {
+		if err := ctx.Providers.SessionProvider.DestroySession(ctx.RequestCtx); err != nil {
+			return "", "", nil, nil, authentication.NotAuthenticated, fmt.Errorf("Unable to destroy user session after the break-glass window elapsed: %s", err)
+		}
+
+		return userSession.Username, userSession.DisplayName, userSession.Groups, userSession.Emails, authentication.NotAuthenticated, fmt.Errorf("The break-glass window of user %s has elapsed", userSession.Username)
+	}
+
+	if !isUserAnonymous && ctx.IsSessionRevoked(userSession) {
+		if err := ctx.Providers.SessionProvider.DestroySession(ctx.RequestCtx); err != nil {
+			return "", "", nil, nil, authentication.NotAuthenticated, fmt.Errorf("Unable to destroy the revoked user session: %s", err)
+		}
+
+		return userSession.Username, userSession.DisplayName, userSession.Groups, userSession.Emails, authentication.NotAuthenticated, errSessionRevoked
+	}
+
+	if !isUserAnonymous && ctx.IsSessionPinViolated(userSession) {
+		if err := ctx.Providers.SessionProvider.DestroySession(ctx.RequestCtx); err != nil {
+			return "", "", nil, nil, authentication.NotAuthenticated, fmt.Errorf("Unable to destroy user session pinned to another client: %s", err)
+		}
+
+		return userSession.Username, userSession.DisplayName, userSession.Groups, userSession.Emails, authentication.NotAuthenticated, fmt.Errorf("The session of user %s is pinned to another client", userSession.Username)
+	}
+
+	if !isUserAnonymous && ctx.IsSessionTravelImpossible(userSession) {
+		if err := ctx.Providers.SessionProvider.DestroySession(ctx.RequestCtx); err != nil {
+			return "", "", nil, nil, authentication.NotAuthenticated, fmt.Errorf("Unable to destroy user session used from distant locations: %s", err)
+		}
+
+		return userSession.Username, userSession.DisplayName, userSession.Groups, userSession.Emails, authentication.NotAuthenticated, fmt.Errorf("The session of user %s has been used from distant locations", userSession.Username)
+	}
+
 	err = verifySessionHasUpToDateProfile(ctx, targetURL, userSession, refreshProfile, refreshProfileInterval)
 	if err != nil {
-		if err == authentication.ErrUserNotFound {
-			err = ctx.Providers.SessionProvider.DestroySession(ctx.RequestCtx)
-			if err != nil {
-				ctx.Logger.Error(fmt.Errorf("Unable to destroy user session after provider refresh didn't find the user: %s", err))
+		if err == authentication.ErrUserNotFound || err == authentication.ErrAccountDisabled {
+			if destroyErr := ctx.Providers.SessionProvider.DestroySession(ctx.RequestCtx); destroyErr != nil {
+				ctx.Logger.Error(fmt.Errorf("Unable to destroy user session after provider refresh: %s", destroyErr))
 			}
 
 			return userSession.Username, userSession.DisplayName, userSession.Groups, userSession.Emails, authentication.NotAuthenticated, err
@@ -189,10 +244,19 @@ func verifySessionCookie(ctx *middlewares.AutheliaCtx, targetURL *url.URL, userS
 		ctx.Logger.Warnf("Error occurred while attempting to update user details from LDAP: %s", err)
 	}
 
-	return userSession.Username, userSession.DisplayName, userSession.Groups, userSession.Emails, userSession.AuthenticationLevel, nil
+	authLevel = userSession.AuthenticationLevel
+
+	// The user is not considered authenticated until the second factor required by the risk engine is completed.
+	if userSession.IsStepUpPending() {
+		ctx.Logger.Debugf("User %s is required to complete the second factor by the risk engine", userSession.Username)
+
+		authLevel = authentication.NotAuthenticated
+	}
+
+	return userSession.Username, userSession.DisplayName, userSession.Groups, userSession.Emails, authLevel, nil
 }
 
-func handleUnauthorized(ctx *middlewares.AutheliaCtx, targetURL fmt.Stringer, isBasicAuth bool, username string, method []byte) {
+func handleUnauthorized(ctx *middlewares.AutheliaCtx, targetURL *url.URL, isBasicAuth bool, username string, method []byte) {
 	friendlyUsername := "<anonymous>"
 	if username != "" {
 		friendlyUsername = username
@@ -219,12 +283,12 @@ func handleUnauthorized(ctx *middlewares.AutheliaCtx, targetURL fmt.Stringer, is
 	}
 
 	if rd != "" {
-		redirectionURL := ""
+		redirectionURL, err := loginRedirectionURL(ctx, rd, targetURL, rm)
+		if err != nil {
+			ctx.Logger.Errorf("Unable to render the login redirection URL of %s: %s", targetURL.String(), err)
+			ctx.ReplyUnauthorized()
 
-		if rm != "" {
-			redirectionURL = fmt.Sprintf("%s?rd=%s&rm=%s", rd, url.QueryEscape(targetURL.String()), rm)
-		} else {
-			redirectionURL = fmt.Sprintf("%s?rd=%s", rd, url.QueryEscape(targetURL.String()))
+			return
 		}
 
 		ctx.Logger.Infof("Access to %s (method %s) is not authorized to user %s, redirecting to %s", targetURL.String(), friendlyMethod, friendlyUsername, redirectionURL)
@@ -425,6 +489,13 @@ func VerifyGet(cfg schema.AuthenticationBackendConfiguration) middlewares.Reques
 
 	return func(ctx *middlewares.AutheliaCtx) {
 		ctx.Logger.Tracef("Headers=%s", ctx.Request.Header.String())
+
+		if !isProxyAuthenticated(ctx) {
+			ctx.ReplyForbidden()
+
+			return
+		}
+
 		targetURL, err := ctx.GetOriginalURL()
 
 		if err != nil {
@@ -470,6 +541,11 @@ func VerifyGet(cfg schema.AuthenticationBackendConfiguration) middlewares.Reques
 		authorized := isTargetURLAuthorized(ctx.Providers.Authorizer, *targetURL, username,
 			groups, ctx.RemoteIP(), method, authLevel)
 
+		// A guest given an access grant reaches the resource without any identity.
+		if authorized != Authorized && isAccessGranted(ctx, targetURL, method) {
+			return
+		}
+
 		switch authorized {
 		case Forbidden:
 			ctx.Logger.Infof("Access to %s is forbidden to user %s", targetURL.String(), username)
@@ -478,6 +554,8 @@ func VerifyGet(cfg schema.AuthenticationBackendConfiguration) middlewares.Reques
 			handleUnauthorized(ctx, targetURL, isBasicAuth, username, method)
 		case Authorized:
 			setForwardedHeaders(&ctx.Response.Header, username, name, groups, emails)
+			setRoleHeaders(ctx, targetURL, username, groups)
+			setIdentityAssertionHeader(ctx, targetURL, username, name, groups, emails)
 		}
 
 		if err := updateActivityTimestamp(ctx, isBasicAuth, username); err != nil {
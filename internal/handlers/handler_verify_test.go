@@ -19,6 +19,7 @@ import (
 	"github.com/authelia/authelia/internal/mocks"
 	"github.com/authelia/authelia/internal/session"
 	"github.com/authelia/authelia/internal/utils"
+	"github.com/authelia/authelia/pkg/autheliatest"
 )
 
 var verifyGetCfg = schema.AuthenticationBackendConfiguration{
@@ -562,6 +563,128 @@ func TestShouldVerifyAuthorizationsUsingSessionCookie(t *testing.T) {
 	}
 }
 
+func TestShouldForwardRoleHeadersOfMatchingRoleMappings(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	mock.Clock.Set(time.Now())
+
+	mock.Ctx.Configuration.AccessControl.RoleMappings = []schema.ACLRoleMapping{
+		{
+			Domains: []string{"one-factor.example.com"},
+			Header:  "X-Grafana-Role",
+			Default: "Viewer",
+			Roles:   []schema.ACLRole{{Group: "admins", Role: "Admin"}},
+		},
+		{
+			Domains: []string{"two-factor.example.com"},
+			Header:  "X-Wiki-Role",
+			Roles:   []schema.ACLRole{{Group: "admins", Role: "editor"}},
+		},
+	}
+	mock.Ctx.Providers.Authorizer = authorization.NewAuthorizer(&mock.Ctx.Configuration)
+
+	userSession := mock.Ctx.GetSession()
+	userSession.Username = testUsername
+	userSession.Groups = []string{"dev", "admins"}
+	userSession.AuthenticationLevel = authentication.OneFactor
+	userSession.RefreshTTL = mock.Clock.Now().Add(5 * time.Minute)
+
+	err := mock.Ctx.SaveSession(userSession)
+	require.NoError(t, err)
+
+	mock.Ctx.Request.Header.Set("X-Original-URL", "https://one-factor.example.com")
+
+	VerifyGet(verifyGetCfg)(mock.Ctx)
+
+	assert.Equal(t, 200, mock.Ctx.Response.StatusCode())
+	assert.Equal(t, []byte("dev,admins"), mock.Ctx.Response.Header.Peek("Remote-Groups"))
+	assert.Equal(t, []byte("Admin"), mock.Ctx.Response.Header.Peek("X-Grafana-Role"))
+	assert.Equal(t, []byte(nil), mock.Ctx.Response.Header.Peek("X-Wiki-Role"))
+}
+
+func TestShouldRequireProxySharedSecret(t *testing.T) {
+	testCases := []struct {
+		name           string
+		secret         string
+		expectedStatus int
+	}{
+		{"ShouldRejectMissingSecret", "", 403},
+		{"ShouldRejectInvalidSecret", "an_invalid_proxy_secret", 403},
+		{"ShouldAcceptCurrentSecret", "the_current_proxy_secret", 200},
+		{"ShouldAcceptPreviousSecret", "the_previous_proxy_secret", 200},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			mock := mocks.NewMockAutheliaCtx(t)
+			defer mock.Close()
+
+			mock.Ctx.Configuration.ProxyAuthentication = &schema.ProxyAuthenticationConfiguration{
+				Header: "Authelia-Proxy-Secret",
+				Rules: []schema.ProxyAuthenticationRule{
+					{Networks: []string{"10.0.0.0/8"}, Secrets: []string{"a_proxy_secret_of_other_proxies"}},
+					{Secrets: []string{"the_current_proxy_secret", "the_previous_proxy_secret"}},
+				},
+			}
+			mock.Ctx.Providers.Authorizer = authorization.NewAuthorizer(&mock.Ctx.Configuration)
+
+			mock.Ctx.Request.Header.Set("X-Original-URL", "https://bypass.example.com")
+
+			if tc.secret != "" {
+				mock.Ctx.Request.Header.Set("Authelia-Proxy-Secret", tc.secret)
+			}
+
+			VerifyGet(verifyGetCfg)(mock.Ctx)
+
+			assert.Equal(t, tc.expectedStatus, mock.Ctx.Response.StatusCode())
+
+			if tc.expectedStatus == 403 {
+				entry := mock.Hook.LastEntry()
+				require.NotNil(t, entry)
+				assert.Equal(t, "Rejected a request to the verify endpoint which doesn't come from an authenticated proxy", entry.Message)
+			}
+		})
+	}
+}
+
+func TestShouldRequireSecondFactorWhenStepUpIsPending(t *testing.T) {
+	testCases := []Pair{
+		{"https://bypass.example.com", "john", []string{"john.doe@example.com"}, authentication.OneFactor, 200},
+		{"https://one-factor.example.com", "john", []string{"john.doe@example.com"}, authentication.OneFactor, 401},
+		{"https://two-factor.example.com", "john", []string{"john.doe@example.com"}, authentication.OneFactor, 401},
+		{"https://one-factor.example.com", "john", []string{"john.doe@example.com"}, authentication.TwoFactor, 200},
+		{"https://two-factor.example.com", "john", []string{"john.doe@example.com"}, authentication.TwoFactor, 200},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.String(), func(t *testing.T) {
+			mock := mocks.NewMockAutheliaCtx(t)
+			defer mock.Close()
+
+			mock.Clock.Set(time.Now())
+
+			userSession := mock.Ctx.GetSession()
+			userSession.Username = testCase.Username
+			userSession.Emails = testCase.Emails
+			userSession.AuthenticationLevel = testCase.AuthenticationLevel
+			userSession.StepUpRequired = true
+			userSession.RefreshTTL = mock.Clock.Now().Add(5 * time.Minute)
+
+			err := mock.Ctx.SaveSession(userSession)
+			require.NoError(t, err)
+
+			mock.Ctx.Request.Header.Set("X-Original-URL", testCase.URL)
+
+			VerifyGet(verifyGetCfg)(mock.Ctx)
+			assert.Equal(t, testCase.ExpectedStatusCode, mock.Ctx.Response.StatusCode())
+		})
+	}
+}
+
 func TestShouldDestroySessionWhenInactiveForTooLong(t *testing.T) {
 	mock := mocks.NewMockAutheliaCtx(t)
 	defer mock.Close()
@@ -785,6 +908,77 @@ func TestShouldURLEncodeRedirectionURLParameter(t *testing.T) {
 		string(mock.Ctx.Response.Body()))
 }
 
+func TestShouldRedirectWithLoginURLTemplate(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	mock.Clock.Set(time.Now())
+	mock.Ctx.Configuration.Redirection.LoginURLTemplate = "{{ .PortalURL }}/login/{{ base64url .TargetURL }}?host={{ queryEscape .Target.Host }}"
+
+	userSession := mock.Ctx.GetSession()
+	userSession.Username = testUsername
+	userSession.AuthenticationLevel = authentication.NotAuthenticated
+	userSession.RefreshTTL = mock.Clock.Now().Add(5 * time.Minute)
+
+	err := mock.Ctx.SaveSession(userSession)
+	require.NoError(t, err)
+
+	mock.Ctx.Request.Header.Set("X-Original-URL", "https://two-factor.example.com")
+	mock.Ctx.Request.SetHost("mydomain.com")
+	mock.Ctx.Request.SetRequestURI("/?rd=https://auth.mydomain.com")
+
+	VerifyGet(verifyGetCfg)(mock.Ctx)
+
+	assert.Equal(t, 302, mock.Ctx.Response.StatusCode())
+	assert.Equal(t, "Found. Redirecting to https://auth.mydomain.com/login/aHR0cHM6Ly90d28tZmFjdG9yLmV4YW1wbGUuY29t?host=two-factor.example.com",
+		string(mock.Ctx.Response.Body()))
+}
+
+func TestShouldRedirectWithSignedState(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	mock.Clock.Set(time.Now())
+	mock.Ctx.Clock = &mock.Clock
+	mock.Ctx.Configuration.JWTSecret = "a_secret"
+	mock.Ctx.Configuration.Redirection.SignedState = true
+	mock.Ctx.Configuration.Redirection.StateLifespan = "10m"
+	mock.Ctx.Providers.StorageProvider = autheliatest.NewMemoryStorageProvider()
+
+	mock.Ctx.Request.Header.Set("X-Original-URL", "https://two-factor.example.com")
+	mock.Ctx.Request.SetHost("mydomain.com")
+	mock.Ctx.Request.SetRequestURI("/?rd=https://auth.mydomain.com")
+
+	VerifyGet(verifyGetCfg)(mock.Ctx)
+
+	assert.Equal(t, 302, mock.Ctx.Response.StatusCode())
+
+	location, err := url.Parse(string(mock.Ctx.Response.Header.Peek("Location")))
+	require.NoError(t, err)
+	assert.Equal(t, "https://two-factor.example.com", location.Query().Get("rd"))
+
+	state := location.Query().Get("state")
+	require.NotEmpty(t, state)
+
+	assert.NoError(t, consumeLoginState(mock.Ctx, state, "https://two-factor.example.com"))
+}
+
+func TestShouldReplyUnauthorizedWhenLoginURLTemplateFails(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	mock.Clock.Set(time.Now())
+	mock.Ctx.Configuration.Redirection.LoginURLTemplate = "{{ .PortalURL }}?rd={{ .Unknown }}"
+
+	mock.Ctx.Request.Header.Set("X-Original-URL", "https://two-factor.example.com")
+	mock.Ctx.Request.SetHost("mydomain.com")
+	mock.Ctx.Request.SetRequestURI("/?rd=https://auth.mydomain.com")
+
+	VerifyGet(verifyGetCfg)(mock.Ctx)
+
+	assert.Equal(t, 401, mock.Ctx.Response.StatusCode())
+}
+
 func TestIsDomainProtected(t *testing.T) {
 	GetURL := func(u string) *url.URL {
 		x, err := url.ParseRequestURI(u)
@@ -1013,6 +1207,68 @@ func TestShouldDestroySessionWhenUserNotExist(t *testing.T) {
 	assert.Equal(t, authentication.NotAuthenticated, userSession.AuthenticationLevel)
 }
 
+func TestShouldDestroySessionWhenSessionsRevoked(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	mock.Clock.Set(time.Now())
+
+	userSession := mock.Ctx.GetSession()
+	userSession.Username = testUsername
+	userSession.AuthenticationLevel = authentication.TwoFactor
+	userSession.FirstFactorAuthnTimestamp = mock.Clock.Now().Add(-time.Hour).Unix()
+	userSession.LastActivity = mock.Clock.Now().Unix()
+	// The revocation is checked even when the profile of the user doesn't need to be refreshed.
+	userSession.RefreshTTL = mock.Clock.Now().Add(time.Hour)
+	userSession.KeepMeLoggedIn = true
+	err := mock.Ctx.SaveSession(userSession)
+
+	require.NoError(t, err)
+
+	storageProvider := autheliatest.NewMemoryStorageProvider()
+	require.NoError(t, storageProvider.RevokeSessions(testUsername, mock.Clock.Now().Add(-time.Minute)))
+	mock.Ctx.Providers.StorageProvider = storageProvider
+
+	mock.Ctx.Request.Header.Set("X-Original-URL", "https://two-factor.example.com")
+
+	VerifyGet(verifyGetCfg)(mock.Ctx)
+
+	assert.Equal(t, 401, mock.Ctx.Response.StatusCode())
+
+	userSession = mock.Ctx.GetSession()
+	assert.Equal(t, "", userSession.Username)
+	assert.Equal(t, authentication.NotAuthenticated, userSession.AuthenticationLevel)
+}
+
+func TestShouldDestroySessionPinnedToAnotherClient(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	mock.Clock.Set(time.Now())
+	mock.Ctx.Configuration.Session.Pinning = schema.SessionPinningConfiguration{IP: true, Strictness: "strict"}
+
+	userSession := mock.Ctx.GetSession()
+	userSession.Username = testUsername
+	userSession.AuthenticationLevel = authentication.TwoFactor
+	userSession.LastActivity = mock.Clock.Now().Unix()
+	userSession.KeepMeLoggedIn = true
+	userSession.Pin = &session.Pin{IPNetwork: "192.168.1.0"}
+	err := mock.Ctx.SaveSession(userSession)
+
+	require.NoError(t, err)
+
+	mock.Ctx.Request.Header.Set("X-Forwarded-For", "10.0.0.5")
+	mock.Ctx.Request.Header.Set("X-Original-URL", "https://two-factor.example.com")
+
+	VerifyGet(verifyGetCfg)(mock.Ctx)
+
+	assert.Equal(t, 401, mock.Ctx.Response.StatusCode())
+
+	userSession = mock.Ctx.GetSession()
+	assert.Equal(t, "", userSession.Username)
+	assert.Equal(t, authentication.NotAuthenticated, userSession.AuthenticationLevel)
+}
+
 func TestShouldGetRemovedUserGroupsFromBackend(t *testing.T) {
 	mock := mocks.NewMockAutheliaCtx(t)
 	defer mock.Close()
@@ -1143,6 +1399,7 @@ func TestShouldGetAddedUserGroupsFromBackend(t *testing.T) {
 
 	mock = mocks.NewMockAutheliaCtx(t)
 	defer mock.Close()
+
 	err = mock.Ctx.SaveSession(userSession)
 	assert.NoError(t, err)
 
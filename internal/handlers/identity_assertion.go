@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/google/uuid"
+	"github.com/ory/fosite/token/jwt"
+
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// setIdentityAssertionHeader sets the header holding a short-lived JWT signed with the OpenID Connect issuer key which
+// asserts the identity of the user to the application of the target URL. The applications can validate it with the
+// JWKS of the OpenID Connect provider to make sure the identity headers weren't injected.
+func setIdentityAssertionHeader(ctx *middlewares.AutheliaCtx, targetURL *url.URL, username, name string, groups, emails []string) {
+	config := ctx.Configuration.IdentityAssertion
+
+	if config == nil || username == "" {
+		return
+	}
+
+	if ctx.Providers.OpenIDConnect.KeyManager == nil {
+		ctx.Logger.Errorf("Unable to sign the identity assertion of user %s: the OpenID Connect provider isn't configured", username)
+		return
+	}
+
+	lifespan, err := utils.ParseDurationString(config.Lifespan)
+	if err != nil {
+		ctx.Logger.Errorf("Unable to sign the identity assertion of user %s: %s", username, err)
+		return
+	}
+
+	now := ctx.Clock.Now()
+
+	claims := jwt.MapClaims{
+		"jti":    uuid.New().String(),
+		"iss":    config.Issuer,
+		"sub":    username,
+		"aud":    []string{fmt.Sprintf("%s://%s", targetURL.Scheme, targetURL.Host)},
+		"iat":    now.Unix(),
+		"nbf":    now.Unix(),
+		"exp":    now.Add(lifespan).Unix(),
+		"name":   name,
+		"groups": groups,
+	}
+
+	if len(emails) != 0 {
+		claims["email"] = emails[0]
+	}
+
+	strategy := ctx.Providers.OpenIDConnect.KeyManager.Strategy()
+
+	token, _, err := strategy.Generate(ctx, claims, &jwt.Headers{
+		Extra: map[string]interface{}{"kid": strategy.KeyID()},
+	})
+	if err != nil {
+		ctx.Logger.Errorf("Unable to sign the identity assertion of user %s: %s", username, err)
+		return
+	}
+
+	ctx.Response.Header.Set(config.Header, token)
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/square/go-jose.v2/jwt"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/internal/oidc"
+)
+
+type identityAssertionClaims struct {
+	jwt.Claims
+
+	Name   string   `json:"name"`
+	Email  string   `json:"email"`
+	Groups []string `json:"groups"`
+}
+
+func TestShouldForwardSignedIdentityAssertion(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	mock.Clock.Set(time.Now())
+	mock.Ctx.Clock = &mock.Clock
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	keyManager := oidc.NewKeyManager()
+	_, err = keyManager.AddActivePrivateKey(key)
+	require.NoError(t, err)
+
+	mock.Ctx.Providers.OpenIDConnect.KeyManager = keyManager
+	mock.Ctx.Configuration.IdentityAssertion = &schema.IdentityAssertionConfiguration{
+		Header:   "Remote-Assertion",
+		Issuer:   "https://auth.example.com",
+		Lifespan: "1m",
+	}
+
+	userSession := mock.Ctx.GetSession()
+	userSession.Username = testUsername
+	userSession.DisplayName = "John Doe"
+	userSession.Emails = []string{"john.doe@example.com"}
+	userSession.Groups = []string{"dev"}
+	userSession.AuthenticationLevel = authentication.OneFactor
+	userSession.RefreshTTL = mock.Clock.Now().Add(5 * time.Minute)
+
+	require.NoError(t, mock.Ctx.SaveSession(userSession))
+
+	mock.Ctx.Request.Header.Set("X-Original-URL", "https://one-factor.example.com/path")
+
+	VerifyGet(verifyGetCfg)(mock.Ctx)
+
+	require.Equal(t, 200, mock.Ctx.Response.StatusCode())
+
+	token, err := jwt.ParseSigned(string(mock.Ctx.Response.Header.Peek("Remote-Assertion")))
+	require.NoError(t, err)
+	require.Len(t, token.Headers, 1)
+	assert.Equal(t, keyManager.GetActiveKeyID(), token.Headers[0].KeyID)
+
+	claims := identityAssertionClaims{}
+	require.NoError(t, token.Claims(&key.PublicKey, &claims))
+
+	assert.NoError(t, claims.ValidateWithLeeway(jwt.Expected{
+		Issuer:   "https://auth.example.com",
+		Subject:  testUsername,
+		Audience: jwt.Audience{"https://one-factor.example.com"},
+		Time:     mock.Clock.Now(),
+	}, 0))
+	assert.Equal(t, "John Doe", claims.Name)
+	assert.Equal(t, "john.doe@example.com", claims.Email)
+	assert.Equal(t, []string{"dev"}, claims.Groups)
+	assert.Equal(t, mock.Clock.Now().Add(time.Minute).Unix(), claims.Expiry.Time().Unix())
+}
+
+func TestShouldNotForwardIdentityAssertionWithoutKeyManager(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	mock.Clock.Set(time.Now())
+
+	mock.Ctx.Configuration.IdentityAssertion = &schema.IdentityAssertionConfiguration{
+		Header:   "Remote-Assertion",
+		Lifespan: "1m",
+	}
+
+	userSession := mock.Ctx.GetSession()
+	userSession.Username = testUsername
+	userSession.AuthenticationLevel = authentication.OneFactor
+	userSession.RefreshTTL = mock.Clock.Now().Add(5 * time.Minute)
+
+	require.NoError(t, mock.Ctx.SaveSession(userSession))
+
+	mock.Ctx.Request.Header.Set("X-Original-URL", "https://one-factor.example.com")
+
+	VerifyGet(verifyGetCfg)(mock.Ctx)
+
+	assert.Equal(t, 200, mock.Ctx.Response.StatusCode())
+	assert.Nil(t, mock.Ctx.Response.Header.Peek("Remote-Assertion"))
+}
@@ -4,8 +4,10 @@ import (
 	"github.com/ory/fosite/handler/openid"
 	"github.com/ory/fosite/token/jwt"
 
+	"github.com/authelia/authelia/internal/middlewares"
 	"github.com/authelia/authelia/internal/oidc"
 	"github.com/authelia/authelia/internal/session"
+	"github.com/authelia/authelia/internal/storage"
 	"github.com/authelia/authelia/internal/utils"
 )
 
@@ -20,6 +22,33 @@ func isConsentMissing(workflow *session.OIDCWorkflowSession, requestedScopes, re
 		len(requestedAudience) > 0 && utils.IsStringSlicesDifferentFold(requestedAudience, workflow.GrantedAudience)
 }
 
+// isConsentRemembered returns true if the user remembered their consent to the client and the remembered consent covers
+// all the requestedScopes and requestedAudience, the consent isn't asked again in this case.
+func isConsentRemembered(ctx *middlewares.AutheliaCtx, username, clientID string, requestedScopes, requestedAudience []string) bool {
+	consent, err := ctx.Providers.StorageProvider.LoadOIDCConsent(username, clientID, ctx.Clock.Now())
+	if err != nil {
+		if err != storage.ErrNoOIDCConsent {
+			ctx.Logger.Errorf("Unable to load the consent of user %s to client %s: %s", username, clientID, err)
+		}
+
+		return false
+	}
+
+	for _, scope := range requestedScopes {
+		if !utils.IsStringInSlice(scope, consent.Scopes) {
+			return false
+		}
+	}
+
+	for _, audience := range requestedAudience {
+		if !utils.IsStringInSliceFold(audience, consent.Audience) {
+			return false
+		}
+	}
+
+	return true
+}
+
 func newOpenIDSession(subject string) *oidc.OpenIDSession {
 	return &oidc.OpenIDSession{
 		DefaultSession: &openid.DefaultSession{
@@ -6,7 +6,7 @@ import (
 	"github.com/authelia/authelia/internal/middlewares"
 )
 
-// RegisterOIDC registers the handlers with the fasthttp *router.Router. TODO: Add paths for UserInfo, Flush, Logout.
+// RegisterOIDC registers the handlers with the fasthttp *router.Router. TODO: Add paths for Flush.
 func RegisterOIDC(router *router.Router, middleware middlewares.RequestHandlerBridge) {
 	// TODO: Add OPTIONS handler.
 	router.GET("/.well-known/openid-configuration", middleware(oidcWellKnown))
@@ -27,6 +27,9 @@ func RegisterOIDC(router *router.Router, middleware middlewares.RequestHandlerBr
 	router.GET(oidcUserinfoPath, middleware(middlewares.NewHTTPToAutheliaHandlerAdaptor(oidcUserinfo)))
 	router.POST(oidcUserinfoPath, middleware(middlewares.NewHTTPToAutheliaHandlerAdaptor(oidcUserinfo)))
 
+	router.GET(oidcLogoutPath, middleware(oidcLogout))
+	router.POST(oidcLogoutPath, middleware(oidcLogout))
+
 	// TODO: Add OPTIONS handler.
 	router.POST(oidcRevokePath, middleware(middlewares.NewHTTPToAutheliaHandlerAdaptor(oidcRevoke)))
 }
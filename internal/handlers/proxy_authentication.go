@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"crypto/subtle"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/authelia/authelia/internal/middlewares"
+)
+
+// isProxyAuthenticated determines if the reverse proxy calling the verify endpoint sent one of the shared secrets
+// required from its source network. The source is the address of the connection since the proxy is the direct peer.
+func isProxyAuthenticated(ctx *middlewares.AutheliaCtx) bool {
+	config := ctx.Configuration.ProxyAuthentication
+	if config == nil {
+		return true
+	}
+
+	sourceIP := ctx.RequestCtx.RemoteIP()
+
+	secrets, required := ctx.Providers.Authorizer.GetProxySecrets(sourceIP)
+	if !required {
+		return true
+	}
+
+	value := ctx.Request.Header.Peek(config.Header)
+
+	if len(value) != 0 {
+		for _, secret := range secrets {
+			if subtle.ConstantTimeCompare(value, []byte(secret)) == 1 {
+				return true
+			}
+		}
+	}
+
+	reason := "invalid shared secret"
+	if len(value) == 0 {
+		reason = "missing shared secret"
+	}
+
+	ctx.Logger.WithFields(logrus.Fields{
+		"source_ip": sourceIP.String(),
+		"header":    config.Header,
+		"reason":    reason,
+	}).Warn("Rejected a request to the verify endpoint which doesn't come from an authenticated proxy")
+
+	return false
+}
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/sirupsen/logrus"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/storage"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+var (
+	errRedirectionNotRegistered = errors.New("redirection URL isn't registered for the client")
+
+	errLoginStateMissing        = errors.New("login state is missing")
+	errLoginStateExpired        = errors.New("login state has expired")
+	errLoginStateTargetMismatch = errors.New("login state was issued for another target")
+	errLoginStateAlreadyUsed    = errors.New("login state has expired or has already been used")
+)
+
+// loginStateClaims are the claims of the signed state of the login flow. The state binds the target URL the user was
+// redirected to the portal for to a single use nonce, so the target can't be tampered with nor the state replayed.
+type loginStateClaims struct {
+	jwt.StandardClaims
+
+	TargetURL string `json:"target_url"`
+	Method    string `json:"method,omitempty"`
+}
+
+// redirectionAllowedDomains returns the domains the users can be redirected to, i.e. the protected domain and the
+// allowed domains.
+func redirectionAllowedDomains(ctx *middlewares.AutheliaCtx) []string {
+	return append([]string{ctx.Configuration.Session.Domain}, ctx.Configuration.Redirection.AllowedDomains...)
+}
+
+// isRedirectionSafe determines if the user can be redirected to the target URL in the given flow, the rejected targets
+// are logged.
+func isRedirectionSafe(ctx *middlewares.AutheliaCtx, flow string, targetURL url.URL) bool {
+	if err := utils.CheckRedirection(targetURL, redirectionAllowedDomains(ctx)); err != nil {
+		logRejectedRedirection(ctx, flow, targetURL.String(), err)
+
+		return false
+	}
+
+	return true
+}
+
+func logRejectedRedirection(ctx *middlewares.AutheliaCtx, flow, target string, reason error) {
+	ctx.Logger.WithFields(logrus.Fields{
+		"flow":   flow,
+		"target": target,
+		"reason": reason.Error(),
+	}).Warn("Rejected an unsafe redirection target")
+}
+
+// safeRedirectionURI returns the URI to redirect the user to once authenticated, without the parameters which must be
+// stripped.
+func safeRedirectionURI(ctx *middlewares.AutheliaCtx, targetURL *url.URL) string {
+	if len(ctx.Configuration.Redirection.StripParameters) == 0 {
+		return targetURL.String()
+	}
+
+	strippedURL := *targetURL
+	utils.StripRedirectionParameters(&strippedURL, ctx.Configuration.Redirection.StripParameters)
+
+	return strippedURL.String()
+}
+
+// loginRedirectionURL returns the URL of the login portal the unauthenticated users are redirected to.
+func loginRedirectionURL(ctx *middlewares.AutheliaCtx, portalURL string, targetURL *url.URL, method string) (string, error) {
+	loginURLTemplate := ctx.Configuration.Redirection.LoginURLTemplate
+	if loginURLTemplate == "" {
+		loginURLTemplate = schema.DefaultRedirectionConfiguration.LoginURLTemplate
+	}
+
+	var state string
+
+	if ctx.Configuration.Redirection.SignedState {
+		var err error
+
+		if state, err = newLoginState(ctx, targetURL.String(), method); err != nil {
+			return "", err
+		}
+	}
+
+	return utils.ExecuteRedirectionTemplate(loginURLTemplate, utils.RedirectionTemplateData{
+		PortalURL: portalURL,
+		TargetURL: targetURL.String(),
+		Target:    targetURL,
+		Method:    method,
+		State:     state,
+	})
+}
+
+// newLoginState signs the state of a login flow to the target URL and saves its nonce until it expires.
+func newLoginState(ctx *middlewares.AutheliaCtx, targetURL, method string) (string, error) {
+	lifespan, err := utils.ParseDurationString(ctx.Configuration.Redirection.StateLifespan)
+	if err != nil || lifespan <= 0 {
+		lifespan, _ = utils.ParseDurationString(schema.DefaultRedirectionConfiguration.StateLifespan)
+	}
+
+	nonce, err := utils.RandomSecureString(loginStateNonceLength, utils.AlphaNumericCharacters)
+	if err != nil {
+		return "", fmt.Errorf("unable to generate the nonce of the login state: %w", err)
+	}
+
+	expiresAt := ctx.Clock.Now().Add(lifespan)
+
+	claims := loginStateClaims{
+		StandardClaims: jwt.StandardClaims{
+			Id:        nonce,
+			ExpiresAt: expiresAt.Unix(),
+		},
+		TargetURL: targetURL,
+		Method:    method,
+	}
+
+	state, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(ctx.Configuration.JWTSecret))
+	if err != nil {
+		return "", fmt.Errorf("unable to sign the login state: %w", err)
+	}
+
+	if err = ctx.Providers.StorageProvider.SaveLoginState(nonce, time.Unix(claims.ExpiresAt, 0)); err != nil {
+		return "", fmt.Errorf("unable to save the nonce of the login state: %w", err)
+	}
+
+	return state, nil
+}
+
+// isLoginStateValid determines if the state sent back by the portal was signed for the target URL, its nonce is
+// consumed so the state can only be used once. The rejected states are logged. Any state is valid when the signed state
+// is disabled.
+func isLoginStateValid(ctx *middlewares.AutheliaCtx, state, targetURI string) bool {
+	if !ctx.Configuration.Redirection.SignedState {
+		return true
+	}
+
+	if err := consumeLoginState(ctx, state, targetURI); err != nil {
+		logRejectedRedirection(ctx, redirectionFlowLogin, targetURI, err)
+
+		return false
+	}
+
+	return true
+}
+
+func consumeLoginState(ctx *middlewares.AutheliaCtx, state, targetURI string) error {
+	if state == "" {
+		return errLoginStateMissing
+	}
+
+	// The expiration is verified against the clock of the context rather than the one of the JWT library.
+	parser := jwt.Parser{
+		ValidMethods:         []string{jwt.SigningMethodHS256.Alg()},
+		SkipClaimsValidation: true,
+	}
+
+	claims := &loginStateClaims{}
+
+	if _, err := parser.ParseWithClaims(state, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(ctx.Configuration.JWTSecret), nil
+	}); err != nil {
+		return fmt.Errorf("login state is invalid: %w", err)
+	}
+
+	now := ctx.Clock.Now()
+
+	if !claims.VerifyExpiresAt(now.Unix(), true) {
+		return errLoginStateExpired
+	}
+
+	if claims.TargetURL != targetURI {
+		return errLoginStateTargetMismatch
+	}
+
+	if err := ctx.Providers.StorageProvider.ConsumeLoginState(claims.Id, now); err != nil {
+		if err == storage.ErrNoLoginState {
+			return errLoginStateAlreadyUsed
+		}
+
+		return fmt.Errorf("unable to consume the nonce of the login state: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/authorization"
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/pkg/autheliatest"
+)
+
+func TestShouldStripParametersOfSafeRedirection(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	mock.Ctx.Configuration.Session.Domain = "example.com"
+	mock.Ctx.Configuration.Redirection.StripParameters = []string{"token"}
+
+	Handle2FAResponse(mock.Ctx, "https://home.example.com/path?token=secret&page=2", "")
+
+	mock.Assert200OK(t, redirectResponse{
+		Redirect: "https://home.example.com/path?page=2",
+	})
+}
+
+func TestShouldRedirectToAllowedDomains(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	mock.Ctx.Configuration.Session.Domain = "example.com"
+	mock.Ctx.Configuration.Redirection.AllowedDomains = []string{"example.org"}
+
+	Handle2FAResponse(mock.Ctx, "https://app.example.org/", "")
+
+	mock.Assert200OK(t, redirectResponse{
+		Redirect: "https://app.example.org/",
+	})
+}
+
+func TestShouldNotRedirectOutsideAllowedDomains(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	mock.Ctx.Configuration.Session.Domain = "example.com"
+	mock.Ctx.Configuration.Redirection.AllowedDomains = []string{"example.org"}
+
+	Handle2FAResponse(mock.Ctx, "https://home.example.net/", "")
+
+	mock.Assert200OK(t, nil)
+
+	entry := mock.Hook.LastEntry()
+	require.NotNil(t, entry)
+	assert.Equal(t, logrus.WarnLevel, entry.Level)
+	assert.Equal(t, "Rejected an unsafe redirection target", entry.Message)
+	assert.Equal(t, "login", entry.Data["flow"])
+	assert.Equal(t, "https://home.example.net/", entry.Data["target"])
+	assert.Equal(t, "redirection URL domain isn't allowed", entry.Data["reason"])
+}
+
+func TestShouldRewriteUnsafeRedirectionToDefaultRedirectionURL(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	mock.Ctx.Configuration.Session.Domain = "example.com"
+	mock.Ctx.Configuration.DefaultRedirectionURL = "https://home.example.com/"
+
+	Handle2FAResponse(mock.Ctx, "http://app.example.com/", "")
+
+	mock.Assert200OK(t, redirectResponse{
+		Redirect: "https://home.example.com/",
+	})
+
+	entry := mock.Hook.LastEntry()
+	require.NotNil(t, entry)
+	assert.Equal(t, "redirection URL scheme isn't https", entry.Data["reason"])
+}
+
+func TestShouldRedirectToDefaultRedirectionURLOfTheGroups(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	mock.Ctx.Configuration.DefaultRedirectionURL = "https://home.example.com/"
+	mock.Ctx.Providers.Authorizer = authorization.NewAuthorizer(&schema.Configuration{
+		Redirection: schema.RedirectionConfiguration{
+			DefaultURLs: []schema.RedirectionDefaultURLConfiguration{
+				{URL: "https://grafana.example.com/", Subjects: [][]string{{"group:ops"}}},
+			},
+		},
+	})
+
+	mock.SetUserSession(t, autheliatest.NewUserSessionBuilder(testUsername).WithGroups("dev", "ops").TwoFactor(mock.Clock.Now()).Build())
+
+	Handle2FAResponse(mock.Ctx, "", "")
+
+	mock.Assert200OK(t, redirectResponse{
+		Redirect: "https://grafana.example.com/",
+	})
+
+	mock.Ctx.Response.Reset()
+	mock.SetUserSession(t, autheliatest.NewUserSessionBuilder("harry").WithGroups("dev").TwoFactor(mock.Clock.Now()).Build())
+
+	Handle2FAResponse(mock.Ctx, "", "")
+
+	mock.Assert200OK(t, redirectResponse{
+		Redirect: "https://home.example.com/",
+	})
+}
+
+func newSignedStateMockAutheliaCtx(t *testing.T) *mocks.MockAutheliaCtx {
+	mock := mocks.NewMockAutheliaCtx(t)
+
+	mock.Clock.Set(time.Unix(1640000000, 0))
+	mock.Ctx.Clock = &mock.Clock
+	mock.Ctx.Configuration.JWTSecret = "a_secret"
+	mock.Ctx.Configuration.Session.Domain = "example.com"
+	mock.Ctx.Configuration.Redirection.SignedState = true
+	mock.Ctx.Configuration.Redirection.StateLifespan = "10m"
+	mock.Ctx.Providers.StorageProvider = autheliatest.NewMemoryStorageProvider()
+
+	return mock
+}
+
+func TestShouldRedirectWithSignedStateOnlyOnce(t *testing.T) {
+	mock := newSignedStateMockAutheliaCtx(t)
+	defer mock.Close()
+
+	state, err := newLoginState(mock.Ctx, "https://home.example.com/", "GET")
+	require.NoError(t, err)
+
+	Handle2FAResponse(mock.Ctx, "https://home.example.com/", state)
+
+	mock.Assert200OK(t, redirectResponse{
+		Redirect: "https://home.example.com/",
+	})
+
+	assert.Equal(t, errLoginStateAlreadyUsed, consumeLoginState(mock.Ctx, state, "https://home.example.com/"))
+}
+
+func TestShouldRejectRedirectionWithInvalidSignedState(t *testing.T) {
+	mock := newSignedStateMockAutheliaCtx(t)
+	defer mock.Close()
+
+	state, err := newLoginState(mock.Ctx, "https://home.example.com/", "GET")
+	require.NoError(t, err)
+
+	Handle2FAResponse(mock.Ctx, "https://app.example.com/", state)
+
+	mock.Assert200OK(t, nil)
+
+	entry := mock.Hook.LastEntry()
+	require.NotNil(t, entry)
+	assert.Equal(t, "Rejected an unsafe redirection target", entry.Message)
+	assert.Equal(t, "https://app.example.com/", entry.Data["target"])
+	assert.Equal(t, "login state was issued for another target", entry.Data["reason"])
+
+	assert.Equal(t, errLoginStateMissing, consumeLoginState(mock.Ctx, "", "https://home.example.com/"))
+	assert.EqualError(t, consumeLoginState(mock.Ctx, state+"x", "https://home.example.com/"), "login state is invalid: signature is invalid")
+
+	mock.Clock.Set(mock.Clock.Now().Add(11 * time.Minute))
+
+	assert.Equal(t, errLoginStateExpired, consumeLoginState(mock.Ctx, state, "https://home.example.com/"))
+}
+
+func TestShouldRewriteRedirectionWithoutSignedStateToDefaultRedirectionURL(t *testing.T) {
+	mock := newSignedStateMockAutheliaCtx(t)
+	defer mock.Close()
+
+	mock.Ctx.Configuration.DefaultRedirectionURL = "https://home.example.com/"
+
+	Handle2FAResponse(mock.Ctx, "https://app.example.com/", "")
+
+	mock.Assert200OK(t, redirectResponse{
+		Redirect: "https://home.example.com/",
+	})
+
+	entry := mock.Hook.LastEntry()
+	require.NotNil(t, entry)
+	assert.Equal(t, "login state is missing", entry.Data["reason"])
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/authelia/authelia/internal/events"
+	"github.com/authelia/authelia/internal/metrics"
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/regulation"
+)
+
+// isSecondFactorBanned returns true and replies with an error when the user is banned from the second factor
+// because of too many failed attempts.
+func isSecondFactorBanned(ctx *middlewares.AutheliaCtx, username string) bool {
+	bannedUntil, err := ctx.Providers.Regulator.RegulateSecondFactor(username)
+	if err == nil {
+		return false
+	}
+
+	if err == regulation.ErrUserIsBanned {
+		handleAuthenticationUnauthorized(ctx, fmt.Errorf("User %s is banned from the second factor until %s", username, bannedUntil), userBannedMessage)
+		return true
+	}
+
+	handleAuthenticationUnauthorized(ctx, fmt.Errorf("Unable to regulate second factor authentication: %s", err), mfaValidationFailedMessage)
+
+	return true
+}
+
+// markSecondFactorAttempt marks a second factor authentication attempt of the given type made by the user.
+func markSecondFactorAttempt(ctx *middlewares.AutheliaCtx, username string, successful bool, authType string) {
+	if err := markAuthenticationAttempt(ctx, username, successful, authType); err != nil {
+		ctx.Logger.Errorf("Unable to mark %s authentication: %s", authType, err)
+	}
+}
+
+// markAuthenticationAttempt marks an authentication attempt of the given type made by the user and publishes it, along
+// with the ban of the user when the failed attempt triggers one.
+func markAuthenticationAttempt(ctx *middlewares.AutheliaCtx, username string, successful bool, authType string) error {
+	ctx.Logger.Debugf("Mark %s authentication attempt made by user %s", authType, username)
+
+	attempt := newAuthenticationAttempt(ctx, username, successful, authType)
+
+	err := ctx.Providers.Regulator.MarkAttempt(attempt)
+
+	if attempt.IsSecondFactor() {
+		metrics.SecondFactorAttempts.Inc(authType, strconv.FormatBool(successful))
+	} else {
+		metrics.FirstFactorAttempts.Inc(strconv.FormatBool(successful))
+	}
+
+	event := events.Event{
+		Type:      events.LoginSucceeded,
+		Time:      ctx.Clock.Now(),
+		Username:  username,
+		RemoteIP:  attempt.RemoteIP,
+		UserAgent: attempt.UserAgent,
+		RequestID: ctx.RequestID(),
+		Details:   map[string]string{events.DetailAuthenticationType: authType},
+	}
+
+	if !successful {
+		event.Type = events.LoginFailed
+	}
+
+	ctx.Providers.Events.Publish(event)
+
+	if successful || err != nil {
+		return err
+	}
+
+	regulate := ctx.Providers.Regulator.Regulate
+	if attempt.IsSecondFactor() {
+		regulate = ctx.Providers.Regulator.RegulateSecondFactor
+	}
+
+	if bannedUntil, err := regulate(username); err == regulation.ErrUserIsBanned {
+		event.Type = events.UserBanned
+		event.Details = map[string]string{
+			events.DetailAuthenticationType: authType,
+			events.DetailBannedUntil:        bannedUntil.Format(time.RFC3339),
+		}
+
+		ctx.Providers.Events.Publish(event)
+	}
+
+	return nil
+}
@@ -8,14 +8,13 @@ import (
 
 	"github.com/authelia/authelia/internal/authorization"
 	"github.com/authelia/authelia/internal/middlewares"
-	"github.com/authelia/authelia/internal/utils"
 )
 
 // handleOIDCWorkflowResponse handle the redirection upon authentication in the OIDC workflow.
 func handleOIDCWorkflowResponse(ctx *middlewares.AutheliaCtx) {
 	userSession := ctx.GetSession()
 
-	if !authorization.IsAuthLevelSufficient(userSession.AuthenticationLevel, userSession.OIDCWorkflowSession.RequiredAuthorizationLevel) {
+	if !authorization.IsAuthLevelSufficient(userSession.AuthenticationLevel, userSession.OIDCWorkflowSession.RequiredAuthorizationLevel) || userSession.IsStepUpPending() {
 		ctx.Logger.Warn("OIDC requires 2FA, cannot be redirected yet")
 		ctx.ReplyOK()
 
@@ -30,10 +29,10 @@ func handleOIDCWorkflowResponse(ctx *middlewares.AutheliaCtx) {
 		return
 	}
 
-	if isConsentMissing(
-		userSession.OIDCWorkflowSession,
-		userSession.OIDCWorkflowSession.RequestedScopes,
-		userSession.OIDCWorkflowSession.RequestedAudience) {
+	workflow := userSession.OIDCWorkflowSession
+
+	if isConsentMissing(workflow, workflow.RequestedScopes, workflow.RequestedAudience) &&
+		!isConsentRemembered(ctx, userSession.Username, workflow.ClientID, workflow.RequestedScopes, workflow.RequestedAudience) {
 		err := ctx.SetJSONBody(redirectResponse{Redirect: fmt.Sprintf("%s/consent", uri)})
 
 		if err != nil {
@@ -47,11 +46,19 @@ func handleOIDCWorkflowResponse(ctx *middlewares.AutheliaCtx) {
 	}
 }
 
-// Handle1FAResponse handle the redirection upon 1FA authentication.
-func Handle1FAResponse(ctx *middlewares.AutheliaCtx, targetURI, requestMethod string, username string, groups []string) {
+// Handle1FAResponse handle the redirection upon 1FA authentication. The state is the signed state of the login flow
+// sent back by the portal, it's only verified when the signed state is enabled.
+func Handle1FAResponse(ctx *middlewares.AutheliaCtx, targetURI, state, requestMethod string, username string, groups []string) {
+	if ctx.GetSession().IsStepUpPending() {
+		ctx.Logger.Warnf("User %s is required to complete the second factor by the risk engine, cannot be redirected yet", username)
+		ctx.ReplyOK()
+
+		return
+	}
+
 	if targetURI == "" {
-		if !ctx.Providers.Authorizer.IsSecondFactorEnabled() && ctx.Configuration.DefaultRedirectionURL != "" {
-			err := ctx.SetJSONBody(redirectResponse{Redirect: ctx.Configuration.DefaultRedirectionURL})
+		if redirectionURL := defaultRedirectionURL(ctx, username, groups); !ctx.Providers.Authorizer.IsSecondFactorEnabled() && redirectionURL != "" {
+			err := ctx.SetJSONBody(redirectResponse{Redirect: redirectionURL})
 			if err != nil {
 				ctx.Logger.Errorf("Unable to set default redirection URL in body: %s", err)
 			}
@@ -85,11 +92,11 @@ func Handle1FAResponse(ctx *middlewares.AutheliaCtx, targetURI, requestMethod st
 		return
 	}
 
-	safeRedirection := utils.IsRedirectionSafe(*targetURL, ctx.Configuration.Session.Domain)
+	safeRedirection := isRedirectionSafe(ctx, redirectionFlowLogin, *targetURL) && isLoginStateValid(ctx, state, targetURI)
 
 	if !safeRedirection {
-		if !ctx.Providers.Authorizer.IsSecondFactorEnabled() && ctx.Configuration.DefaultRedirectionURL != "" {
-			err := ctx.SetJSONBody(redirectResponse{Redirect: ctx.Configuration.DefaultRedirectionURL})
+		if redirectionURL := defaultRedirectionURL(ctx, username, groups); !ctx.Providers.Authorizer.IsSecondFactorEnabled() && redirectionURL != "" {
+			err := ctx.SetJSONBody(redirectResponse{Redirect: redirectionURL})
 			if err != nil {
 				ctx.Logger.Errorf("Unable to set default redirection URL in body: %s", err)
 			}
@@ -102,7 +109,7 @@ func Handle1FAResponse(ctx *middlewares.AutheliaCtx, targetURI, requestMethod st
 
 	ctx.Logger.Debugf("Redirection URL %s is safe", targetURI)
 
-	response := redirectResponse{Redirect: targetURI}
+	response := redirectResponse{Redirect: safeRedirectionURI(ctx, targetURL)}
 
 	err = ctx.SetJSONBody(response)
 	if err != nil {
@@ -110,11 +117,15 @@ func Handle1FAResponse(ctx *middlewares.AutheliaCtx, targetURI, requestMethod st
 	}
 }
 
-// Handle2FAResponse handle the redirection upon 2FA authentication.
-func Handle2FAResponse(ctx *middlewares.AutheliaCtx, targetURI string) {
+// Handle2FAResponse handle the redirection upon 2FA authentication. The state is the signed state of the login flow
+// sent back by the portal, it's only verified when the signed state is enabled.
+func Handle2FAResponse(ctx *middlewares.AutheliaCtx, targetURI, state string) {
+	userSession := ctx.GetSession()
+	redirectionURL := defaultRedirectionURL(ctx, userSession.Username, userSession.Groups)
+
 	if targetURI == "" {
-		if ctx.Configuration.DefaultRedirectionURL != "" {
-			err := ctx.SetJSONBody(redirectResponse{Redirect: ctx.Configuration.DefaultRedirectionURL})
+		if redirectionURL != "" {
+			err := ctx.SetJSONBody(redirectResponse{Redirect: redirectionURL})
 			if err != nil {
 				ctx.Logger.Errorf("Unable to set default redirection URL in body: %s", err)
 			}
@@ -132,16 +143,31 @@ func Handle2FAResponse(ctx *middlewares.AutheliaCtx, targetURI string) {
 		return
 	}
 
-	if targetURL != nil && utils.IsRedirectionSafe(*targetURL, ctx.Configuration.Session.Domain) {
-		err := ctx.SetJSONBody(redirectResponse{Redirect: targetURI})
+	if targetURL != nil && isRedirectionSafe(ctx, redirectionFlowLogin, *targetURL) && isLoginStateValid(ctx, state, targetURI) {
+		err := ctx.SetJSONBody(redirectResponse{Redirect: safeRedirectionURI(ctx, targetURL)})
 		if err != nil {
 			ctx.Logger.Errorf("Unable to set redirection URL in body: %s", err)
 		}
+	} else if redirectionURL != "" {
+		err := ctx.SetJSONBody(redirectResponse{Redirect: redirectionURL})
+		if err != nil {
+			ctx.Logger.Errorf("Unable to set default redirection URL in body: %s", err)
+		}
 	} else {
 		ctx.ReplyOK()
 	}
 }
 
+// defaultRedirectionURL returns the URL the user is redirected to when there is no target URL. The default redirection
+// URL configured for the user or one of the groups of the user prevails over the global one.
+func defaultRedirectionURL(ctx *middlewares.AutheliaCtx, username string, groups []string) string {
+	if redirectionURL, ok := ctx.Providers.Authorizer.GetDefaultRedirectionURL(authorization.Subject{Username: username, Groups: groups}); ok {
+		return redirectionURL
+	}
+
+	return ctx.Configuration.DefaultRedirectionURL
+}
+
 // handleAuthenticationUnauthorized provides harmonized response codes for 1FA.
 func handleAuthenticationUnauthorized(ctx *middlewares.AutheliaCtx, err error, message string) {
 	ctx.SetStatusCode(fasthttp.StatusUnauthorized)
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/risk"
+)
+
+// assessLoginRisk evaluates the risk of a login of the user when the risk based authentication is enabled. The second
+// factor is required when the risk can't be evaluated.
+func assessLoginRisk(ctx *middlewares.AutheliaCtx, username string) risk.Decision {
+	if ctx.Providers.Risk == nil {
+		return risk.Allow
+	}
+
+	assessment, err := ctx.Providers.Risk.Evaluate(risk.Login{
+		Username:  username,
+		RemoteIP:  ctx.RemoteIP().String(),
+		UserAgent: string(ctx.UserAgent()),
+		Time:      ctx.Clock.Now(),
+	})
+	if err != nil {
+		ctx.Logger.Errorf("Unable to evaluate the login risk of user %s, the second factor is required: %s", username, err)
+
+		return risk.StepUp
+	}
+
+	evaluation := assessment.Evaluation
+
+	ctx.Logger.Debugf("Login risk of user %s is %d (new device: %t, new country: %t, impossible travel: %t, failed attempts: %d), decision is %s",
+		username, assessment.Score, evaluation.NewDevice, evaluation.NewCountry, evaluation.ImpossibleTravel, evaluation.FailedAttempts, assessment.Decision)
+
+	return assessment.Decision
+}
@@ -1,31 +1,104 @@
 package handlers
 
 import (
+	"crypto/hmac"
+	"crypto/sha1" //nolint:gosec // SHA1 is the algorithm of the TOTPs registered by the users.
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"strings"
 	"time"
 
 	"github.com/pquerna/otp"
-	"github.com/pquerna/otp/totp"
+
+	"github.com/authelia/authelia/internal/utils"
 )
 
 // TOTPVerifier is the interface for verifying TOTPs.
 type TOTPVerifier interface {
-	Verify(token, secret string) (bool, error)
+	Verify(token, secret string) (step uint64, valid bool, err error)
 }
 
 // TOTPVerifierImpl the production implementation for TOTP verification.
 type TOTPVerifierImpl struct {
 	Period uint
 	Skew   uint
+	Clock  utils.Clock
+}
+
+// Verify verifies TOTPs and returns the time step the token has been generated for so it can't be used twice.
+func (tv *TOTPVerifierImpl) Verify(token, secret string) (step uint64, valid bool, err error) {
+	return tv.verifyAt(token, secret, tv.Clock.Now().UTC())
 }
 
-// Verify verifies TOTPs.
-func (tv *TOTPVerifierImpl) Verify(token, secret string) (bool, error) {
-	opts := totp.ValidateOpts{
-		Period:    tv.Period,
-		Skew:      tv.Skew,
-		Digits:    otp.DigitsSix,
-		Algorithm: otp.AlgorithmSHA1,
+func (tv *TOTPVerifierImpl) verifyAt(token, secret string, t time.Time) (step uint64, valid bool, err error) {
+	token = strings.TrimSpace(token)
+
+	if len(token) != otp.DigitsSix.Length() {
+		return 0, false, otp.ErrValidateInputInvalidLength
+	}
+
+	// The secret is decoded once for all the steps and wiped as soon as the token is verified.
+	key, err := decodeTOTPSecret(secret)
+	if err != nil {
+		return 0, false, err
+	}
+
+	defer key.Wipe()
+
+	current := uint64(t.Unix()) / uint64(tv.Period)
+
+	// Check the most recent steps first so a token is always associated to the latest step it's valid for.
+	for i := int64(tv.Skew); i >= -int64(tv.Skew); i-- {
+		step = uint64(int64(current) + i)
+
+		if subtle.ConstantTimeCompare([]byte(generateHOTP(key, step)), []byte(token)) == 1 {
+			return step, true, nil
+		}
 	}
 
-	return totp.ValidateCustom(token, secret, time.Now().UTC(), opts)
+	return 0, false, nil
+}
+
+// decodeTOTPSecret decodes the base32 secret of a TOTP the way the authenticator applications do, i.e. the padding is
+// optional and the secret is case insensitive.
+func decodeTOTPSecret(secret string) (key *utils.SecureBuffer, err error) {
+	secret = strings.TrimSpace(secret)
+
+	encoded := make([]byte, (len(secret)+7)/8*8)
+	defer utils.WipeBytes(encoded)
+
+	copy(encoded, strings.ToUpper(secret))
+
+	for i := len(secret); i < len(encoded); i++ {
+		encoded[i] = '='
+	}
+
+	decoded := make([]byte, base32.StdEncoding.DecodedLen(len(encoded)))
+	defer utils.WipeBytes(decoded)
+
+	n, err := base32.StdEncoding.Decode(decoded, encoded)
+	if err != nil {
+		return nil, otp.ErrValidateSecretInvalidBase32
+	}
+
+	return utils.NewSecureBuffer(decoded[:n]), nil
+}
+
+// generateHOTP generates the six digits HOTP of the counter as specified in RFC 4226.
+func generateHOTP(key *utils.SecureBuffer, counter uint64) string {
+	message := make([]byte, 8)
+	binary.BigEndian.PutUint64(message, counter)
+
+	mac := hmac.New(sha1.New, key.Reveal())
+	_, _ = mac.Write(message)
+	sum := mac.Sum(nil)
+
+	defer utils.WipeBytes(sum)
+
+	// Dynamic truncation, see https://tools.ietf.org/html/rfc4226#section-5.4.
+	offset := sum[len(sum)-1] & 0xf
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return otp.DigitsSix.Format(int32(value % 1000000))
 }
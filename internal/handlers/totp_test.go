@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/utils"
+)
+
+const testTOTPSecret = "JBSWY3DPEHPK3PXP"
+
+func generateTestTOTP(t *testing.T, at time.Time) string {
+	token, err := totp.GenerateCodeCustom(testTOTPSecret, at, totp.ValidateOpts{
+		Period:    30,
+		Digits:    otp.DigitsSix,
+		Algorithm: otp.AlgorithmSHA1,
+	})
+	require.NoError(t, err)
+
+	return token
+}
+
+func TestShouldReturnStepOfValidTOTP(t *testing.T) {
+	verifier := &TOTPVerifierImpl{Period: 30, Skew: 1}
+	now := time.Unix(30000, 0)
+
+	step, valid, err := verifier.verifyAt(generateTestTOTP(t, now), testTOTPSecret, now)
+	require.NoError(t, err)
+	assert.True(t, valid)
+	assert.Equal(t, uint64(1000), step)
+
+	step, valid, err = verifier.verifyAt(generateTestTOTP(t, now.Add(-30*time.Second)), testTOTPSecret, now)
+	require.NoError(t, err)
+	assert.True(t, valid)
+	assert.Equal(t, uint64(999), step)
+}
+
+func TestShouldNotValidateTOTPOutsideOfSkew(t *testing.T) {
+	verifier := &TOTPVerifierImpl{Period: 30, Skew: 1}
+	now := time.Unix(30000, 0)
+
+	_, valid, err := verifier.verifyAt(generateTestTOTP(t, now.Add(-60*time.Second)), testTOTPSecret, now)
+	require.NoError(t, err)
+	assert.False(t, valid)
+}
+
+func TestShouldValidateTOTPOfSecretWithoutPaddingInLowerCase(t *testing.T) {
+	verifier := &TOTPVerifierImpl{Period: 30, Skew: 1}
+	now := time.Unix(30000, 0)
+
+	secret := "JBSWY3DPEHPK3PXPJBSW"
+
+	token, err := totp.GenerateCodeCustom(secret, now, totp.ValidateOpts{
+		Period:    30,
+		Digits:    otp.DigitsSix,
+		Algorithm: otp.AlgorithmSHA1,
+	})
+	require.NoError(t, err)
+
+	step, valid, err := verifier.verifyAt(token, " jbswy3dpehpk3pxpjbsw ", now)
+	require.NoError(t, err)
+	assert.True(t, valid)
+	assert.Equal(t, uint64(1000), step)
+}
+
+func TestShouldFailToVerifyTOTPWithInvalidInput(t *testing.T) {
+	verifier := &TOTPVerifierImpl{Period: 30, Skew: 1}
+	now := time.Unix(30000, 0)
+
+	_, valid, err := verifier.verifyAt("12345", testTOTPSecret, now)
+	assert.EqualError(t, err, otp.ErrValidateInputInvalidLength.Error())
+	assert.False(t, valid)
+
+	_, valid, err = verifier.verifyAt(generateTestTOTP(t, now), "JBSWY3DPEHPK3PX1", now)
+	assert.EqualError(t, err, otp.ErrValidateSecretInvalidBase32.Error())
+	assert.False(t, valid)
+}
+
+func TestShouldVerifyTOTPAtTheTimeOfTheClock(t *testing.T) {
+	clock := utils.NewFakeClock(time.Unix(30000, 0))
+	verifier := &TOTPVerifierImpl{Period: 30, Skew: 1, Clock: clock}
+	token := generateTestTOTP(t, clock.Now())
+
+	step, valid, err := verifier.Verify(token, testTOTPSecret)
+	require.NoError(t, err)
+	assert.True(t, valid)
+	assert.Equal(t, uint64(1000), step)
+
+	clock.Advance(time.Minute)
+
+	_, valid, err = verifier.Verify(token, testTOTPSecret)
+	require.NoError(t, err)
+	assert.False(t, valid)
+}
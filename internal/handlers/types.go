@@ -4,6 +4,7 @@ import (
 	"github.com/tstranex/u2f"
 
 	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/webauthn"
 )
 
 // MethodList is the list of available methods.
@@ -24,22 +25,158 @@ type UserInfo struct {
 
 	// True if a TOTP device has been registered.
 	HasTOTP bool `json:"has_totp" valid:"required"`
+
+	// The TOTP devices registered by the user, the oldest first.
+	TOTPDevices []UserTOTPDevice `json:"totp_devices"`
+
+	// True if a WebAuthn credential has been registered.
+	HasWebauthn bool `json:"has_webauthn" valid:"required"`
+
+	// The number of backup codes which haven't been used yet.
+	BackupCodesRemaining int `json:"backup_codes_remaining"`
+
+	// The enrollment state of the user with the push provider, it's omitted when it has never been checked.
+	PushEnrollment string `json:"push_enrollment,omitempty"`
+
+	// The regulation state of the first factor of the user.
+	FirstFactorRegulation UserRegulationInfo `json:"first_factor_regulation"`
+
+	// The regulation state of the second factor of the user.
+	SecondFactorRegulation UserRegulationInfo `json:"second_factor_regulation"`
+
+	// The description of the info which couldn't be loaded.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// UserTOTPDevice is the model of a TOTP device registered by a user, the secret of the device is never sent back.
+type UserTOTPDevice struct {
+	// The ID of the device used to rename or delete it.
+	ID string `json:"id"`
+
+	// The friendly name of the device.
+	Description string `json:"description"`
+
+	// The unix timestamp of the registration of the device, it's omitted when it's unknown.
+	CreatedAt int64 `json:"created_at,omitempty"`
+
+	// The unix timestamp of the last use of the device, it's omitted when the device has never been used.
+	LastUsedAt int64 `json:"last_used_at,omitempty"`
+}
+
+// BackupCodesResponse is the model of the backup codes generated for a user, they're only returned once.
+type BackupCodesResponse struct {
+	// The backup codes, each of them can be used once.
+	Codes []string `json:"codes"`
+}
+
+// UserEventsResponse is the model of a page of the security events of a user.
+type UserEventsResponse struct {
+	// The events of the page, the most recent first.
+	Events []UserEventResponse `json:"events"`
+
+	// The number of the page starting from 1.
+	Page int `json:"page"`
+
+	// The maximum number of events per page.
+	PerPage int `json:"per_page"`
+
+	// True if there are older events in the next page.
+	HasMore bool `json:"has_more"`
+}
+
+// UserEventResponse is the model of a security event of a user.
+type UserEventResponse struct {
+	// The type of the event, the type of the attempt for the authentication attempts.
+	Type string `json:"type"`
+
+	// True if the event was successful.
+	Successful bool `json:"successful"`
+
+	// The unix timestamp of the event.
+	Time int64 `json:"time"`
+
+	// The IP of the client which triggered the event.
+	RemoteIP string `json:"remote_ip,omitempty"`
+
+	// The user agent of the client which triggered the event.
+	UserAgent string `json:"user_agent,omitempty"`
+}
+
+// UserRegulationInfo is the model of the regulation state of a user.
+type UserRegulationInfo struct {
+	// True if the user is currently banned.
+	Banned bool `json:"banned"`
+
+	// The unix timestamp until when the user is banned.
+	BannedUntil int64 `json:"banned_until,omitempty"`
+
+	// The number of failed attempts since the latest successful attempt within the regulation find time.
+	FailedAttempts int `json:"failed_attempts"`
 }
 
 // signTOTPRequestBody model of the request body received by TOTP authentication endpoint.
 type signTOTPRequestBody struct {
 	Token     string `json:"token" valid:"required"`
 	TargetURL string `json:"targetURL"`
+	State     string `json:"state"`
+}
+
+// signBackupCodeRequestBody model of the request body received by the backup code authentication endpoint.
+type signBackupCodeRequestBody struct {
+	Code      string `json:"code" valid:"required"`
+	TargetURL string `json:"targetURL"`
+	State     string `json:"state"`
 }
 
 // signU2FRequestBody model of the request body of U2F authentication endpoint.
 type signU2FRequestBody struct {
 	SignResponse u2f.SignResponse `json:"signResponse"`
 	TargetURL    string           `json:"targetURL"`
+	State        string           `json:"state"`
+}
+
+// signWebauthnRequestBody model of the request body of WebAuthn authentication endpoint.
+type signWebauthnRequestBody struct {
+	Credential webauthn.CredentialAssertion `json:"credential"`
+	TargetURL  string                       `json:"targetURL"`
+	State      string                       `json:"state"`
+}
+
+// webauthnRegistrationRequestBody model of the request body of the WebAuthn registration endpoints, it's the
+// credential returned by the authenticator along with the friendly name the user gave it.
+type webauthnRegistrationRequestBody struct {
+	webauthn.CredentialAttestation
+
+	Description string `json:"description"`
+}
+
+// announcementAcknowledgmentRequestBody model of the request body of the endpoint acknowledging the announcement.
+type announcementAcknowledgmentRequestBody struct {
+	ID string `json:"id" valid:"required"`
+}
+
+// totpDeviceRequestBody model of the request body of the endpoint renaming a TOTP device.
+type totpDeviceRequestBody struct {
+	Description string `json:"description" valid:"required"`
 }
 
 type signDuoRequestBody struct {
 	TargetURL string `json:"targetURL"`
+	State     string `json:"state"`
+}
+
+// pushStartResponseBody represents the response of the endpoint sending a push notification.
+type pushStartResponseBody struct {
+	// The enrollment state of the user with the push provider, the notification is only sent to the enrolled users.
+	Enrollment string `json:"enrollment"`
+	// The URL the user enrolls a device at, it's only set when the user is not enrolled.
+	EnrollURL string `json:"enroll_url,omitempty"`
+}
+
+// pushStatusResponseBody represents the response of the endpoint polling a push notification the user hasn't approved
+// or denied yet.
+type pushStatusResponseBody struct {
+	Result string `json:"result"`
 }
 
 // firstFactorRequestBody represents the JSON body received by the endpoint.
@@ -47,12 +184,22 @@ type firstFactorRequestBody struct {
 	Username       string `json:"username" valid:"required"`
 	Password       string `json:"password" valid:"required"`
 	TargetURL      string `json:"targetURL"`
+	State          string `json:"state"`
 	RequestMethod  string `json:"requestMethod"`
 	KeepMeLoggedIn *bool  `json:"keepMeLoggedIn"`
 	// KeepMeLoggedIn: Cannot require this field because of https://github.com/asaskevich/govalidator/pull/329
 	// TODO(c.michaud): add required validation once the above PR is merged.
 }
 
+// firstFactorPasskeyRequestBody represents the JSON body received by the passkey login endpoint.
+type firstFactorPasskeyRequestBody struct {
+	Credential     webauthn.CredentialAssertion `json:"credential"`
+	TargetURL      string                       `json:"targetURL"`
+	State          string                       `json:"state"`
+	RequestMethod  string                       `json:"requestMethod"`
+	KeepMeLoggedIn *bool                        `json:"keepMeLoggedIn"`
+}
+
 // redirectResponse represent the response sent by the first factor endpoint
 // when a redirection URL has been provided.
 type redirectResponse struct {
@@ -69,9 +216,20 @@ type TOTPKeyResponse struct {
 type StateResponse struct {
 	Username              string               `json:"username"`
 	AuthenticationLevel   authentication.Level `json:"authentication_level"`
+	StepUpRequired        bool                 `json:"step_up_required"`
 	DefaultRedirectionURL string               `json:"default_redirection_url"`
 }
 
+// PortalResponse represents the response sent by the portal endpoint, it combines the responses of the state,
+// configuration and user info endpoints. The configuration and the user info are only sent to authenticated users, the
+// login banner is sent to all users since it's displayed before they login.
+type PortalResponse struct {
+	State         StateResponse      `json:"state"`
+	LoginBanner   string             `json:"login_banner,omitempty"`
+	Configuration *ConfigurationBody `json:"configuration,omitempty"`
+	UserInfo      *UserInfo          `json:"user_info,omitempty"`
+}
+
 // resetPasswordStep1RequestBody model of the reset password (step1) request body.
 type resetPasswordStep1RequestBody struct {
 	Username string `json:"username"`
@@ -81,3 +239,145 @@ type resetPasswordStep1RequestBody struct {
 type resetPasswordStep2RequestBody struct {
 	Password string `json:"password"`
 }
+
+// PasswordPolicyBody the password policy returned to the portal so it can validate the passwords as they're typed.
+type PasswordPolicyBody struct {
+	MinLength        int  `json:"min_length"`
+	MaxLength        int  `json:"max_length"`
+	RequireUppercase bool `json:"require_uppercase"`
+	RequireLowercase bool `json:"require_lowercase"`
+	RequireNumber    bool `json:"require_number"`
+	RequireSpecial   bool `json:"require_special"`
+	MinScore         int  `json:"min_score"`
+}
+
+// passwordStrengthRequestBody model of the password strength request body.
+type passwordStrengthRequestBody struct {
+	Password string `json:"password"`
+}
+
+// PasswordStrengthBody the evaluation of a password against the password policy.
+type PasswordStrengthBody struct {
+	Score int      `json:"score"`
+	Valid bool     `json:"valid"`
+	Unmet []string `json:"unmet"`
+}
+
+// adminOIDCKeyRotateRequestBody model of the OpenID Connect key rotation request body, the next key is made active
+// when no key id is provided.
+type adminOIDCKeyRotateRequestBody struct {
+	KeyID string `json:"key_id"`
+}
+
+// AdminOIDCKeysBody the key ids of the OpenID Connect issuer keys and the key id of the active one.
+type AdminOIDCKeysBody struct {
+	ActiveKeyID string   `json:"active_key_id"`
+	KeyIDs      []string `json:"key_ids"`
+}
+
+// AdminOIDCClient is the model of an OpenID Connect client registered with the dynamic registration endpoint returned
+// to the administrators, the hash of its secret is left out.
+type AdminOIDCClient struct {
+	ID                     string   `json:"id"`
+	Description            string   `json:"description"`
+	Policy                 string   `json:"authorization_policy"`
+	RedirectURIs           []string `json:"redirect_uris"`
+	GrantTypes             []string `json:"grant_types"`
+	ResponseTypes          []string `json:"response_types"`
+	Scopes                 []string `json:"scopes"`
+	PostLogoutRedirectURIs []string `json:"post_logout_redirect_uris,omitempty"`
+	BackChannelLogoutURI   string   `json:"backchannel_logout_uri,omitempty"`
+	FrontChannelLogoutURI  string   `json:"frontchannel_logout_uri,omitempty"`
+	RegisteredBy           string   `json:"registered_by"`
+
+	// The unix timestamp of the registration of the client.
+	CreatedAt int64 `json:"created_at"`
+}
+
+// AdminUserBody is the model of the second factor devices and the regulation state of a user returned to the
+// administrators.
+type AdminUserBody struct {
+	UserInfo
+
+	// The username of the user.
+	Username string `json:"username"`
+
+	// The WebAuthn credentials registered by the user, the oldest first.
+	WebauthnCredentials []AdminWebauthnCredential `json:"webauthn_credentials"`
+}
+
+// AdminWebauthnCredential is the model of a WebAuthn credential registered by a user, the public key is never sent.
+type AdminWebauthnCredential struct {
+	// The ID of the credential encoded with the URL safe base64 encoding without padding, used to delete it.
+	ID string `json:"id"`
+
+	// The friendly name of the credential.
+	Description string `json:"description"`
+
+	// The unix timestamp of the registration of the credential, it's omitted when it's unknown.
+	CreatedAt int64 `json:"created_at,omitempty"`
+}
+
+// adminSecondFactorResetRequestBody is the model of the request of an operator to reset the second factor of a user.
+type adminSecondFactorResetRequestBody struct {
+	// The reason of the reset, e.g. the user lost their devices.
+	Reason string `json:"reason" valid:"required"`
+
+	// Who approves the reset, admin or user. Another operator approves it when it's omitted.
+	Approval string `json:"approval"`
+}
+
+// AdminSecondFactorReset is the model of a second factor reset returned to the administrators, the approval token sent
+// to the user is never returned.
+type AdminSecondFactorReset struct {
+	ID          string `json:"id"`
+	Username    string `json:"username"`
+	RequestedBy string `json:"requested_by"`
+	Reason      string `json:"reason"`
+	Approval    string `json:"approval"`
+	Status      string `json:"status"`
+	DecidedBy   string `json:"decided_by,omitempty"`
+
+	// The unix timestamps of the request, the expiration and the decision of the reset, the decision is omitted while
+	// the reset is pending.
+	RequestedAt int64 `json:"requested_at"`
+	ExpiresAt   int64 `json:"expires_at"`
+	DecidedAt   int64 `json:"decided_at,omitempty"`
+}
+
+// secondFactorResetApprovalBody is the model of the approval of the reset of their second factor by the users, with
+// the token sent by email.
+type secondFactorResetApprovalBody struct {
+	ID    string `json:"id" valid:"required"`
+	Token string `json:"token" valid:"required"`
+}
+
+// accessGrantRequestBody is the model of the request of an access grant to a resource for a guest.
+type accessGrantRequestBody struct {
+	TargetURL string `json:"target_url" valid:"required"`
+
+	// The duration of the grant, the default lifespan is used when it's omitted.
+	Lifespan string `json:"lifespan"`
+
+	Description string `json:"description"`
+}
+
+// AccessGrant is the model of an access grant returned to the users and the administrators, the link with the token
+// given to the guest is only returned once when the grant is created.
+type AccessGrant struct {
+	ID          string `json:"id"`
+	CreatedBy   string `json:"created_by"`
+	TargetURL   string `json:"target_url"`
+	Description string `json:"description,omitempty"`
+
+	// The unix timestamps of the creation and the expiration of the grant.
+	CreatedAt int64 `json:"created_at"`
+	ExpiresAt int64 `json:"expires_at"`
+
+	URL string `json:"url,omitempty"`
+}
+
+// AdminOIDCKeyRotateBody the key id of the OpenID Connect issuer key made active by a rotation.
+type AdminOIDCKeyRotateBody struct {
+	KeyID string `json:"key_id"`
+}
@@ -4,9 +4,24 @@ package handlers
 type ConsentPostRequestBody struct {
 	ClientID       string `json:"client_id"`
 	AcceptOrReject string `json:"accept_or_reject"`
+
+	// RememberConsent saves the consent when it's accepted so it isn't asked again during the remember consent lifespan.
+	RememberConsent bool `json:"remember_consent"`
 }
 
 // ConsentPostResponseBody schema of the response body of the consent POST endpoint.
 type ConsentPostResponseBody struct {
 	RedirectURI string `json:"redirect_uri"`
 }
+
+// OIDCConsent is the model of a consent a user remembered for an OpenID Connect client returned to the user.
+type OIDCConsent struct {
+	ClientID          string   `json:"client_id"`
+	ClientDescription string   `json:"client_description,omitempty"`
+	Scopes            []string `json:"scopes"`
+	Audience          []string `json:"audience"`
+
+	// The unix timestamps of the consent and its expiration.
+	CreatedAt int64 `json:"created_at"`
+	ExpiresAt int64 `json:"expires_at"`
+}
@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/models"
+)
+
+// appendUserEvent records a security event of the user made by the current client. Failing to record the event doesn't
+// fail the request.
+func appendUserEvent(ctx *middlewares.AutheliaCtx, username, eventType string) {
+	client := ctx.ClientMetadata()
+
+	err := ctx.Providers.StorageProvider.AppendUserEvent(models.UserEvent{
+		Username:   username,
+		Type:       eventType,
+		Successful: true,
+		Time:       ctx.Clock.Now(),
+		RemoteIP:   client.RemoteIP,
+		UserAgent:  client.UserAgent,
+	})
+	if err != nil {
+		ctx.Logger.Errorf("Unable to record %s event of user %s: %s", eventType, username, err)
+	}
+}
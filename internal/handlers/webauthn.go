@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/authelia/authelia/internal/authorization"
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/webauthn"
+)
+
+// newWebauthnRelyingParty returns the WebAuthn relying party. Credentials are scoped to the session domain so they can
+// be used from any portal URL under it. The origin the ceremonies are checked against is the one of the server external
+// url rather than the X-Forwarded-* headers of the request which a caller could forge.
+func newWebauthnRelyingParty(ctx *middlewares.AutheliaCtx) (*webauthn.RelyingParty, error) {
+	if ctx.Configuration.Server.ExternalURL == "" {
+		return nil, errMissingExternalURL
+	}
+
+	externalURL, err := url.Parse(ctx.Configuration.Server.ExternalURL)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to parse the server external url: %w", err)
+	}
+
+	return &webauthn.RelyingParty{
+		ID:     ctx.Configuration.Session.Domain,
+		Name:   ctx.Configuration.Webauthn.DisplayName,
+		Origin: fmt.Sprintf("%s://%s", externalURL.Scheme, externalURL.Host),
+	}, nil
+}
+
+// isPasskeyTwoFactor returns true if passkeys satisfy both factors, in which case the authenticator must verify
+// the user, for example with a PIN or biometrics.
+func isPasskeyTwoFactor(ctx *middlewares.AutheliaCtx) bool {
+	return authorization.PolicyToLevel(ctx.Configuration.Webauthn.Passwordless.Policy) == authorization.TwoFactor
+}
+
+func webauthnUserVerification(requireUserVerification bool) string {
+	if requireUserVerification {
+		return webauthn.UserVerificationRequired
+	}
+
+	return webauthn.UserVerificationPreferred
+}
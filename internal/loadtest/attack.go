@@ -0,0 +1,120 @@
+package loadtest
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Attack sends the requests of the target at a constant rate per second for the duration and summarizes the responses.
+// The requests are sent on schedule even if the previous ones haven't completed, so a slow server doesn't lower the
+// load and its latencies are measured faithfully.
+func Attack(client *http.Client, target Target, rate int, duration time.Duration) Metrics {
+	interval := time.Second / time.Duration(rate)
+	total := int(duration / interval)
+
+	hits := make(chan hit, total)
+
+	var wg sync.WaitGroup
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	start := time.Now()
+
+	for i := 0; i < total; i++ {
+		if i > 0 {
+			<-ticker.C
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			hits <- send(client, target)
+		}()
+	}
+
+	wg.Wait()
+	close(hits)
+
+	return summarize(target.Name, hits, time.Since(start))
+}
+
+func send(client *http.Client, target Target) hit {
+	req, err := http.NewRequest(target.Method, target.URL, bytes.NewReader(target.Body))
+	if err != nil {
+		return hit{}
+	}
+
+	for name, values := range target.Header {
+		req.Header[name] = values
+	}
+
+	start := time.Now()
+
+	res, err := client.Do(req)
+	if err != nil {
+		return hit{latency: time.Since(start)}
+	}
+
+	// The latency includes the body, and reading it entirely lets the connection be reused.
+	_, _ = io.Copy(ioutil.Discard, res.Body)
+	_ = res.Body.Close()
+
+	return hit{statusCode: res.StatusCode, latency: time.Since(start), success: res.StatusCode == target.ExpectedStatus}
+}
+
+func summarize(name string, hits <-chan hit, duration time.Duration) (metrics Metrics) {
+	metrics = Metrics{Target: name, Duration: duration, StatusCodes: map[int]int{}}
+
+	var (
+		latencies []time.Duration
+		sum       time.Duration
+	)
+
+	for h := range hits {
+		metrics.Requests++
+		metrics.StatusCodes[h.statusCode]++
+
+		if h.success {
+			metrics.Successes++
+		}
+
+		latencies = append(latencies, h.latency)
+		sum += h.latency
+	}
+
+	if len(latencies) == 0 {
+		return metrics
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	metrics.Latencies = Latencies{
+		Mean: sum / time.Duration(len(latencies)),
+		P50:  percentile(latencies, 0.50),
+		P95:  percentile(latencies, 0.95),
+		P99:  percentile(latencies, 0.99),
+		Max:  latencies[len(latencies)-1],
+	}
+
+	return metrics
+}
+
+// percentile returns the nearest-rank percentile of the sorted latencies.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+
+	if rank < 0 {
+		rank = 0
+	}
+
+	return sorted[rank]
+}
@@ -0,0 +1,63 @@
+package loadtest
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+)
+
+// LoadBudgets reads the performance budgets per target from the YAML file.
+func LoadBudgets(path string) (budgets map[string]Budget, err error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = yaml.UnmarshalStrict(data, &budgets); err != nil {
+		return nil, fmt.Errorf("unable to parse the performance budget %s: %w", path, err)
+	}
+
+	for name, budget := range budgets {
+		if budget.Rate <= 0 || budget.Duration <= 0 {
+			return nil, fmt.Errorf("the budget of target %s must have a rate and a duration above 0", name)
+		}
+	}
+
+	return budgets, nil
+}
+
+// SuccessRatio returns the ratio of successful responses of the attack.
+func (m Metrics) SuccessRatio() float64 {
+	if m.Requests == 0 {
+		return 0
+	}
+
+	return float64(m.Successes) / float64(m.Requests)
+}
+
+// Throughput returns the successful responses per second of the attack.
+func (m Metrics) Throughput() float64 {
+	if m.Duration <= 0 {
+		return 0
+	}
+
+	return float64(m.Successes) / m.Duration.Seconds()
+}
+
+// Check returns the thresholds of the budget the metrics exceed, a threshold which is zero isn't checked.
+func (b Budget) Check(metrics Metrics) (errs []error) {
+	if ratio := metrics.SuccessRatio(); ratio < b.MinSuccessRatio {
+		errs = append(errs, fmt.Errorf("target %s: the success ratio %.4f is below the budget of %.4f", metrics.Target, ratio, b.MinSuccessRatio))
+	}
+
+	if b.MaxP95 != 0 && metrics.Latencies.P95 > b.MaxP95 {
+		errs = append(errs, fmt.Errorf("target %s: the 95th percentile latency %s is above the budget of %s", metrics.Target, metrics.Latencies.P95, b.MaxP95))
+	}
+
+	if b.MaxP99 != 0 && metrics.Latencies.P99 > b.MaxP99 {
+		errs = append(errs, fmt.Errorf("target %s: the 99th percentile latency %s is above the budget of %s", metrics.Target, metrics.Latencies.P99, b.MaxP99))
+	}
+
+	return errs
+}
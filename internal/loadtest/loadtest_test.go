@@ -0,0 +1,148 @@
+package loadtest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldAttackTargetAtConstantRate(t *testing.T) {
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Every fourth request fails.
+		if atomic.AddInt32(&requests, 1)%4 == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		assert.Equal(t, "https://secure.example.com", r.Header.Get("X-Original-URL"))
+		assert.Equal(t, "authelia_session=abc", r.Header.Get("Cookie"))
+	}))
+	defer server.Close()
+
+	target := NewVerifyTarget(server.URL, "https://secure.example.com", "authelia_session=abc")
+
+	metrics := Attack(server.Client(), target, 100, 200*time.Millisecond)
+
+	assert.Equal(t, TargetVerify, metrics.Target)
+	assert.Equal(t, 20, metrics.Requests)
+	assert.Equal(t, 15, metrics.Successes)
+	assert.Equal(t, map[int]int{http.StatusOK: 15, http.StatusUnauthorized: 5}, metrics.StatusCodes)
+	assert.Equal(t, 0.75, metrics.SuccessRatio())
+	assert.GreaterOrEqual(t, int64(metrics.Duration), int64(190*time.Millisecond))
+	assert.LessOrEqual(t, int64(metrics.Latencies.P50), int64(metrics.Latencies.P95))
+	assert.LessOrEqual(t, int64(metrics.Latencies.P99), int64(metrics.Latencies.Max))
+}
+
+func TestShouldCountRequestsFailingWithoutResponse(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	metrics := Attack(server.Client(), NewFirstFactorTarget(server.URL, "john", "password"), 50, 100*time.Millisecond)
+
+	assert.Equal(t, 5, metrics.Requests)
+	assert.Equal(t, 0, metrics.Successes)
+	assert.Equal(t, map[int]int{0: 5}, metrics.StatusCodes)
+}
+
+func TestShouldComputeNearestRankPercentiles(t *testing.T) {
+	hits := make(chan hit, 100)
+
+	for i := 1; i <= 100; i++ {
+		hits <- hit{statusCode: http.StatusOK, latency: time.Duration(i) * time.Millisecond, success: true}
+	}
+
+	close(hits)
+
+	metrics := summarize("test", hits, time.Second)
+
+	assert.Equal(t, Latencies{
+		Mean: 50500 * time.Microsecond,
+		P50:  50 * time.Millisecond,
+		P95:  95 * time.Millisecond,
+		P99:  99 * time.Millisecond,
+		Max:  100 * time.Millisecond,
+	}, metrics.Latencies)
+	assert.Equal(t, 100.0, metrics.Throughput())
+}
+
+func TestShouldCheckBudget(t *testing.T) {
+	budget := Budget{MinSuccessRatio: 0.99, MaxP95: 50 * time.Millisecond, MaxP99: 100 * time.Millisecond}
+
+	metrics := Metrics{
+		Target:    TargetVerify,
+		Requests:  100,
+		Successes: 100,
+		Latencies: Latencies{P95: 50 * time.Millisecond, P99: 100 * time.Millisecond},
+	}
+
+	assert.Empty(t, budget.Check(metrics))
+
+	metrics.Successes = 98
+	metrics.Latencies = Latencies{P95: 60 * time.Millisecond, P99: 150 * time.Millisecond}
+
+	errs := budget.Check(metrics)
+	require.Len(t, errs, 3)
+	assert.EqualError(t, errs[0], "target verify: the success ratio 0.9800 is below the budget of 0.9900")
+	assert.EqualError(t, errs[1], "target verify: the 95th percentile latency 60ms is above the budget of 50ms")
+	assert.EqualError(t, errs[2], "target verify: the 99th percentile latency 150ms is above the budget of 100ms")
+}
+
+func TestShouldLoadPublishedBudget(t *testing.T) {
+	budgets, err := LoadBudgets("budget.yml")
+	require.NoError(t, err)
+
+	assert.Equal(t, Budget{
+		Rate:            100,
+		Duration:        30 * time.Second,
+		MinSuccessRatio: 0.999,
+		MaxP95:          50 * time.Millisecond,
+		MaxP99:          100 * time.Millisecond,
+	}, budgets[TargetVerify])
+	assert.Contains(t, budgets, TargetFirstFactor)
+}
+
+func TestShouldFailToLoadInvalidBudget(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "budget.yml")
+	require.NoError(t, ioutil.WriteFile(path, []byte("verify:\n  rate: 0\n  duration: 1s\n"), 0600))
+
+	_, err := LoadBudgets(path)
+	assert.EqualError(t, err, "the budget of target verify must have a rate and a duration above 0")
+
+	require.NoError(t, ioutil.WriteFile(path, []byte("verify:\n  rte: 10\n"), 0600))
+
+	_, err = LoadBudgets(path)
+	assert.Error(t, err)
+}
+
+func TestShouldLoginAndReturnSessionCookie(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		if string(body) != `{"password":"password","username":"john"}` {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		http.SetCookie(w, &http.Cookie{Name: "authelia_session", Value: "abc"})
+	}))
+	defer server.Close()
+
+	cookie, err := Login(server.Client(), server.URL, "john", "password")
+	require.NoError(t, err)
+	assert.Equal(t, "authelia_session=abc", cookie)
+
+	_, err = Login(server.Client(), server.URL, "john", "bad")
+	assert.EqualError(t, err, "unable to authenticate user john: the server replied with status 401")
+}
@@ -0,0 +1,80 @@
+package loadtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	// TargetVerify is the name of the target verifying the requests of an authenticated user.
+	TargetVerify = "verify"
+
+	// TargetFirstFactor is the name of the target authenticating a user with the first factor.
+	TargetFirstFactor = "firstfactor"
+)
+
+// NewVerifyTarget creates the target verifying the requests of the user with the session cookie to the protected URL.
+func NewVerifyTarget(baseURL, protectedURL, cookie string) Target {
+	header := http.Header{}
+	header.Set("X-Original-URL", protectedURL)
+	header.Set("X-Forwarded-Proto", "https")
+	header.Set("Cookie", cookie)
+
+	return Target{
+		Name:           TargetVerify,
+		Method:         http.MethodGet,
+		URL:            fmt.Sprintf("%s/api/verify", baseURL),
+		Header:         header,
+		ExpectedStatus: http.StatusOK,
+	}
+}
+
+// NewFirstFactorTarget creates the target authenticating the user with the first factor.
+func NewFirstFactorTarget(baseURL, username, password string) Target {
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+
+	return Target{
+		Name:           TargetFirstFactor,
+		Method:         http.MethodPost,
+		URL:            fmt.Sprintf("%s/api/firstfactor", baseURL),
+		Header:         header,
+		Body:           firstFactorBody(username, password),
+		ExpectedStatus: http.StatusOK,
+	}
+}
+
+// Login authenticates the user with the first factor and returns the cookies of the session for the Cookie header.
+func Login(client *http.Client, baseURL, username, password string) (cookie string, err error) {
+	res, err := client.Post(fmt.Sprintf("%s/api/firstfactor", baseURL), "application/json", bytes.NewReader(firstFactorBody(username, password)))
+	if err != nil {
+		return "", err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unable to authenticate user %s: the server replied with status %d", username, res.StatusCode)
+	}
+
+	cookies := make([]string, 0, len(res.Cookies()))
+
+	for _, c := range res.Cookies() {
+		cookies = append(cookies, fmt.Sprintf("%s=%s", c.Name, c.Value))
+	}
+
+	if len(cookies) == 0 {
+		return "", fmt.Errorf("unable to authenticate user %s: the server did not set the session cookie", username)
+	}
+
+	return strings.Join(cookies, "; "), nil
+}
+
+func firstFactorBody(username, password string) []byte {
+	body, _ := json.Marshal(map[string]string{"username": username, "password": password})
+
+	return body
+}
@@ -0,0 +1,55 @@
+package loadtest
+
+import (
+	"net/http"
+	"time"
+)
+
+// Target is the request sent repeatedly during an attack.
+type Target struct {
+	Name   string
+	Method string
+	URL    string
+	Header http.Header
+	Body   []byte
+
+	// ExpectedStatus is the status code of the successful responses, any other status code is a failure.
+	ExpectedStatus int
+}
+
+// Latencies are the latencies of the responses of an attack.
+type Latencies struct {
+	Mean time.Duration
+	P50  time.Duration
+	P95  time.Duration
+	P99  time.Duration
+	Max  time.Duration
+}
+
+// Metrics summarizes the responses of an attack.
+type Metrics struct {
+	Target    string
+	Requests  int
+	Successes int
+	Duration  time.Duration
+	Latencies Latencies
+
+	// StatusCodes counts the responses per status code, the requests which failed without a response are counted
+	// with the status code 0.
+	StatusCodes map[int]int
+}
+
+// Budget is the performance budget of a target: the load it must sustain and the thresholds its responses must meet.
+type Budget struct {
+	Rate            int           `yaml:"rate"`
+	Duration        time.Duration `yaml:"duration"`
+	MinSuccessRatio float64       `yaml:"min_success_ratio"`
+	MaxP95          time.Duration `yaml:"max_p95"`
+	MaxP99          time.Duration `yaml:"max_p99"`
+}
+
+type hit struct {
+	statusCode int
+	latency    time.Duration
+	success    bool
+}
@@ -1,3 +1,15 @@
 package logging
 
 const logFormatJSON = "json"
+
+const (
+	minimizationNone     = "none"
+	minimizationHash     = "hash"
+	minimizationTruncate = "truncate"
+
+	// minimizationHashLength is the number of hexadecimal characters of the HMAC kept in the logs, enough to correlate
+	// the entries of a user.
+	minimizationHashLength = 16
+
+	minimizationMask = "***"
+)
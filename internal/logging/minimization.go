@@ -0,0 +1,236 @@
+package logging
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+var (
+	emailRegexp = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+	// The candidates are validated with net.ParseIP, the expression only finds the tokens made of hexadecimal digits
+	// separated by dots or colons.
+	ipAddressRegexp = regexp.MustCompile(`[0-9A-Fa-f]*[:.][0-9A-Fa-f:.]*[0-9A-Fa-f]`)
+)
+
+// Minimizer hashes or truncates the usernames, IP addresses and emails of the log entries and audit records. A nil
+// Minimizer leaves the data unchanged.
+type Minimizer struct {
+	usernames   string
+	ipAddresses string
+	emails      string
+
+	key []byte
+}
+
+// NewMinimizer creates a Minimizer from the configuration, it returns nil when none of the data is minimized.
+func NewMinimizer(configuration *schema.LogMinimizationConfiguration) *Minimizer {
+	if configuration == nil {
+		return nil
+	}
+
+	if isMinimizationDisabled(configuration.Usernames) && isMinimizationDisabled(configuration.IPAddresses) &&
+		isMinimizationDisabled(configuration.Emails) {
+		return nil
+	}
+
+	return &Minimizer{
+		usernames:   configuration.Usernames,
+		ipAddresses: configuration.IPAddresses,
+		emails:      configuration.Emails,
+		key:         []byte(configuration.HMACKey),
+	}
+}
+
+// Username minimizes a username, the truncated usernames keep their first two characters.
+func (m *Minimizer) Username(username string) string {
+	if m == nil || username == "" {
+		return username
+	}
+
+	switch m.usernames {
+	case minimizationHash:
+		return m.hash(username)
+	case minimizationTruncate:
+		runes := []rune(username)
+		if len(runes) <= 2 {
+			return minimizationMask
+		}
+
+		return string(runes[:2]) + minimizationMask
+	default:
+		return username
+	}
+}
+
+// IP minimizes an IP address, the truncated IPv4 addresses keep their /24 network and the IPv6 addresses their /48
+// network. The values which aren't IP addresses are left unchanged.
+func (m *Minimizer) IP(ip string) string {
+	if m == nil || ip == "" {
+		return ip
+	}
+
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil {
+		return ip
+	}
+
+	switch m.ipAddresses {
+	case minimizationHash:
+		return m.hash(parsedIP.String())
+	case minimizationTruncate:
+		if ipv4 := parsedIP.To4(); ipv4 != nil {
+			return ipv4.Mask(net.CIDRMask(24, 32)).String()
+		}
+
+		return parsedIP.Mask(net.CIDRMask(48, 128)).String()
+	default:
+		return ip
+	}
+}
+
+// Email minimizes an email address, the truncated addresses keep the first character of the local part and the domain.
+func (m *Minimizer) Email(email string) string {
+	if m == nil || email == "" {
+		return email
+	}
+
+	switch m.emails {
+	case minimizationHash:
+		return m.hash(strings.ToLower(email))
+	case minimizationTruncate:
+		i := strings.LastIndex(email, "@")
+		if i < 1 {
+			return minimizationMask
+		}
+
+		return email[:1] + minimizationMask + email[i:]
+	default:
+		return email
+	}
+}
+
+// Message minimizes the email and IP addresses found in a message. The usernames can't be told apart from the other
+// words, they are only minimized in the messages of the log entries having them as fields.
+func (m *Minimizer) Message(message string) string {
+	if m == nil {
+		return message
+	}
+
+	if !isMinimizationDisabled(m.emails) {
+		message = emailRegexp.ReplaceAllStringFunc(message, m.Email)
+	}
+
+	if !isMinimizationDisabled(m.ipAddresses) {
+		message = ipAddressRegexp.ReplaceAllStringFunc(message, m.IP)
+	}
+
+	return message
+}
+
+// Levels returns the levels of the log entries minimized by the hook, i.e. all of them.
+func (m *Minimizer) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire minimizes the fields and the message of a log entry.
+func (m *Minimizer) Fire(entry *logrus.Entry) error {
+	message := m.Message(entry.Message)
+
+	for key, value := range entry.Data {
+		s, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		var minimized string
+
+		switch key {
+		case "username", "actor", "requested_by":
+			// The actors which aren't users, e.g. the admin API tokens, are described by several words.
+			if strings.ContainsRune(s, ' ') {
+				continue
+			}
+
+			minimized = m.Username(s)
+		case "remote_ip", "source_ip":
+			minimized = m.IP(s)
+		case "email":
+			minimized = m.Email(s)
+		default:
+			continue
+		}
+
+		if minimized == s {
+			continue
+		}
+
+		entry.Data[key] = minimized
+		message = replaceWord(message, s, minimized)
+	}
+
+	entry.Message = message
+
+	return nil
+}
+
+func (m *Minimizer) hash(value string) string {
+	mac := hmac.New(sha256.New, m.key)
+	mac.Write([]byte(value))
+
+	return hex.EncodeToString(mac.Sum(nil))[:minimizationHashLength]
+}
+
+// replaceWord replaces the occurrences of old in s which aren't part of a longer word.
+func replaceWord(s, old, replacement string) string {
+	var b strings.Builder
+
+	offset := 0
+
+	for {
+		i := strings.Index(s[offset:], old)
+		if i < 0 {
+			b.WriteString(s[offset:])
+			return b.String()
+		}
+
+		start, end := offset+i, offset+i+len(old)
+		b.WriteString(s[offset:start])
+
+		if !isWordCharacter(s, start-1) && !isWordCharacter(s, end) {
+			b.WriteString(replacement)
+		} else {
+			b.WriteString(old)
+		}
+
+		offset = end
+	}
+}
+
+// isWordCharacter returns true when the character at index i of s is part of a word, the dots only are when they're
+// followed by another character of the word, e.g. in an email address but not at the end of a sentence.
+func isWordCharacter(s string, i int) bool {
+	if i < 0 || i >= len(s) {
+		return false
+	}
+
+	r := rune(s[i])
+	if r == '.' {
+		return isWordCharacter(s, i+1)
+	}
+
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '@'
+}
+
+func isMinimizationDisabled(mode string) bool {
+	return mode == "" || mode == minimizationNone
+}
@@ -0,0 +1,103 @@
+package logging
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+func TestShouldNotCreateMinimizerWhenNothingIsMinimized(t *testing.T) {
+	assert.Nil(t, NewMinimizer(nil))
+	assert.Nil(t, NewMinimizer(&schema.LogMinimizationConfiguration{Usernames: "none", IPAddresses: "none", Emails: "none"}))
+
+	var minimizer *Minimizer
+
+	assert.Equal(t, "john", minimizer.Username("john"))
+	assert.Equal(t, "192.168.1.20", minimizer.IP("192.168.1.20"))
+	assert.Equal(t, "john@example.com", minimizer.Email("john@example.com"))
+}
+
+func TestShouldHashPersonalData(t *testing.T) {
+	minimizer := NewMinimizer(&schema.LogMinimizationConfiguration{
+		Usernames:   "hash",
+		IPAddresses: "hash",
+		Emails:      "hash",
+		HMACKey:     "a-very-long-key",
+	})
+
+	username := minimizer.Username("john")
+
+	assert.Len(t, username, 16)
+	assert.NotEqual(t, "john", username)
+	assert.Equal(t, username, minimizer.Username("john"))
+	assert.NotEqual(t, username, minimizer.Username("harry"))
+
+	// The representations of an address have the same hash.
+	assert.Equal(t, minimizer.IP("2001:db8::1"), minimizer.IP("2001:0db8:0000::0001"))
+	assert.Equal(t, minimizer.Email("john@example.com"), minimizer.Email("John@Example.com"))
+
+	other := NewMinimizer(&schema.LogMinimizationConfiguration{Usernames: "hash", HMACKey: "another-long-key"})
+
+	assert.NotEqual(t, username, other.Username("john"))
+}
+
+func TestShouldTruncatePersonalData(t *testing.T) {
+	minimizer := NewMinimizer(&schema.LogMinimizationConfiguration{
+		Usernames:   "truncate",
+		IPAddresses: "truncate",
+		Emails:      "truncate",
+	})
+
+	assert.Equal(t, "jo***", minimizer.Username("john"))
+	assert.Equal(t, "***", minimizer.Username("jo"))
+	assert.Equal(t, "192.168.1.0", minimizer.IP("192.168.1.20"))
+	assert.Equal(t, "2001:db8:85a3::", minimizer.IP("2001:db8:85a3:8d3:1319:8a2e:370:7348"))
+	assert.Equal(t, "not an IP", minimizer.IP("not an IP"))
+	assert.Equal(t, "j***@example.com", minimizer.Email("john@example.com"))
+}
+
+func TestShouldMinimizeMessages(t *testing.T) {
+	minimizer := NewMinimizer(&schema.LogMinimizationConfiguration{
+		Usernames:   "truncate",
+		IPAddresses: "truncate",
+		Emails:      "truncate",
+	})
+
+	assert.Equal(t, "Sending an email to j***@example.com from 10.0.0.0 and 2001:db8:85a3:: at 12:34:56",
+		minimizer.Message("Sending an email to john@example.com from 10.0.0.5 and 2001:db8:85a3::1 at 12:34:56"))
+}
+
+func TestShouldMinimizeLogEntries(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+	logger.AddHook(NewMinimizer(&schema.LogMinimizationConfiguration{
+		Usernames:   "truncate",
+		IPAddresses: "truncate",
+		Emails:      "none",
+	}))
+
+	// The hooks are fired in order, the entry is recorded once minimized.
+	hook := test.NewLocal(logger)
+
+	logger.WithFields(logrus.Fields{
+		"username":  "john",
+		"remote_ip": "192.168.1.20",
+		"actor":     "admin API token 1a2b3c",
+	}).Info("Event login_succeeded of user john from 192.168.1.20, johnny isn't john.")
+
+	entry := hook.LastEntry()
+	require.NotNil(t, entry)
+
+	assert.Equal(t, "Event login_succeeded of user jo*** from 192.168.1.0, johnny isn't jo***.", entry.Message)
+	assert.Equal(t, logrus.Fields{
+		"username":  "jo***",
+		"remote_ip": "192.168.1.0",
+		"actor":     "admin API token 1a2b3c",
+	}, entry.Data)
+}
@@ -0,0 +1,117 @@
+package logout
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/logging"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// NewProvider creates a new Provider calling the logout URLs of the configured applications.
+func NewProvider(configuration *schema.LogoutConfiguration) (provider *Provider, err error) {
+	timeout, err := utils.ParseDurationString(configuration.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	provider = &Provider{
+		client: &http.Client{Timeout: timeout},
+	}
+
+	for _, app := range configuration.Applications {
+		a := application{
+			name:        app.Name,
+			method:      strings.ToUpper(app.Method),
+			contentType: app.ContentType,
+		}
+
+		if a.url, err = utils.NewURLTemplate(app.Name, app.URL); err != nil {
+			return nil, fmt.Errorf("unable to parse the logout url of the application %s: %w", app.Name, err)
+		}
+
+		if app.Body != "" {
+			if a.body, err = utils.NewURLTemplate(app.Name, app.Body); err != nil {
+				return nil, fmt.Errorf("unable to parse the logout body of the application %s: %w", app.Name, err)
+			}
+		}
+
+		provider.applications = append(provider.applications, a)
+	}
+
+	return provider, nil
+}
+
+// Logout calls the logout URLs of all the applications concurrently and waits for them to respond. The applications
+// failing to log the user out are logged but don't prevent the other applications from being called.
+func (p *Provider) Logout(user User) {
+	wg := sync.WaitGroup{}
+
+	for _, app := range p.applications {
+		wg.Add(1)
+
+		go func(app application) {
+			defer wg.Done()
+
+			if err := p.logout(app, user); err != nil {
+				logging.Logger().Errorf("Unable to log user %s out of the application %s: %v", user.Username, app.name, err)
+				return
+			}
+
+			logging.Logger().Debugf("User %s logged out of the application %s", user.Username, app.name)
+		}(app)
+	}
+
+	wg.Wait()
+}
+
+func (p *Provider) logout(app application, user User) error {
+	rawURL := new(bytes.Buffer)
+
+	if err := app.url.Execute(rawURL, user); err != nil {
+		return fmt.Errorf("unable to render the logout url: %w", err)
+	}
+
+	var body io.Reader
+
+	if app.body != nil {
+		buf := new(bytes.Buffer)
+
+		if err := app.body.Execute(buf, user); err != nil {
+			return fmt.Errorf("unable to render the logout body: %w", err)
+		}
+
+		body = buf
+	}
+
+	req, err := http.NewRequest(app.method, strings.TrimSpace(rawURL.String()), body)
+	if err != nil {
+		return err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", app.contentType)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	// Drain the body so the connection can be reused.
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("logout url responded with status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
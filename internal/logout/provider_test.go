@@ -0,0 +1,125 @@
+package logout
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+type recordedRequest struct {
+	method      string
+	uri         string
+	contentType string
+	body        string
+}
+
+func newRecordingServer(t *testing.T, status int) (*httptest.Server, func() []recordedRequest) {
+	var (
+		mutex    sync.Mutex
+		requests []recordedRequest
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		mutex.Lock()
+		requests = append(requests, recordedRequest{
+			method:      r.Method,
+			uri:         r.URL.RequestURI(),
+			contentType: r.Header.Get("Content-Type"),
+			body:        string(body),
+		})
+		mutex.Unlock()
+
+		w.WriteHeader(status)
+	}))
+
+	return server, func() []recordedRequest {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		return requests
+	}
+}
+
+func TestShouldCallApplicationsLogoutURLs(t *testing.T) {
+	server, requests := newRecordingServer(t, http.StatusOK)
+	defer server.Close()
+
+	provider, err := NewProvider(&schema.LogoutConfiguration{
+		Timeout: "1s",
+		Applications: []schema.LogoutApplicationConfiguration{
+			{
+				Name:   "grafana",
+				URL:    server.URL + "/grafana/logout?user={{ queryEscape .Username }}",
+				Method: "GET",
+			},
+			{
+				Name:        "wiki",
+				URL:         server.URL + "/wiki/logout",
+				Method:      "post",
+				Body:        `{"user":"{{ .Username }}","email":"{{ .Email }}"}`,
+				ContentType: "application/json",
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	provider.Logout(User{Username: "john doe", Emails: []string{"john@example.com", "doe@example.com"}})
+
+	recorded := requests()
+	require.Len(t, recorded, 2)
+	assert.ElementsMatch(t, []recordedRequest{
+		{method: "GET", uri: "/grafana/logout?user=john+doe"},
+		{method: "POST", uri: "/wiki/logout", contentType: "application/json", body: `{"user":"john doe","email":"john@example.com"}`},
+	}, recorded)
+}
+
+func TestShouldCallOtherApplicationsWhenOneFails(t *testing.T) {
+	failing, _ := newRecordingServer(t, http.StatusInternalServerError)
+	defer failing.Close()
+
+	server, requests := newRecordingServer(t, http.StatusNoContent)
+	defer server.Close()
+
+	provider, err := NewProvider(&schema.LogoutConfiguration{
+		Timeout: "1s",
+		Applications: []schema.LogoutApplicationConfiguration{
+			{Name: "failing", URL: failing.URL, Method: "GET"},
+			{Name: "unreachable", URL: "http://127.0.0.1:1/logout", Method: "GET"},
+			{Name: "app", URL: server.URL + "/logout", Method: "GET"},
+		},
+	})
+	require.NoError(t, err)
+
+	app := provider.applications[0]
+	assert.EqualError(t, provider.logout(app, User{Username: "john"}), "logout url responded with status code 500")
+
+	provider.Logout(User{Username: "john"})
+
+	assert.Len(t, requests(), 1)
+}
+
+func TestShouldFailToCreateProviderWithInvalidTemplate(t *testing.T) {
+	_, err := NewProvider(&schema.LogoutConfiguration{
+		Timeout: "1s",
+		Applications: []schema.LogoutApplicationConfiguration{
+			{Name: "app", URL: "https://app.example.com/logout?user={{ .Username", Method: "GET"},
+		},
+	})
+
+	assert.EqualError(t, err, "unable to parse the logout url of the application app: template: app:1: unclosed action")
+}
+
+func TestShouldReturnPrimaryEmail(t *testing.T) {
+	assert.Equal(t, "", User{}.Email())
+	assert.Equal(t, "john@example.com", User{Emails: []string{"john@example.com", "doe@example.com"}}.Email())
+}
@@ -0,0 +1,38 @@
+package logout
+
+import (
+	"net/http"
+	"text/template"
+)
+
+// User is the user logging out. It's available to the templates of the logout URLs and bodies of the applications,
+// e.g. {{ .Username }} or {{ .Email }}.
+type User struct {
+	Username    string
+	DisplayName string
+	Emails      []string
+	Groups      []string
+}
+
+// Email returns the primary email address of the user.
+func (u User) Email() string {
+	if len(u.Emails) == 0 {
+		return ""
+	}
+
+	return u.Emails[0]
+}
+
+// Provider logs the users out of the applications keeping their own sessions by calling their logout URLs.
+type Provider struct {
+	applications []application
+	client       *http.Client
+}
+
+type application struct {
+	name        string
+	method      string
+	contentType string
+	url         *template.Template
+	body        *template.Template
+}
@@ -0,0 +1,48 @@
+package metrics
+
+// DefaultRegistry holds the metrics of Authelia exposed by the metrics endpoint.
+var DefaultRegistry = NewRegistry()
+
+var (
+	// FirstFactorAttempts counts the first factor authentication attempts by result.
+	FirstFactorAttempts = DefaultRegistry.NewCounterVec("authelia_authentication_first_factor_total",
+		"Number of first factor authentication attempts.", "success")
+
+	// SecondFactorAttempts counts the second factor authentication attempts by method and result.
+	SecondFactorAttempts = DefaultRegistry.NewCounterVec("authelia_authentication_second_factor_total",
+		"Number of second factor authentication attempts.", "type", "success")
+
+	// RequestDuration measures the duration of the requests by route, method and status code. The route is the pattern
+	// of the matched route such as /api/verify, or an empty string when no route matched.
+	RequestDuration = DefaultRegistry.NewHistogramVec("authelia_request_duration_seconds",
+		"Duration of the requests in seconds.", DefaultBuckets, "route", "method", "code")
+
+	// LDAPBindDuration measures the duration of the binds to the LDAP server by result, including the dial.
+	LDAPBindDuration = DefaultRegistry.NewHistogramVec("authelia_authentication_ldap_bind_duration_seconds",
+		"Duration of the binds to the LDAP server in seconds.", DefaultBuckets, "success")
+
+	// LDAPPoolConnections is the number of connections of the pool of the LDAP connections by state, idle or in use.
+	LDAPPoolConnections = DefaultRegistry.NewGaugeVec("authelia_authentication_ldap_pool_connections",
+		"Number of connections of the pool of the LDAP connections.", "state")
+
+	// LDAPPoolAcquisitions counts the connections taken from the pool of the LDAP connections by whether an idle
+	// connection was reused or a new one was opened.
+	LDAPPoolAcquisitions = DefaultRegistry.NewCounterVec("authelia_authentication_ldap_pool_acquisitions_total",
+		"Number of connections taken from the pool of the LDAP connections.", "reused")
+
+	// LDAPServerUp is 1 when the LDAP server is healthy and 0 when the connections to it fail, by URL.
+	LDAPServerUp = DefaultRegistry.NewGaugeVec("authelia_authentication_ldap_server_up",
+		"Whether the LDAP server is healthy.", "url")
+
+	// LDAPServerFailures counts the failed connections to the LDAP servers by URL, including the health checks.
+	LDAPServerFailures = DefaultRegistry.NewCounterVec("authelia_authentication_ldap_server_failures_total",
+		"Number of failed connections to the LDAP servers.", "url")
+
+	// SessionOperationDuration measures the duration of the operations on the session store by operation and result.
+	SessionOperationDuration = DefaultRegistry.NewHistogramVec("authelia_session_operation_duration_seconds",
+		"Duration of the operations on the session store in seconds.", DefaultBuckets, "operation", "success")
+
+	// OIDCTokensIssued counts the requests to the OpenID Connect token endpoint by grant type and result.
+	OIDCTokensIssued = DefaultRegistry.NewCounterVec("authelia_oidc_token_issuance_total",
+		"Number of requests to the OpenID Connect token endpoint.", "grant_type", "success")
+)
@@ -0,0 +1,353 @@
+package metrics
+
+import (
+	"bytes"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/valyala/fasthttp"
+)
+
+// contentType is the content type of the Prometheus text exposition format.
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets are the upper bounds in seconds of the buckets of the duration histograms.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type collector interface {
+	name() string
+	write(buf *bytes.Buffer)
+}
+
+// Registry holds the metrics exposed in the Prometheus text exposition format.
+type Registry struct {
+	mutex      sync.Mutex
+	collectors []collector
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// NewCounterVec registers a counter partitioned by the given labels.
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{family: newFamily(name, help, labels)}
+
+	r.register(c)
+
+	return c
+}
+
+// NewGaugeVec registers a gauge partitioned by the given labels.
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{family: newFamily(name, help, labels)}
+	r.register(g)
+
+	return g
+}
+
+// NewHistogramVec registers a histogram with the given buckets partitioned by the given labels.
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{family: newFamily(name, help, labels), buckets: buckets}
+
+	r.register(h)
+
+	return h
+}
+
+func (r *Registry) register(c collector) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, registered := range r.collectors {
+		if registered.name() == c.name() {
+			panic("metric " + c.name() + " is already registered")
+		}
+	}
+
+	r.collectors = append(r.collectors, c)
+}
+
+// Bytes returns the metrics in the Prometheus text exposition format, sorted by name.
+func (r *Registry) Bytes() []byte {
+	r.mutex.Lock()
+	collectors := make([]collector, len(r.collectors))
+	copy(collectors, r.collectors)
+	r.mutex.Unlock()
+
+	sort.Slice(collectors, func(i, j int) bool {
+		return collectors[i].name() < collectors[j].name()
+	})
+
+	buf := &bytes.Buffer{}
+
+	for _, c := range collectors {
+		c.write(buf)
+	}
+
+	return buf.Bytes()
+}
+
+// Handler serves the metrics of the registry.
+func (r *Registry) Handler(ctx *fasthttp.RequestCtx) {
+	ctx.SetContentType(contentType)
+	ctx.SetBody(r.Bytes())
+}
+
+// family holds the series of a metric, one per combination of label values.
+type family struct {
+	metricName string
+	help       string
+	labels     []string
+
+	mutex  sync.Mutex
+	series map[string][]string
+}
+
+func newFamily(name, help string, labels []string) family {
+	return family{metricName: name, help: help, labels: labels, series: map[string][]string{}}
+}
+
+func (f *family) name() string {
+	return f.metricName
+}
+
+// key returns the key of the series with the given label values, f.mutex must be held.
+func (f *family) key(values []string) string {
+	if len(values) != len(f.labels) {
+		panic("metric " + f.metricName + " expects " + strconv.Itoa(len(f.labels)) + " label values")
+	}
+
+	key := strings.Join(values, "\xff")
+
+	if _, ok := f.series[key]; !ok {
+		f.series[key] = append([]string(nil), values...)
+	}
+
+	return key
+}
+
+// sortedKeys returns the keys of the series sorted by label values, f.mutex must be held.
+func (f *family) sortedKeys() []string {
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (f *family) writeHeader(buf *bytes.Buffer, metricType string) {
+	buf.WriteString("# HELP " + f.metricName + " " + escape(f.help, false) + "\n")
+	buf.WriteString("# TYPE " + f.metricName + " " + metricType + "\n")
+}
+
+// writeSample writes a sample of the series with the given label values and the extra label if any.
+func (f *family) writeSample(buf *bytes.Buffer, suffix string, values []string, extraLabel, extraValue string, value float64) {
+	buf.WriteString(f.metricName + suffix)
+
+	if len(values) != 0 || extraLabel != "" {
+		buf.WriteByte('{')
+
+		for i, label := range f.labels {
+			if i != 0 {
+				buf.WriteByte(',')
+			}
+
+			buf.WriteString(label + `="` + escape(values[i], true) + `"`)
+		}
+
+		if extraLabel != "" {
+			if len(values) != 0 {
+				buf.WriteByte(',')
+			}
+
+			buf.WriteString(extraLabel + `="` + extraValue + `"`)
+		}
+
+		buf.WriteByte('}')
+	}
+
+	buf.WriteString(" " + formatFloat(value) + "\n")
+}
+
+// CounterVec is a counter partitioned by labels.
+type CounterVec struct {
+	family
+
+	values map[string]float64
+}
+
+// Inc increments the counter of the series with the given label values.
+func (c *CounterVec) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add adds the given positive value to the counter of the series with the given label values.
+func (c *CounterVec) Add(value float64, values ...string) {
+	if value < 0 {
+		panic("counter " + c.metricName + " can't be decreased")
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.values == nil {
+		c.values = map[string]float64{}
+	}
+
+	c.values[c.key(values)] += value
+}
+
+// Value returns the counter of the series with the given label values.
+func (c *CounterVec) Value(values ...string) float64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.values[strings.Join(values, "\xff")]
+}
+
+func (c *CounterVec) write(buf *bytes.Buffer) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.writeHeader(buf, "counter")
+
+	for _, key := range c.sortedKeys() {
+		c.writeSample(buf, "", c.series[key], "", "", c.values[key])
+	}
+}
+
+// GaugeVec is a gauge partitioned by labels.
+type GaugeVec struct {
+	family
+
+	values map[string]float64
+}
+
+// Set sets the gauge of the series with the given label values.
+func (g *GaugeVec) Set(value float64, values ...string) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.values == nil {
+		g.values = map[string]float64{}
+	}
+
+	g.values[g.key(values)] = value
+}
+
+// Value returns the gauge of the series with the given label values.
+func (g *GaugeVec) Value(values ...string) float64 {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	return g.values[strings.Join(values, "\xff")]
+}
+
+func (g *GaugeVec) write(buf *bytes.Buffer) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.writeHeader(buf, "gauge")
+
+	for _, key := range g.sortedKeys() {
+		g.writeSample(buf, "", g.series[key], "", "", g.values[key])
+	}
+}
+
+// HistogramVec is a histogram partitioned by labels.
+type HistogramVec struct {
+	family
+
+	buckets []float64
+	values  map[string]*histogram
+}
+
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// Observe adds the given value to the histogram of the series with the given label values.
+func (h *HistogramVec) Observe(value float64, values ...string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.values == nil {
+		h.values = map[string]*histogram{}
+	}
+
+	key := h.key(values)
+
+	s, ok := h.values[key]
+	if !ok {
+		s = &histogram{counts: make([]uint64, len(h.buckets))}
+		h.values[key] = s
+	}
+
+	for i, bound := range h.buckets {
+		if value <= bound {
+			s.counts[i]++
+		}
+	}
+
+	s.count++
+	s.sum += value
+}
+
+// Count returns the number of values observed by the series with the given label values.
+func (h *HistogramVec) Count(values ...string) uint64 {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if s, ok := h.values[strings.Join(values, "\xff")]; ok {
+		return s.count
+	}
+
+	return 0
+}
+
+func (h *HistogramVec) write(buf *bytes.Buffer) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.writeHeader(buf, "histogram")
+
+	for _, key := range h.sortedKeys() {
+		values, s := h.series[key], h.values[key]
+
+		for i, bound := range h.buckets {
+			h.writeSample(buf, "_bucket", values, "le", formatFloat(bound), float64(s.counts[i]))
+		}
+
+		h.writeSample(buf, "_bucket", values, "le", "+Inf", float64(s.count))
+		h.writeSample(buf, "_sum", values, "", "", s.sum)
+		h.writeSample(buf, "_count", values, "", "", float64(s.count))
+	}
+}
+
+func formatFloat(value float64) string {
+	if math.IsInf(value, 1) {
+		return "+Inf"
+	}
+
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// escape escapes the backslashes and the line feeds, and the double quotes of the label values.
+func escape(value string, quotes bool) string {
+	replacements := []string{`\`, `\\`, "\n", `\n`}
+	if quotes {
+		replacements = append(replacements, `"`, `\"`)
+	}
+
+	return strings.NewReplacer(replacements...).Replace(value)
+}
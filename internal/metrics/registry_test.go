@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestShouldExposeCountersAndHistograms(t *testing.T) {
+	registry := NewRegistry()
+
+	histogram := registry.NewHistogramVec("test_duration_seconds", "Duration of the tests.", []float64{0.1, 1}, "name")
+	counter := registry.NewCounterVec("test_total", "Number of \"tests\".", "success")
+
+	counter.Inc("true")
+	counter.Add(2, "true")
+	counter.Inc("false")
+
+	histogram.Observe(0.05, `quoted "name"`)
+	histogram.Observe(0.5, `quoted "name"`)
+
+	assert.Equal(t, float64(3), counter.Value("true"))
+	assert.Equal(t, uint64(2), histogram.Count(`quoted "name"`))
+	assert.Equal(t, uint64(0), histogram.Count("other"))
+
+	expected := `# HELP test_duration_seconds Duration of the tests.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{name="quoted \"name\"",le="0.1"} 1
+test_duration_seconds_bucket{name="quoted \"name\"",le="1"} 2
+test_duration_seconds_bucket{name="quoted \"name\"",le="+Inf"} 2
+test_duration_seconds_sum{name="quoted \"name\""} 0.55
+test_duration_seconds_count{name="quoted \"name\""} 2
+# HELP test_total Number of "tests".
+# TYPE test_total counter
+test_total{success="false"} 1
+test_total{success="true"} 3
+`
+
+	assert.Equal(t, expected, string(registry.Bytes()))
+}
+
+func TestShouldExposeGauges(t *testing.T) {
+	registry := NewRegistry()
+
+	gauge := registry.NewGaugeVec("test_connections", "Number of connections.", "state")
+
+	gauge.Set(3, "idle")
+	gauge.Set(2, "in_use")
+	gauge.Set(1, "idle")
+
+	assert.Equal(t, float64(1), gauge.Value("idle"))
+	assert.Equal(t, float64(0), gauge.Value("other"))
+
+	expected := `# HELP test_connections Number of connections.
+# TYPE test_connections gauge
+test_connections{state="idle"} 1
+test_connections{state="in_use"} 2
+`
+
+	assert.Equal(t, expected, string(registry.Bytes()))
+}
+
+func TestShouldServeMetrics(t *testing.T) {
+	registry := NewRegistry()
+	registry.NewCounterVec("test_total", "Number of tests.").Inc()
+
+	ctx := &fasthttp.RequestCtx{}
+	registry.Handler(ctx)
+
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", string(ctx.Response.Header.ContentType()))
+	assert.Equal(t, "# HELP test_total Number of tests.\n# TYPE test_total counter\ntest_total 1\n", string(ctx.Response.Body()))
+}
+
+func TestShouldPanicOnDuplicateOrWrongLabels(t *testing.T) {
+	registry := NewRegistry()
+	counter := registry.NewCounterVec("test_total", "Number of tests.", "success")
+
+	assert.Panics(t, func() { registry.NewCounterVec("test_total", "Number of tests.") })
+	assert.Panics(t, func() { counter.Inc() })
+	assert.Panics(t, func() { counter.Add(-1, "true") })
+}
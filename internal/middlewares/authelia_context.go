@@ -1,6 +1,7 @@
 package middlewares
 
 import (
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"net"
@@ -8,10 +9,12 @@ import (
 	"strings"
 
 	"github.com/asaskevich/govalidator"
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"github.com/valyala/fasthttp"
 
 	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/events"
 	"github.com/authelia/authelia/internal/session"
 	"github.com/authelia/authelia/internal/utils"
 )
@@ -37,9 +40,14 @@ func NewAutheliaCtx(ctx *fasthttp.RequestCtx, configuration schema.Configuration
 	return autheliaCtx, nil
 }
 
-// AutheliaMiddleware is wrapping the RequestCtx into an AutheliaCtx providing Authelia related objects.
-func AutheliaMiddleware(configuration schema.Configuration, providers Providers) RequestHandlerBridge {
+// AutheliaMiddleware is wrapping the RequestCtx into an AutheliaCtx providing Authelia related objects. The optional
+// interceptors are applied in order to every handler bridged by the returned RequestHandlerBridge.
+func AutheliaMiddleware(configuration schema.Configuration, providers Providers, interceptors ...Middleware) RequestHandlerBridge {
+	chain := NewChain(interceptors...)
+
 	return func(next RequestHandler) fasthttp.RequestHandler {
+		next = chain.Then(next)
+
 		return func(ctx *fasthttp.RequestCtx) {
 			autheliaCtx, err := NewAutheliaCtx(ctx, configuration, providers)
 			if err != nil {
@@ -158,7 +166,8 @@ func (c *AutheliaCtx) ReplyOK() {
 	c.SetBody(okMessageBytes)
 }
 
-// ParseBody parse the request body into the type of value.
+// ParseBody parse the request body into the type of value. When the body can be decoded but fails validation the
+// returned error is a *BodyValidationError which contains the error of each invalid field.
 func (c *AutheliaCtx) ParseBody(value interface{}) error {
 	err := json.Unmarshal(c.PostBody(), &value)
 
@@ -169,7 +178,7 @@ func (c *AutheliaCtx) ParseBody(value interface{}) error {
 	valid, err := govalidator.ValidateStruct(value)
 
 	if err != nil {
-		return fmt.Errorf("Unable to validate body: %s", err)
+		return &BodyValidationError{Fields: govalidator.ErrorsByField(err), err: err}
 	}
 
 	if !valid {
@@ -181,12 +190,75 @@ func (c *AutheliaCtx) ParseBody(value interface{}) error {
 
 // SetJSONBody Set json body.
 func (c *AutheliaCtx) SetJSONBody(value interface{}) error {
+	return c.setJSON(OKResponse{Status: "OK", Data: value})
+}
+
+// SetJSONBodyWithStatus sets the status code and the json body wrapped in the OKResponse envelope.
+func (c *AutheliaCtx) SetJSONBodyWithStatus(statusCode int, value interface{}) error {
+	if err := c.SetJSONBody(value); err != nil {
+		return err
+	}
+
+	c.SetStatusCode(statusCode)
+
+	return nil
+}
+
+// SetJSONRawBody sets the json body without the OKResponse envelope, this is intended for endpoints which must follow
+// a format defined by a specification such as the OpenID Connect discovery document.
+func (c *AutheliaCtx) SetJSONRawBody(value interface{}) error {
+	return c.setJSON(value)
+}
+
+// SetConditionalJSONBody sets the json body wrapped in the OKResponse envelope along with its ETag. The client must
+// revalidate the body on each request and a 304 Not Modified is replied without the body when the ETag of the body it
+// already has still matches.
+func (c *AutheliaCtx) SetConditionalJSONBody(value interface{}) error {
 	b, err := json.Marshal(OKResponse{Status: "OK", Data: value})
 	if err != nil {
-		return fmt.Errorf("Unable to marshal JSON body")
+		return fmt.Errorf("Unable to marshal JSON body: %w", err)
 	}
 
-	c.SetContentType("application/json")
+	sum := sha256.Sum256(b)
+	etag := fmt.Sprintf("\"%x\"", sum[:16])
+
+	c.Response.Header.Set(headerETag, etag)
+	c.Response.Header.Set(headerCacheControl, "private, no-cache")
+	c.Response.Header.Set(headerVary, "Cookie")
+
+	if isETagMatching(c.Request.Header.Peek(headerIfNoneMatch), etag) {
+		c.SetStatusCode(fasthttp.StatusNotModified)
+		c.ResetBody()
+
+		return nil
+	}
+
+	c.SetContentType(applicationJSONContentType)
+	c.SetBody(b)
+
+	return nil
+}
+
+// isETagMatching checks whether the ETag is one of the ETags of the If-None-Match header, using the weak comparison.
+func isETagMatching(ifNoneMatch []byte, etag string) bool {
+	for _, candidate := range strings.Split(string(ifNoneMatch), ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *AutheliaCtx) setJSON(value interface{}) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("Unable to marshal JSON body: %w", err)
+	}
+
+	c.SetContentType(applicationJSONContentType)
 	c.SetBody(b)
 
 	return nil
@@ -199,13 +271,96 @@ func (c *AutheliaCtx) RemoteIP() net.IP {
 		ips := strings.Split(string(XForwardedFor), ",")
 
 		if len(ips) > 0 {
-			return net.ParseIP(strings.Trim(ips[0], " "))
+			return utils.ParseIP(ips[0])
 		}
 	}
 
 	return c.RequestCtx.RemoteIP()
 }
 
+// ClientMetadata returns the metadata describing the client which made the current request.
+func (c *AutheliaCtx) ClientMetadata() session.ClientMetadata {
+	userAgent := string(c.UserAgent())
+	remoteIP := c.RemoteIP()
+
+	return session.ClientMetadata{
+		RemoteIP:  remoteIP.String(),
+		Location:  c.GeoIPLocation(remoteIP),
+		UserAgent: userAgent,
+		Device:    utils.ParseUserAgent(userAgent).String(),
+		Timestamp: c.Clock.Now().Unix(),
+	}
+}
+
+// RequestID returns the ID of the current request, the one provided by the proxy in the X-Request-Id header if it's
+// valid, otherwise a random one generated on the first call.
+func (c *AutheliaCtx) RequestID() string {
+	if id, ok := c.UserValue(requestIDUserValue).(string); ok {
+		return id
+	}
+
+	id := string(c.Request.Header.Peek(xRequestIDHeader))
+	if !isValidRequestID(id) {
+		id = uuid.New().String()
+	}
+
+	c.SetUserValue(requestIDUserValue, id)
+
+	return id
+}
+
+// isValidRequestID determines if the request ID is short and made of characters safe to log, i.e. letters, digits and
+// the separators '-', '_', '.' and ':'.
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.', r == ':':
+			continue
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// PublishEvent publishes an event of the given type concerning the user, made by the client of the current request.
+func (c *AutheliaCtx) PublishEvent(eventType events.Type, username string, details map[string]string) {
+	c.Providers.Events.Publish(events.Event{
+		Type:      eventType,
+		Time:      c.Clock.Now(),
+		Username:  username,
+		RemoteIP:  c.RemoteIP().String(),
+		UserAgent: string(c.UserAgent()),
+		RequestID: c.RequestID(),
+		Details:   details,
+	})
+}
+
+// GeoIPLocation returns a human readable location of the given IP if GeoIP is configured, otherwise it's empty.
+func (c *AutheliaCtx) GeoIPLocation(ip net.IP) string {
+	if c.Providers.GeoIP == nil {
+		return ""
+	}
+
+	location, err := c.Providers.GeoIP.Lookup(ip)
+	if err != nil {
+		c.Logger.Warnf("Unable to lookup the GeoIP location of %s: %v", ip, err)
+
+		return ""
+	}
+
+	if location == nil {
+		return ""
+	}
+
+	return location.String()
+}
+
 // GetOriginalURL extract the URL from the request headers (X-Original-URI or X-Forwarded-* headers).
 func (c *AutheliaCtx) GetOriginalURL() (*url.URL, error) {
 	originalURL := c.XOriginalURL()
@@ -1,14 +1,19 @@
 package middlewares_test
 
 import (
+	"errors"
+	"net"
 	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/valyala/fasthttp"
 
 	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/geoip"
 	"github.com/authelia/authelia/internal/middlewares"
 	"github.com/authelia/authelia/internal/mocks"
 	"github.com/authelia/authelia/internal/session"
@@ -35,6 +40,31 @@ func TestShouldCallNextWithAutheliaCtx(t *testing.T) {
 	assert.True(t, nextCalled)
 }
 
+func TestShouldCallInterceptorsBeforeNext(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := &fasthttp.RequestCtx{}
+	configuration := schema.Configuration{}
+	providers := middlewares.Providers{
+		UserProvider:    mocks.NewMockUserProvider(ctrl),
+		SessionProvider: session.NewProvider(configuration.Session, nil),
+	}
+
+	var calls []string
+
+	interceptor := func(next middlewares.RequestHandler) middlewares.RequestHandler {
+		return func(actx *middlewares.AutheliaCtx) {
+			calls = append(calls, "interceptor")
+			next(actx)
+		}
+	}
+
+	middlewares.AutheliaMiddleware(configuration, providers, interceptor)(func(actx *middlewares.AutheliaCtx) {
+		calls = append(calls, "next")
+	})(ctx)
+
+	assert.Equal(t, []string{"interceptor", "next"}, calls)
+}
+
 // Test getOriginalURL.
 func TestShouldGetOriginalURLFromOriginalURLHeader(t *testing.T) {
 	mock := mocks.NewMockAutheliaCtx(t)
@@ -70,3 +100,169 @@ func TestShouldGetOriginalURLFromForwardedHeadersWithURI(t *testing.T) {
 	assert.Error(t, err)
 	assert.Equal(t, "Unable to parse URL extracted from X-Original-URL header: parse \"htt-ps//home?-.example.com\": invalid URI for request", err.Error())
 }
+
+func TestShouldReturnFieldErrorsWhenBodyIsInvalid(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	body := struct {
+		Username string `json:"username" valid:"required"`
+		Password string `json:"password" valid:"required"`
+	}{}
+
+	mock.Ctx.Request.SetBodyString(`{"username":"john"}`)
+
+	err := mock.Ctx.ParseBody(&body)
+	require.Error(t, err)
+	assert.Equal(t, "Unable to validate body: password: non zero value required", err.Error())
+
+	var validationErr *middlewares.BodyValidationError
+
+	require.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, map[string]string{"password": "non zero value required"}, validationErr.Fields)
+}
+
+func TestShouldSetJSONBodyWithStatus(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	err := mock.Ctx.SetJSONBodyWithStatus(fasthttp.StatusCreated, map[string]string{"key": "value"})
+	require.NoError(t, err)
+
+	assert.Equal(t, fasthttp.StatusCreated, mock.Ctx.Response.StatusCode())
+	assert.Equal(t, "application/json", string(mock.Ctx.Response.Header.ContentType()))
+	assert.Equal(t, `{"status":"OK","data":{"key":"value"}}`, string(mock.Ctx.Response.Body()))
+}
+
+func TestShouldSetJSONRawBody(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	err := mock.Ctx.SetJSONRawBody(map[string]string{"key": "value"})
+	require.NoError(t, err)
+
+	assert.Equal(t, fasthttp.StatusOK, mock.Ctx.Response.StatusCode())
+	assert.Equal(t, `{"key":"value"}`, string(mock.Ctx.Response.Body()))
+
+	err = mock.Ctx.SetJSONRawBody(make(chan int))
+	assert.Error(t, err)
+}
+
+func TestShouldSetConditionalJSONBody(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	err := mock.Ctx.SetConditionalJSONBody(map[string]string{"key": "value"})
+	require.NoError(t, err)
+
+	etag := string(mock.Ctx.Response.Header.Peek("ETag"))
+
+	assert.Equal(t, fasthttp.StatusOK, mock.Ctx.Response.StatusCode())
+	assert.Regexp(t, `^"[0-9a-f]{32}"$`, etag)
+	assert.Equal(t, "private, no-cache", string(mock.Ctx.Response.Header.Peek("Cache-Control")))
+	assert.Equal(t, `{"status":"OK","data":{"key":"value"}}`, string(mock.Ctx.Response.Body()))
+
+	mock.Ctx.Response.Reset()
+	mock.Ctx.Request.Header.Set("If-None-Match", `"another", W/`+etag)
+
+	err = mock.Ctx.SetConditionalJSONBody(map[string]string{"key": "value"})
+	require.NoError(t, err)
+
+	assert.Equal(t, fasthttp.StatusNotModified, mock.Ctx.Response.StatusCode())
+	assert.Equal(t, etag, string(mock.Ctx.Response.Header.Peek("ETag")))
+	assert.Empty(t, mock.Ctx.Response.Body())
+
+	mock.Ctx.Response.Reset()
+
+	err = mock.Ctx.SetConditionalJSONBody(map[string]string{"key": "changed"})
+	require.NoError(t, err)
+
+	assert.Equal(t, fasthttp.StatusOK, mock.Ctx.Response.StatusCode())
+	assert.NotEqual(t, etag, string(mock.Ctx.Response.Header.Peek("ETag")))
+	assert.Equal(t, `{"status":"OK","data":{"key":"changed"}}`, string(mock.Ctx.Response.Body()))
+}
+
+type staticGeoIPProvider map[string]geoip.Location
+
+func (p staticGeoIPProvider) Lookup(ip net.IP) (*geoip.Location, error) {
+	location, ok := p[ip.String()]
+	if !ok {
+		return nil, nil
+	}
+
+	return &location, nil
+}
+
+func TestShouldEnrichClientMetadataWithGeoIPLocation(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	mock.Ctx.Request.Header.Set("X-Forwarded-For", "192.168.1.10")
+	mock.Ctx.Request.Header.SetUserAgent("Mozilla/5.0 (X11; Linux x86_64; rv:88.0) Gecko/20100101 Firefox/88.0")
+
+	client := mock.Ctx.ClientMetadata()
+	assert.Equal(t, "192.168.1.10", client.RemoteIP)
+	assert.Equal(t, "", client.Location)
+	assert.Equal(t, "Firefox on Linux", client.Device)
+
+	mock.Ctx.Providers.GeoIP = staticGeoIPProvider{
+		"192.168.1.10": {Country: "France", City: "Paris"},
+	}
+
+	client = mock.Ctx.ClientMetadata()
+	assert.Equal(t, "Paris, France", client.Location)
+
+	mock.Ctx.Request.Header.Set("X-Forwarded-For", "10.0.0.1")
+
+	client = mock.Ctx.ClientMetadata()
+	assert.Equal(t, "", client.Location)
+}
+
+func TestShouldParseIPv6RemoteIPFromForwardedFor(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	mock.Ctx.Request.Header.Set("X-Forwarded-For", "[fe80::1%eth0]:4321, 10.0.0.1")
+	assert.Equal(t, "fe80::1", mock.Ctx.RemoteIP().String())
+
+	mock.Ctx.Request.Header.Set("X-Forwarded-For", "2001:db8::1")
+	assert.Equal(t, "2001:db8::1", mock.Ctx.RemoteIP().String())
+}
+
+func TestShouldUseRequestIDFromProxy(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	mock.Ctx.Request.Header.Set("X-Request-Id", "7f3c9a2e-1b4d:frontend.01")
+
+	assert.Equal(t, "7f3c9a2e-1b4d:frontend.01", mock.Ctx.RequestID())
+}
+
+func TestShouldGenerateRequestIDWhenProxyIDIsInvalid(t *testing.T) {
+	testCases := []struct {
+		name string
+		id   string
+	}{
+		{"Missing", ""},
+		{"InvalidCharacters", "abc\ninjected=true"},
+		{"TooLong", strings.Repeat("a", 129)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock := mocks.NewMockAutheliaCtx(t)
+			defer mock.Close()
+
+			if tc.id != "" {
+				mock.Ctx.Request.Header.Set("X-Request-Id", tc.id)
+			}
+
+			id := mock.Ctx.RequestID()
+			assert.Len(t, id, 36)
+			assert.NotEqual(t, tc.id, id)
+
+			// The generated ID is kept for the whole request.
+			assert.Equal(t, id, mock.Ctx.RequestID())
+		})
+	}
+}
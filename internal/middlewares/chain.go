@@ -0,0 +1,25 @@
+package middlewares
+
+// NewChain creates a new Chain from the provided middlewares.
+func NewChain(middlewares ...Middleware) Chain {
+	return append(Chain{}, middlewares...)
+}
+
+// Append returns a new Chain with the provided middlewares added after the existing ones. The original Chain is left
+// untouched so a base Chain can safely be shared between routes.
+func (c Chain) Append(middlewares ...Middleware) Chain {
+	chain := make(Chain, 0, len(c)+len(middlewares))
+
+	chain = append(chain, c...)
+
+	return append(chain, middlewares...)
+}
+
+// Then applies the Chain to the provided RequestHandler.
+func (c Chain) Then(handler RequestHandler) RequestHandler {
+	for i := len(c) - 1; i >= 0; i-- {
+		handler = c[i](handler)
+	}
+
+	return handler
+}
@@ -0,0 +1,56 @@
+package middlewares
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newRecordingMiddleware(name string, calls *[]string) Middleware {
+	return func(next RequestHandler) RequestHandler {
+		return func(ctx *AutheliaCtx) {
+			*calls = append(*calls, name)
+			next(ctx)
+		}
+	}
+}
+
+func TestShouldApplyChainInOrder(t *testing.T) {
+	var calls []string
+
+	chain := NewChain(newRecordingMiddleware("first", &calls), newRecordingMiddleware("second", &calls))
+
+	chain.Then(func(ctx *AutheliaCtx) {
+		calls = append(calls, "handler")
+	})(&AutheliaCtx{})
+
+	assert.Equal(t, []string{"first", "second", "handler"}, calls)
+}
+
+func TestShouldNotModifyChainOnAppend(t *testing.T) {
+	var calls []string
+
+	base := NewChain(newRecordingMiddleware("base", &calls))
+	extended := base.Append(newRecordingMiddleware("extended", &calls))
+
+	assert.Len(t, base, 1)
+	assert.Len(t, extended, 2)
+
+	base.Then(func(ctx *AutheliaCtx) {})(&AutheliaCtx{})
+	assert.Equal(t, []string{"base"}, calls)
+
+	calls = nil
+
+	extended.Then(func(ctx *AutheliaCtx) {})(&AutheliaCtx{})
+	assert.Equal(t, []string{"base", "extended"}, calls)
+}
+
+func TestShouldCallHandlerWithEmptyChain(t *testing.T) {
+	called := false
+
+	NewChain().Then(func(ctx *AutheliaCtx) {
+		called = true
+	})(&AutheliaCtx{})
+
+	assert.True(t, called)
+}
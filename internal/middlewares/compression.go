@@ -0,0 +1,105 @@
+package middlewares
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+// CompressionMiddleware compresses the responses of the next handler with the first algorithm of the configuration
+// accepted by the client. Only the responses of the configured content types and at least as large as the minimum size
+// are compressed, streamed responses such as the server-sent events are never compressed.
+func CompressionMiddleware(configuration schema.ServerCompressionConfiguration, next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		next(ctx)
+
+		if !isResponseCompressible(ctx, configuration) {
+			return
+		}
+
+		appendVaryHeader(&ctx.Response, headerAcceptEncoding)
+
+		for _, algorithm := range configuration.Algorithms {
+			if !ctx.Request.Header.HasAcceptEncoding(algorithm) {
+				continue
+			}
+
+			body := ctx.Response.Body()
+
+			switch algorithm {
+			case compressionBrotli:
+				ctx.Response.SetBodyRaw(fasthttp.AppendBrotliBytesLevel(nil, body, fasthttp.CompressBrotliDefaultCompression))
+			case compressionGzip:
+				ctx.Response.SetBodyRaw(fasthttp.AppendGzipBytesLevel(nil, body, fasthttp.CompressDefaultCompression))
+			default:
+				continue
+			}
+
+			ctx.Response.Header.Set(fasthttp.HeaderContentEncoding, algorithm)
+
+			return
+		}
+	}
+}
+
+func isResponseCompressible(ctx *fasthttp.RequestCtx, configuration schema.ServerCompressionConfiguration) bool {
+	if ctx.IsHead() || ctx.Response.IsBodyStream() {
+		return false
+	}
+
+	switch ctx.Response.StatusCode() {
+	case fasthttp.StatusNoContent, fasthttp.StatusNotModified:
+		return false
+	}
+
+	if len(ctx.Response.Header.Peek(fasthttp.HeaderContentEncoding)) != 0 {
+		return false
+	}
+
+	if len(ctx.Response.Body()) < configuration.MinimumSize {
+		return false
+	}
+
+	return isContentTypeCompressible(ctx.Response.Header.ContentType(), configuration.ContentTypes)
+}
+
+// isContentTypeCompressible checks the media type of the content type, ignoring its parameters, against the
+// configured media types which can end with a wildcard subtype such as text/*.
+func isContentTypeCompressible(contentType []byte, contentTypes []string) bool {
+	if i := bytes.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+
+	mediaType := strings.ToLower(strings.TrimSpace(string(contentType)))
+
+	for _, t := range contentTypes {
+		if t == mediaType {
+			return true
+		}
+
+		if strings.HasSuffix(t, "/*") && strings.HasPrefix(mediaType, t[:len(t)-1]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func appendVaryHeader(response *fasthttp.Response, header string) {
+	vary := response.Header.Peek(headerVary)
+	if len(vary) == 0 {
+		response.Header.Set(headerVary, header)
+		return
+	}
+
+	for _, v := range strings.Split(string(vary), ",") {
+		if strings.EqualFold(strings.TrimSpace(v), header) {
+			return
+		}
+	}
+
+	response.Header.Set(headerVary, string(vary)+", "+header)
+}
@@ -0,0 +1,117 @@
+package middlewares
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+var compressibleBody = strings.Repeat("{\"status\":\"OK\"}", 100)
+
+func newCompressionTestCtx(acceptEncoding string) *fasthttp.RequestCtx {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod(fasthttp.MethodGet)
+	ctx.Request.Header.Set(headerAcceptEncoding, acceptEncoding)
+
+	return ctx
+}
+
+func jsonHandler(body string) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		ctx.SetContentType("application/json; charset=utf-8")
+		ctx.SetBodyString(body)
+	}
+}
+
+func TestShouldCompressWithTheFirstAcceptedAlgorithm(t *testing.T) {
+	ctx := newCompressionTestCtx("gzip, deflate, br")
+	CompressionMiddleware(schema.DefaultServerCompressionConfiguration, jsonHandler(compressibleBody))(ctx)
+
+	assert.Equal(t, "br", string(ctx.Response.Header.Peek(fasthttp.HeaderContentEncoding)))
+	assert.Equal(t, "Accept-Encoding", string(ctx.Response.Header.Peek(headerVary)))
+
+	body, err := ctx.Response.BodyUnbrotli()
+	require.NoError(t, err)
+	assert.Equal(t, compressibleBody, string(body))
+
+	ctx = newCompressionTestCtx("gzip")
+	CompressionMiddleware(schema.DefaultServerCompressionConfiguration, jsonHandler(compressibleBody))(ctx)
+
+	assert.Equal(t, "gzip", string(ctx.Response.Header.Peek(fasthttp.HeaderContentEncoding)))
+
+	body, err = ctx.Response.BodyGunzip()
+	require.NoError(t, err)
+	assert.Equal(t, compressibleBody, string(body))
+}
+
+func TestShouldNotCompressWhenNoAlgorithmIsAccepted(t *testing.T) {
+	ctx := newCompressionTestCtx("deflate")
+	CompressionMiddleware(schema.DefaultServerCompressionConfiguration, jsonHandler(compressibleBody))(ctx)
+
+	assert.Len(t, ctx.Response.Header.Peek(fasthttp.HeaderContentEncoding), 0)
+	assert.Equal(t, "Accept-Encoding", string(ctx.Response.Header.Peek(headerVary)))
+	assert.Equal(t, compressibleBody, string(ctx.Response.Body()))
+}
+
+func TestShouldNotCompressSmallResponses(t *testing.T) {
+	ctx := newCompressionTestCtx("br, gzip")
+	CompressionMiddleware(schema.DefaultServerCompressionConfiguration, jsonHandler("{\"status\":\"OK\"}"))(ctx)
+
+	assert.Len(t, ctx.Response.Header.Peek(fasthttp.HeaderContentEncoding), 0)
+	assert.Len(t, ctx.Response.Header.Peek(headerVary), 0)
+	assert.Equal(t, "{\"status\":\"OK\"}", string(ctx.Response.Body()))
+}
+
+func TestShouldNotCompressOtherContentTypes(t *testing.T) {
+	ctx := newCompressionTestCtx("br, gzip")
+	CompressionMiddleware(schema.DefaultServerCompressionConfiguration, func(ctx *fasthttp.RequestCtx) {
+		ctx.SetContentType("image/png")
+		ctx.SetBodyString(compressibleBody)
+	})(ctx)
+
+	assert.Len(t, ctx.Response.Header.Peek(fasthttp.HeaderContentEncoding), 0)
+	assert.Equal(t, compressibleBody, string(ctx.Response.Body()))
+}
+
+func TestShouldCompressWildcardContentTypes(t *testing.T) {
+	configuration := schema.ServerCompressionConfiguration{
+		Algorithms:   []string{"gzip"},
+		MinimumSize:  1,
+		ContentTypes: []string{"text/*"},
+	}
+
+	ctx := newCompressionTestCtx("br, gzip")
+	CompressionMiddleware(configuration, func(ctx *fasthttp.RequestCtx) {
+		ctx.SetContentType("text/css")
+		ctx.SetBodyString("body {}")
+	})(ctx)
+
+	assert.Equal(t, "gzip", string(ctx.Response.Header.Peek(fasthttp.HeaderContentEncoding)))
+}
+
+func TestShouldNotCompressStreamedResponses(t *testing.T) {
+	ctx := newCompressionTestCtx("br, gzip")
+	CompressionMiddleware(schema.DefaultServerCompressionConfiguration, func(ctx *fasthttp.RequestCtx) {
+		ctx.SetContentType("application/json")
+		ctx.SetBodyStream(bytes.NewReader([]byte(compressibleBody)), -1)
+	})(ctx)
+
+	assert.Len(t, ctx.Response.Header.Peek(fasthttp.HeaderContentEncoding), 0)
+	assert.True(t, ctx.Response.IsBodyStream())
+}
+
+func TestShouldAppendToTheVaryHeader(t *testing.T) {
+	ctx := newCompressionTestCtx("gzip")
+	CompressionMiddleware(schema.DefaultServerCompressionConfiguration, func(ctx *fasthttp.RequestCtx) {
+		ctx.Response.Header.Set(headerVary, "Cookie")
+		jsonHandler(compressibleBody)(ctx)
+	})(ctx)
+
+	assert.Equal(t, "Cookie, Accept-Encoding", string(ctx.Response.Header.Peek(headerVary)))
+}
@@ -1,5 +1,7 @@
 package middlewares
 
+import "time"
+
 const jwtIssuer = "Authelia"
 
 const xForwardedProtoHeader = "X-Forwarded-Proto"
@@ -9,8 +11,36 @@ const xForwardedURIHeader = "X-Forwarded-URI"
 
 const xOriginalURLHeader = "X-Original-URL"
 
+const xRequestIDHeader = "X-Request-Id"
+
+// requestIDUserValue is the key of the user value holding the ID of the request.
+const requestIDUserValue = "request_id"
+
+// maxRequestIDLength is the maximum length of the request IDs provided by the proxy.
+const maxRequestIDLength = 128
+
+// adminCallerUserValue is the key of the user value holding the caller authorized to use the admin API.
+const adminCallerUserValue = "admin_caller"
+
+const authorizationHeader = "Authorization"
+const bearerPrefix = "Bearer "
+
 const applicationJSONContentType = "application/json"
 
+const (
+	headerETag         = "ETag"
+	headerIfNoneMatch  = "If-None-Match"
+	headerCacheControl = "Cache-Control"
+	headerVary         = "Vary"
+
+	headerAcceptEncoding = "Accept-Encoding"
+)
+
+const (
+	compressionBrotli = "br"
+	compressionGzip   = "gzip"
+)
+
 var okMessageBytes = []byte("{\"status\":\"OK\"}")
 
 const operationFailedMessage = "Operation failed"
@@ -18,3 +48,10 @@ const identityVerificationTokenAlreadyUsedMessage = "The identity verification t
 const identityVerificationTokenHasExpiredMessage = "The identity verification token has expired"
 
 var protoHostSeparator = []byte("://")
+
+// sessionPinningStrictnessLog is the strictness of the session pinning only logging the mismatches.
+const sessionPinningStrictnessLog = "log"
+
+// sessionLastCountryRefreshInterval is the interval the time the session was last used from its country is refreshed
+// at, the session isn't saved on every request.
+const sessionLastCountryRefreshInterval = time.Minute
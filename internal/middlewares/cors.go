@@ -0,0 +1,45 @@
+package middlewares
+
+import (
+	"strconv"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// CORS allows the configured origins to send credentialed cross-origin requests to the next handler. The preflight
+// requests of the allowed origins are replied to without calling the next handler. The headers are set once the next
+// handler replied since the error replies reset them.
+func CORS(configuration schema.ServerCORSConfiguration) Middleware {
+	maxAge := strconv.Itoa(configuration.MaxAge)
+
+	return func(next RequestHandler) RequestHandler {
+		return func(ctx *AutheliaCtx) {
+			origin := string(ctx.Request.Header.Peek(fasthttp.HeaderOrigin))
+			allowed := origin != "" && utils.IsStringInSlice(origin, configuration.AllowedOrigins)
+			method := ctx.Request.Header.Peek(fasthttp.HeaderAccessControlRequestMethod)
+
+			if allowed && ctx.IsOptions() && len(method) != 0 {
+				ctx.SetStatusCode(fasthttp.StatusNoContent)
+				ctx.Response.Header.SetBytesV(fasthttp.HeaderAccessControlAllowMethods, method)
+
+				if headers := ctx.Request.Header.Peek(fasthttp.HeaderAccessControlRequestHeaders); len(headers) != 0 {
+					ctx.Response.Header.SetBytesV(fasthttp.HeaderAccessControlAllowHeaders, headers)
+				}
+
+				ctx.Response.Header.Set(fasthttp.HeaderAccessControlMaxAge, maxAge)
+			} else {
+				next(ctx)
+			}
+
+			appendVaryHeader(&ctx.Response, fasthttp.HeaderOrigin)
+
+			if allowed {
+				ctx.Response.Header.Set(fasthttp.HeaderAccessControlAllowOrigin, origin)
+				ctx.Response.Header.Set(fasthttp.HeaderAccessControlAllowCredentials, "true")
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package middlewares_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/mocks"
+)
+
+func callCORS(mock *mocks.MockAutheliaCtx) bool {
+	called := false
+
+	middlewares.CORS(schema.ServerCORSConfiguration{AllowedOrigins: []string{"https://app.example.com"}, MaxAge: 600})(
+		func(ctx *middlewares.AutheliaCtx) {
+			called = true
+		})(mock.Ctx)
+
+	return called
+}
+
+func TestShouldAllowCrossOriginRequestsOfAllowedOrigins(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	mock.Ctx.Request.Header.SetMethod(fasthttp.MethodPost)
+	mock.Ctx.Request.Header.Set(fasthttp.HeaderOrigin, "https://app.example.com")
+
+	assert.True(t, callCORS(mock))
+	assert.Equal(t, "https://app.example.com", string(mock.Ctx.Response.Header.Peek(fasthttp.HeaderAccessControlAllowOrigin)))
+	assert.Equal(t, "true", string(mock.Ctx.Response.Header.Peek(fasthttp.HeaderAccessControlAllowCredentials)))
+	assert.Equal(t, "Origin", string(mock.Ctx.Response.Header.Peek(fasthttp.HeaderVary)))
+}
+
+func TestShouldNotAllowCrossOriginRequestsOfOtherOrigins(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	mock.Ctx.Request.Header.SetMethod(fasthttp.MethodPost)
+	mock.Ctx.Request.Header.Set(fasthttp.HeaderOrigin, "https://evil.example.com")
+
+	assert.True(t, callCORS(mock))
+	assert.Empty(t, mock.Ctx.Response.Header.Peek(fasthttp.HeaderAccessControlAllowOrigin))
+	assert.Empty(t, mock.Ctx.Response.Header.Peek(fasthttp.HeaderAccessControlAllowCredentials))
+	assert.Equal(t, "Origin", string(mock.Ctx.Response.Header.Peek(fasthttp.HeaderVary)))
+}
+
+func TestShouldReplyToPreflightRequestsOfAllowedOrigins(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	mock.Ctx.Request.Header.SetMethod(fasthttp.MethodOptions)
+	mock.Ctx.Request.Header.Set(fasthttp.HeaderOrigin, "https://app.example.com")
+	mock.Ctx.Request.Header.Set(fasthttp.HeaderAccessControlRequestMethod, fasthttp.MethodPost)
+	mock.Ctx.Request.Header.Set(fasthttp.HeaderAccessControlRequestHeaders, "Content-Type")
+
+	assert.False(t, callCORS(mock))
+	assert.Equal(t, fasthttp.StatusNoContent, mock.Ctx.Response.StatusCode())
+	assert.Equal(t, "https://app.example.com", string(mock.Ctx.Response.Header.Peek(fasthttp.HeaderAccessControlAllowOrigin)))
+	assert.Equal(t, "POST", string(mock.Ctx.Response.Header.Peek(fasthttp.HeaderAccessControlAllowMethods)))
+	assert.Equal(t, "Content-Type", string(mock.Ctx.Response.Header.Peek(fasthttp.HeaderAccessControlAllowHeaders)))
+	assert.Equal(t, "600", string(mock.Ctx.Response.Header.Peek(fasthttp.HeaderAccessControlMaxAge)))
+}
+
+func TestShouldKeepCORSHeadersOfErrorReplies(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	mock.Ctx.Request.Header.SetMethod(fasthttp.MethodGet)
+	mock.Ctx.Request.Header.Set(fasthttp.HeaderOrigin, "https://app.example.com")
+
+	middlewares.CORS(schema.ServerCORSConfiguration{AllowedOrigins: []string{"https://app.example.com"}, MaxAge: 600})(
+		func(ctx *middlewares.AutheliaCtx) {
+			ctx.ReplyForbidden()
+		})(mock.Ctx)
+
+	assert.Equal(t, fasthttp.StatusForbidden, mock.Ctx.Response.StatusCode())
+	assert.Equal(t, "https://app.example.com", string(mock.Ctx.Response.Header.Peek(fasthttp.HeaderAccessControlAllowOrigin)))
+}
@@ -1,6 +1,9 @@
 package middlewares
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 // InternalError is the error message sent when there was an internal error but it should
 // be hidden to the end user. In that case the error should be in the server logs.
@@ -11,3 +14,13 @@ const UnauthorizedError = "You're not authorized."
 
 var errMissingXForwardedHost = errors.New("Missing header X-Forwarded-Host")
 var errMissingXForwardedProto = errors.New("Missing header X-Forwarded-Proto")
+
+// Error returns the error string.
+func (e *BodyValidationError) Error() string {
+	return fmt.Sprintf("Unable to validate body: %s", e.err)
+}
+
+// Unwrap returns the underlying validation error.
+func (e *BodyValidationError) Unwrap() error {
+	return e.err
+}
@@ -120,6 +120,8 @@ func NewHTTPToAutheliaHandlerAdaptor(h AutheliaHandlerFunc) RequestHandler {
 			}
 		}
 
-		ctx.Write(w.body) //nolint:errcheck
+		if _, err := ctx.Write(w.body); err != nil {
+			ctx.Logger.Errorf("Unable to write response body: %v", err)
+		}
 	}
 }
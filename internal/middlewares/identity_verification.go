@@ -8,6 +8,7 @@ import (
 
 	"github.com/golang-jwt/jwt"
 
+	"github.com/authelia/authelia/internal/notification"
 	"github.com/authelia/authelia/internal/templates"
 )
 
@@ -31,7 +32,7 @@ func IdentityVerificationStart(args IdentityVerificationStartArgs) RequestHandle
 		// Create the claim with the action to sign it.
 		claims := &IdentityVerificationClaim{
 			jwt.StandardClaims{
-				ExpiresAt: time.Now().Add(5 * time.Minute).Unix(),
+				ExpiresAt: ctx.Clock.Now().Add(5 * time.Minute).Unix(),
 				Issuer:    jwtIssuer,
 			},
 			args.ActionClaim,
@@ -45,7 +46,7 @@ func IdentityVerificationStart(args IdentityVerificationStartArgs) RequestHandle
 			return
 		}
 
-		err = ctx.Providers.StorageProvider.SaveIdentityVerificationToken(ss)
+		err = ctx.Providers.StorageProvider.SaveIdentityVerificationToken(ss, time.Unix(claims.ExpiresAt, 0))
 		if err != nil {
 			ctx.Error(err, operationFailedMessage)
 			return
@@ -58,6 +59,7 @@ func IdentityVerificationStart(args IdentityVerificationStartArgs) RequestHandle
 		}
 
 		link := fmt.Sprintf("%s%s%s?token=%s", uri, ctx.Configuration.Server.Path, args.TargetEndpoint, ss)
+		client := ctx.ClientMetadata()
 
 		bufHTML := new(bytes.Buffer)
 
@@ -68,9 +70,11 @@ func IdentityVerificationStart(args IdentityVerificationStartArgs) RequestHandle
 
 		if !disableHTML {
 			htmlParams := map[string]interface{}{
-				"title":  args.MailTitle,
-				"url":    link,
-				"button": args.MailButtonContent,
+				"title":    args.MailTitle,
+				"url":      link,
+				"button":   args.MailButtonContent,
+				"remoteIP": client.RemoteIP,
+				"location": client.Location,
 			}
 
 			err = templates.HTMLEmailTemplate.Execute(bufHTML, htmlParams)
@@ -83,7 +87,9 @@ func IdentityVerificationStart(args IdentityVerificationStartArgs) RequestHandle
 
 		bufText := new(bytes.Buffer)
 		textParams := map[string]interface{}{
-			"url": link,
+			"url":      link,
+			"remoteIP": client.RemoteIP,
+			"location": client.Location,
 		}
 
 		err = templates.PlainTextEmailTemplate.Execute(bufText, textParams)
@@ -96,7 +102,8 @@ func IdentityVerificationStart(args IdentityVerificationStartArgs) RequestHandle
 		ctx.Logger.Debugf("Sending an email to user %s (%s) to confirm identity for registering a device.",
 			identity.Username, identity.Email)
 
-		err = ctx.Providers.Notifier.Send(identity.Email, args.MailTitle, bufText.String(), bufHTML.String())
+		err = notification.SendWithCategory(ctx.Providers.Notifier, notification.CategoryVerification, identity.Email,
+			args.MailTitle, bufText.String(), bufHTML.String())
 
 		if err != nil {
 			ctx.Error(err, operationFailedMessage)
@@ -139,11 +146,18 @@ func IdentityVerificationFinish(args IdentityVerificationFinishArgs, next func(c
 			return
 		}
 
-		token, err := jwt.ParseWithClaims(finishBody.Token, &IdentityVerificationClaim{},
+		// The expiration is verified against the clock of the context rather than the one of the JWT library.
+		parser := jwt.Parser{SkipClaimsValidation: true}
+
+		token, err := parser.ParseWithClaims(finishBody.Token, &IdentityVerificationClaim{},
 			func(token *jwt.Token) (interface{}, error) {
 				return []byte(ctx.Configuration.JWTSecret), nil
 			})
 
+		if err == nil && !token.Claims.(*IdentityVerificationClaim).VerifyExpiresAt(ctx.Clock.Now().Unix(), true) {
+			err = &jwt.ValidationError{Errors: jwt.ValidationErrorExpired}
+		}
+
 		if err != nil {
 			if ve, ok := err.(*jwt.ValidationError); ok {
 				switch {
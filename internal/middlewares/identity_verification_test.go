@@ -55,7 +55,7 @@ func TestShouldFailIfJWTCannotBeSaved(t *testing.T) {
 	mock.Ctx.Configuration.JWTSecret = testJWTSecret
 
 	mock.StorageProviderMock.EXPECT().
-		SaveIdentityVerificationToken(gomock.Any()).
+		SaveIdentityVerificationToken(gomock.Any(), gomock.Any()).
 		Return(fmt.Errorf("cannot save"))
 
 	args := newArgs(defaultRetriever)
@@ -74,7 +74,7 @@ func TestShouldFailSendingAnEmail(t *testing.T) {
 	mock.Ctx.Request.Header.Add("X-Forwarded-Host", "host")
 
 	mock.StorageProviderMock.EXPECT().
-		SaveIdentityVerificationToken(gomock.Any()).
+		SaveIdentityVerificationToken(gomock.Any(), gomock.Any()).
 		Return(nil)
 
 	mock.NotifierMock.EXPECT().
@@ -96,7 +96,7 @@ func TestShouldFailWhenXForwardedProtoHeaderIsMissing(t *testing.T) {
 	mock.Ctx.Request.Header.Add("X-Forwarded-Host", "host")
 
 	mock.StorageProviderMock.EXPECT().
-		SaveIdentityVerificationToken(gomock.Any()).
+		SaveIdentityVerificationToken(gomock.Any(), gomock.Any()).
 		Return(nil)
 
 	args := newArgs(defaultRetriever)
@@ -114,7 +114,7 @@ func TestShouldFailWhenXForwardedHostHeaderIsMissing(t *testing.T) {
 	mock.Ctx.Request.Header.Add("X-Forwarded-Proto", "http")
 
 	mock.StorageProviderMock.EXPECT().
-		SaveIdentityVerificationToken(gomock.Any()).
+		SaveIdentityVerificationToken(gomock.Any(), gomock.Any()).
 		Return(nil)
 
 	args := newArgs(defaultRetriever)
@@ -132,7 +132,7 @@ func TestShouldSucceedIdentityVerificationStartProcess(t *testing.T) {
 	mock.Ctx.Request.Header.Add("X-Forwarded-Host", "host")
 
 	mock.StorageProviderMock.EXPECT().
-		SaveIdentityVerificationToken(gomock.Any()).
+		SaveIdentityVerificationToken(gomock.Any(), gomock.Any()).
 		Return(nil)
 
 	mock.NotifierMock.EXPECT().
@@ -156,6 +156,7 @@ type IdentityVerificationFinishProcess struct {
 
 func (s *IdentityVerificationFinishProcess) SetupTest() {
 	s.mock = mocks.NewMockAutheliaCtx(s.T())
+	s.mock.Ctx.Clock = &s.mock.Clock
 
 	s.mock.Ctx.Configuration.JWTSecret = testJWTSecret
 }
@@ -232,13 +233,15 @@ func (s *IdentityVerificationFinishProcess) TestShouldFailIfTokenIsInvalid() {
 func (s *IdentityVerificationFinishProcess) TestShouldFailIfTokenExpired() {
 	args := newArgs(defaultRetriever)
 	token := createToken(s.mock.Ctx.Configuration.JWTSecret, "john", args.ActionClaim,
-		time.Now().Add(-1*time.Minute))
+		s.mock.Clock.Now().Add(5*time.Minute))
 	s.mock.Ctx.Request.SetBodyString(fmt.Sprintf("{\"token\":\"%s\"}", token))
 
 	s.mock.StorageProviderMock.EXPECT().
 		FindIdentityVerificationToken(gomock.Eq(token)).
 		Return(true, nil)
 
+	s.mock.Clock.Advance(5*time.Minute + time.Second)
+
 	middlewares.IdentityVerificationFinish(newFinishArgs(), next)(s.mock.Ctx)
 
 	s.mock.Assert200KO(s.T(), "The identity verification token has expired")
@@ -247,7 +250,7 @@ func (s *IdentityVerificationFinishProcess) TestShouldFailIfTokenExpired() {
 
 func (s *IdentityVerificationFinishProcess) TestShouldFailForWrongAction() {
 	token := createToken(s.mock.Ctx.Configuration.JWTSecret, "", "",
-		time.Now().Add(1*time.Minute))
+		s.mock.Clock.Now().Add(1*time.Minute))
 	s.mock.Ctx.Request.SetBodyString(fmt.Sprintf("{\"token\":\"%s\"}", token))
 
 	s.mock.StorageProviderMock.EXPECT().
@@ -262,7 +265,7 @@ func (s *IdentityVerificationFinishProcess) TestShouldFailForWrongAction() {
 
 func (s *IdentityVerificationFinishProcess) TestShouldFailForWrongUser() {
 	token := createToken(s.mock.Ctx.Configuration.JWTSecret, "harry", "EXP_ACTION",
-		time.Now().Add(1*time.Minute))
+		s.mock.Clock.Now().Add(1*time.Minute))
 	s.mock.Ctx.Request.SetBodyString(fmt.Sprintf("{\"token\":\"%s\"}", token))
 
 	s.mock.StorageProviderMock.EXPECT().
@@ -279,7 +282,7 @@ func (s *IdentityVerificationFinishProcess) TestShouldFailForWrongUser() {
 
 func (s *IdentityVerificationFinishProcess) TestShouldFailIfTokenCannotBeRemovedFromDB() {
 	token := createToken(s.mock.Ctx.Configuration.JWTSecret, "john", "EXP_ACTION",
-		time.Now().Add(1*time.Minute))
+		s.mock.Clock.Now().Add(1*time.Minute))
 	s.mock.Ctx.Request.SetBodyString(fmt.Sprintf("{\"token\":\"%s\"}", token))
 
 	s.mock.StorageProviderMock.EXPECT().
@@ -298,7 +301,7 @@ func (s *IdentityVerificationFinishProcess) TestShouldFailIfTokenCannotBeRemoved
 
 func (s *IdentityVerificationFinishProcess) TestShouldReturn200OnFinishComplete() {
 	token := createToken(s.mock.Ctx.Configuration.JWTSecret, "john", "EXP_ACTION",
-		time.Now().Add(1*time.Minute))
+		s.mock.Clock.Now().Add(1*time.Minute))
 	s.mock.Ctx.Request.SetBodyString(fmt.Sprintf("{\"token\":\"%s\"}", token))
 
 	s.mock.StorageProviderMock.EXPECT().
@@ -0,0 +1,12 @@
+package middlewares
+
+// LogHandledRequest logs the status of the request handled by the next handler and the time it took to handle it.
+func LogHandledRequest(next RequestHandler) RequestHandler {
+	return func(ctx *AutheliaCtx) {
+		start := ctx.Clock.Now()
+
+		next(ctx)
+
+		ctx.Logger.Debugf("Handled request (status=%d, duration=%s)", ctx.Response.StatusCode(), ctx.Clock.Now().Sub(start))
+	}
+}
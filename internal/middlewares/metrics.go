@@ -0,0 +1,26 @@
+package middlewares
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/fasthttp/router"
+	"github.com/valyala/fasthttp"
+
+	"github.com/authelia/authelia/internal/metrics"
+)
+
+// MetricsMiddleware measures the duration of the requests. The route label is the pattern of the route matched by the
+// router which must save it, it's empty when no route matched.
+func MetricsMiddleware(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		start := time.Now()
+
+		next(ctx)
+
+		route, _ := ctx.UserValue(router.MatchedRoutePathParam).(string)
+
+		metrics.RequestDuration.Observe(time.Since(start).Seconds(),
+			route, string(ctx.Method()), strconv.Itoa(ctx.Response.StatusCode()))
+	}
+}
@@ -0,0 +1,37 @@
+package middlewares
+
+import (
+	"testing"
+
+	"github.com/fasthttp/router"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+
+	"github.com/authelia/authelia/internal/metrics"
+)
+
+func TestShouldObserveRequestDurationByRoute(t *testing.T) {
+	r := router.New()
+	r.SaveMatchedRoutePath = true
+	r.GET("/api/verify", func(ctx *fasthttp.RequestCtx) {
+		ctx.SetStatusCode(fasthttp.StatusUnauthorized)
+	})
+
+	handler := MetricsMiddleware(r.Handler)
+
+	before := metrics.RequestDuration.Count("/api/verify", "GET", "401")
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/api/verify?rd=https://login.example.com")
+	handler(ctx)
+
+	assert.Equal(t, before+1, metrics.RequestDuration.Count("/api/verify", "GET", "401"))
+
+	before = metrics.RequestDuration.Count("", "GET", "404")
+
+	ctx = &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/unknown")
+	handler(ctx)
+
+	assert.Equal(t, before+1, metrics.RequestDuration.Count("", "GET", "404"))
+}
@@ -0,0 +1,91 @@
+package middlewares
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// RateLimiter counts the requests of each client address in fixed windows of the configured period.
+type RateLimiter struct {
+	requests int
+	period   time.Duration
+
+	mutex   sync.Mutex
+	windows map[string]*rateLimitWindow
+	swept   time.Time
+}
+
+type rateLimitWindow struct {
+	start time.Time
+	count int
+}
+
+// NewRateLimiter creates a RateLimiter from the rate limit configuration.
+func NewRateLimiter(configuration schema.ServerRateLimitConfiguration) *RateLimiter {
+	// The period has already been validated.
+	period, _ := utils.ParseDurationString(configuration.Period)
+
+	return &RateLimiter{
+		requests: configuration.Requests,
+		period:   period,
+		windows:  map[string]*rateLimitWindow{},
+	}
+}
+
+// Allow counts a request of the client and returns true when the client has not exceeded the number of requests of
+// the current window, otherwise it returns the time left until the next window.
+func (l *RateLimiter) Allow(client string, now time.Time) (allowed bool, retryAfter time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	// The windows of the clients which are no longer active are removed once per period.
+	if now.Sub(l.swept) >= l.period {
+		for key, window := range l.windows {
+			if now.Sub(window.start) >= l.period {
+				delete(l.windows, key)
+			}
+		}
+
+		l.swept = now
+	}
+
+	window, ok := l.windows[client]
+	if !ok || now.Sub(window.start) >= l.period {
+		window = &rateLimitWindow{start: now}
+		l.windows[client] = window
+	}
+
+	if window.count >= l.requests {
+		return false, window.start.Add(l.period).Sub(now)
+	}
+
+	window.count++
+
+	return true, 0
+}
+
+// RateLimit replies with the too many requests status to the clients which exceeded the number of requests of the
+// limiter, the clients are identified by their remote IP.
+func RateLimit(limiter *RateLimiter) Middleware {
+	return func(next RequestHandler) RequestHandler {
+		return func(ctx *AutheliaCtx) {
+			allowed, retryAfter := limiter.Allow(ctx.RemoteIP().String(), ctx.Clock.Now())
+
+			if !allowed {
+				ctx.Logger.Debugf("Client %s exceeded the rate limit", ctx.RemoteIP())
+				ctx.RequestCtx.Error(fasthttp.StatusMessage(fasthttp.StatusTooManyRequests), fasthttp.StatusTooManyRequests)
+				ctx.Response.Header.Set(fasthttp.HeaderRetryAfter, strconv.Itoa(int(retryAfter.Round(time.Second)/time.Second)))
+
+				return
+			}
+
+			next(ctx)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package middlewares_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/mocks"
+)
+
+func TestShouldLimitTheRequestsOfEachClientPerWindow(t *testing.T) {
+	limiter := middlewares.NewRateLimiter(schema.ServerRateLimitConfiguration{Requests: 2, Period: "1m"})
+	now := time.Unix(1600000000, 0)
+
+	allowed, _ := limiter.Allow("192.168.0.1", now)
+	assert.True(t, allowed)
+
+	allowed, _ = limiter.Allow("192.168.0.1", now.Add(10*time.Second))
+	assert.True(t, allowed)
+
+	allowed, retryAfter := limiter.Allow("192.168.0.1", now.Add(20*time.Second))
+	assert.False(t, allowed)
+	assert.Equal(t, 40*time.Second, retryAfter)
+
+	allowed, _ = limiter.Allow("192.168.0.2", now.Add(20*time.Second))
+	assert.True(t, allowed)
+
+	allowed, _ = limiter.Allow("192.168.0.1", now.Add(time.Minute))
+	assert.True(t, allowed)
+}
+
+func TestShouldReplyTooManyRequestsOnceTheRateLimitIsExceeded(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	mock.Clock.Set(time.Unix(1600000000, 0))
+	mock.Ctx.Clock = &mock.Clock
+	mock.Ctx.Request.Header.Set("X-Forwarded-For", "192.168.0.1")
+
+	calls := 0
+	handler := middlewares.RateLimit(middlewares.NewRateLimiter(schema.ServerRateLimitConfiguration{Requests: 1, Period: "1m"}))(
+		func(ctx *middlewares.AutheliaCtx) {
+			calls++
+		})
+
+	handler(mock.Ctx)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, fasthttp.StatusOK, mock.Ctx.Response.StatusCode())
+
+	mock.Clock.Set(time.Unix(1600000015, 0))
+	handler(mock.Ctx)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, fasthttp.StatusTooManyRequests, mock.Ctx.Response.StatusCode())
+	assert.Equal(t, "45", string(mock.Ctx.Response.Header.Peek(fasthttp.HeaderRetryAfter)))
+}
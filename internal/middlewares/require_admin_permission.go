@@ -0,0 +1,135 @@
+package middlewares
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/storage"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// RequireAdminPermission checks the caller of an admin endpoint is granted the permission of the operation. The caller
+// is either an admin API token, provided as a bearer token, granted the scope of the permission or a user who completed
+// the second factor and is a member of a group granted a role having the permission. The permissions only allowed to
+// the users are never granted to the admin API tokens.
+func RequireAdminPermission(permission models.AdminPermission) Middleware {
+	return func(next RequestHandler) RequestHandler {
+		return func(ctx *AutheliaCtx) {
+			var (
+				caller string
+				ok     bool
+			)
+
+			if header := string(ctx.Request.Header.Peek(authorizationHeader)); strings.HasPrefix(header, bearerPrefix) {
+				caller, ok = authorizeAdminAPIToken(ctx, strings.TrimPrefix(header, bearerPrefix), permission)
+			} else {
+				caller, ok = authorizeAdminUser(ctx, permission)
+			}
+
+			if !ok {
+				return
+			}
+
+			ctx.SetUserValue(adminCallerUserValue, caller)
+
+			next(ctx)
+		}
+	}
+}
+
+// AdminCaller returns the caller authorized by RequireAdminPermission, i.e. the username of the user or the
+// description of the admin API token.
+func (c *AutheliaCtx) AdminCaller() string {
+	caller, _ := c.UserValue(adminCallerUserValue).(string)
+
+	return caller
+}
+
+// AdminRoles returns the roles of the admin API granted to the members of the given groups, the members of the
+// administrators group are granted the admin role.
+func AdminRoles(configuration schema.ServerConfiguration, groups []string) (roles []string) {
+	grants := []struct {
+		role   string
+		groups []string
+	}{
+		{models.AdminRoleAuditor, configuration.AdminRoles.Auditor},
+		{models.AdminRoleHelpdesk, configuration.AdminRoles.Helpdesk},
+		{models.AdminRoleAdmin, append([]string{configuration.AdminGroup}, configuration.AdminRoles.Admin...)},
+	}
+
+	for _, grant := range grants {
+		for _, group := range grant.groups {
+			if group != "" && utils.IsStringInSlice(group, groups) {
+				roles = append(roles, grant.role)
+				break
+			}
+		}
+	}
+
+	return roles
+}
+
+func authorizeAdminUser(ctx *AutheliaCtx, permission models.AdminPermission) (caller string, ok bool) {
+	userSession := ctx.GetSession()
+
+	if destroyInvalidatedSession(ctx, &userSession) {
+		ctx.ReplyForbidden()
+
+		return "", false
+	}
+
+	if userSession.AuthenticationLevel >= authentication.TwoFactor && !userSession.IsBreakGlassExpired(ctx.Clock.Now()) {
+		for _, role := range AdminRoles(ctx.Configuration.Server, userSession.Groups) {
+			if models.AdminRoleHasPermission(role, permission) {
+				return userSession.Username, true
+			}
+		}
+	}
+
+	ctx.Logger.Debugf("User %s is not granted the admin permission %s", userSession.Username, permission.Name)
+	ctx.ReplyForbidden()
+
+	return "", false
+}
+
+// authorizeAdminAPIToken returns the caller identified by an admin API token when the token is valid and granted the
+// scope of the permission, otherwise it replies with an error.
+func authorizeAdminAPIToken(ctx *AutheliaCtx, value string, permission models.AdminPermission) (caller string, ok bool) {
+	token, err := ctx.Providers.StorageProvider.LoadAdminAPIToken(utils.HashSHA256FromString(value))
+
+	switch {
+	case err == storage.ErrNoAdminAPIToken:
+		ctx.Logger.Debugf("Admin API token is unknown or has been revoked")
+		ctx.ReplyUnauthorized()
+
+		return "", false
+	case err != nil:
+		ctx.Error(fmt.Errorf("Unable to load admin API token: %s", err), operationFailedMessage)
+
+		return "", false
+	case token.IsExpired(ctx.Clock.Now()):
+		ctx.Logger.Debugf("Admin API token %s has expired", token.ID)
+		ctx.ReplyUnauthorized()
+
+		return "", false
+	case permission.UsersOnly:
+		ctx.Logger.Debugf("Admin permission %s can't be granted to admin API token %s", permission.Name, token.ID)
+		ctx.ReplyForbidden()
+
+		return "", false
+	case !token.HasScope(permission.Scope, permission.Write):
+		ctx.Logger.Debugf("Admin API token %s is not granted the admin permission %s", token.ID, permission.Name)
+		ctx.ReplyForbidden()
+
+		return "", false
+	}
+
+	if err = ctx.Providers.StorageProvider.UpdateAdminAPITokenLastUsed(token.ID, ctx.Clock.Now()); err != nil {
+		ctx.Logger.Errorf("Unable to update the last use of admin API token %s: %s", token.ID, err)
+	}
+
+	return "admin API token " + token.ID, true
+}
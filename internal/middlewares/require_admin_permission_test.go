@@ -0,0 +1,210 @@
+package middlewares_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/utils"
+	"github.com/authelia/authelia/pkg/autheliatest"
+)
+
+type RequireAdminPermissionSuite struct {
+	suite.Suite
+
+	mock    *mocks.MockAutheliaCtx
+	storage *autheliatest.MemoryStorageProvider
+	caller  string
+}
+
+func (s *RequireAdminPermissionSuite) SetupTest() {
+	s.mock = mocks.NewMockAutheliaCtx(s.T())
+	s.mock.Clock.Set(time.Unix(1600000000, 0))
+	s.mock.Ctx.Clock = &s.mock.Clock
+	s.mock.Ctx.Configuration.Server.AdminRoles = schema.ServerAdminRolesConfiguration{
+		Auditor:  []string{"security"},
+		Helpdesk: []string{"support"},
+		Admin:    []string{"ops"},
+	}
+
+	s.storage = autheliatest.NewMemoryStorageProvider()
+	s.mock.Ctx.Providers.StorageProvider = s.storage
+
+	s.caller = ""
+}
+
+func (s *RequireAdminPermissionSuite) TearDownTest() {
+	s.mock.Close()
+}
+
+// call calls a handler recording the caller through the middleware requiring the given permission and returns true
+// when the handler has been called.
+func (s *RequireAdminPermissionSuite) call(permission models.AdminPermission) bool {
+	s.mock.Ctx.Response.Reset()
+
+	called := false
+
+	middlewares.RequireAdminPermission(permission)(func(ctx *middlewares.AutheliaCtx) {
+		called = true
+		s.caller = ctx.AdminCaller()
+	})(s.mock.Ctx)
+
+	return called
+}
+
+func (s *RequireAdminPermissionSuite) saveToken(id string, expiresAt time.Time, scopes ...string) {
+	s.Require().NoError(s.storage.SaveAdminAPIToken(models.AdminAPIToken{
+		ID:        id,
+		TokenHash: utils.HashSHA256FromString(id + "-secret"),
+		Scopes:    scopes,
+		CreatedAt: s.mock.Clock.Now().Add(-time.Hour),
+		ExpiresAt: expiresAt,
+	}))
+
+	s.mock.Ctx.Request.Header.Set("Authorization", "Bearer "+id+"-secret")
+}
+
+func (s *RequireAdminPermissionSuite) setGroups(groups ...string) {
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder("john").WithGroups(groups...).TwoFactor(s.mock.Clock.Now()).Build())
+}
+
+func (s *RequireAdminPermissionSuite) TestShouldAllowTokenWithScope() {
+	s.saveToken("automation", time.Time{}, models.AdminAPIScopeUsers)
+
+	s.Assert().True(s.call(models.AdminPermissionUsersPurge))
+	s.Assert().Equal("admin API token automation", s.caller)
+
+	tokens, err := s.storage.LoadAdminAPITokens()
+	s.Require().NoError(err)
+	s.Assert().Equal(s.mock.Clock.Now(), tokens[0].LastUsedAt)
+}
+
+func (s *RequireAdminPermissionSuite) TestShouldAllowReadOnlyOperationsWithReadScope() {
+	s.saveToken("monitoring", s.mock.Clock.Now().Add(time.Hour), models.AdminAPIScopeRead)
+
+	s.Assert().True(s.call(models.AdminPermissionUsersRead))
+	s.Assert().True(s.call(models.AdminPermissionOIDCKeysRead))
+
+	s.Assert().False(s.call(models.AdminPermissionUsersPurge))
+	s.Assert().Equal(403, s.mock.Ctx.Response.StatusCode())
+}
+
+func (s *RequireAdminPermissionSuite) TestShouldForbidTokenOfAnotherResource() {
+	s.saveToken("keys", time.Time{}, models.AdminAPIScopeOIDCKeys)
+
+	s.Assert().False(s.call(models.AdminPermissionUsersRead))
+	s.Assert().Equal(403, s.mock.Ctx.Response.StatusCode())
+}
+
+func (s *RequireAdminPermissionSuite) TestShouldForbidTokenPermissionsOnlyAllowedToUsers() {
+	s.saveToken("automation", time.Time{}, models.AdminAPIScopeUsers)
+
+	s.Assert().True(s.call(models.AdminPermissionSecondFactorResetsRequest))
+
+	s.Assert().False(s.call(models.AdminPermissionSecondFactorResetsApprove))
+	s.Assert().Equal(403, s.mock.Ctx.Response.StatusCode())
+
+	s.Assert().False(s.call(models.AdminPermissionUsersDevicesDelete))
+	s.Assert().Equal(403, s.mock.Ctx.Response.StatusCode())
+}
+
+func (s *RequireAdminPermissionSuite) TestShouldRejectExpiredToken() {
+	s.saveToken("expired", s.mock.Clock.Now(), models.AdminAPIScopeUsers)
+
+	s.Assert().False(s.call(models.AdminPermissionUsersPurge))
+	s.Assert().Equal(401, s.mock.Ctx.Response.StatusCode())
+}
+
+func (s *RequireAdminPermissionSuite) TestShouldRejectUnknownToken() {
+	s.saveToken("revoked", time.Time{}, models.AdminAPIScopeUsers)
+	s.Require().NoError(s.storage.DeleteAdminAPIToken("revoked"))
+
+	s.Assert().False(s.call(models.AdminPermissionUsersPurge))
+	s.Assert().Equal(401, s.mock.Ctx.Response.StatusCode())
+}
+
+func (s *RequireAdminPermissionSuite) TestShouldForbidUsersWithoutRole() {
+	s.setGroups("admins", "dev")
+
+	s.Assert().False(s.call(models.AdminPermissionUsersRead))
+	s.Assert().Equal(403, s.mock.Ctx.Response.StatusCode())
+}
+
+func (s *RequireAdminPermissionSuite) TestShouldForbidUsersWithoutSecondFactor() {
+	s.mock.SetUserSession(s.T(), autheliatest.NewUserSessionBuilder("john").WithGroups("ops").OneFactor(s.mock.Clock.Now()).Build())
+
+	s.Assert().False(s.call(models.AdminPermissionUsersRead))
+	s.Assert().Equal(403, s.mock.Ctx.Response.StatusCode())
+}
+
+func (s *RequireAdminPermissionSuite) TestShouldForbidAndDestroyRevokedSession() {
+	s.setGroups("ops")
+	s.Require().NoError(s.storage.RevokeSessions("john", s.mock.Clock.Now()))
+
+	s.Assert().False(s.call(models.AdminPermissionUsersRead))
+	s.Assert().Equal(403, s.mock.Ctx.Response.StatusCode())
+	s.Assert().Equal("", s.mock.Ctx.GetSession().Username)
+}
+
+func (s *RequireAdminPermissionSuite) TestShouldAllowAuditorsToRead() {
+	s.setGroups("security")
+
+	s.Assert().True(s.call(models.AdminPermissionUsersRead))
+	s.Assert().Equal("john", s.caller)
+	s.Assert().True(s.call(models.AdminPermissionOIDCKeysRead))
+
+	s.Assert().False(s.call(models.AdminPermissionUsersManage))
+	s.Assert().False(s.call(models.AdminPermissionUsersPurge))
+	s.Assert().False(s.call(models.AdminPermissionOIDCKeysRotate))
+}
+
+func (s *RequireAdminPermissionSuite) TestShouldAllowHelpdeskToManageUsers() {
+	s.setGroups("support")
+
+	s.Assert().True(s.call(models.AdminPermissionUsersRead))
+	s.Assert().True(s.call(models.AdminPermissionUsersManage))
+	s.Assert().True(s.call(models.AdminPermissionSecondFactorResetsRequest))
+
+	s.Assert().False(s.call(models.AdminPermissionSecondFactorResetsApprove))
+	s.Assert().False(s.call(models.AdminPermissionUsersDevicesDelete))
+	s.Assert().False(s.call(models.AdminPermissionUsersPurge))
+	s.Assert().False(s.call(models.AdminPermissionOIDCKeysRead))
+	s.Assert().False(s.call(models.AdminPermissionOIDCKeysRotate))
+}
+
+func (s *RequireAdminPermissionSuite) TestShouldAllowAdminsEverything() {
+	s.mock.Ctx.Configuration.Server.AdminGroup = "admins"
+
+	for _, groups := range [][]string{{"ops"}, {"admins"}} {
+		s.setGroups(groups...)
+
+		for _, permission := range models.AdminRolePermissions[models.AdminRoleAdmin] {
+			s.Assert().True(s.call(permission), permission.Name)
+		}
+	}
+}
+
+func TestRunRequireAdminPermissionSuite(t *testing.T) {
+	suite.Run(t, new(RequireAdminPermissionSuite))
+}
+
+func TestShouldMapGroupsToAdminRoles(t *testing.T) {
+	configuration := schema.ServerConfiguration{
+		AdminGroup: "admins",
+		AdminRoles: schema.ServerAdminRolesConfiguration{
+			Auditor:  []string{"security", "compliance"},
+			Helpdesk: []string{"support"},
+		},
+	}
+
+	assert.Equal(t, []string{models.AdminRoleAuditor, models.AdminRoleHelpdesk},
+		middlewares.AdminRoles(configuration, []string{"dev", "compliance", "support"}))
+	assert.Equal(t, []string{models.AdminRoleAdmin}, middlewares.AdminRoles(configuration, []string{"admins"}))
+	assert.Nil(t, middlewares.AdminRoles(configuration, []string{"dev", ""}))
+}
@@ -2,12 +2,20 @@ package middlewares
 
 import (
 	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/session"
 )
 
 // RequireFirstFactor check if user has enough permissions to execute the next handler.
 func RequireFirstFactor(next RequestHandler) RequestHandler {
 	return func(ctx *AutheliaCtx) {
-		if ctx.GetSession().AuthenticationLevel < authentication.OneFactor {
+		userSession := ctx.GetSession()
+
+		if userSession.AuthenticationLevel < authentication.OneFactor || userSession.IsBreakGlassExpired(ctx.Clock.Now()) {
+			ctx.ReplyForbidden()
+			return
+		}
+
+		if destroyInvalidatedSession(ctx, &userSession) {
 			ctx.ReplyForbidden()
 			return
 		}
@@ -15,3 +23,27 @@ func RequireFirstFactor(next RequestHandler) RequestHandler {
 		next(ctx)
 	}
 }
+
+// destroyInvalidatedSession destroys the session when it has been revoked, is used by another client than the one it is
+// pinned to or is used from locations too distant from one another, it returns true when the session has been
+// invalidated.
+func destroyInvalidatedSession(ctx *AutheliaCtx, userSession *session.UserSession) bool {
+	var reason string
+
+	switch {
+	case ctx.IsSessionRevoked(userSession):
+		reason = "revoked session"
+	case ctx.IsSessionPinViolated(userSession):
+		reason = "session pinned to another client"
+	case ctx.IsSessionTravelImpossible(userSession):
+		reason = "session used from distant locations"
+	default:
+		return false
+	}
+
+	if err := ctx.Providers.SessionProvider.DestroySession(ctx.RequestCtx); err != nil {
+		ctx.Logger.Errorf("Unable to destroy the %s of user %s: %s", reason, userSession.Username, err)
+	}
+
+	return true
+}
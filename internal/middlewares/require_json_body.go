@@ -0,0 +1,35 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// RequireJSONBody replies with the unsupported media type status when the request has a body which isn't declared
+// as JSON and with the bad request status when it isn't valid JSON. The requests without a body are passed through.
+func RequireJSONBody(next RequestHandler) RequestHandler {
+	return func(ctx *AutheliaCtx) {
+		body := ctx.PostBody()
+
+		if len(body) == 0 {
+			next(ctx)
+			return
+		}
+
+		mediaType := strings.TrimSpace(strings.SplitN(string(ctx.Request.Header.ContentType()), ";", 2)[0])
+
+		if !strings.EqualFold(mediaType, applicationJSONContentType) {
+			ctx.RequestCtx.Error(fasthttp.StatusMessage(fasthttp.StatusUnsupportedMediaType), fasthttp.StatusUnsupportedMediaType)
+			return
+		}
+
+		if !json.Valid(body) {
+			ctx.ReplyBadRequest()
+			return
+		}
+
+		next(ctx)
+	}
+}
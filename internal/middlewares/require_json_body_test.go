@@ -0,0 +1,54 @@
+package middlewares_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/mocks"
+)
+
+func callRequireJSONBody(mock *mocks.MockAutheliaCtx, contentType, body string) bool {
+	called := false
+
+	mock.Ctx.Request.Header.SetContentType(contentType)
+	mock.Ctx.Request.SetBodyString(body)
+
+	middlewares.RequireJSONBody(func(ctx *middlewares.AutheliaCtx) {
+		called = true
+	})(mock.Ctx)
+
+	return called
+}
+
+func TestShouldPassRequestsWithJSONBody(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	assert.True(t, callRequireJSONBody(mock, "application/json; charset=utf-8", `{"username":"john"}`))
+}
+
+func TestShouldPassRequestsWithoutBody(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	assert.True(t, callRequireJSONBody(mock, "", ""))
+}
+
+func TestShouldRejectBodiesNotDeclaredAsJSON(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	assert.False(t, callRequireJSONBody(mock, "application/x-www-form-urlencoded", "username=john"))
+	assert.Equal(t, fasthttp.StatusUnsupportedMediaType, mock.Ctx.Response.StatusCode())
+}
+
+func TestShouldRejectInvalidJSONBodies(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	assert.False(t, callRequireJSONBody(mock, "application/json", `{"username":`))
+	assert.Equal(t, fasthttp.StatusBadRequest, mock.Ctx.Response.StatusCode())
+}
@@ -0,0 +1,61 @@
+package middlewares
+
+import (
+	"net"
+
+	"github.com/authelia/authelia/internal/events"
+	"github.com/authelia/authelia/internal/session"
+)
+
+// SessionPin returns the pin of the client which made the current request, the sessions it creates are pinned to it.
+// It's nil when the session pinning is disabled.
+func (c *AutheliaCtx) SessionPin() *session.Pin {
+	configuration := c.Configuration.Session.Pinning
+
+	if !configuration.IP && !configuration.TLSFingerprint {
+		return nil
+	}
+
+	pin := &session.Pin{}
+
+	if configuration.IP {
+		pin.IPNetwork = ipNetwork(c.RemoteIP())
+	}
+
+	if configuration.TLSFingerprint {
+		pin.TLSFingerprint = string(c.Request.Header.Peek(configuration.TLSFingerprintHeader))
+	}
+
+	return pin
+}
+
+// IsSessionPinViolated returns true when the session is pinned to another client than the one which made the current
+// request and the session must be invalidated. The mismatches are logged and published whatever the strictness.
+func (c *AutheliaCtx) IsSessionPinViolated(userSession *session.UserSession) bool {
+	if !userSession.IsPinMismatched(c.SessionPin()) {
+		return false
+	}
+
+	strictness := c.Configuration.Session.Pinning.Strictness
+
+	c.Logger.Warnf("Session of user %s is used by another client than the one it's pinned to", userSession.Username)
+	c.PublishEvent(events.SessionPinMismatched, userSession.Username, map[string]string{
+		events.DetailStrictness: strictness,
+	})
+
+	return strictness != sessionPinningStrictnessLog
+}
+
+// ipNetwork returns the network of an IP address the sessions are pinned to, the /24 network of the IPv4 addresses and
+// the /48 network of the IPv6 addresses.
+func ipNetwork(ip net.IP) string {
+	if ip == nil {
+		return ""
+	}
+
+	if ipv4 := ip.To4(); ipv4 != nil {
+		return ipv4.Mask(net.CIDRMask(24, 32)).String()
+	}
+
+	return ip.Mask(net.CIDRMask(48, 128)).String()
+}
@@ -0,0 +1,103 @@
+package middlewares_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/events"
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/internal/session"
+)
+
+func TestShouldNotPinSessionsWhenPinningIsDisabled(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	assert.Nil(t, mock.Ctx.SessionPin())
+}
+
+func TestShouldPinSessionsToNetworkAndTLSFingerprint(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	mock.Ctx.Configuration.Session.Pinning = schema.SessionPinningConfiguration{
+		IP:                   true,
+		TLSFingerprint:       true,
+		TLSFingerprintHeader: "X-JA3-Fingerprint",
+	}
+
+	mock.Ctx.Request.Header.Set("X-Forwarded-For", "192.168.1.20")
+	mock.Ctx.Request.Header.Set("X-JA3-Fingerprint", "e7d705a3286e19ea42f587b344ee6865")
+
+	assert.Equal(t, &session.Pin{IPNetwork: "192.168.1.0", TLSFingerprint: "e7d705a3286e19ea42f587b344ee6865"}, mock.Ctx.SessionPin())
+
+	mock.Ctx.Request.Header.Set("X-Forwarded-For", "2001:db8:85a3:8d3:1319:8a2e:370:7348")
+
+	assert.Equal(t, "2001:db8:85a3::", mock.Ctx.SessionPin().IPNetwork)
+}
+
+func TestShouldDestroySessionPinnedToAnotherClient(t *testing.T) {
+	testCases := []struct {
+		name       string
+		strictness string
+		ip         string
+		allowed    bool
+		mismatched bool
+	}{
+		{"ShouldAllowSameNetwork", "strict", "192.168.1.50", true, false},
+		{"ShouldDenyOtherNetwork", "strict", "10.0.0.5", false, true},
+		{"ShouldOnlyLogOtherNetwork", "log", "10.0.0.5", true, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock := mocks.NewMockAutheliaCtx(t)
+			defer mock.Close()
+
+			mock.Ctx.Configuration.Session.Pinning = schema.SessionPinningConfiguration{IP: true, Strictness: tc.strictness}
+
+			var published []events.Event
+
+			mock.Ctx.Providers.Events.Subscribe(func(event events.Event) {
+				published = append(published, event)
+			}, events.SessionPinMismatched)
+
+			userSession := mock.Ctx.GetSession()
+			userSession.Username = "john"
+			userSession.AuthenticationLevel = authentication.OneFactor
+			userSession.Pin = &session.Pin{IPNetwork: "192.168.1.0"}
+			require.NoError(t, mock.Ctx.SaveSession(userSession))
+
+			mock.Ctx.Request.Header.Set("X-Forwarded-For", tc.ip)
+
+			called := false
+
+			middlewares.RequireFirstFactor(func(ctx *middlewares.AutheliaCtx) {
+				called = true
+			})(mock.Ctx)
+
+			assert.Equal(t, tc.allowed, called)
+
+			if tc.allowed {
+				assert.Equal(t, "john", mock.Ctx.GetSession().Username)
+			} else {
+				assert.Equal(t, 403, mock.Ctx.Response.StatusCode())
+				assert.Equal(t, "", mock.Ctx.GetSession().Username)
+			}
+
+			if !tc.mismatched {
+				assert.Len(t, published, 0)
+				return
+			}
+
+			require.Len(t, published, 1)
+			assert.Equal(t, "john", published[0].Username)
+			assert.Equal(t, map[string]string{events.DetailStrictness: tc.strictness}, published[0].Details)
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package middlewares
+
+import (
+	"github.com/authelia/authelia/internal/session"
+)
+
+// IsSessionRevoked returns true when the sessions of the user were revoked after the session was authenticated, e.g.
+// by an operator or when the user was purged, the session must then be destroyed. It's checked on every authenticated
+// request regardless of the refresh of the profile of the user.
+func (c *AutheliaCtx) IsSessionRevoked(userSession *session.UserSession) bool {
+	if userSession.Username == "" {
+		return false
+	}
+
+	revokedAt, err := c.Providers.StorageProvider.LoadSessionsRevocation(userSession.Username)
+	if err != nil {
+		c.Logger.Errorf("Unable to load the sessions revocation of user %s: %s", userSession.Username, err)
+		return false
+	}
+
+	if revokedAt.IsZero() || userSession.FirstFactorAuthnTimestamp > revokedAt.Unix() {
+		return false
+	}
+
+	c.Logger.Debugf("Session of user %s has been revoked", userSession.Username)
+
+	return true
+}
@@ -0,0 +1,60 @@
+package middlewares_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/pkg/autheliatest"
+)
+
+func TestShouldDestroySessionRevokedAfterItsAuthentication(t *testing.T) {
+	testCases := []struct {
+		name          string
+		authenticated time.Duration
+		allowed       bool
+	}{
+		{"ShouldDenySessionAuthenticatedBeforeRevocation", -time.Hour, false},
+		{"ShouldAllowSessionAuthenticatedAfterRevocation", time.Minute, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock := mocks.NewMockAutheliaCtx(t)
+			defer mock.Close()
+
+			mock.Clock.Set(time.Unix(1600000000, 0))
+			mock.Ctx.Clock = &mock.Clock
+
+			storageProvider := autheliatest.NewMemoryStorageProvider()
+			require.NoError(t, storageProvider.RevokeSessions("john", mock.Clock.Now()))
+			mock.Ctx.Providers.StorageProvider = storageProvider
+
+			userSession := mock.Ctx.GetSession()
+			userSession.Username = "john"
+			userSession.AuthenticationLevel = authentication.OneFactor
+			userSession.FirstFactorAuthnTimestamp = mock.Clock.Now().Add(tc.authenticated).Unix()
+			require.NoError(t, mock.Ctx.SaveSession(userSession))
+
+			called := false
+
+			middlewares.RequireFirstFactor(func(ctx *middlewares.AutheliaCtx) {
+				called = true
+			})(mock.Ctx)
+
+			assert.Equal(t, tc.allowed, called)
+
+			if tc.allowed {
+				assert.Equal(t, "john", mock.Ctx.GetSession().Username)
+			} else {
+				assert.Equal(t, 403, mock.Ctx.Response.StatusCode())
+				assert.Equal(t, "", mock.Ctx.GetSession().Username)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package middlewares
+
+import (
+	"time"
+
+	"github.com/authelia/authelia/internal/events"
+	"github.com/authelia/authelia/internal/session"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// IsSessionTravelImpossible returns true when the session is used from another country than the one it was last used
+// from within the impossible travel window, the session must be invalidated and the user is notified. Otherwise the
+// country the session is used from is recorded in the session.
+func (c *AutheliaCtx) IsSessionTravelImpossible(userSession *session.UserSession) bool {
+	configuration := c.Configuration.Session.ImpossibleTravel
+
+	if !configuration.Enabled || c.Providers.GeoIP == nil {
+		return false
+	}
+
+	location, err := c.Providers.GeoIP.Lookup(c.RemoteIP())
+	if err != nil {
+		c.Logger.Warnf("Unable to lookup the GeoIP location of %s: %v", c.RemoteIP(), err)
+		return false
+	}
+
+	// The session can't be located, e.g. when it's used from a private network.
+	if location == nil || location.Country == "" {
+		return false
+	}
+
+	now := c.Clock.Now()
+
+	if userSession.LastCountry != "" && userSession.LastCountry != location.Country {
+		window, err := utils.ParseDurationString(configuration.Window)
+		if err != nil {
+			c.Logger.Errorf("Unable to parse the session impossible travel window: %s", err)
+			return false
+		}
+
+		if now.Sub(time.Unix(userSession.LastCountryTimestamp, 0)) < window {
+			c.Logger.Warnf("Session of user %s is used from %s shortly after being used from %s", userSession.Username, location.Country, userSession.LastCountry)
+
+			details := map[string]string{
+				events.DetailLocation:         location.String(),
+				events.DetailPreviousLocation: userSession.LastCountry,
+			}
+
+			if len(userSession.Emails) != 0 {
+				details[events.DetailEmail] = userSession.Emails[0]
+			}
+
+			c.PublishEvent(events.SessionImpossibleTravel, userSession.Username, details)
+
+			return true
+		}
+	}
+
+	if userSession.LastCountry == location.Country && now.Sub(time.Unix(userSession.LastCountryTimestamp, 0)) < sessionLastCountryRefreshInterval {
+		return false
+	}
+
+	userSession.LastCountry = location.Country
+	userSession.LastCountryTimestamp = now.Unix()
+
+	if err = c.SaveSession(*userSession); err != nil {
+		c.Logger.Errorf("Unable to save the country the session of user %s is used from: %s", userSession.Username, err)
+	}
+
+	return false
+}
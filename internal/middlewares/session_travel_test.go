@@ -0,0 +1,124 @@
+package middlewares_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/events"
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/mocks"
+)
+
+func TestShouldRecordCountryOfSession(t *testing.T) {
+	mock := mocks.NewMockAutheliaCtx(t)
+	defer mock.Close()
+
+	mock.Clock.Set(time.Unix(1600000000, 0))
+	mock.Ctx.Clock = &mock.Clock
+	mock.Ctx.Configuration.Session.ImpossibleTravel = schema.SessionImpossibleTravelConfiguration{Enabled: true, Window: "2h"}
+	mock.Ctx.Providers.GeoIP = staticGeoIPProvider{
+		"192.168.1.10": {Country: "France", City: "Paris"},
+	}
+
+	mock.Ctx.Request.Header.Set("X-Forwarded-For", "192.168.1.10")
+
+	userSession := mock.Ctx.GetSession()
+	userSession.Username = "john"
+
+	assert.False(t, mock.Ctx.IsSessionTravelImpossible(&userSession))
+	assert.Equal(t, "France", mock.Ctx.GetSession().LastCountry)
+	assert.Equal(t, int64(1600000000), mock.Ctx.GetSession().LastCountryTimestamp)
+
+	// The time the session was last used from its country is only refreshed once per minute.
+	mock.Clock.Set(time.Unix(1600000030, 0))
+
+	assert.False(t, mock.Ctx.IsSessionTravelImpossible(&userSession))
+	assert.Equal(t, int64(1600000000), mock.Ctx.GetSession().LastCountryTimestamp)
+
+	mock.Clock.Set(time.Unix(1600000060, 0))
+
+	assert.False(t, mock.Ctx.IsSessionTravelImpossible(&userSession))
+	assert.Equal(t, int64(1600000060), mock.Ctx.GetSession().LastCountryTimestamp)
+
+	// The sessions used from unknown locations are ignored.
+	mock.Ctx.Request.Header.Set("X-Forwarded-For", "10.0.0.1")
+
+	assert.False(t, mock.Ctx.IsSessionTravelImpossible(&userSession))
+	assert.Equal(t, "France", mock.Ctx.GetSession().LastCountry)
+}
+
+func TestShouldDestroySessionUsedFromDistantLocations(t *testing.T) {
+	testCases := []struct {
+		name     string
+		ip       string
+		elapsed  time.Duration
+		allowed  bool
+		location string
+	}{
+		{"ShouldAllowSameCountry", "192.168.1.10", time.Minute, true, ""},
+		{"ShouldAllowOtherCountryAfterWindow", "192.168.2.10", 3 * time.Hour, true, ""},
+		{"ShouldDenyOtherCountryWithinWindow", "192.168.2.10", time.Hour, false, "Tokyo, Japan"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock := mocks.NewMockAutheliaCtx(t)
+			defer mock.Close()
+
+			mock.Clock.Set(time.Unix(1600000000, 0).Add(tc.elapsed))
+			mock.Ctx.Clock = &mock.Clock
+			mock.Ctx.Configuration.Session.ImpossibleTravel = schema.SessionImpossibleTravelConfiguration{Enabled: true, Window: "2h"}
+			mock.Ctx.Providers.GeoIP = staticGeoIPProvider{
+				"192.168.1.10": {Country: "France", City: "Paris"},
+				"192.168.2.10": {Country: "Japan", City: "Tokyo"},
+			}
+
+			var published []events.Event
+
+			mock.Ctx.Providers.Events.Subscribe(func(event events.Event) {
+				published = append(published, event)
+			}, events.SessionImpossibleTravel)
+
+			userSession := mock.Ctx.GetSession()
+			userSession.Username = "john"
+			userSession.Emails = []string{"john@example.com"}
+			userSession.AuthenticationLevel = authentication.OneFactor
+			userSession.LastCountry = "France"
+			userSession.LastCountryTimestamp = 1600000000
+			require.NoError(t, mock.Ctx.SaveSession(userSession))
+
+			mock.Ctx.Request.Header.Set("X-Forwarded-For", tc.ip)
+
+			called := false
+
+			middlewares.RequireFirstFactor(func(ctx *middlewares.AutheliaCtx) {
+				called = true
+			})(mock.Ctx)
+
+			assert.Equal(t, tc.allowed, called)
+
+			if tc.allowed {
+				assert.Equal(t, "john", mock.Ctx.GetSession().Username)
+				assert.Len(t, published, 0)
+
+				return
+			}
+
+			assert.Equal(t, 403, mock.Ctx.Response.StatusCode())
+			assert.Equal(t, "", mock.Ctx.GetSession().Username)
+
+			require.Len(t, published, 1)
+			assert.Equal(t, "john", published[0].Username)
+			assert.Equal(t, map[string]string{
+				events.DetailLocation:         tc.location,
+				events.DetailPreviousLocation: "France",
+				events.DetailEmail:            "john@example.com",
+			}, published[0].Details)
+		})
+	}
+}
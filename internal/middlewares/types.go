@@ -8,9 +8,13 @@ import (
 	"github.com/authelia/authelia/internal/authentication"
 	"github.com/authelia/authelia/internal/authorization"
 	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/events"
+	"github.com/authelia/authelia/internal/geoip"
+	"github.com/authelia/authelia/internal/logout"
 	"github.com/authelia/authelia/internal/notification"
 	"github.com/authelia/authelia/internal/oidc"
 	"github.com/authelia/authelia/internal/regulation"
+	"github.com/authelia/authelia/internal/risk"
 	"github.com/authelia/authelia/internal/session"
 	"github.com/authelia/authelia/internal/storage"
 	"github.com/authelia/authelia/internal/utils"
@@ -37,6 +41,11 @@ type Providers struct {
 	UserProvider    authentication.UserProvider
 	StorageProvider storage.Provider
 	Notifier        notification.Notifier
+	GeoIP           geoip.Provider
+	Risk            *risk.Evaluator
+	Events          *events.Bus
+	EventStream     *events.Broker
+	Logout          *logout.Provider
 }
 
 // RequestHandler represents an Authelia request handler.
@@ -45,6 +54,9 @@ type RequestHandler = func(*AutheliaCtx)
 // Middleware represent an Authelia middleware.
 type Middleware = func(RequestHandler) RequestHandler
 
+// Chain is an ordered list of Middleware, the first Middleware in the list is the outermost one.
+type Chain []Middleware
+
 // RequestHandlerBridge bridge a AutheliaCtx handle to a RequestHandler handler.
 type RequestHandlerBridge = func(RequestHandler) fasthttp.RequestHandler
 
@@ -101,6 +113,14 @@ type OKResponse struct {
 	Data   interface{} `json:"data,omitempty"`
 }
 
+// BodyValidationError is returned by AutheliaCtx.ParseBody when the body is not valid, Fields contains the validation
+// error of each invalid field keyed by the field name.
+type BodyValidationError struct {
+	Fields map[string]string
+
+	err error
+}
+
 // ErrorResponse model of an error response.
 type ErrorResponse struct {
 	Status  string `json:"status"`
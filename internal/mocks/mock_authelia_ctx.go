@@ -15,10 +15,13 @@ import (
 
 	"github.com/authelia/authelia/internal/authorization"
 	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/events"
 	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/notification"
 	"github.com/authelia/authelia/internal/regulation"
 	"github.com/authelia/authelia/internal/session"
 	"github.com/authelia/authelia/internal/storage"
+	"github.com/authelia/authelia/internal/utils"
 )
 
 // MockAutheliaCtx a mock of AutheliaCtx.
@@ -38,31 +41,12 @@ type MockAutheliaCtx struct {
 	Clock TestingClock
 }
 
-// TestingClock implementation of clock for tests.
-type TestingClock struct {
-	now time.Time
-}
-
-// Now return the stored clock.
-func (dc *TestingClock) Now() time.Time {
-	return dc.now
-}
-
-// After return a channel receiving the time after duration has elapsed.
-func (dc *TestingClock) After(d time.Duration) <-chan time.Time {
-	return time.After(d)
-}
-
-// Set set the time of the clock.
-func (dc *TestingClock) Set(now time.Time) {
-	dc.now = now
-}
+// TestingClock implementation of clock for tests, the clock of the context uses it once assigned to Ctx.Clock.
+type TestingClock = utils.FakeClock
 
 // NewMockAutheliaCtx create an instance of AutheliaCtx mock.
 func NewMockAutheliaCtx(t *testing.T) *MockAutheliaCtx {
 	mockAuthelia := new(MockAutheliaCtx)
-	mockAuthelia.Clock = TestingClock{}
-
 	datetime, _ := time.Parse("2006-Jan-02", "2013-Feb-03")
 	mockAuthelia.Clock.Set(datetime)
 
@@ -101,9 +85,16 @@ func NewMockAutheliaCtx(t *testing.T) *MockAutheliaCtx {
 	mockAuthelia.StorageProviderMock = storage.NewMockProvider(mockAuthelia.Ctrl)
 	providers.StorageProvider = mockAuthelia.StorageProviderMock
 
+	// The sessions revocation is checked on every authenticated request, the sessions are never revoked by default. The
+	// tests revoking the sessions use the autheliatest.MemoryStorageProvider.
+	mockAuthelia.StorageProviderMock.EXPECT().LoadSessionsRevocation(gomock.Any()).Return(time.Time{}, nil).AnyTimes()
+
 	mockAuthelia.NotifierMock = NewMockNotifier(mockAuthelia.Ctrl)
 	providers.Notifier = mockAuthelia.NotifierMock
 
+	providers.Events = events.NewBus()
+	providers.Events.Subscribe(notification.NewDeviceRegistrationSubscriber(mockAuthelia.NotifierMock, false), events.DeviceRegistered)
+
 	providers.Authorizer = authorization.NewAuthorizer(
 		&configuration)
 
@@ -127,6 +118,22 @@ func NewMockAutheliaCtx(t *testing.T) *MockAutheliaCtx {
 	return mockAuthelia
 }
 
+// NewMockAutheliaCtxWithConfiguration create an instance of AutheliaCtx mock using the provided configuration. The
+// authorizer is created from the access control rules of the configuration.
+func NewMockAutheliaCtxWithConfiguration(t *testing.T, configuration schema.Configuration) *MockAutheliaCtx {
+	mockAuthelia := NewMockAutheliaCtx(t)
+
+	mockAuthelia.Ctx.Configuration = configuration
+	mockAuthelia.Ctx.Providers.Authorizer = authorization.NewAuthorizer(&configuration)
+
+	return mockAuthelia
+}
+
+// SetUserSession saves the provided session as the session of the mocked request.
+func (m *MockAutheliaCtx) SetUserSession(t *testing.T, userSession session.UserSession) {
+	require.NoError(t, m.Ctx.SaveSession(userSession))
+}
+
 // Close close the mock.
 func (m *MockAutheliaCtx) Close() {
 	m.Hook.Reset()
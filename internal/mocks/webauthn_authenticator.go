@@ -0,0 +1,134 @@
+package mocks
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+
+	"github.com/authelia/authelia/internal/webauthn"
+)
+
+// WebauthnAuthenticator is a software WebAuthn authenticator holding a single ES256 discoverable credential, intended
+// to be used by tests to perform the registration and authentication ceremonies.
+type WebauthnAuthenticator struct {
+	CredentialID []byte
+	UserHandle   []byte
+	SignCount    uint32
+
+	// UserVerified sets the user verified flag in the authenticator data.
+	UserVerified bool
+
+	key *ecdsa.PrivateKey
+}
+
+// NewWebauthnAuthenticator creates a new WebauthnAuthenticator with a newly generated credential.
+func NewWebauthnAuthenticator(userHandle []byte) *WebauthnAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+
+	id := make([]byte, 16)
+	if _, err = rand.Read(id); err != nil {
+		panic(err)
+	}
+
+	return &WebauthnAuthenticator{CredentialID: id, UserHandle: userHandle, UserVerified: true, key: key}
+}
+
+// PublicKey returns the COSE encoded public key of the credential.
+func (a *WebauthnAuthenticator) PublicKey() []byte {
+	x, y := a.key.X.FillBytes(make([]byte, 32)), a.key.Y.FillBytes(make([]byte, 32))
+
+	// COSE_Key map {1: 2 (EC2), 3: -7 (ES256), -1: 1 (P-256), -2: x, -3: y}.
+	key := []byte{0xa5, 0x01, 0x02, 0x03, 0x26, 0x20, 0x01, 0x21, 0x58, 0x20}
+	key = append(key, x...)
+	key = append(key, 0x22, 0x58, 0x20)
+
+	return append(key, y...)
+}
+
+// Create performs the registration ceremony.
+func (a *WebauthnAuthenticator) Create(rpID, origin string, challenge []byte) webauthn.CredentialAttestation {
+	authData := a.authenticatorData(rpID)
+	authData[32] |= 0x40
+
+	// AAGUID, credential ID length, credential ID and public key.
+	authData = append(authData, make([]byte, 16)...)
+	authData = append(authData, byte(len(a.CredentialID)>>8), byte(len(a.CredentialID)))
+	authData = append(authData, a.CredentialID...)
+	authData = append(authData, a.PublicKey()...)
+
+	// Attestation object map {"fmt": "none", "attStmt": {}, "authData": authData}.
+	object := []byte{0xa3, 0x63, 'f', 'm', 't', 0x64, 'n', 'o', 'n', 'e', 0x67, 'a', 't', 't', 'S', 't', 'm', 't', 0xa0}
+	object = append(object, 0x68, 'a', 'u', 't', 'h', 'D', 'a', 't', 'a', 0x59, byte(len(authData)>>8), byte(len(authData)))
+	object = append(object, authData...)
+
+	return webauthn.CredentialAttestation{
+		ID:    base64.RawURLEncoding.EncodeToString(a.CredentialID),
+		RawID: a.CredentialID,
+		Type:  "public-key",
+		Response: webauthn.AttestationResponse{
+			ClientDataJSON:    clientDataJSON("webauthn.create", origin, challenge),
+			AttestationObject: object,
+		},
+	}
+}
+
+// Get performs the authentication ceremony, the signature counter is incremented on each call.
+func (a *WebauthnAuthenticator) Get(rpID, origin string, challenge []byte) webauthn.CredentialAssertion {
+	a.SignCount++
+
+	authData := a.authenticatorData(rpID)
+	clientData := clientDataJSON("webauthn.get", origin, challenge)
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte(nil), authData...), clientDataHash[:]...))
+
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		panic(err)
+	}
+
+	return webauthn.CredentialAssertion{
+		ID:    base64.RawURLEncoding.EncodeToString(a.CredentialID),
+		RawID: a.CredentialID,
+		Type:  "public-key",
+		Response: webauthn.AssertionResponse{
+			ClientDataJSON:    clientData,
+			AuthenticatorData: authData,
+			Signature:         signature,
+			UserHandle:        a.UserHandle,
+		},
+	}
+}
+
+func (a *WebauthnAuthenticator) authenticatorData(rpID string) []byte {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+
+	flags := byte(0x01)
+	if a.UserVerified {
+		flags |= 0x04
+	}
+
+	authData := append(rpIDHash[:], flags, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(authData[33:], a.SignCount)
+
+	return authData
+}
+
+func clientDataJSON(ceremony, origin string, challenge []byte) []byte {
+	data, err := json.Marshal(map[string]string{
+		"type":      ceremony,
+		"challenge": base64.RawURLEncoding.EncodeToString(challenge),
+		"origin":    origin,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	return data
+}
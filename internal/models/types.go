@@ -2,6 +2,18 @@ package models
 
 import "time"
 
+// Types of the authentication attempts.
+const (
+	AuthenticationTypeFirstFactor = "1FA"
+	AuthenticationTypePasskey     = "Passkey"
+	AuthenticationTypeTOTP        = "TOTP"
+	AuthenticationTypeU2F         = "U2F"
+	AuthenticationTypeDuo         = "Duo"
+	AuthenticationTypeWebauthn    = "WebAuthn"
+	AuthenticationTypeBackupCode  = "BackupCode"
+	AuthenticationTypePush        = "Push"
+)
+
 // AuthenticationAttempt represent an authentication attempt.
 type AuthenticationAttempt struct {
 	// The user who tried to authenticate.
@@ -10,4 +22,417 @@ type AuthenticationAttempt struct {
 	Successful bool
 	// The time of the attempt.
 	Time time.Time
+	// The IP of the client which made the attempt.
+	RemoteIP string
+	// The user agent of the client which made the attempt.
+	UserAgent string
+	// The type of the attempt such as 1FA or TOTP, it's empty for the attempts recorded before it was introduced.
+	Type string
+}
+
+// IsSecondFactor returns true if the attempt is a second factor attempt. The attempts without type were recorded before
+// the second factor attempts were, they are first factor attempts.
+func (a AuthenticationAttempt) IsSecondFactor() bool {
+	switch a.Type {
+	case AuthenticationTypeTOTP, AuthenticationTypeU2F, AuthenticationTypeDuo, AuthenticationTypeWebauthn, AuthenticationTypeBackupCode,
+		AuthenticationTypePush:
+		return true
+	default:
+		return false
+	}
+}
+
+// Types of the user events other than the authentication attempts.
+const (
+	UserEventTOTPRegistered       = "TOTPRegistered"
+	UserEventTOTPDeleted          = "TOTPDeleted"
+	UserEventU2FRegistered        = "U2FRegistered"
+	UserEventU2FDeleted           = "U2FDeleted"
+	UserEventPasskeyRegistered    = "PasskeyRegistered"
+	UserEventWebauthnRegistered   = "WebauthnRegistered"
+	UserEventWebauthnDeleted      = "WebauthnDeleted"
+	UserEventPasswordReset        = "PasswordReset"
+	UserEventBackupCodesGenerated = "BackupCodesGenerated"
+	UserEventSecondFactorReset    = "SecondFactorReset"
+)
+
+// UserEvent represents a security event of a user such as an authentication attempt or the registration of a device.
+type UserEvent struct {
+	// The user the event relates to.
+	Username string
+	// The type of the event, it's the type of the attempt for the authentication attempts.
+	Type string
+	// Successful true if the event was successful, the events other than authentication attempts are always successful.
+	Successful bool
+	// The time of the event.
+	Time time.Time
+	// The IP of the client which triggered the event.
+	RemoteIP string
+	// The user agent of the client which triggered the event.
+	UserAgent string
+}
+
+// TOTPDevice represents a device generating the one-time passwords of a user, such as an authenticator application.
+type TOTPDevice struct {
+	// The ID of the device, it's unique among the devices of the user.
+	ID string
+	// The user who owns the device.
+	Username string
+	// The friendly name given by the user to tell the devices apart.
+	Description string
+	// The base32 encoded secret shared with the device.
+	Secret string
+	// The time the device was registered, it's zero for the device registered before several devices were supported.
+	CreatedAt time.Time
+	// The time a passcode of the device was last used, it's zero if the device has never been used.
+	LastUsedAt time.Time
+}
+
+// WebauthnCredential represents a WebAuthn credential registered by a user.
+type WebauthnCredential struct {
+	// The ID of the credential as generated by the authenticator.
+	ID []byte
+	// The user who owns the credential.
+	Username string
+	// The type of the credential, either WebauthnCredentialTypePasskey or WebauthnCredentialTypeSecondFactor.
+	Type string
+	// The friendly name given by the user to tell the credentials apart, it's empty for the passkeys registered before
+	// it was introduced.
+	Description string
+	// The COSE encoded public key of the credential.
+	PublicKey []byte
+	// The signature counter last reported by the authenticator.
+	SignCount uint32
+	// The time the credential was registered.
+	CreatedAt time.Time
+}
+
+// IsPasskey returns true if the credential can be used to login without a password.
+func (c WebauthnCredential) IsPasskey() bool {
+	return c.Type == WebauthnCredentialTypePasskey
+}
+
+// IsSecondFactor returns true if the credential can be used as a second factor.
+func (c WebauthnCredential) IsSecondFactor() bool {
+	return c.Type == WebauthnCredentialTypeSecondFactor
+}
+
+// Types of the WebAuthn credentials, the passkeys are used to login without a password and the second factor
+// credentials are used as a second factor only so a single authenticator never satisfies both factors.
+const (
+	WebauthnCredentialTypePasskey      = "passkey"
+	WebauthnCredentialTypeSecondFactor = "second_factor"
+)
+
+// Kinds of the throttled notifications.
+const (
+	NotificationKindDeviceRegistered        = "device_registered"
+	NotificationKindDeviceRemoved           = "device_removed"
+	NotificationKindLoginFailed             = "login_failed"
+	NotificationKindSessionImpossibleTravel = "session_impossible_travel"
+)
+
+// Enrollment states of the users with the push notification provider.
+const (
+	// PushEnrollmentEnrolled is the state of the users able to approve the push notifications.
+	PushEnrollmentEnrolled = "enrolled"
+	// PushEnrollmentNotEnrolled is the state of the users who haven't enrolled a device with the provider yet.
+	PushEnrollmentNotEnrolled = "not_enrolled"
+	// PushEnrollmentDenied is the state of the users the provider denies the authentication to.
+	PushEnrollmentDenied = "denied"
+)
+
+// PushEnrollment represents the enrollment state of a user with the push notification provider, as of the last time
+// the user requested a push notification.
+type PushEnrollment struct {
+	// The user the state belongs to.
+	Username string
+	// The name of the provider such as duo.
+	Provider string
+	// The state of the enrollment such as enrolled.
+	Status string
+	// The number of devices of the user able to receive the push notifications.
+	Devices int
+	// The time the state was checked with the provider.
+	CheckedAt time.Time
+}
+
+// Approvals of the second factor resets, i.e. who approves the reset requested by an operator.
+const (
+	// SecondFactorResetApprovalAdmin is the approval of the resets by another operator granted the admin role.
+	SecondFactorResetApprovalAdmin = "admin"
+	// SecondFactorResetApprovalUser is the approval of the resets by the user following the link sent by email.
+	SecondFactorResetApprovalUser = "user"
+)
+
+// States of the second factor resets.
+const (
+	// SecondFactorResetPending is the state of the resets waiting for the approval.
+	SecondFactorResetPending = "pending"
+	// SecondFactorResetApproved is the state of the resets approved, the devices of the user have been deleted.
+	SecondFactorResetApproved = "approved"
+	// SecondFactorResetRejected is the state of the resets rejected by an operator.
+	SecondFactorResetRejected = "rejected"
+)
+
+// SecondFactorReset represents the request of an operator to delete all the second factor devices of a user, for
+// instance when the user lost them. The devices are only deleted once a second operator or the user approved it.
+type SecondFactorReset struct {
+	// The ID of the reset.
+	ID string
+	// The user whose devices are deleted.
+	Username string
+	// The operator who requested the reset.
+	RequestedBy string
+	// The reason given by the operator who requested the reset.
+	Reason string
+	// Who approves the reset, one of SecondFactorResetApprovalAdmin and SecondFactorResetApprovalUser.
+	Approval string
+	// The SHA-256 hash of the token sent to the user to approve the reset, it's only set for the user approvals.
+	ApprovalTokenHash string
+	// The state of the reset such as pending.
+	Status string
+	// The operator or user who approved or rejected the reset.
+	DecidedBy string
+	// The time the reset was requested.
+	RequestedAt time.Time
+	// The time the reset can't be approved anymore.
+	ExpiresAt time.Time
+	// The time the reset was approved or rejected, it's zero while the reset is pending.
+	DecidedAt time.Time
+}
+
+// IsPending returns true when the reset can still be approved at the given time.
+func (r SecondFactorReset) IsPending(now time.Time) bool {
+	return r.Status == SecondFactorResetPending && now.Before(r.ExpiresAt)
+}
+
+// AccessGrant represents a time-boxed access to a protected resource given to a guest without an account, the signed
+// token of the grant isn't saved.
+type AccessGrant struct {
+	// The ID of the grant, it's the ID of its token.
+	ID string
+	// The user or the admin API token who created the grant.
+	Username string
+	// The URL of the resource the grant gives access to, the resources below it are covered when it ends with a slash.
+	TargetURL string
+	// The description of the grant, e.g. who it has been given to.
+	Description string
+	// The time the grant was created.
+	CreatedAt time.Time
+	// The time the grant expires.
+	ExpiresAt time.Time
+}
+
+// BreakGlassActivation represents the first use of a break-glass account since its last reset, the account is disabled
+// once the break-glass window has elapsed since then.
+type BreakGlassActivation struct {
+	// The username of the break-glass account.
+	Username string
+	// The time the break-glass account was first used.
+	ActivatedAt time.Time
+}
+
+// OIDCConsent represents the consent of a user to the scopes and audience requested by an OpenID Connect client which
+// the user asked to remember, the consent isn't asked again until it expires or is revoked.
+type OIDCConsent struct {
+	// The user who consented.
+	Username string
+	// The ID of the client the user consented to.
+	ClientID string
+	// The scopes granted to the client.
+	Scopes []string
+	// The audience granted to the client.
+	Audience []string
+	// The time the user consented.
+	CreatedAt time.Time
+	// The time the consent expires.
+	ExpiresAt time.Time
+}
+
+// OIDCClient represents an OpenID Connect client registered at runtime with the dynamic registration endpoint, the
+// clients of the configuration aren't stored.
+type OIDCClient struct {
+	// The ID of the client generated at its registration.
+	ID string
+	// The name of the client shown to the users on the consent page.
+	Description string
+	// The crypt hash of the secret of the client, the secret itself is only returned once at the registration.
+	SecretHash string
+	// The authorization policy of the client such as two_factor.
+	Policy string
+	// The URIs the users are redirected to after the authorization.
+	RedirectURIs []string
+	// The grant types the client may use such as authorization_code.
+	GrantTypes []string
+	// The response types the client may use such as code.
+	ResponseTypes []string
+	// The scopes the client may request.
+	Scopes []string
+	// The URIs the users may be redirected to after they logged out.
+	PostLogoutRedirectURIs []string
+	// The URI the logout tokens are sent to, it's empty when the client doesn't support the back-channel logout.
+	BackChannelLogoutURI string
+	// The URI loaded in a frame when the users log out, it's empty when the client doesn't support the front-channel
+	// logout.
+	FrontChannelLogoutURI string
+	// The operator or the description of the admin API token who registered the client.
+	RegisteredBy string
+	// The time the client was registered.
+	CreatedAt time.Time
+}
+
+// NotificationThrottle represents the state of the throttling of a kind of security notification sent to a user.
+type NotificationThrottle struct {
+	// The user the notifications are sent to.
+	Username string
+	// The kind of notification such as device_registered.
+	Kind string
+	// The time the last notification of this kind has been sent to the user, it's zero when none has been sent.
+	LastSentAt time.Time
+	// The number of notifications of this kind not sent yet to the user, they're sent as a digest.
+	Pending int
+	// The time of the first of the pending notifications, it's zero when there is none.
+	PendingSince time.Time
+}
+
+// Scopes of the admin API tokens.
+const (
+	// AdminAPIScopeRead allows the read-only operations on all the resources of the admin API.
+	AdminAPIScopeRead = "read"
+	// AdminAPIScopeUsers allows all the operations on the users such as the purge.
+	AdminAPIScopeUsers = "users"
+	// AdminAPIScopeOIDCKeys allows all the operations on the OpenID Connect issuer keys such as the rotation.
+	AdminAPIScopeOIDCKeys = "oidc_keys"
+	// AdminAPIScopeAccessGrants allows all the operations on the access grants such as their revocation.
+	AdminAPIScopeAccessGrants = "access_grants"
+	// AdminAPIScopeOIDCClients allows all the operations on the OpenID Connect clients such as their registration.
+	AdminAPIScopeOIDCClients = "oidc_clients"
+)
+
+// AdminAPIScopes are the scopes the admin API tokens can be granted.
+var AdminAPIScopes = []string{AdminAPIScopeRead, AdminAPIScopeUsers, AdminAPIScopeOIDCKeys, AdminAPIScopeAccessGrants, AdminAPIScopeOIDCClients}
+
+// Roles of the users allowed to use the admin API, they're granted to the members of the groups configured for them.
+const (
+	// AdminRoleAuditor allows the read-only operations on all the resources of the admin API.
+	AdminRoleAuditor = "auditor"
+	// AdminRoleHelpdesk allows to read the users, to manage their bans and sessions and to request the reset of their
+	// second factor.
+	AdminRoleHelpdesk = "helpdesk"
+	// AdminRoleAdmin allows all the operations of the admin API.
+	AdminRoleAdmin = "admin"
+)
+
+// AdminPermission represents an operation of the admin API, the roles of the users are granted permissions while the
+// admin API tokens are granted scopes.
+type AdminPermission struct {
+	// The name of the permission such as users.purge.
+	Name string
+	// The scope an admin API token must be granted to perform the operation.
+	Scope string
+	// True when the operation changes the resources, the read scope doesn't allow it.
+	Write bool
+	// True when the operation is only allowed to the users, never to the admin API tokens, e.g. the approvals which
+	// must be made by a second operator.
+	UsersOnly bool
+}
+
+// Permissions of the operations of the admin API.
+var (
+	// AdminPermissionUsersRead allows to read the devices and the events of the users.
+	AdminPermissionUsersRead = AdminPermission{Name: "users.read", Scope: AdminAPIScopeUsers}
+	// AdminPermissionUsersManage allows to reset the preferred method, lift the bans and revoke the sessions of the users.
+	AdminPermissionUsersManage = AdminPermission{Name: "users.manage", Scope: AdminAPIScopeUsers, Write: true}
+	// AdminPermissionUsersDevicesDelete allows to delete a second factor device of the users directly, the other
+	// operators and the admin API tokens must go through the approved second factor resets.
+	AdminPermissionUsersDevicesDelete = AdminPermission{Name: "users.devices.delete", Scope: AdminAPIScopeUsers, Write: true, UsersOnly: true}
+	// AdminPermissionUsersPurge allows to purge the departing users.
+	AdminPermissionUsersPurge = AdminPermission{Name: "users.purge", Scope: AdminAPIScopeUsers, Write: true}
+	// AdminPermissionSecondFactorResetsRequest allows to request the reset of the second factor devices of the users,
+	// and to reject the pending requests.
+	AdminPermissionSecondFactorResetsRequest = AdminPermission{Name: "second_factor_resets.request", Scope: AdminAPIScopeUsers, Write: true}
+	// AdminPermissionSecondFactorResetsApprove allows to approve the second factor resets requested by another operator.
+	AdminPermissionSecondFactorResetsApprove = AdminPermission{Name: "second_factor_resets.approve", Scope: AdminAPIScopeUsers, Write: true, UsersOnly: true}
+	// AdminPermissionOIDCKeysRead allows to list the OpenID Connect issuer keys.
+	AdminPermissionOIDCKeysRead = AdminPermission{Name: "oidc_keys.read", Scope: AdminAPIScopeOIDCKeys}
+	// AdminPermissionOIDCKeysRotate allows to rotate the OpenID Connect issuer keys.
+	AdminPermissionOIDCKeysRotate = AdminPermission{Name: "oidc_keys.rotate", Scope: AdminAPIScopeOIDCKeys, Write: true}
+	// AdminPermissionAccessGrantsRead allows to list the access grants of all the users.
+	AdminPermissionAccessGrantsRead = AdminPermission{Name: "access_grants.read", Scope: AdminAPIScopeAccessGrants}
+	// AdminPermissionAccessGrantsManage allows to create access grants and to revoke the access grants of all the users.
+	AdminPermissionAccessGrantsManage = AdminPermission{Name: "access_grants.manage", Scope: AdminAPIScopeAccessGrants, Write: true}
+	// AdminPermissionOIDCClientsRead allows to list the OpenID Connect clients registered at runtime.
+	AdminPermissionOIDCClientsRead = AdminPermission{Name: "oidc_clients.read", Scope: AdminAPIScopeOIDCClients}
+	// AdminPermissionOIDCClientsRegister allows to register OpenID Connect clients with the dynamic registration
+	// endpoint.
+	AdminPermissionOIDCClientsRegister = AdminPermission{Name: "oidc_clients.register", Scope: AdminAPIScopeOIDCClients, Write: true}
+	// AdminPermissionOIDCClientsDelete allows to delete the OpenID Connect clients registered at runtime.
+	AdminPermissionOIDCClientsDelete = AdminPermission{Name: "oidc_clients.delete", Scope: AdminAPIScopeOIDCClients, Write: true}
+)
+
+// AdminRolePermissions are the permissions granted to each role.
+var AdminRolePermissions = map[string][]AdminPermission{
+	AdminRoleAuditor: {
+		AdminPermissionUsersRead, AdminPermissionOIDCKeysRead, AdminPermissionAccessGrantsRead,
+		AdminPermissionOIDCClientsRead,
+	},
+	AdminRoleHelpdesk: {
+		AdminPermissionUsersRead, AdminPermissionUsersManage, AdminPermissionSecondFactorResetsRequest,
+		AdminPermissionAccessGrantsRead,
+	},
+	AdminRoleAdmin: {
+		AdminPermissionUsersRead, AdminPermissionUsersManage, AdminPermissionUsersDevicesDelete, AdminPermissionUsersPurge,
+		AdminPermissionSecondFactorResetsRequest, AdminPermissionSecondFactorResetsApprove,
+		AdminPermissionOIDCKeysRead, AdminPermissionOIDCKeysRotate,
+		AdminPermissionAccessGrantsRead, AdminPermissionAccessGrantsManage,
+		AdminPermissionOIDCClientsRead, AdminPermissionOIDCClientsRegister, AdminPermissionOIDCClientsDelete,
+	},
+}
+
+// AdminRoleHasPermission returns true when the role is granted the permission.
+func AdminRoleHasPermission(role string, permission AdminPermission) bool {
+	for _, p := range AdminRolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AdminAPIToken represents a token allowing automation to call the admin API without a session, only its hash is
+// saved.
+type AdminAPIToken struct {
+	// The ID of the token used to manage it, it's not a secret.
+	ID string
+	// The description of the automation the token has been created for.
+	Description string
+	// The SHA-256 hash of the token.
+	TokenHash string
+	// The scopes granted to the token.
+	Scopes []string
+	// The time the token was created.
+	CreatedAt time.Time
+	// The time the token expires, it's zero when it never expires.
+	ExpiresAt time.Time
+	// The time the token was last used, it's zero if the token has never been used.
+	LastUsedAt time.Time
+}
+
+// IsExpired returns true when the token has expired at the given time.
+func (t AdminAPIToken) IsExpired(now time.Time) bool {
+	return !t.ExpiresAt.IsZero() && !now.Before(t.ExpiresAt)
+}
+
+// HasScope returns true when the token is allowed to perform an operation on the resource of the given scope, the read
+// scope allows the read-only operations on all the resources.
+func (t AdminAPIToken) HasScope(scope string, write bool) bool {
+	for _, s := range t.Scopes {
+		if s == scope || (!write && s == AdminAPIScopeRead) {
+			return true
+		}
+	}
+
+	return false
 }
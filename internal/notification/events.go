@@ -0,0 +1,159 @@
+package notification
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/events"
+	"github.com/authelia/authelia/internal/logging"
+	"github.com/authelia/authelia/internal/templates"
+)
+
+// NewDeviceRegistrationSubscriber creates a subscriber of the DeviceRegistered events sending a security notification
+// to the user who registered the device. Failing to notify the user doesn't fail the registration.
+func NewDeviceRegistrationSubscriber(notifier Notifier, disableHTMLEmails bool) events.Subscriber {
+	return func(event events.Event) {
+		device := event.Details[events.DetailDevice]
+
+		title := fmt.Sprintf("New %s registered", device)
+		body := fmt.Sprintf("A new %s has been registered on your account.", device)
+
+		if event.Details[events.DetailReplaced] == "true" {
+			body += fmt.Sprintf(" It replaces the %s previously registered which can no longer be used.", device)
+		}
+
+		body += " If you did not register it your credentials might have been compromised. You should reset your password and contact an administrator."
+
+		sendSecurityNotification(notifier, disableHTMLEmails, event, fmt.Sprintf("the registration of a %s", device), title, body)
+	}
+}
+
+// NewDeviceRemovalSubscriber creates a subscriber of the DeviceRevoked and SecondFactorResetApproved events sending a
+// security notification to the user whose second factor devices have been deleted. The email address of the user is
+// found with the users provider when the user didn't delete the device themselves.
+func NewDeviceRemovalSubscriber(notifier Notifier, users authentication.UserProvider, disableHTMLEmails bool) events.Subscriber {
+	return func(event events.Event) {
+		var what, title, body string
+
+		device := event.Details[events.DetailDevice]
+
+		switch {
+		case event.Type == events.SecondFactorResetApproved:
+			what, title = "the reset of their second factor", "Second factor reset"
+			body = "All the second factor devices of your account have been removed following the approval of the reset of your second factor, you must register new ones."
+		case event.Details[events.DetailActor] != "":
+			what, title = fmt.Sprintf("the removal of a %s", device), "Device removed from your account"
+			body = fmt.Sprintf("A %s has been removed from your account by an administrator.", device)
+		default:
+			what, title = fmt.Sprintf("the removal of a %s", device), "Device removed from your account"
+			body = fmt.Sprintf("A %s has been removed from your account.", device)
+		}
+
+		body += " If you did not ask for it your credentials might have been compromised. You should reset your password and contact an administrator."
+
+		if event.Details[events.DetailEmail] == "" {
+			event = withUserEmail(users, event)
+		}
+
+		sendSecurityNotification(notifier, disableHTMLEmails, event, what, title, body)
+	}
+}
+
+// withUserEmail returns a copy of the event with the email address of the user found with the users provider, the
+// event is returned unchanged when the user can't be found.
+func withUserEmail(users authentication.UserProvider, event events.Event) events.Event {
+	details, err := users.GetDetails(event.Username)
+	if err != nil || len(details.Emails) == 0 {
+		return event
+	}
+
+	eventDetails := make(map[string]string, len(event.Details)+1)
+
+	for key, value := range event.Details {
+		eventDetails[key] = value
+	}
+
+	eventDetails[events.DetailEmail] = details.Emails[0]
+	event.Details = eventDetails
+
+	return event
+}
+
+// NewSessionImpossibleTravelSubscriber creates a subscriber of the SessionImpossibleTravel events sending a security
+// notification to the user whose session has been used from distant locations and invalidated.
+func NewSessionImpossibleTravelSubscriber(notifier Notifier, disableHTMLEmails bool) events.Subscriber {
+	return func(event events.Event) {
+		title := "Session used from distant locations"
+		body := fmt.Sprintf("Your session has been used from %s shortly after being used from %s, it has been closed and you must log in again.",
+			event.Details[events.DetailLocation], event.Details[events.DetailPreviousLocation])
+
+		body += " If you were not travelling your session might have been stolen. You should reset your password and contact an administrator."
+
+		sendSecurityNotification(notifier, disableHTMLEmails, event, "the use of their session from distant locations", title, body)
+	}
+}
+
+// sendSecurityNotification sends a security notification of the event to the user of the event, what describes the
+// event in the logs.
+func sendSecurityNotification(notifier Notifier, disableHTMLEmails bool, event events.Event, what, title, body string) {
+	logger := logging.Logger()
+
+	email := event.Details[events.DetailEmail]
+
+	if email == "" {
+		logger.Warnf("Unable to notify user %s of %s: user does not have any email address", event.Username, what)
+		return
+	}
+
+	params := map[string]interface{}{
+		"title":    title,
+		"body":     body,
+		"remoteIP": event.RemoteIP,
+		"location": event.Details[events.DetailLocation],
+	}
+
+	if url := event.Details[events.DetailResetPasswordURL]; url != "" {
+		params["url"] = url
+		params["button"] = "Reset password"
+	}
+
+	bufHTML := new(bytes.Buffer)
+
+	if !disableHTMLEmails {
+		if err := templates.HTMLEmailTemplate.Execute(bufHTML, params); err != nil {
+			logger.Errorf("Unable to render the notification of %s to user %s: %s", what, event.Username, err)
+			return
+		}
+	}
+
+	bufText := new(bytes.Buffer)
+
+	if err := templates.PlainTextSecurityEmailTemplate.Execute(bufText, params); err != nil {
+		logger.Errorf("Unable to render the notification of %s to user %s: %s", what, event.Username, err)
+		return
+	}
+
+	logger.Debugf("Sending an email to user %s (%s) to notify %s", event.Username, email, what)
+
+	if err := SendWithCategory(notifier, CategorySecurityAlert, email, title, bufText.String(), bufHTML.String()); err != nil {
+		logger.Errorf("Unable to notify user %s of %s: %s", event.Username, what, err)
+	}
+}
+
+// NewBreakGlassSubscriber creates a subscriber of the BreakGlassUsed and BreakGlassDenied events alerting the operators
+// of every use of the break-glass accounts.
+func NewBreakGlassSubscriber(operator *OperatorNotifier) events.Subscriber {
+	return func(event events.Event) {
+		switch event.Type {
+		case events.BreakGlassUsed:
+			operator.Alert(AlertBreakGlassUsed, "Break-glass account used",
+				fmt.Sprintf("The break-glass account %s logged in from %s, it's disabled at %s",
+					event.Username, event.RemoteIP, event.Details[events.DetailExpiresAt]))
+		case events.BreakGlassDenied:
+			operator.Alert(AlertBreakGlassUsed, "Disabled break-glass account used",
+				fmt.Sprintf("The break-glass account %s attempted to log in from %s but it has been disabled at %s, it must be reset to be used again",
+					event.Username, event.RemoteIP, event.Details[events.DetailExpiresAt]))
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package notification
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/events"
+	"github.com/authelia/authelia/internal/logging"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+func TestShouldNotifyDeviceRegistrationEvents(t *testing.T) {
+	notifier := &failingNotifier{attempts: make(chan string, 1)}
+	subscriber := NewDeviceRegistrationSubscriber(notifier, true)
+
+	subscriber(events.Event{
+		Type:     events.DeviceRegistered,
+		Username: "john",
+		Details: map[string]string{
+			events.DetailDevice: "security key",
+			events.DetailEmail:  "john@example.com",
+		},
+	})
+
+	require.Len(t, notifier.attempts, 1)
+	assert.Equal(t, "john@example.com", <-notifier.attempts)
+}
+
+func TestShouldNotNotifyDeviceRegistrationEventsWithoutEmail(t *testing.T) {
+	hook := test.NewLocal(logging.Logger())
+	defer hook.Reset()
+
+	notifier := &failingNotifier{attempts: make(chan string, 1)}
+	subscriber := NewDeviceRegistrationSubscriber(notifier, false)
+
+	subscriber(events.Event{
+		Type:     events.DeviceRegistered,
+		Username: "john",
+		Details: map[string]string{
+			events.DetailDevice: "one-time password device",
+		},
+	})
+
+	assert.Len(t, notifier.attempts, 0)
+	require.NotNil(t, hook.LastEntry())
+	assert.Equal(t, "Unable to notify user john of the registration of a one-time password device: user does not have any email address", hook.LastEntry().Message)
+}
+
+func TestShouldNotifyDeviceRemovalEvents(t *testing.T) {
+	testCases := []struct {
+		name  string
+		event events.Event
+		email string
+	}{
+		{"ShouldNotifyRemovalByUser", events.Event{
+			Type:     events.DeviceRevoked,
+			Username: "harry",
+			Details: map[string]string{
+				events.DetailDevice: "one-time password device",
+				events.DetailEmail:  "harry@example.com",
+			},
+		}, "harry@example.com"},
+		{"ShouldNotifyRemovalByAdministrator", events.Event{
+			Type:     events.DeviceRevoked,
+			Username: "john",
+			Details: map[string]string{
+				events.DetailDevice: "security key",
+				events.DetailActor:  "alice",
+			},
+		}, "john@example.com"},
+		{"ShouldNotifyApprovedReset", events.Event{
+			Type:     events.SecondFactorResetApproved,
+			Username: "john",
+			Details: map[string]string{
+				events.DetailResetID: "reset",
+				events.DetailActor:   "alice",
+			},
+		}, "john@example.com"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			notifier := &failingNotifier{attempts: make(chan string, 1)}
+			subscriber := NewDeviceRemovalSubscriber(notifier, fakeUserProvider{}, false)
+
+			_, hadEmail := tc.event.Details[events.DetailEmail]
+
+			subscriber(tc.event)
+
+			require.Len(t, notifier.attempts, 1)
+			assert.Equal(t, tc.email, <-notifier.attempts)
+
+			// The email address looked up isn't added to the details shared by the other subscribers.
+			_, hasEmail := tc.event.Details[events.DetailEmail]
+			assert.Equal(t, hadEmail, hasEmail)
+		})
+	}
+}
+
+func TestShouldNotNotifyDeviceRemovalEventsOfUnknownUser(t *testing.T) {
+	notifier := &failingNotifier{attempts: make(chan string, 1)}
+	subscriber := NewDeviceRemovalSubscriber(notifier, fakeUserProvider{}, false)
+
+	details := map[string]string{events.DetailDevice: "security key", events.DetailActor: "alice"}
+
+	subscriber(events.Event{Type: events.DeviceRevoked, Username: "harry", Details: details})
+
+	assert.Len(t, notifier.attempts, 0)
+	assert.NotContains(t, details, events.DetailEmail)
+}
+
+func TestShouldNotifySessionImpossibleTravelEvents(t *testing.T) {
+	notifier := &failingNotifier{attempts: make(chan string, 1)}
+	subscriber := NewSessionImpossibleTravelSubscriber(notifier, false)
+
+	subscriber(events.Event{
+		Type:     events.SessionImpossibleTravel,
+		Username: "john",
+		Details: map[string]string{
+			events.DetailLocation:         "Tokyo, Japan",
+			events.DetailPreviousLocation: "France",
+			events.DetailEmail:            "john@example.com",
+		},
+	})
+
+	require.Len(t, notifier.attempts, 1)
+	assert.Equal(t, "john@example.com", <-notifier.attempts)
+}
+
+func TestShouldAlertOperatorsOfBreakGlassEvents(t *testing.T) {
+	hook := test.NewLocal(logging.Logger())
+	defer hook.Reset()
+
+	notifier := &failingNotifier{attempts: make(chan string, 2)}
+	operator := NewOperatorNotifier(schema.NotifierOperatorConfiguration{Email: "ops@example.com"}, notifier,
+		utils.NewFakeClock(time.Unix(1600000000, 0)), true)
+	subscriber := NewBreakGlassSubscriber(operator)
+
+	subscriber(events.Event{
+		Type:     events.BreakGlassUsed,
+		Username: "emergency",
+		RemoteIP: "10.0.0.1",
+		Details:  map[string]string{events.DetailExpiresAt: "2020-09-13T16:26:40Z"},
+	})
+
+	require.NotNil(t, hook.LastEntry())
+	assert.Equal(t, "Alerting the operators: The break-glass account emergency logged in from 10.0.0.1, it's disabled at 2020-09-13T16:26:40Z", hook.LastEntry().Message)
+	assert.Equal(t, "ops@example.com", receiveAttempt(t, notifier.attempts))
+
+	subscriber(events.Event{
+		Type:     events.BreakGlassDenied,
+		Username: "emergency",
+		RemoteIP: "10.0.0.1",
+		Details:  map[string]string{events.DetailExpiresAt: "2020-09-13T16:26:40Z"},
+	})
+
+	assert.Equal(t, "Alerting the operators: The break-glass account emergency attempted to log in from 10.0.0.1 but it has been disabled at 2020-09-13T16:26:40Z, it must be reset to be used again", hook.LastEntry().Message)
+	assert.Equal(t, "ops@example.com", receiveAttempt(t, notifier.attempts))
+}
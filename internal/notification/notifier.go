@@ -5,3 +5,35 @@ type Notifier interface {
 	Send(recipient, subject, body, htmlBody string) error
 	StartupCheck() (bool, error)
 }
+
+// Category is the category of a notification, the notifiers implementing CategoryNotifier can customize the
+// notifications of each category.
+type Category string
+
+const (
+	// CategoryVerification is the category of the notifications verifying the identity of the users.
+	CategoryVerification Category = "verification"
+
+	// CategorySecurityAlert is the category of the notifications alerting the users of a security event on their
+	// account.
+	CategorySecurityAlert Category = "security_alert"
+
+	// CategoryOperatorAlert is the category of the notifications alerting the operators of an operational event of the
+	// server.
+	CategoryOperatorAlert Category = "operator_alert"
+)
+
+// CategoryNotifier is a Notifier customizing the notifications of each category.
+type CategoryNotifier interface {
+	SendCategory(category Category, recipient, subject, body, htmlBody string) error
+}
+
+// SendWithCategory sends the notification with its category when the notifier supports the categories, as a regular
+// notification otherwise.
+func SendWithCategory(notifier Notifier, category Category, recipient, subject, body, htmlBody string) error {
+	if categoryNotifier, ok := notifier.(CategoryNotifier); ok {
+		return categoryNotifier.SendCategory(category, recipient, subject, body, htmlBody)
+	}
+
+	return notifier.Send(recipient, subject, body, htmlBody)
+}
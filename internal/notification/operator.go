@@ -0,0 +1,416 @@
+package notification
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/logging"
+	"github.com/authelia/authelia/internal/templates"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// AlertKind is the kind of an alert sent to the operators.
+type AlertKind string
+
+const (
+	// AlertCertificateExpiring is sent when a certificate used by Authelia is nearing its expiry.
+	AlertCertificateExpiring AlertKind = "certificate_expiring"
+
+	// AlertStorageMigrated is sent when the schema of the storage has been upgraded.
+	AlertStorageMigrated AlertKind = "storage_migrated"
+
+	// AlertLDAPUnreachable is sent when the LDAP server becomes unreachable.
+	AlertLDAPUnreachable AlertKind = "ldap_unreachable"
+
+	// AlertKeyRotated is sent when the active OpenID Connect issuer key has been changed.
+	AlertKeyRotated AlertKind = "key_rotated"
+
+	// AlertBreakGlassUsed is sent when a break-glass account logs in or attempts to log in after its window elapsed.
+	AlertBreakGlassUsed AlertKind = "break_glass_used"
+)
+
+// operatorWebhookTimeout bounds the time the webhook is given to respond to an alert.
+const operatorWebhookTimeout = 10 * time.Second
+
+// Alert is an operational event of the server the operators are alerted of, unlike the notifications which are sent
+// to the users.
+type Alert struct {
+	Kind    AlertKind `json:"kind"`
+	Title   string    `json:"title"`
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+}
+
+// OperatorNotifier sends the alerts to the operators by email through the notifier and/or to a webhook receiving the
+// alerts as JSON. Alerting through a nil OperatorNotifier does nothing so the callers don't need to check whether the
+// operator alerts are configured.
+type OperatorNotifier struct {
+	email             string
+	webhookURL        string
+	notifier          Notifier
+	client            *http.Client
+	clock             utils.Clock
+	disableHTMLEmails bool
+}
+
+// NewOperatorNotifier creates an OperatorNotifier from the operator configuration of the notifier.
+func NewOperatorNotifier(configuration schema.NotifierOperatorConfiguration, notifier Notifier, clock utils.Clock, disableHTMLEmails bool) *OperatorNotifier {
+	return &OperatorNotifier{
+		email:             configuration.Email,
+		webhookURL:        configuration.WebhookURL,
+		notifier:          notifier,
+		client:            &http.Client{Timeout: operatorWebhookTimeout},
+		clock:             clock,
+		disableHTMLEmails: disableHTMLEmails,
+	}
+}
+
+// Alert sends the alert to the operators in the background, the failures are logged.
+func (n *OperatorNotifier) Alert(kind AlertKind, title, message string) {
+	if n == nil {
+		return
+	}
+
+	alert := Alert{Kind: kind, Title: title, Message: message, Time: n.clock.Now()}
+
+	logging.Logger().Warnf("Alerting the operators: %s", message)
+
+	go func() {
+		if err := n.Send(alert); err != nil {
+			logging.Logger().Errorf("Unable to send the %s alert to the operators: %s", alert.Kind, err)
+		}
+	}()
+}
+
+// Send sends the alert to the email and the webhook of the operators.
+func (n *OperatorNotifier) Send(alert Alert) (err error) {
+	var errs []string
+
+	if n.email != "" {
+		if err = n.sendEmail(alert); err != nil {
+			errs = append(errs, fmt.Sprintf("email: %s", err))
+		}
+	}
+
+	if n.webhookURL != "" {
+		if err = n.sendWebhook(alert); err != nil {
+			errs = append(errs, fmt.Sprintf("webhook: %s", err))
+		}
+	}
+
+	if len(errs) != 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+
+	return nil
+}
+
+func (n *OperatorNotifier) sendEmail(alert Alert) (err error) {
+	params := map[string]interface{}{
+		"title": alert.Title,
+		"body":  alert.Message,
+	}
+
+	bufHTML := new(bytes.Buffer)
+
+	if !n.disableHTMLEmails {
+		if err = templates.HTMLEmailTemplate.Execute(bufHTML, params); err != nil {
+			return err
+		}
+	}
+
+	bufText := new(bytes.Buffer)
+
+	if err = templates.PlainTextSecurityEmailTemplate.Execute(bufText, params); err != nil {
+		return err
+	}
+
+	return SendWithCategory(n.notifier, CategoryOperatorAlert, n.email, alert.Title, bufText.String(), bufHTML.String())
+}
+
+func (n *OperatorNotifier) sendWebhook(alert Alert) (err error) {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, n.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	// Drain the body so the connection can be reused.
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("webhook responded with status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// certificatesExpiry holds the days to the expiry of the certificates checked by the OperatorMonitor by location and
+// subject, it's exposed by the expvars endpoint.
+var certificatesExpiry = expvar.NewMap("authelia_certificates_days_to_expiry")
+
+// OperatorMonitor periodically checks the certificates and the dependencies of Authelia and alerts the operators when
+// a certificate nears its expiry or a dependency becomes unreachable. The days to the expiry of the certificates are
+// exposed as metrics even when the operator alerts aren't configured.
+type OperatorMonitor struct {
+	operator *OperatorNotifier
+	clock    utils.Clock
+
+	interval time.Duration
+	expiry   time.Duration
+
+	mutex        sync.Mutex
+	certificates map[string]string
+	directories  map[string]string
+	pems         []*monitoredPEM
+	dependencies []*monitoredDependency
+
+	// alerted holds the certificates the operators have already been alerted of, by location and serial number.
+	alerted map[string]bool
+
+	// metrics holds the keys of the certificates expiry metrics set by the last check.
+	metrics map[string]bool
+}
+
+type monitoredPEM struct {
+	name         string
+	location     string
+	certificates []*x509.Certificate
+}
+
+type monitoredDependency struct {
+	kind  AlertKind
+	name  string
+	check func() error
+
+	unreachable bool
+}
+
+// NewOperatorMonitor creates an OperatorMonitor from the operator configuration of the notifier, the operator is nil
+// when the operator alerts aren't configured.
+func NewOperatorMonitor(configuration schema.NotifierOperatorConfiguration, operator *OperatorNotifier, clock utils.Clock) *OperatorMonitor {
+	interval, _ := utils.ParseDurationString(configuration.CheckInterval)
+	expiry, _ := utils.ParseDurationString(configuration.CertificateExpiry)
+
+	return &OperatorMonitor{
+		operator:     operator,
+		clock:        clock,
+		interval:     interval,
+		expiry:       expiry,
+		certificates: map[string]string{},
+		directories:  map[string]string{},
+		alerted:      map[string]bool{},
+		metrics:      map[string]bool{},
+	}
+}
+
+// WatchCertificate checks the expiry of the certificates of the PEM file at each check.
+func (m *OperatorMonitor) WatchCertificate(name, path string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.certificates[path] = name
+}
+
+// WatchCertificatesDirectory checks the expiry of the certificates of the .cer, .crt and .pem files of the directory at
+// each check, the files added to the directory are checked too.
+func (m *OperatorMonitor) WatchCertificatesDirectory(name, directory string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.directories[directory] = name
+}
+
+// WatchCertificatesPEM checks the expiry of the certificates found in the PEM data at each check, the location
+// identifies the data in the alerts and the metrics. Only the certificates are kept, not the rest of the data such as
+// a private key.
+func (m *OperatorMonitor) WatchCertificatesPEM(name, location string, data []byte) {
+	certificates := parseCertificates(data)
+	if len(certificates) == 0 {
+		return
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.pems = append(m.pems, &monitoredPEM{name: name, location: location, certificates: certificates})
+}
+
+// WatchDependency runs the check of the dependency at each check, the operators are alerted with an alert of the
+// given kind when the check starts failing.
+func (m *OperatorMonitor) WatchDependency(kind AlertKind, name string, check func() error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.dependencies = append(m.dependencies, &monitoredDependency{kind: kind, name: name, check: check})
+}
+
+// Start runs the checks at every check interval in the background.
+func (m *OperatorMonitor) Start() {
+	go func() {
+		for {
+			m.Check()
+
+			<-m.clock.After(m.interval)
+		}
+	}()
+}
+
+// Check runs the checks of the certificates and of the dependencies once.
+func (m *OperatorMonitor) Check() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	metrics := map[string]bool{}
+
+	for path, name := range m.certificates {
+		m.checkCertificateFile(name, path, metrics)
+	}
+
+	for directory, name := range m.directories {
+		paths, err := listCertificateFiles(directory)
+		if err != nil {
+			logging.Logger().Errorf("Unable to check the expiry of the %s certificates: %s", name, err)
+			continue
+		}
+
+		for _, path := range paths {
+			m.checkCertificateFile(name, path, metrics)
+		}
+	}
+
+	for _, source := range m.pems {
+		m.checkCertificates(source.name, source.location, source.certificates, metrics)
+	}
+
+	// Remove the metrics of the certificates which have been renewed or removed.
+	for key := range m.metrics {
+		if !metrics[key] {
+			certificatesExpiry.Delete(key)
+		}
+	}
+
+	m.metrics = metrics
+
+	for _, dependency := range m.dependencies {
+		err := dependency.check()
+
+		switch {
+		case err != nil && !dependency.unreachable:
+			m.operator.Alert(dependency.kind, fmt.Sprintf("%s unreachable", dependency.name),
+				fmt.Sprintf("The %s is unreachable: %s", dependency.name, err))
+		case err == nil && dependency.unreachable:
+			logging.Logger().Infof("The %s is reachable again", dependency.name)
+		}
+
+		dependency.unreachable = err != nil
+	}
+}
+
+// checkCertificateFile must be called with the mutex locked.
+func (m *OperatorMonitor) checkCertificateFile(name, path string, metrics map[string]bool) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		logging.Logger().Errorf("Unable to check the expiry of the %s certificate: %s", name, err)
+		return
+	}
+
+	certificates := parseCertificates(data)
+	if len(certificates) == 0 {
+		logging.Logger().Errorf("Unable to check the expiry of the %s certificate: no certificate found in %s", name, path)
+		return
+	}
+
+	m.checkCertificates(name, path, certificates, metrics)
+}
+
+// checkCertificates must be called with the mutex locked.
+func (m *OperatorMonitor) checkCertificates(name, location string, certificates []*x509.Certificate, metrics map[string]bool) {
+	now := m.clock.Now()
+
+	for _, certificate := range certificates {
+		remaining := certificate.NotAfter.Sub(now)
+
+		metric := location + ":" + certificate.Subject.String()
+		metrics[metric] = true
+
+		days := new(expvar.Int)
+		days.Set(int64(math.Floor(remaining.Hours() / 24)))
+		certificatesExpiry.Set(metric, days)
+
+		key := location + ":" + certificate.SerialNumber.String()
+
+		if m.alerted[key] || remaining > m.expiry {
+			continue
+		}
+
+		m.alerted[key] = true
+
+		m.operator.Alert(AlertCertificateExpiring, fmt.Sprintf("%s certificate expiring", name),
+			fmt.Sprintf("The %s certificate %s expires on %s.", name, location, certificate.NotAfter.UTC().Format(time.RFC1123)))
+	}
+}
+
+// listCertificateFiles lists the files of the directory which can hold certificates.
+func listCertificateFiles(directory string) (paths []string, err error) {
+	files, err := ioutil.ReadDir(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		name := strings.ToLower(file.Name())
+
+		if !file.IsDir() && (strings.HasSuffix(name, ".cer") || strings.HasSuffix(name, ".crt") || strings.HasSuffix(name, ".pem")) {
+			paths = append(paths, filepath.Join(directory, file.Name()))
+		}
+	}
+
+	return paths, nil
+}
+
+// parseCertificates parses the certificates of PEM data, the other blocks such as the private keys are skipped.
+func parseCertificates(data []byte) (certificates []*x509.Certificate) {
+	for {
+		var block *pem.Block
+
+		if block, data = pem.Decode(data); block == nil {
+			return certificates
+		}
+
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		if certificate, err := x509.ParseCertificate(block.Bytes); err == nil {
+			certificates = append(certificates, certificate)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package notification
+
+import (
+	"errors"
+	"expvar"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/logging"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// ErrQueueFull is returned when a notification can't be queued because the queue is full.
+var ErrQueueFull = errors.New("the notifications queue is full")
+
+// queuedNotifications counts the notifications which have been sent, retried or dropped by the queue, it's exposed by
+// the expvars endpoint.
+var queuedNotifications = expvar.NewMap("authelia_notifications")
+
+type notification struct {
+	category  Category
+	recipient string
+	subject   string
+	body      string
+	htmlBody  string
+}
+
+// QueuedNotifier is a notifier sending the notifications of another notifier in the background through a bounded pool
+// of workers. An attempt which fails is retried with an exponential backoff, the notifications which still can't be
+// sent are logged as dropped. An attempt which times out is awaited rather than retried.
+type QueuedNotifier struct {
+	notifier Notifier
+	clock    utils.Clock
+	log      *logrus.Logger
+
+	queue chan notification
+
+	timeout    time.Duration
+	maxRetries int
+	backoff    time.Duration
+}
+
+// NewQueuedNotifier creates a QueuedNotifier from the queue configuration and starts its workers. The notifier is
+// returned as is when the queue has no worker, in which case the notifications are sent synchronously.
+func NewQueuedNotifier(configuration schema.NotifierQueueConfiguration, notifier Notifier, clock utils.Clock) Notifier {
+	if configuration.Workers <= 0 {
+		return notifier
+	}
+
+	queued := &QueuedNotifier{
+		notifier:   notifier,
+		clock:      clock,
+		log:        logging.Logger(),
+		queue:      make(chan notification, configuration.Size),
+		timeout:    mustParseDuration(configuration.Timeout),
+		maxRetries: configuration.MaxRetries,
+		backoff:    mustParseDuration(configuration.Backoff),
+	}
+
+	for i := 0; i < configuration.Workers; i++ {
+		go queued.work()
+	}
+
+	return queued
+}
+
+func mustParseDuration(input string) time.Duration {
+	duration, err := utils.ParseDurationString(input)
+	if err != nil {
+		panic(err)
+	}
+
+	return duration
+}
+
+// StartupCheck checks the underlying notifier.
+func (n *QueuedNotifier) StartupCheck() (bool, error) {
+	return n.notifier.StartupCheck()
+}
+
+// Send queues the notification, it only fails when the queue is full.
+func (n *QueuedNotifier) Send(recipient, subject, body, htmlBody string) error {
+	return n.SendCategory("", recipient, subject, body, htmlBody)
+}
+
+// SendCategory queues the notification with its category, it only fails when the queue is full.
+func (n *QueuedNotifier) SendCategory(category Category, recipient, subject, body, htmlBody string) error {
+	select {
+	case n.queue <- notification{category: category, recipient: recipient, subject: subject, body: body, htmlBody: htmlBody}:
+		return nil
+	default:
+		queuedNotifications.Add("dropped", 1)
+		return ErrQueueFull
+	}
+}
+
+func (n *QueuedNotifier) work() {
+	for message := range n.queue {
+		n.deliver(message)
+	}
+}
+
+func (n *QueuedNotifier) deliver(message notification) {
+	backoff := n.backoff
+
+	for attempt := 0; ; attempt++ {
+		err := n.attempt(message)
+		if err == nil {
+			queuedNotifications.Add("sent", 1)
+			return
+		}
+
+		if attempt >= n.maxRetries {
+			queuedNotifications.Add("dropped", 1)
+			n.log.Errorf("Dropped the notification to %s with subject '%s' after %d attempts: %s", message.recipient, message.subject, attempt+1, err)
+
+			return
+		}
+
+		queuedNotifications.Add("retried", 1)
+		n.log.Warnf("Unable to send the notification to %s with subject '%s', retrying in %s: %s", message.recipient, message.subject, backoff, err)
+
+		<-n.clock.After(backoff)
+
+		backoff *= 2
+	}
+}
+
+// attempt sends the notification and returns its result. The notifiers don't support being cancelled, so an attempt
+// which times out is logged and still awaited: retrying it while it's in flight would send the notification twice.
+// The attempts of the SMTP notifier are bounded by the deadline of its connections.
+func (n *QueuedNotifier) attempt(message notification) error {
+	result := make(chan error, 1)
+
+	go func() {
+		result <- SendWithCategory(n.notifier, message.category, message.recipient, message.subject, message.body, message.htmlBody)
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-n.clock.After(n.timeout):
+		queuedNotifications.Add("timed_out", 1)
+		n.log.Warnf("Sending the notification to %s with subject '%s' takes longer than %s, waiting for the attempt to complete before retrying",
+			message.recipient, message.subject, n.timeout)
+	}
+
+	return <-result
+}
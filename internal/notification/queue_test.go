@@ -0,0 +1,147 @@
+package notification
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// failingNotifier fails the first sends and reports every attempt, the attempts started are counted in calls.
+type failingNotifier struct {
+	failures int
+	block    chan struct{}
+	attempts chan string
+	calls    int32
+}
+
+func (n *failingNotifier) StartupCheck() (bool, error) {
+	return true, nil
+}
+
+func (n *failingNotifier) Send(recipient, _, _, _ string) error {
+	atomic.AddInt32(&n.calls, 1)
+
+	if n.block != nil {
+		<-n.block
+	}
+
+	n.attempts <- recipient
+
+	if n.failures > 0 {
+		n.failures--
+		return errors.New("failed")
+	}
+
+	return nil
+}
+
+func receiveAttempt(t *testing.T, attempts chan string) string {
+	select {
+	case recipient := <-attempts:
+		return recipient
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no attempt to send the notification")
+		return ""
+	}
+}
+
+func TestShouldSendNotificationsSynchronouslyWithoutWorkers(t *testing.T) {
+	notifier := &failingNotifier{attempts: make(chan string, 1)}
+
+	queued := NewQueuedNotifier(schema.NotifierQueueConfiguration{}, notifier, utils.RealClock{})
+	assert.Equal(t, notifier, queued)
+}
+
+func TestShouldRetryNotificationsWithBackoff(t *testing.T) {
+	notifier := &failingNotifier{failures: 2, attempts: make(chan string, 3)}
+
+	queued := NewQueuedNotifier(schema.NotifierQueueConfiguration{
+		Workers: 1, Size: 1, Timeout: "1h", MaxRetries: 2, Backoff: "0",
+	}, notifier, utils.RealClock{})
+
+	require.NoError(t, queued.Send("john@example.com", "Subject", "Body", ""))
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, "john@example.com", receiveAttempt(t, notifier.attempts))
+	}
+}
+
+// categoryNotifier reports the category of every notification.
+type categoryNotifier struct {
+	failingNotifier
+	categories chan Category
+}
+
+func (n *categoryNotifier) SendCategory(category Category, recipient, subject, body, htmlBody string) error {
+	n.categories <- category
+
+	return n.Send(recipient, subject, body, htmlBody)
+}
+
+func TestShouldQueueNotificationsWithTheirCategory(t *testing.T) {
+	notifier := &categoryNotifier{
+		failingNotifier: failingNotifier{attempts: make(chan string, 1)},
+		categories:      make(chan Category, 1),
+	}
+
+	queued := NewQueuedNotifier(schema.NotifierQueueConfiguration{
+		Workers: 1, Size: 1, Timeout: "1h", Backoff: "0",
+	}, notifier, utils.RealClock{})
+
+	require.NoError(t, SendWithCategory(queued, CategorySecurityAlert, "john@example.com", "Subject", "Body", ""))
+
+	assert.Equal(t, "john@example.com", receiveAttempt(t, notifier.attempts))
+	assert.Equal(t, CategorySecurityAlert, <-notifier.categories)
+}
+
+func TestShouldDropNotificationsWhenQueueIsFull(t *testing.T) {
+	notifier := &failingNotifier{block: make(chan struct{}), attempts: make(chan string, 2)}
+	defer close(notifier.block)
+
+	queued := NewQueuedNotifier(schema.NotifierQueueConfiguration{
+		Workers: 1, Size: 1, Timeout: "1h", Backoff: "0",
+	}, notifier, utils.RealClock{}).(*QueuedNotifier)
+
+	// The first notification is picked by the worker which is blocked, the second one fills the queue.
+	require.NoError(t, queued.Send("john@example.com", "Subject", "Body", ""))
+
+	assert.Eventually(t, func() bool { return len(queued.queue) == 0 }, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, queued.Send("harry@example.com", "Subject", "Body", ""))
+	assert.Equal(t, ErrQueueFull, queued.Send("bob@example.com", "Subject", "Body", ""))
+}
+
+func TestShouldAwaitTimedOutNotificationsInsteadOfRetrying(t *testing.T) {
+	notifier := &failingNotifier{block: make(chan struct{}), attempts: make(chan string, 2)}
+
+	clock := utils.NewFakeClock(time.Unix(0, 0))
+
+	queued := NewQueuedNotifier(schema.NotifierQueueConfiguration{
+		Workers: 1, Size: 1, Timeout: "1m", MaxRetries: 3, Backoff: "0",
+	}, notifier, clock).(*QueuedNotifier)
+
+	require.NoError(t, queued.Send("john@example.com", "Subject", "Body", ""))
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+
+	// The attempt which timed out is still in flight, it's not retried.
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&notifier.calls))
+
+	// The attempt eventually succeeds, the notification is sent once.
+	close(notifier.block)
+
+	assert.Equal(t, "john@example.com", receiveAttempt(t, notifier.attempts))
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&notifier.calls))
+	assert.Len(t, notifier.attempts, 0)
+}
@@ -0,0 +1,75 @@
+package notification
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// NotifierFactory creates a Notifier from the notifier configuration and the options of the notifier decoded from the
+// notifier.options configuration block.
+type NotifierFactory func(configuration schema.NotifierConfiguration, options map[string]interface{}, certPool *x509.CertPool) (Notifier, error)
+
+var (
+	notifierFactoriesMu sync.RWMutex
+	notifierFactories   = map[string]NotifierFactory{}
+)
+
+// RegisterNotifier makes a Notifier available under the provided name so it can be selected with the
+// notifier.provider configuration key. It panics if the name is already registered or the factory is nil.
+func RegisterNotifier(name string, factory NotifierFactory) {
+	notifierFactoriesMu.Lock()
+	defer notifierFactoriesMu.Unlock()
+
+	if factory == nil {
+		panic("notification: RegisterNotifier factory is nil")
+	}
+
+	if _, dup := notifierFactories[name]; dup {
+		panic("notification: RegisterNotifier called twice for provider " + name)
+	}
+
+	notifierFactories[name] = factory
+}
+
+// Notifiers returns a sorted list of the names of the registered notifiers.
+func Notifiers() (names []string) {
+	notifierFactoriesMu.RLock()
+	defer notifierFactoriesMu.RUnlock()
+
+	for name := range notifierFactories {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// NewNotifier creates the Notifier described by the configuration, either one of the built-in notifiers or a
+// notifier registered with RegisterNotifier.
+func NewNotifier(configuration schema.NotifierConfiguration, certPool *utils.X509CertPool) (Notifier, error) {
+	switch {
+	case configuration.Provider != "":
+		notifierFactoriesMu.RLock()
+		factory, ok := notifierFactories[configuration.Provider]
+		notifierFactoriesMu.RUnlock()
+
+		if !ok {
+			return nil, fmt.Errorf("unknown notifier provider '%s'", configuration.Provider)
+		}
+
+		return factory(configuration, configuration.Options, certPool.CertPool())
+	case configuration.SMTP != nil:
+		return NewSMTPNotifier(*configuration.SMTP, certPool), nil
+	case configuration.FileSystem != nil:
+		return NewFileNotifier(*configuration.FileSystem), nil
+	default:
+		return nil, errors.New("unrecognized notifier")
+	}
+}
@@ -0,0 +1,46 @@
+package notification
+
+import (
+	"crypto/x509"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+func TestShouldCreateRegisteredNotifier(t *testing.T) {
+	expected := NewFileNotifier(schema.FileSystemNotifierConfiguration{Filename: "/tmp/notification.txt"})
+
+	var options map[string]interface{}
+
+	RegisterNotifier("test_registered", func(_ schema.NotifierConfiguration, o map[string]interface{}, _ *x509.CertPool) (Notifier, error) {
+		options = o
+		return expected, nil
+	})
+
+	assert.Contains(t, Notifiers(), "test_registered")
+
+	notifier, err := NewNotifier(schema.NotifierConfiguration{
+		Provider: "test_registered",
+		Options:  map[string]interface{}{"webhook": "https://chat.example.com/hook"},
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, expected, notifier)
+	assert.Equal(t, map[string]interface{}{"webhook": "https://chat.example.com/hook"}, options)
+
+	assert.Panics(t, func() {
+		RegisterNotifier("test_registered", func(_ schema.NotifierConfiguration, _ map[string]interface{}, _ *x509.CertPool) (Notifier, error) {
+			return nil, nil
+		})
+	})
+}
+
+func TestShouldErrorOnUnknownNotifier(t *testing.T) {
+	_, err := NewNotifier(schema.NotifierConfiguration{Provider: "test_unknown"}, nil)
+	assert.EqualError(t, err, "unknown notifier provider 'test_unknown'")
+
+	_, err = NewNotifier(schema.NotifierConfiguration{}, nil)
+	assert.EqualError(t, err, "unrecognized notifier")
+}
@@ -0,0 +1,47 @@
+package notification
+
+import (
+	"sync"
+)
+
+// ReloadableNotifier is a notifier sending the notifications through another notifier which can be swapped when the
+// configuration is reloaded. The notifications being sent when the notifier is swapped are sent by the previous one.
+type ReloadableNotifier struct {
+	mutex    sync.RWMutex
+	notifier Notifier
+}
+
+// NewReloadableNotifier creates a ReloadableNotifier sending the notifications through the given notifier.
+func NewReloadableNotifier(notifier Notifier) *ReloadableNotifier {
+	return &ReloadableNotifier{notifier: notifier}
+}
+
+// Swap replaces the notifier sending the notifications.
+func (n *ReloadableNotifier) Swap(notifier Notifier) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	n.notifier = notifier
+}
+
+func (n *ReloadableNotifier) current() Notifier {
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+
+	return n.notifier
+}
+
+// Send sends the notification through the current notifier.
+func (n *ReloadableNotifier) Send(recipient, subject, body, htmlBody string) error {
+	return n.current().Send(recipient, subject, body, htmlBody)
+}
+
+// SendCategory sends the notification with its category through the current notifier.
+func (n *ReloadableNotifier) SendCategory(category Category, recipient, subject, body, htmlBody string) error {
+	return SendWithCategory(n.current(), category, recipient, subject, body, htmlBody)
+}
+
+// StartupCheck checks the current notifier.
+func (n *ReloadableNotifier) StartupCheck() (bool, error) {
+	return n.current().StartupCheck()
+}
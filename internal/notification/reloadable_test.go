@@ -0,0 +1,23 @@
+package notification
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldSendThroughSwappedNotifier(t *testing.T) {
+	previous := &failingNotifier{attempts: make(chan string, 1)}
+	next := &failingNotifier{failures: 1, attempts: make(chan string, 1)}
+
+	notifier := NewReloadableNotifier(previous)
+
+	assert.NoError(t, notifier.Send("john@example.com", "subject", "body", ""))
+	assert.Equal(t, "john@example.com", <-previous.attempts)
+
+	notifier.Swap(next)
+
+	assert.EqualError(t, SendWithCategory(notifier, CategorySecurityAlert, "harry@example.com", "subject", "body", ""), "failed")
+	assert.Equal(t, "harry@example.com", <-next.attempts)
+	assert.Len(t, previous.attempts, 0)
+}
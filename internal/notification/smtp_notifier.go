@@ -2,9 +2,10 @@ package notification
 
 import (
 	"crypto/tls"
-	"crypto/x509"
 	"errors"
 	"fmt"
+	"net"
+	"net/mail"
 	"net/smtp"
 	"strings"
 	"time"
@@ -26,12 +27,25 @@ type SMTPNotifier struct {
 	address             string
 	subject             string
 	startupCheckAddress string
-	client              *smtp.Client
+	timeout             time.Duration
 	tlsConfig           *tls.Config
+	categories          map[Category]smtpCategory
+}
+
+// smtpCategory is the From header, the Reply-To header and the subject template of the emails of a category.
+type smtpCategory struct {
+	from    string
+	replyTo string
+	subject string
 }
 
 // NewSMTPNotifier creates a SMTPNotifier using the notifier configuration.
-func NewSMTPNotifier(configuration schema.SMTPNotifierConfiguration, certPool *x509.CertPool) *SMTPNotifier {
+func NewSMTPNotifier(configuration schema.SMTPNotifierConfiguration, certPool *utils.X509CertPool) *SMTPNotifier {
+	timeout, err := utils.ParseDurationString(configuration.Timeout)
+	if err != nil || timeout <= 0 {
+		timeout, _ = utils.ParseDurationString(schema.DefaultSMTPNotifierConfiguration.Timeout)
+	}
+
 	notifier := &SMTPNotifier{
 		username:            configuration.Username,
 		password:            configuration.Password,
@@ -43,26 +57,59 @@ func NewSMTPNotifier(configuration schema.SMTPNotifierConfiguration, certPool *x
 		address:             fmt.Sprintf("%s:%d", configuration.Host, configuration.Port),
 		subject:             configuration.Subject,
 		startupCheckAddress: configuration.StartupCheckAddress,
+		timeout:             timeout,
 		tlsConfig:           utils.NewTLSConfig(configuration.TLS, tls.VersionTLS12, certPool),
+		categories:          map[Category]smtpCategory{},
 	}
 
+	notifier.addCategory(CategoryVerification, configuration.Categories.Verification)
+	notifier.addCategory(CategorySecurityAlert, configuration.Categories.SecurityAlert)
+
 	return notifier
 }
 
+func (n *SMTPNotifier) addCategory(category Category, configuration *schema.SMTPNotifierCategoryConfiguration) {
+	if configuration == nil {
+		return
+	}
+
+	c := smtpCategory{from: n.sender, replyTo: configuration.ReplyTo, subject: n.subject}
+
+	if configuration.SenderName != "" {
+		c.from = (&mail.Address{Name: configuration.SenderName, Address: n.sender}).String()
+	}
+
+	if configuration.Subject != "" {
+		c.subject = configuration.Subject
+	}
+
+	n.categories[category] = c
+}
+
+// category returns the customization of the emails of the category, the emails without category or whose category
+// isn't customized are sent with the sender and the subject of the notifier.
+func (n *SMTPNotifier) category(category Category) smtpCategory {
+	if c, ok := n.categories[category]; ok {
+		return c
+	}
+
+	return smtpCategory{from: n.sender, subject: n.subject}
+}
+
 // Do startTLS if available (some servers only provide the auth extension after, and encryption is preferred).
-func (n *SMTPNotifier) startTLS() error {
+func (n *SMTPNotifier) startTLS(client *smtp.Client) error {
 	logger := logging.Logger()
 	// Only start if not already encrypted
-	if _, ok := n.client.TLSConnectionState(); ok {
+	if _, ok := client.TLSConnectionState(); ok {
 		logger.Debugf("Notifier SMTP connection is already encrypted, skipping STARTTLS")
 		return nil
 	}
 
-	switch ok, _ := n.client.Extension("STARTTLS"); ok {
+	switch ok, _ := client.Extension("STARTTLS"); ok {
 	case true:
 		logger.Debugf("Notifier SMTP server supports STARTTLS (disableVerifyCert: %t, ServerName: %s), attempting", n.tlsConfig.InsecureSkipVerify, n.tlsConfig.ServerName)
 
-		if err := n.client.StartTLS(n.tlsConfig); err != nil {
+		if err := client.StartTLS(n.tlsConfig); err != nil {
 			return err
 		}
 
@@ -80,17 +127,17 @@ func (n *SMTPNotifier) startTLS() error {
 }
 
 // Attempt Authentication.
-func (n *SMTPNotifier) auth() error {
+func (n *SMTPNotifier) auth(client *smtp.Client) error {
 	logger := logging.Logger()
 	// Attempt AUTH if password is specified only.
 	if n.password != "" {
-		_, ok := n.client.TLSConnectionState()
+		_, ok := client.TLSConnectionState()
 		if !ok {
 			return errors.New("Notifier SMTP client does not support authentication over plain text and the connection is currently plain text")
 		}
 
 		// Check the server supports AUTH, and get the mechanisms.
-		ok, m := n.client.Extension("AUTH")
+		ok, m := client.Extension("AUTH")
 		if ok {
 			var auth smtp.Auth
 
@@ -114,7 +161,7 @@ func (n *SMTPNotifier) auth() error {
 			}
 
 			// Authenticate.
-			if err := n.client.Auth(auth); err != nil {
+			if err := client.Auth(auth); err != nil {
 				return err
 			}
 
@@ -131,18 +178,18 @@ func (n *SMTPNotifier) auth() error {
 	return nil
 }
 
-func (n *SMTPNotifier) compose(recipient, subject, body, htmlBody string) error {
+func (n *SMTPNotifier) compose(client *smtp.Client, c smtpCategory, recipient, subject, body, htmlBody string) error {
 	logger := logging.Logger()
 	logger.Debugf("Notifier SMTP client attempting to send email body to %s", recipient)
 
 	if !n.disableRequireTLS {
-		_, ok := n.client.TLSConnectionState()
+		_, ok := client.TLSConnectionState()
 		if !ok {
 			return errors.New("Notifier SMTP client can't send an email over plain text connection")
 		}
 	}
 
-	wc, err := n.client.Data()
+	wc, err := client.Data()
 	if err != nil {
 		logger.Debugf("Notifier SMTP client error while obtaining WriteCloser: %s", err)
 		return err
@@ -153,8 +200,13 @@ func (n *SMTPNotifier) compose(recipient, subject, body, htmlBody string) error
 	now := time.Now()
 
 	msg := "Date:" + now.Format(rfc5322DateTimeLayout) + "\n" +
-		"From: " + n.sender + "\n" +
-		"To: " + recipient + "\n" +
+		"From: " + c.from + "\n"
+
+	if c.replyTo != "" {
+		msg += "Reply-To: " + c.replyTo + "\n"
+	}
+
+	msg += "To: " + recipient + "\n" +
 		"Subject: " + subject + "\n" +
 		"MIME-version: 1.0\n" +
 		"Content-Type: multipart/alternative; boundary=" + boundary + "\n\n" +
@@ -187,78 +239,86 @@ func (n *SMTPNotifier) compose(recipient, subject, body, htmlBody string) error
 	return nil
 }
 
-// Dial the SMTP server with the SMTPNotifier config.
-func (n *SMTPNotifier) dial() error {
+// Dial the SMTP server with the SMTPNotifier config. Every email is sent over its own connection so the notifier can be
+// used concurrently. The deadline of the connection bounds the whole exchange with the server so an unresponsive server
+// can't block the sender forever.
+func (n *SMTPNotifier) dial() (client *smtp.Client, err error) {
 	logger := logging.Logger()
 	logger.Debugf("Notifier SMTP client attempting connection to %s", n.address)
 
+	dialer := &net.Dialer{Timeout: n.timeout}
+
+	var conn net.Conn
+
 	if n.port == 465 {
 		logger.Warnf("Notifier SMTP client configured to connect to a SMTPS server. It's highly recommended you use a non SMTPS port and STARTTLS instead of SMTPS, as the protocol is long deprecated.")
 
-		conn, err := tls.Dial("tcp", n.address, n.tlsConfig)
-		if err != nil {
-			return err
-		}
+		conn, err = tls.DialWithDialer(dialer, "tcp", n.address, n.tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", n.address)
+	}
 
-		client, err := smtp.NewClient(conn, n.host)
-		if err != nil {
-			return err
-		}
+	if err != nil {
+		return nil, err
+	}
 
-		n.client = client
-	} else {
-		client, err := smtp.Dial(n.address)
-		if err != nil {
-			return err
-		}
+	if err = conn.SetDeadline(time.Now().Add(n.timeout)); err != nil {
+		conn.Close()
+		return nil, err
+	}
 
-		n.client = client
+	client, err = smtp.NewClient(conn, n.host)
+	if err != nil {
+		conn.Close()
+		return nil, err
 	}
 
 	logger.Debug("Notifier SMTP client connected successfully")
 
-	return nil
+	return client, nil
 }
 
 // Closes the connection properly.
-func (n *SMTPNotifier) cleanup() {
+func (n *SMTPNotifier) cleanup(client *smtp.Client) {
 	logger := logging.Logger()
 
-	err := n.client.Quit()
+	err := client.Quit()
 	if err != nil {
 		logger.Warnf("Notifier SMTP client encountered error during cleanup: %s", err)
 	}
 }
 
-// StartupCheck checks the server is functioning correctly and the configuration is correct.
+// StartupCheck checks the server is functioning correctly and the configuration is correct. The errors tell which step
+// of the exchange with the server failed so the misconfiguration can be found before the first email is sent.
 func (n *SMTPNotifier) StartupCheck() (bool, error) {
-	if err := n.dial(); err != nil {
-		return false, err
+	client, err := n.dial()
+	if err != nil {
+		return false, fmt.Errorf("unable to connect to the SMTP server %s: %w", n.address, err)
 	}
 
-	defer n.cleanup()
+	defer n.cleanup(client)
 
-	if err := n.client.Hello(n.identifier); err != nil {
-		return false, err
+	if err := client.Hello(n.identifier); err != nil {
+		return false, fmt.Errorf("unable to greet the SMTP server with identifier %s: %w", n.identifier, err)
 	}
 
-	if err := n.startTLS(); err != nil {
-		return false, err
+	if err := n.startTLS(client); err != nil {
+		return false, fmt.Errorf("unable to secure the connection to the SMTP server: %w", err)
 	}
 
-	if err := n.auth(); err != nil {
-		return false, err
+	if err := n.auth(client); err != nil {
+		return false, fmt.Errorf("unable to authenticate to the SMTP server as %s: %w", n.username, err)
 	}
 
-	if err := n.client.Mail(n.sender); err != nil {
-		return false, err
+	if err := client.Mail(n.sender); err != nil {
+		return false, fmt.Errorf("the SMTP server refused the sender %s: %w", n.sender, err)
 	}
 
-	if err := n.client.Rcpt(n.startupCheckAddress); err != nil {
-		return false, err
+	if err := client.Rcpt(n.startupCheckAddress); err != nil {
+		return false, fmt.Errorf("the SMTP server refused the recipient %s: %w", n.startupCheckAddress, err)
 	}
 
-	if err := n.client.Reset(); err != nil {
+	if err := client.Reset(); err != nil {
 		return false, err
 	}
 
@@ -267,42 +327,50 @@ func (n *SMTPNotifier) StartupCheck() (bool, error) {
 
 // Send is used to send an email to a recipient.
 func (n *SMTPNotifier) Send(recipient, title, body, htmlBody string) error {
+	return n.SendCategory("", recipient, title, body, htmlBody)
+}
+
+// SendCategory is used to send an email to a recipient with the sender display name, the reply-to address and the
+// subject of its category.
+func (n *SMTPNotifier) SendCategory(category Category, recipient, title, body, htmlBody string) error {
 	logger := logging.Logger()
-	subject := strings.ReplaceAll(n.subject, "{title}", title)
+	c := n.category(category)
+	subject := strings.ReplaceAll(c.subject, "{title}", title)
 
-	if err := n.dial(); err != nil {
+	client, err := n.dial()
+	if err != nil {
 		return err
 	}
 
 	// Always execute QUIT at the end once we're connected.
-	defer n.cleanup()
+	defer n.cleanup(client)
 
-	if err := n.client.Hello(n.identifier); err != nil {
+	if err := client.Hello(n.identifier); err != nil {
 		return err
 	}
 
 	// Start TLS and then Authenticate.
-	if err := n.startTLS(); err != nil {
+	if err := n.startTLS(client); err != nil {
 		return err
 	}
 
-	if err := n.auth(); err != nil {
+	if err := n.auth(client); err != nil {
 		return err
 	}
 
 	// Set the sender and recipient first.
-	if err := n.client.Mail(n.sender); err != nil {
+	if err := client.Mail(n.sender); err != nil {
 		logger.Debugf("Notifier SMTP failed while sending MAIL FROM (using sender) with error: %s", err)
 		return err
 	}
 
-	if err := n.client.Rcpt(recipient); err != nil {
+	if err := client.Rcpt(recipient); err != nil {
 		logger.Debugf("Notifier SMTP failed while sending RCPT TO (using recipient) with error: %s", err)
 		return err
 	}
 
 	// Compose and send the email body to the server.
-	if err := n.compose(recipient, subject, body, htmlBody); err != nil {
+	if err := n.compose(client, c, recipient, subject, body, htmlBody); err != nil {
 		return err
 	}
 
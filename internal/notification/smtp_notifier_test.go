@@ -2,9 +2,16 @@ package notification
 
 import (
 	"crypto/tls"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/textproto"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/authelia/authelia/internal/configuration/schema"
 	"github.com/authelia/authelia/internal/configuration/validator"
@@ -55,3 +62,178 @@ func TestShouldConfigureSMTPNotifierWithServerNameOverrideAndDefaultTLS12(t *tes
 	assert.False(t, notifier.tlsConfig.InsecureSkipVerify)
 	assert.Equal(t, "smtp.example.com:25", notifier.address)
 }
+
+func TestShouldReportTheFailingStepOfTheStartupCheck(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	defer listener.Close()
+
+	// A SMTP server which doesn't support STARTTLS.
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		text := textproto.NewConn(conn)
+
+		_ = text.PrintfLine("220 localhost ESMTP")
+
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				return
+			}
+
+			switch {
+			case strings.HasPrefix(line, "EHLO"):
+				_ = text.PrintfLine("250 localhost")
+			case strings.HasPrefix(line, "QUIT"):
+				_ = text.PrintfLine("221 bye")
+				return
+			default:
+				_ = text.PrintfLine("500 unknown command")
+			}
+		}
+	}()
+
+	address := listener.Addr().(*net.TCPAddr)
+
+	notifier := NewSMTPNotifier(schema.SMTPNotifierConfiguration{
+		Host:       "127.0.0.1",
+		Port:       address.Port,
+		Identifier: "localhost",
+		TLS:        &schema.TLSConfig{},
+	}, nil)
+
+	_, err = notifier.StartupCheck()
+	assert.EqualError(t, err, "unable to secure the connection to the SMTP server: Notifier SMTP server does not support TLS and it is required by default (see documentation if you want to disable this highly recommended requirement)")
+
+	notifier = NewSMTPNotifier(schema.SMTPNotifierConfiguration{Host: "127.0.0.1", Port: 1, TLS: &schema.TLSConfig{}}, nil)
+
+	_, err = notifier.StartupCheck()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to connect to the SMTP server 127.0.0.1:1: ")
+}
+
+func TestShouldBoundTheExchangeWithAnUnresponsiveSMTPServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	defer listener.Close()
+
+	// A SMTP server which accepts the connection but never greets the client.
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		_, _ = io.Copy(ioutil.Discard, conn)
+	}()
+
+	notifier := NewSMTPNotifier(schema.SMTPNotifierConfiguration{
+		Host:    "127.0.0.1",
+		Port:    listener.Addr().(*net.TCPAddr).Port,
+		Timeout: "1s",
+		TLS:     &schema.TLSConfig{},
+	}, nil)
+
+	start := time.Now()
+
+	err = notifier.Send("john@example.com", "Title", "Body", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "i/o timeout")
+	assert.Less(t, int64(time.Since(start)), int64(5*time.Second))
+}
+
+// serveSMTP accepts a single plain text SMTP session and sends the data of the email it receives.
+func serveSMTP(listener net.Listener, data chan<- string) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+
+	defer conn.Close()
+
+	text := textproto.NewConn(conn)
+
+	_ = text.PrintfLine("220 localhost ESMTP")
+
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+
+		switch {
+		case strings.HasPrefix(line, "EHLO"), strings.HasPrefix(line, "MAIL"), strings.HasPrefix(line, "RCPT"):
+			_ = text.PrintfLine("250 OK")
+		case strings.HasPrefix(line, "DATA"):
+			_ = text.PrintfLine("354 Go ahead")
+
+			lines, err := text.ReadDotLines()
+			if err != nil {
+				return
+			}
+
+			data <- strings.Join(lines, "\n")
+
+			_ = text.PrintfLine("250 OK")
+		case strings.HasPrefix(line, "QUIT"):
+			_ = text.PrintfLine("221 bye")
+			return
+		default:
+			_ = text.PrintfLine("500 unknown command")
+		}
+	}
+}
+
+func TestShouldSendEmailsWithTheSenderNameReplyToAndSubjectOfTheirCategory(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	defer listener.Close()
+
+	data := make(chan string, 1)
+
+	notifier := NewSMTPNotifier(schema.SMTPNotifierConfiguration{
+		Host:              "127.0.0.1",
+		Port:              listener.Addr().(*net.TCPAddr).Port,
+		Identifier:        "localhost",
+		Sender:            "admin@example.com",
+		Subject:           "[Authelia] {title}",
+		DisableRequireTLS: true,
+		TLS:               &schema.TLSConfig{},
+		Categories: schema.SMTPNotifierCategoriesConfiguration{
+			SecurityAlert: &schema.SMTPNotifierCategoryConfiguration{
+				SenderName: "Authelia Security",
+				ReplyTo:    "security@example.com",
+				Subject:    "[Security] {title}",
+			},
+		},
+	}, nil)
+
+	go serveSMTP(listener, data)
+
+	require.NoError(t, notifier.SendCategory(CategorySecurityAlert, "john@example.com", "New device", "Body", ""))
+
+	email := <-data
+	assert.Contains(t, email, "\nFrom: \"Authelia Security\" <admin@example.com>\n")
+	assert.Contains(t, email, "\nReply-To: security@example.com\n")
+	assert.Contains(t, email, "\nSubject: [Security] New device\n")
+
+	go serveSMTP(listener, data)
+
+	require.NoError(t, notifier.SendCategory(CategoryVerification, "john@example.com", "Reset your password", "Body", ""))
+
+	email = <-data
+	assert.Contains(t, email, "\nFrom: admin@example.com\n")
+	assert.NotContains(t, email, "Reply-To:")
+	assert.Contains(t, email, "\nSubject: [Authelia] Reset your password\n")
+}
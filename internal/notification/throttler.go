@@ -0,0 +1,279 @@
+package notification
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/events"
+	"github.com/authelia/authelia/internal/logging"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/templates"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// throttlerFlushInterval is the interval at which the throttler looks for the digests to send.
+const throttlerFlushInterval = time.Minute
+
+// ThrottleProvider stores the throttling state of the security notifications, it's implemented by the storage
+// providers so the state is shared by the instances of Authelia and survives restarts.
+type ThrottleProvider interface {
+	LoadNotificationThrottle(username, kind string) (models.NotificationThrottle, error)
+	SaveNotificationThrottle(throttle models.NotificationThrottle) error
+	LoadPendingNotificationThrottles() ([]models.NotificationThrottle, error)
+}
+
+// Throttler limits the security notifications sent to a user to one of each kind per interval so the users aren't
+// spammed during an attack. The notifications suppressed in between are counted and sent as a digest once the
+// interval has elapsed. The failed authentication attempts are only ever notified as a digest, when a user fails
+// enough of them during an interval.
+type Throttler struct {
+	provider          ThrottleProvider
+	notifier          Notifier
+	users             authentication.UserProvider
+	clock             utils.Clock
+	disableHTMLEmails bool
+
+	interval       time.Duration
+	failedAttempts int
+
+	// mutex serializes the updates of the throttling state made by this instance.
+	mutex sync.Mutex
+}
+
+// NewThrottler creates a Throttler from the throttling configuration, the users provider is used to find the email
+// address the digests are sent to.
+func NewThrottler(configuration schema.NotifierThrottlingConfiguration, provider ThrottleProvider, notifier Notifier,
+	users authentication.UserProvider, clock utils.Clock, disableHTMLEmails bool) *Throttler {
+	interval, _ := utils.ParseDurationString(configuration.Interval)
+
+	return &Throttler{
+		provider:          provider,
+		notifier:          notifier,
+		users:             users,
+		clock:             clock,
+		disableHTMLEmails: disableHTMLEmails,
+		interval:          interval,
+		failedAttempts:    configuration.FailedAttempts,
+	}
+}
+
+// Start sends the digests of the suppressed notifications in the background, the throttling is disabled when the
+// interval is 0.
+func (t *Throttler) Start() {
+	if t.interval <= 0 {
+		return
+	}
+
+	go func() {
+		for {
+			<-t.clock.After(throttlerFlushInterval)
+
+			t.Flush()
+		}
+	}()
+}
+
+// Throttle wraps the subscriber sending a kind of notification so it's only called once per interval for each user,
+// the events suppressed in between are counted to be sent as a digest.
+func (t *Throttler) Throttle(kind string, subscriber events.Subscriber) events.Subscriber {
+	return func(event events.Event) {
+		allowed, err := t.Allow(event.Username, kind)
+		if err != nil {
+			logging.Logger().Errorf("Unable to throttle the %s notification of user %s: %s", kind, event.Username, err)
+		}
+
+		if allowed || err != nil {
+			subscriber(event)
+			return
+		}
+
+		logging.Logger().Debugf("Throttling the %s notification of user %s", kind, event.Username)
+	}
+}
+
+// FailedAttemptsSubscriber creates a subscriber of the LoginFailed events counting the failed attempts of the users
+// so they're sent a digest when they reach the configured number of failed attempts during an interval.
+func (t *Throttler) FailedAttemptsSubscriber() events.Subscriber {
+	return func(event events.Event) {
+		if err := t.RecordFailedAttempt(event.Username); err != nil {
+			logging.Logger().Errorf("Unable to record the failed attempt of user %s for the notifications: %s", event.Username, err)
+		}
+	}
+}
+
+// Allow returns true when a notification of the given kind can be sent to the user, i.e. when none has been sent
+// during the interval. Otherwise the notification is counted as pending to be sent in the digest.
+func (t *Throttler) Allow(username, kind string) (bool, error) {
+	if t.interval <= 0 {
+		return true, nil
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	throttle, err := t.provider.LoadNotificationThrottle(username, kind)
+	if err != nil {
+		return false, err
+	}
+
+	now := t.clock.Now()
+
+	if now.Sub(throttle.LastSentAt) >= t.interval {
+		throttle.LastSentAt = now
+
+		return true, t.provider.SaveNotificationThrottle(throttle)
+	}
+
+	throttle.Pending++
+
+	if throttle.PendingSince.IsZero() {
+		throttle.PendingSince = now
+	}
+
+	return false, t.provider.SaveNotificationThrottle(throttle)
+}
+
+// RecordFailedAttempt counts a failed authentication attempt of the user, the count is reset when the user didn't
+// fail enough attempts during the interval to be sent a digest.
+func (t *Throttler) RecordFailedAttempt(username string) error {
+	if t.interval <= 0 || t.failedAttempts <= 0 {
+		return nil
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	throttle, err := t.provider.LoadNotificationThrottle(username, models.NotificationKindLoginFailed)
+	if err != nil {
+		return err
+	}
+
+	now := t.clock.Now()
+
+	if throttle.Pending < t.failedAttempts && now.Sub(throttle.PendingSince) >= t.interval {
+		throttle.Pending, throttle.PendingSince = 0, now
+	}
+
+	throttle.Pending++
+
+	return t.provider.SaveNotificationThrottle(throttle)
+}
+
+// Flush sends the digests of the notifications suppressed during an interval which has now elapsed.
+func (t *Throttler) Flush() {
+	logger := logging.Logger()
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	throttles, err := t.provider.LoadPendingNotificationThrottles()
+	if err != nil {
+		logger.Errorf("Unable to load the pending notifications: %s", err)
+		return
+	}
+
+	now := t.clock.Now()
+
+	for _, throttle := range throttles {
+		if now.Sub(throttle.LastSentAt) < t.interval {
+			continue
+		}
+
+		switch {
+		case throttle.Kind != models.NotificationKindLoginFailed:
+		case throttle.Pending >= t.failedAttempts:
+		case now.Sub(throttle.PendingSince) >= t.interval:
+			// The user didn't fail enough attempts during the interval to be notified.
+			throttle.Pending, throttle.PendingSince = 0, time.Time{}
+
+			if err = t.provider.SaveNotificationThrottle(throttle); err != nil {
+				logger.Errorf("Unable to reset the failed attempts of user %s: %s", throttle.Username, err)
+			}
+
+			continue
+		default:
+			continue
+		}
+
+		if err = t.notify(throttle); err != nil {
+			logger.Errorf("Unable to send the digest of the %s notifications to user %s: %s", throttle.Kind, throttle.Username, err)
+			continue
+		}
+
+		throttle.LastSentAt, throttle.Pending, throttle.PendingSince = now, 0, time.Time{}
+
+		if err = t.provider.SaveNotificationThrottle(throttle); err != nil {
+			logger.Errorf("Unable to save the digest of the %s notifications of user %s: %s", throttle.Kind, throttle.Username, err)
+		}
+	}
+}
+
+// notify sends the digest of the pending notifications to the user. The digests of the users who can't be found, such
+// as the usernames tried by an attacker, or who don't have any email address are dropped.
+func (t *Throttler) notify(throttle models.NotificationThrottle) error {
+	details, err := t.users.GetDetails(throttle.Username)
+	if err != nil {
+		logging.Logger().Debugf("Dropping the digest of the %s notifications of user %s: %s", throttle.Kind, throttle.Username, err)
+		return nil
+	}
+
+	if len(details.Emails) == 0 {
+		logging.Logger().Warnf("Unable to send the digest of the %s notifications to user %s: user does not have any email address", throttle.Kind, throttle.Username)
+		return nil
+	}
+
+	return t.sendDigest(throttle, details.Emails[0])
+}
+
+// sendDigest sends the digest of the pending notifications to the user.
+func (t *Throttler) sendDigest(throttle models.NotificationThrottle, email string) (err error) {
+	var title, body string
+
+	since := throttle.PendingSince.UTC().Format(time.RFC1123)
+
+	switch throttle.Kind {
+	case models.NotificationKindLoginFailed:
+		title = "Failed login attempts"
+		body = fmt.Sprintf("There have been %d failed login attempts on your account since %s.", throttle.Pending, since)
+	case models.NotificationKindDeviceRegistered:
+		title = "New devices registered"
+		body = fmt.Sprintf("%d more devices have been registered on your account since %s.", throttle.Pending, since)
+	case models.NotificationKindDeviceRemoved:
+		title = "Devices removed"
+		body = fmt.Sprintf("%d more devices have been removed from your account since %s.", throttle.Pending, since)
+	case models.NotificationKindSessionImpossibleTravel:
+		title = "Sessions used from distant locations"
+		body = fmt.Sprintf("Your sessions have been used from distant locations %d more times since %s.", throttle.Pending, since)
+	default:
+		return fmt.Errorf("unknown kind of notification %s", throttle.Kind)
+	}
+
+	body += " If it was not you your credentials might have been compromised. You should reset your password and contact an administrator."
+
+	params := map[string]interface{}{
+		"title": title,
+		"body":  body,
+	}
+
+	bufHTML := new(bytes.Buffer)
+
+	if !t.disableHTMLEmails {
+		if err = templates.HTMLEmailTemplate.Execute(bufHTML, params); err != nil {
+			return err
+		}
+	}
+
+	bufText := new(bytes.Buffer)
+
+	if err = templates.PlainTextSecurityEmailTemplate.Execute(bufText, params); err != nil {
+		return err
+	}
+
+	logging.Logger().Debugf("Sending an email to user %s (%s) with the digest of the %s notifications", throttle.Username, email, throttle.Kind)
+
+	return SendWithCategory(t.notifier, CategorySecurityAlert, email, title, bufText.String(), bufHTML.String())
+}
@@ -0,0 +1,187 @@
+package notification
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/events"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+type fakeThrottleProvider struct {
+	throttles map[string]models.NotificationThrottle
+}
+
+func (p *fakeThrottleProvider) LoadNotificationThrottle(username, kind string) (models.NotificationThrottle, error) {
+	if throttle, ok := p.throttles[username+kind]; ok {
+		return throttle, nil
+	}
+
+	return models.NotificationThrottle{Username: username, Kind: kind}, nil
+}
+
+func (p *fakeThrottleProvider) SaveNotificationThrottle(throttle models.NotificationThrottle) error {
+	p.throttles[throttle.Username+throttle.Kind] = throttle
+	return nil
+}
+
+func (p *fakeThrottleProvider) LoadPendingNotificationThrottles() ([]models.NotificationThrottle, error) {
+	var throttles []models.NotificationThrottle
+
+	for _, throttle := range p.throttles {
+		if throttle.Pending > 0 {
+			throttles = append(throttles, throttle)
+		}
+	}
+
+	return throttles, nil
+}
+
+type fakeUserProvider struct {
+	authentication.UserProvider
+}
+
+func (p fakeUserProvider) GetDetails(username string) (*authentication.UserDetails, error) {
+	if username != "john" {
+		return nil, fmt.Errorf("User '%s' does not exist in database", username)
+	}
+
+	return &authentication.UserDetails{Username: username, Emails: []string{"john@example.com"}}, nil
+}
+
+func newTestThrottler(failedAttempts int) (*Throttler, *failingNotifier, *fakeThrottleProvider, *utils.FakeClock) {
+	notifier := &failingNotifier{attempts: make(chan string, 10)}
+	provider := &fakeThrottleProvider{throttles: map[string]models.NotificationThrottle{}}
+	clock := utils.NewFakeClock(time.Unix(1600000000, 0))
+
+	throttler := NewThrottler(schema.NotifierThrottlingConfiguration{Interval: "1h", FailedAttempts: failedAttempts},
+		provider, notifier, fakeUserProvider{}, clock, true)
+
+	return throttler, notifier, provider, clock
+}
+
+func TestShouldThrottleNotificationsAndSendDigest(t *testing.T) {
+	throttler, notifier, provider, clock := newTestThrottler(0)
+
+	notified := 0
+	subscriber := throttler.Throttle(models.NotificationKindDeviceRegistered, func(event events.Event) {
+		notified++
+	})
+
+	for i := 0; i < 3; i++ {
+		subscriber(events.Event{Type: events.DeviceRegistered, Username: "john"})
+		clock.Advance(time.Minute)
+	}
+
+	assert.Equal(t, 1, notified)
+
+	throttle, err := provider.LoadNotificationThrottle("john", models.NotificationKindDeviceRegistered)
+	require.NoError(t, err)
+	assert.Equal(t, 2, throttle.Pending)
+
+	// The digest waits for the end of the interval.
+	throttler.Flush()
+	assert.Len(t, notifier.attempts, 0)
+
+	clock.Advance(time.Hour)
+	throttler.Flush()
+
+	require.Len(t, notifier.attempts, 1)
+	assert.Equal(t, "john@example.com", <-notifier.attempts)
+
+	throttle, err = provider.LoadNotificationThrottle("john", models.NotificationKindDeviceRegistered)
+	require.NoError(t, err)
+	assert.Equal(t, 0, throttle.Pending)
+	assert.Equal(t, clock.Now(), throttle.LastSentAt)
+
+	// The digest counts as the notification of the interval.
+	subscriber(events.Event{Type: events.DeviceRegistered, Username: "john"})
+	assert.Equal(t, 1, notified)
+
+	clock.Advance(time.Hour)
+	subscriber(events.Event{Type: events.DeviceRegistered, Username: "john"})
+	assert.Equal(t, 2, notified)
+}
+
+func TestShouldSendDigestOfRemovedDevices(t *testing.T) {
+	throttler, notifier, provider, clock := newTestThrottler(0)
+
+	subscriber := throttler.Throttle(models.NotificationKindDeviceRemoved, func(event events.Event) {})
+
+	for i := 0; i < 2; i++ {
+		subscriber(events.Event{Type: events.DeviceRevoked, Username: "john"})
+	}
+
+	clock.Advance(time.Hour)
+	throttler.Flush()
+
+	require.Len(t, notifier.attempts, 1)
+	assert.Equal(t, "john@example.com", <-notifier.attempts)
+
+	throttle, err := provider.LoadNotificationThrottle("john", models.NotificationKindDeviceRemoved)
+	require.NoError(t, err)
+	assert.Equal(t, 0, throttle.Pending)
+}
+
+func TestShouldNotThrottleNotificationsWithoutInterval(t *testing.T) {
+	throttler := NewThrottler(schema.NotifierThrottlingConfiguration{Interval: "0"},
+		&fakeThrottleProvider{}, nil, fakeUserProvider{}, utils.NewFakeClock(time.Unix(1600000000, 0)), true)
+
+	for i := 0; i < 3; i++ {
+		allowed, err := throttler.Allow("john", models.NotificationKindDeviceRegistered)
+		assert.NoError(t, err)
+		assert.True(t, allowed)
+	}
+}
+
+func TestShouldSendDigestOfFailedAttempts(t *testing.T) {
+	throttler, notifier, provider, clock := newTestThrottler(3)
+	subscriber := throttler.FailedAttemptsSubscriber()
+
+	// Two failed attempts aren't enough to be notified, they're forgotten after the interval.
+	for i := 0; i < 2; i++ {
+		subscriber(events.Event{Type: events.LoginFailed, Username: "john"})
+	}
+
+	clock.Advance(time.Hour)
+	throttler.Flush()
+	assert.Len(t, notifier.attempts, 0)
+
+	throttle, err := provider.LoadNotificationThrottle("john", models.NotificationKindLoginFailed)
+	require.NoError(t, err)
+	assert.Equal(t, 0, throttle.Pending)
+
+	for i := 0; i < 5; i++ {
+		subscriber(events.Event{Type: events.LoginFailed, Username: "john"})
+		subscriber(events.Event{Type: events.LoginFailed, Username: "unknown"})
+	}
+
+	throttler.Flush()
+
+	require.Len(t, notifier.attempts, 1)
+	assert.Equal(t, "john@example.com", <-notifier.attempts)
+
+	// The digest of the unknown user is dropped.
+	throttles, err := provider.LoadPendingNotificationThrottles()
+	require.NoError(t, err)
+	assert.Len(t, throttles, 0)
+
+	// The next digest can't be sent before the end of the interval.
+	for i := 0; i < 5; i++ {
+		subscriber(events.Event{Type: events.LoginFailed, Username: "john"})
+	}
+
+	throttler.Flush()
+	assert.Len(t, notifier.attempts, 0)
+
+	clock.Advance(time.Hour)
+	throttler.Flush()
+	assert.Len(t, notifier.attempts, 1)
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/authelia/authelia/internal/authentication"
 	"github.com/authelia/authelia/internal/authorization"
 	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/models"
 	"github.com/authelia/authelia/internal/session"
 )
 
@@ -20,9 +21,14 @@ func NewClient(config schema.OpenIDConnectClientConfiguration) (client *Internal
 		GrantTypes:    config.GrantTypes,
 		ResponseTypes: config.ResponseTypes,
 		Scopes:        config.Scopes,
+		Audience:      config.Audience,
 
 		UserinfoSigningAlgorithm: config.UserinfoSigningAlgorithm,
 
+		BackChannelLogoutURI:   config.BackChannelLogoutURI,
+		FrontChannelLogoutURI:  config.FrontChannelLogoutURI,
+		PostLogoutRedirectURIs: config.PostLogoutRedirectURIs,
+
 		ResponseModes: []fosite.ResponseModeType{
 			fosite.ResponseModeDefault,
 		},
@@ -35,6 +41,28 @@ func NewClient(config schema.OpenIDConnectClientConfiguration) (client *Internal
 	return client
 }
 
+// NewRegisteredClient creates a new InternalClient from a client registered with the dynamic registration endpoint, its
+// secret is the crypt hash of the secret.
+func NewRegisteredClient(client models.OIDCClient) *InternalClient {
+	return NewClient(schema.OpenIDConnectClientConfiguration{
+		ID:            client.ID,
+		Description:   client.Description,
+		Secret:        client.SecretHash,
+		RedirectURIs:  client.RedirectURIs,
+		Policy:        client.Policy,
+		Scopes:        client.Scopes,
+		GrantTypes:    client.GrantTypes,
+		ResponseTypes: client.ResponseTypes,
+		ResponseModes: schema.DefaultOpenIDConnectClientConfiguration.ResponseModes,
+
+		UserinfoSigningAlgorithm: schema.DefaultOpenIDConnectClientConfiguration.UserinfoSigningAlgorithm,
+
+		BackChannelLogoutURI:   client.BackChannelLogoutURI,
+		FrontChannelLogoutURI:  client.FrontChannelLogoutURI,
+		PostLogoutRedirectURIs: client.PostLogoutRedirectURIs,
+	})
+}
+
 // IsAuthenticationLevelSufficient returns if the provided authentication.Level is sufficient for the client of the AutheliaClient.
 func (c InternalClient) IsAuthenticationLevelSufficient(level authentication.Level) bool {
 	return authorization.IsAuthLevelSufficient(level, c.Policy)
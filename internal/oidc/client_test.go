@@ -32,10 +32,12 @@ func TestNewClient(t *testing.T) {
 		ResponseTypes: schema.DefaultOpenIDConnectClientConfiguration.ResponseTypes,
 		GrantTypes:    schema.DefaultOpenIDConnectClientConfiguration.GrantTypes,
 		ResponseModes: schema.DefaultOpenIDConnectClientConfiguration.ResponseModes,
+		Audience:      []string{"https://api.example.com"},
 	}
 
 	exampleClient := NewClient(exampleConfig)
 	assert.Equal(t, "myapp", exampleClient.ID)
+	assert.Equal(t, fosite.Arguments{"https://api.example.com"}, exampleClient.GetAudience())
 	require.Len(t, exampleClient.ResponseModes, 4)
 	assert.Equal(t, fosite.ResponseModeDefault, exampleClient.ResponseModes[0])
 	assert.Equal(t, fosite.ResponseModeFormPost, exampleClient.ResponseModes[1])
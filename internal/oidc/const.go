@@ -1,10 +1,45 @@
 package oidc
 
+import (
+	"net/http"
+	"time"
+)
+
 var scopeDescriptions = map[string]string{
 	"openid":  "Use OpenID to verify your identity",
 	"email":   "Access your email addresses",
 	"profile": "Access your display name",
 	"groups":  "Access your group membership",
+
+	"offline_access": "Keep the access without you being logged in",
 }
 
 var audienceDescriptions = map[string]string{}
+
+const (
+	// backChannelLogoutEvent is the member of the events claim of the logout tokens.
+	backChannelLogoutEvent = "http://schemas.openid.net/event/backchannel-logout"
+
+	// logoutTokenLifespan is how long the clients accept the logout tokens, they're sent right after being issued.
+	logoutTokenLifespan = 2 * time.Minute
+)
+
+// The error codes of the client registration.
+//
+// See https://datatracker.ietf.org/doc/html/rfc7591#section-3.2.2
+const (
+	errCodeInvalidRedirectURI    = "invalid_redirect_uri"
+	errCodeInvalidClientMetadata = "invalid_client_metadata"
+)
+
+// registrationGrantTypes are the grant types the registered clients may use, the password grant is reserved to the
+// clients of the configuration.
+var registrationGrantTypes = []string{"authorization_code", "implicit", "refresh_token", "client_credentials"}
+
+// registrationTokenEndpointAuthMethods are the methods the registered clients may authenticate with, the first one is
+// the default. The registered clients are always confidential clients.
+var registrationTokenEndpointAuthMethods = []string{"client_secret_basic", "client_secret_post"}
+
+// backChannelLogoutClient sends the logout tokens to the clients, it doesn't wait long for unresponsive clients since
+// the logout of the user waits for all of them.
+var backChannelLogoutClient = &http.Client{Timeout: 5 * time.Second}
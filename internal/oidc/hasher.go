@@ -3,10 +3,23 @@ package oidc
 import (
 	"context"
 	"crypto/subtle"
+	"strings"
+
+	"github.com/authelia/authelia/internal/authentication"
 )
 
-// Compare compares the hash with the data and returns an error if they don't match.
+// Compare compares the hash with the data and returns an error if they don't match. The hash may either be the
+// plain text secret or a crypt hash in the same format as the file authentication backend (argon2id or sha512).
 func (h AutheliaHasher) Compare(_ context.Context, hash, data []byte) (err error) {
+	if isCryptHash(hash) {
+		ok, err := authentication.CheckPassword(string(data), string(hash))
+		if err != nil || !ok {
+			return errPasswordsDoNotMatch
+		}
+
+		return nil
+	}
+
 	if subtle.ConstantTimeCompare(hash, data) == 0 {
 		return errPasswordsDoNotMatch
 	}
@@ -18,3 +31,9 @@ func (h AutheliaHasher) Compare(_ context.Context, hash, data []byte) (err error
 func (h AutheliaHasher) Hash(_ context.Context, data []byte) (hash []byte, err error) {
 	return data, nil
 }
+
+func isCryptHash(hash []byte) bool {
+	value := string(hash)
+
+	return strings.HasPrefix(value, "$argon2id$") || strings.HasPrefix(value, "$6$")
+}
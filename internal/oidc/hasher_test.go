@@ -45,3 +45,27 @@ func TestShouldHashPassword(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, data, hash)
 }
+
+func TestShouldNotRaiseErrorOnMatchingCryptHash(t *testing.T) {
+	hasher := AutheliaHasher{}
+
+	hash := []byte("$6$rounds=50000$aFr56HjK3DrB8t3S$zhPQiS85cgBlNhUKKE6n/AHMlpqrvYSnSL3fEVkK0yHFQ.oFFAd8D4OhPAy18K5U61Z2eBhxQXExGU/eknXlY1")
+
+	ctx := context.Background()
+
+	err := hasher.Compare(ctx, hash, []byte("password"))
+
+	assert.NoError(t, err)
+}
+
+func TestShouldRaiseErrorOnNonMatchingCryptHash(t *testing.T) {
+	hasher := AutheliaHasher{}
+
+	hash := []byte("$6$rounds=50000$aFr56HjK3DrB8t3S$zhPQiS85cgBlNhUKKE6n/AHMlpqrvYSnSL3fEVkK0yHFQ.oFFAd8D4OhPAy18K5U61Z2eBhxQXExGU/eknXlY1")
+
+	ctx := context.Background()
+
+	err := hasher.Compare(ctx, hash, []byte("not_the_password"))
+
+	assert.Equal(t, errPasswordsDoNotMatch, err)
+}
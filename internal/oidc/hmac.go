@@ -0,0 +1,100 @@
+package oidc
+
+import (
+	"context"
+	"time"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/compose"
+	"github.com/ory/fosite/handler/oauth2"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// RotatingHMACStrategy is the strategy of the access tokens, refresh tokens and authorize codes while the HMAC secret
+// is being rotated. The tokens are signed with the HMAC secret, the tokens signed with the previous HMAC secret are
+// accepted until it expires.
+type RotatingHMACStrategy struct {
+	rotating oauth2.CoreStrategy
+	current  oauth2.CoreStrategy
+
+	expiresAt time.Time
+	clock     utils.Clock
+}
+
+// NewRotatingHMACStrategy creates a RotatingHMACStrategy accepting the tokens signed with the previous HMAC secret
+// until expiresAt.
+func NewRotatingHMACStrategy(config *compose.Config, secret, previousSecret []byte, expiresAt time.Time, clock utils.Clock) *RotatingHMACStrategy {
+	return &RotatingHMACStrategy{
+		rotating:  compose.NewOAuth2HMACStrategy(config, secret, [][]byte{previousSecret}),
+		current:   compose.NewOAuth2HMACStrategy(config, secret, nil),
+		expiresAt: expiresAt,
+		clock:     clock,
+	}
+}
+
+// newHMACStrategy creates the HMAC strategy of the provider from the configuration.
+func newHMACStrategy(configuration *schema.OpenIDConnectConfiguration, config *compose.Config) oauth2.CoreStrategy {
+	secret := []byte(utils.HashSHA256FromString(configuration.HMACSecret))
+
+	if configuration.PreviousHMACSecret == "" {
+		return compose.NewOAuth2HMACStrategy(config, secret, nil)
+	}
+
+	return NewRotatingHMACStrategy(config, secret, []byte(utils.HashSHA256FromString(configuration.PreviousHMACSecret)),
+		time.Now().Add(configuration.PreviousHMACSecretLifespan), utils.RealClock{})
+}
+
+func (s *RotatingHMACStrategy) strategy() oauth2.CoreStrategy {
+	if s.clock.Now().Before(s.expiresAt) {
+		return s.rotating
+	}
+
+	return s.current
+}
+
+// AccessTokenSignature implements oauth2.AccessTokenStrategy.
+func (s *RotatingHMACStrategy) AccessTokenSignature(token string) string {
+	return s.strategy().AccessTokenSignature(token)
+}
+
+// GenerateAccessToken implements oauth2.AccessTokenStrategy.
+func (s *RotatingHMACStrategy) GenerateAccessToken(ctx context.Context, requester fosite.Requester) (token string, signature string, err error) {
+	return s.strategy().GenerateAccessToken(ctx, requester)
+}
+
+// ValidateAccessToken implements oauth2.AccessTokenStrategy.
+func (s *RotatingHMACStrategy) ValidateAccessToken(ctx context.Context, requester fosite.Requester, token string) (err error) {
+	return s.strategy().ValidateAccessToken(ctx, requester, token)
+}
+
+// RefreshTokenSignature implements oauth2.RefreshTokenStrategy.
+func (s *RotatingHMACStrategy) RefreshTokenSignature(token string) string {
+	return s.strategy().RefreshTokenSignature(token)
+}
+
+// GenerateRefreshToken implements oauth2.RefreshTokenStrategy.
+func (s *RotatingHMACStrategy) GenerateRefreshToken(ctx context.Context, requester fosite.Requester) (token string, signature string, err error) {
+	return s.strategy().GenerateRefreshToken(ctx, requester)
+}
+
+// ValidateRefreshToken implements oauth2.RefreshTokenStrategy.
+func (s *RotatingHMACStrategy) ValidateRefreshToken(ctx context.Context, requester fosite.Requester, token string) (err error) {
+	return s.strategy().ValidateRefreshToken(ctx, requester, token)
+}
+
+// AuthorizeCodeSignature implements oauth2.AuthorizeCodeStrategy.
+func (s *RotatingHMACStrategy) AuthorizeCodeSignature(token string) string {
+	return s.strategy().AuthorizeCodeSignature(token)
+}
+
+// GenerateAuthorizeCode implements oauth2.AuthorizeCodeStrategy.
+func (s *RotatingHMACStrategy) GenerateAuthorizeCode(ctx context.Context, requester fosite.Requester) (token string, signature string, err error) {
+	return s.strategy().GenerateAuthorizeCode(ctx, requester)
+}
+
+// ValidateAuthorizeCode implements oauth2.AuthorizeCodeStrategy.
+func (s *RotatingHMACStrategy) ValidateAuthorizeCode(ctx context.Context, requester fosite.Requester, token string) (err error) {
+	return s.strategy().ValidateAuthorizeCode(ctx, requester, token)
+}
@@ -0,0 +1,44 @@
+package oidc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ory/fosite"
+	"github.com/ory/fosite/compose"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/utils"
+)
+
+func TestRotatingHMACStrategy_ShouldValidatePreviousTokensUntilExpiry(t *testing.T) {
+	config := &compose.Config{AccessTokenLifespan: time.Hour}
+	secret := []byte(utils.HashSHA256FromString("new-secret"))
+	previousSecret := []byte(utils.HashSHA256FromString("previous-secret"))
+
+	requester := &fosite.Request{Session: &fosite.DefaultSession{
+		ExpiresAt: map[fosite.TokenType]time.Time{fosite.AccessToken: time.Now().Add(time.Hour)},
+	}}
+
+	previousToken, _, err := compose.NewOAuth2HMACStrategy(config, previousSecret, nil).GenerateAccessToken(context.Background(), requester)
+	require.NoError(t, err)
+
+	clock := utils.NewFakeClock(time.Unix(1600000000, 0))
+	strategy := NewRotatingHMACStrategy(config, secret, previousSecret, clock.Now().Add(time.Hour), clock)
+
+	token, _, err := strategy.GenerateAccessToken(context.Background(), requester)
+	require.NoError(t, err)
+
+	assert.NoError(t, strategy.ValidateAccessToken(context.Background(), requester, previousToken))
+	assert.NoError(t, strategy.ValidateAccessToken(context.Background(), requester, token))
+
+	// The new tokens are signed with the new secret.
+	assert.NoError(t, compose.NewOAuth2HMACStrategy(config, secret, nil).ValidateAccessToken(context.Background(), requester, token))
+
+	clock.Advance(time.Hour)
+
+	assert.Error(t, strategy.ValidateAccessToken(context.Background(), requester, previousToken))
+	assert.NoError(t, strategy.ValidateAccessToken(context.Background(), requester, token))
+}
@@ -3,26 +3,47 @@ package oidc
 import (
 	"context"
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/ory/fosite/token/jwt"
 	"gopkg.in/square/go-jose.v2"
 
 	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/logging"
 	"github.com/authelia/authelia/internal/utils"
 )
 
-// NewKeyManagerWithConfiguration when provided a schema.OpenIDConnectConfiguration creates a new KeyManager and adds an
-// initial key to the manager.
+// NewKeyManagerWithConfiguration when provided a schema.OpenIDConnectConfiguration creates a new KeyManager and adds
+// the issuer keys to the manager in the configured order.
 func NewKeyManagerWithConfiguration(configuration *schema.OpenIDConnectConfiguration) (manager *KeyManager, err error) {
 	manager = NewKeyManager()
 
-	_, _, err = manager.AddActivePrivateKeyData(configuration.IssuerPrivateKey)
-	if err != nil {
-		return nil, err
+	if configuration.IssuerPrivateKey != "" {
+		if _, _, err = manager.AddActivePrivateKeyData(configuration.IssuerPrivateKey); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, key := range configuration.IssuerPrivateKeys {
+		if key.Active {
+			_, _, err = manager.AddActivePrivateKeyData(key.Key)
+		} else {
+			_, _, err = manager.AddPrivateKeyData(key.Key)
+		}
+
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return manager, nil
@@ -31,24 +52,78 @@ func NewKeyManagerWithConfiguration(configuration *schema.OpenIDConnectConfigura
 // NewKeyManager creates a new empty KeyManager.
 func NewKeyManager() (manager *KeyManager) {
 	manager = new(KeyManager)
-	manager.keys = map[string]*rsa.PrivateKey{}
+	manager.keys = map[string]crypto.Signer{}
 	manager.keySet = new(jose.JSONWebKeySet)
 
 	return manager
 }
 
-// Strategy returns the RS256JWTStrategy.
-func (m KeyManager) Strategy() (strategy *RS256JWTStrategy) {
+// StartupCheck signs a token with the active key and validates it to check the key can sign the tokens of the provider.
+func (m *KeyManager) StartupCheck() (err error) {
+	strategy, activeKeyID := m.Strategy(), m.GetActiveKeyID()
+
+	if strategy == nil {
+		return errors.New("there is no active key")
+	}
+
+	ctx := context.Background()
+
+	token, _, err := strategy.Generate(ctx, jwt.MapClaims{"iat": time.Now().Unix()}, &jwt.Headers{})
+	if err != nil {
+		return fmt.Errorf("unable to sign a token with the key %s: %w", activeKeyID, err)
+	}
+
+	if _, err = strategy.Validate(ctx, token); err != nil {
+		return fmt.Errorf("unable to validate a token signed with the key %s: %w", activeKeyID, err)
+	}
+
+	return nil
+}
+
+// StartRotation makes the next key the active key at every interval in the background, the rotation is disabled when
+// the interval is 0.
+func (m *KeyManager) StartRotation(interval time.Duration, clock utils.Clock) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		for {
+			<-clock.After(interval)
+
+			keyID, err := m.RotateActiveKey()
+			if err != nil {
+				logging.Logger().Errorf("Unable to rotate the OpenID Connect issuer key: %v", err)
+				continue
+			}
+
+			logging.Logger().Infof("OpenID Connect issuer key %s is now the active key", keyID)
+		}
+	}()
+}
+
+// Strategy returns the JWTStrategy.
+func (m *KeyManager) Strategy() (strategy *JWTStrategy) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
 	return m.strategy
 }
 
-// GetKeySet returns the joseJSONWebKeySet containing the rsa.PublicKey types.
-func (m KeyManager) GetKeySet() (keySet *jose.JSONWebKeySet) {
-	return m.keySet
+// GetKeySet returns a snapshot of the joseJSONWebKeySet containing the public keys of all the keys, active
+// or not, so the tokens signed by a previously active key can still be verified.
+func (m *KeyManager) GetKeySet() (keySet *jose.JSONWebKeySet) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return &jose.JSONWebKeySet{Keys: append([]jose.JSONWebKey(nil), m.keySet.Keys...)}
 }
 
 // GetActiveWebKey obtains the currently active jose.JSONWebKey.
-func (m KeyManager) GetActiveWebKey() (webKey *jose.JSONWebKey, err error) {
+func (m *KeyManager) GetActiveWebKey() (webKey *jose.JSONWebKey, err error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
 	webKeys := m.keySet.Key(m.activeKeyID)
 	if len(webKeys) == 1 {
 		return &webKeys[0], nil
@@ -62,21 +137,30 @@ func (m KeyManager) GetActiveWebKey() (webKey *jose.JSONWebKey, err error) {
 }
 
 // GetActiveKeyID returns the key id of the currently active key.
-func (m KeyManager) GetActiveKeyID() (keyID string) {
+func (m *KeyManager) GetActiveKeyID() (keyID string) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
 	return m.activeKeyID
 }
 
-// GetActiveKey returns the rsa.PublicKey of the currently active key.
-func (m KeyManager) GetActiveKey() (key *rsa.PublicKey, err error) {
+// GetActiveKey returns the crypto.PublicKey of the currently active key.
+func (m *KeyManager) GetActiveKey() (key crypto.PublicKey, err error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
 	if key, ok := m.keys[m.activeKeyID]; ok {
-		return &key.PublicKey, nil
+		return key.Public(), nil
 	}
 
 	return nil, errors.New("failed to retrieve active public key")
 }
 
-// GetActivePrivateKey returns the rsa.PrivateKey of the currently active key.
-func (m KeyManager) GetActivePrivateKey() (key *rsa.PrivateKey, err error) {
+// GetActivePrivateKey returns the crypto.Signer of the currently active key.
+func (m *KeyManager) GetActivePrivateKey() (key crypto.Signer, err error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
 	if key, ok := m.keys[m.activeKeyID]; ok {
 		return key, nil
 	}
@@ -84,9 +168,24 @@ func (m KeyManager) GetActivePrivateKey() (key *rsa.PrivateKey, err error) {
 	return nil, errors.New("failed to retrieve active private key")
 }
 
-// AddActivePrivateKeyData adds a rsa.PublicKey given the key in the PEM string format, then sets it to the active key.
-func (m *KeyManager) AddActivePrivateKeyData(data string) (key *rsa.PrivateKey, webKey *jose.JSONWebKey, err error) {
-	key, err = utils.ParseRsaPrivateKeyFromPemStr(data)
+// GetAlgorithms returns the signing algorithms of the keys without duplicates, in the order the keys were added.
+func (m *KeyManager) GetAlgorithms() (algorithms []string) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	for _, webKey := range m.keySet.Keys {
+		if !utils.IsStringInSlice(webKey.Algorithm, algorithms) {
+			algorithms = append(algorithms, webKey.Algorithm)
+		}
+	}
+
+	return algorithms
+}
+
+// AddActivePrivateKeyData adds a RSA, ECDSA P-256 or Ed25519 key given the key in the PEM string format, then sets it
+// to the active key.
+func (m *KeyManager) AddActivePrivateKeyData(data string) (key crypto.Signer, webKey *jose.JSONWebKey, err error) {
+	key, err = utils.ParsePrivateKeyFromPemStr(data)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -96,11 +195,132 @@ func (m *KeyManager) AddActivePrivateKeyData(data string) (key *rsa.PrivateKey,
 	return key, webKey, err
 }
 
-// AddActivePrivateKey adds a rsa.PublicKey, then sets it to the active key.
-func (m *KeyManager) AddActivePrivateKey(key *rsa.PrivateKey) (webKey *jose.JSONWebKey, err error) {
+// AddPrivateKeyData adds a RSA, ECDSA P-256 or Ed25519 key given the key in the PEM string format without making it
+// the active key.
+func (m *KeyManager) AddPrivateKeyData(data string) (key crypto.Signer, webKey *jose.JSONWebKey, err error) {
+	key, err = utils.ParsePrivateKeyFromPemStr(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	webKey, err = m.AddPrivateKey(key)
+
+	return key, webKey, err
+}
+
+// AddActivePrivateKey adds a private key, then sets it to the active key.
+func (m *KeyManager) AddActivePrivateKey(key crypto.Signer) (webKey *jose.JSONWebKey, err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if webKey, err = m.addPrivateKey(key); err != nil {
+		return nil, err
+	}
+
+	m.setActiveKey(webKey.KeyID)
+
+	return webKey, nil
+}
+
+// AddPrivateKey adds a private key without making it the active key, it's published in the key set and can be made
+// the active key later on.
+func (m *KeyManager) AddPrivateKey(key crypto.Signer) (webKey *jose.JSONWebKey, err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.addPrivateKey(key)
+}
+
+// OnActiveKeyChange registers a function called with the key id of the new active key when the active key is changed
+// by SetActiveKey or RotateActiveKey, e.g. to alert the operators of the rotation.
+func (m *KeyManager) OnActiveKeyChange(hook func(keyID string)) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.activeKeyHook = hook
+}
+
+// SetActiveKey makes the key with the given key id the active key, the tokens are signed by it from now on.
+func (m *KeyManager) SetActiveKey(keyID string) (err error) {
+	if err = m.changeActiveKey(keyID); err != nil {
+		return err
+	}
+
+	m.activeKeyChanged(keyID)
+
+	return nil
+}
+
+// RotateActiveKey makes the key following the active key in the order the keys were added the active key, and
+// returns its key id.
+func (m *KeyManager) RotateActiveKey() (keyID string, err error) {
+	if keyID, err = m.rotateActiveKey(); err != nil {
+		return "", err
+	}
+
+	m.activeKeyChanged(keyID)
+
+	return keyID, nil
+}
+
+func (m *KeyManager) changeActiveKey(keyID string) (err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, ok := m.keys[keyID]; !ok {
+		return fmt.Errorf("key id %s does not exist", keyID)
+	}
+
+	m.setActiveKey(keyID)
+
+	return nil
+}
+
+func (m *KeyManager) rotateActiveKey() (keyID string, err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if len(m.keySet.Keys) < 2 {
+		return "", errors.New("at least 2 keys are required to rotate the active key")
+	}
+
+	for i, webKey := range m.keySet.Keys {
+		if webKey.KeyID == m.activeKeyID {
+			keyID = m.keySet.Keys[(i+1)%len(m.keySet.Keys)].KeyID
+			break
+		}
+	}
+
+	if keyID == "" {
+		keyID = m.keySet.Keys[0].KeyID
+	}
+
+	m.setActiveKey(keyID)
+
+	return keyID, nil
+}
+
+// activeKeyChanged calls the hook of the changes of the active key, it must be called with the mutex unlocked.
+func (m *KeyManager) activeKeyChanged(keyID string) {
+	m.mutex.RLock()
+	hook := m.activeKeyHook
+	m.mutex.RUnlock()
+
+	if hook != nil {
+		hook(keyID)
+	}
+}
+
+// addPrivateKey must be called with the mutex locked.
+func (m *KeyManager) addPrivateKey(key crypto.Signer) (webKey *jose.JSONWebKey, err error) {
+	algorithm, err := SigningAlgorithm(key.Public())
+	if err != nil {
+		return nil, err
+	}
+
 	wk := jose.JSONWebKey{
-		Key:       &key.PublicKey,
-		Algorithm: "RS256",
+		Key:       key.Public(),
+		Algorithm: algorithm,
 		Use:       "sig",
 	}
 
@@ -119,79 +339,266 @@ func (m *KeyManager) AddActivePrivateKey(key *rsa.PrivateKey) (webKey *jose.JSON
 		return nil, fmt.Errorf("key id %s already exists", strKeyID)
 	}
 
-	// TODO: Add Mutex here when implementing key rotation.
 	wk.KeyID = strKeyID
 	m.keySet.Keys = append(m.keySet.Keys, wk)
 	m.keys[strKeyID] = key
-	m.activeKeyID = strKeyID
 
-	m.strategy, err = NewRS256JWTStrategy(wk.KeyID, key)
-	if err != nil {
-		return &wk, err
+	if m.strategy != nil {
+		m.strategy.AddKey(strKeyID, key)
 	}
 
 	return &wk, nil
 }
 
-// NewRS256JWTStrategy returns a new RS256JWTStrategy.
-func NewRS256JWTStrategy(id string, key *rsa.PrivateKey) (strategy *RS256JWTStrategy, err error) {
-	strategy = new(RS256JWTStrategy)
-	strategy.JWTStrategy = new(jwt.RS256JWTStrategy)
+// setActiveKey must be called with the mutex locked. The strategy is created along with the first active key and
+// reused afterwards since fosite keeps a reference to it.
+func (m *KeyManager) setActiveKey(keyID string) {
+	m.activeKeyID = keyID
+
+	if m.strategy == nil {
+		m.strategy = NewJWTStrategy()
 
-	strategy.SetKey(id, key)
+		for _, webKey := range m.keySet.Keys {
+			_ = m.strategy.AddKey(webKey.KeyID, m.keys[webKey.KeyID])
+		}
+	}
 
-	return strategy, nil
+	_ = m.strategy.SetKey(keyID, m.keys[keyID])
 }
 
-// RS256JWTStrategy is a decorator struct for the fosite RS256JWTStrategy.
-type RS256JWTStrategy struct {
-	JWTStrategy *jwt.RS256JWTStrategy
+// SigningAlgorithm returns the JWS algorithm the tokens are signed with by a key given its public key: RS256 for the
+// RSA keys, ES256 for the ECDSA P-256 keys and EdDSA for the Ed25519 keys.
+func SigningAlgorithm(key crypto.PublicKey) (algorithm string, err error) {
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		return "RS256", nil
+	case *ecdsa.PublicKey:
+		if key.Curve != elliptic.P256() {
+			return "", fmt.Errorf("ECDSA key curve %s is not supported, only P-256 is", key.Curve.Params().Name)
+		}
+
+		return "ES256", nil
+	case ed25519.PublicKey:
+		return "EdDSA", nil
+	default:
+		return "", fmt.Errorf("key type %T is not supported", key)
+	}
+}
 
-	keyID string
+// NewJWTStrategy returns a new empty JWTStrategy.
+func NewJWTStrategy() (strategy *JWTStrategy) {
+	return &JWTStrategy{keys: map[string]crypto.Signer{}, algorithms: map[string]string{}}
+}
+
+// JWTStrategy implements the fosite jwt.JWTStrategy for the RS256, ES256 and EdDSA algorithms. It signs the tokens with
+// the active key using the algorithm of the key and verifies them with the key matching their kid header so the tokens
+// signed by a previously active key remain valid.
+type JWTStrategy struct {
+	mutex      sync.RWMutex
+	keyID      string
+	order      []string
+	keys       map[string]crypto.Signer
+	algorithms map[string]string
 }
 
 // KeyID returns the key id.
-func (s RS256JWTStrategy) KeyID() (id string) {
+func (s *JWTStrategy) KeyID() (id string) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
 	return s.keyID
 }
 
+// Algorithm returns the signing algorithm of the active key.
+func (s *JWTStrategy) Algorithm() (algorithm string) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.algorithms[s.keyID]
+}
+
 // SetKey sets the provided key id and key as the active key (this is what triggers fosite to use it).
-func (s *RS256JWTStrategy) SetKey(id string, key *rsa.PrivateKey) {
+func (s *JWTStrategy) SetKey(id string, key crypto.Signer) (err error) {
+	if err = s.AddKey(id, key); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
 	s.keyID = id
-	s.JWTStrategy.PrivateKey = key
+
+	return nil
 }
 
-// Hash is a decorator func for the underlying fosite RS256JWTStrategy.
-func (s *RS256JWTStrategy) Hash(ctx context.Context, in []byte) ([]byte, error) {
-	return s.JWTStrategy.Hash(ctx, in)
+// AddKey adds a key the tokens can be verified with without making it the active key.
+func (s *JWTStrategy) AddKey(id string, key crypto.Signer) (err error) {
+	algorithm, err := SigningAlgorithm(key.Public())
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.keys[id]; !ok {
+		s.order = append(s.order, id)
+	}
+
+	s.keys[id], s.algorithms[id] = key, algorithm
+
+	return nil
 }
 
-// GetSigningMethodLength is a decorator func for the underlying fosite RS256JWTStrategy.
-func (s *RS256JWTStrategy) GetSigningMethodLength() int {
-	return s.JWTStrategy.GetSigningMethodLength()
+func (s *JWTStrategy) active() (id string, key crypto.Signer, algorithm string) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.keyID, s.keys[s.keyID], s.algorithms[s.keyID]
 }
 
-// GetSignature is a decorator func for the underlying fosite RS256JWTStrategy.
-func (s *RS256JWTStrategy) GetSignature(ctx context.Context, token string) (string, error) {
-	return s.JWTStrategy.GetSignature(ctx, token)
+// keyWithAlgorithm returns the active key if it signs the tokens with the algorithm, the first key added signing with
+// it otherwise.
+func (s *JWTStrategy) keyWithAlgorithm(algorithm string) (id string, key crypto.Signer, err error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if s.algorithms[s.keyID] == algorithm {
+		return s.keyID, s.keys[s.keyID], nil
+	}
+
+	for _, id = range s.order {
+		if s.algorithms[id] == algorithm {
+			return id, s.keys[id], nil
+		}
+	}
+
+	return "", nil, fmt.Errorf("there is no key signing with the algorithm %s", algorithm)
 }
 
-// Generate is a decorator func for the underlying fosite RS256JWTStrategy.
-func (s *RS256JWTStrategy) Generate(ctx context.Context, claims jwt.MapClaims, header jwt.Mapper) (string, string, error) {
-	return s.JWTStrategy.Generate(ctx, claims, header)
+func (s *JWTStrategy) verificationKey(token *jwt.Token) (interface{}, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	id, ok := token.Header["kid"].(string)
+	if !ok || id == "" {
+		id = s.keyID
+	}
+
+	key, ok := s.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("the token is signed by the unknown key %s", id)
+	}
+
+	if string(token.Method) != s.algorithms[id] {
+		return nil, fmt.Errorf("the token is signed with the algorithm %s but the key %s signs with %s", token.Method, id, s.algorithms[id])
+	}
+
+	// The public key is wrapped in a jose.JSONWebKey since fosite makes a pointer of the keys which aren't already
+	// one, which go-jose doesn't support for the ed25519.PublicKey.
+	return &jose.JSONWebKey{Key: key.Public(), KeyID: id, Algorithm: s.algorithms[id]}, nil
 }
 
-// Validate is a decorator func for the underlying fosite RS256JWTStrategy.
-func (s *RS256JWTStrategy) Validate(ctx context.Context, token string) (string, error) {
-	return s.JWTStrategy.Validate(ctx, token)
+// Hash hashes the input with the hash function of the algorithm of the active key, SHA-512 for EdDSA and SHA-256
+// otherwise.
+func (s *JWTStrategy) Hash(_ context.Context, in []byte) ([]byte, error) {
+	if _, _, algorithm := s.active(); algorithm == "EdDSA" {
+		sum := sha512.Sum512(in)
+
+		return sum[:], nil
+	}
+
+	sum := sha256.Sum256(in)
+
+	return sum[:], nil
+}
+
+// GetSigningMethodLength returns the length of the hash function of the algorithm of the active key.
+func (s *JWTStrategy) GetSigningMethodLength() int {
+	if _, _, algorithm := s.active(); algorithm == "EdDSA" {
+		return sha512.Size
+	}
+
+	return sha256.Size
+}
+
+// GetSignature returns the signature of the token.
+func (s *JWTStrategy) GetSignature(_ context.Context, token string) (string, error) {
+	split := strings.Split(token, ".")
+	if len(split) != 3 {
+		return "", errors.New("header, body and signature must all be set")
+	}
+
+	return split[2], nil
+}
+
+// Generate signs the token with the active key, the kid header is set to the key signing the token since the active
+// key may be rotated at any time.
+func (s *JWTStrategy) Generate(ctx context.Context, claims jwt.MapClaims, header jwt.Mapper) (string, string, error) {
+	id, key, algorithm := s.active()
+
+	return s.generate(ctx, id, key, algorithm, claims, header)
+}
+
+// GenerateWithAlgorithm signs the token with a key signing with the given algorithm, preferably the active key. It's
+// used for the tokens whose algorithm is chosen by the client such as the signed userinfo responses.
+func (s *JWTStrategy) GenerateWithAlgorithm(ctx context.Context, algorithm string, claims jwt.MapClaims, header jwt.Mapper) (string, string, error) {
+	id, key, err := s.keyWithAlgorithm(algorithm)
+	if err != nil {
+		return "", "", err
+	}
+
+	return s.generate(ctx, id, key, algorithm, claims, header)
+}
+
+func (s *JWTStrategy) generate(ctx context.Context, id string, key crypto.Signer, algorithm string, claims jwt.MapClaims, header jwt.Mapper) (string, string, error) {
+	if claims == nil || header == nil {
+		return "", "", errors.New("either claims or header is nil")
+	}
+
+	if key == nil {
+		return "", "", errors.New("there is no active key")
+	}
+
+	header.Add("kid", id)
+
+	token := jwt.NewWithClaims(jose.SignatureAlgorithm(algorithm), claims)
+
+	for k, v := range header.ToMap() {
+		if _, ok := token.Header[k]; !ok {
+			token.Header[k] = v
+		}
+	}
+
+	raw, err := token.SignedString(key)
+	if err != nil {
+		return "", "", err
+	}
+
+	signature, err := s.GetSignature(ctx, raw)
+	if err != nil {
+		return "", "", err
+	}
+
+	return raw, signature, nil
+}
+
+// Validate validates the token with the key matching its kid header.
+func (s *JWTStrategy) Validate(ctx context.Context, token string) (string, error) {
+	if _, err := s.Decode(ctx, token); err != nil {
+		return "", err
+	}
+
+	return s.GetSignature(ctx, token)
 }
 
-// Decode is a decorator func for the underlying fosite RS256JWTStrategy.
-func (s *RS256JWTStrategy) Decode(ctx context.Context, token string) (*jwt.Token, error) {
-	return s.JWTStrategy.Decode(ctx, token)
+// Decode decodes the token and verifies it with the key matching its kid header, the active key is used when the
+// token has no kid header.
+func (s *JWTStrategy) Decode(_ context.Context, token string) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(token, jwt.MapClaims{}, s.verificationKey)
 }
 
-// GetPublicKeyID is a decorator func for the underlying fosite RS256JWTStrategy.
-func (s *RS256JWTStrategy) GetPublicKeyID(_ context.Context) (string, error) {
-	return s.keyID, nil
+// GetPublicKeyID returns the key id of the active key.
+func (s *JWTStrategy) GetPublicKeyID(_ context.Context) (string, error) {
+	return s.KeyID(), nil
 }
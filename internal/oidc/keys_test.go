@@ -1,11 +1,18 @@
 package oidc
 
 import (
+	"context"
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
 	"fmt"
 	"strings"
 	"testing"
 
+	"github.com/ory/fosite/token/jwt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -51,3 +58,216 @@ func TestKeyManager_AddActiveKeyData(t *testing.T) {
 	assert.NotNil(t, keySet)
 	assert.Equal(t, kid, manager.GetActiveKeyID())
 }
+
+func TestKeyManager_StartupCheck(t *testing.T) {
+	manager := NewKeyManager()
+
+	assert.EqualError(t, manager.StartupCheck(), "there is no active key")
+
+	_, _, err := manager.AddActivePrivateKeyData(exampleIssuerPrivateKey)
+	require.NoError(t, err)
+
+	assert.NoError(t, manager.StartupCheck())
+}
+
+func TestKeyManager_RotateActiveKey(t *testing.T) {
+	manager := NewKeyManager()
+
+	_, err := manager.RotateActiveKey()
+	assert.EqualError(t, err, "at least 2 keys are required to rotate the active key")
+
+	_, first, err := manager.AddActivePrivateKeyData(exampleIssuerPrivateKey)
+	require.NoError(t, err)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	second, err := manager.AddPrivateKey(key)
+	require.NoError(t, err)
+
+	assert.Equal(t, first.KeyID, manager.GetActiveKeyID())
+	assert.Len(t, manager.GetKeySet().Keys, 2)
+
+	ctx := context.Background()
+	strategy := manager.Strategy()
+
+	token, _, err := strategy.Generate(ctx, jwt.MapClaims{"sub": "john"}, &jwt.Headers{})
+	require.NoError(t, err)
+
+	keyID, err := manager.RotateActiveKey()
+	require.NoError(t, err)
+	assert.Equal(t, second.KeyID, keyID)
+	assert.Equal(t, second.KeyID, manager.GetActiveKeyID())
+	assert.Equal(t, second.KeyID, strategy.KeyID())
+
+	// The tokens signed by the previously active key are still valid.
+	_, err = strategy.Validate(ctx, token)
+	assert.NoError(t, err)
+
+	rotated, _, err := strategy.Generate(ctx, jwt.MapClaims{"sub": "john"}, &jwt.Headers{})
+	require.NoError(t, err)
+
+	decoded, err := strategy.Decode(ctx, rotated)
+	require.NoError(t, err)
+	assert.Equal(t, second.KeyID, decoded.Header["kid"])
+
+	keyID, err = manager.RotateActiveKey()
+	require.NoError(t, err)
+	assert.Equal(t, first.KeyID, keyID)
+}
+
+func TestKeyManager_SetActiveKey(t *testing.T) {
+	manager := NewKeyManager()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	inactive, err := manager.AddPrivateKey(key)
+	require.NoError(t, err)
+	assert.Nil(t, manager.Strategy())
+
+	_, active, err := manager.AddActivePrivateKeyData(exampleIssuerPrivateKey)
+	require.NoError(t, err)
+	assert.Equal(t, active.KeyID, manager.GetActiveKeyID())
+
+	assert.EqualError(t, manager.SetActiveKey("abcdef"), "key id abcdef does not exist")
+
+	require.NoError(t, manager.SetActiveKey(inactive.KeyID))
+	assert.Equal(t, inactive.KeyID, manager.Strategy().KeyID())
+	assert.NoError(t, manager.StartupCheck())
+}
+
+func TestKeyManager_ShouldCallHookWhenActiveKeyChanges(t *testing.T) {
+	manager := NewKeyManager()
+
+	var changes []string
+
+	manager.OnActiveKeyChange(func(keyID string) {
+		changes = append(changes, keyID)
+	})
+
+	_, first, err := manager.AddActivePrivateKeyData(exampleIssuerPrivateKey)
+	require.NoError(t, err)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	second, err := manager.AddPrivateKey(key)
+	require.NoError(t, err)
+
+	// Adding the keys isn't a change of the active key.
+	assert.Empty(t, changes)
+
+	_, err = manager.RotateActiveKey()
+	require.NoError(t, err)
+
+	require.NoError(t, manager.SetActiveKey(first.KeyID))
+	assert.Error(t, manager.SetActiveKey("abcdef"))
+
+	assert.Equal(t, []string{second.KeyID, first.KeyID}, changes)
+}
+
+func TestKeyManager_ShouldSignWithTheAlgorithmOfTheKey(t *testing.T) {
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	manager := NewKeyManager()
+
+	_, err = manager.AddActivePrivateKey(ecdsaKey)
+	require.NoError(t, err)
+
+	_, err = manager.AddPrivateKey(ed25519Key)
+	require.NoError(t, err)
+
+	_, _, err = manager.AddPrivateKeyData(exampleIssuerPrivateKey)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"ES256", "EdDSA", "RS256"}, manager.GetAlgorithms())
+	assert.NoError(t, manager.StartupCheck())
+
+	ctx := context.Background()
+	strategy := manager.Strategy()
+
+	for _, algorithm := range []string{"ES256", "EdDSA", "RS256"} {
+		assert.Equal(t, algorithm, strategy.Algorithm())
+
+		token, _, err := strategy.Generate(ctx, jwt.MapClaims{"sub": "john"}, &jwt.Headers{})
+		require.NoError(t, err)
+
+		decoded, err := strategy.Decode(ctx, token)
+		require.NoError(t, err)
+		assert.Equal(t, algorithm, decoded.Header["alg"])
+		assert.Equal(t, manager.GetActiveKeyID(), decoded.Header["kid"])
+
+		_, err = manager.RotateActiveKey()
+		require.NoError(t, err)
+	}
+
+	hash, err := strategy.Hash(ctx, []byte("abc"))
+	require.NoError(t, err)
+	assert.Len(t, hash, strategy.GetSigningMethodLength())
+}
+
+func TestJWTStrategy_ShouldGenerateWithAlgorithm(t *testing.T) {
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	manager := NewKeyManager()
+
+	_, active, err := manager.AddActivePrivateKeyData(exampleIssuerPrivateKey)
+	require.NoError(t, err)
+
+	inactive, err := manager.AddPrivateKey(ed25519Key)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	strategy := manager.Strategy()
+
+	token, _, err := strategy.GenerateWithAlgorithm(ctx, "EdDSA", jwt.MapClaims{"sub": "john"}, &jwt.Headers{})
+	require.NoError(t, err)
+
+	decoded, err := strategy.Decode(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, "EdDSA", decoded.Header["alg"])
+	assert.Equal(t, inactive.KeyID, decoded.Header["kid"])
+
+	token, _, err = strategy.GenerateWithAlgorithm(ctx, "RS256", jwt.MapClaims{"sub": "john"}, &jwt.Headers{})
+	require.NoError(t, err)
+
+	decoded, err = strategy.Decode(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, active.KeyID, decoded.Header["kid"])
+
+	_, _, err = strategy.GenerateWithAlgorithm(ctx, "ES256", jwt.MapClaims{"sub": "john"}, &jwt.Headers{})
+	assert.EqualError(t, err, "there is no key signing with the algorithm ES256")
+}
+
+func TestKeyManager_ShouldRejectUnsupportedCurves(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	_, err = NewKeyManager().AddActivePrivateKey(key)
+	assert.EqualError(t, err, "ECDSA key curve P-384 is not supported, only P-256 is")
+}
+
+func TestJWTStrategy_ShouldRejectTokensOfUnknownKeys(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	other := NewJWTStrategy()
+	require.NoError(t, other.SetKey("abcdef", key))
+
+	token, _, err := other.Generate(context.Background(), jwt.MapClaims{"sub": "john"}, &jwt.Headers{})
+	require.NoError(t, err)
+
+	manager := NewKeyManager()
+
+	_, _, err = manager.AddActivePrivateKeyData(exampleIssuerPrivateKey)
+	require.NoError(t, err)
+
+	_, err = manager.Strategy().Validate(context.Background(), token)
+	assert.EqualError(t, err, "the token is signed by the unknown key abcdef")
+}
@@ -0,0 +1,140 @@
+package oidc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ory/fosite/token/jwt"
+
+	"github.com/authelia/authelia/internal/logging"
+)
+
+// NewLogoutToken creates a logout token signed with the active key telling the client the session of the subject
+// ended.
+//
+// See https://openid.net/specs/openid-connect-backchannel-1_0.html#LogoutToken
+func (m *KeyManager) NewLogoutToken(issuer, clientID, subject, sessionID string, issuedAt time.Time) (token string, err error) {
+	claims := jwt.MapClaims{
+		"iss": issuer,
+		"aud": []string{clientID},
+		"iat": issuedAt.Unix(),
+		"exp": issuedAt.Add(logoutTokenLifespan).Unix(),
+		"jti": uuid.New().String(),
+		"events": map[string]interface{}{
+			backChannelLogoutEvent: map[string]interface{}{},
+		},
+	}
+
+	if subject != "" {
+		claims["sub"] = subject
+	}
+
+	if sessionID != "" {
+		claims["sid"] = sessionID
+	}
+
+	token, _, err = m.Strategy().Generate(context.Background(), claims, &jwt.Headers{})
+
+	return token, err
+}
+
+// BackChannelLogout sends a logout token to the back-channel logout URI of the clients concurrently and waits for
+// them to respond. The clients failing to log the user out are logged but don't prevent the other clients from being
+// called.
+func (p OpenIDConnectProvider) BackChannelLogout(issuer, subject, sessionID string, clientIDs []string, now time.Time) {
+	wg := sync.WaitGroup{}
+
+	for _, id := range clientIDs {
+		client, err := p.Store.GetInternalClient(id)
+		if err != nil || client.BackChannelLogoutURI == "" {
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(client *InternalClient) {
+			defer wg.Done()
+
+			if err := p.backChannelLogout(client, issuer, subject, sessionID, now); err != nil {
+				logging.Logger().Errorf("Unable to log user %s out of the OpenID Connect client %s: %v", subject, client.ID, err)
+				return
+			}
+
+			logging.Logger().Debugf("User %s logged out of the OpenID Connect client %s", subject, client.ID)
+		}(client)
+	}
+
+	wg.Wait()
+}
+
+func (p OpenIDConnectProvider) backChannelLogout(client *InternalClient, issuer, subject, sessionID string, now time.Time) error {
+	token, err := p.KeyManager.NewLogoutToken(issuer, client.ID, subject, sessionID, now)
+	if err != nil {
+		return fmt.Errorf("unable to generate the logout token: %w", err)
+	}
+
+	form := url.Values{}
+	form.Set("logout_token", token)
+
+	req, err := http.NewRequest(http.MethodPost, client.BackChannelLogoutURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := backChannelLogoutClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	// Drain the body so the connection can be reused.
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("back-channel logout uri responded with status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// FrontChannelLogoutURIs returns the front-channel logout URIs of the clients with the iss and sid parameters the
+// portal loads in frames to log the user out of the clients.
+//
+// See https://openid.net/specs/openid-connect-frontchannel-1_0.html#RPLogout
+func (p OpenIDConnectProvider) FrontChannelLogoutURIs(issuer, sessionID string, clientIDs []string) (uris []string) {
+	for _, id := range clientIDs {
+		client, err := p.Store.GetInternalClient(id)
+		if err != nil || client.FrontChannelLogoutURI == "" {
+			continue
+		}
+
+		uri, err := url.Parse(client.FrontChannelLogoutURI)
+		if err != nil {
+			continue
+		}
+
+		query := uri.Query()
+		query.Set("iss", issuer)
+
+		if sessionID != "" {
+			query.Set("sid", sessionID)
+		}
+
+		uri.RawQuery = query.Encode()
+
+		uris = append(uris, uri.String())
+	}
+
+	return uris
+}
@@ -0,0 +1,83 @@
+package oidc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+func TestOpenIDConnectProvider_ShouldSendLogoutTokensToBackChannelLogoutURIs(t *testing.T) {
+	mutex := sync.Mutex{}
+	tokens := map[string]string{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/x-www-form-urlencoded", r.Header.Get("Content-Type"))
+
+		mutex.Lock()
+		tokens[r.URL.Path] = r.FormValue("logout_token")
+		mutex.Unlock()
+
+		if r.URL.Path == "/failing" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	provider, err := NewOpenIDConnectProvider(&schema.OpenIDConnectConfiguration{
+		IssuerPrivateKey: exampleIssuerPrivateKey,
+		HMACSecret:       "rLABDrx87et5KvRHVUgTm3pezWWd8LMN",
+		Clients: []schema.OpenIDConnectClientConfiguration{
+			{ID: "app", Secret: "secret", BackChannelLogoutURI: server.URL + "/app"},
+			{ID: "failing", Secret: "secret", BackChannelLogoutURI: server.URL + "/failing"},
+			{ID: "frontchannel", Secret: "secret", FrontChannelLogoutURI: server.URL + "/frontchannel"},
+		},
+	})
+	require.NoError(t, err)
+
+	now := time.Now()
+
+	provider.BackChannelLogout("https://auth.example.com", "john", "session-id", []string{"app", "failing", "frontchannel", "unknown"}, now)
+
+	require.Len(t, tokens, 2)
+	require.Contains(t, tokens, "/app")
+	require.Contains(t, tokens, "/failing")
+
+	token, err := provider.KeyManager.Strategy().Decode(context.Background(), tokens["/app"])
+	require.NoError(t, err)
+
+	claims := token.Claims
+
+	assert.Equal(t, "https://auth.example.com", claims["iss"])
+	assert.Equal(t, []interface{}{"app"}, claims["aud"])
+	assert.Equal(t, "john", claims["sub"])
+	assert.Equal(t, "session-id", claims["sid"])
+	assert.Equal(t, now.Unix(), claims["iat"])
+	assert.NotEmpty(t, claims["jti"])
+	assert.Nil(t, claims["nonce"])
+	assert.Contains(t, claims["events"], backChannelLogoutEvent)
+}
+
+func TestOpenIDConnectProvider_ShouldReturnFrontChannelLogoutURIs(t *testing.T) {
+	provider, err := NewOpenIDConnectProvider(&schema.OpenIDConnectConfiguration{
+		IssuerPrivateKey: exampleIssuerPrivateKey,
+		HMACSecret:       "rLABDrx87et5KvRHVUgTm3pezWWd8LMN",
+		Clients: []schema.OpenIDConnectClientConfiguration{
+			{ID: "app", Secret: "secret", FrontChannelLogoutURI: "https://app.example.com/logout?mode=frame"},
+			{ID: "backchannel", Secret: "secret", BackChannelLogoutURI: "https://backchannel.example.com/logout"},
+		},
+	})
+	require.NoError(t, err)
+
+	uris := provider.FrontChannelLogoutURIs("https://auth.example.com", "session-id", []string{"app", "backchannel", "unknown"})
+
+	assert.Equal(t, []string{"https://app.example.com/logout?iss=https%3A%2F%2Fauth.example.com&mode=frame&sid=session-id"}, uris)
+}
@@ -4,10 +4,10 @@ import (
 	"net/http"
 
 	"github.com/ory/fosite/compose"
+	"github.com/ory/fosite/handler/openid"
 	"github.com/ory/herodot"
 
 	"github.com/authelia/authelia/internal/configuration/schema"
-	"github.com/authelia/authelia/internal/utils"
 )
 
 // NewOpenIDConnectProvider new-ups a OpenIDConnectProvider.
@@ -41,21 +41,20 @@ func NewOpenIDConnectProvider(configuration *schema.OpenIDConnectConfiguration)
 
 	provider.KeyManager = keyManager
 
-	key, err := provider.KeyManager.GetActivePrivateKey()
-	if err != nil {
+	if _, err = provider.KeyManager.GetActivePrivateKey(); err != nil {
 		return provider, err
 	}
 
 	strategy := &compose.CommonStrategy{
-		CoreStrategy: compose.NewOAuth2HMACStrategy(
-			composeConfiguration,
-			[]byte(utils.HashSHA256FromString(configuration.HMACSecret)),
-			nil,
-		),
-		OpenIDConnectTokenStrategy: compose.NewOpenIDConnectStrategy(
-			composeConfiguration,
-			key,
-		),
+		CoreStrategy: newHMACStrategy(configuration, composeConfiguration),
+		// The ID tokens are signed by the strategy of the key manager instead of a static key so the active key can be
+		// rotated without a restart.
+		OpenIDConnectTokenStrategy: &openid.DefaultStrategy{
+			JWTStrategy:         provider.KeyManager.Strategy(),
+			Expiry:              composeConfiguration.GetIDTokenLifespan(),
+			Issuer:              composeConfiguration.IDTokenIssuer,
+			MinParameterEntropy: composeConfiguration.GetMinParameterEntropy(),
+		},
 		JWTStrategy: provider.KeyManager.Strategy(),
 	}
 
@@ -1,9 +1,17 @@
 package oidc
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/ory/fosite/handler/openid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/authelia/authelia/internal/configuration/schema"
 )
@@ -64,3 +72,50 @@ func TestOpenIDConnectProvider_NewOpenIDConnectProvider_GoodConfiguration(t *tes
 	assert.NotNil(t, provider)
 	assert.NoError(t, err)
 }
+
+func TestOpenIDConnectProvider_ShouldIssueClientCredentialsAccessToken(t *testing.T) {
+	provider, err := NewOpenIDConnectProvider(&schema.OpenIDConnectConfiguration{
+		IssuerPrivateKey:    exampleIssuerPrivateKey,
+		HMACSecret:          "rLABDrx87et5KvRHVUgTm3pezWWd8LMN",
+		AccessTokenLifespan: time.Minute * 5,
+		Clients: []schema.OpenIDConnectClientConfiguration{
+			{
+				ID:         "service",
+				Secret:     "service-secret",
+				Policy:     "two_factor",
+				Scopes:     []string{"openid", "groups"},
+				GrantTypes: []string{"client_credentials"},
+				Audience:   []string{"https://api.example.com"},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	newRequest := func(audience string) *http.Request {
+		form := url.Values{"grant_type": {"client_credentials"}, "scope": {"groups"}, "audience": {audience}}
+
+		req := httptest.NewRequest(http.MethodPost, "https://auth.example.com/api/oidc/token", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth("service", "service-secret")
+
+		return req
+	}
+
+	ctx := context.Background()
+
+	accessRequest, err := provider.Fosite.NewAccessRequest(ctx, newRequest("https://api.example.com"), openid.NewDefaultSession())
+	require.NoError(t, err)
+
+	accessRequest.GrantScope("groups")
+	accessRequest.GrantAudience("https://api.example.com")
+
+	response, err := provider.Fosite.NewAccessResponse(ctx, accessRequest)
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, response.GetAccessToken())
+	assert.Nil(t, response.ToMap()["refresh_token"])
+	assert.InDelta(t, 300, response.ToMap()["expires_in"], 1)
+
+	_, err = provider.Fosite.NewAccessRequest(ctx, newRequest("https://other.example.com"), openid.NewDefaultSession())
+	assert.Error(t, err)
+}
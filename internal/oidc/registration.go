@@ -0,0 +1,186 @@
+package oidc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// ParseRegistrationRequest parses the metadata of a client registration request, a RegistrationError is returned when
+// it's not a JSON object.
+func ParseRegistrationRequest(body []byte) (request RegistrationRequest, err error) {
+	if err = json.Unmarshal(body, &request); err != nil {
+		return request, newRegistrationError(errCodeInvalidClientMetadata, "the client metadata is not a valid JSON object: %s", err)
+	}
+
+	return request, nil
+}
+
+// Validate validates the metadata of a client registration request and sets the default values of the metadata the
+// client didn't provide. The scopes are restricted to the ones of the dynamic registration configuration, a
+// RegistrationError is returned when the metadata is invalid.
+//
+// See https://datatracker.ietf.org/doc/html/rfc7591#section-2
+func (r *RegistrationRequest) Validate(config *schema.OpenIDConnectDynamicRegistrationConfiguration) error {
+	if r.TokenEndpointAuthMethod == "" {
+		r.TokenEndpointAuthMethod = registrationTokenEndpointAuthMethods[0]
+	} else if !utils.IsStringInSlice(r.TokenEndpointAuthMethod, registrationTokenEndpointAuthMethods) {
+		return newRegistrationError(errCodeInvalidClientMetadata, "the token endpoint auth method '%s' isn't supported, must be one of: '%s'",
+			r.TokenEndpointAuthMethod, strings.Join(registrationTokenEndpointAuthMethods, "', '"))
+	}
+
+	if err := r.validateGrantTypes(); err != nil {
+		return err
+	}
+
+	if err := r.validateScope(config.Scopes); err != nil {
+		return err
+	}
+
+	if err := r.validateRedirectURIs(); err != nil {
+		return err
+	}
+
+	return r.validateLogoutURIs()
+}
+
+func (r *RegistrationRequest) validateGrantTypes() error {
+	if len(r.GrantTypes) == 0 {
+		r.GrantTypes = []string{"authorization_code"}
+	}
+
+	for _, grantType := range r.GrantTypes {
+		if !utils.IsStringInSlice(grantType, registrationGrantTypes) {
+			return newRegistrationError(errCodeInvalidClientMetadata, "the grant type '%s' isn't supported, must be one of: '%s'",
+				grantType, strings.Join(registrationGrantTypes, "', '"))
+		}
+	}
+
+	if utils.IsStringInSlice("refresh_token", r.GrantTypes) && !utils.IsStringInSlice("authorization_code", r.GrantTypes) {
+		return newRegistrationError(errCodeInvalidClientMetadata, "the 'refresh_token' grant type requires the 'authorization_code' grant type")
+	}
+
+	if len(r.ResponseTypes) == 0 && utils.IsStringInSlice("authorization_code", r.GrantTypes) {
+		r.ResponseTypes = []string{"code"}
+	}
+
+	// The response types must be consistent with the grant types, the code is exchanged with the authorization_code
+	// grant while the tokens are returned by the implicit grant.
+	for _, responseType := range r.ResponseTypes {
+		for _, value := range strings.Fields(responseType) {
+			grantType := "implicit"
+
+			switch value {
+			case "code":
+				grantType = "authorization_code"
+			case "token", "id_token":
+			default:
+				return newRegistrationError(errCodeInvalidClientMetadata, "the response type '%s' isn't supported", responseType)
+			}
+
+			if !utils.IsStringInSlice(grantType, r.GrantTypes) {
+				return newRegistrationError(errCodeInvalidClientMetadata, "the response type '%s' requires the '%s' grant type", responseType, grantType)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *RegistrationRequest) validateScope(allowed []string) error {
+	if r.Scope == "" {
+		r.Scope = strings.Join(allowed, " ")
+		return nil
+	}
+
+	for _, scope := range strings.Fields(r.Scope) {
+		if !utils.IsStringInSlice(scope, allowed) {
+			return newRegistrationError(errCodeInvalidClientMetadata, "the scope '%s' isn't allowed, must be one of: '%s'",
+				scope, strings.Join(allowed, "', '"))
+		}
+	}
+
+	if !utils.IsStringInSlice("openid", strings.Fields(r.Scope)) {
+		r.Scope = "openid " + r.Scope
+	}
+
+	return nil
+}
+
+func (r *RegistrationRequest) validateRedirectURIs() error {
+	// The redirect URIs are only required by the grants redirecting the users to the client.
+	if len(r.RedirectURIs) == 0 && (utils.IsStringInSlice("authorization_code", r.GrantTypes) || utils.IsStringInSlice("implicit", r.GrantTypes)) {
+		return newRegistrationError(errCodeInvalidRedirectURI, "at least one redirect URI is required by the grant types")
+	}
+
+	for _, uri := range r.RedirectURIs {
+		parsedURI, err := url.Parse(uri)
+		if err != nil || !isRegistrationURI(parsedURI) || parsedURI.Fragment != "" {
+			return newRegistrationError(errCodeInvalidRedirectURI, "the redirect URI '%s' must be an absolute http or https URL without a fragment", uri)
+		}
+	}
+
+	return nil
+}
+
+func (r *RegistrationRequest) validateLogoutURIs() error {
+	uris := append([]string{}, r.PostLogoutRedirectURIs...)
+
+	if r.BackChannelLogoutURI != "" {
+		uris = append(uris, r.BackChannelLogoutURI)
+	}
+
+	if r.FrontChannelLogoutURI != "" {
+		uris = append(uris, r.FrontChannelLogoutURI)
+	}
+
+	for _, uri := range uris {
+		parsedURI, err := url.Parse(uri)
+		if err != nil || !isRegistrationURI(parsedURI) {
+			return newRegistrationError(errCodeInvalidClientMetadata, "the logout URI '%s' must be an absolute http or https URL", uri)
+		}
+	}
+
+	return nil
+}
+
+// Client returns the client saved in the storage from a validated registration request, the caller sets who registered
+// it and when.
+func (r RegistrationRequest) Client(id, secretHash, policy string) models.OIDCClient {
+	description := r.ClientName
+	if description == "" {
+		description = id
+	}
+
+	return models.OIDCClient{
+		ID:                     id,
+		Description:            description,
+		SecretHash:             secretHash,
+		Policy:                 policy,
+		RedirectURIs:           r.RedirectURIs,
+		GrantTypes:             r.GrantTypes,
+		ResponseTypes:          r.ResponseTypes,
+		Scopes:                 strings.Fields(r.Scope),
+		PostLogoutRedirectURIs: r.PostLogoutRedirectURIs,
+		BackChannelLogoutURI:   r.BackChannelLogoutURI,
+		FrontChannelLogoutURI:  r.FrontChannelLogoutURI,
+	}
+}
+
+func isRegistrationURI(uri *url.URL) bool {
+	return uri.IsAbs() && uri.Host != "" && (uri.Scheme == "https" || uri.Scheme == "http")
+}
+
+func newRegistrationError(code, format string, args ...interface{}) *RegistrationError {
+	return &RegistrationError{Code: code, Description: fmt.Sprintf(format, args...)}
+}
+
+// Error returns the description of the registration error.
+func (e *RegistrationError) Error() string {
+	return e.Description
+}
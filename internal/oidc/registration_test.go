@@ -0,0 +1,133 @@
+package oidc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+var testRegistrationConfiguration = &schema.OpenIDConnectDynamicRegistrationConfiguration{
+	Policy: "two_factor",
+	Scopes: []string{"openid", "profile", "groups"},
+}
+
+func TestRegistrationRequest_ShouldSetDefaultValues(t *testing.T) {
+	request := RegistrationRequest{RedirectURIs: []string{"https://app.example.com/callback"}}
+
+	require.NoError(t, request.Validate(testRegistrationConfiguration))
+
+	assert.Equal(t, "client_secret_basic", request.TokenEndpointAuthMethod)
+	assert.Equal(t, []string{"authorization_code"}, request.GrantTypes)
+	assert.Equal(t, []string{"code"}, request.ResponseTypes)
+	assert.Equal(t, "openid profile groups", request.Scope)
+
+	client := request.Client("id", "hash", "two_factor")
+
+	assert.Equal(t, "id", client.Description)
+	assert.Equal(t, []string{"openid", "profile", "groups"}, client.Scopes)
+}
+
+func TestRegistrationRequest_ShouldAddOpenIDScope(t *testing.T) {
+	request := RegistrationRequest{
+		RedirectURIs: []string{"https://app.example.com/callback"},
+		ClientName:   "My App",
+		Scope:        "groups",
+	}
+
+	require.NoError(t, request.Validate(testRegistrationConfiguration))
+
+	assert.Equal(t, "openid groups", request.Scope)
+	assert.Equal(t, "My App", request.Client("id", "hash", "two_factor").Description)
+}
+
+func TestRegistrationRequest_ShouldAcceptClientCredentialsWithoutRedirectURIs(t *testing.T) {
+	request := RegistrationRequest{GrantTypes: []string{"client_credentials"}, ResponseTypes: []string{"token"}}
+
+	err := request.Validate(testRegistrationConfiguration)
+
+	assert.EqualError(t, err, "the response type 'token' requires the 'implicit' grant type")
+
+	request = RegistrationRequest{GrantTypes: []string{"client_credentials"}}
+
+	assert.NoError(t, request.Validate(testRegistrationConfiguration))
+	assert.Empty(t, request.ResponseTypes)
+}
+
+func TestRegistrationRequest_ShouldRejectInvalidMetadata(t *testing.T) {
+	testCases := []struct {
+		name    string
+		request RegistrationRequest
+		code    string
+		err     string
+	}{
+		{
+			"ShouldRejectPublicClient",
+			RegistrationRequest{TokenEndpointAuthMethod: "none"},
+			errCodeInvalidClientMetadata,
+			"the token endpoint auth method 'none' isn't supported, must be one of: 'client_secret_basic', 'client_secret_post'",
+		},
+		{
+			"ShouldRejectPasswordGrant",
+			RegistrationRequest{GrantTypes: []string{"password"}},
+			errCodeInvalidClientMetadata,
+			"the grant type 'password' isn't supported, must be one of: 'authorization_code', 'implicit', 'refresh_token', 'client_credentials'",
+		},
+		{
+			"ShouldRejectRefreshTokenGrantAlone",
+			RegistrationRequest{GrantTypes: []string{"refresh_token"}},
+			errCodeInvalidClientMetadata,
+			"the 'refresh_token' grant type requires the 'authorization_code' grant type",
+		},
+		{
+			"ShouldRejectUnknownResponseType",
+			RegistrationRequest{ResponseTypes: []string{"code device"}},
+			errCodeInvalidClientMetadata,
+			"the response type 'code device' isn't supported",
+		},
+		{
+			"ShouldRejectScopeNotAllowed",
+			RegistrationRequest{Scope: "openid email"},
+			errCodeInvalidClientMetadata,
+			"the scope 'email' isn't allowed, must be one of: 'openid', 'profile', 'groups'",
+		},
+		{
+			"ShouldRejectMissingRedirectURIs",
+			RegistrationRequest{},
+			errCodeInvalidRedirectURI,
+			"at least one redirect URI is required by the grant types",
+		},
+		{
+			"ShouldRejectRedirectURIWithFragment",
+			RegistrationRequest{RedirectURIs: []string{"https://app.example.com/callback#fragment"}},
+			errCodeInvalidRedirectURI,
+			"the redirect URI 'https://app.example.com/callback#fragment' must be an absolute http or https URL without a fragment",
+		},
+		{
+			"ShouldRejectRelativeRedirectURI",
+			RegistrationRequest{RedirectURIs: []string{"/callback"}},
+			errCodeInvalidRedirectURI,
+			"the redirect URI '/callback' must be an absolute http or https URL without a fragment",
+		},
+		{
+			"ShouldRejectInvalidLogoutURI",
+			RegistrationRequest{RedirectURIs: []string{"https://app.example.com/callback"}, BackChannelLogoutURI: "ftp://app.example.com/logout"},
+			errCodeInvalidClientMetadata,
+			"the logout URI 'ftp://app.example.com/logout' must be an absolute http or https URL",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.request.Validate(testRegistrationConfiguration)
+
+			var registrationErr *RegistrationError
+
+			require.ErrorAs(t, err, &registrationErr)
+			assert.Equal(t, tc.code, registrationErr.Code)
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package oidc
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/ory/fosite"
@@ -11,11 +12,14 @@ import (
 	"github.com/authelia/authelia/internal/authorization"
 	"github.com/authelia/authelia/internal/configuration/schema"
 	"github.com/authelia/authelia/internal/logging"
+	"github.com/authelia/authelia/internal/models"
 )
 
 // NewOpenIDConnectStore returns a new OpenIDConnectStore using the provided schema.OpenIDConnectConfiguration.
 func NewOpenIDConnectStore(configuration *schema.OpenIDConnectConfiguration) (store *OpenIDConnectStore, err error) {
 	store = &OpenIDConnectStore{
+		lock:       &sync.RWMutex{},
+		registered: map[string]*InternalClient{},
 		memory: &storage.MemoryStore{
 			IDSessions:             map[string]fosite.Requester{},
 			Users:                  map[string]storage.MemoryUserRelation{},
@@ -28,16 +32,52 @@ func NewOpenIDConnectStore(configuration *schema.OpenIDConnectConfiguration) (st
 		},
 	}
 
-	store.clients = make(map[string]*InternalClient)
+	store.SetClients(configuration.Clients)
 
-	for _, client := range configuration.Clients {
+	return store, nil
+}
+
+// SetClients replaces the clients of the store.
+func (s *OpenIDConnectStore) SetClients(configurations []schema.OpenIDConnectClientConfiguration) {
+	clients := make(map[string]*InternalClient)
+
+	for _, client := range configurations {
 		policy := authorization.PolicyToLevel(client.Policy)
 		logging.Logger().Debugf("registering client %s with policy %s (%v)", client.ID, client.Policy, policy)
 
-		store.clients[client.ID] = NewClient(client)
+		clients[client.ID] = NewClient(client)
 	}
 
-	return store, nil
+	s.lock.Lock()
+	s.clients = clients
+	s.lock.Unlock()
+}
+
+// SetRegisteredClients replaces the clients registered with the dynamic registration endpoint.
+func (s *OpenIDConnectStore) SetRegisteredClients(registered []models.OIDCClient) {
+	clients := make(map[string]*InternalClient, len(registered))
+
+	for _, client := range registered {
+		clients[client.ID] = NewRegisteredClient(client)
+	}
+
+	s.lock.Lock()
+	s.registered = clients
+	s.lock.Unlock()
+}
+
+// AddRegisteredClient adds a client registered with the dynamic registration endpoint.
+func (s *OpenIDConnectStore) AddRegisteredClient(client models.OIDCClient) {
+	s.lock.Lock()
+	s.registered[client.ID] = NewRegisteredClient(client)
+	s.lock.Unlock()
+}
+
+// DeleteRegisteredClient deletes a client registered with the dynamic registration endpoint.
+func (s *OpenIDConnectStore) DeleteRegisteredClient(id string) {
+	s.lock.Lock()
+	delete(s.registered, id)
+	s.lock.Unlock()
 }
 
 // GetClientPolicy retrieves the policy from the client with the matching provided id.
@@ -52,7 +92,15 @@ func (s OpenIDConnectStore) GetClientPolicy(id string) (level authorization.Leve
 
 // GetInternalClient returns a fosite.Client asserted as an InternalClient matching the provided id.
 func (s OpenIDConnectStore) GetInternalClient(id string) (client *InternalClient, err error) {
+	s.lock.RLock()
 	client, ok := s.clients[id]
+
+	// The clients of the configuration take precedence over the registered ones.
+	if !ok {
+		client, ok = s.registered[id]
+	}
+	s.lock.RUnlock()
+
 	if !ok {
 		return nil, fosite.ErrNotFound
 	}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/authelia/authelia/internal/authorization"
 	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/models"
 )
 
 func TestOpenIDConnectStore_GetClientPolicy(t *testing.T) {
@@ -140,3 +141,64 @@ func TestOpenIDConnectStore_IsValidClientID(t *testing.T) {
 	assert.True(t, validClient)
 	assert.False(t, invalidClient)
 }
+
+func TestOpenIDConnectStore_SetClients(t *testing.T) {
+	s, err := NewOpenIDConnectStore(&schema.OpenIDConnectConfiguration{
+		IssuerPrivateKey: exampleIssuerPrivateKey,
+		Clients: []schema.OpenIDConnectClientConfiguration{
+			{
+				ID:     "myclient",
+				Policy: "one_factor",
+				Secret: "mysecret",
+			},
+		},
+	})
+
+	require.NoError(t, err)
+
+	s.SetClients([]schema.OpenIDConnectClientConfiguration{
+		{
+			ID:     "mynewclient",
+			Policy: "two_factor",
+			Secret: "mysecret",
+		},
+	})
+
+	assert.False(t, s.IsValidClientID("myclient"))
+	assert.True(t, s.IsValidClientID("mynewclient"))
+	assert.Equal(t, authorization.TwoFactor, s.GetClientPolicy("mynewclient"))
+}
+
+func TestOpenIDConnectStore_ShouldGetRegisteredClients(t *testing.T) {
+	s, err := NewOpenIDConnectStore(&schema.OpenIDConnectConfiguration{
+		IssuerPrivateKey: exampleIssuerPrivateKey,
+		Clients: []schema.OpenIDConnectClientConfiguration{
+			{ID: "myclient", Policy: "one_factor", Secret: "mysecret"},
+		},
+	})
+	require.NoError(t, err)
+
+	s.SetRegisteredClients([]models.OIDCClient{
+		{ID: "registered", Description: "Registered", Policy: "one_factor", SecretHash: "$argon2id$hash", RedirectURIs: []string{"https://app.example.com/callback"}},
+		{ID: "myclient", Policy: "two_factor"},
+	})
+	s.AddRegisteredClient(models.OIDCClient{ID: "added", Policy: "two_factor"})
+
+	client, err := s.GetInternalClient("registered")
+	require.NoError(t, err)
+	assert.Equal(t, "Registered", client.Description)
+	assert.Equal(t, []byte("$argon2id$hash"), client.GetHashedSecret())
+	assert.Equal(t, []string{"https://app.example.com/callback"}, client.GetRedirectURIs())
+	assert.Equal(t, authorization.OneFactor, client.Policy)
+	assert.False(t, client.IsPublic())
+
+	assert.Equal(t, authorization.OneFactor, s.GetClientPolicy("myclient"))
+	assert.True(t, s.IsValidClientID("added"))
+
+	s.SetClients(nil)
+	s.DeleteRegisteredClient("added")
+
+	assert.True(t, s.IsValidClientID("registered"))
+	assert.False(t, s.IsValidClientID("added"))
+	assert.Equal(t, authorization.TwoFactor, s.GetClientPolicy("myclient"))
+}
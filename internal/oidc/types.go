@@ -1,7 +1,8 @@
 package oidc
 
 import (
-	"crypto/rsa"
+	"crypto"
+	"sync"
 
 	"github.com/ory/fosite"
 	"github.com/ory/fosite/handler/openid"
@@ -27,8 +28,10 @@ type OpenIDConnectProvider struct {
 //	The long term plan is to have these methods interact with the Authelia storage and
 //	session providers where applicable.
 type OpenIDConnectStore struct {
-	clients map[string]*InternalClient
-	memory  *storage.MemoryStore
+	lock       *sync.RWMutex
+	clients    map[string]*InternalClient
+	registered map[string]*InternalClient
+	memory     *storage.MemoryStore
 }
 
 // InternalClient represents the client internally.
@@ -47,16 +50,61 @@ type InternalClient struct {
 
 	UserinfoSigningAlgorithm string `json:"userinfo_signed_response_alg,omitempty"`
 
+	BackChannelLogoutURI   string   `json:"backchannel_logout_uri,omitempty"`
+	FrontChannelLogoutURI  string   `json:"frontchannel_logout_uri,omitempty"`
+	PostLogoutRedirectURIs []string `json:"post_logout_redirect_uris,omitempty"`
+
 	Policy authorization.Level `json:"-"`
 }
 
-// KeyManager keeps track of all of the active/inactive rsa keys and provides them to services requiring them.
-// It additionally allows us to add keys for the purpose of key rotation in the future.
+// RegistrationRequest is the metadata of a client sent to the dynamic registration endpoint.
+//
+// See https://datatracker.ietf.org/doc/html/rfc7591#section-2
+type RegistrationRequest struct {
+	RedirectURIs            []string `json:"redirect_uris"`
+	ClientName              string   `json:"client_name,omitempty"`
+	TokenEndpointAuthMethod string   `json:"token_endpoint_auth_method"`
+	GrantTypes              []string `json:"grant_types"`
+	ResponseTypes           []string `json:"response_types"`
+	Scope                   string   `json:"scope"`
+
+	PostLogoutRedirectURIs []string `json:"post_logout_redirect_uris,omitempty"`
+	BackChannelLogoutURI   string   `json:"backchannel_logout_uri,omitempty"`
+	FrontChannelLogoutURI  string   `json:"frontchannel_logout_uri,omitempty"`
+}
+
+// RegistrationResponse is the response of the dynamic registration endpoint, the secret is only returned once.
+//
+// See https://datatracker.ietf.org/doc/html/rfc7591#section-3.2.1
+type RegistrationResponse struct {
+	ClientID              string `json:"client_id"`
+	ClientSecret          string `json:"client_secret"`
+	ClientIDIssuedAt      int64  `json:"client_id_issued_at"`
+	ClientSecretExpiresAt int64  `json:"client_secret_expires_at"`
+
+	RegistrationRequest
+}
+
+// RegistrationError is the error returned by the dynamic registration endpoint when the metadata is invalid.
+//
+// See https://datatracker.ietf.org/doc/html/rfc7591#section-3.2.2
+type RegistrationError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+// KeyManager keeps track of all of the active/inactive keys and provides them to services requiring them.
+// The active key can be rotated at any time, the inactive keys are still published so the tokens signed by them can
+// be verified.
 type KeyManager struct {
+	mutex sync.RWMutex
+
 	activeKeyID string
-	keys        map[string]*rsa.PrivateKey
+	keys        map[string]crypto.Signer
 	keySet      *jose.JSONWebKeySet
-	strategy    *RS256JWTStrategy
+	strategy    *JWTStrategy
+
+	activeKeyHook func(keyID string)
 }
 
 // AutheliaHasher implements the fosite.Hasher interface without an actual hashing algo.
@@ -68,6 +116,10 @@ type ConsentGetResponseBody struct {
 	ClientDescription string     `json:"client_description"`
 	Scopes            []Scope    `json:"scopes"`
 	Audience          []Audience `json:"audience"`
+
+	// PreAuthorized is true when the user remembered their consent to the client and it covers the requested scopes
+	// and audience, the consent is given again without asking the user.
+	PreAuthorized bool `json:"pre_authorized"`
 }
 
 // Scope represents the scope information.
@@ -93,21 +145,31 @@ type WellKnownConfiguration struct {
 	TokenEndpoint         string `json:"token_endpoint"`
 	RevocationEndpoint    string `json:"revocation_endpoint"`
 	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	EndSessionEndpoint    string `json:"end_session_endpoint"`
+	RegistrationEndpoint  string `json:"registration_endpoint,omitempty"`
 
 	Algorithms         []string `json:"id_token_signing_alg_values_supported"`
 	UserinfoAlgorithms []string `json:"userinfo_signing_alg_values_supported"`
 
-	SubjectTypesSupported  []string `json:"subject_types_supported"`
-	ResponseTypesSupported []string `json:"response_types_supported"`
-	ResponseModesSupported []string `json:"response_modes_supported"`
-	ScopesSupported        []string `json:"scopes_supported"`
-	ClaimsSupported        []string `json:"claims_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	ResponseModesSupported            []string `json:"response_modes_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
 
 	RequestURIParameterSupported       bool `json:"request_uri_parameter_supported"`
 	BackChannelLogoutSupported         bool `json:"backchannel_logout_supported"`
 	FrontChannelLogoutSupported        bool `json:"frontchannel_logout_supported"`
 	BackChannelLogoutSessionSupported  bool `json:"backchannel_logout_session_supported"`
 	FrontChannelLogoutSessionSupported bool `json:"frontchannel_logout_session_supported"`
+
+	// The lifespans of the tokens in seconds, they aren't part of the discovery specification but let the clients
+	// plan the refresh of the tokens.
+	AccessTokenLifespan  int64 `json:"access_token_lifespan"`
+	IDTokenLifespan      int64 `json:"id_token_lifespan"`
+	RefreshTokenLifespan int64 `json:"refresh_token_lifespan"`
 }
 
 // OpenIDSession holds OIDC Session information.
@@ -0,0 +1,5 @@
+package privacy
+
+// eventsPageSize is the number of events loaded at once from the storage, the events of a user are exported page by
+// page.
+const eventsPageSize = 100
@@ -0,0 +1,170 @@
+package privacy
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/authelia/authelia/internal/storage"
+)
+
+// NewExport exports the personal data stored about a user. The data is the same whether the user is still known to the
+// authentication backend or not, the details of the user held by the backend aren't exported.
+func NewExport(storageProvider storage.Provider, username string, now time.Time) (export *Export, err error) {
+	export = &Export{
+		Username:            username,
+		ExportedAt:          now,
+		TOTPDevices:         []TOTPDevice{},
+		WebauthnCredentials: []WebauthnCredential{},
+		Events:              []Event{},
+		OIDCConsents:        []OIDCConsent{},
+		AccessGrants:        []AccessGrant{},
+	}
+
+	if export.Preferences.SecondFactorMethod, err = storageProvider.LoadPreferred2FAMethod(username); err != nil {
+		return nil, fmt.Errorf("unable to load the preferences: %w", err)
+	}
+
+	if err = export.loadDevices(storageProvider); err != nil {
+		return nil, err
+	}
+
+	if err = export.loadEvents(storageProvider); err != nil {
+		return nil, fmt.Errorf("unable to load the events: %w", err)
+	}
+
+	consents, err := storageProvider.LoadUserOIDCConsents(username, now)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the OpenID Connect consents: %w", err)
+	}
+
+	for _, consent := range consents {
+		export.OIDCConsents = append(export.OIDCConsents, OIDCConsent{
+			ClientID:  consent.ClientID,
+			Scopes:    consent.Scopes,
+			Audience:  consent.Audience,
+			CreatedAt: consent.CreatedAt,
+			ExpiresAt: consent.ExpiresAt,
+		})
+	}
+
+	grants, err := storageProvider.LoadUserAccessGrants(username, now)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the access grants: %w", err)
+	}
+
+	for _, grant := range grants {
+		export.AccessGrants = append(export.AccessGrants, AccessGrant{
+			ID:          grant.ID,
+			TargetURL:   grant.TargetURL,
+			Description: grant.Description,
+			CreatedAt:   grant.CreatedAt,
+			ExpiresAt:   grant.ExpiresAt,
+		})
+	}
+
+	revokedAt, err := storageProvider.LoadSessionsRevocation(username)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the sessions revocation: %w", err)
+	}
+
+	export.SessionsRevokedAt = optionalTime(revokedAt)
+
+	return export, nil
+}
+
+func (e *Export) loadDevices(storageProvider storage.Provider) (err error) {
+	devices, err := storageProvider.LoadTOTPDevices(e.Username)
+	if err != nil {
+		return fmt.Errorf("unable to load the TOTP devices: %w", err)
+	}
+
+	for _, device := range devices {
+		e.TOTPDevices = append(e.TOTPDevices, TOTPDevice{
+			ID:          device.ID,
+			Description: device.Description,
+			CreatedAt:   optionalTime(device.CreatedAt),
+			LastUsedAt:  optionalTime(device.LastUsedAt),
+		})
+	}
+
+	if _, _, err = storageProvider.LoadU2FDeviceHandle(e.Username); err == nil {
+		e.U2FDevice = true
+	} else if err != storage.ErrNoU2FDeviceHandle {
+		return fmt.Errorf("unable to load the U2F device: %w", err)
+	}
+
+	credentials, err := storageProvider.LoadWebauthnCredentials(e.Username)
+	if err != nil {
+		return fmt.Errorf("unable to load the WebAuthn credentials: %w", err)
+	}
+
+	for _, credential := range credentials {
+		e.WebauthnCredentials = append(e.WebauthnCredentials, WebauthnCredential{
+			ID:          base64.RawURLEncoding.EncodeToString(credential.ID),
+			Description: credential.Description,
+			CreatedAt:   credential.CreatedAt,
+		})
+	}
+
+	if e.BackupCodes, err = storageProvider.CountBackupCodes(e.Username); err != nil {
+		return fmt.Errorf("unable to count the backup codes: %w", err)
+	}
+
+	enrollment, err := storageProvider.LoadPushEnrollment(e.Username)
+
+	switch {
+	case err == nil:
+		e.PushEnrollment = &PushEnrollment{
+			Provider:  enrollment.Provider,
+			Status:    enrollment.Status,
+			Devices:   enrollment.Devices,
+			CheckedAt: enrollment.CheckedAt,
+		}
+	case err != storage.ErrNoPushEnrollment:
+		return fmt.Errorf("unable to load the push enrollment: %w", err)
+	}
+
+	return nil
+}
+
+func (e *Export) loadEvents(storageProvider storage.Provider) error {
+	for offset := 0; ; offset += eventsPageSize {
+		events, err := storageProvider.LoadUserEvents(e.Username, "", eventsPageSize, offset)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			e.Events = append(e.Events, Event{
+				Type:       event.Type,
+				Successful: event.Successful,
+				Time:       event.Time,
+				RemoteIP:   event.RemoteIP,
+				UserAgent:  event.UserAgent,
+			})
+		}
+
+		if len(events) < eventsPageSize {
+			return nil
+		}
+	}
+}
+
+// Write writes the export as an indented JSON document.
+func (e *Export) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(e)
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
@@ -0,0 +1,76 @@
+package privacy
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/pkg/autheliatest"
+)
+
+func TestShouldExportPersonalData(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	createdAt := time.Unix(1500000000, 0)
+
+	storageProvider := autheliatest.NewMemoryStorageProvider()
+	require.NoError(t, storageProvider.SavePreferred2FAMethod("john", "webauthn"))
+	require.NoError(t, storageProvider.SaveTOTPDevice(models.TOTPDevice{ID: "phone", Username: "john", Description: "Phone", Secret: "SECRET", CreatedAt: createdAt}))
+	require.NoError(t, storageProvider.SaveWebauthnCredential(models.WebauthnCredential{ID: []byte{0x01, 0x02}, Username: "john", Description: "Key", PublicKey: []byte("key"), CreatedAt: createdAt}))
+	require.NoError(t, storageProvider.SaveBackupCodes("john", []string{"hash1", "hash2"}, createdAt))
+	require.NoError(t, storageProvider.SavePushEnrollment(models.PushEnrollment{Username: "john", Provider: "duo", Status: "enrolled", Devices: 1, CheckedAt: createdAt}))
+	require.NoError(t, storageProvider.SaveOIDCConsent(models.OIDCConsent{Username: "john", ClientID: "app", Scopes: []string{"openid"}, CreatedAt: createdAt, ExpiresAt: now.Add(time.Hour)}))
+	require.NoError(t, storageProvider.SaveAccessGrant(models.AccessGrant{ID: "grant", Username: "john", TargetURL: "https://app.example.com/", CreatedAt: createdAt, ExpiresAt: now.Add(time.Hour)}))
+	require.NoError(t, storageProvider.RevokeSessions("john", createdAt))
+	require.NoError(t, storageProvider.AppendAuthenticationLog(models.AuthenticationAttempt{Username: "john", Successful: true, Time: createdAt, RemoteIP: "192.168.1.10", Type: models.AuthenticationTypeFirstFactor}))
+	require.NoError(t, storageProvider.SaveTOTPDevice(models.TOTPDevice{ID: "other", Username: "harry", Description: "Other"}))
+
+	for i := 1; i <= eventsPageSize; i++ {
+		require.NoError(t, storageProvider.AppendUserEvent(models.UserEvent{Username: "john", Type: "password_reset", Successful: true, Time: createdAt.Add(time.Duration(i) * time.Second)}))
+	}
+
+	export, err := NewExport(storageProvider, "john", now)
+	require.NoError(t, err)
+
+	assert.Equal(t, "john", export.Username)
+	assert.Equal(t, now, export.ExportedAt)
+	assert.Equal(t, Preferences{SecondFactorMethod: "webauthn"}, export.Preferences)
+	assert.Equal(t, []TOTPDevice{{ID: "phone", Description: "Phone", CreatedAt: &createdAt}}, export.TOTPDevices)
+	assert.False(t, export.U2FDevice)
+	assert.Equal(t, []WebauthnCredential{{ID: "AQI", Description: "Key", CreatedAt: createdAt}}, export.WebauthnCredentials)
+	assert.Equal(t, 2, export.BackupCodes)
+	assert.Equal(t, &PushEnrollment{Provider: "duo", Status: "enrolled", Devices: 1, CheckedAt: createdAt}, export.PushEnrollment)
+	assert.Equal(t, []OIDCConsent{{ClientID: "app", Scopes: []string{"openid"}, CreatedAt: createdAt, ExpiresAt: now.Add(time.Hour)}}, export.OIDCConsents)
+	assert.Equal(t, []AccessGrant{{ID: "grant", TargetURL: "https://app.example.com/", CreatedAt: createdAt, ExpiresAt: now.Add(time.Hour)}}, export.AccessGrants)
+	assert.Equal(t, &createdAt, export.SessionsRevokedAt)
+
+	require.Len(t, export.Events, eventsPageSize+1)
+	assert.Equal(t, "password_reset", export.Events[0].Type)
+	assert.Equal(t, Event{Type: models.AuthenticationTypeFirstFactor, Successful: true, Time: createdAt, RemoteIP: "192.168.1.10"}, export.Events[eventsPageSize])
+}
+
+func TestShouldExportUnknownUser(t *testing.T) {
+	export, err := NewExport(autheliatest.NewMemoryStorageProvider(), "harry", time.Unix(1600000000, 0).UTC())
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, export.Write(buf))
+
+	assert.JSONEq(t, `{
+		"username": "harry",
+		"exported_at": "2020-09-13T12:26:40Z",
+		"preferences": {"second_factor_method": ""},
+		"totp_devices": [],
+		"u2f_device": false,
+		"webauthn_credentials": [],
+		"backup_codes": 0,
+		"push_enrollment": null,
+		"events": [],
+		"oidc_consents": [],
+		"access_grants": [],
+		"sessions_revoked_at": null
+	}`, buf.String())
+}
@@ -0,0 +1,84 @@
+package privacy
+
+import (
+	"time"
+)
+
+// Export is the personal data stored about a user, exported in a machine-readable form when the user requests it. The
+// secrets, i.e. the TOTP secrets, the public keys of the security keys and the hashes of the backup codes, are left out.
+type Export struct {
+	Username   string    `json:"username"`
+	ExportedAt time.Time `json:"exported_at"`
+
+	Preferences Preferences `json:"preferences"`
+
+	TOTPDevices         []TOTPDevice         `json:"totp_devices"`
+	U2FDevice           bool                 `json:"u2f_device"`
+	WebauthnCredentials []WebauthnCredential `json:"webauthn_credentials"`
+	BackupCodes         int                  `json:"backup_codes"`
+	PushEnrollment      *PushEnrollment      `json:"push_enrollment"`
+
+	// The authentication attempts and the other security events of the user, the most recent first.
+	Events []Event `json:"events"`
+
+	OIDCConsents []OIDCConsent `json:"oidc_consents"`
+	AccessGrants []AccessGrant `json:"access_grants"`
+
+	// The time the sessions of the user were last revoked, nil when they have never been revoked.
+	SessionsRevokedAt *time.Time `json:"sessions_revoked_at"`
+}
+
+// Preferences are the preferences of an exported user.
+type Preferences struct {
+	SecondFactorMethod string `json:"second_factor_method"`
+}
+
+// TOTPDevice is a TOTP device of an exported user, the times are nil when they're unknown.
+type TOTPDevice struct {
+	ID          string     `json:"id"`
+	Description string     `json:"description"`
+	CreatedAt   *time.Time `json:"created_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+}
+
+// WebauthnCredential is a WebAuthn credential of an exported user, its ID is base64url encoded.
+type WebauthnCredential struct {
+	ID          string    `json:"id"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// PushEnrollment is the push enrollment of an exported user.
+type PushEnrollment struct {
+	Provider  string    `json:"provider"`
+	Status    string    `json:"status"`
+	Devices   int       `json:"devices"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Event is an authentication attempt or another security event of an exported user.
+type Event struct {
+	Type       string    `json:"type"`
+	Successful bool      `json:"successful"`
+	Time       time.Time `json:"time"`
+	RemoteIP   string    `json:"remote_ip"`
+	UserAgent  string    `json:"user_agent"`
+}
+
+// OIDCConsent is a consent an exported user remembered for an OpenID Connect client.
+type OIDCConsent struct {
+	ClientID  string    `json:"client_id"`
+	Scopes    []string  `json:"scopes"`
+	Audience  []string  `json:"audience"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// AccessGrant is an unexpired access grant created by an exported user.
+type AccessGrant struct {
+	ID          string    `json:"id"`
+	TargetURL   string    `json:"target_url"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
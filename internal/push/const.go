@@ -0,0 +1,21 @@
+package push
+
+// ProviderNameDuo is the name of the Duo provider.
+const ProviderNameDuo = "duo"
+
+const (
+	duoPreAuthPath    = "/auth/v2/preauth"
+	duoAuthPath       = "/auth/v2/auth"
+	duoAuthStatusPath = "/auth/v2/auth_status"
+)
+
+const (
+	duoStatOK = "OK"
+
+	duoPreAuthResultAuth   = "auth"
+	duoPreAuthResultAllow  = "allow"
+	duoPreAuthResultEnroll = "enroll"
+	duoPreAuthResultDeny   = "deny"
+
+	duoCapabilityPush = "push"
+)
@@ -0,0 +1,165 @@
+package push
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	duoapi "github.com/duosecurity/duo_api_golang"
+
+	"github.com/authelia/authelia/internal/models"
+)
+
+// DuoCaller is the interface of the Duo API client making the signed calls, it's satisfied by *duoapi.DuoApi.
+type DuoCaller interface {
+	SignedCall(method string, uri string, params url.Values, options ...duoapi.DuoApiOption) (*http.Response, []byte, error)
+}
+
+// DuoProvider is a Provider sending the push notifications with the Duo Auth API.
+type DuoProvider struct {
+	api DuoCaller
+}
+
+// NewDuoProvider creates a new DuoProvider making the calls with the given client.
+func NewDuoProvider(api DuoCaller) *DuoProvider {
+	return &DuoProvider{api: api}
+}
+
+// duoResponse is the envelope of the responses of the Duo Auth API.
+type duoResponse struct {
+	Stat          string          `json:"stat"`
+	Code          int             `json:"code"`
+	Message       string          `json:"message"`
+	MessageDetail string          `json:"message_detail"`
+	Response      json.RawMessage `json:"response"`
+}
+
+type duoPreAuthResponse struct {
+	Result          string `json:"result"`
+	EnrollPortalURL string `json:"enroll_portal_url"`
+	Devices         []struct {
+		Capabilities []string `json:"capabilities"`
+	} `json:"devices"`
+}
+
+type duoAuthResponse struct {
+	TransactionID string `json:"txid"`
+}
+
+type duoAuthStatusResponse struct {
+	Result string `json:"result"`
+}
+
+// Name returns the name of the provider.
+func (p *DuoProvider) Name() string {
+	return ProviderNameDuo
+}
+
+// Enrollment returns the enrollment state of the user with Duo, the users Duo lets authenticate without a second
+// factor are considered enrolled.
+func (p *DuoProvider) Enrollment(username, remoteIP string) (enrollment *Enrollment, err error) {
+	values := url.Values{}
+	values.Set("username", username)
+	values.Set("ipaddr", remoteIP)
+
+	var response duoPreAuthResponse
+
+	if err = p.call(http.MethodPost, duoPreAuthPath, values, &response); err != nil {
+		return nil, err
+	}
+
+	enrollment = &Enrollment{}
+
+	switch response.Result {
+	case duoPreAuthResultAuth, duoPreAuthResultAllow:
+		enrollment.Status = models.PushEnrollmentEnrolled
+	case duoPreAuthResultEnroll:
+		enrollment.Status = models.PushEnrollmentNotEnrolled
+		enrollment.EnrollURL = response.EnrollPortalURL
+	case duoPreAuthResultDeny:
+		enrollment.Status = models.PushEnrollmentDenied
+	default:
+		return nil, fmt.Errorf("unknown preauth result '%s'", response.Result)
+	}
+
+	for _, device := range response.Devices {
+		for _, capability := range device.Capabilities {
+			if capability == duoCapabilityPush {
+				enrollment.Devices++
+				break
+			}
+		}
+	}
+
+	return enrollment, nil
+}
+
+// Start sends a push notification to the devices of the user without waiting for the user to approve it.
+func (p *DuoProvider) Start(request Request) (transactionID string, err error) {
+	values := url.Values{}
+	values.Set("username", request.Username)
+	values.Set("ipaddr", request.RemoteIP)
+	values.Set("factor", "push")
+	values.Set("device", "auto")
+	values.Set("async", "1")
+
+	if request.TargetURL != "" {
+		values.Set("pushinfo", fmt.Sprintf("target%%20url=%s", request.TargetURL))
+	}
+
+	var response duoAuthResponse
+
+	if err = p.call(http.MethodPost, duoAuthPath, values, &response); err != nil {
+		return "", err
+	}
+
+	if response.TransactionID == "" {
+		return "", fmt.Errorf("no transaction ID in the auth response")
+	}
+
+	return response.TransactionID, nil
+}
+
+// Status returns the result of the transaction, the transactions Duo neither allowed nor denied are waiting.
+func (p *DuoProvider) Status(transactionID string) (result Result, err error) {
+	values := url.Values{}
+	values.Set("txid", transactionID)
+
+	var response duoAuthStatusResponse
+
+	if err = p.call(http.MethodGet, duoAuthStatusPath, values, &response); err != nil {
+		return "", err
+	}
+
+	switch Result(response.Result) {
+	case ResultAllow, ResultDeny:
+		return Result(response.Result), nil
+	default:
+		return ResultWaiting, nil
+	}
+}
+
+// call makes a signed call to the Duo Auth API and decodes the response of a successful call into v.
+func (p *DuoProvider) call(method, path string, values url.Values, v interface{}) error {
+	_, body, err := p.api.SignedCall(method, path, values, duoapi.UseTimeout)
+	if err != nil {
+		return err
+	}
+
+	var response duoResponse
+
+	if err = json.Unmarshal(body, &response); err != nil {
+		return fmt.Errorf("unable to decode the response of %s: %w", path, err)
+	}
+
+	if response.Stat != duoStatOK {
+		return fmt.Errorf("%s failed: %s (%s), error code %d", path, response.Message, response.MessageDetail, response.Code)
+	}
+
+	if err = json.Unmarshal(response.Response, v); err != nil {
+		return fmt.Errorf("unable to decode the response of %s: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package push
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	duoapi "github.com/duosecurity/duo_api_golang"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/models"
+)
+
+type duoCall struct {
+	method string
+	uri    string
+	params url.Values
+}
+
+// fakeDuoCaller records the calls and replies with the configured body.
+type fakeDuoCaller struct {
+	calls []duoCall
+	body  string
+	err   error
+}
+
+func (c *fakeDuoCaller) SignedCall(method string, uri string, params url.Values, _ ...duoapi.DuoApiOption) (*http.Response, []byte, error) {
+	c.calls = append(c.calls, duoCall{method: method, uri: uri, params: params})
+
+	return nil, []byte(c.body), c.err
+}
+
+func TestShouldReturnEnrolledUser(t *testing.T) {
+	caller := &fakeDuoCaller{body: `{"stat":"OK","response":{"result":"auth","devices":[` +
+		`{"capabilities":["auto","push","sms"]},{"capabilities":["phone"]},{"capabilities":["push"]}]}}`}
+
+	enrollment, err := NewDuoProvider(caller).Enrollment("john", "192.168.1.10")
+	require.NoError(t, err)
+
+	assert.Equal(t, &Enrollment{Status: models.PushEnrollmentEnrolled, Devices: 2}, enrollment)
+	require.Len(t, caller.calls, 1)
+	assert.Equal(t, duoCall{method: "POST", uri: "/auth/v2/preauth", params: url.Values{
+		"username": {"john"},
+		"ipaddr":   {"192.168.1.10"},
+	}}, caller.calls[0])
+}
+
+func TestShouldReturnUserNotEnrolledWithEnrollURL(t *testing.T) {
+	caller := &fakeDuoCaller{body: `{"stat":"OK","response":{"result":"enroll","enroll_portal_url":"https://api-123.duosecurity.com/portal?code=abc"}}`}
+
+	enrollment, err := NewDuoProvider(caller).Enrollment("john", "192.168.1.10")
+	require.NoError(t, err)
+
+	assert.Equal(t, &Enrollment{
+		Status:    models.PushEnrollmentNotEnrolled,
+		EnrollURL: "https://api-123.duosecurity.com/portal?code=abc",
+	}, enrollment)
+}
+
+func TestShouldReturnDeniedUser(t *testing.T) {
+	caller := &fakeDuoCaller{body: `{"stat":"OK","response":{"result":"deny"}}`}
+
+	enrollment, err := NewDuoProvider(caller).Enrollment("john", "192.168.1.10")
+	require.NoError(t, err)
+
+	assert.Equal(t, models.PushEnrollmentDenied, enrollment.Status)
+}
+
+func TestShouldFailEnrollmentOnUnknownResult(t *testing.T) {
+	caller := &fakeDuoCaller{body: `{"stat":"OK","response":{"result":"maybe"}}`}
+
+	_, err := NewDuoProvider(caller).Enrollment("john", "192.168.1.10")
+	assert.EqualError(t, err, "unknown preauth result 'maybe'")
+}
+
+func TestShouldStartAsynchronousPush(t *testing.T) {
+	caller := &fakeDuoCaller{body: `{"stat":"OK","response":{"txid":"45f7c92b-f45f-4862-8545-e0f58e78075a"}}`}
+
+	transactionID, err := NewDuoProvider(caller).Start(Request{
+		Username:  "john",
+		RemoteIP:  "192.168.1.10",
+		TargetURL: "https://home.example.com",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "45f7c92b-f45f-4862-8545-e0f58e78075a", transactionID)
+	require.Len(t, caller.calls, 1)
+	assert.Equal(t, duoCall{method: "POST", uri: "/auth/v2/auth", params: url.Values{
+		"username": {"john"},
+		"ipaddr":   {"192.168.1.10"},
+		"factor":   {"push"},
+		"device":   {"auto"},
+		"async":    {"1"},
+		"pushinfo": {"target%20url=https://home.example.com"},
+	}}, caller.calls[0])
+}
+
+func TestShouldFailToStartPushWhenDuoFails(t *testing.T) {
+	caller := &fakeDuoCaller{body: `{"stat":"FAIL","code":40002,"message":"Invalid request parameters","message_detail":"username"}`}
+
+	_, err := NewDuoProvider(caller).Start(Request{Username: "john", RemoteIP: "192.168.1.10"})
+	assert.EqualError(t, err, "/auth/v2/auth failed: Invalid request parameters (username), error code 40002")
+
+	caller = &fakeDuoCaller{err: fmt.Errorf("connection refused")}
+
+	_, err = NewDuoProvider(caller).Start(Request{Username: "john", RemoteIP: "192.168.1.10"})
+	assert.EqualError(t, err, "connection refused")
+}
+
+func TestShouldReturnStatusOfTransaction(t *testing.T) {
+	testCases := []struct {
+		body     string
+		expected Result
+	}{
+		{`{"stat":"OK","response":{"result":"waiting","status":"pushed"}}`, ResultWaiting},
+		{`{"stat":"OK","response":{"result":"allow","status":"allow"}}`, ResultAllow},
+		{`{"stat":"OK","response":{"result":"deny","status":"timeout"}}`, ResultDeny},
+	}
+
+	for _, tc := range testCases {
+		caller := &fakeDuoCaller{body: tc.body}
+
+		result, err := NewDuoProvider(caller).Status("txid")
+		require.NoError(t, err)
+
+		assert.Equal(t, tc.expected, result)
+		assert.Equal(t, duoCall{method: "GET", uri: "/auth/v2/auth_status", params: url.Values{"txid": {"txid"}}}, caller.calls[0])
+	}
+}
@@ -0,0 +1,46 @@
+package push
+
+// Provider is an interface for sending push notifications to the mobile devices of the users so they approve their
+// second factor authentication. The approval is asynchronous, the result of a transaction is polled until the user
+// approved or denied it.
+type Provider interface {
+	// Name returns the name of the provider such as duo.
+	Name() string
+	// Enrollment returns the enrollment state of the user with the provider.
+	Enrollment(username, remoteIP string) (enrollment *Enrollment, err error)
+	// Start sends a push notification to the devices of the user and returns the ID of the transaction.
+	Start(request Request) (transactionID string, err error)
+	// Status returns the result of the transaction with the given ID.
+	Status(transactionID string) (result Result, err error)
+}
+
+// Request represents a push notification sent to the devices of a user.
+type Request struct {
+	Username  string
+	RemoteIP  string
+	TargetURL string
+}
+
+// Enrollment represents the enrollment state of a user with the provider.
+type Enrollment struct {
+	// The state of the enrollment, one of models.PushEnrollmentEnrolled, models.PushEnrollmentNotEnrolled and
+	// models.PushEnrollmentDenied.
+	Status string
+	// The number of devices of the user able to receive the push notifications.
+	Devices int
+	// The URL the user enrolls a device at, it's only set when the user is not enrolled.
+	EnrollURL string
+}
+
+// Result is the result of a push notification transaction.
+type Result string
+
+// Results of the push notification transactions.
+const (
+	// ResultWaiting is the result of the transactions the user neither approved nor denied yet.
+	ResultWaiting Result = "waiting"
+	// ResultAllow is the result of the transactions the user approved.
+	ResultAllow Result = "allow"
+	// ResultDeny is the result of the transactions the user denied or which timed out.
+	ResultDeny Result = "deny"
+)
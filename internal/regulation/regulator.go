@@ -16,62 +16,143 @@ func NewRegulator(configuration *schema.RegulationConfiguration, provider storag
 	regulator.clock = clock
 
 	if configuration != nil {
-		findTime, err := utils.ParseDurationString(configuration.FindTime)
-		if err != nil {
-			panic(err)
-		}
+		regulator.firstFactor = newThresholds(configuration.MaxRetries, configuration.FindTime, configuration.BanTime)
 
-		banTime, err := utils.ParseDurationString(configuration.BanTime)
-		if err != nil {
-			panic(err)
+		if configuration.SecondFactor != nil {
+			regulator.secondFactor = newThresholds(configuration.SecondFactor.MaxRetries,
+				configuration.SecondFactor.FindTime, configuration.SecondFactor.BanTime)
 		}
+	}
 
-		if findTime > banTime {
-			panic(fmt.Errorf("find_time cannot be greater than ban_time"))
-		}
+	return regulator
+}
 
-		// Set regulator enabled only if MaxRetries is not 0.
-		regulator.enabled = configuration.MaxRetries > 0
-		regulator.maxRetries = configuration.MaxRetries
-		regulator.findTime = findTime
-		regulator.banTime = banTime
+func newThresholds(maxRetries int, findTimeString, banTimeString string) thresholds {
+	findTime, err := utils.ParseDurationString(findTimeString)
+	if err != nil {
+		panic(err)
 	}
 
-	return regulator
+	banTime, err := utils.ParseDurationString(banTimeString)
+	if err != nil {
+		panic(err)
+	}
+
+	if findTime > banTime {
+		panic(fmt.Errorf("find_time cannot be greater than ban_time"))
+	}
+
+	return thresholds{
+		// Set regulator enabled only if MaxRetries is not 0.
+		enabled:    maxRetries > 0,
+		maxRetries: maxRetries,
+		findTime:   findTime,
+		banTime:    banTime,
+	}
 }
 
 // Mark mark an authentication attempt.
 // We split Mark and Regulate in order to avoid timing attacks.
 func (r *Regulator) Mark(username string, successful bool) error {
-	return r.storageProvider.AppendAuthenticationLog(models.AuthenticationAttempt{
+	return r.MarkAttempt(models.AuthenticationAttempt{
 		Username:   username,
 		Successful: successful,
-		Time:       r.clock.Now(),
 	})
 }
 
-// Regulate regulate the authentication attempts for a given user.
+// MarkAttempt mark an authentication attempt including the client information such as the remote IP and user agent.
+// The time of the attempt is always set by the regulator.
+func (r *Regulator) MarkAttempt(attempt models.AuthenticationAttempt) error {
+	attempt.Time = r.clock.Now()
+
+	return r.storageProvider.AppendAuthenticationLog(attempt)
+}
+
+// Regulate regulate the first factor authentication attempts for a given user.
 // This method returns ErrUserIsBanned if the user is banned along with the time until when
 // the user is banned.
 func (r *Regulator) Regulate(username string) (time.Time, error) {
+	return r.regulate(username, r.firstFactor, false)
+}
+
+// RegulateSecondFactor regulate the second factor authentication attempts for a given user, they are regulated
+// separately from the first factor attempts with their own thresholds.
+// This method returns ErrUserIsBanned if the user is banned along with the time until when
+// the user is banned.
+func (r *Regulator) RegulateSecondFactor(username string) (time.Time, error) {
+	return r.regulate(username, r.secondFactor, true)
+}
+
+func (r *Regulator) regulate(username string, t thresholds, secondFactor bool) (time.Time, error) {
 	// If there is regulation configuration, no regulation applies.
-	if !r.enabled {
+	if !t.enabled {
 		return time.Time{}, nil
 	}
 
-	now := r.clock.Now()
-
 	// TODO(c.michaud): make sure FindTime < BanTime.
-	attempts, err := r.storageProvider.LoadLatestAuthenticationLogs(username, now.Add(-r.banTime))
+	attempts, err := r.storageProvider.LoadLatestAuthenticationLogs(username, r.clock.Now().Add(-t.banTime))
 
 	if err != nil {
 		return time.Time{}, nil
 	}
 
-	latestFailedAttempts := make([]models.AuthenticationAttempt, 0, r.maxRetries)
+	if bannedUntil, banned := t.bannedUntil(attempts, secondFactor); banned {
+		return bannedUntil, ErrUserIsBanned
+	}
+
+	return time.Time{}, nil
+}
+
+// Status returns the first factor regulation status of a given user.
+func (r *Regulator) Status(username string) (Status, error) {
+	return r.status(username, r.firstFactor, false)
+}
+
+// SecondFactorStatus returns the second factor regulation status of a given user.
+func (r *Regulator) SecondFactorStatus(username string) (Status, error) {
+	return r.status(username, r.secondFactor, true)
+}
+
+func (r *Regulator) status(username string, t thresholds, secondFactor bool) (status Status, err error) {
+	if !t.enabled {
+		return status, nil
+	}
+
+	now := r.clock.Now()
+
+	attempts, err := r.storageProvider.LoadLatestAuthenticationLogs(username, now.Add(-t.banTime))
+	if err != nil {
+		return status, err
+	}
 
 	for _, attempt := range attempts {
-		if attempt.Successful || len(latestFailedAttempts) >= r.maxRetries {
+		if attempt.IsSecondFactor() != secondFactor {
+			continue
+		}
+
+		if attempt.Successful || now.Sub(attempt.Time) >= t.findTime {
+			break
+		}
+
+		status.FailedAttempts++
+	}
+
+	status.BannedUntil, status.Banned = t.bannedUntil(attempts, secondFactor)
+
+	return status, nil
+}
+
+// bannedUntil returns the time until when the user is banned given the latest attempts of the user within the ban
+// time, ordered from the most recent to the oldest, and true if the user is banned.
+func (t thresholds) bannedUntil(attempts []models.AuthenticationAttempt, secondFactor bool) (time.Time, bool) {
+	latestFailedAttempts := make([]models.AuthenticationAttempt, 0, t.maxRetries)
+
+	for _, attempt := range attempts {
+		if attempt.IsSecondFactor() != secondFactor {
+			continue
+		}
+
+		if attempt.Successful || len(latestFailedAttempts) >= t.maxRetries {
 			// We stop appending failed attempts once we find the first successful attempts or we reach
 			// the configured number of retries, meaning the user is already banned.
 			break
@@ -82,19 +163,18 @@ func (r *Regulator) Regulate(username string) (time.Time, error) {
 
 	// If the number of failed attempts within the ban time is less than the max number of retries
 	// then the user is not banned.
-	if len(latestFailedAttempts) < r.maxRetries {
-		return time.Time{}, nil
+	if len(latestFailedAttempts) < t.maxRetries {
+		return time.Time{}, false
 	}
 
 	// Now we compute the time between the latest attempt and the MaxRetry-th one. If it's
 	// within the FindTime then it means that the user has been banned.
 	durationBetweenLatestAttempts := latestFailedAttempts[0].Time.Sub(
-		latestFailedAttempts[r.maxRetries-1].Time)
+		latestFailedAttempts[t.maxRetries-1].Time)
 
-	if durationBetweenLatestAttempts < r.findTime {
-		bannedUntil := latestFailedAttempts[0].Time.Add(r.banTime)
-		return bannedUntil, ErrUserIsBanned
+	if durationBetweenLatestAttempts < t.findTime {
+		return latestFailedAttempts[0].Time.Add(t.banTime), true
 	}
 
-	return time.Time{}, nil
+	return time.Time{}, false
 }
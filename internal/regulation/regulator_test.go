@@ -1,6 +1,7 @@
 package regulation_test
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -257,6 +258,64 @@ func TestRunRegulatorSuite(t *testing.T) {
 }
 
 // This test checks that the regulator is disabled when configuration is set to 0.
+func (s *RegulatorSuite) TestShouldRegulateSecondFactorSeparately() {
+	attemptsInDB := []models.AuthenticationAttempt{
+		{
+			Username:   "john",
+			Successful: false,
+			Time:       s.clock.Now().Add(-1 * time.Second),
+			Type:       models.AuthenticationTypeTOTP,
+		},
+		{
+			Username:   "john",
+			Successful: false,
+			Time:       s.clock.Now().Add(-2 * time.Second),
+			Type:       models.AuthenticationTypeDuo,
+		},
+		{
+			Username:   "john",
+			Successful: true,
+			Time:       s.clock.Now().Add(-3 * time.Second),
+			Type:       models.AuthenticationTypeFirstFactor,
+		},
+		{
+			Username:   "john",
+			Successful: false,
+			Time:       s.clock.Now().Add(-4 * time.Second),
+			Type:       models.AuthenticationTypeU2F,
+		},
+	}
+
+	s.storageMock.EXPECT().
+		LoadLatestAuthenticationLogs(gomock.Eq("john"), gomock.Any()).
+		Return(attemptsInDB, nil).
+		Times(2)
+
+	s.configuration.SecondFactor = &schema.RegulationSecondFactorConfiguration{
+		MaxRetries: 3,
+		BanTime:    "180",
+		FindTime:   "30",
+	}
+
+	regulator := regulation.NewRegulator(&s.configuration, s.storageMock, &s.clock)
+
+	// The successful first factor attempt doesn't reset the second factor attempts.
+	bannedUntil, err := regulator.RegulateSecondFactor("john")
+	assert.Equal(s.T(), regulation.ErrUserIsBanned, err)
+	assert.Equal(s.T(), s.clock.Now().Add(-1*time.Second).Add(180*time.Second), bannedUntil)
+
+	// The failed second factor attempts are not counted as first factor attempts.
+	_, err = regulator.Regulate("john")
+	assert.NoError(s.T(), err)
+}
+
+func (s *RegulatorSuite) TestShouldHaveSecondFactorRegulatorDisabledWithoutConfiguration() {
+	regulator := regulation.NewRegulator(&s.configuration, s.storageMock, &s.clock)
+
+	_, err := regulator.RegulateSecondFactor("john")
+	assert.NoError(s.T(), err)
+}
+
 func (s *RegulatorSuite) TestShouldHaveRegulatorDisabled() {
 	attemptsInDB := []models.AuthenticationAttempt{
 		{
@@ -302,3 +361,98 @@ func (s *RegulatorSuite) TestShouldHaveRegulatorDisabled() {
 	_, err = regulator.Regulate("john")
 	assert.Equal(s.T(), regulation.ErrUserIsBanned, err)
 }
+
+func (s *RegulatorSuite) TestShouldReturnStatusOfBannedUser() {
+	attemptsInDB := []models.AuthenticationAttempt{
+		{
+			Username:   "john",
+			Successful: false,
+			Time:       s.clock.Now().Add(-1 * time.Second),
+		},
+		{
+			Username:   "john",
+			Successful: false,
+			Time:       s.clock.Now().Add(-4 * time.Second),
+		},
+		{
+			Username:   "john",
+			Successful: false,
+			Time:       s.clock.Now().Add(-6 * time.Second),
+		},
+		{
+			Username:   "john",
+			Successful: false,
+			Time:       s.clock.Now().Add(-50 * time.Second),
+		},
+	}
+
+	s.storageMock.EXPECT().
+		LoadLatestAuthenticationLogs(gomock.Eq("john"), gomock.Any()).
+		Return(attemptsInDB, nil)
+
+	regulator := regulation.NewRegulator(&s.configuration, s.storageMock, &s.clock)
+
+	status, err := regulator.Status("john")
+	s.Require().NoError(err)
+	s.Assert().True(status.Banned)
+	s.Assert().Equal(s.clock.Now().Add(179*time.Second), status.BannedUntil)
+	s.Assert().Equal(3, status.FailedAttempts)
+}
+
+func (s *RegulatorSuite) TestShouldReturnStatusOfSecondFactorSinceLatestSuccessfulAttempt() {
+	s.configuration.SecondFactor = &schema.RegulationSecondFactorConfiguration{
+		MaxRetries: 3,
+		BanTime:    "180",
+		FindTime:   "30",
+	}
+
+	attemptsInDB := []models.AuthenticationAttempt{
+		{
+			Username:   "john",
+			Successful: false,
+			Time:       s.clock.Now().Add(-1 * time.Second),
+			Type:       models.AuthenticationTypeTOTP,
+		},
+		{
+			Username:   "john",
+			Successful: false,
+			Time:       s.clock.Now().Add(-2 * time.Second),
+			Type:       models.AuthenticationTypeFirstFactor,
+		},
+		{
+			Username:   "john",
+			Successful: true,
+			Time:       s.clock.Now().Add(-3 * time.Second),
+			Type:       models.AuthenticationTypeTOTP,
+		},
+		{
+			Username:   "john",
+			Successful: false,
+			Time:       s.clock.Now().Add(-4 * time.Second),
+			Type:       models.AuthenticationTypeTOTP,
+		},
+	}
+
+	s.storageMock.EXPECT().
+		LoadLatestAuthenticationLogs(gomock.Eq("john"), gomock.Any()).
+		Return(attemptsInDB, nil)
+
+	regulator := regulation.NewRegulator(&s.configuration, s.storageMock, &s.clock)
+
+	status, err := regulator.SecondFactorStatus("john")
+	s.Require().NoError(err)
+	s.Assert().False(status.Banned)
+	s.Assert().True(status.BannedUntil.IsZero())
+	s.Assert().Equal(1, status.FailedAttempts)
+}
+
+func (s *RegulatorSuite) TestShouldReturnStatusErrorWhenStorageFails() {
+	s.storageMock.EXPECT().
+		LoadLatestAuthenticationLogs(gomock.Eq("john"), gomock.Any()).
+		Return(nil, fmt.Errorf("failure"))
+
+	regulator := regulation.NewRegulator(&s.configuration, s.storageMock, &s.clock)
+
+	_, err := regulator.Status("john")
+	s.Assert().EqualError(err, "failure")
+}
@@ -9,6 +9,28 @@ import (
 
 // Regulator an authentication regulator preventing attackers to brute force the service.
 type Regulator struct {
+	// The thresholds of the first factor attempts.
+	firstFactor thresholds
+	// The thresholds of the second factor attempts.
+	secondFactor thresholds
+
+	storageProvider storage.Provider
+
+	clock utils.Clock
+}
+
+// Status is the regulation status of a user.
+type Status struct {
+	// True if the user is currently banned.
+	Banned bool
+	// The time until when the user is banned.
+	BannedUntil time.Time
+	// The number of failed attempts made by the user within the find time since their latest successful attempt.
+	FailedAttempts int
+}
+
+// thresholds are the thresholds after which a user is banned.
+type thresholds struct {
 	// Is the regulation enabled.
 	enabled bool
 	// The number of failed authentication attempt before banning the user
@@ -17,8 +39,4 @@ type Regulator struct {
 	findTime time.Duration
 	// If a user has been banned, this duration is the timelapse during which the user is banned.
 	banTime time.Duration
-
-	storageProvider storage.Provider
-
-	clock utils.Clock
 }
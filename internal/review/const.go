@@ -0,0 +1,13 @@
+package review
+
+// Formats of the access review reports.
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// factorBackupCodes is the factor of the users having backup codes left, the other factors are the second factor
+// methods.
+const factorBackupCodes = "backup_codes"
+
+var csvHeader = []string{"username", "display_name", "emails", "groups", "deleted", "factors", "last_login", "rules"}
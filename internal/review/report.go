@@ -0,0 +1,192 @@
+package review
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/authorization"
+	"github.com/authelia/authelia/internal/storage"
+)
+
+// NewReport generates the access review report of the users known to the storage, i.e. the users who logged in, set a
+// preference or registered a second factor device. The details of the users are loaded from the authentication
+// backend, the users it doesn't find anymore are reported as deleted.
+func NewReport(storageProvider storage.Provider, userProvider authentication.UserProvider,
+	authorizer *authorization.Authorizer, now time.Time) (report *Report, err error) {
+	usernames, err := storageProvider.LoadUsernames()
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the users: %w", err)
+	}
+
+	report = &Report{
+		GeneratedAt: now,
+		Users:       make([]User, 0, len(usernames)),
+	}
+
+	for _, username := range usernames {
+		user, err := newUser(storageProvider, userProvider, authorizer, username)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load user %s: %w", username, err)
+		}
+
+		report.Users = append(report.Users, user)
+	}
+
+	return report, nil
+}
+
+func newUser(storageProvider storage.Provider, userProvider authentication.UserProvider,
+	authorizer *authorization.Authorizer, username string) (user User, err error) {
+	user = User{Username: username}
+
+	details, err := userProvider.GetDetails(username)
+
+	switch {
+	case err == nil:
+		user.DisplayName, user.Emails, user.Groups = details.DisplayName, details.Emails, details.Groups
+	case err == authentication.ErrUserNotFound, err == authentication.ErrAccountDisabled:
+		user.Deleted = true
+	default:
+		return user, err
+	}
+
+	if user.Factors, err = loadFactors(storageProvider, username); err != nil {
+		return user, err
+	}
+
+	lastLogin, err := storageProvider.LoadLastLogin(username)
+	if err != nil {
+		return user, err
+	}
+
+	if !lastLogin.IsZero() {
+		user.LastLogin = &lastLogin
+	}
+
+	for _, rule := range authorizer.GetSubjectRules(authorization.Subject{Username: username, Groups: user.Groups}) {
+		user.Rules = append(user.Rules, Rule{
+			Position: rule.Position,
+			Domains:  rule.Definition.Domains,
+			Policy:   rule.Definition.Policy,
+		})
+	}
+
+	return user, nil
+}
+
+func loadFactors(storageProvider storage.Provider, username string) (factors []string, err error) {
+	devices, err := storageProvider.LoadTOTPDevices(username)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(devices) != 0 {
+		factors = append(factors, authentication.TOTP)
+	}
+
+	credentials, err := storageProvider.LoadWebauthnCredentials(username)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, credential := range credentials {
+		if credential.IsSecondFactor() {
+			factors = append(factors, authentication.Webauthn)
+			break
+		}
+	}
+
+	if _, _, err = storageProvider.LoadU2FDeviceHandle(username); err == nil {
+		factors = append(factors, authentication.U2F)
+	} else if err != storage.ErrNoU2FDeviceHandle {
+		return nil, err
+	}
+
+	enrollment, err := storageProvider.LoadPushEnrollment(username)
+
+	switch {
+	case err == nil && enrollment.Devices != 0:
+		factors = append(factors, authentication.Push)
+	case err != nil && err != storage.ErrNoPushEnrollment:
+		return nil, err
+	}
+
+	count, err := storageProvider.CountBackupCodes(username)
+	if err != nil {
+		return nil, err
+	}
+
+	if count != 0 {
+		factors = append(factors, factorBackupCodes)
+	}
+
+	return factors, nil
+}
+
+// Write writes the report in the given format, either csv or json.
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatCSV:
+		return r.WriteCSV(w)
+	case FormatJSON:
+		return r.WriteJSON(w)
+	default:
+		return fmt.Errorf("unknown access review format '%s', must be either '%s' or '%s'", format, FormatCSV, FormatJSON)
+	}
+}
+
+// WriteJSON writes the report as an indented JSON document.
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(r)
+}
+
+// WriteCSV writes the report as CSV with a row per user. The lists are space separated and the rules are formatted as
+// position:policy:domains with the domains separated by commas.
+func (r *Report) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, user := range r.Users {
+		lastLogin := ""
+		if user.LastLogin != nil {
+			lastLogin = user.LastLogin.UTC().Format(time.RFC3339)
+		}
+
+		rules := make([]string, 0, len(user.Rules))
+
+		for _, rule := range user.Rules {
+			rules = append(rules, fmt.Sprintf("%d:%s:%s", rule.Position, rule.Policy, strings.Join(rule.Domains, ",")))
+		}
+
+		record := []string{
+			user.Username,
+			user.DisplayName,
+			strings.Join(user.Emails, " "),
+			strings.Join(user.Groups, " "),
+			strconv.FormatBool(user.Deleted),
+			strings.Join(user.Factors, " "),
+			lastLogin,
+			strings.Join(rules, " "),
+		}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
@@ -0,0 +1,127 @@
+package review
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/authorization"
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/pkg/autheliatest"
+)
+
+func newTestReport(t *testing.T) *Report {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	storageProvider := autheliatest.NewMemoryStorageProvider()
+	require.NoError(t, storageProvider.AppendAuthenticationLog(models.AuthenticationAttempt{Username: "john", Successful: true, Time: time.Unix(1500000000, 0)}))
+	require.NoError(t, storageProvider.AppendAuthenticationLog(models.AuthenticationAttempt{Username: "john", Successful: false, Time: time.Unix(1500000100, 0)}))
+	require.NoError(t, storageProvider.SaveTOTPDevice(models.TOTPDevice{ID: "phone", Username: "john", Description: "Phone"}))
+	require.NoError(t, storageProvider.SaveBackupCodes("john", []string{"hash"}, time.Unix(1500000000, 0)))
+	require.NoError(t, storageProvider.SavePreferred2FAMethod("harry", authentication.TOTP))
+	require.NoError(t, storageProvider.SaveU2FDeviceHandle("harry", []byte("handle"), []byte("key")))
+
+	userProvider := mocks.NewMockUserProvider(ctrl)
+	userProvider.EXPECT().
+		GetDetails("harry").
+		Return(nil, authentication.ErrUserNotFound)
+	userProvider.EXPECT().
+		GetDetails("john").
+		Return(&authentication.UserDetails{
+			Username:    "john",
+			DisplayName: "John Doe",
+			Emails:      []string{"john@example.com"},
+			Groups:      []string{"admins", "dev"},
+		}, nil)
+
+	authorizer := authorization.NewAuthorizer(&schema.Configuration{
+		AccessControl: schema.AccessControlConfiguration{
+			DefaultPolicy: "deny",
+			Rules: []schema.ACLRule{
+				{Domains: []string{"public.example.com"}, Policy: "bypass"},
+				{Domains: []string{"admin.example.com", "*.admin.example.com"}, Policy: "two_factor", Subjects: [][]string{{"group:admins"}}},
+				{Domains: []string{"harry.example.com"}, Policy: "one_factor", Subjects: [][]string{{"user:harry"}}},
+			},
+		},
+	})
+
+	report, err := NewReport(storageProvider, userProvider, authorizer, time.Unix(1600000000, 0))
+	require.NoError(t, err)
+
+	return report
+}
+
+func TestShouldGenerateAccessReviewReport(t *testing.T) {
+	report := newTestReport(t)
+
+	lastLogin := time.Unix(1500000000, 0)
+
+	assert.Equal(t, time.Unix(1600000000, 0), report.GeneratedAt)
+	assert.Equal(t, []User{
+		{
+			Username: "harry",
+			Deleted:  true,
+			Factors:  []string{authentication.U2F},
+			Rules:    []Rule{{Position: 3, Domains: []string{"harry.example.com"}, Policy: "one_factor"}},
+		},
+		{
+			Username:    "john",
+			DisplayName: "John Doe",
+			Emails:      []string{"john@example.com"},
+			Groups:      []string{"admins", "dev"},
+			Factors:     []string{authentication.TOTP, factorBackupCodes},
+			LastLogin:   &lastLogin,
+			Rules:       []Rule{{Position: 2, Domains: []string{"admin.example.com", "*.admin.example.com"}, Policy: "two_factor"}},
+		},
+	}, report.Users)
+}
+
+func TestShouldWriteAccessReviewReportAsCSV(t *testing.T) {
+	report := newTestReport(t)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, report.Write(buf, FormatCSV))
+
+	assert.Equal(t, "username,display_name,emails,groups,deleted,factors,last_login,rules\n"+
+		"harry,,,,true,u2f,,3:one_factor:harry.example.com\n"+
+		"john,John Doe,john@example.com,admins dev,false,totp backup_codes,2017-07-14T02:40:00Z,\"2:two_factor:admin.example.com,*.admin.example.com\"\n",
+		buf.String())
+}
+
+func TestShouldWriteAccessReviewReportAsJSON(t *testing.T) {
+	report := &Report{
+		GeneratedAt: time.Unix(1600000000, 0).UTC(),
+		Users:       []User{{Username: "harry", Deleted: true}},
+	}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, report.Write(buf, FormatJSON))
+
+	assert.JSONEq(t, `{
+		"generated_at": "2020-09-13T12:26:40Z",
+		"users": [{
+			"username": "harry",
+			"display_name": "",
+			"emails": null,
+			"groups": null,
+			"deleted": true,
+			"factors": null,
+			"last_login": null,
+			"rules": null
+		}]
+	}`, buf.String())
+}
+
+func TestShouldFailToWriteAccessReviewReportInUnknownFormat(t *testing.T) {
+	err := (&Report{}).Write(&bytes.Buffer{}, "xml")
+
+	assert.EqualError(t, err, "unknown access review format 'xml', must be either 'csv' or 'json'")
+}
@@ -0,0 +1,41 @@
+package review
+
+import (
+	"time"
+)
+
+// Report is an access review report listing the users known to Authelia and the access they have. It's meant to feed
+// the periodic access reviews and compliance processes.
+type Report struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Users       []User    `json:"users"`
+}
+
+// User is a user of an access review report.
+type User struct {
+	Username    string   `json:"username"`
+	DisplayName string   `json:"display_name"`
+	Emails      []string `json:"emails"`
+	Groups      []string `json:"groups"`
+
+	// True when the user isn't found in the authentication backend anymore, or their account is disabled, while some of
+	// their data is still stored.
+	Deleted bool `json:"deleted"`
+
+	// The second factor methods the user enrolled and the backup codes when some are left.
+	Factors []string `json:"factors"`
+
+	// The time of the latest successful authentication of the user, nil when the user hasn't logged in since the
+	// authentication logs were last pruned.
+	LastLogin *time.Time `json:"last_login"`
+
+	// The access control rules naming the user or one of their groups in their subjects.
+	Rules []Rule `json:"rules"`
+}
+
+// Rule is an access control rule matching a user of an access review report.
+type Rule struct {
+	Position int      `json:"position"`
+	Domains  []string `json:"domains"`
+	Policy   string   `json:"policy"`
+}
@@ -0,0 +1,195 @@
+package risk
+
+import (
+	"net"
+	"time"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/geoip"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/storage"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// Evaluator gathers the signals of a login from the login history of the user, has them scored by an Engine and
+// decides whether the login is allowed, requires a step-up to the second factor or is denied.
+type Evaluator struct {
+	engine          Engine
+	storageProvider storage.Provider
+	geoIP           geoip.Provider
+
+	history                time.Duration
+	impossibleTravelWindow time.Duration
+	stepUpScore            int
+	denyScore              int
+}
+
+// NewEvaluator creates an Evaluator from the configuration. The external engine is used when configured, otherwise
+// the built-in heuristic engine is used. The GeoIP provider is optional, the signals relying on the location of the
+// user are never raised without it.
+func NewEvaluator(configuration *schema.RiskConfiguration, provider storage.Provider, geoIP geoip.Provider) *Evaluator {
+	history, err := utils.ParseDurationString(configuration.History)
+	if err != nil {
+		panic(err)
+	}
+
+	impossibleTravelWindow, err := utils.ParseDurationString(configuration.ImpossibleTravelWindow)
+	if err != nil {
+		panic(err)
+	}
+
+	var engine Engine = NewHeuristicEngine()
+
+	if configuration.External != nil {
+		timeout, err := utils.ParseDurationString(configuration.External.Timeout)
+		if err != nil {
+			panic(err)
+		}
+
+		engine = NewExternalEngine(configuration.External.URL, timeout)
+	}
+
+	return NewEvaluatorWithEngine(engine, provider, geoIP, history, impossibleTravelWindow, configuration.StepUpScore, configuration.DenyScore)
+}
+
+// NewEvaluatorWithEngine creates an Evaluator scoring the logins with the given Engine.
+func NewEvaluatorWithEngine(engine Engine, provider storage.Provider, geoIP geoip.Provider, history, impossibleTravelWindow time.Duration, stepUpScore, denyScore int) *Evaluator {
+	return &Evaluator{
+		engine:                 engine,
+		storageProvider:        provider,
+		geoIP:                  geoIP,
+		history:                history,
+		impossibleTravelWindow: impossibleTravelWindow,
+		stepUpScore:            stepUpScore,
+		denyScore:              denyScore,
+	}
+}
+
+// Evaluate assesses the risk of a login. It must be called before the login is appended to the authentication log.
+func (e *Evaluator) Evaluate(login Login) (assessment *Assessment, err error) {
+	attempts, err := e.storageProvider.LoadLatestAuthenticationLogs(login.Username, login.Time.Add(-e.history))
+	if err != nil {
+		return nil, err
+	}
+
+	evaluation := e.newEvaluation(login, attempts)
+
+	score, err := e.engine.Score(evaluation)
+	if err != nil {
+		return nil, err
+	}
+
+	assessment = &Assessment{
+		Evaluation: evaluation,
+		Score:      score,
+		Decision:   Allow,
+	}
+
+	switch {
+	case score >= e.denyScore:
+		assessment.Decision = Deny
+	case score >= e.stepUpScore:
+		assessment.Decision = StepUp
+	}
+
+	return assessment, nil
+}
+
+// newEvaluation gathers the signals of the login from the attempts which are ordered from the most recent.
+func (e *Evaluator) newEvaluation(login Login, attempts []models.AuthenticationAttempt) (evaluation Evaluation) {
+	countries := map[string]string{}
+
+	evaluation = Evaluation{
+		Username:  login.Username,
+		RemoteIP:  login.RemoteIP,
+		UserAgent: login.UserAgent,
+		Country:   e.lookupCountry(login.RemoteIP, countries),
+		Time:      login.Time,
+	}
+
+	device := utils.ParseUserAgent(login.UserAgent).String()
+
+	var (
+		lastSuccess                     *models.AuthenticationAttempt
+		knownDevice, knownCountry       bool
+		successes, successesWithCountry int
+	)
+
+	for i, attempt := range attempts {
+		// Only the logins are compared, the second factor attempts are regulated separately.
+		if attempt.IsSecondFactor() {
+			continue
+		}
+
+		if !attempt.Successful {
+			if lastSuccess == nil {
+				evaluation.FailedAttempts++
+			}
+
+			continue
+		}
+
+		if lastSuccess == nil {
+			lastSuccess = &attempts[i]
+		}
+
+		successes++
+
+		if utils.ParseUserAgent(attempt.UserAgent).String() == device {
+			knownDevice = true
+		}
+
+		if country := e.lookupCountry(attempt.RemoteIP, countries); country != "" {
+			successesWithCountry++
+
+			if country == evaluation.Country {
+				knownCountry = true
+			}
+		}
+	}
+
+	// A user without any login history isn't considered at risk, there is nothing to compare the login with.
+	if successes == 0 {
+		return evaluation
+	}
+
+	evaluation.NewDevice = !knownDevice
+
+	if evaluation.Country == "" || successesWithCountry == 0 {
+		return evaluation
+	}
+
+	evaluation.NewCountry = !knownCountry
+
+	if login.Time.Sub(lastSuccess.Time) < e.impossibleTravelWindow {
+		if country := e.lookupCountry(lastSuccess.RemoteIP, countries); country != "" && country != evaluation.Country {
+			evaluation.ImpossibleTravel = true
+		}
+	}
+
+	return evaluation
+}
+
+// lookupCountry returns the country of the IP or an empty string if it can't be determined. The results are cached
+// in the given map for the duration of an evaluation.
+func (e *Evaluator) lookupCountry(remoteIP string, cache map[string]string) string {
+	if e.geoIP == nil || remoteIP == "" {
+		return ""
+	}
+
+	if country, ok := cache[remoteIP]; ok {
+		return country
+	}
+
+	var country string
+
+	if ip := net.ParseIP(remoteIP); ip != nil {
+		if location, err := e.geoIP.Lookup(ip); err == nil && location != nil {
+			country = location.Country
+		}
+	}
+
+	cache[remoteIP] = country
+
+	return country
+}
@@ -0,0 +1,183 @@
+package risk_test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/geoip"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/risk"
+	"github.com/authelia/authelia/pkg/autheliatest"
+)
+
+const (
+	firefoxOnLinux  = "Mozilla/5.0 (X11; Linux x86_64; rv:89.0) Gecko/20100101 Firefox/89.0"
+	chromeOnWindows = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36"
+)
+
+type testGeoIPProvider map[string]string
+
+func (p testGeoIPProvider) Lookup(ip net.IP) (location *geoip.Location, err error) {
+	country, ok := p[ip.String()]
+	if !ok {
+		return nil, nil
+	}
+
+	return &geoip.Location{Country: country}, nil
+}
+
+var testGeoIP = testGeoIPProvider{
+	"10.0.0.1": "France",
+	"10.0.0.2": "France",
+	"20.0.0.1": "Japan",
+}
+
+func newTestEvaluator(provider *autheliatest.MemoryStorageProvider) *risk.Evaluator {
+	return risk.NewEvaluatorWithEngine(risk.NewHeuristicEngine(), provider, testGeoIP, 30*24*time.Hour, 2*time.Hour, 30, 80)
+}
+
+func appendAttempt(t *testing.T, provider *autheliatest.MemoryStorageProvider, attempt models.AuthenticationAttempt) {
+	attempt.Username = "john"
+
+	require.NoError(t, provider.AppendAuthenticationLog(attempt))
+}
+
+func TestEvaluatorShouldAllowUserWithoutHistory(t *testing.T) {
+	provider := autheliatest.NewMemoryStorageProvider()
+
+	assessment, err := newTestEvaluator(provider).Evaluate(risk.Login{
+		Username:  "john",
+		RemoteIP:  "20.0.0.1",
+		UserAgent: firefoxOnLinux,
+		Time:      time.Now(),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Japan", assessment.Evaluation.Country)
+	assert.False(t, assessment.Evaluation.NewDevice)
+	assert.False(t, assessment.Evaluation.NewCountry)
+	assert.Equal(t, 0, assessment.Score)
+	assert.Equal(t, risk.Allow, assessment.Decision)
+}
+
+func TestEvaluatorShouldAllowKnownDeviceAndCountry(t *testing.T) {
+	provider := autheliatest.NewMemoryStorageProvider()
+	now := time.Now()
+
+	appendAttempt(t, provider, models.AuthenticationAttempt{Successful: true, Time: now.Add(-24 * time.Hour), RemoteIP: "10.0.0.1", UserAgent: firefoxOnLinux})
+
+	assessment, err := newTestEvaluator(provider).Evaluate(risk.Login{
+		Username:  "john",
+		RemoteIP:  "10.0.0.2",
+		UserAgent: firefoxOnLinux,
+		Time:      now,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, risk.Evaluation{
+		Username:  "john",
+		RemoteIP:  "10.0.0.2",
+		UserAgent: firefoxOnLinux,
+		Country:   "France",
+		Time:      now,
+	}, assessment.Evaluation)
+	assert.Equal(t, risk.Allow, assessment.Decision)
+}
+
+func TestEvaluatorShouldRequireStepUpForNewDeviceAndCountry(t *testing.T) {
+	provider := autheliatest.NewMemoryStorageProvider()
+	now := time.Now()
+
+	appendAttempt(t, provider, models.AuthenticationAttempt{Successful: true, Time: now.Add(-24 * time.Hour), RemoteIP: "10.0.0.1", UserAgent: firefoxOnLinux})
+
+	assessment, err := newTestEvaluator(provider).Evaluate(risk.Login{
+		Username:  "john",
+		RemoteIP:  "20.0.0.1",
+		UserAgent: chromeOnWindows,
+		Time:      now,
+	})
+
+	require.NoError(t, err)
+	assert.True(t, assessment.Evaluation.NewDevice)
+	assert.True(t, assessment.Evaluation.NewCountry)
+	assert.False(t, assessment.Evaluation.ImpossibleTravel)
+	assert.Equal(t, 50, assessment.Score)
+	assert.Equal(t, risk.StepUp, assessment.Decision)
+}
+
+func TestEvaluatorShouldDenyImpossibleTravel(t *testing.T) {
+	provider := autheliatest.NewMemoryStorageProvider()
+	now := time.Now()
+
+	appendAttempt(t, provider, models.AuthenticationAttempt{Successful: true, Time: now.Add(-time.Hour), RemoteIP: "10.0.0.1", UserAgent: firefoxOnLinux})
+
+	assessment, err := newTestEvaluator(provider).Evaluate(risk.Login{
+		Username:  "john",
+		RemoteIP:  "20.0.0.1",
+		UserAgent: firefoxOnLinux,
+		Time:      now,
+	})
+
+	require.NoError(t, err)
+	assert.False(t, assessment.Evaluation.NewDevice)
+	assert.True(t, assessment.Evaluation.NewCountry)
+	assert.True(t, assessment.Evaluation.ImpossibleTravel)
+	assert.Equal(t, 90, assessment.Score)
+	assert.Equal(t, risk.Deny, assessment.Decision)
+}
+
+func TestEvaluatorShouldCountFailedAttemptsSinceLastSuccess(t *testing.T) {
+	provider := autheliatest.NewMemoryStorageProvider()
+	now := time.Now()
+
+	appendAttempt(t, provider, models.AuthenticationAttempt{Successful: false, Time: now.Add(-5 * time.Hour), RemoteIP: "10.0.0.1"})
+	appendAttempt(t, provider, models.AuthenticationAttempt{Successful: true, Time: now.Add(-4 * time.Hour), RemoteIP: "10.0.0.1", UserAgent: firefoxOnLinux})
+	appendAttempt(t, provider, models.AuthenticationAttempt{Successful: false, Time: now.Add(-3 * time.Minute), RemoteIP: "10.0.0.1"})
+	appendAttempt(t, provider, models.AuthenticationAttempt{Successful: false, Time: now.Add(-2 * time.Minute), RemoteIP: "10.0.0.1"})
+	appendAttempt(t, provider, models.AuthenticationAttempt{Successful: false, Time: now.Add(-time.Minute), RemoteIP: "10.0.0.1"})
+
+	assessment, err := newTestEvaluator(provider).Evaluate(risk.Login{
+		Username:  "john",
+		RemoteIP:  "10.0.0.1",
+		UserAgent: firefoxOnLinux,
+		Time:      now,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, assessment.Evaluation.FailedAttempts)
+	assert.Equal(t, 30, assessment.Score)
+	assert.Equal(t, risk.StepUp, assessment.Decision)
+}
+
+func TestEvaluatorShouldIgnoreCountrySignalsWithoutGeoIP(t *testing.T) {
+	provider := autheliatest.NewMemoryStorageProvider()
+	now := time.Now()
+
+	appendAttempt(t, provider, models.AuthenticationAttempt{Successful: true, Time: now.Add(-time.Hour), RemoteIP: "10.0.0.1", UserAgent: firefoxOnLinux})
+
+	evaluator := risk.NewEvaluatorWithEngine(risk.NewHeuristicEngine(), provider, nil, 30*24*time.Hour, 2*time.Hour, 30, 80)
+
+	assessment, err := evaluator.Evaluate(risk.Login{
+		Username:  "john",
+		RemoteIP:  "20.0.0.1",
+		UserAgent: firefoxOnLinux,
+		Time:      now,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "", assessment.Evaluation.Country)
+	assert.False(t, assessment.Evaluation.NewCountry)
+	assert.False(t, assessment.Evaluation.ImpossibleTravel)
+	assert.Equal(t, risk.Allow, assessment.Decision)
+}
+
+func TestDecisionString(t *testing.T) {
+	assert.Equal(t, "allow", risk.Allow.String())
+	assert.Equal(t, "step_up", risk.StepUp.String())
+	assert.Equal(t, "deny", risk.Deny.String())
+	assert.Equal(t, "unknown", risk.Decision(10).String())
+}
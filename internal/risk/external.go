@@ -0,0 +1,59 @@
+package risk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ExternalEngine is an Engine delegating the scoring to an external service. The evaluation is sent as JSON in the
+// body of a POST request and the service responds with a JSON object such as '{"score": 42}'.
+type ExternalEngine struct {
+	url    string
+	client *http.Client
+}
+
+type externalScoreResponse struct {
+	Score *int `json:"score"`
+}
+
+// NewExternalEngine creates a new ExternalEngine sending the evaluations to the given URL.
+func NewExternalEngine(url string, timeout time.Duration) *ExternalEngine {
+	return &ExternalEngine{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Score requests the score of the evaluation from the external service.
+func (e *ExternalEngine) Score(evaluation Evaluation) (score int, err error) {
+	body, err := json.Marshal(evaluation)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := e.client.Post(e.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("external risk engine responded with status code %d", resp.StatusCode)
+	}
+
+	var response externalScoreResponse
+
+	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return 0, fmt.Errorf("unable to decode the response of the external risk engine: %w", err)
+	}
+
+	if response.Score == nil {
+		return 0, fmt.Errorf("external risk engine response has no score")
+	}
+
+	return *response.Score, nil
+}
@@ -0,0 +1,63 @@
+package risk
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExternalEngineShouldSendEvaluationAndReturnScore(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var evaluation Evaluation
+
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&evaluation))
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "john", evaluation.Username)
+		assert.True(t, evaluation.NewCountry)
+
+		_, _ = w.Write([]byte(`{"score": 42}`))
+	}))
+	defer server.Close()
+
+	engine := NewExternalEngine(server.URL, time.Second)
+
+	score, err := engine.Score(Evaluation{Username: "john", NewCountry: true})
+
+	require.NoError(t, err)
+	assert.Equal(t, 42, score)
+}
+
+func TestExternalEngineShouldFailOnInvalidResponse(t *testing.T) {
+	testCases := []struct {
+		name     string
+		status   int
+		body     string
+		expected string
+	}{
+		{"status code", http.StatusInternalServerError, `{"score": 1}`, "external risk engine responded with status code 500"},
+		{"no score", http.StatusOK, `{}`, "external risk engine response has no score"},
+		{"invalid json", http.StatusOK, `abc`, "unable to decode the response of the external risk engine: invalid character 'a' looking for beginning of value"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+
+			_, err := NewExternalEngine(server.URL, time.Second).Score(Evaluation{})
+
+			assert.EqualError(t, err, tc.expected)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package risk
+
+// Weights of the signals used by the HeuristicEngine.
+const (
+	heuristicNewDeviceWeight        = 20
+	heuristicNewCountryWeight       = 30
+	heuristicImpossibleTravelWeight = 60
+	heuristicFailedAttemptWeight    = 10
+	heuristicFailedAttemptsMaximum  = 40
+)
+
+// HeuristicEngine is the built-in Engine, the score is the sum of the weights of the signals raised by the login.
+type HeuristicEngine struct{}
+
+// NewHeuristicEngine creates a new HeuristicEngine.
+func NewHeuristicEngine() *HeuristicEngine {
+	return &HeuristicEngine{}
+}
+
+// Score returns the sum of the weights of the signals raised by the evaluation.
+func (e *HeuristicEngine) Score(evaluation Evaluation) (score int, err error) {
+	if evaluation.NewDevice {
+		score += heuristicNewDeviceWeight
+	}
+
+	if evaluation.NewCountry {
+		score += heuristicNewCountryWeight
+	}
+
+	if evaluation.ImpossibleTravel {
+		score += heuristicImpossibleTravelWeight
+	}
+
+	failed := evaluation.FailedAttempts * heuristicFailedAttemptWeight
+	if failed > heuristicFailedAttemptsMaximum {
+		failed = heuristicFailedAttemptsMaximum
+	}
+
+	return score + failed, nil
+}
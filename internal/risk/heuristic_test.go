@@ -0,0 +1,34 @@
+package risk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeuristicEngineShouldSumSignalWeights(t *testing.T) {
+	testCases := []struct {
+		name       string
+		evaluation Evaluation
+		expected   int
+	}{
+		{"no signal", Evaluation{}, 0},
+		{"new device", Evaluation{NewDevice: true}, 20},
+		{"new country", Evaluation{NewDevice: true, NewCountry: true}, 50},
+		{"impossible travel", Evaluation{NewCountry: true, ImpossibleTravel: true}, 90},
+		{"failed attempts", Evaluation{FailedAttempts: 2}, 20},
+		{"failed attempts capped", Evaluation{NewDevice: true, FailedAttempts: 10}, 60},
+	}
+
+	engine := NewHeuristicEngine()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			score, err := engine.Score(tc.evaluation)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, score)
+		})
+	}
+}
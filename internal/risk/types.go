@@ -0,0 +1,76 @@
+package risk
+
+import (
+	"time"
+)
+
+// Decision is the outcome of a risk evaluation.
+type Decision int
+
+const (
+	// Allow lets the authentication proceed as usual.
+	Allow Decision = iota
+
+	// StepUp requires the user to complete the second factor even for resources only requiring one factor.
+	StepUp
+
+	// Deny rejects the authentication.
+	Deny
+)
+
+// String returns a string representation of the Decision.
+func (d Decision) String() string {
+	switch d {
+	case Allow:
+		return "allow"
+	case StepUp:
+		return "step_up"
+	case Deny:
+		return "deny"
+	default:
+		return "unknown"
+	}
+}
+
+// Engine computes the risk score of a login.
+type Engine interface {
+	Score(evaluation Evaluation) (score int, err error)
+}
+
+// Login describes a login which is being evaluated.
+type Login struct {
+	Username  string
+	RemoteIP  string
+	UserAgent string
+	Time      time.Time
+}
+
+// Evaluation is the input of an Engine, it's the login along with the signals gathered from the login history of the
+// user.
+type Evaluation struct {
+	Username  string    `json:"username"`
+	RemoteIP  string    `json:"remote_ip"`
+	UserAgent string    `json:"user_agent"`
+	Country   string    `json:"country,omitempty"`
+	Time      time.Time `json:"time"`
+
+	// NewDevice is true when the user never successfully logged in with this kind of device before.
+	NewDevice bool `json:"new_device"`
+
+	// NewCountry is true when the user never successfully logged in from this country before.
+	NewCountry bool `json:"new_country"`
+
+	// ImpossibleTravel is true when the last successful login of the user was from another country too recently
+	// for the user to have travelled.
+	ImpossibleTravel bool `json:"impossible_travel"`
+
+	// FailedAttempts is the number of failed attempts since the last successful login of the user.
+	FailedAttempts int `json:"failed_attempts"`
+}
+
+// Assessment is the result of a risk evaluation.
+type Assessment struct {
+	Evaluation Evaluation
+	Score      int
+	Decision   Decision
+}
@@ -0,0 +1,102 @@
+package rpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/authelia/authelia/internal/events"
+	"github.com/authelia/authelia/internal/logging"
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/rpc/pb"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// adminServer exposes the administration operations to the callers authenticated with the admin token.
+type adminServer struct {
+	pb.UnimplementedAdminServer
+
+	providers middlewares.Providers
+	clock     utils.Clock
+}
+
+func newAdminServer(providers middlewares.Providers, clock utils.Clock) *adminServer {
+	return &adminServer{providers: providers, clock: clock}
+}
+
+// PurgeUser deletes all the data of a departing user and revokes their sessions.
+func (s *adminServer) PurgeUser(ctx context.Context, in *pb.PurgeUserRequest) (*pb.PurgeUserResponse, error) {
+	logger := logging.Logger()
+
+	if in.Username == "" {
+		return nil, status.Error(codes.InvalidArgument, "No username provided")
+	}
+
+	now := s.clock.Now()
+
+	if err := s.providers.StorageProvider.PurgeUser(in.Username, now); err != nil {
+		logger.Errorf("Unable to purge user %s: %s", in.Username, err)
+
+		return nil, status.Error(codes.Internal, "Operation failed")
+	}
+
+	logger.Infof("User %s has been purged through the gRPC admin API", in.Username)
+
+	s.providers.Events.Publish(events.Event{
+		Type:     events.UserDataErased,
+		Time:     now,
+		Username: in.Username,
+		Details:  map[string]string{events.DetailActor: adminActor},
+	})
+
+	s.providers.Events.Publish(events.Event{
+		Type:     events.SessionsRevoked,
+		Time:     now,
+		Username: in.Username,
+		Details:  map[string]string{events.DetailActor: adminActor},
+	})
+
+	return &pb.PurgeUserResponse{}, nil
+}
+
+// newAdminTokenInterceptor creates the interceptor rejecting the calls to the admin service which don't provide the
+// admin token as a bearer token. The calls to the other services aren't affected.
+func newAdminTokenInterceptor(token string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !strings.HasPrefix(info.FullMethod, adminServicePrefix) {
+			return handler(ctx, req)
+		}
+
+		if token == "" || !isAdminTokenValid(ctx, token) {
+			logging.Logger().Warnf("Rejected a call to %s with a missing or invalid admin token", info.FullMethod)
+
+			return nil, status.Error(codes.Unauthenticated, "A valid admin token is required")
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func isAdminTokenValid(ctx context.Context, token string) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false
+	}
+
+	for _, value := range md.Get(metadataAuthorization) {
+		if !strings.HasPrefix(value, bearerPrefix) {
+			continue
+		}
+
+		if subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(value, bearerPrefix)), []byte(token)) == 1 {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,24 @@
+package rpc
+
+const (
+	headerXOriginalURL     = "X-Original-URL"
+	headerXForwardedMethod = "X-Forwarded-Method"
+)
+
+// verifyPath is the path of the verify endpoint the checks are evaluated against.
+const verifyPath = "/api/verify"
+
+// adminServicePrefix is the prefix of the full names of the methods of the admin service.
+const adminServicePrefix = "/authelia.v1.Admin/"
+
+// adminActor is the actor of the events published by the admin service, it's recorded in the audit log.
+const adminActor = "gRPC admin API"
+
+const (
+	metadataAuthorization = "authorization"
+	bearerPrefix          = "Bearer "
+)
+
+// skippedResponseHeaders are the headers of the verify endpoint replies which only make sense to HTTP clients and
+// aren't returned in the check responses.
+var skippedResponseHeaders = []string{"Content-Length", "Content-Type", "Server", "Connection"}
@@ -0,0 +1,66 @@
+package rpc
+
+import (
+	"net"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/logging"
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/rpc/pb"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+//go:generate protoc --proto_path=../../api --go_out=pb --go_opt=paths=source_relative --go-grpc_out=pb --go-grpc_opt=paths=source_relative authelia.proto
+
+// NewServer creates the gRPC server exposing the verify service and, when an admin token is configured, the admin
+// service.
+func NewServer(configuration schema.Configuration, providers middlewares.Providers, opts ...grpc.ServerOption) *grpc.Server {
+	token := configuration.GRPC.AdminToken
+
+	opts = append(opts, grpc.UnaryInterceptor(newAdminTokenInterceptor(token)))
+
+	server := grpc.NewServer(opts...)
+
+	pb.RegisterVerifyServer(server, newVerifyServer(configuration, providers))
+
+	if token != "" {
+		pb.RegisterAdminServer(server, newAdminServer(providers, utils.RealClock{}))
+	}
+
+	return server
+}
+
+// StartServer starts the gRPC server with the given configuration and providers.
+func StartServer(configuration schema.Configuration, providers middlewares.Providers) {
+	logger := logging.Logger()
+
+	var opts []grpc.ServerOption
+
+	if configuration.GRPC.TLSCert != "" && configuration.GRPC.TLSKey != "" {
+		creds, err := credentials.NewServerTLSFromFile(configuration.GRPC.TLSCert, configuration.GRPC.TLSKey)
+		if err != nil {
+			logger.Fatalf("Unable to load the TLS certificate of the gRPC server: %s", err)
+		}
+
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	addrPattern := net.JoinHostPort(configuration.GRPC.Host, strconv.Itoa(configuration.GRPC.Port))
+
+	listener, err := net.Listen(utils.ListenNetwork(configuration.GRPC.AddressFamily), addrPattern)
+	if err != nil {
+		logger.Fatalf("Error initializing the gRPC listener: %s", err)
+	}
+
+	if len(opts) > 0 {
+		logger.Infof("Authelia is listening for gRPC TLS connections on %s", addrPattern)
+	} else {
+		logger.Infof("Authelia is listening for gRPC non-TLS connections on %s", addrPattern)
+	}
+
+	logger.Fatal(NewServer(configuration, providers, opts...).Serve(listener))
+}
@@ -0,0 +1,108 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"net/url"
+
+	"github.com/valyala/fasthttp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/handlers"
+	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/rpc/pb"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// verifyServer answers the checks by running the handler of the verify endpoint, so the decisions are the same as the
+// ones of the HTTP API.
+type verifyServer struct {
+	pb.UnimplementedVerifyServer
+
+	handler fasthttp.RequestHandler
+}
+
+func newVerifyServer(configuration schema.Configuration, providers middlewares.Providers) *verifyServer {
+	autheliaMiddleware := middlewares.AutheliaMiddleware(configuration, providers)
+
+	return &verifyServer{
+		handler: autheliaMiddleware(handlers.VerifyGet(configuration.AuthenticationBackend)),
+	}
+}
+
+// Check returns the decision for the request described by the proxy.
+func (s *verifyServer) Check(ctx context.Context, in *pb.CheckRequest) (*pb.CheckResponse, error) {
+	if in.TargetUrl == "" {
+		return nil, status.Error(codes.InvalidArgument, "The target URL is required")
+	}
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+
+	req.SetRequestURI(verifyRequestURI(in))
+
+	for _, header := range in.Headers {
+		req.Header.Add(header.Name, header.Value)
+	}
+
+	req.Header.Set(headerXOriginalURL, in.TargetUrl)
+
+	if in.Method != "" {
+		req.Header.Set(headerXForwardedMethod, in.Method)
+	}
+
+	var remoteAddr net.Addr
+
+	if p, ok := peer.FromContext(ctx); ok {
+		remoteAddr = p.Addr
+	}
+
+	requestCtx := &fasthttp.RequestCtx{}
+	requestCtx.Init(req, remoteAddr, nil)
+
+	s.handler(requestCtx)
+
+	return newCheckResponse(&requestCtx.Response), nil
+}
+
+func verifyRequestURI(in *pb.CheckRequest) string {
+	query := url.Values{}
+
+	if in.RedirectionUrl != "" {
+		query.Set("rd", in.RedirectionUrl)
+	}
+
+	if in.BasicAuth {
+		query.Set("auth", "basic")
+	}
+
+	if len(query) == 0 {
+		return verifyPath
+	}
+
+	return verifyPath + "?" + query.Encode()
+}
+
+func newCheckResponse(response *fasthttp.Response) *pb.CheckResponse {
+	statusCode := response.StatusCode()
+
+	checkResponse := &pb.CheckResponse{
+		Allowed:    statusCode == fasthttp.StatusOK,
+		StatusCode: int32(statusCode),
+	}
+
+	response.Header.VisitAll(func(key, value []byte) {
+		name := string(key)
+
+		if utils.IsStringInSliceFold(name, skippedResponseHeaders) {
+			return
+		}
+
+		checkResponse.Headers = append(checkResponse.Headers, &pb.Header{Name: name, Value: string(value)})
+	})
+
+	return checkResponse
+}
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"net"
+	"strconv"
+
+	"github.com/fasthttp/router"
+	"github.com/valyala/fasthttp"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/logging"
+	"github.com/authelia/authelia/internal/metrics"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// StartMetricsServer starts the server exposing the metrics in the Prometheus format on its own listener so they
+// aren't reachable through the reverse proxy.
+func StartMetricsServer(configuration schema.Configuration) {
+	logger := logging.Logger()
+
+	r := router.New()
+	r.GET("/metrics", metrics.DefaultRegistry.Handler)
+
+	server := &fasthttp.Server{
+		ErrorHandler:          autheliaErrorHandler,
+		Handler:               r.Handler,
+		NoDefaultServerHeader: true,
+	}
+
+	addrPattern := net.JoinHostPort(configuration.Metrics.Host, strconv.Itoa(configuration.Metrics.Port))
+
+	listener, err := net.Listen(utils.ListenNetwork(configuration.Metrics.AddressFamily), addrPattern)
+	if err != nil {
+		logger.Fatalf("Error initializing the metrics listener: %s", err)
+	}
+
+	logger.Infof("Authelia is exposing the metrics on %s/metrics", addrPattern)
+	logger.Fatal(server.Serve(listener))
+}
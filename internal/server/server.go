@@ -1,6 +1,7 @@
 package server
 
 import (
+	"crypto/tls"
 	"embed"
 	"io/fs"
 	"io/ioutil"
@@ -19,17 +20,21 @@ import (
 	"github.com/valyala/fasthttp/pprofhandler"
 
 	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/daemon"
 	"github.com/authelia/authelia/internal/duo"
 	"github.com/authelia/authelia/internal/handlers"
 	"github.com/authelia/authelia/internal/logging"
 	"github.com/authelia/authelia/internal/middlewares"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/push"
+	"github.com/authelia/authelia/internal/utils"
 )
 
 //go:embed public_html
 var assets embed.FS
 
-func registerRoutes(configuration schema.Configuration, providers middlewares.Providers) fasthttp.RequestHandler {
-	autheliaMiddleware := middlewares.AutheliaMiddleware(configuration, providers)
+func registerRoutes(configuration schema.Configuration, providers middlewares.Providers, interceptors []middlewares.Middleware) fasthttp.RequestHandler {
+	autheliaMiddleware := middlewares.AutheliaMiddleware(configuration, providers, interceptors...)
 	rememberMe := strconv.FormatBool(configuration.Session.RememberMeDuration != "0")
 	resetPassword := strconv.FormatBool(!configuration.AuthenticationBackend.DisableResetPassword)
 
@@ -41,7 +46,34 @@ func registerRoutes(configuration schema.Configuration, providers middlewares.Pr
 	serveSwaggerHandler := ServeTemplatedFile(swaggerAssets, indexFile, configuration.Server.Path, rememberMe, resetPassword, configuration.Session.Name, configuration.Theme)
 	serveSwaggerAPIHandler := ServeTemplatedFile(swaggerAssets, apiFile, configuration.Server.Path, rememberMe, resetPassword, configuration.Session.Name, configuration.Theme)
 
+	// The middlewares of each route are declared by the chain it is registered with. The routes called by the proxies and
+	// the monitoring are neither rate limited nor cross-origin, the requests of the clients are and their bodies must
+	// be JSON.
+	proxied := middlewares.NewChain(middlewares.LogHandledRequest)
+	public := proxied
+
+	if configuration.Server.RateLimit != nil {
+		public = public.Append(middlewares.RateLimit(middlewares.NewRateLimiter(*configuration.Server.RateLimit)))
+	}
+
+	if configuration.Server.CORS != nil {
+		public = public.Append(middlewares.CORS(*configuration.Server.CORS))
+	}
+
+	publicJSON := public.Append(middlewares.RequireJSONBody)
+	firstFactor := public.Append(middlewares.RequireFirstFactor)
+	firstFactorJSON := firstFactor.Append(middlewares.RequireJSONBody)
+
+	admin := func(permission models.AdminPermission) middlewares.Chain {
+		return public.Append(middlewares.RequireAdminPermission(permission))
+	}
+
+	adminJSON := func(permission models.AdminPermission) middlewares.Chain {
+		return admin(permission).Append(middlewares.RequireJSONBody)
+	}
+
 	r := router.New()
+	r.SaveMatchedRoutePath = configuration.Metrics != nil
 	r.GET("/", serveIndexHandler)
 	r.GET("/api/", serveSwaggerHandler)
 	r.GET("/api/"+apiFile, serveSwaggerAPIHandler)
@@ -53,78 +85,215 @@ func registerRoutes(configuration schema.Configuration, providers middlewares.Pr
 	r.GET("/static/{filepath:*}", embeddedFS)
 	r.ANY("/api/{filepath:*}", embeddedFS)
 
-	r.GET("/api/health", autheliaMiddleware(handlers.HealthGet))
-	r.GET("/api/state", autheliaMiddleware(handlers.StateGet))
+	r.GET("/api/health", autheliaMiddleware(proxied.Then(handlers.HealthGet)))
+	r.GET("/api/state", autheliaMiddleware(public.Then(handlers.StateGet)))
+	r.GET("/api/portal", autheliaMiddleware(public.Then(handlers.PortalGet)))
 
 	r.GET("/api/configuration", autheliaMiddleware(
-		middlewares.RequireFirstFactor(handlers.ConfigurationGet)))
+		firstFactor.Then(handlers.ConfigurationGet)))
 
-	r.GET("/api/verify", autheliaMiddleware(handlers.VerifyGet(configuration.AuthenticationBackend)))
-	r.HEAD("/api/verify", autheliaMiddleware(handlers.VerifyGet(configuration.AuthenticationBackend)))
+	r.GET("/api/verify", autheliaMiddleware(proxied.Then(handlers.VerifyGet(configuration.AuthenticationBackend))))
+	r.HEAD("/api/verify", autheliaMiddleware(proxied.Then(handlers.VerifyGet(configuration.AuthenticationBackend))))
 
-	r.POST("/api/firstfactor", autheliaMiddleware(handlers.FirstFactorPost(1000, true)))
-	r.POST("/api/logout", autheliaMiddleware(handlers.LogoutPost))
+	r.POST("/api/firstfactor", autheliaMiddleware(publicJSON.Then(handlers.FirstFactorPost(1000, true))))
+	r.POST("/api/logout", autheliaMiddleware(publicJSON.Then(handlers.LogoutPost)))
 
 	// Only register endpoints if forgot password is not disabled.
 	if !configuration.AuthenticationBackend.DisableResetPassword {
 		// Password reset related endpoints.
 		r.POST("/api/reset-password/identity/start", autheliaMiddleware(
-			handlers.ResetPasswordIdentityStart))
+			publicJSON.Then(handlers.ResetPasswordIdentityStart)))
 		r.POST("/api/reset-password/identity/finish", autheliaMiddleware(
-			handlers.ResetPasswordIdentityFinish))
+			publicJSON.Then(handlers.ResetPasswordIdentityFinish)))
 		r.POST("/api/reset-password", autheliaMiddleware(
-			handlers.ResetPasswordPost))
+			publicJSON.Then(handlers.ResetPasswordPost)))
+		r.GET("/api/reset-password/policy", autheliaMiddleware(
+			public.Then(handlers.PasswordPolicyGet)))
+		r.POST("/api/reset-password/strength", autheliaMiddleware(
+			publicJSON.Then(handlers.PasswordStrengthPost)))
 	}
 
 	// Information about the user.
 	r.GET("/api/user/info", autheliaMiddleware(
-		middlewares.RequireFirstFactor(handlers.UserInfoGet)))
+		firstFactor.Then(handlers.UserInfoGet)))
 	r.POST("/api/user/info/2fa_method", autheliaMiddleware(
-		middlewares.RequireFirstFactor(handlers.MethodPreferencePost)))
+		firstFactorJSON.Then(handlers.MethodPreferencePost)))
+	r.GET("/api/user/events", autheliaMiddleware(
+		firstFactor.Then(handlers.UserEventsGet)))
+	r.GET("/api/user/data", autheliaMiddleware(
+		firstFactor.Then(handlers.UserDataGet)))
+	r.PUT("/api/user/totp/devices/{id}", autheliaMiddleware(
+		firstFactorJSON.Then(handlers.UserTOTPDevicePut)))
+	r.DELETE("/api/user/totp/devices/{id}", autheliaMiddleware(
+		firstFactor.Then(handlers.UserTOTPDeviceDelete)))
+	r.POST("/api/user/backup_codes", autheliaMiddleware(
+		firstFactorJSON.Then(handlers.UserBackupCodesPost)))
+	r.POST("/api/user/announcement/acknowledgment", autheliaMiddleware(
+		firstFactorJSON.Then(handlers.UserAnnouncementAcknowledgmentPost)))
+
+	if providers.EventStream != nil {
+		r.GET("/api/user/stream", autheliaMiddleware(
+			firstFactor.Then(handlers.UserStreamGet)))
+	}
+
+	// The admin endpoints authorize either the users granted a role having the permission of the operation or the admin
+	// API tokens created with the CLI granted its scope.
+	usersRead := admin(models.AdminPermissionUsersRead)
+	usersManage := admin(models.AdminPermissionUsersManage)
+	devicesDelete := admin(models.AdminPermissionUsersDevicesDelete)
+
+	r.GET("/api/admin/users/{username}/events", autheliaMiddleware(usersRead.Then(handlers.AdminUserEventsGet)))
+	r.POST("/api/admin/users/{username}/purge", autheliaMiddleware(
+		adminJSON(models.AdminPermissionUsersPurge).Then(handlers.AdminUserPurgePost)))
+	r.GET("/api/admin/users/{username}", autheliaMiddleware(usersRead.Then(handlers.AdminUserGet)))
+	r.GET("/api/admin/users/{username}/data", autheliaMiddleware(usersRead.Then(handlers.AdminUserDataGet)))
+	r.GET("/api/admin/access_review", autheliaMiddleware(usersRead.Then(handlers.AdminAccessReviewGet)))
+	r.DELETE("/api/admin/users/{username}/totp/devices/{id}", autheliaMiddleware(devicesDelete.Then(handlers.AdminUserTOTPDeviceDelete)))
+	r.DELETE("/api/admin/users/{username}/u2f/device", autheliaMiddleware(devicesDelete.Then(handlers.AdminUserU2FDeviceDelete)))
+	r.DELETE("/api/admin/users/{username}/webauthn/credentials/{id}", autheliaMiddleware(devicesDelete.Then(handlers.AdminUserWebauthnCredentialDelete)))
+	r.DELETE("/api/admin/users/{username}/method", autheliaMiddleware(usersManage.Then(handlers.AdminUserMethodDelete)))
+	r.DELETE("/api/admin/users/{username}/ban", autheliaMiddleware(usersManage.Then(handlers.AdminUserBanDelete)))
+	r.DELETE("/api/admin/users/{username}/sessions", autheliaMiddleware(usersManage.Then(handlers.AdminUserSessionsDelete)))
+
+	// The second factor resets are requested by an operator and approved by another one, or by the user by email.
+	resetsRequest := adminJSON(models.AdminPermissionSecondFactorResetsRequest)
+
+	r.POST("/api/admin/users/{username}/2fa/reset", autheliaMiddleware(resetsRequest.Then(handlers.AdminSecondFactorResetPost)))
+	r.GET("/api/admin/2fa/resets", autheliaMiddleware(usersRead.Then(handlers.AdminSecondFactorResetsGet)))
+	r.POST("/api/admin/2fa/resets/{id}/approve", autheliaMiddleware(
+		adminJSON(models.AdminPermissionSecondFactorResetsApprove).Then(handlers.AdminSecondFactorResetApprovePost)))
+	r.POST("/api/admin/2fa/resets/{id}/reject", autheliaMiddleware(resetsRequest.Then(handlers.AdminSecondFactorResetRejectPost)))
+	r.POST("/api/secondfactor/reset/approve", autheliaMiddleware(
+		firstFactorJSON.Then(handlers.SecondFactorResetApprovalPost)))
+
+	// The access grants give a guest a time-boxed access to a resource whose rule permits them.
+	if configuration.AccessGrants != nil {
+		accessGrantsManage := admin(models.AdminPermissionAccessGrantsManage)
+
+		r.GET("/api/user/access_grants", autheliaMiddleware(
+			firstFactor.Then(handlers.UserAccessGrantsGet)))
+		r.POST("/api/user/access_grants", autheliaMiddleware(
+			firstFactorJSON.Then(handlers.UserAccessGrantsPost)))
+		r.DELETE("/api/user/access_grants/{id}", autheliaMiddleware(
+			firstFactor.Then(handlers.UserAccessGrantDelete)))
+
+		r.GET("/api/admin/access_grants", autheliaMiddleware(
+			admin(models.AdminPermissionAccessGrantsRead).Then(handlers.AdminAccessGrantsGet)))
+		r.POST("/api/admin/access_grants", autheliaMiddleware(
+			adminJSON(models.AdminPermissionAccessGrantsManage).Then(handlers.AdminAccessGrantsPost)))
+		r.DELETE("/api/admin/access_grants/{id}", autheliaMiddleware(accessGrantsManage.Then(handlers.AdminAccessGrantDelete)))
+	}
+
+	// The consents the users remembered for the OpenID Connect clients, they're asked again once revoked.
+	if providers.OpenIDConnect.Fosite != nil {
+		r.GET("/api/user/oidc/consents", autheliaMiddleware(
+			firstFactor.Then(handlers.UserOIDCConsentsGet)))
+		r.DELETE("/api/user/oidc/consents/{client_id}", autheliaMiddleware(
+			firstFactor.Then(handlers.UserOIDCConsentDelete)))
+	}
+
+	// The OpenID Connect clients registered at runtime by the trusted callers, they're persisted in the storage.
+	if providers.OpenIDConnect.Fosite != nil && configuration.IdentityProviders.OIDC.DynamicRegistration != nil {
+		r.POST(handlers.OIDCRegistrationPath, autheliaMiddleware(
+			adminJSON(models.AdminPermissionOIDCClientsRegister).Then(handlers.OIDCRegistrationPost)))
+		r.GET("/api/admin/oidc/clients", autheliaMiddleware(
+			admin(models.AdminPermissionOIDCClientsRead).Then(handlers.AdminOIDCClientsGet)))
+		r.DELETE("/api/admin/oidc/clients/{id}", autheliaMiddleware(
+			admin(models.AdminPermissionOIDCClientsDelete).Then(handlers.AdminOIDCClientDelete)))
+	}
+
+	if providers.OpenIDConnect.KeyManager != nil {
+		r.GET("/api/admin/oidc/keys", autheliaMiddleware(
+			admin(models.AdminPermissionOIDCKeysRead).Then(handlers.AdminOIDCKeysGet)))
+		r.POST("/api/admin/oidc/keys/rotate", autheliaMiddleware(
+			adminJSON(models.AdminPermissionOIDCKeysRotate).Then(handlers.AdminOIDCKeyRotatePost)))
+	}
 
 	// TOTP related endpoints.
 	r.POST("/api/secondfactor/totp/identity/start", autheliaMiddleware(
-		middlewares.RequireFirstFactor(handlers.SecondFactorTOTPIdentityStart)))
+		firstFactorJSON.Then(handlers.SecondFactorTOTPIdentityStart)))
 	r.POST("/api/secondfactor/totp/identity/finish", autheliaMiddleware(
-		middlewares.RequireFirstFactor(handlers.SecondFactorTOTPIdentityFinish)))
+		firstFactorJSON.Then(handlers.SecondFactorTOTPIdentityFinish)))
 	r.POST("/api/secondfactor/totp", autheliaMiddleware(
-		middlewares.RequireFirstFactor(handlers.SecondFactorTOTPPost(&handlers.TOTPVerifierImpl{
+		firstFactorJSON.Then(handlers.SecondFactorTOTPPost(&handlers.TOTPVerifierImpl{
 			Period: uint(configuration.TOTP.Period),
 			Skew:   uint(*configuration.TOTP.Skew),
+			Clock:  utils.RealClock{},
 		}))))
 
+	// Backup code related endpoints.
+	r.POST("/api/secondfactor/backup_code", autheliaMiddleware(
+		firstFactorJSON.Then(handlers.SecondFactorBackupCodePost)))
+
 	// U2F related endpoints.
 	r.POST("/api/secondfactor/u2f/identity/start", autheliaMiddleware(
-		middlewares.RequireFirstFactor(handlers.SecondFactorU2FIdentityStart)))
+		firstFactorJSON.Then(handlers.SecondFactorU2FIdentityStart)))
 	r.POST("/api/secondfactor/u2f/identity/finish", autheliaMiddleware(
-		middlewares.RequireFirstFactor(handlers.SecondFactorU2FIdentityFinish)))
+		firstFactorJSON.Then(handlers.SecondFactorU2FIdentityFinish)))
 
 	r.POST("/api/secondfactor/u2f/register", autheliaMiddleware(
-		middlewares.RequireFirstFactor(handlers.SecondFactorU2FRegister)))
+		firstFactorJSON.Then(handlers.SecondFactorU2FRegister)))
 
 	r.POST("/api/secondfactor/u2f/sign_request", autheliaMiddleware(
-		middlewares.RequireFirstFactor(handlers.SecondFactorU2FSignGet)))
+		firstFactorJSON.Then(handlers.SecondFactorU2FSignGet)))
 
 	r.POST("/api/secondfactor/u2f/sign", autheliaMiddleware(
-		middlewares.RequireFirstFactor(handlers.SecondFactorU2FSignPost(&handlers.U2FVerifierImpl{}))))
+		firstFactorJSON.Then(handlers.SecondFactorU2FSignPost(&handlers.U2FVerifierImpl{}))))
+
+	// WebAuthn related endpoints.
+	r.POST("/api/secondfactor/webauthn/identity/start", autheliaMiddleware(
+		firstFactorJSON.Then(handlers.SecondFactorWebauthnIdentityStart)))
+	r.POST("/api/secondfactor/webauthn/identity/finish", autheliaMiddleware(
+		firstFactorJSON.Then(handlers.SecondFactorWebauthnIdentityFinish)))
+
+	r.POST("/api/secondfactor/webauthn/register", autheliaMiddleware(
+		firstFactorJSON.Then(handlers.SecondFactorWebauthnRegister)))
+
+	r.POST("/api/secondfactor/webauthn/assertion/options", autheliaMiddleware(
+		firstFactorJSON.Then(handlers.SecondFactorWebauthnAssertionOptionsPost)))
+
+	r.POST("/api/secondfactor/webauthn", autheliaMiddleware(
+		firstFactorJSON.Then(handlers.SecondFactorWebauthnPost)))
+
+	// Passkey related endpoints, only registered if passwordless login is enabled.
+	if configuration.Webauthn.Passwordless.Enable {
+		r.POST("/api/firstfactor/passkey/options", autheliaMiddleware(publicJSON.Then(handlers.FirstFactorPasskeyOptionsPost)))
+		r.POST("/api/firstfactor/passkey", autheliaMiddleware(publicJSON.Then(handlers.FirstFactorPasskeyPost)))
+
+		r.POST("/api/webauthn/register/options", autheliaMiddleware(
+			firstFactorJSON.Then(handlers.WebauthnRegistrationOptionsPost)))
+		r.POST("/api/webauthn/register", autheliaMiddleware(
+			firstFactorJSON.Then(handlers.WebauthnRegistrationPost)))
+	}
 
 	// Configure DUO api endpoint only if configuration exists.
 	if configuration.DuoAPI != nil {
-		var duoAPI duo.API
+		var duoClient *duoapi.DuoApi
+
+		proxy := duoapi.SetProxy(utils.NewProxyFunc(configuration.OutboundProxy))
+		policy := duoapi.SetTransport(utils.NewOutboundPolicy(configuration.OutboundRequests).Apply)
+
 		if os.Getenv("ENVIRONMENT") == dev {
-			duoAPI = duo.NewDuoAPI(duoapi.NewDuoApi(
+			duoClient = duoapi.NewDuoApi(
 				configuration.DuoAPI.IntegrationKey,
 				configuration.DuoAPI.SecretKey,
-				configuration.DuoAPI.Hostname, "", duoapi.SetInsecure()))
+				configuration.DuoAPI.Hostname, "", proxy, policy, duoapi.SetInsecure())
 		} else {
-			duoAPI = duo.NewDuoAPI(duoapi.NewDuoApi(
+			duoClient = duoapi.NewDuoApi(
 				configuration.DuoAPI.IntegrationKey,
 				configuration.DuoAPI.SecretKey,
-				configuration.DuoAPI.Hostname, ""))
+				configuration.DuoAPI.Hostname, "", proxy, policy)
 		}
 
+		duoAPI := duo.NewDuoAPI(duoClient)
+		pushProvider := push.NewDuoProvider(duoClient)
+
 		r.POST("/api/secondfactor/duo", autheliaMiddleware(
-			middlewares.RequireFirstFactor(handlers.SecondFactorDuoPost(duoAPI))))
+			firstFactorJSON.Then(handlers.SecondFactorDuoPost(duoAPI))))
+		r.POST("/api/secondfactor/push", autheliaMiddleware(
+			firstFactorJSON.Then(handlers.SecondFactorPushPost(pushProvider))))
+		r.POST("/api/secondfactor/push/status", autheliaMiddleware(
+			firstFactorJSON.Then(handlers.SecondFactorPushStatusPost(pushProvider))))
 	}
 
 	if configuration.Server.EnablePprof {
@@ -135,6 +304,13 @@ func registerRoutes(configuration schema.Configuration, providers middlewares.Pr
 		r.GET("/debug/vars", expvarhandler.ExpvarHandler)
 	}
 
+	// The preflight requests are answered by the CORS middleware, the router doesn't dispatch them to the routes.
+	if configuration.Server.CORS != nil {
+		r.GlobalOPTIONS = autheliaMiddleware(public.Then(func(ctx *middlewares.AutheliaCtx) {
+			ctx.SetStatusCode(fasthttp.StatusNoContent)
+		}))
+	}
+
 	r.NotFound = serveIndexHandler
 
 	handler := middlewares.LogRequestMiddleware(r.Handler)
@@ -142,6 +318,14 @@ func registerRoutes(configuration schema.Configuration, providers middlewares.Pr
 		handler = middlewares.StripPathMiddleware(handler)
 	}
 
+	if configuration.Server.Compression != nil {
+		handler = middlewares.CompressionMiddleware(*configuration.Server.Compression, handler)
+	}
+
+	if configuration.Metrics != nil {
+		handler = middlewares.MetricsMiddleware(handler)
+	}
+
 	if providers.OpenIDConnect.Fosite != nil {
 		handlers.RegisterOIDC(r, autheliaMiddleware)
 	}
@@ -149,11 +333,17 @@ func registerRoutes(configuration schema.Configuration, providers middlewares.Pr
 	return handler
 }
 
-// StartServer start Authelia server with the given configuration and providers.
-func StartServer(configuration schema.Configuration, providers middlewares.Providers) {
+// StartServer start Authelia server with the given configuration and providers. The optional interceptors are applied
+// to every Authelia handler before any route specific middleware.
+func StartServer(configuration schema.Configuration, providers middlewares.Providers, interceptors ...middlewares.Middleware) {
 	logger := logging.Logger()
 
-	handler := registerRoutes(configuration, providers)
+	handler := registerRoutes(configuration, providers, interceptors)
+
+	// The timeouts have already been validated.
+	readTimeout, _ := utils.ParseDurationString(configuration.Server.ReadTimeout)
+	writeTimeout, _ := utils.ParseDurationString(configuration.Server.WriteTimeout)
+	idleTimeout, _ := utils.ParseDurationString(configuration.Server.IdleTimeout)
 
 	server := &fasthttp.Server{
 		ErrorHandler:          autheliaErrorHandler,
@@ -161,15 +351,24 @@ func StartServer(configuration schema.Configuration, providers middlewares.Provi
 		NoDefaultServerHeader: true,
 		ReadBufferSize:        configuration.Server.ReadBufferSize,
 		WriteBufferSize:       configuration.Server.WriteBufferSize,
+		MaxConnsPerIP:         configuration.Server.MaxConnectionsPerIP,
+		MaxRequestBodySize:    configuration.Server.MaxRequestBodySize,
+		ReadTimeout:           readTimeout,
+		WriteTimeout:          writeTimeout,
+		IdleTimeout:           idleTimeout,
+		DisableKeepalive:      configuration.Server.DisableKeepAlive,
 	}
 
 	addrPattern := net.JoinHostPort(configuration.Host, strconv.Itoa(configuration.Port))
 
-	listener, err := net.Listen("tcp", addrPattern)
+	listener, err := net.Listen(utils.ListenNetwork(configuration.Server.AddressFamily), addrPattern)
 	if err != nil {
 		logger.Fatalf("Error initializing listener: %s", err)
 	}
 
+	// The connections are queued by the listener until the server serves them.
+	daemon.NotifyReady()
+
 	// TODO(clems4ever): move that piece to a more related location, probably in the configuration package.
 	if configuration.AuthenticationBackend.File != nil && configuration.AuthenticationBackend.File.Password.Algorithm == "argon2id" && runtime.GOOS == "linux" {
 		f, err := ioutil.ReadFile("/sys/fs/cgroup/memory/memory.limit_in_bytes")
@@ -189,7 +388,19 @@ func StartServer(configuration schema.Configuration, providers middlewares.Provi
 
 	if configuration.TLSCert != "" && configuration.TLSKey != "" {
 		logger.Infof("Authelia is listening for TLS connections on %s%s", addrPattern, configuration.Server.Path)
-		logger.Fatal(server.ServeTLS(listener, configuration.TLSCert, configuration.TLSKey))
+
+		if tlsConfig := utils.NewServerTLSConfig(configuration.CryptoProfile); tlsConfig != nil {
+			certificate, err := tls.LoadX509KeyPair(configuration.TLSCert, configuration.TLSKey)
+			if err != nil {
+				logger.Fatalf("Unable to load the TLS certificate: %s", err)
+			}
+
+			tlsConfig.Certificates = []tls.Certificate{certificate}
+
+			logger.Fatal(server.Serve(tls.NewListener(listener, tlsConfig)))
+		} else {
+			logger.Fatal(server.ServeTLS(listener, configuration.TLSCert, configuration.TLSKey))
+		}
 	} else {
 		logger.Infof("Authelia is listening for non-TLS connections on %s%s", addrPattern, configuration.Server.Path)
 		logger.Fatal(server.Serve(listener))
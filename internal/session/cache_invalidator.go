@@ -0,0 +1,89 @@
+package session
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/logging"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// RedisCacheInvalidator propagates the invalidations of the storage cache to the other instances of Authelia through a
+// redis pub/sub channel of the redis server storing the sessions.
+type RedisCacheInvalidator struct {
+	client  redis.UniversalClient
+	channel string
+}
+
+// NewRedisCacheInvalidator creates a RedisCacheInvalidator connected to the redis server storing the sessions, it
+// returns nil when the sessions are kept in memory since Authelia can't run on several instances in that case.
+func NewRedisCacheInvalidator(configuration schema.SessionConfiguration, certPool *utils.X509CertPool) *RedisCacheInvalidator {
+	providerConfig := NewProviderConfig(configuration, certPool)
+
+	var client redis.UniversalClient
+
+	switch {
+	case providerConfig.redisConfig != nil:
+		config := providerConfig.redisConfig
+
+		client = redis.NewClient(&redis.Options{
+			Network:   config.Network,
+			Addr:      config.Addr,
+			Username:  config.Username,
+			Password:  config.Password,
+			DB:        config.DB,
+			TLSConfig: config.TLSConfig,
+		})
+	case providerConfig.redisSentinelConfig != nil:
+		config := providerConfig.redisSentinelConfig
+
+		client = redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:       config.MasterName,
+			SentinelAddrs:    config.SentinelAddrs,
+			SentinelPassword: config.SentinelPassword,
+			Username:         config.Username,
+			Password:         config.Password,
+			DB:               config.DB,
+			TLSConfig:        config.TLSConfig,
+		})
+	default:
+		return nil
+	}
+
+	return &RedisCacheInvalidator{
+		client:  client,
+		channel: configuration.Redis.KeyPrefix + storageCacheInvalidationChannel,
+	}
+}
+
+// Publish publishes the invalidation of the cached entries of the user.
+func (i *RedisCacheInvalidator) Publish(username string) error {
+	return i.client.Publish(context.Background(), i.channel, username).Err()
+}
+
+// Subscribe calls invalidate for each invalidation published by any instance, the subscription is restored by the
+// client when the connection to redis is lost.
+func (i *RedisCacheInvalidator) Subscribe(invalidate func(username string)) error {
+	ctx := context.Background()
+
+	pubsub := i.client.Subscribe(ctx, i.channel)
+
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+
+		return fmt.Errorf("unable to subscribe to the redis channel %s: %w", i.channel, err)
+	}
+
+	go func() {
+		for message := range pubsub.Channel() {
+			invalidate(message.Payload)
+		}
+
+		logging.Logger().Warnf("The subscription to the redis channel %s has been closed", i.channel)
+	}()
+
+	return nil
+}
@@ -2,7 +2,14 @@ package session
 
 const userSessionStorerKey = "UserSession"
 
+// startupCheckSessionPrefix prefixes the ID of the session saved by the startup check.
+const startupCheckSessionPrefix = "startup-check-"
+
 const testDomain = "example.com"
 const testExpiration = "40"
 const testName = "my_session"
 const testUsername = "john"
+
+// storageCacheInvalidationChannel is the redis channel, prefixed by the key prefix, where the invalidations of the
+// storage cache are published.
+const storageCacheInvalidationChannel = ":storage-cache-invalidation"
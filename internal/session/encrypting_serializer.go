@@ -3,6 +3,7 @@ package session
 import (
 	"crypto/sha256"
 	"fmt"
+	"time"
 
 	"github.com/fasthttp/session/v2"
 
@@ -11,13 +12,64 @@ import (
 
 // EncryptingSerializer a serializer encrypting the data with AES-GCM with 256-bit keys.
 type EncryptingSerializer struct {
-	key [32]byte
+	key *utils.SecureBuffer
+
+	// previousKey decrypts the sessions encrypted before the secret was rotated until previousExpiresAt, the sessions
+	// are encrypted again with the key when they're saved.
+	previousKey       *utils.SecureBuffer
+	previousExpiresAt time.Time
+	clock             utils.Clock
 }
 
 // NewEncryptingSerializer return new encrypt instance.
 func NewEncryptingSerializer(secret string) *EncryptingSerializer {
+	return &EncryptingSerializer{key: newSessionKeyBuffer(secret)}
+}
+
+// NewRotatingEncryptingSerializer returns an encrypt instance which also decrypts the sessions encrypted with the
+// previous secret until it expires.
+func NewRotatingEncryptingSerializer(secret, previousSecret string, previousExpiresAt time.Time, clock utils.Clock) *EncryptingSerializer {
+	return &EncryptingSerializer{
+		key:               newSessionKeyBuffer(secret),
+		previousKey:       newSessionKeyBuffer(previousSecret),
+		previousExpiresAt: previousExpiresAt,
+		clock:             clock,
+	}
+}
+
+func newSessionKeyBuffer(secret string) *utils.SecureBuffer {
 	key := sha256.Sum256([]byte(secret))
-	return &EncryptingSerializer{key}
+	defer utils.WipeBytes(key[:])
+
+	return utils.NewSecureBuffer(key[:])
+}
+
+// sessionKey copies the key to the array expected by the cipher functions, the copy must be wiped after use.
+func sessionKey(buffer *utils.SecureBuffer) (key *[32]byte) {
+	key = new([32]byte)
+	copy(key[:], buffer.Reveal())
+
+	return key
+}
+
+// decrypt decrypts the session with the key, or with the previous key when it hasn't expired yet.
+func (e *EncryptingSerializer) decrypt(src []byte) (decrypted []byte, err error) {
+	key := sessionKey(e.key)
+	defer utils.WipeBytes(key[:])
+
+	decrypted, err = utils.Decrypt(src, key)
+	if err == nil || e.previousKey == nil || !e.clock.Now().Before(e.previousExpiresAt) {
+		return decrypted, err
+	}
+
+	previousKey := sessionKey(e.previousKey)
+	defer utils.WipeBytes(previousKey[:])
+
+	if decrypted, previousErr := utils.Decrypt(src, previousKey); previousErr == nil {
+		return decrypted, nil
+	}
+
+	return nil, err
 }
 
 // Encode encode and encrypt session.
@@ -31,7 +83,10 @@ func (e *EncryptingSerializer) Encode(src session.Dict) ([]byte, error) {
 		return nil, fmt.Errorf("unable to marshal session: %v", err)
 	}
 
-	encryptedDst, err := utils.Encrypt(dst, &e.key)
+	key := sessionKey(e.key)
+	defer utils.WipeBytes(key[:])
+
+	encryptedDst, err := utils.Encrypt(dst, key)
 	if err != nil {
 		return nil, fmt.Errorf("unable to encrypt session: %v", err)
 	}
@@ -47,7 +102,7 @@ func (e *EncryptingSerializer) Decode(dst *session.Dict, src []byte) error {
 
 	dst.Reset()
 
-	decryptedSrc, err := utils.Decrypt(src, &e.key)
+	decryptedSrc, err := e.decrypt(src)
 	if err != nil {
 		// If an error is thrown while decrypting, it's probably an old unencrypted session
 		// so we just unmarshall it without decrypting. It's a way to avoid a breaking change
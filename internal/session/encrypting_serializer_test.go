@@ -2,11 +2,14 @@ package session
 
 import (
 	"testing"
+	"time"
 
 	"github.com/fasthttp/session/v2"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/utils"
 )
 
 func TestShouldEncryptAndDecrypt(t *testing.T) {
@@ -44,3 +47,31 @@ func TestShouldSupportUnencryptedSessionForBackwardCompatibility(t *testing.T) {
 
 	assert.Equal(t, "value", decodedPayload.Get("key"))
 }
+
+func TestShouldDecryptWithPreviousSecretUntilItExpires(t *testing.T) {
+	payload := session.Dict{}
+	payload.Set("key", "value")
+
+	encryptedDst, err := NewEncryptingSerializer("previous").Encode(payload)
+	require.NoError(t, err)
+
+	clock := utils.NewFakeClock(time.Unix(1600000000, 0))
+	serializer := NewRotatingEncryptingSerializer("asecret", "previous", clock.Now().Add(time.Hour), clock)
+
+	decodedPayload := session.Dict{}
+	require.NoError(t, serializer.Decode(&decodedPayload, encryptedDst))
+	assert.Equal(t, "value", decodedPayload.Get("key"))
+
+	// The session is encrypted with the new secret when it's saved.
+	reencryptedDst, err := serializer.Encode(decodedPayload)
+	require.NoError(t, err)
+
+	decodedPayload = session.Dict{}
+	require.NoError(t, NewEncryptingSerializer("asecret").Decode(&decodedPayload, reencryptedDst))
+	assert.Equal(t, "value", decodedPayload.Get("key"))
+
+	clock.Advance(time.Hour)
+
+	decodedPayload = session.Dict{}
+	assert.EqualError(t, serializer.Decode(&decodedPayload, encryptedDst), "unable to decrypt session: cipher: message authentication failed")
+}
@@ -1,8 +1,12 @@
 package session
 
 import (
-	"crypto/x509"
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	fasthttpsession "github.com/fasthttp/session/v2"
@@ -12,18 +16,20 @@ import (
 
 	"github.com/authelia/authelia/internal/configuration/schema"
 	"github.com/authelia/authelia/internal/logging"
+	"github.com/authelia/authelia/internal/metrics"
 	"github.com/authelia/authelia/internal/utils"
 )
 
 // Provider a session provider.
 type Provider struct {
 	sessionHolder *fasthttpsession.Session
+	backend       fasthttpsession.Provider
 	RememberMe    time.Duration
 	Inactivity    time.Duration
 }
 
 // NewProvider instantiate a session provider given a configuration.
-func NewProvider(configuration schema.SessionConfiguration, certPool *x509.CertPool) *Provider {
+func NewProvider(configuration schema.SessionConfiguration, certPool *utils.X509CertPool) *Provider {
 	providerConfig := NewProviderConfig(configuration, certPool)
 
 	provider := new(Provider)
@@ -51,12 +57,12 @@ func NewProvider(configuration schema.SessionConfiguration, certPool *x509.CertP
 	case providerConfig.redisConfig != nil:
 		providerImpl, err = redis.New(*providerConfig.redisConfig)
 		if err != nil {
-			logger.Fatal(err)
+			logger.Fatal(newRedisConnectionError(configuration.Redis, err))
 		}
 	case providerConfig.redisSentinelConfig != nil:
 		providerImpl, err = redis.NewFailoverCluster(*providerConfig.redisSentinelConfig)
 		if err != nil {
-			logger.Fatal(err)
+			logger.Fatal(newRedisConnectionError(configuration.Redis, err))
 		}
 	default:
 		providerImpl, err = memory.New(memory.Config{})
@@ -70,11 +76,67 @@ func NewProvider(configuration schema.SessionConfiguration, certPool *x509.CertP
 		logger.Fatal(err)
 	}
 
+	provider.backend = providerImpl
+
 	return provider
 }
 
+// newRedisConnectionError explains why the connection to redis failed with the username and database index used.
+func newRedisConnectionError(configuration *schema.RedisSessionConfiguration, err error) error {
+	username := configuration.Username
+	if username == "" {
+		username = "default"
+	}
+
+	message := err.Error()
+
+	var hint string
+
+	switch {
+	case strings.Contains(message, "WRONGPASS"):
+		hint = "the username or password is incorrect"
+	case strings.Contains(message, "NOAUTH"):
+		hint = "redis requires a password"
+	case strings.Contains(message, "wrong number of arguments for 'auth'"):
+		hint = "the username is only supported by redis 6.0 and above"
+	case strings.Contains(message, "DB index is out of range"):
+		hint = "the database_index is greater than the number of databases of redis"
+	default:
+		return fmt.Errorf("unable to connect to redis as user '%s' with database index %d: %w", username, configuration.DatabaseIndex, err)
+	}
+
+	return fmt.Errorf("unable to connect to redis as user '%s' with database index %d, %s: %w", username, configuration.DatabaseIndex, hint, err)
+}
+
+// StartupCheck saves, loads and destroys a session to check the backend storing the sessions, e.g. redis, is ready.
+func (p *Provider) StartupCheck() (err error) {
+	id := []byte(startupCheckSessionPrefix + utils.RandomString(16, utils.AlphaNumericCharacters))
+	data := []byte(utils.RandomString(16, utils.AlphaNumericCharacters))
+
+	if err = p.backend.Save(id, data, time.Minute); err != nil {
+		return fmt.Errorf("unable to save a session: %w", err)
+	}
+
+	saved, err := p.backend.Get(id)
+	if err != nil {
+		return fmt.Errorf("unable to load a session: %w", err)
+	}
+
+	if !bytes.Equal(saved, data) {
+		return errors.New("the session loaded does not match the session saved")
+	}
+
+	if err = p.backend.Destroy(id); err != nil {
+		return fmt.Errorf("unable to destroy a session: %w", err)
+	}
+
+	return nil
+}
+
 // GetSession return the user session from a request.
-func (p *Provider) GetSession(ctx *fasthttp.RequestCtx) (UserSession, error) {
+func (p *Provider) GetSession(ctx *fasthttp.RequestCtx) (userSession UserSession, err error) {
+	defer observeOperation("get", time.Now(), &err)
+
 	store, err := p.sessionHolder.Get(ctx)
 
 	if err != nil {
@@ -86,14 +148,13 @@ func (p *Provider) GetSession(ctx *fasthttp.RequestCtx) (UserSession, error) {
 	// If userSession is not yet defined we create the new session with default values
 	// and save it in the store.
 	if !ok {
-		userSession := NewDefaultUserSession()
+		userSession = NewDefaultUserSession()
 
 		store.Set(userSessionStorerKey, userSession)
 
 		return userSession, nil
 	}
 
-	var userSession UserSession
 	err = json.Unmarshal(userSessionJSON, &userSession)
 
 	if err != nil {
@@ -104,7 +165,9 @@ func (p *Provider) GetSession(ctx *fasthttp.RequestCtx) (UserSession, error) {
 }
 
 // SaveSession save the user session.
-func (p *Provider) SaveSession(ctx *fasthttp.RequestCtx, userSession UserSession) error {
+func (p *Provider) SaveSession(ctx *fasthttp.RequestCtx, userSession UserSession) (err error) {
+	defer observeOperation("save", time.Now(), &err)
+
 	store, err := p.sessionHolder.Get(ctx)
 
 	if err != nil {
@@ -129,19 +192,25 @@ func (p *Provider) SaveSession(ctx *fasthttp.RequestCtx, userSession UserSession
 }
 
 // RegenerateSession regenerate a session ID.
-func (p *Provider) RegenerateSession(ctx *fasthttp.RequestCtx) error {
-	err := p.sessionHolder.Regenerate(ctx)
+func (p *Provider) RegenerateSession(ctx *fasthttp.RequestCtx) (err error) {
+	defer observeOperation("regenerate", time.Now(), &err)
+
+	err = p.sessionHolder.Regenerate(ctx)
 
 	return err
 }
 
 // DestroySession destroy a session ID and delete the cookie.
-func (p *Provider) DestroySession(ctx *fasthttp.RequestCtx) error {
+func (p *Provider) DestroySession(ctx *fasthttp.RequestCtx) (err error) {
+	defer observeOperation("destroy", time.Now(), &err)
+
 	return p.sessionHolder.Destroy(ctx)
 }
 
 // UpdateExpiration update the expiration of the cookie and session.
-func (p *Provider) UpdateExpiration(ctx *fasthttp.RequestCtx, expiration time.Duration) error {
+func (p *Provider) UpdateExpiration(ctx *fasthttp.RequestCtx, expiration time.Duration) (err error) {
+	defer observeOperation("update_expiration", time.Now(), &err)
+
 	store, err := p.sessionHolder.Get(ctx)
 
 	if err != nil {
@@ -167,3 +236,8 @@ func (p *Provider) GetExpiration(ctx *fasthttp.RequestCtx) (time.Duration, error
 
 	return store.GetExpiration(), nil
 }
+
+// observeOperation measures the duration of an operation on the session store started at the given time.
+func observeOperation(operation string, start time.Time, err *error) {
+	metrics.SessionOperationDuration.Observe(time.Since(start).Seconds(), operation, strconv.FormatBool(*err == nil))
+}
@@ -2,9 +2,9 @@ package session
 
 import (
 	"crypto/tls"
-	"crypto/x509"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/fasthttp/session/v2"
 	"github.com/fasthttp/session/v2/providers/redis"
@@ -15,7 +15,7 @@ import (
 )
 
 // NewProviderConfig creates a configuration for creating the session provider.
-func NewProviderConfig(configuration schema.SessionConfiguration, certPool *x509.CertPool) ProviderConfig {
+func NewProviderConfig(configuration schema.SessionConfiguration, certPool *utils.X509CertPool) ProviderConfig {
 	config := session.NewDefaultConfig()
 
 	// Override the cookie name.
@@ -56,7 +56,7 @@ func NewProviderConfig(configuration schema.SessionConfiguration, certPool *x509
 	// If redis configuration is provided, then use the redis provider.
 	switch {
 	case configuration.Redis != nil:
-		serializer := NewEncryptingSerializer(configuration.Secret)
+		serializer := newEncryptingSerializer(configuration)
 
 		var tlsConfig *tls.Config
 
@@ -92,7 +92,7 @@ func NewProviderConfig(configuration schema.SessionConfiguration, certPool *x509
 				MinIdleConns:     configuration.Redis.MinimumIdleConnections,
 				IdleTimeout:      300,
 				TLSConfig:        tlsConfig,
-				KeyPrefix:        "authelia-session",
+				KeyPrefix:        configuration.Redis.KeyPrefix,
 			}
 		} else {
 			providerName = "redis"
@@ -100,7 +100,7 @@ func NewProviderConfig(configuration schema.SessionConfiguration, certPool *x509
 
 			var addr string
 
-			if configuration.Redis.Port == 0 {
+			if strings.HasPrefix(configuration.Redis.Host, "/") {
 				network = "unix"
 				addr = configuration.Redis.Host
 			} else {
@@ -117,7 +117,7 @@ func NewProviderConfig(configuration schema.SessionConfiguration, certPool *x509
 				MinIdleConns: configuration.Redis.MinimumIdleConnections,
 				IdleTimeout:  300,
 				TLSConfig:    tlsConfig,
-				KeyPrefix:    "authelia-session",
+				KeyPrefix:    configuration.Redis.KeyPrefix,
 			}
 		}
 
@@ -134,3 +134,17 @@ func NewProviderConfig(configuration schema.SessionConfiguration, certPool *x509
 		providerName,
 	}
 }
+
+// newEncryptingSerializer creates the serializer of the sessions, the sessions encrypted with the previous secret are
+// accepted during its lifespan when the secret is being rotated.
+func newEncryptingSerializer(configuration schema.SessionConfiguration) *EncryptingSerializer {
+	if configuration.PreviousSecret == "" {
+		return NewEncryptingSerializer(configuration.Secret)
+	}
+
+	// Ignore the error as it will be handled by validator.
+	lifespan, _ := utils.ParseDurationString(configuration.PreviousSecretLifespan)
+
+	return NewRotatingEncryptingSerializer(configuration.Secret, configuration.PreviousSecret,
+		time.Now().Add(lifespan), utils.RealClock{})
+}
@@ -238,6 +238,51 @@ func TestShouldCreateRedisSessionProviderWithUnixSocket(t *testing.T) {
 	assert.Nil(t, pConfig.TLSConfig)
 }
 
+func TestShouldCreateRedisSessionProviderWithUnixSocketTLS(t *testing.T) {
+	configuration := schema.SessionConfiguration{}
+	configuration.Domain = testDomain
+	configuration.Name = testName
+	configuration.Expiration = testExpiration
+	configuration.Redis = &schema.RedisSessionConfiguration{
+		Host:     "/var/run/redis/redis.sock",
+		Password: "pass",
+		TLS: &schema.TLSConfig{
+			ServerName: "redis.example.com",
+		},
+	}
+
+	providerConfig := NewProviderConfig(configuration, nil)
+
+	pConfig := providerConfig.redisConfig
+	assert.Equal(t, "unix", pConfig.Network)
+	assert.Equal(t, "/var/run/redis/redis.sock", pConfig.Addr)
+
+	require.NotNil(t, pConfig.TLSConfig)
+	assert.Equal(t, "redis.example.com", pConfig.TLSConfig.ServerName)
+}
+
+func TestShouldSetRedisKeyPrefix(t *testing.T) {
+	configuration := schema.SessionConfiguration{}
+	configuration.Domain = testDomain
+	configuration.Name = testName
+	configuration.Expiration = testExpiration
+	configuration.Redis = &schema.RedisSessionConfiguration{
+		Host:      "redis.example.com",
+		Port:      6379,
+		KeyPrefix: "authelia-staging",
+	}
+
+	providerConfig := NewProviderConfig(configuration, nil)
+	assert.Equal(t, "authelia-staging", providerConfig.redisConfig.KeyPrefix)
+
+	configuration.Redis.HighAvailability = &schema.RedisHighAvailabilityConfiguration{
+		SentinelName: "sentinel",
+	}
+
+	providerConfig = NewProviderConfig(configuration, nil)
+	assert.Equal(t, "authelia-staging", providerConfig.redisSentinelConfig.KeyPrefix)
+}
+
 func TestShouldSetDbNumber(t *testing.T) {
 	configuration := schema.SessionConfiguration{}
 	configuration.Domain = testDomain
@@ -1,6 +1,7 @@
 package session
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -154,3 +155,31 @@ func TestShouldDestroySessionAndWipeSessionData(t *testing.T) {
 	assert.Equal(t, "", newUserSession.Username)
 	assert.Equal(t, authentication.NotAuthenticated, newUserSession.AuthenticationLevel)
 }
+
+func TestShouldExplainRedisConnectionErrors(t *testing.T) {
+	configuration := &schema.RedisSessionConfiguration{
+		Username:      "authelia",
+		DatabaseIndex: 20,
+	}
+
+	err := newRedisConnectionError(configuration, errors.New("Redis connection error: WRONGPASS invalid username-password pair or user is disabled."))
+	assert.EqualError(t, err, "unable to connect to redis as user 'authelia' with database index 20, the username or password is incorrect: Redis connection error: WRONGPASS invalid username-password pair or user is disabled.")
+
+	err = newRedisConnectionError(configuration, errors.New("Redis connection error: ERR DB index is out of range"))
+	assert.EqualError(t, err, "unable to connect to redis as user 'authelia' with database index 20, the database_index is greater than the number of databases of redis: Redis connection error: ERR DB index is out of range")
+
+	err = newRedisConnectionError(&schema.RedisSessionConfiguration{}, errors.New("Redis connection error: dial tcp 127.0.0.1:6379: connect: connection refused"))
+	assert.EqualError(t, err, "unable to connect to redis as user 'default' with database index 0: Redis connection error: dial tcp 127.0.0.1:6379: connect: connection refused")
+}
+
+func TestShouldSaveAndDestroySessionOnStartupCheck(t *testing.T) {
+	configuration := schema.SessionConfiguration{}
+	configuration.Domain = testDomain
+	configuration.Name = testName
+	configuration.Expiration = testExpiration
+
+	provider := NewProvider(configuration, nil)
+
+	require.NoError(t, provider.StartupCheck())
+	assert.Equal(t, 0, provider.backend.Count())
+}
@@ -40,6 +40,27 @@ type UserSession struct {
 	FirstFactorAuthnTimestamp  int64
 	SecondFactorAuthnTimestamp int64
 
+	// The client which performed the first and second factor authentication respectively.
+	FirstFactorClient  *ClientMetadata
+	SecondFactorClient *ClientMetadata
+
+	// The network and TLS fingerprint of the client the session is pinned to when the session pinning is enabled, the
+	// session is invalidated when they change.
+	Pin *Pin
+
+	// The country the session was last used from and the unix timestamp it was last used from there when the impossible
+	// travel detection is enabled, the session is invalidated when it's used from another country too soon after.
+	LastCountry          string
+	LastCountryTimestamp int64
+
+	// StepUpRequired is set when the risk engine or the login of a break-glass account requires the user to complete
+	// the second factor before accessing any protected resource, even those only requiring one factor.
+	StepUpRequired bool
+
+	// BreakGlassExpiresAt is the unix timestamp the window of the break-glass account the user logged in with elapses
+	// at, the session isn't valid anymore then. It's zero for the other users.
+	BreakGlassExpiresAt int64
+
 	// The challenge generated in first step of U2F registration (after identity verification) or authentication.
 	// This is used reused in the second phase to check that the challenge has been completed.
 	U2FChallenge *u2f.Challenge
@@ -47,9 +68,31 @@ type UserSession struct {
 	// This is used in second phase of a U2F authentication.
 	U2FRegistration *U2FRegistration
 
+	// The challenge generated when a WebAuthn registration or passkey login is initiated, it's verified and cleared
+	// when the ceremony is completed.
+	WebauthnChallenge []byte
+	// The challenge generated after the identity verification of a WebAuthn second factor registration. It's kept apart
+	// from WebauthnChallenge so a challenge generated by another ceremony can't register a credential without the
+	// identity verification.
+	WebauthnRegistrationChallenge []byte
+	// The ID of the passkey the user logged in with, it can't be used as the second factor of the same session.
+	FirstFactorWebauthnCredentialID []byte
+
+	// The ID of the transaction of the push notification sent to the devices of the user, it's polled until the user
+	// approved or denied it and cleared then.
+	PushTransactionID string
+
 	// Represent an OIDC workflow session initiated by the client if not null.
 	OIDCWorkflowSession *OIDCWorkflowSession
 
+	// The ID of the session given to the OpenID Connect clients in the sid claim and the clients the user authorized
+	// during the session, they're logged out when the session ends.
+	OIDCSessionID string
+	OIDCClients   []string
+
+	// The URI registered by the client which initiated the logout the user is redirected to once logged out.
+	OIDCPostLogoutRedirectURI string
+
 	// This boolean is set to true after identity verification and checked
 	// while doing the query actually updating the password.
 	PasswordResetUsername *string
@@ -57,6 +100,21 @@ type UserSession struct {
 	RefreshTTL time.Time
 }
 
+// ClientMetadata describes the client which performed an authentication step.
+type ClientMetadata struct {
+	RemoteIP  string
+	Location  string
+	UserAgent string
+	Device    string
+	Timestamp int64
+}
+
+// Pin describes the client a session is pinned to, the empty values aren't pinned.
+type Pin struct {
+	IPNetwork      string
+	TLSFingerprint string
+}
+
 // Identity identity of the user who is being verified.
 type Identity struct {
 	Username string
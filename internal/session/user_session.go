@@ -38,6 +38,37 @@ func (s *UserSession) SetTwoFactor(now time.Time) {
 	s.AuthenticationLevel = authentication.TwoFactor
 }
 
+// SetFirstFactorClient sets the metadata of the client which performed the first factor authentication.
+func (s *UserSession) SetFirstFactorClient(client ClientMetadata) {
+	s.FirstFactorClient = &client
+}
+
+// SetSecondFactorClient sets the metadata of the client which performed the second factor authentication.
+func (s *UserSession) SetSecondFactorClient(client ClientMetadata) {
+	s.SecondFactorClient = &client
+}
+
+// IsPinMismatched returns true when the session is pinned to another client than the one described by the given pin.
+// The sessions created before the pinning was enabled aren't pinned, they're never mismatched.
+func (s UserSession) IsPinMismatched(pin *Pin) bool {
+	if s.Pin == nil || pin == nil {
+		return false
+	}
+
+	return (s.Pin.IPNetwork != "" && s.Pin.IPNetwork != pin.IPNetwork) ||
+		(s.Pin.TLSFingerprint != "" && s.Pin.TLSFingerprint != pin.TLSFingerprint)
+}
+
+// IsStepUpPending returns true when the risk engine required the second factor and the user hasn't completed it yet.
+func (s UserSession) IsStepUpPending() bool {
+	return s.StepUpRequired && s.AuthenticationLevel < authentication.TwoFactor
+}
+
+// IsBreakGlassExpired returns true when the user logged in with a break-glass account whose window has elapsed.
+func (s UserSession) IsBreakGlassExpired(now time.Time) bool {
+	return s.BreakGlassExpiresAt != 0 && now.Unix() >= s.BreakGlassExpiresAt
+}
+
 // AuthenticatedTime returns the unix timestamp this session authenticated successfully at the given level.
 func (s UserSession) AuthenticatedTime(level authorization.Level) (authenticatedTime time.Time, err error) {
 	switch level {
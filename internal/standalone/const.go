@@ -0,0 +1,73 @@
+package standalone
+
+const (
+	configurationFileName = "configuration.yml"
+	usersDatabaseFileName = "users_database.yml"
+	storageFileName       = "db.sqlite3"
+	notificationFileName  = "notification.txt"
+)
+
+const (
+	adminUsername       = "admin"
+	adminPasswordLength = 24
+	secretLength        = 64
+)
+
+const configurationTemplate = `---
+###############################################################################
+#                 Authelia Configuration (standalone mode)                    #
+###############################################################################
+
+## This configuration has been generated by the standalone mode on the first start, it's a regular configuration which
+## can be edited. See https://www.authelia.com/docs/configuration/ for the available options.
+
+host: 0.0.0.0
+port: 9091
+
+default_redirection_url: {{ printf "https://%s" .Domain | printf "%q" }}
+
+jwt_secret: {{ .JWTSecret }}
+
+authentication_backend:
+  file:
+    path: {{ printf "%q" .UsersDatabasePath }}
+
+access_control:
+  default_policy: deny
+  rules:
+    - domain:
+        - {{ printf "%q" .Domain }}
+        - {{ printf "*.%s" .Domain | printf "%q" }}
+      policy: two_factor
+
+session:
+  domain: {{ printf "%q" .Domain }}
+  secret: {{ .SessionSecret }}
+
+storage:
+  local:
+    path: {{ printf "%q" .StoragePath }}
+
+notifier:
+  filesystem:
+    filename: {{ printf "%q" .NotificationPath }}
+...
+`
+
+const usersDatabaseTemplate = `---
+###############################################################################
+#                                Users Database                               #
+###############################################################################
+
+## This users database has been generated by the standalone mode on the first start. The passwords are hashed with
+## the authelia hash-password command.
+
+users:
+  {{ .Username }}:
+    displayname: "Administrator"
+    password: {{ printf "%q" .PasswordHash }}
+    email: {{ printf "%s@%s" .Username .Domain | printf "%q" }}
+    groups:
+      - admins
+...
+`
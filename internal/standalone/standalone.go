@@ -0,0 +1,125 @@
+package standalone
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/logging"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+var (
+	configurationTmpl = template.Must(template.New("configuration").Parse(configurationTemplate))
+	usersDatabaseTmpl = template.Must(template.New("users").Parse(usersDatabaseTemplate))
+)
+
+// Initialize prepares the data directory of the standalone mode and returns the path of its configuration. On the
+// first start it generates a configuration with random secrets, storing the data in a SQLite database and reading
+// the users from a file database in the directory, along with the users database containing an admin user whose
+// password is logged once. The configuration isn't changed on the next starts so the domain is only required on the
+// first one.
+func Initialize(directory, domain string) (configPath string, err error) {
+	configPath = filepath.Join(directory, configurationFileName)
+
+	if _, err = os.Stat(configPath); err == nil {
+		return configPath, nil
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	if domain == "" {
+		return "", errors.New("the domain protected by Authelia is required to initialize the data directory")
+	}
+
+	if err = os.MkdirAll(directory, 0700); err != nil {
+		return "", fmt.Errorf("unable to create the data directory: %w", err)
+	}
+
+	logger := logging.Logger()
+
+	usersDatabasePath := filepath.Join(directory, usersDatabaseFileName)
+
+	if _, err = os.Stat(usersDatabasePath); os.IsNotExist(err) {
+		var password string
+
+		if password, err = generateUsersDatabase(usersDatabasePath, domain); err != nil {
+			return "", err
+		}
+
+		logger.Warnf("Created the user %s with the password %s in %s, this is the only time the password is displayed",
+			adminUsername, password, usersDatabasePath)
+	}
+
+	if err = generateConfiguration(configPath, directory, domain); err != nil {
+		return "", err
+	}
+
+	logger.Infof("Generated the configuration of the standalone mode in %s", configPath)
+
+	return configPath, nil
+}
+
+func generateConfiguration(path, directory, domain string) error {
+	jwtSecret, err := utils.RandomSecureString(secretLength, utils.AlphaNumericCharacters)
+	if err != nil {
+		return fmt.Errorf("unable to generate the JWT secret: %w", err)
+	}
+
+	sessionSecret, err := utils.RandomSecureString(secretLength, utils.AlphaNumericCharacters)
+	if err != nil {
+		return fmt.Errorf("unable to generate the session secret: %w", err)
+	}
+
+	return writeTemplate(path, configurationTmpl, map[string]string{
+		"Domain":            domain,
+		"JWTSecret":         jwtSecret,
+		"SessionSecret":     sessionSecret,
+		"UsersDatabasePath": filepath.Join(directory, usersDatabaseFileName),
+		"StoragePath":       filepath.Join(directory, storageFileName),
+		"NotificationPath":  filepath.Join(directory, notificationFileName),
+	})
+}
+
+func generateUsersDatabase(path, domain string) (password string, err error) {
+	password, err = utils.RandomSecureString(adminPasswordLength, utils.AlphaNumericCharacters)
+	if err != nil {
+		return "", fmt.Errorf("unable to generate the password of the admin user: %w", err)
+	}
+
+	config := schema.DefaultPasswordConfiguration
+
+	hash, err := authentication.HashPassword(password, "", authentication.HashingAlgorithmArgon2id, config.Iterations,
+		config.Memory*1024, config.Parallelism, config.KeyLength, config.SaltLength)
+	if err != nil {
+		return "", fmt.Errorf("unable to hash the password of the admin user: %w", err)
+	}
+
+	err = writeTemplate(path, usersDatabaseTmpl, map[string]string{
+		"Username":     adminUsername,
+		"Domain":       domain,
+		"PasswordHash": hash,
+	})
+
+	return password, err
+}
+
+func writeTemplate(path string, tmpl *template.Template, data map[string]string) error {
+	buffer := new(bytes.Buffer)
+
+	if err := tmpl.Execute(buffer, data); err != nil {
+		return fmt.Errorf("unable to render %s: %w", path, err)
+	}
+
+	if err := ioutil.WriteFile(path, buffer.Bytes(), 0600); err != nil {
+		return fmt.Errorf("unable to write %s: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package standalone
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/configuration"
+)
+
+func TestShouldGenerateValidConfiguration(t *testing.T) {
+	dir, err := ioutil.TempDir("", "authelia-standalone")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	directory := filepath.Join(dir, "data")
+
+	configPath, err := Initialize(directory, "example.com")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(directory, configurationFileName), configPath)
+
+	for _, name := range []string{configurationFileName, usersDatabaseFileName} {
+		info, err := os.Stat(filepath.Join(directory, name))
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+
+	config, errs := configuration.Read(configPath)
+	require.Len(t, errs, 0)
+
+	assert.Equal(t, "example.com", config.Session.Domain)
+	assert.Len(t, config.JWTSecret, secretLength)
+	assert.Len(t, config.Session.Secret, secretLength)
+	assert.NotEqual(t, config.JWTSecret, config.Session.Secret)
+	assert.Equal(t, filepath.Join(directory, storageFileName), config.Storage.Local.Path)
+	assert.Equal(t, filepath.Join(directory, usersDatabaseFileName), config.AuthenticationBackend.File.Path)
+
+	provider := authentication.NewFileUserProvider(config.AuthenticationBackend.File)
+
+	details, err := provider.GetDetails(adminUsername)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"admin@example.com"}, details.Emails)
+}
+
+func TestShouldKeepExistingConfiguration(t *testing.T) {
+	dir, err := ioutil.TempDir("", "authelia-standalone")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	configPath, err := Initialize(dir, "example.com")
+	require.NoError(t, err)
+
+	content, err := ioutil.ReadFile(configPath)
+	require.NoError(t, err)
+
+	configPath, err = Initialize(dir, "")
+	require.NoError(t, err)
+
+	unchanged, err := ioutil.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, content, unchanged)
+}
+
+func TestShouldRequireDomainOnFirstStart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "authelia-standalone")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	_, err = Initialize(dir, "")
+	assert.EqualError(t, err, "the domain protected by Authelia is required to initialize the data directory")
+
+	_, err = os.Stat(filepath.Join(dir, usersDatabaseFileName))
+	assert.True(t, os.IsNotExist(err))
+}
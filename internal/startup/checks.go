@@ -0,0 +1,59 @@
+package startup
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/authelia/authelia/internal/notification"
+	"github.com/authelia/authelia/internal/storage"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// NewCheck creates a check of a provider implementing Checker.
+func NewCheck(name string, checker Checker) Check {
+	return Check{Name: name, Run: checker.StartupCheck}
+}
+
+// NewNotifierCheck creates the check of the notifier, e.g. the SMTP server greets Authelia, accepts its credentials and
+// the sender. The check is required because the users can't register their devices without notifications.
+func NewNotifierCheck(notifier notification.Notifier) Check {
+	return Check{
+		Name:     "notifier",
+		Required: true,
+		Run: func() (err error) {
+			_, err = notifier.StartupCheck()
+
+			return err
+		},
+	}
+}
+
+// NewStorageCheck creates the check of the storage writing, reading and removing an identity verification token.
+func NewStorageCheck(provider storage.Provider) Check {
+	return Check{
+		Name: "storage",
+		Run: func() (err error) {
+			token := startupCheckTokenPrefix + utils.RandomString(32, utils.AlphaNumericCharacters)
+
+			if err = provider.SaveIdentityVerificationToken(token, time.Now().Add(time.Minute)); err != nil {
+				return fmt.Errorf("unable to write to the storage: %w", err)
+			}
+
+			found, err := provider.FindIdentityVerificationToken(token)
+			if err != nil {
+				return fmt.Errorf("unable to read from the storage: %w", err)
+			}
+
+			if !found {
+				return errors.New("the token written to the storage could not be read")
+			}
+
+			if err = provider.RemoveIdentityVerificationToken(token); err != nil {
+				return fmt.Errorf("unable to remove from the storage: %w", err)
+			}
+
+			return nil
+		},
+	}
+}
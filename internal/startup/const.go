@@ -0,0 +1,4 @@
+package startup
+
+// startupCheckTokenPrefix prefixes the identity verification token written by the storage check.
+const startupCheckTokenPrefix = "startup-check-"
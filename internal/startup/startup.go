@@ -0,0 +1,101 @@
+package startup
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Run runs the checks concurrently and returns the report of their results in the order of the checks. A check which
+// doesn't complete within the timeout fails.
+func Run(checks []Check, timeout time.Duration) (report Report) {
+	report.Results = make([]Result, len(checks))
+
+	done := make(chan int, len(checks))
+
+	for i, check := range checks {
+		go func(i int, check Check) {
+			start := time.Now()
+			err := check.Run()
+
+			report.Results[i] = Result{Name: check.Name, Required: check.Required, Duration: time.Since(start), Err: err}
+			done <- i
+		}(i, check)
+	}
+
+	completed := make([]bool, len(checks))
+	deadline := time.NewTimer(timeout)
+
+	defer deadline.Stop()
+
+	for remaining := len(checks); remaining > 0; remaining-- {
+		select {
+		case i := <-done:
+			completed[i] = true
+		case <-deadline.C:
+			// The results of the checks still running aren't written by their goroutine anymore once they're reported.
+			return timedOut(report, checks, completed, timeout)
+		}
+	}
+
+	return report
+}
+
+func timedOut(report Report, checks []Check, completed []bool, timeout time.Duration) Report {
+	results := make([]Result, len(checks))
+
+	for i, check := range checks {
+		if completed[i] {
+			results[i] = report.Results[i]
+			continue
+		}
+
+		results[i] = Result{
+			Name:     check.Name,
+			Required: check.Required,
+			Duration: timeout,
+			Err:      fmt.Errorf("the check did not complete within %s", timeout),
+		}
+	}
+
+	return Report{Results: results}
+}
+
+// Failed returns true when a required check failed, or any check when the startup is strict.
+func (r Report) Failed(strict bool) bool {
+	for _, result := range r.Results {
+		if result.Err != nil && (strict || result.Required) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Log logs the report. The failures preventing Authelia from starting are logged as errors, the other ones as
+// warnings.
+func (r Report) Log(logger *logrus.Logger, strict bool) {
+	failed := 0
+
+	for _, result := range r.Results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+
+	logger.Infof("Startup checks: %d passed, %d failed", len(r.Results)-failed, failed)
+
+	for _, result := range r.Results {
+		duration := result.Duration.Round(time.Millisecond)
+
+		switch {
+		case result.Err == nil:
+			logger.Infof("Startup check %s passed in %s", result.Name, duration)
+		case strict || result.Required:
+			logger.Errorf("Startup check %s failed in %s: %s", result.Name, duration, result.Err)
+		default:
+			logger.Warnf("Startup check %s failed in %s: %s", result.Name, duration, result.Err)
+		}
+	}
+}
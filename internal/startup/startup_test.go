@@ -0,0 +1,114 @@
+package startup
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/pkg/autheliatest"
+)
+
+func TestShouldRunChecksAndReportResultsInOrder(t *testing.T) {
+	report := Run([]Check{
+		{Name: "slow", Run: func() error {
+			time.Sleep(20 * time.Millisecond)
+			return nil
+		}},
+		{Name: "failing", Run: func() error { return errors.New("unreachable") }},
+		{Name: "required", Required: true, Run: func() error { return nil }},
+	}, time.Second)
+
+	require.Len(t, report.Results, 3)
+
+	assert.Equal(t, "slow", report.Results[0].Name)
+	assert.NoError(t, report.Results[0].Err)
+	assert.GreaterOrEqual(t, int64(report.Results[0].Duration), int64(20*time.Millisecond))
+
+	assert.Equal(t, "failing", report.Results[1].Name)
+	assert.EqualError(t, report.Results[1].Err, "unreachable")
+
+	assert.Equal(t, "required", report.Results[2].Name)
+	assert.True(t, report.Results[2].Required)
+	assert.NoError(t, report.Results[2].Err)
+
+	assert.False(t, report.Failed(false))
+	assert.True(t, report.Failed(true))
+}
+
+func TestShouldFailChecksNotCompletingWithinTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	report := Run([]Check{
+		{Name: "hanging", Required: true, Run: func() error {
+			<-release
+			return nil
+		}},
+		{Name: "fast", Run: func() error { return nil }},
+	}, 50*time.Millisecond)
+
+	require.Len(t, report.Results, 2)
+
+	assert.EqualError(t, report.Results[0].Err, "the check did not complete within 50ms")
+	assert.NoError(t, report.Results[1].Err)
+
+	assert.True(t, report.Failed(false))
+}
+
+func TestShouldLogReport(t *testing.T) {
+	report := Report{Results: []Result{
+		{Name: "storage", Duration: 12 * time.Millisecond},
+		{Name: "session", Duration: time.Millisecond, Err: errors.New("unreachable")},
+		{Name: "notifier", Required: true, Duration: time.Millisecond, Err: errors.New("refused")},
+	}}
+
+	logger, hook := test.NewNullLogger()
+
+	report.Log(logger, false)
+
+	require.Len(t, hook.Entries, 4)
+	assert.Equal(t, "Startup checks: 1 passed, 2 failed", hook.Entries[0].Message)
+	assert.Equal(t, "Startup check storage passed in 12ms", hook.Entries[1].Message)
+	assert.Equal(t, logrus.WarnLevel, hook.Entries[2].Level)
+	assert.Equal(t, "Startup check session failed in 1ms: unreachable", hook.Entries[2].Message)
+	assert.Equal(t, logrus.ErrorLevel, hook.Entries[3].Level)
+	assert.Equal(t, "Startup check notifier failed in 1ms: refused", hook.Entries[3].Message)
+
+	hook.Reset()
+
+	report.Log(logger, true)
+
+	require.Len(t, hook.Entries, 4)
+	assert.Equal(t, logrus.ErrorLevel, hook.Entries[2].Level)
+}
+
+func TestShouldWriteReadAndRemoveTokenOnStorageCheck(t *testing.T) {
+	provider := autheliatest.NewMemoryStorageProvider()
+
+	check := NewStorageCheck(provider)
+
+	assert.Equal(t, "storage", check.Name)
+	assert.False(t, check.Required)
+	assert.NoError(t, check.Run())
+}
+
+func TestShouldRequireNotifierCheck(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	notifier := mocks.NewMockNotifier(ctrl)
+	notifier.EXPECT().StartupCheck().Return(false, errors.New("the SMTP server refused the sender"))
+
+	check := NewNotifierCheck(notifier)
+
+	assert.Equal(t, "notifier", check.Name)
+	assert.True(t, check.Required)
+	assert.EqualError(t, check.Run(), "the SMTP server refused the sender")
+}
@@ -0,0 +1,34 @@
+package startup
+
+import (
+	"time"
+)
+
+// Checker is implemented by the providers able to check the dependencies they rely on are ready, e.g. the LDAP user
+// provider binds to the server.
+type Checker interface {
+	StartupCheck() (err error)
+}
+
+// Check is an active check of a dependency run when Authelia starts.
+type Check struct {
+	Name string
+
+	// Required checks prevent Authelia from starting when they fail, even if the startup isn't strict.
+	Required bool
+
+	Run func() (err error)
+}
+
+// Result is the outcome of a check.
+type Result struct {
+	Name     string
+	Required bool
+	Duration time.Duration
+	Err      error
+}
+
+// Report is the readiness report consolidating the results of the checks.
+type Report struct {
+	Results []Result
+}
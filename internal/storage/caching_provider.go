@@ -0,0 +1,290 @@
+package storage
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/logging"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// cacheEntryLifespan bounds how long a cached entry is served, it limits the staleness of the cache when an
+// invalidation published by another instance is lost.
+const cacheEntryLifespan = 5 * time.Minute
+
+// CacheInvalidator propagates the invalidations of the cached entries of a user to the other instances of Authelia
+// sharing the same storage.
+type CacheInvalidator interface {
+	Publish(username string) error
+	Subscribe(invalidate func(username string)) error
+}
+
+// CachingProvider is a Provider caching the preferred 2FA method and the second factor lookups of the users in a
+// least recently used cache, since they are loaded on every page load of the portal. The entries of a user are
+// invalidated when they are written by this instance or by another instance through the CacheInvalidator.
+type CachingProvider struct {
+	Provider
+
+	invalidator CacheInvalidator
+	clock       utils.Clock
+	size        int
+
+	mutex   sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+
+	// generation is incremented by every invalidation so a lookup started before an invalidation doesn't cache the
+	// value it loaded, which may be stale.
+	generation uint64
+}
+
+type cachedUser struct {
+	username  string
+	expiresAt time.Time
+
+	method *string
+	totp   []models.TOTPDevice
+	u2f    *cachedU2FDeviceHandle
+}
+
+type cachedU2FDeviceHandle struct {
+	keyHandle []byte
+	publicKey []byte
+	err       error
+}
+
+// NewCachingProvider creates a Provider caching the lookups of the provider. The invalidator is optional, it must be
+// provided when several instances of Authelia share the same storage.
+func NewCachingProvider(provider Provider, configuration schema.StorageCacheConfiguration, invalidator CacheInvalidator, clock utils.Clock) (*CachingProvider, error) {
+	p := &CachingProvider{
+		Provider:    provider,
+		invalidator: invalidator,
+		clock:       clock,
+		size:        configuration.Size,
+		entries:     make(map[string]*list.Element),
+		order:       list.New(),
+	}
+
+	if invalidator != nil {
+		if err := invalidator.Subscribe(p.invalidateLocal); err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// lookup returns the cached entry of the user if it's still valid along with the current generation of the cache.
+func (p *CachingProvider) lookup(username string) (entry cachedUser, found bool, generation uint64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	element, ok := p.entries[username]
+	if !ok {
+		return entry, false, p.generation
+	}
+
+	cached := element.Value.(*cachedUser)
+
+	if !p.clock.Now().Before(cached.expiresAt) {
+		p.remove(element)
+
+		return entry, false, p.generation
+	}
+
+	p.order.MoveToFront(element)
+
+	return *cached, true, p.generation
+}
+
+// store updates the entry of the user unless the cache has been invalidated since the generation was read.
+func (p *CachingProvider) store(username string, generation uint64, update func(entry *cachedUser)) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if generation != p.generation {
+		return
+	}
+
+	element, ok := p.entries[username]
+	if !ok {
+		element = p.order.PushFront(&cachedUser{username: username, expiresAt: p.clock.Now().Add(cacheEntryLifespan)})
+		p.entries[username] = element
+
+		for p.order.Len() > p.size {
+			p.remove(p.order.Back())
+		}
+	} else {
+		p.order.MoveToFront(element)
+	}
+
+	update(element.Value.(*cachedUser))
+}
+
+// remove must be called with the mutex locked.
+func (p *CachingProvider) remove(element *list.Element) {
+	p.order.Remove(element)
+	delete(p.entries, element.Value.(*cachedUser).username)
+}
+
+// invalidateLocal removes the entry of the user from the cache of this instance.
+func (p *CachingProvider) invalidateLocal(username string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.generation++
+
+	if element, ok := p.entries[username]; ok {
+		p.remove(element)
+	}
+}
+
+// invalidate removes the entry of the user from the cache of this instance and of the other instances.
+func (p *CachingProvider) invalidate(username string) {
+	p.invalidateLocal(username)
+
+	if p.invalidator == nil {
+		return
+	}
+
+	if err := p.invalidator.Publish(username); err != nil {
+		logging.Logger().Errorf("Unable to publish the invalidation of the storage cache of user %s: %v", username, err)
+	}
+}
+
+// LoadPreferred2FAMethod load the preferred method for 2FA from the cache or the provider.
+func (p *CachingProvider) LoadPreferred2FAMethod(username string) (string, error) {
+	entry, found, generation := p.lookup(username)
+	if found && entry.method != nil {
+		return *entry.method, nil
+	}
+
+	method, err := p.Provider.LoadPreferred2FAMethod(username)
+	if err != nil {
+		return method, err
+	}
+
+	p.store(username, generation, func(entry *cachedUser) {
+		entry.method = &method
+	})
+
+	return method, nil
+}
+
+// SavePreferred2FAMethod save the preferred method for 2FA and invalidate the cache of the user.
+func (p *CachingProvider) SavePreferred2FAMethod(username string, method string) error {
+	defer p.invalidate(username)
+
+	return p.Provider.SavePreferred2FAMethod(username, method)
+}
+
+// DeletePreferred2FAMethod delete the preferred method for 2FA and invalidate the cache of the user.
+func (p *CachingProvider) DeletePreferred2FAMethod(username string) error {
+	defer p.invalidate(username)
+
+	return p.Provider.DeletePreferred2FAMethod(username)
+}
+
+// LoadTOTPDevices load the TOTP devices of a given user from the cache or the provider.
+func (p *CachingProvider) LoadTOTPDevices(username string) ([]models.TOTPDevice, error) {
+	entry, found, generation := p.lookup(username)
+	if found && entry.totp != nil {
+		return entry.totp, nil
+	}
+
+	devices, err := p.Provider.LoadTOTPDevices(username)
+	if err != nil {
+		return devices, err
+	}
+
+	// The devices are cached even when the user has none, the nil slice marks the devices which aren't cached.
+	if devices == nil {
+		devices = []models.TOTPDevice{}
+	}
+
+	p.store(username, generation, func(entry *cachedUser) {
+		entry.totp = devices
+	})
+
+	return devices, nil
+}
+
+// SaveTOTPDevice save a TOTP device of a given user and invalidate the cache of the user.
+func (p *CachingProvider) SaveTOTPDevice(device models.TOTPDevice) error {
+	defer p.invalidate(device.Username)
+
+	return p.Provider.SaveTOTPDevice(device)
+}
+
+// UpdateTOTPDeviceDescription update the description of a TOTP device and invalidate the cache of the user.
+func (p *CachingProvider) UpdateTOTPDeviceDescription(username, deviceID, description string) error {
+	defer p.invalidate(username)
+
+	return p.Provider.UpdateTOTPDeviceDescription(username, deviceID, description)
+}
+
+// DeleteTOTPDevice delete a TOTP device of a given user and invalidate the cache of the user.
+func (p *CachingProvider) DeleteTOTPDevice(username, deviceID string) error {
+	defer p.invalidate(username)
+
+	return p.Provider.DeleteTOTPDevice(username, deviceID)
+}
+
+// SaveTOTPDeviceLastUsed save the last TOTP time step used with a device and invalidate the cache of the user since
+// the time the device was last used is cached along with it.
+func (p *CachingProvider) SaveTOTPDeviceLastUsed(username, deviceID string, step uint64, usedAt time.Time) error {
+	defer p.invalidate(username)
+
+	return p.Provider.SaveTOTPDeviceLastUsed(username, deviceID, step, usedAt)
+}
+
+// LoadU2FDeviceHandle load a U2F device handle given a username from the cache or the provider.
+func (p *CachingProvider) LoadU2FDeviceHandle(username string) (keyHandle []byte, publicKey []byte, err error) {
+	entry, found, generation := p.lookup(username)
+	if found && entry.u2f != nil {
+		return entry.u2f.keyHandle, entry.u2f.publicKey, entry.u2f.err
+	}
+
+	keyHandle, publicKey, err = p.Provider.LoadU2FDeviceHandle(username)
+	if err != nil && err != ErrNoU2FDeviceHandle {
+		return keyHandle, publicKey, err
+	}
+
+	p.store(username, generation, func(entry *cachedUser) {
+		entry.u2f = &cachedU2FDeviceHandle{keyHandle: keyHandle, publicKey: publicKey, err: err}
+	})
+
+	return keyHandle, publicKey, err
+}
+
+// SaveU2FDeviceHandle save a registered U2F device registration blob and invalidate the cache of the user.
+func (p *CachingProvider) SaveU2FDeviceHandle(username string, keyHandle []byte, publicKey []byte) error {
+	defer p.invalidate(username)
+
+	return p.Provider.SaveU2FDeviceHandle(username, keyHandle, publicKey)
+}
+
+// DeleteU2FDeviceHandle delete the U2F device of a given user and invalidate the cache of the user.
+func (p *CachingProvider) DeleteU2FDeviceHandle(username string) error {
+	defer p.invalidate(username)
+
+	return p.Provider.DeleteU2FDeviceHandle(username)
+}
+
+// ApproveSecondFactorReset approve a pending second factor reset, delete the devices of the user and invalidate the
+// cache of the user.
+func (p *CachingProvider) ApproveSecondFactorReset(id, username, approvedBy string, approvedAt time.Time) error {
+	defer p.invalidate(username)
+
+	return p.Provider.ApproveSecondFactorReset(id, username, approvedBy, approvedAt)
+}
+
+// PurgeUser delete all the data of the user and invalidate the cache of the user.
+func (p *CachingProvider) PurgeUser(username string, revokedAt time.Time) error {
+	defer p.invalidate(username)
+
+	return p.Provider.PurgeUser(username, revokedAt)
+}
@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+type fakeCacheInvalidator struct {
+	published  []string
+	invalidate func(username string)
+}
+
+func (i *fakeCacheInvalidator) Publish(username string) error {
+	i.published = append(i.published, username)
+	return nil
+}
+
+func (i *fakeCacheInvalidator) Subscribe(invalidate func(username string)) error {
+	i.invalidate = invalidate
+	return nil
+}
+
+func TestShouldCacheLookupsUntilTheyAreWritten(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mock := NewMockProvider(ctrl)
+	invalidator := &fakeCacheInvalidator{}
+
+	provider, err := NewCachingProvider(mock, schema.StorageCacheConfiguration{Size: 10}, invalidator, utils.NewFakeClock(time.Unix(1640000000, 0)))
+	require.NoError(t, err)
+
+	gomock.InOrder(
+		mock.EXPECT().LoadPreferred2FAMethod("john").Return("totp", nil),
+		mock.EXPECT().SavePreferred2FAMethod("john", "webauthn").Return(nil),
+		mock.EXPECT().LoadPreferred2FAMethod("john").Return("webauthn", nil),
+	)
+
+	mock.EXPECT().LoadTOTPDevices("john").Return(nil, nil)
+	mock.EXPECT().LoadU2FDeviceHandle("john").Return([]byte("key"), []byte("public"), nil)
+
+	for i := 0; i < 2; i++ {
+		method, err := provider.LoadPreferred2FAMethod("john")
+		assert.NoError(t, err)
+		assert.Equal(t, "totp", method)
+
+		devices, err := provider.LoadTOTPDevices("john")
+		assert.NoError(t, err)
+		assert.Empty(t, devices)
+
+		keyHandle, _, err := provider.LoadU2FDeviceHandle("john")
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("key"), keyHandle)
+	}
+
+	require.NoError(t, provider.SavePreferred2FAMethod("john", "webauthn"))
+	assert.Equal(t, []string{"john"}, invalidator.published)
+
+	method, err := provider.LoadPreferred2FAMethod("john")
+	assert.NoError(t, err)
+	assert.Equal(t, "webauthn", method)
+}
+
+func TestShouldNotCacheLookupErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mock := NewMockProvider(ctrl)
+
+	provider, err := NewCachingProvider(mock, schema.StorageCacheConfiguration{Size: 10}, nil, utils.NewFakeClock(time.Unix(1640000000, 0)))
+	require.NoError(t, err)
+
+	gomock.InOrder(
+		mock.EXPECT().LoadTOTPDevices("john").Return(nil, errors.New("connection refused")),
+		mock.EXPECT().LoadTOTPDevices("john").Return([]models.TOTPDevice{{ID: "phone", Username: "john", Secret: "secret"}}, nil),
+	)
+
+	_, err = provider.LoadTOTPDevices("john")
+	assert.EqualError(t, err, "connection refused")
+
+	devices, err := provider.LoadTOTPDevices("john")
+	assert.NoError(t, err)
+	require.Len(t, devices, 1)
+	assert.Equal(t, "secret", devices[0].Secret)
+}
+
+func TestShouldInvalidateCacheFromOtherInstances(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mock := NewMockProvider(ctrl)
+	invalidator := &fakeCacheInvalidator{}
+
+	provider, err := NewCachingProvider(mock, schema.StorageCacheConfiguration{Size: 10}, invalidator, utils.NewFakeClock(time.Unix(1640000000, 0)))
+	require.NoError(t, err)
+
+	gomock.InOrder(
+		mock.EXPECT().LoadTOTPDevices("john").Return([]models.TOTPDevice{}, nil),
+		mock.EXPECT().LoadTOTPDevices("john").Return([]models.TOTPDevice{{ID: "phone", Username: "john", Secret: "secret"}}, nil),
+	)
+
+	devices, err := provider.LoadTOTPDevices("john")
+	assert.NoError(t, err)
+	assert.Empty(t, devices)
+
+	invalidator.invalidate("john")
+
+	devices, err = provider.LoadTOTPDevices("john")
+	assert.NoError(t, err)
+	assert.Len(t, devices, 1)
+	assert.Empty(t, invalidator.published)
+}
+
+func TestShouldEvictLeastRecentlyUsedAndExpiredEntries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mock := NewMockProvider(ctrl)
+	clock := utils.NewFakeClock(time.Unix(1640000000, 0))
+
+	provider, err := NewCachingProvider(mock, schema.StorageCacheConfiguration{Size: 2}, nil, clock)
+	require.NoError(t, err)
+
+	mock.EXPECT().LoadPreferred2FAMethod("john").Return("totp", nil).Times(2)
+	mock.EXPECT().LoadPreferred2FAMethod("harry").Return("totp", nil).Times(2)
+	mock.EXPECT().LoadPreferred2FAMethod("bob").Return("totp", nil).Times(1)
+
+	for _, username := range []string{"john", "harry", "john", "bob", "john", "harry"} {
+		_, err = provider.LoadPreferred2FAMethod(username)
+		assert.NoError(t, err)
+	}
+
+	clock.Advance(cacheEntryLifespan)
+
+	_, err = provider.LoadPreferred2FAMethod("john")
+	assert.NoError(t, err)
+}
@@ -2,18 +2,56 @@ package storage
 
 import (
 	"fmt"
+	"strings"
+
+	"github.com/authelia/authelia/internal/models"
 )
 
-const storageSchemaCurrentVersion = SchemaVersion(1)
+const storageSchemaCurrentVersion = SchemaVersion(24)
 const storageSchemaUpgradeMessage = "Storage schema upgraded to v"
 const storageSchemaUpgradeErrorText = "storage schema upgrade failed at v"
+const storageSchemaDowngradeMessage = "Storage schema downgraded to v"
+const storageSchemaDowngradeErrorText = "storage schema downgrade failed at v"
+
+// storageSchemaEncryptionVersion is the version of the schema the secrets can be encrypted at rest from.
+const storageSchemaEncryptionVersion = SchemaVersion(22)
+
+// encryptedValuePrefix prefixes the secrets encrypted at rest, it's neither part of the base32 alphabet of the TOTP
+// secrets nor of the base64 alphabet of the U2F key handles so the secrets stored in plaintext can be told apart.
+const encryptedValuePrefix = "$aes256gcm$"
+
+// The config entry holding a value encrypted with the storage encryption key, it's used to verify the key is the one
+// the secrets stored are encrypted with.
+const (
+	encryptionCheckCategory = "encryption"
+	encryptionCheckKeyName  = "check"
+	encryptionCheckValue    = "authelia"
+)
+
+// postgreSQLDefaultPort is the port of the PostgreSQL servers listed without one when there are several servers.
+const postgreSQLDefaultPort = 5432
 
 // Keep table names in lower case because some DB does not support upper case.
 const userPreferencesTableName = "user_preferences"
 const identityVerificationTokensTableName = "identity_verification_tokens"
 const totpSecretsTableName = "totp_secrets"
+const totpDevicesTableName = "totp_devices"
 const u2fDeviceHandlesTableName = "u2f_devices"
 const authenticationLogsTableName = "authentication_logs"
+const webauthnCredentialsTableName = "webauthn_credentials"
+const userEventsTableName = "user_events"
+const sessionRevocationsTableName = "session_revocations"
+const notificationThrottlesTableName = "notification_throttles"
+const loginStatesTableName = "login_states"
+const announcementAcknowledgmentsTableName = "announcement_acknowledgments"
+const backupCodesTableName = "backup_codes"
+const adminAPITokensTableName = "admin_api_tokens"
+const pushEnrollmentsTableName = "push_enrollments"
+const secondFactorResetsTableName = "second_factor_resets"
+const accessGrantsTableName = "access_grants"
+const breakGlassActivationsTableName = "break_glass_activations"
+const oidcConsentsTableName = "oidc_consents"
+const oidcClientsTableName = "oidc_clients"
 const configTableName = "config"
 
 // sqlUpgradeCreateTableStatements is a map of the schema version number, plus a map of the table name and the statement used to create it.
@@ -27,6 +65,82 @@ var sqlUpgradeCreateTableStatements = map[SchemaVersion]map[string]string{
 		authenticationLogsTableName:         "CREATE TABLE %s (username VARCHAR(100), successful BOOL, time INTEGER)",
 		configTableName:                     "CREATE TABLE %s (category VARCHAR(32) NOT NULL, key_name VARCHAR(32) NOT NULL, value TEXT, PRIMARY KEY (category, key_name))",
 	},
+	SchemaVersion(3): {
+		webauthnCredentialsTableName: "CREATE TABLE %s (credential_id VARCHAR(512) PRIMARY KEY, username VARCHAR(100) NOT NULL, public_key TEXT NOT NULL, sign_count INTEGER, created_at INTEGER)",
+	},
+	SchemaVersion(6): {
+		userEventsTableName: "CREATE TABLE %s (username VARCHAR(100) NOT NULL, event VARCHAR(32) NOT NULL, successful BOOL, time INTEGER, remote_ip VARCHAR(47), user_agent TEXT)",
+	},
+	SchemaVersion(7): {
+		sessionRevocationsTableName: "CREATE TABLE %s (username VARCHAR(100) PRIMARY KEY, revoked_at INTEGER NOT NULL)",
+	},
+	SchemaVersion(9): {
+		notificationThrottlesTableName: "CREATE TABLE %s (username VARCHAR(100) NOT NULL, kind VARCHAR(32) NOT NULL, last_sent_at INTEGER NOT NULL, pending INTEGER NOT NULL, pending_since INTEGER NOT NULL, PRIMARY KEY (username, kind))",
+	},
+	SchemaVersion(11): {
+		totpDevicesTableName: "CREATE TABLE %s (username VARCHAR(100) NOT NULL, device_id VARCHAR(32) NOT NULL, description VARCHAR(64), secret VARCHAR(64) NOT NULL, created_at INTEGER, last_used_at INTEGER, last_used_step BIGINT, PRIMARY KEY (username, device_id))",
+	},
+	SchemaVersion(12): {
+		loginStatesTableName: "CREATE TABLE %s (nonce VARCHAR(64) PRIMARY KEY, expires_at INTEGER NOT NULL)",
+	},
+	SchemaVersion(13): {
+		announcementAcknowledgmentsTableName: "CREATE TABLE %s (username VARCHAR(100) NOT NULL, announcement_id VARCHAR(64) NOT NULL, acknowledged_at INTEGER NOT NULL, PRIMARY KEY (username, announcement_id))",
+	},
+	SchemaVersion(14): {
+		backupCodesTableName: "CREATE TABLE %s (username VARCHAR(100) NOT NULL, code_hash VARCHAR(64) NOT NULL, created_at INTEGER NOT NULL, PRIMARY KEY (username, code_hash))",
+	},
+	SchemaVersion(15): {
+		adminAPITokensTableName: "CREATE TABLE %s (id VARCHAR(64) PRIMARY KEY, description VARCHAR(255) NOT NULL, token_hash VARCHAR(64) NOT NULL UNIQUE, scopes VARCHAR(255) NOT NULL, created_at INTEGER NOT NULL, expires_at INTEGER NOT NULL, last_used_at INTEGER)",
+	},
+	SchemaVersion(16): {
+		pushEnrollmentsTableName: "CREATE TABLE %s (username VARCHAR(100) PRIMARY KEY, provider VARCHAR(32) NOT NULL, status VARCHAR(16) NOT NULL, devices INTEGER NOT NULL, checked_at INTEGER NOT NULL)",
+	},
+	SchemaVersion(17): {
+		secondFactorResetsTableName: "CREATE TABLE %s (id VARCHAR(64) PRIMARY KEY, username VARCHAR(100) NOT NULL, requested_by VARCHAR(255) NOT NULL, reason VARCHAR(255) NOT NULL, approval VARCHAR(16) NOT NULL, approval_token_hash VARCHAR(64), status VARCHAR(16) NOT NULL, decided_by VARCHAR(255), requested_at INTEGER NOT NULL, expires_at INTEGER NOT NULL, decided_at INTEGER)",
+	},
+	SchemaVersion(18): {
+		accessGrantsTableName: "CREATE TABLE %s (id VARCHAR(64) PRIMARY KEY, username VARCHAR(255) NOT NULL, target_url TEXT NOT NULL, description VARCHAR(255), created_at INTEGER NOT NULL, expires_at INTEGER NOT NULL)",
+	},
+	SchemaVersion(19): {
+		breakGlassActivationsTableName: "CREATE TABLE %s (username VARCHAR(100) PRIMARY KEY, activated_at INTEGER NOT NULL)",
+	},
+	SchemaVersion(20): {
+		oidcConsentsTableName: "CREATE TABLE %s (username VARCHAR(100) NOT NULL, client_id VARCHAR(100) NOT NULL, scopes VARCHAR(255) NOT NULL, audience TEXT NOT NULL, created_at INTEGER NOT NULL, expires_at INTEGER NOT NULL, PRIMARY KEY (username, client_id))",
+	},
+	SchemaVersion(21): {
+		oidcClientsTableName: "CREATE TABLE %s (id VARCHAR(100) PRIMARY KEY, description VARCHAR(255) NOT NULL, secret_hash VARCHAR(255) NOT NULL, policy VARCHAR(20) NOT NULL, redirect_uris TEXT NOT NULL, grant_types VARCHAR(255) NOT NULL, response_types VARCHAR(255) NOT NULL, scopes VARCHAR(255) NOT NULL, post_logout_redirect_uris TEXT NOT NULL, backchannel_logout_uri TEXT NOT NULL, frontchannel_logout_uri TEXT NOT NULL, registered_by VARCHAR(100) NOT NULL, created_at INTEGER NOT NULL)",
+	},
+}
+
+// sqlUpgradeCreateTableStatementsMySQL are the create table statements of MySQL which differ from the generic ones, the
+// index of the authentication logs is created along with the table.
+var sqlUpgradeCreateTableStatementsMySQL = map[SchemaVersion]map[string]string{
+	SchemaVersion(1): {
+		authenticationLogsTableName: "CREATE TABLE %s (username VARCHAR(100), successful BOOL, time INTEGER, INDEX usr_time_idx (username, time))",
+	},
+}
+
+// sqlUpgradeCreateTableStatementsWithDialect returns a deep copy of the create table statements including the
+// statements of the dialect, each provider gets its own copy so the dialects of the providers of a process don't leak
+// into one another.
+func sqlUpgradeCreateTableStatementsWithDialect(dialect map[SchemaVersion]map[string]string) map[SchemaVersion]map[string]string {
+	statements := make(map[SchemaVersion]map[string]string, len(sqlUpgradeCreateTableStatements))
+
+	for version, tables := range sqlUpgradeCreateTableStatements {
+		statements[version] = make(map[string]string, len(tables))
+
+		for table, statement := range tables {
+			statements[version][table] = statement
+		}
+	}
+
+	for version, tables := range dialect {
+		for table, statement := range tables {
+			statements[version][table] = statement
+		}
+	}
+
+	return statements
 }
 
 // sqlUpgradesCreateTableIndexesStatements is a map of t he schema version number, plus a slice of statements to create all of the indexes.
@@ -36,4 +150,202 @@ var sqlUpgradesCreateTableIndexesStatements = map[SchemaVersion][]string{
 	},
 }
 
+// sqlUpgradesStatements is a map of the schema version number, plus a slice of statements which alter existing tables
+// during the upgrade to that version. These statements are run after the tables of the version have been created.
+var sqlUpgradesStatements = map[SchemaVersion][]string{
+	SchemaVersion(2): {
+		fmt.Sprintf("ALTER TABLE %s ADD COLUMN remote_ip VARCHAR(47)", authenticationLogsTableName),
+		fmt.Sprintf("ALTER TABLE %s ADD COLUMN user_agent TEXT", authenticationLogsTableName),
+	},
+	SchemaVersion(4): {
+		fmt.Sprintf("ALTER TABLE %s ADD COLUMN auth_type VARCHAR(32)", authenticationLogsTableName),
+	},
+	SchemaVersion(5): {
+		fmt.Sprintf("ALTER TABLE %s ADD COLUMN last_used_step BIGINT", totpSecretsTableName),
+	},
+	SchemaVersion(6): {
+		fmt.Sprintf("CREATE INDEX usr_events_time_idx ON %s (username, time)", userEventsTableName),
+	},
+	SchemaVersion(8): {
+		fmt.Sprintf("ALTER TABLE %s ADD COLUMN expires_at INTEGER", identityVerificationTokensTableName),
+	},
+	SchemaVersion(10): {
+		fmt.Sprintf("ALTER TABLE %s ADD COLUMN description VARCHAR(64)", webauthnCredentialsTableName),
+	},
+	// The secret of each user becomes the default device of the user, its registration time is unknown.
+	SchemaVersion(11): {
+		fmt.Sprintf("INSERT INTO %s (username, device_id, description, secret, last_used_step) SELECT username, '%s', '%s', secret, last_used_step FROM %s",
+			totpDevicesTableName, totpDefaultDeviceID, totpDefaultDeviceDescription, totpSecretsTableName),
+		fmt.Sprintf("DROP TABLE %s", totpSecretsTableName),
+	},
+	// The credentials are either passkeys or second factor credentials, the credentials registered before were passkeys.
+	SchemaVersion(24): {
+		fmt.Sprintf("ALTER TABLE %s ADD COLUMN credential_type VARCHAR(16) NOT NULL DEFAULT '%s'", webauthnCredentialsTableName, models.WebauthnCredentialTypePasskey),
+	},
+}
+
+// The secrets of the TOTP devices are longer once encrypted, their column is widened in v22 by the dialects enforcing
+// the length of the columns, i.e. all of them but SQLite. The column of the backup codes is widened in v23 to hold the
+// salted hashes of the password hasher.
+var (
+	sqlUpgradesStatementsMySQL = sqlUpgradesStatementsWithDialect(map[SchemaVersion][]string{
+		SchemaVersion(22): {fmt.Sprintf("ALTER TABLE %s MODIFY secret VARCHAR(255) NOT NULL", totpDevicesTableName)},
+		SchemaVersion(23): {fmt.Sprintf("ALTER TABLE %s MODIFY code_hash VARCHAR(255) NOT NULL", backupCodesTableName)},
+	})
+
+	sqlUpgradesStatementsPostgreSQL = sqlUpgradesStatementsWithDialect(map[SchemaVersion][]string{
+		SchemaVersion(22): {fmt.Sprintf("ALTER TABLE %s ALTER COLUMN secret TYPE VARCHAR(255)", totpDevicesTableName)},
+		SchemaVersion(23): {fmt.Sprintf("ALTER TABLE %s ALTER COLUMN code_hash TYPE VARCHAR(255)", backupCodesTableName)},
+	})
+)
+
+// sqlUpgradesStatementsWithDialect returns the upgrade statements including the statements of the dialect.
+func sqlUpgradesStatementsWithDialect(dialect map[SchemaVersion][]string) map[SchemaVersion][]string {
+	statements := make(map[SchemaVersion][]string, len(sqlUpgradesStatements)+len(dialect))
+
+	for version, versionStatements := range sqlUpgradesStatements {
+		statements[version] = versionStatements
+	}
+
+	for version, versionStatements := range dialect {
+		statements[version] = append(append([]string{}, statements[version]...), versionStatements...)
+	}
+
+	return statements
+}
+
+// sqlUpgradesDroppedTableNames is a map of the schema version number, plus a slice of the tables dropped during the
+// upgrade to that version.
+var sqlUpgradesDroppedTableNames = map[SchemaVersion][]string{
+	SchemaVersion(11): {totpSecretsTableName},
+}
+
+// sqlDowngradesStatements is a map of the schema version number, plus a slice of statements which revert the alter
+// statements of that version during the downgrade to the previous version. The tables created by the version are
+// dropped once these statements have been run.
+var sqlDowngradesStatements = map[SchemaVersion][]string{
+	SchemaVersion(2): {
+		fmt.Sprintf("ALTER TABLE %s DROP COLUMN remote_ip", authenticationLogsTableName),
+		fmt.Sprintf("ALTER TABLE %s DROP COLUMN user_agent", authenticationLogsTableName),
+	},
+	SchemaVersion(4): {
+		fmt.Sprintf("ALTER TABLE %s DROP COLUMN auth_type", authenticationLogsTableName),
+	},
+	SchemaVersion(5): {
+		fmt.Sprintf("ALTER TABLE %s DROP COLUMN last_used_step", totpSecretsTableName),
+	},
+	SchemaVersion(8): {
+		fmt.Sprintf("ALTER TABLE %s DROP COLUMN expires_at", identityVerificationTokensTableName),
+	},
+	SchemaVersion(10): {
+		fmt.Sprintf("ALTER TABLE %s DROP COLUMN description", webauthnCredentialsTableName),
+	},
+	// The first device of each user becomes the secret of the user, the other devices are lost.
+	SchemaVersion(11): {
+		fmt.Sprintf("CREATE TABLE %s (username VARCHAR(100) PRIMARY KEY, secret VARCHAR(64), last_used_step BIGINT)", totpSecretsTableName),
+		fmt.Sprintf("INSERT INTO %s (username, secret, last_used_step) SELECT username, secret, last_used_step FROM %s AS devices WHERE device_id=(SELECT MIN(device_id) FROM %s WHERE username=devices.username)",
+			totpSecretsTableName, totpDevicesTableName, totpDevicesTableName),
+	},
+	// The backup codes hashed with the password hasher can't be checked by the previous versions, they're deleted.
+	SchemaVersion(23): {
+		fmt.Sprintf("DELETE FROM %s WHERE code_hash LIKE '$%%'", backupCodesTableName),
+	},
+	// The second factor credentials can't be told apart from the passkeys by the previous versions, they're deleted.
+	SchemaVersion(24): {
+		fmt.Sprintf("DELETE FROM %s WHERE credential_type<>'%s'", webauthnCredentialsTableName, models.WebauthnCredentialTypePasskey),
+		fmt.Sprintf("ALTER TABLE %s DROP COLUMN credential_type", webauthnCredentialsTableName),
+	},
+}
+
+// sqlUserEventsQuery is the query returning the authentication attempts and the other events of a user, it's
+// fmt.Sprintf'd with the placeholders of the username, the event type filter twice, the limit and the offset.
+var sqlUserEventsQuery = fmt.Sprintf("SELECT event, successful, time, remote_ip, user_agent FROM ("+
+	"SELECT username, COALESCE(auth_type, '%s') AS event, successful, time, remote_ip, user_agent FROM %s "+
+	"UNION ALL SELECT username, event, successful, time, remote_ip, user_agent FROM %s"+
+	") AS events WHERE username=%%s AND (%%s='' OR event=%%s) ORDER BY time DESC LIMIT %%s OFFSET %%s",
+	models.AuthenticationTypeFirstFactor, authenticationLogsTableName, userEventsTableName)
+
+// sqlUserEventsQueryPostgreSQL is sqlUserEventsQuery with the numbered placeholders of PostgreSQL. Each argument has its
+// own placeholder, including the event type passed twice, as LoadUserEvents binds the arguments by position.
+var sqlUserEventsQueryPostgreSQL = fmt.Sprintf(sqlUserEventsQuery, "$1", "$2", "$3", "$4", "$5")
+
+// userDataTableNames are the tables holding data of a user keyed by the username, the data is deleted from all of
+// them when the user is purged.
+var userDataTableNames = []string{
+	userPreferencesTableName,
+	totpDevicesTableName,
+	u2fDeviceHandlesTableName,
+	webauthnCredentialsTableName,
+	authenticationLogsTableName,
+	userEventsTableName,
+	notificationThrottlesTableName,
+	announcementAcknowledgmentsTableName,
+	backupCodesTableName,
+	pushEnrollmentsTableName,
+	secondFactorResetsTableName,
+	accessGrantsTableName,
+	oidcConsentsTableName,
+}
+
+// secondFactorTableNames are the tables holding the second factor devices of a user keyed by the username, the devices
+// are deleted from all of them when the second factor of the user is reset.
+var secondFactorTableNames = []string{
+	totpDevicesTableName,
+	u2fDeviceHandlesTableName,
+	webauthnCredentialsTableName,
+	backupCodesTableName,
+	pushEnrollmentsTableName,
+}
+
+// usernameTableNames are the tables the users known to the storage are loaded from, the users are known once they
+// logged in, set a preference or registered a second factor device.
+var usernameTableNames = []string{
+	authenticationLogsTableName,
+	userPreferencesTableName,
+	totpDevicesTableName,
+	u2fDeviceHandlesTableName,
+	webauthnCredentialsTableName,
+	userEventsTableName,
+}
+
+// sqlSelectUsernamesStatement returns the statement selecting the distinct usernames of the tables holding the users
+// known to the storage, it has no placeholder so it's the same in all the dialects.
+func sqlSelectUsernamesStatement() string {
+	statements := make([]string, 0, len(usernameTableNames))
+
+	for _, table := range usernameTableNames {
+		statements = append(statements, fmt.Sprintf("SELECT username FROM %s", table))
+	}
+
+	return strings.Join(statements, " UNION ") + " ORDER BY username"
+}
+
+// sqlDeleteUserDataStatements returns the statements deleting the data of a user from all the tables holding some,
+// the placeholder is the one of the username in the dialect.
+func sqlDeleteUserDataStatements(placeholder string) []string {
+	return sqlDeleteByUsernameStatements(userDataTableNames, placeholder)
+}
+
+// sqlDeleteSecondFactorDataStatements returns the statements deleting the second factor devices of a user, the
+// placeholder is the one of the username in the dialect.
+func sqlDeleteSecondFactorDataStatements(placeholder string) []string {
+	return sqlDeleteByUsernameStatements(secondFactorTableNames, placeholder)
+}
+
+func sqlDeleteByUsernameStatements(tables []string, placeholder string) []string {
+	statements := make([]string, 0, len(tables))
+
+	for _, table := range tables {
+		statements = append(statements, fmt.Sprintf("DELETE FROM %s WHERE username=%s", table, placeholder))
+	}
+
+	return statements
+}
+
+// The ID and description of the TOTP device the secret registered before several devices were supported is migrated to.
+const (
+	totpDefaultDeviceID          = "default"
+	totpDefaultDeviceDescription = "One-time password device"
+)
+
 const unitTestUser = "john"
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// SchemaDowngrade downgrades the schema to the provided version by reverting the upgrades of the versions above it,
+// e.g. before running an older version of Authelia. The data of the tables and columns introduced by these versions is
+// lost. It returns the version the schema was downgraded from.
+func (p *SQLProvider) SchemaDowngrade(version SchemaVersion) (from SchemaVersion, err error) {
+	from, tables, err := p.getSchemaBasicDetails()
+	if err != nil {
+		return from, err
+	}
+
+	if version < 1 || version >= from {
+		return from, fmt.Errorf("storage schema v%d can't be downgraded to v%d, the version must be at least v1 and lower than the current one", from, version)
+	}
+
+	// The versions before the encryption of the secrets read the encrypted secrets as if they were in plaintext.
+	if version < storageSchemaEncryptionVersion {
+		check, err := p.loadEncryptionCheck()
+		if err != nil {
+			return from, err
+		}
+
+		if check != "" {
+			return from, fmt.Errorf("storage schema v%d can't be downgraded to v%d while the secrets are encrypted, they must be decrypted first", from, version)
+		}
+	}
+
+	tx, err := p.begin()
+	if err != nil {
+		return from, err
+	}
+
+	for current := from; current > version; current-- {
+		if err = p.downgradeSchemaFromVersion(tx, current, tables); err != nil {
+			return from, p.handleSchemaChangeFailure(tx, storageSchemaDowngradeErrorText, current, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return from, err
+	}
+
+	if p.dryRun != nil {
+		p.log.Infof("Storage schema downgrade to v%d has not been applied (dry-run)", version)
+		return from, nil
+	}
+
+	p.log.Infof("Storage schema downgrade from v%d to v%d completed", from, version)
+
+	return from, nil
+}
+
+// downgradeSchemaFromVersion downgrades the schema from the provided version to the previous one by running its
+// downgrade statements and dropping the tables introduced in that version.
+func (p *SQLProvider) downgradeSchemaFromVersion(tx transaction, version SchemaVersion, existingTables []string) error {
+	if err := p.upgradeRunMultipleStatements(tx, p.sqlDowngradesStatements[version]); err != nil {
+		return fmt.Errorf("Unable to alter tables: %v", err)
+	}
+
+	keys := make([]string, 0, len(p.sqlUpgradesCreateTableStatements[version]))
+	for k := range p.sqlUpgradesCreateTableStatements[version] {
+		keys = append(keys, k)
+	}
+
+	sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+
+	for _, table := range keys {
+		if utils.IsStringInSlice(table, existingTables) {
+			if _, err := tx.Exec(fmt.Sprintf("DROP TABLE %s", table)); err != nil {
+				return fmt.Errorf("Unable to drop table %s: %v", table, err)
+			}
+		}
+	}
+
+	if _, err := tx.Exec(p.sqlConfigSetValue, "schema", "version", (version - 1).ToString()); err != nil {
+		return err
+	}
+
+	p.log.Debugf("%s%d", storageSchemaDowngradeMessage, version-1)
+
+	return nil
+}
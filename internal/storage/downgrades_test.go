@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"encoding/base64"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/models"
+)
+
+func TestShouldDowngradeAndUpgradeSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.sqlite3")
+
+	provider := NewSQLiteProvider(path)
+
+	require.NoError(t, provider.SaveTOTPDevice(models.TOTPDevice{ID: "phone", Username: unitTestUser, Secret: "secret1", CreatedAt: time.Unix(1577880000, 0)}))
+	require.NoError(t, provider.SaveTOTPDevice(models.TOTPDevice{ID: "tablet", Username: unitTestUser, Secret: "secret2", CreatedAt: time.Unix(1577880001, 0)}))
+	require.NoError(t, provider.AppendAuthenticationLog(models.AuthenticationAttempt{Username: unitTestUser, Successful: true, Time: time.Unix(1577880002, 0), RemoteIP: "127.0.0.1"}))
+
+	from, err := provider.SchemaDowngrade(10)
+	require.NoError(t, err)
+	assert.Equal(t, storageSchemaCurrentVersion, from)
+
+	version, tables, err := provider.getSchemaBasicDetails()
+	require.NoError(t, err)
+	assert.Equal(t, SchemaVersion(10), version)
+	assert.Contains(t, tables, totpSecretsTableName)
+	assert.NotContains(t, tables, totpDevicesTableName)
+	assert.NotContains(t, tables, oidcClientsTableName)
+
+	// The first device of the user becomes the secret of the user.
+	var secret string
+
+	require.NoError(t, provider.db.QueryRow("SELECT secret FROM totp_secrets WHERE username=?", unitTestUser).Scan(&secret))
+	assert.Equal(t, "secret1", secret)
+
+	_, err = provider.SchemaDowngrade(1)
+	require.NoError(t, err)
+
+	_, err = provider.SchemaDowngrade(1)
+	assert.EqualError(t, err, "storage schema v1 can't be downgraded to v1, the version must be at least v1 and lower than the current one")
+
+	// The schema is upgraded again when the provider is initialized.
+	provider = NewSQLiteProvider(path)
+
+	from, to, upgraded := provider.SchemaUpgrade()
+	assert.True(t, upgraded)
+	assert.Equal(t, SchemaVersion(1), from)
+	assert.Equal(t, storageSchemaCurrentVersion, to)
+
+	devices, err := provider.LoadTOTPDevices(unitTestUser)
+	require.NoError(t, err)
+	require.Len(t, devices, 1)
+	assert.Equal(t, totpDefaultDeviceID, devices[0].ID)
+	assert.Equal(t, "secret1", devices[0].Secret)
+
+	attempts, err := provider.LoadLatestAuthenticationLogs(unitTestUser, time.Unix(0, 0))
+	require.NoError(t, err)
+	require.Len(t, attempts, 1)
+	assert.Equal(t, "", attempts[0].RemoteIP)
+}
+
+func TestShouldDeleteSaltedBackupCodesOnDowngrade(t *testing.T) {
+	provider := NewSQLiteProvider(filepath.Join(t.TempDir(), "db.sqlite3"))
+
+	require.NoError(t, provider.SaveBackupCodes(unitTestUser, []string{
+		"8ba0ee0b6f5ab5c7b1f5e3ac3d3a0a0f0e9de0e9c1d6e46a1c0b4b1d1a7c2c3e",
+		"$argon2id$v=19$m=65536,t=1,p=8$c2FsdA$a2V5",
+	}, time.Unix(1577880000, 0)))
+
+	_, err := provider.SchemaDowngrade(SchemaVersion(22))
+	require.NoError(t, err)
+
+	// The codes hashed before the password hasher was used can still be checked by the previous version.
+	codeHashes, err := provider.LoadBackupCodeHashes(unitTestUser)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"8ba0ee0b6f5ab5c7b1f5e3ac3d3a0a0f0e9de0e9c1d6e46a1c0b4b1d1a7c2c3e"}, codeHashes)
+}
+
+func TestShouldDeleteSecondFactorWebauthnCredentialsOnDowngrade(t *testing.T) {
+	provider := NewSQLiteProvider(filepath.Join(t.TempDir(), "db.sqlite3"))
+
+	for _, credential := range []models.WebauthnCredential{
+		{ID: []byte("passkey"), Username: unitTestUser, Type: models.WebauthnCredentialTypePasskey, CreatedAt: time.Unix(1577880000, 0)},
+		{ID: []byte("second-factor"), Username: unitTestUser, Type: models.WebauthnCredentialTypeSecondFactor, CreatedAt: time.Unix(1577880001, 0)},
+	} {
+		require.NoError(t, provider.SaveWebauthnCredential(credential))
+	}
+
+	_, err := provider.SchemaDowngrade(SchemaVersion(23))
+	require.NoError(t, err)
+
+	// The previous versions would let the second factor credentials login without a password.
+	rows, err := provider.db.Query(fmt.Sprintf("SELECT credential_id FROM %s", webauthnCredentialsTableName))
+	require.NoError(t, err)
+
+	defer rows.Close()
+
+	var ids []string
+
+	for rows.Next() {
+		var id string
+
+		require.NoError(t, rows.Scan(&id))
+
+		ids = append(ids, id)
+	}
+
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []string{base64.StdEncoding.EncodeToString([]byte("passkey"))}, ids)
+}
@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+// NewDryRunProvider creates the storage Provider described by the configuration in dry-run mode, the statements
+// changing the database including the pending schema upgrades are written to out instead of being executed. Only the
+// built-in SQL providers support the dry-run mode.
+func NewDryRunProvider(configuration schema.StorageConfiguration, out io.Writer) (Provider, error) {
+	var provider encryptingProvider
+
+	switch {
+	case configuration.Provider != "":
+		return nil, fmt.Errorf("storage provider '%s' doesn't support the dry-run mode", configuration.Provider)
+	case configuration.PostgreSQL != nil:
+		provider = newPostgreSQLProvider(*configuration.PostgreSQL, out)
+	case configuration.MySQL != nil:
+		provider = newMySQLProvider(*configuration.MySQL, out)
+	case configuration.Local != nil:
+		provider = newSQLiteProvider(configuration.Local.Path, out)
+	default:
+		return nil, fmt.Errorf("unrecognized storage backend")
+	}
+
+	if err := provider.useEncryptionKey(configuration.EncryptionKey, configuration.PreviousEncryptionKey); err != nil {
+		return nil, err
+	}
+
+	return provider, nil
+}
+
+// dryRunTransaction is a transaction writing the statements to out instead of executing them.
+type dryRunTransaction struct {
+	out io.Writer
+}
+
+// Exec writes the statement followed by its arguments as a comment.
+func (t dryRunTransaction) Exec(query string, args ...interface{}) (result sql.Result, err error) {
+	if len(args) == 0 {
+		_, err = fmt.Fprintf(t.out, "%s;\n", query)
+	} else {
+		values := make([]string, len(args))
+
+		for i, arg := range args {
+			values[i] = fmt.Sprintf("%v", arg)
+		}
+
+		_, err = fmt.Fprintf(t.out, "%s; -- %s\n", query, strings.Join(values, ", "))
+	}
+
+	return driver.RowsAffected(0), err
+}
+
+// Commit does nothing as nothing has been executed.
+func (t dryRunTransaction) Commit() error {
+	return nil
+}
+
+// Rollback does nothing as nothing has been executed.
+func (t dryRunTransaction) Rollback() error {
+	return nil
+}
@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+func TestShouldWriteStatementsInsteadOfExecutingThemInDryRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.sqlite3")
+	out := &bytes.Buffer{}
+
+	provider, err := NewDryRunProvider(schema.StorageConfiguration{Local: &schema.LocalStorageConfiguration{Path: path}}, out)
+	require.NoError(t, err)
+
+	assert.Contains(t, out.String(), fmt.Sprintf("CREATE TABLE %s (username VARCHAR(100) PRIMARY KEY, revoked_at INTEGER NOT NULL);\n", sessionRevocationsTableName))
+	assert.Contains(t, out.String(), fmt.Sprintf("REPLACE INTO %s (category, key_name, value) VALUES (?, ?, ?); -- schema, version, %d\n", configTableName, storageSchemaCurrentVersion))
+
+	out.Reset()
+
+	require.NoError(t, provider.PurgeUser(unitTestUser, time.Unix(1577880001, 0)))
+
+	assert.Contains(t, out.String(), fmt.Sprintf("DELETE FROM %s WHERE username=?; -- john\n", totpDevicesTableName))
+	assert.Contains(t, out.String(), fmt.Sprintf("REPLACE INTO %s (username, revoked_at) VALUES (?, ?); -- john, 1577880001\n", sessionRevocationsTableName))
+
+	// Nothing has been applied so the database is still empty.
+	version, tables, err := provider.(*SQLiteProvider).getSchemaBasicDetails()
+	require.NoError(t, err)
+	assert.Equal(t, SchemaVersion(0), version)
+	assert.Len(t, tables, 0)
+}
+
+func TestShouldNotSupportDryRunForRegisteredProviders(t *testing.T) {
+	_, err := NewDryRunProvider(schema.StorageConfiguration{Provider: "custom"}, &bytes.Buffer{})
+	assert.EqualError(t, err, "storage provider 'custom' doesn't support the dry-run mode")
+}
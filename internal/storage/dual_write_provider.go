@@ -0,0 +1,666 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/authelia/authelia/internal/logging"
+	"github.com/authelia/authelia/internal/models"
+)
+
+// DualWriteProvider is a Provider migrating the data from the current provider to a target provider. The reads are
+// served by the current provider and the writes are sent to both providers. The writes failing on the target provider
+// are logged without failing the request, they're reported by the verification of the migration.
+type DualWriteProvider struct {
+	current Provider
+	target  Provider
+}
+
+// NewDualWriteProvider creates a Provider reading from the current provider and writing to both providers.
+func NewDualWriteProvider(current, target Provider) *DualWriteProvider {
+	return &DualWriteProvider{current: current, target: target}
+}
+
+// SchemaUpgrade returns the upgrade of the schema of the current provider.
+func (p *DualWriteProvider) SchemaUpgrade() (from, to SchemaVersion, upgraded bool) {
+	if upgrader, ok := p.current.(SchemaUpgrader); ok {
+		return upgrader.SchemaUpgrade()
+	}
+
+	return 0, 0, false
+}
+
+// SchemaDowngrade downgrades the schema of the current provider, then the one of the target provider so they keep the
+// same schema.
+func (p *DualWriteProvider) SchemaDowngrade(version SchemaVersion) (from SchemaVersion, err error) {
+	current, currentOk := p.current.(SchemaDowngrader)
+	target, targetOk := p.target.(SchemaDowngrader)
+
+	if !currentOk || !targetOk {
+		return 0, errors.New("the storage providers don't support schema downgrades")
+	}
+
+	if from, err = current.SchemaDowngrade(version); err != nil {
+		return from, err
+	}
+
+	if _, err = target.SchemaDowngrade(version); err != nil {
+		return from, fmt.Errorf("Unable to downgrade the schema of the storage migration target: %v", err)
+	}
+
+	return from, nil
+}
+
+// ChangeEncryptionKey encrypts the secrets of the current provider with the provided key, then the ones of the target
+// provider so they keep being encrypted with the same key. It returns the number of secrets of the current provider
+// encrypted.
+func (p *DualWriteProvider) ChangeEncryptionKey(key string) (count int, err error) {
+	current, currentOk := p.current.(SecretsEncrypter)
+	target, targetOk := p.target.(SecretsEncrypter)
+
+	if !currentOk || !targetOk {
+		return 0, errors.New("the storage providers don't support the encryption of the secrets")
+	}
+
+	if count, err = current.ChangeEncryptionKey(key); err != nil {
+		return count, err
+	}
+
+	if _, err = target.ChangeEncryptionKey(key); err != nil {
+		return count, fmt.Errorf("Unable to encrypt the secrets of the storage migration target: %v", err)
+	}
+
+	return count, nil
+}
+
+// RotateEncryptionKey rotates the secrets of the current provider to the encryption key, then the ones of the target
+// provider. It returns the number of secrets of the current provider rotated.
+func (p *DualWriteProvider) RotateEncryptionKey(batchSize int) (count int, err error) {
+	current, currentOk := p.current.(SecretsEncrypter)
+	target, targetOk := p.target.(SecretsEncrypter)
+
+	if !currentOk || !targetOk {
+		return 0, errors.New("the storage providers don't support the encryption of the secrets")
+	}
+
+	if count, err = current.RotateEncryptionKey(batchSize); err != nil {
+		return count, err
+	}
+
+	if _, err = target.RotateEncryptionKey(batchSize); err != nil {
+		return count, fmt.Errorf("Unable to rotate the secrets of the storage migration target: %v", err)
+	}
+
+	return count, nil
+}
+
+// CheckEncryption checks the secrets of the current provider which serves the reads.
+func (p *DualWriteProvider) CheckEncryption() (check EncryptionCheck, err error) {
+	current, ok := p.current.(SecretsEncrypter)
+	if !ok {
+		return check, errors.New("the storage providers don't support the encryption of the secrets")
+	}
+
+	return current.CheckEncryption()
+}
+
+// write applies the write to the current provider, then to the target provider when it succeeded.
+func (p *DualWriteProvider) write(operation string, write func(provider Provider) error) error {
+	if err := write(p.current); err != nil {
+		return err
+	}
+
+	if err := write(p.target); err != nil {
+		logging.Logger().Errorf("Unable to %s in the storage migration target: %v", operation, err)
+	}
+
+	return nil
+}
+
+// prune applies the pruning to both providers and returns the number of rows pruned from the current provider.
+func (p *DualWriteProvider) prune(operation string, prune func(provider Provider) (int64, error)) (int64, error) {
+	count, err := prune(p.current)
+	if err != nil {
+		return count, err
+	}
+
+	if _, err = prune(p.target); err != nil {
+		logging.Logger().Errorf("Unable to %s in the storage migration target: %v", operation, err)
+	}
+
+	return count, nil
+}
+
+// LoadPreferred2FAMethod load the preferred method for 2FA from the current provider.
+func (p *DualWriteProvider) LoadPreferred2FAMethod(username string) (string, error) {
+	return p.current.LoadPreferred2FAMethod(username)
+}
+
+// SavePreferred2FAMethod save the preferred method for 2FA to both providers.
+func (p *DualWriteProvider) SavePreferred2FAMethod(username string, method string) error {
+	return p.write("save the preferred 2FA method", func(provider Provider) error {
+		return provider.SavePreferred2FAMethod(username, method)
+	})
+}
+
+// DeletePreferred2FAMethod delete the preferred 2FA method of a given user from both providers.
+func (p *DualWriteProvider) DeletePreferred2FAMethod(username string) error {
+	return p.write("delete the preferred 2FA method", func(provider Provider) error {
+		return provider.DeletePreferred2FAMethod(username)
+	})
+}
+
+// FindIdentityVerificationToken look for an identity verification token in the current provider.
+func (p *DualWriteProvider) FindIdentityVerificationToken(token string) (bool, error) {
+	return p.current.FindIdentityVerificationToken(token)
+}
+
+// SaveIdentityVerificationToken save an identity verification token to both providers.
+func (p *DualWriteProvider) SaveIdentityVerificationToken(token string, expiresAt time.Time) error {
+	return p.write("save the identity verification token", func(provider Provider) error {
+		return provider.SaveIdentityVerificationToken(token, expiresAt)
+	})
+}
+
+// RemoveIdentityVerificationToken remove an identity verification token from both providers.
+func (p *DualWriteProvider) RemoveIdentityVerificationToken(token string) error {
+	return p.write("remove the identity verification token", func(provider Provider) error {
+		return provider.RemoveIdentityVerificationToken(token)
+	})
+}
+
+// SaveLoginState save the nonce of a login state to both providers.
+func (p *DualWriteProvider) SaveLoginState(nonce string, expiresAt time.Time) error {
+	return p.write("save the login state", func(provider Provider) error {
+		return provider.SaveLoginState(nonce, expiresAt)
+	})
+}
+
+// ConsumeLoginState consume the nonce of a login state from both providers. The nonces saved before the migration
+// started are only known by the current provider so they're not reported as missing from the target provider.
+func (p *DualWriteProvider) ConsumeLoginState(nonce string, now time.Time) error {
+	return p.write("consume the login state", func(provider Provider) error {
+		if err := provider.ConsumeLoginState(nonce, now); err != nil && (provider == p.current || err != ErrNoLoginState) {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// SaveTOTPDevice save a TOTP device registered by a user to both providers.
+func (p *DualWriteProvider) SaveTOTPDevice(device models.TOTPDevice) error {
+	return p.write("save the TOTP device", func(provider Provider) error {
+		return provider.SaveTOTPDevice(device)
+	})
+}
+
+// LoadTOTPDevices load all the TOTP devices of a given user from the current provider.
+func (p *DualWriteProvider) LoadTOTPDevices(username string) ([]models.TOTPDevice, error) {
+	return p.current.LoadTOTPDevices(username)
+}
+
+// UpdateTOTPDeviceDescription update the description of a TOTP device of a given user in both providers.
+func (p *DualWriteProvider) UpdateTOTPDeviceDescription(username, deviceID, description string) error {
+	return p.write("update the TOTP device description", func(provider Provider) error {
+		return provider.UpdateTOTPDeviceDescription(username, deviceID, description)
+	})
+}
+
+// DeleteTOTPDevice delete a TOTP device of a given user from both providers.
+func (p *DualWriteProvider) DeleteTOTPDevice(username, deviceID string) error {
+	return p.write("delete the TOTP device", func(provider Provider) error {
+		return provider.DeleteTOTPDevice(username, deviceID)
+	})
+}
+
+// SaveTOTPDeviceLastUsed save the last TOTP time step used with a device of a given user to both providers.
+func (p *DualWriteProvider) SaveTOTPDeviceLastUsed(username, deviceID string, step uint64, usedAt time.Time) error {
+	return p.write("save the last used TOTP step", func(provider Provider) error {
+		return provider.SaveTOTPDeviceLastUsed(username, deviceID, step, usedAt)
+	})
+}
+
+// SaveU2FDeviceHandle save a registered U2F device registration blob to both providers.
+func (p *DualWriteProvider) SaveU2FDeviceHandle(username string, keyHandle []byte, publicKey []byte) error {
+	return p.write("save the U2F device handle", func(provider Provider) error {
+		return provider.SaveU2FDeviceHandle(username, keyHandle, publicKey)
+	})
+}
+
+// LoadU2FDeviceHandle load a U2F device registration blob for a given username from the current provider.
+func (p *DualWriteProvider) LoadU2FDeviceHandle(username string) (keyHandle []byte, publicKey []byte, err error) {
+	return p.current.LoadU2FDeviceHandle(username)
+}
+
+// DeleteU2FDeviceHandle delete the U2F device of a given user from both providers. The device is only checked against
+// the current provider, the target may not have it yet when it has been copied before the device was registered.
+func (p *DualWriteProvider) DeleteU2FDeviceHandle(username string) error {
+	return p.write("delete the U2F device", func(provider Provider) error {
+		if err := provider.DeleteU2FDeviceHandle(username); err != nil && (provider == p.current || err != ErrNoU2FDeviceHandle) {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// SaveWebauthnCredential save a WebAuthn credential to both providers.
+func (p *DualWriteProvider) SaveWebauthnCredential(credential models.WebauthnCredential) error {
+	return p.write("save the WebAuthn credential", func(provider Provider) error {
+		return provider.SaveWebauthnCredential(credential)
+	})
+}
+
+// LoadWebauthnCredential load a WebAuthn credential given its ID from the current provider.
+func (p *DualWriteProvider) LoadWebauthnCredential(credentialID []byte) (*models.WebauthnCredential, error) {
+	return p.current.LoadWebauthnCredential(credentialID)
+}
+
+// LoadWebauthnCredentials load the WebAuthn credentials of a given user from the current provider.
+func (p *DualWriteProvider) LoadWebauthnCredentials(username string) ([]models.WebauthnCredential, error) {
+	return p.current.LoadWebauthnCredentials(username)
+}
+
+// UpdateWebauthnCredentialSignCount update the sign count of a WebAuthn credential in both providers.
+func (p *DualWriteProvider) UpdateWebauthnCredentialSignCount(credentialID []byte, signCount uint32) error {
+	return p.write("update the WebAuthn credential sign count", func(provider Provider) error {
+		return provider.UpdateWebauthnCredentialSignCount(credentialID, signCount)
+	})
+}
+
+// DeleteWebauthnCredential delete a WebAuthn credential of a given user from both providers. The credential is only
+// checked against the current provider, the target may not have it yet when it has been copied before the credential
+// was registered.
+func (p *DualWriteProvider) DeleteWebauthnCredential(username string, credentialID []byte) error {
+	return p.write("delete the WebAuthn credential", func(provider Provider) error {
+		if err := provider.DeleteWebauthnCredential(username, credentialID); err != nil && (provider == p.current || err != ErrNoWebauthnCredential) {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// AppendAuthenticationLog append a mark to the authentication log of both providers.
+func (p *DualWriteProvider) AppendAuthenticationLog(attempt models.AuthenticationAttempt) error {
+	return p.write("append the authentication log", func(provider Provider) error {
+		return provider.AppendAuthenticationLog(attempt)
+	})
+}
+
+// LoadLatestAuthenticationLogs retrieve the latest marks from the authentication log of the current provider.
+func (p *DualWriteProvider) LoadLatestAuthenticationLogs(username string, fromDate time.Time) ([]models.AuthenticationAttempt, error) {
+	return p.current.LoadLatestAuthenticationLogs(username, fromDate)
+}
+
+// DeleteFailedAuthenticationLogs delete the failed authentication attempts of a given user from both providers and
+// returns the number of attempts deleted from the current provider.
+func (p *DualWriteProvider) DeleteFailedAuthenticationLogs(username string) (int64, error) {
+	return p.prune("delete the failed authentication attempts", func(provider Provider) (int64, error) {
+		return provider.DeleteFailedAuthenticationLogs(username)
+	})
+}
+
+// LoadLastLogin load the time of the latest successful authentication of a user from the current provider.
+func (p *DualWriteProvider) LoadLastLogin(username string) (time.Time, error) {
+	return p.current.LoadLastLogin(username)
+}
+
+// AppendUserEvent append a security event of a user to both providers.
+func (p *DualWriteProvider) AppendUserEvent(event models.UserEvent) error {
+	return p.write("append the user event", func(provider Provider) error {
+		return provider.AppendUserEvent(event)
+	})
+}
+
+// LoadUserEvents load the security events of a user from the current provider.
+func (p *DualWriteProvider) LoadUserEvents(username, eventType string, limit, offset int) ([]models.UserEvent, error) {
+	return p.current.LoadUserEvents(username, eventType, limit, offset)
+}
+
+// LoadNotificationThrottle load the throttling state of a kind of notification sent to a user from the current provider.
+func (p *DualWriteProvider) LoadNotificationThrottle(username, kind string) (models.NotificationThrottle, error) {
+	return p.current.LoadNotificationThrottle(username, kind)
+}
+
+// SaveNotificationThrottle save the throttling state of a kind of notification sent to a user to both providers.
+func (p *DualWriteProvider) SaveNotificationThrottle(throttle models.NotificationThrottle) error {
+	return p.write("save the notification throttle", func(provider Provider) error {
+		return provider.SaveNotificationThrottle(throttle)
+	})
+}
+
+// LoadPendingNotificationThrottles load the throttling states having pending notifications from the current provider.
+func (p *DualWriteProvider) LoadPendingNotificationThrottles() ([]models.NotificationThrottle, error) {
+	return p.current.LoadPendingNotificationThrottles()
+}
+
+// SaveAnnouncementAcknowledgment save the time a user acknowledged an announcement to both providers.
+func (p *DualWriteProvider) SaveAnnouncementAcknowledgment(username, announcementID string, acknowledgedAt time.Time) error {
+	return p.write("save the announcement acknowledgment", func(provider Provider) error {
+		return provider.SaveAnnouncementAcknowledgment(username, announcementID, acknowledgedAt)
+	})
+}
+
+// LoadAnnouncementAcknowledgment load the time a user acknowledged an announcement from the current provider.
+func (p *DualWriteProvider) LoadAnnouncementAcknowledgment(username, announcementID string) (time.Time, error) {
+	return p.current.LoadAnnouncementAcknowledgment(username, announcementID)
+}
+
+// SaveBackupCodes replace the backup codes of a user in both providers.
+func (p *DualWriteProvider) SaveBackupCodes(username string, codeHashes []string, createdAt time.Time) error {
+	return p.write("save the backup codes", func(provider Provider) error {
+		return provider.SaveBackupCodes(username, codeHashes, createdAt)
+	})
+}
+
+// ConsumeBackupCode delete a backup code of a user from both providers. The code is only checked against the current
+// provider, the target may not have it yet when it has been copied before the code was generated.
+func (p *DualWriteProvider) ConsumeBackupCode(username, codeHash string) error {
+	return p.write("consume the backup code", func(provider Provider) error {
+		if err := provider.ConsumeBackupCode(username, codeHash); err != nil && (provider == p.current || err != ErrNoBackupCode) {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// LoadBackupCodeHashes load the hashes of the unused backup codes of a user from the current provider.
+func (p *DualWriteProvider) LoadBackupCodeHashes(username string) ([]string, error) {
+	return p.current.LoadBackupCodeHashes(username)
+}
+
+// CountBackupCodes count the unused backup codes of a user in the current provider.
+func (p *DualWriteProvider) CountBackupCodes(username string) (int, error) {
+	return p.current.CountBackupCodes(username)
+}
+
+// SaveAdminAPIToken save a new admin API token in both providers.
+func (p *DualWriteProvider) SaveAdminAPIToken(token models.AdminAPIToken) error {
+	return p.write("save the admin API token", func(provider Provider) error {
+		return provider.SaveAdminAPIToken(token)
+	})
+}
+
+// LoadAdminAPIToken load an admin API token from the current provider.
+func (p *DualWriteProvider) LoadAdminAPIToken(tokenHash string) (*models.AdminAPIToken, error) {
+	return p.current.LoadAdminAPIToken(tokenHash)
+}
+
+// SavePushEnrollment save the enrollment state of a user with the push notification provider in both providers.
+func (p *DualWriteProvider) SavePushEnrollment(enrollment models.PushEnrollment) error {
+	return p.write("save the push enrollment", func(provider Provider) error {
+		return provider.SavePushEnrollment(enrollment)
+	})
+}
+
+// LoadPushEnrollment load the enrollment state of a user with the push notification provider from the current provider.
+func (p *DualWriteProvider) LoadPushEnrollment(username string) (*models.PushEnrollment, error) {
+	return p.current.LoadPushEnrollment(username)
+}
+
+// SaveSecondFactorReset save a new pending second factor reset in both providers.
+func (p *DualWriteProvider) SaveSecondFactorReset(reset models.SecondFactorReset) error {
+	return p.write("save the second factor reset", func(provider Provider) error {
+		return provider.SaveSecondFactorReset(reset)
+	})
+}
+
+// LoadSecondFactorReset load the second factor reset with the given ID from the current provider.
+func (p *DualWriteProvider) LoadSecondFactorReset(id string) (*models.SecondFactorReset, error) {
+	return p.current.LoadSecondFactorReset(id)
+}
+
+// LoadPendingSecondFactorResets load the pending second factor resets from the current provider.
+func (p *DualWriteProvider) LoadPendingSecondFactorResets(now time.Time) ([]models.SecondFactorReset, error) {
+	return p.current.LoadPendingSecondFactorResets(now)
+}
+
+// ApproveSecondFactorReset approve a pending second factor reset and delete the devices of the user in both providers.
+func (p *DualWriteProvider) ApproveSecondFactorReset(id, username, approvedBy string, approvedAt time.Time) error {
+	return p.write("approve the second factor reset", func(provider Provider) error {
+		return provider.ApproveSecondFactorReset(id, username, approvedBy, approvedAt)
+	})
+}
+
+// RejectSecondFactorReset reject a pending second factor reset in both providers.
+func (p *DualWriteProvider) RejectSecondFactorReset(id, username, rejectedBy string, rejectedAt time.Time) error {
+	return p.write("reject the second factor reset", func(provider Provider) error {
+		return provider.RejectSecondFactorReset(id, username, rejectedBy, rejectedAt)
+	})
+}
+
+// SaveAccessGrant save a new access grant in both providers.
+func (p *DualWriteProvider) SaveAccessGrant(grant models.AccessGrant) error {
+	return p.write("save the access grant", func(provider Provider) error {
+		return provider.SaveAccessGrant(grant)
+	})
+}
+
+// LoadAccessGrant load the access grant with the given ID from the current provider.
+func (p *DualWriteProvider) LoadAccessGrant(id string) (*models.AccessGrant, error) {
+	return p.current.LoadAccessGrant(id)
+}
+
+// LoadAccessGrants load the access grants which haven't expired from the current provider.
+func (p *DualWriteProvider) LoadAccessGrants(now time.Time) ([]models.AccessGrant, error) {
+	return p.current.LoadAccessGrants(now)
+}
+
+// LoadUserAccessGrants load the access grants of a user which haven't expired from the current provider.
+func (p *DualWriteProvider) LoadUserAccessGrants(username string, now time.Time) ([]models.AccessGrant, error) {
+	return p.current.LoadUserAccessGrants(username, now)
+}
+
+// DeleteAccessGrant delete the access grant with the given ID from both providers. The grant is only checked against
+// the current provider, the target may not have it yet when it has been copied before the grant was created.
+func (p *DualWriteProvider) DeleteAccessGrant(id string) error {
+	return p.write("delete the access grant", func(provider Provider) error {
+		if err := provider.DeleteAccessGrant(id); err != nil && (provider == p.current || err != ErrNoAccessGrant) {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// LoadAdminAPITokens load all the admin API tokens from the current provider.
+func (p *DualWriteProvider) LoadAdminAPITokens() ([]models.AdminAPIToken, error) {
+	return p.current.LoadAdminAPITokens()
+}
+
+// UpdateAdminAPITokenLastUsed update the time an admin API token was last used in both providers.
+func (p *DualWriteProvider) UpdateAdminAPITokenLastUsed(id string, lastUsedAt time.Time) error {
+	return p.write("update the last use of the admin API token", func(provider Provider) error {
+		return provider.UpdateAdminAPITokenLastUsed(id, lastUsedAt)
+	})
+}
+
+// DeleteAdminAPIToken delete an admin API token from both providers. The token is only checked against the current
+// provider, the target may not have it yet when it has been copied before the token was created.
+func (p *DualWriteProvider) DeleteAdminAPIToken(id string) error {
+	return p.write("delete the admin API token", func(provider Provider) error {
+		if err := provider.DeleteAdminAPIToken(id); err != nil && (provider == p.current || err != ErrNoAdminAPIToken) {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// LoadUsernames load the usernames of the users known to the current provider.
+func (p *DualWriteProvider) LoadUsernames() ([]string, error) {
+	return p.current.LoadUsernames()
+}
+
+// PurgeUser delete all the data of a user from both providers and revoke the sessions of the user.
+func (p *DualWriteProvider) PurgeUser(username string, revokedAt time.Time) error {
+	return p.write("purge the user", func(provider Provider) error {
+		return provider.PurgeUser(username, revokedAt)
+	})
+}
+
+// RevokeSessions revoke the sessions of a user in both providers.
+func (p *DualWriteProvider) RevokeSessions(username string, revokedAt time.Time) error {
+	return p.write("revoke the sessions", func(provider Provider) error {
+		return provider.RevokeSessions(username, revokedAt)
+	})
+}
+
+// LoadSessionsRevocation load the time the sessions of a user have been revoked from the current provider.
+func (p *DualWriteProvider) LoadSessionsRevocation(username string) (time.Time, error) {
+	return p.current.LoadSessionsRevocation(username)
+}
+
+// PruneIdentityVerificationTokens delete the expired identity verification tokens from both providers.
+func (p *DualWriteProvider) PruneIdentityVerificationTokens(now time.Time) (int64, error) {
+	return p.prune("prune the identity verification tokens", func(provider Provider) (int64, error) {
+		return provider.PruneIdentityVerificationTokens(now)
+	})
+}
+
+// PruneAuthenticationLogs delete the authentication logs older than the given time from both providers.
+func (p *DualWriteProvider) PruneAuthenticationLogs(before time.Time) (int64, error) {
+	return p.prune("prune the authentication logs", func(provider Provider) (int64, error) {
+		return provider.PruneAuthenticationLogs(before)
+	})
+}
+
+// PruneSessionRevocations delete the session revocations older than the given time from both providers.
+func (p *DualWriteProvider) PruneSessionRevocations(before time.Time) (int64, error) {
+	return p.prune("prune the session revocations", func(provider Provider) (int64, error) {
+		return provider.PruneSessionRevocations(before)
+	})
+}
+
+// PruneLoginStates delete the expired login states from both providers.
+func (p *DualWriteProvider) PruneLoginStates(now time.Time) (int64, error) {
+	return p.prune("prune the login states", func(provider Provider) (int64, error) {
+		return provider.PruneLoginStates(now)
+	})
+}
+
+// SaveBreakGlassActivation save the first use of a break-glass account in both providers.
+func (p *DualWriteProvider) SaveBreakGlassActivation(activation models.BreakGlassActivation) error {
+	return p.write("save the break-glass activation", func(provider Provider) error {
+		return provider.SaveBreakGlassActivation(activation)
+	})
+}
+
+// LoadBreakGlassActivation load the first use of a break-glass account from the current provider.
+func (p *DualWriteProvider) LoadBreakGlassActivation(username string) (*models.BreakGlassActivation, error) {
+	return p.current.LoadBreakGlassActivation(username)
+}
+
+// LoadBreakGlassActivations load the first use of all the break-glass accounts from the current provider.
+func (p *DualWriteProvider) LoadBreakGlassActivations() ([]models.BreakGlassActivation, error) {
+	return p.current.LoadBreakGlassActivations()
+}
+
+// DeleteBreakGlassActivation delete the first use of a break-glass account from both providers. The activation is only
+// checked against the current provider, the target may not have it yet when it has been copied before the activation.
+func (p *DualWriteProvider) DeleteBreakGlassActivation(username string) error {
+	return p.write("delete the break-glass activation", func(provider Provider) error {
+		if err := provider.DeleteBreakGlassActivation(username); err != nil && (provider == p.current || err != ErrNoBreakGlassActivation) {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// SaveOIDCConsent save the consent of a user to a client in both providers.
+func (p *DualWriteProvider) SaveOIDCConsent(consent models.OIDCConsent) error {
+	return p.write("save the OpenID Connect consent", func(provider Provider) error {
+		return provider.SaveOIDCConsent(consent)
+	})
+}
+
+// LoadOIDCConsent load the consent of a user to a client from the current provider.
+func (p *DualWriteProvider) LoadOIDCConsent(username, clientID string, now time.Time) (*models.OIDCConsent, error) {
+	return p.current.LoadOIDCConsent(username, clientID, now)
+}
+
+// LoadUserOIDCConsents load the consents of a user from the current provider.
+func (p *DualWriteProvider) LoadUserOIDCConsents(username string, now time.Time) ([]models.OIDCConsent, error) {
+	return p.current.LoadUserOIDCConsents(username, now)
+}
+
+// DeleteOIDCConsent delete the consent of a user to a client from both providers. The consent is only checked against
+// the current provider, the target may not have it yet when it has been copied before the consent.
+func (p *DualWriteProvider) DeleteOIDCConsent(username, clientID string) error {
+	return p.write("delete the OpenID Connect consent", func(provider Provider) error {
+		if err := provider.DeleteOIDCConsent(username, clientID); err != nil && (provider == p.current || err != ErrNoOIDCConsent) {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// SaveOIDCClient save a registered OpenID Connect client in both providers.
+func (p *DualWriteProvider) SaveOIDCClient(client models.OIDCClient) error {
+	return p.write("save the OpenID Connect client", func(provider Provider) error {
+		return provider.SaveOIDCClient(client)
+	})
+}
+
+// LoadOIDCClients load the registered OpenID Connect clients from the current provider.
+func (p *DualWriteProvider) LoadOIDCClients() ([]models.OIDCClient, error) {
+	return p.current.LoadOIDCClients()
+}
+
+// DeleteOIDCClient delete a registered OpenID Connect client from both providers. The client is only checked against
+// the current provider, the target may not have it yet when it has been copied before the registration.
+func (p *DualWriteProvider) DeleteOIDCClient(id string) error {
+	return p.write("delete the OpenID Connect client", func(provider Provider) error {
+		if err := provider.DeleteOIDCClient(id); err != nil && (provider == p.current || err != ErrNoOIDCClient) {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// PruneAccessGrants delete the expired access grants from both providers.
+func (p *DualWriteProvider) PruneAccessGrants(now time.Time) (int64, error) {
+	return p.prune("prune the access grants", func(provider Provider) (int64, error) {
+		return provider.PruneAccessGrants(now)
+	})
+}
+
+// PruneOIDCConsents delete the expired OpenID Connect consents from both providers.
+func (p *DualWriteProvider) PruneOIDCConsents(now time.Time) (int64, error) {
+	return p.prune("prune the OpenID Connect consents", func(provider Provider) (int64, error) {
+		return provider.PruneOIDCConsents(now)
+	})
+}
+
+// Export copy the data of all the tables of the current provider.
+func (p *DualWriteProvider) Export() (*Export, error) {
+	exporter, ok := p.current.(Exporter)
+	if !ok {
+		return nil, errors.New("the current storage provider doesn't support exports")
+	}
+
+	return exporter.Export()
+}
+
+// Import replace the data of all the tables of both providers by the exported data.
+func (p *DualWriteProvider) Import(export *Export) error {
+	for _, provider := range []Provider{p.current, p.target} {
+		importer, ok := provider.(Exporter)
+		if !ok {
+			return errors.New("the storage providers don't support imports")
+		}
+
+		if err := importer.Import(export); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
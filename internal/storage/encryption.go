@@ -0,0 +1,456 @@
+package storage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// secretsEncryption encrypts the secrets of the second factor devices at rest with AES-256-GCM, the key is derived from
+// the storage encryption key with SHA-256. A nil secretsEncryption leaves the secrets in plaintext.
+type secretsEncryption struct {
+	aead cipher.AEAD
+}
+
+func newSecretsEncryption(key string) (*secretsEncryption, error) {
+	if key == "" {
+		return nil, nil
+	}
+
+	sum := sha256.Sum256([]byte(key))
+
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &secretsEncryption{aead: aead}, nil
+}
+
+// encrypt encrypts a value with a random nonce, the value is returned unchanged when there is no encryption key.
+func (e *secretsEncryption) encrypt(value string) (string, error) {
+	if e == nil {
+		return value, nil
+	}
+
+	nonce := make([]byte, e.aead.NonceSize())
+
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	return encryptedValuePrefix + base64.RawStdEncoding.EncodeToString(e.aead.Seal(nonce, nonce, []byte(value), nil)), nil
+}
+
+// decrypt decrypts a value encrypted by encrypt, the values stored before the encryption was enabled are returned
+// unchanged.
+func (e *secretsEncryption) decrypt(value string) (string, error) {
+	if !strings.HasPrefix(value, encryptedValuePrefix) {
+		return value, nil
+	}
+
+	if e == nil {
+		return "", ErrNoEncryptionKey
+	}
+
+	data, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(value, encryptedValuePrefix))
+	if err != nil {
+		return "", fmt.Errorf("Unable to decode the encrypted value: %v", err)
+	}
+
+	if len(data) < e.aead.NonceSize() {
+		return "", errors.New("the encrypted value is too short")
+	}
+
+	plaintext, err := e.aead.Open(nil, data[:e.aead.NonceSize()], data[e.aead.NonceSize():], nil)
+	if err != nil {
+		return "", ErrWrongEncryptionKey
+	}
+
+	return string(plaintext), nil
+}
+
+// useEncryptionKey sets the key the secrets are encrypted with and the one they were encrypted with before it was
+// rotated. The keys are checked against the value encrypted with the key of the secrets already stored, the value is
+// saved when there is none yet.
+func (p *SQLProvider) useEncryptionKey(key, previousKey string) error {
+	encryption, err := newSecretsEncryption(key)
+	if err != nil {
+		return err
+	}
+
+	previousEncryption, err := newSecretsEncryption(previousKey)
+	if err != nil {
+		return err
+	}
+
+	check, err := p.loadEncryptionCheck()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case check == "" && encryption == nil:
+		break
+	case check == "":
+		tx, err := p.begin()
+		if err != nil {
+			return err
+		}
+
+		if err = p.saveEncryptionCheck(tx, encryption); err != nil {
+			return p.rollback(tx, err)
+		}
+
+		if err = tx.Commit(); err != nil {
+			return err
+		}
+	default:
+		// The check value is still encrypted with the previous key while the secrets are rotated.
+		err = checkEncryptionKey(encryption, check)
+		if err == ErrWrongEncryptionKey && previousEncryption != nil {
+			err = checkEncryptionKey(previousEncryption, check)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	p.encryption = encryption
+	p.previousEncryption = previousEncryption
+
+	return nil
+}
+
+// checkEncryptionKey returns an error when the check value isn't encrypted with the key.
+func checkEncryptionKey(encryption *secretsEncryption, check string) error {
+	value, err := encryption.decrypt(check)
+	if err != nil {
+		return err
+	}
+
+	if value != encryptionCheckValue {
+		return ErrWrongEncryptionKey
+	}
+
+	return nil
+}
+
+// decrypt decrypts a value with the encryption key, or with the previous one when the value hasn't been rotated yet.
+func (p *SQLProvider) decrypt(value string) (string, error) {
+	plaintext, err := p.encryption.decrypt(value)
+	if err == ErrWrongEncryptionKey && p.previousEncryption != nil {
+		return p.previousEncryption.decrypt(value)
+	}
+
+	return plaintext, err
+}
+
+// isEncryptedWithKey returns true when the value is encrypted with the encryption key.
+func (p *SQLProvider) isEncryptedWithKey(value string) bool {
+	if !strings.HasPrefix(value, encryptedValuePrefix) {
+		return false
+	}
+
+	_, err := p.encryption.decrypt(value)
+
+	return err == nil
+}
+
+// ChangeEncryptionKey encrypts the secrets of the second factor devices with the provided key, the secrets are stored
+// in plaintext when it's empty. The secrets stored before the encryption was enabled are encrypted too. It returns the
+// number of secrets encrypted.
+func (p *SQLProvider) ChangeEncryptionKey(key string) (count int, err error) {
+	encryption, err := newSecretsEncryption(key)
+	if err != nil {
+		return 0, err
+	}
+
+	totpSecrets, err := p.loadEncryptedValues(p.sqlGetTOTPDeviceSecrets)
+	if err != nil {
+		return 0, fmt.Errorf("Unable to load the TOTP secrets: %v", err)
+	}
+
+	u2fKeyHandles, err := p.loadEncryptedValues(p.sqlGetU2FDeviceKeyHandles)
+	if err != nil {
+		return 0, fmt.Errorf("Unable to load the U2F key handles: %v", err)
+	}
+
+	tx, err := p.begin()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, values := range []struct {
+		rows   []encryptedValue
+		update string
+	}{
+		{totpSecrets, p.sqlUpdateTOTPDeviceSecret},
+		{u2fKeyHandles, p.sqlUpdateU2FDeviceKeyHandle},
+	} {
+		for _, row := range values.rows {
+			if err = p.reencryptValue(tx, encryption, values.update, row); err != nil {
+				return 0, p.rollback(tx, err)
+			}
+
+			count++
+		}
+	}
+
+	if encryption == nil {
+		_, err = tx.Exec(p.sqlConfigDeleteValue, encryptionCheckCategory, encryptionCheckKeyName)
+	} else {
+		err = p.saveEncryptionCheck(tx, encryption)
+	}
+
+	if err != nil {
+		return 0, p.rollback(tx, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	if p.dryRun == nil {
+		p.encryption = encryption
+	}
+
+	return count, nil
+}
+
+// RotateEncryptionKey encrypts the secrets stored in plaintext or encrypted with the previous encryption key with the
+// encryption key. They're rotated in batches of batchSize secrets each committed on its own so the storage stays
+// available while they're rotated, a secret is only updated when it hasn't been changed since it was loaded. The value
+// checking the key is saved with the encryption key once all the secrets are rotated, the previous key can then be
+// removed from the configuration. It returns the number of secrets rotated.
+func (p *SQLProvider) RotateEncryptionKey(batchSize int) (count int, err error) {
+	if p.encryption == nil {
+		return 0, errors.New("no storage encryption key is configured")
+	}
+
+	if batchSize < 1 {
+		return 0, errors.New("the batch size must be greater than 0")
+	}
+
+	var rows []encryptedValue
+
+	for _, values := range []struct {
+		kind   string
+		query  string
+		update string
+	}{
+		{"TOTP secrets", p.sqlGetTOTPDeviceSecrets, p.sqlRotateTOTPDeviceSecret},
+		{"U2F key handles", p.sqlGetU2FDeviceKeyHandles, p.sqlRotateU2FDeviceKeyHandle},
+	} {
+		if rows, err = p.loadEncryptedValues(values.query); err != nil {
+			return count, fmt.Errorf("Unable to load the %s: %v", values.kind, err)
+		}
+
+		var pending []encryptedValue
+
+		for _, row := range rows {
+			if !p.isEncryptedWithKey(row.value) {
+				pending = append(pending, row)
+			}
+		}
+
+		for start := 0; start < len(pending); start += batchSize {
+			end := start + batchSize
+			if end > len(pending) {
+				end = len(pending)
+			}
+
+			if err = p.rotateValues(values.update, pending[start:end]); err != nil {
+				return count, fmt.Errorf("Unable to rotate the %s: %v", values.kind, err)
+			}
+
+			count += end - start
+		}
+	}
+
+	tx, err := p.begin()
+	if err != nil {
+		return count, err
+	}
+
+	if err = p.saveEncryptionCheck(tx, p.encryption); err != nil {
+		return count, p.rollback(tx, err)
+	}
+
+	return count, tx.Commit()
+}
+
+// rotateValues encrypts the values with the encryption key in a single transaction, the update is conditioned on the
+// value loaded so the values changed in the meantime, which are encrypted with the encryption key, are left unchanged.
+func (p *SQLProvider) rotateValues(update string, rows []encryptedValue) error {
+	tx, err := p.begin()
+	if err != nil {
+		return err
+	}
+
+	var value string
+
+	for _, row := range rows {
+		if value, err = p.decrypt(row.value); err != nil {
+			return p.rollback(tx, fmt.Errorf("Unable to decrypt the value of user %s: %v", row.key[0], err))
+		}
+
+		if value, err = p.encryption.encrypt(value); err != nil {
+			return p.rollback(tx, err)
+		}
+
+		args := append(append([]interface{}{value}, row.key...), row.value)
+
+		if _, err = tx.Exec(update, args...); err != nil {
+			return p.rollback(tx, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// CheckEncryption checks the secrets of the second factor devices can be decrypted with the configured keys.
+func (p *SQLProvider) CheckEncryption() (check EncryptionCheck, err error) {
+	var rows []encryptedValue
+
+	for _, values := range []struct {
+		kind  string
+		query string
+	}{
+		{"TOTP secret", p.sqlGetTOTPDeviceSecrets},
+		{"U2F key handle", p.sqlGetU2FDeviceKeyHandles},
+	} {
+		if rows, err = p.loadEncryptedValues(values.query); err != nil {
+			return check, fmt.Errorf("Unable to load the %ss: %v", values.kind, err)
+		}
+
+		for _, row := range rows {
+			check.Total++
+
+			switch {
+			case !strings.HasPrefix(row.value, encryptedValuePrefix):
+				if p.encryption != nil {
+					check.Plaintext++
+				}
+			case p.isEncryptedWithKey(row.value):
+				continue
+			default:
+				if _, err = p.decrypt(row.value); err != nil {
+					check.Failures = append(check.Failures, EncryptionCheckFailure{
+						Kind:     values.kind,
+						Username: fmt.Sprint(row.key[0]),
+						Err:      err,
+					})
+
+					continue
+				}
+
+				check.Previous++
+			}
+		}
+	}
+
+	return check, nil
+}
+
+// encryptedValue is a value encrypted at rest along with the key of its row, i.e. the username and the ID of the
+// device for the TOTP devices.
+type encryptedValue struct {
+	value string
+	key   []interface{}
+}
+
+// loadEncryptedValues loads the values returned by the query, the value is the first column and the key of the row the
+// others.
+func (p *SQLProvider) loadEncryptedValues(query string) (values []encryptedValue, err error) {
+	rows, err := p.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		var (
+			value encryptedValue
+			key   = make([]string, len(columns)-1)
+			dest  = []interface{}{&value.value}
+		)
+
+		for i := range key {
+			dest = append(dest, &key[i])
+		}
+
+		if err = rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		for _, k := range key {
+			value.key = append(value.key, k)
+		}
+
+		values = append(values, value)
+	}
+
+	return values, rows.Err()
+}
+
+// reencryptValue decrypts the value with the current key and updates it with the value encrypted with the new one.
+func (p *SQLProvider) reencryptValue(tx transaction, encryption *secretsEncryption, update string, row encryptedValue) error {
+	value, err := p.decrypt(row.value)
+	if err != nil {
+		return err
+	}
+
+	if value, err = encryption.encrypt(value); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(update, append([]interface{}{value}, row.key...)...)
+
+	return err
+}
+
+// loadEncryptionCheck loads the value encrypted with the key of the secrets stored, there is none when the schema
+// hasn't been created yet, i.e. in dry-run mode.
+func (p *SQLProvider) loadEncryptionCheck() (check string, err error) {
+	version, _, err := p.getSchemaBasicDetails()
+	if err != nil || version == 0 {
+		return "", err
+	}
+
+	err = p.db.QueryRow(p.sqlConfigGetValue, encryptionCheckCategory, encryptionCheckKeyName).Scan(&check)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+
+	return check, err
+}
+
+func (p *SQLProvider) saveEncryptionCheck(tx transaction, encryption *secretsEncryption) error {
+	check, err := encryption.encrypt(encryptionCheckValue)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(p.sqlConfigSetValue, encryptionCheckCategory, encryptionCheckKeyName, check)
+
+	return err
+}
@@ -0,0 +1,249 @@
+package storage
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/models"
+)
+
+func TestShouldEncryptAndDecryptSecrets(t *testing.T) {
+	encryption, err := newSecretsEncryption("a-very-long-encryption-key")
+	require.NoError(t, err)
+
+	encrypted, err := encryption.encrypt("secret")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(encrypted, encryptedValuePrefix))
+	assert.NotContains(t, encrypted, "secret")
+
+	value, err := encryption.decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", value)
+
+	// The values stored before the encryption was enabled are left unchanged.
+	value, err = encryption.decrypt("plaintext")
+	require.NoError(t, err)
+	assert.Equal(t, "plaintext", value)
+
+	other, err := newSecretsEncryption("another-very-long-encryption-key")
+	require.NoError(t, err)
+
+	_, err = other.decrypt(encrypted)
+	assert.Equal(t, ErrWrongEncryptionKey, err)
+
+	var none *secretsEncryption
+
+	_, err = none.decrypt(encrypted)
+	assert.Equal(t, ErrNoEncryptionKey, err)
+}
+
+func TestShouldEncryptSecretsAtRest(t *testing.T) {
+	configuration := schema.StorageConfiguration{Local: &schema.LocalStorageConfiguration{Path: filepath.Join(t.TempDir(), "db.sqlite3")}}
+
+	provider, err := NewProvider(configuration)
+	require.NoError(t, err)
+
+	// The secrets are stored in plaintext before the encryption is enabled.
+	require.NoError(t, provider.SaveTOTPDevice(models.TOTPDevice{ID: "phone", Username: unitTestUser, Secret: "secret1", CreatedAt: time.Unix(1577880000, 0)}))
+	require.NoError(t, provider.SaveU2FDeviceHandle(unitTestUser, []byte("handle"), []byte("key")))
+
+	configuration.EncryptionKey = "a-very-long-encryption-key"
+
+	provider, err = NewProvider(configuration)
+	require.NoError(t, err)
+
+	require.NoError(t, provider.SaveTOTPDevice(models.TOTPDevice{ID: "tablet", Username: unitTestUser, Secret: "secret2", CreatedAt: time.Unix(1577880001, 0)}))
+
+	count, err := provider.(SecretsEncrypter).ChangeEncryptionKey(configuration.EncryptionKey)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	sqlite := provider.(*SQLiteProvider)
+
+	rows, err := sqlite.loadEncryptedValues(sqlite.sqlGetTOTPDeviceSecrets)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+
+	for _, row := range rows {
+		assert.True(t, strings.HasPrefix(row.value, encryptedValuePrefix))
+	}
+
+	devices, err := provider.LoadTOTPDevices(unitTestUser)
+	require.NoError(t, err)
+	require.Len(t, devices, 2)
+	assert.Equal(t, "secret1", devices[0].Secret)
+	assert.Equal(t, "secret2", devices[1].Secret)
+
+	keyHandle, _, err := provider.LoadU2FDeviceHandle(unitTestUser)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("handle"), keyHandle)
+
+	// The secrets can't be downgraded to a schema unaware of the encryption.
+	_, err = sqlite.SchemaDowngrade(storageSchemaEncryptionVersion - 1)
+	assert.EqualError(t, err, fmt.Sprintf("storage schema v%d can't be downgraded to v21 while the secrets are encrypted, they must be decrypted first", storageSchemaCurrentVersion))
+
+	_, err = NewProvider(schema.StorageConfiguration{Local: configuration.Local, EncryptionKey: "another-very-long-encryption-key"})
+	assert.Equal(t, ErrWrongEncryptionKey, err)
+
+	_, err = NewProvider(schema.StorageConfiguration{Local: configuration.Local})
+	assert.Equal(t, ErrNoEncryptionKey, err)
+
+	count, err = provider.(SecretsEncrypter).ChangeEncryptionKey("")
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	provider, err = NewProvider(schema.StorageConfiguration{Local: configuration.Local})
+	require.NoError(t, err)
+
+	devices, err = provider.LoadTOTPDevices(unitTestUser)
+	require.NoError(t, err)
+	require.Len(t, devices, 2)
+	assert.Equal(t, "secret1", devices[0].Secret)
+
+	_, err = provider.(*SQLiteProvider).SchemaDowngrade(storageSchemaEncryptionVersion - 1)
+	assert.NoError(t, err)
+}
+
+func TestShouldRotateEncryptionKeyOnline(t *testing.T) {
+	configuration := schema.StorageConfiguration{
+		Local:         &schema.LocalStorageConfiguration{Path: filepath.Join(t.TempDir(), "db.sqlite3")},
+		EncryptionKey: "a-very-long-encryption-key",
+	}
+
+	provider, err := NewProvider(configuration)
+	require.NoError(t, err)
+
+	require.NoError(t, provider.SaveTOTPDevice(models.TOTPDevice{ID: "phone", Username: unitTestUser, Secret: "secret1", CreatedAt: time.Unix(1577880000, 0)}))
+	require.NoError(t, provider.SaveTOTPDevice(models.TOTPDevice{ID: "tablet", Username: unitTestUser, Secret: "secret2", CreatedAt: time.Unix(1577880001, 0)}))
+	require.NoError(t, provider.SaveU2FDeviceHandle(unitTestUser, []byte("handle"), []byte("key")))
+
+	configuration.PreviousEncryptionKey = configuration.EncryptionKey
+	configuration.EncryptionKey = "another-very-long-encryption-key"
+
+	provider, err = NewProvider(configuration)
+	require.NoError(t, err)
+
+	// The secrets encrypted with the previous key are still decrypted while they're rotated.
+	require.NoError(t, provider.SaveTOTPDevice(models.TOTPDevice{ID: "watch", Username: unitTestUser, Secret: "secret3", CreatedAt: time.Unix(1577880002, 0)}))
+
+	devices, err := provider.LoadTOTPDevices(unitTestUser)
+	require.NoError(t, err)
+	require.Len(t, devices, 3)
+	assert.Equal(t, "secret1", devices[0].Secret)
+	assert.Equal(t, "secret3", devices[2].Secret)
+
+	check, err := provider.(SecretsEncrypter).CheckEncryption()
+	require.NoError(t, err)
+	assert.Equal(t, EncryptionCheck{Total: 4, Previous: 3}, check)
+
+	// The check value is still encrypted with the previous key until the rotation is complete.
+	_, err = NewProvider(schema.StorageConfiguration{Local: configuration.Local, EncryptionKey: configuration.EncryptionKey})
+	assert.Equal(t, ErrWrongEncryptionKey, err)
+
+	count, err := provider.(SecretsEncrypter).RotateEncryptionKey(2)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	check, err = provider.(SecretsEncrypter).CheckEncryption()
+	require.NoError(t, err)
+	assert.Equal(t, EncryptionCheck{Total: 4}, check)
+
+	provider, err = NewProvider(schema.StorageConfiguration{Local: configuration.Local, EncryptionKey: configuration.EncryptionKey})
+	require.NoError(t, err)
+
+	devices, err = provider.LoadTOTPDevices(unitTestUser)
+	require.NoError(t, err)
+	require.Len(t, devices, 3)
+	assert.Equal(t, "secret2", devices[1].Secret)
+
+	keyHandle, _, err := provider.LoadU2FDeviceHandle(unitTestUser)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("handle"), keyHandle)
+
+	_, err = NewProvider(schema.StorageConfiguration{Local: configuration.Local, EncryptionKey: configuration.PreviousEncryptionKey})
+	assert.Equal(t, ErrWrongEncryptionKey, err)
+}
+
+func TestShouldNotRotateSecretsChangedWhileRotated(t *testing.T) {
+	configuration := schema.StorageConfiguration{
+		Local:         &schema.LocalStorageConfiguration{Path: filepath.Join(t.TempDir(), "db.sqlite3")},
+		EncryptionKey: "a-very-long-encryption-key",
+	}
+
+	provider, err := NewProvider(configuration)
+	require.NoError(t, err)
+
+	require.NoError(t, provider.SaveTOTPDevice(models.TOTPDevice{ID: "phone", Username: unitTestUser, Secret: "secret1", CreatedAt: time.Unix(1577880000, 0)}))
+
+	provider, err = NewProvider(schema.StorageConfiguration{
+		Local:                 configuration.Local,
+		EncryptionKey:         "another-very-long-encryption-key",
+		PreviousEncryptionKey: configuration.EncryptionKey,
+	})
+	require.NoError(t, err)
+
+	sqlite := provider.(*SQLiteProvider)
+
+	rows, err := sqlite.loadEncryptedValues(sqlite.sqlGetTOTPDeviceSecrets)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+
+	// The secret is changed after it was loaded by the rotation.
+	encrypted, err := sqlite.encryption.encrypt("secret2")
+	require.NoError(t, err)
+
+	_, err = sqlite.db.Exec(sqlite.sqlUpdateTOTPDeviceSecret, encrypted, unitTestUser, "phone")
+	require.NoError(t, err)
+
+	require.NoError(t, sqlite.rotateValues(sqlite.sqlRotateTOTPDeviceSecret, rows))
+
+	devices, err := provider.LoadTOTPDevices(unitTestUser)
+	require.NoError(t, err)
+	require.Len(t, devices, 1)
+	assert.Equal(t, "secret2", devices[0].Secret)
+}
+
+func TestShouldReportSecretsWhichCantBeDecrypted(t *testing.T) {
+	configuration := schema.StorageConfiguration{
+		Local:         &schema.LocalStorageConfiguration{Path: filepath.Join(t.TempDir(), "db.sqlite3")},
+		EncryptionKey: "a-very-long-encryption-key",
+	}
+
+	provider, err := NewProvider(configuration)
+	require.NoError(t, err)
+
+	require.NoError(t, provider.SaveTOTPDevice(models.TOTPDevice{ID: "phone", Username: unitTestUser, Secret: "secret1", CreatedAt: time.Unix(1577880000, 0)}))
+	require.NoError(t, provider.SaveTOTPDevice(models.TOTPDevice{ID: "tablet", Username: "bob", Secret: "secret2", CreatedAt: time.Unix(1577880001, 0)}))
+
+	other, err := newSecretsEncryption("yet-another-very-long-encryption-key")
+	require.NoError(t, err)
+
+	encrypted, err := other.encrypt("secret2")
+	require.NoError(t, err)
+
+	sqlite := provider.(*SQLiteProvider)
+
+	_, err = sqlite.db.Exec(sqlite.sqlUpdateTOTPDeviceSecret, encrypted, "bob", "tablet")
+	require.NoError(t, err)
+
+	_, err = sqlite.db.Exec(sqlite.sqlUpdateTOTPDeviceSecret, "plaintext", unitTestUser, "phone")
+	require.NoError(t, err)
+
+	check, err := provider.(SecretsEncrypter).CheckEncryption()
+	require.NoError(t, err)
+	assert.Equal(t, 2, check.Total)
+	assert.Equal(t, 1, check.Plaintext)
+	assert.Equal(t, 0, check.Previous)
+	require.Len(t, check.Failures, 1)
+	assert.Equal(t, EncryptionCheckFailure{Kind: "TOTP secret", Username: "bob", Err: ErrWrongEncryptionKey}, check.Failures[0])
+
+	_, err = provider.(SecretsEncrypter).RotateEncryptionKey(10)
+	assert.EqualError(t, err, "Unable to rotate the TOTP secrets: Unable to decrypt the value of user bob: the storage encryption key isn't the one the storage secrets are encrypted with")
+}
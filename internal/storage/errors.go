@@ -6,6 +6,43 @@ var (
 	// ErrNoU2FDeviceHandle error thrown when no U2F device handle has been found in DB.
 	ErrNoU2FDeviceHandle = errors.New("No U2F device handle found")
 
-	// ErrNoTOTPSecret error thrown when no TOTP secret has been found in DB.
-	ErrNoTOTPSecret = errors.New("No TOTP secret registered")
+	// ErrTOTPStepAlreadyUsed error thrown when a TOTP time step is not more recent than the last one used with the device.
+	ErrTOTPStepAlreadyUsed = errors.New("TOTP time step has already been used")
+
+	// ErrNoWebauthnCredential error thrown when no WebAuthn credential has been found in DB.
+	ErrNoWebauthnCredential = errors.New("No WebAuthn credential found")
+
+	// ErrNoLoginState error thrown when a login state nonce is unknown, has expired or has already been consumed.
+	ErrNoLoginState = errors.New("No login state found")
+
+	// ErrNoBackupCode error thrown when a backup code is unknown or has already been used.
+	ErrNoBackupCode = errors.New("No backup code found")
+
+	// ErrNoAdminAPIToken error thrown when an admin API token is unknown or has been revoked.
+	ErrNoAdminAPIToken = errors.New("No admin API token found")
+
+	// ErrNoPushEnrollment error thrown when the enrollment of a user with the push provider has never been checked.
+	ErrNoPushEnrollment = errors.New("No push enrollment found")
+
+	// ErrNoSecondFactorReset error thrown when a second factor reset is unknown or isn't pending anymore.
+	ErrNoSecondFactorReset = errors.New("No second factor reset found")
+
+	// ErrNoAccessGrant error thrown when an access grant is unknown or has been revoked.
+	ErrNoAccessGrant = errors.New("No access grant found")
+
+	// ErrNoBreakGlassActivation error thrown when a break-glass account hasn't been used since its last reset.
+	ErrNoBreakGlassActivation = errors.New("No break-glass activation found")
+
+	// ErrNoOIDCConsent error thrown when a user hasn't remembered their consent to a client or it has expired.
+	ErrNoOIDCConsent = errors.New("No OpenID Connect consent found")
+
+	// ErrNoOIDCClient error thrown when no OpenID Connect client has been registered with the given ID.
+	ErrNoOIDCClient = errors.New("No OpenID Connect client found")
+
+	// ErrNoEncryptionKey error thrown when the secrets stored are encrypted but no storage encryption key is configured.
+	ErrNoEncryptionKey = errors.New("the storage secrets are encrypted but no storage encryption key is configured")
+
+	// ErrWrongEncryptionKey error thrown when the storage encryption key isn't the one the secrets stored are encrypted
+	// with.
+	ErrWrongEncryptionKey = errors.New("the storage encryption key isn't the one the storage secrets are encrypted with")
 )
@@ -0,0 +1,206 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// reColumnName matches the column names which can safely be used in the import statements.
+var reColumnName = regexp.MustCompile(`^[a-zA-Z_]+$`)
+
+// exportTableNames are the tables exported and imported by the SQL providers, the config table is excluded as it only
+// holds the schema version which must be the same on both ends and the value checking the encryption key, which is
+// saved by each provider. The secrets are exported as they're stored, i.e. encrypted when the encryption is enabled.
+var exportTableNames = []string{
+	userPreferencesTableName,
+	identityVerificationTokensTableName,
+	totpDevicesTableName,
+	u2fDeviceHandlesTableName,
+	authenticationLogsTableName,
+	webauthnCredentialsTableName,
+	userEventsTableName,
+	sessionRevocationsTableName,
+	notificationThrottlesTableName,
+	loginStatesTableName,
+	announcementAcknowledgmentsTableName,
+	backupCodesTableName,
+	adminAPITokensTableName,
+	pushEnrollmentsTableName,
+	secondFactorResetsTableName,
+	accessGrantsTableName,
+	breakGlassActivationsTableName,
+	oidcConsentsTableName,
+	oidcClientsTableName,
+}
+
+// Exporter is implemented by the storage providers able to export all of their data and to import it back.
+type Exporter interface {
+	Export() (*Export, error)
+	Import(export *Export) error
+}
+
+// Export is a copy of the data of all the tables of a storage.
+type Export struct {
+	SchemaVersion SchemaVersion           `json:"schema_version"`
+	Tables        map[string]*ExportTable `json:"tables"`
+}
+
+// ExportTable is a copy of the rows of a table.
+type ExportTable struct {
+	Columns []string        `json:"columns"`
+	Rows    [][]interface{} `json:"rows"`
+}
+
+// UnmarshalJSON decodes the table keeping the precision of the integers.
+func (t *ExportTable) UnmarshalJSON(data []byte) error {
+	var table struct {
+		Columns []string        `json:"columns"`
+		Rows    [][]interface{} `json:"rows"`
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&table); err != nil {
+		return err
+	}
+
+	for _, row := range table.Rows {
+		for i, value := range row {
+			number, ok := value.(json.Number)
+			if !ok {
+				continue
+			}
+
+			if integer, err := number.Int64(); err == nil {
+				row[i] = integer
+			} else if row[i], err = number.Float64(); err != nil {
+				return fmt.Errorf("invalid number %s: %w", number, err)
+			}
+		}
+	}
+
+	t.Columns, t.Rows = table.Columns, table.Rows
+
+	return nil
+}
+
+// Export copy the data of all the tables.
+func (p *SQLProvider) Export() (*Export, error) {
+	version, _, err := p.getSchemaBasicDetails()
+	if err != nil {
+		return nil, err
+	}
+
+	export := &Export{SchemaVersion: version, Tables: map[string]*ExportTable{}}
+
+	for _, table := range exportTableNames {
+		if export.Tables[table], err = p.exportTable(table); err != nil {
+			return nil, fmt.Errorf("Unable to export table %s: %v", table, err)
+		}
+	}
+
+	return export, nil
+}
+
+func (p *SQLProvider) exportTable(table string) (*ExportTable, error) {
+	rows, err := p.db.Query(fmt.Sprintf("SELECT * FROM %s", table))
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	export := &ExportTable{Columns: columns, Rows: [][]interface{}{}}
+
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+
+		if err = rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+
+		// Some drivers return the text columns as bytes.
+		for i, value := range values {
+			if b, ok := value.([]byte); ok {
+				values[i] = string(b)
+			}
+		}
+
+		export.Rows = append(export.Rows, values)
+	}
+
+	return export, rows.Err()
+}
+
+// Import replace the data of all the tables by the exported data in a single transaction. The schema of the export
+// must be the same as the one of the database.
+func (p *SQLProvider) Import(export *Export) error {
+	version, _, err := p.getSchemaBasicDetails()
+	if err != nil {
+		return err
+	}
+
+	if export.SchemaVersion != version {
+		return fmt.Errorf("export schema version v%d doesn't match the storage schema version v%d", export.SchemaVersion, version)
+	}
+
+	tx, err := p.begin()
+	if err != nil {
+		return err
+	}
+
+	for _, table := range exportTableNames {
+		if _, err = tx.Exec(fmt.Sprintf("DELETE FROM %s", table)); err != nil {
+			return p.rollback(tx, fmt.Errorf("Unable to import table %s: %v", table, err))
+		}
+
+		data, ok := export.Tables[table]
+		if !ok {
+			continue
+		}
+
+		for _, column := range data.Columns {
+			if !reColumnName.MatchString(column) {
+				return p.rollback(tx, fmt.Errorf("Unable to import table %s: invalid column name '%s'", table, column))
+			}
+		}
+
+		placeholders := make([]string, len(data.Columns))
+
+		for i := range placeholders {
+			placeholders[i] = p.placeholder(i + 1)
+		}
+
+		statement := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(data.Columns, ", "), strings.Join(placeholders, ", "))
+
+		for _, row := range data.Rows {
+			if _, err = tx.Exec(statement, row...); err != nil {
+				return p.rollback(tx, fmt.Errorf("Unable to import table %s: %v", table, err))
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// placeholder returns the placeholder of the n-th argument of a statement in the dialect of the provider.
+func (p *SQLProvider) placeholder(n int) string {
+	if p.name == "postgres" {
+		return fmt.Sprintf("$%d", n)
+	}
+
+	return "?"
+}
@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/models"
+)
+
+func TestShouldExportAndImportStorage(t *testing.T) {
+	source := NewSQLiteProvider(filepath.Join(t.TempDir(), "source.sqlite3"))
+
+	require.NoError(t, source.SaveTOTPDevice(models.TOTPDevice{ID: "phone", Username: unitTestUser, Description: "Phone", Secret: "secret", CreatedAt: time.Unix(1577880000, 0)}))
+	require.NoError(t, source.SaveTOTPDeviceLastUsed(unitTestUser, "phone", 123456789012, time.Unix(1577880001, 0)))
+	require.NoError(t, source.SaveU2FDeviceHandle(unitTestUser, []byte("handle"), []byte("key")))
+	require.NoError(t, source.AppendAuthenticationLog(models.AuthenticationAttempt{Username: unitTestUser, Successful: true, Time: time.Unix(1577880001, 0), Type: models.AuthenticationTypeTOTP}))
+
+	export, err := source.Export()
+	require.NoError(t, err)
+	assert.Equal(t, storageSchemaCurrentVersion, export.SchemaVersion)
+	assert.Len(t, export.Tables, len(exportTableNames))
+
+	// The export is written in the backups as JSON.
+	data, err := json.Marshal(export)
+	require.NoError(t, err)
+
+	decoded := &Export{}
+	require.NoError(t, json.Unmarshal(data, decoded))
+
+	destination := NewSQLiteProvider(filepath.Join(t.TempDir(), "destination.sqlite3"))
+	require.NoError(t, destination.SavePreferred2FAMethod("harry", "u2f"))
+
+	require.NoError(t, destination.Import(decoded))
+
+	devices, err := destination.LoadTOTPDevices(unitTestUser)
+	require.NoError(t, err)
+	assert.Equal(t, []models.TOTPDevice{{ID: "phone", Username: unitTestUser, Description: "Phone", Secret: "secret",
+		CreatedAt: time.Unix(1577880000, 0), LastUsedAt: time.Unix(1577880001, 0)}}, devices)
+
+	assert.Equal(t, ErrTOTPStepAlreadyUsed, destination.SaveTOTPDeviceLastUsed(unitTestUser, "phone", 123456789012, time.Unix(1577880002, 0)))
+
+	keyHandle, publicKey, err := destination.LoadU2FDeviceHandle(unitTestUser)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("handle"), keyHandle)
+	assert.Equal(t, []byte("key"), publicKey)
+
+	attempts, err := destination.LoadLatestAuthenticationLogs(unitTestUser, time.Unix(0, 0))
+	require.NoError(t, err)
+	require.Len(t, attempts, 1)
+	assert.Equal(t, models.AuthenticationTypeTOTP, attempts[0].Type)
+
+	// The existing data is replaced.
+	method, err := destination.LoadPreferred2FAMethod("harry")
+	require.NoError(t, err)
+	assert.Equal(t, "", method)
+}
+
+func TestShouldRefuseToImportExportOfAnotherSchemaVersion(t *testing.T) {
+	provider := NewSQLiteProvider(filepath.Join(t.TempDir(), "db.sqlite3"))
+
+	err := provider.Import(&Export{SchemaVersion: 1})
+	assert.EqualError(t, err, fmt.Sprintf("export schema version v1 doesn't match the storage schema version v%d", storageSchemaCurrentVersion))
+}
+
+func TestShouldRefuseToImportInvalidColumnNames(t *testing.T) {
+	provider := NewSQLiteProvider(filepath.Join(t.TempDir(), "db.sqlite3"))
+
+	err := provider.Import(&Export{SchemaVersion: storageSchemaCurrentVersion, Tables: map[string]*ExportTable{
+		totpDevicesTableName: {Columns: []string{"username) VALUES ('x'); --"}},
+	}})
+	assert.EqualError(t, err, "Unable to import table totp_devices: invalid column name 'username) VALUES ('x'); --'")
+}
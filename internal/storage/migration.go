@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Migrate replaces the data of the target provider by the data of the current provider. The schema of both providers
+// must be the same.
+func Migrate(current, target Provider) (*Export, error) {
+	exporter, ok := current.(Exporter)
+	if !ok {
+		return nil, errors.New("the current storage provider doesn't support exports")
+	}
+
+	importer, ok := target.(Exporter)
+	if !ok {
+		return nil, errors.New("the storage migration target doesn't support imports")
+	}
+
+	export, err := exporter.Export()
+	if err != nil {
+		return nil, fmt.Errorf("Unable to export the current storage: %v", err)
+	}
+
+	if err = importer.Import(export); err != nil {
+		return nil, fmt.Errorf("Unable to import into the storage migration target: %v", err)
+	}
+
+	return export, nil
+}
+
+// VerifyMigration compares the data of the current provider and the target provider and returns the differences
+// between them.
+func VerifyMigration(current, target Provider) (differences []string, err error) {
+	exports := make([]*Export, 2)
+
+	for i, provider := range []Provider{current, target} {
+		exporter, ok := provider.(Exporter)
+		if !ok {
+			return nil, errors.New("the storage providers don't support exports")
+		}
+
+		if exports[i], err = exporter.Export(); err != nil {
+			return nil, err
+		}
+	}
+
+	return CompareExports(exports[0], exports[1]), nil
+}
+
+// CompareExports returns the differences between the data of two exports, regardless of the order of the rows and
+// the columns and of the types the drivers use for the values.
+func CompareExports(current, target *Export) (differences []string) {
+	if current.SchemaVersion != target.SchemaVersion {
+		differences = append(differences, fmt.Sprintf("schema version v%d doesn't match the target schema version v%d",
+			current.SchemaVersion, target.SchemaVersion))
+	}
+
+	for _, table := range exportTableNames {
+		currentRows, targetRows := normalizeExportTable(current.Tables[table]), normalizeExportTable(target.Tables[table])
+
+		missing, unexpected := diffRows(currentRows, targetRows)
+
+		if missing != 0 || unexpected != 0 {
+			differences = append(differences, fmt.Sprintf("table %s has %d rows, the target has %d rows of which %d are missing and %d are unexpected",
+				table, len(currentRows), len(targetRows), missing, unexpected))
+		}
+	}
+
+	return differences
+}
+
+// diffRows counts the rows of the current rows missing from the target rows and the target rows which aren't in the
+// current rows, both must be sorted.
+func diffRows(current, target []string) (missing, unexpected int) {
+	i, j := 0, 0
+
+	for i < len(current) && j < len(target) {
+		switch {
+		case current[i] == target[j]:
+			i++
+			j++
+		case current[i] < target[j]:
+			missing++
+			i++
+		default:
+			unexpected++
+			j++
+		}
+	}
+
+	return missing + len(current) - i, unexpected + len(target) - j
+}
+
+// normalizeExportTable returns the sorted rows of the table, each one being the values of its columns sorted by name.
+func normalizeExportTable(table *ExportTable) (rows []string) {
+	if table == nil {
+		return nil
+	}
+
+	columns := make([]int, len(table.Columns))
+
+	for i := range columns {
+		columns[i] = i
+	}
+
+	sort.Slice(columns, func(i, j int) bool {
+		return strings.ToLower(table.Columns[columns[i]]) < strings.ToLower(table.Columns[columns[j]])
+	})
+
+	for _, row := range table.Rows {
+		values := make([]string, len(columns))
+
+		for i, column := range columns {
+			values[i] = fmt.Sprintf("%s=%s", strings.ToLower(table.Columns[column]), normalizeExportValue(row[column]))
+		}
+
+		rows = append(rows, strings.Join(values, "\x1f"))
+	}
+
+	sort.Strings(rows)
+
+	return rows
+}
+
+// normalizeExportValue formats a value the same way whatever the type the driver uses for it.
+func normalizeExportValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if v {
+			return "1"
+		}
+
+		return "0"
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/models"
+)
+
+func TestShouldMigrateAndDualWriteStorage(t *testing.T) {
+	current := NewSQLiteProvider(filepath.Join(t.TempDir(), "current.sqlite3"))
+	target := NewSQLiteProvider(filepath.Join(t.TempDir(), "target.sqlite3"))
+
+	require.NoError(t, current.SaveTOTPDevice(models.TOTPDevice{ID: "phone", Username: unitTestUser, Secret: "secret", CreatedAt: time.Unix(1577880000, 0)}))
+	require.NoError(t, current.AppendAuthenticationLog(models.AuthenticationAttempt{Username: unitTestUser, Successful: true, Time: time.Unix(1577880001, 0)}))
+
+	differences, err := VerifyMigration(current, target)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"table totp_devices has 1 rows, the target has 0 rows of which 1 are missing and 0 are unexpected",
+		"table authentication_logs has 1 rows, the target has 0 rows of which 1 are missing and 0 are unexpected",
+	}, differences)
+
+	_, err = Migrate(current, target)
+	require.NoError(t, err)
+
+	provider := NewDualWriteProvider(current, target)
+
+	require.NoError(t, provider.SavePreferred2FAMethod(unitTestUser, "webauthn"))
+	require.NoError(t, provider.AppendUserEvent(models.UserEvent{Username: unitTestUser, Type: "password_changed", Successful: true, Time: time.Unix(1577880002, 0)}))
+
+	method, err := target.LoadPreferred2FAMethod(unitTestUser)
+	require.NoError(t, err)
+	assert.Equal(t, "webauthn", method)
+
+	differences, err = VerifyMigration(current, target)
+	require.NoError(t, err)
+	assert.Len(t, differences, 0)
+}
+
+func TestDualWriteProviderShouldReadFromCurrentProvider(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	current, target := NewMockProvider(ctrl), NewMockProvider(ctrl)
+	current.EXPECT().LoadTOTPDevices(unitTestUser).Return([]models.TOTPDevice{{ID: "phone", Username: unitTestUser, Secret: "secret"}}, nil)
+
+	devices, err := NewDualWriteProvider(current, target).LoadTOTPDevices(unitTestUser)
+	require.NoError(t, err)
+	require.Len(t, devices, 1)
+	assert.Equal(t, "secret", devices[0].Secret)
+}
+
+func TestDualWriteProviderShouldNotFailWhenTargetWriteFails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	current, target := NewMockProvider(ctrl), NewMockProvider(ctrl)
+	current.EXPECT().DeleteTOTPDevice(unitTestUser, "phone").Return(nil)
+	target.EXPECT().DeleteTOTPDevice(unitTestUser, "phone").Return(errors.New("connection refused"))
+
+	assert.NoError(t, NewDualWriteProvider(current, target).DeleteTOTPDevice(unitTestUser, "phone"))
+}
+
+func TestDualWriteProviderShouldNotWriteToTargetWhenCurrentWriteFails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	current, target := NewMockProvider(ctrl), NewMockProvider(ctrl)
+	current.EXPECT().DeleteTOTPDevice(unitTestUser, "phone").Return(errors.New("connection refused"))
+
+	assert.EqualError(t, NewDualWriteProvider(current, target).DeleteTOTPDevice(unitTestUser, "phone"), "connection refused")
+}
+
+func TestDualWriteProviderShouldConsumeLoginStateSavedBeforeMigration(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Unix(1577880000, 0)
+
+	current, target := NewMockProvider(ctrl), NewMockProvider(ctrl)
+	current.EXPECT().ConsumeLoginState("nonce", now).Return(nil)
+	target.EXPECT().ConsumeLoginState("nonce", now).Return(ErrNoLoginState)
+
+	assert.NoError(t, NewDualWriteProvider(current, target).ConsumeLoginState("nonce", now))
+
+	current.EXPECT().ConsumeLoginState("nonce", now).Return(ErrNoLoginState)
+
+	assert.Equal(t, ErrNoLoginState, NewDualWriteProvider(current, target).ConsumeLoginState("nonce", now))
+}
+
+func TestShouldCompareExportsRegardlessOfTypesAndOrder(t *testing.T) {
+	current := &Export{SchemaVersion: 1, Tables: map[string]*ExportTable{
+		authenticationLogsTableName: {
+			Columns: []string{"username", "successful", "time"},
+			Rows:    [][]interface{}{{"john", int64(1), int64(10)}, {"harry", int64(0), int64(20)}},
+		},
+		u2fDeviceHandlesTableName: {
+			Columns: []string{"username", "keyHandle", "publicKey"},
+			Rows:    [][]interface{}{{"john", "handle", "key"}},
+		},
+	}}
+
+	target := &Export{SchemaVersion: 1, Tables: map[string]*ExportTable{
+		authenticationLogsTableName: {
+			Columns: []string{"time", "username", "successful"},
+			Rows:    [][]interface{}{{int64(20), "harry", false}, {int64(10), "john", true}},
+		},
+		u2fDeviceHandlesTableName: {
+			Columns: []string{"username", "keyhandle", "publickey"},
+			Rows:    [][]interface{}{{"john", []byte("handle"), []byte("key")}, {"harry", "handle", "key"}},
+		},
+	}}
+
+	assert.Equal(t, []string{
+		"table u2f_devices has 1 rows, the target has 2 rows of which 0 are missing and 1 are unexpected",
+	}, CompareExports(current, target))
+}
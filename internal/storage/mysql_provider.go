@@ -3,6 +3,7 @@ package storage
 import (
 	"database/sql"
 	"fmt"
+	"io"
 
 	_ "github.com/go-sql-driver/mysql" // Load the MySQL Driver used in the connection string.
 
@@ -16,38 +17,163 @@ type MySQLProvider struct {
 
 // NewMySQLProvider a MySQL provider.
 func NewMySQLProvider(configuration schema.MySQLStorageConfiguration) *MySQLProvider {
-	provider := MySQLProvider{
+	return newMySQLProvider(configuration, nil)
+}
+
+// newMySQLProvider constructs the provider, when dryRun isn't nil the statements changing the database are written
+// to it instead of being executed.
+func newMySQLProvider(configuration schema.MySQLStorageConfiguration, dryRun io.Writer) *MySQLProvider {
+	provider := newMySQLProviderStatements(dryRun)
+
+	db, err := sql.Open("mysql", mySQLConnectionString(configuration, configuration.Host, configuration.Port))
+	if err != nil {
+		provider.log.Fatalf("Unable to connect to SQL database: %v", err)
+	}
+
+	configureSQLPool(db, configuration.Pool)
+
+	if err := provider.initialize(db); err != nil {
+		provider.log.Fatalf("Unable to initialize SQL database: %v", err)
+	}
+
+	for _, replica := range configuration.ReadReplicas {
+		host, port := splitReadReplicaAddress(replica, configuration.Port)
+
+		db, err := sql.Open("mysql", mySQLConnectionString(configuration, host, port))
+		if err != nil {
+			provider.log.Fatalf("Unable to connect to SQL read replica %s: %v", replica, err)
+		}
+
+		configureSQLPool(db, configuration.Pool)
+
+		provider.replicas = append(provider.replicas, db)
+	}
+
+	return provider
+}
+
+// newMySQLProviderStatements constructs the provider with the statements of its dialect, it isn't connected to the database.
+func newMySQLProviderStatements(dryRun io.Writer) *MySQLProvider {
+	return &MySQLProvider{
 		SQLProvider{
-			name: "mysql",
+			name:   "mysql",
+			dryRun: dryRun,
 
-			sqlUpgradesCreateTableStatements: sqlUpgradeCreateTableStatements,
+			sqlUpgradesCreateTableStatements: sqlUpgradeCreateTableStatementsWithDialect(sqlUpgradeCreateTableStatementsMySQL),
+			sqlUpgradesStatements:            sqlUpgradesStatementsMySQL,
+			sqlDowngradesStatements:          sqlDowngradesStatements,
 
 			sqlGetPreferencesByUsername:     fmt.Sprintf("SELECT second_factor_method FROM %s WHERE username=?", userPreferencesTableName),
 			sqlUpsertSecondFactorPreference: fmt.Sprintf("REPLACE INTO %s (username, second_factor_method) VALUES (?, ?)", userPreferencesTableName),
-
-			sqlTestIdentityVerificationTokenExistence: fmt.Sprintf("SELECT EXISTS (SELECT * FROM %s WHERE token=?)", identityVerificationTokensTableName),
-			sqlInsertIdentityVerificationToken:        fmt.Sprintf("INSERT INTO %s (token) VALUES (?)", identityVerificationTokensTableName),
-			sqlDeleteIdentityVerificationToken:        fmt.Sprintf("DELETE FROM %s WHERE token=?", identityVerificationTokensTableName),
-
-			sqlGetTOTPSecretByUsername: fmt.Sprintf("SELECT secret FROM %s WHERE username=?", totpSecretsTableName),
-			sqlUpsertTOTPSecret:        fmt.Sprintf("REPLACE INTO %s (username, secret) VALUES (?, ?)", totpSecretsTableName),
-			sqlDeleteTOTPSecret:        fmt.Sprintf("DELETE FROM %s WHERE username=?", totpSecretsTableName),
+			sqlDeletePreferencesByUsername:  fmt.Sprintf("DELETE FROM %s WHERE username=?", userPreferencesTableName),
+
+			sqlTestIdentityVerificationTokenExistence:  fmt.Sprintf("SELECT EXISTS (SELECT * FROM %s WHERE token=?)", identityVerificationTokensTableName),
+			sqlInsertIdentityVerificationToken:         fmt.Sprintf("INSERT INTO %s (token, expires_at) VALUES (?, ?)", identityVerificationTokensTableName),
+			sqlDeleteIdentityVerificationToken:         fmt.Sprintf("DELETE FROM %s WHERE token=?", identityVerificationTokensTableName),
+			sqlDeleteExpiredIdentityVerificationTokens: fmt.Sprintf("DELETE FROM %s WHERE expires_at IS NULL OR expires_at<?", identityVerificationTokensTableName),
+
+			sqlInsertLoginState:         fmt.Sprintf("INSERT INTO %s (nonce, expires_at) VALUES (?, ?)", loginStatesTableName),
+			sqlDeleteLoginState:         fmt.Sprintf("DELETE FROM %s WHERE nonce=? AND expires_at>=?", loginStatesTableName),
+			sqlDeleteExpiredLoginStates: fmt.Sprintf("DELETE FROM %s WHERE expires_at<?", loginStatesTableName),
+
+			sqlInsertTOTPDevice:            fmt.Sprintf("INSERT INTO %s (username, device_id, description, secret, created_at) VALUES (?, ?, ?, ?, ?)", totpDevicesTableName),
+			sqlGetTOTPDevicesByUsername:    fmt.Sprintf("SELECT device_id, username, COALESCE(description, ''), secret, created_at, last_used_at FROM %s WHERE username=? ORDER BY COALESCE(created_at, 0), device_id", totpDevicesTableName),
+			sqlUpdateTOTPDeviceDescription: fmt.Sprintf("UPDATE %s SET description=? WHERE username=? AND device_id=?", totpDevicesTableName),
+			sqlDeleteTOTPDevice:            fmt.Sprintf("DELETE FROM %s WHERE username=? AND device_id=?", totpDevicesTableName),
+			sqlUpdateTOTPDeviceLastUsed:    fmt.Sprintf("UPDATE %s SET last_used_step=?, last_used_at=? WHERE username=? AND device_id=? AND (last_used_step IS NULL OR last_used_step<?)", totpDevicesTableName),
+			sqlGetTOTPDeviceSecrets:        fmt.Sprintf("SELECT secret, username, device_id FROM %s", totpDevicesTableName),
+			sqlUpdateTOTPDeviceSecret:      fmt.Sprintf("UPDATE %s SET secret=? WHERE username=? AND device_id=?", totpDevicesTableName),
+			sqlRotateTOTPDeviceSecret:      fmt.Sprintf("UPDATE %s SET secret=? WHERE username=? AND device_id=? AND secret=?", totpDevicesTableName),
 
 			sqlGetU2FDeviceHandleByUsername: fmt.Sprintf("SELECT keyHandle, publicKey FROM %s WHERE username=?", u2fDeviceHandlesTableName),
 			sqlUpsertU2FDeviceHandle:        fmt.Sprintf("REPLACE INTO %s (username, keyHandle, publicKey) VALUES (?, ?, ?)", u2fDeviceHandlesTableName),
-
-			sqlInsertAuthenticationLog:     fmt.Sprintf("INSERT INTO %s (username, successful, time) VALUES (?, ?, ?)", authenticationLogsTableName),
-			sqlGetLatestAuthenticationLogs: fmt.Sprintf("SELECT successful, time FROM %s WHERE time>? AND username=? ORDER BY time DESC", authenticationLogsTableName),
+			sqlDeleteU2FDeviceHandle:        fmt.Sprintf("DELETE FROM %s WHERE username=?", u2fDeviceHandlesTableName),
+			sqlGetU2FDeviceKeyHandles:       fmt.Sprintf("SELECT keyHandle, username FROM %s", u2fDeviceHandlesTableName),
+			sqlUpdateU2FDeviceKeyHandle:     fmt.Sprintf("UPDATE %s SET keyHandle=? WHERE username=?", u2fDeviceHandlesTableName),
+			sqlRotateU2FDeviceKeyHandle:     fmt.Sprintf("UPDATE %s SET keyHandle=? WHERE username=? AND keyHandle=?", u2fDeviceHandlesTableName),
+
+			sqlInsertWebauthnCredential:          fmt.Sprintf("INSERT INTO %s (credential_id, username, credential_type, description, public_key, sign_count, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)", webauthnCredentialsTableName),
+			sqlGetWebauthnCredentialByID:         fmt.Sprintf("SELECT credential_id, username, credential_type, COALESCE(description, ''), public_key, sign_count, created_at FROM %s WHERE credential_id=?", webauthnCredentialsTableName),
+			sqlGetWebauthnCredentialsByUsername:  fmt.Sprintf("SELECT credential_id, username, credential_type, COALESCE(description, ''), public_key, sign_count, created_at FROM %s WHERE username=? ORDER BY created_at", webauthnCredentialsTableName),
+			sqlUpdateWebauthnCredentialSignCount: fmt.Sprintf("UPDATE %s SET sign_count=? WHERE credential_id=?", webauthnCredentialsTableName),
+			sqlDeleteWebauthnCredential:          fmt.Sprintf("DELETE FROM %s WHERE username=? AND credential_id=?", webauthnCredentialsTableName),
+
+			sqlInsertAuthenticationLog:        fmt.Sprintf("INSERT INTO %s (username, successful, time, remote_ip, user_agent, auth_type) VALUES (?, ?, ?, ?, ?, ?)", authenticationLogsTableName),
+			sqlGetLatestAuthenticationLogs:    fmt.Sprintf("SELECT successful, time, remote_ip, user_agent, auth_type FROM %s WHERE time>? AND username=? ORDER BY time DESC", authenticationLogsTableName),
+			sqlDeleteFailedAuthenticationLogs: fmt.Sprintf("DELETE FROM %s WHERE username=? AND successful=?", authenticationLogsTableName),
+			sqlDeleteAuthenticationLogsBefore: fmt.Sprintf("DELETE FROM %s WHERE time<?", authenticationLogsTableName),
+
+			sqlInsertUserEvent: fmt.Sprintf("INSERT INTO %s (username, event, successful, time, remote_ip, user_agent) VALUES (?, ?, ?, ?, ?, ?)", userEventsTableName),
+			sqlGetUserEvents:   fmt.Sprintf(sqlUserEventsQuery, "?", "?", "?", "?", "?"),
+
+			sqlGetNotificationThrottle:         fmt.Sprintf("SELECT last_sent_at, pending, pending_since FROM %s WHERE username=? AND kind=?", notificationThrottlesTableName),
+			sqlUpsertNotificationThrottle:      fmt.Sprintf("REPLACE INTO %s (username, kind, last_sent_at, pending, pending_since) VALUES (?, ?, ?, ?, ?)", notificationThrottlesTableName),
+			sqlGetPendingNotificationThrottles: fmt.Sprintf("SELECT username, kind, last_sent_at, pending, pending_since FROM %s WHERE pending>0", notificationThrottlesTableName),
+
+			sqlUpsertAnnouncementAcknowledgment: fmt.Sprintf("REPLACE INTO %s (username, announcement_id, acknowledged_at) VALUES (?, ?, ?)", announcementAcknowledgmentsTableName),
+			sqlGetAnnouncementAcknowledgment:    fmt.Sprintf("SELECT acknowledged_at FROM %s WHERE username=? AND announcement_id=?", announcementAcknowledgmentsTableName),
+
+			sqlDeleteBackupCodes:      fmt.Sprintf("DELETE FROM %s WHERE username=?", backupCodesTableName),
+			sqlInsertBackupCode:       fmt.Sprintf("INSERT INTO %s (username, code_hash, created_at) VALUES (?, ?, ?)", backupCodesTableName),
+			sqlDeleteBackupCode:       fmt.Sprintf("DELETE FROM %s WHERE username=? AND code_hash=?", backupCodesTableName),
+			sqlCountBackupCodes:       fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE username=?", backupCodesTableName),
+			sqlSelectBackupCodeHashes: fmt.Sprintf("SELECT code_hash FROM %s WHERE username=?", backupCodesTableName),
+
+			sqlInsertAdminAPIToken:         fmt.Sprintf("INSERT INTO %s (id, description, token_hash, scopes, created_at, expires_at) VALUES (?, ?, ?, ?, ?, ?)", adminAPITokensTableName),
+			sqlGetAdminAPITokenByHash:      fmt.Sprintf("SELECT id, description, token_hash, scopes, created_at, expires_at, last_used_at FROM %s WHERE token_hash=?", adminAPITokensTableName),
+			sqlGetAdminAPITokens:           fmt.Sprintf("SELECT id, description, token_hash, scopes, created_at, expires_at, last_used_at FROM %s ORDER BY created_at", adminAPITokensTableName),
+			sqlUpdateAdminAPITokenLastUsed: fmt.Sprintf("UPDATE %s SET last_used_at=? WHERE id=?", adminAPITokensTableName),
+			sqlDeleteAdminAPIToken:         fmt.Sprintf("DELETE FROM %s WHERE id=?", adminAPITokensTableName),
+
+			sqlUpsertPushEnrollment: fmt.Sprintf("REPLACE INTO %s (username, provider, status, devices, checked_at) VALUES (?, ?, ?, ?, ?)", pushEnrollmentsTableName),
+			sqlGetPushEnrollment:    fmt.Sprintf("SELECT provider, status, devices, checked_at FROM %s WHERE username=?", pushEnrollmentsTableName),
+
+			sqlInsertSecondFactorReset:         fmt.Sprintf("INSERT INTO %s (id, username, requested_by, reason, approval, approval_token_hash, status, requested_at, expires_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)", secondFactorResetsTableName),
+			sqlGetSecondFactorReset:            fmt.Sprintf("SELECT id, username, requested_by, reason, approval, approval_token_hash, status, decided_by, requested_at, expires_at, decided_at FROM %s WHERE id=?", secondFactorResetsTableName),
+			sqlGetPendingSecondFactorResets:    fmt.Sprintf("SELECT id, username, requested_by, reason, approval, approval_token_hash, status, decided_by, requested_at, expires_at, decided_at FROM %s WHERE status=? AND expires_at>? ORDER BY requested_at", secondFactorResetsTableName),
+			sqlUpdateSecondFactorResetDecision: fmt.Sprintf("UPDATE %s SET status=?, decided_by=?, decided_at=? WHERE id=? AND username=? AND status=? AND expires_at>?", secondFactorResetsTableName),
+			sqlDeleteSecondFactorData:          sqlDeleteSecondFactorDataStatements("?"),
+
+			sqlInsertAccessGrant:         fmt.Sprintf("INSERT INTO %s (id, username, target_url, description, created_at, expires_at) VALUES (?, ?, ?, ?, ?, ?)", accessGrantsTableName),
+			sqlGetAccessGrant:            fmt.Sprintf("SELECT id, username, target_url, description, created_at, expires_at FROM %s WHERE id=?", accessGrantsTableName),
+			sqlGetAccessGrants:           fmt.Sprintf("SELECT id, username, target_url, description, created_at, expires_at FROM %s WHERE expires_at>? ORDER BY created_at", accessGrantsTableName),
+			sqlGetUserAccessGrants:       fmt.Sprintf("SELECT id, username, target_url, description, created_at, expires_at FROM %s WHERE username=? AND expires_at>? ORDER BY created_at", accessGrantsTableName),
+			sqlDeleteAccessGrant:         fmt.Sprintf("DELETE FROM %s WHERE id=?", accessGrantsTableName),
+			sqlDeleteExpiredAccessGrants: fmt.Sprintf("DELETE FROM %s WHERE expires_at<?", accessGrantsTableName),
+
+			sqlInsertBreakGlassActivation: fmt.Sprintf("INSERT INTO %s (username, activated_at) VALUES (?, ?)", breakGlassActivationsTableName),
+			sqlGetBreakGlassActivation:    fmt.Sprintf("SELECT username, activated_at FROM %s WHERE username=?", breakGlassActivationsTableName),
+			sqlGetBreakGlassActivations:   fmt.Sprintf("SELECT username, activated_at FROM %s ORDER BY activated_at", breakGlassActivationsTableName),
+			sqlDeleteBreakGlassActivation: fmt.Sprintf("DELETE FROM %s WHERE username=?", breakGlassActivationsTableName),
+
+			sqlUpsertOIDCConsent:         fmt.Sprintf("REPLACE INTO %s (username, client_id, scopes, audience, created_at, expires_at) VALUES (?, ?, ?, ?, ?, ?)", oidcConsentsTableName),
+			sqlGetOIDCConsent:            fmt.Sprintf("SELECT username, client_id, scopes, audience, created_at, expires_at FROM %s WHERE username=? AND client_id=? AND expires_at>?", oidcConsentsTableName),
+			sqlGetUserOIDCConsents:       fmt.Sprintf("SELECT username, client_id, scopes, audience, created_at, expires_at FROM %s WHERE username=? AND expires_at>? ORDER BY created_at", oidcConsentsTableName),
+			sqlDeleteOIDCConsent:         fmt.Sprintf("DELETE FROM %s WHERE username=? AND client_id=?", oidcConsentsTableName),
+			sqlDeleteExpiredOIDCConsents: fmt.Sprintf("DELETE FROM %s WHERE expires_at<?", oidcConsentsTableName),
+
+			sqlInsertOIDCClient: fmt.Sprintf("INSERT INTO %s (id, description, secret_hash, policy, redirect_uris, grant_types, response_types, scopes, post_logout_redirect_uris, backchannel_logout_uri, frontchannel_logout_uri, registered_by, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", oidcClientsTableName),
+			sqlGetOIDCClients:   fmt.Sprintf("SELECT id, description, secret_hash, policy, redirect_uris, grant_types, response_types, scopes, post_logout_redirect_uris, backchannel_logout_uri, frontchannel_logout_uri, registered_by, created_at FROM %s ORDER BY created_at", oidcClientsTableName),
+			sqlDeleteOIDCClient: fmt.Sprintf("DELETE FROM %s WHERE id=?", oidcClientsTableName),
+
+			sqlGetUsernames: sqlSelectUsernamesStatement(),
+			sqlGetLastLogin: fmt.Sprintf("SELECT time FROM %s WHERE username=? AND successful=? ORDER BY time DESC LIMIT 1", authenticationLogsTableName),
+
+			sqlDeleteUserData:                 sqlDeleteUserDataStatements("?"),
+			sqlUpsertSessionRevocation:        fmt.Sprintf("REPLACE INTO %s (username, revoked_at) VALUES (?, ?)", sessionRevocationsTableName),
+			sqlGetSessionRevocation:           fmt.Sprintf("SELECT revoked_at FROM %s WHERE username=?", sessionRevocationsTableName),
+			sqlDeleteSessionRevocationsBefore: fmt.Sprintf("DELETE FROM %s WHERE revoked_at<?", sessionRevocationsTableName),
 
 			sqlGetExistingTables: "SELECT table_name FROM information_schema.tables WHERE table_type='BASE TABLE' AND table_schema=database()",
 
-			sqlConfigSetValue: fmt.Sprintf("REPLACE INTO %s (category, key_name, value) VALUES (?, ?, ?)", configTableName),
-			sqlConfigGetValue: fmt.Sprintf("SELECT value FROM %s WHERE category=? AND key_name=?", configTableName),
+			sqlConfigSetValue:    fmt.Sprintf("REPLACE INTO %s (category, key_name, value) VALUES (?, ?, ?)", configTableName),
+			sqlConfigGetValue:    fmt.Sprintf("SELECT value FROM %s WHERE category=? AND key_name=?", configTableName),
+			sqlConfigDeleteValue: fmt.Sprintf("DELETE FROM %s WHERE category=? AND key_name=?", configTableName),
 		},
 	}
+}
 
-	provider.sqlUpgradesCreateTableStatements[SchemaVersion(1)][authenticationLogsTableName] = "CREATE TABLE %s (username VARCHAR(100), successful BOOL, time INTEGER, INDEX usr_time_idx (username, time))"
-
+func mySQLConnectionString(configuration schema.MySQLStorageConfiguration, host string, port int) string {
 	connectionString := configuration.Username
 
 	if configuration.Password != "" {
@@ -58,9 +184,9 @@ func NewMySQLProvider(configuration schema.MySQLStorageConfiguration) *MySQLProv
 		connectionString += "@"
 	}
 
-	address := configuration.Host
-	if configuration.Port > 0 {
-		address += fmt.Sprintf(":%d", configuration.Port)
+	address := host
+	if port > 0 {
+		address += fmt.Sprintf(":%d", port)
 	}
 
 	connectionString += fmt.Sprintf("tcp(%s)", address)
@@ -68,14 +194,5 @@ func NewMySQLProvider(configuration schema.MySQLStorageConfiguration) *MySQLProv
 		connectionString += fmt.Sprintf("/%s", configuration.Database)
 	}
 
-	db, err := sql.Open("mysql", connectionString)
-	if err != nil {
-		provider.log.Fatalf("Unable to connect to SQL database: %v", err)
-	}
-
-	if err := provider.initialize(db); err != nil {
-		provider.log.Fatalf("Unable to initialize SQL database: %v", err)
-	}
-
-	return &provider
+	return connectionString
 }
@@ -3,6 +3,8 @@ package storage
 import (
 	"database/sql"
 	"fmt"
+	"io"
+	"strconv"
 	"strings"
 
 	_ "github.com/jackc/pgx/v4/stdlib" // Load the PostgreSQL Driver used in the connection string.
@@ -17,37 +19,175 @@ type PostgreSQLProvider struct {
 
 // NewPostgreSQLProvider a PostgreSQL provider.
 func NewPostgreSQLProvider(configuration schema.PostgreSQLStorageConfiguration) *PostgreSQLProvider {
-	provider := PostgreSQLProvider{
+	return newPostgreSQLProvider(configuration, nil)
+}
+
+// newPostgreSQLProvider constructs the provider, when dryRun isn't nil the statements changing the database are written
+// to it instead of being executed.
+func newPostgreSQLProvider(configuration schema.PostgreSQLStorageConfiguration, dryRun io.Writer) *PostgreSQLProvider {
+	provider := newPostgreSQLProviderStatements(dryRun)
+
+	// The primary database is the first of the host and the fallback hosts accepting writes.
+	hosts, ports := []string{configuration.Host}, []int{configuration.Port}
+
+	for _, fallback := range configuration.FallbackHosts {
+		host, port := splitReadReplicaAddress(fallback, configuration.Port)
+
+		hosts, ports = append(hosts, host), append(ports, port)
+	}
+
+	db, err := sql.Open("pgx", postgreSQLConnectionString(configuration, hosts, ports))
+	if err != nil {
+		provider.log.Fatalf("Unable to connect to SQL database: %v", err)
+	}
+
+	configureSQLPool(db, configuration.Pool)
+
+	if err := provider.initialize(db); err != nil {
+		provider.log.Fatalf("Unable to initialize SQL database: %v", err)
+	}
+
+	for _, replica := range configuration.ReadReplicas {
+		host, port := splitReadReplicaAddress(replica, configuration.Port)
+
+		db, err := sql.Open("pgx", postgreSQLConnectionString(configuration, []string{host}, []int{port}))
+		if err != nil {
+			provider.log.Fatalf("Unable to connect to SQL read replica %s: %v", replica, err)
+		}
+
+		configureSQLPool(db, configuration.Pool)
+
+		provider.replicas = append(provider.replicas, db)
+	}
+
+	return provider
+}
+
+// newPostgreSQLProviderStatements constructs the provider with the statements of its dialect, it isn't connected to the database.
+func newPostgreSQLProviderStatements(dryRun io.Writer) *PostgreSQLProvider {
+	return &PostgreSQLProvider{
 		SQLProvider{
-			name: "postgres",
+			name:   "postgres",
+			dryRun: dryRun,
 
-			sqlUpgradesCreateTableStatements:        sqlUpgradeCreateTableStatements,
+			sqlUpgradesCreateTableStatements:        sqlUpgradeCreateTableStatementsWithDialect(nil),
+			sqlUpgradesStatements:                   sqlUpgradesStatementsPostgreSQL,
 			sqlUpgradesCreateTableIndexesStatements: sqlUpgradesCreateTableIndexesStatements,
+			sqlDowngradesStatements:                 sqlDowngradesStatements,
 
 			sqlGetPreferencesByUsername:     fmt.Sprintf("SELECT second_factor_method FROM %s WHERE username=$1", userPreferencesTableName),
 			sqlUpsertSecondFactorPreference: fmt.Sprintf("INSERT INTO %s (username, second_factor_method) VALUES ($1, $2) ON CONFLICT (username) DO UPDATE SET second_factor_method=$2", userPreferencesTableName),
+			sqlDeletePreferencesByUsername:  fmt.Sprintf("DELETE FROM %s WHERE username=$1", userPreferencesTableName),
+
+			sqlTestIdentityVerificationTokenExistence:  fmt.Sprintf("SELECT EXISTS (SELECT * FROM %s WHERE token=$1)", identityVerificationTokensTableName),
+			sqlInsertIdentityVerificationToken:         fmt.Sprintf("INSERT INTO %s (token, expires_at) VALUES ($1, $2)", identityVerificationTokensTableName),
+			sqlDeleteIdentityVerificationToken:         fmt.Sprintf("DELETE FROM %s WHERE token=$1", identityVerificationTokensTableName),
+			sqlDeleteExpiredIdentityVerificationTokens: fmt.Sprintf("DELETE FROM %s WHERE expires_at IS NULL OR expires_at<$1", identityVerificationTokensTableName),
 
-			sqlTestIdentityVerificationTokenExistence: fmt.Sprintf("SELECT EXISTS (SELECT * FROM %s WHERE token=$1)", identityVerificationTokensTableName),
-			sqlInsertIdentityVerificationToken:        fmt.Sprintf("INSERT INTO %s (token) VALUES ($1)", identityVerificationTokensTableName),
-			sqlDeleteIdentityVerificationToken:        fmt.Sprintf("DELETE FROM %s WHERE token=$1", identityVerificationTokensTableName),
+			sqlInsertLoginState:         fmt.Sprintf("INSERT INTO %s (nonce, expires_at) VALUES ($1, $2)", loginStatesTableName),
+			sqlDeleteLoginState:         fmt.Sprintf("DELETE FROM %s WHERE nonce=$1 AND expires_at>=$2", loginStatesTableName),
+			sqlDeleteExpiredLoginStates: fmt.Sprintf("DELETE FROM %s WHERE expires_at<$1", loginStatesTableName),
 
-			sqlGetTOTPSecretByUsername: fmt.Sprintf("SELECT secret FROM %s WHERE username=$1", totpSecretsTableName),
-			sqlUpsertTOTPSecret:        fmt.Sprintf("INSERT INTO %s (username, secret) VALUES ($1, $2) ON CONFLICT (username) DO UPDATE SET secret=$2", totpSecretsTableName),
-			sqlDeleteTOTPSecret:        fmt.Sprintf("DELETE FROM %s WHERE username=$1", totpSecretsTableName),
+			sqlInsertTOTPDevice:            fmt.Sprintf("INSERT INTO %s (username, device_id, description, secret, created_at) VALUES ($1, $2, $3, $4, $5)", totpDevicesTableName),
+			sqlGetTOTPDevicesByUsername:    fmt.Sprintf("SELECT device_id, username, COALESCE(description, ''), secret, created_at, last_used_at FROM %s WHERE username=$1 ORDER BY COALESCE(created_at, 0), device_id", totpDevicesTableName),
+			sqlUpdateTOTPDeviceDescription: fmt.Sprintf("UPDATE %s SET description=$1 WHERE username=$2 AND device_id=$3", totpDevicesTableName),
+			sqlDeleteTOTPDevice:            fmt.Sprintf("DELETE FROM %s WHERE username=$1 AND device_id=$2", totpDevicesTableName),
+			sqlUpdateTOTPDeviceLastUsed:    fmt.Sprintf("UPDATE %s SET last_used_step=$1, last_used_at=$2 WHERE username=$3 AND device_id=$4 AND (last_used_step IS NULL OR last_used_step<$5)", totpDevicesTableName),
+			sqlGetTOTPDeviceSecrets:        fmt.Sprintf("SELECT secret, username, device_id FROM %s", totpDevicesTableName),
+			sqlUpdateTOTPDeviceSecret:      fmt.Sprintf("UPDATE %s SET secret=$1 WHERE username=$2 AND device_id=$3", totpDevicesTableName),
+			sqlRotateTOTPDeviceSecret:      fmt.Sprintf("UPDATE %s SET secret=$1 WHERE username=$2 AND device_id=$3 AND secret=$4", totpDevicesTableName),
 
 			sqlGetU2FDeviceHandleByUsername: fmt.Sprintf("SELECT keyHandle, publicKey FROM %s WHERE username=$1", u2fDeviceHandlesTableName),
 			sqlUpsertU2FDeviceHandle:        fmt.Sprintf("INSERT INTO %s (username, keyHandle, publicKey) VALUES ($1, $2, $3) ON CONFLICT (username) DO UPDATE SET keyHandle=$2, publicKey=$3", u2fDeviceHandlesTableName),
+			sqlDeleteU2FDeviceHandle:        fmt.Sprintf("DELETE FROM %s WHERE username=$1", u2fDeviceHandlesTableName),
+			sqlGetU2FDeviceKeyHandles:       fmt.Sprintf("SELECT keyHandle, username FROM %s", u2fDeviceHandlesTableName),
+			sqlUpdateU2FDeviceKeyHandle:     fmt.Sprintf("UPDATE %s SET keyHandle=$1 WHERE username=$2", u2fDeviceHandlesTableName),
+			sqlRotateU2FDeviceKeyHandle:     fmt.Sprintf("UPDATE %s SET keyHandle=$1 WHERE username=$2 AND keyHandle=$3", u2fDeviceHandlesTableName),
+
+			sqlInsertWebauthnCredential:          fmt.Sprintf("INSERT INTO %s (credential_id, username, credential_type, description, public_key, sign_count, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7)", webauthnCredentialsTableName),
+			sqlGetWebauthnCredentialByID:         fmt.Sprintf("SELECT credential_id, username, credential_type, COALESCE(description, ''), public_key, sign_count, created_at FROM %s WHERE credential_id=$1", webauthnCredentialsTableName),
+			sqlGetWebauthnCredentialsByUsername:  fmt.Sprintf("SELECT credential_id, username, credential_type, COALESCE(description, ''), public_key, sign_count, created_at FROM %s WHERE username=$1 ORDER BY created_at", webauthnCredentialsTableName),
+			sqlUpdateWebauthnCredentialSignCount: fmt.Sprintf("UPDATE %s SET sign_count=$1 WHERE credential_id=$2", webauthnCredentialsTableName),
+			sqlDeleteWebauthnCredential:          fmt.Sprintf("DELETE FROM %s WHERE username=$1 AND credential_id=$2", webauthnCredentialsTableName),
+
+			sqlInsertAuthenticationLog:        fmt.Sprintf("INSERT INTO %s (username, successful, time, remote_ip, user_agent, auth_type) VALUES ($1, $2, $3, $4, $5, $6)", authenticationLogsTableName),
+			sqlGetLatestAuthenticationLogs:    fmt.Sprintf("SELECT successful, time, remote_ip, user_agent, auth_type FROM %s WHERE time>$1 AND username=$2 ORDER BY time DESC", authenticationLogsTableName),
+			sqlDeleteFailedAuthenticationLogs: fmt.Sprintf("DELETE FROM %s WHERE username=$1 AND successful=$2", authenticationLogsTableName),
+			sqlDeleteAuthenticationLogsBefore: fmt.Sprintf("DELETE FROM %s WHERE time<$1", authenticationLogsTableName),
+
+			sqlInsertUserEvent: fmt.Sprintf("INSERT INTO %s (username, event, successful, time, remote_ip, user_agent) VALUES ($1, $2, $3, $4, $5, $6)", userEventsTableName),
+			sqlGetUserEvents:   sqlUserEventsQueryPostgreSQL,
 
-			sqlInsertAuthenticationLog:     fmt.Sprintf("INSERT INTO %s (username, successful, time) VALUES ($1, $2, $3)", authenticationLogsTableName),
-			sqlGetLatestAuthenticationLogs: fmt.Sprintf("SELECT successful, time FROM %s WHERE time>$1 AND username=$2 ORDER BY time DESC", authenticationLogsTableName),
+			sqlGetNotificationThrottle:         fmt.Sprintf("SELECT last_sent_at, pending, pending_since FROM %s WHERE username=$1 AND kind=$2", notificationThrottlesTableName),
+			sqlUpsertNotificationThrottle:      fmt.Sprintf("INSERT INTO %s (username, kind, last_sent_at, pending, pending_since) VALUES ($1, $2, $3, $4, $5) ON CONFLICT (username, kind) DO UPDATE SET last_sent_at=$3, pending=$4, pending_since=$5", notificationThrottlesTableName),
+			sqlGetPendingNotificationThrottles: fmt.Sprintf("SELECT username, kind, last_sent_at, pending, pending_since FROM %s WHERE pending>0", notificationThrottlesTableName),
+
+			sqlUpsertAnnouncementAcknowledgment: fmt.Sprintf("INSERT INTO %s (username, announcement_id, acknowledged_at) VALUES ($1, $2, $3) ON CONFLICT (username, announcement_id) DO UPDATE SET acknowledged_at=$3", announcementAcknowledgmentsTableName),
+			sqlGetAnnouncementAcknowledgment:    fmt.Sprintf("SELECT acknowledged_at FROM %s WHERE username=$1 AND announcement_id=$2", announcementAcknowledgmentsTableName),
+
+			sqlDeleteBackupCodes:      fmt.Sprintf("DELETE FROM %s WHERE username=$1", backupCodesTableName),
+			sqlInsertBackupCode:       fmt.Sprintf("INSERT INTO %s (username, code_hash, created_at) VALUES ($1, $2, $3)", backupCodesTableName),
+			sqlDeleteBackupCode:       fmt.Sprintf("DELETE FROM %s WHERE username=$1 AND code_hash=$2", backupCodesTableName),
+			sqlCountBackupCodes:       fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE username=$1", backupCodesTableName),
+			sqlSelectBackupCodeHashes: fmt.Sprintf("SELECT code_hash FROM %s WHERE username=$1", backupCodesTableName),
+
+			sqlInsertAdminAPIToken:         fmt.Sprintf("INSERT INTO %s (id, description, token_hash, scopes, created_at, expires_at) VALUES ($1, $2, $3, $4, $5, $6)", adminAPITokensTableName),
+			sqlGetAdminAPITokenByHash:      fmt.Sprintf("SELECT id, description, token_hash, scopes, created_at, expires_at, last_used_at FROM %s WHERE token_hash=$1", adminAPITokensTableName),
+			sqlGetAdminAPITokens:           fmt.Sprintf("SELECT id, description, token_hash, scopes, created_at, expires_at, last_used_at FROM %s ORDER BY created_at", adminAPITokensTableName),
+			sqlUpdateAdminAPITokenLastUsed: fmt.Sprintf("UPDATE %s SET last_used_at=$1 WHERE id=$2", adminAPITokensTableName),
+			sqlDeleteAdminAPIToken:         fmt.Sprintf("DELETE FROM %s WHERE id=$1", adminAPITokensTableName),
+
+			sqlUpsertPushEnrollment: fmt.Sprintf("INSERT INTO %s (username, provider, status, devices, checked_at) VALUES ($1, $2, $3, $4, $5) ON CONFLICT (username) DO UPDATE SET provider=$2, status=$3, devices=$4, checked_at=$5", pushEnrollmentsTableName),
+			sqlGetPushEnrollment:    fmt.Sprintf("SELECT provider, status, devices, checked_at FROM %s WHERE username=$1", pushEnrollmentsTableName),
+
+			sqlInsertSecondFactorReset:         fmt.Sprintf("INSERT INTO %s (id, username, requested_by, reason, approval, approval_token_hash, status, requested_at, expires_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)", secondFactorResetsTableName),
+			sqlGetSecondFactorReset:            fmt.Sprintf("SELECT id, username, requested_by, reason, approval, approval_token_hash, status, decided_by, requested_at, expires_at, decided_at FROM %s WHERE id=$1", secondFactorResetsTableName),
+			sqlGetPendingSecondFactorResets:    fmt.Sprintf("SELECT id, username, requested_by, reason, approval, approval_token_hash, status, decided_by, requested_at, expires_at, decided_at FROM %s WHERE status=$1 AND expires_at>$2 ORDER BY requested_at", secondFactorResetsTableName),
+			sqlUpdateSecondFactorResetDecision: fmt.Sprintf("UPDATE %s SET status=$1, decided_by=$2, decided_at=$3 WHERE id=$4 AND username=$5 AND status=$6 AND expires_at>$7", secondFactorResetsTableName),
+			sqlDeleteSecondFactorData:          sqlDeleteSecondFactorDataStatements("$1"),
+
+			sqlInsertAccessGrant:         fmt.Sprintf("INSERT INTO %s (id, username, target_url, description, created_at, expires_at) VALUES ($1, $2, $3, $4, $5, $6)", accessGrantsTableName),
+			sqlGetAccessGrant:            fmt.Sprintf("SELECT id, username, target_url, description, created_at, expires_at FROM %s WHERE id=$1", accessGrantsTableName),
+			sqlGetAccessGrants:           fmt.Sprintf("SELECT id, username, target_url, description, created_at, expires_at FROM %s WHERE expires_at>$1 ORDER BY created_at", accessGrantsTableName),
+			sqlGetUserAccessGrants:       fmt.Sprintf("SELECT id, username, target_url, description, created_at, expires_at FROM %s WHERE username=$1 AND expires_at>$2 ORDER BY created_at", accessGrantsTableName),
+			sqlDeleteAccessGrant:         fmt.Sprintf("DELETE FROM %s WHERE id=$1", accessGrantsTableName),
+			sqlDeleteExpiredAccessGrants: fmt.Sprintf("DELETE FROM %s WHERE expires_at<$1", accessGrantsTableName),
+
+			sqlInsertBreakGlassActivation: fmt.Sprintf("INSERT INTO %s (username, activated_at) VALUES ($1, $2)", breakGlassActivationsTableName),
+			sqlGetBreakGlassActivation:    fmt.Sprintf("SELECT username, activated_at FROM %s WHERE username=$1", breakGlassActivationsTableName),
+			sqlGetBreakGlassActivations:   fmt.Sprintf("SELECT username, activated_at FROM %s ORDER BY activated_at", breakGlassActivationsTableName),
+			sqlDeleteBreakGlassActivation: fmt.Sprintf("DELETE FROM %s WHERE username=$1", breakGlassActivationsTableName),
+
+			sqlUpsertOIDCConsent:         fmt.Sprintf("INSERT INTO %s (username, client_id, scopes, audience, created_at, expires_at) VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT (username, client_id) DO UPDATE SET scopes=$3, audience=$4, created_at=$5, expires_at=$6", oidcConsentsTableName),
+			sqlGetOIDCConsent:            fmt.Sprintf("SELECT username, client_id, scopes, audience, created_at, expires_at FROM %s WHERE username=$1 AND client_id=$2 AND expires_at>$3", oidcConsentsTableName),
+			sqlGetUserOIDCConsents:       fmt.Sprintf("SELECT username, client_id, scopes, audience, created_at, expires_at FROM %s WHERE username=$1 AND expires_at>$2 ORDER BY created_at", oidcConsentsTableName),
+			sqlDeleteOIDCConsent:         fmt.Sprintf("DELETE FROM %s WHERE username=$1 AND client_id=$2", oidcConsentsTableName),
+			sqlDeleteExpiredOIDCConsents: fmt.Sprintf("DELETE FROM %s WHERE expires_at<$1", oidcConsentsTableName),
+
+			sqlInsertOIDCClient: fmt.Sprintf("INSERT INTO %s (id, description, secret_hash, policy, redirect_uris, grant_types, response_types, scopes, post_logout_redirect_uris, backchannel_logout_uri, frontchannel_logout_uri, registered_by, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)", oidcClientsTableName),
+			sqlGetOIDCClients:   fmt.Sprintf("SELECT id, description, secret_hash, policy, redirect_uris, grant_types, response_types, scopes, post_logout_redirect_uris, backchannel_logout_uri, frontchannel_logout_uri, registered_by, created_at FROM %s ORDER BY created_at", oidcClientsTableName),
+			sqlDeleteOIDCClient: fmt.Sprintf("DELETE FROM %s WHERE id=$1", oidcClientsTableName),
+
+			sqlGetUsernames: sqlSelectUsernamesStatement(),
+			sqlGetLastLogin: fmt.Sprintf("SELECT time FROM %s WHERE username=$1 AND successful=$2 ORDER BY time DESC LIMIT 1", authenticationLogsTableName),
+
+			sqlDeleteUserData:                 sqlDeleteUserDataStatements("$1"),
+			sqlUpsertSessionRevocation:        fmt.Sprintf("INSERT INTO %s (username, revoked_at) VALUES ($1, $2) ON CONFLICT (username) DO UPDATE SET revoked_at=$2", sessionRevocationsTableName),
+			sqlGetSessionRevocation:           fmt.Sprintf("SELECT revoked_at FROM %s WHERE username=$1", sessionRevocationsTableName),
+			sqlDeleteSessionRevocationsBefore: fmt.Sprintf("DELETE FROM %s WHERE revoked_at<$1", sessionRevocationsTableName),
 
 			sqlGetExistingTables: "SELECT table_name FROM information_schema.tables WHERE table_type='BASE TABLE' AND table_schema='public'",
 
-			sqlConfigSetValue: fmt.Sprintf("INSERT INTO %s (category, key_name, value) VALUES ($1, $2, $3) ON CONFLICT (category, key_name) DO UPDATE SET value=$3", configTableName),
-			sqlConfigGetValue: fmt.Sprintf("SELECT value FROM %s WHERE category=$1 AND key_name=$2", configTableName),
+			sqlConfigSetValue:    fmt.Sprintf("INSERT INTO %s (category, key_name, value) VALUES ($1, $2, $3) ON CONFLICT (category, key_name) DO UPDATE SET value=$3", configTableName),
+			sqlConfigGetValue:    fmt.Sprintf("SELECT value FROM %s WHERE category=$1 AND key_name=$2", configTableName),
+			sqlConfigDeleteValue: fmt.Sprintf("DELETE FROM %s WHERE category=$1 AND key_name=$2", configTableName),
 		},
 	}
+}
 
+// postgreSQLConnectionString returns the connection string of the servers, the connections are made to the first one
+// accepting writes when there are several servers.
+func postgreSQLConnectionString(configuration schema.PostgreSQLStorageConfiguration, hosts []string, ports []int) string {
 	args := make([]string, 0)
 	if configuration.Username != "" {
 		args = append(args, fmt.Sprintf("user='%s'", configuration.Username))
@@ -57,12 +197,12 @@ func NewPostgreSQLProvider(configuration schema.PostgreSQLStorageConfiguration)
 		args = append(args, fmt.Sprintf("password='%s'", configuration.Password))
 	}
 
-	if configuration.Host != "" {
-		args = append(args, fmt.Sprintf("host=%s", configuration.Host))
+	if hosts[0] != "" {
+		args = append(args, fmt.Sprintf("host=%s", strings.Join(hosts, ",")))
 	}
 
-	if configuration.Port > 0 {
-		args = append(args, fmt.Sprintf("port=%d", configuration.Port))
+	if port := postgreSQLPorts(ports); port != "" {
+		args = append(args, fmt.Sprintf("port=%s", port))
 	}
 
 	if configuration.Database != "" {
@@ -73,16 +213,44 @@ func NewPostgreSQLProvider(configuration schema.PostgreSQLStorageConfiguration)
 		args = append(args, fmt.Sprintf("sslmode=%s", configuration.SSLMode))
 	}
 
-	connectionString := strings.Join(args, " ")
+	if configuration.SSLRootCertificate != "" {
+		args = append(args, fmt.Sprintf("sslrootcert='%s'", configuration.SSLRootCertificate))
+	}
 
-	db, err := sql.Open("pgx", connectionString)
-	if err != nil {
-		provider.log.Fatalf("Unable to connect to SQL database: %v", err)
+	if configuration.SSLCertificate != "" {
+		args = append(args, fmt.Sprintf("sslcert='%s'", configuration.SSLCertificate), fmt.Sprintf("sslkey='%s'", configuration.SSLKey))
 	}
 
-	if err := provider.initialize(db); err != nil {
-		provider.log.Fatalf("Unable to initialize SQL database: %v", err)
+	if configuration.DisablePreparedStatements {
+		args = append(args, "statement_cache_mode=describe")
+	}
+
+	if len(hosts) > 1 {
+		args = append(args, "target_session_attrs=read-write")
+	}
+
+	return strings.Join(args, " ")
+}
+
+// postgreSQLPorts returns the ports of the servers separated by commas, the servers without a port use the default one.
+// It's empty when none of the servers has a port.
+func postgreSQLPorts(ports []int) string {
+	values := make([]string, len(ports))
+	empty := true
+
+	for i, port := range ports {
+		if port > 0 {
+			empty = false
+		} else {
+			port = postgreSQLDefaultPort
+		}
+
+		values[i] = strconv.Itoa(port)
+	}
+
+	if empty {
+		return ""
 	}
 
-	return &provider
+	return strings.Join(values, ",")
 }
@@ -11,18 +11,96 @@ import (
 type Provider interface {
 	LoadPreferred2FAMethod(username string) (string, error)
 	SavePreferred2FAMethod(username string, method string) error
+	DeletePreferred2FAMethod(username string) error
 
 	FindIdentityVerificationToken(token string) (bool, error)
-	SaveIdentityVerificationToken(token string) error
+	SaveIdentityVerificationToken(token string, expiresAt time.Time) error
 	RemoveIdentityVerificationToken(token string) error
 
-	SaveTOTPSecret(username string, secret string) error
-	LoadTOTPSecret(username string) (string, error)
-	DeleteTOTPSecret(username string) error
+	SaveLoginState(nonce string, expiresAt time.Time) error
+	ConsumeLoginState(nonce string, now time.Time) error
+
+	SaveTOTPDevice(device models.TOTPDevice) error
+	LoadTOTPDevices(username string) ([]models.TOTPDevice, error)
+	UpdateTOTPDeviceDescription(username, deviceID, description string) error
+	DeleteTOTPDevice(username, deviceID string) error
+	SaveTOTPDeviceLastUsed(username, deviceID string, step uint64, usedAt time.Time) error
 
 	SaveU2FDeviceHandle(username string, keyHandle []byte, publicKey []byte) error
 	LoadU2FDeviceHandle(username string) (keyHandle []byte, publicKey []byte, err error)
+	DeleteU2FDeviceHandle(username string) error
+
+	SaveWebauthnCredential(credential models.WebauthnCredential) error
+	LoadWebauthnCredential(credentialID []byte) (*models.WebauthnCredential, error)
+	LoadWebauthnCredentials(username string) ([]models.WebauthnCredential, error)
+	UpdateWebauthnCredentialSignCount(credentialID []byte, signCount uint32) error
+	DeleteWebauthnCredential(username string, credentialID []byte) error
 
 	AppendAuthenticationLog(attempt models.AuthenticationAttempt) error
 	LoadLatestAuthenticationLogs(username string, fromDate time.Time) ([]models.AuthenticationAttempt, error)
+	DeleteFailedAuthenticationLogs(username string) (int64, error)
+	LoadLastLogin(username string) (time.Time, error)
+
+	AppendUserEvent(event models.UserEvent) error
+	LoadUserEvents(username, eventType string, limit, offset int) ([]models.UserEvent, error)
+
+	LoadNotificationThrottle(username, kind string) (models.NotificationThrottle, error)
+	SaveNotificationThrottle(throttle models.NotificationThrottle) error
+	LoadPendingNotificationThrottles() ([]models.NotificationThrottle, error)
+
+	SaveAnnouncementAcknowledgment(username, announcementID string, acknowledgedAt time.Time) error
+	LoadAnnouncementAcknowledgment(username, announcementID string) (time.Time, error)
+
+	SaveBackupCodes(username string, codeHashes []string, createdAt time.Time) error
+	ConsumeBackupCode(username, codeHash string) error
+	LoadBackupCodeHashes(username string) ([]string, error)
+	CountBackupCodes(username string) (int, error)
+
+	SaveAdminAPIToken(token models.AdminAPIToken) error
+	LoadAdminAPIToken(tokenHash string) (*models.AdminAPIToken, error)
+	LoadAdminAPITokens() ([]models.AdminAPIToken, error)
+	UpdateAdminAPITokenLastUsed(id string, lastUsedAt time.Time) error
+	DeleteAdminAPIToken(id string) error
+
+	SavePushEnrollment(enrollment models.PushEnrollment) error
+	LoadPushEnrollment(username string) (*models.PushEnrollment, error)
+
+	SaveSecondFactorReset(reset models.SecondFactorReset) error
+	LoadSecondFactorReset(id string) (*models.SecondFactorReset, error)
+	LoadPendingSecondFactorResets(now time.Time) ([]models.SecondFactorReset, error)
+	ApproveSecondFactorReset(id, username, approvedBy string, approvedAt time.Time) error
+	RejectSecondFactorReset(id, username, rejectedBy string, rejectedAt time.Time) error
+
+	SaveAccessGrant(grant models.AccessGrant) error
+	LoadAccessGrant(id string) (*models.AccessGrant, error)
+	LoadAccessGrants(now time.Time) ([]models.AccessGrant, error)
+	LoadUserAccessGrants(username string, now time.Time) ([]models.AccessGrant, error)
+	DeleteAccessGrant(id string) error
+
+	SaveBreakGlassActivation(activation models.BreakGlassActivation) error
+	LoadBreakGlassActivation(username string) (*models.BreakGlassActivation, error)
+	LoadBreakGlassActivations() ([]models.BreakGlassActivation, error)
+	DeleteBreakGlassActivation(username string) error
+
+	SaveOIDCConsent(consent models.OIDCConsent) error
+	LoadOIDCConsent(username, clientID string, now time.Time) (*models.OIDCConsent, error)
+	LoadUserOIDCConsents(username string, now time.Time) ([]models.OIDCConsent, error)
+	DeleteOIDCConsent(username, clientID string) error
+
+	SaveOIDCClient(client models.OIDCClient) error
+	LoadOIDCClients() ([]models.OIDCClient, error)
+	DeleteOIDCClient(id string) error
+
+	LoadUsernames() ([]string, error)
+
+	PurgeUser(username string, revokedAt time.Time) error
+	RevokeSessions(username string, revokedAt time.Time) error
+	LoadSessionsRevocation(username string) (time.Time, error)
+
+	PruneIdentityVerificationTokens(now time.Time) (int64, error)
+	PruneAuthenticationLogs(before time.Time) (int64, error)
+	PruneSessionRevocations(before time.Time) (int64, error)
+	PruneLoginStates(now time.Time) (int64, error)
+	PruneAccessGrants(now time.Time) (int64, error)
+	PruneOIDCConsents(now time.Time) (int64, error)
 }
@@ -65,6 +65,20 @@ func (mr *MockProviderMockRecorder) SavePreferred2FAMethod(username, method inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePreferred2FAMethod", reflect.TypeOf((*MockProvider)(nil).SavePreferred2FAMethod), username, method)
 }
 
+// DeletePreferred2FAMethod mocks base method
+func (m *MockProvider) DeletePreferred2FAMethod(username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePreferred2FAMethod", username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePreferred2FAMethod indicates an expected call of DeletePreferred2FAMethod
+func (mr *MockProviderMockRecorder) DeletePreferred2FAMethod(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePreferred2FAMethod", reflect.TypeOf((*MockProvider)(nil).DeletePreferred2FAMethod), username)
+}
+
 // FindIdentityVerificationToken mocks base method
 func (m *MockProvider) FindIdentityVerificationToken(token string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -81,17 +95,17 @@ func (mr *MockProviderMockRecorder) FindIdentityVerificationToken(token interfac
 }
 
 // SaveIdentityVerificationToken mocks base method
-func (m *MockProvider) SaveIdentityVerificationToken(token string) error {
+func (m *MockProvider) SaveIdentityVerificationToken(token string, expiresAt time.Time) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveIdentityVerificationToken", token)
+	ret := m.ctrl.Call(m, "SaveIdentityVerificationToken", token, expiresAt)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SaveIdentityVerificationToken indicates an expected call of SaveIdentityVerificationToken
-func (mr *MockProviderMockRecorder) SaveIdentityVerificationToken(token interface{}) *gomock.Call {
+func (mr *MockProviderMockRecorder) SaveIdentityVerificationToken(token, expiresAt interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveIdentityVerificationToken", reflect.TypeOf((*MockProvider)(nil).SaveIdentityVerificationToken), token)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveIdentityVerificationToken", reflect.TypeOf((*MockProvider)(nil).SaveIdentityVerificationToken), token, expiresAt)
 }
 
 // RemoveIdentityVerificationToken mocks base method
@@ -108,47 +122,103 @@ func (mr *MockProviderMockRecorder) RemoveIdentityVerificationToken(token interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveIdentityVerificationToken", reflect.TypeOf((*MockProvider)(nil).RemoveIdentityVerificationToken), token)
 }
 
-// SaveTOTPSecret mocks base method
-func (m *MockProvider) SaveTOTPSecret(username, secret string) error {
+// SaveLoginState mocks base method
+func (m *MockProvider) SaveLoginState(nonce string, expiresAt time.Time) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveTOTPSecret", username, secret)
+	ret := m.ctrl.Call(m, "SaveLoginState", nonce, expiresAt)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// SaveTOTPSecret indicates an expected call of SaveTOTPSecret
-func (mr *MockProviderMockRecorder) SaveTOTPSecret(username, secret interface{}) *gomock.Call {
+// SaveLoginState indicates an expected call of SaveLoginState
+func (mr *MockProviderMockRecorder) SaveLoginState(nonce, expiresAt interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTOTPSecret", reflect.TypeOf((*MockProvider)(nil).SaveTOTPSecret), username, secret)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveLoginState", reflect.TypeOf((*MockProvider)(nil).SaveLoginState), nonce, expiresAt)
 }
 
-// LoadTOTPSecret mocks base method
-func (m *MockProvider) LoadTOTPSecret(username string) (string, error) {
+// ConsumeLoginState mocks base method
+func (m *MockProvider) ConsumeLoginState(nonce string, now time.Time) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "LoadTOTPSecret", username)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "ConsumeLoginState", nonce, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConsumeLoginState indicates an expected call of ConsumeLoginState
+func (mr *MockProviderMockRecorder) ConsumeLoginState(nonce, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeLoginState", reflect.TypeOf((*MockProvider)(nil).ConsumeLoginState), nonce, now)
+}
+
+// SaveTOTPDevice mocks base method
+func (m *MockProvider) SaveTOTPDevice(device models.TOTPDevice) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTOTPDevice", device)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveTOTPDevice indicates an expected call of SaveTOTPDevice
+func (mr *MockProviderMockRecorder) SaveTOTPDevice(device interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTOTPDevice", reflect.TypeOf((*MockProvider)(nil).SaveTOTPDevice), device)
+}
+
+// LoadTOTPDevices mocks base method
+func (m *MockProvider) LoadTOTPDevices(username string) ([]models.TOTPDevice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadTOTPDevices", username)
+	ret0, _ := ret[0].([]models.TOTPDevice)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// LoadTOTPSecret indicates an expected call of LoadTOTPSecret
-func (mr *MockProviderMockRecorder) LoadTOTPSecret(username interface{}) *gomock.Call {
+// LoadTOTPDevices indicates an expected call of LoadTOTPDevices
+func (mr *MockProviderMockRecorder) LoadTOTPDevices(username interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadTOTPSecret", reflect.TypeOf((*MockProvider)(nil).LoadTOTPSecret), username)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadTOTPDevices", reflect.TypeOf((*MockProvider)(nil).LoadTOTPDevices), username)
 }
 
-// DeleteTOTPSecret mocks base method
-func (m *MockProvider) DeleteTOTPSecret(username string) error {
+// UpdateTOTPDeviceDescription mocks base method
+func (m *MockProvider) UpdateTOTPDeviceDescription(username, deviceID, description string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteTOTPSecret", username)
+	ret := m.ctrl.Call(m, "UpdateTOTPDeviceDescription", username, deviceID, description)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteTOTPSecret indicates an expected call of DeleteTOTPSecret
-func (mr *MockProviderMockRecorder) DeleteTOTPSecret(username interface{}) *gomock.Call {
+// UpdateTOTPDeviceDescription indicates an expected call of UpdateTOTPDeviceDescription
+func (mr *MockProviderMockRecorder) UpdateTOTPDeviceDescription(username, deviceID, description interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTOTPSecret", reflect.TypeOf((*MockProvider)(nil).DeleteTOTPSecret), username)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTOTPDeviceDescription", reflect.TypeOf((*MockProvider)(nil).UpdateTOTPDeviceDescription), username, deviceID, description)
+}
+
+// DeleteTOTPDevice mocks base method
+func (m *MockProvider) DeleteTOTPDevice(username, deviceID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTOTPDevice", username, deviceID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTOTPDevice indicates an expected call of DeleteTOTPDevice
+func (mr *MockProviderMockRecorder) DeleteTOTPDevice(username, deviceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTOTPDevice", reflect.TypeOf((*MockProvider)(nil).DeleteTOTPDevice), username, deviceID)
+}
+
+// SaveTOTPDeviceLastUsed mocks base method
+func (m *MockProvider) SaveTOTPDeviceLastUsed(username, deviceID string, step uint64, usedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTOTPDeviceLastUsed", username, deviceID, step, usedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveTOTPDeviceLastUsed indicates an expected call of SaveTOTPDeviceLastUsed
+func (mr *MockProviderMockRecorder) SaveTOTPDeviceLastUsed(username, deviceID, step, usedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTOTPDeviceLastUsed", reflect.TypeOf((*MockProvider)(nil).SaveTOTPDeviceLastUsed), username, deviceID, step, usedAt)
 }
 
 // SaveU2FDeviceHandle mocks base method
@@ -181,6 +251,92 @@ func (mr *MockProviderMockRecorder) LoadU2FDeviceHandle(username interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadU2FDeviceHandle", reflect.TypeOf((*MockProvider)(nil).LoadU2FDeviceHandle), username)
 }
 
+// DeleteU2FDeviceHandle mocks base method
+func (m *MockProvider) DeleteU2FDeviceHandle(username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteU2FDeviceHandle", username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteU2FDeviceHandle indicates an expected call of DeleteU2FDeviceHandle
+func (mr *MockProviderMockRecorder) DeleteU2FDeviceHandle(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteU2FDeviceHandle", reflect.TypeOf((*MockProvider)(nil).DeleteU2FDeviceHandle), username)
+}
+
+// SaveWebauthnCredential mocks base method
+func (m *MockProvider) SaveWebauthnCredential(credential models.WebauthnCredential) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveWebauthnCredential", credential)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveWebauthnCredential indicates an expected call of SaveWebauthnCredential
+func (mr *MockProviderMockRecorder) SaveWebauthnCredential(credential interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveWebauthnCredential", reflect.TypeOf((*MockProvider)(nil).SaveWebauthnCredential), credential)
+}
+
+// LoadWebauthnCredential mocks base method
+func (m *MockProvider) LoadWebauthnCredential(credentialID []byte) (*models.WebauthnCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadWebauthnCredential", credentialID)
+	ret0, _ := ret[0].(*models.WebauthnCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadWebauthnCredential indicates an expected call of LoadWebauthnCredential
+func (mr *MockProviderMockRecorder) LoadWebauthnCredential(credentialID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadWebauthnCredential", reflect.TypeOf((*MockProvider)(nil).LoadWebauthnCredential), credentialID)
+}
+
+// LoadWebauthnCredentials mocks base method
+func (m *MockProvider) LoadWebauthnCredentials(username string) ([]models.WebauthnCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadWebauthnCredentials", username)
+	ret0, _ := ret[0].([]models.WebauthnCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadWebauthnCredentials indicates an expected call of LoadWebauthnCredentials
+func (mr *MockProviderMockRecorder) LoadWebauthnCredentials(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadWebauthnCredentials", reflect.TypeOf((*MockProvider)(nil).LoadWebauthnCredentials), username)
+}
+
+// UpdateWebauthnCredentialSignCount mocks base method
+func (m *MockProvider) UpdateWebauthnCredentialSignCount(credentialID []byte, signCount uint32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebauthnCredentialSignCount", credentialID, signCount)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebauthnCredentialSignCount indicates an expected call of UpdateWebauthnCredentialSignCount
+func (mr *MockProviderMockRecorder) UpdateWebauthnCredentialSignCount(credentialID, signCount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebauthnCredentialSignCount", reflect.TypeOf((*MockProvider)(nil).UpdateWebauthnCredentialSignCount), credentialID, signCount)
+}
+
+// DeleteWebauthnCredential mocks base method
+func (m *MockProvider) DeleteWebauthnCredential(username string, credentialID []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebauthnCredential", username, credentialID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebauthnCredential indicates an expected call of DeleteWebauthnCredential
+func (mr *MockProviderMockRecorder) DeleteWebauthnCredential(username, credentialID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebauthnCredential", reflect.TypeOf((*MockProvider)(nil).DeleteWebauthnCredential), username, credentialID)
+}
+
 // AppendAuthenticationLog mocks base method
 func (m *MockProvider) AppendAuthenticationLog(attempt models.AuthenticationAttempt) error {
 	m.ctrl.T.Helper()
@@ -209,3 +365,746 @@ func (mr *MockProviderMockRecorder) LoadLatestAuthenticationLogs(username, fromD
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadLatestAuthenticationLogs", reflect.TypeOf((*MockProvider)(nil).LoadLatestAuthenticationLogs), username, fromDate)
 }
+
+// DeleteFailedAuthenticationLogs mocks base method
+func (m *MockProvider) DeleteFailedAuthenticationLogs(username string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFailedAuthenticationLogs", username)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteFailedAuthenticationLogs indicates an expected call of DeleteFailedAuthenticationLogs
+func (mr *MockProviderMockRecorder) DeleteFailedAuthenticationLogs(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFailedAuthenticationLogs", reflect.TypeOf((*MockProvider)(nil).DeleteFailedAuthenticationLogs), username)
+}
+
+// LoadLastLogin mocks base method
+func (m *MockProvider) LoadLastLogin(username string) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadLastLogin", username)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadLastLogin indicates an expected call of LoadLastLogin
+func (mr *MockProviderMockRecorder) LoadLastLogin(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadLastLogin", reflect.TypeOf((*MockProvider)(nil).LoadLastLogin), username)
+}
+
+// AppendUserEvent mocks base method
+func (m *MockProvider) AppendUserEvent(event models.UserEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AppendUserEvent", event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AppendUserEvent indicates an expected call of AppendUserEvent
+func (mr *MockProviderMockRecorder) AppendUserEvent(event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppendUserEvent", reflect.TypeOf((*MockProvider)(nil).AppendUserEvent), event)
+}
+
+// LoadUserEvents mocks base method
+func (m *MockProvider) LoadUserEvents(username, eventType string, limit, offset int) ([]models.UserEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadUserEvents", username, eventType, limit, offset)
+	ret0, _ := ret[0].([]models.UserEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadUserEvents indicates an expected call of LoadUserEvents
+func (mr *MockProviderMockRecorder) LoadUserEvents(username, eventType, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadUserEvents", reflect.TypeOf((*MockProvider)(nil).LoadUserEvents), username, eventType, limit, offset)
+}
+
+// LoadNotificationThrottle mocks base method
+func (m *MockProvider) LoadNotificationThrottle(username, kind string) (models.NotificationThrottle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadNotificationThrottle", username, kind)
+	ret0, _ := ret[0].(models.NotificationThrottle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadNotificationThrottle indicates an expected call of LoadNotificationThrottle
+func (mr *MockProviderMockRecorder) LoadNotificationThrottle(username, kind interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadNotificationThrottle", reflect.TypeOf((*MockProvider)(nil).LoadNotificationThrottle), username, kind)
+}
+
+// SaveNotificationThrottle mocks base method
+func (m *MockProvider) SaveNotificationThrottle(throttle models.NotificationThrottle) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveNotificationThrottle", throttle)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveNotificationThrottle indicates an expected call of SaveNotificationThrottle
+func (mr *MockProviderMockRecorder) SaveNotificationThrottle(throttle interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveNotificationThrottle", reflect.TypeOf((*MockProvider)(nil).SaveNotificationThrottle), throttle)
+}
+
+// LoadPendingNotificationThrottles mocks base method
+func (m *MockProvider) LoadPendingNotificationThrottles() ([]models.NotificationThrottle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadPendingNotificationThrottles")
+	ret0, _ := ret[0].([]models.NotificationThrottle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadPendingNotificationThrottles indicates an expected call of LoadPendingNotificationThrottles
+func (mr *MockProviderMockRecorder) LoadPendingNotificationThrottles() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadPendingNotificationThrottles", reflect.TypeOf((*MockProvider)(nil).LoadPendingNotificationThrottles))
+}
+
+// SaveAnnouncementAcknowledgment mocks base method
+func (m *MockProvider) SaveAnnouncementAcknowledgment(username, announcementID string, acknowledgedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAnnouncementAcknowledgment", username, announcementID, acknowledgedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveAnnouncementAcknowledgment indicates an expected call of SaveAnnouncementAcknowledgment
+func (mr *MockProviderMockRecorder) SaveAnnouncementAcknowledgment(username, announcementID, acknowledgedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAnnouncementAcknowledgment", reflect.TypeOf((*MockProvider)(nil).SaveAnnouncementAcknowledgment), username, announcementID, acknowledgedAt)
+}
+
+// LoadAnnouncementAcknowledgment mocks base method
+func (m *MockProvider) LoadAnnouncementAcknowledgment(username, announcementID string) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadAnnouncementAcknowledgment", username, announcementID)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadAnnouncementAcknowledgment indicates an expected call of LoadAnnouncementAcknowledgment
+func (mr *MockProviderMockRecorder) LoadAnnouncementAcknowledgment(username, announcementID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadAnnouncementAcknowledgment", reflect.TypeOf((*MockProvider)(nil).LoadAnnouncementAcknowledgment), username, announcementID)
+}
+
+// SaveBackupCodes mocks base method
+func (m *MockProvider) SaveBackupCodes(username string, codeHashes []string, createdAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveBackupCodes", username, codeHashes, createdAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveBackupCodes indicates an expected call of SaveBackupCodes
+func (mr *MockProviderMockRecorder) SaveBackupCodes(username, codeHashes, createdAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveBackupCodes", reflect.TypeOf((*MockProvider)(nil).SaveBackupCodes), username, codeHashes, createdAt)
+}
+
+// ConsumeBackupCode mocks base method
+func (m *MockProvider) ConsumeBackupCode(username, codeHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeBackupCode", username, codeHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConsumeBackupCode indicates an expected call of ConsumeBackupCode
+func (mr *MockProviderMockRecorder) ConsumeBackupCode(username, codeHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeBackupCode", reflect.TypeOf((*MockProvider)(nil).ConsumeBackupCode), username, codeHash)
+}
+
+// LoadBackupCodeHashes mocks base method
+func (m *MockProvider) LoadBackupCodeHashes(username string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadBackupCodeHashes", username)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadBackupCodeHashes indicates an expected call of LoadBackupCodeHashes
+func (mr *MockProviderMockRecorder) LoadBackupCodeHashes(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadBackupCodeHashes", reflect.TypeOf((*MockProvider)(nil).LoadBackupCodeHashes), username)
+}
+
+// CountBackupCodes mocks base method
+func (m *MockProvider) CountBackupCodes(username string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountBackupCodes", username)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountBackupCodes indicates an expected call of CountBackupCodes
+func (mr *MockProviderMockRecorder) CountBackupCodes(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountBackupCodes", reflect.TypeOf((*MockProvider)(nil).CountBackupCodes), username)
+}
+
+// SaveAdminAPIToken mocks base method
+func (m *MockProvider) SaveAdminAPIToken(token models.AdminAPIToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAdminAPIToken", token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveAdminAPIToken indicates an expected call of SaveAdminAPIToken
+func (mr *MockProviderMockRecorder) SaveAdminAPIToken(token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAdminAPIToken", reflect.TypeOf((*MockProvider)(nil).SaveAdminAPIToken), token)
+}
+
+// LoadAdminAPIToken mocks base method
+func (m *MockProvider) LoadAdminAPIToken(tokenHash string) (*models.AdminAPIToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadAdminAPIToken", tokenHash)
+	ret0, _ := ret[0].(*models.AdminAPIToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadAdminAPIToken indicates an expected call of LoadAdminAPIToken
+func (mr *MockProviderMockRecorder) LoadAdminAPIToken(tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadAdminAPIToken", reflect.TypeOf((*MockProvider)(nil).LoadAdminAPIToken), tokenHash)
+}
+
+// SavePushEnrollment mocks base method
+func (m *MockProvider) SavePushEnrollment(enrollment models.PushEnrollment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePushEnrollment", enrollment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SavePushEnrollment indicates an expected call of SavePushEnrollment
+func (mr *MockProviderMockRecorder) SavePushEnrollment(enrollment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePushEnrollment", reflect.TypeOf((*MockProvider)(nil).SavePushEnrollment), enrollment)
+}
+
+// LoadPushEnrollment mocks base method
+func (m *MockProvider) LoadPushEnrollment(username string) (*models.PushEnrollment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadPushEnrollment", username)
+	ret0, _ := ret[0].(*models.PushEnrollment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadPushEnrollment indicates an expected call of LoadPushEnrollment
+func (mr *MockProviderMockRecorder) LoadPushEnrollment(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadPushEnrollment", reflect.TypeOf((*MockProvider)(nil).LoadPushEnrollment), username)
+}
+
+// SaveSecondFactorReset mocks base method
+func (m *MockProvider) SaveSecondFactorReset(reset models.SecondFactorReset) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSecondFactorReset", reset)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSecondFactorReset indicates an expected call of SaveSecondFactorReset
+func (mr *MockProviderMockRecorder) SaveSecondFactorReset(reset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSecondFactorReset", reflect.TypeOf((*MockProvider)(nil).SaveSecondFactorReset), reset)
+}
+
+// LoadSecondFactorReset mocks base method
+func (m *MockProvider) LoadSecondFactorReset(id string) (*models.SecondFactorReset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadSecondFactorReset", id)
+	ret0, _ := ret[0].(*models.SecondFactorReset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadSecondFactorReset indicates an expected call of LoadSecondFactorReset
+func (mr *MockProviderMockRecorder) LoadSecondFactorReset(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadSecondFactorReset", reflect.TypeOf((*MockProvider)(nil).LoadSecondFactorReset), id)
+}
+
+// LoadPendingSecondFactorResets mocks base method
+func (m *MockProvider) LoadPendingSecondFactorResets(now time.Time) ([]models.SecondFactorReset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadPendingSecondFactorResets", now)
+	ret0, _ := ret[0].([]models.SecondFactorReset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadPendingSecondFactorResets indicates an expected call of LoadPendingSecondFactorResets
+func (mr *MockProviderMockRecorder) LoadPendingSecondFactorResets(now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadPendingSecondFactorResets", reflect.TypeOf((*MockProvider)(nil).LoadPendingSecondFactorResets), now)
+}
+
+// ApproveSecondFactorReset mocks base method
+func (m *MockProvider) ApproveSecondFactorReset(id, username, approvedBy string, approvedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApproveSecondFactorReset", id, username, approvedBy, approvedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ApproveSecondFactorReset indicates an expected call of ApproveSecondFactorReset
+func (mr *MockProviderMockRecorder) ApproveSecondFactorReset(id, username, approvedBy, approvedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveSecondFactorReset", reflect.TypeOf((*MockProvider)(nil).ApproveSecondFactorReset), id, username, approvedBy, approvedAt)
+}
+
+// RejectSecondFactorReset mocks base method
+func (m *MockProvider) RejectSecondFactorReset(id, username, rejectedBy string, rejectedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RejectSecondFactorReset", id, username, rejectedBy, rejectedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RejectSecondFactorReset indicates an expected call of RejectSecondFactorReset
+func (mr *MockProviderMockRecorder) RejectSecondFactorReset(id, username, rejectedBy, rejectedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectSecondFactorReset", reflect.TypeOf((*MockProvider)(nil).RejectSecondFactorReset), id, username, rejectedBy, rejectedAt)
+}
+
+// SaveAccessGrant mocks base method
+func (m *MockProvider) SaveAccessGrant(grant models.AccessGrant) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAccessGrant", grant)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveAccessGrant indicates an expected call of SaveAccessGrant
+func (mr *MockProviderMockRecorder) SaveAccessGrant(grant interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAccessGrant", reflect.TypeOf((*MockProvider)(nil).SaveAccessGrant), grant)
+}
+
+// LoadAccessGrant mocks base method
+func (m *MockProvider) LoadAccessGrant(id string) (*models.AccessGrant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadAccessGrant", id)
+	ret0, _ := ret[0].(*models.AccessGrant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadAccessGrant indicates an expected call of LoadAccessGrant
+func (mr *MockProviderMockRecorder) LoadAccessGrant(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadAccessGrant", reflect.TypeOf((*MockProvider)(nil).LoadAccessGrant), id)
+}
+
+// LoadAccessGrants mocks base method
+func (m *MockProvider) LoadAccessGrants(now time.Time) ([]models.AccessGrant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadAccessGrants", now)
+	ret0, _ := ret[0].([]models.AccessGrant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadAccessGrants indicates an expected call of LoadAccessGrants
+func (mr *MockProviderMockRecorder) LoadAccessGrants(now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadAccessGrants", reflect.TypeOf((*MockProvider)(nil).LoadAccessGrants), now)
+}
+
+// LoadUserAccessGrants mocks base method
+func (m *MockProvider) LoadUserAccessGrants(username string, now time.Time) ([]models.AccessGrant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadUserAccessGrants", username, now)
+	ret0, _ := ret[0].([]models.AccessGrant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadUserAccessGrants indicates an expected call of LoadUserAccessGrants
+func (mr *MockProviderMockRecorder) LoadUserAccessGrants(username, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadUserAccessGrants", reflect.TypeOf((*MockProvider)(nil).LoadUserAccessGrants), username, now)
+}
+
+// DeleteAccessGrant mocks base method
+func (m *MockProvider) DeleteAccessGrant(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccessGrant", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccessGrant indicates an expected call of DeleteAccessGrant
+func (mr *MockProviderMockRecorder) DeleteAccessGrant(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccessGrant", reflect.TypeOf((*MockProvider)(nil).DeleteAccessGrant), id)
+}
+
+// LoadAdminAPITokens mocks base method
+func (m *MockProvider) LoadAdminAPITokens() ([]models.AdminAPIToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadAdminAPITokens")
+	ret0, _ := ret[0].([]models.AdminAPIToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadAdminAPITokens indicates an expected call of LoadAdminAPITokens
+func (mr *MockProviderMockRecorder) LoadAdminAPITokens() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadAdminAPITokens", reflect.TypeOf((*MockProvider)(nil).LoadAdminAPITokens))
+}
+
+// UpdateAdminAPITokenLastUsed mocks base method
+func (m *MockProvider) UpdateAdminAPITokenLastUsed(id string, lastUsedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAdminAPITokenLastUsed", id, lastUsedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAdminAPITokenLastUsed indicates an expected call of UpdateAdminAPITokenLastUsed
+func (mr *MockProviderMockRecorder) UpdateAdminAPITokenLastUsed(id, lastUsedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAdminAPITokenLastUsed", reflect.TypeOf((*MockProvider)(nil).UpdateAdminAPITokenLastUsed), id, lastUsedAt)
+}
+
+// DeleteAdminAPIToken mocks base method
+func (m *MockProvider) DeleteAdminAPIToken(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAdminAPIToken", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAdminAPIToken indicates an expected call of DeleteAdminAPIToken
+func (mr *MockProviderMockRecorder) DeleteAdminAPIToken(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAdminAPIToken", reflect.TypeOf((*MockProvider)(nil).DeleteAdminAPIToken), id)
+}
+
+// SaveBreakGlassActivation mocks base method
+func (m *MockProvider) SaveBreakGlassActivation(activation models.BreakGlassActivation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveBreakGlassActivation", activation)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveBreakGlassActivation indicates an expected call of SaveBreakGlassActivation
+func (mr *MockProviderMockRecorder) SaveBreakGlassActivation(activation interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveBreakGlassActivation", reflect.TypeOf((*MockProvider)(nil).SaveBreakGlassActivation), activation)
+}
+
+// LoadBreakGlassActivation mocks base method
+func (m *MockProvider) LoadBreakGlassActivation(username string) (*models.BreakGlassActivation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadBreakGlassActivation", username)
+	ret0, _ := ret[0].(*models.BreakGlassActivation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadBreakGlassActivation indicates an expected call of LoadBreakGlassActivation
+func (mr *MockProviderMockRecorder) LoadBreakGlassActivation(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadBreakGlassActivation", reflect.TypeOf((*MockProvider)(nil).LoadBreakGlassActivation), username)
+}
+
+// LoadBreakGlassActivations mocks base method
+func (m *MockProvider) LoadBreakGlassActivations() ([]models.BreakGlassActivation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadBreakGlassActivations")
+	ret0, _ := ret[0].([]models.BreakGlassActivation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadBreakGlassActivations indicates an expected call of LoadBreakGlassActivations
+func (mr *MockProviderMockRecorder) LoadBreakGlassActivations() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadBreakGlassActivations", reflect.TypeOf((*MockProvider)(nil).LoadBreakGlassActivations))
+}
+
+// DeleteBreakGlassActivation mocks base method
+func (m *MockProvider) DeleteBreakGlassActivation(username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBreakGlassActivation", username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBreakGlassActivation indicates an expected call of DeleteBreakGlassActivation
+func (mr *MockProviderMockRecorder) DeleteBreakGlassActivation(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBreakGlassActivation", reflect.TypeOf((*MockProvider)(nil).DeleteBreakGlassActivation), username)
+}
+
+// SaveOIDCConsent mocks base method
+func (m *MockProvider) SaveOIDCConsent(consent models.OIDCConsent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveOIDCConsent", consent)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveOIDCConsent indicates an expected call of SaveOIDCConsent
+func (mr *MockProviderMockRecorder) SaveOIDCConsent(consent interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOIDCConsent", reflect.TypeOf((*MockProvider)(nil).SaveOIDCConsent), consent)
+}
+
+// LoadOIDCConsent mocks base method
+func (m *MockProvider) LoadOIDCConsent(username string, clientID string, now time.Time) (*models.OIDCConsent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadOIDCConsent", username, clientID, now)
+	ret0, _ := ret[0].(*models.OIDCConsent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadOIDCConsent indicates an expected call of LoadOIDCConsent
+func (mr *MockProviderMockRecorder) LoadOIDCConsent(username, clientID, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadOIDCConsent", reflect.TypeOf((*MockProvider)(nil).LoadOIDCConsent), username, clientID, now)
+}
+
+// LoadUserOIDCConsents mocks base method
+func (m *MockProvider) LoadUserOIDCConsents(username string, now time.Time) ([]models.OIDCConsent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadUserOIDCConsents", username, now)
+	ret0, _ := ret[0].([]models.OIDCConsent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadUserOIDCConsents indicates an expected call of LoadUserOIDCConsents
+func (mr *MockProviderMockRecorder) LoadUserOIDCConsents(username, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadUserOIDCConsents", reflect.TypeOf((*MockProvider)(nil).LoadUserOIDCConsents), username, now)
+}
+
+// DeleteOIDCConsent mocks base method
+func (m *MockProvider) DeleteOIDCConsent(username string, clientID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOIDCConsent", username, clientID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOIDCConsent indicates an expected call of DeleteOIDCConsent
+func (mr *MockProviderMockRecorder) DeleteOIDCConsent(username, clientID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOIDCConsent", reflect.TypeOf((*MockProvider)(nil).DeleteOIDCConsent), username, clientID)
+}
+
+// SaveOIDCClient mocks base method
+func (m *MockProvider) SaveOIDCClient(arg0 models.OIDCClient) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveOIDCClient", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveOIDCClient indicates an expected call of SaveOIDCClient
+func (mr *MockProviderMockRecorder) SaveOIDCClient(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOIDCClient", reflect.TypeOf((*MockProvider)(nil).SaveOIDCClient), arg0)
+}
+
+// LoadOIDCClients mocks base method
+func (m *MockProvider) LoadOIDCClients() ([]models.OIDCClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadOIDCClients")
+	ret0, _ := ret[0].([]models.OIDCClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadOIDCClients indicates an expected call of LoadOIDCClients
+func (mr *MockProviderMockRecorder) LoadOIDCClients() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadOIDCClients", reflect.TypeOf((*MockProvider)(nil).LoadOIDCClients))
+}
+
+// DeleteOIDCClient mocks base method
+func (m *MockProvider) DeleteOIDCClient(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOIDCClient", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOIDCClient indicates an expected call of DeleteOIDCClient
+func (mr *MockProviderMockRecorder) DeleteOIDCClient(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOIDCClient", reflect.TypeOf((*MockProvider)(nil).DeleteOIDCClient), arg0)
+}
+
+// LoadUsernames mocks base method
+func (m *MockProvider) LoadUsernames() ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadUsernames")
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadUsernames indicates an expected call of LoadUsernames
+func (mr *MockProviderMockRecorder) LoadUsernames() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadUsernames", reflect.TypeOf((*MockProvider)(nil).LoadUsernames))
+}
+
+// PurgeUser mocks base method
+func (m *MockProvider) PurgeUser(username string, revokedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeUser", username, revokedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeUser indicates an expected call of PurgeUser
+func (mr *MockProviderMockRecorder) PurgeUser(username, revokedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeUser", reflect.TypeOf((*MockProvider)(nil).PurgeUser), username, revokedAt)
+}
+
+// RevokeSessions mocks base method
+func (m *MockProvider) RevokeSessions(username string, revokedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSessions", username, revokedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSessions indicates an expected call of RevokeSessions
+func (mr *MockProviderMockRecorder) RevokeSessions(username, revokedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSessions", reflect.TypeOf((*MockProvider)(nil).RevokeSessions), username, revokedAt)
+}
+
+// LoadSessionsRevocation mocks base method
+func (m *MockProvider) LoadSessionsRevocation(username string) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadSessionsRevocation", username)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadSessionsRevocation indicates an expected call of LoadSessionsRevocation
+func (mr *MockProviderMockRecorder) LoadSessionsRevocation(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadSessionsRevocation", reflect.TypeOf((*MockProvider)(nil).LoadSessionsRevocation), username)
+}
+
+// PruneIdentityVerificationTokens mocks base method
+func (m *MockProvider) PruneIdentityVerificationTokens(now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PruneIdentityVerificationTokens", now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PruneIdentityVerificationTokens indicates an expected call of PruneIdentityVerificationTokens
+func (mr *MockProviderMockRecorder) PruneIdentityVerificationTokens(now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PruneIdentityVerificationTokens", reflect.TypeOf((*MockProvider)(nil).PruneIdentityVerificationTokens), now)
+}
+
+// PruneAuthenticationLogs mocks base method
+func (m *MockProvider) PruneAuthenticationLogs(before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PruneAuthenticationLogs", before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PruneAuthenticationLogs indicates an expected call of PruneAuthenticationLogs
+func (mr *MockProviderMockRecorder) PruneAuthenticationLogs(before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PruneAuthenticationLogs", reflect.TypeOf((*MockProvider)(nil).PruneAuthenticationLogs), before)
+}
+
+// PruneSessionRevocations mocks base method
+func (m *MockProvider) PruneSessionRevocations(before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PruneSessionRevocations", before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PruneSessionRevocations indicates an expected call of PruneSessionRevocations
+func (mr *MockProviderMockRecorder) PruneSessionRevocations(before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PruneSessionRevocations", reflect.TypeOf((*MockProvider)(nil).PruneSessionRevocations), before)
+}
+
+// PruneLoginStates mocks base method
+func (m *MockProvider) PruneLoginStates(now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PruneLoginStates", now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PruneLoginStates indicates an expected call of PruneLoginStates
+func (mr *MockProviderMockRecorder) PruneLoginStates(now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PruneLoginStates", reflect.TypeOf((*MockProvider)(nil).PruneLoginStates), now)
+}
+
+// PruneAccessGrants mocks base method
+func (m *MockProvider) PruneAccessGrants(now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PruneAccessGrants", now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PruneAccessGrants indicates an expected call of PruneAccessGrants
+func (mr *MockProviderMockRecorder) PruneAccessGrants(now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PruneAccessGrants", reflect.TypeOf((*MockProvider)(nil).PruneAccessGrants), now)
+}
+
+// PruneOIDCConsents mocks base method
+func (m *MockProvider) PruneOIDCConsents(now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PruneOIDCConsents", now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PruneOIDCConsents indicates an expected call of PruneOIDCConsents
+func (mr *MockProviderMockRecorder) PruneOIDCConsents(now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PruneOIDCConsents", reflect.TypeOf((*MockProvider)(nil).PruneOIDCConsents), now)
+}
@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"expvar"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/logging"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+// prunedRows counts the rows deleted by the pruner per table, it's exposed by the expvars endpoint.
+var prunedRows = expvar.NewMap("authelia_storage_pruned_rows")
+
+// Pruner periodically deletes the data of the storage which is not needed anymore.
+type Pruner struct {
+	provider Provider
+	clock    utils.Clock
+	log      *logrus.Logger
+
+	interval time.Duration
+
+	// The retention of the authentication logs, they are kept forever when it's 0.
+	authenticationLogs time.Duration
+
+	// The revocations are kept as long as a session created before them may still be alive.
+	sessionRevocations time.Duration
+}
+
+// NewPruner create a pruner from the retention configuration. The sessions configuration is used to determine how
+// long the sessions revocations need to be kept.
+func NewPruner(configuration schema.StorageRetentionConfiguration, session schema.SessionConfiguration, provider Provider, clock utils.Clock) *Pruner {
+	pruner := &Pruner{
+		provider:           provider,
+		clock:              clock,
+		log:                logging.Logger(),
+		interval:           mustParseDuration(configuration.Interval),
+		authenticationLogs: mustParseDuration(configuration.AuthenticationLogs),
+		sessionRevocations: mustParseDuration(session.Expiration),
+	}
+
+	if session.RememberMeDuration != "" {
+		if rememberMe := mustParseDuration(session.RememberMeDuration); rememberMe > pruner.sessionRevocations {
+			pruner.sessionRevocations = rememberMe
+		}
+	}
+
+	return pruner
+}
+
+func mustParseDuration(input string) time.Duration {
+	duration, err := utils.ParseDurationString(input)
+	if err != nil {
+		panic(err)
+	}
+
+	return duration
+}
+
+// Start prunes the storage at every interval in the background, the pruner is disabled when the interval is 0.
+func (p *Pruner) Start() {
+	if p.interval <= 0 {
+		return
+	}
+
+	go func() {
+		for {
+			<-p.clock.After(p.interval)
+
+			p.Prune()
+		}
+	}()
+}
+
+// Prune deletes the expired identity verification tokens, login states, access grants and OpenID Connect consents, the
+// authentication logs older than their retention and the sessions revocations which can't apply to any session anymore.
+func (p *Pruner) Prune() {
+	now := p.clock.Now()
+
+	p.prune(identityVerificationTokensTableName, func() (int64, error) {
+		return p.provider.PruneIdentityVerificationTokens(now)
+	})
+
+	p.prune(loginStatesTableName, func() (int64, error) {
+		return p.provider.PruneLoginStates(now)
+	})
+
+	p.prune(accessGrantsTableName, func() (int64, error) {
+		return p.provider.PruneAccessGrants(now)
+	})
+
+	p.prune(oidcConsentsTableName, func() (int64, error) {
+		return p.provider.PruneOIDCConsents(now)
+	})
+
+	if p.authenticationLogs > 0 {
+		p.prune(authenticationLogsTableName, func() (int64, error) {
+			return p.provider.PruneAuthenticationLogs(now.Add(-p.authenticationLogs))
+		})
+	}
+
+	p.prune(sessionRevocationsTableName, func() (int64, error) {
+		return p.provider.PruneSessionRevocations(now.Add(-p.sessionRevocations))
+	})
+}
+
+func (p *Pruner) prune(table string, delete func() (int64, error)) {
+	count, err := delete()
+	if err != nil {
+		p.log.Errorf("Unable to prune table %s: %s", table, err)
+		return
+	}
+
+	prunedRows.Add(table, count)
+
+	if count > 0 {
+		p.log.Debugf("Pruned %d rows from table %s", count, table)
+	}
+}
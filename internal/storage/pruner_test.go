@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"errors"
+	"expvar"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/utils"
+)
+
+type fixedClock struct {
+	now time.Time
+}
+
+func (c fixedClock) Now() time.Time {
+	return c.now
+}
+
+func (c fixedClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+var prunerTestSession = schema.SessionConfiguration{
+	Expiration:         "1h",
+	RememberMeDuration: "1M",
+}
+
+func TestShouldPruneStorage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Unix(1640000000, 0)
+	provider := NewMockProvider(ctrl)
+
+	pruner := NewPruner(schema.StorageRetentionConfiguration{Interval: "1h", AuthenticationLogs: "1w"},
+		prunerTestSession, provider, fixedClock{now: now})
+
+	before := prunedRows.Get(authenticationLogsTableName)
+
+	gomock.InOrder(
+		provider.EXPECT().PruneIdentityVerificationTokens(now).Return(int64(2), nil),
+		provider.EXPECT().PruneLoginStates(now).Return(int64(4), nil),
+		provider.EXPECT().PruneAccessGrants(now).Return(int64(1), nil),
+		provider.EXPECT().PruneOIDCConsents(now).Return(int64(3), nil),
+		provider.EXPECT().PruneAuthenticationLogs(now.Add(-time.Hour*24*7)).Return(int64(5), nil),
+		provider.EXPECT().PruneSessionRevocations(now.Add(-utils.Month)).Return(int64(0), errors.New("failed")),
+	)
+
+	pruner.Prune()
+
+	var previous int64
+	if before != nil {
+		previous = before.(*expvar.Int).Value()
+	}
+
+	assert.Equal(t, previous+5, prunedRows.Get(authenticationLogsTableName).(*expvar.Int).Value())
+}
+
+func TestShouldKeepAuthenticationLogsForeverByDefault(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Unix(1640000000, 0)
+	provider := NewMockProvider(ctrl)
+
+	pruner := NewPruner(schema.DefaultStorageRetentionConfiguration,
+		schema.SessionConfiguration{Expiration: "1w", RememberMeDuration: "1d"}, provider, fixedClock{now: now})
+
+	provider.EXPECT().PruneIdentityVerificationTokens(now).Return(int64(0), nil)
+	provider.EXPECT().PruneLoginStates(now).Return(int64(0), nil)
+	provider.EXPECT().PruneAccessGrants(now).Return(int64(0), nil)
+	provider.EXPECT().PruneOIDCConsents(now).Return(int64(0), nil)
+	provider.EXPECT().PruneSessionRevocations(now.Add(-time.Hour*24*7)).Return(int64(1), nil)
+
+	pruner.Prune()
+}
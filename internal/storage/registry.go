@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+// ProviderFactory creates a Provider from the storage configuration and the options of the provider decoded from the
+// storage.options configuration block.
+type ProviderFactory func(configuration schema.StorageConfiguration, options map[string]interface{}) (Provider, error)
+
+var (
+	providerFactoriesMu sync.RWMutex
+	providerFactories   = map[string]ProviderFactory{}
+)
+
+// RegisterProvider makes a storage Provider available under the provided name so it can be selected with the
+// storage.provider configuration key. It panics if the name is already registered or the factory is nil.
+func RegisterProvider(name string, factory ProviderFactory) {
+	providerFactoriesMu.Lock()
+	defer providerFactoriesMu.Unlock()
+
+	if factory == nil {
+		panic("storage: RegisterProvider factory is nil")
+	}
+
+	if _, dup := providerFactories[name]; dup {
+		panic("storage: RegisterProvider called twice for provider " + name)
+	}
+
+	providerFactories[name] = factory
+}
+
+// Providers returns a sorted list of the names of the registered storage providers.
+func Providers() (names []string) {
+	providerFactoriesMu.RLock()
+	defer providerFactoriesMu.RUnlock()
+
+	for name := range providerFactories {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// NewProvider creates the storage Provider described by the configuration, either one of the built-in providers or a
+// provider registered with RegisterProvider.
+func NewProvider(configuration schema.StorageConfiguration) (Provider, error) {
+	switch {
+	case configuration.Provider != "":
+		providerFactoriesMu.RLock()
+		factory, ok := providerFactories[configuration.Provider]
+		providerFactoriesMu.RUnlock()
+
+		if !ok {
+			return nil, fmt.Errorf("unknown storage provider '%s'", configuration.Provider)
+		}
+
+		return factory(configuration, configuration.Options)
+	case configuration.MigrationTarget != nil:
+		current, err := newBuiltinProvider(configuration.Local, configuration.MySQL, configuration.PostgreSQL, configuration.EncryptionKey, configuration.PreviousEncryptionKey)
+		if err != nil {
+			return nil, err
+		}
+
+		target, err := NewMigrationTargetProvider(configuration)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewDualWriteProvider(current, target), nil
+	default:
+		return newBuiltinProvider(configuration.Local, configuration.MySQL, configuration.PostgreSQL, configuration.EncryptionKey, configuration.PreviousEncryptionKey)
+	}
+}
+
+// NewMigrationTargetProvider creates the storage Provider of the backend the data is migrated to.
+func NewMigrationTargetProvider(configuration schema.StorageConfiguration) (Provider, error) {
+	if configuration.MigrationTarget == nil {
+		return nil, errors.New("no storage migration target is configured")
+	}
+
+	target := configuration.MigrationTarget
+
+	return newBuiltinProvider(target.Local, target.MySQL, target.PostgreSQL, configuration.EncryptionKey, configuration.PreviousEncryptionKey)
+}
+
+func newBuiltinProvider(local *schema.LocalStorageConfiguration, mysql *schema.MySQLStorageConfiguration,
+	postgres *schema.PostgreSQLStorageConfiguration, encryptionKey, previousEncryptionKey string) (Provider, error) {
+	var provider encryptingProvider
+
+	switch {
+	case postgres != nil:
+		provider = NewPostgreSQLProvider(*postgres)
+	case mysql != nil:
+		provider = NewMySQLProvider(*mysql)
+	case local != nil:
+		provider = NewSQLiteProvider(local.Path)
+	default:
+		return nil, errors.New("unrecognized storage backend")
+	}
+
+	if err := provider.useEncryptionKey(encryptionKey, previousEncryptionKey); err != nil {
+		return nil, err
+	}
+
+	return provider, nil
+}
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+func TestShouldCreateRegisteredProvider(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	expected := NewMockProvider(ctrl)
+
+	var options map[string]interface{}
+
+	RegisterProvider("test_registered", func(_ schema.StorageConfiguration, o map[string]interface{}) (Provider, error) {
+		options = o
+		return expected, nil
+	})
+
+	assert.Contains(t, Providers(), "test_registered")
+
+	provider, err := NewProvider(schema.StorageConfiguration{
+		Provider: "test_registered",
+		Options:  map[string]interface{}{"bucket": "authelia"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, expected, provider)
+	assert.Equal(t, map[string]interface{}{"bucket": "authelia"}, options)
+
+	assert.Panics(t, func() {
+		RegisterProvider("test_registered", func(_ schema.StorageConfiguration, _ map[string]interface{}) (Provider, error) {
+			return nil, nil
+		})
+	})
+}
+
+func TestShouldErrorOnUnknownProvider(t *testing.T) {
+	_, err := NewProvider(schema.StorageConfiguration{Provider: "test_unknown"})
+	assert.EqualError(t, err, "unknown storage provider 'test_unknown'")
+
+	_, err = NewProvider(schema.StorageConfiguration{})
+	assert.EqualError(t, err, "unrecognized storage backend")
+}
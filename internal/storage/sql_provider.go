@@ -4,10 +4,16 @@ import (
 	"database/sql"
 	"encoding/base64"
 	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/sirupsen/logrus"
 
+	"github.com/authelia/authelia/internal/configuration/schema"
 	"github.com/authelia/authelia/internal/logging"
 	"github.com/authelia/authelia/internal/models"
 	"github.com/authelia/authelia/internal/utils"
@@ -19,30 +25,137 @@ type SQLProvider struct {
 	log  *logrus.Logger
 	name string
 
+	// replicas are the read replicas the reads tolerating stale data are sent to in turn.
+	replicas    []*sql.DB
+	replicaNext uint32
+
+	// dryRun receives the statements changing the database instead of executing them when it's not nil.
+	dryRun io.Writer
+
+	// upgradedFrom is the version of the schema before it was upgraded when the provider was initialized, it's nil
+	// when no upgrade has been applied.
+	upgradedFrom *SchemaVersion
+
 	sqlUpgradesCreateTableStatements        map[SchemaVersion]map[string]string
 	sqlUpgradesCreateTableIndexesStatements map[SchemaVersion][]string
+	sqlUpgradesStatements                   map[SchemaVersion][]string
+	sqlDowngradesStatements                 map[SchemaVersion][]string
 
 	sqlGetPreferencesByUsername     string
 	sqlUpsertSecondFactorPreference string
+	sqlDeletePreferencesByUsername  string
 
 	sqlTestIdentityVerificationTokenExistence string
 	sqlInsertIdentityVerificationToken        string
 	sqlDeleteIdentityVerificationToken        string
 
-	sqlGetTOTPSecretByUsername string
-	sqlUpsertTOTPSecret        string
-	sqlDeleteTOTPSecret        string
+	sqlDeleteExpiredIdentityVerificationTokens string
+
+	sqlInsertLoginState         string
+	sqlDeleteLoginState         string
+	sqlDeleteExpiredLoginStates string
+
+	sqlInsertTOTPDevice            string
+	sqlGetTOTPDevicesByUsername    string
+	sqlUpdateTOTPDeviceDescription string
+	sqlDeleteTOTPDevice            string
+	sqlUpdateTOTPDeviceLastUsed    string
+	sqlGetTOTPDeviceSecrets        string
+	sqlUpdateTOTPDeviceSecret      string
+	sqlRotateTOTPDeviceSecret      string
 
 	sqlGetU2FDeviceHandleByUsername string
 	sqlUpsertU2FDeviceHandle        string
-
-	sqlInsertAuthenticationLog     string
-	sqlGetLatestAuthenticationLogs string
+	sqlDeleteU2FDeviceHandle        string
+	sqlGetU2FDeviceKeyHandles       string
+	sqlUpdateU2FDeviceKeyHandle     string
+	sqlRotateU2FDeviceKeyHandle     string
+
+	sqlInsertWebauthnCredential          string
+	sqlGetWebauthnCredentialByID         string
+	sqlGetWebauthnCredentialsByUsername  string
+	sqlUpdateWebauthnCredentialSignCount string
+	sqlDeleteWebauthnCredential          string
+
+	sqlInsertAuthenticationLog        string
+	sqlGetLatestAuthenticationLogs    string
+	sqlDeleteFailedAuthenticationLogs string
+
+	sqlDeleteAuthenticationLogsBefore string
+
+	sqlInsertUserEvent string
+	sqlGetUserEvents   string
+
+	sqlGetNotificationThrottle         string
+	sqlUpsertNotificationThrottle      string
+	sqlGetPendingNotificationThrottles string
+
+	sqlUpsertAnnouncementAcknowledgment string
+	sqlGetAnnouncementAcknowledgment    string
+
+	sqlDeleteBackupCodes      string
+	sqlInsertBackupCode       string
+	sqlDeleteBackupCode       string
+	sqlCountBackupCodes       string
+	sqlSelectBackupCodeHashes string
+
+	sqlInsertAdminAPIToken         string
+	sqlGetAdminAPITokenByHash      string
+	sqlGetAdminAPITokens           string
+	sqlUpdateAdminAPITokenLastUsed string
+	sqlDeleteAdminAPIToken         string
+
+	sqlUpsertPushEnrollment string
+	sqlGetPushEnrollment    string
+
+	sqlInsertSecondFactorReset         string
+	sqlGetSecondFactorReset            string
+	sqlGetPendingSecondFactorResets    string
+	sqlUpdateSecondFactorResetDecision string
+	sqlDeleteSecondFactorData          []string
+
+	sqlInsertAccessGrant         string
+	sqlGetAccessGrant            string
+	sqlGetAccessGrants           string
+	sqlGetUserAccessGrants       string
+	sqlDeleteAccessGrant         string
+	sqlDeleteExpiredAccessGrants string
+
+	sqlInsertBreakGlassActivation string
+	sqlGetBreakGlassActivation    string
+	sqlGetBreakGlassActivations   string
+	sqlDeleteBreakGlassActivation string
+
+	sqlUpsertOIDCConsent         string
+	sqlGetOIDCConsent            string
+	sqlGetUserOIDCConsents       string
+	sqlDeleteOIDCConsent         string
+	sqlDeleteExpiredOIDCConsents string
+
+	sqlInsertOIDCClient string
+	sqlGetOIDCClients   string
+	sqlDeleteOIDCClient string
+
+	sqlGetUsernames string
+	sqlGetLastLogin string
+
+	sqlDeleteUserData          []string
+	sqlUpsertSessionRevocation string
+	sqlGetSessionRevocation    string
+
+	sqlDeleteSessionRevocationsBefore string
 
 	sqlGetExistingTables string
 
-	sqlConfigSetValue string
-	sqlConfigGetValue string
+	sqlConfigSetValue    string
+	sqlConfigGetValue    string
+	sqlConfigDeleteValue string
+
+	// encryption encrypts the secrets of the second factor devices at rest, they're stored in plaintext when it's nil.
+	encryption *secretsEncryption
+
+	// previousEncryption decrypts the secrets encrypted with the previous encryption key while they're rotated.
+	previousEncryption *secretsEncryption
 }
 
 func (p *SQLProvider) initialize(db *sql.DB) error {
@@ -52,6 +165,27 @@ func (p *SQLProvider) initialize(db *sql.DB) error {
 	return p.upgrade()
 }
 
+// read runs the read on the next read replica when there are some. The read runs on the primary database instead when it
+// fails on the replica or doesn't find anything, since the data may not have been replicated yet.
+func (p *SQLProvider) read(read func(db *sql.DB) (found bool, err error)) error {
+	if len(p.replicas) != 0 {
+		replica := p.replicas[int(atomic.AddUint32(&p.replicaNext, 1)-1)%len(p.replicas)]
+
+		found, err := read(replica)
+		if err == nil && found {
+			return nil
+		}
+
+		if err != nil {
+			p.log.Debugf("Reading from the primary database after an error on the read replica: %v", err)
+		}
+	}
+
+	_, err := read(p.db)
+
+	return err
+}
+
 func (p *SQLProvider) getSchemaBasicDetails() (version SchemaVersion, tables []string, err error) {
 	rows, err := p.db.Query(p.sqlGetExistingTables)
 	if err != nil {
@@ -96,10 +230,17 @@ func (p *SQLProvider) upgrade() error {
 		return err
 	}
 
+	if version > storageSchemaCurrentVersion {
+		return fmt.Errorf("storage schema v%d is newer than the latest v%d supported by this version, it must be downgraded by the newer version first",
+			version, storageSchemaCurrentVersion)
+	}
+
 	if version < storageSchemaCurrentVersion {
 		p.log.Debugf("Storage schema is v%d, latest is v%d", version, storageSchemaCurrentVersion)
 
-		tx, err := p.db.Begin()
+		from := version
+
+		tx, err := p.begin()
 		if err != nil {
 			return err
 		}
@@ -108,28 +249,67 @@ func (p *SQLProvider) upgrade() error {
 		case 0:
 			err := p.upgradeSchemaToVersion001(tx, tables)
 			if err != nil {
-				return p.handleUpgradeFailure(tx, 1, err)
+				return p.handleSchemaChangeFailure(tx, storageSchemaUpgradeErrorText, 1, err)
 			}
 
+			version = 1
+
 			fallthrough
 		default:
+			for next := version + 1; next <= storageSchemaCurrentVersion; next++ {
+				if err := p.upgradeSchemaToVersion(tx, next, tables); err != nil {
+					return p.handleSchemaChangeFailure(tx, storageSchemaUpgradeErrorText, next, err)
+				}
+			}
+
 			err := tx.Commit()
 			if err != nil {
 				return err
 			}
 
+			if p.dryRun != nil {
+				p.log.Infof("Storage schema upgrade to v%d has not been applied (dry-run)", storageSchemaCurrentVersion)
+				return nil
+			}
+
 			p.log.Infof("Storage schema upgrade to v%d completed", storageSchemaCurrentVersion)
+
+			p.upgradedFrom = &from
 		}
 	} else {
+		if missing := missingSchemaTables(version, tables); len(missing) != 0 {
+			return fmt.Errorf("storage schema v%d is missing the tables %s", version, strings.Join(missing, ", "))
+		}
+
 		p.log.Debug("Storage schema is up to date")
 	}
 
 	return nil
 }
 
-func (p *SQLProvider) handleUpgradeFailure(tx *sql.Tx, version SchemaVersion, err error) error {
+// SchemaUpgrade returns the versions the schema was upgraded from and to when the provider was initialized, upgraded
+// is false when the schema was already up to date.
+func (p *SQLProvider) SchemaUpgrade() (from, to SchemaVersion, upgraded bool) {
+	if p.upgradedFrom == nil {
+		return 0, storageSchemaCurrentVersion, false
+	}
+
+	return *p.upgradedFrom, storageSchemaCurrentVersion, true
+}
+
+// begin starts a transaction, it's a dry-run one writing the statements instead of executing them in dry-run mode.
+func (p *SQLProvider) begin() (sqlTransaction, error) {
+	if p.dryRun != nil {
+		return dryRunTransaction{out: p.dryRun}, nil
+	}
+
+	return p.db.Begin()
+}
+
+// handleSchemaChangeFailure rolls back the upgrade or the downgrade of the schema which failed at the version.
+func (p *SQLProvider) handleSchemaChangeFailure(tx sqlTransaction, errorText string, version SchemaVersion, err error) error {
 	rollbackErr := tx.Rollback()
-	formattedErr := fmt.Errorf("%s%d: %v", storageSchemaUpgradeErrorText, version, err)
+	formattedErr := fmt.Errorf("%s%d: %v", errorText, version, err)
 
 	if rollbackErr != nil {
 		return fmt.Errorf("rollback error occurred: %v (inner error %v)", rollbackErr, formattedErr)
@@ -139,20 +319,20 @@ func (p *SQLProvider) handleUpgradeFailure(tx *sql.Tx, version SchemaVersion, er
 }
 
 // LoadPreferred2FAMethod load the preferred method for 2FA from the database.
-func (p *SQLProvider) LoadPreferred2FAMethod(username string) (string, error) {
-	var method string
-
-	rows, err := p.db.Query(p.sqlGetPreferencesByUsername, username)
-	if err != nil {
-		return "", err
-	}
-	defer rows.Close()
+func (p *SQLProvider) LoadPreferred2FAMethod(username string) (method string, err error) {
+	err = p.read(func(db *sql.DB) (found bool, err error) {
+		rows, err := db.Query(p.sqlGetPreferencesByUsername, username)
+		if err != nil {
+			return false, err
+		}
+		defer rows.Close()
 
-	if !rows.Next() {
-		return "", nil
-	}
+		if !rows.Next() {
+			return false, nil
+		}
 
-	err = rows.Scan(&method)
+		return true, rows.Scan(&method)
+	})
 
 	return method, err
 }
@@ -163,6 +343,12 @@ func (p *SQLProvider) SavePreferred2FAMethod(username string, method string) err
 	return err
 }
 
+// DeletePreferred2FAMethod delete the preferred method for 2FA of a given user so the default method applies again.
+func (p *SQLProvider) DeletePreferred2FAMethod(username string) error {
+	_, err := p.db.Exec(p.sqlDeletePreferencesByUsername, username)
+	return err
+}
+
 // FindIdentityVerificationToken look for an identity verification token in the database.
 func (p *SQLProvider) FindIdentityVerificationToken(token string) (bool, error) {
 	var found bool
@@ -175,9 +361,9 @@ func (p *SQLProvider) FindIdentityVerificationToken(token string) (bool, error)
 	return found, nil
 }
 
-// SaveIdentityVerificationToken save an identity verification token in the database.
-func (p *SQLProvider) SaveIdentityVerificationToken(token string) error {
-	_, err := p.db.Exec(p.sqlInsertIdentityVerificationToken, token)
+// SaveIdentityVerificationToken save an identity verification token in the database along with its expiration time.
+func (p *SQLProvider) SaveIdentityVerificationToken(token string, expiresAt time.Time) error {
+	_, err := p.db.Exec(p.sqlInsertIdentityVerificationToken, token, expiresAt.Unix())
 	return err
 }
 
@@ -187,51 +373,188 @@ func (p *SQLProvider) RemoveIdentityVerificationToken(token string) error {
 	return err
 }
 
-// SaveTOTPSecret save a TOTP secret of a given user in the database.
-func (p *SQLProvider) SaveTOTPSecret(username string, secret string) error {
-	_, err := p.db.Exec(p.sqlUpsertTOTPSecret, username, secret)
+// SaveLoginState save the nonce of a login state in the database along with its expiration time.
+func (p *SQLProvider) SaveLoginState(nonce string, expiresAt time.Time) error {
+	_, err := p.db.Exec(p.sqlInsertLoginState, nonce, expiresAt.Unix())
 	return err
 }
 
-// LoadTOTPSecret load a TOTP secret given a username from the database.
-func (p *SQLProvider) LoadTOTPSecret(username string) (string, error) {
-	var secret string
-	if err := p.db.QueryRow(p.sqlGetTOTPSecretByUsername, username).Scan(&secret); err != nil {
-		if err == sql.ErrNoRows {
-			return "", ErrNoTOTPSecret
+// ConsumeLoginState delete the nonce of a login state from the database so it can only be used once, ErrNoLoginState
+// is returned when the nonce is unknown or expired before the given time.
+func (p *SQLProvider) ConsumeLoginState(nonce string, now time.Time) error {
+	result, err := p.db.Exec(p.sqlDeleteLoginState, nonce, now.Unix())
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrNoLoginState
+	}
+
+	return nil
+}
+
+// SaveTOTPDevice save a TOTP device registered by a user in the database, its secret is encrypted when a storage
+// encryption key is configured.
+func (p *SQLProvider) SaveTOTPDevice(device models.TOTPDevice) error {
+	secret, err := p.encryption.encrypt(device.Secret)
+	if err != nil {
+		return fmt.Errorf("Unable to encrypt the TOTP secret: %v", err)
+	}
+
+	_, err = p.db.Exec(p.sqlInsertTOTPDevice, device.Username, device.ID, device.Description, secret, device.CreatedAt.Unix())
+
+	return err
+}
+
+// LoadTOTPDevices load all the TOTP devices of a given user from the database, oldest first.
+func (p *SQLProvider) LoadTOTPDevices(username string) (devices []models.TOTPDevice, err error) {
+	err = p.read(func(db *sql.DB) (found bool, err error) {
+		rows, err := db.Query(p.sqlGetTOTPDevicesByUsername, username)
+		if err != nil {
+			return false, err
+		}
+
+		defer rows.Close()
+
+		if devices, err = scanTOTPDevices(rows); err != nil {
+			return false, err
 		}
 
-		return "", err
+		return len(devices) != 0, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i, device := range devices {
+		if devices[i].Secret, err = p.decrypt(device.Secret); err != nil {
+			return nil, fmt.Errorf("Unable to decrypt the secret of TOTP device %s: %v", device.ID, err)
+		}
 	}
 
-	return secret, nil
+	return devices, nil
 }
 
-// DeleteTOTPSecret delete a TOTP secret from the database given a username.
-func (p *SQLProvider) DeleteTOTPSecret(username string) error {
-	_, err := p.db.Exec(p.sqlDeleteTOTPSecret, username)
+// UpdateTOTPDeviceDescription update the description of a TOTP device of a given user.
+func (p *SQLProvider) UpdateTOTPDeviceDescription(username, deviceID, description string) error {
+	_, err := p.db.Exec(p.sqlUpdateTOTPDeviceDescription, description, username, deviceID)
 	return err
 }
 
-// SaveU2FDeviceHandle save a registered U2F device registration blob.
+// DeleteTOTPDevice delete a TOTP device of a given user from the database.
+func (p *SQLProvider) DeleteTOTPDevice(username, deviceID string) error {
+	_, err := p.db.Exec(p.sqlDeleteTOTPDevice, username, deviceID)
+	return err
+}
+
+// SaveTOTPDeviceLastUsed save the last TOTP time step used with a device of a given user along with the time it was used.
+// The step is only saved when it's more recent than the last one, ErrTOTPStepAlreadyUsed is returned otherwise so a
+// passcode can't be replayed.
+func (p *SQLProvider) SaveTOTPDeviceLastUsed(username, deviceID string, step uint64, usedAt time.Time) error {
+	result, err := p.db.Exec(p.sqlUpdateTOTPDeviceLastUsed, int64(step), usedAt.Unix(), username, deviceID, int64(step))
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrTOTPStepAlreadyUsed
+	}
+
+	return nil
+}
+
+func scanTOTPDevices(rows *sql.Rows) ([]models.TOTPDevice, error) {
+	devices := make([]models.TOTPDevice, 0, 1)
+
+	for rows.Next() {
+		var (
+			device                models.TOTPDevice
+			createdAt, lastUsedAt sql.NullInt64
+		)
+
+		if err := rows.Scan(&device.ID, &device.Username, &device.Description, &device.Secret, &createdAt, &lastUsedAt); err != nil {
+			return nil, err
+		}
+
+		if createdAt.Valid {
+			device.CreatedAt = time.Unix(createdAt.Int64, 0)
+		}
+
+		if lastUsedAt.Valid {
+			device.LastUsedAt = time.Unix(lastUsedAt.Int64, 0)
+		}
+
+		devices = append(devices, device)
+	}
+
+	return devices, rows.Err()
+}
+
+// SaveU2FDeviceHandle save a registered U2F device registration blob, the key handle is encrypted when a storage
+// encryption key is configured.
 func (p *SQLProvider) SaveU2FDeviceHandle(username string, keyHandle []byte, publicKey []byte) error {
-	_, err := p.db.Exec(p.sqlUpsertU2FDeviceHandle,
+	keyHandleBase64, err := p.encryption.encrypt(base64.StdEncoding.EncodeToString(keyHandle))
+	if err != nil {
+		return fmt.Errorf("Unable to encrypt the U2F key handle: %v", err)
+	}
+
+	_, err = p.db.Exec(p.sqlUpsertU2FDeviceHandle,
 		username,
-		base64.StdEncoding.EncodeToString(keyHandle),
+		keyHandleBase64,
 		base64.StdEncoding.EncodeToString(publicKey))
 
 	return err
 }
 
+// DeleteU2FDeviceHandle delete the U2F device of a given user, ErrNoU2FDeviceHandle is returned when the user has none.
+func (p *SQLProvider) DeleteU2FDeviceHandle(username string) error {
+	return p.deleteOne(ErrNoU2FDeviceHandle, p.sqlDeleteU2FDeviceHandle, username)
+}
+
 // LoadU2FDeviceHandle load a U2F device registration blob for a given username.
 func (p *SQLProvider) LoadU2FDeviceHandle(username string) ([]byte, []byte, error) {
-	var keyHandleBase64, publicKeyBase64 string
-	if err := p.db.QueryRow(p.sqlGetU2FDeviceHandleByUsername, username).Scan(&keyHandleBase64, &publicKeyBase64); err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil, ErrNoU2FDeviceHandle
+	var (
+		keyHandleBase64, publicKeyBase64 string
+		found                            bool
+	)
+
+	err := p.read(func(db *sql.DB) (bool, error) {
+		found = false
+
+		if err := db.QueryRow(p.sqlGetU2FDeviceHandleByUsername, username).Scan(&keyHandleBase64, &publicKeyBase64); err != nil {
+			if err == sql.ErrNoRows {
+				return false, nil
+			}
+
+			return false, err
 		}
 
+		found = true
+
+		return true, nil
+	})
+
+	switch {
+	case err != nil:
 		return nil, nil, err
+	case !found:
+		return nil, nil, ErrNoU2FDeviceHandle
+	}
+
+	if keyHandleBase64, err = p.decrypt(keyHandleBase64); err != nil {
+		return nil, nil, fmt.Errorf("Unable to decrypt the U2F key handle: %v", err)
 	}
 
 	keyHandle, err := base64.StdEncoding.DecodeString(keyHandleBase64)
@@ -249,15 +572,124 @@ func (p *SQLProvider) LoadU2FDeviceHandle(username string) ([]byte, []byte, erro
 	return keyHandle, publicKey, nil
 }
 
+// SaveWebauthnCredential save a registered WebAuthn credential.
+func (p *SQLProvider) SaveWebauthnCredential(credential models.WebauthnCredential) error {
+	_, err := p.db.Exec(p.sqlInsertWebauthnCredential,
+		base64.StdEncoding.EncodeToString(credential.ID),
+		credential.Username,
+		credential.Type,
+		credential.Description,
+		base64.StdEncoding.EncodeToString(credential.PublicKey),
+		credential.SignCount,
+		credential.CreatedAt.Unix())
+
+	return err
+}
+
+// LoadWebauthnCredential load a WebAuthn credential given its ID.
+func (p *SQLProvider) LoadWebauthnCredential(credentialID []byte) (*models.WebauthnCredential, error) {
+	rows, err := p.db.Query(p.sqlGetWebauthnCredentialByID, base64.StdEncoding.EncodeToString(credentialID))
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	credentials, err := scanWebauthnCredentials(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(credentials) == 0 {
+		return nil, ErrNoWebauthnCredential
+	}
+
+	return &credentials[0], nil
+}
+
+// LoadWebauthnCredentials load all the WebAuthn credentials of a given user.
+func (p *SQLProvider) LoadWebauthnCredentials(username string) (credentials []models.WebauthnCredential, err error) {
+	err = p.read(func(db *sql.DB) (found bool, err error) {
+		rows, err := db.Query(p.sqlGetWebauthnCredentialsByUsername, username)
+		if err != nil {
+			return false, err
+		}
+
+		defer rows.Close()
+
+		if credentials, err = scanWebauthnCredentials(rows); err != nil {
+			return false, err
+		}
+
+		return len(credentials) != 0, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return credentials, nil
+}
+
+// UpdateWebauthnCredentialSignCount update the signature counter of a WebAuthn credential.
+func (p *SQLProvider) UpdateWebauthnCredentialSignCount(credentialID []byte, signCount uint32) error {
+	_, err := p.db.Exec(p.sqlUpdateWebauthnCredentialSignCount, signCount, base64.StdEncoding.EncodeToString(credentialID))
+	return err
+}
+
+// DeleteWebauthnCredential delete a WebAuthn credential of a given user, ErrNoWebauthnCredential is returned when the
+// user has no such credential.
+func (p *SQLProvider) DeleteWebauthnCredential(username string, credentialID []byte) error {
+	return p.deleteOne(ErrNoWebauthnCredential, p.sqlDeleteWebauthnCredential, username, base64.StdEncoding.EncodeToString(credentialID))
+}
+
+func scanWebauthnCredentials(rows *sql.Rows) ([]models.WebauthnCredential, error) {
+	var (
+		idBase64, publicKeyBase64 string
+		createdAt                 int64
+	)
+
+	credentials := make([]models.WebauthnCredential, 0, 1)
+
+	for rows.Next() {
+		var (
+			credential models.WebauthnCredential
+			err        error
+		)
+
+		if err = rows.Scan(&idBase64, &credential.Username, &credential.Type, &credential.Description, &publicKeyBase64, &credential.SignCount, &createdAt); err != nil {
+			return nil, err
+		}
+
+		if credential.ID, err = base64.StdEncoding.DecodeString(idBase64); err != nil {
+			return nil, err
+		}
+
+		if credential.PublicKey, err = base64.StdEncoding.DecodeString(publicKeyBase64); err != nil {
+			return nil, err
+		}
+
+		credential.CreatedAt = time.Unix(createdAt, 0)
+
+		credentials = append(credentials, credential)
+	}
+
+	return credentials, rows.Err()
+}
+
 // AppendAuthenticationLog append a mark to the authentication log.
 func (p *SQLProvider) AppendAuthenticationLog(attempt models.AuthenticationAttempt) error {
-	_, err := p.db.Exec(p.sqlInsertAuthenticationLog, attempt.Username, attempt.Successful, attempt.Time.Unix())
+	_, err := p.db.Exec(p.sqlInsertAuthenticationLog, attempt.Username, attempt.Successful, attempt.Time.Unix(),
+		attempt.RemoteIP, attempt.UserAgent, attempt.Type)
 	return err
 }
 
 // LoadLatestAuthenticationLogs retrieve the latest marks from the authentication log.
 func (p *SQLProvider) LoadLatestAuthenticationLogs(username string, fromDate time.Time) ([]models.AuthenticationAttempt, error) {
-	var t int64
+	var (
+		t                             int64
+		remoteIP, userAgent, authType sql.NullString
+	)
 
 	rows, err := p.db.Query(p.sqlGetLatestAuthenticationLogs, fromDate.Unix(), username)
 
@@ -271,8 +703,11 @@ func (p *SQLProvider) LoadLatestAuthenticationLogs(username string, fromDate tim
 		attempt := models.AuthenticationAttempt{
 			Username: username,
 		}
-		err = rows.Scan(&attempt.Successful, &t)
+		err = rows.Scan(&attempt.Successful, &t, &remoteIP, &userAgent, &authType)
 		attempt.Time = time.Unix(t, 0)
+		attempt.RemoteIP = remoteIP.String
+		attempt.UserAgent = userAgent.String
+		attempt.Type = authType.String
 
 		if err != nil {
 			return nil, err
@@ -283,3 +718,945 @@ func (p *SQLProvider) LoadLatestAuthenticationLogs(username string, fromDate tim
 
 	return attempts, nil
 }
+
+// LoadLastLogin load the time of the latest successful authentication of a user, the zero time is returned when the
+// user hasn't logged in since the authentication logs were last pruned.
+func (p *SQLProvider) LoadLastLogin(username string) (time.Time, error) {
+	var t int64
+
+	if err := p.db.QueryRow(p.sqlGetLastLogin, username, true).Scan(&t); err != nil {
+		if err == sql.ErrNoRows {
+			return time.Time{}, nil
+		}
+
+		return time.Time{}, err
+	}
+
+	return time.Unix(t, 0), nil
+}
+
+// DeleteFailedAuthenticationLogs delete the failed attempts of the authentication log of a given user, which lifts the
+// bans of the regulation, and returns the number of attempts deleted.
+func (p *SQLProvider) DeleteFailedAuthenticationLogs(username string) (int64, error) {
+	result, err := p.db.Exec(p.sqlDeleteFailedAuthenticationLogs, username, false)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// AppendUserEvent append an event to the events of a user.
+func (p *SQLProvider) AppendUserEvent(event models.UserEvent) error {
+	_, err := p.db.Exec(p.sqlInsertUserEvent, event.Username, event.Type, event.Successful, event.Time.Unix(),
+		event.RemoteIP, event.UserAgent)
+	return err
+}
+
+// LoadUserEvents retrieve the events of a user including the authentication attempts from the most recent to the
+// oldest. The events can be filtered by type, all events are retrieved when the type is empty.
+func (p *SQLProvider) LoadUserEvents(username, eventType string, limit, offset int) ([]models.UserEvent, error) {
+	var (
+		t                   int64
+		remoteIP, userAgent sql.NullString
+	)
+
+	rows, err := p.db.Query(p.sqlGetUserEvents, username, eventType, eventType, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	events := make([]models.UserEvent, 0, limit)
+
+	for rows.Next() {
+		event := models.UserEvent{
+			Username: username,
+		}
+
+		if err = rows.Scan(&event.Type, &event.Successful, &t, &remoteIP, &userAgent); err != nil {
+			return nil, err
+		}
+
+		event.Time = time.Unix(t, 0)
+		event.RemoteIP = remoteIP.String
+		event.UserAgent = userAgent.String
+
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// LoadNotificationThrottle load the throttling state of a kind of notification sent to a user, the zero state is
+// returned when no notification of this kind has been sent to the user.
+func (p *SQLProvider) LoadNotificationThrottle(username, kind string) (models.NotificationThrottle, error) {
+	throttle := models.NotificationThrottle{Username: username, Kind: kind}
+
+	var lastSentAt, pendingSince int64
+
+	err := p.db.QueryRow(p.sqlGetNotificationThrottle, username, kind).Scan(&lastSentAt, &throttle.Pending, &pendingSince)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return throttle, nil
+		}
+
+		return throttle, err
+	}
+
+	throttle.LastSentAt, throttle.PendingSince = unixToTime(lastSentAt), unixToTime(pendingSince)
+
+	return throttle, nil
+}
+
+// SaveNotificationThrottle save the throttling state of a kind of notification sent to a user.
+func (p *SQLProvider) SaveNotificationThrottle(throttle models.NotificationThrottle) error {
+	_, err := p.db.Exec(p.sqlUpsertNotificationThrottle, throttle.Username, throttle.Kind,
+		timeToUnix(throttle.LastSentAt), throttle.Pending, timeToUnix(throttle.PendingSince))
+
+	return err
+}
+
+// LoadPendingNotificationThrottles load the throttling states having pending notifications.
+func (p *SQLProvider) LoadPendingNotificationThrottles() ([]models.NotificationThrottle, error) {
+	rows, err := p.db.Query(p.sqlGetPendingNotificationThrottles)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var throttles []models.NotificationThrottle
+
+	for rows.Next() {
+		var (
+			throttle                 models.NotificationThrottle
+			lastSentAt, pendingSince int64
+		)
+
+		if err = rows.Scan(&throttle.Username, &throttle.Kind, &lastSentAt, &throttle.Pending, &pendingSince); err != nil {
+			return nil, err
+		}
+
+		throttle.LastSentAt, throttle.PendingSince = unixToTime(lastSentAt), unixToTime(pendingSince)
+
+		throttles = append(throttles, throttle)
+	}
+
+	return throttles, rows.Err()
+}
+
+// timeToUnix converts the time to a unix timestamp, the zero time is converted to 0.
+func timeToUnix(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+
+	return t.Unix()
+}
+
+// unixToTime converts the unix timestamp to a time, 0 is converted to the zero time.
+func unixToTime(timestamp int64) time.Time {
+	if timestamp == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(timestamp, 0)
+}
+
+// SaveAnnouncementAcknowledgment save the time a user acknowledged an announcement in the database.
+func (p *SQLProvider) SaveAnnouncementAcknowledgment(username, announcementID string, acknowledgedAt time.Time) error {
+	_, err := p.db.Exec(p.sqlUpsertAnnouncementAcknowledgment, username, announcementID, acknowledgedAt.Unix())
+	return err
+}
+
+// LoadAnnouncementAcknowledgment load the time a user acknowledged an announcement from the database, the zero time is
+// returned when the user hasn't acknowledged it.
+func (p *SQLProvider) LoadAnnouncementAcknowledgment(username, announcementID string) (acknowledgedAt time.Time, err error) {
+	err = p.read(func(db *sql.DB) (found bool, err error) {
+		var acknowledged int64
+
+		if err = db.QueryRow(p.sqlGetAnnouncementAcknowledgment, username, announcementID).Scan(&acknowledged); err != nil {
+			if err == sql.ErrNoRows {
+				return false, nil
+			}
+
+			return false, err
+		}
+
+		acknowledgedAt = time.Unix(acknowledged, 0)
+
+		return true, nil
+	})
+
+	return acknowledgedAt, err
+}
+
+// SaveBackupCodes replace the backup codes of a user with the given hashes of new codes in a single transaction.
+func (p *SQLProvider) SaveBackupCodes(username string, codeHashes []string, createdAt time.Time) error {
+	tx, err := p.begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(p.sqlDeleteBackupCodes, username); err != nil {
+		return p.rollback(tx, err)
+	}
+
+	for _, codeHash := range codeHashes {
+		if _, err = tx.Exec(p.sqlInsertBackupCode, username, codeHash, createdAt.Unix()); err != nil {
+			return p.rollback(tx, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ConsumeBackupCode delete the backup code of a user with the given hash so it can't be used again. ErrNoBackupCode is
+// returned when the user has no such code, including when it has already been used.
+func (p *SQLProvider) ConsumeBackupCode(username, codeHash string) error {
+	result, err := p.db.Exec(p.sqlDeleteBackupCode, username, codeHash)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if deleted == 0 {
+		return ErrNoBackupCode
+	}
+
+	return nil
+}
+
+// LoadBackupCodeHashes load the hashes of the backup codes of a user which haven't been used yet. They're loaded from
+// the primary database as the code matching one of them is consumed right after.
+func (p *SQLProvider) LoadBackupCodeHashes(username string) (codeHashes []string, err error) {
+	rows, err := p.db.Query(p.sqlSelectBackupCodeHashes, username)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var codeHash string
+
+		if err = rows.Scan(&codeHash); err != nil {
+			return nil, err
+		}
+
+		codeHashes = append(codeHashes, codeHash)
+	}
+
+	return codeHashes, rows.Err()
+}
+
+// CountBackupCodes count the backup codes of a user which haven't been used yet.
+func (p *SQLProvider) CountBackupCodes(username string) (count int, err error) {
+	err = p.read(func(db *sql.DB) (found bool, err error) {
+		if err = db.QueryRow(p.sqlCountBackupCodes, username).Scan(&count); err != nil {
+			return false, err
+		}
+
+		return count != 0, nil
+	})
+
+	return count, err
+}
+
+// SaveAdminAPIToken save a new admin API token in the database.
+func (p *SQLProvider) SaveAdminAPIToken(token models.AdminAPIToken) error {
+	var expiresAt int64
+	if !token.ExpiresAt.IsZero() {
+		expiresAt = token.ExpiresAt.Unix()
+	}
+
+	_, err := p.db.Exec(p.sqlInsertAdminAPIToken, token.ID, token.Description, token.TokenHash,
+		strings.Join(token.Scopes, " "), token.CreatedAt.Unix(), expiresAt)
+
+	return err
+}
+
+// LoadAdminAPIToken load the admin API token with the given hash, ErrNoAdminAPIToken is returned when the token is
+// unknown or has been revoked. It's always read from the primary database so a revoked token can't be used anymore.
+func (p *SQLProvider) LoadAdminAPIToken(tokenHash string) (*models.AdminAPIToken, error) {
+	rows, err := p.db.Query(p.sqlGetAdminAPITokenByHash, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	tokens, err := scanAdminAPITokens(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tokens) == 0 {
+		return nil, ErrNoAdminAPIToken
+	}
+
+	return &tokens[0], nil
+}
+
+// SavePushEnrollment save the enrollment state of a user with the push notification provider.
+func (p *SQLProvider) SavePushEnrollment(enrollment models.PushEnrollment) error {
+	_, err := p.db.Exec(p.sqlUpsertPushEnrollment, enrollment.Username, enrollment.Provider, enrollment.Status,
+		enrollment.Devices, enrollment.CheckedAt.Unix())
+
+	return err
+}
+
+// LoadPushEnrollment load the enrollment state of a user with the push notification provider. ErrNoPushEnrollment is
+// returned when it has never been checked.
+func (p *SQLProvider) LoadPushEnrollment(username string) (*models.PushEnrollment, error) {
+	enrollment := models.PushEnrollment{Username: username}
+
+	var checkedAt int64
+
+	err := p.db.QueryRow(p.sqlGetPushEnrollment, username).Scan(&enrollment.Provider, &enrollment.Status, &enrollment.Devices, &checkedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNoPushEnrollment
+		}
+
+		return nil, err
+	}
+
+	enrollment.CheckedAt = time.Unix(checkedAt, 0)
+
+	return &enrollment, nil
+}
+
+// LoadAdminAPITokens load all the admin API tokens, oldest first.
+func (p *SQLProvider) LoadAdminAPITokens() (tokens []models.AdminAPIToken, err error) {
+	err = p.read(func(db *sql.DB) (found bool, err error) {
+		rows, err := db.Query(p.sqlGetAdminAPITokens)
+		if err != nil {
+			return false, err
+		}
+
+		defer rows.Close()
+
+		if tokens, err = scanAdminAPITokens(rows); err != nil {
+			return false, err
+		}
+
+		return len(tokens) != 0, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// UpdateAdminAPITokenLastUsed update the time an admin API token was last used.
+func (p *SQLProvider) UpdateAdminAPITokenLastUsed(id string, lastUsedAt time.Time) error {
+	_, err := p.db.Exec(p.sqlUpdateAdminAPITokenLastUsed, lastUsedAt.Unix(), id)
+	return err
+}
+
+// DeleteAdminAPIToken delete the admin API token with the given ID so it can't be used anymore, ErrNoAdminAPIToken is
+// returned when there is no such token.
+func (p *SQLProvider) DeleteAdminAPIToken(id string) error {
+	return p.deleteOne(ErrNoAdminAPIToken, p.sqlDeleteAdminAPIToken, id)
+}
+
+// deleteOne runs the delete query and returns errNotFound when it didn't delete any row.
+func (p *SQLProvider) deleteOne(errNotFound error, query string, args ...interface{}) error {
+	result, err := p.db.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if deleted == 0 {
+		return errNotFound
+	}
+
+	return nil
+}
+
+// SaveSecondFactorReset save a new pending second factor reset.
+func (p *SQLProvider) SaveSecondFactorReset(reset models.SecondFactorReset) error {
+	_, err := p.db.Exec(p.sqlInsertSecondFactorReset, reset.ID, reset.Username, reset.RequestedBy, reset.Reason,
+		reset.Approval, reset.ApprovalTokenHash, models.SecondFactorResetPending, reset.RequestedAt.Unix(), reset.ExpiresAt.Unix())
+
+	return err
+}
+
+// LoadSecondFactorReset load the second factor reset with the given ID, ErrNoSecondFactorReset is returned when it's
+// unknown. It's always read from the primary database so a decided reset isn't seen as pending.
+func (p *SQLProvider) LoadSecondFactorReset(id string) (*models.SecondFactorReset, error) {
+	rows, err := p.db.Query(p.sqlGetSecondFactorReset, id)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	resets, err := scanSecondFactorResets(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resets) == 0 {
+		return nil, ErrNoSecondFactorReset
+	}
+
+	return &resets[0], nil
+}
+
+// LoadPendingSecondFactorResets load the second factor resets which can still be approved at the given time, the
+// oldest first.
+func (p *SQLProvider) LoadPendingSecondFactorResets(now time.Time) ([]models.SecondFactorReset, error) {
+	rows, err := p.db.Query(p.sqlGetPendingSecondFactorResets, models.SecondFactorResetPending, now.Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	return scanSecondFactorResets(rows)
+}
+
+// ApproveSecondFactorReset approve a pending second factor reset of a user and delete the second factor devices of the
+// user in a single transaction. ErrNoSecondFactorReset is returned when the reset isn't pending anymore, e.g. when it
+// has been approved concurrently, in which case no device is deleted.
+func (p *SQLProvider) ApproveSecondFactorReset(id, username, approvedBy string, approvedAt time.Time) error {
+	tx, err := p.begin()
+	if err != nil {
+		return err
+	}
+
+	if err = p.decideSecondFactorReset(tx, id, username, models.SecondFactorResetApproved, approvedBy, approvedAt); err != nil {
+		return p.rollback(tx, err)
+	}
+
+	for _, statement := range p.sqlDeleteSecondFactorData {
+		if _, err = tx.Exec(statement, username); err != nil {
+			return p.rollback(tx, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// RejectSecondFactorReset reject a pending second factor reset of a user, ErrNoSecondFactorReset is returned when the
+// reset isn't pending anymore.
+func (p *SQLProvider) RejectSecondFactorReset(id, username, rejectedBy string, rejectedAt time.Time) error {
+	return p.decideSecondFactorReset(p.db, id, username, models.SecondFactorResetRejected, rejectedBy, rejectedAt)
+}
+
+func (p *SQLProvider) decideSecondFactorReset(tx transaction, id, username, status, decidedBy string, decidedAt time.Time) error {
+	result, err := tx.Exec(p.sqlUpdateSecondFactorResetDecision, status, decidedBy, decidedAt.Unix(),
+		id, username, models.SecondFactorResetPending, decidedAt.Unix())
+	if err != nil {
+		return err
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if updated == 0 {
+		return ErrNoSecondFactorReset
+	}
+
+	return nil
+}
+
+// SaveAccessGrant save a new access grant.
+func (p *SQLProvider) SaveAccessGrant(grant models.AccessGrant) error {
+	_, err := p.db.Exec(p.sqlInsertAccessGrant, grant.ID, grant.Username, grant.TargetURL, grant.Description,
+		grant.CreatedAt.Unix(), grant.ExpiresAt.Unix())
+
+	return err
+}
+
+// LoadAccessGrant load the access grant with the given ID, ErrNoAccessGrant is returned when it's unknown or has been
+// revoked. It's always read from the primary database so a revoked grant can't be used anymore.
+func (p *SQLProvider) LoadAccessGrant(id string) (*models.AccessGrant, error) {
+	rows, err := p.db.Query(p.sqlGetAccessGrant, id)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	grants, err := scanAccessGrants(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(grants) == 0 {
+		return nil, ErrNoAccessGrant
+	}
+
+	return &grants[0], nil
+}
+
+// LoadAccessGrants load the access grants which haven't expired at the given time, the oldest first.
+func (p *SQLProvider) LoadAccessGrants(now time.Time) ([]models.AccessGrant, error) {
+	return p.queryAccessGrants(p.sqlGetAccessGrants, now.Unix())
+}
+
+// LoadUserAccessGrants load the access grants created by a user which haven't expired at the given time, the oldest
+// first.
+func (p *SQLProvider) LoadUserAccessGrants(username string, now time.Time) ([]models.AccessGrant, error) {
+	return p.queryAccessGrants(p.sqlGetUserAccessGrants, username, now.Unix())
+}
+
+// DeleteAccessGrant delete the access grant with the given ID so it can't be used anymore, ErrNoAccessGrant is returned
+// when there is no such grant.
+func (p *SQLProvider) DeleteAccessGrant(id string) error {
+	return p.deleteOne(ErrNoAccessGrant, p.sqlDeleteAccessGrant, id)
+}
+
+func (p *SQLProvider) queryAccessGrants(query string, args ...interface{}) ([]models.AccessGrant, error) {
+	rows, err := p.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	return scanAccessGrants(rows)
+}
+
+func scanAccessGrants(rows *sql.Rows) ([]models.AccessGrant, error) {
+	grants := make([]models.AccessGrant, 0, 1)
+
+	for rows.Next() {
+		var (
+			grant                models.AccessGrant
+			description          sql.NullString
+			createdAt, expiresAt int64
+		)
+
+		if err := rows.Scan(&grant.ID, &grant.Username, &grant.TargetURL, &description, &createdAt, &expiresAt); err != nil {
+			return nil, err
+		}
+
+		grant.Description = description.String
+		grant.CreatedAt = time.Unix(createdAt, 0)
+		grant.ExpiresAt = time.Unix(expiresAt, 0)
+
+		grants = append(grants, grant)
+	}
+
+	return grants, rows.Err()
+}
+
+// SaveBreakGlassActivation save the first use of a break-glass account.
+func (p *SQLProvider) SaveBreakGlassActivation(activation models.BreakGlassActivation) error {
+	_, err := p.db.Exec(p.sqlInsertBreakGlassActivation, activation.Username, activation.ActivatedAt.Unix())
+
+	return err
+}
+
+// LoadBreakGlassActivation load the first use of a break-glass account, ErrNoBreakGlassActivation is returned when it
+// hasn't been used since its last reset. It's always read from the primary database so a disabled account can't be used
+// anymore.
+func (p *SQLProvider) LoadBreakGlassActivation(username string) (*models.BreakGlassActivation, error) {
+	rows, err := p.db.Query(p.sqlGetBreakGlassActivation, username)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	activations, err := scanBreakGlassActivations(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(activations) == 0 {
+		return nil, ErrNoBreakGlassActivation
+	}
+
+	return &activations[0], nil
+}
+
+// LoadBreakGlassActivations load the first use of all the break-glass accounts used since their last reset, the oldest
+// first.
+func (p *SQLProvider) LoadBreakGlassActivations() ([]models.BreakGlassActivation, error) {
+	rows, err := p.db.Query(p.sqlGetBreakGlassActivations)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	return scanBreakGlassActivations(rows)
+}
+
+// DeleteBreakGlassActivation delete the first use of a break-glass account so its window starts again on its next use,
+// ErrNoBreakGlassActivation is returned when it hasn't been used since its last reset.
+func (p *SQLProvider) DeleteBreakGlassActivation(username string) error {
+	return p.deleteOne(ErrNoBreakGlassActivation, p.sqlDeleteBreakGlassActivation, username)
+}
+
+func scanBreakGlassActivations(rows *sql.Rows) ([]models.BreakGlassActivation, error) {
+	activations := make([]models.BreakGlassActivation, 0, 1)
+
+	for rows.Next() {
+		var (
+			activation  models.BreakGlassActivation
+			activatedAt int64
+		)
+
+		if err := rows.Scan(&activation.Username, &activatedAt); err != nil {
+			return nil, err
+		}
+
+		activation.ActivatedAt = time.Unix(activatedAt, 0)
+
+		activations = append(activations, activation)
+	}
+
+	return activations, rows.Err()
+}
+
+// SaveOIDCConsent save the consent of a user to a client, it replaces the consent previously remembered for the client.
+func (p *SQLProvider) SaveOIDCConsent(consent models.OIDCConsent) error {
+	_, err := p.db.Exec(p.sqlUpsertOIDCConsent, consent.Username, consent.ClientID, strings.Join(consent.Scopes, " "),
+		strings.Join(consent.Audience, " "), consent.CreatedAt.Unix(), consent.ExpiresAt.Unix())
+
+	return err
+}
+
+// LoadOIDCConsent load the consent of a user to a client which hasn't expired at the given time, ErrNoOIDCConsent is
+// returned when there is none.
+func (p *SQLProvider) LoadOIDCConsent(username, clientID string, now time.Time) (*models.OIDCConsent, error) {
+	consents, err := p.queryOIDCConsents(p.sqlGetOIDCConsent, username, clientID, now.Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	if len(consents) == 0 {
+		return nil, ErrNoOIDCConsent
+	}
+
+	return &consents[0], nil
+}
+
+// LoadUserOIDCConsents load the consents of a user which haven't expired at the given time, the oldest first.
+func (p *SQLProvider) LoadUserOIDCConsents(username string, now time.Time) ([]models.OIDCConsent, error) {
+	return p.queryOIDCConsents(p.sqlGetUserOIDCConsents, username, now.Unix())
+}
+
+// DeleteOIDCConsent delete the consent of a user to a client so it's asked again, ErrNoOIDCConsent is returned when
+// there is none.
+func (p *SQLProvider) DeleteOIDCConsent(username, clientID string) error {
+	return p.deleteOne(ErrNoOIDCConsent, p.sqlDeleteOIDCConsent, username, clientID)
+}
+
+func (p *SQLProvider) queryOIDCConsents(query string, args ...interface{}) ([]models.OIDCConsent, error) {
+	rows, err := p.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	consents := make([]models.OIDCConsent, 0, 1)
+
+	for rows.Next() {
+		var (
+			consent              models.OIDCConsent
+			scopes, audience     string
+			createdAt, expiresAt int64
+		)
+
+		if err := rows.Scan(&consent.Username, &consent.ClientID, &scopes, &audience, &createdAt, &expiresAt); err != nil {
+			return nil, err
+		}
+
+		consent.Scopes = strings.Fields(scopes)
+		consent.Audience = strings.Fields(audience)
+		consent.CreatedAt = time.Unix(createdAt, 0)
+		consent.ExpiresAt = time.Unix(expiresAt, 0)
+
+		consents = append(consents, consent)
+	}
+
+	return consents, rows.Err()
+}
+
+// SaveOIDCClient save an OpenID Connect client registered with the dynamic registration endpoint.
+func (p *SQLProvider) SaveOIDCClient(client models.OIDCClient) error {
+	_, err := p.db.Exec(p.sqlInsertOIDCClient, client.ID, client.Description, client.SecretHash, client.Policy,
+		strings.Join(client.RedirectURIs, " "), strings.Join(client.GrantTypes, " "), strings.Join(client.ResponseTypes, " "),
+		strings.Join(client.Scopes, " "), strings.Join(client.PostLogoutRedirectURIs, " "), client.BackChannelLogoutURI,
+		client.FrontChannelLogoutURI, client.RegisteredBy, client.CreatedAt.Unix())
+
+	return err
+}
+
+// LoadOIDCClients load the registered OpenID Connect clients, the oldest first.
+func (p *SQLProvider) LoadOIDCClients() ([]models.OIDCClient, error) {
+	rows, err := p.db.Query(p.sqlGetOIDCClients)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	clients := make([]models.OIDCClient, 0, 1)
+
+	for rows.Next() {
+		var (
+			client                                                      models.OIDCClient
+			redirectURIs, grantTypes, responseTypes, scopes, logoutURIs string
+			createdAt                                                   int64
+		)
+
+		if err := rows.Scan(&client.ID, &client.Description, &client.SecretHash, &client.Policy, &redirectURIs, &grantTypes,
+			&responseTypes, &scopes, &logoutURIs, &client.BackChannelLogoutURI, &client.FrontChannelLogoutURI,
+			&client.RegisteredBy, &createdAt); err != nil {
+			return nil, err
+		}
+
+		client.RedirectURIs = strings.Fields(redirectURIs)
+		client.GrantTypes = strings.Fields(grantTypes)
+		client.ResponseTypes = strings.Fields(responseTypes)
+		client.Scopes = strings.Fields(scopes)
+		client.PostLogoutRedirectURIs = strings.Fields(logoutURIs)
+		client.CreatedAt = time.Unix(createdAt, 0)
+
+		clients = append(clients, client)
+	}
+
+	return clients, rows.Err()
+}
+
+// DeleteOIDCClient delete a registered OpenID Connect client, ErrNoOIDCClient is returned when there is none.
+func (p *SQLProvider) DeleteOIDCClient(id string) error {
+	return p.deleteOne(ErrNoOIDCClient, p.sqlDeleteOIDCClient, id)
+}
+
+func scanSecondFactorResets(rows *sql.Rows) ([]models.SecondFactorReset, error) {
+	resets := make([]models.SecondFactorReset, 0, 1)
+
+	for rows.Next() {
+		var (
+			reset                  models.SecondFactorReset
+			tokenHash, decidedBy   sql.NullString
+			requestedAt, expiresAt int64
+			decidedAt              sql.NullInt64
+		)
+
+		if err := rows.Scan(&reset.ID, &reset.Username, &reset.RequestedBy, &reset.Reason, &reset.Approval, &tokenHash,
+			&reset.Status, &decidedBy, &requestedAt, &expiresAt, &decidedAt); err != nil {
+			return nil, err
+		}
+
+		reset.ApprovalTokenHash = tokenHash.String
+		reset.DecidedBy = decidedBy.String
+		reset.RequestedAt = time.Unix(requestedAt, 0)
+		reset.ExpiresAt = time.Unix(expiresAt, 0)
+
+		if decidedAt.Valid {
+			reset.DecidedAt = time.Unix(decidedAt.Int64, 0)
+		}
+
+		resets = append(resets, reset)
+	}
+
+	return resets, rows.Err()
+}
+
+func scanAdminAPITokens(rows *sql.Rows) ([]models.AdminAPIToken, error) {
+	tokens := make([]models.AdminAPIToken, 0, 1)
+
+	for rows.Next() {
+		var (
+			token                models.AdminAPIToken
+			scopes               string
+			createdAt, expiresAt int64
+			lastUsedAt           sql.NullInt64
+		)
+
+		if err := rows.Scan(&token.ID, &token.Description, &token.TokenHash, &scopes, &createdAt, &expiresAt, &lastUsedAt); err != nil {
+			return nil, err
+		}
+
+		token.Scopes = strings.Fields(scopes)
+		token.CreatedAt = time.Unix(createdAt, 0)
+
+		if expiresAt != 0 {
+			token.ExpiresAt = time.Unix(expiresAt, 0)
+		}
+
+		if lastUsedAt.Valid {
+			token.LastUsedAt = time.Unix(lastUsedAt.Int64, 0)
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	return tokens, rows.Err()
+}
+
+// LoadUsernames load the usernames of the users known to the storage in alphabetical order.
+func (p *SQLProvider) LoadUsernames() ([]string, error) {
+	rows, err := p.db.Query(p.sqlGetUsernames)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var usernames []string
+
+	for rows.Next() {
+		var username string
+
+		if err = rows.Scan(&username); err != nil {
+			return nil, err
+		}
+
+		usernames = append(usernames, username)
+	}
+
+	return usernames, rows.Err()
+}
+
+// PurgeUser delete all the data of a user and revoke the sessions of the user authenticated before the given time in a
+// single transaction.
+func (p *SQLProvider) PurgeUser(username string, revokedAt time.Time) error {
+	tx, err := p.begin()
+	if err != nil {
+		return err
+	}
+
+	for _, statement := range p.sqlDeleteUserData {
+		if _, err = tx.Exec(statement, username); err != nil {
+			return p.rollback(tx, err)
+		}
+	}
+
+	if _, err = tx.Exec(p.sqlUpsertSessionRevocation, username, revokedAt.Unix()); err != nil {
+		return p.rollback(tx, err)
+	}
+
+	return tx.Commit()
+}
+
+// RevokeSessions revoke the sessions of a user authenticated before the given time.
+func (p *SQLProvider) RevokeSessions(username string, revokedAt time.Time) error {
+	_, err := p.db.Exec(p.sqlUpsertSessionRevocation, username, revokedAt.Unix())
+	return err
+}
+
+// LoadSessionsRevocation load the time the sessions of a user have been revoked at, the zero time is returned when
+// they have never been revoked.
+func (p *SQLProvider) LoadSessionsRevocation(username string) (time.Time, error) {
+	var revokedAt int64
+
+	if err := p.db.QueryRow(p.sqlGetSessionRevocation, username).Scan(&revokedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return time.Time{}, nil
+		}
+
+		return time.Time{}, err
+	}
+
+	return time.Unix(revokedAt, 0), nil
+}
+
+func (p *SQLProvider) rollback(tx sqlTransaction, err error) error {
+	if rollbackErr := tx.Rollback(); rollbackErr != nil {
+		return fmt.Errorf("rollback error occurred: %v (inner error %v)", rollbackErr, err)
+	}
+
+	return err
+}
+
+// PruneIdentityVerificationTokens delete the identity verification tokens which expired before the given time, the
+// tokens saved without an expiration time are deleted too.
+func (p *SQLProvider) PruneIdentityVerificationTokens(now time.Time) (int64, error) {
+	return p.prune(p.sqlDeleteExpiredIdentityVerificationTokens, now)
+}
+
+// PruneAuthenticationLogs delete the marks of the authentication log older than the given time.
+func (p *SQLProvider) PruneAuthenticationLogs(before time.Time) (int64, error) {
+	return p.prune(p.sqlDeleteAuthenticationLogsBefore, before)
+}
+
+// PruneSessionRevocations delete the session revocations which happened before the given time.
+func (p *SQLProvider) PruneSessionRevocations(before time.Time) (int64, error) {
+	return p.prune(p.sqlDeleteSessionRevocationsBefore, before)
+}
+
+// PruneLoginStates delete the nonces of the login states which expired before the given time.
+func (p *SQLProvider) PruneLoginStates(now time.Time) (int64, error) {
+	return p.prune(p.sqlDeleteExpiredLoginStates, now)
+}
+
+// PruneAccessGrants delete the access grants which expired before the given time.
+func (p *SQLProvider) PruneAccessGrants(now time.Time) (int64, error) {
+	return p.prune(p.sqlDeleteExpiredAccessGrants, now)
+}
+
+// PruneOIDCConsents delete the OpenID Connect consents which expired before the given time.
+func (p *SQLProvider) PruneOIDCConsents(now time.Time) (int64, error) {
+	return p.prune(p.sqlDeleteExpiredOIDCConsents, now)
+}
+
+func (p *SQLProvider) prune(statement string, before time.Time) (int64, error) {
+	result, err := p.db.Exec(statement, before.Unix())
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// splitReadReplicaAddress splits the address of a read replica into its host and port, the port of the primary
+// database is used when the address doesn't have one.
+func splitReadReplicaAddress(address string, defaultPort int) (host string, port int) {
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return address, defaultPort
+	}
+
+	if port, err = strconv.Atoi(portStr); err != nil {
+		return address, defaultPort
+	}
+
+	return host, port
+}
+
+// configureSQLPool tunes the pool of connections to the database, the values which aren't set keep the defaults of
+// database/sql.
+func configureSQLPool(db *sql.DB, configuration schema.SQLStoragePoolConfiguration) {
+	if configuration.MaxOpenConnections > 0 {
+		db.SetMaxOpenConns(configuration.MaxOpenConnections)
+	}
+
+	if configuration.MaxIdleConnections > 0 {
+		db.SetMaxIdleConns(configuration.MaxIdleConnections)
+	}
+
+	if lifetime, err := utils.ParseDurationString(configuration.ConnectionMaxLifetime); err == nil && lifetime > 0 {
+		db.SetConnMaxLifetime(lifetime)
+	}
+
+	if idleTime, err := utils.ParseDurationString(configuration.ConnectionMaxIdleTime); err == nil && idleTime > 0 {
+		db.SetConnMaxIdleTime(idleTime)
+	}
+}
@@ -1,9 +1,12 @@
 package storage
 
 import (
+	"database/sql"
 	"database/sql/driver"
 	"encoding/base64"
 	"fmt"
+	"path/filepath"
+	"regexp"
 	"sort"
 	"testing"
 	"time"
@@ -13,10 +16,62 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/configuration/schema"
 	"github.com/authelia/authelia/internal/models"
 )
 
-const currentSchemaMockSchemaVersion = "1"
+var currentSchemaMockSchemaVersion = storageSchemaCurrentVersion.ToString()
+
+// expectSchemaUpgrades adds the expectations for the upgrades following the provided schema version.
+func expectSchemaUpgrades(mock sqlmock.Sqlmock, from SchemaVersion) {
+	for version := from + 1; version <= storageSchemaCurrentVersion; version++ {
+		keys := make([]string, 0, len(sqlUpgradeCreateTableStatements[version]))
+		for k := range sqlUpgradeCreateTableStatements[version] {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+
+		for _, table := range keys {
+			mock.ExpectExec(
+				fmt.Sprintf("CREATE TABLE %s .*", table)).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+		}
+
+		for _, statement := range sqlUpgradesStatements[version] {
+			mock.ExpectExec(regexp.QuoteMeta(statement)).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+		}
+
+		mock.ExpectExec(
+			fmt.Sprintf("REPLACE INTO %s \\(category, key_name, value\\) VALUES \\(\\?, \\?, \\?\\)", configTableName)).
+			WithArgs("schema", "version", version.ToString()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+	}
+}
+
+// expectSchemaUpToDate adds the expectations for the check of a schema which is up to date.
+func expectSchemaUpToDate(mock sqlmock.Sqlmock) {
+	rows := sqlmock.NewRows([]string{"name"})
+
+	for version := SchemaVersion(1); version <= storageSchemaCurrentVersion; version++ {
+		for table := range sqlUpgradeCreateTableStatements[version] {
+			if table != totpSecretsTableName {
+				rows.AddRow(table)
+			}
+		}
+	}
+
+	mock.ExpectQuery(
+		"SELECT name FROM sqlite_master WHERE type='table'").
+		WillReturnRows(rows)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT value FROM %s WHERE category=\\? AND key_name=\\?", configTableName)).
+		WithArgs("schema", "version").
+		WillReturnRows(sqlmock.NewRows([]string{"value"}).
+			AddRow(currentSchemaMockSchemaVersion))
+}
 
 func TestSQLInitializeDatabase(t *testing.T) {
 	provider, mock := NewSQLMockProvider()
@@ -50,6 +105,8 @@ func TestSQLInitializeDatabase(t *testing.T) {
 		WithArgs("schema", "version", "1").
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
+	expectSchemaUpgrades(mock, 1)
+
 	mock.ExpectCommit()
 
 	err := provider.initialize(provider.db)
@@ -83,58 +140,112 @@ func TestSQLUpgradeDatabase(t *testing.T) {
 		WithArgs("schema", "version", "1").
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
+	expectSchemaUpgrades(mock, 1)
+
 	mock.ExpectCommit()
 
+	_, _, upgraded := provider.SchemaUpgrade()
+	assert.False(t, upgraded)
+
 	err := provider.initialize(provider.db)
 	assert.NoError(t, err)
+
+	from, to, upgraded := provider.SchemaUpgrade()
+	assert.True(t, upgraded)
+	assert.Equal(t, SchemaVersion(0), from)
+	assert.Equal(t, storageSchemaCurrentVersion, to)
 }
 
-func TestSQLProviderMethodsAuthenticationLogs(t *testing.T) {
+func TestSQLShouldRefuseNewerSchema(t *testing.T) {
 	provider, mock := NewSQLMockProvider()
 
 	mock.ExpectQuery(
 		"SELECT name FROM sqlite_master WHERE type='table'").
 		WillReturnRows(sqlmock.NewRows([]string{"name"}).
-			AddRow(userPreferencesTableName).
-			AddRow(identityVerificationTokensTableName).
-			AddRow(totpSecretsTableName).
-			AddRow(u2fDeviceHandlesTableName).
-			AddRow(authenticationLogsTableName).
 			AddRow(configTableName))
 
-	args := []driver.Value{"schema", "version"}
 	mock.ExpectQuery(
 		fmt.Sprintf("SELECT value FROM %s WHERE category=\\? AND key_name=\\?", configTableName)).
-		WithArgs(args...).
+		WithArgs("schema", "version").
 		WillReturnRows(sqlmock.NewRows([]string{"value"}).
-			AddRow("1"))
+			AddRow((storageSchemaCurrentVersion + 1).ToString()))
+
+	err := provider.initialize(provider.db)
+	assert.EqualError(t, err, fmt.Sprintf("storage schema v%d is newer than the latest v%d supported by this version, it must be downgraded by the newer version first",
+		storageSchemaCurrentVersion+1, storageSchemaCurrentVersion))
+}
+
+func TestSQLShouldRefuseSchemaWithMissingTables(t *testing.T) {
+	provider, mock := NewSQLMockProvider()
+
+	rows := sqlmock.NewRows([]string{"name"})
+
+	for version := SchemaVersion(1); version <= storageSchemaCurrentVersion; version++ {
+		for table := range sqlUpgradeCreateTableStatements[version] {
+			if table != totpSecretsTableName && table != oidcClientsTableName && table != backupCodesTableName {
+				rows.AddRow(table)
+			}
+		}
+	}
+
+	mock.ExpectQuery(
+		"SELECT name FROM sqlite_master WHERE type='table'").
+		WillReturnRows(rows)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT value FROM %s WHERE category=\\? AND key_name=\\?", configTableName)).
+		WithArgs("schema", "version").
+		WillReturnRows(sqlmock.NewRows([]string{"value"}).
+			AddRow(currentSchemaMockSchemaVersion))
+
+	err := provider.initialize(provider.db)
+	assert.EqualError(t, err, fmt.Sprintf("storage schema v%d is missing the tables backup_codes, oidc_clients", storageSchemaCurrentVersion))
+}
+
+func TestSQLProvidersShouldNotShareCreateTableStatements(t *testing.T) {
+	expected := sqlUpgradeCreateTableStatements[SchemaVersion(1)][authenticationLogsTableName]
+
+	mysql := newMySQLProviderStatements(nil)
+	postgres := newPostgreSQLProviderStatements(nil)
+
+	assert.Equal(t, "CREATE TABLE %s (username VARCHAR(100), successful BOOL, time INTEGER, INDEX usr_time_idx (username, time))",
+		mysql.sqlUpgradesCreateTableStatements[SchemaVersion(1)][authenticationLogsTableName])
+	assert.Equal(t, expected, postgres.sqlUpgradesCreateTableStatements[SchemaVersion(1)][authenticationLogsTableName])
+	assert.NotContains(t, postgres.sqlUpgradesCreateTableStatements[SchemaVersion(1)][authenticationLogsTableName], "INDEX")
+	assert.NotContains(t, sqlUpgradeCreateTableStatements[SchemaVersion(1)][authenticationLogsTableName], "INDEX")
+}
+
+func TestSQLProviderMethodsAuthenticationLogs(t *testing.T) {
+	provider, mock := NewSQLMockProvider()
+
+	expectSchemaUpToDate(mock)
 
 	err := provider.initialize(provider.db)
 	assert.NoError(t, err)
 
 	attempts := []models.AuthenticationAttempt{
-		{Username: unitTestUser, Successful: true, Time: time.Unix(1577880001, 0)},
+		{Username: unitTestUser, Successful: true, Time: time.Unix(1577880001, 0), RemoteIP: "127.0.0.1", UserAgent: "Mozilla/5.0", Type: models.AuthenticationTypeFirstFactor},
 		{Username: unitTestUser, Successful: true, Time: time.Unix(1577880002, 0)},
-		{Username: unitTestUser, Successful: false, Time: time.Unix(1577880003, 0)},
+		{Username: unitTestUser, Successful: false, Time: time.Unix(1577880003, 0), Type: models.AuthenticationTypeTOTP},
 	}
 
-	rows := sqlmock.NewRows([]string{"successful", "time"})
+	rows := sqlmock.NewRows([]string{"successful", "time", "remote_ip", "user_agent", "auth_type"})
 
 	for id, attempt := range attempts {
-		args = []driver.Value{attempt.Username, attempt.Successful, attempt.Time.Unix()}
+		args := []driver.Value{attempt.Username, attempt.Successful, attempt.Time.Unix(), attempt.RemoteIP, attempt.UserAgent, attempt.Type}
 		mock.ExpectExec(
-			fmt.Sprintf("INSERT INTO %s \\(username, successful, time\\) VALUES \\(\\?, \\?, \\?\\)", authenticationLogsTableName)).
+			fmt.Sprintf("INSERT INTO %s \\(username, successful, time, remote_ip, user_agent, auth_type\\) VALUES \\(\\?, \\?, \\?, \\?, \\?, \\?\\)", authenticationLogsTableName)).
 			WithArgs(args...).
 			WillReturnResult(sqlmock.NewResult(int64(id), 1))
 
 		err := provider.AppendAuthenticationLog(attempt)
 		assert.NoError(t, err)
-		rows.AddRow(attempt.Successful, attempt.Time.Unix())
+		rows.AddRow(attempt.Successful, attempt.Time.Unix(), attempt.RemoteIP, attempt.UserAgent, attempt.Type)
 	}
 
-	args = []driver.Value{1577880000, unitTestUser}
+	args := []driver.Value{1577880000, unitTestUser}
 	mock.ExpectQuery(
-		fmt.Sprintf("SELECT successful, time FROM %s WHERE time>\\? AND username=\\? ORDER BY time DESC", authenticationLogsTableName)).
+		fmt.Sprintf("SELECT successful, time, remote_ip, user_agent, auth_type FROM %s WHERE time>\\? AND username=\\? ORDER BY time DESC", authenticationLogsTableName)).
 		WithArgs(args...).
 		WillReturnRows(rows)
 
@@ -145,43 +256,184 @@ func TestSQLProviderMethodsAuthenticationLogs(t *testing.T) {
 	assert.Equal(t, unitTestUser, results[0].Username)
 	assert.Equal(t, true, results[0].Successful)
 	assert.Equal(t, time.Unix(1577880001, 0), results[0].Time)
+	assert.Equal(t, "127.0.0.1", results[0].RemoteIP)
+	assert.Equal(t, "Mozilla/5.0", results[0].UserAgent)
+	assert.Equal(t, models.AuthenticationTypeFirstFactor, results[0].Type)
 	assert.Equal(t, unitTestUser, results[1].Username)
 	assert.Equal(t, true, results[1].Successful)
 	assert.Equal(t, time.Unix(1577880002, 0), results[1].Time)
 	assert.Equal(t, unitTestUser, results[2].Username)
 	assert.Equal(t, false, results[2].Successful)
 	assert.Equal(t, time.Unix(1577880003, 0), results[2].Time)
+	assert.Equal(t, models.AuthenticationTypeTOTP, results[2].Type)
 
 	// Test Blank Rows.
 	mock.ExpectQuery(
-		fmt.Sprintf("SELECT successful, time FROM %s WHERE time>\\? AND username=\\? ORDER BY time DESC", authenticationLogsTableName)).
+		fmt.Sprintf("SELECT successful, time, remote_ip, user_agent, auth_type FROM %s WHERE time>\\? AND username=\\? ORDER BY time DESC", authenticationLogsTableName)).
 		WithArgs(args...).
-		WillReturnRows(sqlmock.NewRows([]string{"successful", "time"}))
+		WillReturnRows(sqlmock.NewRows([]string{"successful", "time", "remote_ip", "user_agent", "auth_type"}))
 
 	results, err = provider.LoadLatestAuthenticationLogs(unitTestUser, after)
 	assert.NoError(t, err)
 	assert.Len(t, results, 0)
 }
 
-func TestSQLProviderMethodsPreferred(t *testing.T) {
+func TestSQLProviderMethodsUserEvents(t *testing.T) {
 	provider, mock := NewSQLMockProvider()
 
-	mock.ExpectQuery(
-		"SELECT name FROM sqlite_master WHERE type='table'").
-		WillReturnRows(sqlmock.NewRows([]string{"name"}).
-			AddRow(userPreferencesTableName).
-			AddRow(identityVerificationTokensTableName).
-			AddRow(totpSecretsTableName).
-			AddRow(u2fDeviceHandlesTableName).
-			AddRow(authenticationLogsTableName).
-			AddRow(configTableName))
+	expectSchemaUpToDate(mock)
 
-	args := []driver.Value{"schema", "version"}
-	mock.ExpectQuery(
-		fmt.Sprintf("SELECT value FROM %s WHERE category=\\? AND key_name=\\?", configTableName)).
-		WithArgs(args...).
-		WillReturnRows(sqlmock.NewRows([]string{"value"}).
-			AddRow(currentSchemaMockSchemaVersion))
+	err := provider.initialize(provider.db)
+	assert.NoError(t, err)
+
+	event := models.UserEvent{Username: unitTestUser, Type: models.UserEventTOTPRegistered, Successful: true, Time: time.Unix(1577880001, 0), RemoteIP: "127.0.0.1", UserAgent: "Mozilla/5.0"}
+
+	mock.ExpectExec(
+		fmt.Sprintf("INSERT INTO %s \\(username, event, successful, time, remote_ip, user_agent\\) VALUES \\(\\?, \\?, \\?, \\?, \\?, \\?\\)", userEventsTableName)).
+		WithArgs(unitTestUser, models.UserEventTOTPRegistered, true, int64(1577880001), "127.0.0.1", "Mozilla/5.0").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = provider.AppendUserEvent(event)
+	assert.NoError(t, err)
+
+	mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf(sqlUserEventsQuery, "?", "?", "?", "?", "?"))).
+		WithArgs(unitTestUser, "", "", 10, 20).
+		WillReturnRows(sqlmock.NewRows([]string{"event", "successful", "time", "remote_ip", "user_agent"}).
+			AddRow(models.UserEventTOTPRegistered, true, int64(1577880001), "127.0.0.1", "Mozilla/5.0").
+			AddRow(models.AuthenticationTypeFirstFactor, false, int64(1577880000), nil, nil))
+
+	events, err := provider.LoadUserEvents(unitTestUser, "", 10, 20)
+	assert.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, event, events[0])
+	assert.Equal(t, models.UserEvent{Username: unitTestUser, Type: models.AuthenticationTypeFirstFactor, Time: time.Unix(1577880000, 0)}, events[1])
+}
+
+func TestSQLProviderPostgreSQLUserEventsQuery(t *testing.T) {
+	// Each argument of LoadUserEvents is bound to its own numbered placeholder.
+	placeholders := map[string]bool{}
+	for _, placeholder := range regexp.MustCompile(`\$[0-9]+`).FindAllString(sqlUserEventsQueryPostgreSQL, -1) {
+		placeholders[placeholder] = true
+	}
+
+	assert.Equal(t, map[string]bool{"$1": true, "$2": true, "$3": true, "$4": true, "$5": true}, placeholders)
+	assert.Contains(t, sqlUserEventsQueryPostgreSQL, "username=$1 AND ($2='' OR event=$3) ORDER BY time DESC LIMIT $4 OFFSET $5")
+
+	provider, mock := NewSQLMockProvider()
+	provider.sqlGetUserEvents = sqlUserEventsQueryPostgreSQL
+
+	mock.ExpectQuery(regexp.QuoteMeta(sqlUserEventsQueryPostgreSQL)).
+		WithArgs(unitTestUser, models.UserEventTOTPRegistered, models.UserEventTOTPRegistered, 10, 20).
+		WillReturnRows(sqlmock.NewRows([]string{"event", "successful", "time", "remote_ip", "user_agent"}).
+			AddRow(models.UserEventTOTPRegistered, true, int64(1577880001), "127.0.0.1", "Mozilla/5.0"))
+
+	events, err := provider.LoadUserEvents(unitTestUser, models.UserEventTOTPRegistered, 10, 20)
+	assert.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, models.UserEventTOTPRegistered, events[0].Type)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLProviderMethodsPurgeUser(t *testing.T) {
+	provider, mock := NewSQLMockProvider()
+
+	expectSchemaUpToDate(mock)
+
+	err := provider.initialize(provider.db)
+	assert.NoError(t, err)
+
+	mock.ExpectBegin()
+
+	for _, table := range userDataTableNames {
+		mock.ExpectExec(fmt.Sprintf("DELETE FROM %s WHERE username=\\?", table)).
+			WithArgs(unitTestUser).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	mock.ExpectExec(fmt.Sprintf("REPLACE INTO %s \\(username, revoked_at\\) VALUES \\(\\?, \\?\\)", sessionRevocationsTableName)).
+		WithArgs(unitTestUser, int64(1577880001)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectCommit()
+
+	err = provider.PurgeUser(unitTestUser, time.Unix(1577880001, 0))
+	assert.NoError(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(fmt.Sprintf("DELETE FROM %s WHERE username=\\?", userPreferencesTableName)).
+		WithArgs(unitTestUser).
+		WillReturnError(fmt.Errorf("failed"))
+	mock.ExpectRollback()
+
+	err = provider.PurgeUser(unitTestUser, time.Unix(1577880001, 0))
+	assert.EqualError(t, err, "failed")
+
+	mock.ExpectQuery(fmt.Sprintf("SELECT revoked_at FROM %s WHERE username=\\?", sessionRevocationsTableName)).
+		WithArgs(unitTestUser).
+		WillReturnRows(sqlmock.NewRows([]string{"revoked_at"}).AddRow(int64(1577880001)))
+
+	revokedAt, err := provider.LoadSessionsRevocation(unitTestUser)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Unix(1577880001, 0), revokedAt)
+
+	mock.ExpectQuery(fmt.Sprintf("SELECT revoked_at FROM %s WHERE username=\\?", sessionRevocationsTableName)).
+		WithArgs("harry").
+		WillReturnRows(sqlmock.NewRows([]string{"revoked_at"}))
+
+	revokedAt, err = provider.LoadSessionsRevocation("harry")
+	assert.NoError(t, err)
+	assert.True(t, revokedAt.IsZero())
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLProviderMethodsPrune(t *testing.T) {
+	provider, mock := NewSQLMockProvider()
+
+	expectSchemaUpToDate(mock)
+
+	err := provider.initialize(provider.db)
+	assert.NoError(t, err)
+
+	mock.ExpectExec(fmt.Sprintf("DELETE FROM %s WHERE expires_at IS NULL OR expires_at<\\?", identityVerificationTokensTableName)).
+		WithArgs(int64(1577880001)).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	count, err := provider.PruneIdentityVerificationTokens(time.Unix(1577880001, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	mock.ExpectExec(fmt.Sprintf("DELETE FROM %s WHERE expires_at<\\?", loginStatesTableName)).
+		WithArgs(int64(1577880001)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	count, err = provider.PruneLoginStates(time.Unix(1577880001, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	mock.ExpectExec(fmt.Sprintf("DELETE FROM %s WHERE time<\\?", authenticationLogsTableName)).
+		WithArgs(int64(1577880001)).
+		WillReturnResult(sqlmock.NewResult(0, 10))
+
+	count, err = provider.PruneAuthenticationLogs(time.Unix(1577880001, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), count)
+
+	mock.ExpectExec(fmt.Sprintf("DELETE FROM %s WHERE revoked_at<\\?", sessionRevocationsTableName)).
+		WithArgs(int64(1577880001)).
+		WillReturnError(fmt.Errorf("failed"))
+
+	count, err = provider.PruneSessionRevocations(time.Unix(1577880001, 0))
+	assert.EqualError(t, err, "failed")
+	assert.Equal(t, int64(0), count)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLProviderMethodsPreferred(t *testing.T) {
+	provider, mock := NewSQLMockProvider()
+
+	expectSchemaUpToDate(mock)
 
 	err := provider.initialize(provider.db)
 	assert.NoError(t, err)
@@ -214,187 +466,1163 @@ func TestSQLProviderMethodsPreferred(t *testing.T) {
 	assert.Equal(t, "", method)
 }
 
-func TestSQLProviderMethodsTOTP(t *testing.T) {
+func TestSQLProviderMethodsNotificationThrottles(t *testing.T) {
 	provider, mock := NewSQLMockProvider()
 
-	mock.ExpectQuery(
-		"SELECT name FROM sqlite_master WHERE type='table'").
-		WillReturnRows(sqlmock.NewRows([]string{"name"}).
-			AddRow(userPreferencesTableName).
-			AddRow(identityVerificationTokensTableName).
-			AddRow(totpSecretsTableName).
-			AddRow(u2fDeviceHandlesTableName).
-			AddRow(authenticationLogsTableName).
-			AddRow(configTableName))
-
-	args := []driver.Value{"schema", "version"}
-	mock.ExpectQuery(
-		fmt.Sprintf("SELECT value FROM %s WHERE category=\\? AND key_name=\\?", configTableName)).
-		WithArgs(args...).
-		WillReturnRows(sqlmock.NewRows([]string{"value"}).
-			AddRow(currentSchemaMockSchemaVersion))
+	expectSchemaUpToDate(mock)
 
 	err := provider.initialize(provider.db)
 	assert.NoError(t, err)
 
-	pretendSecret := "abc123"
-	args = []driver.Value{unitTestUser, pretendSecret}
 	mock.ExpectExec(
-		fmt.Sprintf("REPLACE INTO %s \\(username, secret\\) VALUES \\(\\?, \\?\\)", totpSecretsTableName)).
-		WithArgs(args...).
+		fmt.Sprintf("REPLACE INTO %s \\(username, kind, last_sent_at, pending, pending_since\\) VALUES \\(\\?, \\?, \\?, \\?, \\?\\)", notificationThrottlesTableName)).
+		WithArgs(unitTestUser, models.NotificationKindLoginFailed, int64(0), 2, int64(1577880001)).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
-	err = provider.SaveTOTPSecret(unitTestUser, pretendSecret)
+	err = provider.SaveNotificationThrottle(models.NotificationThrottle{
+		Username:     unitTestUser,
+		Kind:         models.NotificationKindLoginFailed,
+		Pending:      2,
+		PendingSince: time.Unix(1577880001, 0),
+	})
 	assert.NoError(t, err)
 
-	args = []driver.Value{unitTestUser}
 	mock.ExpectQuery(
-		fmt.Sprintf("SELECT secret FROM %s WHERE username=\\?", totpSecretsTableName)).
-		WithArgs(args...).
-		WillReturnRows(sqlmock.NewRows([]string{"secret"}).AddRow(pretendSecret))
+		fmt.Sprintf("SELECT last_sent_at, pending, pending_since FROM %s WHERE username=\\? AND kind=\\?", notificationThrottlesTableName)).
+		WithArgs(unitTestUser, models.NotificationKindLoginFailed).
+		WillReturnRows(sqlmock.NewRows([]string{"last_sent_at", "pending", "pending_since"}).AddRow(int64(0), 2, int64(1577880001)))
 
-	secret, err := provider.LoadTOTPSecret(unitTestUser)
+	throttle, err := provider.LoadNotificationThrottle(unitTestUser, models.NotificationKindLoginFailed)
 	assert.NoError(t, err)
-	assert.Equal(t, pretendSecret, secret)
+	assert.True(t, throttle.LastSentAt.IsZero())
+	assert.Equal(t, 2, throttle.Pending)
+	assert.Equal(t, time.Unix(1577880001, 0), throttle.PendingSince)
 
-	mock.ExpectExec(
-		fmt.Sprintf("DELETE FROM %s WHERE username=\\?", totpSecretsTableName)).
-		WithArgs(unitTestUser).
-		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT last_sent_at, pending, pending_since FROM %s WHERE username=\\? AND kind=\\?", notificationThrottlesTableName)).
+		WithArgs("harry", models.NotificationKindLoginFailed).
+		WillReturnRows(sqlmock.NewRows([]string{"last_sent_at", "pending", "pending_since"}))
 
-	err = provider.DeleteTOTPSecret(unitTestUser)
+	throttle, err = provider.LoadNotificationThrottle("harry", models.NotificationKindLoginFailed)
 	assert.NoError(t, err)
+	assert.Equal(t, models.NotificationThrottle{Username: "harry", Kind: models.NotificationKindLoginFailed}, throttle)
 
 	mock.ExpectQuery(
-		fmt.Sprintf("SELECT secret FROM %s WHERE username=\\?", totpSecretsTableName)).
-		WithArgs(args...).
-		WillReturnRows(sqlmock.NewRows([]string{"secret"}))
+		fmt.Sprintf("SELECT username, kind, last_sent_at, pending, pending_since FROM %s WHERE pending>0", notificationThrottlesTableName)).
+		WillReturnRows(sqlmock.NewRows([]string{"username", "kind", "last_sent_at", "pending", "pending_since"}).
+			AddRow(unitTestUser, models.NotificationKindDeviceRegistered, int64(1577880001), 1, int64(1577880002)))
 
-	// Test Blank Rows
-	secret, err = provider.LoadTOTPSecret(unitTestUser)
-	assert.EqualError(t, err, "No TOTP secret registered")
-	assert.Equal(t, "", secret)
+	throttles, err := provider.LoadPendingNotificationThrottles()
+	assert.NoError(t, err)
+	assert.Equal(t, []models.NotificationThrottle{{
+		Username:     unitTestUser,
+		Kind:         models.NotificationKindDeviceRegistered,
+		LastSentAt:   time.Unix(1577880001, 0),
+		Pending:      1,
+		PendingSince: time.Unix(1577880002, 0),
+	}}, throttles)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
-func TestSQLProviderMethodsU2F(t *testing.T) {
+func TestSQLProviderMethodsAnnouncementAcknowledgments(t *testing.T) {
 	provider, mock := NewSQLMockProvider()
 
-	mock.ExpectQuery(
-		"SELECT name FROM sqlite_master WHERE type='table'").
-		WillReturnRows(sqlmock.NewRows([]string{"name"}).
-			AddRow(userPreferencesTableName).
-			AddRow(identityVerificationTokensTableName).
-			AddRow(totpSecretsTableName).
-			AddRow(u2fDeviceHandlesTableName).
-			AddRow(authenticationLogsTableName).
-			AddRow(configTableName))
-
-	args := []driver.Value{"schema", "version"}
-	mock.ExpectQuery(
-		fmt.Sprintf("SELECT value FROM %s WHERE category=\\? AND key_name=\\?", configTableName)).
-		WithArgs(args...).
-		WillReturnRows(sqlmock.NewRows([]string{"value"}).
-			AddRow(currentSchemaMockSchemaVersion))
+	expectSchemaUpToDate(mock)
 
 	err := provider.initialize(provider.db)
 	assert.NoError(t, err)
 
-	pretendKeyHandle := []byte("abc")
-	pretendPublicKey := []byte("123")
-	pretendKeyHandleB64 := base64.StdEncoding.EncodeToString(pretendKeyHandle)
-	pretendPublicKeyB64 := base64.StdEncoding.EncodeToString(pretendPublicKey)
-
-	args = []driver.Value{unitTestUser, pretendKeyHandleB64, pretendPublicKeyB64}
 	mock.ExpectExec(
-		fmt.Sprintf("REPLACE INTO %s \\(username, keyHandle, publicKey\\) VALUES \\(\\?, \\?, \\?\\)", u2fDeviceHandlesTableName)).
-		WithArgs(args...).
+		fmt.Sprintf("REPLACE INTO %s \\(username, announcement_id, acknowledged_at\\) VALUES \\(\\?, \\?, \\?\\)", announcementAcknowledgmentsTableName)).
+		WithArgs(unitTestUser, "maintenance", int64(1577880001)).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
-	err = provider.SaveU2FDeviceHandle(unitTestUser, pretendKeyHandle, pretendPublicKey)
+	err = provider.SaveAnnouncementAcknowledgment(unitTestUser, "maintenance", time.Unix(1577880001, 0))
 	assert.NoError(t, err)
 
-	args = []driver.Value{unitTestUser}
 	mock.ExpectQuery(
-		fmt.Sprintf("SELECT keyHandle, publicKey FROM %s WHERE username=\\?", u2fDeviceHandlesTableName)).
-		WithArgs(args...).
-		WillReturnRows(sqlmock.NewRows([]string{"keyHandle", "publicKey"}).
-			AddRow(pretendKeyHandleB64, pretendPublicKeyB64))
+		fmt.Sprintf("SELECT acknowledged_at FROM %s WHERE username=\\? AND announcement_id=\\?", announcementAcknowledgmentsTableName)).
+		WithArgs(unitTestUser, "maintenance").
+		WillReturnRows(sqlmock.NewRows([]string{"acknowledged_at"}).AddRow(int64(1577880001)))
 
-	keyHandle, publicKey, err := provider.LoadU2FDeviceHandle(unitTestUser)
+	acknowledgedAt, err := provider.LoadAnnouncementAcknowledgment(unitTestUser, "maintenance")
 	assert.NoError(t, err)
-	assert.Equal(t, pretendKeyHandle, keyHandle)
-	assert.Equal(t, pretendPublicKey, publicKey)
+	assert.Equal(t, time.Unix(1577880001, 0), acknowledgedAt)
 
-	// Test Blank Rows.
 	mock.ExpectQuery(
-		fmt.Sprintf("SELECT keyHandle, publicKey FROM %s WHERE username=\\?", u2fDeviceHandlesTableName)).
-		WithArgs(args...).
-		WillReturnRows(sqlmock.NewRows([]string{"keyHandle", "publicKey"}))
+		fmt.Sprintf("SELECT acknowledged_at FROM %s WHERE username=\\? AND announcement_id=\\?", announcementAcknowledgmentsTableName)).
+		WithArgs("harry", "maintenance").
+		WillReturnRows(sqlmock.NewRows([]string{"acknowledged_at"}))
 
-	keyHandle, publicKey, err = provider.LoadU2FDeviceHandle(unitTestUser)
-	assert.EqualError(t, err, "No U2F device handle found")
-	assert.Equal(t, []byte(nil), keyHandle)
-	assert.Equal(t, []byte(nil), publicKey)
+	acknowledgedAt, err = provider.LoadAnnouncementAcknowledgment("harry", "maintenance")
+	assert.NoError(t, err)
+	assert.True(t, acknowledgedAt.IsZero())
+
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
-func TestSQLProviderMethodsIdentityVerificationTokens(t *testing.T) {
+func TestSQLProviderMethodsBackupCodes(t *testing.T) {
 	provider, mock := NewSQLMockProvider()
 
-	mock.ExpectQuery(
-		"SELECT name FROM sqlite_master WHERE type='table'").
-		WillReturnRows(sqlmock.NewRows([]string{"name"}).
-			AddRow(userPreferencesTableName).
-			AddRow(identityVerificationTokensTableName).
-			AddRow(totpSecretsTableName).
-			AddRow(u2fDeviceHandlesTableName).
-			AddRow(authenticationLogsTableName).
-			AddRow(configTableName))
-
-	args := []driver.Value{"schema", "version"}
-	mock.ExpectQuery(
-		fmt.Sprintf("SELECT value FROM %s WHERE category=\\? AND key_name=\\?", configTableName)).
-		WithArgs(args...).
-		WillReturnRows(sqlmock.NewRows([]string{"value"}).
-			AddRow(currentSchemaMockSchemaVersion))
+	expectSchemaUpToDate(mock)
 
 	err := provider.initialize(provider.db)
 	assert.NoError(t, err)
 
-	fakeIdentityVerificationToken := "abc"
-
+	mock.ExpectBegin()
 	mock.ExpectExec(
-		fmt.Sprintf("INSERT INTO %s \\(token\\) VALUES \\(\\?\\)", identityVerificationTokensTableName)).
-		WithArgs(fakeIdentityVerificationToken).
-		WillReturnResult(sqlmock.NewResult(1, 1))
+		fmt.Sprintf("DELETE FROM %s WHERE username=\\?", backupCodesTableName)).
+		WithArgs(unitTestUser).
+		WillReturnResult(sqlmock.NewResult(0, 2))
 
-	err = provider.SaveIdentityVerificationToken(fakeIdentityVerificationToken)
-	assert.NoError(t, err)
+	for _, codeHash := range []string{"hash1", "hash2"} {
+		mock.ExpectExec(
+			fmt.Sprintf("INSERT INTO %s \\(username, code_hash, created_at\\) VALUES \\(\\?, \\?, \\?\\)", backupCodesTableName)).
+			WithArgs(unitTestUser, codeHash, int64(1577880001)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
 
-	mock.ExpectQuery(
-		fmt.Sprintf("SELECT EXISTS \\(SELECT \\* FROM %s WHERE token=\\?\\)", identityVerificationTokensTableName)).
-		WithArgs(fakeIdentityVerificationToken).
-		WillReturnRows(sqlmock.NewRows([]string{"EXISTS"}).
-			AddRow(true))
+	mock.ExpectCommit()
 
-	valid, err := provider.FindIdentityVerificationToken(fakeIdentityVerificationToken)
+	err = provider.SaveBackupCodes(unitTestUser, []string{"hash1", "hash2"}, time.Unix(1577880001, 0))
 	assert.NoError(t, err)
-	assert.True(t, valid)
 
 	mock.ExpectExec(
-		fmt.Sprintf("DELETE FROM %s WHERE token=\\?", identityVerificationTokensTableName)).
-		WithArgs(fakeIdentityVerificationToken).
+		fmt.Sprintf("DELETE FROM %s WHERE username=\\? AND code_hash=\\?", backupCodesTableName)).
+		WithArgs(unitTestUser, "hash1").
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
-	err = provider.RemoveIdentityVerificationToken(fakeIdentityVerificationToken)
+	err = provider.ConsumeBackupCode(unitTestUser, "hash1")
 	assert.NoError(t, err)
 
-	mock.ExpectQuery(
-		fmt.Sprintf("SELECT EXISTS \\(SELECT \\* FROM %s WHERE token=\\?\\)", identityVerificationTokensTableName)).
-		WithArgs(fakeIdentityVerificationToken).
-		WillReturnRows(sqlmock.NewRows([]string{"EXISTS"}).
-			AddRow(false))
+	mock.ExpectExec(
+		fmt.Sprintf("DELETE FROM %s WHERE username=\\? AND code_hash=\\?", backupCodesTableName)).
+		WithArgs(unitTestUser, "hash1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = provider.ConsumeBackupCode(unitTestUser, "hash1")
+	assert.Equal(t, ErrNoBackupCode, err)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT COUNT\\(\\*\\) FROM %s WHERE username=\\?", backupCodesTableName)).
+		WithArgs(unitTestUser).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	count, err := provider.CountBackupCodes(unitTestUser)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT code_hash FROM %s WHERE username=\\?", backupCodesTableName)).
+		WithArgs(unitTestUser).
+		WillReturnRows(sqlmock.NewRows([]string{"code_hash"}).AddRow("hash2"))
+
+	codeHashes, err := provider.LoadBackupCodeHashes(unitTestUser)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"hash2"}, codeHashes)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLProviderMethodsAdminAPITokens(t *testing.T) {
+	provider, mock := NewSQLMockProvider()
+
+	expectSchemaUpToDate(mock)
+
+	err := provider.initialize(provider.db)
+	assert.NoError(t, err)
+
+	mock.ExpectExec(
+		fmt.Sprintf("INSERT INTO %s \\(id, description, token_hash, scopes, created_at, expires_at\\) VALUES \\(\\?, \\?, \\?, \\?, \\?, \\?\\)", adminAPITokensTableName)).
+		WithArgs("abc", "Backup job", "hash", "read users", int64(1577880001), int64(0)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = provider.SaveAdminAPIToken(models.AdminAPIToken{
+		ID:          "abc",
+		Description: "Backup job",
+		TokenHash:   "hash",
+		Scopes:      []string{"read", "users"},
+		CreatedAt:   time.Unix(1577880001, 0),
+	})
+	assert.NoError(t, err)
+
+	columns := []string{"id", "description", "token_hash", "scopes", "created_at", "expires_at", "last_used_at"}
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT id, description, token_hash, scopes, created_at, expires_at, last_used_at FROM %s WHERE token_hash=\\?", adminAPITokensTableName)).
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("abc", "Backup job", "hash", "read users", int64(1577880001), int64(1577890001), nil))
+
+	token, err := provider.LoadAdminAPIToken("hash")
+	require.NoError(t, err)
+	assert.Equal(t, models.AdminAPIToken{
+		ID:          "abc",
+		Description: "Backup job",
+		TokenHash:   "hash",
+		Scopes:      []string{"read", "users"},
+		CreatedAt:   time.Unix(1577880001, 0),
+		ExpiresAt:   time.Unix(1577890001, 0),
+	}, *token)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT id, description, token_hash, scopes, created_at, expires_at, last_used_at FROM %s WHERE token_hash=\\?", adminAPITokensTableName)).
+		WithArgs("unknown").
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	_, err = provider.LoadAdminAPIToken("unknown")
+	assert.Equal(t, ErrNoAdminAPIToken, err)
+
+	mock.ExpectExec(
+		fmt.Sprintf("UPDATE %s SET last_used_at=\\? WHERE id=\\?", adminAPITokensTableName)).
+		WithArgs(int64(1577880100), "abc").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = provider.UpdateAdminAPITokenLastUsed("abc", time.Unix(1577880100, 0))
+	assert.NoError(t, err)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT id, description, token_hash, scopes, created_at, expires_at, last_used_at FROM %s ORDER BY created_at", adminAPITokensTableName)).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("abc", "Backup job", "hash", "read users", int64(1577880001), int64(0), int64(1577880100)))
+
+	tokens, err := provider.LoadAdminAPITokens()
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	assert.True(t, tokens[0].ExpiresAt.IsZero())
+	assert.Equal(t, time.Unix(1577880100, 0), tokens[0].LastUsedAt)
+
+	mock.ExpectExec(
+		fmt.Sprintf("DELETE FROM %s WHERE id=\\?", adminAPITokensTableName)).
+		WithArgs("abc").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = provider.DeleteAdminAPIToken("abc")
+	assert.NoError(t, err)
+
+	mock.ExpectExec(
+		fmt.Sprintf("DELETE FROM %s WHERE id=\\?", adminAPITokensTableName)).
+		WithArgs("abc").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = provider.DeleteAdminAPIToken("abc")
+	assert.Equal(t, ErrNoAdminAPIToken, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLProviderMethodsUserManagement(t *testing.T) {
+	provider, mock := NewSQLMockProvider()
+
+	expectSchemaUpToDate(mock)
+
+	err := provider.initialize(provider.db)
+	assert.NoError(t, err)
+
+	mock.ExpectExec(
+		fmt.Sprintf("DELETE FROM %s WHERE username=\\?", userPreferencesTableName)).
+		WithArgs("john").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = provider.DeletePreferred2FAMethod("john")
+	assert.NoError(t, err)
+
+	mock.ExpectExec(
+		fmt.Sprintf("DELETE FROM %s WHERE username=\\?", u2fDeviceHandlesTableName)).
+		WithArgs("john").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = provider.DeleteU2FDeviceHandle("john")
+	assert.NoError(t, err)
+
+	mock.ExpectExec(
+		fmt.Sprintf("DELETE FROM %s WHERE username=\\?", u2fDeviceHandlesTableName)).
+		WithArgs("john").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = provider.DeleteU2FDeviceHandle("john")
+	assert.Equal(t, ErrNoU2FDeviceHandle, err)
+
+	mock.ExpectExec(
+		fmt.Sprintf("DELETE FROM %s WHERE username=\\? AND credential_id=\\?", webauthnCredentialsTableName)).
+		WithArgs("john", "YWJj").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = provider.DeleteWebauthnCredential("john", []byte("abc"))
+	assert.Equal(t, ErrNoWebauthnCredential, err)
+
+	mock.ExpectExec(
+		fmt.Sprintf("DELETE FROM %s WHERE username=\\? AND successful=\\?", authenticationLogsTableName)).
+		WithArgs("john", false).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	deleted, err := provider.DeleteFailedAuthenticationLogs("john")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+
+	mock.ExpectExec(
+		fmt.Sprintf("REPLACE INTO %s \\(username, revoked_at\\) VALUES \\(\\?, \\?\\)", sessionRevocationsTableName)).
+		WithArgs("john", int64(1577880001)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = provider.RevokeSessions("john", time.Unix(1577880001, 0))
+	assert.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLProviderMethodsAccessReview(t *testing.T) {
+	provider, mock := NewSQLMockProvider()
+
+	expectSchemaUpToDate(mock)
+
+	err := provider.initialize(provider.db)
+	assert.NoError(t, err)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT username FROM %s UNION SELECT username FROM %s UNION .* ORDER BY username", authenticationLogsTableName, userPreferencesTableName)).
+		WillReturnRows(sqlmock.NewRows([]string{"username"}).
+			AddRow("harry").
+			AddRow("john"))
+
+	usernames, err := provider.LoadUsernames()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"harry", "john"}, usernames)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT time FROM %s WHERE username=\\? AND successful=\\? ORDER BY time DESC LIMIT 1", authenticationLogsTableName)).
+		WithArgs("john", true).
+		WillReturnRows(sqlmock.NewRows([]string{"time"}).
+			AddRow(int64(1577880001)))
+
+	lastLogin, err := provider.LoadLastLogin("john")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Unix(1577880001, 0), lastLogin)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT time FROM %s WHERE username=\\? AND successful=\\? ORDER BY time DESC LIMIT 1", authenticationLogsTableName)).
+		WithArgs("harry", true).
+		WillReturnRows(sqlmock.NewRows([]string{"time"}))
+
+	lastLogin, err = provider.LoadLastLogin("harry")
+	assert.NoError(t, err)
+	assert.True(t, lastLogin.IsZero())
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLProviderMethodsTOTP(t *testing.T) {
+	provider, mock := NewSQLMockProvider()
+
+	expectSchemaUpToDate(mock)
+
+	err := provider.initialize(provider.db)
+	assert.NoError(t, err)
+
+	device := models.TOTPDevice{ID: "phone", Username: unitTestUser, Description: "Phone", Secret: "abc123", CreatedAt: time.Unix(1577880000, 0)}
+
+	mock.ExpectExec(
+		fmt.Sprintf("INSERT INTO %s \\(username, device_id, description, secret, created_at\\) VALUES \\(\\?, \\?, \\?, \\?, \\?\\)", totpDevicesTableName)).
+		WithArgs(unitTestUser, "phone", "Phone", "abc123", int64(1577880000)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = provider.SaveTOTPDevice(device)
+	assert.NoError(t, err)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT device_id, username, COALESCE\\(description, ''\\), secret, created_at, last_used_at FROM %s WHERE username=\\? ORDER BY COALESCE\\(created_at, 0\\), device_id", totpDevicesTableName)).
+		WithArgs(unitTestUser).
+		WillReturnRows(sqlmock.NewRows([]string{"device_id", "username", "description", "secret", "created_at", "last_used_at"}).
+			AddRow("default", unitTestUser, "One-time password device", "def456", nil, nil).
+			AddRow("phone", unitTestUser, "Phone", "abc123", int64(1577880000), int64(1577880001)))
+
+	devices, err := provider.LoadTOTPDevices(unitTestUser)
+	assert.NoError(t, err)
+
+	device.LastUsedAt = time.Unix(1577880001, 0)
+	assert.Equal(t, []models.TOTPDevice{{ID: "default", Username: unitTestUser, Description: "One-time password device", Secret: "def456"}, device}, devices)
+
+	mock.ExpectExec(
+		fmt.Sprintf("UPDATE %s SET description=\\? WHERE username=\\? AND device_id=\\?", totpDevicesTableName)).
+		WithArgs("Work phone", unitTestUser, "phone").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = provider.UpdateTOTPDeviceDescription(unitTestUser, "phone", "Work phone")
+	assert.NoError(t, err)
+
+	mock.ExpectExec(
+		fmt.Sprintf("UPDATE %s SET last_used_step=\\?, last_used_at=\\? WHERE username=\\? AND device_id=\\? AND \\(last_used_step IS NULL OR last_used_step<\\?\\)", totpDevicesTableName)).
+		WithArgs(int64(1000), int64(1577880002), unitTestUser, "phone", int64(1000)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = provider.SaveTOTPDeviceLastUsed(unitTestUser, "phone", 1000, time.Unix(1577880002, 0))
+	assert.NoError(t, err)
+
+	mock.ExpectExec(
+		fmt.Sprintf("UPDATE %s SET last_used_step=\\?, last_used_at=\\? WHERE username=\\? AND device_id=\\? AND \\(last_used_step IS NULL OR last_used_step<\\?\\)", totpDevicesTableName)).
+		WithArgs(int64(1000), int64(1577880003), unitTestUser, "phone", int64(1000)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = provider.SaveTOTPDeviceLastUsed(unitTestUser, "phone", 1000, time.Unix(1577880003, 0))
+	assert.Equal(t, ErrTOTPStepAlreadyUsed, err)
+
+	mock.ExpectExec(
+		fmt.Sprintf("DELETE FROM %s WHERE username=\\? AND device_id=\\?", totpDevicesTableName)).
+		WithArgs(unitTestUser, "phone").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = provider.DeleteTOTPDevice(unitTestUser, "phone")
+	assert.NoError(t, err)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT device_id, username, COALESCE\\(description, ''\\), secret, created_at, last_used_at FROM %s WHERE username=\\? ORDER BY COALESCE\\(created_at, 0\\), device_id", totpDevicesTableName)).
+		WithArgs(unitTestUser).
+		WillReturnRows(sqlmock.NewRows([]string{"device_id", "username", "description", "secret", "created_at", "last_used_at"}))
+
+	// Test Blank Rows
+	devices, err = provider.LoadTOTPDevices(unitTestUser)
+	assert.NoError(t, err)
+	assert.Empty(t, devices)
+}
+
+func TestSQLProviderMethodsU2F(t *testing.T) {
+	provider, mock := NewSQLMockProvider()
+
+	expectSchemaUpToDate(mock)
+
+	err := provider.initialize(provider.db)
+	assert.NoError(t, err)
+
+	pretendKeyHandle := []byte("abc")
+	pretendPublicKey := []byte("123")
+	pretendKeyHandleB64 := base64.StdEncoding.EncodeToString(pretendKeyHandle)
+	pretendPublicKeyB64 := base64.StdEncoding.EncodeToString(pretendPublicKey)
+
+	args := []driver.Value{unitTestUser, pretendKeyHandleB64, pretendPublicKeyB64}
+	mock.ExpectExec(
+		fmt.Sprintf("REPLACE INTO %s \\(username, keyHandle, publicKey\\) VALUES \\(\\?, \\?, \\?\\)", u2fDeviceHandlesTableName)).
+		WithArgs(args...).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = provider.SaveU2FDeviceHandle(unitTestUser, pretendKeyHandle, pretendPublicKey)
+	assert.NoError(t, err)
+
+	args = []driver.Value{unitTestUser}
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT keyHandle, publicKey FROM %s WHERE username=\\?", u2fDeviceHandlesTableName)).
+		WithArgs(args...).
+		WillReturnRows(sqlmock.NewRows([]string{"keyHandle", "publicKey"}).
+			AddRow(pretendKeyHandleB64, pretendPublicKeyB64))
+
+	keyHandle, publicKey, err := provider.LoadU2FDeviceHandle(unitTestUser)
+	assert.NoError(t, err)
+	assert.Equal(t, pretendKeyHandle, keyHandle)
+	assert.Equal(t, pretendPublicKey, publicKey)
+
+	// Test Blank Rows.
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT keyHandle, publicKey FROM %s WHERE username=\\?", u2fDeviceHandlesTableName)).
+		WithArgs(args...).
+		WillReturnRows(sqlmock.NewRows([]string{"keyHandle", "publicKey"}))
+
+	keyHandle, publicKey, err = provider.LoadU2FDeviceHandle(unitTestUser)
+	assert.EqualError(t, err, "No U2F device handle found")
+	assert.Equal(t, []byte(nil), keyHandle)
+	assert.Equal(t, []byte(nil), publicKey)
+}
+
+func TestSQLProviderMethodsWebauthn(t *testing.T) {
+	provider, mock := NewSQLMockProvider()
+
+	expectSchemaUpToDate(mock)
+
+	err := provider.initialize(provider.db)
+	assert.NoError(t, err)
+
+	credential := models.WebauthnCredential{
+		ID:          []byte("abc"),
+		Username:    unitTestUser,
+		Type:        models.WebauthnCredentialTypeSecondFactor,
+		Description: "YubiKey",
+		PublicKey:   []byte("123"),
+		SignCount:   10,
+		CreatedAt:   time.Unix(1577880001, 0),
+	}
+	idB64 := base64.StdEncoding.EncodeToString(credential.ID)
+	publicKeyB64 := base64.StdEncoding.EncodeToString(credential.PublicKey)
+
+	args := []driver.Value{idB64, unitTestUser, models.WebauthnCredentialTypeSecondFactor, "YubiKey", publicKeyB64, credential.SignCount, credential.CreatedAt.Unix()}
+	mock.ExpectExec(
+		fmt.Sprintf("INSERT INTO %s \\(credential_id, username, credential_type, description, public_key, sign_count, created_at\\) VALUES \\(\\?, \\?, \\?, \\?, \\?, \\?, \\?\\)", webauthnCredentialsTableName)).
+		WithArgs(args...).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = provider.SaveWebauthnCredential(credential)
+	assert.NoError(t, err)
+
+	columns := []string{"credential_id", "username", "credential_type", "description", "public_key", "sign_count", "created_at"}
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT credential_id, username, credential_type, COALESCE\\(description, ''\\), public_key, sign_count, created_at FROM %s WHERE credential_id=\\?", webauthnCredentialsTableName)).
+		WithArgs(idB64).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(idB64, unitTestUser, models.WebauthnCredentialTypeSecondFactor, "YubiKey", publicKeyB64, 10, int64(1577880001)))
+
+	loaded, err := provider.LoadWebauthnCredential(credential.ID)
+	require.NoError(t, err)
+	assert.Equal(t, credential, *loaded)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT credential_id, username, credential_type, COALESCE\\(description, ''\\), public_key, sign_count, created_at FROM %s WHERE username=\\? ORDER BY created_at", webauthnCredentialsTableName)).
+		WithArgs(unitTestUser).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(idB64, unitTestUser, models.WebauthnCredentialTypeSecondFactor, "YubiKey", publicKeyB64, 10, int64(1577880001)))
+
+	credentials, err := provider.LoadWebauthnCredentials(unitTestUser)
+	require.NoError(t, err)
+	assert.Equal(t, []models.WebauthnCredential{credential}, credentials)
+
+	mock.ExpectExec(
+		fmt.Sprintf("UPDATE %s SET sign_count=\\? WHERE credential_id=\\?", webauthnCredentialsTableName)).
+		WithArgs(11, idB64).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = provider.UpdateWebauthnCredentialSignCount(credential.ID, 11)
+	assert.NoError(t, err)
+
+	// Test Blank Rows.
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT credential_id, username, credential_type, COALESCE\\(description, ''\\), public_key, sign_count, created_at FROM %s WHERE credential_id=\\?", webauthnCredentialsTableName)).
+		WithArgs(idB64).
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	loaded, err = provider.LoadWebauthnCredential(credential.ID)
+	assert.EqualError(t, err, "No WebAuthn credential found")
+	assert.Nil(t, loaded)
+}
+
+func TestSQLProviderMethodsIdentityVerificationTokens(t *testing.T) {
+	provider, mock := NewSQLMockProvider()
+
+	expectSchemaUpToDate(mock)
+
+	err := provider.initialize(provider.db)
+	assert.NoError(t, err)
+
+	fakeIdentityVerificationToken := "abc"
+
+	fakeExpiresAt := time.Unix(1640000000, 0)
+
+	mock.ExpectExec(
+		fmt.Sprintf("INSERT INTO %s \\(token, expires_at\\) VALUES \\(\\?, \\?\\)", identityVerificationTokensTableName)).
+		WithArgs(fakeIdentityVerificationToken, fakeExpiresAt.Unix()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err = provider.SaveIdentityVerificationToken(fakeIdentityVerificationToken, fakeExpiresAt)
+	assert.NoError(t, err)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT EXISTS \\(SELECT \\* FROM %s WHERE token=\\?\\)", identityVerificationTokensTableName)).
+		WithArgs(fakeIdentityVerificationToken).
+		WillReturnRows(sqlmock.NewRows([]string{"EXISTS"}).
+			AddRow(true))
+
+	valid, err := provider.FindIdentityVerificationToken(fakeIdentityVerificationToken)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	mock.ExpectExec(
+		fmt.Sprintf("DELETE FROM %s WHERE token=\\?", identityVerificationTokensTableName)).
+		WithArgs(fakeIdentityVerificationToken).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = provider.RemoveIdentityVerificationToken(fakeIdentityVerificationToken)
+	assert.NoError(t, err)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT EXISTS \\(SELECT \\* FROM %s WHERE token=\\?\\)", identityVerificationTokensTableName)).
+		WithArgs(fakeIdentityVerificationToken).
+		WillReturnRows(sqlmock.NewRows([]string{"EXISTS"}).
+			AddRow(false))
 
 	valid, err = provider.FindIdentityVerificationToken(fakeIdentityVerificationToken)
 	assert.NoError(t, err)
 	assert.False(t, valid)
 }
+
+func TestSQLProviderMethodsLoginStates(t *testing.T) {
+	provider, mock := NewSQLMockProvider()
+
+	expectSchemaUpToDate(mock)
+
+	err := provider.initialize(provider.db)
+	assert.NoError(t, err)
+
+	nonce := "5tP0gZ5NmUhUDGbR"
+	now := time.Unix(1640000000, 0)
+
+	mock.ExpectExec(
+		fmt.Sprintf("INSERT INTO %s \\(nonce, expires_at\\) VALUES \\(\\?, \\?\\)", loginStatesTableName)).
+		WithArgs(nonce, now.Add(time.Minute).Unix()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err = provider.SaveLoginState(nonce, now.Add(time.Minute))
+	assert.NoError(t, err)
+
+	mock.ExpectExec(
+		fmt.Sprintf("DELETE FROM %s WHERE nonce=\\? AND expires_at>=\\?", loginStatesTableName)).
+		WithArgs(nonce, now.Unix()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = provider.ConsumeLoginState(nonce, now)
+	assert.NoError(t, err)
+
+	mock.ExpectExec(
+		fmt.Sprintf("DELETE FROM %s WHERE nonce=\\? AND expires_at>=\\?", loginStatesTableName)).
+		WithArgs(nonce, now.Unix()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = provider.ConsumeLoginState(nonce, now)
+	assert.Equal(t, ErrNoLoginState, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestShouldSplitReadReplicaAddress(t *testing.T) {
+	host, port := splitReadReplicaAddress("replica1", 5432)
+	assert.Equal(t, "replica1", host)
+	assert.Equal(t, 5432, port)
+
+	host, port = splitReadReplicaAddress("replica2:5433", 5432)
+	assert.Equal(t, "replica2", host)
+	assert.Equal(t, 5433, port)
+
+	host, port = splitReadReplicaAddress("[::1]:5434", 5432)
+	assert.Equal(t, "::1", host)
+	assert.Equal(t, 5434, port)
+}
+
+func TestShouldBuildPostgreSQLConnectionString(t *testing.T) {
+	configuration := schema.PostgreSQLStorageConfiguration{
+		SQLStorageConfiguration: schema.SQLStorageConfiguration{
+			Host:     "postgres1",
+			Database: "authelia",
+			Username: "authelia",
+			Password: "secret",
+		},
+		SSLMode: "verify-full",
+	}
+
+	assert.Equal(t, "user='authelia' password='secret' host=postgres1 dbname=authelia sslmode=verify-full",
+		postgreSQLConnectionString(configuration, []string{"postgres1"}, []int{0}))
+
+	configuration.SSLRootCertificate = "/certs/ca.pem"
+	configuration.SSLCertificate = "/certs/authelia.pem"
+	configuration.SSLKey = "/certs/authelia.key"
+	configuration.DisablePreparedStatements = true
+
+	assert.Equal(t, "user='authelia' password='secret' host=postgres1,postgres2 port=5432,5433 dbname=authelia sslmode=verify-full "+
+		"sslrootcert='/certs/ca.pem' sslcert='/certs/authelia.pem' sslkey='/certs/authelia.key' statement_cache_mode=describe target_session_attrs=read-write",
+		postgreSQLConnectionString(configuration, []string{"postgres1", "postgres2"}, []int{0, 5433}))
+}
+
+func TestShouldReadFromReplicaAndFallbackToPrimary(t *testing.T) {
+	provider := NewSQLiteProvider(filepath.Join(t.TempDir(), "primary.sqlite3"))
+	replica := NewSQLiteProvider(filepath.Join(t.TempDir(), "replica.sqlite3"))
+
+	provider.replicas = []*sql.DB{replica.db}
+
+	// The replica serves the reads, even when it lags behind the primary.
+	require.NoError(t, provider.SavePreferred2FAMethod(unitTestUser, "totp"))
+	require.NoError(t, replica.SavePreferred2FAMethod(unitTestUser, "webauthn"))
+
+	method, err := provider.LoadPreferred2FAMethod(unitTestUser)
+	require.NoError(t, err)
+	assert.Equal(t, "webauthn", method)
+
+	// The data not replicated yet is read from the primary.
+	require.NoError(t, provider.SaveTOTPDevice(models.TOTPDevice{ID: "phone", Username: unitTestUser, Secret: "secret", CreatedAt: time.Unix(1577880000, 0)}))
+
+	devices, err := provider.LoadTOTPDevices(unitTestUser)
+	require.NoError(t, err)
+	require.Len(t, devices, 1)
+	assert.Equal(t, "secret", devices[0].Secret)
+
+	devices, err = provider.LoadTOTPDevices("harry")
+	require.NoError(t, err)
+	assert.Empty(t, devices)
+
+	// The primary is read when the replica fails.
+	require.NoError(t, replica.db.Close())
+
+	method, err = provider.LoadPreferred2FAMethod(unitTestUser)
+	require.NoError(t, err)
+	assert.Equal(t, "totp", method)
+}
+
+func TestSQLProviderMethodsPushEnrollment(t *testing.T) {
+	provider, mock := NewSQLMockProvider()
+
+	expectSchemaUpToDate(mock)
+
+	err := provider.initialize(provider.db)
+	assert.NoError(t, err)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT provider, status, devices, checked_at FROM %s WHERE username=\\?", pushEnrollmentsTableName)).
+		WithArgs("john").
+		WillReturnRows(sqlmock.NewRows([]string{"provider", "status", "devices", "checked_at"}))
+
+	_, err = provider.LoadPushEnrollment("john")
+	assert.Equal(t, ErrNoPushEnrollment, err)
+
+	mock.ExpectExec(
+		fmt.Sprintf("REPLACE INTO %s \\(username, provider, status, devices, checked_at\\) VALUES \\(\\?, \\?, \\?, \\?, \\?\\)", pushEnrollmentsTableName)).
+		WithArgs("john", "duo", models.PushEnrollmentEnrolled, 2, int64(1600000000)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = provider.SavePushEnrollment(models.PushEnrollment{
+		Username:  "john",
+		Provider:  "duo",
+		Status:    models.PushEnrollmentEnrolled,
+		Devices:   2,
+		CheckedAt: time.Unix(1600000000, 0),
+	})
+	assert.NoError(t, err)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT provider, status, devices, checked_at FROM %s WHERE username=\\?", pushEnrollmentsTableName)).
+		WithArgs("john").
+		WillReturnRows(sqlmock.NewRows([]string{"provider", "status", "devices", "checked_at"}).
+			AddRow("duo", models.PushEnrollmentEnrolled, 2, 1600000000))
+
+	enrollment, err := provider.LoadPushEnrollment("john")
+	require.NoError(t, err)
+	assert.Equal(t, models.PushEnrollment{
+		Username:  "john",
+		Provider:  "duo",
+		Status:    models.PushEnrollmentEnrolled,
+		Devices:   2,
+		CheckedAt: time.Unix(1600000000, 0),
+	}, *enrollment)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLProviderMethodsSecondFactorResets(t *testing.T) {
+	provider, mock := NewSQLMockProvider()
+
+	expectSchemaUpToDate(mock)
+
+	err := provider.initialize(provider.db)
+	assert.NoError(t, err)
+
+	columns := []string{"id", "username", "requested_by", "reason", "approval", "approval_token_hash", "status",
+		"decided_by", "requested_at", "expires_at", "decided_at"}
+
+	mock.ExpectExec(
+		fmt.Sprintf("INSERT INTO %s \\(id, username, requested_by, reason, approval, approval_token_hash, status, requested_at, expires_at\\) VALUES \\(\\?, \\?, \\?, \\?, \\?, \\?, \\?, \\?, \\?\\)", secondFactorResetsTableName)).
+		WithArgs("reset1", unitTestUser, "harry", "Lost phone", models.SecondFactorResetApprovalAdmin, "",
+			models.SecondFactorResetPending, int64(1600000000), int64(1600086400)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = provider.SaveSecondFactorReset(models.SecondFactorReset{
+		ID:          "reset1",
+		Username:    unitTestUser,
+		RequestedBy: "harry",
+		Reason:      "Lost phone",
+		Approval:    models.SecondFactorResetApprovalAdmin,
+		RequestedAt: time.Unix(1600000000, 0),
+		ExpiresAt:   time.Unix(1600086400, 0),
+	})
+	assert.NoError(t, err)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT id, username, requested_by, reason, approval, approval_token_hash, status, decided_by, requested_at, expires_at, decided_at FROM %s WHERE status=\\? AND expires_at>\\? ORDER BY requested_at", secondFactorResetsTableName)).
+		WithArgs(models.SecondFactorResetPending, int64(1600000100)).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("reset1", unitTestUser, "harry", "Lost phone", models.SecondFactorResetApprovalAdmin, nil,
+				models.SecondFactorResetPending, nil, 1600000000, 1600086400, nil))
+
+	resets, err := provider.LoadPendingSecondFactorResets(time.Unix(1600000100, 0))
+	require.NoError(t, err)
+	assert.Equal(t, []models.SecondFactorReset{{
+		ID:          "reset1",
+		Username:    unitTestUser,
+		RequestedBy: "harry",
+		Reason:      "Lost phone",
+		Approval:    models.SecondFactorResetApprovalAdmin,
+		Status:      models.SecondFactorResetPending,
+		RequestedAt: time.Unix(1600000000, 0),
+		ExpiresAt:   time.Unix(1600086400, 0),
+	}}, resets)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT id, username, requested_by, reason, approval, approval_token_hash, status, decided_by, requested_at, expires_at, decided_at FROM %s WHERE id=\\?", secondFactorResetsTableName)).
+		WithArgs("unknown").
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	_, err = provider.LoadSecondFactorReset("unknown")
+	assert.Equal(t, ErrNoSecondFactorReset, err)
+
+	updateQuery := fmt.Sprintf("UPDATE %s SET status=\\?, decided_by=\\?, decided_at=\\? WHERE id=\\? AND username=\\? AND status=\\? AND expires_at>\\?", secondFactorResetsTableName)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(updateQuery).
+		WithArgs(models.SecondFactorResetApproved, "bob", int64(1600000200), "reset1", unitTestUser,
+			models.SecondFactorResetPending, int64(1600000200)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	for _, table := range secondFactorTableNames {
+		mock.ExpectExec(fmt.Sprintf("DELETE FROM %s WHERE username=\\?", table)).
+			WithArgs(unitTestUser).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	mock.ExpectCommit()
+
+	err = provider.ApproveSecondFactorReset("reset1", unitTestUser, "bob", time.Unix(1600000200, 0))
+	assert.NoError(t, err)
+
+	// The devices aren't deleted when the reset has been approved concurrently.
+	mock.ExpectBegin()
+	mock.ExpectExec(updateQuery).
+		WithArgs(models.SecondFactorResetApproved, "bob", int64(1600000300), "reset1", unitTestUser,
+			models.SecondFactorResetPending, int64(1600000300)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = provider.ApproveSecondFactorReset("reset1", unitTestUser, "bob", time.Unix(1600000300, 0))
+	assert.Equal(t, ErrNoSecondFactorReset, err)
+
+	mock.ExpectExec(updateQuery).
+		WithArgs(models.SecondFactorResetRejected, "bob", int64(1600000400), "reset2", unitTestUser,
+			models.SecondFactorResetPending, int64(1600000400)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = provider.RejectSecondFactorReset("reset2", unitTestUser, "bob", time.Unix(1600000400, 0))
+	assert.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLProviderMethodsAccessGrants(t *testing.T) {
+	provider, mock := NewSQLMockProvider()
+
+	expectSchemaUpToDate(mock)
+
+	err := provider.initialize(provider.db)
+	assert.NoError(t, err)
+
+	columns := []string{"id", "username", "target_url", "description", "created_at", "expires_at"}
+
+	grant := models.AccessGrant{
+		ID:          "grant1",
+		Username:    unitTestUser,
+		TargetURL:   "https://secure.example.com/documents/1",
+		Description: "Contractor",
+		CreatedAt:   time.Unix(1600000000, 0),
+		ExpiresAt:   time.Unix(1600003600, 0),
+	}
+
+	mock.ExpectExec(
+		fmt.Sprintf("INSERT INTO %s \\(id, username, target_url, description, created_at, expires_at\\) VALUES \\(\\?, \\?, \\?, \\?, \\?, \\?\\)", accessGrantsTableName)).
+		WithArgs("grant1", unitTestUser, "https://secure.example.com/documents/1", "Contractor", int64(1600000000), int64(1600003600)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = provider.SaveAccessGrant(grant)
+	assert.NoError(t, err)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT id, username, target_url, description, created_at, expires_at FROM %s WHERE id=\\?", accessGrantsTableName)).
+		WithArgs("grant1").
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("grant1", unitTestUser, "https://secure.example.com/documents/1", "Contractor", 1600000000, 1600003600))
+
+	loaded, err := provider.LoadAccessGrant("grant1")
+	require.NoError(t, err)
+	assert.Equal(t, grant, *loaded)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT id, username, target_url, description, created_at, expires_at FROM %s WHERE id=\\?", accessGrantsTableName)).
+		WithArgs("unknown").
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	_, err = provider.LoadAccessGrant("unknown")
+	assert.Equal(t, ErrNoAccessGrant, err)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT id, username, target_url, description, created_at, expires_at FROM %s WHERE username=\\? AND expires_at>\\? ORDER BY created_at", accessGrantsTableName)).
+		WithArgs(unitTestUser, int64(1600000100)).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("grant1", unitTestUser, "https://secure.example.com/documents/1", nil, 1600000000, 1600003600))
+
+	grants, err := provider.LoadUserAccessGrants(unitTestUser, time.Unix(1600000100, 0))
+	require.NoError(t, err)
+	require.Len(t, grants, 1)
+	assert.Equal(t, "", grants[0].Description)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT id, username, target_url, description, created_at, expires_at FROM %s WHERE expires_at>\\? ORDER BY created_at", accessGrantsTableName)).
+		WithArgs(int64(1600000100)).
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	grants, err = provider.LoadAccessGrants(time.Unix(1600000100, 0))
+	require.NoError(t, err)
+	assert.Len(t, grants, 0)
+
+	mock.ExpectExec(
+		fmt.Sprintf("DELETE FROM %s WHERE id=\\?", accessGrantsTableName)).
+		WithArgs("grant1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = provider.DeleteAccessGrant("grant1")
+	assert.NoError(t, err)
+
+	mock.ExpectExec(
+		fmt.Sprintf("DELETE FROM %s WHERE id=\\?", accessGrantsTableName)).
+		WithArgs("grant1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = provider.DeleteAccessGrant("grant1")
+	assert.Equal(t, ErrNoAccessGrant, err)
+
+	mock.ExpectExec(
+		fmt.Sprintf("DELETE FROM %s WHERE expires_at<\\?", accessGrantsTableName)).
+		WithArgs(int64(1600003601)).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	count, err := provider.PruneAccessGrants(time.Unix(1600003601, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLProviderMethodsBreakGlassActivations(t *testing.T) {
+	provider, mock := NewSQLMockProvider()
+
+	expectSchemaUpToDate(mock)
+
+	err := provider.initialize(provider.db)
+	assert.NoError(t, err)
+
+	columns := []string{"username", "activated_at"}
+
+	activation := models.BreakGlassActivation{
+		Username:    "emergency",
+		ActivatedAt: time.Unix(1600000000, 0),
+	}
+
+	mock.ExpectExec(
+		fmt.Sprintf("INSERT INTO %s \\(username, activated_at\\) VALUES \\(\\?, \\?\\)", breakGlassActivationsTableName)).
+		WithArgs("emergency", int64(1600000000)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = provider.SaveBreakGlassActivation(activation)
+	assert.NoError(t, err)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT username, activated_at FROM %s WHERE username=\\?", breakGlassActivationsTableName)).
+		WithArgs("emergency").
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("emergency", 1600000000))
+
+	loaded, err := provider.LoadBreakGlassActivation("emergency")
+	require.NoError(t, err)
+	assert.Equal(t, activation, *loaded)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT username, activated_at FROM %s WHERE username=\\?", breakGlassActivationsTableName)).
+		WithArgs("unknown").
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	_, err = provider.LoadBreakGlassActivation("unknown")
+	assert.Equal(t, ErrNoBreakGlassActivation, err)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT username, activated_at FROM %s ORDER BY activated_at", breakGlassActivationsTableName)).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("emergency", 1600000000))
+
+	activations, err := provider.LoadBreakGlassActivations()
+	require.NoError(t, err)
+	assert.Equal(t, []models.BreakGlassActivation{activation}, activations)
+
+	mock.ExpectExec(
+		fmt.Sprintf("DELETE FROM %s WHERE username=\\?", breakGlassActivationsTableName)).
+		WithArgs("emergency").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = provider.DeleteBreakGlassActivation("emergency")
+	assert.NoError(t, err)
+
+	mock.ExpectExec(
+		fmt.Sprintf("DELETE FROM %s WHERE username=\\?", breakGlassActivationsTableName)).
+		WithArgs("emergency").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = provider.DeleteBreakGlassActivation("emergency")
+	assert.Equal(t, ErrNoBreakGlassActivation, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLProviderMethodsOIDCConsents(t *testing.T) {
+	provider, mock := NewSQLMockProvider()
+
+	expectSchemaUpToDate(mock)
+
+	err := provider.initialize(provider.db)
+	assert.NoError(t, err)
+
+	columns := []string{"username", "client_id", "scopes", "audience", "created_at", "expires_at"}
+	now := time.Unix(1600000100, 0)
+
+	consent := models.OIDCConsent{
+		Username:  unitTestUser,
+		ClientID:  "grafana",
+		Scopes:    []string{"openid", "groups"},
+		Audience:  []string{"https://api.example.com"},
+		CreatedAt: time.Unix(1600000000, 0),
+		ExpiresAt: time.Unix(1600086400, 0),
+	}
+
+	mock.ExpectExec(
+		fmt.Sprintf("REPLACE INTO %s \\(username, client_id, scopes, audience, created_at, expires_at\\) VALUES \\(\\?, \\?, \\?, \\?, \\?, \\?\\)", oidcConsentsTableName)).
+		WithArgs(unitTestUser, "grafana", "openid groups", "https://api.example.com", int64(1600000000), int64(1600086400)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = provider.SaveOIDCConsent(consent)
+	assert.NoError(t, err)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT username, client_id, scopes, audience, created_at, expires_at FROM %s WHERE username=\\? AND client_id=\\? AND expires_at>\\?", oidcConsentsTableName)).
+		WithArgs(unitTestUser, "grafana", int64(1600000100)).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(unitTestUser, "grafana", "openid groups", "https://api.example.com", 1600000000, 1600086400))
+
+	loaded, err := provider.LoadOIDCConsent(unitTestUser, "grafana", now)
+	require.NoError(t, err)
+	assert.Equal(t, consent, *loaded)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT username, client_id, scopes, audience, created_at, expires_at FROM %s WHERE username=\\? AND client_id=\\? AND expires_at>\\?", oidcConsentsTableName)).
+		WithArgs(unitTestUser, "unknown", int64(1600000100)).
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	_, err = provider.LoadOIDCConsent(unitTestUser, "unknown", now)
+	assert.Equal(t, ErrNoOIDCConsent, err)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT username, client_id, scopes, audience, created_at, expires_at FROM %s WHERE username=\\? AND expires_at>\\? ORDER BY created_at", oidcConsentsTableName)).
+		WithArgs(unitTestUser, int64(1600000100)).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(unitTestUser, "grafana", "openid groups", "https://api.example.com", 1600000000, 1600086400).
+			AddRow(unitTestUser, "gitea", "openid", "", 1600000050, 1600086450))
+
+	consents, err := provider.LoadUserOIDCConsents(unitTestUser, now)
+	require.NoError(t, err)
+	require.Len(t, consents, 2)
+	assert.Equal(t, consent, consents[0])
+	assert.Equal(t, []string{"openid"}, consents[1].Scopes)
+	assert.Len(t, consents[1].Audience, 0)
+
+	mock.ExpectExec(
+		fmt.Sprintf("DELETE FROM %s WHERE username=\\? AND client_id=\\?", oidcConsentsTableName)).
+		WithArgs(unitTestUser, "grafana").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = provider.DeleteOIDCConsent(unitTestUser, "grafana")
+	assert.NoError(t, err)
+
+	mock.ExpectExec(
+		fmt.Sprintf("DELETE FROM %s WHERE username=\\? AND client_id=\\?", oidcConsentsTableName)).
+		WithArgs(unitTestUser, "grafana").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = provider.DeleteOIDCConsent(unitTestUser, "grafana")
+	assert.Equal(t, ErrNoOIDCConsent, err)
+
+	mock.ExpectExec(
+		fmt.Sprintf("DELETE FROM %s WHERE expires_at<\\?", oidcConsentsTableName)).
+		WithArgs(int64(1600000100)).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	pruned, err := provider.PruneOIDCConsents(now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), pruned)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLProviderMethodsOIDCClients(t *testing.T) {
+	provider, mock := NewSQLMockProvider()
+
+	expectSchemaUpToDate(mock)
+
+	err := provider.initialize(provider.db)
+	assert.NoError(t, err)
+
+	columns := []string{"id", "description", "secret_hash", "policy", "redirect_uris", "grant_types", "response_types",
+		"scopes", "post_logout_redirect_uris", "backchannel_logout_uri", "frontchannel_logout_uri", "registered_by", "created_at"}
+
+	client := models.OIDCClient{
+		ID:                     "5b2c1e0a-8f3d-4c6b-9a7e-1d2f3a4b5c6d",
+		Description:            "Grafana",
+		SecretHash:             "$argon2id$v=19$m=65536,t=3,p=4$c2FsdA$aGFzaA",
+		Policy:                 "two_factor",
+		RedirectURIs:           []string{"https://grafana.example.com/login/generic_oauth", "https://grafana.example.com/callback"},
+		GrantTypes:             []string{"authorization_code", "refresh_token"},
+		ResponseTypes:          []string{"code"},
+		Scopes:                 []string{"openid", "groups"},
+		PostLogoutRedirectURIs: []string{"https://grafana.example.com/logged-out"},
+		BackChannelLogoutURI:   "https://grafana.example.com/logout",
+		RegisteredBy:           "ci",
+		CreatedAt:              time.Unix(1600000000, 0),
+	}
+
+	mock.ExpectExec(
+		fmt.Sprintf("INSERT INTO %s \\(id, description, secret_hash, policy, redirect_uris, grant_types, response_types, scopes, post_logout_redirect_uris, backchannel_logout_uri, frontchannel_logout_uri, registered_by, created_at\\) VALUES \\(\\?, \\?, \\?, \\?, \\?, \\?, \\?, \\?, \\?, \\?, \\?, \\?, \\?\\)", oidcClientsTableName)).
+		WithArgs(client.ID, "Grafana", client.SecretHash, "two_factor", "https://grafana.example.com/login/generic_oauth https://grafana.example.com/callback",
+			"authorization_code refresh_token", "code", "openid groups", "https://grafana.example.com/logged-out",
+			"https://grafana.example.com/logout", "", "ci", int64(1600000000)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = provider.SaveOIDCClient(client)
+	assert.NoError(t, err)
+
+	mock.ExpectQuery(
+		fmt.Sprintf("SELECT id, description, secret_hash, policy, redirect_uris, grant_types, response_types, scopes, post_logout_redirect_uris, backchannel_logout_uri, frontchannel_logout_uri, registered_by, created_at FROM %s ORDER BY created_at", oidcClientsTableName)).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(client.ID, "Grafana", client.SecretHash, "two_factor", "https://grafana.example.com/login/generic_oauth https://grafana.example.com/callback",
+				"authorization_code refresh_token", "code", "openid groups", "https://grafana.example.com/logged-out",
+				"https://grafana.example.com/logout", "", "ci", 1600000000).
+			AddRow("gitea", "Gitea", "hash", "one_factor", "https://gitea.example.com/callback", "authorization_code", "code", "openid", "", "", "", "admin", 1600000050))
+
+	clients, err := provider.LoadOIDCClients()
+	require.NoError(t, err)
+	require.Len(t, clients, 2)
+	assert.Equal(t, client, clients[0])
+	assert.Equal(t, "gitea", clients[1].ID)
+	assert.Len(t, clients[1].PostLogoutRedirectURIs, 0)
+
+	mock.ExpectExec(
+		fmt.Sprintf("DELETE FROM %s WHERE id=\\?", oidcClientsTableName)).
+		WithArgs("gitea").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = provider.DeleteOIDCClient("gitea")
+	assert.NoError(t, err)
+
+	mock.ExpectExec(
+		fmt.Sprintf("DELETE FROM %s WHERE id=\\?", oidcClientsTableName)).
+		WithArgs("gitea").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = provider.DeleteOIDCClient("gitea")
+	assert.Equal(t, ErrNoOIDCClient, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
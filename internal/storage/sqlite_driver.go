@@ -0,0 +1,12 @@
+//go:build !sqlite_cgo
+// +build !sqlite_cgo
+
+package storage
+
+import (
+	_ "modernc.org/sqlite" // Load the pure Go SQLite driver.
+)
+
+// sqliteDriverName is the name of the SQLite driver, the default builds use the pure Go driver so the binaries can be
+// built without cgo, e.g. statically for musl based distributions or cross-compiled for ARM.
+const sqliteDriverName = "sqlite"
@@ -0,0 +1,12 @@
+//go:build sqlite_cgo
+// +build sqlite_cgo
+
+package storage
+
+import (
+	_ "github.com/mattn/go-sqlite3" // Load the cgo SQLite driver.
+)
+
+// sqliteDriverName is the name of the SQLite driver, the builds with the sqlite_cgo tag use the cgo driver linked to
+// the C library for the platforms the pure Go driver doesn't support. They require cgo.
+const sqliteDriverName = "sqlite3"
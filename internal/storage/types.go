@@ -13,6 +13,58 @@ func (s SchemaVersion) ToString() string {
 	return strconv.Itoa(int(s))
 }
 
+// SchemaUpgrader is implemented by the providers upgrading the schema of the storage when they're initialized.
+type SchemaUpgrader interface {
+	SchemaUpgrade() (from, to SchemaVersion, upgraded bool)
+}
+
+// SchemaDowngrader is implemented by the providers able to downgrade the schema of the storage to a previous version.
+type SchemaDowngrader interface {
+	SchemaDowngrade(version SchemaVersion) (from SchemaVersion, err error)
+}
+
+// SecretsEncrypter is implemented by the providers encrypting the secrets of the second factor devices at rest.
+type SecretsEncrypter interface {
+	ChangeEncryptionKey(key string) (count int, err error)
+	RotateEncryptionKey(batchSize int) (count int, err error)
+	CheckEncryption() (check EncryptionCheck, err error)
+}
+
+// EncryptionCheck is the result of the check of the encryption of the secrets of the second factor devices.
+type EncryptionCheck struct {
+	// Total is the number of secrets checked.
+	Total int
+
+	// Plaintext is the number of secrets stored in plaintext while an encryption key is configured.
+	Plaintext int
+
+	// Previous is the number of secrets still encrypted with the previous encryption key.
+	Previous int
+
+	// Failures are the secrets which can't be decrypted with the configured keys.
+	Failures []EncryptionCheckFailure
+}
+
+// EncryptionCheckFailure is a secret which can't be decrypted.
+type EncryptionCheckFailure struct {
+	Kind     string
+	Username string
+	Err      error
+}
+
+// encryptingProvider is a built-in provider whose secrets are encrypted with the storage encryption key.
+type encryptingProvider interface {
+	Provider
+	useEncryptionKey(key, previousKey string) error
+}
+
 type transaction interface {
 	Exec(query string, args ...interface{}) (sql.Result, error)
 }
+
+// sqlTransaction is a transaction which is either a real one or a dry-run one.
+type sqlTransaction interface {
+	transaction
+	Commit() error
+	Rollback() error
+}
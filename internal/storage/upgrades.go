@@ -50,6 +50,22 @@ func (p *SQLProvider) upgradeFinalize(tx transaction, version SchemaVersion) err
 	return nil
 }
 
+// upgradeSchemaToVersion upgrades the schema to the provided version by creating the tables introduced in that version
+// and running its alter statements.
+func (p *SQLProvider) upgradeSchemaToVersion(tx transaction, version SchemaVersion, tables []string) error {
+	err := p.upgradeCreateTableStatements(tx, p.sqlUpgradesCreateTableStatements[version], tables)
+	if err != nil {
+		return err
+	}
+
+	err = p.upgradeRunMultipleStatements(tx, p.sqlUpgradesStatements[version])
+	if err != nil {
+		return fmt.Errorf("Unable to alter tables: %v", err)
+	}
+
+	return p.upgradeFinalize(tx, version)
+}
+
 // upgradeSchemaToVersion001 upgrades the schema to version 1.
 func (p *SQLProvider) upgradeSchemaToVersion001(tx transaction, tables []string) error {
 	version := SchemaVersion(1)
@@ -74,3 +90,29 @@ func (p *SQLProvider) upgradeSchemaToVersion001(tx transaction, tables []string)
 
 	return nil
 }
+
+// missingSchemaTables returns the tables of the schema version which don't exist, i.e. the tables created by the
+// upgrades up to that version and not dropped since.
+func missingSchemaTables(version SchemaVersion, existingTables []string) (missing []string) {
+	expected := map[string]bool{}
+
+	for v := SchemaVersion(1); v <= version; v++ {
+		for table := range sqlUpgradeCreateTableStatements[v] {
+			expected[table] = true
+		}
+
+		for _, table := range sqlUpgradesDroppedTableNames[v] {
+			delete(expected, table)
+		}
+	}
+
+	for table := range expected {
+		if !utils.IsStringInSlice(table, existingTables) {
+			missing = append(missing, table)
+		}
+	}
+
+	sort.Strings(missing)
+
+	return missing
+}
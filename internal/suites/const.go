@@ -55,3 +55,7 @@ const defaultChromeDriverPort = "4444"
 
 const testUsername = "john"
 const testPassword = "password"
+
+// lockedUsername is the user locked out by the authentication backend, i.e. by the password policy of OpenLDAP or
+// disabled in Active Directory. Its password is testPassword.
+const lockedUsername = "dave"
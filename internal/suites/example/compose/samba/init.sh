@@ -77,6 +77,8 @@ appProvision () {
   samba-tool user create harry password --userou=OU=Users --use-username-as-cn --given-name Harry --surname Potter --mail-address harry.potter@authelia.com
   samba-tool user create bob password --userou=OU=Users --use-username-as-cn --given-name Bob --surname Dylan --mail-address bob.dylan@authelia.com
   samba-tool user create james password --userou=OU=Users --use-username-as-cn --given-name James --surname Dean --mail-address james.dean@authelia.com
+  samba-tool user create dave password --userou=OU=Users --use-username-as-cn --given-name Dave --surname Grohl --mail-address dave.grohl@authelia.com
+  samba-tool user disable dave
   samba-tool group addmembers "dev" john,bob
   samba-tool group addmembers "admins" john
 }
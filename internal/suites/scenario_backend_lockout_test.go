@@ -0,0 +1,72 @@
+package suites
+
+import (
+	"context"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// BackendLockoutScenario checks the accounts locked out by the authentication backend can't log in even though their
+// password is correct. It requires a backend where lockedUsername is locked out, i.e. OpenLDAP or Active Directory.
+type BackendLockoutScenario struct {
+	*SeleniumSuite
+}
+
+func NewBackendLockoutScenario() *BackendLockoutScenario {
+	return &BackendLockoutScenario{SeleniumSuite: new(SeleniumSuite)}
+}
+
+func (s *BackendLockoutScenario) SetupSuite() {
+	wds, err := StartWebDriver()
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	s.WebDriverSession = wds
+}
+
+func (s *BackendLockoutScenario) TearDownSuite() {
+	err := s.WebDriverSession.Stop()
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func (s *BackendLockoutScenario) SetupTest() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	s.doLogout(ctx, s.T())
+	s.doVisit(s.T(), HomeBaseURL)
+	s.verifyIsHome(ctx, s.T())
+}
+
+func (s *BackendLockoutScenario) TestShouldRejectUserLockedOutByBackend() {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	s.doLoginOneFactor(ctx, s.T(), lockedUsername, testPassword, false, "")
+	s.verifyNotificationDisplayed(ctx, s.T(), "Incorrect username or password.")
+	s.verifyIsFirstFactorPage(ctx, s.T())
+}
+
+func (s *BackendLockoutScenario) TestShouldNotLockOutOtherUsers() {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	s.doLoginOneFactor(ctx, s.T(), testUsername, testPassword, false, "")
+	s.verifyIsSecondFactorPage(ctx, s.T())
+}
+
+func TestRunBackendLockoutScenario(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping suite test in short mode")
+	}
+
+	suite.Run(t, NewBackendLockoutScenario())
+}
@@ -34,6 +34,10 @@ func (s *ActiveDirectorySuite) TestSigninEmailScenario() {
 	suite.Run(s.T(), NewSigninEmailScenario())
 }
 
+func (s *ActiveDirectorySuite) TestBackendLockoutScenario() {
+	suite.Run(s.T(), NewBackendLockoutScenario())
+}
+
 func TestActiveDirectorySuite(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping suite test in short mode")
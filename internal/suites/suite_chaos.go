@@ -0,0 +1,67 @@
+package suites
+
+import (
+	"fmt"
+	"time"
+)
+
+var chaosSuiteName = "Chaos"
+
+var chaosDockerEnvironment = NewDockerEnvironment([]string{
+	"internal/suites/docker-compose.yml",
+	"internal/suites/Chaos/docker-compose.yml",
+	"internal/suites/example/compose/authelia/docker-compose.backend.{}.yml",
+	"internal/suites/example/compose/authelia/docker-compose.frontend.{}.yml",
+	"internal/suites/example/compose/nginx/backend/docker-compose.yml",
+	"internal/suites/example/compose/nginx/portal/docker-compose.yml",
+	"internal/suites/example/compose/smtp/docker-compose.yml",
+	"internal/suites/example/compose/ldap/docker-compose.yml",
+	"internal/suites/example/compose/redis/docker-compose.yml",
+	"internal/suites/example/compose/mysql/docker-compose.yml",
+	"internal/suites/example/compose/toxiproxy/docker-compose.yml",
+})
+
+func init() {
+	setup := func(suitePath string) error {
+		if err := chaosDockerEnvironment.Up(); err != nil {
+			return err
+		}
+
+		return waitUntilAutheliaIsReady(chaosDockerEnvironment, chaosSuiteName)
+	}
+
+	displayAutheliaLogs := func() error {
+		backendLogs, err := chaosDockerEnvironment.Logs("authelia-backend", nil)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(backendLogs)
+
+		toxiproxyLogs, err := chaosDockerEnvironment.Logs("toxiproxy", nil)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(toxiproxyLogs)
+
+		return nil
+	}
+
+	teardown := func(suitePath string) error {
+		err := chaosDockerEnvironment.Down()
+		return err
+	}
+
+	GlobalRegistry.Register(chaosSuiteName, Suite{
+		SetUp:           setup,
+		SetUpTimeout:    5 * time.Minute,
+		OnSetupTimeout:  displayAutheliaLogs,
+		OnError:         displayAutheliaLogs,
+		TestTimeout:     5 * time.Minute,
+		TearDown:        teardown,
+		TearDownTimeout: 2 * time.Minute,
+		Description: "This suite injects faults in the connections to LDAP, redis and MySQL with toxiproxy to check " +
+			"Authelia degrades gracefully and recovers",
+	})
+}
@@ -0,0 +1,187 @@
+package suites
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ChaosSuite struct {
+	suite.Suite
+
+	toxiproxy *ToxiproxyClient
+}
+
+func NewChaosSuite() *ChaosSuite {
+	return &ChaosSuite{toxiproxy: NewToxiproxyClient(ToxiproxyBaseURL)}
+}
+
+func (s *ChaosSuite) SetupTest() {
+	s.Require().NoError(s.toxiproxy.Reset())
+}
+
+func (s *ChaosSuite) TearDownTest() {
+	s.Require().NoError(s.toxiproxy.Reset())
+}
+
+// newClient creates a client keeping the session cookie of the user.
+func (s *ChaosSuite) newClient() *http.Client {
+	jar, err := cookiejar.New(nil)
+	s.Require().NoError(err)
+
+	client := NewHTTPClient()
+	client.Jar = jar
+	client.Timeout = time.Minute
+
+	return client
+}
+
+// login authenticates the user with the first factor and returns the status code and the status of the reply.
+func (s *ChaosSuite) login(client *http.Client) (statusCode int, status string) {
+	body, err := json.Marshal(map[string]string{"username": testUsername, "password": testPassword})
+	s.Require().NoError(err)
+
+	res, err := client.Post(fmt.Sprintf("%s/api/firstfactor", AutheliaBaseURL), "application/json", bytes.NewReader(body))
+	s.Require().NoError(err)
+
+	defer res.Body.Close()
+
+	reply := struct {
+		Status string `json:"status"`
+	}{}
+
+	s.Require().NoError(json.NewDecoder(res.Body).Decode(&reply))
+
+	return res.StatusCode, reply.Status
+}
+
+// verify returns the status code of the verification of a request to the one factor domain.
+func (s *ChaosSuite) verify(client *http.Client) (statusCode int) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/api/verify", AutheliaBaseURL), nil)
+	s.Require().NoError(err)
+
+	req.Header.Set("X-Forwarded-Proto", "https")
+	req.Header.Set("X-Original-URL", SingleFactorBaseURL)
+
+	res, err := client.Do(req)
+	s.Require().NoError(err)
+
+	defer res.Body.Close()
+
+	return res.StatusCode
+}
+
+// assertHealthy asserts Authelia still serves the requests, i.e. it didn't crash because of the fault.
+func (s *ChaosSuite) assertHealthy() {
+	res, err := NewHTTPClient().Get(fmt.Sprintf("%s/api/health", AutheliaBaseURL))
+	s.Require().NoError(err)
+
+	defer res.Body.Close()
+
+	s.Assert().Equal(http.StatusOK, res.StatusCode)
+}
+
+// assertRecovers asserts the user can authenticate again shortly after the fault is removed.
+func (s *ChaosSuite) assertRecovers() {
+	s.Assert().Eventually(func() bool {
+		client := s.newClient()
+
+		statusCode, status := s.login(client)
+
+		return statusCode == http.StatusOK && status == "OK" && s.verify(client) == http.StatusOK
+	}, 30*time.Second, time.Second)
+}
+
+func (s *ChaosSuite) TestShouldAuthenticateWhenLDAPIsSlow() {
+	s.Require().NoError(s.toxiproxy.AddToxic("ldap", Toxic{
+		Name:       "latency",
+		Type:       "latency",
+		Stream:     "downstream",
+		Toxicity:   1,
+		Attributes: map[string]interface{}{"latency": 1500},
+	}))
+
+	client := s.newClient()
+	start := time.Now()
+
+	statusCode, status := s.login(client)
+
+	s.Assert().Equal(http.StatusOK, statusCode)
+	s.Assert().Equal("OK", status)
+	s.Assert().GreaterOrEqual(int64(time.Since(start)), int64(1500*time.Millisecond))
+	s.Assert().Equal(http.StatusOK, s.verify(client))
+}
+
+func (s *ChaosSuite) TestShouldFailAuthenticationGracefullyWhenLDAPIsDown() {
+	s.Require().NoError(s.toxiproxy.SetEnabled("ldap", false))
+
+	statusCode, status := s.login(s.newClient())
+
+	s.Assert().Equal(http.StatusUnauthorized, statusCode)
+	s.Assert().Equal("KO", status)
+	s.assertHealthy()
+
+	s.Require().NoError(s.toxiproxy.SetEnabled("ldap", true))
+	s.assertRecovers()
+}
+
+func (s *ChaosSuite) TestShouldFailAuthenticationGracefullyWhenRedisIsDown() {
+	s.Require().NoError(s.toxiproxy.SetEnabled("redis", false))
+
+	client := s.newClient()
+	statusCode, status := s.login(client)
+
+	s.Assert().Equal(http.StatusUnauthorized, statusCode)
+	s.Assert().Equal("KO", status)
+
+	// The sessions can't be loaded so the requests are considered unauthenticated rather than failing.
+	s.Assert().Equal(http.StatusUnauthorized, s.verify(client))
+	s.assertHealthy()
+
+	s.Require().NoError(s.toxiproxy.SetEnabled("redis", true))
+	s.assertRecovers()
+}
+
+func (s *ChaosSuite) TestShouldRecoverWhenRedisRestarts() {
+	client := s.newClient()
+
+	statusCode, status := s.login(client)
+	s.Require().Equal(http.StatusOK, statusCode)
+	s.Require().Equal("OK", status)
+	s.Require().Equal(http.StatusOK, s.verify(client))
+
+	s.Require().NoError(chaosDockerEnvironment.Restart("redis"))
+
+	// The session might be lost with the restart, but the verification must not fail.
+	s.Assert().Contains([]int{http.StatusOK, http.StatusUnauthorized}, s.verify(client))
+	s.assertHealthy()
+	s.assertRecovers()
+}
+
+func (s *ChaosSuite) TestShouldFailAuthenticationGracefullyWhenSQLIsDown() {
+	s.Require().NoError(s.toxiproxy.SetEnabled("mysql", false))
+
+	statusCode, status := s.login(s.newClient())
+
+	// The authentication attempts can't be recorded, so the authentication fails.
+	s.Assert().Equal(http.StatusUnauthorized, statusCode)
+	s.Assert().Equal("KO", status)
+	s.assertHealthy()
+
+	s.Require().NoError(s.toxiproxy.SetEnabled("mysql", true))
+	s.assertRecovers()
+}
+
+func TestChaosSuite(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping suite test in short mode")
+	}
+
+	suite.Run(t, NewChaosSuite())
+}
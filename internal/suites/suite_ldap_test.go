@@ -34,6 +34,10 @@ func (s *LDAPSuite) TestSigninEmailScenario() {
 	suite.Run(s.T(), NewSigninEmailScenario())
 }
 
+func (s *LDAPSuite) TestBackendLockoutScenario() {
+	suite.Run(s.T(), NewBackendLockoutScenario())
+}
+
 func TestLDAPSuite(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping suite test in short mode")
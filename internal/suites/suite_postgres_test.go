@@ -22,6 +22,14 @@ func (s *PostgresSuite) TestTwoFactorScenario() {
 	suite.Run(s.T(), NewTwoFactorScenario())
 }
 
+func (s *PostgresSuite) TestResetPassword() {
+	suite.Run(s.T(), NewResetPasswordScenario())
+}
+
+func (s *PostgresSuite) TestRegulationScenario() {
+	suite.Run(s.T(), NewRegulationScenario())
+}
+
 func TestPostgresSuite(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping suite test in short mode")
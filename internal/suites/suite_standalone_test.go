@@ -73,9 +73,15 @@ func (s *StandaloneWebDriverSuite) TestShouldCheckUserIsAskedToRegisterDevice()
 	username := "john"
 	password := "password"
 
-	// Clean up any TOTP secret already in DB.
+	// Clean up any TOTP device already in DB.
 	provider := storage.NewSQLiteProvider("/tmp/db.sqlite3")
-	require.NoError(s.T(), provider.DeleteTOTPSecret(username))
+
+	devices, err := provider.LoadTOTPDevices(username)
+	require.NoError(s.T(), err)
+
+	for _, device := range devices {
+		require.NoError(s.T(), provider.DeleteTOTPDevice(username, device.ID))
+	}
 
 	// Login one factor.
 	s.doLoginOneFactor(ctx, s.T(), username, password, false, "")
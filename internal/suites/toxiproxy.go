@@ -0,0 +1,68 @@
+package suites
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// ToxiproxyBaseURL the base URL of the toxiproxy API used by the Chaos suite.
+var ToxiproxyBaseURL = "http://toxiproxy.example.com:8474"
+
+// Toxic is a fault injected by toxiproxy in the connections of a proxy, e.g. a latency or a timeout.
+type Toxic struct {
+	Name       string                 `json:"name"`
+	Type       string                 `json:"type"`
+	Stream     string                 `json:"stream"`
+	Toxicity   float32                `json:"toxicity"`
+	Attributes map[string]interface{} `json:"attributes"`
+}
+
+// ToxiproxyClient is a minimal client of the toxiproxy API.
+type ToxiproxyClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewToxiproxyClient creates a client of the toxiproxy API.
+func NewToxiproxyClient(baseURL string) *ToxiproxyClient {
+	return &ToxiproxyClient{baseURL: baseURL, client: &http.Client{}}
+}
+
+// AddToxic injects the toxic in the connections of the proxy.
+func (c *ToxiproxyClient) AddToxic(proxy string, toxic Toxic) error {
+	return c.post(fmt.Sprintf("/proxies/%s/toxics", proxy), toxic)
+}
+
+// SetEnabled enables or disables the proxy, disabling it closes all its connections and refuses the new ones.
+func (c *ToxiproxyClient) SetEnabled(proxy string, enabled bool) error {
+	return c.post(fmt.Sprintf("/proxies/%s", proxy), map[string]bool{"enabled": enabled})
+}
+
+// Reset enables all the proxies and removes all their toxics.
+func (c *ToxiproxyClient) Reset() error {
+	return c.post("/reset", nil)
+}
+
+func (c *ToxiproxyClient) post(path string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.client.Post(c.baseURL+path, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		message, _ := ioutil.ReadAll(res.Body)
+		return fmt.Errorf("toxiproxy replied to %s with status %d: %s", path, res.StatusCode, message)
+	}
+
+	return nil
+}
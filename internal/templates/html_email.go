@@ -4,7 +4,8 @@ import (
 	"text/template"
 )
 
-// HTMLEmailTemplate the template of email that the user will receive for identity verification.
+// HTMLEmailTemplate the template of email that the user will receive for identity verification. The body and the
+// button can be overridden or omitted to send other notifications.
 var HTMLEmailTemplate *template.Template
 
 func init() {
@@ -311,8 +312,8 @@ const emailHTMLContent = `
                                              <tr>
                                                 <td style="font-family: Helvetica, arial, sans-serif; font-size: 16px; color: #333333; text-align:center; line-height: 30px;"
                                                    st-title="fulltext-content">
-                                                   This email has been sent to you in order to validate your identity.
-                                                   If you did not initiate the process your credentials might have been compromised. You should reset your password and contact an administrator.
+                                                   {{if .body}}{{.body}}{{else}}This email has been sent to you in order to validate your identity.
+                                                   If you did not initiate the process your credentials might have been compromised. You should reset your password and contact an administrator.{{end}}
                                                 </td>
                                              </tr>
                                              <!-- End of Title -->
@@ -323,6 +324,7 @@ const emailHTMLContent = `
                                                    &nbsp;</td>
                                              </tr>
                                              <!-- End of spacing -->
+                                             {{if .url}}
                                              <!-- content -->
                                              <tr>
                                                 <td style="font-family: Helvetica, arial, sans-serif; font-size: 16px; color: #666666; text-align:center; line-height: 30px;"
@@ -331,6 +333,17 @@ const emailHTMLContent = `
                                                 </td>
                                              </tr>
                                              <!-- End of content -->
+                                             {{end}}
+                                             {{if .remoteIP}}
+                                             <!-- origin -->
+                                             <tr>
+                                                <td style="font-family: Helvetica, arial, sans-serif; font-size: 12px; color: #999999; text-align:center; line-height: 30px;"
+                                                   st-content="fulltext-content">
+                                                   This request was made from {{.remoteIP}}{{if .location}} ({{.location}}){{end}}.
+                                                </td>
+                                             </tr>
+                                             <!-- End of origin -->
+                                             {{end}}
                                           </tbody>
                                        </table>
                                     </td>
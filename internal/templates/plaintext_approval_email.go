@@ -0,0 +1,26 @@
+package templates
+
+import (
+	"text/template"
+)
+
+// PlainTextApprovalEmailTemplate the template of email that the user will receive when a change made to their account
+// by an operator awaits their approval.
+var PlainTextApprovalEmailTemplate *template.Template
+
+func init() {
+	t, err := template.New("text_approval_email_template").Parse(emailApprovalPlainTextContent)
+	if err != nil {
+		panic(err)
+	}
+
+	PlainTextApprovalEmailTemplate = t
+}
+
+const emailApprovalPlainTextContent = `
+{{.body}}
+
+To approve it please visit the following URL: {{.url}}
+
+Please contact an administrator if you did not ask for it.
+`
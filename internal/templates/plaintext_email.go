@@ -21,6 +21,8 @@ This email has been sent to you in order to validate your identity.
 If you did not initiate the process your credentials might have been compromised. You should reset your password and contact an administrator.
 
 To setup your 2FA please visit the following URL: {{.url}}
-
+{{if .remoteIP}}
+This request was made from {{.remoteIP}}{{if .location}} ({{.location}}){{end}}.
+{{end}}
 Please contact an administrator if you did not initiate the process.
 `
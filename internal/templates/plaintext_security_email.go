@@ -0,0 +1,26 @@
+package templates
+
+import (
+	"text/template"
+)
+
+// PlainTextSecurityEmailTemplate the template of email that the user will receive when a security related change is
+// made to their account.
+var PlainTextSecurityEmailTemplate *template.Template
+
+func init() {
+	t, err := template.New("text_security_email_template").Parse(emailSecurityPlainTextContent)
+	if err != nil {
+		panic(err)
+	}
+
+	PlainTextSecurityEmailTemplate = t
+}
+
+const emailSecurityPlainTextContent = `
+{{.body}}
+{{if .url}}
+To reset your password please visit the following URL: {{.url}}
+{{end}}{{if .remoteIP}}
+This change was made from {{.remoteIP}}{{if .location}} ({{.location}}){{end}}.
+{{end}}`
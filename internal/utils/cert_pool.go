@@ -0,0 +1,213 @@
+package utils
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/logging"
+)
+
+// X509CertPool is a certificate pool loaded from the system PKI, a certificates directory and extra certificate files
+// which can be reloaded when they change.
+type X509CertPool struct {
+	directory string
+	files     []string
+
+	mutex sync.RWMutex
+	pool  *x509.CertPool
+
+	// The pools trusting extra certificates in addition to the certificates of this pool, they are reloaded along with
+	// it.
+	extensions []*X509CertPool
+}
+
+// LoadX509CertPool loads the certificates of the system PKI and of the directory specified into a X509CertPool.
+func LoadX509CertPool(directory string) (certPool *X509CertPool, errors []error, nonFatalErrors []error) {
+	certPool = &X509CertPool{directory: directory}
+
+	certPool.pool, errors, nonFatalErrors = NewX509CertPool(directory)
+
+	return certPool, errors, nonFatalErrors
+}
+
+// CertPool returns the current certificates of the pool.
+func (p *X509CertPool) CertPool() *x509.CertPool {
+	if p == nil {
+		return nil
+	}
+
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.pool
+}
+
+// Extend returns a pool trusting the certificate files in addition to the certificates of this pool.
+func (p *X509CertPool) Extend(files []string) (certPool *X509CertPool, errors []error) {
+	certPool = &X509CertPool{}
+
+	if p != nil {
+		certPool.directory = p.directory
+		certPool.files = append(certPool.files, p.files...)
+	}
+
+	certPool.files = append(certPool.files, files...)
+
+	certPool.pool, errors, _ = NewX509CertPool(certPool.directory, certPool.files...)
+
+	if p != nil {
+		p.mutex.Lock()
+		p.extensions = append(p.extensions, certPool)
+		p.mutex.Unlock()
+	}
+
+	return certPool, errors
+}
+
+// Reload loads the certificates of the pool and of its extensions again. The current certificates of a pool are kept
+// when its certificates can't be loaded.
+func (p *X509CertPool) Reload() (errors []error) {
+	pool, errors, _ := NewX509CertPool(p.directory, p.files...)
+
+	p.mutex.Lock()
+
+	if len(errors) == 0 {
+		p.pool = pool
+	}
+
+	extensions := p.extensions
+
+	p.mutex.Unlock()
+
+	for _, extension := range extensions {
+		errors = append(errors, extension.Reload()...)
+	}
+
+	return errors
+}
+
+// Watch reloads the pool when the files of the certificates directory or the certificate files of the extensions
+// change. The extensions must be created before the pool is watched.
+func (p *X509CertPool) Watch() error {
+	directories := p.directories()
+	if len(directories) == 0 {
+		return nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	for _, directory := range directories {
+		if err = watcher.Add(directory); err != nil {
+			_ = watcher.Close()
+			return err
+		}
+	}
+
+	go func() {
+		logger := logging.Logger()
+
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+
+				if event.Op == fsnotify.Chmod {
+					continue
+				}
+
+				logger.Debugf("Reloading the certificates after %s changed", event.Name)
+
+				for _, err := range p.Reload() {
+					logger.Errorf("Unable to reload the certificates: %s", err)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+
+				logger.Errorf("Error watching the certificates: %s", err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// directories returns the directories holding the certificates of the pool and of its extensions.
+func (p *X509CertPool) directories() (directories []string) {
+	seen := map[string]bool{}
+
+	add := func(directory string) {
+		if directory != "" && !seen[directory] {
+			seen[directory] = true
+			directories = append(directories, directory)
+		}
+	}
+
+	add(p.directory)
+
+	for _, file := range p.files {
+		add(filepath.Dir(file))
+	}
+
+	p.mutex.RLock()
+	extensions := p.extensions
+	p.mutex.RUnlock()
+
+	for _, extension := range extensions {
+		for _, directory := range extension.directories() {
+			add(directory)
+		}
+	}
+
+	return directories
+}
+
+// verifyConnection verifies the certificate chain presented by the server against the current certificates of the
+// pool, the same way crypto/tls does, and checks the certificates are not revoked. The server name of the connection
+// is used when none is configured, it's not available when connecting to an IP address.
+func (p *X509CertPool) verifyConnection(state tls.ConnectionState, serverName string, revocation schema.TLSRevocationConfig) error {
+	if len(state.PeerCertificates) == 0 {
+		return errors.New("tls: the server didn't present any certificate")
+	}
+
+	if serverName == "" {
+		serverName = state.ServerName
+	}
+
+	if serverName == "" {
+		return errors.New("tls: either ServerName or InsecureSkipVerify must be specified in the tls.Config")
+	}
+
+	intermediates := x509.NewCertPool()
+
+	for _, certificate := range state.PeerCertificates[1:] {
+		intermediates.AddCert(certificate)
+	}
+
+	chains, err := state.PeerCertificates[0].Verify(x509.VerifyOptions{
+		Roots:         p.CertPool(),
+		DNSName:       serverName,
+		Intermediates: intermediates,
+	})
+	if err != nil {
+		return err
+	}
+
+	if !revocationEnabled(revocation) {
+		return nil
+	}
+
+	return checkRevocation(revocation, chains[0], state.OCSPResponse)
+}
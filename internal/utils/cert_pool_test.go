@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"crypto/tls"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+// newTestTLSServer starts a TLS server and returns its address along with its certificate encoded in PEM.
+func newTestTLSServer(t *testing.T) (address string, certificate []byte) {
+	server := httptest.NewTLSServer(nil)
+	t.Cleanup(server.Close)
+
+	certificate = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	return server.Listener.Addr().String(), certificate
+}
+
+func handshake(address string, tlsConfig *tls.Config) error {
+	conn, err := tls.Dial("tcp", address, tlsConfig)
+	if err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
+
+func TestShouldTrustReloadedCertificates(t *testing.T) {
+	address, certificate := newTestTLSServer(t)
+	directory := t.TempDir()
+
+	certPool, errs, _ := LoadX509CertPool(directory)
+	require.Len(t, errs, 0)
+
+	tlsConfig := NewTLSConfig(&schema.TLSConfig{ServerName: "example.com"}, tls.VersionTLS12, certPool)
+
+	err := handshake(address, tlsConfig)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "certificate signed by unknown authority")
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(directory, "ca.pem"), certificate, 0600))
+	require.Len(t, certPool.Reload(), 0)
+
+	assert.NoError(t, handshake(address, tlsConfig))
+
+	// The server name is still verified.
+	tlsConfig = NewTLSConfig(&schema.TLSConfig{ServerName: "authelia.com"}, tls.VersionTLS12, certPool)
+
+	err = handshake(address, tlsConfig)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "certificate is valid for")
+}
+
+func TestShouldKeepCertificatesWhenReloadFails(t *testing.T) {
+	address, certificate := newTestTLSServer(t)
+	directory := t.TempDir()
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(directory, "ca.pem"), certificate, 0600))
+
+	certPool, errs, _ := LoadX509CertPool(directory)
+	require.Len(t, errs, 0)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(directory, "ca.pem"), []byte("invalid"), 0600))
+
+	errs = certPool.Reload()
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "could not import certificate ca.pem")
+
+	assert.NoError(t, handshake(address, NewTLSConfig(&schema.TLSConfig{ServerName: "example.com"}, tls.VersionTLS12, certPool)))
+}
+
+func TestShouldTrustCertificateAuthoritiesOfTheComponent(t *testing.T) {
+	address, certificate := newTestTLSServer(t)
+	directory := t.TempDir()
+	authority := filepath.Join(t.TempDir(), "ca.pem")
+
+	require.NoError(t, ioutil.WriteFile(authority, certificate, 0600))
+
+	certPool, errs, _ := LoadX509CertPool(directory)
+	require.Len(t, errs, 0)
+
+	assert.Error(t, handshake(address, NewTLSConfig(&schema.TLSConfig{ServerName: "example.com"}, tls.VersionTLS12, certPool)))
+
+	tlsConfig := NewTLSConfig(&schema.TLSConfig{
+		ServerName:             "example.com",
+		CertificateAuthorities: []string{authority},
+	}, tls.VersionTLS12, certPool)
+
+	assert.NoError(t, handshake(address, tlsConfig))
+	assert.ElementsMatch(t, []string{directory, filepath.Dir(authority)}, certPool.directories())
+}
+
+func TestShouldWatchCertificatesDirectory(t *testing.T) {
+	address, certificate := newTestTLSServer(t)
+	directory := t.TempDir()
+
+	certPool, errs, _ := LoadX509CertPool(directory)
+	require.Len(t, errs, 0)
+	require.NoError(t, certPool.Watch())
+
+	tlsConfig := NewTLSConfig(&schema.TLSConfig{ServerName: "example.com"}, tls.VersionTLS12, certPool)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(directory, "ca.pem"), certificate, 0600))
+
+	assert.Eventually(t, func() bool {
+		return handshake(address, tlsConfig) == nil
+	}, 5*time.Second, 50*time.Millisecond)
+}
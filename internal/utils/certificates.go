@@ -12,23 +12,72 @@ import (
 	"github.com/authelia/authelia/internal/logging"
 )
 
-// NewTLSConfig generates a tls.Config from a schema.TLSConfig and a x509.CertPool.
-func NewTLSConfig(config *schema.TLSConfig, defaultMinVersion uint16, certPool *x509.CertPool) (tlsConfig *tls.Config) {
+// NewTLSConfig generates a tls.Config from a schema.TLSConfig and a X509CertPool. The server certificates are verified
+// against the current certificates of the pool, extended with the certificate authorities of the configuration, so
+// the certificates reloaded by the pool are trusted without creating a new tls.Config.
+func NewTLSConfig(config *schema.TLSConfig, defaultMinVersion uint16, certPool *X509CertPool) (tlsConfig *tls.Config) {
 	minVersion, err := TLSStringToTLSConfigVersion(config.MinimumVersion)
 	if err != nil {
 		minVersion = defaultMinVersion
 	}
 
-	return &tls.Config{
+	tlsConfig = &tls.Config{
 		ServerName:         config.ServerName,
 		InsecureSkipVerify: config.SkipVerify, //nolint:gosec // Informed choice by user. Off by default.
 		MinVersion:         minVersion,
-		RootCAs:            certPool,
 	}
+
+	for _, name := range config.CipherSuites {
+		if cipherSuite, err := TLSStringToCipherSuite(name); err == nil {
+			tlsConfig.CipherSuites = append(tlsConfig.CipherSuites, cipherSuite)
+		}
+	}
+
+	for _, name := range config.CurvePreferences {
+		if curve, err := TLSStringToCurveID(name); err == nil {
+			tlsConfig.CurvePreferences = append(tlsConfig.CurvePreferences, curve)
+		}
+	}
+
+	if len(config.CertificateAuthorities) != 0 {
+		var errs []error
+
+		if certPool, errs = certPool.Extend(config.CertificateAuthorities); len(errs) != 0 {
+			for _, err := range errs {
+				logging.Logger().Errorf("Unable to load the certificate authorities: %s", err)
+			}
+		}
+	}
+
+	if (certPool != nil || revocationEnabled(config.Revocation)) && !config.SkipVerify {
+		// The verification done by crypto/tls is replaced by the same verification against the current pool, followed
+		// by the revocation checks.
+		revocation := config.Revocation
+
+		tlsConfig.InsecureSkipVerify = true //nolint:gosec // The certificates are verified by VerifyConnection.
+		tlsConfig.VerifyConnection = func(state tls.ConnectionState) error {
+			return certPool.verifyConnection(state, tlsConfig.ServerName, revocation)
+		}
+	}
+
+	if config.ClientCertificate != "" && config.ClientKey != "" {
+		// The key pair is loaded on every handshake so a renewed certificate is used without restarting.
+		tlsConfig.GetClientCertificate = func(_ *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			certificate, err := tls.LoadX509KeyPair(config.ClientCertificate, config.ClientKey)
+			if err != nil {
+				return nil, fmt.Errorf("could not load the TLS client certificate: %w", err)
+			}
+
+			return &certificate, nil
+		}
+	}
+
+	return tlsConfig
 }
 
-// NewX509CertPool generates a x509.CertPool from the system PKI and the directory specified.
-func NewX509CertPool(directory string) (certPool *x509.CertPool, errors []error, nonFatalErrors []error) {
+// NewX509CertPool generates a x509.CertPool from the system PKI, the directory specified and the extra certificate
+// files.
+func NewX509CertPool(directory string, files ...string) (certPool *x509.CertPool, errors []error, nonFatalErrors []error) {
 	certPool, err := x509.SystemCertPool()
 	if err != nil {
 		nonFatalErrors = append(nonFatalErrors, fmt.Errorf("could not load system certificate pool which may result in untrusted certificate issues: %v", err))
@@ -65,6 +114,15 @@ func NewX509CertPool(directory string) (certPool *x509.CertPool, errors []error,
 
 	logger.Tracef("Finished scan of directory %s for certificates", directory)
 
+	for _, file := range files {
+		certBytes, err := ioutil.ReadFile(file)
+		if err != nil {
+			errors = append(errors, fmt.Errorf("could not read certificate %v", err))
+		} else if ok := certPool.AppendCertsFromPEM(certBytes); !ok {
+			errors = append(errors, fmt.Errorf("could not import certificate %s", file))
+		}
+	}
+
 	return certPool, errors, nonFatalErrors
 }
 
@@ -83,3 +141,37 @@ func TLSStringToTLSConfigVersion(input string) (version uint16, err error) {
 
 	return 0, ErrTLSVersionNotSupported
 }
+
+// TLSStringToCipherSuite returns a go crypto/tls cipher suite for a tls.Config based on its IANA name. Only the secure
+// cipher suites configurable for TLS 1.2 and below are supported, the TLS 1.3 cipher suites can't be configured.
+func TLSStringToCipherSuite(input string) (cipherSuite uint16, err error) {
+	for _, suite := range tls.CipherSuites() {
+		if !strings.EqualFold(suite.Name, input) {
+			continue
+		}
+
+		for _, version := range suite.SupportedVersions {
+			if version != tls.VersionTLS13 {
+				return suite.ID, nil
+			}
+		}
+	}
+
+	return 0, ErrTLSCipherSuiteNotSupported
+}
+
+// TLSStringToCurveID returns a go crypto/tls curve for a tls.Config based on string input.
+func TLSStringToCurveID(input string) (curve tls.CurveID, err error) {
+	switch strings.ToUpper(input) {
+	case "X25519":
+		return tls.X25519, nil
+	case "P256", "P-256":
+		return tls.CurveP256, nil
+	case "P384", "P-384":
+		return tls.CurveP384, nil
+	case "P521", "P-521":
+		return tls.CurveP521, nil
+	}
+
+	return 0, ErrTLSCurveNotSupported
+}
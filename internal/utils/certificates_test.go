@@ -76,6 +76,52 @@ func TestShouldReturnZeroAndErrorOnInvalidTLSVersions(t *testing.T) {
 	assert.EqualError(t, err, "supplied TLS version isn't supported")
 }
 
+func TestShouldReturnCorrectTLSCipherSuites(t *testing.T) {
+	cipherSuite, err := TLSStringToCipherSuite("TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384")
+	assert.NoError(t, err)
+	assert.Equal(t, tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384, cipherSuite)
+
+	cipherSuite, err = TLSStringToCipherSuite("tls_ecdhe_rsa_with_chacha20_poly1305_sha256")
+	assert.NoError(t, err)
+	assert.Equal(t, tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256, cipherSuite)
+
+	// Insecure cipher suites and the TLS 1.3 cipher suites aren't supported.
+	for _, name := range []string{"TLS_RSA_WITH_RC4_128_SHA", "TLS_AES_128_GCM_SHA256", "TLS_NOT_A_CIPHER_SUITE"} {
+		cipherSuite, err = TLSStringToCipherSuite(name)
+		assert.EqualError(t, err, "supplied TLS cipher suite isn't supported")
+		assert.Equal(t, uint16(0), cipherSuite)
+	}
+}
+
+func TestShouldReturnCorrectTLSCurves(t *testing.T) {
+	curve, err := TLSStringToCurveID("X25519")
+	assert.NoError(t, err)
+	assert.Equal(t, tls.X25519, curve)
+
+	curve, err = TLSStringToCurveID("p-384")
+	assert.NoError(t, err)
+	assert.Equal(t, tls.CurveP384, curve)
+
+	curve, err = TLSStringToCurveID("P224")
+	assert.EqualError(t, err, "supplied TLS curve isn't supported")
+	assert.Equal(t, tls.CurveID(0), curve)
+}
+
+func TestShouldSetupTLSCipherSuitesAndCurves(t *testing.T) {
+	tlsConfig := NewTLSConfig(&schema.TLSConfig{
+		CipherSuites:     []string{"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384", "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"},
+		CurvePreferences: []string{"X25519", "P-256"},
+	}, tls.VersionTLS12, nil)
+
+	assert.Equal(t, []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384, tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384}, tlsConfig.CipherSuites)
+	assert.Equal(t, []tls.CurveID{tls.X25519, tls.CurveP256}, tlsConfig.CurvePreferences)
+
+	tlsConfig = NewTLSConfig(&schema.TLSConfig{}, tls.VersionTLS12, nil)
+
+	assert.Nil(t, tlsConfig.CipherSuites)
+	assert.Nil(t, tlsConfig.CurvePreferences)
+}
+
 func TestShouldReturnErrWhenX509DirectoryNotExist(t *testing.T) {
 	pool, errs, nonFatalErrs := NewX509CertPool("/tmp/asdfzyxabc123/not/a/real/dir")
 	assert.NotNil(t, pool)
@@ -124,3 +170,28 @@ func TestShouldReadCertsFromDirectoryButNotKeys(t *testing.T) {
 
 	assert.EqualError(t, errs[0], "could not import certificate key.pem")
 }
+
+func TestShouldLoadTLSClientCertificate(t *testing.T) {
+	tlsConfig := NewTLSConfig(&schema.TLSConfig{}, tls.VersionTLS12, nil)
+	assert.Nil(t, tlsConfig.GetClientCertificate)
+
+	tlsConfig = NewTLSConfig(&schema.TLSConfig{
+		ClientCertificate: "../suites/common/ssl/cert.pem",
+		ClientKey:         "../suites/common/ssl/key.pem",
+	}, tls.VersionTLS12, nil)
+
+	require.NotNil(t, tlsConfig.GetClientCertificate)
+
+	certificate, err := tlsConfig.GetClientCertificate(&tls.CertificateRequestInfo{})
+	require.NoError(t, err)
+	assert.Len(t, certificate.Certificate, 1)
+
+	tlsConfig = NewTLSConfig(&schema.TLSConfig{
+		ClientCertificate: "../suites/common/ssl/cert.pem",
+		ClientKey:         "../suites/common/ssl/not-a-key.pem",
+	}, tls.VersionTLS12, nil)
+
+	_, err = tlsConfig.GetClientCertificate(&tls.CertificateRequestInfo{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "could not load the TLS client certificate: ")
+}
@@ -1,6 +1,9 @@
 package utils
 
-import "time"
+import (
+	"sync"
+	"time"
+)
 
 // Clock is an interface for a clock.
 type Clock interface {
@@ -20,3 +23,103 @@ func (RealClock) Now() time.Time {
 func (RealClock) After(d time.Duration) <-chan time.Time {
 	return time.After(d)
 }
+
+// FakeClock is a deterministic clock for the tests: its time only changes when it's set or advanced, which fires the
+// channels returned by After whose duration has elapsed. This lets the tests of the expiry logic advance the time
+// instead of sleeping. The zero value is a clock set to the zero time.
+type FakeClock struct {
+	mutex   sync.Mutex
+	now     time.Time
+	waiters []fakeClockWaiter
+	changed *sync.Cond
+}
+
+type fakeClockWaiter struct {
+	deadline time.Time
+	channel  chan time.Time
+}
+
+// NewFakeClock creates a FakeClock set to the given time.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the time of the clock.
+func (c *FakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.now
+}
+
+// After returns a channel receiving the time of the clock once it has been advanced by the duration.
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	channel := make(chan time.Time, 1)
+
+	if d <= 0 {
+		channel <- c.now
+		return channel
+	}
+
+	c.waiters = append(c.waiters, fakeClockWaiter{deadline: c.now.Add(d), channel: channel})
+	c.cond().Broadcast()
+
+	return channel
+}
+
+// Set sets the time of the clock and fires the channels returned by After whose duration has elapsed.
+func (c *FakeClock) Set(now time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.set(now)
+}
+
+// Advance advances the time of the clock by the duration.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.set(c.now.Add(d))
+}
+
+// set must be called with the mutex locked.
+func (c *FakeClock) set(now time.Time) {
+	c.now = now
+
+	waiters := c.waiters[:0]
+
+	for _, waiter := range c.waiters {
+		if waiter.deadline.After(now) {
+			waiters = append(waiters, waiter)
+			continue
+		}
+
+		waiter.channel <- now
+	}
+
+	c.waiters = waiters
+}
+
+// BlockUntil blocks until the given number of channels returned by After are waiting for the clock to be advanced, so
+// a test can advance the clock once the code under test waits for it.
+func (c *FakeClock) BlockUntil(waiters int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for len(c.waiters) < waiters {
+		c.cond().Wait()
+	}
+}
+
+// cond returns the condition signaling a new waiter, it must be called with the mutex locked.
+func (c *FakeClock) cond() *sync.Cond {
+	if c.changed == nil {
+		c.changed = sync.NewCond(&c.mutex)
+	}
+
+	return c.changed
+}
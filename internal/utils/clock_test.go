@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeClockShouldOnlyChangeWhenSetOrAdvanced(t *testing.T) {
+	now := time.Unix(1000, 0)
+	clock := NewFakeClock(now)
+
+	assert.Equal(t, now, clock.Now())
+
+	clock.Advance(time.Minute)
+	assert.Equal(t, now.Add(time.Minute), clock.Now())
+
+	clock.Set(now)
+	assert.Equal(t, now, clock.Now())
+}
+
+func TestFakeClockShouldFireAfterOnceAdvancedByTheDuration(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1000, 0))
+
+	channel := clock.After(time.Minute)
+
+	clock.Advance(59 * time.Second)
+
+	select {
+	case <-channel:
+		t.Fatal("the channel fired before the duration elapsed")
+	default:
+	}
+
+	clock.Advance(time.Second)
+
+	select {
+	case fired := <-channel:
+		assert.Equal(t, time.Unix(1060, 0), fired)
+	default:
+		t.Fatal("the channel didn't fire once the duration elapsed")
+	}
+}
+
+func TestFakeClockShouldFireAfterImmediatelyWithoutDuration(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1000, 0))
+
+	assert.Equal(t, time.Unix(1000, 0), <-clock.After(0))
+}
+
+func TestFakeClockShouldBlockUntilWaiting(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1000, 0))
+	fired := make(chan time.Time)
+
+	go func() {
+		fired <- <-clock.After(time.Second)
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+
+	assert.Equal(t, time.Unix(1001, 0), <-fired)
+}
@@ -7,12 +7,23 @@ import (
 )
 
 const (
+	dnsPort = 53
+
 	windows         = "windows"
 	testStringInput = "abcdefghijkl"
 
 	// RFC3339Zero is the default value for time.Time.Unix().
 	RFC3339Zero = int64(-62135596800)
 
+	// AddressFamilyDual is the address family of the listeners accepting both IPv4 and IPv6 connections.
+	AddressFamilyDual = "dual"
+
+	// AddressFamilyIPv4 is the address family of the listeners only accepting IPv4 connections.
+	AddressFamilyIPv4 = "ipv4"
+
+	// AddressFamilyIPv6 is the address family of the listeners only accepting IPv6 connections.
+	AddressFamilyIPv6 = "ipv6"
+
 	// TLS13 is the textual representation of TLS 1.3.
 	TLS13 = "1.3"
 
@@ -49,8 +60,22 @@ const (
 var ErrTimeoutReached = errors.New("timeout reached")
 var parseDurationRegexp = regexp.MustCompile(`^(?P<Duration>[1-9]\d*?)(?P<Unit>[smhdwMy])?$`)
 
+var hostDomainRegexp = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?(\.[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?)*$`)
+
 // AlphaNumericCharacters are literally just valid alphanumeric chars.
 var AlphaNumericCharacters = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789")
 
 // ErrTLSVersionNotSupported returned when an unknown TLS version supplied.
 var ErrTLSVersionNotSupported = errors.New("supplied TLS version isn't supported")
+
+// ErrTLSCipherSuiteNotSupported returned when an unknown or insecure TLS cipher suite is supplied.
+var ErrTLSCipherSuiteNotSupported = errors.New("supplied TLS cipher suite isn't supported")
+
+// ErrTLSCurveNotSupported returned when an unknown TLS curve is supplied.
+var ErrTLSCurveNotSupported = errors.New("supplied TLS curve isn't supported")
+
+// ErrRedirectionSchemeNotSecure returned when a redirection URL doesn't use the https scheme.
+var ErrRedirectionSchemeNotSecure = errors.New("redirection URL scheme isn't https")
+
+// ErrRedirectionDomainNotAllowed returned when a redirection URL isn't on one of the allowed domains.
+var ErrRedirectionDomainNotAllowed = errors.New("redirection URL domain isn't allowed")
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"crypto/tls"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+// FIPSCipherSuites are the cipher suites of TLS 1.2 and below approved by FIPS 140-2.
+var FIPSCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+}
+
+// FIPSCurves are the elliptic curves approved by FIPS 140-2.
+var FIPSCurves = []tls.CurveID{tls.CurveP256, tls.CurveP384, tls.CurveP521}
+
+// CryptoProfileMinimumTLSVersion returns the minimum TLS version allowed by a crypto profile.
+func CryptoProfileMinimumTLSVersion(profile string) uint16 {
+	switch profile {
+	case schema.CryptoProfileStrict:
+		return tls.VersionTLS13
+	case schema.CryptoProfileFIPS:
+		return tls.VersionTLS12
+	default:
+		return tls.VersionTLS10
+	}
+}
+
+// NewServerTLSConfig generates the tls.Config of the TLS connections accepted by Authelia with a crypto profile. It
+// returns nil for the default profile, in which case the defaults of the server are used.
+func NewServerTLSConfig(profile string) *tls.Config {
+	switch profile {
+	case schema.CryptoProfileFIPS, schema.CryptoProfileStrict:
+		return &tls.Config{
+			MinVersion:       CryptoProfileMinimumTLSVersion(profile),
+			CipherSuites:     FIPSCipherSuites,
+			CurvePreferences: FIPSCurves,
+		}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+func TestShouldReturnServerTLSConfigOfCryptoProfile(t *testing.T) {
+	assert.Nil(t, NewServerTLSConfig(schema.CryptoProfileDefault))
+
+	tlsConfig := NewServerTLSConfig(schema.CryptoProfileFIPS)
+	require.NotNil(t, tlsConfig)
+	assert.Equal(t, uint16(tls.VersionTLS12), tlsConfig.MinVersion)
+	assert.Equal(t, FIPSCipherSuites, tlsConfig.CipherSuites)
+	assert.Equal(t, FIPSCurves, tlsConfig.CurvePreferences)
+
+	tlsConfig = NewServerTLSConfig(schema.CryptoProfileStrict)
+	require.NotNil(t, tlsConfig)
+	assert.Equal(t, uint16(tls.VersionTLS13), tlsConfig.MinVersion)
+}
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/authelia/authelia/internal/logging"
+)
+
+// WatchFile calls reload when the file changes. The directory of the file is watched so the file can be replaced,
+// including by Kubernetes which swaps the ..data symlink of the mounted ConfigMaps and Secrets.
+func WatchFile(path, name string, reload func() error) error {
+	path = filepath.Clean(path)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	if err = watcher.Add(filepath.Dir(path)); err != nil {
+		_ = watcher.Close()
+		return err
+	}
+
+	go func() {
+		logger := logging.Logger()
+
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+
+				if event.Op == fsnotify.Chmod || !isWatchedFileEvent(path, event.Name) {
+					continue
+				}
+
+				logger.Debugf("Reloading the %s after %s changed", name, event.Name)
+
+				if err := reload(); err != nil {
+					logger.Errorf("Unable to reload the %s, the current one is kept: %s", name, err)
+				} else {
+					logger.Infof("Reloaded the %s from %s", name, path)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+
+				logger.Errorf("Error watching the %s: %s", name, err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// isWatchedFileEvent returns true if the event on the given name affects the watched file.
+func isWatchedFileEvent(path, name string) bool {
+	name = filepath.Clean(name)
+
+	return name == path || strings.HasPrefix(filepath.Base(name), "..")
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldMatchWatchedFileEvents(t *testing.T) {
+	assert.True(t, isWatchedFileEvent("/config/rules.yml", "/config/rules.yml"))
+	assert.True(t, isWatchedFileEvent("/config/rules.yml", "/config/..data"))
+	assert.True(t, isWatchedFileEvent("/config/rules.yml", "/config/..2021_08_01_10_00_00.123456789"))
+	assert.False(t, isWatchedFileEvent("/config/rules.yml", "/config/db.sqlite3"))
+}
+
+func TestShouldReloadWatchedFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "authelia-watch")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "rules.yml")
+	require.NoError(t, ioutil.WriteFile(path, []byte("rules: []\n"), 0600))
+
+	reloaded := make(chan struct{}, 10)
+
+	require.NoError(t, WatchFile(path, "rules", func() error {
+		reloaded <- struct{}{}
+		return nil
+	}))
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "other.yml"), []byte("a: b\n"), 0600))
+	require.NoError(t, ioutil.WriteFile(path, []byte("rules: [{}]\n"), 0600))
+
+	select {
+	case <-reloaded:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the file was not reloaded")
+	}
+}
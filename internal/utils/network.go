@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// ParseIP parses an IP address as found in the headers of a request. Unlike net.ParseIP it accepts the IPv6 addresses
+// enclosed in brackets, with a port or with a zone ID. The zone is discarded as net.IP doesn't carry it. It returns nil
+// if the value isn't an IP address.
+func ParseIP(value string) net.IP {
+	value = strings.TrimSpace(value)
+
+	if host, _, err := net.SplitHostPort(value); err == nil {
+		value = host
+	} else if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+		value = value[1 : len(value)-1]
+	}
+
+	return net.ParseIP(stripZone(value))
+}
+
+// ParseNetwork parses an IP address or a network in CIDR notation into a network. An IP address is a network of a
+// single address. The zone ID of the IPv6 addresses is discarded, and the IPv4-mapped IPv6 networks are converted to
+// IPv4 networks so they match the IPv4 addresses like the other IPv4 networks.
+func ParseNetwork(value string) (network *net.IPNet, err error) {
+	address, bits := value, ""
+
+	if i := strings.IndexByte(value, '/'); i >= 0 {
+		address, bits = value[:i], value[i+1:]
+	}
+
+	address = stripZone(address)
+
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return nil, fmt.Errorf("could not parse the network %s: invalid IP address", value)
+	}
+
+	if bits == "" {
+		if ip.To4() != nil {
+			bits = "32"
+		} else {
+			bits = "128"
+		}
+	} else if ip.To4() != nil && strings.Contains(address, ":") {
+		// An IPv4-mapped IPv6 network, e.g. ::ffff:10.0.0.0/104, its prefix length must cover the mapping prefix.
+		ones, err := strconv.Atoi(bits)
+		if err != nil || ones < 96 || ones > 128 {
+			return nil, fmt.Errorf("could not parse the network %s: invalid prefix length for an IPv4-mapped network", value)
+		}
+
+		bits = strconv.Itoa(ones - 96)
+	}
+
+	if ip.To4() != nil {
+		address = ip.To4().String()
+	}
+
+	if _, network, err = net.ParseCIDR(address + "/" + bits); err != nil {
+		return nil, fmt.Errorf("could not parse the network %s: %w", value, err)
+	}
+
+	return network, nil
+}
+
+// ListenNetwork returns the network to listen on for the address family of a listener: tcp4 for ipv4, tcp6 for ipv6,
+// and tcp for dual which listens on both the IPv4 and IPv6 addresses when the host is unspecified.
+func ListenNetwork(addressFamily string) string {
+	switch addressFamily {
+	case AddressFamilyIPv4:
+		return "tcp4"
+	case AddressFamilyIPv6:
+		return "tcp6"
+	default:
+		return "tcp"
+	}
+}
+
+func stripZone(address string) string {
+	if i := strings.IndexByte(address, '%'); i >= 0 && strings.Contains(address, ":") {
+		return address[:i]
+	}
+
+	return address
+}
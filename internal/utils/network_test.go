@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldParseIPFromHeaders(t *testing.T) {
+	testCases := map[string]string{
+		" 192.168.1.10 ":          "192.168.1.10",
+		"192.168.1.10:4321":       "192.168.1.10",
+		"fec0::1":                 "fec0::1",
+		"[fec0::1]":               "fec0::1",
+		"[fec0::1]:4321":          "fec0::1",
+		"fe80::1%eth0":            "fe80::1",
+		"[fe80::1%eth0]:4321":     "fe80::1",
+		"::ffff:192.168.1.10":     "192.168.1.10",
+		"2001:db8:0:0:0:0:0:0001": "2001:db8::1",
+	}
+
+	for value, expected := range testCases {
+		t.Run(value, func(t *testing.T) {
+			assert.Equal(t, expected, ParseIP(value).String())
+		})
+	}
+
+	assert.Nil(t, ParseIP("unknown"))
+	assert.Nil(t, ParseIP(""))
+}
+
+func TestShouldParseNetworks(t *testing.T) {
+	testCases := map[string]string{
+		"10.0.0.1":              "10.0.0.1/32",
+		"10.0.0.0/8":            "10.0.0.0/8",
+		"fec0::1":               "fec0::1/128",
+		"fec0::1/64":            "fec0::/64",
+		"fe80::1%eth0":          "fe80::1/128",
+		"fe80::%eth0/10":        "fe80::/10",
+		"::ffff:10.0.0.1":       "10.0.0.1/32",
+		"::ffff:10.0.0.0/104":   "10.0.0.0/8",
+		"::ffff:192.168.0.0/96": "0.0.0.0/0",
+	}
+
+	for value, expected := range testCases {
+		t.Run(value, func(t *testing.T) {
+			network, err := ParseNetwork(value)
+			require.NoError(t, err)
+			assert.Equal(t, expected, network.String())
+		})
+	}
+}
+
+func TestShouldMatchIPv4AddressesWithMappedNetworks(t *testing.T) {
+	network, err := ParseNetwork("::ffff:10.0.0.0/104")
+	require.NoError(t, err)
+
+	assert.True(t, network.Contains(net.ParseIP("10.1.2.3")))
+	assert.True(t, network.Contains(net.ParseIP("::ffff:10.1.2.3")))
+	assert.False(t, network.Contains(net.ParseIP("192.168.1.1")))
+}
+
+func TestShouldNotParseInvalidNetworks(t *testing.T) {
+	for _, value := range []string{"bad/8", "10.0.0.0/33", "fec0::1/129", "::ffff:10.0.0.0/64", "10.0.0.0/abc", ""} {
+		t.Run(value, func(t *testing.T) {
+			_, err := ParseNetwork(value)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestShouldReturnListenNetworkOfAddressFamily(t *testing.T) {
+	assert.Equal(t, "tcp", ListenNetwork(AddressFamilyDual))
+	assert.Equal(t, "tcp4", ListenNetwork(AddressFamilyIPv4))
+	assert.Equal(t, "tcp6", ListenNetwork(AddressFamilyIPv6))
+	assert.Equal(t, "tcp", ListenNetwork(""))
+}
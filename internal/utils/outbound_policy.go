@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+// OutboundPolicy restricts the destinations of the outbound HTTP requests initiated by the server, preventing the
+// server-side request forgery through the URLs influenced by the users.
+type OutboundPolicy struct {
+	allowed         hostMatcher
+	restricted      bool
+	blockedNetworks []*net.IPNet
+
+	// lookupIP resolves the hosts of the requests sent through a proxy, the dialer only sees the address of the proxy.
+	lookupIP func(ctx context.Context, network, host string) ([]net.IP, error)
+}
+
+// NewOutboundPolicy creates the policy of the outbound HTTP requests. The requests are only allowed to the allowed
+// hosts if any are configured, and never to the default blocked networks nor the configured ones.
+func NewOutboundPolicy(configuration schema.OutboundRequestsConfiguration) *OutboundPolicy {
+	policy := &OutboundPolicy{
+		allowed:    newHostMatcher(configuration.AllowedHosts),
+		restricted: len(configuration.AllowedHosts) != 0,
+		lookupIP:   net.DefaultResolver.LookupIP,
+	}
+
+	for _, entry := range append(append([]string{}, schema.DefaultOutboundRequestsBlockedNetworks...), configuration.BlockedNetworks...) {
+		// The networks have already been validated.
+		if network, err := ParseNetwork(entry); err == nil {
+			policy.blockedNetworks = append(policy.blockedNetworks, network)
+		}
+	}
+
+	return policy
+}
+
+// CheckURL returns an error if the host of the URL isn't allowed.
+func (p *OutboundPolicy) CheckURL(u *url.URL) error {
+	if p.restricted && !p.allowed.matches(u.Hostname()) {
+		return fmt.Errorf("the outbound request to %s is blocked: the host isn't allowed", u.Host)
+	}
+
+	if ip := net.ParseIP(stripZone(u.Hostname())); ip != nil {
+		return p.checkIP(ip)
+	}
+
+	return nil
+}
+
+// Apply enforces the policy on the requests sent with the transport. The URL of each request, including the
+// redirections, is checked before it's sent, and the addresses the names resolve to are checked before connecting so
+// a name can't resolve to a blocked network. The requests sent through a proxy are connected to the proxy which
+// resolves the names itself, so the names are resolved and checked before the request is handed to the proxy.
+func (p *OutboundPolicy) Apply(transport *http.Transport) {
+	proxy := transport.Proxy
+
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		if err := p.CheckURL(req.URL); err != nil {
+			return nil, err
+		}
+
+		if proxy == nil {
+			return nil, nil
+		}
+
+		proxyURL, err := proxy(req)
+		if err != nil || proxyURL == nil {
+			return proxyURL, err
+		}
+
+		if err = p.checkHost(req.Context(), req.URL.Hostname()); err != nil {
+			return nil, err
+		}
+
+		return proxyURL, nil
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   p.control,
+	}
+
+	transport.DialContext = dialer.DialContext
+}
+
+func (p *OutboundPolicy) control(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	if ip := net.ParseIP(stripZone(host)); ip != nil {
+		return p.checkIP(ip)
+	}
+
+	return nil
+}
+
+// checkHost resolves the host and returns an error if any of its addresses is in a blocked network, the IP addresses
+// have already been checked with the URL.
+func (p *OutboundPolicy) checkHost(ctx context.Context, host string) error {
+	if net.ParseIP(stripZone(host)) != nil {
+		return nil
+	}
+
+	ips, err := p.lookupIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("the outbound request to %s is blocked: unable to resolve the host: %w", host, err)
+	}
+
+	for _, ip := range ips {
+		if err = p.checkIP(ip); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *OutboundPolicy) checkIP(ip net.IP) error {
+	for _, network := range p.blockedNetworks {
+		if network.Contains(ip) {
+			return fmt.Errorf("the outbound request to %s is blocked: the address is in the blocked network %s", ip, network)
+		}
+	}
+
+	return nil
+}
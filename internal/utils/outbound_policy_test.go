@@ -0,0 +1,159 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+func newPolicyClient(configuration schema.OutboundRequestsConfiguration) *http.Client {
+	transport := &http.Transport{}
+	NewOutboundPolicy(configuration).Apply(transport)
+
+	return &http.Client{Transport: transport}
+}
+
+func TestShouldBlockTheMetadataServices(t *testing.T) {
+	policy := NewOutboundPolicy(schema.OutboundRequestsConfiguration{})
+
+	for _, target := range []string{
+		"http://169.254.169.254/latest/meta-data/",
+		"http://[fd00:ec2::254]/latest/meta-data/",
+		"http://100.100.100.200/latest/meta-data/",
+		"http://[fe80::1%25eth0]:8080/",
+		"http://[::ffff:169.254.169.254]/",
+		"http://0.0.0.0:8080/",
+	} {
+		t.Run(target, func(t *testing.T) {
+			u, err := url.Parse(target)
+			require.NoError(t, err)
+
+			assert.Error(t, policy.CheckURL(u))
+		})
+	}
+
+	u, err := url.Parse("https://app.example.com/logout")
+	require.NoError(t, err)
+	assert.NoError(t, policy.CheckURL(u))
+}
+
+func TestShouldOnlyAllowTheAllowedHosts(t *testing.T) {
+	policy := NewOutboundPolicy(schema.OutboundRequestsConfiguration{
+		AllowedHosts: []string{"example.com", "10.0.0.0/8"},
+	})
+
+	for target, allowed := range map[string]bool{
+		"https://example.com/logout":          true,
+		"https://app.example.com/logout":      true,
+		"http://10.1.2.3:8080/score":          true,
+		"https://example.com.attacker.net/":   false,
+		"https://attacker.net/?example.com":   false,
+		"http://192.168.1.10/score":           false,
+		"http://169.254.169.254/latest/meta/": false,
+		"https://notexample.com/logout":       false,
+		"https://app.example.com.:443/logout": true,
+		"https://APP.EXAMPLE.COM/logout":      true,
+	} {
+		t.Run(target, func(t *testing.T) {
+			u, err := url.Parse(target)
+			require.NoError(t, err)
+
+			if allowed {
+				assert.NoError(t, policy.CheckURL(u))
+			} else {
+				assert.Error(t, policy.CheckURL(u))
+			}
+		})
+	}
+}
+
+func TestShouldBlockTheNamesResolvingToBlockedNetworks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	client := newPolicyClient(schema.OutboundRequestsConfiguration{})
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	client = newPolicyClient(schema.OutboundRequestsConfiguration{BlockedNetworks: []string{"127.0.0.0/8"}})
+
+	_, err = client.Get("http://localhost:" + u.Port())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "the outbound request to 127.0.0.1 is blocked: the address is in the blocked network 127.0.0.0/8")
+}
+
+func TestShouldCheckTheRedirections(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		http.Redirect(rw, r, "http://169.254.169.254/latest/meta-data/", http.StatusFound)
+	}))
+	defer server.Close()
+
+	client := newPolicyClient(schema.OutboundRequestsConfiguration{})
+
+	_, err := client.Get(server.URL)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "the outbound request to 169.254.169.254 is blocked: the address is in the blocked network 169.254.0.0/16")
+}
+
+func TestShouldBlockTheNamesResolvingToBlockedNetworksThroughAProxy(t *testing.T) {
+	var proxied []string
+
+	proxy := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer proxy.Close()
+
+	proxyURL, err := url.Parse(proxy.URL)
+	require.NoError(t, err)
+
+	policy := NewOutboundPolicy(schema.OutboundRequestsConfiguration{})
+	policy.lookupIP = func(_ context.Context, _, host string) ([]net.IP, error) {
+		switch host {
+		case "app.example.com":
+			return []net.IP{net.ParseIP("203.0.113.10")}, nil
+		case "metadata.example.com":
+			return []net.IP{net.ParseIP("203.0.113.11"), net.ParseIP("169.254.169.254")}, nil
+		default:
+			return nil, fmt.Errorf("no such host")
+		}
+	}
+
+	transport := &http.Transport{Proxy: http.ProxyURL(proxyURL)}
+	policy.Apply(transport)
+
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Get("http://app.example.com/logout")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	// The dialer only sees the address of the proxy, the host is resolved and checked before reaching the proxy.
+	_, err = client.Get("http://metadata.example.com/latest/meta-data/")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "the outbound request to 169.254.169.254 is blocked: the address is in the blocked network 169.254.0.0/16")
+
+	_, err = client.Get("http://unknown.example.com/")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "the outbound request to unknown.example.com is blocked: unable to resolve the host: no such host")
+
+	assert.Equal(t, []string{"http://app.example.com/logout"}, proxied)
+}
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+// NewProxyFunc returns the function selecting the proxy of the outbound HTTP requests. Without configuration, the proxy
+// is read from the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables like with the default HTTP transport.
+func NewProxyFunc(configuration *schema.OutboundProxyConfiguration) func(*http.Request) (*url.URL, error) {
+	if configuration == nil {
+		return http.ProxyFromEnvironment
+	}
+
+	// The URL has already been validated.
+	proxyURL, _ := url.Parse(configuration.URL)
+	bypass := newHostMatcher(configuration.NoProxy)
+
+	return func(req *http.Request) (*url.URL, error) {
+		host := req.URL.Hostname()
+
+		// The loopback hosts always bypass the proxy.
+		if ip := net.ParseIP(stripZone(host)); host == "localhost" || (ip != nil && ip.IsLoopback()) {
+			return nil, nil
+		}
+
+		if bypass.matches(host) {
+			return nil, nil
+		}
+
+		return proxyURL, nil
+	}
+}
+
+// IsHostEntryValid checks an entry of a list of hosts is either *, a domain, an IP address or a network in CIDR
+// notation.
+func IsHostEntryValid(entry string) bool {
+	if entry == "*" {
+		return true
+	}
+
+	if _, err := ParseNetwork(entry); err == nil {
+		return true
+	}
+
+	return hostDomainRegexp.MatchString(trimHostDomain(entry))
+}
+
+// hostMatcher matches the hosts against a list of domains, which also match their subdomains, IP addresses and
+// networks. The * entry matches all the hosts.
+type hostMatcher struct {
+	all      bool
+	domains  []string
+	networks []*net.IPNet
+}
+
+func newHostMatcher(entries []string) (matcher hostMatcher) {
+	for _, entry := range entries {
+		if entry == "*" {
+			matcher.all = true
+			continue
+		}
+
+		if network, err := ParseNetwork(entry); err == nil {
+			matcher.networks = append(matcher.networks, network)
+			continue
+		}
+
+		matcher.domains = append(matcher.domains, strings.ToLower(trimHostDomain(entry)))
+	}
+
+	return matcher
+}
+
+func (m hostMatcher) matches(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+	if m.all {
+		return true
+	}
+
+	if ip := net.ParseIP(stripZone(host)); ip != nil {
+		for _, network := range m.networks {
+			if network.Contains(ip) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	for _, domain := range m.domains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func trimHostDomain(entry string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(entry, "*"), ".")
+}
@@ -0,0 +1,140 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/base64"
+	"net/url"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// RedirectionTemplateData is the data available to the template of the login portal redirection URL.
+type RedirectionTemplateData struct {
+	// PortalURL is the URL of the login portal provided by the proxy with the rd parameter.
+	PortalURL string
+
+	// TargetURL is the URL the user was trying to access.
+	TargetURL string
+
+	// Target is the parsed URL the user was trying to access, e.g. to use {{ .Target.Host }}.
+	Target *url.URL
+
+	// Method is the HTTP method of the request to the target URL, it's empty when the proxy didn't provide it.
+	Method string
+
+	// State is the signed state of the login flow the portal must send back along with the target URL, it's empty when
+	// the signed state is disabled.
+	State string
+}
+
+// URLTemplateFuncs are the functions available to the templates of URLs such as the login portal redirection URL.
+var URLTemplateFuncs = template.FuncMap{
+	"queryEscape": url.QueryEscape,
+	"pathEscape":  url.PathEscape,
+	"base64": func(value string) string {
+		return base64.StdEncoding.EncodeToString([]byte(value))
+	},
+	"base64url": func(value string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(value))
+	},
+}
+
+var redirectionTemplates sync.Map
+
+// NewURLTemplate parses a template of a URL, or of a request body, with the URL functions. The execution fails when the
+// template references a field which doesn't exist.
+func NewURLTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(URLTemplateFuncs).Option("missingkey=error").Parse(text)
+}
+
+// ParseRedirectionTemplate parses the template of the login portal redirection URL with the redirection functions.
+func ParseRedirectionTemplate(text string) (*template.Template, error) {
+	if tmpl, ok := redirectionTemplates.Load(text); ok {
+		return tmpl.(*template.Template), nil
+	}
+
+	tmpl, err := NewURLTemplate("redirection", text)
+	if err != nil {
+		return nil, err
+	}
+
+	redirectionTemplates.Store(text, tmpl)
+
+	return tmpl, nil
+}
+
+// ExecuteRedirectionTemplate renders the login portal redirection URL with the given template.
+func ExecuteRedirectionTemplate(text string, data RedirectionTemplateData) (string, error) {
+	tmpl, err := ParseRedirectionTemplate(text)
+	if err != nil {
+		return "", err
+	}
+
+	if data.Target == nil {
+		if data.Target, err = url.Parse(data.TargetURL); err != nil {
+			return "", err
+		}
+	}
+
+	buf := new(bytes.Buffer)
+
+	if err = tmpl.Execute(buf, data); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// IsRedirectionAllowed determines if a redirection URL is secured and targets one of the allowed domains or their
+// subdomains.
+func IsRedirectionAllowed(url url.URL, allowedDomains []string) bool {
+	return CheckRedirection(url, allowedDomains) == nil
+}
+
+// CheckRedirection returns the reason why a redirection URL isn't secured or doesn't target one of the allowed domains
+// or their subdomains, or nil if it's allowed.
+func CheckRedirection(url url.URL, allowedDomains []string) error {
+	if url.Scheme != "https" {
+		return ErrRedirectionSchemeNotSecure
+	}
+
+	hostname := strings.ToLower(url.Hostname())
+
+	for _, domain := range allowedDomains {
+		domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+
+		if domain == "" {
+			continue
+		}
+
+		if hostname == domain || strings.HasSuffix(hostname, "."+domain) {
+			return nil
+		}
+	}
+
+	return ErrRedirectionDomainNotAllowed
+}
+
+// StripRedirectionParameters removes the given query parameters from the redirection URL.
+func StripRedirectionParameters(url *url.URL, parameters []string) {
+	if len(parameters) == 0 || url.RawQuery == "" {
+		return
+	}
+
+	query := url.Query()
+	stripped := false
+
+	for _, parameter := range parameters {
+		if _, ok := query[parameter]; ok {
+			query.Del(parameter)
+
+			stripped = true
+		}
+	}
+
+	// Only encode the query again when it changed so the order of the other parameters is kept.
+	if stripped {
+		url.RawQuery = query.Encode()
+	}
+}
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldRenderDefaultLoginRedirectionTemplate(t *testing.T) {
+	text := "{{ .PortalURL }}?rd={{ queryEscape .TargetURL }}{{ with .Method }}&rm={{ . }}{{ end }}"
+
+	redirectionURL, err := ExecuteRedirectionTemplate(text, RedirectionTemplateData{
+		PortalURL: "https://login.example.com",
+		TargetURL: "https://home.example.com/?a=b",
+		Method:    "GET",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "https://login.example.com?rd=https%3A%2F%2Fhome.example.com%2F%3Fa%3Db&rm=GET", redirectionURL)
+
+	redirectionURL, err = ExecuteRedirectionTemplate(text, RedirectionTemplateData{
+		PortalURL: "https://login.example.com",
+		TargetURL: "https://home.example.com/",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "https://login.example.com?rd=https%3A%2F%2Fhome.example.com%2F", redirectionURL)
+}
+
+func TestShouldRenderLoginRedirectionTemplateFunctions(t *testing.T) {
+	redirectionURL, err := ExecuteRedirectionTemplate("{{ .PortalURL }}/{{ pathEscape .Target.Host }}/{{ base64 .TargetURL }}/{{ base64url .TargetURL }}", RedirectionTemplateData{
+		PortalURL: "https://login.example.com",
+		TargetURL: "https://home.example.com/?>",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "https://login.example.com/home.example.com/aHR0cHM6Ly9ob21lLmV4YW1wbGUuY29tLz8+/aHR0cHM6Ly9ob21lLmV4YW1wbGUuY29tLz8-", redirectionURL)
+}
+
+func TestShouldFailToRenderInvalidLoginRedirectionTemplate(t *testing.T) {
+	_, err := ExecuteRedirectionTemplate("{{ .PortalURL ", RedirectionTemplateData{})
+	assert.EqualError(t, err, "template: redirection:1: unclosed action")
+
+	_, err = ExecuteRedirectionTemplate("{{ unknown .PortalURL }}", RedirectionTemplateData{})
+	assert.EqualError(t, err, "template: redirection:1: function \"unknown\" not defined")
+
+	_, err = ExecuteRedirectionTemplate("{{ .Unknown }}", RedirectionTemplateData{})
+	assert.Error(t, err)
+}
+
+func TestShouldAllowRedirectionToAllowedDomains(t *testing.T) {
+	domains := []string{"example.com", ".example.org"}
+
+	testCases := map[string]bool{
+		"https://example.com":            true,
+		"https://home.example.com":       true,
+		"https://HOME.EXAMPLE.COM:8443/": true,
+		"https://app.example.org":        true,
+		"http://home.example.com":        false,
+		"https://evilexample.com":        false,
+		"https://example.com.evil.net":   false,
+		"https://example.net":            false,
+	}
+
+	for rawURL, expected := range testCases {
+		t.Run(rawURL, func(t *testing.T) {
+			u, err := url.Parse(rawURL)
+			require.NoError(t, err)
+
+			assert.Equal(t, expected, IsRedirectionAllowed(*u, domains))
+		})
+	}
+
+	u, err := url.Parse("https://example.com")
+	require.NoError(t, err)
+
+	assert.False(t, IsRedirectionAllowed(*u, []string{""}))
+}
+
+func TestShouldStripRedirectionParameters(t *testing.T) {
+	u, err := url.Parse("https://home.example.com/?z=1&token=abc&a=2")
+	require.NoError(t, err)
+
+	StripRedirectionParameters(u, []string{"code"})
+	assert.Equal(t, "https://home.example.com/?z=1&token=abc&a=2", u.String())
+
+	StripRedirectionParameters(u, []string{"token", "code"})
+	assert.Equal(t, "https://home.example.com/?a=2&z=1", u.String())
+}
+
+func TestShouldReturnRedirectionRejectionReason(t *testing.T) {
+	u, err := url.Parse("http://home.example.com")
+	require.NoError(t, err)
+
+	assert.Equal(t, ErrRedirectionSchemeNotSecure, CheckRedirection(*u, []string{"example.com"}))
+
+	u.Scheme = "https"
+
+	assert.NoError(t, CheckRedirection(*u, []string{"example.com"}))
+	assert.Equal(t, ErrRedirectionDomainNotAllowed, CheckRedirection(*u, []string{"example.org"}))
+}
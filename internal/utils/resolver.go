@@ -0,0 +1,163 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+// NormalizeResolverAddress adds the default DNS port to the address of a DNS server when it has none. The host must be
+// an IP address since the address of the resolver can't be resolved.
+func NormalizeResolverAddress(address string) (normalized string, err error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		host, port = strings.TrimSuffix(strings.TrimPrefix(address, "["), "]"), strconv.Itoa(dnsPort)
+	}
+
+	if ip := net.ParseIP(stripZone(host)); ip == nil {
+		return "", fmt.Errorf("the host must be an IP address")
+	}
+
+	if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+		return "", fmt.Errorf("the port must be between 1 and 65535")
+	}
+
+	return net.JoinHostPort(host, port), nil
+}
+
+// NewResolver creates a resolver querying the DNS servers of the configuration instead of the servers of the system.
+// The first server is queried until it fails to answer, then the next one is. With the rotation enabled, the queries are
+// spread across all the servers instead.
+func NewResolver(configuration schema.ResolverConfiguration) *net.Resolver {
+	// The timeout has already been validated.
+	timeout, _ := ParseDurationString(configuration.Timeout)
+
+	dialer := &resolverDialer{
+		addresses: configuration.Addresses,
+		rotate:    configuration.Rotate,
+		dialer:    &net.Dialer{Timeout: timeout},
+		timeout:   timeout,
+	}
+
+	return &net.Resolver{
+		PreferGo: true,
+		Dial:     dialer.DialContext,
+	}
+}
+
+type resolverDialer struct {
+	addresses []string
+	rotate    bool
+	dialer    *net.Dialer
+	timeout   time.Duration
+
+	next uint32
+}
+
+// DialContext dials the DNS server to query in place of the server of the system given by the resolver.
+func (d *resolverDialer) DialContext(ctx context.Context, network, _ string) (net.Conn, error) {
+	var index uint32
+
+	if d.rotate {
+		index = atomic.AddUint32(&d.next, 1) - 1
+	} else {
+		index = atomic.LoadUint32(&d.next)
+	}
+
+	conn, err := d.dialer.DialContext(ctx, network, d.addresses[index%uint32(len(d.addresses))])
+	if err != nil {
+		d.failover(index)
+		return nil, err
+	}
+
+	rc := &resolverConn{Conn: conn, dialer: d, index: index}
+
+	// The resolver only sends the queries in datagrams to the connections implementing net.PacketConn.
+	if pc, ok := conn.(net.PacketConn); ok {
+		return &resolverPacketConn{resolverConn: rc, packetConn: pc}, nil
+	}
+
+	return rc, nil
+}
+
+// failover switches to the next server when the server which failed is still the current one.
+func (d *resolverDialer) failover(index uint32) {
+	if !d.rotate {
+		atomic.CompareAndSwapUint32(&d.next, index, index+1)
+	}
+}
+
+// resolverConn bounds the deadlines set by the resolver with the configured timeout and reports the failures of the
+// server to the dialer.
+type resolverConn struct {
+	net.Conn
+
+	dialer *resolverDialer
+	index  uint32
+}
+
+func (c *resolverConn) SetDeadline(t time.Time) error {
+	return c.Conn.SetDeadline(c.bound(t))
+}
+
+func (c *resolverConn) SetReadDeadline(t time.Time) error {
+	return c.Conn.SetReadDeadline(c.bound(t))
+}
+
+func (c *resolverConn) SetWriteDeadline(t time.Time) error {
+	return c.Conn.SetWriteDeadline(c.bound(t))
+}
+
+func (c *resolverConn) Read(b []byte) (n int, err error) {
+	n, err = c.Conn.Read(b)
+	c.check(err)
+
+	return n, err
+}
+
+func (c *resolverConn) Write(b []byte) (n int, err error) {
+	n, err = c.Conn.Write(b)
+	c.check(err)
+
+	return n, err
+}
+
+func (c *resolverConn) bound(t time.Time) time.Time {
+	if deadline := time.Now().Add(c.dialer.timeout); t.IsZero() || deadline.Before(t) {
+		return deadline
+	}
+
+	return t
+}
+
+func (c *resolverConn) check(err error) {
+	if err != nil {
+		c.dialer.failover(c.index)
+	}
+}
+
+type resolverPacketConn struct {
+	*resolverConn
+
+	packetConn net.PacketConn
+}
+
+func (c *resolverPacketConn) ReadFrom(b []byte) (n int, addr net.Addr, err error) {
+	n, addr, err = c.packetConn.ReadFrom(b)
+	c.check(err)
+
+	return n, addr, err
+}
+
+func (c *resolverPacketConn) WriteTo(b []byte, addr net.Addr) (n int, err error) {
+	n, err = c.packetConn.WriteTo(b, addr)
+	c.check(err)
+
+	return n, err
+}
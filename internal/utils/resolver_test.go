@@ -0,0 +1,154 @@
+package utils
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+// startTestDNSServer starts a DNS server answering all the A queries with the given IP and the other queries with no
+// answer, and returns its address.
+func startTestDNSServer(t *testing.T, ip net.IP) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = conn.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+
+			// The question starts after the 12 bytes of the header and ends with its type and class.
+			end := 12
+			for end < n && buf[end] != 0 {
+				end += int(buf[end]) + 1
+			}
+
+			end += 5
+
+			qtype := binary.BigEndian.Uint16(buf[end-4 : end-2])
+
+			response := append([]byte{}, buf[:end]...)
+			response[2], response[3] = 0x81, 0x80
+
+			// No answer, authority or additional records unless it's an A query.
+			for i := 6; i < 12; i++ {
+				response[i] = 0
+			}
+
+			if qtype == 1 {
+				binary.BigEndian.PutUint16(response[6:8], 1)
+				response = append(response, 0xc0, 0x0c, 0x00, 0x01, 0x00, 0x01, 0x00, 0x00, 0x00, 0x3c, 0x00, 0x04)
+				response = append(response, ip.To4()...)
+			}
+
+			_, _ = conn.WriteTo(response, addr)
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+// unusedUDPAddress returns the address of a closed UDP port.
+func unusedUDPAddress(t *testing.T) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	address := conn.LocalAddr().String()
+	require.NoError(t, conn.Close())
+
+	return address
+}
+
+func TestShouldNormalizeResolverAddresses(t *testing.T) {
+	testCases := map[string]string{
+		"10.0.0.53":      "10.0.0.53:53",
+		"10.0.0.53:5353": "10.0.0.53:5353",
+		"fec0::53":       "[fec0::53]:53",
+		"[fec0::53]":     "[fec0::53]:53",
+		"[fec0::53]:853": "[fec0::53]:853",
+		"fe80::53%eth0":  "[fe80::53%eth0]:53",
+	}
+
+	for address, expected := range testCases {
+		t.Run(address, func(t *testing.T) {
+			normalized, err := NormalizeResolverAddress(address)
+			require.NoError(t, err)
+			assert.Equal(t, expected, normalized)
+		})
+	}
+
+	_, err := NormalizeResolverAddress("dns.example.com")
+	assert.EqualError(t, err, "the host must be an IP address")
+
+	_, err = NormalizeResolverAddress("10.0.0.53:99999")
+	assert.EqualError(t, err, "the port must be between 1 and 65535")
+}
+
+func TestShouldResolveWithTheConfiguredServers(t *testing.T) {
+	resolver := NewResolver(schema.ResolverConfiguration{
+		Addresses: []string{startTestDNSServer(t, net.ParseIP("10.1.2.3"))},
+		Timeout:   "2s",
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	addresses, err := resolver.LookupHost(ctx, "ldap.example.test.")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.1.2.3"}, addresses)
+}
+
+func TestShouldFailoverToTheNextServer(t *testing.T) {
+	resolver := NewResolver(schema.ResolverConfiguration{
+		Addresses: []string{unusedUDPAddress(t), startTestDNSServer(t, net.ParseIP("10.1.2.4"))},
+		Timeout:   "2s",
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	addresses, err := resolver.LookupHost(ctx, "smtp.example.test.")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.1.2.4"}, addresses)
+
+	// The next lookups directly query the server which answered.
+	addresses, err = resolver.LookupHost(ctx, "redis.example.test.")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.1.2.4"}, addresses)
+}
+
+func TestShouldRotateTheServers(t *testing.T) {
+	resolver := NewResolver(schema.ResolverConfiguration{
+		Addresses: []string{startTestDNSServer(t, net.ParseIP("10.1.2.5")), startTestDNSServer(t, net.ParseIP("10.1.2.6"))},
+		Timeout:   "2s",
+		Rotate:    true,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	seen := map[string]bool{}
+
+	for i := 0; i < 4; i++ {
+		addresses, err := resolver.LookupIP(ctx, "ip4", "webhook.example.test.")
+		require.NoError(t, err)
+		require.Len(t, addresses, 1)
+
+		seen[addresses[0].String()] = true
+	}
+
+	assert.Equal(t, map[string]bool{"10.1.2.5": true, "10.1.2.6": true}, seen)
+}
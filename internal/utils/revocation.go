@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/logging"
+)
+
+// ErrCertificateRevoked is returned when a certificate presented by a server has been revoked.
+var ErrCertificateRevoked = errors.New("tls: the certificate has been revoked")
+
+const ocspResponseMaxSize = 1024 * 1024
+
+var ocspHTTPClient = &http.Client{Timeout: 5 * time.Second}
+
+// revocationEnabled returns true when the configuration enables a revocation check.
+func revocationEnabled(config schema.TLSRevocationConfig) bool {
+	return config.CRLFile != "" || (config.OCSP != "" && config.OCSP != schema.OCSPModeDisable)
+}
+
+// checkRevocation checks the certificates of a verified chain are not revoked. The CRL file applies to every
+// certificate of the chain signed by its issuer while OCSP is only checked for the leaf certificate, using the response
+// stapled by the server when there is one.
+func checkRevocation(config schema.TLSRevocationConfig, chain []*x509.Certificate, staple []byte) error {
+	if config.CRLFile != "" {
+		if err := checkCRL(config.CRLFile, chain); err != nil {
+			return err
+		}
+	}
+
+	if config.OCSP == "" || config.OCSP == schema.OCSPModeDisable || len(chain) < 2 {
+		return nil
+	}
+
+	status, err := ocspStatus(chain[0], chain[1], staple)
+
+	switch {
+	case err == nil && status == ocsp.Good:
+		return nil
+	case err == nil && status == ocsp.Revoked:
+		return fmt.Errorf("%w: serial %s of %s has been revoked according to OCSP", ErrCertificateRevoked, chain[0].SerialNumber, chain[0].Subject)
+	case err == nil:
+		err = errors.New("the OCSP responder doesn't know the certificate")
+	}
+
+	if config.OCSP == schema.OCSPModeHardFail {
+		return fmt.Errorf("tls: unable to check the revocation of the certificate of %s: %w", chain[0].Subject, err)
+	}
+
+	logging.Logger().Warnf("Unable to check the revocation of the certificate of %s with OCSP, it's trusted anyway: %s", chain[0].Subject, err)
+
+	return nil
+}
+
+func checkCRL(path string, chain []*x509.Certificate) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("tls: unable to read the CRL file: %w", err)
+	}
+
+	crl, err := x509.ParseCRL(content)
+	if err != nil {
+		return fmt.Errorf("tls: unable to parse the CRL file %s: %w", path, err)
+	}
+
+	for i := 0; i < len(chain)-1; i++ {
+		// The CRL only applies to the certificates issued by the certificate it's signed with.
+		if chain[i+1].CheckCRLSignature(crl) != nil {
+			continue
+		}
+
+		for _, revoked := range crl.TBSCertList.RevokedCertificates {
+			if revoked.SerialNumber.Cmp(chain[i].SerialNumber) == 0 {
+				return fmt.Errorf("%w: serial %s of %s is listed in the CRL", ErrCertificateRevoked, chain[i].SerialNumber, chain[i].Subject)
+			}
+		}
+	}
+
+	return nil
+}
+
+func ocspStatus(certificate, issuer *x509.Certificate, staple []byte) (status int, err error) {
+	response := staple
+
+	if len(response) == 0 {
+		if response, err = queryOCSPResponder(certificate, issuer); err != nil {
+			return ocsp.Unknown, err
+		}
+	}
+
+	parsed, err := ocsp.ParseResponseForCert(response, certificate, issuer)
+	if err != nil {
+		return ocsp.Unknown, err
+	}
+
+	if !parsed.NextUpdate.IsZero() && parsed.NextUpdate.Before(time.Now()) {
+		return ocsp.Unknown, errors.New("the OCSP response is stale")
+	}
+
+	return parsed.Status, nil
+}
+
+func queryOCSPResponder(certificate, issuer *x509.Certificate) ([]byte, error) {
+	if len(certificate.OCSPServer) == 0 {
+		return nil, errors.New("the certificate doesn't have an OCSP responder")
+	}
+
+	request, err := ocsp.CreateRequest(certificate, issuer, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := ocspHTTPClient.Post(certificate.OCSPServer[0], "application/ocsp-request", bytes.NewReader(request))
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("the OCSP responder returned status %d", resp.StatusCode)
+	}
+
+	return ioutil.ReadAll(io.LimitReader(resp.Body, ocspResponseMaxSize))
+}
@@ -0,0 +1,188 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ocsp"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+type testPKI struct {
+	ca     *x509.Certificate
+	caKey  crypto.Signer
+	leaf   *x509.Certificate
+	server *httptest.Server
+}
+
+// newTestPKI creates a CA and a certificate for example.com served by a TLS server, the certificate refers to an OCSP
+// responder answering with the status given.
+func newTestPKI(t *testing.T, status int) *testPKI {
+	pki := &testPKI{}
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Authelia Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, caKey.Public(), caKey)
+	require.NoError(t, err)
+
+	pki.ca, err = x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	pki.caKey = caKey
+
+	responder := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		request, err := ocsp.ParseRequest(body)
+		require.NoError(t, err)
+
+		response, err := ocsp.CreateResponse(pki.ca, pki.ca, ocsp.Response{
+			Status:       status,
+			SerialNumber: request.SerialNumber,
+			ThisUpdate:   time.Now().Add(-time.Minute),
+			NextUpdate:   time.Now().Add(time.Hour),
+			RevokedAt:    time.Now().Add(-time.Minute),
+		}, caKey)
+		require.NoError(t, err)
+
+		_, _ = w.Write(response)
+	}))
+	t.Cleanup(responder.Close)
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	leafTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		DNSNames:     []string{"example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		OCSPServer:   []string{responder.URL},
+	}
+
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTemplate, pki.ca, leafKey.Public(), caKey)
+	require.NoError(t, err)
+
+	pki.leaf, err = x509.ParseCertificate(leafDER)
+	require.NoError(t, err)
+
+	pki.server = httptest.NewUnstartedServer(nil)
+	pki.server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{leafDER}, PrivateKey: leafKey, Leaf: pki.leaf}},
+	}
+	pki.server.StartTLS()
+	t.Cleanup(pki.server.Close)
+
+	return pki
+}
+
+func (pki *testPKI) certPool(t *testing.T) *X509CertPool {
+	directory := t.TempDir()
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(directory, "ca.pem"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: pki.ca.Raw}), 0600))
+
+	certPool, errs, _ := LoadX509CertPool(directory)
+	require.Len(t, errs, 0)
+
+	return certPool
+}
+
+func (pki *testPKI) handshake(t *testing.T, revocation schema.TLSRevocationConfig) error {
+	tlsConfig := NewTLSConfig(&schema.TLSConfig{ServerName: "example.com", Revocation: revocation}, tls.VersionTLS12, pki.certPool(t))
+
+	return handshake(pki.server.Listener.Addr().String(), tlsConfig)
+}
+
+func TestShouldAcceptCertificateWithGoodOCSPStatus(t *testing.T) {
+	pki := newTestPKI(t, ocsp.Good)
+
+	assert.NoError(t, pki.handshake(t, schema.TLSRevocationConfig{OCSP: schema.OCSPModeHardFail}))
+}
+
+func TestShouldRejectCertificateRevokedWithOCSP(t *testing.T) {
+	pki := newTestPKI(t, ocsp.Revoked)
+
+	assert.NoError(t, pki.handshake(t, schema.TLSRevocationConfig{OCSP: schema.OCSPModeDisable}))
+
+	err := pki.handshake(t, schema.TLSRevocationConfig{OCSP: schema.OCSPModeSoftFail})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrCertificateRevoked))
+}
+
+func TestShouldFailOCSPCheckDependingOnMode(t *testing.T) {
+	pki := newTestPKI(t, ocsp.Good)
+	chain := []*x509.Certificate{pki.leaf, pki.ca}
+
+	// The responder doesn't answer anymore.
+	pki.leaf.OCSPServer = []string{"http://127.0.0.1:1"}
+
+	assert.NoError(t, checkRevocation(schema.TLSRevocationConfig{OCSP: schema.OCSPModeSoftFail}, chain, nil))
+
+	err := checkRevocation(schema.TLSRevocationConfig{OCSP: schema.OCSPModeHardFail}, chain, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tls: unable to check the revocation of the certificate of CN=example.com")
+	assert.False(t, errors.Is(err, ErrCertificateRevoked))
+}
+
+func TestShouldRejectCertificateListedInCRL(t *testing.T) {
+	pki := newTestPKI(t, ocsp.Good)
+
+	writeCRL := func(serials ...int64) string {
+		var revoked []pkix.RevokedCertificate
+
+		for _, serial := range serials {
+			revoked = append(revoked, pkix.RevokedCertificate{SerialNumber: big.NewInt(serial), RevocationTime: time.Now()})
+		}
+
+		crl, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+			Number:              big.NewInt(1),
+			ThisUpdate:          time.Now(),
+			NextUpdate:          time.Now().Add(time.Hour),
+			RevokedCertificates: revoked,
+		}, pki.ca, pki.caKey)
+		require.NoError(t, err)
+
+		path := filepath.Join(t.TempDir(), "ca.crl")
+		require.NoError(t, ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: crl}), 0600))
+
+		return path
+	}
+
+	assert.NoError(t, pki.handshake(t, schema.TLSRevocationConfig{CRLFile: writeCRL(3)}))
+
+	err := pki.handshake(t, schema.TLSRevocationConfig{CRLFile: writeCRL(2)})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrCertificateRevoked))
+}
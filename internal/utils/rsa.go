@@ -1,11 +1,15 @@
 package utils
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
+	"fmt"
 )
 
 // GenerateRsaKeyPair generate an RSA key pair.
@@ -30,11 +34,17 @@ func ExportRsaPrivateKeyAsPemStr(privkey *rsa.PrivateKey) string {
 
 // ParseRsaPrivateKeyFromPemStr parse a RSA private key from PEM string.
 func ParseRsaPrivateKeyFromPemStr(privPEM string) (*rsa.PrivateKey, error) {
-	block, _ := pem.Decode([]byte(privPEM))
+	data := []byte(privPEM)
+	defer WipeBytes(data)
+
+	block, _ := pem.Decode(data)
 	if block == nil {
 		return nil, errors.New("failed to parse PEM block containing the key")
 	}
 
+	// The parsed key doesn't reference the DER encoded key so it's wiped once parsed.
+	defer WipeBytes(block.Bytes)
+
 	priv, err := x509.ParsePKCS1PrivateKey(block.Bytes)
 	if err != nil {
 		return nil, err
@@ -81,3 +91,44 @@ func ParseRsaPublicKeyFromPemStr(pubPEM string) (*rsa.PublicKey, error) {
 
 	return nil, errors.New("key type is not RSA")
 }
+
+// ParsePrivateKeyFromPemStr parse a RSA, ECDSA P-256 or Ed25519 private key from a PEM string. The RSA and ECDSA keys
+// can be in their PKCS #1 and SEC 1 formats or in the PKCS #8 format, the Ed25519 keys in the PKCS #8 format.
+func ParsePrivateKeyFromPemStr(privPEM string) (key crypto.Signer, err error) {
+	data := []byte(privPEM)
+	defer WipeBytes(data)
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("failed to parse PEM block containing the key")
+	}
+
+	// The parsed key doesn't reference the DER encoded key so it's wiped once parsed.
+	defer WipeBytes(block.Bytes)
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		if key, err = x509.ParseECPrivateKey(block.Bytes); err != nil {
+			return nil, err
+		}
+	default:
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		var ok bool
+
+		if key, ok = parsed.(crypto.Signer); !ok {
+			return nil, errors.New("key type is not RSA, ECDSA or Ed25519")
+		}
+	}
+
+	if key, ok := key.(*ecdsa.PrivateKey); ok && key.Curve != elliptic.P256() {
+		return nil, fmt.Errorf("ECDSA key curve %s is not supported, only P-256 is", key.Curve.Params().Name)
+	}
+
+	return key, nil
+}
@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// redacted replaces the secrets in the logs and the serialized values.
+const redacted = "[REDACTED]"
+
+// SecureBuffer holds a secret in memory which, where the platform supports it, is locked in RAM so it's never swapped
+// and excluded from the core dumps. The secret is zeroized when the buffer is wiped, and never printed, logged or
+// serialized: the buffer is formatted as [REDACTED].
+//
+// Reveal and RevealString are the only accessors of the secret so its uses can be audited by searching for them.
+type SecureBuffer struct {
+	data []byte
+	free func()
+}
+
+// NewSecureBuffer creates a SecureBuffer holding a copy of the secret. The caller should wipe the secret it passed
+// with WipeBytes once it's no longer needed.
+func NewSecureBuffer(secret []byte) *SecureBuffer {
+	buffer := &SecureBuffer{}
+	buffer.data, buffer.free = allocateSecureMemory(len(secret))
+
+	copy(buffer.data, secret)
+
+	// The memory allocated outside of the Go heap must be released even if the buffer isn't wiped.
+	runtime.SetFinalizer(buffer, (*SecureBuffer).Wipe)
+
+	return buffer
+}
+
+// NewSecureBufferFromString creates a SecureBuffer holding a copy of the secret. The strings are immutable, so the
+// original secret can't be zeroized and remains in memory until it's garbage collected.
+func NewSecureBufferFromString(secret string) *SecureBuffer {
+	return NewSecureBuffer([]byte(secret))
+}
+
+// Reveal returns the secret. The returned slice must neither be modified nor retained after the buffer is wiped.
+func (b *SecureBuffer) Reveal() []byte {
+	if b == nil {
+		return nil
+	}
+
+	return b.data
+}
+
+// RevealString returns a copy of the secret as a string for the APIs only accepting strings. The copy can't be
+// zeroized, so Reveal must be preferred whenever possible.
+func (b *SecureBuffer) RevealString() string {
+	return string(b.Reveal())
+}
+
+// Len returns the length of the secret.
+func (b *SecureBuffer) Len() int {
+	return len(b.Reveal())
+}
+
+// Wipe zeroizes the secret and releases the memory holding it. The buffer is empty afterwards.
+func (b *SecureBuffer) Wipe() {
+	if b == nil || b.free == nil {
+		return
+	}
+
+	WipeBytes(b.data)
+	b.free()
+
+	b.data, b.free = nil, nil
+
+	runtime.SetFinalizer(b, nil)
+}
+
+// String implements fmt.Stringer so the secret is never printed.
+func (b *SecureBuffer) String() string {
+	return redacted
+}
+
+// GoString implements fmt.GoStringer so the secret is never printed with the %#v verb.
+func (b *SecureBuffer) GoString() string {
+	return redacted
+}
+
+// Format implements fmt.Formatter so the secret is never printed whatever the verb.
+func (b *SecureBuffer) Format(f fmt.State, _ rune) {
+	_, _ = f.Write([]byte(redacted))
+}
+
+// MarshalText implements encoding.TextMarshaler so the secret is never serialized.
+func (b *SecureBuffer) MarshalText() ([]byte, error) {
+	return []byte(redacted), nil
+}
+
+// MarshalJSON implements json.Marshaler so the secret is never serialized.
+func (b *SecureBuffer) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + redacted + `"`), nil
+}
+
+// WipeBytes zeroizes the bytes of a secret.
+func WipeBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+
+	// Prevents the compiler from eliminating the zeroization of a slice which isn't used afterwards.
+	runtime.KeepAlive(b)
+}
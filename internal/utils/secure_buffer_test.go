@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldCopySecretToSecureBuffer(t *testing.T) {
+	secret := []byte("a secret")
+
+	buffer := NewSecureBuffer(secret)
+	defer buffer.Wipe()
+
+	WipeBytes(secret)
+
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 0}, secret)
+	assert.Equal(t, []byte("a secret"), buffer.Reveal())
+	assert.Equal(t, "a secret", buffer.RevealString())
+	assert.Equal(t, 8, buffer.Len())
+}
+
+func TestShouldWipeSecureBuffer(t *testing.T) {
+	buffer := NewSecureBufferFromString("a secret")
+	buffer.Wipe()
+
+	assert.Nil(t, buffer.Reveal())
+	assert.Equal(t, "", buffer.RevealString())
+	assert.Equal(t, 0, buffer.Len())
+
+	// Wiping twice doesn't release the memory twice.
+	buffer.Wipe()
+}
+
+func TestShouldHandleEmptyAndNilSecureBuffers(t *testing.T) {
+	buffer := NewSecureBuffer(nil)
+
+	assert.Equal(t, 0, buffer.Len())
+	assert.Equal(t, "", buffer.RevealString())
+
+	buffer.Wipe()
+
+	var nilBuffer *SecureBuffer
+
+	assert.Nil(t, nilBuffer.Reveal())
+	assert.Equal(t, 0, nilBuffer.Len())
+
+	nilBuffer.Wipe()
+}
+
+func TestShouldRedactSecureBuffer(t *testing.T) {
+	buffer := NewSecureBufferFromString("a secret")
+	defer buffer.Wipe()
+
+	for _, format := range []string{"%s", "%v", "%+v", "%#v", "%q", "%x", "%d"} {
+		assert.Equal(t, "[REDACTED]", fmt.Sprintf(format, buffer), format)
+	}
+
+	value := struct {
+		Password *SecureBuffer `json:"password"`
+	}{buffer}
+
+	assert.Equal(t, "{[REDACTED]}", fmt.Sprintf("%v", value))
+
+	data, err := json.Marshal(value)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"password":"[REDACTED]"}`, string(data))
+}
@@ -0,0 +1,37 @@
+//go:build linux
+// +build linux
+
+package utils
+
+import (
+	"os"
+	"syscall"
+)
+
+// madvDontDump is the MADV_DONTDUMP advice excluding the memory from the core dumps, which is missing from syscall.
+const madvDontDump = 0x10
+
+// allocateSecureMemory allocates the memory of a secret outside of the Go heap, so the garbage collector never copies
+// it, locked in RAM and excluded from the core dumps. It falls back to the Go heap if the memory can't be mapped.
+func allocateSecureMemory(size int) (data []byte, free func()) {
+	if size == 0 {
+		return []byte{}, func() {}
+	}
+
+	pageSize := os.Getpagesize()
+	length := (size + pageSize - 1) / pageSize * pageSize
+
+	memory, err := syscall.Mmap(-1, 0, length, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_PRIVATE|syscall.MAP_ANON)
+	if err != nil {
+		return make([]byte, size), func() {}
+	}
+
+	// Both are best effort: the locking fails beyond the RLIMIT_MEMLOCK limit of the process.
+	_ = syscall.Mlock(memory)
+	_ = syscall.Madvise(memory, madvDontDump)
+
+	return memory[:size], func() {
+		_ = syscall.Munlock(memory)
+		_ = syscall.Munmap(memory)
+	}
+}
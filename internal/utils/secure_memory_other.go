@@ -0,0 +1,10 @@
+//go:build !linux
+// +build !linux
+
+package utils
+
+// allocateSecureMemory allocates the memory of a secret on the Go heap, locking it and excluding it from the core
+// dumps is only supported on Linux.
+func allocateSecureMemory(size int) (data []byte, free func()) {
+	return make([]byte, size), func() {}
+}
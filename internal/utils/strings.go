@@ -1,7 +1,9 @@
 package utils
 
 import (
+	crand "crypto/rand"
 	"fmt"
+	"math/big"
 	"math/rand"
 	"net/url"
 	"strings"
@@ -139,3 +141,20 @@ func RandomString(n int, characters []rune) (randomString string) {
 
 	return string(b)
 }
+
+// RandomSecureString generate a random string of n characters using crypto/rand, suitable for secrets.
+func RandomSecureString(n int, characters []rune) (randomString string, err error) {
+	max := big.NewInt(int64(len(characters)))
+
+	b := make([]rune, n)
+	for i := range b {
+		index, err := crand.Int(crand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+
+		b[i] = characters[index.Int64()]
+	}
+
+	return string(b), nil
+}
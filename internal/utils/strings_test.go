@@ -131,3 +131,15 @@ func TestShouldNotFindStringInSliceFold(t *testing.T) {
 	assert.False(t, IsStringInSliceFold(a, slice))
 	assert.False(t, IsStringInSliceFold(b, slice))
 }
+
+func TestShouldGenerateRandomSecureString(t *testing.T) {
+	a, err := RandomSecureString(32, AlphaNumericCharacters)
+	require.NoError(t, err)
+
+	b, err := RandomSecureString(32, AlphaNumericCharacters)
+	require.NoError(t, err)
+
+	assert.Len(t, a, 32)
+	assert.True(t, IsStringAlphaNumeric(a))
+	assert.NotEqual(t, a, b)
+}
@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// UserAgent is a parsed representation of a User-Agent header.
+type UserAgent struct {
+	Browser string
+	OS      string
+	Device  string
+}
+
+// String returns a human readable description of the user agent such as 'Firefox on Linux'.
+func (ua UserAgent) String() string {
+	switch {
+	case ua.Browser == "" && ua.OS == "":
+		return "Unknown"
+	case ua.OS == "":
+		return ua.Browser
+	case ua.Browser == "":
+		return ua.OS
+	default:
+		return fmt.Sprintf("%s on %s", ua.Browser, ua.OS)
+	}
+}
+
+type userAgentToken struct {
+	token string
+	name  string
+}
+
+// The order matters as most browsers include the tokens of the browsers they are derived from.
+var userAgentBrowsers = []userAgentToken{
+	{"edg/", "Edge"},
+	{"edge/", "Edge"},
+	{"opr/", "Opera"},
+	{"opera", "Opera"},
+	{"vivaldi/", "Vivaldi"},
+	{"firefox/", "Firefox"},
+	{"fxios/", "Firefox"},
+	{"crios/", "Chrome"},
+	{"chromium/", "Chromium"},
+	{"chrome/", "Chrome"},
+	{"safari/", "Safari"},
+	{"msie ", "Internet Explorer"},
+	{"trident/", "Internet Explorer"},
+	{"curl/", "curl"},
+	{"wget/", "Wget"},
+}
+
+var userAgentOperatingSystems = []userAgentToken{
+	{"android", "Android"},
+	{"iphone", "iOS"},
+	{"ipad", "iOS"},
+	{"ipod", "iOS"},
+	{"windows", "Windows"},
+	{"mac os x", "macOS"},
+	{"macintosh", "macOS"},
+	{"cros", "Chrome OS"},
+	{"freebsd", "FreeBSD"},
+	{"openbsd", "OpenBSD"},
+	{"linux", "Linux"},
+}
+
+// ParseUserAgent parses the browser, operating system and device type from a User-Agent header value.
+// It is only intended to produce human readable descriptions and must not be relied upon for security decisions.
+func ParseUserAgent(value string) (ua UserAgent) {
+	lower := strings.ToLower(value)
+
+	for _, browser := range userAgentBrowsers {
+		if strings.Contains(lower, browser.token) {
+			ua.Browser = browser.name
+			break
+		}
+	}
+
+	for _, system := range userAgentOperatingSystems {
+		if strings.Contains(lower, system.token) {
+			ua.OS = system.name
+			break
+		}
+	}
+
+	switch {
+	case lower == "":
+		ua.Device = ""
+	case strings.Contains(lower, "ipad") || strings.Contains(lower, "tablet"):
+		ua.Device = "Tablet"
+	case strings.Contains(lower, "mobile") || strings.Contains(lower, "iphone") || strings.Contains(lower, "android"):
+		ua.Device = "Mobile"
+	case ua.Browser == "curl" || ua.Browser == "Wget":
+		ua.Device = "Other"
+	default:
+		ua.Device = "Desktop"
+	}
+
+	return ua
+}
@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldParseUserAgents(t *testing.T) {
+	testCases := []struct {
+		name     string
+		have     string
+		expected UserAgent
+		desc     string
+	}{
+		{
+			"Firefox on Linux",
+			"Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:88.0) Gecko/20100101 Firefox/88.0",
+			UserAgent{Browser: "Firefox", OS: "Linux", Device: "Desktop"},
+			"Firefox on Linux",
+		},
+		{
+			"Chrome on Windows",
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/90.0.4430.93 Safari/537.36",
+			UserAgent{Browser: "Chrome", OS: "Windows", Device: "Desktop"},
+			"Chrome on Windows",
+		},
+		{
+			"Edge on Windows",
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/90.0.4430.93 Safari/537.36 Edg/90.0.818.51",
+			UserAgent{Browser: "Edge", OS: "Windows", Device: "Desktop"},
+			"Edge on Windows",
+		},
+		{
+			"Safari on iPhone",
+			"Mozilla/5.0 (iPhone; CPU iPhone OS 14_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/14.1 Mobile/15E148 Safari/604.1",
+			UserAgent{Browser: "Safari", OS: "iOS", Device: "Mobile"},
+			"Safari on iOS",
+		},
+		{
+			"Chrome on Android",
+			"Mozilla/5.0 (Linux; Android 11; Pixel 5) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/90.0.4430.91 Mobile Safari/537.36",
+			UserAgent{Browser: "Chrome", OS: "Android", Device: "Mobile"},
+			"Chrome on Android",
+		},
+		{
+			"curl",
+			"curl/7.76.1",
+			UserAgent{Browser: "curl", Device: "Other"},
+			"curl",
+		},
+		{
+			"Empty",
+			"",
+			UserAgent{},
+			"Unknown",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ua := ParseUserAgent(tc.have)
+
+			assert.Equal(t, tc.expected, ua)
+			assert.Equal(t, tc.desc, ua.String())
+		})
+	}
+}
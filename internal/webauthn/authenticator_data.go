@@ -0,0 +1,129 @@
+package webauthn
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Flags of the authenticator data.
+const (
+	flagUserPresent            = byte(0x01)
+	flagUserVerified           = byte(0x04)
+	flagAttestedCredentialData = byte(0x40)
+)
+
+const (
+	ceremonyCreate = "webauthn.create"
+	ceremonyGet    = "webauthn.get"
+)
+
+// authenticatorData is the parsed authenticator data of an attestation or assertion.
+type authenticatorData struct {
+	rpIDHash  []byte
+	flags     byte
+	signCount uint32
+
+	// Only available when the attested credential data flag is set.
+	credentialID        []byte
+	credentialPublicKey []byte
+}
+
+func parseAuthenticatorData(data []byte) (authData *authenticatorData, err error) {
+	if len(data) < 37 {
+		return nil, errors.New("authenticator data is too short")
+	}
+
+	authData = &authenticatorData{
+		rpIDHash:  data[:32],
+		flags:     data[32],
+		signCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+
+	if authData.flags&flagAttestedCredentialData == 0 {
+		return authData, nil
+	}
+
+	// The attested credential data is the AAGUID (16 bytes), the credential ID length (2 bytes), the credential ID
+	// and the COSE encoded public key.
+	data = data[37:]
+
+	if len(data) < 18 {
+		return nil, errors.New("attested credential data is too short")
+	}
+
+	length := int(binary.BigEndian.Uint16(data[16:18]))
+	data = data[18:]
+
+	if len(data) < length {
+		return nil, errors.New("attested credential ID is too short")
+	}
+
+	authData.credentialID = data[:length]
+	data = data[length:]
+
+	_, rest, err := decodeCBOR(data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode credential public key: %w", err)
+	}
+
+	authData.credentialPublicKey = data[:len(data)-len(rest)]
+
+	return authData, nil
+}
+
+// verify checks the relying party ID hash and the flags of the authenticator data.
+func (a *authenticatorData) verify(rpID string, requireUserVerification bool) error {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+
+	if subtle.ConstantTimeCompare(a.rpIDHash, rpIDHash[:]) != 1 {
+		return ErrRelyingPartyMismatch
+	}
+
+	if a.flags&flagUserPresent == 0 {
+		return ErrUserNotPresent
+	}
+
+	if requireUserVerification && a.flags&flagUserVerified == 0 {
+		return ErrUserNotVerified
+	}
+
+	return nil
+}
+
+// collectedClientData is the client data collected by the browser during a ceremony.
+type collectedClientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+// verifyClientData checks the client data matches the ceremony, challenge and origin expected by the relying party.
+func verifyClientData(raw []byte, ceremony string, challenge []byte, origin string) error {
+	var clientData collectedClientData
+
+	if err := json.Unmarshal(raw, &clientData); err != nil {
+		return fmt.Errorf("unable to parse client data: %w", err)
+	}
+
+	if clientData.Type != ceremony {
+		return fmt.Errorf("unexpected ceremony type '%s'", clientData.Type)
+	}
+
+	received, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(clientData.Challenge, "="))
+	if err != nil || subtle.ConstantTimeCompare(received, challenge) != 1 {
+		return ErrChallengeMismatch
+	}
+
+	if clientData.Origin != origin {
+		return fmt.Errorf("%w: '%s'", ErrOriginMismatch, clientData.Origin)
+	}
+
+	return nil
+}
@@ -0,0 +1,166 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+const (
+	cborMajorUnsigned = iota
+	cborMajorNegative
+	cborMajorBytes
+	cborMajorText
+	cborMajorArray
+	cborMajorMap
+	cborMajorTag
+	cborMajorSimple
+)
+
+const cborMaxDepth = 16
+
+var errCBORTruncated = errors.New("cbor: unexpected end of data")
+
+// decodeCBOR decodes the first CBOR data item of data and returns it along with the remaining bytes.
+//
+// Only the subset of CBOR used by WebAuthn attestation objects and COSE keys is supported: integers, byte and text
+// strings, arrays, maps, tags, booleans and null. Integers are decoded as int64, byte strings as []byte, text strings
+// as string, arrays as []interface{} and maps as map[interface{}]interface{}.
+func decodeCBOR(data []byte) (value interface{}, rest []byte, err error) {
+	return decodeCBORItem(data, 0)
+}
+
+func decodeCBORItem(data []byte, depth int) (value interface{}, rest []byte, err error) {
+	if depth > cborMaxDepth {
+		return nil, nil, errors.New("cbor: maximum nesting depth exceeded")
+	}
+
+	if len(data) == 0 {
+		return nil, nil, errCBORTruncated
+	}
+
+	major, info := data[0]>>5, data[0]&0x1f
+
+	if major == cborMajorSimple {
+		switch info {
+		case 20:
+			return false, data[1:], nil
+		case 21:
+			return true, data[1:], nil
+		case 22, 23:
+			return nil, data[1:], nil
+		default:
+			return nil, nil, fmt.Errorf("cbor: unsupported simple value %d", info)
+		}
+	}
+
+	argument, data, err := decodeCBORArgument(info, data[1:])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch major {
+	case cborMajorUnsigned:
+		if argument > math.MaxInt64 {
+			return nil, nil, errors.New("cbor: integer overflow")
+		}
+
+		return int64(argument), data, nil
+	case cborMajorNegative:
+		if argument > math.MaxInt64 {
+			return nil, nil, errors.New("cbor: integer overflow")
+		}
+
+		return -1 - int64(argument), data, nil
+	case cborMajorBytes, cborMajorText:
+		if argument > uint64(len(data)) {
+			return nil, nil, errCBORTruncated
+		}
+
+		if major == cborMajorText {
+			return string(data[:argument]), data[argument:], nil
+		}
+
+		return append([]byte(nil), data[:argument]...), data[argument:], nil
+	case cborMajorArray:
+		// Each item is at least one byte long which bounds the allocation to the size of the input.
+		if argument > uint64(len(data)) {
+			return nil, nil, errCBORTruncated
+		}
+
+		items := make([]interface{}, argument)
+
+		for i := range items {
+			if items[i], data, err = decodeCBORItem(data, depth+1); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		return items, data, nil
+	case cborMajorMap:
+		if argument > uint64(len(data)) {
+			return nil, nil, errCBORTruncated
+		}
+
+		items := make(map[interface{}]interface{}, argument)
+
+		for i := uint64(0); i < argument; i++ {
+			var key, item interface{}
+
+			if key, data, err = decodeCBORItem(data, depth+1); err != nil {
+				return nil, nil, err
+			}
+
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, nil, fmt.Errorf("cbor: unsupported map key type %T", key)
+			}
+
+			if item, data, err = decodeCBORItem(data, depth+1); err != nil {
+				return nil, nil, err
+			}
+
+			items[key] = item
+		}
+
+		return items, data, nil
+	default:
+		// Tags carry no meaning for WebAuthn so the tagged item is returned as is.
+		return decodeCBORItem(data, depth+1)
+	}
+}
+
+func decodeCBORArgument(info byte, data []byte) (argument uint64, rest []byte, err error) {
+	switch {
+	case info < 24:
+		return uint64(info), data, nil
+	case info == 24:
+		if len(data) < 1 {
+			return 0, nil, errCBORTruncated
+		}
+
+		return uint64(data[0]), data[1:], nil
+	case info == 25:
+		if len(data) < 2 {
+			return 0, nil, errCBORTruncated
+		}
+
+		return uint64(binary.BigEndian.Uint16(data)), data[2:], nil
+	case info == 26:
+		if len(data) < 4 {
+			return 0, nil, errCBORTruncated
+		}
+
+		return uint64(binary.BigEndian.Uint32(data)), data[4:], nil
+	case info == 27:
+		if len(data) < 8 {
+			return 0, nil, errCBORTruncated
+		}
+
+		return binary.BigEndian.Uint64(data), data[8:], nil
+	default:
+		return 0, nil, errors.New("cbor: indefinite length items are not supported")
+	}
+}
@@ -0,0 +1,137 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encodeCBOR encodes the subset of CBOR supported by decodeCBOR, used to build test fixtures.
+func encodeCBOR(value interface{}) []byte {
+	header := func(major byte, argument uint64) []byte {
+		switch {
+		case argument < 24:
+			return []byte{major<<5 | byte(argument)}
+		case argument <= 0xff:
+			return []byte{major<<5 | 24, byte(argument)}
+		case argument <= 0xffff:
+			b := []byte{major<<5 | 25, 0, 0}
+			binary.BigEndian.PutUint16(b[1:], uint16(argument))
+
+			return b
+		default:
+			b := []byte{major<<5 | 26, 0, 0, 0, 0}
+			binary.BigEndian.PutUint32(b[1:], uint32(argument))
+
+			return b
+		}
+	}
+
+	switch v := value.(type) {
+	case int:
+		return encodeCBOR(int64(v))
+	case int64:
+		if v < 0 {
+			return header(cborMajorNegative, uint64(-1-v))
+		}
+
+		return header(cborMajorUnsigned, uint64(v))
+	case []byte:
+		return append(header(cborMajorBytes, uint64(len(v))), v...)
+	case string:
+		return append(header(cborMajorText, uint64(len(v))), v...)
+	case bool:
+		if v {
+			return []byte{0xf5}
+		}
+
+		return []byte{0xf4}
+	case nil:
+		return []byte{0xf6}
+	case []interface{}:
+		b := header(cborMajorArray, uint64(len(v)))
+		for _, item := range v {
+			b = append(b, encodeCBOR(item)...)
+		}
+
+		return b
+	case map[interface{}]interface{}:
+		b := header(cborMajorMap, uint64(len(v)))
+		for key, item := range v {
+			b = append(b, encodeCBOR(key)...)
+			b = append(b, encodeCBOR(item)...)
+		}
+
+		return b
+	default:
+		panic("unsupported type")
+	}
+}
+
+func TestShouldDecodeCBOR(t *testing.T) {
+	testCases := []struct {
+		name     string
+		have     []byte
+		expected interface{}
+	}{
+		{"Unsigned", []byte{0x18, 0x64}, int64(100)},
+		{"Negative", []byte{0x38, 0x63}, int64(-100)},
+		{"Bytes", []byte{0x43, 0x01, 0x02, 0x03}, []byte{1, 2, 3}},
+		{"Text", []byte{0x63, 'a', 'b', 'c'}, "abc"},
+		{"Array", []byte{0x82, 0x01, 0x20}, []interface{}{int64(1), int64(-1)}},
+		{"Map", []byte{0xa2, 0x01, 0x02, 0x61, 'a', 0xf5}, map[interface{}]interface{}{int64(1): int64(2), "a": true}},
+		{"Tag", []byte{0xc2, 0x41, 0x01}, []byte{1}},
+		{"Null", []byte{0xf6}, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			value, rest, err := decodeCBOR(append(tc.have, 0xff))
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, value)
+			assert.Equal(t, []byte{0xff}, rest)
+		})
+	}
+}
+
+func TestShouldRoundTripCBOR(t *testing.T) {
+	value := map[interface{}]interface{}{
+		int64(1):  int64(2),
+		int64(-2): []byte("0123456789012345678901234567890123456789"),
+		"fmt":     "none",
+		"attStmt": map[interface{}]interface{}{},
+	}
+
+	decoded, rest, err := decodeCBOR(encodeCBOR(value))
+
+	require.NoError(t, err)
+	assert.Len(t, rest, 0)
+	assert.Equal(t, value, decoded)
+}
+
+func TestShouldFailToDecodeInvalidCBOR(t *testing.T) {
+	testCases := []struct {
+		name string
+		have []byte
+		err  string
+	}{
+		{"Empty", []byte{}, "cbor: unexpected end of data"},
+		{"TruncatedBytes", []byte{0x43, 0x01}, "cbor: unexpected end of data"},
+		{"TruncatedArgument", []byte{0x19, 0x01}, "cbor: unexpected end of data"},
+		{"Indefinite", []byte{0x5f}, "cbor: indefinite length items are not supported"},
+		{"Float", []byte{0xf9, 0x00, 0x00}, "cbor: unsupported simple value 25"},
+		{"HugeArray", []byte{0x9a, 0xff, 0xff, 0xff, 0xff}, "cbor: unexpected end of data"},
+		{"InvalidMapKey", []byte{0xa1, 0x41, 0x01, 0x01}, "cbor: unsupported map key type []uint8"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := decodeCBOR(tc.have)
+
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
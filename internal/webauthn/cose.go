@@ -0,0 +1,141 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// COSE key parameters, see https://www.iana.org/assignments/cose/cose.xhtml.
+const (
+	coseKeyType   = int64(1)
+	coseAlgorithm = int64(3)
+
+	coseKeyTypeOKP = int64(1)
+	coseKeyTypeEC2 = int64(2)
+	coseKeyTypeRSA = int64(3)
+
+	coseEC2Curve = int64(-1)
+	coseEC2X     = int64(-2)
+	coseEC2Y     = int64(-3)
+
+	coseOKPCurve = int64(-1)
+	coseOKPX     = int64(-2)
+
+	coseRSAModulus  = int64(-1)
+	coseRSAExponent = int64(-2)
+
+	coseCurveP256    = int64(1)
+	coseCurveEd25519 = int64(6)
+)
+
+// COSE algorithm identifiers supported for credentials.
+const (
+	AlgorithmES256 = int64(-7)
+	AlgorithmEdDSA = int64(-8)
+	AlgorithmRS256 = int64(-257)
+)
+
+// supportedAlgorithms lists the supported algorithms in order of preference.
+var supportedAlgorithms = []int64{AlgorithmES256, AlgorithmEdDSA, AlgorithmRS256}
+
+// coseKey is a decoded COSE_Key.
+type coseKey struct {
+	algorithm int64
+	key       crypto.PublicKey
+}
+
+// parseCOSEKey parses a CBOR encoded COSE_Key.
+func parseCOSEKey(data []byte) (key *coseKey, err error) {
+	value, rest, err := decodeCBOR(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rest) != 0 {
+		return nil, errors.New("unexpected trailing data after public key")
+	}
+
+	params, ok := value.(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.New("public key is not a map")
+	}
+
+	keyType, _ := params[coseKeyType].(int64)
+	algorithm, _ := params[coseAlgorithm].(int64)
+
+	key = &coseKey{algorithm: algorithm}
+
+	switch {
+	case keyType == coseKeyTypeEC2 && algorithm == AlgorithmES256:
+		curve, _ := params[coseEC2Curve].(int64)
+		x, _ := params[coseEC2X].([]byte)
+		y, _ := params[coseEC2Y].([]byte)
+
+		if curve != coseCurveP256 || len(x) != 32 || len(y) != 32 {
+			return nil, errors.New("invalid EC2 public key parameters")
+		}
+
+		publicKey := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+
+		if !publicKey.Curve.IsOnCurve(publicKey.X, publicKey.Y) {
+			return nil, errors.New("EC2 public key is not on the curve")
+		}
+
+		key.key = publicKey
+	case keyType == coseKeyTypeOKP && algorithm == AlgorithmEdDSA:
+		curve, _ := params[coseOKPCurve].(int64)
+		x, _ := params[coseOKPX].([]byte)
+
+		if curve != coseCurveEd25519 || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid OKP public key parameters")
+		}
+
+		key.key = ed25519.PublicKey(x)
+	case keyType == coseKeyTypeRSA && algorithm == AlgorithmRS256:
+		n, _ := params[coseRSAModulus].([]byte)
+		e, _ := params[coseRSAExponent].([]byte)
+
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("invalid RSA public key parameters")
+		}
+
+		key.key = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	default:
+		return nil, fmt.Errorf("unsupported public key type %d with algorithm %d", keyType, algorithm)
+	}
+
+	return key, nil
+}
+
+// verify the signature of data.
+func (k *coseKey) verify(data, signature []byte) error {
+	switch publicKey := k.key.(type) {
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(data)
+
+		if !ecdsa.VerifyASN1(publicKey, digest[:], signature) {
+			return ErrInvalidSignature
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(publicKey, data, signature) {
+			return ErrInvalidSignature
+		}
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(data)
+
+		if rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature) != nil {
+			return ErrInvalidSignature
+		}
+	default:
+		return fmt.Errorf("unsupported public key type %T", k.key)
+	}
+
+	return nil
+}
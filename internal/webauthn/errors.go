@@ -0,0 +1,27 @@
+package webauthn
+
+import "errors"
+
+var (
+	// ErrChallengeMismatch is returned when the challenge signed by the authenticator is not the expected one.
+	ErrChallengeMismatch = errors.New("challenge mismatch")
+
+	// ErrOriginMismatch is returned when the ceremony was performed from an unexpected origin.
+	ErrOriginMismatch = errors.New("origin mismatch")
+
+	// ErrRelyingPartyMismatch is returned when the credential is scoped to another relying party.
+	ErrRelyingPartyMismatch = errors.New("relying party ID mismatch")
+
+	// ErrUserNotPresent is returned when the authenticator did not test the presence of the user.
+	ErrUserNotPresent = errors.New("user presence is required")
+
+	// ErrUserNotVerified is returned when user verification is required but wasn't performed by the authenticator.
+	ErrUserNotVerified = errors.New("user verification is required")
+
+	// ErrInvalidSignature is returned when the signature of the assertion is invalid.
+	ErrInvalidSignature = errors.New("invalid signature")
+
+	// ErrSignCountInvalid is returned when the signature counter did not increase which may indicate the authenticator
+	// has been cloned.
+	ErrSignCountInvalid = errors.New("signature counter did not increase, the authenticator may have been cloned")
+)
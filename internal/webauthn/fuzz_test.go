@@ -0,0 +1,64 @@
+//go:build go1.18
+// +build go1.18
+
+package webauthn
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+)
+
+func FuzzDecodeCBOR(f *testing.F) {
+	f.Add(encodeCBOR(map[interface{}]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[interface{}]interface{}{},
+		"authData": []byte{0x01, 0x02, 0x03},
+	}))
+	f.Add(encodeCBOR([]interface{}{int64(-1), int64(1 << 40), "text", true, nil}))
+	f.Add([]byte{0x9f, 0xff})
+	f.Add([]byte{0x5b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		_, rest, err := decodeCBOR(data)
+		if err != nil {
+			return
+		}
+
+		if len(rest) >= len(data) || !bytes.HasSuffix(data, rest) {
+			t.Fatalf("the remaining bytes aren't a suffix of the data decoded: %x %x", data, rest)
+		}
+	})
+}
+
+func FuzzParseAuthenticatorData(f *testing.F) {
+	rpIDHash := sha256.Sum256([]byte("example.com"))
+
+	attested := append(append([]byte{}, rpIDHash[:]...), flagUserPresent|flagAttestedCredentialData, 0, 0, 0, 1)
+	attested = append(attested, make([]byte, 16)...)
+	attested = append(attested, 0, 2, 0xca, 0xfe)
+	attested = append(attested, encodeCBOR(map[interface{}]interface{}{int64(1): int64(2), int64(3): int64(-7)})...)
+
+	f.Add(append(append([]byte{}, rpIDHash[:]...), flagUserPresent, 0, 0, 0, 1))
+	f.Add(attested)
+	f.Add(attested[:40])
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		authData, err := parseAuthenticatorData(data)
+		if err != nil {
+			return
+		}
+
+		if len(authData.rpIDHash) != sha256.Size {
+			t.Fatalf("the relying party ID hash is %d bytes long", len(authData.rpIDHash))
+		}
+
+		if authData.flags&flagAttestedCredentialData == 0 {
+			return
+		}
+
+		if _, rest, err := decodeCBOR(authData.credentialPublicKey); err != nil || len(rest) != 0 {
+			t.Fatalf("the credential public key isn't a single CBOR data item: %x", authData.credentialPublicKey)
+		}
+	})
+}
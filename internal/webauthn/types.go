@@ -0,0 +1,123 @@
+package webauthn
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+)
+
+// URLEncodedBase64 is a byte slice encoded in JSON as an unpadded base64url string as used by the WebAuthn API.
+type URLEncodedBase64 []byte
+
+// MarshalJSON encodes the bytes as an unpadded base64url string.
+func (b URLEncodedBase64) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.RawURLEncoding.EncodeToString(b))
+}
+
+// UnmarshalJSON decodes the bytes from a base64url string, padded or not.
+func (b *URLEncodedBase64) UnmarshalJSON(data []byte) error {
+	var value string
+
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+	if err != nil {
+		return err
+	}
+
+	*b = decoded
+
+	return nil
+}
+
+// RelyingPartyEntity describes the relying party to the authenticator.
+type RelyingPartyEntity struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// UserEntity describes the user account to the authenticator.
+type UserEntity struct {
+	ID          URLEncodedBase64 `json:"id"`
+	Name        string           `json:"name"`
+	DisplayName string           `json:"displayName"`
+}
+
+// CredentialParameters describes a type of credential the relying party accepts.
+type CredentialParameters struct {
+	Type      string `json:"type"`
+	Algorithm int64  `json:"alg"`
+}
+
+// CredentialDescriptor identifies a credential.
+type CredentialDescriptor struct {
+	Type string           `json:"type"`
+	ID   URLEncodedBase64 `json:"id"`
+}
+
+// AuthenticatorSelection describes the requirements on the authenticator during registration.
+type AuthenticatorSelection struct {
+	ResidentKey        string `json:"residentKey"`
+	RequireResidentKey bool   `json:"requireResidentKey"`
+	UserVerification   string `json:"userVerification"`
+}
+
+// CreationOptions are the options passed to navigator.credentials.create() to register a credential.
+type CreationOptions struct {
+	Challenge              URLEncodedBase64       `json:"challenge"`
+	RelyingParty           RelyingPartyEntity     `json:"rp"`
+	User                   UserEntity             `json:"user"`
+	Parameters             []CredentialParameters `json:"pubKeyCredParams"`
+	Timeout                int                    `json:"timeout,omitempty"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials,omitempty"`
+	AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+// RequestOptions are the options passed to navigator.credentials.get() to authenticate with a credential.
+type RequestOptions struct {
+	Challenge        URLEncodedBase64       `json:"challenge"`
+	Timeout          int                    `json:"timeout,omitempty"`
+	RelyingPartyID   string                 `json:"rpId"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials,omitempty"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+// AttestationResponse is the response of the authenticator to navigator.credentials.create().
+type AttestationResponse struct {
+	ClientDataJSON    URLEncodedBase64 `json:"clientDataJSON"`
+	AttestationObject URLEncodedBase64 `json:"attestationObject"`
+}
+
+// AssertionResponse is the response of the authenticator to navigator.credentials.get().
+type AssertionResponse struct {
+	ClientDataJSON    URLEncodedBase64 `json:"clientDataJSON"`
+	AuthenticatorData URLEncodedBase64 `json:"authenticatorData"`
+	Signature         URLEncodedBase64 `json:"signature"`
+	UserHandle        URLEncodedBase64 `json:"userHandle"`
+}
+
+// CredentialAttestation is the public key credential returned by navigator.credentials.create().
+type CredentialAttestation struct {
+	ID       string              `json:"id"`
+	RawID    URLEncodedBase64    `json:"rawId"`
+	Type     string              `json:"type"`
+	Response AttestationResponse `json:"response"`
+}
+
+// CredentialAssertion is the public key credential returned by navigator.credentials.get().
+type CredentialAssertion struct {
+	ID       string            `json:"id"`
+	RawID    URLEncodedBase64  `json:"rawId"`
+	Type     string            `json:"type"`
+	Response AssertionResponse `json:"response"`
+}
+
+// Credential is a credential which has been successfully registered.
+type Credential struct {
+	ID        []byte
+	PublicKey []byte
+	SignCount uint32
+}
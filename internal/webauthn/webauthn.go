@@ -0,0 +1,203 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+const (
+	challengeLength  = 32
+	publicKeyType    = "public-key"
+	ceremonyTimeout  = 60000
+	attestationNone  = "none"
+	residentRequired = "required"
+
+	residentDiscouraged = "discouraged"
+
+	// UserVerificationRequired requires the authenticator to verify the user, for example with a PIN or biometrics.
+	UserVerificationRequired = "required"
+
+	// UserVerificationPreferred asks the authenticator to verify the user when it's able to.
+	UserVerificationPreferred = "preferred"
+)
+
+// RelyingParty performs the WebAuthn ceremonies for a relying party.
+//
+// Attestation statements are not verified, the relying party trusts the authenticator on first use, which is the
+// equivalent of requesting the 'none' attestation conveyance.
+type RelyingParty struct {
+	// ID is the relying party ID which credentials are scoped to, it's a registrable domain suffix of the origin.
+	ID string
+
+	// Name is the name of the relying party displayed to the user by the authenticator.
+	Name string
+
+	// Origin is the origin the ceremonies are expected to be performed from such as 'https://auth.example.com'.
+	Origin string
+}
+
+// NewChallenge generates a new random challenge.
+func NewChallenge() ([]byte, error) {
+	challenge := make([]byte, challengeLength)
+
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, err
+	}
+
+	return challenge, nil
+}
+
+// NewCreationOptions returns the options to register a discoverable credential for the given user. The existing
+// credentials of the user are excluded so the same authenticator isn't registered twice.
+func (rp RelyingParty) NewCreationOptions(challenge, userID []byte, username, displayName string, existing [][]byte, userVerification string) CreationOptions {
+	return rp.newCreationOptions(challenge, userID, username, displayName, existing, userVerification, true)
+}
+
+// NewSecondFactorCreationOptions returns the options to register a credential used as a second factor for the given
+// user. The credential doesn't need to be discoverable since the user is known when it's used, so the security keys
+// unable to store discoverable credentials can be registered too.
+func (rp RelyingParty) NewSecondFactorCreationOptions(challenge, userID []byte, username, displayName string, existing [][]byte) CreationOptions {
+	return rp.newCreationOptions(challenge, userID, username, displayName, existing, UserVerificationPreferred, false)
+}
+
+func (rp RelyingParty) newCreationOptions(challenge, userID []byte, username, displayName string, existing [][]byte, userVerification string, discoverable bool) CreationOptions {
+	options := CreationOptions{
+		Challenge:    challenge,
+		RelyingParty: RelyingPartyEntity{ID: rp.ID, Name: rp.Name},
+		User:         UserEntity{ID: userID, Name: username, DisplayName: displayName},
+		Timeout:      ceremonyTimeout,
+		AuthenticatorSelection: AuthenticatorSelection{
+			ResidentKey:        residentDiscouraged,
+			RequireResidentKey: discoverable,
+			UserVerification:   userVerification,
+		},
+		Attestation: attestationNone,
+	}
+
+	if discoverable {
+		options.AuthenticatorSelection.ResidentKey = residentRequired
+	}
+
+	for _, algorithm := range supportedAlgorithms {
+		options.Parameters = append(options.Parameters, CredentialParameters{Type: publicKeyType, Algorithm: algorithm})
+	}
+
+	for _, id := range existing {
+		options.ExcludeCredentials = append(options.ExcludeCredentials, CredentialDescriptor{Type: publicKeyType, ID: id})
+	}
+
+	return options
+}
+
+// NewRequestOptions returns the options to authenticate with a discoverable credential.
+func (rp RelyingParty) NewRequestOptions(challenge []byte, userVerification string) RequestOptions {
+	return RequestOptions{
+		Challenge:        challenge,
+		Timeout:          ceremonyTimeout,
+		RelyingPartyID:   rp.ID,
+		UserVerification: userVerification,
+	}
+}
+
+// NewSecondFactorRequestOptions returns the options to authenticate with one of the given credentials of a user who
+// already completed the first factor.
+func (rp RelyingParty) NewSecondFactorRequestOptions(challenge []byte, credentials [][]byte) RequestOptions {
+	options := rp.NewRequestOptions(challenge, UserVerificationPreferred)
+
+	for _, id := range credentials {
+		options.AllowCredentials = append(options.AllowCredentials, CredentialDescriptor{Type: publicKeyType, ID: id})
+	}
+
+	return options
+}
+
+// VerifyRegistration verifies the attestation of a new credential and returns the credential to save.
+func (rp RelyingParty) VerifyRegistration(challenge []byte, attestation CredentialAttestation, requireUserVerification bool) (credential *Credential, err error) {
+	if err = verifyClientData(attestation.Response.ClientDataJSON, ceremonyCreate, challenge, rp.Origin); err != nil {
+		return nil, err
+	}
+
+	value, _, err := decodeCBOR(attestation.Response.AttestationObject)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode attestation object: %w", err)
+	}
+
+	object, ok := value.(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.New("attestation object is not a map")
+	}
+
+	rawAuthData, ok := object["authData"].([]byte)
+	if !ok {
+		return nil, errors.New("attestation object has no authenticator data")
+	}
+
+	authData, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = authData.verify(rp.ID, requireUserVerification); err != nil {
+		return nil, err
+	}
+
+	if authData.credentialID == nil {
+		return nil, errors.New("attestation has no attested credential data")
+	}
+
+	if !bytes.Equal(authData.credentialID, attestation.RawID) {
+		return nil, errors.New("attested credential ID does not match the credential")
+	}
+
+	if _, err = parseCOSEKey(authData.credentialPublicKey); err != nil {
+		return nil, err
+	}
+
+	return &Credential{
+		ID:        authData.credentialID,
+		PublicKey: authData.credentialPublicKey,
+		SignCount: authData.signCount,
+	}, nil
+}
+
+// VerifyAssertion verifies the assertion made with a registered credential and returns the new signature counter.
+func (rp RelyingParty) VerifyAssertion(challenge []byte, credential Credential, assertion CredentialAssertion, requireUserVerification bool) (signCount uint32, err error) {
+	if !bytes.Equal(credential.ID, assertion.RawID) {
+		return 0, errors.New("assertion was not made with the credential")
+	}
+
+	if err = verifyClientData(assertion.Response.ClientDataJSON, ceremonyGet, challenge, rp.Origin); err != nil {
+		return 0, err
+	}
+
+	authData, err := parseAuthenticatorData(assertion.Response.AuthenticatorData)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = authData.verify(rp.ID, requireUserVerification); err != nil {
+		return 0, err
+	}
+
+	key, err := parseCOSEKey(credential.PublicKey)
+	if err != nil {
+		return 0, err
+	}
+
+	clientDataHash := sha256.Sum256(assertion.Response.ClientDataJSON)
+	signed := append(append([]byte(nil), assertion.Response.AuthenticatorData...), clientDataHash[:]...)
+
+	if err = key.verify(signed, assertion.Response.Signature); err != nil {
+		return 0, err
+	}
+
+	// Authenticators which don't implement a counter always report zero.
+	if (authData.signCount != 0 || credential.SignCount != 0) && authData.signCount <= credential.SignCount {
+		return 0, ErrSignCountInvalid
+	}
+
+	return authData.signCount, nil
+}
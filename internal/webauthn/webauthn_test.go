@@ -0,0 +1,140 @@
+package webauthn_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/mocks"
+	"github.com/authelia/authelia/internal/webauthn"
+)
+
+var testRelyingParty = webauthn.RelyingParty{ID: "example.com", Name: "Authelia", Origin: "https://login.example.com"}
+
+func registerTestCredential(t *testing.T, authenticator *mocks.WebauthnAuthenticator) *webauthn.Credential {
+	challenge, err := webauthn.NewChallenge()
+	require.NoError(t, err)
+
+	credential, err := testRelyingParty.VerifyRegistration(challenge, authenticator.Create(testRelyingParty.ID, testRelyingParty.Origin, challenge), true)
+	require.NoError(t, err)
+
+	return credential
+}
+
+func TestShouldRegisterAndAuthenticateWithCredential(t *testing.T) {
+	authenticator := mocks.NewWebauthnAuthenticator([]byte("john"))
+	credential := registerTestCredential(t, authenticator)
+
+	assert.Equal(t, authenticator.CredentialID, credential.ID)
+	assert.Equal(t, authenticator.PublicKey(), credential.PublicKey)
+	assert.Equal(t, uint32(0), credential.SignCount)
+
+	for i := 1; i <= 2; i++ {
+		challenge, err := webauthn.NewChallenge()
+		require.NoError(t, err)
+
+		signCount, err := testRelyingParty.VerifyAssertion(challenge, *credential, authenticator.Get(testRelyingParty.ID, testRelyingParty.Origin, challenge), true)
+		require.NoError(t, err)
+		assert.Equal(t, uint32(i), signCount)
+
+		credential.SignCount = signCount
+	}
+}
+
+func TestShouldFailRegistrationWithWrongChallengeOrOrigin(t *testing.T) {
+	authenticator := mocks.NewWebauthnAuthenticator([]byte("john"))
+
+	challenge, err := webauthn.NewChallenge()
+	require.NoError(t, err)
+
+	_, err = testRelyingParty.VerifyRegistration([]byte("another challenge"), authenticator.Create(testRelyingParty.ID, testRelyingParty.Origin, challenge), true)
+	assert.Equal(t, webauthn.ErrChallengeMismatch, err)
+
+	_, err = testRelyingParty.VerifyRegistration(challenge, authenticator.Create(testRelyingParty.ID, "https://evil.example.net", challenge), true)
+	assert.True(t, errors.Is(err, webauthn.ErrOriginMismatch))
+
+	_, err = testRelyingParty.VerifyRegistration(challenge, authenticator.Create("example.net", testRelyingParty.Origin, challenge), true)
+	assert.Equal(t, webauthn.ErrRelyingPartyMismatch, err)
+}
+
+func TestShouldRequireUserVerificationWhenConfigured(t *testing.T) {
+	authenticator := mocks.NewWebauthnAuthenticator([]byte("john"))
+	credential := registerTestCredential(t, authenticator)
+
+	authenticator.UserVerified = false
+
+	challenge, err := webauthn.NewChallenge()
+	require.NoError(t, err)
+
+	_, err = testRelyingParty.VerifyAssertion(challenge, *credential, authenticator.Get(testRelyingParty.ID, testRelyingParty.Origin, challenge), true)
+	assert.Equal(t, webauthn.ErrUserNotVerified, err)
+
+	_, err = testRelyingParty.VerifyAssertion(challenge, *credential, authenticator.Get(testRelyingParty.ID, testRelyingParty.Origin, challenge), false)
+	assert.NoError(t, err)
+}
+
+func TestShouldFailAssertionWithInvalidSignature(t *testing.T) {
+	authenticator := mocks.NewWebauthnAuthenticator([]byte("john"))
+	credential := registerTestCredential(t, authenticator)
+
+	challenge, err := webauthn.NewChallenge()
+	require.NoError(t, err)
+
+	assertion := authenticator.Get(testRelyingParty.ID, testRelyingParty.Origin, challenge)
+	assertion.Response.AuthenticatorData[36]++
+
+	_, err = testRelyingParty.VerifyAssertion(challenge, *credential, assertion, true)
+	assert.Equal(t, webauthn.ErrInvalidSignature, err)
+}
+
+func TestShouldFailAssertionWhenSignCountDoesNotIncrease(t *testing.T) {
+	authenticator := mocks.NewWebauthnAuthenticator([]byte("john"))
+	credential := registerTestCredential(t, authenticator)
+	credential.SignCount = 5
+
+	challenge, err := webauthn.NewChallenge()
+	require.NoError(t, err)
+
+	_, err = testRelyingParty.VerifyAssertion(challenge, *credential, authenticator.Get(testRelyingParty.ID, testRelyingParty.Origin, challenge), true)
+	assert.Equal(t, webauthn.ErrSignCountInvalid, err)
+}
+
+func TestShouldFailAssertionWithAnotherCredential(t *testing.T) {
+	authenticator := mocks.NewWebauthnAuthenticator([]byte("john"))
+	other := mocks.NewWebauthnAuthenticator([]byte("john"))
+	credential := registerTestCredential(t, authenticator)
+
+	challenge, err := webauthn.NewChallenge()
+	require.NoError(t, err)
+
+	_, err = testRelyingParty.VerifyAssertion(challenge, *credential, other.Get(testRelyingParty.ID, testRelyingParty.Origin, challenge), true)
+	assert.EqualError(t, err, "assertion was not made with the credential")
+}
+
+func TestShouldMarshalOptionsAsURLEncodedBase64(t *testing.T) {
+	options := testRelyingParty.NewRequestOptions([]byte{0xfb, 0xff}, webauthn.UserVerificationRequired)
+
+	data, err := json.Marshal(options)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"challenge":"-_8","timeout":60000,"rpId":"example.com","userVerification":"required"}`, string(data))
+
+	var decoded webauthn.RequestOptions
+
+	require.NoError(t, json.Unmarshal([]byte(`{"challenge":"-_8="}`), &decoded))
+	assert.Equal(t, webauthn.URLEncodedBase64{0xfb, 0xff}, decoded.Challenge)
+}
+
+func TestShouldListCredentialsInSecondFactorOptions(t *testing.T) {
+	creation := testRelyingParty.NewSecondFactorCreationOptions([]byte("challenge"), []byte("john"), "john", "John Doe", [][]byte{{0x01}})
+
+	assert.Equal(t, webauthn.AuthenticatorSelection{ResidentKey: "discouraged", UserVerification: webauthn.UserVerificationPreferred}, creation.AuthenticatorSelection)
+	assert.Equal(t, []webauthn.CredentialDescriptor{{Type: "public-key", ID: []byte{0x01}}}, creation.ExcludeCredentials)
+
+	request := testRelyingParty.NewSecondFactorRequestOptions([]byte("challenge"), [][]byte{{0x01}, {0x02}})
+
+	assert.Equal(t, webauthn.UserVerificationPreferred, request.UserVerification)
+	assert.Equal(t, []webauthn.CredentialDescriptor{{Type: "public-key", ID: []byte{0x01}}, {Type: "public-key", ID: []byte{0x02}}}, request.AllowCredentials)
+}
@@ -0,0 +1,4 @@
+// Package autheliatest provides the fakes and the fixtures which allow extensions and suite authors to test against
+// working implementations rather than strict mock expectations: an in-memory storage provider, a fake LDAP server
+// serving an in-memory directory and a builder of user sessions.
+package autheliatest
@@ -0,0 +1,554 @@
+package autheliatest
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+)
+
+const (
+	ldapOIDPasswordModify           = "1.3.6.1.4.1.4203.1.11.1"
+	ldapPasswordAttribute           = "userPassword"
+	ldapSupportedExtensionAttribute = "supportedExtension"
+)
+
+// LDAPEntry is an entry of the directory served by the LDAPServer.
+type LDAPEntry struct {
+	DN         string
+	Attributes map[string][]string
+}
+
+// LDAPServer is a fake LDAP server serving an in-memory directory on a local TCP listener. It supports the operations
+// used by the LDAP user provider, i.e. the simple binds checked against the userPassword attribute, the searches with
+// the and, or, not, equality, substrings and presence filters, the modifications and the password modify extended
+// operation. The root DSE advertises the password modify extended operation.
+type LDAPServer struct {
+	// URL is the ldap:// URL the server listens on.
+	URL string
+
+	listener net.Listener
+	wg       sync.WaitGroup
+
+	mutex   sync.RWMutex
+	entries []*LDAPEntry
+	conns   map[net.Conn]struct{}
+}
+
+// NewLDAPServer starts a new LDAPServer serving an empty directory on a random local port.
+func NewLDAPServer() (*LDAPServer, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	server := &LDAPServer{
+		URL:      fmt.Sprintf("ldap://%s", listener.Addr().String()),
+		listener: listener,
+		conns:    map[net.Conn]struct{}{},
+	}
+
+	server.wg.Add(1)
+
+	go server.serve()
+
+	return server, nil
+}
+
+// AddEntry adds an entry to the directory or replaces the one with the same DN. The password of the entries binding to
+// the server is set with the userPassword attribute.
+func (s *LDAPServer) AddEntry(dn string, attributes map[string][]string) {
+	entry := &LDAPEntry{DN: dn, Attributes: map[string][]string{}}
+
+	for name, values := range attributes {
+		entry.Attributes[name] = append([]string(nil), values...)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i, e := range s.entries {
+		if strings.EqualFold(e.DN, dn) {
+			s.entries[i] = entry
+			return
+		}
+	}
+
+	s.entries = append(s.entries, entry)
+}
+
+// Entry returns a copy of the entry with the DN, e.g. to assert the password of a user has been changed.
+func (s *LDAPServer) Entry(dn string) (entry LDAPEntry, ok bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	e := s.entry(dn)
+	if e == nil {
+		return entry, false
+	}
+
+	entry = LDAPEntry{DN: e.DN, Attributes: map[string][]string{}}
+
+	for name, values := range e.Attributes {
+		entry.Attributes[name] = append([]string(nil), values...)
+	}
+
+	return entry, true
+}
+
+// Close stops the server and closes the connections.
+func (s *LDAPServer) Close() error {
+	err := s.listener.Close()
+
+	s.mutex.Lock()
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.mutex.Unlock()
+
+	s.wg.Wait()
+
+	return err
+}
+
+func (s *LDAPServer) serve() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mutex.Lock()
+		s.conns[conn] = struct{}{}
+		s.mutex.Unlock()
+
+		s.wg.Add(1)
+
+		go s.handle(conn)
+	}
+}
+
+func (s *LDAPServer) handle(conn net.Conn) {
+	defer s.wg.Done()
+
+	defer func() {
+		s.mutex.Lock()
+		delete(s.conns, conn)
+		s.mutex.Unlock()
+
+		_ = conn.Close()
+	}()
+
+	var boundDN string
+
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+
+		messageID, _ := packet.Children[0].Value.(int64)
+		request := packet.Children[1]
+
+		var responses []*ber.Packet
+
+		switch request.Tag {
+		case ldap.ApplicationBindRequest:
+			responses = []*ber.Packet{s.bind(messageID, request, &boundDN)}
+		case ldap.ApplicationSearchRequest:
+			responses = s.search(messageID, request)
+		case ldap.ApplicationModifyRequest:
+			responses = []*ber.Packet{s.modify(messageID, request)}
+		case ldap.ApplicationExtendedRequest:
+			responses = []*ber.Packet{s.extended(messageID, request, boundDN)}
+		case ldap.ApplicationUnbindRequest:
+			return
+		case ldap.ApplicationAbandonRequest:
+			continue
+		default:
+			responses = []*ber.Packet{newLDAPResponse(messageID, request.Tag+1, ldap.LDAPResultUnwillingToPerform, "operation not supported")}
+		}
+
+		for _, response := range responses {
+			if _, err = conn.Write(response.Bytes()); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *LDAPServer) bind(messageID int64, request *ber.Packet, boundDN *string) *ber.Packet {
+	if len(request.Children) < 3 {
+		return newLDAPResponse(messageID, ldap.ApplicationBindResponse, ldap.LDAPResultProtocolError, "malformed bind request")
+	}
+
+	dn, password := packetString(request.Children[1]), packetString(request.Children[2])
+
+	if dn == "" && password == "" {
+		*boundDN = ""
+
+		return newLDAPResponse(messageID, ldap.ApplicationBindResponse, ldap.LDAPResultSuccess, "")
+	}
+
+	s.mutex.RLock()
+	entry := s.entry(dn)
+	valid := entry != nil && password != "" && hasValue(entry.Attributes[attributeName(entry, ldapPasswordAttribute)], password, false)
+	s.mutex.RUnlock()
+
+	if !valid {
+		return newLDAPResponse(messageID, ldap.ApplicationBindResponse, ldap.LDAPResultInvalidCredentials, "invalid credentials")
+	}
+
+	*boundDN = dn
+
+	return newLDAPResponse(messageID, ldap.ApplicationBindResponse, ldap.LDAPResultSuccess, "")
+}
+
+func (s *LDAPServer) search(messageID int64, request *ber.Packet) (responses []*ber.Packet) {
+	if len(request.Children) < 8 {
+		return []*ber.Packet{newLDAPResponse(messageID, ldap.ApplicationSearchResultDone, ldap.LDAPResultProtocolError, "malformed search request")}
+	}
+
+	baseDN := packetString(request.Children[0])
+	scope, _ := request.Children[1].Value.(int64)
+	sizeLimit, _ := request.Children[3].Value.(int64)
+	filter := request.Children[6]
+
+	var attributes []string
+
+	for _, attribute := range request.Children[7].Children {
+		attributes = append(attributes, packetString(attribute))
+	}
+
+	if baseDN == "" && scope == ldap.ScopeBaseObject {
+		rootDSE := &LDAPEntry{Attributes: map[string][]string{
+			"objectClass":                   {"top"},
+			ldapSupportedExtensionAttribute: {ldapOIDPasswordModify},
+		}}
+
+		return []*ber.Packet{
+			newLDAPSearchResultEntry(messageID, rootDSE, attributes),
+			newLDAPResponse(messageID, ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess, ""),
+		}
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, entry := range s.entries {
+		if !inScope(entry.DN, baseDN, scope) || !matchFilter(entry, filter) {
+			continue
+		}
+
+		if sizeLimit > 0 && int64(len(responses)) == sizeLimit {
+			return append(responses, newLDAPResponse(messageID, ldap.ApplicationSearchResultDone, ldap.LDAPResultSizeLimitExceeded, ""))
+		}
+
+		responses = append(responses, newLDAPSearchResultEntry(messageID, entry, attributes))
+	}
+
+	return append(responses, newLDAPResponse(messageID, ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess, ""))
+}
+
+func (s *LDAPServer) modify(messageID int64, request *ber.Packet) *ber.Packet {
+	if len(request.Children) < 2 {
+		return newLDAPResponse(messageID, ldap.ApplicationModifyResponse, ldap.LDAPResultProtocolError, "malformed modify request")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entry := s.entry(packetString(request.Children[0]))
+	if entry == nil {
+		return newLDAPResponse(messageID, ldap.ApplicationModifyResponse, ldap.LDAPResultNoSuchObject, "no such object")
+	}
+
+	for _, change := range request.Children[1].Children {
+		if len(change.Children) < 2 || len(change.Children[1].Children) < 2 {
+			return newLDAPResponse(messageID, ldap.ApplicationModifyResponse, ldap.LDAPResultProtocolError, "malformed change")
+		}
+
+		operation, _ := change.Children[0].Value.(int64)
+		name := attributeName(entry, packetString(change.Children[1].Children[0]))
+
+		var values []string
+
+		for _, value := range change.Children[1].Children[1].Children {
+			values = append(values, packetString(value))
+		}
+
+		switch operation {
+		case ldap.AddAttribute:
+			entry.Attributes[name] = append(entry.Attributes[name], values...)
+		case ldap.DeleteAttribute:
+			entry.Attributes[name] = removeValues(entry.Attributes[name], values)
+		case ldap.ReplaceAttribute:
+			entry.Attributes[name] = values
+		}
+
+		if len(entry.Attributes[name]) == 0 {
+			delete(entry.Attributes, name)
+		}
+	}
+
+	return newLDAPResponse(messageID, ldap.ApplicationModifyResponse, ldap.LDAPResultSuccess, "")
+}
+
+func (s *LDAPServer) extended(messageID int64, request *ber.Packet, boundDN string) *ber.Packet {
+	if len(request.Children) == 0 || packetString(request.Children[0]) != ldapOIDPasswordModify {
+		return newLDAPResponse(messageID, ldap.ApplicationExtendedResponse, ldap.LDAPResultProtocolError, "extended operation not supported")
+	}
+
+	userDN, newPassword := boundDN, ""
+
+	if len(request.Children) > 1 {
+		value, err := ber.DecodePacketErr(request.Children[1].Data.Bytes())
+		if err != nil {
+			return newLDAPResponse(messageID, ldap.ApplicationExtendedResponse, ldap.LDAPResultProtocolError, "malformed password modify request")
+		}
+
+		for _, child := range value.Children {
+			switch child.Tag {
+			case 0:
+				userDN = packetString(child)
+			case 2:
+				newPassword = packetString(child)
+			}
+		}
+	}
+
+	if newPassword == "" {
+		return newLDAPResponse(messageID, ldap.ApplicationExtendedResponse, ldap.LDAPResultUnwillingToPerform, "the password can't be generated")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entry := s.entry(userDN)
+	if entry == nil {
+		return newLDAPResponse(messageID, ldap.ApplicationExtendedResponse, ldap.LDAPResultNoSuchObject, "no such object")
+	}
+
+	entry.Attributes[attributeName(entry, ldapPasswordAttribute)] = []string{newPassword}
+
+	return newLDAPResponse(messageID, ldap.ApplicationExtendedResponse, ldap.LDAPResultSuccess, "")
+}
+
+// entry returns the entry with the DN, the mutex must be held.
+func (s *LDAPServer) entry(dn string) *LDAPEntry {
+	for _, entry := range s.entries {
+		if strings.EqualFold(entry.DN, dn) {
+			return entry
+		}
+	}
+
+	return nil
+}
+
+func inScope(dn, baseDN string, scope int64) bool {
+	dn, baseDN = strings.ToLower(dn), strings.ToLower(baseDN)
+
+	switch scope {
+	case ldap.ScopeBaseObject:
+		return dn == baseDN
+	case ldap.ScopeSingleLevel:
+		parts := strings.SplitN(dn, ",", 2)
+		return len(parts) == 2 && parts[1] == baseDN
+	default:
+		return baseDN == "" || dn == baseDN || strings.HasSuffix(dn, ","+baseDN)
+	}
+}
+
+func matchFilter(entry *LDAPEntry, filter *ber.Packet) bool {
+	switch filter.Tag {
+	case ldap.FilterAnd:
+		for _, child := range filter.Children {
+			if !matchFilter(entry, child) {
+				return false
+			}
+		}
+
+		return true
+	case ldap.FilterOr:
+		for _, child := range filter.Children {
+			if matchFilter(entry, child) {
+				return true
+			}
+		}
+
+		return false
+	case ldap.FilterNot:
+		return len(filter.Children) == 1 && !matchFilter(entry, filter.Children[0])
+	case ldap.FilterEqualityMatch, ldap.FilterApproxMatch:
+		if len(filter.Children) != 2 {
+			return false
+		}
+
+		return hasValue(entry.Attributes[attributeName(entry, packetString(filter.Children[0]))], packetString(filter.Children[1]), true)
+	case ldap.FilterPresent:
+		return len(entry.Attributes[attributeName(entry, packetString(filter))]) != 0
+	case ldap.FilterSubstrings:
+		if len(filter.Children) != 2 {
+			return false
+		}
+
+		for _, value := range entry.Attributes[attributeName(entry, packetString(filter.Children[0]))] {
+			if matchSubstrings(strings.ToLower(value), filter.Children[1].Children) {
+				return true
+			}
+		}
+
+		return false
+	default:
+		return false
+	}
+}
+
+func matchSubstrings(value string, substrings []*ber.Packet) bool {
+	for _, substring := range substrings {
+		s := strings.ToLower(packetString(substring))
+
+		switch substring.Tag {
+		case ldap.FilterSubstringsInitial:
+			if !strings.HasPrefix(value, s) {
+				return false
+			}
+
+			value = value[len(s):]
+		case ldap.FilterSubstringsAny:
+			i := strings.Index(value, s)
+			if i < 0 {
+				return false
+			}
+
+			value = value[i+len(s):]
+		case ldap.FilterSubstringsFinal:
+			if !strings.HasSuffix(value, s) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// attributeName returns the name of the attribute of the entry matching the name case-insensitively, or the name
+// itself when the entry has no such attribute.
+func attributeName(entry *LDAPEntry, name string) string {
+	for attribute := range entry.Attributes {
+		if strings.EqualFold(attribute, name) {
+			return attribute
+		}
+	}
+
+	return name
+}
+
+func hasValue(values []string, value string, ignoreCase bool) bool {
+	for _, v := range values {
+		if v == value || (ignoreCase && strings.EqualFold(v, value)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func removeValues(values, removed []string) (remaining []string) {
+	if len(removed) == 0 {
+		return nil
+	}
+
+	for _, value := range values {
+		if !hasValue(removed, value, true) {
+			remaining = append(remaining, value)
+		}
+	}
+
+	return remaining
+}
+
+// packetString returns the content of a primitive packet, the content of the context specific packets isn't decoded
+// as a string by ber.
+func packetString(packet *ber.Packet) string {
+	return packet.Data.String()
+}
+
+func newLDAPEnvelope(messageID int64) *ber.Packet {
+	envelope := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	envelope.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "Message ID"))
+
+	return envelope
+}
+
+func newLDAPResponse(messageID int64, tag ber.Tag, resultCode uint16, message string) *ber.Packet {
+	envelope := newLDAPEnvelope(messageID)
+
+	response := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, ldap.ApplicationMap[uint8(tag)])
+	response.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(resultCode), "Result Code"))
+	response.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	response.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, message, "Diagnostic Message"))
+
+	envelope.AppendChild(response)
+
+	return envelope
+}
+
+// newLDAPSearchResultEntry returns the entry with the requested attributes, all of them but the password are returned
+// when none or the * wildcard is requested.
+func newLDAPSearchResultEntry(messageID int64, entry *LDAPEntry, requested []string) *ber.Packet {
+	envelope := newLDAPEnvelope(messageID)
+
+	response := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+	response.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.DN, "Object Name"))
+
+	attributes := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+
+	for name, values := range entry.Attributes {
+		if returned, ok := returnedAttributeName(name, requested); ok {
+			attributes.AppendChild(newLDAPAttribute(returned, values))
+		}
+	}
+
+	response.AppendChild(attributes)
+	envelope.AppendChild(response)
+
+	return envelope
+}
+
+// returnedAttributeName returns the name of the attribute as it was requested, the LDAP clients match the names of
+// the attributes returned case-sensitively.
+func returnedAttributeName(name string, requested []string) (string, bool) {
+	all := len(requested) == 0
+
+	for _, r := range requested {
+		switch {
+		case r == "*":
+			all = true
+		case strings.EqualFold(r, name):
+			return r, true
+		}
+	}
+
+	return name, all && !strings.EqualFold(name, ldapPasswordAttribute)
+}
+
+func newLDAPAttribute(name string, values []string) *ber.Packet {
+	attribute := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+	attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+
+	set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+
+	for _, value := range values {
+		set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+	}
+
+	attribute.AppendChild(set)
+
+	return attribute
+}
@@ -0,0 +1,116 @@
+package autheliatest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/configuration/schema"
+)
+
+func newTestLDAPServer(t *testing.T) *LDAPServer {
+	server, err := NewLDAPServer()
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, server.Close())
+	})
+
+	server.AddEntry("cn=admin,dc=example,dc=com", map[string][]string{
+		"objectClass":  {"person"},
+		"userPassword": {"password"},
+	})
+	server.AddEntry("uid=john,ou=users,dc=example,dc=com", map[string][]string{
+		"objectClass":  {"inetOrgPerson"},
+		"uid":          {"john"},
+		"mail":         {"john@example.com", "john.doe@example.com"},
+		"displayName":  {"John Doe"},
+		"userPassword": {"password"},
+	})
+	server.AddEntry("uid=harry,ou=users,dc=example,dc=com", map[string][]string{
+		"objectClass":  {"inetOrgPerson"},
+		"uid":          {"harry"},
+		"mail":         {"harry@example.com"},
+		"displayName":  {"Harry Potter"},
+		"userPassword": {"password"},
+	})
+	server.AddEntry("cn=admins,ou=groups,dc=example,dc=com", map[string][]string{
+		"objectClass": {"groupOfNames"},
+		"cn":          {"admins"},
+		"member":      {"uid=john,ou=users,dc=example,dc=com"},
+	})
+	server.AddEntry("cn=dev,ou=groups,dc=example,dc=com", map[string][]string{
+		"objectClass": {"groupOfNames"},
+		"cn":          {"dev"},
+		"member":      {"uid=john,ou=users,dc=example,dc=com", "uid=harry,ou=users,dc=example,dc=com"},
+	})
+
+	return server
+}
+
+func newTestLDAPUserProvider(t *testing.T, server *LDAPServer) *authentication.LDAPUserProvider {
+	provider, err := authentication.NewLDAPUserProvider(schema.AuthenticationBackendConfiguration{
+		LDAP: &schema.LDAPAuthenticationBackendConfiguration{
+			Implementation:       schema.LDAPImplementationCustom,
+			URL:                  server.URL,
+			BaseDN:               "dc=example,dc=com",
+			AdditionalUsersDN:    "ou=users",
+			UsersFilter:          "(&({username_attribute}={input})(objectClass=inetOrgPerson))",
+			AdditionalGroupsDN:   "ou=groups",
+			GroupsFilter:         "(&(member={dn})(objectClass=groupOfNames))",
+			GroupNameAttribute:   "cn",
+			UsernameAttribute:    "uid",
+			MailAttribute:        "mail",
+			DisplayNameAttribute: "displayName",
+			User:                 "cn=admin,dc=example,dc=com",
+			Password:             "password",
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	return provider
+}
+
+func TestShouldServeTheLDAPUserProvider(t *testing.T) {
+	server := newTestLDAPServer(t)
+	provider := newTestLDAPUserProvider(t, server)
+
+	valid, err := provider.CheckUserPassword("john", "password")
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	valid, err = provider.CheckUserPassword("john", "wrong")
+	assert.Error(t, err)
+	assert.False(t, valid)
+
+	details, err := provider.GetDetails("john")
+	require.NoError(t, err)
+	assert.Equal(t, "john", details.Username)
+	assert.Equal(t, "John Doe", details.DisplayName)
+	assert.Equal(t, []string{"john@example.com", "john.doe@example.com"}, details.Emails)
+	assert.ElementsMatch(t, []string{"admins", "dev"}, details.Groups)
+
+	details, err = provider.GetDetails("harry")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dev"}, details.Groups)
+
+	_, err = provider.GetDetails("bob")
+	assert.Error(t, err)
+}
+
+func TestShouldChangeThePasswordWithTheLDAPUserProvider(t *testing.T) {
+	server := newTestLDAPServer(t)
+	provider := newTestLDAPUserProvider(t, server)
+
+	require.NoError(t, provider.UpdatePassword("john", "new-password"))
+
+	entry, ok := server.Entry("uid=john,ou=users,dc=example,dc=com")
+	require.True(t, ok)
+	assert.Equal(t, []string{"new-password"}, entry.Attributes["userPassword"])
+
+	valid, err := provider.CheckUserPassword("john", "new-password")
+	require.NoError(t, err)
+	assert.True(t, valid)
+}
@@ -0,0 +1,1226 @@
+package autheliatest
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/storage"
+)
+
+// MemoryStorageProvider is an in-memory implementation of storage.Provider intended to be used by tests which need a
+// working storage provider rather than strict expectations.
+type MemoryStorageProvider struct {
+	mutex sync.RWMutex
+
+	preferences        map[string]string
+	identityTokens     map[string]time.Time
+	loginStates        map[string]time.Time
+	totpDevices        []totpDevice
+	u2fDeviceHandles   map[string]u2fDeviceHandle
+	webauthnCreds      []models.WebauthnCredential
+	authenticationLogs []models.AuthenticationAttempt
+	userEvents         []models.UserEvent
+	sessionRevocations map[string]time.Time
+	throttles          map[notificationThrottleKey]models.NotificationThrottle
+	acknowledgments    map[announcementAcknowledgmentKey]time.Time
+	backupCodes        map[string]map[string]time.Time
+	adminAPITokens     []models.AdminAPIToken
+	pushEnrollments    map[string]models.PushEnrollment
+	resets             []models.SecondFactorReset
+	accessGrants       []models.AccessGrant
+	activations        []models.BreakGlassActivation
+	oidcConsents       map[oidcConsentKey]models.OIDCConsent
+	oidcClients        []models.OIDCClient
+}
+
+type oidcConsentKey struct {
+	username string
+	clientID string
+}
+
+type notificationThrottleKey struct {
+	username string
+	kind     string
+}
+
+type announcementAcknowledgmentKey struct {
+	username       string
+	announcementID string
+}
+
+type totpDevice struct {
+	models.TOTPDevice
+
+	used         bool
+	lastUsedStep uint64
+}
+
+type u2fDeviceHandle struct {
+	keyHandle []byte
+	publicKey []byte
+}
+
+var _ storage.Provider = (*MemoryStorageProvider)(nil)
+
+// NewMemoryStorageProvider creates a new empty MemoryStorageProvider.
+func NewMemoryStorageProvider() *MemoryStorageProvider {
+	return &MemoryStorageProvider{
+		preferences:        map[string]string{},
+		identityTokens:     map[string]time.Time{},
+		loginStates:        map[string]time.Time{},
+		u2fDeviceHandles:   map[string]u2fDeviceHandle{},
+		sessionRevocations: map[string]time.Time{},
+		throttles:          map[notificationThrottleKey]models.NotificationThrottle{},
+		acknowledgments:    map[announcementAcknowledgmentKey]time.Time{},
+		backupCodes:        map[string]map[string]time.Time{},
+		pushEnrollments:    map[string]models.PushEnrollment{},
+		oidcConsents:       map[oidcConsentKey]models.OIDCConsent{},
+	}
+}
+
+// LoadPreferred2FAMethod load the preferred method for 2FA.
+func (p *MemoryStorageProvider) LoadPreferred2FAMethod(username string) (string, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.preferences[username], nil
+}
+
+// SavePreferred2FAMethod save the preferred method for 2FA.
+func (p *MemoryStorageProvider) SavePreferred2FAMethod(username string, method string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.preferences[username] = method
+
+	return nil
+}
+
+// DeletePreferred2FAMethod delete the preferred method for 2FA of a given user.
+func (p *MemoryStorageProvider) DeletePreferred2FAMethod(username string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	delete(p.preferences, username)
+
+	return nil
+}
+
+// FindIdentityVerificationToken look for an identity verification token.
+func (p *MemoryStorageProvider) FindIdentityVerificationToken(token string) (bool, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	_, ok := p.identityTokens[token]
+
+	return ok, nil
+}
+
+// SaveIdentityVerificationToken save an identity verification token along with its expiration time.
+func (p *MemoryStorageProvider) SaveIdentityVerificationToken(token string, expiresAt time.Time) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.identityTokens[token] = expiresAt
+
+	return nil
+}
+
+// RemoveIdentityVerificationToken remove an identity verification token.
+func (p *MemoryStorageProvider) RemoveIdentityVerificationToken(token string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	delete(p.identityTokens, token)
+
+	return nil
+}
+
+// SaveLoginState save the nonce of a login state along with its expiration time.
+func (p *MemoryStorageProvider) SaveLoginState(nonce string, expiresAt time.Time) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.loginStates[nonce] = expiresAt
+
+	return nil
+}
+
+// ConsumeLoginState delete the nonce of a login state, storage.ErrNoLoginState is returned when the nonce is unknown
+// or expired before the given time.
+func (p *MemoryStorageProvider) ConsumeLoginState(nonce string, now time.Time) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	expiresAt, ok := p.loginStates[nonce]
+	if !ok || expiresAt.Unix() < now.Unix() {
+		return storage.ErrNoLoginState
+	}
+
+	delete(p.loginStates, nonce)
+
+	return nil
+}
+
+// SaveTOTPDevice save a TOTP device registered by a user.
+func (p *MemoryStorageProvider) SaveTOTPDevice(device models.TOTPDevice) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.totpDevices = append(p.totpDevices, totpDevice{TOTPDevice: device})
+
+	return nil
+}
+
+// LoadTOTPDevices load all the TOTP devices of a given user.
+func (p *MemoryStorageProvider) LoadTOTPDevices(username string) ([]models.TOTPDevice, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	devices := make([]models.TOTPDevice, 0, 1)
+
+	for _, device := range p.totpDevices {
+		if device.Username == username {
+			devices = append(devices, device.TOTPDevice)
+		}
+	}
+
+	return devices, nil
+}
+
+// UpdateTOTPDeviceDescription update the description of a TOTP device of a given user.
+func (p *MemoryStorageProvider) UpdateTOTPDeviceDescription(username, deviceID, description string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for i := range p.totpDevices {
+		if p.totpDevices[i].Username == username && p.totpDevices[i].ID == deviceID {
+			p.totpDevices[i].Description = description
+		}
+	}
+
+	return nil
+}
+
+// DeleteTOTPDevice delete a TOTP device of a given user.
+func (p *MemoryStorageProvider) DeleteTOTPDevice(username, deviceID string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	devices := make([]totpDevice, 0, len(p.totpDevices))
+
+	for _, device := range p.totpDevices {
+		if device.Username != username || device.ID != deviceID {
+			devices = append(devices, device)
+		}
+	}
+
+	p.totpDevices = devices
+
+	return nil
+}
+
+// SaveTOTPDeviceLastUsed save the last TOTP time step used with a device of a given user if it's more recent than the
+// last one.
+func (p *MemoryStorageProvider) SaveTOTPDeviceLastUsed(username, deviceID string, step uint64, usedAt time.Time) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for i := range p.totpDevices {
+		device := &p.totpDevices[i]
+
+		if device.Username != username || device.ID != deviceID || (device.used && step <= device.lastUsedStep) {
+			continue
+		}
+
+		device.used = true
+		device.lastUsedStep = step
+		device.LastUsedAt = usedAt
+
+		return nil
+	}
+
+	return storage.ErrTOTPStepAlreadyUsed
+}
+
+// SaveU2FDeviceHandle save a registered U2F device registration blob.
+func (p *MemoryStorageProvider) SaveU2FDeviceHandle(username string, keyHandle []byte, publicKey []byte) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.u2fDeviceHandles[username] = u2fDeviceHandle{keyHandle: keyHandle, publicKey: publicKey}
+
+	return nil
+}
+
+// LoadU2FDeviceHandle load a U2F device registration blob for a given username.
+func (p *MemoryStorageProvider) LoadU2FDeviceHandle(username string) (keyHandle []byte, publicKey []byte, err error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	handle, ok := p.u2fDeviceHandles[username]
+	if !ok {
+		return nil, nil, storage.ErrNoU2FDeviceHandle
+	}
+
+	return handle.keyHandle, handle.publicKey, nil
+}
+
+// DeleteU2FDeviceHandle delete the U2F device of a given user.
+func (p *MemoryStorageProvider) DeleteU2FDeviceHandle(username string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if _, ok := p.u2fDeviceHandles[username]; !ok {
+		return storage.ErrNoU2FDeviceHandle
+	}
+
+	delete(p.u2fDeviceHandles, username)
+
+	return nil
+}
+
+// SaveWebauthnCredential save a registered WebAuthn credential.
+func (p *MemoryStorageProvider) SaveWebauthnCredential(credential models.WebauthnCredential) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.webauthnCreds = append(p.webauthnCreds, credential)
+
+	return nil
+}
+
+// LoadWebauthnCredential load a WebAuthn credential given its ID.
+func (p *MemoryStorageProvider) LoadWebauthnCredential(credentialID []byte) (*models.WebauthnCredential, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	for _, credential := range p.webauthnCreds {
+		if bytes.Equal(credential.ID, credentialID) {
+			return &credential, nil
+		}
+	}
+
+	return nil, storage.ErrNoWebauthnCredential
+}
+
+// LoadWebauthnCredentials load all the WebAuthn credentials of a given user.
+func (p *MemoryStorageProvider) LoadWebauthnCredentials(username string) ([]models.WebauthnCredential, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	credentials := make([]models.WebauthnCredential, 0, 1)
+
+	for _, credential := range p.webauthnCreds {
+		if credential.Username == username {
+			credentials = append(credentials, credential)
+		}
+	}
+
+	return credentials, nil
+}
+
+// UpdateWebauthnCredentialSignCount update the signature counter of a WebAuthn credential.
+func (p *MemoryStorageProvider) UpdateWebauthnCredentialSignCount(credentialID []byte, signCount uint32) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for i := range p.webauthnCreds {
+		if bytes.Equal(p.webauthnCreds[i].ID, credentialID) {
+			p.webauthnCreds[i].SignCount = signCount
+		}
+	}
+
+	return nil
+}
+
+// DeleteWebauthnCredential delete a WebAuthn credential of a given user.
+func (p *MemoryStorageProvider) DeleteWebauthnCredential(username string, credentialID []byte) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for i, credential := range p.webauthnCreds {
+		if credential.Username == username && bytes.Equal(credential.ID, credentialID) {
+			p.webauthnCreds = append(p.webauthnCreds[:i], p.webauthnCreds[i+1:]...)
+			return nil
+		}
+	}
+
+	return storage.ErrNoWebauthnCredential
+}
+
+// AppendAuthenticationLog append a mark to the authentication log.
+func (p *MemoryStorageProvider) AppendAuthenticationLog(attempt models.AuthenticationAttempt) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.authenticationLogs = append(p.authenticationLogs, attempt)
+
+	return nil
+}
+
+// LoadLatestAuthenticationLogs retrieve the marks of a user from the authentication log after the provided date, the
+// most recent mark first.
+func (p *MemoryStorageProvider) LoadLatestAuthenticationLogs(username string, fromDate time.Time) ([]models.AuthenticationAttempt, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	attempts := make([]models.AuthenticationAttempt, 0, 10)
+
+	for _, attempt := range p.authenticationLogs {
+		if attempt.Username == username && attempt.Time.Unix() > fromDate.Unix() {
+			attempts = append(attempts, attempt)
+		}
+	}
+
+	sort.SliceStable(attempts, func(i, j int) bool {
+		return attempts[i].Time.After(attempts[j].Time)
+	})
+
+	return attempts, nil
+}
+
+// DeleteFailedAuthenticationLogs delete the failed attempts of a given user from the authentication log.
+func (p *MemoryStorageProvider) DeleteFailedAuthenticationLogs(username string) (int64, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var deleted int64
+
+	attempts := make([]models.AuthenticationAttempt, 0, len(p.authenticationLogs))
+
+	for _, attempt := range p.authenticationLogs {
+		if attempt.Username == username && !attempt.Successful {
+			deleted++
+			continue
+		}
+
+		attempts = append(attempts, attempt)
+	}
+
+	p.authenticationLogs = attempts
+
+	return deleted, nil
+}
+
+// LoadLastLogin load the time of the latest successful authentication of a user, the zero time is returned when the
+// user has never logged in.
+func (p *MemoryStorageProvider) LoadLastLogin(username string) (time.Time, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	var lastLogin time.Time
+
+	for _, attempt := range p.authenticationLogs {
+		if attempt.Username == username && attempt.Successful && attempt.Time.After(lastLogin) {
+			lastLogin = attempt.Time
+		}
+	}
+
+	return lastLogin, nil
+}
+
+// AppendUserEvent append an event to the events of a user.
+func (p *MemoryStorageProvider) AppendUserEvent(event models.UserEvent) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.userEvents = append(p.userEvents, event)
+
+	return nil
+}
+
+// LoadUserEvents retrieve the events of a user including the authentication attempts, the most recent event first.
+func (p *MemoryStorageProvider) LoadUserEvents(username, eventType string, limit, offset int) ([]models.UserEvent, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	events := make([]models.UserEvent, 0, len(p.authenticationLogs)+len(p.userEvents))
+
+	for _, attempt := range p.authenticationLogs {
+		event := models.UserEvent{
+			Username:   attempt.Username,
+			Type:       attempt.Type,
+			Successful: attempt.Successful,
+			Time:       attempt.Time,
+			RemoteIP:   attempt.RemoteIP,
+			UserAgent:  attempt.UserAgent,
+		}
+
+		if event.Type == "" {
+			event.Type = models.AuthenticationTypeFirstFactor
+		}
+
+		events = append(events, event)
+	}
+
+	events = append(events, p.userEvents...)
+
+	filtered := make([]models.UserEvent, 0, len(events))
+
+	for _, event := range events {
+		if event.Username == username && (eventType == "" || event.Type == eventType) {
+			filtered = append(filtered, event)
+		}
+	}
+
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return filtered[i].Time.After(filtered[j].Time)
+	})
+
+	if offset >= len(filtered) {
+		return []models.UserEvent{}, nil
+	}
+
+	filtered = filtered[offset:]
+
+	if len(filtered) > limit {
+		filtered = filtered[:limit]
+	}
+
+	return filtered, nil
+}
+
+// LoadNotificationThrottle load the throttling state of a kind of notification sent to a user.
+func (p *MemoryStorageProvider) LoadNotificationThrottle(username, kind string) (models.NotificationThrottle, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if throttle, ok := p.throttles[notificationThrottleKey{username, kind}]; ok {
+		return throttle, nil
+	}
+
+	return models.NotificationThrottle{Username: username, Kind: kind}, nil
+}
+
+// SaveNotificationThrottle save the throttling state of a kind of notification sent to a user.
+func (p *MemoryStorageProvider) SaveNotificationThrottle(throttle models.NotificationThrottle) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.throttles[notificationThrottleKey{throttle.Username, throttle.Kind}] = throttle
+
+	return nil
+}
+
+// LoadPendingNotificationThrottles load the throttling states having pending notifications.
+func (p *MemoryStorageProvider) LoadPendingNotificationThrottles() ([]models.NotificationThrottle, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	var throttles []models.NotificationThrottle
+
+	for _, throttle := range p.throttles {
+		if throttle.Pending > 0 {
+			throttles = append(throttles, throttle)
+		}
+	}
+
+	return throttles, nil
+}
+
+// SaveAnnouncementAcknowledgment save the time a user acknowledged an announcement.
+func (p *MemoryStorageProvider) SaveAnnouncementAcknowledgment(username, announcementID string, acknowledgedAt time.Time) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.acknowledgments[announcementAcknowledgmentKey{username: username, announcementID: announcementID}] = acknowledgedAt
+
+	return nil
+}
+
+// LoadAnnouncementAcknowledgment load the time a user acknowledged an announcement, the zero time is returned when the
+// user hasn't acknowledged it.
+func (p *MemoryStorageProvider) LoadAnnouncementAcknowledgment(username, announcementID string) (time.Time, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.acknowledgments[announcementAcknowledgmentKey{username: username, announcementID: announcementID}], nil
+}
+
+// SaveBackupCodes replace the backup codes of a user with the given hashes of new codes.
+func (p *MemoryStorageProvider) SaveBackupCodes(username string, codeHashes []string, createdAt time.Time) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	codes := make(map[string]time.Time, len(codeHashes))
+
+	for _, codeHash := range codeHashes {
+		codes[codeHash] = createdAt
+	}
+
+	p.backupCodes[username] = codes
+
+	return nil
+}
+
+// ConsumeBackupCode delete the backup code of a user with the given hash so it can't be used again.
+func (p *MemoryStorageProvider) ConsumeBackupCode(username, codeHash string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if _, ok := p.backupCodes[username][codeHash]; !ok {
+		return storage.ErrNoBackupCode
+	}
+
+	delete(p.backupCodes[username], codeHash)
+
+	return nil
+}
+
+// LoadBackupCodeHashes load the hashes of the backup codes of a user which haven't been used yet.
+func (p *MemoryStorageProvider) LoadBackupCodeHashes(username string) ([]string, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	codeHashes := make([]string, 0, len(p.backupCodes[username]))
+
+	for codeHash := range p.backupCodes[username] {
+		codeHashes = append(codeHashes, codeHash)
+	}
+
+	sort.Strings(codeHashes)
+
+	return codeHashes, nil
+}
+
+// CountBackupCodes count the backup codes of a user which haven't been used yet.
+func (p *MemoryStorageProvider) CountBackupCodes(username string) (int, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return len(p.backupCodes[username]), nil
+}
+
+// SaveAdminAPIToken save a new admin API token.
+func (p *MemoryStorageProvider) SaveAdminAPIToken(token models.AdminAPIToken) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, t := range p.adminAPITokens {
+		if t.ID == token.ID || t.TokenHash == token.TokenHash {
+			return fmt.Errorf("admin API token %s already exists", token.ID)
+		}
+	}
+
+	p.adminAPITokens = append(p.adminAPITokens, token)
+
+	return nil
+}
+
+// LoadAdminAPIToken load the admin API token with the given hash.
+func (p *MemoryStorageProvider) LoadAdminAPIToken(tokenHash string) (*models.AdminAPIToken, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	for _, token := range p.adminAPITokens {
+		if token.TokenHash == tokenHash {
+			return &token, nil
+		}
+	}
+
+	return nil, storage.ErrNoAdminAPIToken
+}
+
+// SavePushEnrollment save the enrollment state of a user with the push notification provider.
+func (p *MemoryStorageProvider) SavePushEnrollment(enrollment models.PushEnrollment) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.pushEnrollments[enrollment.Username] = enrollment
+
+	return nil
+}
+
+// LoadPushEnrollment load the enrollment state of a user with the push notification provider.
+func (p *MemoryStorageProvider) LoadPushEnrollment(username string) (*models.PushEnrollment, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	enrollment, ok := p.pushEnrollments[username]
+	if !ok {
+		return nil, storage.ErrNoPushEnrollment
+	}
+
+	return &enrollment, nil
+}
+
+// LoadAdminAPITokens load all the admin API tokens, oldest first.
+func (p *MemoryStorageProvider) LoadAdminAPITokens() ([]models.AdminAPIToken, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return append([]models.AdminAPIToken{}, p.adminAPITokens...), nil
+}
+
+// UpdateAdminAPITokenLastUsed update the time an admin API token was last used.
+func (p *MemoryStorageProvider) UpdateAdminAPITokenLastUsed(id string, lastUsedAt time.Time) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for i := range p.adminAPITokens {
+		if p.adminAPITokens[i].ID == id {
+			p.adminAPITokens[i].LastUsedAt = lastUsedAt
+		}
+	}
+
+	return nil
+}
+
+// DeleteAdminAPIToken delete the admin API token with the given ID.
+func (p *MemoryStorageProvider) DeleteAdminAPIToken(id string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for i, token := range p.adminAPITokens {
+		if token.ID == id {
+			p.adminAPITokens = append(p.adminAPITokens[:i], p.adminAPITokens[i+1:]...)
+			return nil
+		}
+	}
+
+	return storage.ErrNoAdminAPIToken
+}
+
+// SaveSecondFactorReset save a new pending second factor reset.
+func (p *MemoryStorageProvider) SaveSecondFactorReset(reset models.SecondFactorReset) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	reset.Status = models.SecondFactorResetPending
+	p.resets = append(p.resets, reset)
+
+	return nil
+}
+
+// LoadSecondFactorReset load the second factor reset with the given ID.
+func (p *MemoryStorageProvider) LoadSecondFactorReset(id string) (*models.SecondFactorReset, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	for _, reset := range p.resets {
+		if reset.ID == id {
+			return &reset, nil
+		}
+	}
+
+	return nil, storage.ErrNoSecondFactorReset
+}
+
+// LoadPendingSecondFactorResets load the second factor resets which can still be approved at the given time, the
+// oldest first.
+func (p *MemoryStorageProvider) LoadPendingSecondFactorResets(now time.Time) ([]models.SecondFactorReset, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	resets := make([]models.SecondFactorReset, 0, len(p.resets))
+
+	for _, reset := range p.resets {
+		if reset.IsPending(now) {
+			resets = append(resets, reset)
+		}
+	}
+
+	return resets, nil
+}
+
+// ApproveSecondFactorReset approve a pending second factor reset of a user and delete the second factor devices of the
+// user.
+func (p *MemoryStorageProvider) ApproveSecondFactorReset(id, username, approvedBy string, approvedAt time.Time) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if err := p.decideSecondFactorReset(id, username, models.SecondFactorResetApproved, approvedBy, approvedAt); err != nil {
+		return err
+	}
+
+	p.deleteSecondFactorData(username)
+
+	return nil
+}
+
+// RejectSecondFactorReset reject a pending second factor reset of a user.
+func (p *MemoryStorageProvider) RejectSecondFactorReset(id, username, rejectedBy string, rejectedAt time.Time) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.decideSecondFactorReset(id, username, models.SecondFactorResetRejected, rejectedBy, rejectedAt)
+}
+
+func (p *MemoryStorageProvider) decideSecondFactorReset(id, username, status, decidedBy string, decidedAt time.Time) error {
+	for i, reset := range p.resets {
+		if reset.ID == id && reset.Username == username && reset.IsPending(decidedAt) {
+			p.resets[i].Status, p.resets[i].DecidedBy, p.resets[i].DecidedAt = status, decidedBy, decidedAt
+			return nil
+		}
+	}
+
+	return storage.ErrNoSecondFactorReset
+}
+
+// SaveAccessGrant save a new access grant.
+func (p *MemoryStorageProvider) SaveAccessGrant(grant models.AccessGrant) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.accessGrants = append(p.accessGrants, grant)
+
+	return nil
+}
+
+// LoadAccessGrant load the access grant with the given ID.
+func (p *MemoryStorageProvider) LoadAccessGrant(id string) (*models.AccessGrant, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	for _, grant := range p.accessGrants {
+		if grant.ID == id {
+			return &grant, nil
+		}
+	}
+
+	return nil, storage.ErrNoAccessGrant
+}
+
+// LoadAccessGrants load the access grants which haven't expired at the given time, the oldest first.
+func (p *MemoryStorageProvider) LoadAccessGrants(now time.Time) ([]models.AccessGrant, error) {
+	return p.LoadUserAccessGrants("", now)
+}
+
+// LoadUserAccessGrants load the access grants created by a user which haven't expired at the given time, the oldest
+// first. The grants of all the users are loaded when the username is empty.
+func (p *MemoryStorageProvider) LoadUserAccessGrants(username string, now time.Time) ([]models.AccessGrant, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	grants := make([]models.AccessGrant, 0, len(p.accessGrants))
+
+	for _, grant := range p.accessGrants {
+		if (username == "" || grant.Username == username) && grant.ExpiresAt.After(now) {
+			grants = append(grants, grant)
+		}
+	}
+
+	return grants, nil
+}
+
+// DeleteAccessGrant delete the access grant with the given ID.
+func (p *MemoryStorageProvider) DeleteAccessGrant(id string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for i, grant := range p.accessGrants {
+		if grant.ID == id {
+			p.accessGrants = append(p.accessGrants[:i], p.accessGrants[i+1:]...)
+			return nil
+		}
+	}
+
+	return storage.ErrNoAccessGrant
+}
+
+// SaveBreakGlassActivation save the first use of a break-glass account.
+func (p *MemoryStorageProvider) SaveBreakGlassActivation(activation models.BreakGlassActivation) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, a := range p.activations {
+		if a.Username == activation.Username {
+			return fmt.Errorf("the break-glass account %s has already been activated", activation.Username)
+		}
+	}
+
+	p.activations = append(p.activations, activation)
+
+	return nil
+}
+
+// LoadBreakGlassActivation load the first use of a break-glass account.
+func (p *MemoryStorageProvider) LoadBreakGlassActivation(username string) (*models.BreakGlassActivation, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	for _, activation := range p.activations {
+		if activation.Username == username {
+			return &activation, nil
+		}
+	}
+
+	return nil, storage.ErrNoBreakGlassActivation
+}
+
+// LoadBreakGlassActivations load the first use of all the break-glass accounts, the oldest first.
+func (p *MemoryStorageProvider) LoadBreakGlassActivations() ([]models.BreakGlassActivation, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return append([]models.BreakGlassActivation{}, p.activations...), nil
+}
+
+// DeleteBreakGlassActivation delete the first use of a break-glass account.
+func (p *MemoryStorageProvider) DeleteBreakGlassActivation(username string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for i, activation := range p.activations {
+		if activation.Username == username {
+			p.activations = append(p.activations[:i], p.activations[i+1:]...)
+			return nil
+		}
+	}
+
+	return storage.ErrNoBreakGlassActivation
+}
+
+// SaveOIDCConsent save the consent of a user to a client, it replaces the consent previously remembered for the client.
+func (p *MemoryStorageProvider) SaveOIDCConsent(consent models.OIDCConsent) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.oidcConsents[oidcConsentKey{consent.Username, consent.ClientID}] = consent
+
+	return nil
+}
+
+// LoadOIDCConsent load the consent of a user to a client which hasn't expired at the given time.
+func (p *MemoryStorageProvider) LoadOIDCConsent(username, clientID string, now time.Time) (*models.OIDCConsent, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	consent, ok := p.oidcConsents[oidcConsentKey{username, clientID}]
+	if !ok || !consent.ExpiresAt.After(now) {
+		return nil, storage.ErrNoOIDCConsent
+	}
+
+	return &consent, nil
+}
+
+// LoadUserOIDCConsents load the consents of a user which haven't expired at the given time, the oldest first.
+func (p *MemoryStorageProvider) LoadUserOIDCConsents(username string, now time.Time) ([]models.OIDCConsent, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	consents := make([]models.OIDCConsent, 0, len(p.oidcConsents))
+
+	for key, consent := range p.oidcConsents {
+		if key.username == username && consent.ExpiresAt.After(now) {
+			consents = append(consents, consent)
+		}
+	}
+
+	sort.Slice(consents, func(i, j int) bool {
+		return consents[i].CreatedAt.Before(consents[j].CreatedAt)
+	})
+
+	return consents, nil
+}
+
+// DeleteOIDCConsent delete the consent of a user to a client.
+func (p *MemoryStorageProvider) DeleteOIDCConsent(username, clientID string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	key := oidcConsentKey{username, clientID}
+
+	if _, ok := p.oidcConsents[key]; !ok {
+		return storage.ErrNoOIDCConsent
+	}
+
+	delete(p.oidcConsents, key)
+
+	return nil
+}
+
+// SaveOIDCClient save a registered OpenID Connect client.
+func (p *MemoryStorageProvider) SaveOIDCClient(client models.OIDCClient) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.oidcClients = append(p.oidcClients, client)
+
+	return nil
+}
+
+// LoadOIDCClients load the registered OpenID Connect clients, the oldest first.
+func (p *MemoryStorageProvider) LoadOIDCClients() ([]models.OIDCClient, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return append([]models.OIDCClient{}, p.oidcClients...), nil
+}
+
+// DeleteOIDCClient delete a registered OpenID Connect client.
+func (p *MemoryStorageProvider) DeleteOIDCClient(id string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for i, client := range p.oidcClients {
+		if client.ID == id {
+			p.oidcClients = append(p.oidcClients[:i], p.oidcClients[i+1:]...)
+			return nil
+		}
+	}
+
+	return storage.ErrNoOIDCClient
+}
+
+// deleteSecondFactorData deletes the second factor devices of a user, the caller holds the lock.
+func (p *MemoryStorageProvider) deleteSecondFactorData(username string) {
+	delete(p.u2fDeviceHandles, username)
+	delete(p.backupCodes, username)
+	delete(p.pushEnrollments, username)
+
+	devices := make([]totpDevice, 0, len(p.totpDevices))
+
+	for _, device := range p.totpDevices {
+		if device.Username != username {
+			devices = append(devices, device)
+		}
+	}
+
+	credentials := make([]models.WebauthnCredential, 0, len(p.webauthnCreds))
+
+	for _, credential := range p.webauthnCreds {
+		if credential.Username != username {
+			credentials = append(credentials, credential)
+		}
+	}
+
+	p.totpDevices, p.webauthnCreds = devices, credentials
+}
+
+// LoadUsernames load the usernames of the users who logged in, set a preference or registered a second factor device
+// in alphabetical order.
+func (p *MemoryStorageProvider) LoadUsernames() ([]string, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	known := map[string]bool{}
+
+	for _, attempt := range p.authenticationLogs {
+		known[attempt.Username] = true
+	}
+
+	for username := range p.preferences {
+		known[username] = true
+	}
+
+	for _, device := range p.totpDevices {
+		known[device.Username] = true
+	}
+
+	for username := range p.u2fDeviceHandles {
+		known[username] = true
+	}
+
+	for _, credential := range p.webauthnCreds {
+		known[credential.Username] = true
+	}
+
+	for _, event := range p.userEvents {
+		known[event.Username] = true
+	}
+
+	usernames := make([]string, 0, len(known))
+
+	for username := range known {
+		usernames = append(usernames, username)
+	}
+
+	sort.Strings(usernames)
+
+	return usernames, nil
+}
+
+// PurgeUser delete all the data of a user and revoke the sessions of the user authenticated before the given time.
+func (p *MemoryStorageProvider) PurgeUser(username string, revokedAt time.Time) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	delete(p.preferences, username)
+	p.deleteSecondFactorData(username)
+
+	attempts := make([]models.AuthenticationAttempt, 0, len(p.authenticationLogs))
+
+	for _, attempt := range p.authenticationLogs {
+		if attempt.Username != username {
+			attempts = append(attempts, attempt)
+		}
+	}
+
+	events := make([]models.UserEvent, 0, len(p.userEvents))
+
+	for _, event := range p.userEvents {
+		if event.Username != username {
+			events = append(events, event)
+		}
+	}
+
+	for key := range p.throttles {
+		if key.username == username {
+			delete(p.throttles, key)
+		}
+	}
+
+	for key := range p.acknowledgments {
+		if key.username == username {
+			delete(p.acknowledgments, key)
+		}
+	}
+
+	for key := range p.oidcConsents {
+		if key.username == username {
+			delete(p.oidcConsents, key)
+		}
+	}
+
+	resets := make([]models.SecondFactorReset, 0, len(p.resets))
+
+	for _, reset := range p.resets {
+		if reset.Username != username {
+			resets = append(resets, reset)
+		}
+	}
+
+	grants := make([]models.AccessGrant, 0, len(p.accessGrants))
+
+	for _, grant := range p.accessGrants {
+		if grant.Username != username {
+			grants = append(grants, grant)
+		}
+	}
+
+	p.authenticationLogs, p.userEvents, p.resets, p.accessGrants = attempts, events, resets, grants
+	p.sessionRevocations[username] = revokedAt
+
+	return nil
+}
+
+// RevokeSessions revoke the sessions of a user authenticated before the given time.
+func (p *MemoryStorageProvider) RevokeSessions(username string, revokedAt time.Time) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.sessionRevocations[username] = revokedAt
+
+	return nil
+}
+
+// LoadSessionsRevocation load the time the sessions of a user have been revoked at, the zero time is returned when
+// they have never been revoked.
+func (p *MemoryStorageProvider) LoadSessionsRevocation(username string) (time.Time, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.sessionRevocations[username], nil
+}
+
+// PruneIdentityVerificationTokens delete the identity verification tokens which expired before the given time.
+func (p *MemoryStorageProvider) PruneIdentityVerificationTokens(now time.Time) (int64, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var pruned int64
+
+	for token, expiresAt := range p.identityTokens {
+		if expiresAt.Unix() < now.Unix() {
+			delete(p.identityTokens, token)
+			pruned++
+		}
+	}
+
+	return pruned, nil
+}
+
+// PruneAuthenticationLogs delete the marks of the authentication log older than the given time.
+func (p *MemoryStorageProvider) PruneAuthenticationLogs(before time.Time) (int64, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	attempts := make([]models.AuthenticationAttempt, 0, len(p.authenticationLogs))
+
+	for _, attempt := range p.authenticationLogs {
+		if attempt.Time.Unix() >= before.Unix() {
+			attempts = append(attempts, attempt)
+		}
+	}
+
+	pruned := int64(len(p.authenticationLogs) - len(attempts))
+	p.authenticationLogs = attempts
+
+	return pruned, nil
+}
+
+// PruneSessionRevocations delete the session revocations which happened before the given time.
+func (p *MemoryStorageProvider) PruneSessionRevocations(before time.Time) (int64, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var pruned int64
+
+	for username, revokedAt := range p.sessionRevocations {
+		if revokedAt.Unix() < before.Unix() {
+			delete(p.sessionRevocations, username)
+			pruned++
+		}
+	}
+
+	return pruned, nil
+}
+
+// PruneLoginStates delete the nonces of the login states which expired before the given time.
+func (p *MemoryStorageProvider) PruneLoginStates(now time.Time) (int64, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var pruned int64
+
+	for nonce, expiresAt := range p.loginStates {
+		if expiresAt.Unix() < now.Unix() {
+			delete(p.loginStates, nonce)
+			pruned++
+		}
+	}
+
+	return pruned, nil
+}
+
+// PruneAccessGrants delete the access grants which expired before the given time.
+func (p *MemoryStorageProvider) PruneAccessGrants(now time.Time) (int64, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	grants := make([]models.AccessGrant, 0, len(p.accessGrants))
+
+	for _, grant := range p.accessGrants {
+		if grant.ExpiresAt.Unix() >= now.Unix() {
+			grants = append(grants, grant)
+		}
+	}
+
+	pruned := int64(len(p.accessGrants) - len(grants))
+	p.accessGrants = grants
+
+	return pruned, nil
+}
+
+// PruneOIDCConsents delete the OpenID Connect consents which expired before the given time.
+func (p *MemoryStorageProvider) PruneOIDCConsents(now time.Time) (int64, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var pruned int64
+
+	for key, consent := range p.oidcConsents {
+		if consent.ExpiresAt.Unix() < now.Unix() {
+			delete(p.oidcConsents, key)
+			pruned++
+		}
+	}
+
+	return pruned, nil
+}
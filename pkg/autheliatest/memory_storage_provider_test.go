@@ -0,0 +1,151 @@
+package autheliatest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/storage"
+)
+
+func TestShouldStoreTOTPDevicesInMemory(t *testing.T) {
+	provider := NewMemoryStorageProvider()
+
+	devices, err := provider.LoadTOTPDevices("john")
+	require.NoError(t, err)
+	assert.Len(t, devices, 0)
+
+	require.NoError(t, provider.SaveTOTPDevice(models.TOTPDevice{ID: "phone", Username: "john", Description: "Phone", Secret: "secret"}))
+	require.NoError(t, provider.SaveTOTPDevice(models.TOTPDevice{ID: "tablet", Username: "john", Description: "Tablet", Secret: "other"}))
+	require.NoError(t, provider.UpdateTOTPDeviceDescription("john", "phone", "Work phone"))
+
+	devices, err = provider.LoadTOTPDevices("john")
+	require.NoError(t, err)
+	require.Len(t, devices, 2)
+	assert.Equal(t, "Work phone", devices[0].Description)
+	assert.Equal(t, "tablet", devices[1].ID)
+
+	require.NoError(t, provider.DeleteTOTPDevice("john", "phone"))
+
+	devices, err = provider.LoadTOTPDevices("john")
+	require.NoError(t, err)
+	require.Len(t, devices, 1)
+	assert.Equal(t, "tablet", devices[0].ID)
+}
+
+func TestShouldOnlySaveMoreRecentTOTPStepsInMemory(t *testing.T) {
+	provider := NewMemoryStorageProvider()
+	now := time.Unix(1000, 0)
+
+	assert.Equal(t, storage.ErrTOTPStepAlreadyUsed, provider.SaveTOTPDeviceLastUsed("john", "phone", 10, now))
+
+	require.NoError(t, provider.SaveTOTPDevice(models.TOTPDevice{ID: "phone", Username: "john", Secret: "secret"}))
+	require.NoError(t, provider.SaveTOTPDevice(models.TOTPDevice{ID: "tablet", Username: "john", Secret: "other"}))
+	require.NoError(t, provider.SaveTOTPDeviceLastUsed("john", "phone", 10, now))
+
+	assert.Equal(t, storage.ErrTOTPStepAlreadyUsed, provider.SaveTOTPDeviceLastUsed("john", "phone", 10, now))
+	assert.Equal(t, storage.ErrTOTPStepAlreadyUsed, provider.SaveTOTPDeviceLastUsed("john", "phone", 9, now))
+	assert.NoError(t, provider.SaveTOTPDeviceLastUsed("john", "phone", 11, now.Add(time.Minute)))
+
+	// The steps are tracked per device.
+	assert.NoError(t, provider.SaveTOTPDeviceLastUsed("john", "tablet", 11, now))
+
+	devices, err := provider.LoadTOTPDevices("john")
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(time.Minute), devices[0].LastUsedAt)
+}
+
+func TestShouldLoadLatestAuthenticationLogsInMemory(t *testing.T) {
+	provider := NewMemoryStorageProvider()
+	now := time.Unix(1000, 0)
+
+	require.NoError(t, provider.AppendAuthenticationLog(models.AuthenticationAttempt{Username: "john", Time: now.Add(-time.Minute)}))
+	require.NoError(t, provider.AppendAuthenticationLog(models.AuthenticationAttempt{Username: "john", Time: now.Add(-time.Second), Successful: true}))
+	require.NoError(t, provider.AppendAuthenticationLog(models.AuthenticationAttempt{Username: "harry", Time: now}))
+	require.NoError(t, provider.AppendAuthenticationLog(models.AuthenticationAttempt{Username: "john", Time: now.Add(-time.Hour)}))
+
+	attempts, err := provider.LoadLatestAuthenticationLogs("john", now.Add(-time.Minute*2))
+	require.NoError(t, err)
+	require.Len(t, attempts, 2)
+	assert.True(t, attempts[0].Successful)
+	assert.Equal(t, now.Add(-time.Minute), attempts[1].Time)
+}
+
+func TestShouldLoadUserEventsInMemory(t *testing.T) {
+	provider := NewMemoryStorageProvider()
+	now := time.Unix(1000, 0)
+
+	require.NoError(t, provider.AppendAuthenticationLog(models.AuthenticationAttempt{Username: "john", Time: now.Add(-time.Minute)}))
+	require.NoError(t, provider.AppendAuthenticationLog(models.AuthenticationAttempt{Username: "john", Time: now.Add(-time.Second), Successful: true, Type: models.AuthenticationTypeTOTP}))
+	require.NoError(t, provider.AppendAuthenticationLog(models.AuthenticationAttempt{Username: "harry", Time: now}))
+	require.NoError(t, provider.AppendUserEvent(models.UserEvent{Username: "john", Type: models.UserEventPasswordReset, Time: now.Add(-time.Hour), Successful: true}))
+
+	events, err := provider.LoadUserEvents("john", "", 2, 0)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, models.AuthenticationTypeTOTP, events[0].Type)
+	assert.Equal(t, models.AuthenticationTypeFirstFactor, events[1].Type)
+
+	events, err = provider.LoadUserEvents("john", "", 2, 2)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, models.UserEventPasswordReset, events[0].Type)
+
+	events, err = provider.LoadUserEvents("john", models.UserEventPasswordReset, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+
+	events, err = provider.LoadUserEvents("john", "", 10, 5)
+	require.NoError(t, err)
+	assert.Len(t, events, 0)
+}
+
+func TestShouldPurgeUserInMemory(t *testing.T) {
+	provider := NewMemoryStorageProvider()
+	now := time.Unix(1000, 0)
+
+	require.NoError(t, provider.SavePreferred2FAMethod("john", "totp"))
+	require.NoError(t, provider.SaveTOTPDevice(models.TOTPDevice{ID: "phone", Username: "john", Secret: "secret"}))
+	require.NoError(t, provider.SaveU2FDeviceHandle("john", []byte("handle"), []byte("key")))
+	require.NoError(t, provider.SaveWebauthnCredential(models.WebauthnCredential{ID: []byte("john"), Username: "john"}))
+	require.NoError(t, provider.SaveWebauthnCredential(models.WebauthnCredential{ID: []byte("harry"), Username: "harry"}))
+	require.NoError(t, provider.AppendAuthenticationLog(models.AuthenticationAttempt{Username: "john", Time: now}))
+	require.NoError(t, provider.AppendAuthenticationLog(models.AuthenticationAttempt{Username: "harry", Time: now}))
+	require.NoError(t, provider.AppendUserEvent(models.UserEvent{Username: "john", Type: models.UserEventTOTPRegistered, Time: now}))
+
+	require.NoError(t, provider.PurgeUser("john", now))
+
+	method, err := provider.LoadPreferred2FAMethod("john")
+	require.NoError(t, err)
+	assert.Equal(t, "", method)
+
+	devices, err := provider.LoadTOTPDevices("john")
+	require.NoError(t, err)
+	assert.Len(t, devices, 0)
+
+	_, _, err = provider.LoadU2FDeviceHandle("john")
+	assert.Equal(t, storage.ErrNoU2FDeviceHandle, err)
+
+	credentials, err := provider.LoadWebauthnCredentials("john")
+	require.NoError(t, err)
+	assert.Len(t, credentials, 0)
+
+	events, err := provider.LoadUserEvents("john", "", 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, events, 0)
+
+	events, err = provider.LoadUserEvents("harry", "", 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+
+	revokedAt, err := provider.LoadSessionsRevocation("john")
+	require.NoError(t, err)
+	assert.Equal(t, now, revokedAt)
+
+	revokedAt, err = provider.LoadSessionsRevocation("harry")
+	require.NoError(t, err)
+	assert.True(t, revokedAt.IsZero())
+}
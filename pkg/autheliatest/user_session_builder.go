@@ -0,0 +1,73 @@
+package autheliatest
+
+import (
+	"time"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/session"
+)
+
+// UserSessionBuilder builds session.UserSession fixtures for tests.
+type UserSessionBuilder struct {
+	userSession session.UserSession
+}
+
+// NewUserSessionBuilder creates a new UserSessionBuilder for the given username, starting from the default session.
+func NewUserSessionBuilder(username string) *UserSessionBuilder {
+	userSession := session.NewDefaultUserSession()
+	userSession.Username = username
+
+	return &UserSessionBuilder{userSession: userSession}
+}
+
+// WithDisplayName sets the display name of the user.
+func (b *UserSessionBuilder) WithDisplayName(displayName string) *UserSessionBuilder {
+	b.userSession.DisplayName = displayName
+
+	return b
+}
+
+// WithEmails sets the emails of the user.
+func (b *UserSessionBuilder) WithEmails(emails ...string) *UserSessionBuilder {
+	b.userSession.Emails = emails
+
+	return b
+}
+
+// WithGroups sets the groups of the user.
+func (b *UserSessionBuilder) WithGroups(groups ...string) *UserSessionBuilder {
+	b.userSession.Groups = groups
+
+	return b
+}
+
+// OneFactor marks the session as authenticated with one factor at the provided time.
+func (b *UserSessionBuilder) OneFactor(at time.Time) *UserSessionBuilder {
+	b.userSession.AuthenticationLevel = authentication.OneFactor
+	b.userSession.FirstFactorAuthnTimestamp = at.Unix()
+	b.userSession.LastActivity = at.Unix()
+
+	return b
+}
+
+// TwoFactor marks the session as authenticated with two factors at the provided time.
+func (b *UserSessionBuilder) TwoFactor(at time.Time) *UserSessionBuilder {
+	b.OneFactor(at)
+
+	b.userSession.AuthenticationLevel = authentication.TwoFactor
+	b.userSession.SecondFactorAuthnTimestamp = at.Unix()
+
+	return b
+}
+
+// KeepMeLoggedIn sets the remember me flag of the session.
+func (b *UserSessionBuilder) KeepMeLoggedIn() *UserSessionBuilder {
+	b.userSession.KeepMeLoggedIn = true
+
+	return b
+}
+
+// Build returns the built session.UserSession.
+func (b *UserSessionBuilder) Build() session.UserSession {
+	return b.userSession
+}
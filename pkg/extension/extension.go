@@ -0,0 +1,109 @@
+// Package extension exposes the stable interfaces and registration functions which allow custom authentication
+// backends, notifiers and storage providers to be compiled into Authelia without modifying its internal packages.
+//
+// Providers register themselves in an init function, in the same fashion as database/sql drivers, and are then
+// selected with the provider key of the relevant configuration section:
+//
+//	func init() {
+//		extension.RegisterUserProvider("myprovider", func(configuration extension.AuthenticationBackendConfiguration, options extension.Options, certPool *x509.CertPool) (extension.UserProvider, error) {
+//			var config MyProviderConfiguration
+//
+//			if err := extension.DecodeOptions(options, &config); err != nil {
+//				return nil, err
+//			}
+//
+//			return NewMyProvider(config), nil
+//		})
+//	}
+//
+// The options are decoded from the options block of the configuration section selecting the provider:
+//
+//	authentication_backend:
+//	  provider: myprovider
+//	  options:
+//	    url: https://idp.example.com
+//
+// The package registering the provider then only needs to be imported for its side effects by the main package.
+package extension
+
+import (
+	"crypto/x509"
+
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/authelia/authelia/internal/authentication"
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/notification"
+	"github.com/authelia/authelia/internal/storage"
+)
+
+// UserProvider is the interface implemented by authentication backends.
+type UserProvider = authentication.UserProvider
+
+// UserDetails represents the details of a user returned by a UserProvider.
+type UserDetails = authentication.UserDetails
+
+// Notifier is the interface implemented by notifiers.
+type Notifier = notification.Notifier
+
+// AuthenticationBackendConfiguration is the configuration passed to a UserProvider factory.
+type AuthenticationBackendConfiguration = schema.AuthenticationBackendConfiguration
+
+// NotifierConfiguration is the configuration passed to a Notifier factory.
+type NotifierConfiguration = schema.NotifierConfiguration
+
+// StorageConfiguration is the configuration passed to a StorageProviderV1 factory.
+type StorageConfiguration = schema.StorageConfiguration
+
+// Options are the options of a custom provider decoded from the options block of its configuration section. The keys
+// are lower case like the other configuration keys.
+type Options = map[string]interface{}
+
+// DecodeOptions decodes the options of a custom provider into the result, which must be a pointer to a struct whose
+// fields are tagged with mapstructure like the configuration. The durations can be written as strings such as 1m30s
+// and the options which don't match a field are reported as an error.
+func DecodeOptions(options Options, result interface{}) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+		ErrorUnused:      true,
+		WeaklyTypedInput: true,
+		Result:           result,
+	})
+	if err != nil {
+		return err
+	}
+
+	return decoder.Decode(options)
+}
+
+// RegisterUserProvider registers a UserProvider factory under the provided name. The provider is selected with the
+// authentication_backend.provider configuration key and its options are those of the authentication_backend.options
+// block. It panics if the name is already registered.
+func RegisterUserProvider(name string, factory func(configuration AuthenticationBackendConfiguration, options Options, certPool *x509.CertPool) (UserProvider, error)) {
+	authentication.RegisterUserProvider(name, factory)
+}
+
+// RegisterNotifier registers a Notifier factory under the provided name. The notifier is selected with the
+// notifier.provider configuration key and its options are those of the notifier.options block. It panics if the name
+// is already registered.
+func RegisterNotifier(name string, factory func(configuration NotifierConfiguration, options Options, certPool *x509.CertPool) (Notifier, error)) {
+	notification.RegisterNotifier(name, factory)
+}
+
+// RegisterStorageProvider registers a StorageProviderV1 factory under the provided name. The provider is selected with
+// the storage.provider configuration key and its options are those of the storage.options block. It panics if the name
+// is already registered.
+func RegisterStorageProvider(name string, factory func(configuration StorageConfiguration, options Options) (StorageProviderV1, error)) {
+	if factory == nil {
+		panic("extension: RegisterStorageProvider factory is nil")
+	}
+
+	storage.RegisterProvider(name, func(configuration schema.StorageConfiguration, options map[string]interface{}) (storage.Provider, error) {
+		provider, err := factory(configuration, options)
+		if err != nil {
+			return nil, err
+		}
+
+		return &storageProviderV1Adapter{provider: provider}, nil
+	})
+}
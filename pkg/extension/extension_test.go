@@ -0,0 +1,45 @@
+package extension_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/pkg/extension"
+)
+
+type webhookOptions struct {
+	URL     string        `mapstructure:"url"`
+	Retries int           `mapstructure:"retries"`
+	Timeout time.Duration `mapstructure:"timeout"`
+	Headers []string      `mapstructure:"headers"`
+}
+
+func TestShouldDecodeOptions(t *testing.T) {
+	var options webhookOptions
+
+	err := extension.DecodeOptions(extension.Options{
+		"url":     "https://chat.example.com/hook",
+		"retries": "3",
+		"timeout": "1m30s",
+		"headers": []interface{}{"X-Source: authelia"},
+	}, &options)
+
+	require.NoError(t, err)
+	assert.Equal(t, webhookOptions{
+		URL:     "https://chat.example.com/hook",
+		Retries: 3,
+		Timeout: 90 * time.Second,
+		Headers: []string{"X-Source: authelia"},
+	}, options)
+}
+
+func TestShouldErrorOnUnknownOptions(t *testing.T) {
+	var options webhookOptions
+
+	err := extension.DecodeOptions(extension.Options{"uri": "https://chat.example.com/hook"}, &options)
+
+	assert.EqualError(t, err, "1 error(s) decoding:\n\n* '' has invalid keys: uri")
+}
@@ -0,0 +1,509 @@
+package extension
+
+import (
+	"errors"
+	"time"
+
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/storage"
+)
+
+// StorageProviderV1 is the interface implemented by the custom storage providers. It's limited to the data of the
+// login flows and never changes, the features beyond it are unavailable with a custom storage provider: their data
+// reads as empty and storing it fails with ErrStorageNotSupported. A later version of the interface is a new type.
+type StorageProviderV1 interface {
+	LoadPreferred2FAMethod(username string) (string, error)
+	SavePreferred2FAMethod(username string, method string) error
+	DeletePreferred2FAMethod(username string) error
+
+	FindIdentityVerificationToken(token string) (bool, error)
+	SaveIdentityVerificationToken(token string, expiresAt time.Time) error
+	RemoveIdentityVerificationToken(token string) error
+
+	// SaveLoginState and ConsumeLoginState store the nonces of the signed login states, ConsumeLoginState returns
+	// ErrNoLoginState when the nonce is unknown, has expired or has already been consumed.
+	SaveLoginState(nonce string, expiresAt time.Time) error
+	ConsumeLoginState(nonce string, now time.Time) error
+
+	// SaveTOTPDeviceLastUsed returns ErrTOTPStepAlreadyUsed when the step isn't more recent than the last one used with
+	// the device.
+	SaveTOTPDevice(device TOTPDevice) error
+	LoadTOTPDevices(username string) ([]TOTPDevice, error)
+	UpdateTOTPDeviceDescription(username, deviceID, description string) error
+	DeleteTOTPDevice(username, deviceID string) error
+	SaveTOTPDeviceLastUsed(username, deviceID string, step uint64, usedAt time.Time) error
+
+	// LoadU2FDeviceHandle and DeleteU2FDeviceHandle return ErrNoU2FDeviceHandle when the user has no U2F device.
+	SaveU2FDeviceHandle(username string, keyHandle []byte, publicKey []byte) error
+	LoadU2FDeviceHandle(username string) (keyHandle []byte, publicKey []byte, err error)
+	DeleteU2FDeviceHandle(username string) error
+
+	// LoadWebauthnCredential and DeleteWebauthnCredential return ErrNoWebauthnCredential when there's no such
+	// credential.
+	SaveWebauthnCredential(credential WebauthnCredential) error
+	LoadWebauthnCredential(credentialID []byte) (*WebauthnCredential, error)
+	LoadWebauthnCredentials(username string) ([]WebauthnCredential, error)
+	UpdateWebauthnCredentialSignCount(credentialID []byte, signCount uint32) error
+	DeleteWebauthnCredential(username string, credentialID []byte) error
+
+	AppendAuthenticationLog(attempt AuthenticationAttempt) error
+	LoadLatestAuthenticationLogs(username string, fromDate time.Time) ([]AuthenticationAttempt, error)
+
+	AppendUserEvent(event UserEvent) error
+	LoadUserEvents(username, eventType string, limit, offset int) ([]UserEvent, error)
+}
+
+// AuthenticationAttempt represents an authentication attempt persisted by a StorageProviderV1.
+type AuthenticationAttempt struct {
+	// The user who tried to authenticate.
+	Username string
+	// Successful true if the attempt was successful.
+	Successful bool
+	// The time of the attempt.
+	Time time.Time
+	// The IP of the client which made the attempt.
+	RemoteIP string
+	// The user agent of the client which made the attempt.
+	UserAgent string
+	// The type of the attempt such as 1FA or TOTP.
+	Type string
+}
+
+// UserEvent represents an event of the account of a user persisted by a StorageProviderV1.
+type UserEvent struct {
+	// The user the event relates to.
+	Username string
+	// The type of the event, it's the type of the attempt for the authentication attempts.
+	Type string
+	// Successful true if the event was successful.
+	Successful bool
+	// The time of the event.
+	Time time.Time
+	// The IP of the client which triggered the event.
+	RemoteIP string
+	// The user agent of the client which triggered the event.
+	UserAgent string
+}
+
+// TOTPDevice represents a device generating the one-time passwords of a user persisted by a StorageProviderV1.
+type TOTPDevice struct {
+	// The ID of the device, it's unique among the devices of the user.
+	ID string
+	// The user who owns the device.
+	Username string
+	// The friendly name given by the user to tell the devices apart.
+	Description string
+	// The base32 encoded secret shared with the device.
+	Secret string
+	// The time the device was registered.
+	CreatedAt time.Time
+	// The time a passcode of the device was last used, it's zero if the device has never been used.
+	LastUsedAt time.Time
+}
+
+// WebauthnCredential represents a WebAuthn credential persisted by a StorageProviderV1.
+type WebauthnCredential struct {
+	// The ID of the credential as generated by the authenticator.
+	ID []byte
+	// The user who owns the credential.
+	Username string
+	// The type of the credential, either WebauthnCredentialTypePasskey or WebauthnCredentialTypeSecondFactor.
+	Type string
+	// The friendly name given by the user to tell the credentials apart.
+	Description string
+	// The COSE encoded public key of the credential.
+	PublicKey []byte
+	// The signature counter last reported by the authenticator.
+	SignCount uint32
+	// The time the credential was registered.
+	CreatedAt time.Time
+}
+
+// Types of the WebAuthn credentials.
+const (
+	WebauthnCredentialTypePasskey      = models.WebauthnCredentialTypePasskey
+	WebauthnCredentialTypeSecondFactor = models.WebauthnCredentialTypeSecondFactor
+)
+
+var (
+	// ErrStorageNotSupported is returned when the data of a feature beyond StorageProviderV1 is stored.
+	ErrStorageNotSupported = errors.New("not supported by the custom storage provider")
+
+	// ErrNoLoginState is returned by a StorageProviderV1 when a login state nonce can't be consumed.
+	ErrNoLoginState = storage.ErrNoLoginState
+
+	// ErrTOTPStepAlreadyUsed is returned by a StorageProviderV1 when a TOTP time step has already been used.
+	ErrTOTPStepAlreadyUsed = storage.ErrTOTPStepAlreadyUsed
+
+	// ErrNoU2FDeviceHandle is returned by a StorageProviderV1 when a user has no U2F device.
+	ErrNoU2FDeviceHandle = storage.ErrNoU2FDeviceHandle
+
+	// ErrNoWebauthnCredential is returned by a StorageProviderV1 when a WebAuthn credential is unknown.
+	ErrNoWebauthnCredential = storage.ErrNoWebauthnCredential
+)
+
+var _ storage.Provider = (*storageProviderV1Adapter)(nil)
+
+// storageProviderV1Adapter adapts a StorageProviderV1 to the storage Provider used internally.
+type storageProviderV1Adapter struct {
+	provider StorageProviderV1
+}
+
+func (a *storageProviderV1Adapter) LoadPreferred2FAMethod(username string) (string, error) {
+	return a.provider.LoadPreferred2FAMethod(username)
+}
+
+func (a *storageProviderV1Adapter) SavePreferred2FAMethod(username string, method string) error {
+	return a.provider.SavePreferred2FAMethod(username, method)
+}
+
+func (a *storageProviderV1Adapter) DeletePreferred2FAMethod(username string) error {
+	return a.provider.DeletePreferred2FAMethod(username)
+}
+
+func (a *storageProviderV1Adapter) FindIdentityVerificationToken(token string) (bool, error) {
+	return a.provider.FindIdentityVerificationToken(token)
+}
+
+func (a *storageProviderV1Adapter) SaveIdentityVerificationToken(token string, expiresAt time.Time) error {
+	return a.provider.SaveIdentityVerificationToken(token, expiresAt)
+}
+
+func (a *storageProviderV1Adapter) RemoveIdentityVerificationToken(token string) error {
+	return a.provider.RemoveIdentityVerificationToken(token)
+}
+
+func (a *storageProviderV1Adapter) SaveLoginState(nonce string, expiresAt time.Time) error {
+	return a.provider.SaveLoginState(nonce, expiresAt)
+}
+
+func (a *storageProviderV1Adapter) ConsumeLoginState(nonce string, now time.Time) error {
+	return a.provider.ConsumeLoginState(nonce, now)
+}
+
+func (a *storageProviderV1Adapter) SaveTOTPDevice(device models.TOTPDevice) error {
+	return a.provider.SaveTOTPDevice(TOTPDevice(device))
+}
+
+func (a *storageProviderV1Adapter) LoadTOTPDevices(username string) ([]models.TOTPDevice, error) {
+	devices, err := a.provider.LoadTOTPDevices(username)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.TOTPDevice, 0, len(devices))
+	for _, device := range devices {
+		result = append(result, models.TOTPDevice(device))
+	}
+
+	return result, nil
+}
+
+func (a *storageProviderV1Adapter) UpdateTOTPDeviceDescription(username, deviceID, description string) error {
+	return a.provider.UpdateTOTPDeviceDescription(username, deviceID, description)
+}
+
+func (a *storageProviderV1Adapter) DeleteTOTPDevice(username, deviceID string) error {
+	return a.provider.DeleteTOTPDevice(username, deviceID)
+}
+
+func (a *storageProviderV1Adapter) SaveTOTPDeviceLastUsed(username, deviceID string, step uint64, usedAt time.Time) error {
+	return a.provider.SaveTOTPDeviceLastUsed(username, deviceID, step, usedAt)
+}
+
+func (a *storageProviderV1Adapter) SaveU2FDeviceHandle(username string, keyHandle []byte, publicKey []byte) error {
+	return a.provider.SaveU2FDeviceHandle(username, keyHandle, publicKey)
+}
+
+func (a *storageProviderV1Adapter) LoadU2FDeviceHandle(username string) (keyHandle []byte, publicKey []byte, err error) {
+	return a.provider.LoadU2FDeviceHandle(username)
+}
+
+func (a *storageProviderV1Adapter) DeleteU2FDeviceHandle(username string) error {
+	return a.provider.DeleteU2FDeviceHandle(username)
+}
+
+func (a *storageProviderV1Adapter) SaveWebauthnCredential(credential models.WebauthnCredential) error {
+	return a.provider.SaveWebauthnCredential(WebauthnCredential(credential))
+}
+
+func (a *storageProviderV1Adapter) LoadWebauthnCredential(credentialID []byte) (*models.WebauthnCredential, error) {
+	credential, err := a.provider.LoadWebauthnCredential(credentialID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := models.WebauthnCredential(*credential)
+
+	return &result, nil
+}
+
+func (a *storageProviderV1Adapter) LoadWebauthnCredentials(username string) ([]models.WebauthnCredential, error) {
+	credentials, err := a.provider.LoadWebauthnCredentials(username)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.WebauthnCredential, 0, len(credentials))
+	for _, credential := range credentials {
+		result = append(result, models.WebauthnCredential(credential))
+	}
+
+	return result, nil
+}
+
+func (a *storageProviderV1Adapter) UpdateWebauthnCredentialSignCount(credentialID []byte, signCount uint32) error {
+	return a.provider.UpdateWebauthnCredentialSignCount(credentialID, signCount)
+}
+
+func (a *storageProviderV1Adapter) DeleteWebauthnCredential(username string, credentialID []byte) error {
+	return a.provider.DeleteWebauthnCredential(username, credentialID)
+}
+
+func (a *storageProviderV1Adapter) AppendAuthenticationLog(attempt models.AuthenticationAttempt) error {
+	return a.provider.AppendAuthenticationLog(AuthenticationAttempt(attempt))
+}
+
+func (a *storageProviderV1Adapter) LoadLatestAuthenticationLogs(username string, fromDate time.Time) ([]models.AuthenticationAttempt, error) {
+	attempts, err := a.provider.LoadLatestAuthenticationLogs(username, fromDate)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.AuthenticationAttempt, 0, len(attempts))
+	for _, attempt := range attempts {
+		result = append(result, models.AuthenticationAttempt(attempt))
+	}
+
+	return result, nil
+}
+
+func (a *storageProviderV1Adapter) DeleteFailedAuthenticationLogs(username string) (int64, error) {
+	return 0, ErrStorageNotSupported
+}
+
+// LoadLastLogin returns the time of the latest successful attempt of the authentication logs.
+func (a *storageProviderV1Adapter) LoadLastLogin(username string) (lastLogin time.Time, err error) {
+	attempts, err := a.provider.LoadLatestAuthenticationLogs(username, time.Time{})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	for _, attempt := range attempts {
+		if attempt.Successful && attempt.Time.After(lastLogin) {
+			lastLogin = attempt.Time
+		}
+	}
+
+	return lastLogin, nil
+}
+
+func (a *storageProviderV1Adapter) AppendUserEvent(event models.UserEvent) error {
+	return a.provider.AppendUserEvent(UserEvent(event))
+}
+
+func (a *storageProviderV1Adapter) LoadUserEvents(username, eventType string, limit, offset int) ([]models.UserEvent, error) {
+	events, err := a.provider.LoadUserEvents(username, eventType, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.UserEvent, 0, len(events))
+	for _, event := range events {
+		result = append(result, models.UserEvent(event))
+	}
+
+	return result, nil
+}
+
+// The features beyond StorageProviderV1 follow, their data reads as empty and storing it fails.
+
+func (a *storageProviderV1Adapter) LoadNotificationThrottle(username, kind string) (models.NotificationThrottle, error) {
+	return models.NotificationThrottle{Username: username, Kind: kind}, nil
+}
+
+func (a *storageProviderV1Adapter) SaveNotificationThrottle(throttle models.NotificationThrottle) error {
+	return ErrStorageNotSupported
+}
+
+func (a *storageProviderV1Adapter) LoadPendingNotificationThrottles() ([]models.NotificationThrottle, error) {
+	return nil, nil
+}
+
+func (a *storageProviderV1Adapter) SaveAnnouncementAcknowledgment(username, announcementID string, acknowledgedAt time.Time) error {
+	return ErrStorageNotSupported
+}
+
+func (a *storageProviderV1Adapter) LoadAnnouncementAcknowledgment(username, announcementID string) (time.Time, error) {
+	return time.Time{}, nil
+}
+
+func (a *storageProviderV1Adapter) SaveBackupCodes(username string, codeHashes []string, createdAt time.Time) error {
+	return ErrStorageNotSupported
+}
+
+func (a *storageProviderV1Adapter) ConsumeBackupCode(username, codeHash string) error {
+	return storage.ErrNoBackupCode
+}
+
+func (a *storageProviderV1Adapter) LoadBackupCodeHashes(username string) ([]string, error) {
+	return nil, nil
+}
+
+func (a *storageProviderV1Adapter) CountBackupCodes(username string) (int, error) {
+	return 0, nil
+}
+
+func (a *storageProviderV1Adapter) SaveAdminAPIToken(token models.AdminAPIToken) error {
+	return ErrStorageNotSupported
+}
+
+func (a *storageProviderV1Adapter) LoadAdminAPIToken(tokenHash string) (*models.AdminAPIToken, error) {
+	return nil, storage.ErrNoAdminAPIToken
+}
+
+func (a *storageProviderV1Adapter) LoadAdminAPITokens() ([]models.AdminAPIToken, error) {
+	return nil, nil
+}
+
+func (a *storageProviderV1Adapter) UpdateAdminAPITokenLastUsed(id string, lastUsedAt time.Time) error {
+	return ErrStorageNotSupported
+}
+
+func (a *storageProviderV1Adapter) DeleteAdminAPIToken(id string) error {
+	return storage.ErrNoAdminAPIToken
+}
+
+func (a *storageProviderV1Adapter) SavePushEnrollment(enrollment models.PushEnrollment) error {
+	return ErrStorageNotSupported
+}
+
+func (a *storageProviderV1Adapter) LoadPushEnrollment(username string) (*models.PushEnrollment, error) {
+	return nil, storage.ErrNoPushEnrollment
+}
+
+func (a *storageProviderV1Adapter) SaveSecondFactorReset(reset models.SecondFactorReset) error {
+	return ErrStorageNotSupported
+}
+
+func (a *storageProviderV1Adapter) LoadSecondFactorReset(id string) (*models.SecondFactorReset, error) {
+	return nil, storage.ErrNoSecondFactorReset
+}
+
+func (a *storageProviderV1Adapter) LoadPendingSecondFactorResets(now time.Time) ([]models.SecondFactorReset, error) {
+	return nil, nil
+}
+
+func (a *storageProviderV1Adapter) ApproveSecondFactorReset(id, username, approvedBy string, approvedAt time.Time) error {
+	return storage.ErrNoSecondFactorReset
+}
+
+func (a *storageProviderV1Adapter) RejectSecondFactorReset(id, username, rejectedBy string, rejectedAt time.Time) error {
+	return storage.ErrNoSecondFactorReset
+}
+
+func (a *storageProviderV1Adapter) SaveAccessGrant(grant models.AccessGrant) error {
+	return ErrStorageNotSupported
+}
+
+func (a *storageProviderV1Adapter) LoadAccessGrant(id string) (*models.AccessGrant, error) {
+	return nil, storage.ErrNoAccessGrant
+}
+
+func (a *storageProviderV1Adapter) LoadAccessGrants(now time.Time) ([]models.AccessGrant, error) {
+	return nil, nil
+}
+
+func (a *storageProviderV1Adapter) LoadUserAccessGrants(username string, now time.Time) ([]models.AccessGrant, error) {
+	return nil, nil
+}
+
+func (a *storageProviderV1Adapter) DeleteAccessGrant(id string) error {
+	return storage.ErrNoAccessGrant
+}
+
+func (a *storageProviderV1Adapter) SaveBreakGlassActivation(activation models.BreakGlassActivation) error {
+	return ErrStorageNotSupported
+}
+
+func (a *storageProviderV1Adapter) LoadBreakGlassActivation(username string) (*models.BreakGlassActivation, error) {
+	return nil, storage.ErrNoBreakGlassActivation
+}
+
+func (a *storageProviderV1Adapter) LoadBreakGlassActivations() ([]models.BreakGlassActivation, error) {
+	return nil, nil
+}
+
+func (a *storageProviderV1Adapter) DeleteBreakGlassActivation(username string) error {
+	return storage.ErrNoBreakGlassActivation
+}
+
+func (a *storageProviderV1Adapter) SaveOIDCConsent(consent models.OIDCConsent) error {
+	return ErrStorageNotSupported
+}
+
+func (a *storageProviderV1Adapter) LoadOIDCConsent(username, clientID string, now time.Time) (*models.OIDCConsent, error) {
+	return nil, storage.ErrNoOIDCConsent
+}
+
+func (a *storageProviderV1Adapter) LoadUserOIDCConsents(username string, now time.Time) ([]models.OIDCConsent, error) {
+	return nil, nil
+}
+
+func (a *storageProviderV1Adapter) DeleteOIDCConsent(username, clientID string) error {
+	return storage.ErrNoOIDCConsent
+}
+
+func (a *storageProviderV1Adapter) SaveOIDCClient(client models.OIDCClient) error {
+	return ErrStorageNotSupported
+}
+
+func (a *storageProviderV1Adapter) LoadOIDCClients() ([]models.OIDCClient, error) {
+	return nil, nil
+}
+
+func (a *storageProviderV1Adapter) DeleteOIDCClient(id string) error {
+	return storage.ErrNoOIDCClient
+}
+
+func (a *storageProviderV1Adapter) LoadUsernames() ([]string, error) {
+	return nil, nil
+}
+
+func (a *storageProviderV1Adapter) PurgeUser(username string, revokedAt time.Time) error {
+	return ErrStorageNotSupported
+}
+
+func (a *storageProviderV1Adapter) RevokeSessions(username string, revokedAt time.Time) error {
+	return ErrStorageNotSupported
+}
+
+func (a *storageProviderV1Adapter) LoadSessionsRevocation(username string) (time.Time, error) {
+	return time.Time{}, nil
+}
+
+// The data of a StorageProviderV1 is pruned by the provider itself, if ever.
+
+func (a *storageProviderV1Adapter) PruneIdentityVerificationTokens(now time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (a *storageProviderV1Adapter) PruneAuthenticationLogs(before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (a *storageProviderV1Adapter) PruneSessionRevocations(before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (a *storageProviderV1Adapter) PruneLoginStates(now time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (a *storageProviderV1Adapter) PruneAccessGrants(now time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (a *storageProviderV1Adapter) PruneOIDCConsents(now time.Time) (int64, error) {
+	return 0, nil
+}
@@ -0,0 +1,99 @@
+package extension_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authelia/authelia/internal/configuration/schema"
+	"github.com/authelia/authelia/internal/models"
+	"github.com/authelia/authelia/internal/storage"
+	"github.com/authelia/authelia/pkg/extension"
+)
+
+// memoryStorageProviderV1 stores the WebAuthn credentials and the authentication logs, the other methods of the
+// interface aren't used by the tests.
+type memoryStorageProviderV1 struct {
+	extension.StorageProviderV1
+
+	credentials []extension.WebauthnCredential
+	attempts    []extension.AuthenticationAttempt
+}
+
+func (p *memoryStorageProviderV1) SaveWebauthnCredential(credential extension.WebauthnCredential) error {
+	p.credentials = append(p.credentials, credential)
+	return nil
+}
+
+func (p *memoryStorageProviderV1) LoadWebauthnCredential(credentialID []byte) (*extension.WebauthnCredential, error) {
+	for _, credential := range p.credentials {
+		if string(credential.ID) == string(credentialID) {
+			return &credential, nil
+		}
+	}
+
+	return nil, extension.ErrNoWebauthnCredential
+}
+
+func (p *memoryStorageProviderV1) AppendAuthenticationLog(attempt extension.AuthenticationAttempt) error {
+	p.attempts = append(p.attempts, attempt)
+	return nil
+}
+
+func (p *memoryStorageProviderV1) LoadLatestAuthenticationLogs(username string, fromDate time.Time) (attempts []extension.AuthenticationAttempt, err error) {
+	for _, attempt := range p.attempts {
+		if attempt.Username == username && !attempt.Time.Before(fromDate) {
+			attempts = append(attempts, attempt)
+		}
+	}
+
+	return attempts, nil
+}
+
+func TestShouldAdaptStorageProviderV1(t *testing.T) {
+	custom := &memoryStorageProviderV1{}
+
+	extension.RegisterStorageProvider("memoryv1", func(configuration extension.StorageConfiguration, options extension.Options) (extension.StorageProviderV1, error) {
+		return custom, nil
+	})
+
+	provider, err := storage.NewProvider(schema.StorageConfiguration{Provider: "memoryv1"})
+	require.NoError(t, err)
+
+	credential := models.WebauthnCredential{
+		ID:        []byte("abc"),
+		Username:  "john",
+		Type:      models.WebauthnCredentialTypeSecondFactor,
+		PublicKey: []byte("key"),
+		CreatedAt: time.Unix(1577880000, 0),
+	}
+
+	require.NoError(t, provider.SaveWebauthnCredential(credential))
+	assert.Equal(t, extension.WebauthnCredentialTypeSecondFactor, custom.credentials[0].Type)
+
+	loaded, err := provider.LoadWebauthnCredential([]byte("abc"))
+	require.NoError(t, err)
+	assert.Equal(t, credential, *loaded)
+
+	_, err = provider.LoadWebauthnCredential([]byte("def"))
+	assert.Equal(t, storage.ErrNoWebauthnCredential, err)
+
+	require.NoError(t, provider.AppendAuthenticationLog(models.AuthenticationAttempt{Username: "john", Successful: true, Time: time.Unix(1577880001, 0)}))
+	require.NoError(t, provider.AppendAuthenticationLog(models.AuthenticationAttempt{Username: "john", Successful: false, Time: time.Unix(1577880002, 0)}))
+
+	lastLogin, err := provider.LoadLastLogin("john")
+	require.NoError(t, err)
+	assert.Equal(t, time.Unix(1577880001, 0), lastLogin)
+
+	// The features beyond the interface read as empty and can't be stored.
+	count, err := provider.CountBackupCodes("john")
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	assert.Equal(t, extension.ErrStorageNotSupported, provider.SaveBackupCodes("john", []string{"hash"}, time.Unix(1577880000, 0)))
+
+	_, err = provider.LoadAdminAPIToken("hash")
+	assert.Equal(t, storage.ErrNoAdminAPIToken, err)
+}
@@ -13,6 +13,7 @@ import {
     RegisterOneTimePasswordRoute,
     LogoutRoute,
     ConsentRoute,
+    ApproveSecondFactorResetRoute,
 } from "@constants/Routes";
 import NotificationsContext from "@hooks/NotificationsContext";
 import { Notification } from "@models/Notifications";
@@ -26,6 +27,7 @@ import LoginPortal from "@views/LoginPortal/LoginPortal";
 import SignOut from "@views/LoginPortal/SignOut/SignOut";
 import ResetPasswordStep1 from "@views/ResetPassword/ResetPasswordStep1";
 import ResetPasswordStep2 from "@views/ResetPassword/ResetPasswordStep2";
+import ApproveSecondFactorReset from "@views/SecondFactorReset/ApproveSecondFactorReset";
 
 import "@fortawesome/fontawesome-svg-core/styles.css";
 
@@ -77,6 +79,9 @@ const App: React.FC = () => {
                         <Route path={RegisterOneTimePasswordRoute} exact>
                             <RegisterOneTimePassword />
                         </Route>
+                        <Route path={ApproveSecondFactorResetRoute} exact>
+                            <ApproveSecondFactorReset />
+                        </Route>
                         <Route path={LogoutRoute} exact>
                             <SignOut />
                         </Route>
@@ -0,0 +1,38 @@
+import React, { ReactNode } from "react";
+
+import { makeStyles, Typography } from "@material-ui/core";
+
+export interface Props {
+    id: string;
+    message: string;
+
+    children?: ReactNode;
+}
+
+const Banner = function (props: Props) {
+    const style = useStyles();
+
+    return (
+        <div id={props.id} className={style.container}>
+            <Typography variant="body2" className={style.message}>
+                {props.message}
+            </Typography>
+            {props.children}
+        </div>
+    );
+};
+
+export default Banner;
+
+const useStyles = makeStyles((theme) => ({
+    container: {
+        border: "1px solid #d6d6d6",
+        borderRadius: "10px",
+        padding: theme.spacing(2),
+        marginBottom: theme.spacing(2),
+        textAlign: "left",
+    },
+    message: {
+        whiteSpace: "pre-wrap",
+    },
+}));
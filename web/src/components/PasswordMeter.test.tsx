@@ -0,0 +1,30 @@
+import React from "react";
+
+import { mount } from "enzyme";
+
+import PasswordMeter from "@components/PasswordMeter";
+
+const policy = {
+    min_length: 8,
+    max_length: 0,
+    require_uppercase: true,
+    require_lowercase: false,
+    require_number: true,
+    require_special: false,
+    min_score: 2,
+};
+
+it("renders without crashing", () => {
+    mount(<PasswordMeter policy={policy} />);
+});
+
+it("renders the requirements of the policy", () => {
+    const element = mount(
+        <PasswordMeter policy={policy} strength={{ score: 1, valid: false, unmet: ["number", "min_score"] }} />,
+    );
+    expect(element.text()).toContain("At least 8 characters");
+    expect(element.text()).toContain("An uppercase letter");
+    expect(element.text()).toContain("A number");
+    expect(element.text()).not.toContain("A lowercase letter");
+    expect(element.find("#password-meter-score").first().text()).toBe("Weak");
+});
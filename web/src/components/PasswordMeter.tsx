@@ -0,0 +1,79 @@
+import React from "react";
+
+import { makeStyles, Typography } from "@material-ui/core";
+
+import LinearProgressBar from "@components/LinearProgressBar";
+import { PasswordPolicy, PasswordRule, PasswordStrength } from "@models/PasswordPolicy";
+
+export interface Props {
+    policy: PasswordPolicy;
+    strength?: PasswordStrength;
+}
+
+const scoreLabels = ["Very weak", "Weak", "Fair", "Strong", "Very strong"];
+
+function requirements(policy: PasswordPolicy): [PasswordRule, string][] {
+    const rules: [PasswordRule, string][] = [];
+
+    if (policy.min_length > 0) {
+        rules.push(["min_length", `At least ${policy.min_length} characters`]);
+    }
+    if (policy.max_length > 0) {
+        rules.push(["max_length", `At most ${policy.max_length} characters`]);
+    }
+    if (policy.require_uppercase) {
+        rules.push(["uppercase", "An uppercase letter"]);
+    }
+    if (policy.require_lowercase) {
+        rules.push(["lowercase", "A lowercase letter"]);
+    }
+    if (policy.require_number) {
+        rules.push(["number", "A number"]);
+    }
+    if (policy.require_special) {
+        rules.push(["special", "A special character"]);
+    }
+    if (policy.min_score > 0) {
+        rules.push(["min_score", `A strength of at least "${scoreLabels[policy.min_score]}"`]);
+    }
+
+    return rules;
+}
+
+const PasswordMeter = function (props: Props) {
+    const style = useStyles();
+    const score = props.strength ? props.strength.score : 0;
+    const unmet = new Set(props.strength ? props.strength.unmet : []);
+
+    return (
+        <div id="password-meter">
+            <LinearProgressBar value={(score + 1) * 20} className={style.bar} />
+            <Typography variant="caption" id="password-meter-score">
+                {props.strength ? scoreLabels[score] : ""}
+            </Typography>
+            {requirements(props.policy).map(([rule, label]) => (
+                <Typography
+                    key={rule}
+                    variant="body2"
+                    className={props.strength && !unmet.has(rule) ? style.met : style.unmet}
+                >
+                    {label}
+                </Typography>
+            ))}
+        </div>
+    );
+};
+
+export default PasswordMeter;
+
+const useStyles = makeStyles((theme) => ({
+    bar: {
+        marginBottom: theme.spacing(),
+    },
+    met: {
+        color: theme.palette.success.main,
+    },
+    unmet: {
+        color: theme.palette.text.secondary,
+    },
+}));
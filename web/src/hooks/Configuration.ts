@@ -1,6 +0,0 @@
-import { useRemoteCall } from "@hooks/RemoteCall";
-import { getConfiguration } from "@services/Configuration";
-
-export function useConfiguration() {
-    return useRemoteCall(getConfiguration, []);
-}
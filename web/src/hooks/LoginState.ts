@@ -0,0 +1,8 @@
+import queryString from "query-string";
+import { useLocation } from "react-router";
+
+export function useLoginState() {
+    const location = useLocation();
+    const queryParams = queryString.parse(location.search);
+    return queryParams && "state" in queryParams ? (queryParams["state"] as string) : undefined;
+}
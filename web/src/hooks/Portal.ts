@@ -0,0 +1,6 @@
+import { useRemoteCall } from "@hooks/RemoteCall";
+import { getPortal } from "@services/Portal";
+
+export function usePortal() {
+    return useRemoteCall(getPortal, []);
+}
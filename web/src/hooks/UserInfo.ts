@@ -1,6 +0,0 @@
-import { useRemoteCall } from "@hooks/RemoteCall";
-import { getUserPreferences } from "@services/UserPreferences";
-
-export function useUserPreferences() {
-    return useRemoteCall(getUserPreferences, []);
-}
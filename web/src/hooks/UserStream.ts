@@ -0,0 +1,15 @@
+import { useEffect } from "react";
+
+import { openUserStream, UserStreamEvent } from "@services/UserStream";
+
+export function useUserStream(enabled: boolean, onEvent: (event: UserStreamEvent) => void) {
+    useEffect(() => {
+        if (!enabled) {
+            return;
+        }
+
+        const source = openUserStream(onEvent);
+
+        return () => source.close();
+    }, [enabled, onEvent]);
+}
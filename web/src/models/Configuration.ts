@@ -4,4 +4,12 @@ export interface Configuration {
     available_methods: Set<SecondFactorMethod>;
     second_factor_enabled: boolean;
     totp_period: number;
+    login_banner?: string;
+    announcement?: Announcement;
+}
+
+export interface Announcement {
+    id: string;
+    message: string;
+    acknowledged: boolean;
 }
@@ -2,4 +2,5 @@ export enum SecondFactorMethod {
     TOTP = 1,
     U2F = 2,
     MobilePush = 3,
+    Webauthn = 4,
 }
@@ -0,0 +1,17 @@
+export interface PasswordPolicy {
+    min_length: number;
+    max_length: number;
+    require_uppercase: boolean;
+    require_lowercase: boolean;
+    require_number: boolean;
+    require_special: boolean;
+    min_score: number;
+}
+
+export type PasswordRule = "min_length" | "max_length" | "uppercase" | "lowercase" | "number" | "special" | "min_score";
+
+export interface PasswordStrength {
+    score: number;
+    valid: boolean;
+    unmet: PasswordRule[];
+}
@@ -1,8 +1,27 @@
 import { SecondFactorMethod } from "@models/Methods";
 
+export interface RegulationInfo {
+    banned: boolean;
+    banned_until?: number;
+    failed_attempts: number;
+}
+
+export interface TOTPDevice {
+    id: string;
+    description: string;
+    created_at?: number;
+    last_used_at?: number;
+}
+
 export interface UserInfo {
     display_name: string;
     method: SecondFactorMethod;
     has_u2f: boolean;
     has_totp: boolean;
+    totp_devices?: TOTPDevice[];
+    has_webauthn: boolean;
+    backup_codes_remaining: number;
+    first_factor_regulation: RegulationInfo;
+    second_factor_regulation: RegulationInfo;
+    warnings?: string[];
 }
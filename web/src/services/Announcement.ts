@@ -0,0 +1,10 @@
+import { UserAnnouncementAcknowledgmentPath } from "@services/Api";
+import { PostWithOptionalResponse } from "@services/Client";
+
+interface AnnouncementAcknowledgmentPayload {
+    id: string;
+}
+
+export function acknowledgeAnnouncement(id: string) {
+    return PostWithOptionalResponse(UserAnnouncementAcknowledgmentPath, { id } as AnnouncementAcknowledgmentPayload);
+}
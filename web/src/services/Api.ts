@@ -18,18 +18,33 @@ export const CompleteU2FRegistrationStep2Path = basePath + "/api/secondfactor/u2
 export const InitiateU2FSignInPath = basePath + "/api/secondfactor/u2f/sign_request";
 export const CompleteU2FSignInPath = basePath + "/api/secondfactor/u2f/sign";
 
+export const InitiateWebauthnRegistrationPath = basePath + "/api/secondfactor/webauthn/identity/start";
+export const CompleteWebauthnRegistrationStep1Path = basePath + "/api/secondfactor/webauthn/identity/finish";
+export const CompleteWebauthnRegistrationStep2Path = basePath + "/api/secondfactor/webauthn/register";
+
+export const InitiateWebauthnSignInPath = basePath + "/api/secondfactor/webauthn/assertion/options";
+export const CompleteWebauthnSignInPath = basePath + "/api/secondfactor/webauthn";
+
 export const CompletePushNotificationSignInPath = basePath + "/api/secondfactor/duo";
 export const CompleteTOTPSignInPath = basePath + "/api/secondfactor/totp";
+export const CompleteBackupCodeSignInPath = basePath + "/api/secondfactor/backup_code";
+export const ApproveSecondFactorResetPath = basePath + "/api/secondfactor/reset/approve";
 
 export const InitiateResetPasswordPath = basePath + "/api/reset-password/identity/start";
 export const CompleteResetPasswordPath = basePath + "/api/reset-password/identity/finish";
 // Do the password reset during completion.
 export const ResetPasswordPath = basePath + "/api/reset-password";
+export const ResetPasswordPolicyPath = basePath + "/api/reset-password/policy";
+export const ResetPasswordStrengthPath = basePath + "/api/reset-password/strength";
 
 export const LogoutPath = basePath + "/api/logout";
 export const StatePath = basePath + "/api/state";
+export const PortalPath = basePath + "/api/portal";
 export const UserInfoPath = basePath + "/api/user/info";
 export const UserInfo2FAMethodPath = basePath + "/api/user/info/2fa_method";
+export const UserStreamPath = basePath + "/api/user/stream";
+export const UserBackupCodesPath = basePath + "/api/user/backup_codes";
+export const UserAnnouncementAcknowledgmentPath = basePath + "/api/user/announcement/acknowledgment";
 
 export const ConfigurationPath = basePath + "/api/configuration";
 
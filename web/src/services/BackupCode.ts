@@ -0,0 +1,30 @@
+import { CompleteBackupCodeSignInPath, UserBackupCodesPath } from "@services/Api";
+import { Post, PostWithOptionalResponse } from "@services/Client";
+import { SignInResponse } from "@services/SignIn";
+
+interface CompleteBackupCodeSignInBody {
+    code: string;
+    targetURL?: string;
+    state?: string;
+}
+
+interface BackupCodesPayload {
+    codes: string[];
+}
+
+export function completeBackupCodeSignIn(code: string, targetURL: string | undefined, state?: string) {
+    const body: CompleteBackupCodeSignInBody = { code };
+    if (targetURL) {
+        body.targetURL = targetURL;
+    }
+    if (state) {
+        body.state = state;
+    }
+    return PostWithOptionalResponse<SignInResponse>(CompleteBackupCodeSignInPath, body);
+}
+
+// generateBackupCodes replaces the backup codes of the user, the new codes are only returned once.
+export async function generateBackupCodes(): Promise<string[]> {
+    const res = await Post<BackupCodesPayload>(UserBackupCodesPath);
+    return res.codes;
+}
@@ -1,15 +1,20 @@
-import { Configuration } from "@models/Configuration";
+import { Announcement, Configuration } from "@models/Configuration";
 import { ConfigurationPath } from "@services/Api";
 import { Get } from "@services/Client";
 import { toEnum, Method2FA } from "@services/UserPreferences";
 
-interface ConfigurationPayload {
+export interface ConfigurationPayload {
     available_methods: Method2FA[];
     second_factor_enabled: boolean;
     totp_period: number;
+    login_banner?: string;
+    announcement?: Announcement;
 }
 
-export async function getConfiguration(): Promise<Configuration> {
-    const config = await Get<ConfigurationPayload>(ConfigurationPath);
+export function toConfiguration(config: ConfigurationPayload): Configuration {
     return { ...config, available_methods: new Set(config.available_methods.map(toEnum)) };
 }
+
+export async function getConfiguration(): Promise<Configuration> {
+    return toConfiguration(await Get<ConfigurationPayload>(ConfigurationPath));
+}
@@ -4,6 +4,7 @@ import { Post, Get } from "@services/Client";
 interface ConsentPostRequestBody {
     client_id: string;
     accept_or_reject: "accept" | "reject";
+    remember_consent: boolean;
 }
 
 interface ConsentPostResponseBody {
@@ -15,6 +16,7 @@ interface ConsentGetResponseBody {
     client_description: string;
     scopes: Scope[];
     audience: Audience[];
+    pre_authorized: boolean;
 }
 
 interface Scope {
@@ -31,12 +33,16 @@ export function getRequestedScopes() {
     return Get<ConsentGetResponseBody>(ConsentPath);
 }
 
-export function acceptConsent(clientID: string) {
-    const body: ConsentPostRequestBody = { client_id: clientID, accept_or_reject: "accept" };
+export function acceptConsent(clientID: string, rememberConsent: boolean) {
+    const body: ConsentPostRequestBody = {
+        client_id: clientID,
+        accept_or_reject: "accept",
+        remember_consent: rememberConsent,
+    };
     return Post<ConsentPostResponseBody>(ConsentPath, body);
 }
 
 export function rejectConsent(clientID: string) {
-    const body: ConsentPostRequestBody = { client_id: clientID, accept_or_reject: "reject" };
+    const body: ConsentPostRequestBody = { client_id: clientID, accept_or_reject: "reject", remember_consent: false };
     return Post<ConsentPostResponseBody>(ConsentPath, body);
 }
@@ -7,6 +7,7 @@ interface PostFirstFactorBody {
     password: string;
     keepMeLoggedIn: boolean;
     targetURL?: string;
+    state?: string;
     requestMethod?: string;
 }
 
@@ -16,6 +17,7 @@ export async function postFirstFactor(
     rememberMe: boolean,
     targetURL?: string,
     requestMethod?: string,
+    state?: string,
 ) {
     const data: PostFirstFactorBody = {
         username,
@@ -27,6 +29,10 @@ export async function postFirstFactor(
         data.targetURL = targetURL;
     }
 
+    if (state) {
+        data.state = state;
+    }
+
     if (requestMethod) {
         data.requestMethod = requestMethod;
     }
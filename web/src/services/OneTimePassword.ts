@@ -5,12 +5,16 @@ import { SignInResponse } from "@services/SignIn";
 interface CompleteU2FSigninBody {
     token: string;
     targetURL?: string;
+    state?: string;
 }
 
-export function completeTOTPSignIn(passcode: string, targetURL: string | undefined) {
+export function completeTOTPSignIn(passcode: string, targetURL: string | undefined, state?: string) {
     const body: CompleteU2FSigninBody = { token: `${passcode}` };
     if (targetURL) {
         body.targetURL = targetURL;
     }
+    if (state) {
+        body.state = state;
+    }
     return PostWithOptionalResponse<SignInResponse>(CompleteTOTPSignInPath, body);
 }
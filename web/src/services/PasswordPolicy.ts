@@ -0,0 +1,11 @@
+import { PasswordPolicy, PasswordStrength } from "@models/PasswordPolicy";
+import { ResetPasswordPolicyPath, ResetPasswordStrengthPath } from "@services/Api";
+import { Get, Post } from "@services/Client";
+
+export async function getPasswordPolicy(): Promise<PasswordPolicy> {
+    return Get<PasswordPolicy>(ResetPasswordPolicyPath);
+}
+
+export async function getPasswordStrength(password: string): Promise<PasswordStrength> {
+    return Post<PasswordStrength>(ResetPasswordStrengthPath, { password });
+}
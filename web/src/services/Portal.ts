@@ -0,0 +1,35 @@
+import { Configuration } from "@models/Configuration";
+import { UserInfo } from "@models/UserInfo";
+import { PortalPath } from "@services/Api";
+import { Get } from "@services/Client";
+import { ConfigurationPayload, toConfiguration } from "@services/Configuration";
+import { AutheliaState } from "@services/State";
+import { toUserInfo, UserInfoPayload } from "@services/UserPreferences";
+
+interface PortalPayload {
+    state: AutheliaState;
+    configuration?: ConfigurationPayload;
+    user_info?: UserInfoPayload;
+    login_banner?: string;
+}
+
+export interface Portal {
+    state: AutheliaState;
+    configuration?: Configuration;
+    userInfo?: UserInfo;
+    loginBanner?: string;
+}
+
+// getPortal fetches the state, the configuration and the user info in a single request. The configuration and the user
+// info are only returned to authenticated users whereas the login banner is returned to everyone. The response is
+// revalidated by the browser with its ETag.
+export async function getPortal(): Promise<Portal> {
+    const portal = await Get<PortalPayload>(PortalPath);
+
+    return {
+        state: portal.state,
+        configuration: portal.configuration ? toConfiguration(portal.configuration) : undefined,
+        userInfo: portal.user_info ? toUserInfo(portal.user_info) : undefined,
+        loginBanner: portal.login_banner,
+    };
+}
@@ -4,12 +4,16 @@ import { SignInResponse } from "@services/SignIn";
 
 interface CompleteU2FSigninBody {
     targetURL?: string;
+    state?: string;
 }
 
-export function completePushNotificationSignIn(targetURL: string | undefined) {
+export function completePushNotificationSignIn(targetURL: string | undefined, state?: string) {
     const body: CompleteU2FSigninBody = {};
     if (targetURL) {
         body.targetURL = targetURL;
     }
+    if (state) {
+        body.state = state;
+    }
     return PostWithOptionalResponse<SignInResponse>(CompletePushNotificationSignInPath, body);
 }
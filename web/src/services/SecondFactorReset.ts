@@ -0,0 +1,6 @@
+import { ApproveSecondFactorResetPath } from "@services/Api";
+import { PostWithOptionalResponse } from "@services/Client";
+
+export async function approveSecondFactorReset(id: string, token: string) {
+    return PostWithOptionalResponse(ApproveSecondFactorResetPath, { id, token });
+}
@@ -21,12 +21,16 @@ export async function initiateU2FSignin() {
 interface CompleteU2FSigninBody {
     signResponse: u2fApi.SignResponse;
     targetURL?: string;
+    state?: string;
 }
 
-export function completeU2FSignin(signResponse: u2fApi.SignResponse, targetURL: string | undefined) {
+export function completeU2FSignin(signResponse: u2fApi.SignResponse, targetURL: string | undefined, state?: string) {
     const body: CompleteU2FSigninBody = { signResponse };
     if (targetURL) {
         body.targetURL = targetURL;
     }
+    if (state) {
+        body.state = state;
+    }
     return PostWithOptionalResponse<SignInResponse>(CompleteU2FSignInPath, body);
 }
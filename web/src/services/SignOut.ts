@@ -1,7 +1,13 @@
 import { LogoutPath } from "@services/Api";
 import { PostWithOptionalResponse } from "@services/Client";
 
-export type SignOutResponse = { safeTargetURL: boolean } | undefined;
+export type SignOutResponse =
+    | {
+          safeTargetURL: boolean;
+          frontChannelLogoutURIs?: string[];
+          postLogoutRedirectURI?: string;
+      }
+    | undefined;
 
 export type SignOutBody = {
     targetURL?: string;
@@ -1,15 +1,21 @@
 import { SecondFactorMethod } from "@models/Methods";
-import { UserInfo } from "@models/UserInfo";
+import { RegulationInfo, TOTPDevice, UserInfo } from "@models/UserInfo";
 import { UserInfoPath, UserInfo2FAMethodPath } from "@services/Api";
 import { Get, PostWithOptionalResponse } from "@services/Client";
 
-export type Method2FA = "u2f" | "totp" | "mobile_push";
+export type Method2FA = "u2f" | "totp" | "mobile_push" | "webauthn";
 
 export interface UserInfoPayload {
     display_name: string;
     method: Method2FA;
     has_u2f: boolean;
     has_totp: boolean;
+    totp_devices?: TOTPDevice[];
+    has_webauthn: boolean;
+    backup_codes_remaining: number;
+    first_factor_regulation: RegulationInfo;
+    second_factor_regulation: RegulationInfo;
+    warnings?: string[];
 }
 
 export interface MethodPreferencePayload {
@@ -24,6 +30,8 @@ export function toEnum(method: Method2FA): SecondFactorMethod {
             return SecondFactorMethod.TOTP;
         case "mobile_push":
             return SecondFactorMethod.MobilePush;
+        case "webauthn":
+            return SecondFactorMethod.Webauthn;
     }
 }
 
@@ -35,14 +43,19 @@ export function toString(method: SecondFactorMethod): Method2FA {
             return "totp";
         case SecondFactorMethod.MobilePush:
             return "mobile_push";
+        case SecondFactorMethod.Webauthn:
+            return "webauthn";
     }
 }
 
-export async function getUserPreferences(): Promise<UserInfo> {
-    const res = await Get<UserInfoPayload>(UserInfoPath);
+export function toUserInfo(res: UserInfoPayload): UserInfo {
     return { ...res, method: toEnum(res.method) };
 }
 
+export async function getUserPreferences(): Promise<UserInfo> {
+    return toUserInfo(await Get<UserInfoPayload>(UserInfoPath));
+}
+
 export function setPreferred2FAMethod(method: SecondFactorMethod) {
     return PostWithOptionalResponse(UserInfo2FAMethodPath, { method: toString(method) } as MethodPreferencePayload);
 }
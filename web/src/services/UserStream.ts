@@ -0,0 +1,25 @@
+import { UserStreamPath } from "@services/Api";
+
+export enum UserStreamEventType {
+    PushRequested = "push_requested",
+    SessionsRevoked = "sessions_revoked",
+}
+
+export interface UserStreamEvent {
+    type: UserStreamEventType;
+    time: number;
+}
+
+// openUserStream listens to the events of the logged in user, the stream is closed by the server once the sessions
+// of the user are revoked.
+export function openUserStream(onEvent: (event: UserStreamEvent) => void): EventSource {
+    const source = new EventSource(UserStreamPath, { withCredentials: true });
+
+    const listener = (e: Event) => onEvent(JSON.parse((e as MessageEvent).data) as UserStreamEvent);
+
+    Object.values(UserStreamEventType).forEach((type) => source.addEventListener(type, listener));
+
+    source.addEventListener(UserStreamEventType.SessionsRevoked, () => source.close());
+
+    return source;
+}
@@ -1,24 +1,57 @@
-import React from "react";
+import React, { useState } from "react";
 
-import { Grid, makeStyles, Button } from "@material-ui/core";
+import { Grid, makeStyles, Button, Typography } from "@material-ui/core";
 import { useHistory } from "react-router";
 
+import Banner from "@components/Banner";
 import { LogoutRoute as SignOutRoute } from "@constants/Routes";
+import { useNotifications } from "@hooks/NotificationsContext";
 import LoginLayout from "@layouts/LoginLayout";
+import { Announcement } from "@models/Configuration";
+import { acknowledgeAnnouncement } from "@services/Announcement";
+import { generateBackupCodes } from "@services/BackupCode";
 import Authenticated from "@views/LoginPortal/Authenticated";
 
 export interface Props {
     name: string;
+    announcement?: Announcement;
+    // Whether the user is authenticated with two factors, which is required to generate backup codes.
+    twoFactor: boolean;
+
+    onAnnouncementAcknowledged: () => void;
 }
 
 const AuthenticatedView = function (props: Props) {
     const style = useStyles();
     const history = useHistory();
+    const { createErrorNotification } = useNotifications();
+    const [backupCodes, setBackupCodes] = useState<string[]>();
 
     const handleLogoutClick = () => {
         history.push(SignOutRoute);
     };
 
+    const handleAcknowledgeClick = async (id: string) => {
+        try {
+            await acknowledgeAnnouncement(id);
+            props.onAnnouncementAcknowledged();
+        } catch (err) {
+            console.error(err);
+            createErrorNotification("There was an issue acknowledging the announcement");
+        }
+    };
+
+    const handleGenerateBackupCodesClick = async () => {
+        try {
+            setBackupCodes(await generateBackupCodes());
+        } catch (err) {
+            console.error(err);
+            createErrorNotification("There was an issue generating the backup codes");
+        }
+    };
+
+    const announcement = props.announcement;
+
     return (
         <LoginLayout id="authenticated-stage" title={`Hi ${props.name}`} showBrand>
             <Grid container>
@@ -26,7 +59,38 @@ const AuthenticatedView = function (props: Props) {
                     <Button color="secondary" onClick={handleLogoutClick} id="logout-button">
                         Logout
                     </Button>
+                    {props.twoFactor ? (
+                        <Button color="secondary" onClick={handleGenerateBackupCodesClick} id="backup-codes-button">
+                            Generate backup codes
+                        </Button>
+                    ) : null}
                 </Grid>
+                {backupCodes ? (
+                    <Grid item xs={12} className={style.mainContainer} id="backup-codes">
+                        <Typography variant="body2">
+                            Keep these codes in a safe place, each of them can be used once when your devices are
+                            unavailable. They won't be displayed again and replace your previous codes.
+                        </Typography>
+                        {backupCodes.map((code) => (
+                            <Typography key={code} variant="body1" className={style.backupCode}>
+                                {code}
+                            </Typography>
+                        ))}
+                    </Grid>
+                ) : null}
+                {announcement && !announcement.acknowledged ? (
+                    <Grid item xs={12}>
+                        <Banner id="announcement" message={announcement.message}>
+                            <Button
+                                color="primary"
+                                onClick={() => handleAcknowledgeClick(announcement.id)}
+                                id="acknowledge-announcement-button"
+                            >
+                                Acknowledge
+                            </Button>
+                        </Banner>
+                    </Grid>
+                ) : null}
                 <Grid item xs={12} className={style.mainContainer}>
                     <Authenticated />
                 </Grid>
@@ -45,4 +109,7 @@ const useStyles = makeStyles((theme) => ({
         marginTop: theme.spacing(2),
         marginBottom: theme.spacing(2),
     },
+    backupCode: {
+        fontFamily: "monospace",
+    },
 }));
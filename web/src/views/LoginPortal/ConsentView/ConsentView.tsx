@@ -1,6 +1,17 @@
-import React, { useEffect, Fragment, ReactNode } from "react";
+import React, { useEffect, useState, Fragment, ReactNode } from "react";
 
-import { Button, Grid, List, ListItem, ListItemIcon, ListItemText, Tooltip, makeStyles } from "@material-ui/core";
+import {
+    Button,
+    Checkbox,
+    FormControlLabel,
+    Grid,
+    List,
+    ListItem,
+    ListItemIcon,
+    ListItemText,
+    Tooltip,
+    makeStyles,
+} from "@material-ui/core";
 import { AccountBox, CheckBox, Contacts, Drafts, Group } from "@material-ui/icons";
 import { useHistory } from "react-router-dom";
 
@@ -34,6 +45,7 @@ const ConsentView = function (props: Props) {
     const redirect = useRedirector();
     const { createErrorNotification, resetNotification } = useNotifications();
     const [resp, fetch, , err] = useRequestedScopes();
+    const [rememberConsent, setRememberConsent] = useState(false);
 
     useEffect(() => {
         if (err) {
@@ -51,7 +63,7 @@ const ConsentView = function (props: Props) {
         if (!resp) {
             return;
         }
-        const res = await acceptConsent(resp.client_id);
+        const res = await acceptConsent(resp.client_id, rememberConsent);
         if (res.redirect_uri) {
             redirect(res.redirect_uri);
         } else {
@@ -71,8 +83,17 @@ const ConsentView = function (props: Props) {
         }
     };
 
+    // The consent the user remembered is given again without showing the consent screen.
+    /* eslint-disable react-hooks/exhaustive-deps */
+    useEffect(() => {
+        if (resp && resp.pre_authorized) {
+            handleAcceptConsent();
+        }
+    }, [resp]);
+    /* eslint-enable react-hooks/exhaustive-deps */
+
     return (
-        <ComponentOrLoading ready={resp !== undefined}>
+        <ComponentOrLoading ready={resp !== undefined && !resp.pre_authorized}>
             <LoginLayout id="consent-stage" title={`Permissions Request`} showBrand>
                 <Grid container>
                     <Grid item xs={12}>
@@ -96,6 +117,19 @@ const ConsentView = function (props: Props) {
                             </List>
                         </div>
                     </Grid>
+                    <Grid item xs={12}>
+                        <FormControlLabel
+                            control={
+                                <Checkbox
+                                    id="remember-consent-checkbox"
+                                    checked={rememberConsent}
+                                    onChange={(ev) => setRememberConsent(ev.target.checked)}
+                                    color="primary"
+                                />
+                            }
+                            label="Remember this consent"
+                        />
+                    </Grid>
                     <Grid item xs={12}>
                         <Grid container spacing={1}>
                             <Grid item xs={6}>
@@ -4,8 +4,10 @@ import { makeStyles, Grid, Button, FormControlLabel, Checkbox, Link } from "@mat
 import classnames from "classnames";
 import { useHistory } from "react-router";
 
+import Banner from "@components/Banner";
 import FixedTextField from "@components/FixedTextField";
 import { ResetPasswordStep1Route } from "@constants/Routes";
+import { useLoginState } from "@hooks/LoginState";
 import { useNotifications } from "@hooks/NotificationsContext";
 import { useRedirectionURL } from "@hooks/RedirectionURL";
 import { useRequestMethod } from "@hooks/RequestMethod";
@@ -16,6 +18,7 @@ export interface Props {
     disabled: boolean;
     rememberMe: boolean;
     resetPassword: boolean;
+    loginBanner?: string;
 
     onAuthenticationStart: () => void;
     onAuthenticationFailure: () => void;
@@ -27,6 +30,7 @@ const FirstFactorForm = function (props: Props) {
     const history = useHistory();
     const redirectionURL = useRedirectionURL();
     const requestMethod = useRequestMethod();
+    const loginState = useLoginState();
 
     const [rememberMe, setRememberMe] = useState(false);
     const [username, setUsername] = useState("");
@@ -62,11 +66,26 @@ const FirstFactorForm = function (props: Props) {
 
         props.onAuthenticationStart();
         try {
-            const res = await postFirstFactor(username, password, rememberMe, redirectionURL, requestMethod);
+            const res = await postFirstFactor(
+                username,
+                password,
+                rememberMe,
+                redirectionURL,
+                requestMethod,
+                loginState,
+            );
             props.onAuthenticationSuccess(res ? res.redirect : undefined);
         } catch (err) {
             console.error(err);
-            createErrorNotification("Incorrect username or password.");
+            if (err.message.includes("Your password has expired")) {
+                createErrorNotification("Your password has expired, please reset it.");
+            } else if (err.message.includes("Your account is disabled")) {
+                createErrorNotification("Your account is disabled.");
+            } else if (err.message.includes("Your account is locked out")) {
+                createErrorNotification("Your account is locked out, please retry later.");
+            } else {
+                createErrorNotification("Incorrect username or password.");
+            }
             props.onAuthenticationFailure();
             setPassword("");
             passwordRef.current.focus();
@@ -80,6 +99,11 @@ const FirstFactorForm = function (props: Props) {
     return (
         <LoginLayout id="first-factor-stage" title="Sign in" showBrand>
             <Grid container spacing={2}>
+                {props.loginBanner ? (
+                    <Grid item xs={12}>
+                        <Banner id="login-banner" message={props.loginBanner} />
+                    </Grid>
+                ) : null}
                 <Grid item xs={12}>
                     <FixedTextField
                         // TODO (PR: #806, Issue: #511) potentially refactor
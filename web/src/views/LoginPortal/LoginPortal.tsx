@@ -10,15 +10,16 @@ import {
     SecondFactorU2FRoute,
     AuthenticatedRoute,
 } from "@constants/Routes";
-import { useConfiguration } from "@hooks/Configuration";
+import { useLoginState } from "@hooks/LoginState";
 import { useNotifications } from "@hooks/NotificationsContext";
+import { usePortal } from "@hooks/Portal";
 import { useRedirectionURL } from "@hooks/RedirectionURL";
 import { useRedirector } from "@hooks/Redirector";
 import { useRequestMethod } from "@hooks/RequestMethod";
-import { useAutheliaState } from "@hooks/State";
-import { useUserPreferences as userUserInfo } from "@hooks/UserInfo";
+import { useUserStream } from "@hooks/UserStream";
 import { SecondFactorMethod } from "@models/Methods";
 import { AuthenticationLevel } from "@services/State";
+import { UserStreamEvent, UserStreamEventType } from "@services/UserStream";
 import LoadingPage from "@views/LoadingPage/LoadingPage";
 import AuthenticatedView from "@views/LoginPortal/AuthenticatedView/AuthenticatedView";
 import FirstFactorForm from "@views/LoginPortal/FirstFactor/FirstFactorForm";
@@ -34,28 +35,24 @@ const LoginPortal = function (props: Props) {
     const location = useLocation();
     const redirectionURL = useRedirectionURL();
     const requestMethod = useRequestMethod();
-    const { createErrorNotification } = useNotifications();
+    const loginState = useLoginState();
+    const { createErrorNotification, createInfoNotification } = useNotifications();
     const [firstFactorDisabled, setFirstFactorDisabled] = useState(true);
     const redirector = useRedirector();
 
-    const [state, fetchState, , fetchStateError] = useAutheliaState();
-    const [userInfo, fetchUserInfo, , fetchUserInfoError] = userUserInfo();
-    const [configuration, fetchConfiguration, , fetchConfigurationError] = useConfiguration();
+    // The state, the configuration and the user info are fetched together, the configuration and the user info are
+    // only available once the user is authenticated.
+    const [portal, fetchPortal, , fetchPortalError] = usePortal();
+    const state = portal?.state;
+    const userInfo = portal?.userInfo;
+    const configuration = portal?.configuration;
 
     const redirect = useCallback((url: string) => history.push(url), [history]);
 
-    // Fetch the state when portal is mounted.
+    // Fetch the portal when it's mounted.
     useEffect(() => {
-        fetchState();
-    }, [fetchState]);
-
-    // Fetch preferences and configuration when user is authenticated.
-    useEffect(() => {
-        if (state && state.authentication_level >= AuthenticationLevel.OneFactor) {
-            fetchUserInfo();
-            fetchConfiguration();
-        }
-    }, [state, fetchUserInfo, fetchConfiguration]);
+        fetchPortal();
+    }, [fetchPortal]);
 
     // Enable first factor when user is unauthenticated.
     useEffect(() => {
@@ -64,32 +61,36 @@ const LoginPortal = function (props: Props) {
         }
     }, [state, setFirstFactorDisabled]);
 
-    // Display an error when state fetching fails
-    useEffect(() => {
-        if (fetchStateError) {
-            createErrorNotification("There was an issue fetching the current user state");
-        }
-    }, [fetchStateError, createErrorNotification]);
-
-    // Display an error when configuration fetching fails
-    useEffect(() => {
-        if (fetchConfigurationError) {
-            createErrorNotification("There was an issue retrieving global configuration");
-        }
-    }, [fetchConfigurationError, createErrorNotification]);
+    // Listen to the events of the user instead of polling the state once authenticated.
+    useUserStream(
+        state !== undefined && state.authentication_level >= AuthenticationLevel.OneFactor,
+        useCallback(
+            (event: UserStreamEvent) => {
+                if (event.type === UserStreamEventType.PushRequested) {
+                    createInfoNotification("A push notification has been sent to your device, it's pending approval");
+                } else if (event.type === UserStreamEventType.SessionsRevoked) {
+                    createErrorNotification("Your sessions have been revoked, you need to sign in again");
+                    fetchPortal();
+                }
+            },
+            [createInfoNotification, createErrorNotification, fetchPortal],
+        ),
+    );
 
-    // Display an error when preferences fetching fails
+    // Display an error when portal fetching fails
     useEffect(() => {
-        if (fetchUserInfoError) {
-            createErrorNotification("There was an issue retrieving user preferences");
+        if (fetchPortalError) {
+            createErrorNotification("There was an issue fetching the current user state");
         }
-    }, [fetchUserInfoError, createErrorNotification]);
+    }, [fetchPortalError, createErrorNotification]);
 
     // Redirect to the correct stage if not enough authenticated
     useEffect(() => {
         if (state) {
+            const methodSuffix = requestMethod ? `&rm=${requestMethod}` : "";
+            const stateSuffix = loginState ? `&state=${encodeURIComponent(loginState)}` : "";
             const redirectionSuffix = redirectionURL
-                ? `?rd=${encodeURIComponent(redirectionURL)}${requestMethod ? `&rm=${requestMethod}` : ""}`
+                ? `?rd=${encodeURIComponent(redirectionURL)}${methodSuffix}${stateSuffix}`
                 : "";
 
             if (state.authentication_level === AuthenticationLevel.Unauthenticated) {
@@ -109,7 +110,7 @@ const LoginPortal = function (props: Props) {
                 }
             }
         }
-    }, [state, redirectionURL, requestMethod, redirect, userInfo, setFirstFactorDisabled, configuration]);
+    }, [state, redirectionURL, requestMethod, loginState, redirect, userInfo, setFirstFactorDisabled, configuration]);
 
     const handleAuthSuccess = async (redirectionURL: string | undefined) => {
         if (redirectionURL) {
@@ -117,7 +118,7 @@ const LoginPortal = function (props: Props) {
             redirector(redirectionURL);
         } else {
             // Refresh state
-            fetchState();
+            fetchPortal();
         }
     };
 
@@ -134,6 +135,7 @@ const LoginPortal = function (props: Props) {
                         disabled={firstFactorDisabled}
                         rememberMe={props.rememberMe}
                         resetPassword={props.resetPassword}
+                        loginBanner={portal?.loginBanner}
                         onAuthenticationStart={() => setFirstFactorDisabled(true)}
                         onAuthenticationFailure={() => setFirstFactorDisabled(false)}
                         onAuthenticationSuccess={handleAuthSuccess}
@@ -146,13 +148,20 @@ const LoginPortal = function (props: Props) {
                         authenticationLevel={state.authentication_level}
                         userInfo={userInfo}
                         configuration={configuration}
-                        onMethodChanged={() => fetchUserInfo()}
+                        onMethodChanged={() => fetchPortal()}
                         onAuthenticationSuccess={handleAuthSuccess}
                     />
                 ) : null}
             </Route>
             <Route path={AuthenticatedRoute} exact>
-                {userInfo ? <AuthenticatedView name={userInfo.display_name} /> : null}
+                {userInfo ? (
+                    <AuthenticatedView
+                        name={userInfo.display_name}
+                        announcement={configuration?.announcement}
+                        twoFactor={state?.authentication_level === AuthenticationLevel.TwoFactor}
+                        onAnnouncementAcknowledged={() => fetchPortal()}
+                    />
+                ) : null}
             </Route>
             {/* By default we route to first factor page */}
             <Route path="/">
@@ -0,0 +1,95 @@
+import React, { useState } from "react";
+
+import { Button, Grid } from "@material-ui/core";
+
+import FixedTextField from "@components/FixedTextField";
+import { useLoginState } from "@hooks/LoginState";
+import { useRedirectionURL } from "@hooks/RedirectionURL";
+import { completeBackupCodeSignIn } from "@services/BackupCode";
+import { AuthenticationLevel } from "@services/State";
+import MethodContainer, { State as MethodContainerState } from "@views/LoginPortal/SecondFactor/MethodContainer";
+
+export interface Props {
+    id: string;
+    authenticationLevel: AuthenticationLevel;
+    remaining: number;
+
+    onSignInError: (err: Error) => void;
+    onSignInSuccess: (redirectURL: string | undefined) => void;
+}
+
+const BackupCodeMethod = function (props: Props) {
+    const [code, setCode] = useState("");
+    const [inProgress, setInProgress] = useState(false);
+    const redirectionURL = useRedirectionURL();
+    const loginState = useLoginState();
+
+    const handleSignIn = async () => {
+        if (code === "" || inProgress) {
+            return;
+        }
+
+        setInProgress(true);
+        try {
+            const res = await completeBackupCodeSignIn(code, redirectionURL, loginState);
+            props.onSignInSuccess(res ? res.redirect : undefined);
+        } catch (err) {
+            console.error(err);
+            props.onSignInError(new Error("The backup code might be wrong or already used"));
+        }
+        setCode("");
+        setInProgress(false);
+    };
+
+    let methodState = MethodContainerState.METHOD;
+    if (props.authenticationLevel === AuthenticationLevel.TwoFactor) {
+        methodState = MethodContainerState.ALREADY_AUTHENTICATED;
+    } else if (props.remaining === 0) {
+        methodState = MethodContainerState.NOT_REGISTERED;
+    }
+
+    return (
+        <MethodContainer
+            id={props.id}
+            title="Backup Code"
+            explanation={`Enter one of your backup codes, ${props.remaining} remaining`}
+            registered={props.remaining > 0}
+            state={methodState}
+        >
+            <Grid container spacing={2}>
+                <Grid item xs={12}>
+                    <FixedTextField
+                        id="backup-code-textfield"
+                        label="Backup code"
+                        variant="outlined"
+                        value={code}
+                        disabled={inProgress}
+                        fullWidth
+                        autoCapitalize="none"
+                        autoComplete="off"
+                        onChange={(v) => setCode(v.target.value)}
+                        onKeyPress={(ev) => {
+                            if (ev.key === "Enter") {
+                                handleSignIn();
+                            }
+                        }}
+                    />
+                </Grid>
+                <Grid item xs={12}>
+                    <Button
+                        id="backup-code-sign-in-button"
+                        variant="contained"
+                        color="primary"
+                        fullWidth
+                        disabled={inProgress}
+                        onClick={handleSignIn}
+                    >
+                        Sign in
+                    </Button>
+                </Grid>
+            </Grid>
+        </MethodContainer>
+    );
+};
+
+export default BackupCodeMethod;
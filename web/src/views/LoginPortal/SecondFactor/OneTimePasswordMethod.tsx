@@ -1,5 +1,6 @@
 import React, { useState, useEffect, useCallback } from "react";
 
+import { useLoginState } from "@hooks/LoginState";
 import { useRedirectionURL } from "@hooks/RedirectionURL";
 import { completeTOTPSignIn } from "@services/OneTimePassword";
 import { AuthenticationLevel } from "@services/State";
@@ -30,6 +31,7 @@ const OneTimePasswordMethod = function (props: Props) {
         props.authenticationLevel === AuthenticationLevel.TwoFactor ? State.Success : State.Idle,
     );
     const redirectionURL = useRedirectionURL();
+    const loginState = useLoginState();
 
     const { onSignInSuccess, onSignInError } = props;
     /* eslint-disable react-hooks/exhaustive-deps */
@@ -50,7 +52,7 @@ const OneTimePasswordMethod = function (props: Props) {
 
         try {
             setState(State.InProgress);
-            const res = await completeTOTPSignIn(passcodeStr, redirectionURL);
+            const res = await completeTOTPSignIn(passcodeStr, redirectionURL, loginState);
             setState(State.Success);
             onSignInSuccessCallback(res ? res.redirect : undefined);
         } catch (err) {
@@ -59,7 +61,14 @@ const OneTimePasswordMethod = function (props: Props) {
             setState(State.Failure);
         }
         setPasscode("");
-    }, [passcode, onSignInErrorCallback, onSignInSuccessCallback, redirectionURL, props.authenticationLevel]);
+    }, [
+        passcode,
+        onSignInErrorCallback,
+        onSignInSuccessCallback,
+        redirectionURL,
+        loginState,
+        props.authenticationLevel,
+    ]);
 
     // Set successful state if user is already authenticated.
     useEffect(() => {
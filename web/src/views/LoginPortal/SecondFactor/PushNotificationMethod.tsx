@@ -5,6 +5,7 @@ import { Button, makeStyles } from "@material-ui/core";
 import FailureIcon from "@components/FailureIcon";
 import PushNotificationIcon from "@components/PushNotificationIcon";
 import SuccessIcon from "@components/SuccessIcon";
+import { useLoginState } from "@hooks/LoginState";
 import { useIsMountedRef } from "@hooks/Mounted";
 import { useRedirectionURL } from "@hooks/RedirectionURL";
 import { completePushNotificationSignIn } from "@services/PushNotification";
@@ -29,6 +30,7 @@ const PushNotificationMethod = function (props: Props) {
     const style = useStyles();
     const [state, setState] = useState(State.SignInInProgress);
     const redirectionURL = useRedirectionURL();
+    const loginState = useLoginState();
     const mounted = useIsMountedRef();
 
     const { onSignInSuccess, onSignInError } = props;
@@ -44,7 +46,7 @@ const PushNotificationMethod = function (props: Props) {
 
         try {
             setState(State.SignInInProgress);
-            const res = await completePushNotificationSignIn(redirectionURL);
+            const res = await completePushNotificationSignIn(redirectionURL, loginState);
             // If the request was initiated and the user changed 2FA method in the meantime,
             // the process is interrupted to avoid updating state of unmounted component.
             if (!mounted.current) return;
@@ -63,7 +65,15 @@ const PushNotificationMethod = function (props: Props) {
             onSignInErrorCallback(new Error("There was an issue completing sign in process"));
             setState(State.Failure);
         }
-    }, [onSignInErrorCallback, onSignInSuccessCallback, setState, redirectionURL, mounted, props.authenticationLevel]);
+    }, [
+        onSignInErrorCallback,
+        onSignInSuccessCallback,
+        setState,
+        redirectionURL,
+        loginState,
+        mounted,
+        props.authenticationLevel,
+    ]);
 
     useEffect(() => {
         signInFunc();
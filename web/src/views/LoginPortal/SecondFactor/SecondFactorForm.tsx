@@ -1,7 +1,7 @@
-import React, { useState, useEffect } from "react";
+import React, { useState, useEffect, Fragment } from "react";
 
 import { Grid, makeStyles, Button } from "@material-ui/core";
-import { useHistory, Switch, Route, Redirect } from "react-router";
+import { useHistory, useLocation, Switch, Route, Redirect } from "react-router";
 import u2fApi from "u2f-api";
 
 import {
@@ -10,6 +10,7 @@ import {
     SecondFactorPushRoute,
     SecondFactorU2FRoute,
     SecondFactorRoute,
+    SecondFactorBackupCodeRoute,
 } from "@constants/Routes";
 import { useNotifications } from "@hooks/NotificationsContext";
 import LoginLayout from "@layouts/LoginLayout";
@@ -19,6 +20,7 @@ import { UserInfo } from "@models/UserInfo";
 import { initiateTOTPRegistrationProcess, initiateU2FRegistrationProcess } from "@services/RegisterDevice";
 import { AuthenticationLevel } from "@services/State";
 import { setPreferred2FAMethod } from "@services/UserPreferences";
+import BackupCodeMethod from "@views/LoginPortal/SecondFactor/BackupCodeMethod";
 import MethodSelectionDialog from "@views/LoginPortal/SecondFactor/MethodSelectionDialog";
 import OneTimePasswordMethod from "@views/LoginPortal/SecondFactor/OneTimePasswordMethod";
 import PushNotificationMethod from "@views/LoginPortal/SecondFactor/PushNotificationMethod";
@@ -39,6 +41,7 @@ export interface Props {
 const SecondFactorForm = function (props: Props) {
     const style = useStyles();
     const history = useHistory();
+    const location = useLocation();
     const [methodSelectionOpen, setMethodSelectionOpen] = useState(false);
     const { createInfoNotification, createErrorNotification } = useNotifications();
     const [registrationInProgress, setRegistrationInProgress] = useState(false);
@@ -106,6 +109,18 @@ const SecondFactorForm = function (props: Props) {
                     <Button color="secondary" onClick={handleMethodSelectionClick} id="methods-button">
                         Methods
                     </Button>
+                    {props.userInfo.backup_codes_remaining > 0 ? (
+                        <Fragment>
+                            {" | "}
+                            <Button
+                                color="secondary"
+                                onClick={() => history.push(SecondFactorBackupCodeRoute + location.search)}
+                                id="backup-code-method-button"
+                            >
+                                Backup code
+                            </Button>
+                        </Fragment>
+                    ) : null}
                 </Grid>
                 <Grid item xs={12} className={style.methodContainer}>
                     <Switch>
@@ -140,6 +155,15 @@ const SecondFactorForm = function (props: Props) {
                                 onSignInSuccess={props.onAuthenticationSuccess}
                             />
                         </Route>
+                        <Route path={SecondFactorBackupCodeRoute} exact>
+                            <BackupCodeMethod
+                                id="backup-code-method"
+                                authenticationLevel={props.authenticationLevel}
+                                remaining={props.userInfo.backup_codes_remaining}
+                                onSignInError={(err) => createErrorNotification(err.message)}
+                                onSignInSuccess={props.onAuthenticationSuccess}
+                            />
+                        </Route>
                         <Route path={SecondFactorRoute}>
                             <Redirect to={SecondFactorTOTPRoute} />
                         </Route>
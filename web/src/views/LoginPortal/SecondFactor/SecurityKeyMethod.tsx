@@ -7,6 +7,7 @@ import u2fApi from "u2f-api";
 import FailureIcon from "@components/FailureIcon";
 import FingerTouchIcon from "@components/FingerTouchIcon";
 import LinearProgressBar from "@components/LinearProgressBar";
+import { useLoginState } from "@hooks/LoginState";
 import { useIsMountedRef } from "@hooks/Mounted";
 import { useRedirectionURL } from "@hooks/RedirectionURL";
 import { useTimer } from "@hooks/Timer";
@@ -36,6 +37,7 @@ const SecurityKeyMethod = function (props: Props) {
     const [state, setState] = useState(State.WaitTouch);
     const style = useStyles();
     const redirectionURL = useRedirectionURL();
+    const loginState = useLoginState();
     const mounted = useIsMountedRef();
     const [timerPercent, triggerTimer] = useTimer(signInTimeout * 1000 - 500);
 
@@ -71,7 +73,7 @@ const SecurityKeyMethod = function (props: Props) {
             if (!mounted.current) return;
 
             setState(State.SigninInProgress);
-            const res = await completeU2FSignin(signResponse, redirectionURL);
+            const res = await completeU2FSignin(signResponse, redirectionURL, loginState);
             onSignInSuccessCallback(res ? res.redirect : undefined);
         } catch (err) {
             // If the request was initiated and the user changed 2FA method in the meantime,
@@ -85,6 +87,7 @@ const SecurityKeyMethod = function (props: Props) {
         onSignInSuccessCallback,
         onSignInErrorCallback,
         redirectionURL,
+        loginState,
         mounted,
         triggerTimer,
         props.authenticationLevel,
@@ -21,6 +21,8 @@ const SignOut = function (props: Props) {
     const redirector = useRedirector();
     const [timedOut, setTimedOut] = useState(false);
     const [safeRedirect, setSafeRedirect] = useState(false);
+    const [frontChannelLogoutURIs, setFrontChannelLogoutURIs] = useState<string[]>([]);
+    const [postLogoutRedirectURI, setPostLogoutRedirectURI] = useState<string | undefined>(undefined);
 
     const doSignOut = useCallback(async () => {
         try {
@@ -28,6 +30,12 @@ const SignOut = function (props: Props) {
             if (res !== undefined && res.safeTargetURL) {
                 setSafeRedirect(true);
             }
+            if (res !== undefined && res.frontChannelLogoutURIs) {
+                setFrontChannelLogoutURIs(res.frontChannelLogoutURIs);
+            }
+            if (res !== undefined && res.postLogoutRedirectURI) {
+                setPostLogoutRedirectURI(res.postLogoutRedirectURI);
+            }
             setTimeout(() => {
                 if (!mounted) {
                     return;
@@ -38,14 +46,24 @@ const SignOut = function (props: Props) {
             console.error(err);
             createErrorNotification("There was an issue signing out");
         }
-    }, [createErrorNotification, redirectionURL, setSafeRedirect, setTimedOut, mounted]);
+    }, [
+        createErrorNotification,
+        redirectionURL,
+        setSafeRedirect,
+        setFrontChannelLogoutURIs,
+        setPostLogoutRedirectURI,
+        setTimedOut,
+        mounted,
+    ]);
 
     useEffect(() => {
         doSignOut();
     }, [doSignOut]);
 
     if (timedOut) {
-        if (redirectionURL && safeRedirect) {
+        if (postLogoutRedirectURI) {
+            redirector(postLogoutRedirectURI);
+        } else if (redirectionURL && safeRedirect) {
             redirector(redirectionURL);
         } else {
             return <Redirect to={FirstFactorRoute} />;
@@ -55,6 +73,9 @@ const SignOut = function (props: Props) {
     return (
         <LoginLayout title="Sign out">
             <Typography className={style.typo}>You're being signed out and redirected...</Typography>
+            {frontChannelLogoutURIs.map((uri) => (
+                <iframe key={uri} src={uri} title="Logout" className={style.frame} />
+            ))}
         </LoginLayout>
     );
 };
@@ -65,4 +86,7 @@ const useStyles = makeStyles((theme) => ({
     typo: {
         padding: theme.spacing(),
     },
+    frame: {
+        display: "none",
+    },
 }));
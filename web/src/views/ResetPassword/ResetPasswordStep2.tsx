@@ -5,9 +5,12 @@ import classnames from "classnames";
 import { useHistory, useLocation } from "react-router";
 
 import FixedTextField from "@components/FixedTextField";
+import PasswordMeter from "@components/PasswordMeter";
 import { FirstFactorRoute } from "@constants/Routes";
 import { useNotifications } from "@hooks/NotificationsContext";
 import LoginLayout from "@layouts/LoginLayout";
+import { PasswordPolicy, PasswordStrength } from "@models/PasswordPolicy";
+import { getPasswordPolicy, getPasswordStrength } from "@services/PasswordPolicy";
 import { completeResetPasswordProcess, resetPassword } from "@services/ResetPassword";
 import { extractIdentityToken } from "@utils/IdentityToken";
 
@@ -19,6 +22,8 @@ const ResetPasswordStep2 = function () {
     const [password2, setPassword2] = useState("");
     const [errorPassword1, setErrorPassword1] = useState(false);
     const [errorPassword2, setErrorPassword2] = useState(false);
+    const [policy, setPolicy] = useState(undefined as PasswordPolicy | undefined);
+    const [strength, setStrength] = useState(undefined as PasswordStrength | undefined);
     const { createSuccessNotification, createErrorNotification } = useNotifications();
     const history = useHistory();
     // Get the token from the query param to give it back to the API when requesting
@@ -35,6 +40,7 @@ const ResetPasswordStep2 = function () {
         try {
             setFormDisabled(true);
             await completeResetPasswordProcess(processToken);
+            setPolicy(await getPasswordPolicy());
             setFormDisabled(false);
         } catch (err) {
             console.error(err);
@@ -49,6 +55,24 @@ const ResetPasswordStep2 = function () {
         completeProcess();
     }, [completeProcess]);
 
+    // The password is evaluated by the server once the user stops typing so the meter follows the enforced policy.
+    useEffect(() => {
+        if (formDisabled || password1 === "") {
+            setStrength(undefined);
+            return;
+        }
+
+        const timeout = setTimeout(async () => {
+            try {
+                setStrength(await getPasswordStrength(password1));
+            } catch (err) {
+                console.error(err);
+            }
+        }, 300);
+
+        return () => clearTimeout(timeout);
+    }, [password1, formDisabled]);
+
     const doResetPassword = async () => {
         if (password1 === "" || password2 === "") {
             if (password1 === "") {
@@ -73,7 +97,10 @@ const ResetPasswordStep2 = function () {
             setFormDisabled(true);
         } catch (err) {
             console.error(err);
-            if (err.message.includes("0000052D.")) {
+            if (
+                err.message.includes("0000052D.") ||
+                err.message.includes("does not meet the password policy requirements")
+            ) {
                 createErrorNotification("Your supplied password does not meet the password policy requirements.");
             } else {
                 createErrorNotification("There was an issue resetting the password.");
@@ -102,6 +129,11 @@ const ResetPasswordStep2 = function () {
                         autoComplete="new-password"
                     />
                 </Grid>
+                {policy ? (
+                    <Grid item xs={12}>
+                        <PasswordMeter policy={policy} strength={strength} />
+                    </Grid>
+                ) : null}
                 <Grid item xs={12}>
                     <FixedTextField
                         id="password2-textfield"
@@ -0,0 +1,92 @@
+import React, { useState } from "react";
+
+import { Grid, Button, Typography, makeStyles } from "@material-ui/core";
+import queryString from "query-string";
+import { useHistory, useLocation } from "react-router";
+
+import { FirstFactorRoute } from "@constants/Routes";
+import { useNotifications } from "@hooks/NotificationsContext";
+import LoginLayout from "@layouts/LoginLayout";
+import { approveSecondFactorReset } from "@services/SecondFactorReset";
+
+// The reset of the second factor requested by an operator is approved by the user with the link sent by email, the
+// user must have signed in with their password first.
+const ApproveSecondFactorReset = function () {
+    const style = useStyles();
+    const location = useLocation();
+    const history = useHistory();
+    const [approving, setApproving] = useState(false);
+    const { createSuccessNotification, createErrorNotification } = useNotifications();
+
+    const queryParams = queryString.parse(location.search);
+    const id = typeof queryParams["id"] === "string" ? queryParams["id"] : null;
+    const token = typeof queryParams["token"] === "string" ? queryParams["token"] : null;
+
+    const handleApproveClick = async () => {
+        if (!id || !token) {
+            createErrorNotification("No approval token provided");
+            return;
+        }
+
+        setApproving(true);
+        try {
+            await approveSecondFactorReset(id, token);
+            createSuccessNotification("Your second factor has been reset, you can now register new devices.");
+            history.push(FirstFactorRoute);
+        } catch (err) {
+            console.error(err);
+            createErrorNotification(
+                "There was an issue approving the reset. Make sure you signed in with your password and the link has not expired.",
+            );
+        }
+        setApproving(false);
+    };
+
+    const handleCancelClick = () => {
+        history.push(FirstFactorRoute);
+    };
+
+    return (
+        <LoginLayout title="Reset second factor" id="approve-second-factor-reset-stage">
+            <Grid container className={style.root} spacing={2}>
+                <Grid item xs={12}>
+                    <Typography>
+                        An operator requested the reset of your second factor. All your devices will be deleted.
+                    </Typography>
+                </Grid>
+                <Grid item xs={6}>
+                    <Button
+                        id="approve-button"
+                        variant="contained"
+                        color="primary"
+                        fullWidth
+                        disabled={approving}
+                        onClick={handleApproveClick}
+                    >
+                        Approve
+                    </Button>
+                </Grid>
+                <Grid item xs={6}>
+                    <Button
+                        id="cancel-button"
+                        variant="contained"
+                        color="primary"
+                        fullWidth
+                        onClick={handleCancelClick}
+                    >
+                        Cancel
+                    </Button>
+                </Grid>
+            </Grid>
+        </LoginLayout>
+    );
+};
+
+export default ApproveSecondFactorReset;
+
+const useStyles = makeStyles((theme) => ({
+    root: {
+        marginTop: theme.spacing(2),
+        marginBottom: theme.spacing(2),
+    },
+}));